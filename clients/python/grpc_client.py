@@ -47,6 +47,7 @@ class Sample:
     success: bool
     error: Optional[str]
     timestamp: datetime
+    message_bytes: int = 0  # serialized size of a stream message, 0 for unary requests
 
 
 class Results:
@@ -110,6 +111,17 @@ class Results:
         idx = min(idx, len(latencies) - 1)
         return latencies[idx]
 
+    def message_size_percentile(self, p: float) -> Optional[int]:
+        sizes = sorted(s.message_bytes for s in self.samples if s.success and s.message_bytes > 0)
+        if not sizes:
+            return None
+        idx = min(int(len(sizes) * p / 100), len(sizes) - 1)
+        return sizes[idx]
+
+    def max_message_size(self) -> Optional[int]:
+        sizes = [s.message_bytes for s in self.samples if s.success and s.message_bytes > 0]
+        return max(sizes) if sizes else None
+
     def avg_latency(self) -> float:
         latencies = self._successful_latencies()
         if not latencies:
@@ -138,6 +150,9 @@ class Results:
         print(f"  min:  {self.min_latency():.2f}ms")
         print(f"  max:  {self.max_latency():.2f}ms")
         print(f"Errors:      {self.total_requests - self.successful_requests} ({self.error_rate:.2f}%)")
+        if self.message_size_percentile(50) is not None:
+            print(f"Msg size:    p50={self.message_size_percentile(50)} B p99={self.message_size_percentile(99)} B "
+                  f"max={self.max_message_size()} B")
         if self.resource_stats:
             print("Resources:")
             print(f"  CPU avg:   {self.resource_stats.cpu_avg_percent:.1f}%")
@@ -165,12 +180,14 @@ class Results:
                 cur.execute(
                     """
                     INSERT INTO benchmark_runs (scenario, protocol, client, concurrency, duration_sec, rate_limit,
-                                                cpu_usage_avg, memory_mb_avg, memory_mb_peak)
-                    VALUES (%s, %s, %s, %s, %s, %s, %s, %s, %s)
+                                                cpu_usage_avg, memory_mb_avg, memory_mb_peak,
+                                                message_p50_bytes, message_p99_bytes, message_max_bytes)
+                    VALUES (%s, %s, %s, %s, %s, %s, %s, %s, %s, %s, %s, %s)
                     RETURNING id
                     """,
                     (scenario, protocol, client, concurrency, int(self.duration_seconds), rate_limit,
-                     cpu_avg, mem_avg, mem_peak),
+                     cpu_avg, mem_avg, mem_peak,
+                     self.message_size_percentile(50), self.message_size_percentile(99), self.max_message_size()),
                 )
                 run_id = cur.fetchone()[0]
 
@@ -300,7 +317,7 @@ class Runner:
         def worker():
             last_event_time = None
             try:
-                for _ in self.client.stream_transactions(self.rate, self._stop_event):
+                for tx in self.client.stream_transactions(self.rate, self._stop_event):
                     if time.time() >= end_time:
                         break
 
@@ -315,6 +332,7 @@ class Runner:
                         success=True,
                         error=None,
                         timestamp=datetime.now(),
+                        message_bytes=tx.ByteSize(),
                     ))
             except Exception as e:
                 if not self._stop_event.is_set():
@@ -5,6 +5,7 @@ use std::time::{Duration, Instant};
 use clap::Parser;
 use deadpool_postgres::{Config as PoolConfig, Pool, Runtime};
 use futures::stream::StreamExt;
+use prost::Message;
 use rand::rngs::StdRng;
 use rand::seq::SliceRandom;
 use rand::SeedableRng;
@@ -79,6 +80,8 @@ struct Args {
 struct Sample {
     latency: Duration,
     success: bool,
+    /// Serialized size of a stream message, 0 for unary requests
+    message_bytes: usize,
 }
 
 #[derive(Debug, Default)]
@@ -121,6 +124,30 @@ impl Results {
         latencies[idx.min(latencies.len() - 1)]
     }
 
+    fn message_sizes(&self) -> Vec<usize> {
+        let mut sizes: Vec<usize> = self
+            .samples
+            .iter()
+            .filter(|s| s.success && s.message_bytes > 0)
+            .map(|s| s.message_bytes)
+            .collect();
+        sizes.sort();
+        sizes
+    }
+
+    fn message_size_percentile(&self, p: f64) -> Option<i32> {
+        let sizes = self.message_sizes();
+        if sizes.is_empty() {
+            return None;
+        }
+        let idx = ((p / 100.0) * sizes.len() as f64) as usize;
+        Some(sizes[idx.min(sizes.len() - 1)] as i32)
+    }
+
+    fn max_message_size(&self) -> Option<i32> {
+        self.message_sizes().last().map(|&n| n as i32)
+    }
+
     fn avg_latency(&self) -> Duration {
         let latencies = self.latencies();
         if latencies.is_empty() {
@@ -195,6 +222,14 @@ impl Results {
             self.error_count(),
             self.error_count() as f64 / self.samples.len().max(1) as f64 * 100.0
         );
+        if let Some(p50) = self.message_size_percentile(50.0) {
+            println!(
+                "Msg size:    p50={} B p99={} B max={} B",
+                p50,
+                self.message_size_percentile(99.0).unwrap_or(0),
+                self.max_message_size().unwrap_or(0)
+            );
+        }
         println!("Resources:");
         println!("  CPU avg:   {:.1}%", self.avg_cpu());
         println!("  Mem avg:   {:.1} MB", self.avg_mem_mb());
@@ -310,8 +345,9 @@ async fn store_results(
     let row = client
         .query_one(
             "INSERT INTO benchmark_runs (scenario, protocol, client, concurrency, duration_sec, \
-             cpu_usage_avg, memory_mb_avg, memory_mb_peak) \
-             VALUES ($1, $2, $3, $4, $5, $6, $7, $8) \
+             cpu_usage_avg, memory_mb_avg, memory_mb_peak, \
+             message_p50_bytes, message_p99_bytes, message_max_bytes) \
+             VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11) \
              RETURNING id",
             &[
                 &scenario,
@@ -322,6 +358,9 @@ async fn store_results(
                 &(results.avg_cpu() as f64),
                 &results.avg_mem_mb(),
                 &results.peak_mem_mb(),
+                &results.message_size_percentile(50.0),
+                &results.message_size_percentile(99.0),
+                &results.max_message_size(),
             ],
         )
         .await?;
@@ -438,7 +477,13 @@ async fn run_grpc_balance(
                 let success = result.is_ok();
 
                 request_count.fetch_add(1, Ordering::Relaxed);
-                let _ = tx.send(Sample { latency, success }).await;
+                let _ = tx
+                    .send(Sample {
+                        latency,
+                        success,
+                        message_bytes: 0,
+                    })
+                    .await;
             }
         });
     }
@@ -523,7 +568,13 @@ async fn run_rest_balance(
                     Err(_) => false,
                 };
 
-                let _ = tx.send(Sample { latency, success }).await;
+                let _ = tx
+                    .send(Sample {
+                        latency,
+                        success,
+                        message_bytes: 0,
+                    })
+                    .await;
             }
         });
     }
@@ -615,7 +666,7 @@ async fn run_grpc_stream(addr: &str, concurrency: usize, duration: Duration, rat
             let mut last_event = Instant::now();
             while running.load(Ordering::Relaxed) {
                 match stream.next().await {
-                    Some(Ok(_)) => {
+                    Some(Ok(msg)) => {
                         let now = Instant::now();
                         let latency = now.duration_since(last_event);
                         last_event = now;
@@ -624,6 +675,7 @@ async fn run_grpc_stream(addr: &str, concurrency: usize, duration: Duration, rat
                             .send(Sample {
                                 latency,
                                 success: true,
+                                message_bytes: msg.encoded_len(),
                             })
                             .await;
                     }
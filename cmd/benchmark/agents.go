@@ -0,0 +1,66 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/kaldun-tech/grpc-rest-benchmark/pkg/db"
+)
+
+// allHealthyAgents selects every healthy registered agent in --agents.
+const allHealthyAgents = "healthy"
+
+// resolveAgents looks up the registered agents named in --agents, or every
+// healthy one for "healthy", failing if any is unknown or unhealthy.
+func resolveAgents(ctx context.Context, global *globalOptions, names []string) ([]*db.Agent, error) {
+	database, err := global.connectDB(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("agents are registered in PostgreSQL: %w", err)
+	}
+	defer database.Close()
+
+	healthy, err := database.ListAgents(ctx, true)
+	if err != nil {
+		return nil, err
+	}
+	if slices.Equal(names, []string{allHealthyAgents}) {
+		if len(healthy) == 0 {
+			return nil, fmt.Errorf("no healthy agents registered")
+		}
+		return healthy, nil
+	}
+
+	agents := make([]*db.Agent, 0, len(names))
+	for _, name := range names {
+		if slices.ContainsFunc(agents, func(a *db.Agent) bool { return a.Name == name }) {
+			return nil, fmt.Errorf("agent %s given twice", name)
+		}
+		i := slices.IndexFunc(healthy, func(a *db.Agent) bool { return a.Name == name })
+		if i >= 0 {
+			agents = append(agents, healthy[i])
+			continue
+		}
+		a, err := database.GetAgent(ctx, name)
+		if errors.Is(err, db.ErrAgentNotFound) {
+			return nil, fmt.Errorf("agent %s is not registered", name)
+		}
+		if err != nil {
+			return nil, err
+		}
+		return nil, fmt.Errorf("agent %s is not healthy (last heartbeat %s)", name, a.LastHeartbeat.Format("2006-01-02 15:04:05"))
+	}
+	return agents, nil
+}
+
+// agentsLabel returns the names of the run's agents, comma-separated, as
+// recorded with the run.
+func agentsLabel(agents []*db.Agent) string {
+	names := make([]string, len(agents))
+	for i, a := range agents {
+		names[i] = a.Name
+	}
+	return strings.Join(names, ",")
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+func newDumpAccountsCmd(global *globalOptions) *cobra.Command {
+	var out string
+
+	cmd := &cobra.Command{
+		Use:   "dump-accounts",
+		Short: "Write the account IDs benchmarks query to a file",
+		Long: `Dump-accounts writes every seeded account ID to --out, one per line, as run
+loads them: from the PostgreSQL database named by the --db-* flags, or from
+the cache of a local results backend. Runs given the file with
+--accounts-file query the same working set without loading it from the
+database, so benchmark agents and remote runs stay consistent with it.`,
+		Example: `  benchmark dump-accounts --out ids.txt
+  benchmark run --accounts-file ids.txt --no-db --rest-addr=https://staging.example.com`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx, cancel := signalContext()
+			defer cancel()
+
+			results, err := global.openResults(ctx)
+			if err != nil {
+				return err
+			}
+			defer results.Close()
+			dataset, closeDataset := datasetDB(ctx, global, results)
+			defer closeDataset()
+
+			ids, err := loadAccountIDs(ctx, dataset, results)
+			if err != nil {
+				return err
+			}
+
+			f, err := os.Create(out)
+			if err != nil {
+				return fmt.Errorf("failed to create account IDs file: %w", err)
+			}
+			if err := writeAccountIDs(f, ids); err != nil {
+				f.Close()
+				return err
+			}
+			if err := f.Close(); err != nil {
+				return fmt.Errorf("failed to write account IDs: %w", err)
+			}
+			log.Printf("Wrote %d account IDs to %s", len(ids), out)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&out, "out", "", "Account IDs file to write")
+	cmd.MarkFlagRequired("out")
+	cmd.MarkFlagFilename("out")
+	return cmd
+}
+
+// writeAccountIDs writes ids to w, one per line, in the format
+// readAccountIDs reads.
+func writeAccountIDs(w io.Writer, ids []string) error {
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "# %d account IDs\n", len(ids))
+	for _, id := range ids {
+		bw.WriteString(id)
+		bw.WriteByte('\n')
+	}
+	if err := bw.Flush(); err != nil {
+		return fmt.Errorf("failed to write account IDs: %w", err)
+	}
+	return nil
+}
+
+// readAccountIDs reads account IDs from path, one per line. Blank lines and
+// lines starting with # are skipped.
+func readAccountIDs(path string) ([]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read account IDs: %w", err)
+	}
+	var ids []string
+	for line := range strings.Lines(string(data)) {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		ids = append(ids, line)
+	}
+	if len(ids) == 0 {
+		return nil, fmt.Errorf("no account IDs in %s", path)
+	}
+	return ids, nil
+}
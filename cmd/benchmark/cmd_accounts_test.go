@@ -0,0 +1,49 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestReadAccountIDs(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "accounts.txt")
+	if err := os.WriteFile(path, []byte("# remote accounts\n0.0.1001\n\n  0.0.1002  \n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	ids, err := readAccountIDs(path)
+	if err != nil {
+		t.Fatalf("readAccountIDs() error = %v", err)
+	}
+	if !slices.Equal(ids, []string{"0.0.1001", "0.0.1002"}) {
+		t.Errorf("readAccountIDs() = %v, want [0.0.1001 0.0.1002]", ids)
+	}
+
+	empty := filepath.Join(t.TempDir(), "empty.txt")
+	if err := os.WriteFile(empty, []byte("# none\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := readAccountIDs(empty); err == nil {
+		t.Error("readAccountIDs() of a file without IDs = nil error, want an error")
+	}
+}
+
+func TestWriteAccountIDs(t *testing.T) {
+	var buf bytes.Buffer
+	if err := writeAccountIDs(&buf, []string{"0.0.1001", "0.0.1002"}); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "ids.txt")
+	if err := os.WriteFile(path, buf.Bytes(), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	ids, err := readAccountIDs(path)
+	if err != nil {
+		t.Fatalf("readAccountIDs() error = %v", err)
+	}
+	if !slices.Equal(ids, []string{"0.0.1001", "0.0.1002"}) {
+		t.Errorf("round trip = %v, want [0.0.1001 0.0.1002]", ids)
+	}
+}
@@ -0,0 +1,472 @@
+package main
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+
+	"github.com/kaldun-tech/grpc-rest-benchmark/pkg/buildinfo"
+	"github.com/kaldun-tech/grpc-rest-benchmark/pkg/db"
+	"github.com/kaldun-tech/grpc-rest-benchmark/pkg/workload"
+)
+
+// bundleVersion is the layout version of the bundles 'benchmark bundle'
+// writes and 'run --from-bundle' reads.
+const bundleVersion = 1
+
+// Entries of a bundle. Input files go under bundleInputs.
+const (
+	bundleManifest = "manifest.json"
+	bundleRun      = "run.json"
+	bundleWorkload = "workload.yaml"
+	bundleRunLog   = "run-log.jsonl"
+	bundleInputs   = "inputs/"
+)
+
+// runConfig is the configuration snapshot stored with every run, from which
+// 'benchmark bundle' packages the run and 'run --from-bundle' repeats it.
+type runConfig struct {
+	Args      []string `json:"args"`                 // run flags that differ from their defaults
+	Workload  string   `json:"workload,omitempty"`   // workload file of a stage run, which Args leaves to it
+	Stage     string   `json:"stage,omitempty"`      // the stage of the workload
+	ChurnSeed int64    `json:"churn_seed,omitempty"` // seed of the working set rotations, 0 without churn
+}
+
+// localRunFlags are the run flags that only concern the machine a run was
+// made on, such as where its output goes, left out of its snapshot.
+var localRunFlags = []string{
+	"workers", "environment", "allow-concurrent", "tag", "server-log", "profile-dir", "attach-profiles",
+	"log-dir", "log-interval", "sample-flush", "no-db", "results-dir", "run-ids-file", "hcs-save",
+}
+
+// inputRunFlags are the run flags naming input files, which bundles carry.
+var inputRunFlags = []string{"accounts-file", "replay-timing"}
+
+// runFlags returns the flags of the run command bound to o, which they set
+// to the defaults.
+func runFlags(o *runOptions) *pflag.FlagSet {
+	cmd := &cobra.Command{}
+	cmd.Flags().StringVar(&o.protocol, "protocol", "grpc", "")
+	addRunFlags(cmd, o)
+	return cmd.Flags()
+}
+
+// snapshot returns the configuration snapshot of a run made with o, as
+// stored in benchmark_runs.run_config.
+func (o *runOptions) snapshot(churnSeed int64) (string, error) {
+	var current runOptions
+	flags := runFlags(&current)
+	current = *o
+
+	cfg := runConfig{Args: []string{}, ChurnSeed: churnSeed}
+	if o.workload != "" {
+		b, err := os.ReadFile(o.workloadPath)
+		if err != nil {
+			return "", fmt.Errorf("failed to read workload: %w", err)
+		}
+		cfg.Workload, cfg.Stage = string(b), o.stage
+	}
+	flags.VisitAll(func(f *pflag.Flag) {
+		if slices.Contains(localRunFlags, f.Name) || f.Value.String() == f.DefValue {
+			return
+		}
+		if cfg.Workload != "" && slices.Contains(workloadFlags, f.Name) {
+			return
+		}
+		if s, ok := f.Value.(pflag.SliceValue); ok {
+			for _, v := range s.GetSlice() {
+				cfg.Args = append(cfg.Args, "--"+f.Name+"="+v)
+			}
+			return
+		}
+		cfg.Args = append(cfg.Args, "--"+f.Name+"="+f.Value.String())
+	})
+
+	b, err := json.Marshal(cfg)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+// inputFiles returns the input files a run read, by path: its accounts and
+// timing files.
+func (c *runConfig) inputFiles() ([]string, error) {
+	var paths []string
+	for _, arg := range c.Args {
+		name, value, _ := strings.Cut(strings.TrimPrefix(arg, "--"), "=")
+		if slices.Contains(inputRunFlags, name) && value != "" {
+			paths = append(paths, value)
+		}
+	}
+	if c.Workload != "" {
+		w, err := workload.Parse([]byte(c.Workload))
+		if err != nil {
+			return nil, fmt.Errorf("workload: %w", err)
+		}
+		if w.Arrival.Timing != "" {
+			paths = append(paths, w.Arrival.Timing)
+		}
+	}
+	slices.Sort(paths)
+	return slices.Compact(paths), nil
+}
+
+// manifest is the manifest.json of a bundle.
+type manifest struct {
+	Version   int               `json:"version"`
+	RunID     int64             `json:"run_id"`
+	CreatedAt time.Time         `json:"created_at"` // when the run was made
+	Config    runConfig         `json:"config"`
+	Inputs    map[string]string `json:"inputs,omitempty"` // paths the run read input files from, by entry
+}
+
+// bundleOptions holds flags for the bundle subcommand.
+type bundleOptions struct {
+	runID int64
+	out   string
+}
+
+func newBundleCmd(global *globalOptions) *cobra.Command {
+	opts := &bundleOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "bundle",
+		Short: "Package a stored run with everything needed to reproduce it elsewhere",
+		Long: `Bundle writes a gzipped tar file with a stored run's configuration snapshot:
+its run flags, workload stage and churn seed, the accounts and timing files
+it read, its run log, and the stored run itself with its results, dataset
+fingerprint and environment (client and server commits, Go version,
+GOMAXPROCS, kernel, host).
+
+'benchmark run --from-bundle' repeats the run from the bundle on another
+machine, with any flags given alongside it taking precedence, and warns
+where the dataset or client differ from the original.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if opts.out == "" {
+				opts.out = fmt.Sprintf("run%d.tar.gz", opts.runID)
+			}
+			ctx, cancel := signalContext()
+			defer cancel()
+			return runBundleCmd(ctx, global, opts)
+		},
+	}
+
+	f := cmd.Flags()
+	f.Int64Var(&opts.runID, "run", 0, "ID of the run to bundle")
+	f.StringVar(&opts.out, "out", "", "Bundle file to write (default run<ID>.tar.gz)")
+	cmd.MarkFlagRequired("run")
+	cmd.MarkFlagFilename("out", "tar.gz", "tgz")
+
+	return cmd
+}
+
+// runBundleCmd writes the bundle of a stored run.
+func runBundleCmd(ctx context.Context, global *globalOptions, opts *bundleOptions) error {
+	results, err := global.openResults(ctx)
+	if err != nil {
+		return err
+	}
+	defer results.Close()
+
+	runs, err := results.GetRuns(ctx, db.StatsFilter{RunIDs: []int64{opts.runID}})
+	if err != nil {
+		return err
+	}
+	if len(runs) == 0 {
+		return fmt.Errorf("run %d not found", opts.runID)
+	}
+
+	if err := writeFile(opts.out, func(f *os.File) error {
+		return writeBundle(f, runs[0])
+	}); err != nil {
+		return fmt.Errorf("failed to write %s: %w", opts.out, err)
+	}
+	fmt.Printf("Wrote run %d to %s\n", opts.runID, opts.out)
+	fmt.Printf("Reproduce with: benchmark run --from-bundle=%s\n", opts.out)
+	return nil
+}
+
+// writeBundle writes the bundle of run to w. Input files must still be at
+// the paths the run read them from; the run log is included if it is.
+func writeBundle(w io.Writer, run *db.BenchmarkRun) error {
+	if run.RunConfig == nil {
+		return fmt.Errorf("run %d has no configuration snapshot; it was stored before snapshots were recorded", run.ID)
+	}
+	m := manifest{Version: bundleVersion, RunID: run.ID, CreatedAt: run.CreatedAt, Inputs: map[string]string{}}
+	if err := json.Unmarshal([]byte(*run.RunConfig), &m.Config); err != nil {
+		return fmt.Errorf("invalid configuration snapshot of run %d: %w", run.ID, err)
+	}
+	inputs, err := m.Config.inputFiles()
+	if err != nil {
+		return err
+	}
+
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+	add := func(name string, data []byte) error {
+		if err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0o644, Size: int64(len(data)), ModTime: time.Now()}); err != nil {
+			return err
+		}
+		_, err := tw.Write(data)
+		return err
+	}
+
+	for i, p := range inputs {
+		data, err := os.ReadFile(p)
+		if err != nil {
+			return fmt.Errorf("input file of run %d: %w", run.ID, err)
+		}
+		name := fmt.Sprintf("%s%d-%s", bundleInputs, i+1, filepath.Base(p))
+		m.Inputs[name] = p
+		if err := add(name, data); err != nil {
+			return err
+		}
+	}
+
+	b, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := add(bundleManifest, b); err != nil {
+		return err
+	}
+	if b, err = json.MarshalIndent(run, "", "  "); err != nil {
+		return err
+	}
+	if err := add(bundleRun, b); err != nil {
+		return err
+	}
+	if m.Config.Workload != "" {
+		if err := add(bundleWorkload, []byte(m.Config.Workload)); err != nil {
+			return err
+		}
+	}
+	if run.LogPath != nil {
+		if b, err := os.ReadFile(*run.LogPath); err == nil {
+			if err := add(bundleRunLog, b); err != nil {
+				return err
+			}
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	return gz.Close()
+}
+
+// runBundle is a bundle opened by 'run --from-bundle', its workload and
+// input files extracted to a temporary directory.
+type runBundle struct {
+	manifest
+	run db.BenchmarkRun
+	dir string
+
+	workloadPath string // extracted workload file, "" for runs without one
+}
+
+// openBundle reads and extracts the bundle at path. Close removes the
+// extracted files.
+func openBundle(path string) (*runBundle, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	dir, err := os.MkdirTemp("", "benchmark-bundle-")
+	if err != nil {
+		return nil, err
+	}
+	b := &runBundle{dir: dir}
+	if err := b.extract(tar.NewReader(gz)); err != nil {
+		b.Close()
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return b, nil
+}
+
+// extract reads the entries of a bundle, writing its workload and input
+// files to b.dir.
+func (b *runBundle) extract(tr *tar.Reader) error {
+	var haveManifest, haveRun bool
+	extracted := map[string]bool{}
+	for {
+		h, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+
+		switch {
+		case h.Name == bundleManifest:
+			if err := json.NewDecoder(tr).Decode(&b.manifest); err != nil {
+				return fmt.Errorf("invalid %s: %w", bundleManifest, err)
+			}
+			haveManifest = true
+		case h.Name == bundleRun:
+			if err := json.NewDecoder(tr).Decode(&b.run); err != nil {
+				return fmt.Errorf("invalid %s: %w", bundleRun, err)
+			}
+			haveRun = true
+		case h.Name == bundleWorkload, strings.HasPrefix(h.Name, bundleInputs):
+			// Entries are only written below dir, never to a path they name
+			base := path.Base(h.Name)
+			if h.Name != bundleWorkload && (h.Name != bundleInputs+base || base == "." || base == "..") {
+				return fmt.Errorf("invalid entry %s", h.Name)
+			}
+			if err := b.write(h.Name, tr); err != nil {
+				return err
+			}
+			extracted[h.Name] = true
+		}
+	}
+
+	switch {
+	case !haveManifest || !haveRun:
+		return errors.New("not a run bundle: missing manifest or run")
+	case b.Version != bundleVersion:
+		return fmt.Errorf("bundle version %d is not supported (want %d)", b.Version, bundleVersion)
+	}
+	for name := range b.Inputs {
+		if !extracted[name] {
+			return fmt.Errorf("input file %s missing", name)
+		}
+	}
+	if b.Config.Workload != "" {
+		b.workloadPath = b.path(bundleWorkload)
+		if !extracted[bundleWorkload] {
+			return fmt.Errorf("%s missing", bundleWorkload)
+		}
+	}
+	return nil
+}
+
+// path returns the extracted file of an entry.
+func (b *runBundle) path(name string) string {
+	return filepath.Join(b.dir, filepath.FromSlash(name))
+}
+
+// write extracts the entry name from r.
+func (b *runBundle) write(name string, r io.Reader) error {
+	p := b.path(name)
+	if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+		return err
+	}
+	return writeFile(p, func(f *os.File) error {
+		_, err := io.Copy(f, r)
+		return err
+	})
+}
+
+// Close removes the extracted files.
+func (b *runBundle) Close() error {
+	return os.RemoveAll(b.dir)
+}
+
+// input returns the extracted copy of the input file the run read from p,
+// or p itself if the bundle does not carry it.
+func (b *runBundle) input(p string) string {
+	for name, orig := range b.Inputs {
+		if orig == p {
+			return b.path(name)
+		}
+	}
+	return p
+}
+
+// apply sets the run flags of cmd to the bundled run's, except those given
+// on the command line, and tags the run with the ID it reproduces.
+func (b *runBundle) apply(cmd *cobra.Command, opts *runOptions) error {
+	var bundled runOptions
+	flags := runFlags(&bundled)
+	if err := flags.Parse(b.Config.Args); err != nil {
+		return fmt.Errorf("invalid configuration snapshot of run %d: %w", b.RunID, err)
+	}
+
+	var err error
+	flags.Visit(func(f *pflag.Flag) {
+		target := cmd.Flags().Lookup(f.Name)
+		if err != nil || target == nil || target.Changed {
+			return
+		}
+		if s, ok := f.Value.(pflag.SliceValue); ok {
+			err = target.Value.(pflag.SliceValue).Replace(s.GetSlice())
+			return
+		}
+		value := f.Value.String()
+		if slices.Contains(inputRunFlags, f.Name) {
+			value = b.input(value)
+		}
+		err = target.Value.Set(value)
+	})
+	if err != nil {
+		return err
+	}
+
+	if b.workloadPath != "" {
+		opts.workloadPath = b.workloadPath
+	}
+	opts.tags = append(opts.tags, fmt.Sprintf("reproduces=%d", b.RunID))
+	opts.bundle = b
+	return nil
+}
+
+// applyWorkload narrows the bundled workload w to the stage the run made,
+// with its churn seed and the extracted timing file.
+func (b *runBundle) applyWorkload(w *workload.Workload) error {
+	i := slices.IndexFunc(w.Stages, func(s workload.Stage) bool { return s.Name == b.Config.Stage })
+	if i < 0 {
+		return fmt.Errorf("bundled workload has no stage %q", b.Config.Stage)
+	}
+	w.Stages = w.Stages[i : i+1]
+	if w.Accounts.ChurnSeed == 0 {
+		w.Accounts.ChurnSeed = b.Config.ChurnSeed
+	}
+	if w.Arrival.Timing != "" {
+		w.Arrival.Timing = b.input(w.Arrival.Timing)
+	}
+	return nil
+}
+
+// check warns where the environment of the run differs from the bundled
+// run's: the seeded dataset, the accounts and timing loaded, or the client.
+func (b *runBundle) check(ctx context.Context, env *runEnv) {
+	fmt.Printf("Reproducing run %d of %s\n", b.RunID, b.CreatedAt.Format(time.DateTime))
+	if differ(b.run.DatasetFingerprint, env.datasetFingerprint) {
+		warnf(ctx, "dataset %s differs from %s of run %d", optionalLabel(env.datasetFingerprint), optionalLabel(b.run.DatasetFingerprint), b.RunID)
+	}
+	if differ(b.run.DatasetHash, env.datasetHash) {
+		warnf(ctx, "accounts and timing loaded differ from those of run %d", b.RunID)
+	}
+	if commit := buildinfo.Commit(); commit != "" && b.run.ClientCommit != nil && commit != *b.run.ClientCommit {
+		warnf(ctx, "client commit %s differs from %s of run %d", commit, *b.run.ClientCommit, b.RunID)
+	}
+}
+
+// differ reports whether a value recorded with the bundled run differs from
+// the value of this run. Nothing recorded matches anything.
+func differ(bundled, current *string) bool {
+	return bundled != nil && (current == nil || *current != *bundled)
+}
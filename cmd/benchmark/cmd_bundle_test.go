@@ -0,0 +1,140 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/kaldun-tech/grpc-rest-benchmark/pkg/db"
+	"github.com/kaldun-tech/grpc-rest-benchmark/pkg/workload"
+)
+
+const bundleTestWorkload = `version: 1
+name: ramp
+protocol: grpc
+accounts:
+  pattern: uniform
+  working_set: 0.05
+  churn: 0.1
+  churn_interval: 1m
+operations:
+  - scenario: balance
+stages:
+  - name: warmup
+    duration: 10s
+    concurrency: 5
+  - name: peak
+    duration: 30s
+    concurrency: 50
+`
+
+func TestRunOptions_Snapshot(t *testing.T) {
+	var opts runOptions
+	runFlags(&opts)
+	opts.scenario = "stream"
+	opts.headers = []string{"X-A=1", "X-B=2"}
+	opts.percentiles = []string{"p50"}
+	opts.logDir = "elsewhere" // local to the machine, left out
+
+	snapshot, err := opts.snapshot(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var cfg runConfig
+	if err := json.Unmarshal([]byte(snapshot), &cfg); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"--header=X-A=1", "--header=X-B=2", "--percentiles=p50", "--scenario=stream"}
+	if !slices.Equal(cfg.Args, want) {
+		t.Errorf("args = %q, want %q", cfg.Args, want)
+	}
+
+	// The snapshot applies to a run command, except for flags given on it
+	var got runOptions
+	cmd := &cobra.Command{}
+	cmd.Flags().AddFlagSet(runFlags(&got))
+	if err := cmd.Flags().Set("percentiles", "p99"); err != nil {
+		t.Fatal(err)
+	}
+	b := &runBundle{manifest: manifest{RunID: 7, Config: cfg}}
+	if err := b.apply(cmd, &got); err != nil {
+		t.Fatalf("apply() error = %v", err)
+	}
+	if got.scenario != "stream" || !slices.Equal(got.headers, opts.headers) {
+		t.Errorf("applied scenario %q, headers %q, want the snapshot's", got.scenario, got.headers)
+	}
+	if !slices.Equal(got.percentiles, []string{"p99"}) {
+		t.Errorf("percentiles = %q, want the command line's p99", got.percentiles)
+	}
+	if got.logDir != "logs" || !slices.Contains(got.tags, "reproduces=7") {
+		t.Errorf("log dir %q, tags %q, want the default and reproduces=7", got.logDir, got.tags)
+	}
+}
+
+func TestBundle_RoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	accounts := filepath.Join(dir, "accounts.txt")
+	if err := os.WriteFile(accounts, []byte("0.0.100000\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	workloadPath := filepath.Join(dir, "ramp.yaml")
+	if err := os.WriteFile(workloadPath, []byte(bundleTestWorkload), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	var opts runOptions
+	runFlags(&opts)
+	opts.accountIDsFile = accounts
+	opts.workloadPath, opts.workload, opts.stage = workloadPath, "ramp", "peak"
+	opts.concurrency = 50 // set by the stage, so left to the workload
+	snapshot, err := opts.snapshot(42)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fingerprint := "10000 accounts/abc"
+	run := &db.BenchmarkRun{ID: 12, CreatedAt: time.Now().UTC(), RunConfig: &snapshot, DatasetFingerprint: &fingerprint}
+
+	out := filepath.Join(dir, "run12.tar.gz")
+	if err := writeFile(out, func(f *os.File) error { return writeBundle(f, run) }); err != nil {
+		t.Fatalf("writeBundle() error = %v", err)
+	}
+	b, err := openBundle(out)
+	if err != nil {
+		t.Fatalf("openBundle() error = %v", err)
+	}
+	defer b.Close()
+
+	if b.RunID != 12 || b.run.DatasetFingerprint == nil || *b.run.DatasetFingerprint != fingerprint {
+		t.Errorf("bundle of run %d, fingerprint %v, want run 12 and its fingerprint", b.RunID, b.run.DatasetFingerprint)
+	}
+	if want := []string{"--accounts-file=" + accounts}; !slices.Equal(b.Config.Args, want) {
+		t.Errorf("args = %q, want %q", b.Config.Args, want)
+	}
+	extracted := b.input(accounts)
+	if data, err := os.ReadFile(extracted); err != nil || string(data) != "0.0.100000\n" {
+		t.Errorf("extracted accounts %s = %q, %v, want the accounts file", extracted, data, err)
+	}
+
+	w, err := workload.Load(b.workloadPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := b.applyWorkload(w); err != nil {
+		t.Fatalf("applyWorkload() error = %v", err)
+	}
+	if len(w.Stages) != 1 || w.Stages[0].Name != "peak" || w.Accounts.ChurnSeed != 42 {
+		t.Errorf("workload narrowed to %d stage(s), churn seed %d, want peak with seed 42", len(w.Stages), w.Accounts.ChurnSeed)
+	}
+}
+
+func TestWriteBundle_NoSnapshot(t *testing.T) {
+	if err := writeBundle(io.Discard, &db.BenchmarkRun{ID: 3}); err == nil {
+		t.Error("writeBundle() of a run without a snapshot succeeded, want an error")
+	}
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	"github.com/kaldun-tech/grpc-rest-benchmark/pkg/auth"
+)
+
+func newCertsCmd() *cobra.Command {
+	var (
+		dir   string
+		hosts []string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "certs",
+		Short: "Generate a throwaway CA, server and client certificate for --auth=mtls",
+		Long: `Certs writes a new CA and a server and client certificate signed by it to
+--dir, for benchmarking with mutual TLS. Start the servers with the server
+certificate and the benchmark with the client certificate, both trusting the
+CA. The server certificate is valid for --hosts.`,
+		Example: `  benchmark certs --dir=certs
+  benchmark run --auth=mtls --tls-cert=certs/client.pem --tls-key=certs/client-key.pem --tls-ca=certs/ca.pem --rest-addr=https://localhost:8080`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := auth.GenerateCerts(dir, hosts); err != nil {
+				return err
+			}
+			fmt.Printf("Wrote %s, %s, %s, %s and %s to %s\n",
+				auth.CACertFile, auth.ServerCertFile, auth.ServerKeyFile, auth.ClientCertFile, auth.ClientKeyFile, filepath.Clean(dir))
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&dir, "dir", "certs", "Directory the certificates and keys are written to")
+	cmd.Flags().StringSliceVar(&hosts, "hosts", []string{"localhost", "127.0.0.1", "::1"}, "Host names and IP addresses the server certificate is valid for")
+	return cmd
+}
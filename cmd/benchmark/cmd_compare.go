@@ -0,0 +1,175 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/kaldun-tech/grpc-rest-benchmark/pkg/bench"
+	"github.com/kaldun-tech/grpc-rest-benchmark/pkg/db"
+)
+
+// compareOptions holds flags for the compare subcommand.
+type compareOptions struct {
+	run       runOptions
+	protocols []string
+	pause     time.Duration
+}
+
+func newCompareCmd(global *globalOptions) *cobra.Command {
+	opts := &compareOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "compare",
+		Short: "Run the same benchmark against two protocols back to back and diff the results",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx, cancel := signalContext()
+			defer cancel()
+			if err := resolveEnvironment(ctx, cmd, global, &opts.run); err != nil {
+				return err
+			}
+			if err := opts.validate(); err != nil {
+				return err
+			}
+			return runCompare(ctx, global, opts)
+		},
+	}
+
+	f := cmd.Flags()
+	f.StringSliceVar(&opts.protocols, "protocols", []string{"grpc", "rest"}, "Two protocols to compare; the first is the baseline")
+	f.DurationVar(&opts.pause, "pause", 5*time.Second, "Pause between runs to let the servers settle")
+	cmd.RegisterFlagCompletionFunc("protocols", fixedCompletion(bench.Protocols))
+	addRunFlags(cmd, &opts.run)
+
+	return cmd
+}
+
+// validate checks compare flags for invalid values.
+func (o *compareOptions) validate() error {
+	if len(o.protocols) != 2 {
+		return fmt.Errorf("compare needs exactly two protocols, got %d", len(o.protocols))
+	}
+	if o.protocols[0] == o.protocols[1] {
+		return fmt.Errorf("compare needs two different protocols")
+	}
+	if o.pause < 0 {
+		return fmt.Errorf("pause must not be negative")
+	}
+	for _, protocol := range o.protocols {
+		run := o.forProtocol(protocol)
+		if err := run.validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// forProtocol returns a copy of the shared run options targeting protocol.
+func (o *compareOptions) forProtocol(protocol string) *runOptions {
+	run := o.run
+	run.protocol = protocol
+	return &run
+}
+
+// runCompare runs the benchmark once per protocol with identical settings,
+// tags both runs with a shared comparison ID and prints a side-by-side diff.
+func runCompare(ctx context.Context, global *globalOptions, opts *compareOptions) error {
+	env, err := prepareRun(ctx, global, &opts.run, opts.run.needsAccounts())
+	if err != nil {
+		return err
+	}
+	defer env.Close()
+
+	comparisonID := fmt.Sprintf("cmp-%s", time.Now().Format("20060102-150405"))
+	env.comparisonID = &comparisonID
+
+	var labels []string
+	var results []*bench.Results
+	var runIDs []int64
+	for i, protocol := range opts.protocols {
+		if i > 0 && opts.pause > 0 {
+			fmt.Printf("Pausing %s before the next run...\n", opts.pause)
+			select {
+			case <-time.After(opts.pause):
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+
+		run := opts.forProtocol(protocol)
+		r, runID, err := executeRun(ctx, global, run, env)
+		if err != nil {
+			return fmt.Errorf("%s run failed: %w", protocol, err)
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		labels = append(labels, run.protocolLabel())
+		results = append(results, r)
+		runIDs = append(runIDs, runID)
+	}
+
+	fmt.Printf("\nComparison %s (%s scenario, concurrency %d)\n", comparisonID, opts.run.scenario, opts.run.concurrency)
+	fmt.Printf("Runs: %s=%d, %s=%d\n\n", labels[0], runIDs[0], labels[1], runIDs[1])
+	printComparison(os.Stdout, labels[0], labels[1], results[0], results[1])
+	return nil
+}
+
+// printComparison writes a side-by-side table of two runs. Deltas are the
+// change from the baseline a to b.
+func printComparison(out io.Writer, labelA, labelB string, a, b *bench.Results) {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "METRIC\t%s\t%s\tDELTA\n", strings.ToUpper(labelA), strings.ToUpper(labelB))
+
+	fmt.Fprintf(w, "throughput (req/s)\t%.2f\t%.2f\t%s\n",
+		a.Throughput(), b.Throughput(), percentDelta(a.Throughput(), b.Throughput()))
+
+	latencyRow := func(name string, da, db time.Duration) {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", name, bench.FormatLatency(da), bench.FormatLatency(db),
+			percentDelta(float64(da), float64(db)))
+	}
+	for _, p := range []float64{50, 90, 99, 99.9} {
+		latencyRow(fmt.Sprintf("p%g latency", p), a.Percentile(p), b.Percentile(p))
+	}
+	latencyRow("avg latency", a.AvgLatency(), b.AvgLatency())
+	latencyRow("max latency", a.MaxLatency(), b.MaxLatency())
+
+	fmt.Fprintf(w, "error rate\t%.2f%%\t%.2f%%\t%+.2f pp\n",
+		a.ErrorRate(), b.ErrorRate(), b.ErrorRate()-a.ErrorRate())
+
+	efficiencyRow := func(name string, ea, eb float64, okA, okB bool) {
+		if okA && okB {
+			fmt.Fprintf(w, "%s per %s CPU-s\t%.0f\t%.0f\t%s\n", a.EfficiencyUnit(), name, ea, eb, percentDelta(ea, eb))
+		}
+	}
+	clientA, okA := a.ClientEfficiency()
+	clientB, okB := b.ClientEfficiency()
+	efficiencyRow("client", clientA, clientB, okA, okB)
+	serverA, okA := a.ServerEfficiency()
+	serverB, okB := b.ServerEfficiency()
+	efficiencyRow("server", serverA, serverB, okA, okB)
+
+	_, costA, okA := a.Cost()
+	_, costB, okB := b.Cost()
+	if okA && okB {
+		fmt.Fprintf(w, "cost per 1M requests\t%.4g\t%.4g\t%s\n", costA, costB, percentDelta(costA, costB))
+	}
+	w.Flush()
+}
+
+// percentDelta formats the relative change from a to b, or "-" if a is zero.
+func percentDelta(a, b float64) string {
+	d := db.PctChange(b, a)
+	if d == nil {
+		return "-"
+	}
+	return fmt.Sprintf("%+.1f%%", *d)
+}
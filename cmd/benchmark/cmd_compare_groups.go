@@ -0,0 +1,276 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/kaldun-tech/grpc-rest-benchmark/pkg/bench"
+	"github.com/kaldun-tech/grpc-rest-benchmark/pkg/db"
+)
+
+// compareGroupsOptions holds flags for the compare-groups subcommand.
+type compareGroupsOptions struct {
+	a, b       string // group specs, e.g. "scenario=balance,protocol=grpc"
+	last       int
+	confidence float64
+	resamples  int
+	seed       uint64
+}
+
+// minGroupRuns is the fewest runs a group is compared with; below
+// reliableGroupRuns the intervals are too rough to trust.
+const (
+	minGroupRuns      = 2
+	reliableGroupRuns = 5
+)
+
+// groupKeys are the run fields a group spec can select on.
+var groupKeys = []string{"scenario", "protocol", "client", "suite", "concurrency", "compression", "connection", "cache_bust", "db_target"}
+
+func newCompareGroupsCmd(global *globalOptions) *cobra.Command {
+	opts := &compareGroupsOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "compare-groups",
+		Short: "Compare the latency of two groups of stored runs with bootstrap confidence intervals",
+		Long: `Compare-groups selects two groups of stored runs, by default the last 10 runs
+matching each of -a and -b, and estimates how much group B's median run
+differs from group A's in p50 latency, p99 latency and throughput.
+
+Each group is a comma-separated list of field=value pairs over the fields
+` + strings.Join(groupKeys, ", ") + `; "none" matches a run
+without compression, connection settings, cache busting or database target.
+
+The unit of comparison is the run, so the intervals reflect the variation
+between runs rather than between requests of one run. They are percentile
+bootstrap intervals of the difference of the groups' medians; a difference
+is significant when its interval excludes zero.`,
+		Example: `  benchmark compare-groups -a scenario=balance,protocol=grpc -b scenario=balance,protocol=rest --last 10`,
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := opts.validate(); err != nil {
+				return err
+			}
+			ctx, cancel := signalContext()
+			defer cancel()
+			return runCompareGroups(ctx, global, opts, os.Stdout)
+		},
+	}
+
+	f := cmd.Flags()
+	f.StringVarP(&opts.a, "a", "a", "", "Group A, e.g. scenario=balance,protocol=grpc")
+	f.StringVarP(&opts.b, "b", "b", "", "Group B, compared against group A")
+	f.IntVar(&opts.last, "last", 10, "Compare the most recent N runs of each group")
+	f.Float64Var(&opts.confidence, "confidence", 0.95, "Confidence level of the intervals, between 0 and 1")
+	f.IntVar(&opts.resamples, "resamples", 10000, "Bootstrap resamples per interval")
+	f.Uint64Var(&opts.seed, "seed", 1, "Seed of the bootstrap resampling, for reproducible intervals")
+	cmd.MarkFlagRequired("a")
+	cmd.MarkFlagRequired("b")
+
+	return cmd
+}
+
+// validate checks compare-groups flags for invalid values.
+func (o *compareGroupsOptions) validate() error {
+	if o.last < minGroupRuns {
+		return fmt.Errorf("last must be at least %d", minGroupRuns)
+	}
+	if o.confidence <= 0 || o.confidence >= 1 {
+		return fmt.Errorf("confidence must be between 0 and 1")
+	}
+	if o.resamples < 100 {
+		return fmt.Errorf("resamples must be at least 100")
+	}
+	return nil
+}
+
+// runGroup selects stored runs by their field values.
+type runGroup struct {
+	spec   string
+	filter db.StatsFilter    // fields the results store filters on
+	fields map[string]string // other fields, checked on each run
+}
+
+// parseRunGroup parses a group spec such as
+// "scenario=balance,protocol=grpc,concurrency=50".
+func parseRunGroup(spec string) (*runGroup, error) {
+	g := &runGroup{spec: spec, fields: make(map[string]string)}
+	seen := make(map[string]bool)
+	for pair := range strings.SplitSeq(spec, ",") {
+		key, value, ok := strings.Cut(strings.TrimSpace(pair), "=")
+		if !ok || value == "" {
+			return nil, fmt.Errorf("invalid group %q: want field=value pairs, got %q", spec, pair)
+		}
+		if !slices.Contains(groupKeys, key) {
+			return nil, fmt.Errorf("invalid group %q: unknown field %s (must be one of: %s)", spec, key, strings.Join(groupKeys, ", "))
+		}
+		if seen[key] {
+			return nil, fmt.Errorf("invalid group %q: %s given twice", spec, key)
+		}
+		seen[key] = true
+		switch key {
+		case "scenario":
+			g.filter.Scenario = value
+		case "protocol":
+			g.filter.Protocol = value
+		case "client":
+			g.filter.Client = value
+		case "suite":
+			g.filter.SuiteID = value
+		case "concurrency":
+			if _, err := strconv.Atoi(value); err != nil {
+				return nil, fmt.Errorf("invalid group %q: concurrency %q is not a number", spec, value)
+			}
+			g.fields[key] = value
+		default:
+			g.fields[key] = value
+		}
+	}
+	return g, nil
+}
+
+// matches reports whether a run has the field values the store does not
+// filter on.
+func (g *runGroup) matches(s *db.BenchmarkStats) bool {
+	for key, want := range g.fields {
+		var got string
+		switch key {
+		case "concurrency":
+			got = strconv.Itoa(s.Concurrency)
+		case "compression":
+			got = optionalLabel(s.Compression)
+		case "connection":
+			got = optionalLabel(s.Connection)
+		case "cache_bust":
+			got = optionalLabel(s.CacheBust)
+		case "db_target":
+			got = optionalLabel(s.DBTarget)
+		}
+		if got != want {
+			return false
+		}
+	}
+	return true
+}
+
+// optionalLabel returns the value of an optional run field, "none" if it
+// is unset.
+func optionalLabel(v *string) string {
+	if v == nil {
+		return "none"
+	}
+	return *v
+}
+
+// loadGroupRuns returns the most recent last runs of g with successful
+// samples, newest first.
+func loadGroupRuns(ctx context.Context, results db.ResultsStore, g *runGroup, last int) ([]*db.BenchmarkStats, error) {
+	stats, err := results.GetFilteredStats(ctx, g.filter)
+	if err != nil {
+		return nil, err
+	}
+	var runs []*db.BenchmarkStats
+	for _, s := range stats {
+		if s.Successful > 0 && g.matches(s) {
+			runs = append(runs, s)
+		}
+		if len(runs) == last {
+			break
+		}
+	}
+	if len(runs) < minGroupRuns {
+		return nil, fmt.Errorf("group %q has %d runs with successful samples, at least %d are needed", g.spec, len(runs), minGroupRuns)
+	}
+	return runs, nil
+}
+
+// runCompareGroups compares the runs of groups A and B, writing the
+// comparison to out.
+func runCompareGroups(ctx context.Context, global *globalOptions, opts *compareGroupsOptions, out io.Writer) error {
+	groupA, err := parseRunGroup(opts.a)
+	if err != nil {
+		return err
+	}
+	groupB, err := parseRunGroup(opts.b)
+	if err != nil {
+		return err
+	}
+
+	results, err := global.openResults(ctx)
+	if err != nil {
+		return err
+	}
+	defer results.Close()
+
+	a, err := loadGroupRuns(ctx, results, groupA, opts.last)
+	if err != nil {
+		return err
+	}
+	b, err := loadGroupRuns(ctx, results, groupB, opts.last)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(out, "Group A (%s): %d runs, %s\n", groupA.spec, len(a), formatRunIDs(a))
+	fmt.Fprintf(out, "Group B (%s): %d runs, %s\n", groupB.spec, len(b), formatRunIDs(b))
+	if len(a) < reliableGroupRuns || len(b) < reliableGroupRuns {
+		fmt.Fprintf(out, "Warning: with fewer than %d runs in a group the intervals are rough\n", reliableGroupRuns)
+	}
+	fmt.Fprintln(out)
+	printGroupComparison(out, a, b, opts)
+	return nil
+}
+
+// formatRunIDs lists the IDs of runs, e.g. "runs 12, 15, 19".
+func formatRunIDs(runs []*db.BenchmarkStats) string {
+	ids := make([]string, len(runs))
+	for i, s := range runs {
+		ids[i] = strconv.FormatInt(s.RunID, 10)
+	}
+	return "runs " + strings.Join(ids, ", ")
+}
+
+// printGroupComparison writes a table of the groups' median p50 latency,
+// p99 latency and throughput, with a bootstrap interval of each difference.
+func printGroupComparison(out io.Writer, a, b []*db.BenchmarkStats, opts *compareGroupsOptions) {
+	rng := rand.New(rand.NewPCG(opts.seed, opts.seed))
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "METRIC (MEDIAN RUN)\tA\tB\tB-A\t%g%% CI\tSIGNIFICANT\n", opts.confidence*100)
+	for _, row := range []struct {
+		name  string
+		value func(*db.BenchmarkStats) float64
+	}{
+		{"p50 latency (ms)", func(s *db.BenchmarkStats) float64 { return s.P50Latency }},
+		{"p99 latency (ms)", func(s *db.BenchmarkStats) float64 { return s.P99Latency }},
+		{"throughput (req/s)", (*db.BenchmarkStats).Throughput},
+	} {
+		va, vb := groupValues(a, row.value), groupValues(b, row.value)
+		r := bench.BootstrapMedianDiff(va, vb, opts.resamples, opts.confidence, rng)
+		significant := "no"
+		if r.Significant() {
+			significant = "yes"
+		}
+		medianA, medianB := bench.Median(va), bench.Median(vb)
+		fmt.Fprintf(w, "%s\t%.3f\t%.3f\t%+.3f (%s)\t[%+.3f, %+.3f]\t%s\n",
+			row.name, medianA, medianB, r.Diff, percentDelta(medianA, medianB), r.Low, r.High, significant)
+	}
+	w.Flush()
+}
+
+// groupValues returns the value of each run.
+func groupValues(runs []*db.BenchmarkStats, value func(*db.BenchmarkStats) float64) []float64 {
+	values := make([]float64, len(runs))
+	for i, s := range runs {
+		values[i] = value(s)
+	}
+	return values
+}
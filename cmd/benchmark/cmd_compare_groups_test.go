@@ -0,0 +1,117 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kaldun-tech/grpc-rest-benchmark/pkg/db"
+)
+
+func TestParseRunGroup(t *testing.T) {
+	g, err := parseRunGroup("scenario=balance, protocol=grpc,concurrency=50,compression=none")
+	if err != nil {
+		t.Fatalf("parseRunGroup() error = %v", err)
+	}
+	if g.filter.Scenario != "balance" || g.filter.Protocol != "grpc" {
+		t.Errorf("filter = %+v, want scenario balance and protocol grpc", g.filter)
+	}
+	gzip := "gzip"
+	for _, tt := range []struct {
+		stats *db.BenchmarkStats
+		want  bool
+	}{
+		{&db.BenchmarkStats{Concurrency: 50}, true},
+		{&db.BenchmarkStats{Concurrency: 10}, false},
+		{&db.BenchmarkStats{Concurrency: 50, Compression: &gzip}, false},
+	} {
+		if got := g.matches(tt.stats); got != tt.want {
+			t.Errorf("matches(concurrency %d, compression %s) = %v, want %v", tt.stats.Concurrency, optionalLabel(tt.stats.Compression), got, tt.want)
+		}
+	}
+
+	for _, spec := range []string{"", "scenario", "scenario=", "color=blue", "scenario=a,scenario=b", "concurrency=many"} {
+		if _, err := parseRunGroup(spec); err == nil {
+			t.Errorf("parseRunGroup(%q) error = nil", spec)
+		}
+	}
+}
+
+func TestRunCompareGroups(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "results.db")
+	local, err := db.OpenLocal(ctx, path)
+	if err != nil {
+		t.Fatalf("OpenLocal() error = %v", err)
+	}
+
+	// Runs with latencies 1..100ms scaled by scale, the first run slightly
+	// faster than the next
+	record := func(protocol string, scale float64) {
+		for run := range 6 {
+			id, err := local.RecordRun(ctx, &db.BenchmarkRun{Scenario: "balance", Protocol: protocol, Concurrency: 10, DurationSec: 10})
+			if err != nil {
+				t.Fatalf("RecordRun() error = %v", err)
+			}
+			samples := make([]*db.BenchmarkSample, 100)
+			for i := range samples {
+				latency := float64(i+1) * scale * (1 + float64(run)/100)
+				samples[i] = &db.BenchmarkSample{RunID: id, LatencyMs: latency, Success: true, Timestamp: time.Now()}
+			}
+			if err := local.RecordSamples(ctx, samples); err != nil {
+				t.Fatalf("RecordSamples() error = %v", err)
+			}
+		}
+	}
+	record("grpc", 1)
+	record("rest", 2)
+	record("connect", 1)
+	local.Close()
+
+	global := &globalOptions{resultsBackend: "local:" + path}
+	compare := func(a, b string) (string, error) {
+		var out bytes.Buffer
+		err := runCompareGroups(ctx, global, &compareGroupsOptions{a: a, b: b, last: 5, confidence: 0.95, resamples: 2000, seed: 1}, &out)
+		return out.String(), err
+	}
+
+	out, err := compare("scenario=balance,protocol=grpc", "scenario=balance,protocol=rest")
+	if err != nil {
+		t.Fatalf("runCompareGroups() error = %v", err)
+	}
+	for _, want := range []string{"Group A (scenario=balance,protocol=grpc): 5 runs", "95% CI"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+	for _, metric := range []string{"p50 latency (ms)", "p99 latency (ms)"} {
+		if line := outputLine(out, metric); !strings.HasSuffix(line, "yes") {
+			t.Errorf("%s not significantly different for twice the latency:\n%s", metric, out)
+		}
+	}
+
+	out, err = compare("protocol=grpc", "protocol=connect")
+	if err != nil {
+		t.Fatalf("runCompareGroups() error = %v", err)
+	}
+	if line := outputLine(out, "p99 latency (ms)"); !strings.HasSuffix(line, "no") {
+		t.Errorf("p99 latency significantly different for equal groups:\n%s", out)
+	}
+
+	if _, err := compare("protocol=grpc", "protocol=grpc-web"); err == nil {
+		t.Error("runCompareGroups() with an empty group error = nil")
+	}
+}
+
+// outputLine returns the line of out starting with prefix, trimmed.
+func outputLine(out, prefix string) string {
+	for line := range strings.SplitSeq(out, "\n") {
+		if strings.HasPrefix(line, prefix) {
+			return strings.TrimSpace(line)
+		}
+	}
+	return ""
+}
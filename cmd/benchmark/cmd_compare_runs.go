@@ -0,0 +1,173 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/kaldun-tech/grpc-rest-benchmark/pkg/bench"
+	"github.com/kaldun-tech/grpc-rest-benchmark/pkg/db"
+)
+
+// errRegression is returned by compare-runs when the candidate regressed,
+// so the command exits non-zero.
+var errRegression = errors.New("performance regression")
+
+// compareRunsOptions holds flags for the compare-runs subcommand.
+type compareRunsOptions struct {
+	baseline  int64
+	candidate int64
+	threshold float64 // largest p99 increase in percent that passes
+	alpha     float64 // significance level of the Mann-Whitney U test
+}
+
+func newCompareRunsCmd(global *globalOptions) *cobra.Command {
+	opts := &compareRunsOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "compare-runs",
+		Short: "Check a stored run for a latency regression against a baseline run",
+		Long: `Compare-runs reads two stored runs and tests whether the candidate's latency
+regressed against the baseline's. It diffs their stats and runs a one-sided
+Mann-Whitney U test on the latencies of their successful samples, which asks
+whether the candidate's latencies tend to be larger without assuming any
+distribution.
+
+The command exits non-zero if the candidate's p99 rose by more than
+--threshold percent and the shift is significant at --alpha, so CI can gate
+changes on it. A p99 increase the test does not find significant passes, as
+it is within the noise between runs.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := opts.validate(); err != nil {
+				return err
+			}
+			ctx, cancel := signalContext()
+			defer cancel()
+			return runCompareRuns(ctx, global, opts, os.Stdout)
+		},
+	}
+
+	f := cmd.Flags()
+	f.Int64Var(&opts.baseline, "baseline", 0, "ID of the baseline run")
+	f.Int64Var(&opts.candidate, "candidate", 0, "ID of the run checked for a regression")
+	f.Float64Var(&opts.threshold, "threshold", 5, "Largest p99 latency increase in percent that passes")
+	f.Float64Var(&opts.alpha, "alpha", 0.05, "Significance level: a latency shift counts if its p-value is below this")
+	cmd.MarkFlagRequired("baseline")
+	cmd.MarkFlagRequired("candidate")
+
+	return cmd
+}
+
+// validate checks compare-runs flags for invalid values.
+func (o *compareRunsOptions) validate() error {
+	if o.baseline == o.candidate {
+		return fmt.Errorf("baseline and candidate must be different runs")
+	}
+	if o.threshold < 0 {
+		return fmt.Errorf("threshold must not be negative")
+	}
+	if o.alpha <= 0 || o.alpha >= 1 {
+		return fmt.Errorf("alpha must be between 0 and 1")
+	}
+	return nil
+}
+
+// runCompareRuns compares the stored baseline and candidate runs, writing
+// the comparison to out. It returns an error wrapping errRegression if the
+// candidate regressed.
+func runCompareRuns(ctx context.Context, global *globalOptions, opts *compareRunsOptions, out io.Writer) error {
+	results, err := global.openResults(ctx)
+	if err != nil {
+		return err
+	}
+	defer results.Close()
+
+	base, baseLatencies, err := loadRunLatencies(ctx, results, opts.baseline)
+	if err != nil {
+		return fmt.Errorf("baseline: %w", err)
+	}
+	cand, candLatencies, err := loadRunLatencies(ctx, results, opts.candidate)
+	if err != nil {
+		return fmt.Errorf("candidate: %w", err)
+	}
+
+	fmt.Fprintf(out, "Baseline run %d: %s %s, concurrency %d\n", base.RunID, base.Scenario, base.Protocol, base.Concurrency)
+	fmt.Fprintf(out, "Candidate run %d: %s %s, concurrency %d\n", cand.RunID, cand.Scenario, cand.Protocol, cand.Concurrency)
+	if base.Scenario != cand.Scenario || base.Protocol != cand.Protocol || base.Concurrency != cand.Concurrency {
+		fmt.Fprintln(out, "Warning: the runs differ in scenario, protocol or concurrency")
+	}
+	fmt.Fprintln(out)
+	printRunDiff(out, base, cand, len(baseLatencies), len(candLatencies))
+
+	mw := bench.MannWhitneyU(baseLatencies, candLatencies)
+	fmt.Fprintf(out, "\nMann-Whitney U: U=%.0f z=%.2f p=%.4g (candidate slower in %.1f%% of sample pairs)\n",
+		mw.U, mw.Z, mw.P, mw.Effect*100)
+
+	if err := checkRegression(base, cand, mw, opts); err != nil {
+		fmt.Fprintf(out, "REGRESSION: %v\n", err)
+		return fmt.Errorf("run %d vs baseline %d: %w", cand.RunID, base.RunID, err)
+	}
+	fmt.Fprintf(out, "PASS: p99 %s (threshold +%g%%), p=%.4g (alpha %g)\n",
+		percentDelta(base.P99Latency, cand.P99Latency), opts.threshold, mw.P, opts.alpha)
+	return nil
+}
+
+// loadRunLatencies reads a run's stats and the latencies of its successful
+// samples.
+func loadRunLatencies(ctx context.Context, results db.ResultsStore, runID int64) (*db.BenchmarkStats, []float64, error) {
+	stats, err := results.GetStats(ctx, runID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("run %d: %w", runID, err)
+	}
+	var latencies []float64
+	err = results.ForEachSample(ctx, []int64{runID}, func(s *db.BenchmarkSample) error {
+		if s.Success {
+			latencies = append(latencies, s.LatencyMs)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("run %d: %w", runID, err)
+	}
+	if len(latencies) == 0 {
+		return nil, nil, fmt.Errorf("run %d has no successful samples stored", runID)
+	}
+	return stats, latencies, nil
+}
+
+// printRunDiff writes a table of the stats of two stored runs.
+func printRunDiff(out io.Writer, base, cand *db.BenchmarkStats, baseSamples, candSamples int) {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "METRIC\tBASELINE\tCANDIDATE\tDELTA")
+	fmt.Fprintf(w, "samples compared\t%d\t%d\t\n", baseSamples, candSamples)
+	fmt.Fprintf(w, "throughput (req/s)\t%.2f\t%.2f\t%s\n",
+		base.Throughput(), cand.Throughput(), percentDelta(base.Throughput(), cand.Throughput()))
+	for _, row := range []struct {
+		name       string
+		base, cand float64
+	}{
+		{"p50 latency (ms)", base.P50Latency, cand.P50Latency},
+		{"p90 latency (ms)", base.P90Latency, cand.P90Latency},
+		{"p99 latency (ms)", base.P99Latency, cand.P99Latency},
+	} {
+		fmt.Fprintf(w, "%s\t%.3f\t%.3f\t%s\n", row.name, row.base, row.cand, percentDelta(row.base, row.cand))
+	}
+	w.Flush()
+}
+
+// checkRegression returns an error wrapping errRegression if the candidate's
+// p99 rose beyond the threshold and the latency shift is significant.
+func checkRegression(base, cand *db.BenchmarkStats, mw bench.MannWhitney, opts *compareRunsOptions) error {
+	delta := db.PctChange(cand.P99Latency, base.P99Latency)
+	if delta == nil || *delta <= opts.threshold || mw.P >= opts.alpha {
+		return nil
+	}
+	return fmt.Errorf("%w: p99 %+.1f%% exceeds the +%g%% threshold, significant at p=%.4g (alpha %g)",
+		errRegression, *delta, opts.threshold, mw.P, opts.alpha)
+}
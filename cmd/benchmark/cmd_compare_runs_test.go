@@ -0,0 +1,86 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kaldun-tech/grpc-rest-benchmark/pkg/bench"
+	"github.com/kaldun-tech/grpc-rest-benchmark/pkg/db"
+)
+
+func TestCheckRegression(t *testing.T) {
+	opts := &compareRunsOptions{threshold: 5, alpha: 0.05}
+	base := &db.BenchmarkStats{P99Latency: 10}
+	tests := []struct {
+		name string
+		p99  float64
+		p    float64
+		want bool
+	}{
+		{"significant p99 regression", 12, 0.001, true},
+		{"p99 within threshold", 10.4, 0.001, false},
+		{"not significant", 12, 0.2, false},
+		{"faster", 8, 0.99, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkRegression(base, &db.BenchmarkStats{P99Latency: tt.p99}, bench.MannWhitney{P: tt.p}, opts)
+			if got := errors.Is(err, errRegression); got != tt.want {
+				t.Errorf("checkRegression() = %v, want regression %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestRunCompareRuns(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "results.db")
+	local, err := db.OpenLocal(ctx, path)
+	if err != nil {
+		t.Fatalf("OpenLocal() error = %v", err)
+	}
+
+	// Latencies 1..200ms, shifted by shift ms
+	record := func(shift float64) int64 {
+		id, err := local.RecordRun(ctx, &db.BenchmarkRun{Scenario: "balance", Protocol: "grpc", Concurrency: 10, DurationSec: 10})
+		if err != nil {
+			t.Fatalf("RecordRun() error = %v", err)
+		}
+		samples := make([]*db.BenchmarkSample, 200)
+		for i := range samples {
+			samples[i] = &db.BenchmarkSample{RunID: id, LatencyMs: float64(i+1) + shift, Success: true, Timestamp: time.Now()}
+		}
+		if err := local.RecordSamples(ctx, samples); err != nil {
+			t.Fatalf("RecordSamples() error = %v", err)
+		}
+		return id
+	}
+	base, same, slower := record(0), record(0.5), record(60)
+	local.Close()
+
+	global := &globalOptions{resultsBackend: "local:" + path}
+	var out bytes.Buffer
+	if err := runCompareRuns(ctx, global, &compareRunsOptions{baseline: base, candidate: same, threshold: 5, alpha: 0.05}, &out); err != nil {
+		t.Errorf("runCompareRuns() on equal runs error = %v\n%s", err, out.String())
+	}
+	for _, want := range []string{"p99 latency (ms)", "Mann-Whitney U", "PASS"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("output missing %q:\n%s", want, out.String())
+		}
+	}
+
+	out.Reset()
+	err = runCompareRuns(ctx, global, &compareRunsOptions{baseline: base, candidate: slower, threshold: 5, alpha: 0.05}, &out)
+	if !errors.Is(err, errRegression) {
+		t.Errorf("runCompareRuns() on slower run error = %v, want a regression\n%s", err, out.String())
+	}
+
+	if err := runCompareRuns(ctx, global, &compareRunsOptions{baseline: base, candidate: 999, threshold: 5, alpha: 0.05}, &out); err == nil {
+		t.Error("runCompareRuns() with a missing run returned no error")
+	}
+}
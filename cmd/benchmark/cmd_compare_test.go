@@ -0,0 +1,100 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kaldun-tech/grpc-rest-benchmark/pkg/bench"
+)
+
+func TestPercentDelta(t *testing.T) {
+	tests := []struct {
+		a, b float64
+		want string
+	}{
+		{100, 110, "+10.0%"},
+		{100, 75, "-25.0%"},
+		{100, 100, "+0.0%"},
+		{0, 50, "-"},
+	}
+
+	for _, tt := range tests {
+		if got := percentDelta(tt.a, tt.b); got != tt.want {
+			t.Errorf("percentDelta(%v, %v) = %q, want %q", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestPrintComparison(t *testing.T) {
+	start := time.Now()
+	newRun := func(latency time.Duration, count int) *bench.Results {
+		r := bench.NewResults()
+		r.SetStartTime(start)
+		r.SetEndTime(start.Add(time.Second))
+		for i := 0; i < count; i++ {
+			r.Add(bench.Sample{Latency: latency, Success: true})
+		}
+		return r
+	}
+
+	var buf bytes.Buffer
+	printComparison(&buf, "grpc", "rest", newRun(time.Millisecond, 100), newRun(2*time.Millisecond, 80))
+	out := buf.String()
+
+	for _, want := range []string{"GRPC", "REST", "throughput (req/s)", "-20.0%", "p99 latency", "+100.0%", "error rate"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("comparison output missing %q:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "cost") {
+		t.Errorf("comparison of unpriced runs shows a cost:\n%s", out)
+	}
+
+	// 1 and 1.2 CPU-hours for 100 and 80 requests: 10000 and 15000 per 1M
+	a, b := newRun(time.Millisecond, 100), newRun(2*time.Millisecond, 80)
+	for r, cpu := range map[*bench.Results]float64{a: 3600, b: 4320} {
+		r.SetResourceStats(bench.ResourceStats{CPUSeconds: cpu})
+		r.SetCostModel(bench.CostModel{CPUHour: 1})
+	}
+	buf.Reset()
+	printComparison(&buf, "grpc", "rest", a, b)
+	if out := buf.String(); !strings.Contains(out, "cost per 1M requests") || !strings.Contains(out, "+50.0%") {
+		t.Errorf("comparison output missing the cost row:\n%s", out)
+	}
+}
+
+func TestCompareOptions_Validate(t *testing.T) {
+	base := runOptions{
+		scenario:        "balance",
+		concurrency:     1,
+		duration:        time.Second,
+		streamMetric:    bench.StreamMetricInterArrival,
+		connectEncoding: "proto",
+		restEncoding:    "json",
+		restShape:       "idiomatic",
+		jsonEncoder:     "std",
+		compression:     "none",
+	}
+
+	tests := []struct {
+		name      string
+		protocols []string
+		wantErr   bool
+	}{
+		{"two protocols", []string{"grpc", "rest"}, false},
+		{"one protocol", []string{"grpc"}, true},
+		{"duplicate", []string{"rest", "rest"}, true},
+		{"unknown", []string{"grpc", "soap"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := &compareOptions{run: base, protocols: tt.protocols}
+			if err := opts.validate(); (err != nil) != tt.wantErr {
+				t.Errorf("validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
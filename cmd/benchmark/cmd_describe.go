@@ -0,0 +1,160 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+
+	"github.com/kaldun-tech/grpc-rest-benchmark/pkg/bench"
+	"github.com/kaldun-tech/grpc-rest-benchmark/pkg/jsoncodec"
+	"github.com/kaldun-tech/grpc-rest-benchmark/pkg/suite"
+	"github.com/kaldun-tech/grpc-rest-benchmark/pkg/workload"
+)
+
+// description is what 'benchmark describe --json' prints: everything a
+// run can select, built from the command's own flags so it cannot drift
+// from them.
+type description struct {
+	Scenarios        []string `json:"scenarios"`
+	Protocols        []string `json:"protocols"`
+	StreamMetrics    []string `json:"stream_metrics"`
+	ConnectEncodings []string `json:"connect_encodings"`
+	RESTEncodings    []string `json:"rest_encodings"`
+	JSONEncoders     []string `json:"json_encoders"`
+
+	GlobalFlags []flagDescription `json:"global_flags"` // shared by every command
+	RunFlags    []flagDescription `json:"run_flags"`
+
+	Suite    fileDescription `json:"suite"`    // run --config
+	Workload fileDescription `json:"workload"` // run --workload
+}
+
+// flagDescription describes one command-line flag.
+type flagDescription struct {
+	Name      string   `json:"name"`
+	Shorthand string   `json:"shorthand,omitempty"`
+	Type      string   `json:"type"`
+	Default   string   `json:"default"`
+	Usage     string   `json:"usage"`
+	Values    []string `json:"values,omitempty"` // the accepted values, when fixed
+	Required  bool     `json:"required,omitempty"`
+}
+
+// fileDescription describes a file format that replaces some run flags.
+type fileDescription struct {
+	Version  int      `json:"version"`
+	Replaces []string `json:"replaces"` // run flags the file sets
+}
+
+func newDescribeCmd() *cobra.Command {
+	var asJSON bool
+
+	cmd := &cobra.Command{
+		Use:   "describe",
+		Short: "Describe the scenarios, protocols and run options, optionally as JSON",
+		Long: `Describe lists the scenarios, protocols and encodings a run can select and
+every run flag with its type, default and accepted values, along with the
+schema versions of suite and workload files and the flags they replace.
+
+With --json the description is machine-readable, for tools that build run
+forms or check suite files without hard-coding the client's options.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			d, err := describe(cmd.Root())
+			if err != nil {
+				return err
+			}
+			if asJSON {
+				enc := json.NewEncoder(os.Stdout)
+				enc.SetIndent("", "  ")
+				return enc.Encode(d)
+			}
+			return printDescription(os.Stdout, d)
+		},
+	}
+
+	cmd.Flags().BoolVar(&asJSON, "json", false, "Print the description as JSON")
+
+	return cmd
+}
+
+// describe builds the description of the run command of root.
+func describe(root *cobra.Command) (*description, error) {
+	run, _, err := root.Find([]string{"run"})
+	if err != nil || run == root {
+		return nil, fmt.Errorf("run command not found")
+	}
+
+	return &description{
+		Scenarios:        bench.Scenarios,
+		Protocols:        bench.Protocols,
+		StreamMetrics:    bench.StreamMetrics,
+		ConnectEncodings: bench.ConnectEncodings,
+		RESTEncodings:    bench.RESTEncodings,
+		JSONEncoders:     jsoncodec.Names,
+		GlobalFlags:      describeFlags(root, root.PersistentFlags()),
+		RunFlags:         describeFlags(run, run.LocalNonPersistentFlags()),
+		Suite:            fileDescription{Version: suite.Version, Replaces: suiteFlags},
+		Workload:         fileDescription{Version: workload.Version, Replaces: workloadFlags},
+	}, nil
+}
+
+// describeFlags describes the visible flags of fs, sorted by name, taking
+// their accepted values from cmd's completions.
+func describeFlags(cmd *cobra.Command, fs *pflag.FlagSet) []flagDescription {
+	var flags []flagDescription
+	fs.VisitAll(func(f *pflag.Flag) {
+		if f.Hidden || f.Deprecated != "" || f.Name == "help" {
+			return
+		}
+		d := flagDescription{
+			Name:      f.Name,
+			Shorthand: f.Shorthand,
+			Type:      f.Value.Type(),
+			Default:   f.DefValue,
+			Usage:     f.Usage,
+			Required:  len(f.Annotations[cobra.BashCompOneRequiredFlag]) > 0,
+		}
+		if complete, ok := cmd.GetFlagCompletionFunc(f.Name); ok {
+			d.Values, _ = complete(cmd, nil, "")
+		}
+		flags = append(flags, d)
+	})
+	slices.SortFunc(flags, func(a, b flagDescription) int { return strings.Compare(a.Name, b.Name) })
+	return flags
+}
+
+// printDescription prints d for people.
+func printDescription(w io.Writer, d *description) error {
+	fmt.Fprintf(w, "Scenarios:         %s\n", strings.Join(d.Scenarios, ", "))
+	fmt.Fprintf(w, "Protocols:         %s\n", strings.Join(d.Protocols, ", "))
+	fmt.Fprintf(w, "Stream metrics:    %s\n", strings.Join(d.StreamMetrics, ", "))
+	fmt.Fprintf(w, "Connect encodings: %s\n", strings.Join(d.ConnectEncodings, ", "))
+	fmt.Fprintf(w, "REST encodings:    %s\n", strings.Join(d.RESTEncodings, ", "))
+	fmt.Fprintf(w, "JSON encoders:     %s\n", strings.Join(d.JSONEncoders, ", "))
+	fmt.Fprintf(w, "Suite files:       version %d, replacing --%s\n", d.Suite.Version, strings.Join(d.Suite.Replaces, ", --"))
+	fmt.Fprintf(w, "Workload files:    version %d, replacing --%s\n", d.Workload.Version, strings.Join(d.Workload.Replaces, ", --"))
+
+	for _, section := range []struct {
+		title string
+		flags []flagDescription
+	}{{"Global flags", d.GlobalFlags}, {"Run flags", d.RunFlags}} {
+		fmt.Fprintf(w, "\n%s:\n", section.title)
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "  FLAG\tTYPE\tDEFAULT\tVALUES")
+		for _, f := range section.flags {
+			fmt.Fprintf(tw, "  --%s\t%s\t%s\t%s\n", f.Name, f.Type, f.Default, strings.Join(f.Values, " | "))
+		}
+		if err := tw.Flush(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/kaldun-tech/grpc-rest-benchmark/pkg/bench"
+)
+
+func TestDescribe(t *testing.T) {
+	d, err := describe(newRootCmd())
+	if err != nil {
+		t.Fatalf("describe() error = %v", err)
+	}
+
+	find := func(flags []flagDescription, name string) *flagDescription {
+		for i := range flags {
+			if flags[i].Name == name {
+				return &flags[i]
+			}
+		}
+		t.Fatalf("flag --%s not described", name)
+		return nil
+	}
+
+	protocol := find(d.RunFlags, "protocol")
+	if protocol.Type != "string" || protocol.Default != "grpc" || !slices.Equal(protocol.Values, bench.Protocols) {
+		t.Errorf("--protocol = %+v, want a string defaulting to grpc with values %v", protocol, bench.Protocols)
+	}
+	if duration := find(d.RunFlags, "duration"); duration.Type != "duration" || duration.Default != "30s" {
+		t.Errorf("--duration = %+v, want a duration defaulting to 30s", duration)
+	}
+	find(d.GlobalFlags, "grpc-addr")
+	for _, f := range d.RunFlags {
+		if f.Name == "grpc-addr" || f.Name == "help" {
+			t.Errorf("run flags include --%s", f.Name)
+		}
+	}
+	if !slices.IsSortedFunc(d.RunFlags, func(a, b flagDescription) int { return strings.Compare(a.Name, b.Name) }) {
+		t.Error("run flags are not sorted by name")
+	}
+
+	// Files may only replace flags that exist
+	for _, name := range append(slices.Clone(d.Suite.Replaces), d.Workload.Replaces...) {
+		find(d.RunFlags, name)
+	}
+}
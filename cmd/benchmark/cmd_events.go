@@ -0,0 +1,121 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/kaldun-tech/grpc-rest-benchmark/pkg/db"
+)
+
+func newEventsCmd(global *globalOptions) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "events",
+		Short: "Record and list operational events shown on the dashboard trends",
+		Long: `Events mark operational changes, such as a server deploy, a configuration
+change or a dataset reseed, in the PostgreSQL database named by the --db-*
+flags. The dashboard draws them over the trend chart, so a shift in the
+benchmark history can be matched to its cause. The seed tool records a
+reseed event itself.`,
+	}
+	cmd.AddCommand(newEventsAddCmd(global), newEventsListCmd(global))
+	return cmd
+}
+
+func newEventsAddCmd(global *globalOptions) *cobra.Command {
+	var kind, message, at string
+
+	cmd := &cobra.Command{
+		Use:   "add",
+		Short: "Record an operational event",
+		Example: `  benchmark events add --kind deploy --message "rest-server v1.4 (pgx 5.7)"
+  benchmark events add --kind config --message "shared_buffers 4GB" --at 2026-03-02T10:00:00Z`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			e := &db.Event{Kind: kind, Message: message}
+			if at != "" {
+				t, err := time.Parse(time.RFC3339, at)
+				if err != nil {
+					return fmt.Errorf("invalid --at %q: must be RFC 3339, e.g. 2026-03-02T10:00:00Z", at)
+				}
+				e.OccurredAt = t.UTC()
+			}
+			if err := e.Validate(); err != nil {
+				return err
+			}
+
+			ctx, cancel := signalContext()
+			defer cancel()
+
+			database, err := global.connectDB(ctx)
+			if err != nil {
+				return fmt.Errorf("failed to connect to database: %w", err)
+			}
+			defer database.Close()
+
+			if err := database.RecordEvent(ctx, e); err != nil {
+				return err
+			}
+			fmt.Printf("Recorded %s event %d at %s\n", e.Kind, e.ID, e.OccurredAt.Format(time.RFC3339))
+			return nil
+		},
+	}
+
+	f := cmd.Flags()
+	f.StringVar(&kind, "kind", db.EventNote, "Event kind: "+strings.Join(db.EventKinds, " | "))
+	f.StringVar(&message, "message", "", "What changed")
+	f.StringVar(&at, "at", "", "When it changed, RFC 3339 (default now)")
+	cmd.MarkFlagRequired("message")
+
+	return cmd
+}
+
+func newEventsListCmd(global *globalOptions) *cobra.Command {
+	var (
+		kind   string
+		window time.Duration
+		limit  int
+	)
+
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List recorded operational events, oldest first",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx, cancel := signalContext()
+			defer cancel()
+
+			database, err := global.connectDB(ctx)
+			if err != nil {
+				return fmt.Errorf("failed to connect to database: %w", err)
+			}
+			defer database.Close()
+
+			events, err := database.ListEvents(ctx, db.EventFilter{Kind: kind, Window: window, Limit: limit})
+			if err != nil {
+				return err
+			}
+			if len(events) == 0 {
+				fmt.Println("No events recorded")
+				return nil
+			}
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "ID\tOCCURRED AT\tKIND\tMESSAGE")
+			for _, e := range events {
+				fmt.Fprintf(w, "%d\t%s\t%s\t%s\n", e.ID, e.OccurredAt.Format(time.RFC3339), e.Kind, e.Message)
+			}
+			return w.Flush()
+		},
+	}
+
+	f := cmd.Flags()
+	f.StringVar(&kind, "kind", "", "Only events of this kind")
+	f.DurationVar(&window, "window", 90*24*time.Hour, "How far back to list (0 = all)")
+	f.IntVar(&limit, "limit", 50, "List at most the newest N events (0 = all)")
+
+	return cmd
+}
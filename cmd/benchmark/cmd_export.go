@@ -0,0 +1,143 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/kaldun-tech/grpc-rest-benchmark/pkg/bench"
+	"github.com/kaldun-tech/grpc-rest-benchmark/pkg/db"
+	"github.com/kaldun-tech/grpc-rest-benchmark/pkg/export"
+)
+
+// validExportFormats are the values accepted by --format.
+var validExportFormats = []string{"parquet"}
+
+// exportOptions holds flags for the export subcommand.
+type exportOptions struct {
+	format string
+	outDir string
+
+	runIDs       []int64
+	scenario     string
+	protocol     string
+	comparisonID string
+	suiteID      string
+	limit        int
+}
+
+func newExportCmd(global *globalOptions) *cobra.Command {
+	opts := &exportOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "export",
+		Short: "Export stored runs and samples for analysis in DuckDB, Spark or pandas",
+		Long: `Export writes the selected runs to runs.parquet and their raw samples to
+samples.parquet in the output directory. Both files carry run_id for joining.
+Without selection flags every stored run is exported.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if !slices.Contains(validExportFormats, opts.format) {
+				return fmt.Errorf("invalid format: %s (must be one of: %s)", opts.format, strings.Join(validExportFormats, ", "))
+			}
+			ctx, cancel := signalContext()
+			defer cancel()
+			return runExport(ctx, global, opts)
+		},
+	}
+
+	f := cmd.Flags()
+	f.StringVar(&opts.format, "format", "parquet", "Output format: "+strings.Join(validExportFormats, " | "))
+	f.StringVar(&opts.outDir, "out", "export", "Output directory")
+	f.Int64SliceVar(&opts.runIDs, "runs", nil, "Run IDs to export (e.g., 12,13,14)")
+	f.StringVar(&opts.scenario, "scenario", "", "Only export runs of this scenario")
+	f.StringVar(&opts.protocol, "protocol", "", "Only export runs of this protocol")
+	f.StringVar(&opts.comparisonID, "comparison-id", "", "Only export runs from this comparison")
+	f.StringVar(&opts.suiteID, "suite-id", "", "Only export runs from this suite (run --config)")
+	f.IntVar(&opts.limit, "limit", 0, "Export at most this many of the newest matching runs (0 = all)")
+
+	cmd.RegisterFlagCompletionFunc("format", fixedCompletion(validExportFormats))
+	cmd.RegisterFlagCompletionFunc("scenario", fixedCompletion(bench.Scenarios))
+	cmd.RegisterFlagCompletionFunc("protocol", fixedCompletion(bench.Protocols))
+	cmd.MarkFlagDirname("out")
+
+	return cmd
+}
+
+// runExport writes the selected runs and their samples to the output
+// directory. Samples are streamed from the database to the file.
+func runExport(ctx context.Context, global *globalOptions, opts *exportOptions) error {
+	database, err := global.openResults(ctx)
+	if err != nil {
+		return err
+	}
+	defer database.Close()
+
+	runs, err := database.GetRuns(ctx, db.StatsFilter{
+		RunIDs:       opts.runIDs,
+		Scenario:     opts.scenario,
+		Protocol:     opts.protocol,
+		ComparisonID: opts.comparisonID,
+		SuiteID:      opts.suiteID,
+		Limit:        opts.limit,
+	})
+	if err != nil {
+		return err
+	}
+	if len(runs) == 0 {
+		return fmt.Errorf("no runs match the selection")
+	}
+
+	if err := os.MkdirAll(opts.outDir, 0o755); err != nil {
+		return err
+	}
+
+	runsPath := filepath.Join(opts.outDir, "runs.parquet")
+	if err := writeFile(runsPath, func(f *os.File) error {
+		return export.WriteRuns(f, runs)
+	}); err != nil {
+		return fmt.Errorf("failed to write %s: %w", runsPath, err)
+	}
+	fmt.Printf("Wrote %d run(s) to %s\n", len(runs), runsPath)
+
+	ids := make([]int64, len(runs))
+	for i, r := range runs {
+		ids[i] = r.ID
+	}
+
+	samplesPath := filepath.Join(opts.outDir, "samples.parquet")
+	var count int
+	if err := writeFile(samplesPath, func(f *os.File) error {
+		sw := export.NewSampleWriter(f)
+		if err := database.ForEachSample(ctx, ids, sw.Write); err != nil {
+			return err
+		}
+		count = sw.Count()
+		return sw.Close()
+	}); err != nil {
+		return fmt.Errorf("failed to write %s: %w", samplesPath, err)
+	}
+	fmt.Printf("Wrote %d sample(s) to %s\n", count, samplesPath)
+
+	return nil
+}
+
+// writeFile creates path and fills it with write, removing the file again if
+// write fails so no truncated output is left behind.
+func writeFile(path string, write func(*os.File) error) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := write(f); err != nil {
+		f.Close()
+		os.Remove(path)
+		return err
+	}
+	return f.Close()
+}
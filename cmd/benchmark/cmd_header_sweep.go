@@ -0,0 +1,219 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/kaldun-tech/grpc-rest-benchmark/pkg/bench"
+)
+
+// paddingHeader is the header or metadata entry the header sweep pads
+// requests with.
+const paddingHeader = "x-sweep-padding"
+
+// headerSweepOptions holds flags for the header-sweep subcommand.
+type headerSweepOptions struct {
+	run       runOptions
+	protocols []string
+	sizes     []int
+	pause     time.Duration
+}
+
+func newHeaderSweepCmd(global *globalOptions) *cobra.Command {
+	opts := &headerSweepOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "header-sweep",
+		Short: "Run each protocol with growing request headers and report the wire bytes they cost",
+		Long: `Runs the benchmark once per protocol and header size, padding every request
+with an x-sweep-padding header (REST, GraphQL, JSON-RPC) or metadata entry
+(the others) of the given size, and reports the bytes each header byte adds on the wire.
+HTTP/1.1 sends headers in full with every request, so REST pays about one
+byte per header byte; HTTP/2 HPACK indexes a repeated header after its first
+request on a connection, so gRPC and Connect pay close to nothing.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx, cancel := signalContext()
+			defer cancel()
+			if err := resolveEnvironment(ctx, cmd, global, &opts.run); err != nil {
+				return err
+			}
+			if err := opts.validate(); err != nil {
+				return err
+			}
+			return runHeaderSweep(ctx, global, opts)
+		},
+	}
+
+	f := cmd.Flags()
+	f.StringSliceVar(&opts.protocols, "protocols", []string{"grpc", "rest"}, "Protocols to sweep")
+	f.IntSliceVar(&opts.sizes, "sizes", []int{0, 256, 1024, 4096, 16384}, "Header value sizes in bytes; the smallest is the baseline")
+	f.DurationVar(&opts.pause, "pause", 5*time.Second, "Pause between runs to let the servers settle")
+	cmd.RegisterFlagCompletionFunc("protocols", fixedCompletion(bench.Protocols))
+	addRunFlags(cmd, &opts.run)
+
+	return cmd
+}
+
+// validate checks header-sweep flags for invalid values.
+func (o *headerSweepOptions) validate() error {
+	if len(o.protocols) == 0 {
+		return fmt.Errorf("header-sweep needs at least one protocol")
+	}
+	if len(o.sizes) < 2 {
+		return fmt.Errorf("header-sweep needs at least two sizes, got %d", len(o.sizes))
+	}
+	for i, size := range o.sizes {
+		if size < 0 {
+			return fmt.Errorf("header size must not be negative, got %d", size)
+		}
+		if slices.Contains(o.sizes[:i], size) {
+			return fmt.Errorf("header size %d given twice", size)
+		}
+	}
+	for i, protocol := range o.protocols {
+		if slices.Contains(o.protocols[:i], protocol) {
+			return fmt.Errorf("protocol %s given twice", protocol)
+		}
+	}
+	if o.pause < 0 {
+		return fmt.Errorf("pause must not be negative")
+	}
+	if len(o.run.workers) > 0 {
+		return fmt.Errorf("header-sweep counts the bytes of its own client and cannot use workers")
+	}
+	for _, protocol := range o.protocols {
+		if err := o.forRun(protocol, o.sizes[0]).validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// forRun returns a copy of the shared run options targeting protocol, with
+// requests padded by a header or metadata value of size bytes.
+func (o *headerSweepOptions) forRun(protocol string, size int) *runOptions {
+	run := o.run
+	run.protocol = protocol
+	if size == 0 {
+		return &run
+	}
+	pair := paddingHeader + "=" + paddingValue(size)
+	if sendsHeaders(protocol) {
+		run.headers = append(slices.Clip(run.headers), pair)
+	} else {
+		run.metadata = append(slices.Clip(run.metadata), pair)
+	}
+	return &run
+}
+
+// paddingValue returns size bytes of base64 characters, which like a token
+// or cookie do not compress much.
+func paddingValue(size int) string {
+	const alphabet = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789+/"
+	b := make([]byte, size)
+	for i := range b {
+		b[i] = alphabet[(i*37+i/64)%len(alphabet)]
+	}
+	return string(b)
+}
+
+// sweepPoint is one run of the header sweep.
+type sweepPoint struct {
+	protocol    string // label recorded with the run
+	headerBytes int    // injected header bytes per request
+	results     *bench.Results
+}
+
+// runHeaderSweep runs the benchmark for every protocol and header size,
+// tags the runs with a shared comparison ID and prints the wire overhead.
+func runHeaderSweep(ctx context.Context, global *globalOptions, opts *headerSweepOptions) error {
+	env, err := prepareRun(ctx, global, &opts.run, opts.run.needsAccounts())
+	if err != nil {
+		return err
+	}
+	defer env.Close()
+
+	comparisonID := fmt.Sprintf("hdr-%s", time.Now().Format("20060102-150405"))
+	env.comparisonID = &comparisonID
+
+	sizes := slices.Sorted(slices.Values(opts.sizes))
+	var points []sweepPoint
+	for _, protocol := range opts.protocols {
+		for _, size := range sizes {
+			if len(points) > 0 && opts.pause > 0 {
+				fmt.Printf("Pausing %s before the next run...\n", opts.pause)
+				select {
+				case <-time.After(opts.pause):
+				case <-ctx.Done():
+					return ctx.Err()
+				}
+			}
+
+			run := opts.forRun(protocol, size)
+			r, _, err := executeRun(ctx, global, run, env)
+			if err != nil {
+				return fmt.Errorf("%s run with %d header bytes failed: %w", protocol, size, err)
+			}
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			points = append(points, sweepPoint{protocol: run.protocolLabel(), headerBytes: run.injectedHeaderBytes(), results: r})
+		}
+	}
+
+	fmt.Printf("\nHeader sweep %s (%s scenario, concurrency %d)\n\n", comparisonID, opts.run.scenario, opts.run.concurrency)
+	printHeaderSweep(os.Stdout, points)
+	return nil
+}
+
+// printHeaderSweep writes the wire bytes each run sent per request and the
+// overhead over the protocol's first, smallest run. Bytes per header byte
+// near 1 mean headers are sent in full with every request (HTTP/1.1); near
+// 0, that header compression sends them once per connection (HPACK).
+func printHeaderSweep(out io.Writer, points []sweepPoint) {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "PROTOCOL\tHEADER BYTES\tWIRE SENT/REQ\tOVERHEAD/REQ\tPER HEADER BYTE\tP50 LATENCY")
+
+	var base *sweepPoint
+	var baseSent float64
+	var baseOK bool
+	for i := range points {
+		p := &points[i]
+		sent, ok := sentPerRequest(p.results)
+		if base == nil || base.protocol != p.protocol {
+			base, baseSent, baseOK = p, sent, ok
+		}
+		if !ok {
+			fmt.Fprintf(w, "%s\t%d\t-\t-\t-\t%s\n", p.protocol, p.headerBytes, bench.FormatLatency(p.results.Percentile(50)))
+			continue
+		}
+
+		overhead, perByte := "-", "-"
+		if p != base && baseOK && p.headerBytes > base.headerBytes {
+			delta := sent - baseSent
+			overhead = fmt.Sprintf("%+.0f B", delta)
+			perByte = fmt.Sprintf("%.2f", delta/float64(p.headerBytes-base.headerBytes))
+		}
+		fmt.Fprintf(w, "%s\t%d\t%.0f B\t%s\t%s\t%s\n", p.protocol, p.headerBytes, sent, overhead, perByte,
+			bench.FormatLatency(p.results.Percentile(50)))
+	}
+	w.Flush()
+}
+
+// sentPerRequest returns the wire bytes the client sent per request, and
+// false if they were not counted.
+func sentPerRequest(r *bench.Results) (float64, bool) {
+	t, ok := r.Transfer()
+	if !ok || r.TotalRequests() == 0 {
+		return 0, false
+	}
+	return float64(t.WireSent) / float64(r.TotalRequests()), true
+}
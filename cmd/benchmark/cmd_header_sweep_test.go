@@ -0,0 +1,98 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kaldun-tech/grpc-rest-benchmark/pkg/bench"
+)
+
+func TestHeaderSweepOptions_ForRun(t *testing.T) {
+	opts := &headerSweepOptions{run: runOptions{headers: []string{"X-Base=1"}, metadata: []string{"base=1"}}}
+
+	rest := opts.forRun("rest", 100)
+	if len(rest.headers) != 2 || len(rest.metadata) != 1 {
+		t.Fatalf("rest run headers = %d, metadata = %d, want the padding header only", len(rest.headers), len(rest.metadata))
+	}
+	if got, want := rest.injectedHeaderBytes(), len("X-Base")+1+len("X-Sweep-Padding")+100; got != want {
+		t.Errorf("rest injected bytes = %d, want %d", got, want)
+	}
+	grpc := opts.forRun("grpc", 100)
+	if len(grpc.metadata) != 2 || len(grpc.headers) != 1 {
+		t.Errorf("grpc run headers = %d, metadata = %d, want the padding metadata only", len(grpc.headers), len(grpc.metadata))
+	}
+	if len(opts.run.headers) != 1 || len(opts.run.metadata) != 1 {
+		t.Errorf("forRun modified the shared options")
+	}
+	if none := opts.forRun("grpc", 0); len(none.metadata) != 1 {
+		t.Errorf("size 0 added %d metadata entries", len(none.metadata)-1)
+	}
+}
+
+func TestHeaderSweepOptions_Validate(t *testing.T) {
+	base := runOptions{
+		scenario:        "balance",
+		concurrency:     1,
+		duration:        time.Second,
+		streamMetric:    bench.StreamMetricInterArrival,
+		connectEncoding: "proto",
+		restEncoding:    "json",
+		restShape:       "idiomatic",
+		jsonEncoder:     "std",
+		compression:     "none",
+	}
+
+	tests := []struct {
+		name      string
+		protocols []string
+		sizes     []int
+		workers   []string
+		wantErr   bool
+	}{
+		{"valid", []string{"grpc", "rest"}, []int{0, 1024}, nil, false},
+		{"one size", []string{"grpc"}, []int{1024}, nil, true},
+		{"negative size", []string{"grpc"}, []int{0, -1}, nil, true},
+		{"duplicate size", []string{"grpc"}, []int{64, 64}, nil, true},
+		{"duplicate protocol", []string{"rest", "rest"}, []int{0, 64}, nil, true},
+		{"unknown protocol", []string{"soap"}, []int{0, 64}, nil, true},
+		{"workers", []string{"grpc"}, []int{0, 64}, []string{"host:9090"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := &headerSweepOptions{run: base, protocols: tt.protocols, sizes: tt.sizes}
+			opts.run.workers = tt.workers
+			if err := opts.validate(); (err != nil) != tt.wantErr {
+				t.Errorf("validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestPrintHeaderSweep(t *testing.T) {
+	newRun := func(wireSent uint64) *bench.Results {
+		r := bench.NewResults()
+		for range 10 {
+			r.Add(bench.Sample{Latency: time.Millisecond, Success: true})
+		}
+		r.SetTransfer(bench.Transfer{WireSent: wireSent})
+		return r
+	}
+
+	var buf bytes.Buffer
+	printHeaderSweep(&buf, []sweepPoint{
+		{"grpc", 0, newRun(1000)},
+		{"grpc", 1000, newRun(1500)},
+		{"rest", 0, newRun(2000)},
+		{"rest", 1000, newRun(12000)},
+	})
+	out := buf.String()
+
+	// gRPC sends 50 more bytes per request for 1000 header bytes, REST 1000
+	for _, want := range []string{"PER HEADER BYTE", "+50 B", "0.05", "+1000 B", "1.00"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("sweep output missing %q:\n%s", want, out)
+		}
+	}
+}
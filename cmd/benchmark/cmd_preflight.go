@@ -0,0 +1,158 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health/grpc_health_v1"
+)
+
+func newPreflightCmd(global *globalOptions) *cobra.Command {
+	var timeout time.Duration
+
+	cmd := &cobra.Command{
+		Use:   "preflight",
+		Short: "Check that the database and servers are ready for benchmarking",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx, cancel := signalContext()
+			defer cancel()
+			return runPreflight(ctx, global, timeout)
+		},
+	}
+
+	cmd.Flags().DurationVar(&timeout, "timeout", 5*time.Second, "Timeout for each check")
+
+	return cmd
+}
+
+// runPreflight checks database contents and server health, printing one line
+// per check. Returns an error if any check failed.
+func runPreflight(ctx context.Context, global *globalOptions, timeout time.Duration) error {
+	failed := 0
+	report := func(name string, err error, detail string) {
+		if err != nil {
+			failed++
+			fmt.Printf("  [FAIL] %-10s %v\n", name, err)
+			return
+		}
+		fmt.Printf("  [ OK ] %-10s %s\n", name, detail)
+	}
+
+	fmt.Println("Preflight checks:")
+
+	// Database connectivity and seed data
+	dbCtx, dbCancel := context.WithTimeout(ctx, timeout)
+	database, err := global.connectDB(dbCtx)
+	if err != nil {
+		report("database", err, "")
+	} else {
+		accounts, accErr := database.GetAccountCount(dbCtx)
+		txs, txErr := database.GetTransactionCount(dbCtx)
+		switch {
+		case accErr != nil:
+			report("database", accErr, "")
+		case txErr != nil:
+			report("database", txErr, "")
+		case accounts == 0:
+			report("database", fmt.Errorf("no accounts found, run 'make seed' first"), "")
+		default:
+			report("database", nil, fmt.Sprintf("%d accounts, %d transactions", accounts, txs))
+		}
+		database.Close()
+	}
+	dbCancel()
+
+	// gRPC server health
+	report("grpc", checkGRPCHealth(ctx, global, timeout), global.grpcAddr)
+
+	// REST server health
+	report("rest", checkRESTHealth(ctx, global, timeout), global.restAddr)
+
+	if failed > 0 {
+		return fmt.Errorf("%d preflight check(s) failed", failed)
+	}
+	return nil
+}
+
+// checkGRPCHealth queries the standard gRPC health service of the gRPC
+// server.
+func checkGRPCHealth(ctx context.Context, global *globalOptions, timeout time.Duration) error {
+	creds, err := global.grpcCredentials()
+	if err != nil {
+		return err
+	}
+	conn, err := grpc.NewClient(global.grpcAddr, creds)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	resp, err := grpc_health_v1.NewHealthClient(conn).Check(ctx, &grpc_health_v1.HealthCheckRequest{})
+	if err != nil {
+		return err
+	}
+	if resp.Status != grpc_health_v1.HealthCheckResponse_SERVING {
+		return fmt.Errorf("status %s", resp.Status)
+	}
+	return nil
+}
+
+// checkRESTHealth queries the REST server's /health endpoint.
+func checkRESTHealth(ctx context.Context, global *globalOptions, timeout time.Duration) error {
+	client, err := global.httpClient()
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	url := strings.TrimSuffix(global.restAddr, "/") + "/health"
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status: %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// checkHTTPReachable checks that an HTTP server answers at baseURL. Any
+// response counts, for servers such as the gRPC-Web proxy that have no
+// health endpoint of their own.
+func checkHTTPReachable(ctx context.Context, global *globalOptions, baseURL string, timeout time.Duration) error {
+	client, err := global.httpClient()
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, baseURL, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
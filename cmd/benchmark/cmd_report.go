@@ -0,0 +1,330 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/kaldun-tech/grpc-rest-benchmark/pkg/bench"
+	"github.com/kaldun-tech/grpc-rest-benchmark/pkg/db"
+	"github.com/kaldun-tech/grpc-rest-benchmark/pkg/payload"
+)
+
+// reportOptions holds flags for the report subcommand.
+type reportOptions struct {
+	runID    int64
+	scenario string
+	protocol string
+	client   string
+	limit    int
+
+	comparisonID string
+	suiteID      string
+}
+
+func newReportCmd(global *globalOptions) *cobra.Command {
+	opts := &reportOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "report",
+		Short: "Print stored benchmark results",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx, cancel := signalContext()
+			defer cancel()
+
+			database, err := global.openResults(ctx)
+			if err != nil {
+				return err
+			}
+			defer database.Close()
+
+			filter := db.StatsFilter{
+				Scenario: opts.scenario,
+				Protocol: opts.protocol,
+				Client:   opts.client,
+				Limit:    opts.limit,
+
+				ComparisonID: opts.comparisonID,
+				SuiteID:      opts.suiteID,
+			}
+			if opts.runID > 0 {
+				filter.RunID = &opts.runID
+			}
+
+			stats, err := database.GetFilteredStats(ctx, filter)
+			if err != nil {
+				return err
+			}
+
+			printStatsTable(stats)
+			printDatasetWarning(os.Stdout, stats)
+			if len(stats) == 1 {
+				printLatencyBreakdown(os.Stdout, stats[0])
+				printVerification(os.Stdout, stats[0])
+				printServerLog(os.Stdout, stats[0])
+			}
+
+			// A single load profile run also gets its per-phase breakdown
+			if len(stats) == 1 && stats[0].LoadProfile != nil {
+				phases, err := database.GetPhaseStats(ctx, stats[0].RunID)
+				if err != nil {
+					return err
+				}
+				fmt.Printf("\nLoad profile: %s\n", *stats[0].LoadProfile)
+				printPhaseTable(phases, *stats[0].LoadProfile)
+			}
+
+			// a single run mixing workloads its per-class breakdown
+			if len(stats) == 1 && slices.Contains(classScenarios, stats[0].Scenario) {
+				classes, err := database.GetClassStats(ctx, stats[0].RunID)
+				if err != nil {
+					return err
+				}
+				fmt.Println("\nWorkload classes:")
+				printClassTable(classes)
+			}
+
+			// and a single run issuing several operations its per-operation one
+			if len(stats) == 1 {
+				ops, err := database.GetOperationStats(ctx, stats[0].RunID)
+				if err != nil {
+					return err
+				}
+				if len(ops) > 1 {
+					fmt.Println("\nOperations:")
+					printOperationTable(ops)
+				}
+			}
+			return nil
+		},
+	}
+
+	f := cmd.Flags()
+	f.Int64Var(&opts.runID, "run-id", 0, "Only show this run")
+	f.StringVar(&opts.scenario, "scenario", "", "Filter by scenario")
+	f.StringVar(&opts.protocol, "protocol", "", "Filter by protocol")
+	f.StringVar(&opts.client, "client", "", "Filter by client implementation")
+	f.IntVar(&opts.limit, "limit", 20, "Maximum number of runs to show")
+	f.StringVar(&opts.comparisonID, "comparison-id", "", "Only show runs from this comparison")
+	f.StringVar(&opts.suiteID, "suite-id", "", "Only show runs from this suite (run --config)")
+
+	cmd.RegisterFlagCompletionFunc("scenario", fixedCompletion(bench.Scenarios))
+	cmd.RegisterFlagCompletionFunc("protocol", fixedCompletion(bench.Protocols))
+
+	return cmd
+}
+
+// printStatsTable prints benchmark stats as an aligned table.
+func printStatsTable(stats []*db.BenchmarkStats) {
+	if len(stats) == 0 {
+		fmt.Println("No results found")
+		return
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "RUN\tSCENARIO\tPROTOCOL\tCLIENT\tCONC\tDATASET\tSAMPLES\tREQ/S\tP50 (ms)\tP99 (ms)\tERRORS\tPER CPU-S (C/S)\tCOST/1M\tVERIFIED\tVS PREVIOUS")
+	for _, s := range stats {
+		vsPrevious := "-"
+		if s.BaselineRunID != nil {
+			vsPrevious = fmt.Sprintf("#%d p99 %s req/s %s", *s.BaselineRunID,
+				bench.FormatDeltaPct(s.P99DeltaPct), bench.FormatDeltaPct(s.ThroughputDeltaPct))
+		}
+		protocol := s.Protocol
+		if s.Compression != nil {
+			protocol += "+" + *s.Compression
+		}
+		scenario := s.Scenario
+		if s.PayloadSize != nil {
+			scenario += "/" + payload.FormatSize(*s.PayloadSize)
+		}
+		if s.WriteRatio != nil {
+			scenario += "/writes=" + formatWriteRatio(*s.WriteRatio)
+		}
+		if s.ServerQoS != nil {
+			scenario += "/qos=" + *s.ServerQoS
+		}
+		if s.DBTarget != nil {
+			scenario += "/db=" + *s.DBTarget
+		}
+		dataset := "-"
+		if s.DatasetSize != nil {
+			dataset = formatCount(*s.DatasetSize)
+		}
+		efficiency := formatPerCPUSec(s.ClientPerCPUSec) + "/" + formatPerCPUSec(s.ServerPerCPUSec)
+		cost := "-"
+		if s.CostPerMillion != nil {
+			cost = strconv.FormatFloat(*s.CostPerMillion, 'g', 4, 64)
+		}
+		verified := "-"
+		if s.Verified != nil {
+			verified = "yes"
+			if !*s.Verified {
+				verified = "NO"
+			}
+		}
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%d\t%s\t%d\t%.2f\t%.2f\t%.2f\t%d\t%s\t%s\t%s\t%s\n",
+			s.RunID, scenario, protocol, s.Client, s.Concurrency, dataset,
+			s.TotalSamples, s.Throughput(), s.P50Latency, s.P99Latency,
+			s.TotalSamples-s.Successful, efficiency, cost, verified, vsPrevious)
+	}
+	w.Flush()
+}
+
+// formatPerCPUSec formats requests per CPU-second, or "-" if unmeasured.
+func formatPerCPUSec(v *float64) string {
+	if v == nil {
+		return "-"
+	}
+	return strconv.FormatFloat(*v, 'f', 0, 64)
+}
+
+// formatCount formats a dataset size compactly, e.g. 10000 as "10K" and
+// 2500000 as "2.5M".
+func formatCount(n int64) string {
+	switch {
+	case n >= 1_000_000:
+		return strconv.FormatFloat(float64(n)/1_000_000, 'f', -1, 64) + "M"
+	case n >= 1_000:
+		return strconv.FormatFloat(float64(n)/1_000, 'f', -1, 64) + "K"
+	}
+	return strconv.FormatInt(n, 10)
+}
+
+// printDatasetWarning flags runs measured on different seeded datasets,
+// whose results are not comparable with each other. Runs without a
+// fingerprint are not flagged.
+func printDatasetWarning(out io.Writer, stats []*db.BenchmarkStats) {
+	var fingerprints []string
+	runs := make(map[string][]string)
+	for _, s := range stats {
+		if s.DatasetFingerprint == nil {
+			continue
+		}
+		f := *s.DatasetFingerprint
+		if _, ok := runs[f]; !ok {
+			fingerprints = append(fingerprints, f)
+		}
+		runs[f] = append(runs[f], strconv.FormatInt(s.RunID, 10))
+	}
+	if len(fingerprints) < 2 {
+		return
+	}
+
+	fmt.Fprintf(out, "\nWarning: these runs used %d different datasets and are not comparable across them:\n", len(fingerprints))
+	for _, f := range fingerprints {
+		fmt.Fprintf(out, "  runs %s: %s\n", strings.Join(runs[f], ", "), f)
+	}
+}
+
+// printLatencyBreakdown prints the database time servers reported for a
+// run's requests and the rest of their latency, if they reported it.
+func printLatencyBreakdown(out io.Writer, s *db.BenchmarkStats) {
+	if s.P50DB == nil || s.P99DB == nil || s.P50Network == nil || s.P99Network == nil {
+		return
+	}
+	fmt.Fprintln(out, "\nLatency breakdown:")
+	fmt.Fprintf(out, "  database:  p50=%.2fms p99=%.2fms\n", *s.P50DB, *s.P99DB)
+	fmt.Fprintf(out, "  network:   p50=%.2fms p99=%.2fms (network, serialization and server overhead)\n", *s.P50Network, *s.P99Network)
+}
+
+// printVerification prints the invariants checked after a run, if any
+// could be.
+func printVerification(out io.Writer, s *db.BenchmarkStats) {
+	if s.Verified == nil || s.Verification == nil {
+		return
+	}
+	status := "verified"
+	if !*s.Verified {
+		status = "NOT verified"
+	}
+	fmt.Fprintf(out, "\nInvariants: run %s\n", status)
+	for _, check := range strings.Split(*s.Verification, "; ") {
+		fmt.Fprintf(out, "  %s\n", check)
+	}
+}
+
+// printServerLog prints the errors and warnings the servers logged during
+// a run, if the client read their logs.
+func printServerLog(out io.Writer, s *db.BenchmarkStats) {
+	if s.ServerLogErrors == nil || s.ServerLogWarnings == nil {
+		return
+	}
+	fmt.Fprintf(out, "\nServer logs: %d errors, %d warnings\n", *s.ServerLogErrors, *s.ServerLogWarnings)
+	if s.ServerLogLines != nil {
+		for _, line := range strings.Split(*s.ServerLogLines, "\n") {
+			fmt.Fprintf(out, "  %s\n", line)
+		}
+	}
+}
+
+// printPhaseTable prints per-phase stats of a load profile run, stored as
+// "<target> <spec>". Throughput is measured over the span of each phase's
+// stored samples.
+func printPhaseTable(phases []*db.PhaseStats, stored string) {
+	target, spec, _ := strings.Cut(stored, " ")
+	profile, _ := bench.ParseLoadProfile(spec, target)
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "PHASE\t%s\tSAMPLES\tREQ/S\tP50 (ms)\tP99 (ms)\tERRORS\n", strings.ToUpper(target))
+	for _, p := range phases {
+		level := "-"
+		if profile != nil && p.Phase >= 1 && p.Phase <= len(profile.Phases) {
+			level = strconv.Itoa(profile.Phases[p.Phase-1].Level)
+		}
+		throughput := 0.0
+		if span := p.EndedAt.Sub(p.StartedAt).Seconds(); span > 0 {
+			throughput = float64(p.TotalSamples) / span
+		}
+		fmt.Fprintf(w, "%d\t%s\t%d\t%.2f\t%.2f\t%.2f\t%d\n",
+			p.Phase, level, p.TotalSamples, throughput, p.P50Latency, p.P99Latency,
+			p.TotalSamples-p.Successful)
+	}
+	w.Flush()
+}
+
+// classScenarios are the scenarios whose workload classes are reported.
+// Classes of the write and mixed scenarios match their operations, which are
+// reported instead.
+var classScenarios = []string{"stream-balance", "fanout"}
+
+// printClassTable prints per-workload-class stats of a run that mixed
+// workloads. Throughput is measured over the span of each class's stored
+// samples.
+func printClassTable(classes []*db.ClassStats) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "CLASS\tSAMPLES\tREQ/S\tP50 (ms)\tP99 (ms)\tERRORS")
+	for _, c := range classes {
+		throughput := 0.0
+		if span := c.EndedAt.Sub(c.StartedAt).Seconds(); span > 0 {
+			throughput = float64(c.TotalSamples) / span
+		}
+		fmt.Fprintf(w, "%s\t%d\t%.2f\t%.2f\t%.2f\t%d\n",
+			c.Class, c.TotalSamples, throughput, c.P50Latency, c.P99Latency,
+			c.TotalSamples-c.Successful)
+	}
+	w.Flush()
+}
+
+// printOperationTable prints per-operation stats of a run. Throughput is
+// measured over the span of each operation's stored samples.
+func printOperationTable(ops []*db.OperationStats) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "OPERATION\tSAMPLES\tREQ/S\tP50 (ms)\tP99 (ms)\tERRORS")
+	for _, o := range ops {
+		throughput := 0.0
+		if span := o.EndedAt.Sub(o.StartedAt).Seconds(); span > 0 {
+			throughput = float64(o.TotalSamples) / span
+		}
+		fmt.Fprintf(w, "%s\t%d\t%.2f\t%.2f\t%.2f\t%d\n",
+			o.Operation, o.TotalSamples, throughput, o.P50Latency, o.P99Latency,
+			o.TotalSamples-o.Successful)
+	}
+	w.Flush()
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/kaldun-tech/grpc-rest-benchmark/pkg/db"
+)
+
+func TestPrintDatasetWarning(t *testing.T) {
+	seedA := "accounts=10 transactions=100 max_tx=2025-01-01T00:00:00Z checksum=aaaa"
+	seedB := "accounts=10 transactions=100 max_tx=2025-01-02T00:00:00Z checksum=bbbb"
+
+	var out strings.Builder
+	printDatasetWarning(&out, []*db.BenchmarkStats{
+		{RunID: 3, DatasetFingerprint: &seedB},
+		{RunID: 2, DatasetFingerprint: &seedA},
+		{RunID: 1, DatasetFingerprint: &seedB},
+		{RunID: 0},
+	})
+	want := "\nWarning: these runs used 2 different datasets and are not comparable across them:\n" +
+		"  runs 3, 1: " + seedB + "\n" +
+		"  runs 2: " + seedA + "\n"
+	if out.String() != want {
+		t.Errorf("printDatasetWarning() =\n%q\nwant\n%q", out.String(), want)
+	}
+
+	out.Reset()
+	printDatasetWarning(&out, []*db.BenchmarkStats{
+		{RunID: 2, DatasetFingerprint: &seedA},
+		{RunID: 1},
+	})
+	if out.Len() != 0 {
+		t.Errorf("printDatasetWarning() with one dataset = %q, want nothing", out.String())
+	}
+}
+
+func TestPrintServerLog(t *testing.T) {
+	errors, warnings := int64(4), int64(1)
+	lines := "3x [rest-server.log] ERROR: cache refresh failed\n[postgres] WARNING:  slow checkpoint"
+
+	var out strings.Builder
+	printServerLog(&out, &db.BenchmarkStats{ServerLogErrors: &errors, ServerLogWarnings: &warnings, ServerLogLines: &lines})
+	want := "\nServer logs: 4 errors, 1 warnings\n" +
+		"  3x [rest-server.log] ERROR: cache refresh failed\n" +
+		"  [postgres] WARNING:  slow checkpoint\n"
+	if out.String() != want {
+		t.Errorf("printServerLog() =\n%q\nwant\n%q", out.String(), want)
+	}
+
+	out.Reset()
+	printServerLog(&out, &db.BenchmarkStats{})
+	if out.Len() != 0 {
+		t.Errorf("printServerLog() without server logs = %q, want nothing", out.String())
+	}
+}
+
+func TestFormatCount(t *testing.T) {
+	tests := map[int64]string{
+		0:          "0",
+		999:        "999",
+		10_000:     "10K",
+		2_500:      "2.5K",
+		1_000_000:  "1M",
+		10_000_000: "10M",
+	}
+	for n, want := range tests {
+		if got := formatCount(n); got != want {
+			t.Errorf("formatCount(%d) = %q, want %q", n, got, want)
+		}
+	}
+}
+
+func TestPrintLatencyBreakdown(t *testing.T) {
+	p50DB, p99DB, p50Net, p99Net := 0.8, 4.25, 1.1, 3.0
+
+	var out strings.Builder
+	printLatencyBreakdown(&out, &db.BenchmarkStats{P50DB: &p50DB, P99DB: &p99DB, P50Network: &p50Net, P99Network: &p99Net})
+	want := "\nLatency breakdown:\n" +
+		"  database:  p50=0.80ms p99=4.25ms\n" +
+		"  network:   p50=1.10ms p99=3.00ms (network, serialization and server overhead)\n"
+	if out.String() != want {
+		t.Errorf("printLatencyBreakdown() =\n%q\nwant\n%q", out.String(), want)
+	}
+
+	out.Reset()
+	printLatencyBreakdown(&out, &db.BenchmarkStats{})
+	if out.Len() != 0 {
+		t.Errorf("printLatencyBreakdown() without database times = %q, want nothing", out.String())
+	}
+}
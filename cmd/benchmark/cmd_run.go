@@ -0,0 +1,911 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/kaldun-tech/grpc-rest-benchmark/pkg/bench"
+	"github.com/kaldun-tech/grpc-rest-benchmark/pkg/compression"
+	"github.com/kaldun-tech/grpc-rest-benchmark/pkg/db"
+	"github.com/kaldun-tech/grpc-rest-benchmark/pkg/payload"
+	"github.com/kaldun-tech/grpc-rest-benchmark/pkg/serverlog"
+	"github.com/kaldun-tech/grpc-rest-benchmark/pkg/suite"
+	"github.com/kaldun-tech/grpc-rest-benchmark/pkg/timing"
+	"github.com/kaldun-tech/grpc-rest-benchmark/pkg/workload"
+)
+
+func newRunCmd(global *globalOptions) *cobra.Command {
+	opts := &runOptions{}
+	var agentNames []string
+	var bundlePath string
+
+	cmd := &cobra.Command{
+		Use:   "run",
+		Short: "Run a single benchmark and store the results",
+		Long: `Run runs a single benchmark and stores its results.
+
+Long runs can be checked on with signals. SIGUSR1 prints an interim summary of
+the run so far and of the current stats segment. SIGUSR2 rotates the segment:
+it logs the segment's stats to the run log and starts the next, so later
+summaries cover only the requests since. With --sample-flush, samples are also
+written to a Parquet file as they are collected, and SIGUSR2 rotates it too:
+the file is completed and renamed after the segment it holds, e.g.
+samples.1.parquet, and the next segment is written to a new one. Samples are
+still stored when the run ends as usual.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx, cancel := signalContext()
+			defer cancel()
+
+			if bundlePath != "" {
+				b, err := openBundle(bundlePath)
+				if err != nil {
+					return err
+				}
+				defer b.Close()
+				if err := b.apply(cmd, opts); err != nil {
+					return err
+				}
+			}
+			if err := resolveEnvironment(ctx, cmd, global, opts); err != nil {
+				return err
+			}
+			if len(agentNames) > 0 {
+				agents, err := resolveAgents(ctx, global, agentNames)
+				if err != nil {
+					return err
+				}
+				opts.agents = agents
+				opts.workers = make([]string, len(agents))
+				for i, a := range agents {
+					opts.workers[i] = a.Address
+				}
+			}
+
+			if opts.configPath != "" {
+				s, err := suite.Load(opts.configPath)
+				if err != nil {
+					return err
+				}
+				return runSuite(ctx, global, opts, s)
+			}
+			if opts.workloadPath != "" {
+				w, err := workload.Load(opts.workloadPath)
+				if err != nil {
+					return err
+				}
+				if opts.bundle != nil && opts.workloadPath == opts.bundle.workloadPath {
+					if err := opts.bundle.applyWorkload(w); err != nil {
+						return err
+					}
+				}
+				return runWorkload(ctx, global, opts, w)
+			}
+
+			if err := opts.validate(); err != nil {
+				return err
+			}
+			return runBenchmark(ctx, global, opts)
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.protocol, "protocol", "grpc", "Protocol to test: "+strings.Join(bench.Protocols, " | "))
+	cmd.RegisterFlagCompletionFunc("protocol", fixedCompletion(bench.Protocols))
+	addRunFlags(cmd, opts)
+
+	cmd.Flags().StringSliceVar(&agentNames, "agents", nil, "Registered agents ('benchmark worker --register') that generate the load, by name, or \"healthy\" for every healthy agent; recorded with the run")
+	cmd.MarkFlagsMutuallyExclusive("agents", "workers")
+
+	cmd.Flags().StringVar(&opts.workloadPath, "workload", "", "Workload YAML file defining stages, operation mix, arrivals and protocol")
+	cmd.MarkFlagFilename("workload", "yaml", "yml")
+	for _, name := range workloadFlags {
+		cmd.MarkFlagsMutuallyExclusive("workload", name)
+	}
+
+	cmd.Flags().StringVar(&opts.configPath, "config", "", "Suite file (YAML or JSON) with a matrix of scenarios, protocols, concurrency levels and durations to run in sequence")
+	cmd.MarkFlagFilename("config", "yaml", "yml", "json")
+	for _, name := range suiteFlags {
+		cmd.MarkFlagsMutuallyExclusive("config", name)
+	}
+
+	cmd.Flags().StringVar(&bundlePath, "from-bundle", "", "Reproduce the run packaged by 'benchmark bundle': its run flags, workload stage and input files apply unless given on the command line")
+	cmd.MarkFlagFilename("from-bundle", "tar.gz", "tgz")
+	cmd.MarkFlagsMutuallyExclusive("from-bundle", "config")
+	cmd.MarkFlagsMutuallyExclusive("from-bundle", "workload")
+
+	return cmd
+}
+
+// runBenchmark executes a single benchmark run and stores the results.
+func runBenchmark(ctx context.Context, global *globalOptions, opts *runOptions) error {
+	env, err := prepareRun(ctx, global, opts, opts.needsAccounts())
+	if err != nil {
+		return err
+	}
+	defer env.Close()
+
+	_, _, err = executeRun(ctx, global, opts, env)
+	return err
+}
+
+// runWorkload runs each stage of a workload back to back, storing each as a
+// separate run.
+func runWorkload(ctx context.Context, global *globalOptions, base *runOptions, w *workload.Workload) error {
+	stages := make([]*runOptions, len(w.Stages))
+	for i, s := range w.Stages {
+		stages[i] = stageOptions(base, w, s)
+		if err := stages[i].validate(); err != nil {
+			return fmt.Errorf("stage %q: %w", s.Name, err)
+		}
+	}
+
+	env, err := prepareRun(ctx, global, stages[0], w.UsesAccounts())
+	if err != nil {
+		return err
+	}
+	defer env.Close()
+
+	fmt.Printf("Workload %s: %d stage(s), %s total\n", w.Name, len(stages), w.Duration())
+	for i, stage := range stages {
+		fmt.Printf("\n=== Stage %d/%d: %s ===\n", i+1, len(stages), stage.stage)
+		if _, _, err := executeRun(ctx, global, stage, env); err != nil {
+			return fmt.Errorf("stage %q failed: %w", stage.stage, err)
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+	}
+	return nil
+}
+
+// runSuite runs every combination of a suite's matrix back to back, storing
+// each as a separate run tagged with a shared suite ID.
+func runSuite(ctx context.Context, global *globalOptions, base *runOptions, s *suite.Suite) error {
+	matrix := s.Runs()
+	runs := make([]*runOptions, len(matrix))
+	needsAccounts := false
+	for i, r := range matrix {
+		runs[i] = suiteOptions(base, s, r)
+		if err := runs[i].validate(); err != nil {
+			return fmt.Errorf("run %q: %w", r, err)
+		}
+		needsAccounts = needsAccounts || runs[i].needsAccounts()
+	}
+
+	env, err := prepareRun(ctx, global, runs[0], needsAccounts)
+	if err != nil {
+		return err
+	}
+	defer env.Close()
+
+	suiteID := fmt.Sprintf("suite-%s", time.Now().Format("20060102-150405"))
+	if env.results != nil {
+		if err := env.results.CreateSuite(ctx, &db.Suite{ID: suiteID, Name: s.Name, Description: s.Description}); err != nil {
+			return fmt.Errorf("failed to store suite %s: %w", suiteID, err)
+		}
+	}
+	env.suiteID = &suiteID
+
+	fmt.Printf("Suite %s: %d run(s), %s total\n", s.Name, len(runs), s.Duration())
+	runIDs := make([]int64, 0, len(runs))
+	for i, run := range runs {
+		if i > 0 && s.Pause > 0 {
+			fmt.Printf("Pausing %s before the next run...\n", s.Pause)
+			select {
+			case <-time.After(s.Pause):
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+
+		fmt.Printf("\n=== Run %d/%d: %s ===\n", i+1, len(runs), matrix[i])
+		_, runID, err := executeRun(ctx, global, run, env)
+		if err != nil {
+			return fmt.Errorf("run %q failed: %w", matrix[i], err)
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		runIDs = append(runIDs, runID)
+	}
+
+	if env.results == nil {
+		fmt.Printf("\nSuite %s (%s): %d run(s) written to %s\n", s.Name, suiteID, len(runIDs), base.resultsDir)
+		return nil
+	}
+	fmt.Printf("\nSuite %s (%s): %d run(s) stored\n", s.Name, suiteID, len(runIDs))
+	for i, id := range runIDs {
+		fmt.Printf("  run %d: %s\n", id, matrix[i])
+	}
+	fmt.Printf("Report with: benchmark report --suite-id=%s\n", suiteID)
+	return nil
+}
+
+// suiteOptions returns a copy of the run options with one combination of a
+// suite's matrix applied. Flags the suite does not cover are kept.
+func suiteOptions(base *runOptions, s *suite.Suite, r suite.Run) *runOptions {
+	opts := *base
+	opts.scenario = r.Scenario
+	opts.protocol = r.Protocol
+	opts.concurrency = r.Concurrency
+	opts.duration = r.Duration
+	if s.Rate > 0 {
+		opts.rate = s.Rate
+	}
+	if r.DBTarget != "" {
+		opts.dbTarget = r.DBTarget
+	}
+	return &opts
+}
+
+// stageOptions returns a copy of the run options with the settings of one
+// workload stage applied. Flags the workload does not cover are kept.
+func stageOptions(base *runOptions, w *workload.Workload, s workload.Stage) *runOptions {
+	opts := *base
+	opts.workload = w.Name
+	opts.stage = s.Name
+	opts.protocol = w.Protocol
+	opts.concurrency = w.StageConcurrency(s)
+	opts.duration = s.Duration
+	opts.rate = w.StageRate(s)
+	opts.accounts = w.Accounts
+
+	switch w.Arrival.Process {
+	case workload.ArrivalPoisson:
+		opts.poisson = true
+	case workload.ArrivalReplay:
+		opts.replayTiming = w.Arrival.Timing
+		opts.replayMode = w.Arrival.Mode
+		opts.replaySpeedup = w.Arrival.Speedup
+	}
+
+	// Batch and write operations only run as part of a mix
+	ops := w.StageOperations(s)
+	if len(ops) == 1 && ops[0].Scenario != workload.ScenarioBatch && ops[0].Scenario != workload.ScenarioWrite {
+		opts.scenario = ops[0].Scenario
+		if ops[0].PayloadSize != "" {
+			opts.payloadSize = ops[0].PayloadSize
+		}
+		return &opts
+	}
+
+	opts.scenario = "mixed"
+	opts.mix = make([]bench.MixOperation, len(ops))
+	for i, op := range ops {
+		size := 0
+		if op.Scenario == workload.ScenarioEcho {
+			size = opts.payloadBytes()
+			if op.PayloadSize != "" {
+				size, _ = payload.ParseSize(op.PayloadSize)
+			}
+		}
+		opts.mix[i] = bench.MixOperation{Scenario: op.Scenario, Weight: op.Weight, PayloadSize: size, BatchSize: op.BatchSize}
+	}
+	return &opts
+}
+
+// runEnv holds state shared by every run in an invocation, loaded once so
+// back-to-back runs see identical inputs.
+type runEnv struct {
+	results      db.ResultsStore // nil with --no-db
+	accountIDs   []string        // only when balance queries are issued
+	timing       *timing.Replay  // nil unless timing replay is configured
+	comparisonID *string         // set when the run is part of a comparison
+	suiteID      *string         // set when the run is part of a suite
+	datasetHash  *string         // hash of accountIDs and timing, nil if neither is loaded
+
+	datasetFingerprint *string // seeded server dataset, nil if PostgreSQL is unreachable
+	datasetSize        *int64  // accounts in the seeded dataset, nil with the fingerprint
+
+	serverConfigs map[string]db.ServerConfig // config each server recorded, keyed by db.ServerGRPC or db.ServerREST
+
+	dataset      *db.DB // seeded PostgreSQL database, nil if unreachable
+	closeDataset func()
+
+	envLock        *db.EnvironmentLock // nil without the dataset database or with a conflict
+	concurrentWith *string             // holder of the environment lock, when overlapping it
+}
+
+// prepareRun opens the results store, fingerprints the seeded dataset and
+// loads timing data, and account IDs if loadAccounts is set, from
+// --accounts-file or the dataset. With --no-db it skips the databases.
+func prepareRun(ctx context.Context, global *globalOptions, opts *runOptions, loadAccounts bool) (*runEnv, error) {
+	if opts.noDB {
+		return prepareOffline(ctx, opts, loadAccounts)
+	}
+	results, err := global.openResults(ctx)
+	if err != nil {
+		return nil, err
+	}
+	env := &runEnv{results: results}
+
+	dataset, closeDataset := datasetDB(ctx, global, results)
+	env.dataset, env.closeDataset = dataset, closeDataset
+
+	if dataset != nil {
+		if f, err := dataset.GetDatasetFingerprint(ctx); err != nil {
+			log.Printf("Warning: %v", err)
+		} else {
+			fingerprint := f.String()
+			env.datasetFingerprint = &fingerprint
+			env.datasetSize = &f.Accounts
+			log.Printf("Dataset: %s", fingerprint)
+		}
+		// The reference scenario's stub is not configured like a server
+		if opts.scenario != "reference" {
+			if env.serverConfigs, err = dataset.GetServerConfigs(ctx); err != nil {
+				log.Printf("Warning: %v", err)
+			}
+			if err := env.lockEnvironment(ctx, global, opts); err != nil {
+				env.Close()
+				return nil, err
+			}
+		}
+	}
+
+	// Pre-fetch account IDs for balance queries
+	switch {
+	case loadAccounts && opts.accountIDsFile != "":
+		if env.accountIDs, err = readAccountIDs(opts.accountIDsFile); err != nil {
+			env.Close()
+			return nil, err
+		}
+		log.Printf("Loaded %d account IDs from %s", len(env.accountIDs), opts.accountIDsFile)
+	case loadAccounts:
+		env.accountIDs, err = loadAccountIDs(ctx, dataset, results)
+		if err != nil {
+			env.Close()
+			return nil, err
+		}
+		log.Printf("Loaded %d account IDs", len(env.accountIDs))
+	}
+
+	if err := env.loadTiming(ctx, opts); err != nil {
+		env.Close()
+		return nil, err
+	}
+	return env, nil
+}
+
+// prepareOffline prepares a --no-db run: account IDs are read from
+// --accounts-file or synthesized, and there is no results store or dataset
+// to fingerprint.
+func prepareOffline(ctx context.Context, opts *runOptions, loadAccounts bool) (*runEnv, error) {
+	log.Printf("Running without a database, results are written to %s", opts.resultsDir)
+	env := &runEnv{closeDataset: func() {}}
+	if loadAccounts {
+		var err error
+		if opts.accountIDsFile != "" {
+			if env.accountIDs, err = readAccountIDs(opts.accountIDsFile); err != nil {
+				return nil, err
+			}
+			log.Printf("Loaded %d account IDs from %s", len(env.accountIDs), opts.accountIDsFile)
+		} else {
+			env.accountIDs = syntheticAccountIDs(opts.syntheticAccounts)
+			log.Printf("Using %d synthetic account IDs", len(env.accountIDs))
+		}
+	}
+	if err := env.loadTiming(ctx, opts); err != nil {
+		return nil, err
+	}
+	return env, nil
+}
+
+// loadTiming loads the timing data of the run, if configured, and hashes
+// it with the account IDs.
+func (e *runEnv) loadTiming(ctx context.Context, opts *runOptions) error {
+	var err error
+	e.timing, err = loadTimingReplay(ctx, opts)
+	if err != nil {
+		return err
+	}
+	if e.timing != nil {
+		e.timing.WriteSummary(os.Stdout)
+		fmt.Println()
+	}
+	e.datasetHash = datasetHash(e.accountIDs, e.timing)
+	return nil
+}
+
+// firstSyntheticAccount is the number of the first seeded account, as in
+// cmd/seed and scripts/seed_data.sql.
+const firstSyntheticAccount = 100000
+
+// syntheticAccountIDs returns the IDs of the first n accounts 'make seed'
+// creates, 0.0.100000 and up.
+func syntheticAccountIDs(n int) []string {
+	ids := make([]string, n)
+	for i := range ids {
+		ids[i] = fmt.Sprintf("0.0.%d", firstSyntheticAccount+i)
+	}
+	return ids
+}
+
+// datasetHash identifies the input data of a run, so that automatic
+// baselines only compare runs over the same accounts and timing data. It
+// returns nil if the run loads neither.
+func datasetHash(accountIDs []string, tr *timing.Replay) *string {
+	if len(accountIDs) == 0 && tr == nil {
+		return nil
+	}
+
+	h := sha256.New()
+	sorted := slices.Sorted(slices.Values(accountIDs))
+	for _, id := range sorted {
+		io.WriteString(h, id)
+		h.Write([]byte{0})
+	}
+	if tr != nil {
+		json.NewEncoder(h).Encode(tr.Data())
+	}
+
+	sum := hex.EncodeToString(h.Sum(nil))[:16]
+	return &sum
+}
+
+// Close releases the dataset database and the results store.
+func (e *runEnv) Close() {
+	e.unlockEnvironment()
+	e.closeDataset()
+	if e.results != nil {
+		e.results.Close()
+	}
+}
+
+// datasetDB returns the PostgreSQL database holding the seeded dataset and a
+// function releasing it. That is the results store itself unless results go
+// to a local file; then it is a separate connection, and nil if PostgreSQL
+// cannot be reached.
+func datasetDB(ctx context.Context, global *globalOptions, results db.ResultsStore) (*db.DB, func()) {
+	if database, ok := results.(*db.DB); ok {
+		return database, func() {}
+	}
+
+	database, err := global.connectDB(ctx)
+	if err != nil {
+		log.Printf("PostgreSQL unavailable (%v), running offline", err)
+		return nil, func() {}
+	}
+	return database, database.Close
+}
+
+// loadAccountIDs loads the account IDs for balance queries from the dataset
+// database. With a local results backend the IDs are also cached in the
+// results file, and the cache is used when database is nil.
+func loadAccountIDs(ctx context.Context, database *db.DB, results db.ResultsStore) ([]string, error) {
+	local, isLocal := results.(*db.LocalDB)
+	if database == nil {
+		log.Printf("Using account IDs cached in %s", local.Path)
+		ids, err := local.GetAllAccountIDs(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if len(ids) == 0 {
+			return nil, fmt.Errorf("no account IDs cached in %s, run once with PostgreSQL reachable first", local.Path)
+		}
+		return ids, nil
+	}
+
+	ids, err := queryAccountIDs(ctx, database)
+	if err != nil {
+		return nil, err
+	}
+	if isLocal {
+		if err := local.SaveAccountIDs(ctx, ids); err != nil {
+			log.Printf("Warning: failed to cache account IDs in %s: %v", local.Path, err)
+		}
+	}
+	return ids, nil
+}
+
+// queryAccountIDs loads every seeded account ID from PostgreSQL.
+func queryAccountIDs(ctx context.Context, database *db.DB) ([]string, error) {
+	log.Println("Loading account IDs from database...")
+	ids, err := database.GetAllAccountIDs(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load account IDs: %w", err)
+	}
+	if len(ids) == 0 {
+		return nil, fmt.Errorf("no accounts found in database, run 'make seed' first")
+	}
+	return ids, nil
+}
+
+// executeRun runs one benchmark with the given options, prints its summary
+// and stores it. It returns the collected results and the stored run ID
+// (zero if the run could not be recorded).
+func executeRun(ctx context.Context, global *globalOptions, opts *runOptions, env *runEnv) (*bench.Results, int64, error) {
+	var runLog *RunLog
+	if opts.logDir != "" {
+		var err error
+		runLog, err = OpenRunLog(opts.logDir, time.Now())
+		if err != nil {
+			return nil, 0, err
+		}
+		defer runLog.Close()
+		ctx = bench.WithLogger(ctx, runLog.Logger)
+		log.Printf("Writing run log to %s", runLog.Path)
+	}
+	logger := bench.LoggerFrom(ctx)
+	logger.Info("run config",
+		"workload", opts.workload,
+		"stage", opts.stage,
+		"scenario", opts.scenario,
+		"protocol", opts.protocolLabel(),
+		"concurrency", opts.concurrency,
+		"duration", opts.sizing().RunDuration().String(),
+		"rate", opts.rate,
+		"load_profile", opts.loadProfile,
+		"measure_window", opts.measureWindow,
+		"load_profile_target", opts.profileTarget(),
+		"stream_metric", opts.primaryStreamMetric(),
+		"subscribers", opts.sizing().StreamSubscribers(),
+		"streams_per_worker", opts.streamsPerWorker,
+		"stream_rate", opts.streamRate,
+		"min_streams", opts.minStreams,
+		"check_ordering", opts.checkOrdering,
+		"live", opts.liveStreams(),
+		"reconnect", opts.reconnects(),
+		"chaos_disconnect", opts.chaosDisconnect.String(),
+		"chaos_restart", opts.chaosRestartLabel(),
+		"staleness", opts.staleness,
+		"correct_omission", opts.correctOmission,
+		"payload_size", opts.payloadBytes(),
+		"write_ratio", opts.writeRatio,
+		"request_timeout", opts.requestTimeout.String(),
+		"backoff", opts.backoff,
+		"process_cost", opts.processCost.String(),
+		"compression", opts.compression,
+		"json_encoder", opts.jsonEncoderLabel(env.serverConfigs[serverName(opts.protocol)]),
+		"rest_stream_format", opts.restStreamLabel(),
+		"connection", opts.connectionLabel(),
+		"cache_bust", opts.conn.CacheBust,
+		"injected_headers", opts.injectedHeadersLabel(),
+		"server_qos", opts.serverQoS,
+		"db_target", opts.dbTarget,
+		"server_logs", opts.serverLogs,
+		"profile_cpu", opts.profileCPU,
+		"profile_mem", opts.profileMem,
+		"workers", opts.workers,
+		"server_pools", env.serverConfigs[serverName(opts.protocol)].Pools,
+		"server_query_tx", env.serverConfigs[serverName(opts.protocol)].QueryTx,
+		"server_faults", env.serverConfigs[serverName(opts.protocol)].Faults,
+		"server_cache", env.serverConfigs[serverName(opts.protocol)].Cache,
+		"server_middleware", env.serverConfigs[serverName(opts.protocol)].Middleware,
+		"auth", global.auth.String(),
+		"mix", opts.operationMix(),
+		"poisson", opts.poisson,
+		"account_pattern", opts.accounts.Pattern,
+		"account_churn", opts.accounts.Churn,
+		"grpc_addr", global.grpcAddr,
+		"grpc_web_addr", global.grpcWebAddr,
+		"rest_addr", global.restAddr,
+		"replay_timing", opts.replayTiming,
+		"replay_mode", opts.replayMode,
+		"replay_speedup", opts.replaySpeedup,
+		"hcs_topic", opts.hcsTopic,
+		"comparison_id", env.comparisonID,
+		"suite_id", env.suiteID,
+	)
+	if opts.bundle != nil {
+		opts.bundle.check(ctx, env)
+	}
+
+	if opts.dbTarget != "" {
+		if err := setDBTarget(ctx, global, opts.protocol, opts.dbTarget); err != nil {
+			return nil, 0, err
+		}
+		log.Printf("Server database target: %s", opts.dbTarget)
+	}
+
+	cfg := opts.benchConfig(global, env)
+	if len(cfg.Workers) == 0 {
+		client, err := newClient(cfg)
+		if err != nil {
+			return nil, 0, err
+		}
+		defer client.Close()
+		cfg.Client = client
+	}
+	var stopSignals func()
+	cfg.Interim, stopSignals = interimSignals()
+	defer stopSignals()
+	cfg.InterimOut = os.Stdout
+	if opts.sampleFlush != "" {
+		flush, err := OpenSampleFlushFile(opts.sampleFlush)
+		if err != nil {
+			return nil, 0, err
+		}
+		defer func() {
+			if err := flush.Close(); err != nil {
+				warnf(ctx, "%v", err)
+			}
+		}()
+		cfg.SampleFlush = flush
+		log.Printf("Flushing samples to %s", flush.Path)
+	}
+
+	profile, tr := cfg.LoadProfile, cfg.Timing
+	protocol := opts.protocolLabel()
+
+	// Run benchmark
+	fmt.Printf("\nStarting %s benchmark (%s protocol)\n", opts.scenario, protocol)
+	if profile != nil && profile.Target == bench.ProfileTargetConcurrency {
+		fmt.Printf("Concurrency: %s | Duration: %s", profile.Spec, profile.Duration())
+	} else {
+		fmt.Printf("Concurrency: %d | Duration: %s", opts.concurrency, opts.sizing().RunDuration())
+	}
+	if opts.streamsPerWorker > 1 {
+		fmt.Printf(" | Streams per worker: %d", opts.streamsPerWorker)
+	}
+	if opts.scenario == "stream" && opts.rate > 0 {
+		fmt.Printf(" | Rate limit: %d events/s", opts.rate)
+	}
+	if opts.scenario == "stream-balance" {
+		fmt.Printf(" | Subscribers: %d", opts.subscribers)
+		if opts.streamRate > 0 {
+			fmt.Printf(" (%d events/s each)", opts.streamRate)
+		}
+	}
+	if opts.scenario == "fanout" {
+		fmt.Printf(" | Subscribers: %d", opts.subscribers)
+	}
+	if opts.scenario != "stream" && opts.rate > 0 {
+		fmt.Printf(" | Target rate: %d req/s", opts.rate)
+		if opts.poisson {
+			fmt.Printf(" (poisson)")
+		}
+	}
+	if profile != nil && profile.Target == bench.ProfileTargetRate {
+		fmt.Printf(" | Target rate: %s req/s", profile.Spec)
+		if opts.poisson {
+			fmt.Printf(" (poisson)")
+		}
+	}
+	if opts.scenario == "mixed" {
+		fmt.Printf(" | Mix: %s", formatMix(opts.operationMix()))
+	}
+	if opts.accounts.Pattern != "" && opts.accounts.Pattern != workload.AccountsUniform {
+		fmt.Printf(" | Accounts: %s", opts.accounts.Pattern)
+	}
+	if opts.accounts.Churn > 0 {
+		fmt.Printf(" | Churn: %s every %s", formatWriteRatio(opts.accounts.Churn), opts.accounts.ChurnInterval)
+	}
+	if opts.scenario == "echo" {
+		fmt.Printf(" | Payload: %s", payload.FormatSize(opts.payloadBytes()))
+	}
+	if opts.scenario == "write" {
+		fmt.Printf(" | Writes: %s", formatWriteRatio(opts.writeRatio))
+	}
+	if opts.requestTimeout > 0 {
+		fmt.Printf(" | Timeout: %s", opts.requestTimeout)
+	}
+	if opts.backoff {
+		fmt.Print(" | Backoff")
+	}
+	if opts.processCost > 0 {
+		fmt.Printf(" | Process cost: %s", opts.processCost)
+	}
+	if opts.compression != compression.None {
+		fmt.Printf(" | Compression: %s", opts.compression)
+	}
+	if label := opts.connectionLabel(); label != "" {
+		fmt.Printf(" | Connections: %s", label)
+	}
+	if label := opts.cacheBustLabel(); label != "" {
+		fmt.Printf(" | Cache busting: %s", label)
+	}
+	if label := opts.injectedHeadersLabel(); label != "" {
+		fmt.Printf(" | Injected headers: %s (%d B per request)", label, opts.injectedHeaderBytes())
+	}
+	if label := opts.serverQoSLabel(); label != "" {
+		fmt.Printf(" | Server QoS: %s", label)
+	}
+	if opts.dbTarget != "" {
+		fmt.Printf(" | Database: %s", opts.dbTarget)
+	}
+	if len(opts.workers) > 0 {
+		fmt.Printf(" | Workers: %d", len(opts.workers))
+	}
+	if faults := env.serverConfigs[serverName(opts.protocol)].Faults; faults != "" {
+		fmt.Printf(" | Server faults: %s", faults)
+	}
+	if cache := env.serverConfigs[serverName(opts.protocol)].Cache; cache != "" {
+		fmt.Printf(" | Server cache: %s", cache)
+	}
+	if middleware := env.serverConfigs[serverName(opts.protocol)].Middleware; middleware != "" {
+		fmt.Printf(" | Server middleware: %s", middleware)
+	}
+	if global.auth.Enabled() {
+		fmt.Printf(" | Auth: %s", global.auth)
+	}
+	if label := opts.jsonEncoderLabel(env.serverConfigs[serverName(opts.protocol)]); label != "" {
+		fmt.Printf(" | JSON encoder: %s", label)
+	}
+	if label := opts.restStreamLabel(); label != "" {
+		fmt.Printf(" | Stream format: %s", label)
+	}
+	if opts.sizing().StreamSubscribers() > 0 {
+		fmt.Printf(" | Latency: %s", opts.primaryStreamMetric())
+	}
+	if opts.scenario == "stream" && opts.live {
+		fmt.Printf(" | Live streams")
+	}
+	if opts.chaosDisconnect > 0 {
+		fmt.Printf(" | Chaos disconnect: %s", opts.chaosDisconnect)
+	} else if opts.reconnect {
+		fmt.Printf(" | Reconnecting")
+	}
+	if opts.chaosRestart > 0 {
+		fmt.Printf(" | Chaos restart: %s", opts.chaosRestartLabel())
+	}
+	if opts.checkOrdering {
+		fmt.Printf(" | Checking ordering")
+	}
+	if opts.staleness {
+		fmt.Printf(" | Measuring balance staleness")
+	}
+	if tr != nil {
+		fmt.Printf(" | Replay: %s (%.1fx)", opts.replayMode, opts.replaySpeedup)
+	}
+	fmt.Println()
+
+	before := countTransactions(ctx, env, opts)
+	var scraper *serverlog.Scraper
+	if len(opts.serverLogs) > 0 {
+		sources, err := opts.serverLogSources()
+		if err != nil {
+			return nil, 0, err
+		}
+		if scraper, err = serverlog.Start(ctx, sources); err != nil {
+			return nil, 0, err
+		}
+	}
+
+	profiles := startProfiles(ctx, global, opts)
+	report, err := bench.Run(ctx, cfg)
+	profiles.stop(ctx, global, opts)
+	var serverLog *serverlog.Summary
+	if scraper != nil {
+		summary, logErr := scraper.Stop()
+		if logErr != nil {
+			warnf(ctx, "server logs were not read completely: %v", logErr)
+		}
+		serverLog = &summary
+	}
+	if err != nil {
+		return nil, 0, err
+	}
+	for _, w := range report.Warnings {
+		log.Printf("Warning: %s", w)
+	}
+	results := report.Results
+	results.SetPercentiles(opts.percentileValues())
+	results.SetHistogram(opts.histogram)
+	results.SetCostModel(opts.cost)
+	concurrency := report.Concurrency
+
+	// Print summary
+	results.PrintSummary(os.Stdout, opts.scenario, protocol, concurrency)
+	if opts.scenario == "reference" {
+		bench.PrintReferenceOverhead(os.Stdout, results)
+	}
+	if rep := report.Ordering; rep != nil {
+		bench.PrintOrderingReport(os.Stdout, *rep)
+		attrs := []any{"subscribers", rep.Subscribers, "checked", rep.Checked, "prefix", rep.Prefix, "divergent", rep.Divergent}
+		if d := rep.First; d != nil {
+			attrs = append(attrs, "index", d.Index, "reference", d.Reference, "subscriber", d.Subscriber, "want", d.Want, "got", d.Got)
+			logger.Warn("stream ordering diverged", attrs...)
+		} else {
+			logger.Info("stream ordering consistent", attrs...)
+		}
+	}
+
+	if serverLog != nil {
+		serverlog.PrintSummary(os.Stdout, *serverLog)
+		attrs := []any{"errors", serverLog.Errors, "warnings", serverLog.Warnings, "lines", serverLog.Excerpt()}
+		if serverLog.Errors > 0 {
+			logger.Warn("server logged errors", attrs...)
+		} else {
+			logger.Info("server logs", attrs...)
+		}
+	}
+
+	verification := results.Verify(serverFacts(ctx, env, opts, before, serverLog))
+	bench.PrintVerification(os.Stdout, verification)
+	if verification.Checked() {
+		attrs := []any{"verified", verification.Verified(), "checks", verification.String()}
+		if verification.Verified() {
+			logger.Info("invariants", attrs...)
+		} else {
+			logger.Warn("invariants", attrs...)
+		}
+	}
+
+	logger.Info("run complete",
+		"requests", results.TotalRequests(),
+		"errors", results.TotalRequests()-results.SuccessfulRequests(),
+		"throughput", results.Throughput(),
+		"p50_ms", bench.DurationMs(results.Percentile(50)),
+		"p99_ms", bench.DurationMs(results.Percentile(99)),
+	)
+	if from, to, ok := results.MeasuredWindow(); ok {
+		logger.Info("measure window",
+			"from", from.String(),
+			"to", to.Round(time.Second).String(),
+			"full_requests", results.FullRequests(),
+			"full_throughput", results.FullThroughput(),
+			"full_p50_ms", bench.DurationMs(results.FullPercentile(50)),
+			"full_p99_ms", bench.DurationMs(results.FullPercentile(99)),
+		)
+	}
+	if n, total, ok := results.GCPauses(); ok {
+		attrs := []any{"pauses", n, "pause_ms", bench.DurationMs(total)}
+		if paused, tail, ok := results.GCTail(); ok {
+			attrs = append(attrs, "p99_samples", tail, "p99_samples_in_pause", paused)
+		}
+		logger.Info("client gc", attrs...)
+	}
+	if p50, ok := results.CorrectedPercentile(50); ok {
+		p99, _ := results.CorrectedPercentile(99)
+		logger.Info("coordinated omission correction",
+			"interval_ms", bench.DurationMs(report.RequestInterval),
+			"corrected_p50_ms", bench.DurationMs(p50),
+			"corrected_p99_ms", bench.DurationMs(p99),
+		)
+	}
+
+	run := runRecord(ctx, global, opts, env, report, verification, serverLog, runLog)
+	return results, storeRun(ctx, opts, env, profiles, results, run), nil
+}
+
+// loadTimingReplay loads timing replay either from file or by fetching from an
+// HCS topic. Returns nil if no replay is configured.
+func loadTimingReplay(ctx context.Context, opts *runOptions) (*timing.Replay, error) {
+	if opts.hcsTopic != "" {
+		// Fetch timing data directly from HCS topic
+		log.Printf("Fetching timing data from HCS topic %s on %s...", opts.hcsTopic, opts.hcsNetwork)
+		fetchCtx, fetchCancel := context.WithTimeout(ctx, 5*time.Minute)
+		timingData, err := timing.Fetch(fetchCtx, opts.hcsTopic, opts.hcsNetwork, opts.hcsLimit, func(count int) {
+			log.Printf("  Fetched %d messages...", count)
+		})
+		fetchCancel()
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch HCS timing data: %w", err)
+		}
+		log.Printf("Fetched %d messages from topic %s", timingData.MessageCount, opts.hcsTopic)
+
+		// Optionally save for reuse
+		if opts.hcsSavePath != "" {
+			if err := timing.Save(opts.hcsSavePath, timingData); err != nil {
+				warnf(ctx, "failed to save timing data: %v", err)
+			} else {
+				log.Printf("Saved timing data to %s", opts.hcsSavePath)
+			}
+		}
+
+		return timing.NewReplay(timingData, opts.replayMode, opts.replaySpeedup), nil
+	}
+
+	if opts.replayTiming != "" {
+		// Load timing data from file
+		timingData, err := timing.Load(opts.replayTiming)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load timing data: %w", err)
+		}
+		return timing.NewReplay(timingData, opts.replayMode, opts.replaySpeedup), nil
+	}
+
+	return nil, nil
+}
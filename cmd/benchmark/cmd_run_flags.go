@@ -0,0 +1,748 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/kaldun-tech/grpc-rest-benchmark/pkg/bench"
+	"github.com/kaldun-tech/grpc-rest-benchmark/pkg/compression"
+	"github.com/kaldun-tech/grpc-rest-benchmark/pkg/db"
+	"github.com/kaldun-tech/grpc-rest-benchmark/pkg/jsoncodec"
+	"github.com/kaldun-tech/grpc-rest-benchmark/pkg/payload"
+	"github.com/kaldun-tech/grpc-rest-benchmark/pkg/profiling"
+	"github.com/kaldun-tech/grpc-rest-benchmark/pkg/qos"
+	"github.com/kaldun-tech/grpc-rest-benchmark/pkg/restapi"
+	"github.com/kaldun-tech/grpc-rest-benchmark/pkg/restart"
+	"github.com/kaldun-tech/grpc-rest-benchmark/pkg/serverlog"
+	"github.com/kaldun-tech/grpc-rest-benchmark/pkg/workload"
+)
+
+// runOptions holds flags for the run subcommand.
+type runOptions struct {
+	scenario    string
+	protocol    string
+	concurrency int
+	duration    time.Duration
+	rate        int
+	maxSamples  int
+
+	// Summary output: latency percentiles (e.g. "p99.9") and histogram
+	percentiles []string
+	histogram   bool
+
+	// Prices the run's resource usage is costed at
+	cost bench.CostModel
+
+	// Stream latency definition for the primary latency columns
+	streamMetric string
+
+	// Stream subscribers alongside the balance workers (stream-balance
+	// scenario) and the events/s each requests
+	subscribers int
+	streamRate  int
+
+	// Streams each stream scenario worker owns
+	streamsPerWorker int
+
+	// Fraction of stream subscribers that must establish their stream for
+	// the run to succeed
+	minStreams float64
+
+	// Verify that all stream subscribers receive the same ordered events
+	checkOrdering bool
+
+	// Stream scenario streams follow live transactions instead of
+	// replaying the table
+	live bool
+
+	// Reopen failed replay streams after the last transaction received,
+	// and cut them every chaosDisconnect to measure reconnection
+	reconnect       bool
+	chaosDisconnect time.Duration
+
+	// Restart the server this long into the run, keeping it down for
+	// restartDowntime, to measure the error burst and recovery time
+	chaosRestart    time.Duration
+	restartDowntime time.Duration
+
+	// Record the age of each returned balance (balance scenario)
+	staleness bool
+
+	// Correct balance latency percentiles for coordinated omission
+	correctOmission bool
+
+	// Echo scenario response size, e.g. "1KB"
+	payloadSize string
+
+	// Deadline for each unary request, 0 for none
+	requestTimeout time.Duration
+
+	// Back off after requests the server rate limited
+	backoff bool
+
+	// CPU time spent consuming each response or event, 0 for none
+	processCost time.Duration
+
+	// Write scenario fraction of requests that submit a transaction
+	writeRatio float64
+
+	// Mixed scenario without a workload file: fraction of requests that
+	// read, fraction of reads that are batch reads, and accounts per batch
+	readRatio  float64
+	batchRatio float64
+	batchSize  int
+
+	// Connect protocol codec
+	connectEncoding string
+
+	// REST body encoding: json or proto
+	restEncoding string
+
+	// Shape of REST JSON bodies: idiomatic, or parity with the protobuf
+	// messages
+	restShape string
+
+	// Framing of REST JSON streams, one of restapi.StreamFormats
+	restStream string
+
+	// JSON encoder of the REST client, one of jsoncodec.Names
+	jsonEncoder string
+
+	// Message compression: none, gzip, deflate or zstd
+	compression string
+
+	// Connection establishment and reuse
+	conn bench.ConnOptions
+
+	// Headers added to REST requests and metadata added to the other
+	// protocols' calls, as name=value pairs
+	headers  []string
+	metadata []string
+
+	// Addresses of worker agents that generate the load instead of this
+	// process
+	workers []string
+
+	// Registered agents selected with --agents, whose addresses are the
+	// workers
+	agents []*db.Agent
+
+	// Bundle the run reproduces (--from-bundle); run only
+	bundle *runBundle
+
+	// QoS mode the servers were started with; recorded, not applied
+	serverQoS string
+
+	// Database target the server is switched to before the run, empty to
+	// leave it as is
+	dbTarget string
+
+	// Target environment locked for the run, derived from the server
+	// addresses if empty; allowConcurrent runs even if another run holds it.
+	// registeredEnvironment is set when the name was found in the
+	// environment registry and supplied the addresses.
+	environment           string
+	allowConcurrent       bool
+	registeredEnvironment bool
+
+	// key=value tags recorded with the run, e.g. "team=payments"
+	tags []string
+
+	// Server log files or "docker:CONTAINER" sources whose errors and
+	// warnings during the run are counted
+	serverLogs []string
+
+	// pprof profiles of the server and this process: CPU over the run,
+	// heap after it. They are written to profileDir, if set, and stored
+	// with the run with attachProfiles.
+	profileCPU     bool
+	profileMem     bool
+	profileDir     string
+	attachProfiles bool
+
+	// Step or ramp load profile, replacing duration; unary scenarios only
+	loadProfile       string
+	loadProfileTarget string
+
+	// Part of the run the stats cover, "" for all of it
+	measureWindow string
+
+	// Per-run log file
+	logDir      string
+	logInterval time.Duration
+
+	// Parquet file samples are flushed to as they are collected, "" for none
+	sampleFlush string
+
+	// Runs without the results database: account IDs come from a file or
+	// are synthesized, and results are written as JSON to resultsDir
+	noDB              bool
+	accountIDsFile    string
+	syntheticAccounts int
+	resultsDir        string
+
+	// File the ID of each stored run is appended to, e.g. for the launch
+	// API to link the runs it started
+	runIDsFile string
+
+	// Timing replay flags (Phase 2d)
+	replayTiming  string
+	replayMode    string
+	replaySpeedup float64
+
+	// HCS fetch flags (hcsreplay integration)
+	hcsTopic    string
+	hcsNetwork  string
+	hcsLimit    int
+	hcsSavePath string
+
+	// Workload file; run only. Each stage becomes a run whose settings
+	// are filled in below by stageOptions.
+	workloadPath string
+	workload     string // workload name, empty for flag-driven runs
+	stage        string
+	mix          []bench.MixOperation // operation mix when scenario is "mixed"
+	poisson      bool
+	accounts     workload.Accounts
+
+	// Suite file; run only. Each combination of its matrix becomes a run
+	// built by suiteOptions.
+	configPath string
+}
+
+// workloadFlags are the run flags a workload file replaces.
+var workloadFlags = []string{
+	"scenario", "protocol", "concurrency", "duration", "rate", "payload-size",
+	"replay-timing", "replay-mode", "replay-speedup", "hcs-topic",
+	"load-profile", "load-profile-target",
+}
+
+// suiteFlags are the run flags a suite file replaces.
+var suiteFlags = []string{"scenario", "protocol", "concurrency", "duration", "rate", "workload"}
+
+// addRunFlags registers the benchmark flags shared by run and compare, i.e.
+// everything except the protocol selection.
+func addRunFlags(cmd *cobra.Command, opts *runOptions) {
+	f := cmd.Flags()
+	f.StringVar(&opts.scenario, "scenario", "balance", "Benchmark scenario: "+strings.Join(bench.Scenarios, " | "))
+	f.IntVar(&opts.concurrency, "concurrency", 10, "Number of parallel workers")
+	f.DurationVar(&opts.duration, "duration", 30*time.Second, "Test duration (e.g., 30s, 1m)")
+	f.IntVar(&opts.rate, "rate", 0, "Target rate: events/s per stream, or total requests/s for balance, echo and write (0 = unlimited)")
+	f.StringVar(&opts.streamMetric, "stream-metric", "", "Stream latency recorded as the primary latency: "+strings.Join(bench.StreamMetrics, " | ")+" (default end-to-end for live streams, delivery otherwise)")
+	f.IntVar(&opts.subscribers, "subscribers", 4, "Stream subscribers running alongside the balance workers (stream-balance scenario), or following the submitted transactions (fanout scenario)")
+	f.IntVar(&opts.streamsPerWorker, "streams-per-worker", 1, "Concurrent streams each of the --concurrency workers owns in the stream scenario, as in an async client")
+	f.IntVar(&opts.streamRate, "stream-rate", 0, "Events/s per stream subscriber in the stream-balance scenario (0 = unlimited)")
+	f.Float64Var(&opts.minStreams, "min-streams", 1, "Fraction of stream subscribers that must receive an event, 0 to 1; the run fails with fewer streams established")
+	f.BoolVar(&opts.live, "live", false, "Stream scenario: follow transactions as they are stored instead of replaying the table, so streams last the whole run (needs server --feed-rate or concurrent writes; no --rate)")
+	f.BoolVar(&opts.reconnect, "reconnect", false, "Stream or stream-balance scenario: reopen a failed replay stream after the last transaction received (Last-Event-ID for SSE) and report the time disconnected")
+	f.DurationVar(&opts.chaosDisconnect, "chaos-disconnect", 0, "Cut every replay stream this long after it was opened and reconnect it, to benchmark reconnection cost (implies --reconnect; 0 = never)")
+	f.DurationVar(&opts.chaosRestart, "chaos-restart-server", 0, "Restart the server gracefully this long into the run through its admin endpoint, to measure the error burst and recovery time (0 = never)")
+	f.DurationVar(&opts.restartDowntime, "chaos-restart-downtime", time.Second, "How long the restarted server stays down once drained")
+	f.BoolVar(&opts.checkOrdering, "check-ordering", false, "Verify that every stream subscriber receives the same events in the same order (stream or stream-balance scenario, at least 2 subscribers)")
+	f.BoolVar(&opts.staleness, "staleness", false, "Record the age of each returned balance (balance or stream-balance scenario; meaningful while balances are being updated)")
+	f.BoolVar(&opts.correctOmission, "correct-omission", false, "Also report balance or echo latency percentiles corrected for coordinated omission (requires --rate)")
+	f.StringVar(&opts.payloadSize, "payload-size", "1KB", "Echo scenario response size, 0 to "+payload.FormatSize(payload.MaxSize)+" (e.g., 100B, 64KB, 1MB)")
+	f.Float64Var(&opts.writeRatio, "write-ratio", 1, "Fraction of write scenario requests that submit a transaction, 0 to 1; the rest query balances")
+	f.Float64Var(&opts.readRatio, "read-ratio", 0.9, "Fraction of mixed scenario requests that read balances, 0 to 1; the rest submit transactions")
+	f.Float64Var(&opts.batchRatio, "batch-ratio", 0, "Fraction of mixed scenario reads that query --batch-size balances at once, 0 to 1")
+	f.IntVar(&opts.batchSize, "batch-size", workload.DefaultBatchSize, "Accounts per batch read in the mixed scenario")
+	f.DurationVar(&opts.requestTimeout, "request-timeout", 0, "Deadline for each unary request; slower requests fail as timeouts (0 = none)")
+	f.BoolVar(&opts.backoff, "backoff", false, "Back off exponentially, with jitter, after requests the server rate limits with 429 or RESOURCE_EXHAUSTED")
+	f.DurationVar(&opts.processCost, "process-cost", 0, "CPU time the client burns consuming each response or stream event, modeling application work (e.g., 200us; 0 = none)")
+	f.IntVar(&opts.maxSamples, "max-stored-samples", 1_000_000, "Maximum raw samples kept for storage; stats still cover every request (0 = unlimited)")
+	f.StringSliceVar(&opts.percentiles, "percentiles", []string{"p50", "p90", "p99", "p99.9"}, "Latency percentiles printed in the summary (e.g., p50,p99,p99.9,p99.99)")
+	f.BoolVar(&opts.histogram, "histogram", true, "Print a log-scaled latency histogram in the summary")
+	f.Float64Var(&opts.cost.CPUHour, "cost-cpu-hour", 0, "Price of one CPU-hour of client CPU time, for the run's cost estimate (0 = not priced)")
+	f.Float64Var(&opts.cost.GB, "cost-per-gb", 0, "Price of one GB transferred on the wire, for the run's cost estimate (0 = not priced)")
+
+	f.StringVar(&opts.connectEncoding, "connect-encoding", "proto", "Connect codec: "+strings.Join(bench.ConnectEncodings, " | "))
+	f.StringVar(&opts.restEncoding, "rest-encoding", "json", "REST body encoding: "+strings.Join(bench.RESTEncodings, " | "))
+	f.StringVar(&opts.restShape, "rest-shape", "idiomatic", "REST JSON body shape: idiomatic bodies, or parity bodies with the protobuf messages' fields and names, encoded with protojson ("+strings.Join(bench.RESTShapes, " | ")+")")
+	f.StringVar(&opts.restStream, "rest-stream-format", restapi.StreamSSE, "Framing of REST JSON streams: Server-Sent Events, one JSON object per line, or a JSON array flushed element by element ("+strings.Join(restapi.StreamFormats, " | ")+")")
+	f.StringVar(&opts.jsonEncoder, "json-encoder", jsoncodec.Std, "REST and JSON-RPC client JSON encoder: "+strings.Join(jsoncodec.Names, " | "))
+	f.StringVar(&opts.compression, "compression", compression.None, "Message compression: "+strings.Join(compression.Names, " | "))
+
+	f.BoolVar(&opts.conn.DisableKeepAlive, "disable-keepalive", false, "Open a new connection for every request to measure cold-connection latency")
+	f.IntVar(&opts.conn.MaxConnsPerHost, "max-conns-per-host", 0, "Maximum connections to the server for rest, connect and grpc-web (0 = unlimited)")
+	f.IntVar(&opts.conn.GRPCConns, "grpc-conns", 1, "Number of gRPC connections the workers are spread over, each worker keeping to one")
+	f.DurationVar(&opts.conn.GRPCKeepaliveTime, "grpc-keepalive-time", 0, "Interval of gRPC keepalive pings on idle connections, at least 10s (0 = disabled)")
+	f.DurationVar(&opts.conn.GRPCKeepaliveTimeout, "grpc-keepalive-timeout", 0, "Time to wait for a gRPC keepalive ping ack before closing the connection (0 = 20s)")
+	f.BoolVar(&opts.conn.GRPCPermitWithoutStream, "grpc-keepalive-permit-without-stream", false, "Send gRPC keepalive pings even without active calls")
+	f.StringArrayVar(&opts.headers, "header", nil, "Header added to every REST, GraphQL and JSON-RPC request as name=value, e.g. to represent tracing or auth headers (repeatable)")
+	f.StringArrayVar(&opts.metadata, "metadata", nil, "Metadata added to every grpc, connect and grpc-web call as key=value (repeatable)")
+	f.StringVar(&opts.conn.CacheBust, "cache-bust", bench.CacheBustNone, "Make every request unique so intermediary caches pass it to the server: a _cb query parameter or an X-Cache-Bust header on REST requests, x-cache-bust metadata on the other protocols ("+strings.Join(bench.CacheBustModes, " | ")+")")
+
+	f.StringSliceVar(&opts.workers, "workers", nil, "Addresses of 'benchmark worker' agents that generate the load, each a share of --concurrency and --rate (e.g., host1:50070,host2:50070)")
+
+	f.StringVar(&opts.serverQoS, "server-qos", qos.ModeNone, "QoS mode the servers were started with (their --qos flag), recorded with the run: "+strings.Join(qos.Modes, " | "))
+	f.StringVar(&opts.dbTarget, "db-target", "", "Switch the server to this database target (its --db-target flag, or \"default\") before the run and record it (empty = leave as is)")
+	f.StringVar(&opts.environment, "environment", "", "Name of the target environment, locked so that no other run uses it at the same time; a registered environment (/api/v1/environments) also supplies the server addresses and is recorded with the run (default: the server addresses)")
+	f.BoolVar(&opts.allowConcurrent, "allow-concurrent", false, "Run even if another run holds the environment, recording the overlap with the run and as an event")
+	f.StringArrayVar(&opts.tags, "tag", nil, "Tag recorded with the run as key=value, e.g. ci=nightly (repeatable)")
+	f.StringArrayVar(&opts.serverLogs, "server-log", nil, "Server log to count errors and warnings in during the run: a file, or docker:CONTAINER for a container's output (repeatable)")
+	f.BoolVar(&opts.profileCPU, "profile-cpu", false, "Capture pprof CPU profiles of the server and this client over the run")
+	f.BoolVar(&opts.profileMem, "profile-mem", false, "Capture pprof heap profiles of the server and this client after the run")
+	f.StringVar(&opts.profileDir, "profile-dir", "profiles", "Directory the profiles are written to, named after the run ID (empty = not written)")
+	f.BoolVar(&opts.attachProfiles, "attach-profiles", false, "Store the profiles with the run in PostgreSQL, served by /api/v1/results/{run_id}/profiles")
+
+	f.StringVar(&opts.loadProfile, "load-profile", "", "Vary load in phases instead of a fixed --duration (e.g., step:10,50,100,200@30s or ramp:0-500@2m)")
+	f.StringVar(&opts.loadProfileTarget, "load-profile-target", bench.ProfileTargetConcurrency, "What the load profile varies: "+strings.Join(bench.ProfileTargets, " | "))
+	f.StringVar(&opts.measureWindow, "measure-window", "", "Only measure requests issued in part of the run, leaving out ramp up and drain (e.g., 10s..50s, 10s.. or auto for the detected steady state; empty = all)")
+
+	f.StringVar(&opts.logDir, "log-dir", "logs", "Directory for per-run structured log files (empty = disabled)")
+	f.DurationVar(&opts.logInterval, "log-interval", 5*time.Second, "Interval between interim stats entries in the run log (0 = disabled); SIGUSR2 also logs the stats segment it ends")
+	f.StringVar(&opts.sampleFlush, "sample-flush", "", "Parquet file samples are written to as they are collected; SIGUSR2 completes it as <name>.<segment>.parquet and starts a new one (empty = disabled)")
+
+	f.BoolVar(&opts.noDB, "no-db", false, "Run without the results database, e.g. against a remote environment: account IDs come from --accounts-file or are synthesized, and results are written as JSON to --results-dir")
+	f.StringVar(&opts.accountIDsFile, "accounts-file", "", "File of account IDs to query, one per line, e.g. from 'benchmark dump-accounts' (default: loaded from the database, or --synthetic-accounts IDs with --no-db)")
+	f.IntVar(&opts.syntheticAccounts, "synthetic-accounts", 10_000, "Account IDs synthesized with --no-db and no --accounts-file, from 0.0.100000 up as seeded by 'make seed'")
+	f.StringVar(&opts.resultsDir, "results-dir", "results", "Directory the JSON results of --no-db runs are written to")
+	f.StringVar(&opts.runIDsFile, "run-ids-file", "", "File the ID of each stored run is appended to, one per line (empty = disabled)")
+
+	f.StringVar(&opts.replayTiming, "replay-timing", "", "Path to HCS timing JSON file for realistic workload replay")
+	f.StringVar(&opts.replayMode, "replay-mode", "sample", "Replay mode: sequential | sample")
+	f.Float64Var(&opts.replaySpeedup, "replay-speedup", 1.0, "Speedup factor for replay (1.0 = real-time, 10.0 = 10x faster)")
+
+	f.StringVar(&opts.hcsTopic, "hcs-topic", "", "HCS topic ID to fetch timing from (e.g., 0.0.120438)")
+	f.StringVar(&opts.hcsNetwork, "hcs-network", "mainnet", "Hedera network: mainnet | testnet | previewnet")
+	f.IntVar(&opts.hcsLimit, "hcs-limit", 1000, "Maximum number of HCS messages to fetch for timing")
+	f.StringVar(&opts.hcsSavePath, "hcs-save", "", "Path to save fetched HCS timing data for reuse")
+
+	cmd.RegisterFlagCompletionFunc("scenario", fixedCompletion(bench.Scenarios))
+	cmd.RegisterFlagCompletionFunc("server-qos", fixedCompletion(qos.Modes))
+	cmd.RegisterFlagCompletionFunc("stream-metric", fixedCompletion(bench.StreamMetrics))
+	cmd.RegisterFlagCompletionFunc("connect-encoding", fixedCompletion(bench.ConnectEncodings))
+	cmd.RegisterFlagCompletionFunc("rest-encoding", fixedCompletion(bench.RESTEncodings))
+	cmd.RegisterFlagCompletionFunc("rest-shape", fixedCompletion(bench.RESTShapes))
+	cmd.RegisterFlagCompletionFunc("rest-stream-format", fixedCompletion(restapi.StreamFormats))
+	cmd.RegisterFlagCompletionFunc("json-encoder", fixedCompletion(jsoncodec.Names))
+	cmd.RegisterFlagCompletionFunc("compression", fixedCompletion(compression.Names))
+	cmd.RegisterFlagCompletionFunc("cache-bust", fixedCompletion(bench.CacheBustModes))
+	cmd.RegisterFlagCompletionFunc("load-profile-target", fixedCompletion(bench.ProfileTargets))
+	cmd.MarkFlagsMutuallyExclusive("load-profile", "duration")
+	cmd.RegisterFlagCompletionFunc("replay-mode", fixedCompletion([]string{"sequential", "sample"}))
+	cmd.RegisterFlagCompletionFunc("hcs-network", fixedCompletion([]string{"mainnet", "testnet", "previewnet"}))
+	cmd.MarkFlagDirname("log-dir")
+	cmd.MarkFlagDirname("results-dir")
+	cmd.MarkFlagFilename("accounts-file")
+	cmd.MarkFlagFilename("run-ids-file")
+	cmd.MarkFlagFilename("replay-timing", "json")
+	cmd.MarkFlagFilename("hcs-save", "json")
+}
+
+// validate checks run flags for invalid values.
+func (o *runOptions) validate() error {
+	if !slices.Contains(bench.Scenarios, o.scenario) {
+		return fmt.Errorf("invalid scenario: %s (must be one of: %s)", o.scenario, strings.Join(bench.Scenarios, ", "))
+	}
+	if !slices.Contains(bench.Protocols, o.protocol) {
+		return fmt.Errorf("invalid protocol: %s (must be one of: %s)", o.protocol, strings.Join(bench.Protocols, ", "))
+	}
+	if o.streamMetric != "" && !slices.Contains(bench.StreamMetrics, o.streamMetric) {
+		return fmt.Errorf("invalid stream metric: %s (must be one of: %s)", o.streamMetric, strings.Join(bench.StreamMetrics, ", "))
+	}
+	if !slices.Contains(bench.ConnectEncodings, o.connectEncoding) {
+		return fmt.Errorf("invalid connect encoding: %s (must be one of: %s)", o.connectEncoding, strings.Join(bench.ConnectEncodings, ", "))
+	}
+	if !slices.Contains(bench.RESTEncodings, o.restEncoding) {
+		return fmt.Errorf("invalid rest encoding: %s (must be one of: %s)", o.restEncoding, strings.Join(bench.RESTEncodings, ", "))
+	}
+	if !slices.Contains(bench.RESTShapes, o.restShape) {
+		return fmt.Errorf("invalid rest shape: %s (must be one of: %s)", o.restShape, strings.Join(bench.RESTShapes, ", "))
+	}
+	if o.restStream != "" && !slices.Contains(restapi.StreamFormats, o.restStream) {
+		return fmt.Errorf("invalid rest stream format: %s (must be one of: %s)", o.restStream, strings.Join(restapi.StreamFormats, ", "))
+	}
+	if !slices.Contains(jsoncodec.Names, o.jsonEncoder) {
+		return fmt.Errorf("invalid json encoder: %s (must be one of: %s)", o.jsonEncoder, strings.Join(jsoncodec.Names, ", "))
+	}
+	if o.parity() && (o.protocol != "rest" || o.restEncoding != "json") {
+		return fmt.Errorf("rest-shape=parity only applies to REST JSON bodies")
+	}
+	if o.parity() && o.jsonEncoder != jsoncodec.Std {
+		return fmt.Errorf("json-encoder does not apply to parity bodies, which are encoded with protojson")
+	}
+	if !slices.Contains(compression.Names, o.compression) {
+		return fmt.Errorf("invalid compression: %s (must be one of: %s)", o.compression, strings.Join(compression.Names, ", "))
+	}
+	if o.compression != compression.None && o.protocol == "grpc-web" {
+		return fmt.Errorf("compression is not supported with grpc-web")
+	}
+	if o.protocol == "graphql" && slices.Contains([]string{"echo", "write", "fanout"}, o.scenario) {
+		return fmt.Errorf("the %s scenario is not supported with graphql, whose schema has no echo or transaction submission", o.scenario)
+	}
+	if o.conn.MaxConnsPerHost < 0 {
+		return fmt.Errorf("max-conns-per-host must not be negative")
+	}
+	if o.conn.GRPCConns < 0 {
+		return fmt.Errorf("grpc-conns must not be negative")
+	}
+	if o.conn.GRPCConns > 1 && o.conn.DisableKeepAlive {
+		return fmt.Errorf("grpc-conns has no effect with --disable-keepalive, every call opens its own connection")
+	}
+	if o.conn.CacheBust != "" && !slices.Contains(bench.CacheBustModes, o.conn.CacheBust) {
+		return fmt.Errorf("invalid cache bust mode: %s (must be one of: %s)", o.conn.CacheBust, strings.Join(bench.CacheBustModes, ", "))
+	}
+	if o.serverQoS != "" && !slices.Contains(qos.Modes, o.serverQoS) {
+		return fmt.Errorf("invalid server qos: %s (must be one of: %s)", o.serverQoS, strings.Join(qos.Modes, ", "))
+	}
+	if o.conn.GRPCKeepaliveTime < 0 || o.conn.GRPCKeepaliveTimeout < 0 {
+		return fmt.Errorf("gRPC keepalive durations must not be negative")
+	}
+	if o.conn.GRPCKeepaliveTime == 0 && (o.conn.GRPCKeepaliveTimeout > 0 || o.conn.GRPCPermitWithoutStream) {
+		return fmt.Errorf("gRPC keepalive settings require --grpc-keepalive-time")
+	}
+	if o.scenario == "stream-balance" {
+		if o.subscribers < 1 {
+			return fmt.Errorf("stream-balance needs at least 1 subscriber")
+		}
+		if o.streamRate < 0 {
+			return fmt.Errorf("stream-rate must not be negative")
+		}
+	}
+	if o.scenario == "fanout" && (o.subscribers < 1 || o.rate < 1) {
+		return fmt.Errorf("fanout needs at least 1 subscriber and a --rate of submitted transactions")
+	}
+	if o.scenario == "reference" && (len(o.workers) > 0 || o.dbTarget != "" || len(o.serverLogs) > 0) {
+		return fmt.Errorf("the reference scenario runs against an in-process stub, without workers, a database target or server logs")
+	}
+	if o.attachProfiles && !o.capturesProfiles() {
+		return fmt.Errorf("--attach-profiles needs --profile-cpu or --profile-mem")
+	}
+	if o.capturesProfiles() && o.profileDir == "" && !o.attachProfiles {
+		return fmt.Errorf("profiles are neither written (--profile-dir is empty) nor attached (--attach-profiles)")
+	}
+	if o.profileCPU && o.sizing().RunDuration() > profiling.MaxDuration {
+		return fmt.Errorf("--profile-cpu covers runs of at most %s", profiling.MaxDuration)
+	}
+	if o.streamsPerWorker < 0 {
+		return fmt.Errorf("streams-per-worker must not be negative")
+	}
+	if o.minStreams < 0 || o.minStreams > 1 {
+		return fmt.Errorf("min-streams must be between 0 and 1")
+	}
+	if o.streamsPerWorker > 1 && o.scenario != "stream" {
+		return fmt.Errorf("streams-per-worker only applies to the stream scenario")
+	}
+	if o.live && (o.scenario != "stream" || o.rate > 0) {
+		return fmt.Errorf("live only applies to the stream scenario, and its streams are not rate limited")
+	}
+	if o.chaosDisconnect < 0 {
+		return fmt.Errorf("chaos-disconnect must not be negative")
+	}
+	if o.chaosRestart < 0 || (o.chaosRestart > 0 && o.chaosRestart >= o.sizing().RunDuration()) {
+		return fmt.Errorf("chaos-restart-server must be within the run")
+	}
+	if o.restartDowntime < 0 || o.restartDowntime > restart.MaxDowntime {
+		return fmt.Errorf("chaos-restart-downtime must be between 0 and %s", restart.MaxDowntime)
+	}
+	if o.chaosRestart > 0 && o.scenario == "reference" {
+		return fmt.Errorf("chaos-restart-server needs a server, not the reference stub")
+	}
+	if o.reconnects() {
+		if (o.scenario != "stream" && o.scenario != "stream-balance") || o.live {
+			return fmt.Errorf("reconnect and chaos-disconnect resume replay streams, they require the stream or stream-balance scenario without --live")
+		}
+		if o.protocol == "grpc-web" {
+			return fmt.Errorf("reconnect is not supported with grpc-web")
+		}
+	}
+	if o.checkOrdering && o.sizing().StreamSubscribers() < 2 {
+		return fmt.Errorf("check-ordering compares stream subscribers, it requires the stream or stream-balance scenario and at least 2 subscribers")
+	}
+	if o.staleness && !o.queriesBalances() {
+		return fmt.Errorf("staleness is only measured in the balance and stream-balance scenarios")
+	}
+	if o.correctOmission && (o.sizing().StreamSubscribers() > 0 || o.rate <= 0) {
+		return fmt.Errorf("correct-omission requires a unary scenario and a target --rate")
+	}
+	if o.loadProfile != "" {
+		if err := o.validateLoadProfile(); err != nil {
+			return err
+		}
+	}
+	if o.measureWindow != "" {
+		if err := o.validateMeasureWindow(); err != nil {
+			return err
+		}
+	}
+	if o.poisson && (o.scenario == "stream" || (o.rate <= 0 && o.profileTarget() != bench.ProfileTargetRate)) {
+		return fmt.Errorf("poisson arrivals require a unary scenario and a target rate")
+	}
+	if o.scenario != "stream" && o.rate > 0 && (o.replayTiming != "" || o.hcsTopic != "") {
+		return fmt.Errorf("--rate and timing replay cannot be combined in the %s scenario", o.scenario)
+	}
+	if o.scenario == "echo" {
+		if _, err := payload.ParseSize(o.payloadSize); err != nil {
+			return err
+		}
+	}
+	if o.scenario == "write" && (o.writeRatio < 0 || o.writeRatio > 1) {
+		return fmt.Errorf("write-ratio must be between 0 and 1")
+	}
+	if o.scenario == "mixed" && len(o.mix) == 0 {
+		if o.readRatio < 0 || o.readRatio > 1 || o.batchRatio < 0 || o.batchRatio > 1 {
+			return fmt.Errorf("read-ratio and batch-ratio must be between 0 and 1")
+		}
+		if o.batchSize < 1 || o.batchSize > maxBatchSize {
+			return fmt.Errorf("batch-size must be between 1 and %d", maxBatchSize)
+		}
+	}
+	if o.requestTimeout < 0 {
+		return fmt.Errorf("request-timeout must not be negative")
+	}
+	if o.processCost < 0 {
+		return fmt.Errorf("process-cost must not be negative")
+	}
+	if o.concurrency < 1 {
+		return fmt.Errorf("concurrency must be at least 1")
+	}
+	if len(o.workers) > 0 {
+		if err := o.validateWorkers(); err != nil {
+			return err
+		}
+	}
+	if _, err := o.tagsLabel(); err != nil {
+		return err
+	}
+	if _, err := bench.ParseHeaders(o.headers); err != nil {
+		return err
+	}
+	if _, err := bench.ParseMetadata(o.metadata); err != nil {
+		return err
+	}
+	if _, err := bench.ParsePercentiles(o.percentiles); err != nil {
+		return err
+	}
+	if o.maxSamples < 0 {
+		return fmt.Errorf("max-stored-samples must not be negative")
+	}
+	if err := o.cost.Validate(); err != nil {
+		return err
+	}
+	if o.sizing().RunDuration() < time.Second {
+		return fmt.Errorf("duration must be at least 1 second")
+	}
+	if _, err := o.serverLogSources(); err != nil {
+		return err
+	}
+	if o.noDB && o.syntheticAccounts < 1 {
+		return fmt.Errorf("synthetic-accounts must be at least 1")
+	}
+	return nil
+}
+
+// validateMeasureWindow checks the measure window against the run.
+func (o *runOptions) validateMeasureWindow() error {
+	w, err := bench.ParseMeasureWindow(o.measureWindow)
+	if err != nil {
+		return err
+	}
+	if o.loadProfile != "" {
+		return fmt.Errorf("--measure-window cannot be combined with a load profile")
+	}
+	if !w.Auto && (w.From >= o.duration || w.To > o.duration) {
+		return fmt.Errorf("measure window %s does not fit in the %s run", w, o.duration)
+	}
+	return nil
+}
+
+// validateLoadProfile checks the load profile flags and the settings they
+// cannot be combined with.
+func (o *runOptions) validateLoadProfile() error {
+	if !slices.Contains(bench.ProfileTargets, o.loadProfileTarget) {
+		return fmt.Errorf("invalid load profile target: %s (must be one of: %s)", o.loadProfileTarget, strings.Join(bench.ProfileTargets, ", "))
+	}
+	if _, err := bench.ParseLoadProfile(o.loadProfile, o.loadProfileTarget); err != nil {
+		return err
+	}
+	if o.sizing().StreamSubscribers() > 0 {
+		return fmt.Errorf("load profiles are only supported in unary scenarios")
+	}
+	if o.correctOmission {
+		return fmt.Errorf("correct-omission cannot be combined with a load profile")
+	}
+	if o.loadProfileTarget == bench.ProfileTargetRate {
+		if o.rate > 0 {
+			return fmt.Errorf("--rate and a rate load profile cannot be combined")
+		}
+		if o.replayTiming != "" || o.hcsTopic != "" {
+			return fmt.Errorf("a rate load profile and timing replay cannot be combined")
+		}
+	}
+	return nil
+}
+
+// validateWorkers checks that the run can be split between its workers,
+// each taking at least one unit of concurrency and rate.
+func (o *runOptions) validateWorkers() error {
+	n := len(o.workers)
+	if o.replayTiming != "" || o.hcsTopic != "" {
+		return fmt.Errorf("timing replay cannot be combined with --workers")
+	}
+	if o.checkOrdering {
+		return fmt.Errorf("check-ordering compares subscribers in one process, it cannot be combined with --workers")
+	}
+	if o.concurrency < n || ((o.scenario == "stream-balance" || o.scenario == "fanout") && o.subscribers < n) {
+		return fmt.Errorf("concurrency and subscribers must be at least the number of workers (%d)", n)
+	}
+	if o.rate > 0 && o.rate < n {
+		return fmt.Errorf("rate must be at least the number of workers (%d)", n)
+	}
+	for _, a := range o.agents {
+		if len(a.Protocols) > 0 && !slices.Contains(a.Protocols, o.protocol) {
+			return fmt.Errorf("agent %s does not support protocol %s (supports: %s)", a.Name, o.protocol, strings.Join(a.Protocols, ", "))
+		}
+	}
+	return nil
+}
+
+// sizing returns the fields of the run's bench.Config that size it, its
+// scenario, load and duration, for the checks and labels that need its
+// bench.Config.RunDuration or StreamSubscribers before it is configured.
+func (o *runOptions) sizing() *bench.Config {
+	return &bench.Config{
+		Scenario:         o.scenario,
+		Concurrency:      o.concurrency,
+		Duration:         o.duration,
+		LoadProfile:      o.profile(),
+		Subscribers:      o.subscribers,
+		StreamsPerWorker: o.streamsPerWorker,
+	}
+}
+
+// primaryStreamMetric returns the stream latency recorded as the primary
+// latency: --stream-metric, by default end-to-end for live streams, whose
+// events carry when they were stored, and delivery otherwise.
+func (o *runOptions) primaryStreamMetric() string {
+	switch {
+	case o.streamMetric != "":
+		return o.streamMetric
+	case o.liveStreams():
+		return bench.StreamMetricEndToEnd
+	}
+	return bench.StreamMetricDelivery
+}
+
+// reconnects reports whether failed streams are reopened: --reconnect, or
+// --chaos-disconnect, which implies it.
+func (o *runOptions) reconnects() bool {
+	return o.reconnect || o.chaosDisconnect > 0
+}
+
+// chaosRestartLabel describes the server restart of the run, e.g.
+// "at=30s,downtime=1s", or returns "" without one.
+func (o *runOptions) chaosRestartLabel() string {
+	if o.chaosRestart <= 0 {
+		return ""
+	}
+	return fmt.Sprintf("at=%s,downtime=%s", o.chaosRestart, o.restartDowntime)
+}
+
+// liveStreams reports whether the run's streams follow live transactions:
+// the fanout scenario, and the stream scenario with --live.
+func (o *runOptions) liveStreams() bool {
+	return o.scenario == "fanout" || (o.scenario == "stream" && o.live)
+}
+
+// queriesBalances reports whether the run issues balance queries directly,
+// and so needs the seeded account IDs.
+func (o *runOptions) queriesBalances() bool {
+	return o.scenario == "balance" || o.scenario == "stream-balance"
+}
+
+// needsAccounts reports whether the run picks accounts from the seeded
+// account IDs: for balance queries, or as the parties of submitted
+// transactions.
+func (o *runOptions) needsAccounts() bool {
+	return o.queriesBalances() || o.scenario == "write" || o.scenario == "mixed" || o.scenario == "fanout"
+}
+
+// maxBatchSize caps --batch-size, keeping REST batch reads within a URL.
+const maxBatchSize = 1000
+
+// mixWeightScale is the total weight of a mix built from the ratio flags,
+// so weights are in tenths of a percent.
+const mixWeightScale = 1000
+
+// operationMix returns the operations of a mixed run: the workload stage's
+// mix, or else balance reads, batch reads and writes weighted by
+// --read-ratio and --batch-ratio. Operations with no share are left out.
+func (o *runOptions) operationMix() []bench.MixOperation {
+	if len(o.mix) > 0 {
+		return o.mix
+	}
+	reads := int(math.Round(o.readRatio * mixWeightScale))
+	batch := int(math.Round(o.readRatio * o.batchRatio * mixWeightScale))
+	var ops []bench.MixOperation
+	for _, op := range []bench.MixOperation{
+		{Scenario: "balance", Weight: reads - batch},
+		{Scenario: "batch", Weight: batch, BatchSize: o.batchSize},
+		{Scenario: "write", Weight: mixWeightScale - reads},
+	} {
+		if op.Weight > 0 {
+			ops = append(ops, op)
+		}
+	}
+	return ops
+}
+
+// serverLogSources parses the --server-log sources.
+func (o *runOptions) serverLogSources() ([]serverlog.Source, error) {
+	sources := make([]serverlog.Source, len(o.serverLogs))
+	for i, s := range o.serverLogs {
+		src, err := serverlog.ParseSource(s)
+		if err != nil {
+			return nil, err
+		}
+		sources[i] = src
+	}
+	return sources, nil
+}
+
+// profile returns the parsed load profile, or nil for a fixed load. The
+// profile has already been checked by validate.
+func (o *runOptions) profile() *bench.LoadProfile {
+	if o.loadProfile == "" {
+		return nil
+	}
+	p, _ := bench.ParseLoadProfile(o.loadProfile, o.loadProfileTarget)
+	return p
+}
+
+// window returns the parsed measure window, or nil for the whole run. The
+// window has already been checked by validate.
+func (o *runOptions) window() *bench.MeasureWindow {
+	if o.measureWindow == "" {
+		return nil
+	}
+	w, _ := bench.ParseMeasureWindow(o.measureWindow)
+	return w
+}
+
+// profileTarget returns what the load profile varies, or "" for a fixed load.
+func (o *runOptions) profileTarget() string {
+	if o.loadProfile == "" {
+		return ""
+	}
+	return o.loadProfileTarget
+}
+
+// payloadBytes returns the echo payload size in bytes. The size has already
+// been checked by validate.
+func (o *runOptions) payloadBytes() int {
+	n, _ := payload.ParseSize(o.payloadSize)
+	return n
+}
+
+// percentileValues returns the summary percentiles. They have already been
+// checked by validate.
+func (o *runOptions) percentileValues() []float64 {
+	ps, _ := bench.ParsePercentiles(o.percentiles)
+	return ps
+}
@@ -0,0 +1,296 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kaldun-tech/grpc-rest-benchmark/pkg/bench"
+	"github.com/kaldun-tech/grpc-rest-benchmark/pkg/db"
+)
+
+func TestOperationMix(t *testing.T) {
+	tests := []struct {
+		readRatio, batchRatio float64
+		want                  string
+	}{
+		{0.9, 0, "balance:900 write:100"},
+		{0.9, 0.2, "balance:720 batch(20):180 write:100"},
+		{1, 1, "batch(20):1000"},
+		{0, 0.5, "write:1000"},
+	}
+	for _, tt := range tests {
+		o := &runOptions{scenario: "mixed", readRatio: tt.readRatio, batchRatio: tt.batchRatio, batchSize: 20}
+		if got := formatMix(o.operationMix()); got != tt.want {
+			t.Errorf("operationMix(%v, %v) = %q, want %q", tt.readRatio, tt.batchRatio, got, tt.want)
+		}
+	}
+
+	// A workload stage's mix takes precedence over the ratio flags
+	o := &runOptions{scenario: "mixed", readRatio: 0.5, mix: []bench.MixOperation{{Scenario: "balance", Weight: 1}}}
+	if got := formatMix(o.operationMix()); got != "balance:1" {
+		t.Errorf("operationMix() with a workload mix = %q, want balance:1", got)
+	}
+}
+
+func TestPrimaryStreamMetric(t *testing.T) {
+	tests := []struct {
+		scenario, metric string
+		live             bool
+		want             string
+	}{
+		{"stream", "", false, bench.StreamMetricDelivery},
+		{"stream", "", true, bench.StreamMetricEndToEnd},
+		{"stream", bench.StreamMetricDelivery, true, bench.StreamMetricDelivery},
+		{"fanout", "", false, bench.StreamMetricEndToEnd},
+		{"stream-balance", "", true, bench.StreamMetricDelivery},
+	}
+	for _, tt := range tests {
+		o := &runOptions{scenario: tt.scenario, streamMetric: tt.metric, live: tt.live}
+		if got := o.primaryStreamMetric(); got != tt.want {
+			t.Errorf("primaryStreamMetric(%s, %q, live=%v) = %s, want %s", tt.scenario, tt.metric, tt.live, got, tt.want)
+		}
+	}
+}
+
+func TestRunOptions_ValidateLoadProfile(t *testing.T) {
+	base := runOptions{
+		scenario:          "balance",
+		protocol:          "grpc",
+		concurrency:       1,
+		duration:          time.Second,
+		streamMetric:      bench.StreamMetricInterArrival,
+		connectEncoding:   "proto",
+		restEncoding:      "json",
+		restShape:         "idiomatic",
+		jsonEncoder:       "std",
+		compression:       "none",
+		loadProfile:       "step:1,2@5s",
+		loadProfileTarget: bench.ProfileTargetConcurrency,
+	}
+
+	tests := []struct {
+		name    string
+		modify  func(o *runOptions)
+		wantErr bool
+	}{
+		{"concurrency profile", func(o *runOptions) {}, false},
+		{"rate profile with poisson", func(o *runOptions) { o.loadProfileTarget = bench.ProfileTargetRate; o.poisson = true }, false},
+		{"bad target", func(o *runOptions) { o.loadProfileTarget = "workers" }, true},
+		{"bad spec", func(o *runOptions) { o.loadProfile = "step:1,2" }, true},
+		{"stream", func(o *runOptions) { o.scenario = "stream" }, true},
+		{"correct omission", func(o *runOptions) { o.correctOmission = true; o.rate = 100 }, true},
+		{"rate profile and rate", func(o *runOptions) { o.loadProfileTarget = bench.ProfileTargetRate; o.rate = 100 }, true},
+		{"rate profile and replay", func(o *runOptions) { o.loadProfileTarget = bench.ProfileTargetRate; o.replayTiming = "t.json" }, true},
+		{"measure window", func(o *runOptions) { o.measureWindow = "auto" }, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := base
+			tt.modify(&opts)
+			if err := opts.validate(); (err != nil) != tt.wantErr {
+				t.Errorf("validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	opts := base
+	if got := opts.sizing().RunDuration(); got != 10*time.Second {
+		t.Errorf("sizing().RunDuration() = %v, want the profile's 10s", got)
+	}
+}
+
+func TestRunOptions_ValidateMeasureWindow(t *testing.T) {
+	base := runOptions{
+		scenario:        "balance",
+		protocol:        "grpc",
+		concurrency:     1,
+		duration:        time.Minute,
+		streamMetric:    bench.StreamMetricInterArrival,
+		connectEncoding: "proto",
+		restEncoding:    "json",
+		restShape:       "idiomatic",
+		jsonEncoder:     "std",
+		compression:     "none",
+	}
+
+	for _, tt := range []struct {
+		window  string
+		wantErr bool
+	}{
+		{"10s..50s", false},
+		{"10s..", false},
+		{"auto", false},
+		{"10s..2m", true},
+		{"1m..", true},
+		{"50s..10s", true},
+		{"10%", true},
+	} {
+		opts := base
+		opts.measureWindow = tt.window
+		if err := opts.validate(); (err != nil) != tt.wantErr {
+			t.Errorf("validate(%q) error = %v, wantErr %v", tt.window, err, tt.wantErr)
+		}
+	}
+}
+
+func TestRunOptions_ValidateReconnect(t *testing.T) {
+	base := runOptions{
+		scenario:        "stream",
+		protocol:        "rest",
+		concurrency:     1,
+		duration:        time.Minute,
+		streamMetric:    bench.StreamMetricInterArrival,
+		connectEncoding: "proto",
+		restEncoding:    "json",
+		restShape:       "idiomatic",
+		jsonEncoder:     "std",
+		compression:     "none",
+		reconnect:       true,
+	}
+
+	tests := []struct {
+		name    string
+		modify  func(o *runOptions)
+		wantErr bool
+	}{
+		{"replay stream", func(o *runOptions) {}, false},
+		{"chaos disconnect", func(o *runOptions) { o.reconnect = false; o.chaosDisconnect = 5 * time.Second }, false},
+		{"stream-balance", func(o *runOptions) { o.scenario = "stream-balance"; o.subscribers = 2 }, false},
+		{"negative chaos disconnect", func(o *runOptions) { o.chaosDisconnect = -time.Second }, true},
+		{"live stream", func(o *runOptions) { o.live = true }, true},
+		{"balance", func(o *runOptions) { o.scenario = "balance" }, true},
+		{"grpc-web", func(o *runOptions) { o.protocol = "grpc-web" }, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := base
+			tt.modify(&opts)
+			if err := opts.validate(); (err != nil) != tt.wantErr {
+				t.Errorf("validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRunOptions_ValidateChaosRestart(t *testing.T) {
+	base := runOptions{
+		scenario:        "balance",
+		protocol:        "grpc",
+		concurrency:     1,
+		duration:        time.Minute,
+		streamMetric:    bench.StreamMetricInterArrival,
+		connectEncoding: "proto",
+		restEncoding:    "json",
+		restShape:       "idiomatic",
+		jsonEncoder:     "std",
+		compression:     "none",
+		chaosRestart:    30 * time.Second,
+		restartDowntime: time.Second,
+	}
+
+	tests := []struct {
+		name    string
+		modify  func(o *runOptions)
+		wantErr bool
+	}{
+		{"within the run", func(o *runOptions) {}, false},
+		{"no downtime", func(o *runOptions) { o.restartDowntime = 0 }, false},
+		{"at the end of the run", func(o *runOptions) { o.chaosRestart = time.Minute }, true},
+		{"negative", func(o *runOptions) { o.chaosRestart = -time.Second }, true},
+		{"downtime too long", func(o *runOptions) { o.restartDowntime = time.Hour }, true},
+		{"reference stub", func(o *runOptions) { o.scenario = "reference" }, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := base
+			tt.modify(&opts)
+			if err := opts.validate(); (err != nil) != tt.wantErr {
+				t.Errorf("validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRunOptions_ValidateRESTShape(t *testing.T) {
+	base := runOptions{
+		scenario:        "balance",
+		protocol:        "rest",
+		concurrency:     1,
+		duration:        time.Minute,
+		streamMetric:    bench.StreamMetricInterArrival,
+		connectEncoding: "proto",
+		restEncoding:    "json",
+		restShape:       "parity",
+		jsonEncoder:     "std",
+		compression:     "none",
+	}
+
+	tests := []struct {
+		name    string
+		modify  func(o *runOptions)
+		wantErr bool
+	}{
+		{"parity", func(o *runOptions) {}, false},
+		{"unknown shape", func(o *runOptions) { o.restShape = "camel" }, true},
+		{"protobuf bodies", func(o *runOptions) { o.restEncoding = "proto" }, true},
+		{"grpc", func(o *runOptions) { o.protocol = "grpc" }, true},
+		{"json encoder", func(o *runOptions) { o.jsonEncoder = "sonic" }, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := base
+			tt.modify(&opts)
+			if err := opts.validate(); (err != nil) != tt.wantErr {
+				t.Errorf("validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRunOptions_ValidateWorkers(t *testing.T) {
+	base := runOptions{
+		scenario:        "balance",
+		protocol:        "grpc",
+		concurrency:     4,
+		duration:        time.Second,
+		streamMetric:    bench.StreamMetricInterArrival,
+		connectEncoding: "proto",
+		restEncoding:    "json",
+		restShape:       "idiomatic",
+		jsonEncoder:     "std",
+		compression:     "none",
+		workers:         []string{"gen1:50070", "gen2:50070"},
+	}
+
+	tests := []struct {
+		name    string
+		modify  func(o *runOptions)
+		wantErr bool
+	}{
+		{"split load", func(o *runOptions) { o.rate = 2 }, false},
+		{"concurrency below workers", func(o *runOptions) { o.concurrency = 1 }, true},
+		{"rate below workers", func(o *runOptions) { o.rate = 1 }, true},
+		{"subscribers below workers", func(o *runOptions) { o.scenario = "stream-balance"; o.subscribers = 1 }, true},
+		{"timing replay", func(o *runOptions) { o.replayTiming = "t.json" }, true},
+		{"agents support protocol", func(o *runOptions) {
+			o.agents = []*db.Agent{{Name: "gen1", Protocols: []string{"grpc", "rest"}}, {Name: "gen2"}}
+		}, false},
+		{"agent lacks protocol", func(o *runOptions) {
+			o.agents = []*db.Agent{{Name: "gen1", Protocols: []string{"rest"}}, {Name: "gen2"}}
+		}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := base
+			tt.modify(&opts)
+			if err := opts.validate(); (err != nil) != tt.wantErr {
+				t.Errorf("validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
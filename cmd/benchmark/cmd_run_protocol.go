@@ -0,0 +1,268 @@
+package main
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"log"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/kaldun-tech/grpc-rest-benchmark/pkg/bench"
+	"github.com/kaldun-tech/grpc-rest-benchmark/pkg/db"
+	"github.com/kaldun-tech/grpc-rest-benchmark/pkg/jsoncodec"
+	"github.com/kaldun-tech/grpc-rest-benchmark/pkg/qos"
+	"github.com/kaldun-tech/grpc-rest-benchmark/pkg/restapi"
+	"github.com/kaldun-tech/grpc-rest-benchmark/pkg/timing"
+)
+
+// protocolLabel returns the protocol name recorded with the run. Connect runs
+// include the codec, and REST runs a protobuf encoding, so JSON and binary
+// results can be told apart.
+func (o *runOptions) protocolLabel() string {
+	switch {
+	case o.scenario == "reference":
+		return bench.ReferenceProtocol
+	case o.protocol == "connect":
+		return "connect-" + o.connectEncoding
+	case o.protocol == "rest" && o.restEncoding == "proto":
+		return "rest-proto"
+	case o.protocol == "rest" && o.parity():
+		return "rest-parity"
+	}
+	return o.protocol
+}
+
+// parity reports whether REST JSON bodies mirror the protobuf messages.
+func (o *runOptions) parity() bool {
+	return o.restShape == restapi.ParityShape
+}
+
+// restStreamLabel returns the framing of the REST JSON streams a run opens,
+// empty for other runs and for SSE, the default.
+func (o *runOptions) restStreamLabel() string {
+	if o.sizing().StreamSubscribers() == 0 || o.protocol != "rest" || o.restEncoding != "json" {
+		return ""
+	}
+	if o.restStream == restapi.StreamSSE {
+		return ""
+	}
+	return o.restStream
+}
+
+// jsonEncoderLabel returns the JSON encoders of the client and of the REST
+// server, recorded with the run (see jsoncodec.Label). Servers that did not
+// record one use encoding/json. Parity runs are decoded with protojson and
+// only labeled when the server encodes them from hand-written structs, e.g.
+// "client=protojson,server=sonic". It returns "" for runs without JSON
+// bodies encoded with the encoders: those other than REST JSON and
+// JSON-RPC.
+func (o *runOptions) jsonEncoderLabel(server db.ServerConfig) string {
+	serverEncoder := cmp.Or(server.JSONEncoder, jsoncodec.Std)
+	if o.protocol == "jsonrpc" {
+		return jsoncodec.Label(o.jsonEncoder, serverEncoder)
+	}
+	if o.protocol != "rest" || o.restEncoding != "json" {
+		return ""
+	}
+	if o.parity() {
+		if server.ParityMarshal != restapi.ParityStructs {
+			return ""
+		}
+		return jsoncodec.Label(restapi.ParityProtoJSON, serverEncoder)
+	}
+	return jsoncodec.Label(o.jsonEncoder, serverEncoder)
+}
+
+// connectionLabel returns the non-default connection flags that apply to
+// the protocol, recorded with the run so cold and reused connection runs
+// are not compared with each other. It returns "" for the defaults.
+func (o *runOptions) connectionLabel() string {
+	var flags []string
+	if o.conn.DisableKeepAlive {
+		flags = append(flags, "--disable-keepalive")
+	}
+	if o.protocol == "grpc" {
+		if o.conn.GRPCConns > 1 {
+			flags = append(flags, fmt.Sprintf("--grpc-conns=%d", o.conn.GRPCConns))
+		}
+		if o.conn.GRPCKeepaliveTime > 0 {
+			flags = append(flags, "--grpc-keepalive-time="+o.conn.GRPCKeepaliveTime.String())
+			if o.conn.GRPCKeepaliveTimeout > 0 {
+				flags = append(flags, "--grpc-keepalive-timeout="+o.conn.GRPCKeepaliveTimeout.String())
+			}
+			if o.conn.GRPCPermitWithoutStream {
+				flags = append(flags, "--grpc-keepalive-permit-without-stream")
+			}
+		}
+	} else if o.conn.MaxConnsPerHost > 0 {
+		flags = append(flags, fmt.Sprintf("--max-conns-per-host=%d", o.conn.MaxConnsPerHost))
+	}
+	return strings.Join(flags, " ")
+}
+
+// cacheBustLabel returns the cache-busting mode as stored with the run,
+// empty when requests are sent unchanged.
+func (o *runOptions) cacheBustLabel() string {
+	if o.conn.CacheBust == bench.CacheBustNone {
+		return ""
+	}
+	return o.conn.CacheBust
+}
+
+// sendsHeaders reports whether protocol's requests carry --header rather
+// than --metadata: those of the REST server's HTTP/1.1 APIs.
+func sendsHeaders(protocol string) bool {
+	return protocol == "rest" || protocol == "graphql" || protocol == "jsonrpc"
+}
+
+// injected returns the headers or metadata added to the protocol's
+// requests: --header for REST, GraphQL and JSON-RPC, --metadata for the
+// others.
+func (o *runOptions) injected() map[string][]string {
+	if sendsHeaders(o.protocol) {
+		h, _ := bench.ParseHeaders(o.headers)
+		return h
+	}
+	md, _ := bench.ParseMetadata(o.metadata)
+	return md
+}
+
+// injectedHeadersLabel returns the names of the headers or metadata added
+// to the protocol's requests, sorted and comma-separated, "" for none.
+// Their values are not recorded, as they may hold credentials.
+func (o *runOptions) injectedHeadersLabel() string {
+	return strings.Join(slices.Sorted(maps.Keys(o.injected())), ",")
+}
+
+// injectedHeaderBytes returns the bytes the injected headers or metadata
+// add to each of the protocol's requests.
+func (o *runOptions) injectedHeaderBytes() int {
+	return bench.InjectedBytes(o.injected())
+}
+
+// serverQoSLabel returns the declared server QoS mode as stored with the
+// run, empty when the servers ran without QoS.
+func (o *runOptions) serverQoSLabel() string {
+	if o.serverQoS == qos.ModeNone {
+		return ""
+	}
+	return o.serverQoS
+}
+
+// benchConfig returns the bench.Config of a run with these options. Each
+// run replays the timing data from the start.
+func (o *runOptions) benchConfig(global *globalOptions, env *runEnv) bench.Config {
+	cfg := bench.Config{
+		Scenario:         o.scenario,
+		Protocol:         o.protocol,
+		Addr:             serverAddr(global, o),
+		ConnectEncoding:  o.connectEncoding,
+		RESTEncoding:     o.restEncoding,
+		RESTShape:        o.restShape,
+		RESTStream:       o.restStream,
+		JSONEncoder:      o.jsonEncoder,
+		Compression:      o.compression,
+		Conn:             o.conn,
+		Workers:          o.workers,
+		AccountIDs:       env.accountIDs,
+		Concurrency:      o.concurrency,
+		Duration:         o.duration,
+		Rate:             o.rate,
+		LoadProfile:      o.profile(),
+		Poisson:          o.poisson,
+		Accounts:         o.accounts,
+		StreamMetric:     o.streamMetric,
+		Subscribers:      o.subscribers,
+		StreamsPerWorker: o.streamsPerWorker,
+		StreamRate:       o.streamRate,
+		MinStreams:       o.minStreams,
+		CheckOrdering:    o.checkOrdering,
+		LiveStreams:      o.live,
+		Reconnect:        o.reconnect,
+		ChaosDisconnect:  o.chaosDisconnect,
+		ChaosRestart:     o.chaosRestart,
+		Staleness:        o.staleness,
+		CorrectOmission:  o.correctOmission,
+		WriteRatio:       o.writeRatio,
+		RequestTimeout:   o.requestTimeout,
+		Backoff:          o.backoff,
+		ProcessCost:      o.processCost,
+		MaxStoredSamples: o.maxSamples,
+		MeasureWindow:    o.window(),
+		ProgressInterval: o.logInterval,
+		ServerStats: func(ctx context.Context) (bench.ServerStats, error) {
+			return serverStats(ctx, global, o.protocol)
+		},
+	}
+	cfg.Conn.Auth = global.auth
+	cfg.Conn.Headers, _ = bench.ParseHeaders(o.headers)
+	cfg.Conn.Metadata, _ = bench.ParseMetadata(o.metadata)
+	if o.scenario == "echo" {
+		cfg.PayloadSize = o.payloadBytes()
+	}
+	if o.scenario == "mixed" {
+		cfg.Mix = o.operationMix()
+	}
+	if o.chaosRestart > 0 {
+		cfg.RestartServer = func(ctx context.Context) error {
+			return restartServer(ctx, global, o.protocol, o.restartDowntime)
+		}
+	}
+	if o.scenario == "reference" {
+		cfg.Addr, cfg.ServerStats = "", nil
+	}
+	if env.timing != nil {
+		cfg.Timing = timing.NewReplay(env.timing.Data(), o.replayMode, o.replaySpeedup)
+	}
+	return cfg
+}
+
+// newClient creates a benchmark client for the configured protocol.
+func newClient(cfg bench.Config) (bench.BenchmarkClient, error) {
+	client, err := bench.NewClient(cfg)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.Scenario == "reference" {
+		log.Printf("Using the in-process reference stub")
+		return client, nil
+	}
+	switch cfg.Protocol {
+	case "grpc":
+		log.Printf("Connected to gRPC server at %s", cfg.Addr)
+	case "rest":
+		log.Printf("Connected to REST server at %s (%s encoding, %s shape, %s JSON encoder)", cfg.Addr, cfg.RESTEncoding, cfg.RESTShape, cfg.JSONEncoder)
+	case "connect":
+		log.Printf("Connected to Connect server at %s (%s codec)", cfg.Addr, cfg.ConnectEncoding)
+	case "grpc-web":
+		log.Printf("Connected to gRPC-Web server at %s", cfg.Addr)
+	case "graphql":
+		log.Printf("Connected to GraphQL endpoint of REST server at %s", cfg.Addr)
+	case "jsonrpc":
+		log.Printf("Connected to JSON-RPC endpoint of REST server at %s (%s JSON encoder)", cfg.Addr, cfg.JSONEncoder)
+	}
+	return client, nil
+}
+
+// serverAddr returns the address of the server opts.protocol talks to.
+func serverAddr(global *globalOptions, opts *runOptions) string {
+	switch opts.protocol {
+	case "grpc":
+		return global.grpcAddr
+	case "grpc-web":
+		return global.grpcWebAddr
+	default:
+		return global.restAddr
+	}
+}
+
+// serverName returns the server that serves protocol, as recorded in the
+// server config.
+func serverName(protocol string) string {
+	if protocol == "grpc" || protocol == "grpc-web" {
+		return db.ServerGRPC
+	}
+	return db.ServerREST
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kaldun-tech/grpc-rest-benchmark/pkg/bench"
+	"github.com/kaldun-tech/grpc-rest-benchmark/pkg/db"
+)
+
+func TestConnectionLabel(t *testing.T) {
+	conn := bench.ConnOptions{
+		DisableKeepAlive:        true,
+		MaxConnsPerHost:         4,
+		GRPCKeepaliveTime:       30 * time.Second,
+		GRPCKeepaliveTimeout:    5 * time.Second,
+		GRPCPermitWithoutStream: true,
+	}
+	tests := []struct {
+		protocol string
+		conn     bench.ConnOptions
+		want     string
+	}{
+		{"grpc", bench.ConnOptions{}, ""},
+		{"grpc", conn, "--disable-keepalive --grpc-keepalive-time=30s --grpc-keepalive-timeout=5s --grpc-keepalive-permit-without-stream"},
+		{"rest", conn, "--disable-keepalive --max-conns-per-host=4"},
+		{"grpc", bench.ConnOptions{GRPCConns: 4}, "--grpc-conns=4"},
+		{"rest", bench.ConnOptions{GRPCConns: 4}, ""},
+		{"connect", bench.ConnOptions{GRPCKeepaliveTime: time.Minute}, ""},
+	}
+	for _, tt := range tests {
+		o := &runOptions{protocol: tt.protocol, conn: tt.conn}
+		if got := o.connectionLabel(); got != tt.want {
+			t.Errorf("connectionLabel(%s, %+v) = %q, want %q", tt.protocol, tt.conn, got, tt.want)
+		}
+	}
+}
+
+func TestProtocolLabel(t *testing.T) {
+	tests := []struct {
+		protocol, connectEncoding, restEncoding, restShape string
+		want                                               string
+	}{
+		{"grpc", "proto", "proto", "idiomatic", "grpc"},
+		{"rest", "proto", "json", "idiomatic", "rest"},
+		{"rest", "proto", "json", "parity", "rest-parity"},
+		{"rest", "proto", "proto", "idiomatic", "rest-proto"},
+		{"connect", "json", "proto", "idiomatic", "connect-json"},
+	}
+	for _, tt := range tests {
+		o := &runOptions{protocol: tt.protocol, connectEncoding: tt.connectEncoding, restEncoding: tt.restEncoding, restShape: tt.restShape}
+		if got := o.protocolLabel(); got != tt.want {
+			t.Errorf("protocolLabel(%s, %s, %s, %s) = %q, want %q", tt.protocol, tt.connectEncoding, tt.restEncoding, tt.restShape, got, tt.want)
+		}
+	}
+}
+
+func TestJSONEncoderLabel(t *testing.T) {
+	tests := []struct {
+		protocol, restEncoding, restShape, client string
+		server                                    db.ServerConfig
+		want                                      string
+	}{
+		{"rest", "json", "", "std", db.ServerConfig{JSONEncoder: "std"}, ""},
+		{"rest", "json", "", "std", db.ServerConfig{}, ""},
+		{"rest", "json", "", "sonic", db.ServerConfig{JSONEncoder: "sonic"}, "sonic"},
+		{"rest", "json", "", "sonic", db.ServerConfig{}, "client=sonic,server=std"},
+		{"rest", "proto", "", "sonic", db.ServerConfig{JSONEncoder: "sonic"}, ""},
+		{"grpc", "json", "", "sonic", db.ServerConfig{}, ""},
+		{"rest", "json", "parity", "std", db.ServerConfig{JSONEncoder: "sonic"}, ""},
+		{"rest", "json", "parity", "std", db.ServerConfig{JSONEncoder: "sonic", ParityMarshal: "protojson"}, ""},
+		{"rest", "json", "parity", "std", db.ServerConfig{JSONEncoder: "sonic", ParityMarshal: "structs"}, "client=protojson,server=sonic"},
+		{"rest", "json", "parity", "std", db.ServerConfig{ParityMarshal: "structs"}, "client=protojson,server=std"},
+		{"jsonrpc", "json", "", "jsoniter", db.ServerConfig{JSONEncoder: "sonic"}, "client=jsoniter,server=sonic"},
+	}
+	for _, tt := range tests {
+		o := &runOptions{protocol: tt.protocol, restEncoding: tt.restEncoding, restShape: tt.restShape, jsonEncoder: tt.client}
+		if got := o.jsonEncoderLabel(tt.server); got != tt.want {
+			t.Errorf("jsonEncoderLabel(%s, %s, %s, %s, %+v) = %q, want %q", tt.protocol, tt.restEncoding, tt.restShape, tt.client, tt.server, got, tt.want)
+		}
+	}
+}
+
+func TestRunOptions_InjectedHeaders(t *testing.T) {
+	o := &runOptions{
+		headers:  []string{"X-Trace=abc", "Authorization=Bearer 123"},
+		metadata: []string{"x-trace=abc"},
+	}
+
+	o.protocol = "rest"
+	if got, want := o.injectedHeadersLabel(), "Authorization,X-Trace"; got != want {
+		t.Errorf("rest injectedHeadersLabel() = %q, want %q", got, want)
+	}
+	if got, want := o.injectedHeaderBytes(), len("X-Trace")+3+len("Authorization")+10; got != want {
+		t.Errorf("rest injectedHeaderBytes() = %d, want %d", got, want)
+	}
+
+	o.protocol = "grpc"
+	if got, want := o.injectedHeadersLabel(), "x-trace"; got != want {
+		t.Errorf("grpc injectedHeadersLabel() = %q, want %q", got, want)
+	}
+	if got, want := o.injectedHeaderBytes(), 10; got != want {
+		t.Errorf("grpc injectedHeaderBytes() = %d, want %d", got, want)
+	}
+
+	o.metadata = nil
+	if got := o.injectedHeadersLabel(); got != "" {
+		t.Errorf("injectedHeadersLabel() without metadata = %q, want empty", got)
+	}
+}
@@ -0,0 +1,251 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kaldun-tech/grpc-rest-benchmark/pkg/bench"
+	"github.com/kaldun-tech/grpc-rest-benchmark/pkg/compression"
+	"github.com/kaldun-tech/grpc-rest-benchmark/pkg/db"
+	"github.com/kaldun-tech/grpc-rest-benchmark/pkg/payload"
+	"github.com/kaldun-tech/grpc-rest-benchmark/pkg/serverlog"
+	"github.com/kaldun-tech/grpc-rest-benchmark/pkg/workload"
+)
+
+// runRecord returns the benchmark_runs row of a finished run: its options,
+// the server and dataset it ran against, and the checks of its results.
+func runRecord(ctx context.Context, global *globalOptions, opts *runOptions, env *runEnv, report bench.Report,
+	verification bench.Verification, serverLog *serverlog.Summary, runLog *RunLog) *db.BenchmarkRun {
+	run := &db.BenchmarkRun{
+		Scenario:    opts.scenario,
+		Protocol:    opts.protocolLabel(),
+		Concurrency: report.Concurrency,
+	}
+	if rate := report.Rate; rate > 0 {
+		run.RateLimit = &rate
+	}
+	if opts.scenario == "echo" {
+		size := opts.payloadBytes()
+		run.PayloadSize = &size
+	}
+	if opts.scenario == "write" {
+		run.WriteRatio = &opts.writeRatio
+	}
+	if opts.streamsPerWorker > 1 {
+		run.StreamsPerWorker = &opts.streamsPerWorker
+	}
+	if opts.processCost > 0 {
+		us := opts.processCost.Microseconds()
+		run.ProcessCostUs = &us
+	}
+	if report.ChurnSeed != 0 {
+		churn := formatChurn(opts.accounts, report.ChurnSeed)
+		run.AccountChurn = &churn
+	}
+	if opts.scenario == "mixed" {
+		mix := formatMix(opts.operationMix())
+		run.OperationMix = &mix
+	}
+	if opts.compression != compression.None {
+		run.Compression = &opts.compression
+	}
+	if label := opts.connectionLabel(); label != "" {
+		run.Connection = &label
+	}
+	if label := opts.cacheBustLabel(); label != "" {
+		run.CacheBust = &label
+	}
+	if label := opts.injectedHeadersLabel(); label != "" {
+		n := opts.injectedHeaderBytes()
+		run.InjectedHeaders = &label
+		run.InjectedHeaderBytes = &n
+	}
+	if label := opts.serverQoSLabel(); label != "" {
+		run.ServerQoS = &label
+	}
+	if opts.dbTarget != "" {
+		run.DBTarget = &opts.dbTarget
+	}
+	if n := len(opts.workers); n > 0 {
+		run.Workers = &n
+	}
+	if len(opts.agents) > 0 {
+		label := agentsLabel(opts.agents)
+		run.Agents = &label
+	}
+	if opts.liveStreams() {
+		live := true
+		run.LiveStream = &live
+	}
+	if opts.chaosDisconnect > 0 {
+		ms := opts.chaosDisconnect.Milliseconds()
+		run.ChaosDisconnectMs = &ms
+	}
+	if label := opts.chaosRestartLabel(); label != "" {
+		run.ChaosRestart = &label
+	}
+	if verification.Checked() {
+		verified, checks := verification.Verified(), verification.String()
+		run.Verified = &verified
+		run.Verification = &checks
+	}
+	if serverLog != nil {
+		run.ServerLogErrors = &serverLog.Errors
+		run.ServerLogWarnings = &serverLog.Warnings
+		if excerpt := serverLog.Excerpt(); excerpt != "" {
+			run.ServerLogLines = &excerpt
+		}
+	}
+	if runLog != nil {
+		run.LogPath = &runLog.Path
+	}
+	if cfg, err := opts.snapshot(report.ChurnSeed); err != nil {
+		warnf(ctx, "configuration snapshot not recorded: %v", err)
+	} else {
+		run.RunConfig = &cfg
+	}
+	run.ComparisonID = env.comparisonID
+	run.SuiteID = env.suiteID
+	run.DatasetHash = env.datasetHash
+	run.DatasetFingerprint = env.datasetFingerprint
+	run.DatasetSize = env.datasetSize
+	run.ConcurrentWith = env.concurrentWith
+	recordOrigin(run, opts, env)
+	if server, ok := env.serverConfigs[serverName(opts.protocol)]; ok {
+		run.ServerPools = &server.Pools
+		if server.QueryTx != "" {
+			run.ServerQueryTx = &server.QueryTx
+		}
+		if server.Faults != "" {
+			run.ServerFaults = &server.Faults
+		}
+		if server.Cache != "" {
+			run.ServerCache = &server.Cache
+		}
+		if server.Middleware != "" {
+			run.ServerMiddleware = &server.Middleware
+		}
+		if server.RateLimit != "" {
+			run.ServerRateLimit = &server.RateLimit
+		}
+	}
+	if label := opts.jsonEncoderLabel(env.serverConfigs[serverName(opts.protocol)]); label != "" {
+		run.JSONEncoder = &label
+	}
+	if label := opts.restStreamLabel(); label != "" {
+		run.RESTStreamFormat = &label
+	}
+	if mode := global.auth.String(); mode != "" {
+		run.Auth = &mode
+	}
+	if opts.backoff {
+		run.Backoff = &opts.backoff
+	}
+	return run
+}
+
+// storeRun stores the results of a finished run with its row in the results
+// store, or writes them to --results-dir as JSON without one, and saves its
+// profiles. It returns the stored run's ID, 0 if it was not stored; failing
+// to store the results is a warning.
+func storeRun(ctx context.Context, opts *runOptions, env *runEnv, profiles *runProfiles, results *bench.Results, run *db.BenchmarkRun) int64 {
+	logger := bench.LoggerFrom(ctx)
+	if env.results == nil {
+		profiles.save(ctx, opts, env, 0)
+		path, err := writeResultsJSON(opts.resultsDir, results, run)
+		if err != nil {
+			warnf(ctx, "%v", err)
+			return 0
+		}
+		logger.Info("results written", "path", path)
+		return 0
+	}
+
+	runID, err := results.StoreResults(ctx, env.results, run)
+	profiles.save(ctx, opts, env, runID)
+	if err != nil {
+		warnf(ctx, "failed to store results: %v", err)
+		return runID
+	}
+	logger.Info("results stored", "run_id", runID)
+	if opts.runIDsFile != "" {
+		if err := appendRunID(opts.runIDsFile, runID); err != nil {
+			warnf(ctx, "failed to record run ID: %v", err)
+		}
+	}
+
+	return runID
+}
+
+// appendRunID appends a stored run ID to the file of --run-ids-file.
+func appendRunID(path string, runID int64) error {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintln(f, runID); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// writeResultsJSON writes the results of a --no-db run to a new file in dir
+// named after the run's creation time, and returns its path.
+func writeResultsJSON(dir string, results *bench.Results, run *db.BenchmarkRun) (string, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create results directory: %w", err)
+	}
+	run.CreatedAt = time.Now()
+	path := filepath.Join(dir, fmt.Sprintf("run-%s.json", run.CreatedAt.Format("20060102-150405.000")))
+	f, err := os.Create(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to create results file: %w", err)
+	}
+	if err := results.WriteJSON(f, run); err != nil {
+		f.Close()
+		return "", err
+	}
+	if err := f.Close(); err != nil {
+		return "", fmt.Errorf("failed to write results file: %w", err)
+	}
+	fmt.Printf("Results written to %s\n", path)
+	return path, nil
+}
+
+// formatMix formats an operation mix as "balance:80 echo(4KB):20" or
+// "balance:720 batch(10):180 write:100".
+func formatMix(mix []bench.MixOperation) string {
+	parts := make([]string, len(mix))
+	for i, op := range mix {
+		name := op.Scenario
+		switch op.Scenario {
+		case "echo":
+			name += "(" + payload.FormatSize(op.PayloadSize) + ")"
+		case "batch":
+			name += "(" + strconv.Itoa(op.BatchSize) + ")"
+		}
+		parts[i] = fmt.Sprintf("%s:%d", name, op.Weight)
+	}
+	return strings.Join(parts, " ")
+}
+
+// formatWriteRatio formats a write ratio as a percentage, e.g. 0.2 as "20%".
+func formatWriteRatio(ratio float64) string {
+	return fmt.Sprintf("%.4g%%", ratio*100)
+}
+
+// formatChurn describes the working set rotation of a run, e.g.
+// "working set 5%, 10% every 1m0s, seed 42".
+func formatChurn(a workload.Accounts, seed int64) string {
+	set := "working set " + formatWriteRatio(a.WorkingSet)
+	if a.Pattern == workload.AccountsHot {
+		set = "hot set " + formatWriteRatio(a.HotFraction)
+	}
+	return fmt.Sprintf("%s, %s every %s, seed %d", set, formatWriteRatio(a.Churn), a.ChurnInterval, seed)
+}
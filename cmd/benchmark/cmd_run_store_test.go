@@ -0,0 +1,25 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/kaldun-tech/grpc-rest-benchmark/pkg/bench"
+)
+
+func TestFormatMix(t *testing.T) {
+	got := formatMix([]bench.MixOperation{
+		{Scenario: "balance", Weight: 80},
+		{Scenario: "echo", Weight: 20, PayloadSize: 4096},
+	})
+	if want := "balance:80 echo(4KB):20"; got != want {
+		t.Errorf("formatMix() = %q, want %q", got, want)
+	}
+}
+
+func TestFormatWriteRatio(t *testing.T) {
+	for ratio, want := range map[float64]string{1: "100%", 0.3: "30%", 0.125: "12.5%", 0: "0%"} {
+		if got := formatWriteRatio(ratio); got != want {
+			t.Errorf("formatWriteRatio(%v) = %q, want %q", ratio, got, want)
+		}
+	}
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/kaldun-tech/grpc-rest-benchmark/pkg/bench"
+	"github.com/kaldun-tech/grpc-rest-benchmark/pkg/workload"
+)
+
+func TestStageOptions(t *testing.T) {
+	w, err := workload.Parse([]byte(`
+version: 1
+name: test
+protocol: rest
+rate: 500
+operations:
+  - scenario: balance
+    weight: 3
+  - scenario: echo
+    payload_size: 4KB
+arrival:
+  process: poisson
+stages:
+  - name: mixed
+    duration: 10s
+  - name: echo-only
+    duration: 5s
+    concurrency: 2
+    operations:
+      - scenario: echo
+        payload_size: 64KB
+`))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	base := &runOptions{
+		scenario:        "balance",
+		protocol:        "grpc",
+		streamMetric:    bench.StreamMetricInterArrival,
+		connectEncoding: "proto",
+		restEncoding:    "json",
+		restShape:       "idiomatic",
+		jsonEncoder:     "std",
+		compression:     "none",
+		payloadSize:     "1KB",
+		logDir:          "custom-logs",
+	}
+
+	mixed := stageOptions(base, w, w.Stages[0])
+	if err := mixed.validate(); err != nil {
+		t.Fatalf("mixed stage validate() error = %v", err)
+	}
+	if mixed.scenario != "mixed" || mixed.protocol != "rest" || mixed.rate != 500 || !mixed.poisson {
+		t.Errorf("mixed stage = %+v", mixed)
+	}
+	if mixed.duration != 10*time.Second || mixed.concurrency != 10 {
+		t.Errorf("mixed stage duration %v concurrency %d, want 10s and 10", mixed.duration, mixed.concurrency)
+	}
+	if len(mixed.mix) != 2 || mixed.mix[0].Weight != 3 || mixed.mix[1].PayloadSize != 4096 {
+		t.Errorf("mixed stage mix = %+v", mixed.mix)
+	}
+	if mixed.logDir != "custom-logs" {
+		t.Errorf("stage lost flag not covered by the workload: logDir = %q", mixed.logDir)
+	}
+
+	echo := stageOptions(base, w, w.Stages[1])
+	if err := echo.validate(); err != nil {
+		t.Fatalf("echo stage validate() error = %v", err)
+	}
+	if echo.scenario != "echo" || echo.payloadBytes() != 64<<10 || echo.concurrency != 2 {
+		t.Errorf("echo stage = %+v", echo)
+	}
+
+	if base.scenario != "balance" || base.protocol != "grpc" {
+		t.Error("stageOptions modified the base options")
+	}
+}
+
+func TestDatasetHash(t *testing.T) {
+	if got := datasetHash(nil, nil); got != nil {
+		t.Errorf("datasetHash(nil, nil) = %q, want nil", *got)
+	}
+
+	a := datasetHash([]string{"0.0.1001", "0.0.1002"}, nil)
+	b := datasetHash([]string{"0.0.1002", "0.0.1001"}, nil)
+	c := datasetHash([]string{"0.0.1001", "0.0.1003"}, nil)
+	if a == nil || b == nil || c == nil {
+		t.Fatal("datasetHash() = nil with account IDs")
+	}
+	if *a != *b {
+		t.Errorf("hash depends on account order: %s != %s", *a, *b)
+	}
+	if *a == *c {
+		t.Errorf("different accounts hash the same: %s", *a)
+	}
+}
+
+func TestSyntheticAccountIDs(t *testing.T) {
+	ids := syntheticAccountIDs(3)
+	if !slices.Equal(ids, []string{"0.0.100000", "0.0.100001", "0.0.100002"}) {
+		t.Errorf("syntheticAccountIDs(3) = %v", ids)
+	}
+}
+
+func TestRunOptions_EnvironmentName(t *testing.T) {
+	global := &globalOptions{grpcAddr: "srv:50051", restAddr: "http://srv:8080", grpcWebAddr: "http://srv:8081"}
+	if got, want := (&runOptions{}).environmentName(global), "grpc=srv:50051 rest=http://srv:8080 grpc-web=http://srv:8081"; got != want {
+		t.Errorf("environmentName() = %q, want %q", got, want)
+	}
+	if got := (&runOptions{environment: "staging"}).environmentName(global); got != "staging" {
+		t.Errorf("environmentName() with --environment = %q, want staging", got)
+	}
+}
+
+func TestRunOptions_TagsLabel(t *testing.T) {
+	got, err := (&runOptions{tags: []string{"team=payments", "ci=nightly", "empty="}}).tagsLabel()
+	if err != nil {
+		t.Fatalf("tagsLabel() error = %v", err)
+	}
+	if want := "ci=nightly,empty=,team=payments"; got != want {
+		t.Errorf("tagsLabel() = %q, want %q", got, want)
+	}
+	for _, tags := range [][]string{{"nightly"}, {"=x"}, {"a=b,c"}, {"a=b c"}, {"a=1", "a=2"}} {
+		if _, err := (&runOptions{tags: tags}).tagsLabel(); err == nil {
+			t.Errorf("tagsLabel() of %q = nil error, want an error", tags)
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/kaldun-tech/grpc-rest-benchmark/pkg/db"
+)
+
+func newSyncCmd(global *globalOptions) *cobra.Command {
+	return &cobra.Command{
+		Use:   "sync",
+		Short: "Upload runs stored with --results-backend=local:FILE to PostgreSQL",
+		Long: `Sync uploads every run in the local results file that has not been uploaded
+yet, with its samples, to the PostgreSQL database named by the --db-* flags.
+Uploaded runs keep their creation time and get new run IDs; the local file
+records them so running sync again only uploads newer runs.`,
+		Example: "  benchmark sync --results-backend=local:results.db --db-host=central.example.com",
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			path, err := global.localResultsPath()
+			if err != nil {
+				return err
+			}
+			if path == "" {
+				return fmt.Errorf("sync uploads a local results file, set --results-backend=local:FILE")
+			}
+
+			ctx, cancel := signalContext()
+			defer cancel()
+
+			local, err := db.OpenLocal(ctx, path)
+			if err != nil {
+				return err
+			}
+			defer local.Close()
+
+			database, err := global.connectDB(ctx)
+			if err != nil {
+				return fmt.Errorf("failed to connect to database: %w", err)
+			}
+			defer database.Close()
+
+			n, err := local.SyncTo(ctx, database, func(localID, remoteID int64, samples int) {
+				fmt.Printf("Uploaded local run %d as run_id %d (%d samples)\n", localID, remoteID, samples)
+			})
+			if err != nil {
+				return fmt.Errorf("sync stopped after %d run(s): %w", n, err)
+			}
+			fmt.Printf("Synced %d run(s) from %s\n", n, path)
+			return nil
+		},
+	}
+}
@@ -0,0 +1,157 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"math/rand"
+	"slices"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/kaldun-tech/grpc-rest-benchmark/pkg/timing"
+)
+
+// timingFitOptions holds flags for the timing fit subcommand.
+type timingFitOptions struct {
+	file      string
+	saveModel string
+}
+
+// timingGenerateOptions holds flags for the timing generate subcommand.
+type timingGenerateOptions struct {
+	model string
+	count int
+	out   string
+	seed  int64
+}
+
+func newTimingCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "timing",
+		Short: "Analyze timing data and generate synthetic workloads",
+		Long: `Fit captured inter-arrival times to candidate distributions
+(exponential, lognormal, Pareto), report goodness of fit, and generate
+synthetic timing data from a fitted model.`,
+	}
+
+	cmd.AddCommand(
+		newTimingFitCmd(),
+		newTimingGenerateCmd(),
+	)
+	return cmd
+}
+
+func newTimingFitCmd() *cobra.Command {
+	opts := &timingFitOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "fit",
+		Short: "Fit timing data to candidate distributions",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			data, err := timing.Load(opts.file)
+			if err != nil {
+				return fmt.Errorf("failed to load timing data: %w", err)
+			}
+
+			fits, err := timing.FitAll(data.InterArrivalMs)
+			if err != nil {
+				return err
+			}
+			printFitReport(cmd.OutOrStdout(), data, fits)
+
+			if opts.saveModel != "" {
+				if err := timing.SaveModel(opts.saveModel, timing.NewModel(fits[0], data)); err != nil {
+					return fmt.Errorf("failed to save model: %w", err)
+				}
+				log.Printf("Saved %s model to %s", fits[0].Dist.Name(), opts.saveModel)
+			}
+			return nil
+		},
+	}
+
+	f := cmd.Flags()
+	f.StringVar(&opts.file, "file", "", "Timing data JSON file to fit")
+	f.StringVar(&opts.saveModel, "save-model", "", "Save the best fit as a model file")
+	cmd.MarkFlagRequired("file")
+
+	return cmd
+}
+
+func newTimingGenerateCmd() *cobra.Command {
+	opts := &timingGenerateOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "generate",
+		Short: "Generate synthetic timing data from a fitted model",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			m, err := timing.LoadModel(opts.model)
+			if err != nil {
+				return fmt.Errorf("failed to load model: %w", err)
+			}
+
+			seed := opts.seed
+			if seed == 0 {
+				seed = time.Now().UnixNano()
+			}
+			data, err := timing.GenerateFromModel(m, opts.count, rand.New(rand.NewSource(seed)))
+			if err != nil {
+				return err
+			}
+
+			if err := timing.Save(opts.out, data); err != nil {
+				return fmt.Errorf("failed to save timing data: %w", err)
+			}
+			log.Printf("Generated %d inter-arrival times (%.2f msg/s) to %s",
+				data.MessageCount, data.AvgRatePerSecond, opts.out)
+			return nil
+		},
+	}
+
+	f := cmd.Flags()
+	f.StringVar(&opts.model, "model", "", "Model file written by 'timing fit --save-model'")
+	f.IntVar(&opts.count, "count", 0, "Number of inter-arrival times (0 = model's message count)")
+	f.StringVar(&opts.out, "out", "", "Output timing data JSON file")
+	f.Int64Var(&opts.seed, "seed", 0, "Random seed (0 = time-based)")
+	cmd.MarkFlagRequired("model")
+	cmd.MarkFlagRequired("out")
+
+	return cmd
+}
+
+// printFitReport prints each fitted distribution and its goodness of fit,
+// best first.
+func printFitReport(out io.Writer, data *timing.Data, fits []timing.FitResult) {
+	fmt.Fprintf(out, "Source: %s topic %s, %d messages, p50=%.1fms, p99=%.1fms\n\n",
+		data.Network, data.TopicID, data.MessageCount, data.Stats.P50Ms, data.Stats.P99Ms)
+
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "DISTRIBUTION\tPARAMETERS\tMEAN (ms)\tKS\tP-VALUE")
+	for _, fit := range fits {
+		fmt.Fprintf(w, "%s\t%s\t%.1f\t%.4f\t%.4f\n",
+			fit.Dist.Name(), formatParams(fit.Dist.Params()), fit.Dist.Mean(), fit.KS, fit.PValue)
+	}
+	w.Flush()
+
+	fmt.Fprintf(out, "\nBest fit: %s\n", fits[0].Dist)
+}
+
+// formatParams formats distribution parameters as sorted key=value pairs.
+func formatParams(params map[string]float64) string {
+	keys := make([]string, 0, len(params))
+	for k := range params {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+
+	parts := make([]string, len(keys))
+	for i, k := range keys {
+		parts[i] = fmt.Sprintf("%s=%.4g", k, params[k])
+	}
+	return strings.Join(parts, " ")
+}
@@ -0,0 +1,158 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"slices"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/kaldun-tech/grpc-rest-benchmark/pkg/timing"
+	"github.com/kaldun-tech/grpc-rest-benchmark/pkg/workload"
+)
+
+// validateOptions holds flags for the validate subcommand.
+type validateOptions struct {
+	workloads    []string
+	checkTargets bool
+	timeout      time.Duration
+}
+
+func newValidateCmd(global *globalOptions) *cobra.Command {
+	opts := &validateOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "validate",
+		Short: "Check workload files for mistakes before running them",
+		Long: `Validate checks workload files the way 'run --workload' would, without
+connecting to the database: schema and field values, every stage's settings,
+and that replay timing files exist and load. With --check-targets it also
+checks that the servers the workloads use are reachable.
+
+Every problem is reported, so a long scheduled run does not fail part way
+through on a typo.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx, cancel := signalContext()
+			defer cancel()
+			return runValidate(ctx, global, opts)
+		},
+	}
+
+	cmd.Flags().StringArrayVar(&opts.workloads, "workload", nil, "Workload YAML file to validate (repeatable)")
+	cmd.Flags().BoolVar(&opts.checkTargets, "check-targets", false, "Also check that the servers the workloads target are reachable")
+	cmd.Flags().DurationVar(&opts.timeout, "timeout", 5*time.Second, "Timeout for each target check")
+	cmd.MarkFlagRequired("workload")
+	cmd.MarkFlagFilename("workload", "yaml", "yml")
+
+	return cmd
+}
+
+// runValidate validates each workload file, printing its problems, then
+// optionally checks the targets of the valid ones. Returns an error if any
+// file is invalid or any target is unreachable.
+func runValidate(ctx context.Context, global *globalOptions, opts *validateOptions) error {
+	invalid := 0
+	var protocols []string
+	for _, path := range opts.workloads {
+		w, problems := validateWorkloadFile(path)
+		if len(problems) > 0 {
+			invalid++
+			fmt.Printf("%s: %d problem(s)\n", path, len(problems))
+			for _, p := range problems {
+				fmt.Printf("  - %v\n", p)
+			}
+			continue
+		}
+		fmt.Printf("%s: OK (%s, %s, %d stage(s), %s)\n", path, w.Name, w.Protocol, len(w.Stages), w.Duration())
+		if !slices.Contains(protocols, w.Protocol) {
+			protocols = append(protocols, w.Protocol)
+		}
+	}
+
+	unreachable := 0
+	if opts.checkTargets && len(protocols) > 0 {
+		fmt.Println("\nTargets:")
+		for _, protocol := range protocols {
+			addr, err := checkTarget(ctx, global, protocol, opts.timeout)
+			if err != nil {
+				unreachable++
+				fmt.Printf("  [FAIL] %-10s %s: %v\n", protocol, addr, err)
+				continue
+			}
+			fmt.Printf("  [ OK ] %-10s %s\n", protocol, addr)
+		}
+	}
+
+	switch {
+	case invalid > 0:
+		return fmt.Errorf("%d of %d workload file(s) invalid", invalid, len(opts.workloads))
+	case unreachable > 0:
+		return fmt.Errorf("%d target(s) unreachable", unreachable)
+	}
+	return nil
+}
+
+// validateWorkloadFile loads a workload file and checks it as far as
+// possible without servers: the schema, each stage's run settings, and the
+// replay timing file. The workload is nil if the file could not be parsed.
+func validateWorkloadFile(path string) (*workload.Workload, []error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, []error{err}
+	}
+	w, err := workload.Parse(b)
+	if err != nil {
+		return nil, splitErrors(err)
+	}
+
+	var problems []error
+	base := defaultRunOptions()
+	for _, s := range w.Stages {
+		if err := stageOptions(base, w, s).validate(); err != nil {
+			problems = append(problems, fmt.Errorf("stage %q: %w", s.Name, err))
+		}
+	}
+
+	if w.Arrival.Process == workload.ArrivalReplay {
+		if _, err := timing.Load(w.Arrival.Timing); errors.Is(err, fs.ErrNotExist) {
+			problems = append(problems, fmt.Errorf("arrival.timing: %s not found (paths are relative to the working directory)", w.Arrival.Timing))
+		} else if err != nil {
+			problems = append(problems, fmt.Errorf("arrival.timing: %s: %w", w.Arrival.Timing, err))
+		}
+	}
+
+	return w, problems
+}
+
+// splitErrors returns the individual errors joined into err.
+func splitErrors(err error) []error {
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		return joined.Unwrap()
+	}
+	return []error{err}
+}
+
+// defaultRunOptions returns run options holding the run flag defaults, the
+// settings a workload stage starts from when no other flags are given.
+func defaultRunOptions() *runOptions {
+	opts := &runOptions{}
+	addRunFlags(&cobra.Command{}, opts)
+	return opts
+}
+
+// checkTarget checks the server a protocol connects to, returning its address.
+func checkTarget(ctx context.Context, global *globalOptions, protocol string, timeout time.Duration) (string, error) {
+	switch protocol {
+	case "grpc":
+		return global.grpcAddr, checkGRPCHealth(ctx, global, timeout)
+	case "grpc-web":
+		return global.grpcWebAddr, checkHTTPReachable(ctx, global, global.grpcWebAddr, timeout)
+	default:
+		return global.restAddr, checkRESTHealth(ctx, global, timeout)
+	}
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeWorkload(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "workload.yaml")
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestValidateWorkloadFile(t *testing.T) {
+	tests := []struct {
+		name string
+		yaml string
+		want []string // substrings, one per expected problem
+	}{
+		{
+			name: "valid",
+			yaml: `
+version: 1
+name: ok
+protocol: connect
+operations:
+  - scenario: echo
+    payload_size: 4KB
+stages:
+  - name: only
+    duration: 10s
+`,
+		},
+		{
+			name: "all schema problems reported",
+			yaml: `
+version: 1
+name: bad
+protocol: http
+operations:
+  - scenario: transfer
+stages:
+  - name: only
+    duration: 10s
+`,
+			want: []string{`protocol: "http"`, `operations[0].scenario: "transfer"`},
+		},
+		{
+			name: "typo",
+			yaml: `
+version: 1
+name: bad
+protocol: grpc
+operations:
+  - scenario: balance
+stages:
+  - name: only
+    durration: 10s
+`,
+			want: []string{`unknown field "durration" (did you mean "duration"?)`},
+		},
+		{
+			name: "missing timing file",
+			yaml: `
+version: 1
+name: bad
+protocol: grpc
+operations:
+  - scenario: stream
+arrival:
+  process: replay
+  timing: does-not-exist.json
+stages:
+  - name: only
+    duration: 10s
+`,
+			want: []string{"arrival.timing: does-not-exist.json not found"},
+		},
+		{
+			name: "stage rejected by run",
+			yaml: `
+version: 1
+name: bad
+protocol: grpc
+operations:
+  - scenario: stream
+arrival:
+  process: poisson
+stages:
+  - name: only
+    duration: 10s
+`,
+			want: []string{`stage "only": poisson arrivals require a unary scenario`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, problems := validateWorkloadFile(writeWorkload(t, tt.yaml))
+			if len(problems) != len(tt.want) {
+				t.Fatalf("got %d problem(s) %v, want %d", len(problems), problems, len(tt.want))
+			}
+			for i, want := range tt.want {
+				if !strings.Contains(problems[i].Error(), want) {
+					t.Errorf("problem %d = %q, want it to contain %q", i, problems[i], want)
+				}
+			}
+		})
+	}
+}
+
+func TestValidateWorkloadFile_Examples(t *testing.T) {
+	paths, err := filepath.Glob("../../workloads/*.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, path := range paths {
+		if _, problems := validateWorkloadFile(path); len(problems) > 0 {
+			t.Errorf("%s: %v", path, problems)
+		}
+	}
+}
@@ -0,0 +1,143 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"runtime"
+	"strconv"
+	"time"
+
+	"github.com/spf13/cobra"
+	"google.golang.org/grpc"
+
+	"github.com/kaldun-tech/grpc-rest-benchmark/pkg/bench"
+	"github.com/kaldun-tech/grpc-rest-benchmark/pkg/db"
+	"github.com/kaldun-tech/grpc-rest-benchmark/pkg/protos"
+	"github.com/kaldun-tech/grpc-rest-benchmark/pkg/results"
+)
+
+// maxWorkerRecvMsgSize bounds the run requests a worker accepts, which carry
+// every account ID of the run.
+const maxWorkerRecvMsgSize = 256 << 20
+
+// heartbeatInterval is how often a registered worker sends heartbeats,
+// three per db.AgentTimeout so one lost heartbeat does not mark it
+// unhealthy.
+const heartbeatInterval = db.AgentTimeout / 3
+
+func newWorkerCmd() *cobra.Command {
+	var listen, register, name, advertise string
+
+	cmd := &cobra.Command{
+		Use:   "worker",
+		Short: "Generate load for a distributed run coordinated by 'benchmark run --workers'",
+		Long: `Worker runs a load generation agent. A coordinator started with
+'benchmark run --workers=HOST:PORT,...' sends each worker its share of the
+run's concurrency and rate; the workers start together, send the load to the
+server named in the run, and stream their samples back to the coordinator,
+which stores them as one run. Server addresses are resolved on the worker.
+
+With --register the worker registers with a REST server's agent registry,
+reporting its protocols and CPU count, and sends heartbeats while it runs,
+so coordinators can select it by name with 'benchmark run --agents'.`,
+		Example: `  benchmark worker --listen=:50070
+  benchmark worker --register=http://results:8080 --name=east-1 --advertise=10.0.0.5:50070`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if register == "" && (cmd.Flags().Changed("name") || advertise != "") {
+				return fmt.Errorf("name and advertise require --register")
+			}
+
+			lis, err := net.Listen("tcp", listen)
+			if err != nil {
+				return fmt.Errorf("failed to listen on %s: %w", listen, err)
+			}
+
+			srv := grpc.NewServer(grpc.MaxRecvMsgSize(maxWorkerRecvMsgSize))
+			protos.RegisterWorkerServiceServer(srv, bench.NewWorker(slog.Default()))
+
+			ctx, cancel := signalContext()
+			defer cancel()
+
+			if register != "" {
+				reg, err := agentRegistration(name, advertise, lis.Addr())
+				if err != nil {
+					lis.Close()
+					return err
+				}
+				c := results.NewClient(register, &http.Client{Timeout: 10 * time.Second})
+				if _, err := c.RegisterAgent(ctx, reg); err != nil {
+					lis.Close()
+					return fmt.Errorf("failed to register with %s: %w", register, err)
+				}
+				log.Printf("Registered as agent %s (%s) with %s", reg.Name, reg.Address, register)
+				go heartbeat(ctx, c, reg)
+			}
+
+			go func() {
+				<-ctx.Done()
+				log.Println("Shutting down worker...")
+				srv.GracefulStop()
+			}()
+
+			log.Printf("Benchmark worker listening on %s", lis.Addr())
+			return srv.Serve(lis)
+		},
+	}
+
+	cmd.Flags().StringVar(&listen, "listen", ":50070", "Address to accept coordinator connections on")
+	cmd.Flags().StringVar(&register, "register", "", "REST server URL to register this worker with as an agent and send heartbeats to (e.g., http://localhost:8080)")
+	cmd.Flags().StringVar(&name, "name", "", "Agent name to register under (default: the hostname)")
+	cmd.Flags().StringVar(&advertise, "advertise", "", "HOST:PORT coordinators reach this worker at (default: the hostname and the --listen port)")
+	return cmd
+}
+
+// agentRegistration describes this worker to the agent registry, defaulting
+// the name and address to the hostname.
+func agentRegistration(name, advertise string, listen net.Addr) (results.AgentRegistration, error) {
+	hostname, err := os.Hostname()
+	if err != nil && (name == "" || advertise == "") {
+		return results.AgentRegistration{}, fmt.Errorf("failed to get the hostname, set --name and --advertise: %w", err)
+	}
+	if name == "" {
+		name = hostname
+	}
+	if advertise == "" {
+		port := strconv.Itoa(listen.(*net.TCPAddr).Port)
+		advertise = net.JoinHostPort(hostname, port)
+	}
+	reg := results.AgentRegistration{Name: name, Address: advertise, Protocols: bench.Protocols, CPUs: runtime.NumCPU()}
+	a := db.Agent{Name: reg.Name, Address: reg.Address, CPUs: reg.CPUs}
+	if err := a.Validate(); err != nil {
+		return results.AgentRegistration{}, err
+	}
+	return reg, nil
+}
+
+// heartbeat sends heartbeats until ctx is done, registering again if the
+// registry forgot the agent, e.g. because it was deleted.
+func heartbeat(ctx context.Context, c *results.Client, reg results.AgentRegistration) {
+	ticker := time.NewTicker(heartbeatInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		err := c.Heartbeat(ctx, reg.Name)
+		var apiErr *results.APIError
+		if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound {
+			_, err = c.RegisterAgent(ctx, reg)
+		}
+		if err != nil && ctx.Err() == nil {
+			log.Printf("Agent heartbeat failed: %v", err)
+		}
+	}
+}
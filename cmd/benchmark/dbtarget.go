@@ -0,0 +1,84 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/kaldun-tech/grpc-rest-benchmark/pkg/db"
+	"github.com/kaldun-tech/grpc-rest-benchmark/pkg/protos"
+	"google.golang.org/grpc"
+)
+
+// dbTargetTimeout bounds switching a server's database target, which
+// connects to the new database first.
+const dbTargetTimeout = 30 * time.Second
+
+// setDBTarget switches the server behind protocol to the named database
+// target (its --db-target flag): the gRPC server for grpc and grpc-web, the
+// REST server for rest and connect. Switching to the target in use does
+// nothing.
+func setDBTarget(ctx context.Context, global *globalOptions, protocol, name string) error {
+	ctx, cancel := context.WithTimeout(ctx, dbTargetTimeout)
+	defer cancel()
+
+	if serverName(protocol) == db.ServerGRPC {
+		creds, err := global.grpcCredentials()
+		if err != nil {
+			return err
+		}
+		return grpcSetDBTarget(ctx, creds, global.grpcAddr, name)
+	}
+	client, err := global.httpClient()
+	if err != nil {
+		return err
+	}
+	return restSetDBTarget(ctx, client, global.restAddr, name)
+}
+
+// grpcSetDBTarget calls the gRPC server's AdminService.
+func grpcSetDBTarget(ctx context.Context, creds grpc.DialOption, addr, name string) error {
+	conn, err := grpc.NewClient(addr, creds)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if _, err := protos.NewAdminServiceClient(conn).SetDBTarget(ctx, &protos.SetDBTargetRequest{Name: name}); err != nil {
+		return fmt.Errorf("failed to switch gRPC server to database target %s: %w", name, err)
+	}
+	return nil
+}
+
+// restSetDBTarget calls PUT /api/v1/admin/db-target on the REST server.
+func restSetDBTarget(ctx context.Context, client *http.Client, baseURL, name string) error {
+	body, err := json.Marshal(map[string]string{"target": name})
+	if err != nil {
+		return err
+	}
+	url := strings.TrimSuffix(baseURL, "/") + "/api/v1/admin/db-target"
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to switch REST server to database target %s: %w", name, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		var e struct {
+			Error string `json:"error"`
+		}
+		json.NewDecoder(resp.Body).Decode(&e)
+		return fmt.Errorf("failed to switch REST server to database target %s: status %d: %s", name, resp.StatusCode, e.Error)
+	}
+	return nil
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/spf13/cobra"
+
+	"github.com/kaldun-tech/grpc-rest-benchmark/pkg/db"
+)
+
+// resolveEnvironment points the run at the registered environment named by
+// --environment: its server addresses replace the address flags, and the
+// run is recorded as measuring it. A name that is not registered, or cannot
+// be looked up without PostgreSQL, only names the environment lock.
+func resolveEnvironment(ctx context.Context, cmd *cobra.Command, global *globalOptions, opts *runOptions) error {
+	if opts.environment == "" {
+		return nil
+	}
+	database, err := global.connectDB(ctx)
+	if err != nil {
+		log.Printf("Warning: PostgreSQL unavailable (%v), environment %s not looked up, using the address flags", err, opts.environment)
+		return nil
+	}
+	defer database.Close()
+
+	e, err := database.GetEnvironment(ctx, opts.environment)
+	if errors.Is(err, db.ErrEnvironmentNotFound) {
+		log.Printf("Environment %s is not registered, using the address flags", opts.environment)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	for _, flag := range []string{"grpc-addr", "rest-addr", "grpc-web-addr"} {
+		if cmd.Flags().Changed(flag) {
+			return fmt.Errorf("--%s conflicts with the addresses of registered environment %s", flag, e.Name)
+		}
+	}
+	if e.GRPCAddr != "" {
+		global.grpcAddr = e.GRPCAddr
+	}
+	if e.RESTAddr != "" {
+		global.restAddr = e.RESTAddr
+	}
+	if e.GRPCWebAddr != "" {
+		global.grpcWebAddr = e.GRPCWebAddr
+	}
+	opts.registeredEnvironment = true
+	log.Printf("Environment %s: grpc=%s rest=%s grpc-web=%s", e.Name, global.grpcAddr, global.restAddr, global.grpcWebAddr)
+	return nil
+}
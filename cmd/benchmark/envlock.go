@@ -0,0 +1,77 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"github.com/kaldun-tech/grpc-rest-benchmark/pkg/db"
+)
+
+// environmentName returns the target environment of the run: --environment,
+// or the server addresses, which runs against the same servers share.
+func (o *runOptions) environmentName(global *globalOptions) string {
+	if o.environment != "" {
+		return o.environment
+	}
+	return fmt.Sprintf("grpc=%s rest=%s grpc-web=%s", global.grpcAddr, global.restAddr, global.grpcWebAddr)
+}
+
+// lockHolder describes this process to runs finding the environment busy.
+func lockHolder() string {
+	host, err := os.Hostname()
+	if err != nil {
+		host = "unknown host"
+	}
+	return fmt.Sprintf("%s pid %d", host, os.Getpid())
+}
+
+// lockEnvironment takes the target environment's lock for the run, failing
+// if another run holds it unless --allow-concurrent is set; then the
+// overlap is recorded with the run and as an event. Without the lock table
+// the run goes ahead unlocked.
+func (e *runEnv) lockEnvironment(ctx context.Context, global *globalOptions, opts *runOptions) error {
+	environment := opts.environmentName(global)
+	lock, err := e.dataset.LockEnvironment(ctx, environment, lockHolder())
+
+	var busy *db.EnvironmentBusyError
+	switch {
+	case errors.As(err, &busy) && opts.allowConcurrent:
+		holder := busy.Holder
+		if holder == "" {
+			holder = "another run"
+		}
+		e.concurrentWith = &holder
+		log.Printf("Warning: %v; running anyway (--allow-concurrent), the results of both runs may be skewed", busy)
+		event := &db.Event{Kind: db.EventNote, Message: fmt.Sprintf("Concurrent runs on %s: %s started while %s held it", environment, lockHolder(), holder)}
+		if err := e.dataset.RecordEvent(ctx, event); err != nil {
+			log.Printf("Warning: %v", err)
+		}
+		return nil
+	case errors.As(err, &busy):
+		return fmt.Errorf("%w (wait for it to finish, or pass --allow-concurrent to run anyway)", busy)
+	case err != nil:
+		log.Printf("Warning: %v, running without the environment lock", err)
+		return nil
+	}
+
+	e.envLock = lock
+	log.Printf("Locked environment %s", environment)
+	return nil
+}
+
+// unlockEnvironment releases the environment lock, if held.
+func (e *runEnv) unlockEnvironment() {
+	if e.envLock == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := e.envLock.Unlock(ctx); err != nil {
+		log.Printf("Warning: %v", err)
+	}
+	e.envLock = nil
+}
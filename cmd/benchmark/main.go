@@ -2,231 +2,198 @@ package main
 
 import (
 	"context"
-	"flag"
 	"fmt"
 	"log"
+	"net/http"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
-	"time"
 
+	"github.com/spf13/cobra"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+
+	"github.com/kaldun-tech/grpc-rest-benchmark/pkg/auth"
 	"github.com/kaldun-tech/grpc-rest-benchmark/pkg/db"
 )
 
-func main() {
-	// CLI flags
-	scenario := flag.String("scenario", "balance", "Benchmark scenario: balance | stream")
-	protocol := flag.String("protocol", "grpc", "Protocol to test: grpc | rest")
-	concurrency := flag.Int("concurrency", 10, "Number of parallel workers")
-	duration := flag.Duration("duration", 30*time.Second, "Test duration (e.g., 30s, 1m)")
-	rate := flag.Int("rate", 0, "Events per second for streaming (0 = unlimited)")
-	grpcAddr := flag.String("grpc-addr", "localhost:50051", "gRPC server address")
-	restAddr := flag.String("rest-addr", "http://localhost:8080", "REST server address")
-
-	// Timing replay flags (Phase 2d)
-	replayTiming := flag.String("replay-timing", "", "Path to HCS timing JSON file for realistic workload replay")
-	replayMode := flag.String("replay-mode", "sample", "Replay mode: sequential | sample")
-	replaySpeedup := flag.Float64("replay-speedup", 1.0, "Speedup factor for replay (1.0 = real-time, 10.0 = 10x faster)")
-
-	// HCS fetch flags (hcsreplay integration)
-	hcsTopic := flag.String("hcs-topic", "", "HCS topic ID to fetch timing from (e.g., 0.0.120438)")
-	hcsNetwork := flag.String("hcs-network", "mainnet", "Hedera network: mainnet | testnet | previewnet")
-	hcsLimit := flag.Int("hcs-limit", 1000, "Maximum number of HCS messages to fetch for timing")
-	hcsSavePath := flag.String("hcs-save", "", "Path to save fetched HCS timing data for reuse")
-
-	// Database flags
-	dbHost := flag.String("db-host", "localhost", "PostgreSQL host")
-	dbPort := flag.Int("db-port", 5432, "PostgreSQL port")
-	dbUser := flag.String("db-user", "benchmark", "PostgreSQL user")
-	dbPass := flag.String("db-pass", "benchmark_pass", "PostgreSQL password")
-	dbName := flag.String("db-name", "grpc_benchmark", "PostgreSQL database")
+// globalOptions holds flags shared by every subcommand.
+type globalOptions struct {
+	grpcAddr    string
+	grpcWebAddr string
+	restAddr    string
+	auth        auth.Config // authentication with the servers
+	db          db.Config
 
-	flag.Parse()
+	resultsBackend string // "postgres" or "local:FILE"
+}
 
-	// Validate inputs
-	if *scenario != "balance" && *scenario != "stream" {
-		log.Fatalf("Invalid scenario: %s (must be 'balance' or 'stream')", *scenario)
-	}
-	if *protocol != "grpc" && *protocol != "rest" {
-		log.Fatalf("Invalid protocol: %s (must be 'grpc' or 'rest')", *protocol)
-	}
-	if *concurrency < 1 {
-		log.Fatalf("Concurrency must be at least 1")
+func main() {
+	if err := newRootCmd().Execute(); err != nil {
+		os.Exit(1)
 	}
-	if *duration < time.Second {
-		log.Fatalf("Duration must be at least 1 second")
+}
+
+// newRootCmd builds the benchmark command tree.
+func newRootCmd() *cobra.Command {
+	opts := &globalOptions{}
+
+	root := &cobra.Command{
+		Use:   "benchmark",
+		Short: "gRPC vs REST benchmark client",
+		Long: `Benchmark client for comparing gRPC and REST performance on
+financial infrastructure workloads (balance queries, transaction streaming).`,
+		SilenceUsage: true,
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			if pass, ok := os.LookupEnv(db.PasswordEnv); ok && !cmd.Flags().Changed("db-pass") {
+				opts.db.Password = pass
+			}
+			return opts.auth.Validate()
+		},
 	}
 
-	ctx, cancel := context.WithCancel(context.Background())
-	defer cancel()
+	// Server flags
+	pf := root.PersistentFlags()
+	pf.StringVar(&opts.grpcAddr, "grpc-addr", "localhost:50051", "gRPC server address")
+	pf.StringVar(&opts.grpcWebAddr, "grpc-web-addr", "http://localhost:8081", "gRPC-Web server address")
+	pf.StringVar(&opts.restAddr, "rest-addr", "http://localhost:8080", "REST server address")
 
-	// Handle interrupt signals
-	sigCh := make(chan os.Signal, 1)
-	signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
-	go func() {
-		<-sigCh
-		log.Println("Received interrupt signal, stopping benchmark...")
-		cancel()
-	}()
+	// Authentication flags, matching the servers'
+	pf.StringVar(&opts.auth.Mode, "auth", auth.None, "Authentication with the servers: "+strings.Join(auth.Modes, ", ")+" (mtls needs https:// addresses)")
+	pf.StringVar(&opts.auth.Secret, "auth-secret", "", "API key (--auth=api-key) or JWT signing key (--auth=jwt) shared with the servers")
+	pf.StringVar(&opts.auth.CertFile, "tls-cert", "", "Client certificate (PEM) for --auth=mtls")
+	pf.StringVar(&opts.auth.KeyFile, "tls-key", "", "Private key (PEM) of --tls-cert")
+	pf.StringVar(&opts.auth.CAFile, "tls-ca", "", "CA certificate (PEM) the servers' certificates must be signed by, for --auth=mtls")
 
-	// Connect to database
-	dbCfg := db.Config{
-		Host:     *dbHost,
-		Port:     *dbPort,
-		User:     *dbUser,
-		Password: *dbPass,
-		Database: *dbName,
-	}
+	// Database flags
+	pf.StringVar(&opts.db.Host, "db-host", "localhost", "PostgreSQL host")
+	pf.IntVar(&opts.db.Port, "db-port", 5432, "PostgreSQL port")
+	pf.StringVar(&opts.db.User, "db-user", "benchmark", "PostgreSQL user")
+	pf.StringVar(&opts.db.Password, "db-pass", "benchmark_pass", "PostgreSQL password; $"+db.PasswordEnv+" is used when not given")
+	pf.StringVar(&opts.db.Database, "db-name", "grpc_benchmark", "PostgreSQL database")
+	pf.StringVar(&opts.resultsBackend, "results-backend", "postgres",
+		"Where results are stored: postgres | local:FILE (SQLite file for runs without PostgreSQL, uploaded later by 'benchmark sync')")
+
+	root.AddCommand(
+		newRunCmd(opts),
+		newCompareCmd(opts),
+		newCompareRunsCmd(opts),
+		newCompareGroupsCmd(opts),
+		newHeaderSweepCmd(opts),
+		newBundleCmd(opts),
+		newReportCmd(opts),
+		newPreflightCmd(opts),
+		newValidateCmd(opts),
+		newExportCmd(opts),
+		newSyncCmd(opts),
+		newEventsCmd(opts),
+		newDumpAccountsCmd(opts),
+		newDescribeCmd(),
+		newTimingCmd(),
+		newWorkerCmd(),
+		newCertsCmd(),
+	)
+
+	return root
+}
 
-	database, err := db.New(ctx, dbCfg)
+// connectDB opens a connection to the results database.
+func (o *globalOptions) connectDB(ctx context.Context) (*db.DB, error) {
+	database, err := db.New(ctx, o.db)
 	if err != nil {
-		log.Fatalf("Failed to connect to database: %v", err)
-	}
-	defer database.Close()
-	log.Printf("Connected to database %s@%s:%d", dbCfg.Database, dbCfg.Host, dbCfg.Port)
-
-	// Pre-fetch account IDs for balance scenario
-	var accountIDs []string
-	if *scenario == "balance" {
-		log.Println("Loading account IDs from database...")
-		accountIDs, err = database.GetAllAccountIDs(ctx)
-		if err != nil {
-			log.Fatalf("Failed to load account IDs: %v", err)
-		}
-		if len(accountIDs) == 0 {
-			log.Fatal("No accounts found in database. Run 'make seed' first.")
-		}
-		log.Printf("Loaded %d account IDs", len(accountIDs))
+		return nil, err
 	}
+	log.Printf("Connected to database %s@%s:%d", o.db.Database, o.db.Host, o.db.Port)
+	return database, nil
+}
 
-	// Create client based on protocol
-	var client BenchmarkClient
-	switch *protocol {
-	case "grpc":
-		client, err = NewGRPCClient(*grpcAddr)
-		if err != nil {
-			log.Fatalf("Failed to create gRPC client: %v", err)
-		}
-		log.Printf("Connected to gRPC server at %s", *grpcAddr)
-	case "rest":
-		client, err = NewHTTPClient(*restAddr)
-		if err != nil {
-			log.Fatalf("Failed to create HTTP client: %v", err)
-		}
-		log.Printf("Connected to REST server at %s", *restAddr)
+// grpcCredentials returns the transport credentials of connections to the
+// gRPC server outside the benchmark itself, such as health checks and
+// server stats: TLS with the client certificate for --auth=mtls, plaintext
+// otherwise. Those services take no bearer token.
+func (o *globalOptions) grpcCredentials() (grpc.DialOption, error) {
+	tlsCfg, err := o.auth.ClientTLS()
+	if err != nil {
+		return nil, err
 	}
-	defer client.Close()
-
-	// Create runner
-	runner := NewRunner(client, accountIDs, *concurrency, *rate)
-
-	// Load timing replay: either from file or by fetching from HCS topic
-	if *hcsTopic != "" {
-		// Fetch timing data directly from HCS topic
-		log.Printf("Fetching timing data from HCS topic %s on %s...", *hcsTopic, *hcsNetwork)
-		fetchCtx, fetchCancel := context.WithTimeout(ctx, 5*time.Minute)
-		timingData, err := FetchTimingData(fetchCtx, *hcsTopic, *hcsNetwork, *hcsLimit, func(count int) {
-			log.Printf("  Fetched %d messages...", count)
-		})
-		fetchCancel()
-		if err != nil {
-			log.Fatalf("Failed to fetch HCS timing data: %v", err)
-		}
-		log.Printf("Fetched %d messages from topic %s", timingData.MessageCount, *hcsTopic)
-
-		// Optionally save for reuse
-		if *hcsSavePath != "" {
-			if err := SaveTimingData(*hcsSavePath, timingData); err != nil {
-				log.Printf("Warning: failed to save timing data: %v", err)
-			} else {
-				log.Printf("Saved timing data to %s", *hcsSavePath)
-			}
-		}
-
-		tr := NewTimingReplay(timingData, *replayMode, *replaySpeedup)
-		runner.SetTimingReplay(tr)
-		tr.PrintSummary()
-		fmt.Println()
-	} else if *replayTiming != "" {
-		// Load timing data from file
-		timingData, err := LoadTimingData(*replayTiming)
-		if err != nil {
-			log.Fatalf("Failed to load timing data: %v", err)
-		}
-		tr := NewTimingReplay(timingData, *replayMode, *replaySpeedup)
-		runner.SetTimingReplay(tr)
-		tr.PrintSummary()
-		fmt.Println()
+	if tlsCfg == nil {
+		return grpc.WithTransportCredentials(insecure.NewCredentials()), nil
 	}
+	return grpc.WithTransportCredentials(credentials.NewTLS(tlsCfg)), nil
+}
 
-	// Setup results collector
-	results := NewResults()
-
-	// Setup resource monitor
-	resourceMonitor, err := NewResourceMonitor(100 * time.Millisecond)
+// httpClient returns the HTTP client of requests to the servers outside the
+// benchmark itself, like grpcCredentials.
+func (o *globalOptions) httpClient() (*http.Client, error) {
+	tlsCfg, err := o.auth.ClientTLS()
 	if err != nil {
-		log.Printf("Warning: could not initialize resource monitor: %v", err)
+		return nil, err
+	}
+	if tlsCfg == nil {
+		return http.DefaultClient, nil
 	}
+	return &http.Client{Transport: &http.Transport{TLSClientConfig: tlsCfg}}, nil
+}
 
-	// Create context with timeout for benchmark duration
-	benchCtx, benchCancel := context.WithTimeout(ctx, *duration)
-	defer benchCancel()
+// localResultsPath returns the file named by --results-backend=local:FILE,
+// or "" when results go to PostgreSQL.
+func (o *globalOptions) localResultsPath() (string, error) {
+	if o.resultsBackend == "postgres" {
+		return "", nil
+	}
+	path, ok := strings.CutPrefix(o.resultsBackend, "local:")
+	if !ok || path == "" {
+		return "", fmt.Errorf("invalid results backend: %s (must be postgres or local:FILE)", o.resultsBackend)
+	}
+	return path, nil
+}
 
-	// Run benchmark
-	fmt.Printf("\nStarting %s benchmark (%s protocol)\n", *scenario, *protocol)
-	fmt.Printf("Concurrency: %d | Duration: %s", *concurrency, *duration)
-	if *scenario == "stream" && *rate > 0 {
-		fmt.Printf(" | Rate limit: %d events/s", *rate)
+// openResults opens the results store selected by --results-backend.
+func (o *globalOptions) openResults(ctx context.Context) (db.ResultsStore, error) {
+	path, err := o.localResultsPath()
+	if err != nil {
+		return nil, err
 	}
-	if *replayTiming != "" || *hcsTopic != "" {
-		fmt.Printf(" | Replay: %s (%.1fx)", *replayMode, *replaySpeedup)
+	if path == "" {
+		database, err := o.connectDB(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to connect to database: %w", err)
+		}
+		return database, nil
 	}
-	fmt.Println()
 
-	// Start resource monitoring
-	var stopResourceMonitor func() ResourceStats
-	if resourceMonitor != nil {
-		stopResourceMonitor = resourceMonitor.Start(benchCtx)
+	local, err := db.OpenLocal(ctx, path)
+	if err != nil {
+		return nil, err
 	}
+	log.Printf("Using local results file %s", path)
+	return local, nil
+}
 
-	results.SetStartTime(time.Now())
+// signalContext returns a context that is cancelled on SIGINT or SIGTERM.
+func signalContext() (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(context.Background())
 
-	// Start results collector in background
-	done := make(chan struct{})
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
 	go func() {
-		results.Collect(runner.Results())
-		close(done)
+		select {
+		case <-sigCh:
+			log.Println("Received interrupt signal, stopping benchmark...")
+			cancel()
+		case <-ctx.Done():
+		}
+		signal.Stop(sigCh)
 	}()
 
-	// Run the benchmark
-	switch *scenario {
-	case "balance":
-		runner.RunBalance(benchCtx)
-	case "stream":
-		runner.RunStream(benchCtx)
-	}
-
-	// Wait for collector to finish
-	<-done
-
-	results.SetEndTime(time.Now())
-
-	// Stop resource monitoring and record stats
-	if stopResourceMonitor != nil {
-		resourceStats := stopResourceMonitor()
-		results.SetResourceStats(resourceStats)
-	}
-
-	// Print summary
-	results.PrintSummary(*scenario, *protocol, *concurrency)
-
-	// Store results in database
-	var rateLimit *int
-	if *scenario == "stream" && *rate > 0 {
-		rateLimit = rate
-	}
+	return ctx, cancel
+}
 
-	if err := results.StoreResults(ctx, database, *scenario, *protocol, *concurrency, rateLimit); err != nil {
-		log.Printf("Warning: failed to store results: %v", err)
+// fixedCompletion returns a flag completion function for a fixed set of values.
+func fixedCompletion(values []string) func(*cobra.Command, []string, string) ([]string, cobra.ShellCompDirective) {
+	return func(*cobra.Command, []string, string) ([]string, cobra.ShellCompDirective) {
+		return values, cobra.ShellCompDirectiveNoFileComp
 	}
 }
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"fmt"
+	"runtime"
+	"slices"
+	"strings"
+
+	"github.com/kaldun-tech/grpc-rest-benchmark/pkg/buildinfo"
+	"github.com/kaldun-tech/grpc-rest-benchmark/pkg/db"
+)
+
+// tagsLabel returns the --tag pairs sorted by key and comma-separated, ""
+// for none.
+func (o *runOptions) tagsLabel() (string, error) {
+	tags := make([]string, 0, len(o.tags))
+	keys := make(map[string]bool, len(o.tags))
+	for _, tag := range o.tags {
+		key, value, ok := strings.Cut(tag, "=")
+		if !ok || key == "" || strings.ContainsAny(tag, ", ") || strings.Contains(value, "=") {
+			return "", fmt.Errorf("invalid tag: %q (must be key=value without commas or spaces)", tag)
+		}
+		if keys[key] {
+			return "", fmt.Errorf("tag %s given twice", key)
+		}
+		keys[key] = true
+		tags = append(tags, tag)
+	}
+	slices.Sort(tags)
+	return strings.Join(tags, ","), nil
+}
+
+// recordOrigin records where the run came from: the client's and server's
+// commits, the client's Go version, GOMAXPROCS, kernel and hostname, and
+// the --tag pairs and the registered environment. With remote workers the
+// host is the coordinator's.
+func recordOrigin(run *db.BenchmarkRun, opts *runOptions, env *runEnv) {
+	if commit := buildinfo.Commit(); commit != "" {
+		run.ClientCommit = &commit
+	}
+	if server, ok := env.serverConfigs[serverName(opts.protocol)]; ok && server.Commit != "" {
+		run.ServerCommit = &server.Commit
+	}
+	goVersion := runtime.Version()
+	run.GoVersion = &goVersion
+	procs := runtime.GOMAXPROCS(0)
+	run.GOMAXPROCS = &procs
+	kernel := buildinfo.Kernel()
+	run.Kernel = &kernel
+	if host := buildinfo.Hostname(); host != "" {
+		run.Hostname = &host
+	}
+	if tags, _ := opts.tagsLabel(); tags != "" {
+		run.Tags = &tags
+	}
+	if opts.registeredEnvironment {
+		run.Environment = &opts.environment
+	}
+}
@@ -0,0 +1,230 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"maps"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kaldun-tech/grpc-rest-benchmark/pkg/db"
+	"github.com/kaldun-tech/grpc-rest-benchmark/pkg/profiling"
+	"github.com/kaldun-tech/grpc-rest-benchmark/pkg/protos"
+	"google.golang.org/grpc"
+)
+
+const (
+	// profileTimeout bounds capturing a server profile beyond the CPU
+	// profile's duration.
+	profileTimeout = 30 * time.Second
+
+	// maxProfileBytes is the largest server profile the client accepts.
+	maxProfileBytes = 64 << 20
+)
+
+// capturesProfiles reports whether the run captures pprof profiles.
+func (o *runOptions) capturesProfiles() bool {
+	return o.profileCPU || o.profileMem
+}
+
+// runProfiles collects the pprof profiles of a run by name, e.g.
+// "server-cpu".
+type runProfiles struct {
+	mu       sync.Mutex
+	profiles map[string][]byte
+
+	wg         sync.WaitGroup
+	stopClient context.CancelFunc
+}
+
+// startProfiles starts the CPU profiles of the run with --profile-cpu: of
+// the server behind the protocol and of this process, over the run's
+// duration. It returns nil if the run captures no profiles.
+func startProfiles(ctx context.Context, global *globalOptions, opts *runOptions) *runProfiles {
+	if !opts.capturesProfiles() {
+		return nil
+	}
+	p := &runProfiles{profiles: make(map[string][]byte), stopClient: func() {}}
+	if !opts.profileCPU {
+		return p
+	}
+
+	duration := opts.sizing().RunDuration()
+	// The reference scenario's stub runs in this process
+	if opts.scenario != "reference" {
+		p.capture(ctx, "server", profiling.CPU, func() ([]byte, error) {
+			return serverProfile(ctx, global, opts.protocol, profiling.CPU, duration)
+		})
+	}
+	clientCtx, cancel := context.WithCancel(ctx)
+	p.stopClient = cancel
+	p.capture(ctx, "client", profiling.CPU, func() ([]byte, error) {
+		var buf bytes.Buffer
+		err := profiling.Capture(clientCtx, &buf, profiling.CPU, duration)
+		return buf.Bytes(), err
+	})
+	return p
+}
+
+// capture runs fn in the background and keeps the profile it returns.
+func (p *runProfiles) capture(ctx context.Context, source, kind string, fn func() ([]byte, error)) {
+	p.wg.Add(1)
+	go func() {
+		defer p.wg.Done()
+		data, err := fn()
+		if err != nil {
+			warnf(ctx, "failed to capture %s %s profile: %v", source, kind, err)
+			return
+		}
+		p.mu.Lock()
+		p.profiles[profiling.Name(source, kind)] = data
+		p.mu.Unlock()
+	}()
+}
+
+// stop ends the CPU profiles once the run is over, waiting for the
+// server's, and takes the heap profiles with --profile-mem.
+func (p *runProfiles) stop(ctx context.Context, global *globalOptions, opts *runOptions) {
+	if p == nil {
+		return
+	}
+	p.stopClient()
+	p.wg.Wait()
+	if !opts.profileMem {
+		return
+	}
+	if opts.scenario != "reference" {
+		p.capture(ctx, "server", profiling.Heap, func() ([]byte, error) {
+			return serverProfile(ctx, global, opts.protocol, profiling.Heap, 0)
+		})
+	}
+	p.capture(ctx, "client", profiling.Heap, func() ([]byte, error) {
+		var buf bytes.Buffer
+		err := profiling.Capture(ctx, &buf, profiling.Heap, 0)
+		return buf.Bytes(), err
+	})
+	p.wg.Wait()
+}
+
+// save writes the profiles to --profile-dir, named after the run ID or, for
+// runs that were not stored, the time and protocol, and with
+// --attach-profiles stores them with the run.
+func (p *runProfiles) save(ctx context.Context, opts *runOptions, env *runEnv, runID int64) {
+	if p == nil || len(p.profiles) == 0 {
+		return
+	}
+	names := slices.Sorted(maps.Keys(p.profiles))
+
+	if opts.profileDir != "" {
+		prefix := fmt.Sprintf("%s-%s", time.Now().Format("20060102-150405"), opts.protocol)
+		if runID > 0 {
+			prefix = fmt.Sprintf("run-%d", runID)
+		}
+		if err := os.MkdirAll(opts.profileDir, 0755); err != nil {
+			warnf(ctx, "failed to create profile directory: %v", err)
+		} else {
+			for _, name := range names {
+				path := filepath.Join(opts.profileDir, fmt.Sprintf("%s-%s.pprof", prefix, name))
+				if err := os.WriteFile(path, p.profiles[name], 0644); err != nil {
+					warnf(ctx, "failed to write profile: %v", err)
+					continue
+				}
+				fmt.Printf("Profile written to %s (go tool pprof -http=: %s)\n", path, path)
+			}
+		}
+	}
+
+	if !opts.attachProfiles {
+		return
+	}
+	database, ok := env.results.(*db.DB)
+	if !ok || runID == 0 {
+		warnf(ctx, "profiles are attached to runs stored in PostgreSQL only, not attached")
+		return
+	}
+	for _, name := range names {
+		if err := database.AttachProfile(ctx, runID, name, p.profiles[name]); err != nil {
+			warnf(ctx, "%v", err)
+			continue
+		}
+		log.Printf("Attached profile %s to run %d", name, runID)
+	}
+}
+
+// serverProfile captures a profile of the server behind protocol: the gRPC
+// server for grpc and grpc-web, the REST server for rest and connect.
+func serverProfile(ctx context.Context, global *globalOptions, protocol, kind string, duration time.Duration) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(ctx, duration+profileTimeout)
+	defer cancel()
+
+	if serverName(protocol) == db.ServerGRPC {
+		creds, err := global.grpcCredentials()
+		if err != nil {
+			return nil, err
+		}
+		return grpcProfile(ctx, creds, global.grpcAddr, kind, duration)
+	}
+	client, err := global.httpClient()
+	if err != nil {
+		return nil, err
+	}
+	return restProfile(ctx, client, global.restAddr, kind, duration)
+}
+
+// grpcProfile calls the gRPC server's AdminService.
+func grpcProfile(ctx context.Context, creds grpc.DialOption, addr, kind string, duration time.Duration) ([]byte, error) {
+	conn, err := grpc.NewClient(addr, creds)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	profile, err := protos.NewAdminServiceClient(conn).CaptureProfile(ctx,
+		&protos.ProfileRequest{Kind: kind, DurationMs: duration.Milliseconds()},
+		grpc.MaxCallRecvMsgSize(maxProfileBytes),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to capture gRPC server profile: %w", err)
+	}
+	return profile.Data, nil
+}
+
+// restProfile calls GET /api/v1/admin/profile on the REST server.
+func restProfile(ctx context.Context, client *http.Client, baseURL, kind string, duration time.Duration) ([]byte, error) {
+	query := url.Values{"kind": {kind}}
+	if kind == profiling.CPU {
+		// The endpoint takes whole seconds
+		query.Set("seconds", strconv.Itoa(max(1, int(duration.Round(time.Second).Seconds()))))
+	}
+	u := strings.TrimSuffix(baseURL, "/") + "/api/v1/admin/profile?" + query.Encode()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to capture REST server profile: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		var e struct {
+			Error string `json:"error"`
+		}
+		json.NewDecoder(resp.Body).Decode(&e)
+		return nil, fmt.Errorf("failed to capture REST server profile: status %d: %s", resp.StatusCode, e.Error)
+	}
+	return io.ReadAll(io.LimitReader(resp.Body, maxProfileBytes))
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestRESTProfile(t *testing.T) {
+	var query string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/admin/profile" {
+			http.NotFound(w, r)
+			return
+		}
+		query = r.URL.RawQuery
+		w.Write([]byte("profile"))
+	}))
+	defer srv.Close()
+
+	got, err := restProfile(context.Background(), http.DefaultClient, srv.URL+"/", "cpu", 1500*time.Millisecond)
+	if err != nil {
+		t.Fatalf("restProfile() error = %v", err)
+	}
+	if string(got) != "profile" {
+		t.Errorf("restProfile() = %q, want the served profile", got)
+	}
+	if want := "kind=cpu&seconds=2"; query != want {
+		t.Errorf("query = %q, want %q", query, want)
+	}
+
+	old := httptest.NewServer(http.NotFoundHandler())
+	defer old.Close()
+	if _, err := restProfile(context.Background(), http.DefaultClient, old.URL, "heap", 0); err == nil {
+		t.Error("restProfile() against a server without the endpoint succeeded")
+	}
+}
+
+func TestRunProfiles_Save(t *testing.T) {
+	dir := t.TempDir()
+	opts := &runOptions{protocol: "rest", profileCPU: true, profileDir: dir}
+	p := &runProfiles{profiles: map[string][]byte{"client-cpu": []byte("client"), "server-cpu": []byte("server")}}
+	p.save(context.Background(), opts, &runEnv{}, 42)
+
+	for name, want := range map[string]string{"run-42-client-cpu.pprof": "client", "run-42-server-cpu.pprof": "server"} {
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil || string(data) != want {
+			t.Errorf("%s = %q, %v, want %q", name, data, err, want)
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/kaldun-tech/grpc-rest-benchmark/pkg/db"
+	"github.com/kaldun-tech/grpc-rest-benchmark/pkg/protos"
+	"google.golang.org/grpc"
+)
+
+// restartTimeout bounds requesting a server restart, which the server
+// acknowledges before it begins.
+const restartTimeout = 5 * time.Second
+
+// restartServer asks the server behind protocol to restart gracefully and
+// stay down for downtime once drained: the gRPC server for grpc and
+// grpc-web, the REST server for rest and connect.
+func restartServer(ctx context.Context, global *globalOptions, protocol string, downtime time.Duration) error {
+	ctx, cancel := context.WithTimeout(ctx, restartTimeout)
+	defer cancel()
+
+	if serverName(protocol) == db.ServerGRPC {
+		creds, err := global.grpcCredentials()
+		if err != nil {
+			return err
+		}
+		return grpcRestart(ctx, creds, global.grpcAddr, downtime)
+	}
+	client, err := global.httpClient()
+	if err != nil {
+		return err
+	}
+	return restRestart(ctx, client, global.restAddr, downtime)
+}
+
+// grpcRestart calls the gRPC server's AdminService.
+func grpcRestart(ctx context.Context, creds grpc.DialOption, addr string, downtime time.Duration) error {
+	conn, err := grpc.NewClient(addr, creds)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if _, err := protos.NewAdminServiceClient(conn).Restart(ctx, &protos.RestartRequest{DowntimeMs: downtime.Milliseconds()}); err != nil {
+		return fmt.Errorf("failed to restart gRPC server: %w", err)
+	}
+	return nil
+}
+
+// restRestart calls POST /api/v1/admin/restart on the REST server.
+func restRestart(ctx context.Context, client *http.Client, baseURL string, downtime time.Duration) error {
+	body, err := json.Marshal(map[string]int64{"downtime_ms": downtime.Milliseconds()})
+	if err != nil {
+		return err
+	}
+	url := strings.TrimSuffix(baseURL, "/") + "/api/v1/admin/restart"
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to restart REST server: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusAccepted {
+		var e struct {
+			Error string `json:"error"`
+		}
+		json.NewDecoder(resp.Body).Decode(&e)
+		return fmt.Errorf("failed to restart REST server: status %d: %s", resp.StatusCode, e.Error)
+	}
+	return nil
+}
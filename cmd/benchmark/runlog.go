@@ -0,0 +1,53 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/kaldun-tech/grpc-rest-benchmark/pkg/bench"
+)
+
+// warnf prints a warning to the console and records it in the run log.
+func warnf(ctx context.Context, format string, args ...any) {
+	msg := fmt.Sprintf(format, args...)
+	log.Printf("Warning: %s", msg)
+	bench.LoggerFrom(ctx).Warn(msg)
+}
+
+// RunLog is a per-run structured (JSON lines) log file. It captures the run
+// configuration, warnings, stream errors and interim stats so odd results can
+// be debugged after the fact.
+type RunLog struct {
+	Path   string
+	Logger *slog.Logger
+	file   *os.File
+}
+
+// OpenRunLog creates a new log file in dir named after the start time.
+func OpenRunLog(dir string, start time.Time) (*RunLog, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create log directory: %w", err)
+	}
+
+	path := filepath.Join(dir, fmt.Sprintf("run-%s.jsonl", start.Format("20060102-150405.000")))
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create run log: %w", err)
+	}
+
+	return &RunLog{
+		Path:   path,
+		Logger: slog.New(slog.NewJSONHandler(f, &slog.HandlerOptions{Level: slog.LevelDebug})),
+		file:   f,
+	}, nil
+}
+
+// Close flushes and closes the log file.
+func (l *RunLog) Close() error {
+	return l.file.Close()
+}
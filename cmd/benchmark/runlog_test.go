@@ -0,0 +1,54 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kaldun-tech/grpc-rest-benchmark/pkg/bench"
+)
+
+func TestOpenRunLog(t *testing.T) {
+	dir := t.TempDir()
+
+	runLog, err := OpenRunLog(dir, time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("OpenRunLog() error = %v", err)
+	}
+
+	ctx := bench.WithLogger(context.Background(), runLog.Logger)
+	bench.LoggerFrom(ctx).Info("run config", "scenario", "balance")
+	warnf(ctx, "something odd: %d", 42)
+
+	if err := runLog.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	if !strings.HasSuffix(runLog.Path, "run-20250102-030405.000.jsonl") {
+		t.Errorf("Path = %q, want run-20250102-030405.000.jsonl suffix", runLog.Path)
+	}
+
+	data, err := os.ReadFile(runLog.Path)
+	if err != nil {
+		t.Fatalf("Failed to read run log: %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("run log has %d lines, want 2", len(lines))
+	}
+
+	var entry map[string]any
+	if err := json.Unmarshal([]byte(lines[1]), &entry); err != nil {
+		t.Fatalf("run log line is not JSON: %v", err)
+	}
+	if entry["level"] != "WARN" {
+		t.Errorf("level = %v, want WARN", entry["level"])
+	}
+	if entry["msg"] != "something odd: 42" {
+		t.Errorf("msg = %v, want %q", entry["msg"], "something odd: 42")
+	}
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/kaldun-tech/grpc-rest-benchmark/pkg/db"
+	"github.com/kaldun-tech/grpc-rest-benchmark/pkg/export"
+)
+
+// SampleFlushFile writes the samples of a run to a Parquet file as they are
+// collected, for --sample-flush. Rotating completes the file, renames it
+// after the segment it holds and starts a new one at the same path, so the
+// samples of ended segments stay readable even if the run is killed.
+type SampleFlushFile struct {
+	Path string
+	file *os.File
+	w    *export.SampleWriter
+}
+
+// OpenSampleFlushFile creates the file at path, and its directory.
+func OpenSampleFlushFile(path string) (*SampleFlushFile, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create sample flush directory: %w", err)
+	}
+	s := &SampleFlushFile{Path: path}
+	if err := s.open(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *SampleFlushFile) open() error {
+	f, err := os.Create(s.Path)
+	if err != nil {
+		return fmt.Errorf("failed to create sample flush file: %w", err)
+	}
+	s.file, s.w = f, export.NewSampleWriter(f)
+	return nil
+}
+
+// Flush writes one sample to the current file.
+func (s *SampleFlushFile) Flush(sample *db.BenchmarkSample) error {
+	return s.w.Write(sample)
+}
+
+// Rotate completes the current file as that of segment and starts the next.
+func (s *SampleFlushFile) Rotate(segment int) error {
+	n := s.w.Count()
+	if err := s.Close(); err != nil {
+		return err
+	}
+	path := segmentPath(s.Path, segment)
+	if err := os.Rename(s.Path, path); err != nil {
+		return fmt.Errorf("failed to rotate sample flush file: %w", err)
+	}
+	log.Printf("Flushed %d samples of segment %d to %s", n, segment, path)
+	return s.open()
+}
+
+// Close completes the current file, which keeps the samples of the last
+// segment at Path. It does nothing if a failed rotation left no file open.
+func (s *SampleFlushFile) Close() error {
+	if s.file == nil {
+		return nil
+	}
+	f, w := s.file, s.w
+	s.file, s.w = nil, nil
+	if err := w.Close(); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to write sample flush file: %w", err)
+	}
+	return f.Close()
+}
+
+// segmentPath names the rotated file of segment after path, e.g.
+// samples.3.parquet for segment 3 of samples.parquet.
+func segmentPath(path string, segment int) string {
+	ext := filepath.Ext(path)
+	return fmt.Sprintf("%s.%d%s", strings.TrimSuffix(path, ext), segment, ext)
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/parquet-go/parquet-go"
+
+	"github.com/kaldun-tech/grpc-rest-benchmark/pkg/db"
+	"github.com/kaldun-tech/grpc-rest-benchmark/pkg/export"
+)
+
+func TestSampleFlushFile_Rotate(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "soak", "samples.parquet")
+	flush, err := OpenSampleFlushFile(path)
+	if err != nil {
+		t.Fatalf("OpenSampleFlushFile() error = %v", err)
+	}
+
+	write := func(n int) {
+		t.Helper()
+		for range n {
+			if err := flush.Flush(&db.BenchmarkSample{LatencyMs: 1, Success: true, Timestamp: time.Now()}); err != nil {
+				t.Fatalf("Flush() error = %v", err)
+			}
+		}
+	}
+	write(3)
+	if err := flush.Rotate(1); err != nil {
+		t.Fatalf("Rotate() error = %v", err)
+	}
+	write(2)
+	if err := flush.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	if err := flush.Close(); err != nil {
+		t.Errorf("second Close() error = %v, want nil", err)
+	}
+
+	for file, want := range map[string]int{filepath.Join(filepath.Dir(path), "samples.1.parquet"): 3, path: 2} {
+		f, err := os.Open(file)
+		if err != nil {
+			t.Fatalf("Failed to open %s: %v", file, err)
+		}
+		defer f.Close()
+		info, err := f.Stat()
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := parquet.Read[export.Sample](f, info.Size())
+		if err != nil {
+			t.Fatalf("parquet.Read(%s) error = %v", file, err)
+		}
+		if len(got) != want {
+			t.Errorf("%s holds %d samples, want %d", file, len(got), want)
+		}
+	}
+}
+
+func TestSegmentPath(t *testing.T) {
+	tests := map[string]string{
+		"samples.parquet":      "samples.4.parquet",
+		"out/soak.run.parquet": "out/soak.run.4.parquet",
+		"samples":              "samples.4",
+	}
+	for path, want := range tests {
+		if got := segmentPath(path, 4); got != want {
+			t.Errorf("segmentPath(%q, 4) = %q, want %q", path, got, want)
+		}
+	}
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/kaldun-tech/grpc-rest-benchmark/pkg/bench"
+	"github.com/kaldun-tech/grpc-rest-benchmark/pkg/db"
+	"github.com/kaldun-tech/grpc-rest-benchmark/pkg/protos"
+	"google.golang.org/grpc"
+)
+
+// serverStatsTimeout bounds each server stats reading, so an older server
+// without the endpoint does not hold up the run.
+const serverStatsTimeout = 5 * time.Second
+
+// serverStats reads the CPU time used so far and the balance cache counters
+// of the server behind protocol: the gRPC server for grpc and grpc-web, the
+// REST server for rest and connect.
+func serverStats(ctx context.Context, global *globalOptions, protocol string) (bench.ServerStats, error) {
+	ctx, cancel := context.WithTimeout(ctx, serverStatsTimeout)
+	defer cancel()
+
+	if serverName(protocol) == db.ServerGRPC {
+		creds, err := global.grpcCredentials()
+		if err != nil {
+			return bench.ServerStats{}, err
+		}
+		return grpcServerStats(ctx, creds, global.grpcAddr)
+	}
+	client, err := global.httpClient()
+	if err != nil {
+		return bench.ServerStats{}, err
+	}
+	return restServerStats(ctx, client, global.restAddr)
+}
+
+// grpcServerStats queries the gRPC server's ServerStatsService.
+func grpcServerStats(ctx context.Context, creds grpc.DialOption, addr string) (bench.ServerStats, error) {
+	conn, err := grpc.NewClient(addr, creds)
+	if err != nil {
+		return bench.ServerStats{}, err
+	}
+	defer conn.Close()
+
+	stats, err := protos.NewServerStatsServiceClient(conn).GetServerStats(ctx, &protos.ServerStatsRequest{})
+	if err != nil {
+		return bench.ServerStats{}, fmt.Errorf("failed to get gRPC server stats: %w", err)
+	}
+	return bench.ServerStats{
+		CPUSeconds:          stats.CpuSeconds,
+		CacheHits:           stats.CacheHits,
+		CacheMisses:         stats.CacheMisses,
+		PoolAcquireWaits:    stats.PoolAcquireWaits,
+		PoolAcquireFailures: stats.PoolAcquireFailures,
+	}, nil
+}
+
+// restServerStats queries the REST server's /api/v1/server-stats.
+func restServerStats(ctx context.Context, client *http.Client, baseURL string) (bench.ServerStats, error) {
+	url := strings.TrimSuffix(baseURL, "/") + "/api/v1/server-stats"
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return bench.ServerStats{}, err
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return bench.ServerStats{}, fmt.Errorf("failed to get REST server stats: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return bench.ServerStats{}, fmt.Errorf("failed to get REST server stats: unexpected status %d", resp.StatusCode)
+	}
+	var stats struct {
+		CPUSeconds  float64 `json:"cpu_seconds"`
+		CacheHits   int64   `json:"cache_hits"`
+		CacheMisses int64   `json:"cache_misses"`
+
+		PoolAcquireWaits    int64 `json:"pool_acquire_waits"`
+		PoolAcquireFailures int64 `json:"pool_acquire_failures"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&stats); err != nil {
+		return bench.ServerStats{}, fmt.Errorf("failed to decode REST server stats: %w", err)
+	}
+	return bench.ServerStats{
+		CPUSeconds:          stats.CPUSeconds,
+		CacheHits:           stats.CacheHits,
+		CacheMisses:         stats.CacheMisses,
+		PoolAcquireWaits:    stats.PoolAcquireWaits,
+		PoolAcquireFailures: stats.PoolAcquireFailures,
+	}, nil
+}
@@ -0,0 +1,35 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/kaldun-tech/grpc-rest-benchmark/pkg/bench"
+)
+
+func TestRESTServerStats(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/server-stats" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(`{"cpu_seconds":12.5,"cache_hits":90,"cache_misses":10,"pool_acquire_waits":7,"pool_acquire_failures":2}`))
+	}))
+	defer srv.Close()
+
+	got, err := restServerStats(context.Background(), http.DefaultClient, srv.URL+"/")
+	if err != nil {
+		t.Fatalf("restServerStats() error = %v", err)
+	}
+	if want := (bench.ServerStats{CPUSeconds: 12.5, CacheHits: 90, CacheMisses: 10, PoolAcquireWaits: 7, PoolAcquireFailures: 2}); got != want {
+		t.Errorf("restServerStats() = %+v, want %+v", got, want)
+	}
+
+	old := httptest.NewServer(http.NotFoundHandler())
+	defer old.Close()
+	if _, err := restServerStats(context.Background(), http.DefaultClient, old.URL); err == nil {
+		t.Error("restServerStats() against a server without the endpoint succeeded")
+	}
+}
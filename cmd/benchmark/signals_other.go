@@ -0,0 +1,11 @@
+//go:build !unix
+
+package main
+
+import "github.com/kaldun-tech/grpc-rest-benchmark/pkg/bench"
+
+// interimSignals returns no interim requests: SIGUSR1 and SIGUSR2 only
+// exist on Unix.
+func interimSignals() (requests <-chan bench.Interim, stop func()) {
+	return nil, func() {}
+}
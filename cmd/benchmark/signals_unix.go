@@ -0,0 +1,44 @@
+//go:build unix
+
+package main
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/kaldun-tech/grpc-rest-benchmark/pkg/bench"
+)
+
+// interimSignals returns a channel of the interim requests of SIGUSR1
+// (summarize the run so far) and SIGUSR2 (rotate its segment), so long runs
+// can be checked on with kill. Call stop when the run ends.
+func interimSignals() (requests <-chan bench.Interim, stop func()) {
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, syscall.SIGUSR1, syscall.SIGUSR2)
+	reqCh := make(chan bench.Interim, 4)
+	done := make(chan struct{})
+	go func() {
+		for {
+			select {
+			case sig := <-sigCh:
+				req := bench.InterimSummary
+				if sig == syscall.SIGUSR2 {
+					req = bench.InterimRotate
+				}
+				// Dropped while the run is still answering others
+				select {
+				case reqCh <- req:
+				default:
+				}
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	return reqCh, func() {
+		signal.Stop(sigCh)
+		close(done)
+	}
+}
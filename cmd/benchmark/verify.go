@@ -0,0 +1,73 @@
+package main
+
+import (
+	"context"
+	"slices"
+
+	"github.com/kaldun-tech/grpc-rest-benchmark/pkg/bench"
+	"github.com/kaldun-tech/grpc-rest-benchmark/pkg/dbtarget"
+	"github.com/kaldun-tech/grpc-rest-benchmark/pkg/serverlog"
+)
+
+// transactionCounts are the rows of the transactions table, counted before
+// and after a run to check stream and write invariants against the
+// database.
+type transactionCounts struct {
+	total     int64
+	submitted int64
+}
+
+// submitsTransactions reports whether the run submits transactions.
+func (o *runOptions) submitsTransactions() bool {
+	if o.scenario == "mixed" {
+		return slices.ContainsFunc(o.operationMix(), func(op bench.MixOperation) bool { return op.Scenario == "write" })
+	}
+	return o.scenario == "write" || o.scenario == "fanout"
+}
+
+// countTransactions counts the transactions in the dataset database if the
+// run's invariants need them. It returns nil if they do not, or if that is
+// not the database the server uses, as it was switched to a database
+// target.
+func countTransactions(ctx context.Context, env *runEnv, opts *runOptions) *transactionCounts {
+	if env.dataset == nil || (opts.dbTarget != "" && opts.dbTarget != dbtarget.Default) {
+		return nil
+	}
+	if opts.sizing().StreamSubscribers() == 0 && !opts.submitsTransactions() {
+		return nil
+	}
+
+	var counts transactionCounts
+	var err error
+	if counts.total, err = env.dataset.GetTransactionCount(ctx); err != nil {
+		warnf(ctx, "stream and write invariants not checked: %v", err)
+		return nil
+	}
+	if counts.submitted, err = env.dataset.GetSubmittedCount(ctx); err != nil {
+		warnf(ctx, "stream and write invariants not checked: %v", err)
+		return nil
+	}
+	return &counts
+}
+
+// serverFacts gathers what a run is verified against: the panics in the
+// server logs, and the transactions table before and after the run.
+func serverFacts(ctx context.Context, env *runEnv, opts *runOptions, before *transactionCounts, serverLog *serverlog.Summary) bench.ServerFacts {
+	var facts bench.ServerFacts
+	if serverLog != nil {
+		facts.LogFatal = &serverLog.Fatal
+	}
+	if before == nil {
+		return facts
+	}
+	if opts.sizing().StreamSubscribers() > 0 {
+		facts.TransactionRows = &before.total
+	}
+	if opts.submitsTransactions() {
+		if after := countTransactions(ctx, env, opts); after != nil {
+			added := after.submitted - before.submitted
+			facts.SubmittedRows = &added
+		}
+	}
+	return facts
+}
@@ -1,45 +1,134 @@
 package main
 
 import (
+	"bytes"
 	"context"
+	"errors"
 	"flag"
 	"fmt"
 	"log"
 	"net"
+	"net/http"
 	"os"
 	"os/signal"
+	"strconv"
+	"strings"
 	"syscall"
 	"time"
 
+	"github.com/improbable-eng/grpc-web/go/grpcweb"
+	"github.com/kaldun-tech/grpc-rest-benchmark/pkg/auth"
+	"github.com/kaldun-tech/grpc-rest-benchmark/pkg/buildinfo"
+	"github.com/kaldun-tech/grpc-rest-benchmark/pkg/cache"
+	_ "github.com/kaldun-tech/grpc-rest-benchmark/pkg/compression" // registers deflate and zstd alongside gzip
 	"github.com/kaldun-tech/grpc-rest-benchmark/pkg/db"
+	"github.com/kaldun-tech/grpc-rest-benchmark/pkg/dbtarget"
+	"github.com/kaldun-tech/grpc-rest-benchmark/pkg/fanout"
+	"github.com/kaldun-tech/grpc-rest-benchmark/pkg/fault"
+	"github.com/kaldun-tech/grpc-rest-benchmark/pkg/feed"
+	"github.com/kaldun-tech/grpc-rest-benchmark/pkg/middleware"
+	"github.com/kaldun-tech/grpc-rest-benchmark/pkg/payload"
+	"github.com/kaldun-tech/grpc-rest-benchmark/pkg/profiling"
 	"github.com/kaldun-tech/grpc-rest-benchmark/pkg/protos"
+	"github.com/kaldun-tech/grpc-rest-benchmark/pkg/qos"
+	"github.com/kaldun-tech/grpc-rest-benchmark/pkg/ratelimit"
+	"github.com/kaldun-tech/grpc-rest-benchmark/pkg/restart"
+	"github.com/kaldun-tech/grpc-rest-benchmark/pkg/serverstats"
+	"github.com/kaldun-tech/grpc-rest-benchmark/pkg/servertiming"
+	"github.com/kaldun-tech/grpc-rest-benchmark/pkg/timing"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/health"
 	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/keepalive"
+	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
 )
 
+// messagesSentTrailer reports how many transactions a completed stream
+// sent, so clients can detect transactions lost in transit.
+const messagesSentTrailer = "messages-sent"
+
 var (
-	port   = flag.Int("port", 50051, "gRPC server port")
-	dbHost = flag.String("db-host", "localhost", "PostgreSQL host")
-	dbPort = flag.Int("db-port", 5432, "PostgreSQL port")
-	dbUser = flag.String("db-user", "benchmark", "PostgreSQL user")
-	dbPass = flag.String("db-pass", "benchmark_pass", "PostgreSQL password")
-	dbName = flag.String("db-name", "grpc_benchmark", "PostgreSQL database")
+	port        = flag.Int("port", 50051, "gRPC server port")
+	grpcWebPort = flag.Int("grpc-web-port", 8081, "gRPC-Web server port (0 to disable)")
+	dbHost      = flag.String("db-host", "localhost", "PostgreSQL host")
+	dbPort      = flag.Int("db-port", 5432, "PostgreSQL port")
+	dbUser      = flag.String("db-user", "benchmark", "PostgreSQL user")
+	dbPass      = flag.String("db-pass", "benchmark_pass", "PostgreSQL password")
+	dbName      = flag.String("db-name", "grpc_benchmark", "PostgreSQL database")
+
+	// Server-side stream pacing from recorded HCS timing
+	paceTiming  = flag.String("pace-timing", "", "Timing JSON file used to pace streams that request no rate limit")
+	paceMode    = flag.String("pace-mode", timing.ModeSequential, "Pacing replay mode: sequential | sample")
+	paceSpeedup = flag.Float64("pace-speedup", 1.0, "Pacing speedup factor (1.0 = real-time)")
 )
 
 func main() {
+	var qosCfg qos.Config
+	qosCfg.RegisterFlags(flag.CommandLine)
+	var queryTx db.QueryTx
+	queryTx.RegisterFlags(flag.CommandLine)
+	var faultCfg fault.Config
+	faultCfg.RegisterFlags(flag.CommandLine)
+	var middlewareCfg middleware.Config
+	middlewareCfg.RegisterFlags(flag.CommandLine)
+	var authCfg auth.Config
+	authCfg.RegisterFlags(flag.CommandLine)
+	var limitCfg ratelimit.Config
+	limitCfg.RegisterFlags(flag.CommandLine)
+	var cacheCfg cache.Config
+	cacheCfg.RegisterFlags(flag.CommandLine)
+	var targetCfg dbtarget.Config
+	targetCfg.RegisterFlags(flag.CommandLine)
+	var liveCfg fanout.Config
+	liveCfg.RegisterFlags(flag.CommandLine)
+	var feedCfg feed.Config
+	feedCfg.RegisterFlags(flag.CommandLine)
 	flag.Parse()
+	if err := qosCfg.Validate(); err != nil {
+		log.Fatal(err)
+	}
+	if err := queryTx.Validate(); err != nil {
+		log.Fatal(err)
+	}
+	if err := faultCfg.Validate(); err != nil {
+		log.Fatal(err)
+	}
+	if err := middlewareCfg.Validate(); err != nil {
+		log.Fatal(err)
+	}
+	if err := limitCfg.Validate(); err != nil {
+		log.Fatal(err)
+	}
+	if err := authCfg.Validate(); err != nil {
+		log.Fatal(err)
+	}
+	tlsCfg, err := authCfg.ServerTLS()
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := cacheCfg.Validate(); err != nil {
+		log.Fatal(err)
+	}
+	if err := liveCfg.Validate(); err != nil {
+		log.Fatal(err)
+	}
+	if err := feedCfg.Validate(); err != nil {
+		log.Fatal(err)
+	}
 
 	// Setup database connection
 	ctx := context.Background()
-	dbCfg := db.Config{
+	dbCfg := qosCfg.DBConfig(db.Config{
 		Host:     *dbHost,
 		Port:     *dbPort,
 		User:     *dbUser,
 		Password: *dbPass,
 		Database: *dbName,
-	}
+	})
 
 	database, err := db.New(ctx, dbCfg)
 	if err != nil {
@@ -48,55 +137,234 @@ func main() {
 	defer database.Close()
 	log.Printf("Connected to database %s@%s:%d", dbCfg.Database, dbCfg.Host, dbCfg.Port)
 
-	// Create gRPC server
-	server := grpc.NewServer()
+	var schedule *timing.Schedule
+	if *paceTiming != "" {
+		schedule, err = timing.LoadSchedule(*paceTiming, *paceMode, *paceSpeedup)
+		if err != nil {
+			log.Fatalf("Failed to load pacing schedule: %v", err)
+		}
+		log.Printf("Pacing unthrottled streams from %s", schedule)
+	}
 
-	// Register services
-	balanceService := NewBalanceService(database)
-	protos.RegisterBalanceServiceServer(server, balanceService)
+	// Create gRPC server. The default keepalive policy answers client pings
+	// more frequent than every 5 minutes with GOAWAY, which would break
+	// benchmark runs with --grpc-keepalive-time. The middleware,
+	// authentication, rate limit and faults apply to the benchmark services
+	// only, not health checks, server stats or admin, and their unary calls
+	// report their database time in a trailer. With mutual TLS the whole
+	// listener is TLS.
+	chain, err := middleware.New(middlewareCfg, log.Default())
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer chain.Close()
+	if middlewareCfg.AccessLog != "" {
+		log.Printf("Writing the access log to %s", middlewareCfg.AccessLog)
+	}
+	authenticator := auth.New(authCfg)
+	limiter := ratelimit.New(limitCfg)
+	faults := fault.New(faultCfg)
+	benchmarkServices := []string{
+		protos.BalanceService_ServiceDesc.ServiceName,
+		protos.TransactionService_ServiceDesc.ServiceName,
+		protos.EchoService_ServiceDesc.ServiceName,
+	}
+	opts := []grpc.ServerOption{
+		grpc.KeepaliveEnforcementPolicy(keepalive.EnforcementPolicy{
+			MinTime:             5 * time.Second,
+			PermitWithoutStream: true,
+		}),
+		grpc.ChainUnaryInterceptor(
+			chain.UnaryServerInterceptor(benchmarkServices...),
+			authenticator.UnaryServerInterceptor(benchmarkServices...),
+			limiter.UnaryServerInterceptor(benchmarkServices...),
+			servertiming.UnaryServerInterceptor(benchmarkServices...),
+			faults.UnaryServerInterceptor(benchmarkServices...),
+		),
+		grpc.ChainStreamInterceptor(
+			chain.StreamServerInterceptor(benchmarkServices...),
+			authenticator.StreamServerInterceptor(benchmarkServices...),
+			limiter.StreamServerInterceptor(benchmarkServices...),
+			faults.StreamServerInterceptor(benchmarkServices...),
+		),
+	}
+	if tlsCfg != nil {
+		opts = append(opts, grpc.Creds(credentials.NewTLS(tlsCfg)))
+	}
 
-	transactionService := NewTransactionService(database)
-	protos.RegisterTransactionServiceServer(server, transactionService)
+	// Set up services
+	dataset, closeDataset, err := qos.Open(ctx, qosCfg, database, dbCfg)
+	if err != nil {
+		log.Fatalf("Failed to apply QoS mode: %v", err)
+	}
+	defer closeDataset()
+	log.Printf("QoS: %s, database pools %s", qosCfg, qosCfg.PoolsLabel())
+	database.SetQueryTx(queryTx)
+	if queryTx != (db.QueryTx{}) {
+		log.Printf("Balance queries run in %s transactions", queryTx)
+	}
+	if faultCfg.Enabled() {
+		log.Printf("Injecting faults: %s", faultCfg)
+	}
+	if middlewareCfg.Enabled() {
+		log.Printf("Middleware: %s", middlewareCfg)
+	}
+	if authCfg.Enabled() {
+		log.Printf("Authentication: %s", authCfg)
+	}
+	if limitCfg.Enabled() {
+		log.Printf("Rate limit: %s", limitCfg)
+	}
+	targets := dbtarget.NewSwitch(targetCfg, dataset, dbtarget.Opener(qosCfg, queryTx))
+	defer targets.Close()
+	if len(targetCfg.Targets) > 0 {
+		log.Printf("Database targets: %s", strings.Join(targets.Names(), ", "))
+	}
+	live := fanout.Wrap(targets)
+	if liveCfg.Source == fanout.SourceNotify {
+		go live.Follow(ctx, database.ListenTransactions, func(err error) {
+			log.Printf("Warning: live streams lost database notifications, listening again: %v", err)
+		})
+		log.Printf("Live streams follow database notifications")
+	}
+	if feedCfg.Enabled() {
+		accountIDs, err := database.GetAllAccountIDs(ctx)
+		if err != nil {
+			log.Fatalf("Failed to load accounts for the feed: %v", err)
+		}
+		go func() {
+			err := feed.Run(ctx, live, accountIDs, feedCfg.Rate, func(err error) {
+				log.Printf("Warning: feed transaction failed: %v", err)
+			})
+			if err != nil {
+				log.Printf("Warning: feed stopped: %v", err)
+			}
+		}()
+		log.Printf("Feeding live streams %d transactions/s", feedCfg.Rate)
+	}
+	balances := cache.Wrap(live, cacheCfg)
+	if cacheCfg.Enabled() {
+		log.Printf("Caching balances: %s", cacheCfg)
+	}
+	serverCfg := db.ServerConfig{Pools: qosCfg.PoolsLabel(), QueryTx: queryTx.String(), Faults: faultCfg.String(), Cache: cacheCfg.String(), Middleware: middlewareCfg.String(), RateLimit: limitCfg.String(), Commit: buildinfo.Commit()}
+	if err := database.RecordServerConfig(ctx, db.ServerGRPC, serverCfg); err != nil {
+		log.Printf("Warning: runs will not record the server config: %v", err)
+	}
 
-	// Register health service
+	balanceService := NewBalanceService(balances)
+	transactionService := NewTransactionService(live, schedule)
+	restarts := restart.NewTrigger()
+	adminService := &AdminService{targets: targets, balances: balances, restarts: restarts}
 	healthServer := health.NewServer()
-	grpc_health_v1.RegisterHealthServer(server, healthServer)
 	healthServer.SetServingStatus("", grpc_health_v1.HealthCheckResponse_SERVING)
 
-	// Enable reflection for debugging with grpcurl
-	reflection.Register(server)
+	// A gRPC server cannot serve again once stopped, so each restart
+	// registers the services with a new one
+	newServer := func() *grpc.Server {
+		server := grpc.NewServer(opts...)
+		protos.RegisterBalanceServiceServer(server, balanceService)
+		protos.RegisterTransactionServiceServer(server, transactionService)
+		protos.RegisterEchoServiceServer(server, &EchoService{})
+		protos.RegisterServerStatsServiceServer(server, &ServerStatsService{balances: balances})
+		protos.RegisterAdminServiceServer(server, adminService)
+		grpc_health_v1.RegisterHealthServer(server, healthServer)
+
+		// Enable reflection for debugging with grpcurl
+		reflection.Register(server)
+		return server
+	}
 
-	// Start listening
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
 	addr := fmt.Sprintf(":%d", *port)
-	listener, err := net.Listen("tcp", addr)
-	if err != nil {
-		log.Fatalf("Failed to listen on %s: %v", addr, err)
+	for {
+		server := newServer()
+		listener, err := net.Listen("tcp", addr)
+		if err != nil {
+			log.Fatalf("Failed to listen on %s: %v", addr, err)
+		}
+
+		// gRPC-Web translation layer in front of the same gRPC server
+		var webServer *http.Server
+		if *grpcWebPort > 0 {
+			webServer = newGRPCWebServer(server, fmt.Sprintf(":%d", *grpcWebPort), chain.Metrics())
+			webServer.TLSConfig = tlsCfg
+			go func() {
+				log.Printf("gRPC-Web server listening on %s", webServer.Addr)
+				serve := webServer.ListenAndServe
+				if tlsCfg != nil {
+					serve = func() error { return webServer.ListenAndServeTLS("", "") }
+				}
+				if err := serve(); err != nil && err != http.ErrServerClosed {
+					log.Fatalf("gRPC-Web server error: %v", err)
+				}
+			}()
+		}
+
+		serveErr := make(chan error, 1)
+		go func() { serveErr <- server.Serve(listener) }()
+		log.Printf("gRPC server listening on %s", addr)
+
+		select {
+		case err := <-serveErr:
+			log.Fatalf("Failed to serve: %v", err)
+		case <-sigCh:
+			log.Println("Shutting down gRPC server...")
+			stopServers(server, webServer, 10*time.Second)
+			return
+		case downtime := <-restarts.C():
+			log.Printf("Restarting gRPC server, down for %s after draining", downtime)
+			stopServers(server, webServer, restart.DrainTimeout)
+			time.Sleep(downtime)
+		}
 	}
+}
 
-	// Graceful shutdown
-	go func() {
-		sigCh := make(chan os.Signal, 1)
-		signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
-		<-sigCh
-		log.Println("Shutting down gRPC server...")
-		server.GracefulStop()
-	}()
+// stopServers stops the gRPC and gRPC-Web servers once their calls in
+// flight are done, or after timeout, closing the connections of those
+// left. webServer may be nil.
+func stopServers(server *grpc.Server, webServer *http.Server, timeout time.Duration) {
+	if webServer != nil {
+		restart.Drain(timeout,
+			func() { webServer.Shutdown(context.Background()) },
+			func() { webServer.Close() })
+	}
+	restart.Drain(timeout, server.GracefulStop, server.Stop)
+}
 
-	log.Printf("gRPC server listening on %s", addr)
-	if err := server.Serve(listener); err != nil {
-		log.Fatalf("Failed to serve: %v", err)
+// newGRPCWebServer wraps server with a gRPC-Web handler, the translation a
+// browser deployment would get from Envoy or a grpc-web proxy. Any origin is
+// allowed so the endpoint can be called from a browser during benchmarks.
+// The request metrics, if any, are served on /metrics alongside, and the
+// pprof endpoints on /debug/pprof/.
+func newGRPCWebServer(server *grpc.Server, addr string, metrics *middleware.Metrics) *http.Server {
+	mux := http.NewServeMux()
+	if metrics != nil {
+		mux.Handle("/metrics", metrics.Handler())
+	}
+	profiling.RegisterDebug(mux)
+	mux.Handle("/", grpcweb.WrapServer(server,
+		grpcweb.WithOriginFunc(func(origin string) bool { return true }),
+	))
+
+	return &http.Server{
+		Addr:         addr,
+		Handler:      mux,
+		ReadTimeout:  10 * time.Second,
+		WriteTimeout: 0, // Disabled for streaming responses
+		IdleTimeout:  120 * time.Second,
 	}
 }
 
 // BalanceService implements the BalanceService gRPC service.
 type BalanceService struct {
 	protos.UnimplementedBalanceServiceServer
-	db *db.DB
+	db qos.Dataset
 }
 
 // NewBalanceService creates a new BalanceService.
-func NewBalanceService(database *db.DB) *BalanceService {
-	return &BalanceService{db: database}
+func NewBalanceService(dataset qos.Dataset) *BalanceService {
+	return &BalanceService{db: dataset}
 }
 
 // GetBalance returns the balance for a single account.
@@ -135,12 +403,13 @@ func (s *BalanceService) GetBalances(ctx context.Context, req *protos.BatchBalan
 // TransactionService implements the TransactionService gRPC service.
 type TransactionService struct {
 	protos.UnimplementedTransactionServiceServer
-	db *db.DB
+	db       qos.Dataset
+	schedule *timing.Schedule // optional pacing for streams without a rate limit
 }
 
-// NewTransactionService creates a new TransactionService.
-func NewTransactionService(database *db.DB) *TransactionService {
-	return &TransactionService{db: database}
+// NewTransactionService creates a new TransactionService. schedule may be nil.
+func NewTransactionService(dataset qos.Dataset, schedule *timing.Schedule) *TransactionService {
+	return &TransactionService{db: dataset, schedule: schedule}
 }
 
 // StreamTransactions streams transactions to the client.
@@ -160,17 +429,27 @@ func (s *TransactionService) StreamTransactions(req *protos.StreamRequest, strea
 	opts := db.StreamTransactionsOptions{
 		Since:         since,
 		FilterAccount: req.FilterAccount,
+		After:         req.AfterTxId,
+		Live:          req.Live,
 	}
 
 	txCh, errCh := s.db.StreamTransactions(ctx, opts)
 
-	// Rate limiting
+	// Rate limiting; a requested rate takes precedence over the pacing
+	// schedule. Live streams deliver transactions as they are submitted.
 	var ticker *time.Ticker
-	if req.RateLimit > 0 {
+	var pacer *timing.Pacer
+	timestampLayout := time.RFC3339
+	if req.Live {
+		timestampLayout = time.RFC3339Nano
+	} else if req.RateLimit > 0 {
 		ticker = time.NewTicker(time.Second / time.Duration(req.RateLimit))
 		defer ticker.Stop()
+	} else if s.schedule != nil {
+		pacer = s.schedule.NewPacer()
 	}
 
+	sent := 0
 	for tx := range txCh {
 		// Apply rate limiting if configured
 		if ticker != nil {
@@ -179,20 +458,27 @@ func (s *TransactionService) StreamTransactions(req *protos.StreamRequest, strea
 			case <-ctx.Done():
 				return ctx.Err()
 			}
+		} else if pacer != nil {
+			if err := pacer.Wait(ctx); err != nil {
+				return err
+			}
 		}
 
+		// Stamped after pacing, so delivery latency covers only the send
 		protoTx := &protos.Transaction{
-			TxId:          tx.TxID,
-			FromAccount:   tx.FromAccount,
-			ToAccount:     tx.ToAccount,
-			AmountTinybar: tx.Amount,
-			TxType:        tx.TxType,
-			Timestamp:     tx.Timestamp.Format(time.RFC3339),
+			TxId:           tx.TxID,
+			FromAccount:    tx.FromAccount,
+			ToAccount:      tx.ToAccount,
+			AmountTinybar:  tx.Amount,
+			TxType:         tx.TxType,
+			Timestamp:      tx.Timestamp.Format(timestampLayout),
+			SentAtUnixNano: time.Now().UnixNano(),
 		}
 
 		if err := stream.Send(protoTx); err != nil {
 			return err
 		}
+		sent++
 	}
 
 	// Check for errors from the stream
@@ -204,5 +490,132 @@ func (s *TransactionService) StreamTransactions(req *protos.StreamRequest, strea
 	default:
 	}
 
+	stream.SetTrailer(metadata.Pairs(messagesSentTrailer, strconv.Itoa(sent)))
 	return nil
 }
+
+// SubmitTransaction stores a new transaction and returns it with its
+// assigned ID and timestamp.
+func (s *TransactionService) SubmitTransaction(ctx context.Context, req *protos.SubmitTransactionRequest) (*protos.Transaction, error) {
+	tx := &db.Transaction{
+		FromAccount: req.FromAccount,
+		ToAccount:   req.ToAccount,
+		Amount:      req.AmountTinybar,
+		TxType:      req.TxType,
+	}
+	if err := tx.Validate(); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if err := s.db.InsertTransaction(ctx, tx); err != nil {
+		return nil, err
+	}
+
+	return &protos.Transaction{
+		TxId:          tx.TxID,
+		FromAccount:   tx.FromAccount,
+		ToAccount:     tx.ToAccount,
+		AmountTinybar: tx.Amount,
+		TxType:        tx.TxType,
+		Timestamp:     tx.Timestamp.Format(time.RFC3339),
+	}, nil
+}
+
+// EchoService implements the EchoService gRPC service.
+type EchoService struct {
+	protos.UnimplementedEchoServiceServer
+}
+
+// Echo returns a payload of the requested size.
+func (s *EchoService) Echo(ctx context.Context, req *protos.EchoRequest) (*protos.EchoResponse, error) {
+	b, err := payload.Bytes(int(req.PayloadSize))
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	return &protos.EchoResponse{Payload: b}, nil
+}
+
+// ServerStatsService implements the ServerStatsService gRPC service.
+type ServerStatsService struct {
+	protos.UnimplementedServerStatsServiceServer
+	balances *cache.Dataset
+}
+
+// GetServerStats returns the CPU time the server has used, its balance
+// cache hits and misses and its database connection acquire counters.
+func (s *ServerStatsService) GetServerStats(ctx context.Context, req *protos.ServerStatsRequest) (*protos.ServerStats, error) {
+	cpu, err := serverstats.CPUSeconds()
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	stats := s.balances.Stats()
+	pools := db.AllPoolStats()
+	return &protos.ServerStats{
+		CpuSeconds:          cpu,
+		CacheHits:           stats.Hits,
+		CacheMisses:         stats.Misses,
+		PoolAcquireWaits:    pools.EmptyAcquires,
+		PoolAcquireFailures: pools.CanceledAcquires,
+	}, nil
+}
+
+// AdminService implements the AdminService gRPC service.
+type AdminService struct {
+	protos.UnimplementedAdminServiceServer
+	targets  *dbtarget.Switch
+	balances *cache.Dataset
+	restarts *restart.Trigger
+}
+
+// GetDBTarget returns the database target in use.
+func (s *AdminService) GetDBTarget(ctx context.Context, req *protos.DBTargetRequest) (*protos.DBTarget, error) {
+	return &protos.DBTarget{Name: s.targets.Current(), Targets: s.targets.Names()}, nil
+}
+
+// SetDBTarget switches the benchmark services to another database and
+// empties the balance cache.
+func (s *AdminService) SetDBTarget(ctx context.Context, req *protos.SetDBTargetRequest) (*protos.DBTarget, error) {
+	previous := s.targets.Current()
+	err := s.targets.Use(ctx, req.Name)
+	if errors.Is(err, dbtarget.ErrUnknownTarget) {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if err != nil {
+		return nil, status.Error(codes.Unavailable, err.Error())
+	}
+	if req.Name != previous {
+		s.balances.Purge()
+		log.Printf("Switched database target from %s to %s", previous, req.Name)
+	}
+	return s.GetDBTarget(ctx, &protos.DBTargetRequest{})
+}
+
+// Restart restarts the server after responding: it drains, stays down for
+// the requested downtime and listens again.
+func (s *AdminService) Restart(ctx context.Context, req *protos.RestartRequest) (*protos.RestartResponse, error) {
+	err := s.restarts.Restart(time.Duration(req.DowntimeMs) * time.Millisecond)
+	if errors.Is(err, restart.ErrPending) {
+		return nil, status.Error(codes.FailedPrecondition, err.Error())
+	}
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	return &protos.RestartResponse{}, nil
+}
+
+// CaptureProfile captures a pprof profile of the server: CPU samples over
+// the requested duration, or the heap.
+func (s *AdminService) CaptureProfile(ctx context.Context, req *protos.ProfileRequest) (*protos.Profile, error) {
+	duration := time.Duration(req.DurationMs) * time.Millisecond
+	if err := profiling.Validate(req.Kind, duration); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	var buf bytes.Buffer
+	err := profiling.Capture(ctx, &buf, req.Kind, duration)
+	if errors.Is(err, profiling.ErrBusy) {
+		return nil, status.Error(codes.FailedPrecondition, err.Error())
+	}
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return &protos.Profile{Data: buf.Bytes()}, nil
+}
@@ -0,0 +1,133 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/kaldun-tech/grpc-rest-benchmark/pkg/dbtarget"
+	"github.com/kaldun-tech/grpc-rest-benchmark/pkg/profiling"
+	"github.com/kaldun-tech/grpc-rest-benchmark/pkg/restart"
+)
+
+// DBTargetRequest is the JSON body selecting a database target.
+type DBTargetRequest struct {
+	Target string `json:"target"`
+}
+
+// DBTargetResponse is the JSON response for the database target: the one in
+// use and all the server was started with.
+type DBTargetResponse struct {
+	Target  string   `json:"target"`
+	Targets []string `json:"targets"`
+}
+
+// handleDBTarget handles GET and PUT /api/v1/admin/db-target. PUT switches
+// the benchmark endpoints to another database and empties the balance cache.
+func (s *Server) handleDBTarget(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodPut:
+		var req DBTargetRequest
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<20)).Decode(&req); err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Sprintf("Invalid request body: %v", err))
+			return
+		}
+		previous := s.targets.Current()
+		err := s.targets.Use(r.Context(), req.Target)
+		if errors.Is(err, dbtarget.ErrUnknownTarget) {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		if err != nil {
+			writeError(w, http.StatusBadGateway, err.Error())
+			return
+		}
+		if req.Target != previous {
+			s.balances.Purge()
+			log.Printf("Switched database target from %s to %s", previous, req.Target)
+		}
+	default:
+		writeError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+	writeJSON(w, http.StatusOK, DBTargetResponse{Target: s.targets.Current(), Targets: s.targets.Names()})
+}
+
+// RestartRequest is the JSON body requesting a graceful restart.
+type RestartRequest struct {
+	DowntimeMs int64 `json:"downtime_ms"` // how long the server stays down once drained
+}
+
+// handleRestart handles POST /api/v1/admin/restart. It responds 202
+// Accepted and then restarts the server: it drains, stays down for the
+// requested downtime and listens again.
+func (s *Server) handleRestart(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+	var req RestartRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<20)).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("Invalid request body: %v", err))
+		return
+	}
+	err := s.restarts.Restart(time.Duration(req.DowntimeMs) * time.Millisecond)
+	if errors.Is(err, restart.ErrPending) {
+		writeError(w, http.StatusConflict, err.Error())
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusAccepted, req)
+}
+
+// handleProfile handles GET /api/v1/admin/profile?kind=cpu|heap&seconds=N
+// and responds with a pprof profile of the server, CPU samples over the
+// given seconds (default 30) or the heap. go tool pprof reads it directly.
+func (s *Server) handleProfile(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+	kind := r.URL.Query().Get("kind")
+	if kind == "" {
+		kind = profiling.CPU
+	}
+	seconds := 30
+	if str := r.URL.Query().Get("seconds"); str != "" {
+		n, err := strconv.Atoi(str)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Sprintf("Invalid seconds: %s", str))
+			return
+		}
+		seconds = n
+	}
+	duration := time.Duration(seconds) * time.Second
+	if err := profiling.Validate(kind, duration); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	// Buffered, so a busy profiler still gets a JSON error response
+	var buf bytes.Buffer
+	err := profiling.Capture(r.Context(), &buf, kind, duration)
+	if errors.Is(err, profiling.ErrBusy) {
+		writeError(w, http.StatusConflict, err.Error())
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	w.Header().Set("Content-Type", "application/octet-stream")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.pprof"`, profiling.Name("server", kind)))
+	w.Write(buf.Bytes())
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/kaldun-tech/grpc-rest-benchmark/pkg/db"
+)
+
+func agentResponse(a *db.Agent) AgentResponse {
+	return AgentResponse{
+		Name:          a.Name,
+		Address:       a.Address,
+		Protocols:     a.Protocols,
+		CPUs:          a.CPUs,
+		RegisteredAt:  a.RegisteredAt,
+		LastHeartbeat: a.LastHeartbeat,
+		Healthy:       a.Healthy,
+	}
+}
+
+// handleAgents handles GET (list, ?healthy=true for the healthy ones only)
+// and POST (register) /api/v1/agents. Registering an existing name updates
+// its address and capabilities.
+func (s *Server) handleAgents(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		healthy := r.URL.Query().Get("healthy")
+		if healthy != "" && healthy != "true" && healthy != "false" {
+			writeError(w, http.StatusBadRequest, fmt.Sprintf("Invalid healthy: %s (must be true or false)", healthy))
+			return
+		}
+		agents, err := s.db.ListAgents(r.Context(), healthy == "true")
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, fmt.Sprintf("Failed to list agents: %v", err))
+			return
+		}
+		resp := AgentsResponse{Agents: make([]AgentResponse, len(agents)), Count: len(agents)}
+		for i, a := range agents {
+			resp.Agents[i] = agentResponse(a)
+		}
+		writeJSON(w, http.StatusOK, resp)
+
+	case http.MethodPost:
+		var req AgentRegistration
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<20)).Decode(&req); err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Sprintf("Invalid request body: %v", err))
+			return
+		}
+		a := &db.Agent{Name: req.Name, Address: req.Address, Protocols: req.Protocols, CPUs: req.CPUs}
+		if err := a.Validate(); err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		if err := s.db.RegisterAgent(r.Context(), a); err != nil {
+			writeError(w, http.StatusInternalServerError, fmt.Sprintf("Failed to register agent: %v", err))
+			return
+		}
+		writeJSON(w, http.StatusCreated, agentResponse(a))
+
+	default:
+		writeError(w, http.StatusMethodNotAllowed, "Method not allowed")
+	}
+}
+
+// handleAgent handles GET and DELETE (unregister) /api/v1/agents/{name}
+// and POST /api/v1/agents/{name}/heartbeat
+func (s *Server) handleAgent(w http.ResponseWriter, r *http.Request) {
+	path := strings.TrimPrefix(r.URL.Path, "/api/v1/agents/")
+	parts := strings.Split(path, "/")
+	if parts[0] == "" || len(parts) > 2 || (len(parts) == 2 && parts[1] != "heartbeat") {
+		writeError(w, http.StatusNotFound, "Not found")
+		return
+	}
+	name := parts[0]
+
+	if len(parts) == 2 {
+		if r.Method != http.MethodPost {
+			writeError(w, http.StatusMethodNotAllowed, "Method not allowed")
+			return
+		}
+		if err := s.db.HeartbeatAgent(r.Context(), name); err != nil {
+			writeAgentError(w, err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		a, err := s.db.GetAgent(r.Context(), name)
+		if err != nil {
+			writeAgentError(w, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, agentResponse(a))
+
+	case http.MethodDelete:
+		if err := s.db.DeleteAgent(r.Context(), name); err != nil {
+			writeAgentError(w, err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+
+	default:
+		writeError(w, http.StatusMethodNotAllowed, "Method not allowed")
+	}
+}
+
+func writeAgentError(w http.ResponseWriter, err error) {
+	if errors.Is(err, db.ErrAgentNotFound) {
+		writeError(w, http.StatusNotFound, "Agent not found")
+		return
+	}
+	writeError(w, http.StatusInternalServerError, err.Error())
+}
@@ -0,0 +1,202 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	"connectrpc.com/connect"
+
+	"github.com/kaldun-tech/grpc-rest-benchmark/pkg/auth"
+	"github.com/kaldun-tech/grpc-rest-benchmark/pkg/compression"
+	"github.com/kaldun-tech/grpc-rest-benchmark/pkg/db"
+	"github.com/kaldun-tech/grpc-rest-benchmark/pkg/fault"
+	"github.com/kaldun-tech/grpc-rest-benchmark/pkg/middleware"
+	"github.com/kaldun-tech/grpc-rest-benchmark/pkg/payload"
+	"github.com/kaldun-tech/grpc-rest-benchmark/pkg/protos"
+	"github.com/kaldun-tech/grpc-rest-benchmark/pkg/protos/protosconnect"
+	"github.com/kaldun-tech/grpc-rest-benchmark/pkg/qos"
+	"github.com/kaldun-tech/grpc-rest-benchmark/pkg/ratelimit"
+	"github.com/kaldun-tech/grpc-rest-benchmark/pkg/servertiming"
+	"github.com/kaldun-tech/grpc-rest-benchmark/pkg/timing"
+)
+
+// messagesSentTrailer reports how many transactions a completed stream
+// sent, so clients can detect transactions lost in transit. SSE streams
+// report the count in their "done" event.
+const messagesSentTrailer = "Messages-Sent"
+
+// ConnectBalanceService implements BalanceService over the Connect protocol.
+// It is mounted on the REST server so Connect shares the same HTTP listener
+// and database pool as the JSON endpoints.
+type ConnectBalanceService struct {
+	db qos.Dataset
+}
+
+// GetBalance returns the balance for a single account.
+func (s *ConnectBalanceService) GetBalance(ctx context.Context, req *connect.Request[protos.BalanceRequest]) (*connect.Response[protos.BalanceResponse], error) {
+	account, err := s.db.GetBalance(ctx, req.Msg.AccountId)
+	if err != nil {
+		return nil, connect.NewError(connect.CodeNotFound, err)
+	}
+
+	return connect.NewResponse(&protos.BalanceResponse{
+		AccountId:      account.AccountID,
+		BalanceTinybar: account.Balance,
+		Timestamp:      account.UpdatedAt.Format(time.RFC3339),
+	}), nil
+}
+
+// GetBalances returns balances for multiple accounts.
+func (s *ConnectBalanceService) GetBalances(ctx context.Context, req *connect.Request[protos.BatchBalanceRequest]) (*connect.Response[protos.BatchBalanceResponse], error) {
+	accounts, err := s.db.GetBalances(ctx, req.Msg.AccountIds)
+	if err != nil {
+		return nil, connect.NewError(connect.CodeInternal, err)
+	}
+
+	balances := make([]*protos.BalanceResponse, len(accounts))
+	for i, acc := range accounts {
+		balances[i] = &protos.BalanceResponse{
+			AccountId:      acc.AccountID,
+			BalanceTinybar: acc.Balance,
+			Timestamp:      acc.UpdatedAt.Format(time.RFC3339),
+		}
+	}
+
+	return connect.NewResponse(&protos.BatchBalanceResponse{Balances: balances}), nil
+}
+
+// ConnectTransactionService implements TransactionService over the Connect protocol.
+type ConnectTransactionService struct {
+	db       qos.Dataset
+	schedule *timing.Schedule // optional pacing for streams without a rate limit
+}
+
+// StreamTransactions streams transactions to the client.
+func (s *ConnectTransactionService) StreamTransactions(ctx context.Context, req *connect.Request[protos.StreamRequest], stream *connect.ServerStream[protos.Transaction]) error {
+	var since time.Time
+	if req.Msg.SinceTimestamp != "" {
+		var err error
+		since, err = time.Parse(time.RFC3339, req.Msg.SinceTimestamp)
+		if err != nil {
+			since = time.Time{} // Default to beginning
+		}
+	}
+
+	opts := db.StreamTransactionsOptions{
+		Since:         since,
+		FilterAccount: req.Msg.FilterAccount,
+		After:         req.Msg.AfterTxId,
+		Live:          req.Msg.Live,
+	}
+
+	txCh, errCh := s.db.StreamTransactions(ctx, opts)
+
+	// Rate limiting; a requested rate takes precedence over the pacing
+	// schedule. Live streams deliver transactions as they are submitted.
+	var ticker *time.Ticker
+	var pacer *timing.Pacer
+	timestampLayout := time.RFC3339
+	if req.Msg.Live {
+		timestampLayout = time.RFC3339Nano
+	} else if req.Msg.RateLimit > 0 {
+		ticker = time.NewTicker(time.Second / time.Duration(req.Msg.RateLimit))
+		defer ticker.Stop()
+	} else if s.schedule != nil {
+		pacer = s.schedule.NewPacer()
+	}
+
+	sent := 0
+	for tx := range txCh {
+		if ticker != nil {
+			select {
+			case <-ticker.C:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		} else if pacer != nil {
+			if err := pacer.Wait(ctx); err != nil {
+				return err
+			}
+		}
+
+		if err := stream.Send(&protos.Transaction{
+			TxId:           tx.TxID,
+			FromAccount:    tx.FromAccount,
+			ToAccount:      tx.ToAccount,
+			AmountTinybar:  tx.Amount,
+			TxType:         tx.TxType,
+			Timestamp:      tx.Timestamp.Format(timestampLayout),
+			SentAtUnixNano: time.Now().UnixNano(),
+		}); err != nil {
+			return err
+		}
+		sent++
+	}
+
+	select {
+	case err := <-errCh:
+		if err != nil {
+			return connect.NewError(connect.CodeInternal, err)
+		}
+	default:
+	}
+
+	stream.ResponseTrailer().Set(messagesSentTrailer, strconv.Itoa(sent))
+	return nil
+}
+
+// SubmitTransaction stores a new transaction and returns it with its
+// assigned ID and timestamp.
+func (s *ConnectTransactionService) SubmitTransaction(ctx context.Context, req *connect.Request[protos.SubmitTransactionRequest]) (*connect.Response[protos.Transaction], error) {
+	tx := &db.Transaction{
+		FromAccount: req.Msg.FromAccount,
+		ToAccount:   req.Msg.ToAccount,
+		Amount:      req.Msg.AmountTinybar,
+		TxType:      req.Msg.TxType,
+	}
+	if err := tx.Validate(); err != nil {
+		return nil, connect.NewError(connect.CodeInvalidArgument, err)
+	}
+	if err := s.db.InsertTransaction(ctx, tx); err != nil {
+		return nil, connect.NewError(connect.CodeInternal, err)
+	}
+
+	return connect.NewResponse(&protos.Transaction{
+		TxId:          tx.TxID,
+		FromAccount:   tx.FromAccount,
+		ToAccount:     tx.ToAccount,
+		AmountTinybar: tx.Amount,
+		TxType:        tx.TxType,
+		Timestamp:     tx.Timestamp.Format(time.RFC3339),
+	}), nil
+}
+
+// ConnectEchoService implements EchoService over the Connect protocol.
+type ConnectEchoService struct{}
+
+// Echo returns a payload of the requested size.
+func (s *ConnectEchoService) Echo(ctx context.Context, req *connect.Request[protos.EchoRequest]) (*connect.Response[protos.EchoResponse], error) {
+	b, err := payload.Bytes(int(req.Msg.PayloadSize))
+	if err != nil {
+		return nil, connect.NewError(connect.CodeInvalidArgument, err)
+	}
+	return connect.NewResponse(&protos.EchoResponse{Payload: b}), nil
+}
+
+// registerConnectHandlers mounts the Connect services on mux. The handlers
+// accept the Connect protocol with both the binary protobuf and JSON codecs,
+// and gzip, deflate or zstd compression, behind the middleware chain,
+// authentication, rate limit and injected faults, with their database time
+// reported in a Server-Timing header. schedule, chain, authenticator,
+// limiter and faults may be nil.
+func registerConnectHandlers(mux *http.ServeMux, dataset qos.Dataset, schedule *timing.Schedule, chain *middleware.Chain, authenticator *auth.Authenticator, limiter *ratelimit.Limiter, faults *fault.Injector) {
+	opts := connect.WithHandlerOptions(compression.ConnectHandlerOptions()...)
+	handle := func(path string, handler http.Handler) {
+		mux.Handle(path, chain.Handler(authenticator.Handler(limiter.ConnectHandler(servertiming.Handler(faults.Handler(handler))))))
+	}
+	handle(protosconnect.NewBalanceServiceHandler(&ConnectBalanceService{db: dataset}, opts))
+	handle(protosconnect.NewTransactionServiceHandler(&ConnectTransactionService{db: dataset, schedule: schedule}, opts))
+	handle(protosconnect.NewEchoServiceHandler(&ConnectEchoService{}, opts))
+}
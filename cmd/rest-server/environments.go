@@ -0,0 +1,138 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/kaldun-tech/grpc-rest-benchmark/pkg/db"
+)
+
+func environmentResponse(e *db.Environment) EnvironmentResponse {
+	return EnvironmentResponse{
+		Name:        e.Name,
+		GRPCAddr:    e.GRPCAddr,
+		RESTAddr:    e.RESTAddr,
+		GRPCWebAddr: e.GRPCWebAddr,
+		Database:    e.Database,
+		Hardware:    e.Hardware,
+		CreatedAt:   e.CreatedAt,
+		UpdatedAt:   e.UpdatedAt,
+	}
+}
+
+func decodeEnvironment(w http.ResponseWriter, r *http.Request) (*db.Environment, bool) {
+	var req EnvironmentRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<20)).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("Invalid request body: %v", err))
+		return nil, false
+	}
+	return &db.Environment{
+		Name:        req.Name,
+		GRPCAddr:    req.GRPCAddr,
+		RESTAddr:    req.RESTAddr,
+		GRPCWebAddr: req.GRPCWebAddr,
+		Database:    req.Database,
+		Hardware:    req.Hardware,
+	}, true
+}
+
+// handleEnvironments handles GET (list) and POST (register)
+// /api/v1/environments.
+func (s *Server) handleEnvironments(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		envs, err := s.db.ListEnvironments(r.Context())
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, fmt.Sprintf("Failed to list environments: %v", err))
+			return
+		}
+		resp := EnvironmentsResponse{Environments: make([]EnvironmentResponse, len(envs)), Count: len(envs)}
+		for i, e := range envs {
+			resp.Environments[i] = environmentResponse(e)
+		}
+		writeJSON(w, http.StatusOK, resp)
+
+	case http.MethodPost:
+		e, ok := decodeEnvironment(w, r)
+		if !ok {
+			return
+		}
+		if err := e.Validate(); err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		if err := s.db.CreateEnvironment(r.Context(), e); err != nil {
+			writeEnvironmentError(w, err)
+			return
+		}
+		writeJSON(w, http.StatusCreated, environmentResponse(e))
+
+	default:
+		writeError(w, http.StatusMethodNotAllowed, "Method not allowed")
+	}
+}
+
+// handleEnvironment handles GET, PUT (replace the addresses and notes) and
+// DELETE /api/v1/environments/{name}. Deleting an environment keeps its
+// runs, which lose the reference.
+func (s *Server) handleEnvironment(w http.ResponseWriter, r *http.Request) {
+	name := strings.TrimPrefix(r.URL.Path, "/api/v1/environments/")
+	if name == "" || strings.Contains(name, "/") {
+		writeError(w, http.StatusNotFound, "Not found")
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		e, err := s.db.GetEnvironment(r.Context(), name)
+		if err != nil {
+			writeEnvironmentError(w, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, environmentResponse(e))
+
+	case http.MethodPut:
+		e, ok := decodeEnvironment(w, r)
+		if !ok {
+			return
+		}
+		if e.Name != "" && e.Name != name {
+			writeError(w, http.StatusBadRequest, "Environment name cannot be changed")
+			return
+		}
+		e.Name = name
+		if err := e.Validate(); err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		if err := s.db.UpdateEnvironment(r.Context(), e); err != nil {
+			writeEnvironmentError(w, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, environmentResponse(e))
+
+	case http.MethodDelete:
+		if err := s.db.DeleteEnvironment(r.Context(), name); err != nil {
+			writeEnvironmentError(w, err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+
+	default:
+		writeError(w, http.StatusMethodNotAllowed, "Method not allowed")
+	}
+}
+
+func writeEnvironmentError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, db.ErrEnvironmentNotFound):
+		writeError(w, http.StatusNotFound, "Environment not found")
+	case errors.Is(err, db.ErrEnvironmentExists):
+		writeError(w, http.StatusConflict, "Environment already exists")
+	default:
+		writeError(w, http.StatusInternalServerError, err.Error())
+	}
+}
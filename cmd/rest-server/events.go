@@ -0,0 +1,99 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/kaldun-tech/grpc-rest-benchmark/pkg/db"
+)
+
+// maxEvents is the most events returned by one list request, the newest
+// ones.
+const maxEvents = 1000
+
+// EventRequest is the JSON body accepted when recording an event.
+// OccurredAt is RFC 3339 and defaults to now.
+type EventRequest struct {
+	Kind       string `json:"kind"`
+	Message    string `json:"message"`
+	OccurredAt string `json:"occurred_at"`
+}
+
+func eventResponse(e *db.Event) EventResponse {
+	return EventResponse{
+		ID:         e.ID,
+		Kind:       e.Kind,
+		Message:    e.Message,
+		OccurredAt: e.OccurredAt,
+		CreatedAt:  e.CreatedAt,
+	}
+}
+
+// handleEvents handles GET (list) and POST (record) /api/v1/events
+func (s *Server) handleEvents(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		query := r.URL.Query()
+		filter := db.EventFilter{Kind: query.Get("kind"), Limit: maxEvents}
+		windowStr := query.Get("window")
+		if windowStr == "" {
+			windowStr = defaultTrendWindow
+		}
+		window, err := parseWindow(windowStr)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Sprintf("Invalid window: %s", windowStr))
+			return
+		}
+		filter.Window = window
+		if limitStr := query.Get("limit"); limitStr != "" {
+			limit, err := strconv.Atoi(limitStr)
+			if err != nil || limit < 1 || limit > maxEvents {
+				writeError(w, http.StatusBadRequest, fmt.Sprintf("Invalid limit: %s (must be 1 to %d)", limitStr, maxEvents))
+				return
+			}
+			filter.Limit = limit
+		}
+
+		events, err := s.db.ListEvents(r.Context(), filter)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, fmt.Sprintf("Failed to list events: %v", err))
+			return
+		}
+		resp := EventsResponse{Events: make([]EventResponse, len(events)), Count: len(events)}
+		for i, e := range events {
+			resp.Events[i] = eventResponse(e)
+		}
+		writeJSON(w, http.StatusOK, resp)
+
+	case http.MethodPost:
+		var req EventRequest
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<20)).Decode(&req); err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Sprintf("Invalid request body: %v", err))
+			return
+		}
+		e := &db.Event{Kind: req.Kind, Message: req.Message}
+		if req.OccurredAt != "" {
+			t, err := time.Parse(time.RFC3339, req.OccurredAt)
+			if err != nil {
+				writeError(w, http.StatusBadRequest, fmt.Sprintf("Invalid occurred_at: %s (must be RFC 3339)", req.OccurredAt))
+				return
+			}
+			e.OccurredAt = t.UTC()
+		}
+		if err := e.Validate(); err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		if err := s.db.RecordEvent(r.Context(), e); err != nil {
+			writeError(w, http.StatusInternalServerError, fmt.Sprintf("Failed to record event: %v", err))
+			return
+		}
+		writeJSON(w, http.StatusCreated, eventResponse(e))
+
+	default:
+		writeError(w, http.StatusMethodNotAllowed, "Method not allowed")
+	}
+}
@@ -0,0 +1,216 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kaldun-tech/grpc-rest-benchmark/pkg/db"
+)
+
+// ExperimentRequest is the JSON body accepted when creating or updating an
+// experiment. RunIDs replaces the experiment's runs.
+type ExperimentRequest struct {
+	Name        string  `json:"name"`
+	Description string  `json:"description"`
+	Hypothesis  string  `json:"hypothesis"`
+	RunIDs      []int64 `json:"run_ids"`
+}
+
+// ExperimentResponse is the JSON representation of an experiment. URL opens
+// the dashboard showing only its runs.
+type ExperimentResponse struct {
+	ID          int64   `json:"id"`
+	Name        string  `json:"name"`
+	Description string  `json:"description"`
+	Hypothesis  string  `json:"hypothesis"`
+	RunIDs      []int64 `json:"run_ids"`
+	URL         string  `json:"url"`
+	CreatedAt   string  `json:"created_at"`
+	UpdatedAt   string  `json:"updated_at"`
+}
+
+// ExperimentsResponse is the JSON response for the experiment list.
+type ExperimentsResponse struct {
+	Experiments []ExperimentResponse `json:"experiments"`
+	Count       int                  `json:"count"`
+}
+
+// ExperimentReport is the JSON export of an experiment with the results of
+// all its runs.
+type ExperimentReport struct {
+	Experiment ExperimentResponse `json:"experiment"`
+	Results    []BenchmarkResult  `json:"results"`
+	Count      int                `json:"count"`
+}
+
+func experimentResponse(e *db.Experiment) ExperimentResponse {
+	runIDs := e.RunIDs
+	if runIDs == nil {
+		runIDs = []int64{}
+	}
+	return ExperimentResponse{
+		ID:          e.ID,
+		Name:        e.Name,
+		Description: e.Description,
+		Hypothesis:  e.Hypothesis,
+		RunIDs:      runIDs,
+		URL:         fmt.Sprintf("/?experiment=%d", e.ID),
+		CreatedAt:   e.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:   e.UpdatedAt.Format(time.RFC3339),
+	}
+}
+
+// handleExperiments handles GET (list) and POST (create) /api/v1/experiments
+func (s *Server) handleExperiments(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		experiments, err := s.db.ListExperiments(r.Context())
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, fmt.Sprintf("Failed to list experiments: %v", err))
+			return
+		}
+		resp := ExperimentsResponse{Experiments: make([]ExperimentResponse, len(experiments)), Count: len(experiments)}
+		for i, e := range experiments {
+			resp.Experiments[i] = experimentResponse(e)
+		}
+		writeJSON(w, http.StatusOK, resp)
+
+	case http.MethodPost:
+		e, ok := decodeExperiment(w, r)
+		if !ok {
+			return
+		}
+		if err := s.db.CreateExperiment(r.Context(), e); err != nil {
+			writeExperimentError(w, err)
+			return
+		}
+		writeJSON(w, http.StatusCreated, experimentResponse(e))
+
+	default:
+		writeError(w, http.StatusMethodNotAllowed, "Method not allowed")
+	}
+}
+
+// handleExperiment handles GET, PUT and DELETE /api/v1/experiments/{id}
+// and GET /api/v1/experiments/{id}/report
+func (s *Server) handleExperiment(w http.ResponseWriter, r *http.Request) {
+	path := strings.TrimPrefix(r.URL.Path, "/api/v1/experiments/")
+	parts := strings.Split(path, "/")
+	if len(parts) > 2 || (len(parts) == 2 && parts[1] != "report") {
+		writeError(w, http.StatusNotFound, "Not found")
+		return
+	}
+	id, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("Invalid experiment ID: %s", parts[0]))
+		return
+	}
+
+	if len(parts) == 2 {
+		if r.Method != http.MethodGet {
+			writeError(w, http.StatusMethodNotAllowed, "Method not allowed")
+			return
+		}
+		s.writeExperimentReport(w, r, id)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		e, err := s.db.GetExperiment(r.Context(), id)
+		if err != nil {
+			writeExperimentError(w, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, experimentResponse(e))
+
+	case http.MethodPut:
+		e, ok := decodeExperiment(w, r)
+		if !ok {
+			return
+		}
+		e.ID = id
+		if err := s.db.UpdateExperiment(r.Context(), e); err != nil {
+			writeExperimentError(w, err)
+			return
+		}
+		// Read back the deduplicated, ordered runs
+		updated, err := s.db.GetExperiment(r.Context(), id)
+		if err != nil {
+			writeExperimentError(w, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, experimentResponse(updated))
+
+	case http.MethodDelete:
+		if err := s.db.DeleteExperiment(r.Context(), id); err != nil {
+			writeExperimentError(w, err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+
+	default:
+		writeError(w, http.StatusMethodNotAllowed, "Method not allowed")
+	}
+}
+
+// writeExperimentReport responds with an experiment and the results of all
+// its runs, newest first.
+func (s *Server) writeExperimentReport(w http.ResponseWriter, r *http.Request, id int64) {
+	e, err := s.db.GetExperiment(r.Context(), id)
+	if err != nil {
+		writeExperimentError(w, err)
+		return
+	}
+
+	report := ExperimentReport{Experiment: experimentResponse(e), Results: []BenchmarkResult{}}
+	if len(e.RunIDs) > 0 {
+		stats, err := s.db.GetFilteredStats(r.Context(), db.StatsFilter{RunIDs: e.RunIDs})
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, fmt.Sprintf("Failed to get results: %v", err))
+			return
+		}
+		if report.Results, err = s.benchmarkResults(r.Context(), stats); err != nil {
+			writeError(w, http.StatusInternalServerError, fmt.Sprintf("Failed to get results: %v", err))
+			return
+		}
+	}
+	report.Count = len(report.Results)
+
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="experiment-%d.json"`, id))
+	writeJSON(w, http.StatusOK, report)
+}
+
+// decodeExperiment reads an ExperimentRequest body, responding with 400 if
+// it is invalid.
+func decodeExperiment(w http.ResponseWriter, r *http.Request) (*db.Experiment, bool) {
+	var req ExperimentRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<20)).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("Invalid request body: %v", err))
+		return nil, false
+	}
+	e := &db.Experiment{Name: req.Name, Description: req.Description, Hypothesis: req.Hypothesis, RunIDs: req.RunIDs}
+	if err := e.Validate(); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return nil, false
+	}
+	return e, true
+}
+
+// writeExperimentError maps experiment errors to 404 and 400 responses,
+// and anything else to 500.
+func writeExperimentError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, db.ErrExperimentNotFound):
+		writeError(w, http.StatusNotFound, "Experiment not found")
+	case errors.Is(err, db.ErrUnknownRun):
+		writeError(w, http.StatusBadRequest, "run_ids contains an unknown run")
+	default:
+		writeError(w, http.StatusInternalServerError, err.Error())
+	}
+}
@@ -0,0 +1,267 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/graphql-go/graphql"
+	"github.com/kaldun-tech/grpc-rest-benchmark/pkg/db"
+	"github.com/kaldun-tech/grpc-rest-benchmark/pkg/graphqlapi"
+	"github.com/kaldun-tech/grpc-rest-benchmark/pkg/servertiming"
+	"github.com/kaldun-tech/grpc-rest-benchmark/pkg/timing"
+)
+
+// graphQLSchema returns the GraphQL schema, resolving balances and
+// transactions from the dataset.
+func (s *Server) graphQLSchema() (graphql.Schema, error) {
+	return graphqlapi.NewSchema(graphqlapi.Resolvers{
+		Balance: func(ctx context.Context, accountID string) (*graphqlapi.Balance, error) {
+			account, err := s.dataset.GetBalance(ctx, accountID)
+			if err != nil {
+				return nil, graphqlapi.NewError(graphqlapi.CodeNotFound, "Account not found: %v", err)
+			}
+			return graphQLBalance(account), nil
+		},
+		Balances: func(ctx context.Context, ids []string) ([]*graphqlapi.Balance, error) {
+			accounts, err := s.dataset.GetBalances(ctx, ids)
+			if err != nil {
+				return nil, graphqlapi.NewError(graphqlapi.CodeInternal, "Failed to get balances: %v", err)
+			}
+			balances := make([]*graphqlapi.Balance, len(accounts))
+			for i, acc := range accounts {
+				balances[i] = graphQLBalance(acc)
+			}
+			return balances, nil
+		},
+		Transactions: s.graphQLTransactions,
+	})
+}
+
+// handleGraphQL handles POST /api/v1/graphql: queries of balances, answered
+// with JSON, and the transactions subscription, answered with GraphQL over
+// SSE (see graphqlapi). Requests that cannot be executed get a response
+// with errors only, with status 400 if the body is not a GraphQL request.
+func (s *Server) handleGraphQL(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		writeGraphQLError(w, http.StatusMethodNotAllowed, graphqlapi.NewError(graphqlapi.CodeBadUserInput, "GraphQL requests must be POSTed"))
+		return
+	}
+
+	data, err := io.ReadAll(http.MaxBytesReader(w, r.Body, 1<<20))
+	if err != nil {
+		writeGraphQLError(w, http.StatusBadRequest, graphqlapi.NewError(graphqlapi.CodeBadUserInput, "Failed to read request: %v", err))
+		return
+	}
+	var req graphqlapi.Request
+	if err := jsonCodec.Unmarshal(data, &req); err != nil || req.Query == "" {
+		writeGraphQLError(w, http.StatusBadRequest, graphqlapi.NewError(graphqlapi.CodeBadUserInput, "Request body must be a JSON object with a query"))
+		return
+	}
+
+	op, gqlErr := graphqlapi.Parse(s.graphQL, &req)
+	if gqlErr != nil {
+		writeGraphQLError(w, http.StatusOK, gqlErr)
+		return
+	}
+
+	if op.Type == "subscription" {
+		s.graphQLSubscription(w, r, op)
+		return
+	}
+	// Queries report their database time like the REST unary endpoints
+	servertiming.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeGraphQLResult(w, op.Execute(r.Context()))
+	})).ServeHTTP(w, r)
+}
+
+// graphQLBalance returns the GraphQL Balance of an account.
+func graphQLBalance(account *db.Account) *graphqlapi.Balance {
+	return &graphqlapi.Balance{
+		AccountID:      account.AccountID,
+		BalanceTinybar: account.Balance,
+		Timestamp:      account.UpdatedAt.Format(time.RFC3339),
+	}
+}
+
+// graphQLStream is the root value of a transactions subscription. Its
+// resolver closes subscribed once the dataset stream has started.
+type graphQLStream struct {
+	subscribed chan struct{}
+	live       bool
+}
+
+// graphQLTransactions resolves the transactions subscription: it streams
+// the dataset's transactions, paced like the REST stream, then the error
+// that ended the stream, if any.
+func (s *Server) graphQLTransactions(ctx context.Context, root any, args graphqlapi.TransactionsArgs) (chan any, error) {
+	var since time.Time
+	if args.Since != "" {
+		var err error
+		if since, err = time.Parse(time.RFC3339, args.Since); err != nil {
+			return nil, graphqlapi.NewError(graphqlapi.CodeBadUserInput, "Invalid since timestamp: %v", err)
+		}
+	}
+
+	txCh, errCh := s.dataset.StreamTransactions(ctx, db.StreamTransactionsOptions{
+		Since:         since,
+		FilterAccount: args.Account,
+		After:         args.After,
+		Live:          args.Live,
+	})
+	stream := root.(*graphQLStream)
+	stream.live = args.Live
+	close(stream.subscribed)
+
+	events := make(chan any)
+	go func() {
+		defer close(events)
+
+		// Rate limiting; a requested rate takes precedence over the
+		// pacing schedule
+		var ticker *time.Ticker
+		var pacer *timing.Pacer
+		timestampLayout := time.RFC3339
+		if args.Live {
+			timestampLayout = time.RFC3339Nano
+		} else if args.RateLimit > 0 {
+			ticker = time.NewTicker(time.Second / time.Duration(args.RateLimit))
+			defer ticker.Stop()
+		} else if s.schedule != nil {
+			pacer = s.schedule.NewPacer()
+		}
+
+		for tx := range txCh {
+			if ticker != nil {
+				select {
+				case <-ticker.C:
+				case <-ctx.Done():
+					return
+				}
+			} else if pacer != nil {
+				if err := pacer.Wait(ctx); err != nil {
+					return
+				}
+			}
+
+			// Stamped after pacing, so delivery latency covers only the send
+			event := &graphqlapi.Transaction{
+				TxID:           tx.TxID,
+				FromAccount:    tx.FromAccount,
+				ToAccount:      tx.ToAccount,
+				AmountTinybar:  tx.Amount,
+				TxType:         tx.TxType,
+				Timestamp:      tx.Timestamp.Format(timestampLayout),
+				SentAtUnixNano: time.Now().UnixNano(),
+			}
+			select {
+			case events <- event:
+			case <-ctx.Done():
+				return
+			}
+		}
+
+		select {
+		case err := <-errCh:
+			if err != nil {
+				select {
+				case events <- graphqlapi.NewError(graphqlapi.CodeInternal, "%v", err):
+				case <-ctx.Done():
+				}
+			}
+		default:
+		}
+	}()
+	return events, nil
+}
+
+// graphQLSubscription executes the transactions subscription, streaming a
+// "next" event per transaction with the fields the operation selects. Live
+// streams send their headers once subscribed. A stream that completes
+// reports the number of transactions sent in the Messages-Sent trailer; one
+// that fails sends a "next" event with the error and ends without it.
+func (s *Server) graphQLSubscription(w http.ResponseWriter, r *http.Request, op *graphqlapi.Operation) {
+	ctx, cancel := context.WithCancel(r.Context())
+	stream := &graphQLStream{subscribed: make(chan struct{})}
+	results := op.Subscribe(ctx, stream)
+	defer func() {
+		cancel()
+		for range results {
+		}
+	}()
+
+	// A result before the subscription started is the error that kept it
+	// from starting, e.g. an invalid argument
+	var first *graphql.Result
+	select {
+	case <-stream.subscribed:
+	case first = <-results:
+	}
+	select {
+	case <-stream.subscribed:
+	default:
+		if first != nil {
+			writeGraphQLResult(w, first)
+		}
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeGraphQLError(w, http.StatusInternalServerError, graphqlapi.NewError(graphqlapi.CodeInternal, "Streaming not supported"))
+		return
+	}
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Trailer", messagesSentTrailer)
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	if stream.live {
+		flusher.Flush()
+	}
+
+	sent := 0
+	send := func(result *graphql.Result) bool {
+		fmt.Fprintf(w, "event: next\ndata: %s\n\n", graphqlapi.Encode(result))
+		if result.HasErrors() {
+			fmt.Fprint(w, "event: complete\ndata:\n\n")
+			flusher.Flush()
+			return false
+		}
+		flusher.Flush()
+		sent++
+		return true
+	}
+	if first != nil && !send(first) {
+		return
+	}
+	for result := range results {
+		if !send(result) {
+			return
+		}
+	}
+
+	if ctx.Err() == nil {
+		fmt.Fprint(w, "event: complete\ndata:\n\n")
+		w.Header().Set(messagesSentTrailer, strconv.Itoa(sent))
+	}
+}
+
+// writeGraphQLResult writes the JSON response to an executed operation.
+func writeGraphQLResult(w http.ResponseWriter, result *graphql.Result) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write(append(graphqlapi.Encode(result), '\n'))
+}
+
+// writeGraphQLError writes the response to a request that failed before
+// execution.
+func writeGraphQLError(w http.ResponseWriter, status int, err *graphqlapi.Error) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	w.Write(append(graphqlapi.ErrorResponse(err), '\n'))
+}
@@ -0,0 +1,328 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/kaldun-tech/grpc-rest-benchmark/pkg/db"
+	"github.com/kaldun-tech/grpc-rest-benchmark/pkg/jsonrpcapi"
+	"github.com/kaldun-tech/grpc-rest-benchmark/pkg/payload"
+	"github.com/kaldun-tech/grpc-rest-benchmark/pkg/restapi"
+	"github.com/kaldun-tech/grpc-rest-benchmark/pkg/servertiming"
+	"github.com/kaldun-tech/grpc-rest-benchmark/pkg/timing"
+)
+
+// handleJSONRPC handles POST /api/v1/jsonrpc: JSON-RPC 2.0 requests and
+// batches of them, answered with JSON, and the streamTransactions request,
+// answered with a stream of notifications (see jsonrpcapi). Errors are
+// reported in responses with status 200, as JSON-RPC over HTTP does;
+// batches of notifications only are answered with 204 No Content.
+func (s *Server) handleJSONRPC(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		writeJSONRPCError(w, http.StatusMethodNotAllowed, jsonrpcapi.NewError(jsonrpcapi.CodeInvalidRequest, "JSON-RPC requests must be POSTed"))
+		return
+	}
+
+	data, err := io.ReadAll(http.MaxBytesReader(w, r.Body, 1<<20))
+	if err != nil {
+		writeJSONRPCError(w, http.StatusBadRequest, jsonrpcapi.NewError(jsonrpcapi.CodeInvalidRequest, "Failed to read request: %v", err))
+		return
+	}
+	if !json.Valid(data) {
+		writeJSONRPCError(w, http.StatusOK, jsonrpcapi.NewError(jsonrpcapi.CodeParseError, "Request body is not valid JSON"))
+		return
+	}
+
+	// A batch is an array of requests, each answered on its own
+	elements := []json.RawMessage{data}
+	batch := bytes.HasPrefix(bytes.TrimLeft(data, " \t\r\n"), []byte("["))
+	if batch {
+		if err := jsonCodec.Unmarshal(data, &elements); err != nil || len(elements) == 0 {
+			writeJSONRPCError(w, http.StatusOK, jsonrpcapi.NewError(jsonrpcapi.CodeInvalidRequest, "A batch must be a non-empty array of requests"))
+			return
+		}
+	}
+	reqs := make([]*jsonrpcapi.Request, len(elements))
+	for i, element := range elements {
+		var req jsonrpcapi.Request
+		if err := jsonCodec.Unmarshal(element, &req); err == nil {
+			reqs[i] = &req
+		}
+	}
+
+	if !batch && reqs[0] != nil && reqs[0].Method == jsonrpcapi.MethodStreamTransactions && reqs[0].Validate() == nil {
+		s.jsonRPCStream(w, r, reqs[0])
+		return
+	}
+	// Calls report their database time like the REST unary endpoints
+	servertiming.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.jsonRPCCalls(w, r, reqs, batch)
+	})).ServeHTTP(w, r)
+}
+
+// jsonRPCCalls executes the requests of a POST in order, nil for elements
+// that are not request objects, and writes their responses.
+func (s *Server) jsonRPCCalls(w http.ResponseWriter, r *http.Request, reqs []*jsonrpcapi.Request, batch bool) {
+	var responses []jsonrpcapi.Response
+	for _, req := range reqs {
+		if req == nil {
+			responses = append(responses, jsonrpcapi.Response{
+				JSONRPC: jsonrpcapi.Version,
+				Error:   jsonrpcapi.NewError(jsonrpcapi.CodeInvalidRequest, "A request must be a JSON object"),
+			})
+			continue
+		}
+
+		resp := jsonrpcapi.Response{JSONRPC: jsonrpcapi.Version, ID: req.ID}
+		if err := req.Validate(); err != nil {
+			resp.Error = err
+		} else if result, err := s.jsonRPCCall(r.Context(), req); err != nil {
+			resp.Error = err
+		} else {
+			resp.Result, resp.Error = marshalJSONRPC(result)
+		}
+		if !req.IsNotification() {
+			responses = append(responses, resp)
+		}
+	}
+
+	if len(responses) == 0 {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	if batch {
+		writeJSON(w, http.StatusOK, responses)
+		return
+	}
+	writeJSON(w, http.StatusOK, responses[0])
+}
+
+// jsonRPCCall executes a unary request and returns its result.
+func (s *Server) jsonRPCCall(ctx context.Context, req *jsonrpcapi.Request) (any, *jsonrpcapi.Error) {
+	switch req.Method {
+	case jsonrpcapi.MethodGetBalance:
+		var params jsonrpcapi.BalanceParams
+		if err := decodeJSONRPCParams(req, &params); err != nil {
+			return nil, err
+		}
+		if params.Account == "" {
+			return nil, jsonrpcapi.NewError(jsonrpcapi.CodeInvalidParams, "account is required")
+		}
+		account, err := s.dataset.GetBalance(ctx, params.Account)
+		if err != nil {
+			return nil, jsonrpcapi.NewError(jsonrpcapi.CodeNotFound, "Account not found: %v", err)
+		}
+		return BalanceResponse{
+			Account:   account.AccountID,
+			Balance:   account.Balance,
+			Timestamp: account.UpdatedAt.Format(time.RFC3339),
+		}, nil
+
+	case jsonrpcapi.MethodGetBalances:
+		var params jsonrpcapi.BalancesParams
+		if err := decodeJSONRPCParams(req, &params); err != nil {
+			return nil, err
+		}
+		if len(params.IDs) == 0 {
+			return nil, jsonrpcapi.NewError(jsonrpcapi.CodeInvalidParams, "ids are required")
+		}
+		accounts, err := s.dataset.GetBalances(ctx, params.IDs)
+		if err != nil {
+			return nil, jsonrpcapi.NewError(jsonrpcapi.CodeInternalError, "Failed to get balances: %v", err)
+		}
+		balances := make([]BalanceResponse, len(accounts))
+		for i, acc := range accounts {
+			balances[i] = BalanceResponse{
+				Account:   acc.AccountID,
+				Balance:   acc.Balance,
+				Timestamp: acc.UpdatedAt.Format(time.RFC3339),
+			}
+		}
+		return BatchBalanceResponse{Balances: balances}, nil
+
+	case jsonrpcapi.MethodSubmitTransaction:
+		var params SubmitTransactionRequest
+		if err := decodeJSONRPCParams(req, &params); err != nil {
+			return nil, err
+		}
+		tx := &db.Transaction{
+			FromAccount: params.From,
+			ToAccount:   params.To,
+			Amount:      params.Amount,
+			TxType:      params.Type,
+		}
+		if err := tx.Validate(); err != nil {
+			return nil, jsonrpcapi.NewError(jsonrpcapi.CodeInvalidParams, "%v", err)
+		}
+		if err := s.dataset.InsertTransaction(ctx, tx); err != nil {
+			return nil, jsonrpcapi.NewError(jsonrpcapi.CodeInternalError, "Failed to submit transaction: %v", err)
+		}
+		return TransactionEvent{
+			TxID:      tx.TxID,
+			From:      tx.FromAccount,
+			To:        tx.ToAccount,
+			Amount:    tx.Amount,
+			Type:      tx.TxType,
+			Timestamp: tx.Timestamp.Format(time.RFC3339),
+		}, nil
+
+	case jsonrpcapi.MethodEcho:
+		var params jsonrpcapi.EchoParams
+		if err := decodeJSONRPCParams(req, &params); err != nil {
+			return nil, err
+		}
+		b, err := payload.Bytes(params.Size)
+		if err != nil {
+			return nil, jsonrpcapi.NewError(jsonrpcapi.CodeInvalidParams, "%v", err)
+		}
+		return EchoResponse{Payload: b}, nil
+
+	case jsonrpcapi.MethodStreamTransactions:
+		return nil, jsonrpcapi.NewError(jsonrpcapi.CodeInvalidRequest, "%s cannot be part of a batch", req.Method)
+	}
+	return nil, jsonrpcapi.NewError(jsonrpcapi.CodeMethodNotFound, "Method not found: %s", req.Method)
+}
+
+// decodeJSONRPCParams decodes the named parameters of req into params.
+func decodeJSONRPCParams(req *jsonrpcapi.Request, params any) *jsonrpcapi.Error {
+	if len(req.Params) == 0 {
+		return nil
+	}
+	if err := jsonCodec.Unmarshal(req.Params, params); err != nil {
+		return jsonrpcapi.NewError(jsonrpcapi.CodeInvalidParams, "Invalid params: %v", err)
+	}
+	return nil
+}
+
+// marshalJSONRPC encodes the result of a request.
+func marshalJSONRPC(result any) (json.RawMessage, *jsonrpcapi.Error) {
+	data, err := jsonCodec.Marshal(result)
+	if err != nil {
+		return nil, jsonrpcapi.NewError(jsonrpcapi.CodeInternalError, "Failed to encode result: %v", err)
+	}
+	return data, nil
+}
+
+// jsonRPCStream executes streamTransactions, streaming a transaction
+// notification per transaction, paced like the REST stream, and then the
+// response: the number of transactions sent, or the error that ended the
+// stream.
+func (s *Server) jsonRPCStream(w http.ResponseWriter, r *http.Request, req *jsonrpcapi.Request) {
+	var params jsonrpcapi.StreamParams
+	var since time.Time
+	err := decodeJSONRPCParams(req, &params)
+	if err == nil && params.Since != "" {
+		var parseErr error
+		if since, parseErr = time.Parse(time.RFC3339, params.Since); parseErr != nil {
+			err = jsonrpcapi.NewError(jsonrpcapi.CodeInvalidParams, "Invalid since timestamp: %v", parseErr)
+		}
+	}
+	if err != nil {
+		writeJSON(w, http.StatusOK, jsonrpcapi.Response{JSONRPC: jsonrpcapi.Version, Error: err, ID: req.ID})
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeJSONRPCError(w, http.StatusInternalServerError, jsonrpcapi.NewError(jsonrpcapi.CodeInternalError, "Streaming not supported"))
+		return
+	}
+	w.Header().Set("Content-Type", jsonrpcapi.StreamContentType)
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+
+	ctx := r.Context()
+	txCh, errCh := s.dataset.StreamTransactions(ctx, db.StreamTransactionsOptions{
+		Since:         since,
+		FilterAccount: params.Account,
+		After:         params.After,
+		Live:          params.Live,
+	})
+
+	// Rate limiting; a requested rate takes precedence over the pacing
+	// schedule. Live streams send their headers once subscribed.
+	var ticker *time.Ticker
+	var pacer *timing.Pacer
+	timestampLayout := time.RFC3339
+	if params.Live {
+		timestampLayout = time.RFC3339Nano
+		flusher.Flush()
+	} else if params.Rate > 0 {
+		ticker = time.NewTicker(time.Second / time.Duration(params.Rate))
+		defer ticker.Stop()
+	} else if s.schedule != nil {
+		pacer = s.schedule.NewPacer()
+	}
+
+	sent := 0
+	for tx := range txCh {
+		if ticker != nil {
+			select {
+			case <-ticker.C:
+			case <-ctx.Done():
+				return
+			}
+		} else if pacer != nil {
+			if err := pacer.Wait(ctx); err != nil {
+				return
+			}
+		}
+
+		// Stamped after pacing, so delivery latency covers only the send
+		event, err := jsonCodec.Marshal(TransactionEvent{
+			TxID:      tx.TxID,
+			From:      tx.FromAccount,
+			To:        tx.ToAccount,
+			Amount:    tx.Amount,
+			Type:      tx.TxType,
+			Timestamp: tx.Timestamp.Format(timestampLayout),
+			SentAt:    time.Now().UnixNano(),
+		})
+		if err != nil {
+			continue
+		}
+		data, err := jsonCodec.Marshal(jsonrpcapi.Request{
+			JSONRPC: jsonrpcapi.Version,
+			Method:  jsonrpcapi.NotificationTransaction,
+			Params:  event,
+		})
+		if err != nil {
+			continue
+		}
+		fmt.Fprintf(w, "%s\n", data)
+		flusher.Flush()
+		sent++
+	}
+
+	resp := jsonrpcapi.Response{JSONRPC: jsonrpcapi.Version, ID: req.ID}
+	select {
+	case err := <-errCh:
+		if err != nil {
+			resp.Error = jsonrpcapi.NewError(jsonrpcapi.CodeInternalError, "%v", err)
+		}
+	default:
+	}
+	if ctx.Err() != nil || req.IsNotification() {
+		return
+	}
+	if resp.Error == nil {
+		resp.Result, resp.Error = marshalJSONRPC(restapi.StreamDone{Sent: int64(sent)})
+	}
+	if data, err := jsonCodec.Marshal(resp); err == nil {
+		fmt.Fprintf(w, "%s\n", data)
+		flusher.Flush()
+	}
+}
+
+// writeJSONRPCError writes the response to a POST whose requests could not
+// be read.
+func writeJSONRPCError(w http.ResponseWriter, status int, err *jsonrpcapi.Error) {
+	writeJSON(w, status, jsonrpcapi.Response{JSONRPC: jsonrpcapi.Version, Error: err})
+}
@@ -0,0 +1,153 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kaldun-tech/grpc-rest-benchmark/pkg/launch"
+)
+
+// LaunchRequest is the JSON body launching a run: a workload file, run
+// locally or with its load generated on a registered agent.
+type LaunchRequest = launch.Request
+
+// LaunchResponse is the JSON representation of a launched run. RunIDs
+// lists the runs it stored so far, one per workload stage, and Results
+// their results API URLs. Output, the end of the client's output, is only
+// returned for a single launch.
+type LaunchResponse struct {
+	ID         int64    `json:"id"`
+	Status     string   `json:"status"`
+	Workload   string   `json:"workload"`
+	Agent      string   `json:"agent,omitempty"`
+	StartedAt  string   `json:"started_at"`
+	FinishedAt string   `json:"finished_at,omitempty"`
+	Error      string   `json:"error,omitempty"`
+	RunIDs     []int64  `json:"run_ids"`
+	Results    []string `json:"results"`
+	URL        string   `json:"url"`
+	Output     string   `json:"output,omitempty"`
+}
+
+// LaunchesResponse is the JSON response for the launch list, newest first,
+// with the configured agents runs may be launched on; registered agents
+// are listed by /api/v1/agents.
+type LaunchesResponse struct {
+	Launches []LaunchResponse `json:"launches"`
+	Count    int              `json:"count"`
+	Agents   []string         `json:"agents"`
+}
+
+func launchResponse(l launch.Launch, withOutput bool) LaunchResponse {
+	resp := LaunchResponse{
+		ID:        l.ID,
+		Status:    l.Status,
+		Workload:  l.Workload,
+		Agent:     l.Agent,
+		StartedAt: l.StartedAt.Format(time.RFC3339),
+		Error:     l.Error,
+		RunIDs:    make([]int64, len(l.RunIDs)),
+		Results:   make([]string, len(l.RunIDs)),
+		URL:       fmt.Sprintf("/api/v1/runs/launch/%d", l.ID),
+	}
+	if !l.FinishedAt.IsZero() {
+		resp.FinishedAt = l.FinishedAt.Format(time.RFC3339)
+	}
+	for i, id := range l.RunIDs {
+		resp.RunIDs[i] = id
+		resp.Results[i] = fmt.Sprintf("/api/v1/results?run_id=%d", id)
+	}
+	if withOutput {
+		resp.Output = l.Output
+	}
+	return resp
+}
+
+// handleLaunches handles GET (list) and POST (launch)
+// /api/v1/runs/launch. POST validates the workload, starts the benchmark
+// client and responds 202 Accepted without waiting for the run.
+func (s *Server) handleLaunches(w http.ResponseWriter, r *http.Request) {
+	if s.launcher == nil {
+		writeError(w, http.StatusServiceUnavailable, "Launching runs is disabled (start the server with --launch-cmd)")
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		launches := s.launcher.List()
+		resp := LaunchesResponse{Launches: make([]LaunchResponse, len(launches)), Count: len(launches), Agents: s.launcher.Agents()}
+		for i, l := range launches {
+			resp.Launches[i] = launchResponse(l, false)
+		}
+		if resp.Agents == nil {
+			resp.Agents = []string{}
+		}
+		writeJSON(w, http.StatusOK, resp)
+
+	case http.MethodPost:
+		var req LaunchRequest
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<20)).Decode(&req); err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Sprintf("Invalid request body: %v", err))
+			return
+		}
+		l, err := s.launcher.Start(r.Context(), req)
+		switch {
+		case errors.Is(err, launch.ErrInvalidRequest):
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		case errors.Is(err, launch.ErrBusy):
+			writeError(w, http.StatusConflict, err.Error())
+			return
+		case err != nil:
+			writeError(w, http.StatusInternalServerError, fmt.Sprintf("Failed to launch run: %v", err))
+			return
+		}
+		log.Printf("Launched run %d: workload %s", l.ID, l.Workload)
+		writeJSON(w, http.StatusAccepted, launchResponse(l, false))
+
+	default:
+		writeError(w, http.StatusMethodNotAllowed, "Method not allowed")
+	}
+}
+
+// handleLaunch handles GET (status) and DELETE (cancel)
+// /api/v1/runs/launch/{id}. A canceled run stops like on Ctrl-C, storing
+// the stage in progress.
+func (s *Server) handleLaunch(w http.ResponseWriter, r *http.Request) {
+	if s.launcher == nil {
+		writeError(w, http.StatusServiceUnavailable, "Launching runs is disabled (start the server with --launch-cmd)")
+		return
+	}
+	idStr := strings.TrimPrefix(r.URL.Path, "/api/v1/runs/launch/")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("Invalid launch ID: %s", idStr))
+		return
+	}
+
+	var l launch.Launch
+	switch r.Method {
+	case http.MethodGet:
+		l, err = s.launcher.Get(id)
+	case http.MethodDelete:
+		l, err = s.launcher.Cancel(id)
+	default:
+		writeError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+	if errors.Is(err, launch.ErrNotFound) {
+		writeError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, launchResponse(l, true))
+}
@@ -2,21 +2,50 @@ package main
 
 import (
 	"context"
-	"encoding/json"
 	"flag"
 	"fmt"
+	"io"
 	"io/fs"
 	"log"
+	"mime"
 	"net/http"
+	"net/url"
 	"os"
 	"os/signal"
+	"slices"
 	"strconv"
 	"strings"
 	"syscall"
 	"time"
 
+	"github.com/graphql-go/graphql"
+	"github.com/kaldun-tech/grpc-rest-benchmark/pkg/auth"
+	"github.com/kaldun-tech/grpc-rest-benchmark/pkg/buildinfo"
+	"github.com/kaldun-tech/grpc-rest-benchmark/pkg/cache"
+	"github.com/kaldun-tech/grpc-rest-benchmark/pkg/compression"
 	"github.com/kaldun-tech/grpc-rest-benchmark/pkg/db"
+	"github.com/kaldun-tech/grpc-rest-benchmark/pkg/dbtarget"
+	"github.com/kaldun-tech/grpc-rest-benchmark/pkg/fanout"
+	"github.com/kaldun-tech/grpc-rest-benchmark/pkg/fault"
+	"github.com/kaldun-tech/grpc-rest-benchmark/pkg/feed"
+	"github.com/kaldun-tech/grpc-rest-benchmark/pkg/jsoncodec"
+	"github.com/kaldun-tech/grpc-rest-benchmark/pkg/launch"
+	"github.com/kaldun-tech/grpc-rest-benchmark/pkg/middleware"
+	"github.com/kaldun-tech/grpc-rest-benchmark/pkg/payload"
+	"github.com/kaldun-tech/grpc-rest-benchmark/pkg/profiling"
+	"github.com/kaldun-tech/grpc-rest-benchmark/pkg/protos"
+	"github.com/kaldun-tech/grpc-rest-benchmark/pkg/qos"
+	"github.com/kaldun-tech/grpc-rest-benchmark/pkg/ratelimit"
+	"github.com/kaldun-tech/grpc-rest-benchmark/pkg/restapi"
+	"github.com/kaldun-tech/grpc-rest-benchmark/pkg/restart"
+	"github.com/kaldun-tech/grpc-rest-benchmark/pkg/results"
+	"github.com/kaldun-tech/grpc-rest-benchmark/pkg/serverstats"
+	"github.com/kaldun-tech/grpc-rest-benchmark/pkg/servertiming"
+	"github.com/kaldun-tech/grpc-rest-benchmark/pkg/timing"
 	"github.com/kaldun-tech/grpc-rest-benchmark/web"
+	"google.golang.org/protobuf/encoding/protodelim"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
 )
 
 var (
@@ -26,80 +55,185 @@ var (
 	dbUser = flag.String("db-user", "benchmark", "PostgreSQL user")
 	dbPass = flag.String("db-pass", "benchmark_pass", "PostgreSQL password")
 	dbName = flag.String("db-name", "grpc_benchmark", "PostgreSQL database")
+
+	// Server-side stream pacing from recorded HCS timing
+	paceTiming  = flag.String("pace-timing", "", "Timing JSON file used to pace streams that request no rate limit")
+	paceMode    = flag.String("pace-mode", timing.ModeSequential, "Pacing replay mode: sequential | sample")
+	paceSpeedup = flag.Float64("pace-speedup", 1.0, "Pacing speedup factor (1.0 = real-time)")
+
+	jsonEncoder   = flag.String("json-encoder", jsoncodec.Std, "JSON encoder: "+strings.Join(jsoncodec.Names, " | "))
+	parityMarshal = flag.String("parity-marshal", restapi.ParityProtoJSON, "Encoder of parity responses: "+strings.Join(restapi.ParityMarshals, " | ")+" (structs = the JSON encoder on hand-written types)")
+
+	// Framing of JSON streams for clients whose Accept header names none
+	restStreamFormat = flag.String("rest-stream-format", restapi.StreamSSE, "Framing of JSON transaction streams for clients that do not ask for one: "+strings.Join(restapi.StreamFormats, " | "))
 )
 
+// jsonCodec encodes JSON responses and decodes transaction submissions
+// with the encoder selected by -json-encoder.
+var jsonCodec jsoncodec.Codec
+
 // Server holds the REST server state.
 type Server struct {
-	db *db.DB
-}
-
-// BalanceResponse is the JSON response for balance queries.
-type BalanceResponse struct {
-	Account   string `json:"account"`
-	Balance   int64  `json:"balance"`
-	Timestamp string `json:"timestamp"`
+	db       *db.DB
+	dataset  qos.Dataset      // balance queries and streams, with the QoS mode and balance cache applied
+	balances *cache.Dataset   // the balance cache, for its hit and miss counts
+	targets  *dbtarget.Switch // the database the dataset reads, switched by the admin endpoint
+	schedule *timing.Schedule // optional pacing for streams without a rate limit
+	restarts *restart.Trigger // restarts requested by the admin endpoint
+	launcher *launch.Launcher // runs started by the launch API, nil when disabled
+	graphQL  graphql.Schema   // the GraphQL schema, resolved from the dataset
 }
 
-// BatchBalanceResponse is the JSON response for batch balance queries.
-type BatchBalanceResponse struct {
-	Balances []BalanceResponse `json:"balances"`
-}
+// The benchmark endpoint bodies are shared with the benchmark client.
+type (
+	BalanceResponse          = restapi.BalanceResponse
+	BatchBalanceResponse     = restapi.BatchBalanceResponse
+	TransactionEvent         = restapi.TransactionEvent
+	SubmitTransactionRequest = restapi.SubmitTransactionRequest
+	EchoResponse             = restapi.EchoResponse
+	ErrorResponse            = restapi.ErrorResponse
+)
 
-// TransactionEvent is the JSON payload for SSE transaction events.
-type TransactionEvent struct {
-	TxID      string `json:"tx_id"`
-	From      string `json:"from"`
-	To        string `json:"to"`
-	Amount    int64  `json:"amount"`
-	Type      string `json:"type"`
-	Timestamp string `json:"timestamp"`
-}
+// ServerStatsResponse is the JSON response for server stats requests.
+type ServerStatsResponse struct {
+	CPUSeconds  float64 `json:"cpu_seconds"`
+	CacheHits   int64   `json:"cache_hits"`
+	CacheMisses int64   `json:"cache_misses"`
 
-// ErrorResponse is the JSON response for errors.
-type ErrorResponse struct {
-	Error string `json:"error"`
+	PoolAcquireWaits    int64 `json:"pool_acquire_waits"`
+	PoolAcquireFailures int64 `json:"pool_acquire_failures"`
 }
 
-// BenchmarkResult is a single benchmark result for the API.
-type BenchmarkResult struct {
-	RunID        int64    `json:"run_id"`
-	Scenario     string   `json:"scenario"`
-	Protocol     string   `json:"protocol"`
-	Client       string   `json:"client"`
-	Concurrency  int      `json:"concurrency"`
-	DurationSec  int      `json:"duration_sec"`
-	TotalSamples int64    `json:"total_samples"`
-	Successful   int64    `json:"successful"`
-	Throughput   float64  `json:"throughput"`
-	P50Latency   float64  `json:"p50_latency_ms"`
-	P90Latency   float64  `json:"p90_latency_ms"`
-	P99Latency   float64  `json:"p99_latency_ms"`
-	AvgLatency   float64  `json:"avg_latency_ms"`
-	MinLatency   float64  `json:"min_latency_ms"`
-	MaxLatency   float64  `json:"max_latency_ms"`
-	CPUUsageAvg  *float64 `json:"cpu_usage_avg,omitempty"`
-	MemoryMBAvg  *float64 `json:"memory_mb_avg,omitempty"`
-	MemoryMBPeak *float64 `json:"memory_mb_peak,omitempty"`
-}
+// The results API response types are shared with its Go client.
+type (
+	BenchmarkResult      = results.Run
+	ConcurrencyGroup     = results.ConcurrencyGroup
+	TimeseriesPoint      = results.TimeseriesPoint
+	TimeseriesResponse   = results.Timeseries
+	TrendPoint           = results.TrendPoint
+	TrendSeries          = results.TrendSeries
+	TrendsResponse       = results.Trends
+	EventResponse        = results.Event
+	EventsResponse       = results.Events
+	AgentResponse        = results.Agent
+	AgentsResponse       = results.Agents
+	AgentRegistration    = results.AgentRegistration
+	EnvironmentRequest   = results.Environment
+	EnvironmentResponse  = results.Environment
+	EnvironmentsResponse = results.Environments
+	RunMetadataRequest   = results.RunMetadata
+	ProfileResponse      = results.Profile
+	ProfilesResponse     = results.Profiles
+)
 
 // ResultsResponse is the JSON response for benchmark results.
 type ResultsResponse struct {
 	Results []BenchmarkResult `json:"results"`
 	Count   int               `json:"count"`
+
+	// NextCursor continues the listing with ?cursor=, set when the page
+	// is full.
+	NextCursor string `json:"next_cursor,omitempty"`
 }
 
+// ConcurrencyResponse is the JSON response for results grouped by
+// concurrency.
+type ConcurrencyResponse struct {
+	Groups []ConcurrencyGroup `json:"groups"`
+	Count  int                `json:"count"`
+}
+
+// protobufContentType is the media type of protobuf-encoded request and
+// response bodies, which the benchmark endpoints use instead of JSON when
+// the client asks for it. Streams of it are length-delimited messages.
+const protobufContentType = "application/x-protobuf"
+
+// Trend query defaults and the largest number of points a series may have
+const (
+	defaultTrendMetric = "p99"
+	defaultTrendWindow = "90d"
+	defaultTrendPoints = 90
+	maxTrendPoints     = 1000
+)
+
 func main() {
+	var qosCfg qos.Config
+	qosCfg.RegisterFlags(flag.CommandLine)
+	var queryTx db.QueryTx
+	queryTx.RegisterFlags(flag.CommandLine)
+	var faultCfg fault.Config
+	faultCfg.RegisterFlags(flag.CommandLine)
+	var middlewareCfg middleware.Config
+	middlewareCfg.RegisterFlags(flag.CommandLine)
+	var authCfg auth.Config
+	authCfg.RegisterFlags(flag.CommandLine)
+	var limitCfg ratelimit.Config
+	limitCfg.RegisterFlags(flag.CommandLine)
+	var cacheCfg cache.Config
+	cacheCfg.RegisterFlags(flag.CommandLine)
+	var targetCfg dbtarget.Config
+	targetCfg.RegisterFlags(flag.CommandLine)
+	var liveCfg fanout.Config
+	liveCfg.RegisterFlags(flag.CommandLine)
+	var feedCfg feed.Config
+	feedCfg.RegisterFlags(flag.CommandLine)
+	var launchCfg launch.Config
+	launchCfg.RegisterFlags(flag.CommandLine)
 	flag.Parse()
+	if err := qosCfg.Validate(); err != nil {
+		log.Fatal(err)
+	}
+	if err := queryTx.Validate(); err != nil {
+		log.Fatal(err)
+	}
+	if err := faultCfg.Validate(); err != nil {
+		log.Fatal(err)
+	}
+	if err := middlewareCfg.Validate(); err != nil {
+		log.Fatal(err)
+	}
+	if err := limitCfg.Validate(); err != nil {
+		log.Fatal(err)
+	}
+	if err := authCfg.Validate(); err != nil {
+		log.Fatal(err)
+	}
+	if err := cacheCfg.Validate(); err != nil {
+		log.Fatal(err)
+	}
+	if err := liveCfg.Validate(); err != nil {
+		log.Fatal(err)
+	}
+	if err := feedCfg.Validate(); err != nil {
+		log.Fatal(err)
+	}
+	if err := launchCfg.Validate(); err != nil {
+		log.Fatal(err)
+	}
+	var err error
+	if jsonCodec, err = jsoncodec.New(*jsonEncoder); err != nil {
+		log.Fatal(err)
+	}
+	if !slices.Contains(restapi.ParityMarshals, *parityMarshal) {
+		log.Fatalf("invalid parity marshal: %s (must be one of: %s)", *parityMarshal, strings.Join(restapi.ParityMarshals, ", "))
+	}
+	if !slices.Contains(restapi.StreamFormats, *restStreamFormat) {
+		log.Fatalf("invalid rest stream format: %s (must be one of: %s)", *restStreamFormat, strings.Join(restapi.StreamFormats, ", "))
+	}
+	tlsCfg, err := authCfg.ServerTLS()
+	if err != nil {
+		log.Fatal(err)
+	}
 
 	// Setup database connection
 	ctx := context.Background()
-	dbCfg := db.Config{
+	dbCfg := qosCfg.DBConfig(db.Config{
 		Host:     *dbHost,
 		Port:     *dbPort,
 		User:     *dbUser,
 		Password: *dbPass,
 		Database: *dbName,
-	}
+	})
 
 	database, err := db.New(ctx, dbCfg)
 	if err != nil {
@@ -108,23 +242,189 @@ func main() {
 	defer database.Close()
 	log.Printf("Connected to database %s@%s:%d", dbCfg.Database, dbCfg.Host, dbCfg.Port)
 
-	server := &Server{db: database}
+	var schedule *timing.Schedule
+	if *paceTiming != "" {
+		schedule, err = timing.LoadSchedule(*paceTiming, *paceMode, *paceSpeedup)
+		if err != nil {
+			log.Fatalf("Failed to load pacing schedule: %v", err)
+		}
+		log.Printf("Pacing unthrottled streams from %s", schedule)
+	}
+
+	dataset, closeDataset, err := qos.Open(ctx, qosCfg, database, dbCfg)
+	if err != nil {
+		log.Fatalf("Failed to apply QoS mode: %v", err)
+	}
+	defer closeDataset()
+	log.Printf("QoS: %s, database pools %s", qosCfg, qosCfg.PoolsLabel())
+	database.SetQueryTx(queryTx)
+	if queryTx != (db.QueryTx{}) {
+		log.Printf("Balance queries run in %s transactions", queryTx)
+	}
+	if faultCfg.Enabled() {
+		log.Printf("Injecting faults: %s", faultCfg)
+	}
+	if middlewareCfg.Enabled() {
+		log.Printf("Middleware: %s", middlewareCfg)
+	}
+	if authCfg.Enabled() {
+		log.Printf("Authentication: %s", authCfg)
+	}
+	if limitCfg.Enabled() {
+		log.Printf("Rate limit: %s", limitCfg)
+	}
+	targets := dbtarget.NewSwitch(targetCfg, dataset, dbtarget.Opener(qosCfg, queryTx))
+	defer targets.Close()
+	if len(targetCfg.Targets) > 0 {
+		log.Printf("Database targets: %s", strings.Join(targets.Names(), ", "))
+	}
+	live := fanout.Wrap(targets)
+	if liveCfg.Source == fanout.SourceNotify {
+		go live.Follow(ctx, database.ListenTransactions, func(err error) {
+			log.Printf("Warning: live streams lost database notifications, listening again: %v", err)
+		})
+		log.Printf("Live streams follow database notifications")
+	}
+	if feedCfg.Enabled() {
+		accountIDs, err := database.GetAllAccountIDs(ctx)
+		if err != nil {
+			log.Fatalf("Failed to load accounts for the feed: %v", err)
+		}
+		go func() {
+			err := feed.Run(ctx, live, accountIDs, feedCfg.Rate, func(err error) {
+				log.Printf("Warning: feed transaction failed: %v", err)
+			})
+			if err != nil {
+				log.Printf("Warning: feed stopped: %v", err)
+			}
+		}()
+		log.Printf("Feeding live streams %d transactions/s", feedCfg.Rate)
+	}
+	balances := cache.Wrap(live, cacheCfg)
+	if cacheCfg.Enabled() {
+		log.Printf("Caching balances: %s", cacheCfg)
+	}
+	if *jsonEncoder != jsoncodec.Std {
+		log.Printf("Encoding JSON with %s", *jsonEncoder)
+	}
+	if *parityMarshal != restapi.ParityProtoJSON {
+		log.Printf("Encoding parity responses from hand-written types with %s", *jsonEncoder)
+	}
+	serverCfg := db.ServerConfig{Pools: qosCfg.PoolsLabel(), QueryTx: queryTx.String(), Faults: faultCfg.String(), Cache: cacheCfg.String(), JSONEncoder: *jsonEncoder, ParityMarshal: *parityMarshal, Middleware: middlewareCfg.String(), RateLimit: limitCfg.String(), Commit: buildinfo.Commit()}
+	if err := database.RecordServerConfig(ctx, db.ServerREST, serverCfg); err != nil {
+		log.Printf("Warning: runs will not record the server config: %v", err)
+	}
+
+	launcher := launch.New(launchCfg, dbCfg, database)
+	defer launcher.Close()
+	if launchCfg.Enabled() {
+		log.Printf("Launching runs with %s", launchCfg)
+	}
 
-	// Setup routes
+	restarts := restart.NewTrigger()
+	server := &Server{db: database, dataset: balances, balances: balances, targets: targets, schedule: schedule, restarts: restarts, launcher: launcher}
+	graphQL, err := server.graphQLSchema()
+	if err != nil {
+		log.Fatalf("Failed to build GraphQL schema: %v", err)
+	}
+	server.graphQL = graphQL
+
+	// Setup routes. The middleware, authentication, rate limit and faults
+	// apply to the benchmark endpoints only, not health checks, server stats
+	// or results, and their unary requests report their database time in a
+	// Server-Timing header.
 	mux := http.NewServeMux()
+	api := http.NewServeMux()
+	chain, err := middleware.New(middlewareCfg, log.Default())
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer chain.Close()
+	if middlewareCfg.AccessLog != "" {
+		log.Printf("Writing the access log to %s", middlewareCfg.AccessLog)
+	}
+	authenticator := auth.New(authCfg)
+	limiter := ratelimit.New(limitCfg)
+	faults := fault.New(faultCfg)
+	unary := func(handler http.HandlerFunc) http.Handler {
+		return chain.Handler(authenticator.Handler(limiter.Handler(servertiming.Handler(faults.Handler(handler)))))
+	}
 
 	// Balance endpoints
-	mux.HandleFunc("/api/v1/accounts/", server.handleAccountBalance)
-	mux.HandleFunc("/api/v1/balances", server.handleBatchBalances)
+	api.Handle("/api/v1/accounts/", unary(server.handleAccountBalance))
+	api.Handle("/api/v1/balances", unary(server.handleBatchBalances))
+
+	// Transaction streaming and submission
+	api.Handle("/api/v1/transactions/stream", chain.Handler(authenticator.Handler(limiter.Handler(faults.Handler(http.HandlerFunc(server.handleTransactionStream))))))
+	api.Handle("/api/v1/transactions", unary(server.handleSubmitTransaction))
+
+	// GraphQL queries and the transactions subscription. Queries report
+	// their database time, so the handler applies servertiming itself.
+	api.Handle("/api/v1/graphql", chain.Handler(authenticator.Handler(limiter.Handler(faults.Handler(http.HandlerFunc(server.handleGraphQL))))))
+
+	// JSON-RPC 2.0 calls and transaction stream, the same way
+	api.Handle("/api/v1/jsonrpc", chain.Handler(authenticator.Handler(limiter.Handler(faults.Handler(http.HandlerFunc(server.handleJSONRPC))))))
+
+	// Payload size scenario
+	api.Handle("/api/v1/echo", unary(server.handleEcho))
 
-	// Transaction streaming
-	mux.HandleFunc("/api/v1/transactions/stream", server.handleTransactionStream)
+	// Server resource usage, read before and after each benchmark run
+	api.HandleFunc("/api/v1/server-stats", server.handleServerStats)
+
+	// Database target of the benchmark endpoints, switched between runs
+	api.HandleFunc("/api/v1/admin/db-target", server.handleDBTarget)
+
+	// Graceful restart during a run, to measure how clients recover
+	api.HandleFunc("/api/v1/admin/restart", server.handleRestart)
+
+	// pprof profiles captured during runs (run --profile-cpu, --profile-mem)
+	api.HandleFunc("/api/v1/admin/profile", server.handleProfile)
+
+	// Runs launched from the dashboard
+	api.HandleFunc("/api/v1/runs/launch", server.handleLaunches)
+	api.HandleFunc("/api/v1/runs/launch/", server.handleLaunch)
+
+	// Load generation agents for distributed and launched runs
+	api.HandleFunc("/api/v1/agents", server.handleAgents)
+	api.HandleFunc("/api/v1/agents/", server.handleAgent)
+
+	// Registered target environments, selected by run --environment
+	api.HandleFunc("/api/v1/environments", server.handleEnvironments)
+	api.HandleFunc("/api/v1/environments/", server.handleEnvironment)
+
+	// Benchmark results
+	api.HandleFunc("/api/v1/results", server.handleResults)
+	api.HandleFunc("/api/v1/results/", server.handleRun)
+	api.HandleFunc("/api/v1/trends", server.handleTrends)
+
+	// Operational events overlaid on the trends
+	api.HandleFunc("/api/v1/events", server.handleEvents)
+
+	// Experiments: curated sets of related runs
+	api.HandleFunc("/api/v1/experiments", server.handleExperiments)
+	api.HandleFunc("/api/v1/experiments/", server.handleExperiment)
+
+	// Suites: runs executed together by benchmark run --config
+	api.HandleFunc("/api/v1/suites", server.handleSuites)
+	api.HandleFunc("/api/v1/suites/", server.handleSuite)
+
+	// JSON API responses are compressed when the client sends Accept-Encoding.
+	// Connect negotiates its own compression, so it is mounted outside.
+	mux.Handle("/api/v1/", compression.Handler(api))
 
 	// Health check
 	mux.HandleFunc("/health", server.handleHealth)
 
-	// Benchmark results
-	mux.HandleFunc("/api/v1/results", server.handleResults)
+	// pprof endpoints for go tool pprof
+	profiling.RegisterDebug(mux)
+
+	// Request metrics of the middleware
+	if metrics := chain.Metrics(); metrics != nil {
+		mux.Handle("/metrics", metrics.Handler())
+	}
+
+	// Connect protocol (binary protobuf and JSON codecs)
+	registerConnectHandlers(mux, balances, schedule, chain, authenticator, limiter, faults)
 
 	// Static files (dashboard)
 	staticFS, err := fs.Sub(web.Content, ".")
@@ -133,30 +433,56 @@ func main() {
 	}
 	mux.Handle("/", http.FileServer(http.FS(staticFS)))
 
-	// Create HTTP server
+	// Accept HTTP/1.1 and cleartext HTTP/2 (h2c), or HTTP/2 over TLS with
+	// mutual TLS. REST clients keep using HTTP/1.1; Connect clients can use
+	// HTTP/2 like gRPC does.
+	protocols := new(http.Protocols)
+	protocols.SetHTTP1(true)
+	if tlsCfg != nil {
+		protocols.SetHTTP2(true)
+	} else {
+		protocols.SetUnencryptedHTTP2(true)
+	}
+
+	// Serve until shut down, with a new HTTP server after each restart, as
+	// one cannot serve again once shut down
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
 	addr := fmt.Sprintf(":%d", *port)
-	httpServer := &http.Server{
-		Addr:         addr,
-		Handler:      mux,
-		ReadTimeout:  10 * time.Second,
-		WriteTimeout: 0, // Disabled for SSE
-		IdleTimeout:  120 * time.Second,
-	}
-
-	// Graceful shutdown
-	go func() {
-		sigCh := make(chan os.Signal, 1)
-		signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
-		<-sigCh
-		log.Println("Shutting down REST server...")
-		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-		defer cancel()
-		httpServer.Shutdown(ctx)
-	}()
-
-	log.Printf("REST server listening on %s", addr)
-	if err := httpServer.ListenAndServe(); err != http.ErrServerClosed {
-		log.Fatalf("Failed to serve: %v", err)
+	for {
+		httpServer := &http.Server{
+			Addr:         addr,
+			Handler:      mux,
+			ReadTimeout:  10 * time.Second,
+			WriteTimeout: 0, // Disabled for SSE
+			IdleTimeout:  120 * time.Second,
+			Protocols:    protocols,
+			TLSConfig:    tlsCfg,
+		}
+		serve := httpServer.ListenAndServe
+		if tlsCfg != nil {
+			serve = func() error { return httpServer.ListenAndServeTLS("", "") }
+		}
+		serveErr := make(chan error, 1)
+		go func() { serveErr <- serve() }()
+		log.Printf("REST server listening on %s", addr)
+
+		select {
+		case err := <-serveErr:
+			log.Fatalf("Failed to serve: %v", err)
+		case <-sigCh:
+			log.Println("Shutting down REST server...")
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			httpServer.Shutdown(ctx)
+			cancel()
+			return
+		case downtime := <-restarts.C():
+			log.Printf("Restarting REST server, down for %s after draining", downtime)
+			restart.Drain(restart.DrainTimeout,
+				func() { httpServer.Shutdown(context.Background()) },
+				func() { httpServer.Close() })
+			time.Sleep(downtime)
+		}
 	}
 }
 
@@ -176,12 +502,21 @@ func (s *Server) handleAccountBalance(w http.ResponseWriter, r *http.Request) {
 	}
 	accountID := parts[0]
 
-	account, err := s.db.GetBalance(r.Context(), accountID)
+	account, err := s.dataset.GetBalance(r.Context(), accountID)
 	if err != nil {
 		writeError(w, http.StatusNotFound, fmt.Sprintf("Account not found: %v", err))
 		return
 	}
 
+	if wantsMessage(r) {
+		writeMessage(w, r, http.StatusOK, &protos.BalanceResponse{
+			AccountId:      account.AccountID,
+			BalanceTinybar: account.Balance,
+			Timestamp:      account.UpdatedAt.Format(time.RFC3339),
+		})
+		return
+	}
+
 	resp := BalanceResponse{
 		Account:   account.AccountID,
 		Balance:   account.Balance,
@@ -205,12 +540,25 @@ func (s *Server) handleBatchBalances(w http.ResponseWriter, r *http.Request) {
 	}
 
 	accountIDs := strings.Split(idsParam, ",")
-	accounts, err := s.db.GetBalances(r.Context(), accountIDs)
+	accounts, err := s.dataset.GetBalances(r.Context(), accountIDs)
 	if err != nil {
 		writeError(w, http.StatusInternalServerError, fmt.Sprintf("Failed to get balances: %v", err))
 		return
 	}
 
+	if wantsMessage(r) {
+		msg := &protos.BatchBalanceResponse{Balances: make([]*protos.BalanceResponse, len(accounts))}
+		for i, acc := range accounts {
+			msg.Balances[i] = &protos.BalanceResponse{
+				AccountId:      acc.AccountID,
+				BalanceTinybar: acc.Balance,
+				Timestamp:      acc.UpdatedAt.Format(time.RFC3339),
+			}
+		}
+		writeMessage(w, r, http.StatusOK, msg)
+		return
+	}
+
 	balances := make([]BalanceResponse, len(accounts))
 	for i, acc := range accounts {
 		balances[i] = BalanceResponse{
@@ -223,15 +571,33 @@ func (s *Server) handleBatchBalances(w http.ResponseWriter, r *http.Request) {
 	writeJSON(w, http.StatusOK, BatchBalanceResponse{Balances: balances})
 }
 
-// handleTransactionStream handles GET /api/v1/transactions/stream (SSE)
+// handleTransactionStream handles GET /api/v1/transactions/stream (SSE).
+// Clients accepting protobuf get length-delimited Transaction messages
+// instead, with the number sent in a Messages-Sent trailer, and clients
+// asking for parity get events of Transaction messages in protojson.
+// Clients accepting NDJSON or JSON get the events framed that way instead
+// of as SSE (see restapi.StreamFormats). SSE events carry
+// the transaction ID as their event ID, so a reconnecting client resumes a
+// replay stream after the last one it received with the Last-Event-ID
+// header, or the after parameter.
 func (s *Server) handleTransactionStream(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
 		writeError(w, http.StatusMethodNotAllowed, "Method not allowed")
 		return
 	}
 
-	// Set SSE headers
-	w.Header().Set("Content-Type", "text/event-stream")
+	// Set SSE, NDJSON, JSON array or protobuf stream headers
+	binary, parity := wantsProtobuf(r), wantsParity(r)
+	format := streamFormat(r)
+	if binary {
+		w.Header().Set("Content-Type", protobufContentType)
+		w.Header().Set("Trailer", messagesSentTrailer)
+	} else {
+		w.Header().Set("Content-Type", restapi.StreamContentType(format))
+		if format != restapi.StreamSSE {
+			w.Header().Set("Trailer", messagesSentTrailer)
+		}
+	}
 	w.Header().Set("Cache-Control", "no-cache")
 	w.Header().Set("Connection", "keep-alive")
 	w.Header().Set("Access-Control-Allow-Origin", "*")
@@ -260,21 +626,46 @@ func (s *Server) handleTransactionStream(w http.ResponseWriter, r *http.Request)
 		fmt.Sscanf(rl, "%d", &rateLimit)
 	}
 
+	live := r.URL.Query().Get("live") == "true"
+
+	after := r.URL.Query().Get("after")
+	if after == "" {
+		after = r.Header.Get("Last-Event-ID")
+	}
+
 	opts := db.StreamTransactionsOptions{
 		Since:         since,
 		FilterAccount: filterAccount,
+		After:         after,
+		Live:          live,
 	}
 
 	ctx := r.Context()
-	txCh, errCh := s.db.StreamTransactions(ctx, opts)
+	txCh, errCh := s.dataset.StreamTransactions(ctx, opts)
 
-	// Rate limiting
+	// Rate limiting; a requested rate takes precedence over the pacing
+	// schedule. Live streams deliver transactions as they are submitted,
+	// and send their headers once subscribed rather than with the first.
 	var ticker *time.Ticker
-	if rateLimit > 0 {
+	var pacer *timing.Pacer
+	timestampLayout := time.RFC3339
+	if live {
+		timestampLayout = time.RFC3339Nano
+		flusher.Flush()
+	} else if rateLimit > 0 {
 		ticker = time.NewTicker(time.Second / time.Duration(rateLimit))
 		defer ticker.Stop()
+	} else if s.schedule != nil {
+		pacer = s.schedule.NewPacer()
+	}
+
+	// A JSON array is opened right away, so that a stream without
+	// transactions is an empty array
+	if !binary && format == restapi.StreamChunkedArray {
+		fmt.Fprint(w, "[")
 	}
 
+	sent := 0
 	for tx := range txCh {
 		// Apply rate limiting if configured
 		if ticker != nil {
@@ -283,35 +674,160 @@ func (s *Server) handleTransactionStream(w http.ResponseWriter, r *http.Request)
 			case <-ctx.Done():
 				return
 			}
+		} else if pacer != nil {
+			if err := pacer.Wait(ctx); err != nil {
+				return
+			}
 		}
 
-		event := TransactionEvent{
-			TxID:      tx.TxID,
-			From:      tx.FromAccount,
-			To:        tx.ToAccount,
-			Amount:    tx.Amount,
-			Type:      tx.TxType,
-			Timestamp: tx.Timestamp.Format(time.RFC3339),
-		}
+		// Stamped after pacing, so delivery latency covers only the send
+		sentAt := time.Now().UnixNano()
+		if binary {
+			if _, err := protodelim.MarshalTo(w, &protos.Transaction{
+				TxId:           tx.TxID,
+				FromAccount:    tx.FromAccount,
+				ToAccount:      tx.ToAccount,
+				AmountTinybar:  tx.Amount,
+				TxType:         tx.TxType,
+				Timestamp:      tx.Timestamp.Format(timestampLayout),
+				SentAtUnixNano: sentAt,
+			}); err != nil {
+				return
+			}
+		} else {
+			var data []byte
+			var err error
+			if parity {
+				data, err = marshalParity(&protos.Transaction{
+					TxId:           tx.TxID,
+					FromAccount:    tx.FromAccount,
+					ToAccount:      tx.ToAccount,
+					AmountTinybar:  tx.Amount,
+					TxType:         tx.TxType,
+					Timestamp:      tx.Timestamp.Format(timestampLayout),
+					SentAtUnixNano: sentAt,
+				})
+			} else {
+				data, err = jsonCodec.Marshal(TransactionEvent{
+					TxID:      tx.TxID,
+					From:      tx.FromAccount,
+					To:        tx.ToAccount,
+					Amount:    tx.Amount,
+					Type:      tx.TxType,
+					Timestamp: tx.Timestamp.Format(timestampLayout),
+					SentAt:    sentAt,
+				})
+			}
+			if err != nil {
+				continue
+			}
 
-		data, err := json.Marshal(event)
-		if err != nil {
-			continue
+			switch {
+			case format == restapi.StreamNDJSON:
+				fmt.Fprintf(w, "%s\n", data)
+			case format == restapi.StreamChunkedArray && sent > 0:
+				fmt.Fprintf(w, ",\n%s", data)
+			case format == restapi.StreamChunkedArray:
+				w.Write(data)
+			default:
+				fmt.Fprintf(w, "event: transaction\nid: %s\ndata: %s\n\n", tx.TxID, data)
+			}
 		}
-
-		fmt.Fprintf(w, "event: transaction\ndata: %s\n\n", data)
 		flusher.Flush()
+		sent++
 	}
 
-	// Check for errors
+	// Check for errors. A protobuf, NDJSON or JSON array stream that failed
+	// ends without its trailer.
 	select {
 	case err := <-errCh:
 		if err != nil {
-			fmt.Fprintf(w, "event: error\ndata: %s\n\n", err.Error())
-			flusher.Flush()
+			if !binary && format == restapi.StreamSSE {
+				fmt.Fprintf(w, "event: error\ndata: %s\n\n", err.Error())
+				flusher.Flush()
+			}
+			return
 		}
 	default:
 	}
+
+	// Announce the end of the stream with the number of transactions sent,
+	// so clients can detect transactions lost in transit
+	if ctx.Err() == nil {
+		if binary || format != restapi.StreamSSE {
+			if format == restapi.StreamChunkedArray && !binary {
+				fmt.Fprint(w, "]\n")
+			}
+			w.Header().Set(messagesSentTrailer, strconv.Itoa(sent))
+			return
+		}
+		fmt.Fprintf(w, "event: done\ndata: {\"sent\":%d}\n\n", sent)
+		flusher.Flush()
+	}
+}
+
+// streamFormat returns the framing of a JSON transaction stream: the one
+// whose media type the request's Accept header names, or the server's
+// --rest-stream-format.
+func streamFormat(r *http.Request) string {
+	var format string
+	if accepts(r, func(mediaType string, params map[string]string) bool {
+		format = restapi.StreamFormatOf(mediaType)
+		return format != ""
+	}) {
+		return format
+	}
+	return *restStreamFormat
+}
+
+// handleSubmitTransaction handles POST /api/v1/transactions
+func (s *Server) handleSubmitTransaction(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	var req SubmitTransactionRequest
+	if err := decodeSubmitTransaction(w, r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("Invalid request body: %v", err))
+		return
+	}
+
+	tx := &db.Transaction{
+		FromAccount: req.From,
+		ToAccount:   req.To,
+		Amount:      req.Amount,
+		TxType:      req.Type,
+	}
+	if err := tx.Validate(); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := s.dataset.InsertTransaction(r.Context(), tx); err != nil {
+		writeError(w, http.StatusInternalServerError, fmt.Sprintf("Failed to submit transaction: %v", err))
+		return
+	}
+
+	if wantsMessage(r) {
+		writeMessage(w, r, http.StatusCreated, &protos.Transaction{
+			TxId:          tx.TxID,
+			FromAccount:   tx.FromAccount,
+			ToAccount:     tx.ToAccount,
+			AmountTinybar: tx.Amount,
+			TxType:        tx.TxType,
+			Timestamp:     tx.Timestamp.Format(time.RFC3339),
+		})
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, TransactionEvent{
+		TxID:      tx.TxID,
+		From:      tx.FromAccount,
+		To:        tx.ToAccount,
+		Amount:    tx.Amount,
+		Type:      tx.TxType,
+		Timestamp: tx.Timestamp.Format(time.RFC3339),
+	})
 }
 
 // handleHealth handles GET /health
@@ -333,7 +849,60 @@ func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
 	writeJSON(w, http.StatusOK, map[string]string{"status": "healthy"})
 }
 
-// handleResults handles GET /api/v1/results?scenario=...&protocol=...&client=...&run_id=...
+// handleEcho handles GET /api/v1/echo?size=1024
+func (s *Server) handleEcho(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	size, err := strconv.Atoi(r.URL.Query().Get("size"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "size parameter required")
+		return
+	}
+
+	b, err := payload.Bytes(size)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if wantsMessage(r) {
+		writeMessage(w, r, http.StatusOK, &protos.EchoResponse{Payload: b})
+		return
+	}
+	writeJSON(w, http.StatusOK, EchoResponse{Payload: b})
+}
+
+// handleServerStats handles GET /api/v1/server-stats
+func (s *Server) handleServerStats(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	cpu, err := serverstats.CPUSeconds()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	stats := s.balances.Stats()
+	pools := db.AllPoolStats()
+	writeJSON(w, http.StatusOK, ServerStatsResponse{
+		CPUSeconds:          cpu,
+		CacheHits:           stats.Hits,
+		CacheMisses:         stats.Misses,
+		PoolAcquireWaits:    pools.EmptyAcquires,
+		PoolAcquireFailures: pools.CanceledAcquires,
+	})
+}
+
+// handleResults handles GET /api/v1/results?scenario=...&protocol=...&client=...&run_id=...&group_by=...
+// Results are paged with limit and offset or cursor, sorted by sort
+// (created_at, p99, throughput) and order (desc, asc), and restricted to
+// runs created in [since, until).
 func (s *Server) handleResults(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
 		writeError(w, http.StatusMethodNotAllowed, "Method not allowed")
@@ -346,6 +915,10 @@ func (s *Server) handleResults(w http.ResponseWriter, r *http.Request) {
 		Protocol: r.URL.Query().Get("protocol"),
 		Client:   r.URL.Query().Get("client"),
 		Limit:    100,
+
+		ComparisonID: r.URL.Query().Get("comparison_id"),
+		SuiteID:      r.URL.Query().Get("suite_id"),
+		Environment:  r.URL.Query().Get("environment"),
 	}
 
 	if runIDStr := r.URL.Query().Get("run_id"); runIDStr != "" {
@@ -360,12 +933,149 @@ func (s *Server) handleResults(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	// Only runs with every label=key=value given
+	for _, label := range r.URL.Query()["label"] {
+		key, value, ok := strings.Cut(label, "=")
+		if !ok || key == "" {
+			writeError(w, http.StatusBadRequest, fmt.Sprintf("Invalid label: %s (must be key=value)", label))
+			return
+		}
+		if filter.Labels == nil {
+			filter.Labels = make(map[string]string)
+		}
+		filter.Labels[key] = value
+	}
+
+	if err := parseResultsPage(r.URL.Query(), &filter); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	// Only the runs of an experiment, all of them unless limited
+	if expStr := r.URL.Query().Get("experiment"); expStr != "" {
+		id, err := strconv.ParseInt(expStr, 10, 64)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Sprintf("Invalid experiment ID: %s", expStr))
+			return
+		}
+		e, err := s.db.GetExperiment(r.Context(), id)
+		if err != nil {
+			writeExperimentError(w, err)
+			return
+		}
+		filter.RunIDs = e.RunIDs
+		if len(filter.RunIDs) == 0 {
+			filter.RunIDs = []int64{0} // matches no run
+		}
+		if r.URL.Query().Get("limit") == "" {
+			filter.Limit = len(filter.RunIDs)
+		}
+	}
+
+	switch groupBy := r.URL.Query().Get("group_by"); groupBy {
+	case "":
+	case "concurrency":
+		s.writeConcurrencyGroups(w, r, filter)
+		return
+	default:
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("Invalid group_by: %s (must be concurrency)", groupBy))
+		return
+	}
+
 	stats, err := s.db.GetFilteredStats(r.Context(), filter)
 	if err != nil {
 		writeError(w, http.StatusInternalServerError, fmt.Sprintf("Failed to get results: %v", err))
 		return
 	}
 
+	results, err := s.benchmarkResults(r.Context(), stats)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, fmt.Sprintf("Failed to get results: %v", err))
+		return
+	}
+
+	resp := ResultsResponse{
+		Results: results,
+		Count:   len(results),
+	}
+	if filter.Limit > 0 && len(stats) == filter.Limit {
+		resp.NextCursor = filter.CursorOf(stats[len(stats)-1]).String()
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// parseResultsPage sets the paging, sorting and date range parameters of
+// /api/v1/results on filter. Dates are RFC 3339 timestamps or YYYY-MM-DD
+// days in UTC.
+func parseResultsPage(q url.Values, filter *db.StatsFilter) error {
+	if s := q.Get("offset"); s != "" {
+		offset, err := strconv.Atoi(s)
+		if err != nil || offset < 0 {
+			return fmt.Errorf("invalid offset: %s", s)
+		}
+		filter.Offset = offset
+	}
+	if s := q.Get("cursor"); s != "" {
+		c, err := db.ParseCursor(s)
+		if err != nil {
+			return err
+		}
+		filter.After = c
+	}
+
+	filter.Sort = q.Get("sort")
+	switch order := q.Get("order"); order {
+	case "", "desc":
+	case "asc":
+		filter.Ascending = true
+	default:
+		return fmt.Errorf("invalid order: %s (must be asc or desc)", order)
+	}
+
+	for _, p := range []struct {
+		name string
+		t    *time.Time
+	}{{"since", &filter.Since}, {"until", &filter.Until}} {
+		s := q.Get(p.name)
+		if s == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, s)
+		if err != nil {
+			t, err = time.Parse(time.DateOnly, s)
+		}
+		if err != nil {
+			return fmt.Errorf("invalid %s: %s (must be RFC 3339 or YYYY-MM-DD)", p.name, s)
+		}
+		*p.t = t
+	}
+	return filter.Validate()
+}
+
+// benchmarkResults converts stats to the API response format, adding
+// throughput and the error breakdown of runs with failures.
+func (s *Server) benchmarkResults(ctx context.Context, stats []*db.BenchmarkStats) ([]BenchmarkResult, error) {
+	// Break down the failures of runs that had any by error type
+	var failedRuns []int64
+	for _, stat := range stats {
+		if stat.Successful < stat.TotalSamples {
+			failedRuns = append(failedRuns, stat.RunID)
+		}
+	}
+	errorCounts := make(map[int64]map[string]int64)
+	if len(failedRuns) > 0 {
+		counts, err := s.db.GetErrorCounts(ctx, failedRuns)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get error counts: %w", err)
+		}
+		for _, c := range counts {
+			if errorCounts[c.RunID] == nil {
+				errorCounts[c.RunID] = make(map[string]int64)
+			}
+			errorCounts[c.RunID][c.ErrorType] = c.Count
+		}
+	}
+
 	// Convert to API response format with throughput calculation
 	results := make([]BenchmarkResult, len(stats))
 	for i, stat := range stats {
@@ -393,21 +1103,421 @@ func (s *Server) handleResults(w http.ResponseWriter, r *http.Request) {
 			CPUUsageAvg:  stat.CPUUsageAvg,
 			MemoryMBAvg:  stat.MemoryMBAvg,
 			MemoryMBPeak: stat.MemoryMBPeak,
+
+			NetBytesSent:  stat.NetBytesSent,
+			NetBytesRecv:  stat.NetBytesRecv,
+			NetInterfaces: stat.NetInterfaces,
+
+			LatencyMetric: stat.LatencyMetric,
+			ComparisonID:  stat.ComparisonID,
+			SuiteID:       stat.SuiteID,
+			PayloadSize:   stat.PayloadSize,
+			WriteRatio:    stat.WriteRatio,
+			OperationMix:  stat.OperationMix,
+			AccountChurn:  stat.AccountChurn,
+			DBTarget:      stat.DBTarget,
+			Compression:   stat.Compression,
+			Connection:    stat.Connection,
+			ServerQoS:     stat.ServerQoS,
+			ServerPools:   stat.ServerPools,
+			ServerQueryTx: stat.ServerQueryTx,
+			ServerFaults:  stat.ServerFaults,
+			ServerCache:   stat.ServerCache,
+			JSONEncoder:   stat.JSONEncoder,
+
+			Cost:           stat.Cost,
+			CostPerMillion: stat.CostPerMillion,
+			CostModel:      stat.CostModel,
+
+			ClientCPUSeconds: stat.ClientCPUSeconds,
+			ServerCPUSeconds: stat.ServerCPUSeconds,
+			ClientPerCPUSec:  stat.ClientPerCPUSec,
+			ServerPerCPUSec:  stat.ServerPerCPUSec,
+			CacheHitRate:     stat.CacheHitRate,
+			Workers:          stat.Workers,
+			StreamsPerWorker: stat.StreamsPerWorker,
+			ProcessCostUs:    stat.ProcessCostUs,
+
+			LoadProfile: stat.LoadProfile,
+			DatasetHash: stat.DatasetHash,
+
+			DatasetFingerprint: stat.DatasetFingerprint,
+			DatasetSize:        stat.DatasetSize,
+			StreamShortfall:    stat.StreamShortfall,
+			StreamsEstablished: stat.StreamsEstablished,
+
+			MeasureWindow:  stat.MeasureWindow,
+			MeasuredWindow: stat.MeasuredWindow,
+			FullThroughput: stat.FullThroughput,
+			FullP50Ms:      stat.FullP50Ms,
+			FullP99Ms:      stat.FullP99Ms,
+
+			GCPauses:       stat.GCPauses,
+			GCPauseMs:      stat.GCPauseMs,
+			GCTailFraction: stat.GCTailFraction,
+
+			LiveStream: stat.LiveStream,
+
+			ChaosDisconnectMs: stat.ChaosDisconnectMs,
+			Reconnects:        stat.Reconnects,
+			ReconnectP50Ms:    stat.ReconnectP50Ms,
+			ReconnectP99Ms:    stat.ReconnectP99Ms,
+
+			ServerMiddleware: stat.ServerMiddleware,
+			Auth:             stat.Auth,
+			ServerRateLimit:  stat.ServerRateLimit,
+			Backoff:          stat.Backoff,
+
+			ChaosRestart:      stat.ChaosRestart,
+			RestartErrors:     stat.RestartErrors,
+			RestartRecoveryMs: stat.RestartRecoveryMs,
+
+			Agents:         stat.Agents,
+			ConcurrentWith: stat.ConcurrentWith,
+
+			ClientCommit: stat.ClientCommit,
+			ServerCommit: stat.ServerCommit,
+			GoVersion:    stat.GoVersion,
+			GOMAXPROCS:   stat.GOMAXPROCS,
+			Kernel:       stat.Kernel,
+			Hostname:     stat.Hostname,
+			Tags:         stat.Tags,
+			Environment:  stat.Environment,
+
+			GoroutinesPeak:  stat.GoroutinesPeak,
+			HeapObjectsPeak: stat.HeapObjectsPeak,
+			HeapMBPeak:      stat.HeapMBPeak,
+			GCCycles:        stat.GCCycles,
+
+			CacheBust: stat.CacheBust,
+
+			InjectedHeaders:     stat.InjectedHeaders,
+			InjectedHeaderBytes: stat.InjectedHeaderBytes,
+
+			WireBytesSent:    stat.WireBytesSent,
+			WireBytesRecv:    stat.WireBytesRecv,
+			PayloadBytesSent: stat.PayloadBytesSent,
+			PayloadBytesRecv: stat.PayloadBytesRecv,
+
+			MessageP50Bytes:  stat.MessageP50Bytes,
+			MessageP99Bytes:  stat.MessageP99Bytes,
+			MessageMaxBytes:  stat.MessageMaxBytes,
+			RESTStreamFormat: stat.RESTStreamFormat,
+			RunConfig:        stat.RunConfig,
+
+			Labels: stat.Labels,
+			Notes:  stat.Notes,
+
+			ServerLogErrors:   stat.ServerLogErrors,
+			ServerLogWarnings: stat.ServerLogWarnings,
+			ServerLogLines:    stat.ServerLogLines,
+
+			Verified:     stat.Verified,
+			Verification: stat.Verification,
+
+			BaselineRunID:      stat.BaselineRunID,
+			P50DeltaPct:        stat.P50DeltaPct,
+			P99DeltaPct:        stat.P99DeltaPct,
+			ThroughputDeltaPct: stat.ThroughputDeltaPct,
+
+			P50Staleness: stat.P50Staleness,
+			P90Staleness: stat.P90Staleness,
+			P99Staleness: stat.P99Staleness,
+
+			P50DB:      stat.P50DB,
+			P99DB:      stat.P99DB,
+			P50Network: stat.P50Network,
+			P99Network: stat.P99Network,
+
+			Errors: errorCounts[stat.RunID],
 		}
 	}
+	return results, nil
+}
 
-	writeJSON(w, http.StatusOK, ResultsResponse{
-		Results: results,
-		Count:   len(results),
+// writeConcurrencyGroups responds with the runs matching filter averaged per
+// configuration and concurrency level, for throughput and latency curves.
+func (s *Server) writeConcurrencyGroups(w http.ResponseWriter, r *http.Request, filter db.StatsFilter) {
+	stats, err := s.db.GetConcurrencyStats(r.Context(), filter)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, fmt.Sprintf("Failed to get results: %v", err))
+		return
+	}
+
+	groups := make([]ConcurrencyGroup, len(stats))
+	for i, g := range stats {
+		groups[i] = ConcurrencyGroup{
+			Scenario:    g.Scenario,
+			Protocol:    g.Protocol,
+			Client:      g.Client,
+			Concurrency: g.Concurrency,
+			Runs:        g.Runs,
+			Throughput:  g.Throughput,
+			P50Latency:  g.P50Latency,
+			P99Latency:  g.P99Latency,
+		}
+	}
+
+	writeJSON(w, http.StatusOK, ConcurrencyResponse{
+		Groups: groups,
+		Count:  len(groups),
 	})
 }
 
+// writeRunTimeseries responds with the per-second timeseries of a run.
+func (s *Server) writeRunTimeseries(w http.ResponseWriter, r *http.Request, runID int64) {
+	points, err := s.db.GetTimeseries(r.Context(), runID)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, fmt.Sprintf("Failed to get timeseries: %v", err))
+		return
+	}
+
+	resp := TimeseriesResponse{RunID: runID, Points: make([]TimeseriesPoint, len(points))}
+	for i, p := range points {
+		resp.Points[i] = TimeseriesPoint{
+			ElapsedSec: p.ElapsedSec,
+			Requests:   p.Requests,
+			Errors:     p.Errors,
+			P50Latency: p.P50LatencyMs,
+			P99Latency: p.P99LatencyMs,
+
+			Goroutines:  p.Goroutines,
+			HeapObjects: p.HeapObjects,
+			HeapMB:      p.HeapMB,
+			GCCycles:    p.GCCycles,
+			GCPause:     p.GCPauseMs,
+		}
+	}
+
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// handleTrends handles GET /api/v1/trends: the history of a metric across
+// runs, downsampled into time buckets per configuration.
+func (s *Server) handleTrends(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	query := r.URL.Query()
+	filter := db.StatsFilter{
+		Scenario: query.Get("scenario"),
+		Protocol: query.Get("protocol"),
+		Client:   query.Get("client"),
+	}
+
+	q := db.TrendQuery{Metric: defaultTrendMetric, Points: defaultTrendPoints}
+	if metric := query.Get("metric"); metric != "" {
+		q.Metric = metric
+	}
+	windowStr := query.Get("window")
+	if windowStr == "" {
+		windowStr = defaultTrendWindow
+	}
+	window, err := parseWindow(windowStr)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("Invalid window: %s", windowStr))
+		return
+	}
+	q.Window = window
+	if pointsStr := query.Get("points"); pointsStr != "" {
+		points, err := strconv.Atoi(pointsStr)
+		if err != nil || points < 1 || points > maxTrendPoints {
+			writeError(w, http.StatusBadRequest, fmt.Sprintf("Invalid points: %s (must be 1 to %d)", pointsStr, maxTrendPoints))
+			return
+		}
+		q.Points = points
+	}
+	if err := q.Validate(); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	points, err := s.db.GetTrends(r.Context(), filter, q)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, fmt.Sprintf("Failed to get trends: %v", err))
+		return
+	}
+
+	// Points arrive ordered by configuration, then bucket
+	resp := TrendsResponse{
+		Metric:    q.Metric,
+		Window:    windowStr,
+		BucketSec: int64(q.Bucket().Seconds()),
+		Series:    []TrendSeries{},
+	}
+	for _, p := range points {
+		n := len(resp.Series)
+		if n == 0 || resp.Series[n-1].Scenario != p.Scenario || resp.Series[n-1].Protocol != p.Protocol || resp.Series[n-1].Client != p.Client {
+			resp.Series = append(resp.Series, TrendSeries{Scenario: p.Scenario, Protocol: p.Protocol, Client: p.Client})
+			n++
+		}
+		resp.Series[n-1].Points = append(resp.Series[n-1].Points, TrendPoint{Time: p.Bucket, Runs: p.Runs, Value: p.Value})
+	}
+
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// parseWindow parses a trend window: a number of days ("90d") or weeks
+// ("4w"), or a Go duration ("12h").
+func parseWindow(s string) (time.Duration, error) {
+	for suffix, unit := range map[string]time.Duration{"d": 24 * time.Hour, "w": 7 * 24 * time.Hour} {
+		if n, ok := strings.CutSuffix(s, suffix); ok {
+			count, err := strconv.Atoi(n)
+			if err != nil || count < 1 {
+				return 0, fmt.Errorf("invalid window: %s", s)
+			}
+			return time.Duration(count) * unit, nil
+		}
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("invalid window: %s", s)
+	}
+	return d, nil
+}
+
 func writeJSON(w http.ResponseWriter, status int, data interface{}) {
+	body, err := jsonCodec.Marshal(data)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to encode response: %v", err), http.StatusInternalServerError)
+		return
+	}
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(status)
-	json.NewEncoder(w).Encode(data)
+	w.Write(append(body, '\n'))
+}
+
+// wantsProtobuf reports whether the request's Accept header asks for
+// protobuf. Quality values are not weighed.
+func wantsProtobuf(r *http.Request) bool {
+	return accepts(r, func(mediaType string, params map[string]string) bool {
+		return mediaType == protobufContentType
+	})
+}
+
+// wantsParity reports whether the request's Accept header asks for parity
+// bodies (see restapi.ShapeParam).
+func wantsParity(r *http.Request) bool {
+	return accepts(r, func(mediaType string, params map[string]string) bool {
+		return params[restapi.ShapeParam] == restapi.ParityShape
+	})
+}
+
+// wantsMessage reports whether the response is to be encoded from its
+// protobuf message, in binary or as parity JSON.
+func wantsMessage(r *http.Request) bool {
+	return wantsProtobuf(r) || wantsParity(r)
+}
+
+// accepts reports whether match holds for a media type of the request's
+// Accept header.
+func accepts(r *http.Request, match func(mediaType string, params map[string]string) bool) bool {
+	for _, accept := range r.Header.Values("Accept") {
+		for part := range strings.SplitSeq(accept, ",") {
+			if mediaType, params, err := mime.ParseMediaType(part); err == nil && match(mediaType, params) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// decodeSubmitTransaction decodes a JSON or, by its Content-Type, protobuf
+// or parity transaction submission into req.
+func decodeSubmitTransaction(w http.ResponseWriter, r *http.Request, req *SubmitTransactionRequest) error {
+	body := http.MaxBytesReader(w, r.Body, 1<<20)
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return err
+	}
+
+	var msg protos.SubmitTransactionRequest
+	mediaType, params, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	switch {
+	case mediaType == protobufContentType:
+		err = proto.Unmarshal(data, &msg)
+	case params[restapi.ShapeParam] == restapi.ParityShape:
+		err = protojson.Unmarshal(data, &msg)
+	default:
+		return jsonCodec.Unmarshal(data, req)
+	}
+	if err != nil {
+		return err
+	}
+	*req = SubmitTransactionRequest{From: msg.FromAccount, To: msg.ToAccount, Amount: msg.AmountTinybar, Type: msg.TxType}
+	return nil
+}
+
+// writeMessage writes msg in binary protobuf if the request accepts it, and
+// otherwise as parity JSON.
+func writeMessage(w http.ResponseWriter, r *http.Request, status int, msg proto.Message) {
+	if wantsProtobuf(r) {
+		writeProtobuf(w, status, msg)
+		return
+	}
+
+	data, err := marshalParity(msg)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, fmt.Sprintf("Failed to encode response: %v", err))
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	w.Write(append(data, '\n'))
+}
+
+// marshalParity encodes msg as a parity body with the encoder selected by
+// -parity-marshal: protojson, or the JSON encoder on its hand-written
+// restapi Parity type.
+func marshalParity(msg proto.Message) ([]byte, error) {
+	if *parityMarshal == restapi.ParityProtoJSON {
+		return protojson.Marshal(msg)
+	}
+	switch m := msg.(type) {
+	case *protos.BalanceResponse:
+		return jsonCodec.Marshal(parityBalance(m))
+	case *protos.BatchBalanceResponse:
+		resp := restapi.ParityBatchBalanceResponse{Balances: make([]restapi.ParityBalanceResponse, len(m.Balances))}
+		for i, b := range m.Balances {
+			resp.Balances[i] = parityBalance(b)
+		}
+		return jsonCodec.Marshal(resp)
+	case *protos.Transaction:
+		return jsonCodec.Marshal(restapi.ParityTransaction{
+			TxID:           m.TxId,
+			FromAccount:    m.FromAccount,
+			ToAccount:      m.ToAccount,
+			AmountTinybar:  m.AmountTinybar,
+			TxType:         m.TxType,
+			Timestamp:      m.Timestamp,
+			SentAtUnixNano: m.SentAtUnixNano,
+		})
+	case *protos.EchoResponse:
+		return jsonCodec.Marshal(restapi.ParityEchoResponse{Payload: m.Payload})
+	}
+	return protojson.Marshal(msg)
+}
+
+// parityBalance returns the hand-written parity type of a balance.
+func parityBalance(m *protos.BalanceResponse) restapi.ParityBalanceResponse {
+	return restapi.ParityBalanceResponse{AccountID: m.AccountId, BalanceTinybar: m.BalanceTinybar, Timestamp: m.Timestamp}
+}
+
+// writeProtobuf writes msg in the binary protobuf encoding.
+func writeProtobuf(w http.ResponseWriter, status int, msg proto.Message) {
+	data, err := proto.Marshal(msg)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, fmt.Sprintf("Failed to encode response: %v", err))
+		return
+	}
+	w.Header().Set("Content-Type", protobufContentType)
+	w.WriteHeader(status)
+	w.Write(data)
 }
 
+// writeError writes an error response. Errors are JSON in either encoding.
 func writeError(w http.ResponseWriter, status int, message string) {
 	writeJSON(w, status, ErrorResponse{Error: message})
 }
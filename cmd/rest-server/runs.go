@@ -0,0 +1,140 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/kaldun-tech/grpc-rest-benchmark/pkg/db"
+)
+
+// handleRun handles GET, PATCH (labels and notes) and DELETE
+// /api/v1/results/{run_id}, GET /api/v1/results/{run_id}/timeseries and
+// GET /api/v1/results/{run_id}/profiles[/{name}]
+func (s *Server) handleRun(w http.ResponseWriter, r *http.Request) {
+	path := strings.TrimPrefix(r.URL.Path, "/api/v1/results/")
+	parts := strings.Split(path, "/")
+	switch {
+	case len(parts) == 2 && (parts[1] == "timeseries" || parts[1] == "profiles"):
+	case len(parts) == 3 && parts[1] == "profiles" && parts[2] != "":
+	case len(parts) > 1:
+		writeError(w, http.StatusNotFound, "Not found")
+		return
+	}
+	runID, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("Invalid run ID: %s", parts[0]))
+		return
+	}
+
+	if len(parts) > 1 {
+		if r.Method != http.MethodGet {
+			writeError(w, http.StatusMethodNotAllowed, "Method not allowed")
+			return
+		}
+		switch {
+		case parts[1] == "timeseries":
+			s.writeRunTimeseries(w, r, runID)
+		case len(parts) == 2:
+			s.writeRunProfiles(w, r, runID)
+		default:
+			s.writeRunProfile(w, r, runID, parts[2])
+		}
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		s.writeRun(w, r, runID)
+
+	case http.MethodPatch:
+		var req RunMetadataRequest
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<20)).Decode(&req); err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Sprintf("Invalid request body: %v", err))
+			return
+		}
+		m := db.RunMetadata{Labels: req.Labels, Notes: req.Notes}
+		if err := m.Validate(); err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		if _, _, err := s.db.UpdateRunMetadata(r.Context(), runID, m); err != nil {
+			writeRunError(w, err)
+			return
+		}
+		s.writeRun(w, r, runID)
+
+	case http.MethodDelete:
+		if err := s.db.DeleteRun(r.Context(), runID); err != nil {
+			writeRunError(w, err)
+			return
+		}
+		log.Printf("Deleted run %d", runID)
+		w.WriteHeader(http.StatusNoContent)
+
+	default:
+		writeError(w, http.StatusMethodNotAllowed, "Method not allowed")
+	}
+}
+
+// writeRun responds with the result of a run.
+func (s *Server) writeRun(w http.ResponseWriter, r *http.Request, runID int64) {
+	stats, err := s.db.GetFilteredStats(r.Context(), db.StatsFilter{RunID: &runID, Limit: 1})
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, fmt.Sprintf("Failed to get result: %v", err))
+		return
+	}
+	if len(stats) == 0 {
+		writeError(w, http.StatusNotFound, "Run not found")
+		return
+	}
+	results, err := s.benchmarkResults(r.Context(), stats)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, fmt.Sprintf("Failed to get result: %v", err))
+		return
+	}
+	writeJSON(w, http.StatusOK, results[0])
+}
+
+func writeRunError(w http.ResponseWriter, err error) {
+	if errors.Is(err, db.ErrRunNotFound) {
+		writeError(w, http.StatusNotFound, "Run not found")
+		return
+	}
+	writeError(w, http.StatusInternalServerError, err.Error())
+}
+
+// writeRunProfiles responds with the profiles attached to a run.
+func (s *Server) writeRunProfiles(w http.ResponseWriter, r *http.Request, runID int64) {
+	profiles, err := s.db.ListProfiles(r.Context(), runID)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, fmt.Sprintf("Failed to list profiles: %v", err))
+		return
+	}
+	resp := ProfilesResponse{Profiles: make([]ProfileResponse, len(profiles)), Count: len(profiles)}
+	for i, p := range profiles {
+		resp.Profiles[i] = ProfileResponse{Name: p.Name, Size: p.Size, CreatedAt: p.CreatedAt}
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// writeRunProfile responds with a profile attached to a run, for go tool
+// pprof.
+func (s *Server) writeRunProfile(w http.ResponseWriter, r *http.Request, runID int64, name string) {
+	p, err := s.db.GetProfile(r.Context(), runID, name)
+	if errors.Is(err, db.ErrProfileNotFound) {
+		writeError(w, http.StatusNotFound, "Profile not found")
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	w.Header().Set("Content-Type", "application/octet-stream")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="run-%d-%s.pprof"`, runID, p.Name))
+	w.Write(p.Data)
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/kaldun-tech/grpc-rest-benchmark/pkg/db"
+)
+
+// SuiteResponse is the JSON representation of a suite. URL opens the
+// dashboard showing only its runs.
+type SuiteResponse struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Runs        int    `json:"runs"`
+	URL         string `json:"url"`
+	CreatedAt   string `json:"created_at"`
+}
+
+// SuitesResponse is the JSON response for the suite list.
+type SuitesResponse struct {
+	Suites []SuiteResponse `json:"suites"`
+	Count  int             `json:"count"`
+}
+
+// SuiteReport is a suite with the results of all its runs.
+type SuiteReport struct {
+	Suite   SuiteResponse     `json:"suite"`
+	Results []BenchmarkResult `json:"results"`
+	Count   int               `json:"count"`
+}
+
+func suiteResponse(s *db.Suite) SuiteResponse {
+	return SuiteResponse{
+		ID:          s.ID,
+		Name:        s.Name,
+		Description: s.Description,
+		Runs:        s.Runs,
+		URL:         "/?suite=" + url.QueryEscape(s.ID),
+		CreatedAt:   s.CreatedAt.Format(time.RFC3339),
+	}
+}
+
+// handleSuites handles GET /api/v1/suites
+func (s *Server) handleSuites(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+	suites, err := s.db.ListSuites(r.Context())
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, fmt.Sprintf("Failed to list suites: %v", err))
+		return
+	}
+	resp := SuitesResponse{Suites: make([]SuiteResponse, len(suites)), Count: len(suites)}
+	for i, suite := range suites {
+		resp.Suites[i] = suiteResponse(suite)
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// handleSuite handles GET /api/v1/suites/{id}: the suite and the results
+// of its runs in the order they ran.
+func (s *Server) handleSuite(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+	id := strings.TrimPrefix(r.URL.Path, "/api/v1/suites/")
+	if id == "" || strings.Contains(id, "/") {
+		writeError(w, http.StatusNotFound, "Not found")
+		return
+	}
+
+	suite, err := s.db.GetSuite(r.Context(), id)
+	if errors.Is(err, db.ErrSuiteNotFound) {
+		writeError(w, http.StatusNotFound, "Suite not found")
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	report := SuiteReport{Suite: suiteResponse(suite), Results: []BenchmarkResult{}}
+	if suite.Runs > 0 {
+		stats, err := s.db.GetFilteredStats(r.Context(), db.StatsFilter{SuiteID: id, Limit: suite.Runs})
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, fmt.Sprintf("Failed to get results: %v", err))
+			return
+		}
+		if report.Results, err = s.benchmarkResults(r.Context(), stats); err != nil {
+			writeError(w, http.StatusInternalServerError, fmt.Sprintf("Failed to get results: %v", err))
+			return
+		}
+		slices.Reverse(report.Results)
+	}
+	report.Count = len(report.Results)
+	writeJSON(w, http.StatusOK, report)
+}
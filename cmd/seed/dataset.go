@@ -0,0 +1,245 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kaldun-tech/grpc-rest-benchmark/pkg/db"
+)
+
+// Value distributions for balances and transaction amounts, all bounded by
+// the configured maximum.
+const (
+	DistUniform     = "uniform"     // every value in [0, max] equally likely
+	DistExponential = "exponential" // mean max/10, most values small
+	DistPareto      = "pareto"      // heavy tail: a few accounts hold most of the value
+)
+
+// Timestamp distributions over the transaction time span.
+const (
+	TimeUniform = "uniform" // constant rate
+	TimeDiurnal = "diurnal" // daily cycle peaking at 14:00 UTC, a fifth of the peak rate at night
+	TimeBursty  = "bursty"  // most transactions in short bursts over a light background
+)
+
+var (
+	validValueDists = []string{DistUniform, DistExponential, DistPareto}
+	validTimeDists  = []string{TimeUniform, TimeDiurnal, TimeBursty}
+)
+
+const (
+	firstAccountNum = 100000              // account IDs start at 0.0.100000, as in scripts/seed_data.sql
+	accountAgeSpan  = 30 * 24 * time.Hour // balances were last updated within this span
+
+	scaleTxPerAccount = 10 // transactions per account of a --dataset-scale preset, as in the default dataset
+
+	paretoAlpha    = 1.16 // the 80/20 rule
+	burstSpread    = 30 * time.Second
+	burstInterval  = 10 * time.Minute // one burst center per interval of the time span
+	burstFraction  = 0.9              // transactions in bursts, the rest are background
+	diurnalPeakUTC = 14
+)
+
+// seedConfig describes the dataset to generate.
+type seedConfig struct {
+	accounts     int
+	transactions int
+	seed         int64
+
+	balanceDist string
+	maxBalance  int64 // tinybar
+	amountDist  string
+	maxAmount   int64 // tinybar
+
+	timeDist string
+	timeSpan time.Duration
+	end      time.Time // newest possible transaction
+}
+
+// parseScale parses a dataset scale: an account count with an optional K or
+// M suffix, e.g. "10K" or "1M".
+func parseScale(s string) (int, error) {
+	mult := 1
+	num := strings.ToUpper(strings.TrimSpace(s))
+	switch {
+	case strings.HasSuffix(num, "K"):
+		mult, num = 1_000, strings.TrimSuffix(num, "K")
+	case strings.HasSuffix(num, "M"):
+		mult, num = 1_000_000, strings.TrimSuffix(num, "M")
+	}
+	n, err := strconv.Atoi(num)
+	if err != nil || n < 1 {
+		return 0, fmt.Errorf("invalid dataset scale: %q (e.g., 10K, 100K, 1M, 10M)", s)
+	}
+	return n * mult, nil
+}
+
+// applyScale sizes the dataset for a scale of n accounts. Transactions
+// follow at scaleTxPerAccount per account unless set explicitly.
+func (c *seedConfig) applyScale(n int, transactionsSet bool) {
+	c.accounts = n
+	if !transactionsSet {
+		c.transactions = n * scaleTxPerAccount
+	}
+}
+
+// validate checks the configuration for invalid values.
+func (c *seedConfig) validate() error {
+	if c.accounts < 1 {
+		return fmt.Errorf("accounts must be at least 1")
+	}
+	if c.transactions < 0 {
+		return fmt.Errorf("transactions must not be negative")
+	}
+	for _, d := range []struct{ name, value string }{{"balance", c.balanceDist}, {"amount", c.amountDist}} {
+		if !slices.Contains(validValueDists, d.value) {
+			return fmt.Errorf("invalid %s distribution: %s (must be one of: %s)", d.name, d.value, strings.Join(validValueDists, ", "))
+		}
+	}
+	if !slices.Contains(validTimeDists, c.timeDist) {
+		return fmt.Errorf("invalid time distribution: %s (must be one of: %s)", c.timeDist, strings.Join(validTimeDists, ", "))
+	}
+	if c.maxBalance < 1 || c.maxAmount < 1 {
+		return fmt.Errorf("max balance and max amount must be at least 1")
+	}
+	if c.timeSpan < time.Second {
+		return fmt.Errorf("time span must be at least 1 second")
+	}
+	return nil
+}
+
+// dataset generates the accounts and then the transactions of a seedConfig.
+// It implements db.DatasetSource; the same seed and end time always yield
+// the same rows.
+type dataset struct {
+	cfg    seedConfig
+	rng    *rand.Rand
+	bursts []time.Time // burst centers for TimeBursty
+
+	nextAccount int
+	nextTx      int
+}
+
+func newDataset(cfg seedConfig) *dataset {
+	d := &dataset{cfg: cfg, rng: rand.New(rand.NewSource(cfg.seed))}
+	if cfg.timeDist == TimeBursty {
+		n := max(1, int(cfg.timeSpan/burstInterval))
+		d.bursts = make([]time.Time, n)
+		for i := range d.bursts {
+			d.bursts[i] = d.uniformTime()
+		}
+	}
+	return d
+}
+
+// accountID returns the ID of the i-th account.
+func accountID(i int) string {
+	return fmt.Sprintf("0.0.%d", firstAccountNum+i)
+}
+
+// NextAccount returns the next account, nil after the last.
+func (d *dataset) NextAccount() *db.Account {
+	if d.nextAccount >= d.cfg.accounts {
+		return nil
+	}
+	acc := &db.Account{
+		AccountID: accountID(d.nextAccount),
+		Balance:   d.value(d.cfg.balanceDist, d.cfg.maxBalance),
+		UpdatedAt: d.cfg.end.Add(-time.Duration(d.rng.Float64() * float64(accountAgeSpan))),
+	}
+	d.nextAccount++
+	return acc
+}
+
+// NextTransaction returns the next transaction between two random accounts,
+// nil after the last.
+func (d *dataset) NextTransaction() *db.Transaction {
+	if d.nextTx >= d.cfg.transactions {
+		return nil
+	}
+	d.nextTx++
+	from := accountID(d.rng.Intn(d.cfg.accounts))
+	ts := d.timestamp()
+	return &db.Transaction{
+		TxID:        fmt.Sprintf("%s@%d.%d", from, ts.Unix(), d.nextTx),
+		FromAccount: from,
+		ToAccount:   accountID(d.rng.Intn(d.cfg.accounts)),
+		Amount:      d.value(d.cfg.amountDist, d.cfg.maxAmount),
+		TxType:      d.txType(),
+		Timestamp:   ts,
+	}
+}
+
+// value draws a value in [0, maxValue] from the named distribution.
+func (d *dataset) value(dist string, maxValue int64) int64 {
+	var v float64
+	switch dist {
+	case DistExponential:
+		v = d.rng.ExpFloat64() * float64(maxValue) / 10
+	case DistPareto:
+		scale := max(1, float64(maxValue)/10000)
+		v = scale / math.Pow(1-d.rng.Float64(), 1/paretoAlpha)
+	default:
+		v = d.rng.Float64() * float64(maxValue)
+	}
+	return min(int64(v), maxValue)
+}
+
+// txType draws a transaction type with the mix of scripts/seed_data.sql:
+// 60% transfers, 36% vesting releases, 4% contract calls.
+func (d *dataset) txType() string {
+	switch r := d.rng.Float64(); {
+	case r < 0.6:
+		return "transfer"
+	case r < 0.96:
+		return "vesting_release"
+	default:
+		return "contract_call"
+	}
+}
+
+// timestamp draws a transaction time within the span ending at cfg.end.
+func (d *dataset) timestamp() time.Time {
+	switch d.cfg.timeDist {
+	case TimeDiurnal:
+		// Rejection sampling against the daily rate curve
+		for {
+			t := d.uniformTime()
+			hour := float64(t.UTC().Hour()) + float64(t.UTC().Minute())/60
+			rate := 0.2 + 0.8*(1+math.Cos(2*math.Pi*(hour-diurnalPeakUTC)/24))/2
+			if d.rng.Float64() < rate {
+				return t
+			}
+		}
+	case TimeBursty:
+		if d.rng.Float64() >= burstFraction {
+			return d.uniformTime()
+		}
+		center := d.bursts[d.rng.Intn(len(d.bursts))]
+		t := center.Add(time.Duration(d.rng.NormFloat64() * float64(burstSpread)))
+		return d.clamp(t)
+	default:
+		return d.uniformTime()
+	}
+}
+
+// uniformTime draws a time uniformly within the span ending at cfg.end.
+func (d *dataset) uniformTime() time.Time {
+	return d.cfg.end.Add(-time.Duration(d.rng.Float64() * float64(d.cfg.timeSpan)))
+}
+
+// clamp limits t to the time span.
+func (d *dataset) clamp(t time.Time) time.Time {
+	if start := d.cfg.end.Add(-d.cfg.timeSpan); t.Before(start) {
+		return start
+	}
+	if t.After(d.cfg.end) {
+		return d.cfg.end
+	}
+	return t
+}
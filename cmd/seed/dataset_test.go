@@ -0,0 +1,147 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func testConfig() seedConfig {
+	return seedConfig{
+		accounts:     100,
+		transactions: 2000,
+		seed:         7,
+		balanceDist:  DistUniform,
+		maxBalance:   1_000_000,
+		amountDist:   DistUniform,
+		maxAmount:    1000,
+		timeDist:     TimeUniform,
+		timeSpan:     24 * time.Hour,
+		end:          time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC),
+	}
+}
+
+func TestSeedConfig_Validate(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(*seedConfig)
+	}{
+		{"no accounts", func(c *seedConfig) { c.accounts = 0 }},
+		{"negative transactions", func(c *seedConfig) { c.transactions = -1 }},
+		{"unknown balance distribution", func(c *seedConfig) { c.balanceDist = "normal" }},
+		{"unknown time distribution", func(c *seedConfig) { c.timeDist = "weekly" }},
+		{"zero max amount", func(c *seedConfig) { c.maxAmount = 0 }},
+		{"short span", func(c *seedConfig) { c.timeSpan = time.Millisecond }},
+	}
+	cfg := testConfig()
+	if err := cfg.validate(); err != nil {
+		t.Fatalf("validate() on the test config error = %v", err)
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := testConfig()
+			tt.modify(&cfg)
+			if err := cfg.validate(); err == nil {
+				t.Error("validate() succeeded, want an error")
+			}
+		})
+	}
+}
+
+func TestDataset_Deterministic(t *testing.T) {
+	a, b := newDataset(testConfig()), newDataset(testConfig())
+	for i := 0; i < 10; i++ {
+		if x, y := a.NextAccount(), b.NextAccount(); *x != *y {
+			t.Fatalf("account %d differs: %+v vs %+v", i, x, y)
+		}
+	}
+	for i := 0; i < 10; i++ {
+		if x, y := a.NextTransaction(), b.NextTransaction(); *x != *y {
+			t.Fatalf("transaction %d differs: %+v vs %+v", i, x, y)
+		}
+	}
+}
+
+func TestDataset_Bounds(t *testing.T) {
+	for _, dist := range validValueDists {
+		for _, timeDist := range validTimeDists {
+			cfg := testConfig()
+			cfg.balanceDist, cfg.amountDist, cfg.timeDist = dist, dist, timeDist
+			d := newDataset(cfg)
+
+			accounts := 0
+			for acc := d.NextAccount(); acc != nil; acc = d.NextAccount() {
+				accounts++
+				if acc.Balance < 0 || acc.Balance > cfg.maxBalance {
+					t.Fatalf("%s: balance %d out of range", dist, acc.Balance)
+				}
+			}
+			if accounts != cfg.accounts {
+				t.Errorf("%s: generated %d accounts, want %d", dist, accounts, cfg.accounts)
+			}
+
+			start := cfg.end.Add(-cfg.timeSpan)
+			ids := make(map[string]bool)
+			for tx := d.NextTransaction(); tx != nil; tx = d.NextTransaction() {
+				if tx.Amount < 0 || tx.Amount > cfg.maxAmount {
+					t.Fatalf("%s: amount %d out of range", dist, tx.Amount)
+				}
+				if tx.Timestamp.Before(start) || tx.Timestamp.After(cfg.end) {
+					t.Fatalf("%s: timestamp %v outside the span", timeDist, tx.Timestamp)
+				}
+				if ids[tx.TxID] {
+					t.Fatalf("duplicate tx_id %s", tx.TxID)
+				}
+				ids[tx.TxID] = true
+			}
+			if len(ids) != cfg.transactions {
+				t.Errorf("%s/%s: generated %d transactions, want %d", dist, timeDist, len(ids), cfg.transactions)
+			}
+		}
+	}
+}
+
+func TestDataset_ParetoSkew(t *testing.T) {
+	cfg := testConfig()
+	cfg.accounts = 10_000
+	cfg.balanceDist = DistPareto
+	d := newDataset(cfg)
+
+	below := 0
+	for acc := d.NextAccount(); acc != nil; acc = d.NextAccount() {
+		if acc.Balance < cfg.maxBalance/100 {
+			below++
+		}
+	}
+	// Nearly every balance is small under the heavy tail, 1% under uniform
+	if below < cfg.accounts*9/10 {
+		t.Errorf("%d of %d balances below 1%% of the maximum, want most", below, cfg.accounts)
+	}
+}
+
+func TestParseScale(t *testing.T) {
+	tests := map[string]int{"500": 500, "10K": 10_000, "100k": 100_000, "1M": 1_000_000, "10M": 10_000_000}
+	for in, want := range tests {
+		if got, err := parseScale(in); err != nil || got != want {
+			t.Errorf("parseScale(%q) = %d, %v; want %d", in, got, err, want)
+		}
+	}
+	for _, in := range []string{"", "0", "-1K", "1G", "K"} {
+		if _, err := parseScale(in); err == nil {
+			t.Errorf("parseScale(%q) succeeded, want an error", in)
+		}
+	}
+}
+
+func TestSeedConfig_ApplyScale(t *testing.T) {
+	cfg := testConfig()
+	cfg.applyScale(1000, false)
+	if cfg.accounts != 1000 || cfg.transactions != 10_000 {
+		t.Errorf("applyScale(1000) = %d accounts, %d transactions; want 1000, 10000", cfg.accounts, cfg.transactions)
+	}
+
+	cfg = testConfig()
+	cfg.applyScale(1000, true)
+	if cfg.transactions != testConfig().transactions {
+		t.Errorf("applyScale() with --transactions set changed transactions to %d", cfg.transactions)
+	}
+}
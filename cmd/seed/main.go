@@ -0,0 +1,106 @@
+// Command seed generates the accounts and transactions the benchmark
+// servers query and bulk-loads them into PostgreSQL, replacing the
+// existing dataset.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/kaldun-tech/grpc-rest-benchmark/pkg/db"
+)
+
+var (
+	dbHost = flag.String("db-host", "localhost", "PostgreSQL host")
+	dbPort = flag.Int("db-port", 5432, "PostgreSQL port")
+	dbUser = flag.String("db-user", "benchmark", "PostgreSQL user")
+	dbPass = flag.String("db-pass", "benchmark_pass", "PostgreSQL password")
+	dbName = flag.String("db-name", "grpc_benchmark", "PostgreSQL database")
+)
+
+func main() {
+	var cfg seedConfig
+	var end, scale string
+	flag.IntVar(&cfg.accounts, "accounts", 10_000, "Number of accounts")
+	flag.IntVar(&cfg.transactions, "transactions", 100_000, "Number of transactions")
+	flag.StringVar(&scale, "dataset-scale", "", "Size the dataset by account count, e.g. 10K, 100K, 1M or 10M, with 10 transactions per account unless --transactions is set")
+	flag.Int64Var(&cfg.seed, "seed", 1, "Random seed; the same seed and --end reproduce the same dataset")
+	flag.StringVar(&cfg.balanceDist, "balance-dist", DistUniform, "Balance distribution: "+strings.Join(validValueDists, " | "))
+	flag.Int64Var(&cfg.maxBalance, "max-balance", 100_000_000_000, "Maximum account balance in tinybar")
+	flag.StringVar(&cfg.amountDist, "amount-dist", DistUniform, "Transaction amount distribution: "+strings.Join(validValueDists, " | "))
+	flag.Int64Var(&cfg.maxAmount, "max-amount", 10_000_000_000, "Maximum transaction amount in tinybar")
+	flag.StringVar(&cfg.timeDist, "time-dist", TimeUniform, "Transaction timestamp distribution: "+strings.Join(validTimeDists, " | "))
+	flag.DurationVar(&cfg.timeSpan, "time-span", 24*time.Hour, "Time span the transactions cover")
+	flag.StringVar(&end, "end", "", "Newest possible transaction time, RFC 3339 (default now)")
+	flag.Parse()
+
+	if scale != "" {
+		set := make(map[string]bool)
+		flag.Visit(func(f *flag.Flag) { set[f.Name] = true })
+		if set["accounts"] {
+			log.Fatal("--dataset-scale and --accounts cannot be combined")
+		}
+		n, err := parseScale(scale)
+		if err != nil {
+			log.Fatal(err)
+		}
+		cfg.applyScale(n, set["transactions"])
+	}
+
+	cfg.end = time.Now().UTC().Truncate(time.Second)
+	if end != "" {
+		t, err := time.Parse(time.RFC3339, end)
+		if err != nil {
+			log.Fatalf("Invalid --end: %v", err)
+		}
+		cfg.end = t.UTC()
+	}
+	if err := cfg.validate(); err != nil {
+		log.Fatal(err)
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+
+	dbCfg := db.Config{
+		Host:     *dbHost,
+		Port:     *dbPort,
+		User:     *dbUser,
+		Password: *dbPass,
+		Database: *dbName,
+	}
+	database, err := db.New(ctx, dbCfg)
+	if err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+	defer database.Close()
+
+	log.Printf("Seeding %d accounts (%s balances) and %d transactions (%s amounts, %s over %s) with seed %d",
+		cfg.accounts, cfg.balanceDist, cfg.transactions, cfg.amountDist, cfg.timeDist, cfg.timeSpan, cfg.seed)
+	start := time.Now()
+	accounts, transactions, err := database.LoadDataset(ctx, newDataset(cfg))
+	if err != nil {
+		log.Fatalf("Failed to seed dataset: %v", err)
+	}
+	log.Printf("Loaded %d accounts and %d transactions in %s", accounts, transactions, time.Since(start).Round(time.Millisecond))
+
+	if f, err := database.GetDatasetFingerprint(ctx); err == nil {
+		log.Printf("Dataset: %s", f)
+	}
+
+	// Mark the reseed on the dashboard trends
+	event := &db.Event{
+		Kind:    db.EventReseed,
+		Message: fmt.Sprintf("Seeded %d accounts and %d transactions with seed %d", accounts, transactions, cfg.seed),
+	}
+	if err := database.RecordEvent(ctx, event); err != nil {
+		log.Printf("Warning: failed to record reseed event: %v", err)
+	}
+}
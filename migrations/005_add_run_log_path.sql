@@ -0,0 +1,2 @@
+-- Reference the client-side structured log file written for each run
+ALTER TABLE benchmark_runs ADD COLUMN log_path TEXT;
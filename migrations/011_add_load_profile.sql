@@ -0,0 +1,57 @@
+-- Load profile (--load-profile) the run stepped through, as "<target> <spec>",
+-- e.g. "concurrency step:10,50,100@30s". NULL for fixed-load runs.
+ALTER TABLE benchmark_runs ADD COLUMN load_profile TEXT;
+
+-- Load profile phase each sample was issued in, counted from 1.
+ALTER TABLE benchmark_samples ADD COLUMN phase INTEGER;
+
+DROP VIEW IF EXISTS benchmark_stats;
+
+CREATE VIEW benchmark_stats AS
+SELECT
+    r.id as run_id,
+    r.scenario,
+    r.protocol,
+    r.client,
+    r.concurrency,
+    r.duration_sec,
+    r.cpu_usage_avg,
+    r.memory_mb_avg,
+    r.memory_mb_peak,
+    r.latency_metric,
+    r.comparison_id,
+    r.payload_size,
+    r.compression,
+    r.load_profile,
+    COUNT(s.id) as total_samples,
+    SUM(CASE WHEN s.success THEN 1 ELSE 0 END) as successful,
+    PERCENTILE_CONT(0.5) WITHIN GROUP (ORDER BY s.latency_ms) as p50_latency,
+    PERCENTILE_CONT(0.9) WITHIN GROUP (ORDER BY s.latency_ms) as p90_latency,
+    PERCENTILE_CONT(0.99) WITHIN GROUP (ORDER BY s.latency_ms) as p99_latency,
+    AVG(s.latency_ms) as avg_latency,
+    MIN(s.latency_ms) as min_latency,
+    MAX(s.latency_ms) as max_latency,
+    PERCENTILE_CONT(0.5) WITHIN GROUP (ORDER BY s.staleness_ms) as p50_staleness,
+    PERCENTILE_CONT(0.9) WITHIN GROUP (ORDER BY s.staleness_ms) as p90_staleness,
+    PERCENTILE_CONT(0.99) WITHIN GROUP (ORDER BY s.staleness_ms) as p99_staleness
+FROM benchmark_runs r
+LEFT JOIN benchmark_samples s ON s.run_id = r.id
+GROUP BY r.id, r.scenario, r.protocol, r.client, r.concurrency, r.duration_sec,
+         r.cpu_usage_avg, r.memory_mb_avg, r.memory_mb_peak, r.latency_metric,
+         r.comparison_id, r.payload_size, r.compression, r.load_profile;
+
+-- Per-phase latency and throughput for load profile runs, for locating the
+-- concurrency or rate at which a protocol saturates.
+CREATE VIEW benchmark_phase_stats AS
+SELECT
+    s.run_id,
+    s.phase,
+    COUNT(*) as total_samples,
+    SUM(CASE WHEN s.success THEN 1 ELSE 0 END) as successful,
+    PERCENTILE_CONT(0.5) WITHIN GROUP (ORDER BY s.latency_ms) as p50_latency,
+    PERCENTILE_CONT(0.99) WITHIN GROUP (ORDER BY s.latency_ms) as p99_latency,
+    MIN(s.timestamp) as started_at,
+    MAX(s.timestamp) as ended_at
+FROM benchmark_samples s
+WHERE s.phase IS NOT NULL
+GROUP BY s.run_id, s.phase;
@@ -0,0 +1,12 @@
+-- Per-second aggregates of each run, by the second a request was issued, to
+-- show instability (GC pauses, checkpoint stalls) that one aggregate per run
+-- hides. Percentiles cover every request, not just the stored samples.
+CREATE TABLE benchmark_timeseries (
+    run_id INT NOT NULL REFERENCES benchmark_runs(id) ON DELETE CASCADE,
+    elapsed_sec INT NOT NULL,
+    requests INT NOT NULL,
+    errors INT NOT NULL,
+    p50_latency_ms FLOAT,
+    p99_latency_ms FLOAT,
+    PRIMARY KEY (run_id, elapsed_sec)
+);
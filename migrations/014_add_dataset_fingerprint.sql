@@ -0,0 +1,48 @@
+-- Fingerprint of the seeded server dataset a run was measured against:
+-- account and transaction counts, the newest transaction timestamp and a
+-- checksum of sampled rows. Runs on different seeds are not comparable.
+-- NULL when the database could not be reached.
+ALTER TABLE benchmark_runs ADD COLUMN dataset_fingerprint TEXT;
+
+DROP VIEW IF EXISTS benchmark_stats;
+
+CREATE VIEW benchmark_stats AS
+SELECT
+    r.id as run_id,
+    r.scenario,
+    r.protocol,
+    r.client,
+    r.concurrency,
+    r.duration_sec,
+    r.cpu_usage_avg,
+    r.memory_mb_avg,
+    r.memory_mb_peak,
+    r.latency_metric,
+    r.comparison_id,
+    r.payload_size,
+    r.compression,
+    r.load_profile,
+    r.dataset_hash,
+    r.dataset_fingerprint,
+    r.baseline_run_id,
+    r.p50_delta_pct,
+    r.p99_delta_pct,
+    r.throughput_delta_pct,
+    COUNT(s.id) as total_samples,
+    SUM(CASE WHEN s.success THEN 1 ELSE 0 END) as successful,
+    PERCENTILE_CONT(0.5) WITHIN GROUP (ORDER BY s.latency_ms) as p50_latency,
+    PERCENTILE_CONT(0.9) WITHIN GROUP (ORDER BY s.latency_ms) as p90_latency,
+    PERCENTILE_CONT(0.99) WITHIN GROUP (ORDER BY s.latency_ms) as p99_latency,
+    AVG(s.latency_ms) as avg_latency,
+    MIN(s.latency_ms) as min_latency,
+    MAX(s.latency_ms) as max_latency,
+    PERCENTILE_CONT(0.5) WITHIN GROUP (ORDER BY s.staleness_ms) as p50_staleness,
+    PERCENTILE_CONT(0.9) WITHIN GROUP (ORDER BY s.staleness_ms) as p90_staleness,
+    PERCENTILE_CONT(0.99) WITHIN GROUP (ORDER BY s.staleness_ms) as p99_staleness
+FROM benchmark_runs r
+LEFT JOIN benchmark_samples s ON s.run_id = r.id
+GROUP BY r.id, r.scenario, r.protocol, r.client, r.concurrency, r.duration_sec,
+         r.cpu_usage_avg, r.memory_mb_avg, r.memory_mb_peak, r.latency_metric,
+         r.comparison_id, r.payload_size, r.compression, r.load_profile,
+         r.dataset_hash, r.dataset_fingerprint, r.baseline_run_id, r.p50_delta_pct, r.p99_delta_pct,
+         r.throughput_delta_pct;
@@ -0,0 +1,19 @@
+-- Workload class of each sample in runs that mix workloads, "stream" or
+-- "balance" in the stream-balance scenario. NULL otherwise.
+ALTER TABLE benchmark_samples ADD COLUMN workload_class TEXT;
+
+-- Per-class latency and throughput for runs that mix workloads, so each
+-- class is reported separately instead of blended into benchmark_stats.
+CREATE VIEW benchmark_class_stats AS
+SELECT
+    s.run_id,
+    s.workload_class,
+    COUNT(*) as total_samples,
+    SUM(CASE WHEN s.success THEN 1 ELSE 0 END) as successful,
+    PERCENTILE_CONT(0.5) WITHIN GROUP (ORDER BY s.latency_ms) as p50_latency,
+    PERCENTILE_CONT(0.99) WITHIN GROUP (ORDER BY s.latency_ms) as p99_latency,
+    MIN(s.timestamp) as started_at,
+    MAX(s.timestamp) as ended_at
+FROM benchmark_samples s
+WHERE s.workload_class IS NOT NULL
+GROUP BY s.run_id, s.workload_class;
@@ -0,0 +1,20 @@
+-- Operation (RPC) each sample measured: GetBalance, GetBalances,
+-- SubmitTransaction, Echo or StreamTransactions. NULL for older samples.
+ALTER TABLE benchmark_samples ADD COLUMN operation TEXT;
+
+-- Per-operation latency and throughput, so runs issuing several operations
+-- (the mixed and write scenarios) report p99 for each instead of blending
+-- them. benchmark_stats keeps one row per run across all operations.
+CREATE VIEW benchmark_operation_stats AS
+SELECT
+    s.run_id,
+    s.operation,
+    COUNT(*) as total_samples,
+    SUM(CASE WHEN s.success THEN 1 ELSE 0 END) as successful,
+    PERCENTILE_CONT(0.5) WITHIN GROUP (ORDER BY s.latency_ms) as p50_latency,
+    PERCENTILE_CONT(0.99) WITHIN GROUP (ORDER BY s.latency_ms) as p99_latency,
+    MIN(s.timestamp) as started_at,
+    MAX(s.timestamp) as ended_at
+FROM benchmark_samples s
+WHERE s.operation IS NOT NULL
+GROUP BY s.run_id, s.operation;
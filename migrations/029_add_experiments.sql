@@ -0,0 +1,19 @@
+-- Curated sets of related runs, e.g. an "HTTP/2 window sweep", so they can
+-- be shared by URL and exported as one report.
+CREATE TABLE experiments (
+    id SERIAL PRIMARY KEY,
+    name TEXT NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    hypothesis TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+-- Runs of each experiment. A run may belong to several experiments.
+CREATE TABLE experiment_runs (
+    experiment_id INT NOT NULL REFERENCES experiments(id) ON DELETE CASCADE,
+    run_id INT NOT NULL REFERENCES benchmark_runs(id) ON DELETE CASCADE,
+    PRIMARY KEY (experiment_id, run_id)
+);
+
+CREATE INDEX idx_experiment_runs_run ON experiment_runs(run_id);
@@ -0,0 +1,74 @@
+-- Balance cache each server ran with (--cache-size, --cache-ttl), e.g.
+-- "size=10000,ttl=1s". Empty for none.
+ALTER TABLE server_config ADD COLUMN cache TEXT NOT NULL DEFAULT '';
+
+-- Cache of the server a run was measured against, copied from
+-- server_config when the run starts, NULL for none; and the fraction of
+-- GetBalance calls the cache answered during the run, NULL when it saw none.
+ALTER TABLE benchmark_runs ADD COLUMN server_cache TEXT;
+ALTER TABLE benchmark_runs ADD COLUMN cache_hit_rate DOUBLE PRECISION;
+
+DROP VIEW IF EXISTS benchmark_stats;
+
+CREATE VIEW benchmark_stats AS
+SELECT
+    r.id as run_id,
+    r.scenario,
+    r.protocol,
+    r.client,
+    r.concurrency,
+    r.duration_sec,
+    r.cpu_usage_avg,
+    r.memory_mb_avg,
+    r.memory_mb_peak,
+    r.net_bytes_sent,
+    r.net_bytes_recv,
+    r.net_interfaces,
+    r.latency_metric,
+    r.comparison_id,
+    r.payload_size,
+    r.compression,
+    r.connection,
+    r.server_qos,
+    r.server_pools,
+    r.write_ratio,
+    r.operation_mix,
+    r.server_query_tx,
+    r.cost,
+    r.cost_per_million,
+    r.cost_model,
+    r.client_cpu_seconds,
+    r.server_cpu_seconds,
+    r.client_per_cpu_sec,
+    r.server_per_cpu_sec,
+    r.server_faults,
+    r.server_cache,
+    r.cache_hit_rate,
+    r.stream_shortfall,
+    r.load_profile,
+    r.dataset_hash,
+    r.dataset_fingerprint,
+    r.dataset_size,
+    r.baseline_run_id,
+    r.p50_delta_pct,
+    r.p99_delta_pct,
+    r.throughput_delta_pct,
+    COUNT(s.id) as total_samples,
+    SUM(CASE WHEN s.success THEN 1 ELSE 0 END) as successful,
+    PERCENTILE_CONT(0.5) WITHIN GROUP (ORDER BY s.latency_ms) as p50_latency,
+    PERCENTILE_CONT(0.9) WITHIN GROUP (ORDER BY s.latency_ms) as p90_latency,
+    PERCENTILE_CONT(0.99) WITHIN GROUP (ORDER BY s.latency_ms) as p99_latency,
+    AVG(s.latency_ms) as avg_latency,
+    MIN(s.latency_ms) as min_latency,
+    MAX(s.latency_ms) as max_latency,
+    PERCENTILE_CONT(0.5) WITHIN GROUP (ORDER BY s.staleness_ms) as p50_staleness,
+    PERCENTILE_CONT(0.9) WITHIN GROUP (ORDER BY s.staleness_ms) as p90_staleness,
+    PERCENTILE_CONT(0.99) WITHIN GROUP (ORDER BY s.staleness_ms) as p99_staleness
+FROM benchmark_runs r
+LEFT JOIN benchmark_samples s ON s.run_id = r.id
+GROUP BY r.id, r.scenario, r.protocol, r.client, r.concurrency, r.duration_sec,
+         r.cpu_usage_avg, r.memory_mb_avg, r.memory_mb_peak, r.net_bytes_sent,
+         r.net_bytes_recv, r.net_interfaces, r.latency_metric,
+         r.comparison_id, r.payload_size, r.compression, r.connection, r.server_qos, r.server_pools, r.write_ratio, r.operation_mix, r.server_query_tx, r.cost, r.cost_per_million, r.cost_model, r.client_cpu_seconds, r.server_cpu_seconds, r.client_per_cpu_sec, r.server_per_cpu_sec, r.server_faults, r.server_cache, r.cache_hit_rate, r.stream_shortfall, r.load_profile,
+         r.dataset_hash, r.dataset_fingerprint, r.dataset_size, r.baseline_run_id, r.p50_delta_pct, r.p99_delta_pct,
+         r.throughput_delta_pct;
@@ -0,0 +1,19 @@
+-- Runs executed together as one comparison, e.g. by benchmark run --config.
+-- Runs refer to their suite by benchmark_runs.suite_id.
+CREATE TABLE benchmark_suites (
+    id TEXT PRIMARY KEY,
+    name TEXT NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+-- Suites of runs recorded before the table existed, named by their ID
+INSERT INTO benchmark_suites (id, name, created_at)
+SELECT suite_id, suite_id, MIN(created_at)
+FROM benchmark_runs
+WHERE suite_id IS NOT NULL
+GROUP BY suite_id;
+
+ALTER TABLE benchmark_runs
+    ADD CONSTRAINT benchmark_runs_suite_id_fkey
+    FOREIGN KEY (suite_id) REFERENCES benchmark_suites(id) ON DELETE SET NULL;
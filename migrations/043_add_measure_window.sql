@@ -0,0 +1,96 @@
+-- Part of the run the samples and stats cover (run --measure-window), as
+-- given, e.g. "10s..50s" or "auto", and as resolved, e.g. "12s..47s"; and
+-- the throughput and p50/p99 latency of the whole run. NULL when the stats
+-- cover the whole run.
+ALTER TABLE benchmark_runs ADD COLUMN measure_window TEXT;
+ALTER TABLE benchmark_runs ADD COLUMN measured_window TEXT;
+ALTER TABLE benchmark_runs ADD COLUMN full_throughput DOUBLE PRECISION;
+ALTER TABLE benchmark_runs ADD COLUMN full_p50_ms DOUBLE PRECISION;
+ALTER TABLE benchmark_runs ADD COLUMN full_p99_ms DOUBLE PRECISION;
+
+DROP VIEW IF EXISTS benchmark_stats;
+
+CREATE VIEW benchmark_stats AS
+SELECT
+    r.id as run_id,
+    r.scenario,
+    r.protocol,
+    r.client,
+    r.concurrency,
+    r.duration_sec,
+    r.cpu_usage_avg,
+    r.memory_mb_avg,
+    r.memory_mb_peak,
+    r.net_bytes_sent,
+    r.net_bytes_recv,
+    r.net_interfaces,
+    r.latency_metric,
+    r.comparison_id,
+    r.suite_id,
+    r.payload_size,
+    r.compression,
+    r.connection,
+    r.server_qos,
+    r.server_pools,
+    r.write_ratio,
+    r.operation_mix,
+    r.server_query_tx,
+    r.cost,
+    r.cost_per_million,
+    r.cost_model,
+    r.client_cpu_seconds,
+    r.server_cpu_seconds,
+    r.client_per_cpu_sec,
+    r.server_per_cpu_sec,
+    r.server_faults,
+    r.server_cache,
+    r.cache_hit_rate,
+    r.workers,
+    r.streams_per_worker,
+    r.process_cost_us,
+    r.account_churn,
+    r.db_target,
+    r.server_log_errors,
+    r.server_log_warnings,
+    r.server_log_lines,
+    r.verified,
+    r.verification,
+    r.stream_shortfall,
+    r.streams_established,
+    r.json_encoder,
+    r.measure_window,
+    r.measured_window,
+    r.full_throughput,
+    r.full_p50_ms,
+    r.full_p99_ms,
+    r.load_profile,
+    r.dataset_hash,
+    r.dataset_fingerprint,
+    r.dataset_size,
+    r.baseline_run_id,
+    r.p50_delta_pct,
+    r.p99_delta_pct,
+    r.throughput_delta_pct,
+    COUNT(s.id) as total_samples,
+    SUM(CASE WHEN s.success THEN 1 ELSE 0 END) as successful,
+    PERCENTILE_CONT(0.5) WITHIN GROUP (ORDER BY s.latency_ms) as p50_latency,
+    PERCENTILE_CONT(0.9) WITHIN GROUP (ORDER BY s.latency_ms) as p90_latency,
+    PERCENTILE_CONT(0.99) WITHIN GROUP (ORDER BY s.latency_ms) as p99_latency,
+    AVG(s.latency_ms) as avg_latency,
+    MIN(s.latency_ms) as min_latency,
+    MAX(s.latency_ms) as max_latency,
+    PERCENTILE_CONT(0.5) WITHIN GROUP (ORDER BY s.staleness_ms) as p50_staleness,
+    PERCENTILE_CONT(0.9) WITHIN GROUP (ORDER BY s.staleness_ms) as p90_staleness,
+    PERCENTILE_CONT(0.99) WITHIN GROUP (ORDER BY s.staleness_ms) as p99_staleness,
+    PERCENTILE_CONT(0.5) WITHIN GROUP (ORDER BY s.db_ms) as p50_db,
+    PERCENTILE_CONT(0.99) WITHIN GROUP (ORDER BY s.db_ms) as p99_db,
+    PERCENTILE_CONT(0.5) WITHIN GROUP (ORDER BY s.latency_ms - s.db_ms) as p50_network,
+    PERCENTILE_CONT(0.99) WITHIN GROUP (ORDER BY s.latency_ms - s.db_ms) as p99_network
+FROM benchmark_runs r
+LEFT JOIN benchmark_samples s ON s.run_id = r.id
+GROUP BY r.id, r.scenario, r.protocol, r.client, r.concurrency, r.duration_sec,
+         r.cpu_usage_avg, r.memory_mb_avg, r.memory_mb_peak, r.net_bytes_sent,
+         r.net_bytes_recv, r.net_interfaces, r.latency_metric,
+         r.comparison_id, r.suite_id, r.payload_size, r.compression, r.connection, r.server_qos, r.server_pools, r.write_ratio, r.operation_mix, r.server_query_tx, r.cost, r.cost_per_million, r.cost_model, r.client_cpu_seconds, r.server_cpu_seconds, r.client_per_cpu_sec, r.server_per_cpu_sec, r.server_faults, r.server_cache, r.cache_hit_rate, r.workers, r.streams_per_worker, r.process_cost_us, r.account_churn, r.db_target, r.server_log_errors, r.server_log_warnings, r.server_log_lines, r.verified, r.verification, r.stream_shortfall, r.streams_established, r.json_encoder, r.measure_window, r.measured_window, r.full_throughput, r.full_p50_ms, r.full_p99_ms, r.load_profile,
+         r.dataset_hash, r.dataset_fingerprint, r.dataset_size, r.baseline_run_id, r.p50_delta_pct, r.p99_delta_pct,
+         r.throughput_delta_pct;
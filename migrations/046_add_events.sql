@@ -0,0 +1,11 @@
+-- Operational events, e.g. a server deploy, a config change or a dataset
+-- reseed, overlaid on trend charts to explain shifts in benchmark history.
+CREATE TABLE events (
+    id SERIAL PRIMARY KEY,
+    kind TEXT NOT NULL,
+    message TEXT NOT NULL,
+    occurred_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_events_occurred_at ON events(occurred_at);
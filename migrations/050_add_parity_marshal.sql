@@ -0,0 +1,5 @@
+-- Encoder of each REST server's parity responses (--parity-marshal):
+-- "protojson" for the generated protobuf types, "structs" for hand-written
+-- types with its JSON encoder. Empty for the gRPC server. Runs record it in
+-- benchmark_runs.json_encoder.
+ALTER TABLE server_config ADD COLUMN parity_marshal TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,10 @@
+-- pprof profiles captured during a run (run --profile-cpu, --profile-mem
+-- with --attach-profiles), served for go tool pprof by
+-- /api/v1/results/{run_id}/profiles/{name}
+CREATE TABLE run_profiles (
+    run_id INT NOT NULL REFERENCES benchmark_runs(id) ON DELETE CASCADE,
+    name TEXT NOT NULL,                  -- source and kind, e.g. "server-cpu"
+    data BYTEA NOT NULL,                 -- gzipped protobuf, as written by runtime/pprof
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (run_id, name)
+);
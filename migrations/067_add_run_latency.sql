@@ -0,0 +1,146 @@
+-- Request counts and latency statistics of the run, from the client's
+-- histograms: the figures it printed. With --max-stored-samples only a uniform
+-- sample of the requests is stored in benchmark_samples, so the view takes
+-- these instead of aggregating the samples, and reports how many samples
+-- were stored. NULL for runs stored before, whose stats still come from
+-- their samples.
+ALTER TABLE benchmark_runs ADD COLUMN requests BIGINT;
+ALTER TABLE benchmark_runs ADD COLUMN successful_requests BIGINT;
+ALTER TABLE benchmark_runs ADD COLUMN latency_p50_ms DOUBLE PRECISION;
+ALTER TABLE benchmark_runs ADD COLUMN latency_p90_ms DOUBLE PRECISION;
+ALTER TABLE benchmark_runs ADD COLUMN latency_p99_ms DOUBLE PRECISION;
+ALTER TABLE benchmark_runs ADD COLUMN latency_avg_ms DOUBLE PRECISION;
+ALTER TABLE benchmark_runs ADD COLUMN latency_min_ms DOUBLE PRECISION;
+ALTER TABLE benchmark_runs ADD COLUMN latency_max_ms DOUBLE PRECISION;
+
+DROP VIEW IF EXISTS benchmark_stats;
+
+CREATE VIEW benchmark_stats AS
+SELECT
+    r.id as run_id,
+    r.scenario,
+    r.protocol,
+    r.client,
+    r.concurrency,
+    r.duration_sec,
+    r.created_at,
+    r.cpu_usage_avg,
+    r.memory_mb_avg,
+    r.memory_mb_peak,
+    r.net_bytes_sent,
+    r.net_bytes_recv,
+    r.net_interfaces,
+    r.latency_metric,
+    r.comparison_id,
+    r.suite_id,
+    r.payload_size,
+    r.compression,
+    r.connection,
+    r.server_qos,
+    r.server_pools,
+    r.write_ratio,
+    r.operation_mix,
+    r.server_query_tx,
+    r.cost,
+    r.cost_per_million,
+    r.cost_model,
+    r.client_cpu_seconds,
+    r.server_cpu_seconds,
+    r.client_per_cpu_sec,
+    r.server_per_cpu_sec,
+    r.server_faults,
+    r.server_cache,
+    r.cache_hit_rate,
+    r.workers,
+    r.streams_per_worker,
+    r.process_cost_us,
+    r.account_churn,
+    r.db_target,
+    r.server_log_errors,
+    r.server_log_warnings,
+    r.server_log_lines,
+    r.verified,
+    r.verification,
+    r.stream_shortfall,
+    r.streams_established,
+    r.json_encoder,
+    r.measure_window,
+    r.measured_window,
+    r.full_throughput,
+    r.full_p50_ms,
+    r.full_p99_ms,
+    r.gc_pauses,
+    r.gc_pause_ms,
+    r.gc_tail_fraction,
+    r.live_stream,
+    r.chaos_disconnect_ms,
+    r.reconnects,
+    r.reconnect_p50_ms,
+    r.reconnect_p99_ms,
+    r.server_middleware,
+    r.auth,
+    r.server_rate_limit,
+    r.backoff,
+    r.chaos_restart,
+    r.restart_errors,
+    r.restart_recovery_ms,
+    r.agents,
+    r.concurrent_with,
+    r.client_commit,
+    r.server_commit,
+    r.go_version,
+    r.gomaxprocs,
+    r.kernel,
+    r.hostname,
+    r.tags,
+    r.environment,
+    r.goroutines_peak,
+    r.heap_objects_peak,
+    r.heap_mb_peak,
+    r.gc_cycles,
+    r.cache_bust,
+    r.injected_headers,
+    r.injected_header_bytes,
+    r.wire_bytes_sent,
+    r.wire_bytes_recv,
+    r.payload_bytes_sent,
+    r.payload_bytes_recv,
+    r.message_p50_bytes,
+    r.message_p99_bytes,
+    r.message_max_bytes,
+    r.rest_stream_format,
+    r.run_config,
+    r.labels,
+    r.notes,
+    r.load_profile,
+    r.dataset_hash,
+    r.dataset_fingerprint,
+    r.dataset_size,
+    r.baseline_run_id,
+    r.p50_delta_pct,
+    r.p99_delta_pct,
+    r.throughput_delta_pct,
+    COALESCE(r.requests, COUNT(s.id)) as total_samples,
+    COALESCE(r.successful_requests, SUM(CASE WHEN s.success THEN 1 ELSE 0 END)) as successful,
+    COALESCE(r.latency_p50_ms, PERCENTILE_CONT(0.5) WITHIN GROUP (ORDER BY s.latency_ms)) as p50_latency,
+    COALESCE(r.latency_p90_ms, PERCENTILE_CONT(0.9) WITHIN GROUP (ORDER BY s.latency_ms)) as p90_latency,
+    COALESCE(r.latency_p99_ms, PERCENTILE_CONT(0.99) WITHIN GROUP (ORDER BY s.latency_ms)) as p99_latency,
+    COALESCE(r.latency_avg_ms, AVG(s.latency_ms)) as avg_latency,
+    COALESCE(r.latency_min_ms, MIN(s.latency_ms)) as min_latency,
+    COALESCE(r.latency_max_ms, MAX(s.latency_ms)) as max_latency,
+    COUNT(s.id) as stored_samples,
+    PERCENTILE_CONT(0.5) WITHIN GROUP (ORDER BY s.staleness_ms) as p50_staleness,
+    PERCENTILE_CONT(0.9) WITHIN GROUP (ORDER BY s.staleness_ms) as p90_staleness,
+    PERCENTILE_CONT(0.99) WITHIN GROUP (ORDER BY s.staleness_ms) as p99_staleness,
+    PERCENTILE_CONT(0.5) WITHIN GROUP (ORDER BY s.db_ms) as p50_db,
+    PERCENTILE_CONT(0.99) WITHIN GROUP (ORDER BY s.db_ms) as p99_db,
+    PERCENTILE_CONT(0.5) WITHIN GROUP (ORDER BY s.latency_ms - s.db_ms) as p50_network,
+    PERCENTILE_CONT(0.99) WITHIN GROUP (ORDER BY s.latency_ms - s.db_ms) as p99_network
+FROM benchmark_runs r
+LEFT JOIN benchmark_samples s ON s.run_id = r.id
+GROUP BY r.id, r.scenario, r.protocol, r.client, r.concurrency, r.duration_sec, r.created_at,
+         r.cpu_usage_avg, r.memory_mb_avg, r.memory_mb_peak, r.net_bytes_sent,
+         r.net_bytes_recv, r.net_interfaces, r.latency_metric,
+         r.comparison_id, r.suite_id, r.payload_size, r.compression, r.connection, r.server_qos, r.server_pools, r.write_ratio, r.operation_mix, r.server_query_tx, r.cost, r.cost_per_million, r.cost_model, r.client_cpu_seconds, r.server_cpu_seconds, r.client_per_cpu_sec, r.server_per_cpu_sec, r.server_faults, r.server_cache, r.cache_hit_rate, r.workers, r.streams_per_worker, r.process_cost_us, r.account_churn, r.db_target, r.server_log_errors, r.server_log_warnings, r.server_log_lines, r.verified, r.verification, r.stream_shortfall, r.streams_established, r.json_encoder, r.measure_window, r.measured_window, r.full_throughput, r.full_p50_ms, r.full_p99_ms, r.gc_pauses, r.gc_pause_ms, r.gc_tail_fraction, r.live_stream, r.chaos_disconnect_ms, r.reconnects, r.reconnect_p50_ms, r.reconnect_p99_ms, r.server_middleware, r.auth, r.server_rate_limit, r.backoff, r.chaos_restart, r.restart_errors, r.restart_recovery_ms, r.agents, r.concurrent_with, r.client_commit, r.server_commit, r.go_version, r.gomaxprocs, r.kernel, r.hostname, r.tags, r.environment, r.goroutines_peak, r.heap_objects_peak, r.heap_mb_peak, r.gc_cycles, r.cache_bust, r.injected_headers, r.injected_header_bytes, r.wire_bytes_sent, r.wire_bytes_recv, r.payload_bytes_sent, r.payload_bytes_recv, r.message_p50_bytes, r.message_p99_bytes, r.message_max_bytes, r.rest_stream_format, r.run_config, r.requests, r.successful_requests, r.latency_p50_ms, r.latency_p90_ms, r.latency_p99_ms, r.latency_avg_ms, r.latency_min_ms, r.latency_max_ms, r.labels, r.notes, r.load_profile,
+         r.dataset_hash, r.dataset_fingerprint, r.dataset_size, r.baseline_run_id, r.p50_delta_pct, r.p99_delta_pct,
+         r.throughput_delta_pct;
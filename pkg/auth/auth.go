@@ -0,0 +1,338 @@
+// Package auth authenticates requests to the benchmark endpoints, so the
+// cost of validating credentials can be compared per protocol. A static API
+// key or an HS256 JWT is sent as a bearer token, in the Authorization header
+// over HTTP and as per-RPC credentials over gRPC; mutual TLS authenticates
+// the connection instead. The REST server checks tokens as HTTP middleware
+// (REST and Connect), the gRPC server as interceptors (gRPC and gRPC-Web), in
+// front of the benchmark services only, like pkg/fault. With mutual TLS the
+// whole listener is TLS.
+package auth
+
+import (
+	"context"
+	"crypto/subtle"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/kaldun-tech/grpc-rest-benchmark/pkg/internal/grpcmethod"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// Authentication modes.
+const (
+	None   = "none"
+	APIKey = "api-key"
+	JWT    = "jwt"
+	MTLS   = "mtls"
+)
+
+// Modes lists the authentication modes.
+var Modes = []string{None, APIKey, JWT, MTLS}
+
+// tokenLifetime is how long the JWTs a client issues itself stay valid,
+// longer than any run.
+const tokenLifetime = 24 * time.Hour
+
+// ErrUnauthenticated is the error of requests without valid credentials.
+var ErrUnauthenticated = errors.New("missing or invalid credentials")
+
+// Config holds the authentication settings of a server or client. Clients
+// of distributed runs receive it with the run, so files are resolved on the
+// worker.
+type Config struct {
+	Mode     string // one of Modes, "" for none
+	Secret   string // API key, or the JWT signing key
+	CertFile string // own certificate (PEM), mtls only
+	KeyFile  string // private key of CertFile (PEM)
+	CAFile   string // CA certificate the peer's certificate must be signed by (PEM)
+}
+
+// RegisterFlags registers the authentication flags of a server on fs.
+func (c *Config) RegisterFlags(fs *flag.FlagSet) {
+	fs.StringVar(&c.Mode, "auth", None, "Authentication of benchmark requests: "+strings.Join(Modes, ", "))
+	fs.StringVar(&c.Secret, "auth-secret", "", "API key (--auth=api-key) or JWT signing key (--auth=jwt)")
+	fs.StringVar(&c.CertFile, "tls-cert", "", "Server certificate (PEM) for --auth=mtls")
+	fs.StringVar(&c.KeyFile, "tls-key", "", "Private key (PEM) of --tls-cert")
+	fs.StringVar(&c.CAFile, "tls-ca", "", "CA certificate (PEM) client certificates must be signed by, for --auth=mtls")
+}
+
+// Validate checks the authentication settings for missing or conflicting
+// values.
+func (c Config) Validate() error {
+	if c.Mode != "" && !slices.Contains(Modes, c.Mode) {
+		return fmt.Errorf("invalid auth mode: %s (must be one of: %s)", c.Mode, strings.Join(Modes, ", "))
+	}
+	bearer := c.Mode == APIKey || c.Mode == JWT
+	if bearer && c.Secret == "" {
+		return fmt.Errorf("--auth=%s requires --auth-secret", c.Mode)
+	}
+	if !bearer && c.Secret != "" {
+		return fmt.Errorf("--auth-secret requires --auth=%s or --auth=%s", APIKey, JWT)
+	}
+	files := c.CertFile != "" || c.KeyFile != "" || c.CAFile != ""
+	if c.Mode == MTLS && (c.CertFile == "" || c.KeyFile == "" || c.CAFile == "") {
+		return fmt.Errorf("--auth=%s requires --tls-cert, --tls-key and --tls-ca", MTLS)
+	}
+	if c.Mode != MTLS && files {
+		return fmt.Errorf("--tls-cert, --tls-key and --tls-ca require --auth=%s", MTLS)
+	}
+	return nil
+}
+
+// Enabled reports whether requests are authenticated.
+func (c Config) Enabled() bool {
+	return c.Mode != "" && c.Mode != None
+}
+
+// String returns the authentication mode, as recorded with each run. It is
+// empty when requests are not authenticated.
+func (c Config) String() string {
+	if !c.Enabled() {
+		return ""
+	}
+	return c.Mode
+}
+
+// Token returns the bearer token a client sends: the API key, or a JWT it
+// signs with the secret. It is empty for other modes.
+func (c Config) Token() (string, error) {
+	switch c.Mode {
+	case APIKey:
+		return c.Secret, nil
+	case JWT:
+		now := time.Now()
+		return signJWT([]byte(c.Secret), jwt.RegisteredClaims{
+			Subject:   "benchmark",
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(tokenLifetime)),
+		})
+	}
+	return "", nil
+}
+
+// ServerTLS returns the TLS configuration of a server that requires client
+// certificates signed by the CA, nil unless the mode is mtls.
+func (c Config) ServerTLS() (*tls.Config, error) {
+	if c.Mode != MTLS {
+		return nil, nil
+	}
+	cert, pool, err := c.load()
+	if err != nil {
+		return nil, err
+	}
+	return &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		ClientCAs:    pool,
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+		MinVersion:   tls.VersionTLS12,
+	}, nil
+}
+
+// ClientTLS returns the TLS configuration of a client that presents its
+// certificate and trusts servers signed by the CA, nil unless the mode is
+// mtls.
+func (c Config) ClientTLS() (*tls.Config, error) {
+	if c.Mode != MTLS {
+		return nil, nil
+	}
+	cert, pool, err := c.load()
+	if err != nil {
+		return nil, err
+	}
+	return &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		RootCAs:      pool,
+		MinVersion:   tls.VersionTLS12,
+	}, nil
+}
+
+// load reads the certificate, its key and the CA.
+func (c Config) load() (tls.Certificate, *x509.CertPool, error) {
+	cert, err := tls.LoadX509KeyPair(c.CertFile, c.KeyFile)
+	if err != nil {
+		return tls.Certificate{}, nil, fmt.Errorf("failed to load TLS certificate: %w", err)
+	}
+	ca, err := os.ReadFile(c.CAFile)
+	if err != nil {
+		return tls.Certificate{}, nil, fmt.Errorf("failed to read TLS CA: %w", err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(ca) {
+		return tls.Certificate{}, nil, fmt.Errorf("no certificates in TLS CA %s", c.CAFile)
+	}
+	return cert, pool, nil
+}
+
+// DialOptions returns the gRPC dial options of a client: TLS transport
+// credentials for mtls, plaintext otherwise, and the bearer token as
+// per-RPC credentials.
+func (c Config) DialOptions() ([]grpc.DialOption, error) {
+	tlsCfg, err := c.ClientTLS()
+	if err != nil {
+		return nil, err
+	}
+	creds := insecure.NewCredentials()
+	if tlsCfg != nil {
+		creds = credentials.NewTLS(tlsCfg)
+	}
+	opts := []grpc.DialOption{grpc.WithTransportCredentials(creds)}
+
+	token, err := c.Token()
+	if err != nil {
+		return nil, err
+	}
+	if token != "" {
+		opts = append(opts, grpc.WithPerRPCCredentials(bearerCredentials("Bearer "+token)))
+	}
+	return opts, nil
+}
+
+// bearerCredentials sends the Authorization value with every call. Tokens
+// are sent over plaintext connections too, as benchmark servers have no
+// TLS outside mtls.
+type bearerCredentials string
+
+func (b bearerCredentials) GetRequestMetadata(ctx context.Context, uri ...string) (map[string]string, error) {
+	return map[string]string{"authorization": string(b)}, nil
+}
+
+func (b bearerCredentials) RequireTransportSecurity() bool {
+	return false
+}
+
+// Transport configures t for the client: TLS with the client certificate
+// for mtls, and the bearer token added to every request.
+func (c Config) Transport(t *http.Transport) (http.RoundTripper, error) {
+	tlsCfg, err := c.ClientTLS()
+	if err != nil {
+		return nil, err
+	}
+	t.TLSClientConfig = tlsCfg
+
+	token, err := c.Token()
+	if err != nil {
+		return nil, err
+	}
+	if token == "" {
+		return t, nil
+	}
+	return &bearerTransport{base: t, authorization: "Bearer " + token}, nil
+}
+
+// bearerTransport adds the Authorization header to every request.
+type bearerTransport struct {
+	base          http.RoundTripper
+	authorization string
+}
+
+func (t *bearerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.Header.Set("Authorization", t.authorization)
+	return t.base.RoundTrip(req)
+}
+
+// Authenticator checks the bearer token of requests. A nil *Authenticator
+// lets every request through, as do servers with mtls, whose TLS handshake
+// already verified the client.
+type Authenticator struct {
+	mode   string
+	secret []byte
+}
+
+// New creates an authenticator for cfg, or returns nil if cfg sends no
+// bearer tokens.
+func New(cfg Config) *Authenticator {
+	if cfg.Mode != APIKey && cfg.Mode != JWT {
+		return nil
+	}
+	return &Authenticator{mode: cfg.Mode, secret: []byte(cfg.Secret)}
+}
+
+// check verifies an Authorization value.
+func (a *Authenticator) check(authorization string) error {
+	token, ok := strings.CutPrefix(authorization, "Bearer ")
+	if !ok {
+		return ErrUnauthenticated
+	}
+	if a.mode == JWT {
+		if err := verifyJWT(a.secret, token, time.Now()); err != nil {
+			return ErrUnauthenticated
+		}
+		return nil
+	}
+	if subtle.ConstantTimeCompare([]byte(token), a.secret) != 1 {
+		return ErrUnauthenticated
+	}
+	return nil
+}
+
+// Handler returns next behind the bearer token check. Requests without a
+// valid token get 401 Unauthorized with a JSON error body.
+func (a *Authenticator) Handler(next http.Handler) http.Handler {
+	if a == nil {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := a.check(r.Header.Get("Authorization")); err != nil {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusUnauthorized)
+			json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// UnaryServerInterceptor checks the bearer token of unary calls to the
+// given services (full names, e.g. "benchmark.BalanceService"); calls to
+// other services, such as health checks, pass through. Calls without a
+// valid token fail with Unauthenticated.
+func (a *Authenticator) UnaryServerInterceptor(services ...string) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if a != nil && grpcmethod.InServices(info.FullMethod, services) {
+			if err := a.checkContext(ctx); err != nil {
+				return nil, err
+			}
+		}
+		return handler(ctx, req)
+	}
+}
+
+// StreamServerInterceptor checks the bearer token of streaming calls to the
+// given services, once before the stream starts.
+func (a *Authenticator) StreamServerInterceptor(services ...string) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if a != nil && grpcmethod.InServices(info.FullMethod, services) {
+			if err := a.checkContext(ss.Context()); err != nil {
+				return err
+			}
+		}
+		return handler(srv, ss)
+	}
+}
+
+// checkContext verifies the authorization metadata of a call.
+func (a *Authenticator) checkContext(ctx context.Context) error {
+	md, _ := metadata.FromIncomingContext(ctx)
+	values := md.Get("authorization")
+	if len(values) == 0 || a.check(values[0]) != nil {
+		return status.Error(codes.Unauthenticated, ErrUnauthenticated.Error())
+	}
+	return nil
+}
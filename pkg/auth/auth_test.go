@@ -0,0 +1,182 @@
+package auth
+
+import (
+	"context"
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestConfig_Validate(t *testing.T) {
+	valid := []Config{
+		{},
+		{Mode: None},
+		{Mode: APIKey, Secret: "key"},
+		{Mode: JWT, Secret: "key"},
+		{Mode: MTLS, CertFile: "c.pem", KeyFile: "k.pem", CAFile: "ca.pem"},
+	}
+	for _, c := range valid {
+		if err := c.Validate(); err != nil {
+			t.Errorf("Validate(%+v) error = %v", c, err)
+		}
+	}
+	invalid := []Config{
+		{Mode: "basic"},
+		{Mode: APIKey},
+		{Mode: None, Secret: "key"},
+		{Mode: MTLS, CertFile: "c.pem"},
+		{Mode: JWT, Secret: "key", CAFile: "ca.pem"},
+	}
+	for _, c := range invalid {
+		if err := c.Validate(); err == nil {
+			t.Errorf("Validate(%+v) = nil, want an error", c)
+		}
+	}
+	if got := (Config{Mode: None}).String(); got != "" {
+		t.Errorf("String() of none = %q, want empty", got)
+	}
+}
+
+// serve returns a server with handler behind the authenticator of cfg.
+func serve(t *testing.T, cfg Config) *httptest.Server {
+	t.Helper()
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+	srv := httptest.NewServer(New(cfg).Handler(ok))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+// get returns the status of a GET through a transport configured by cfg.
+func get(t *testing.T, url string, cfg Config) int {
+	t.Helper()
+	transport, err := cfg.Transport(&http.Transport{})
+	if err != nil {
+		t.Fatalf("Transport() error = %v", err)
+	}
+	resp, err := (&http.Client{Transport: transport}).Get(url)
+	if err != nil {
+		t.Fatalf("GET error = %v", err)
+	}
+	resp.Body.Close()
+	return resp.StatusCode
+}
+
+func TestAuthenticator_Handler(t *testing.T) {
+	for _, mode := range []string{APIKey, JWT} {
+		t.Run(mode, func(t *testing.T) {
+			srv := serve(t, Config{Mode: mode, Secret: "secret"})
+			if got := get(t, srv.URL, Config{Mode: mode, Secret: "secret"}); got != http.StatusOK {
+				t.Errorf("status with the right secret = %d, want 200", got)
+			}
+			if got := get(t, srv.URL, Config{Mode: mode, Secret: "wrong"}); got != http.StatusUnauthorized {
+				t.Errorf("status with the wrong secret = %d, want 401", got)
+			}
+			if got := get(t, srv.URL, Config{}); got != http.StatusUnauthorized {
+				t.Errorf("status without credentials = %d, want 401", got)
+			}
+		})
+	}
+}
+
+func TestVerifyJWT(t *testing.T) {
+	secret := []byte("secret")
+	now := time.Now()
+	valid, err := signJWT(secret, jwt.RegisteredClaims{ExpiresAt: jwt.NewNumericDate(now.Add(time.Minute))})
+	if err != nil {
+		t.Fatalf("signJWT() error = %v", err)
+	}
+	if err := verifyJWT(secret, valid, now); err != nil {
+		t.Errorf("verifyJWT(valid) error = %v", err)
+	}
+	if err := verifyJWT(secret, valid, now.Add(time.Hour)); err == nil {
+		t.Error("verifyJWT accepted an expired token")
+	}
+	if err := verifyJWT([]byte("other"), valid, now); err == nil {
+		t.Error("verifyJWT accepted a token signed with another secret")
+	}
+
+	// An unsigned token with the algorithm "none" must not pass
+	parts := strings.Split(valid, ".")
+	none := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"none"}`)) + "." + parts[1] + "."
+	if err := verifyJWT(secret, none, now); err == nil {
+		t.Error("verifyJWT accepted an unsigned token")
+	}
+
+	// Tokens must expire
+	forever, err := signJWT(secret, jwt.RegisteredClaims{Subject: "benchmark"})
+	if err != nil {
+		t.Fatalf("signJWT() error = %v", err)
+	}
+	if err := verifyJWT(secret, forever, now); err == nil {
+		t.Error("verifyJWT accepted a token without an expiry")
+	}
+}
+
+func TestAuthenticator_UnaryServerInterceptor(t *testing.T) {
+	a := New(Config{Mode: APIKey, Secret: "key"})
+	interceptor := a.UnaryServerInterceptor("benchmark.BalanceService")
+	ok := func(ctx context.Context, req any) (any, error) { return "ok", nil }
+	call := func(method, authorization string) error {
+		ctx := context.Background()
+		if authorization != "" {
+			ctx = metadata.NewIncomingContext(ctx, metadata.Pairs("authorization", authorization))
+		}
+		_, err := interceptor(ctx, nil, &grpc.UnaryServerInfo{FullMethod: method}, ok)
+		return err
+	}
+
+	const balance = "/benchmark.BalanceService/GetBalance"
+	if err := call(balance, "Bearer key"); err != nil {
+		t.Errorf("call with the key error = %v", err)
+	}
+	if err := call(balance, "Bearer nope"); status.Code(err) != codes.Unauthenticated {
+		t.Errorf("call with the wrong key error = %v, want Unauthenticated", err)
+	}
+	if err := call("/grpc.health.v1.Health/Check", ""); err != nil {
+		t.Errorf("health check without credentials error = %v", err)
+	}
+}
+
+func TestMTLS(t *testing.T) {
+	dir := t.TempDir()
+	if err := GenerateCerts(dir, []string{"127.0.0.1", "localhost"}); err != nil {
+		t.Fatalf("GenerateCerts() error = %v", err)
+	}
+	file := func(name string) string { return dir + "/" + name }
+	serverCfg := Config{Mode: MTLS, CertFile: file(ServerCertFile), KeyFile: file(ServerKeyFile), CAFile: file(CACertFile)}
+	clientCfg := Config{Mode: MTLS, CertFile: file(ClientCertFile), KeyFile: file(ClientKeyFile), CAFile: file(CACertFile)}
+
+	tlsCfg, err := serverCfg.ServerTLS()
+	if err != nil {
+		t.Fatalf("ServerTLS() error = %v", err)
+	}
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	srv.TLS = tlsCfg
+	srv.StartTLS()
+	defer srv.Close()
+
+	if got := get(t, srv.URL, clientCfg); got != http.StatusOK {
+		t.Errorf("status with a client certificate = %d, want 200", got)
+	}
+
+	// Without a client certificate the handshake fails
+	withoutCert := clientCfg
+	withoutCert.CertFile, withoutCert.KeyFile = file(ServerCertFile), file(ServerKeyFile)
+	transport, err := withoutCert.Transport(&http.Transport{})
+	if err != nil {
+		t.Fatalf("Transport() error = %v", err)
+	}
+	if resp, err := (&http.Client{Transport: transport}).Get(srv.URL); err == nil {
+		resp.Body.Close()
+		t.Error("request with a server certificate as client certificate succeeded")
+	}
+}
@@ -0,0 +1,32 @@
+package auth
+
+import (
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// HS256 JSON Web Tokens (RFC 7519), with github.com/golang-jwt/jwt: the
+// client signs its own token with the shared secret, and the server verifies
+// the signature and expiry of every request.
+
+// jwtMethods are the signing algorithms accepted: HS256, the only one
+// issued.
+var jwtMethods = []string{jwt.SigningMethodHS256.Alg()}
+
+// signJWT returns the signed token of claims.
+func signJWT(secret []byte, claims jwt.RegisteredClaims) (string, error) {
+	return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(secret)
+}
+
+// verifyJWT checks that token is an HS256 token signed with secret that has
+// not expired at now.
+func verifyJWT(secret []byte, token string, now time.Time) error {
+	_, err := jwt.ParseWithClaims(token, new(jwt.RegisteredClaims),
+		func(*jwt.Token) (any, error) { return secret, nil },
+		jwt.WithValidMethods(jwtMethods),
+		jwt.WithExpirationRequired(),
+		jwt.WithTimeFunc(func() time.Time { return now }),
+	)
+	return err
+}
@@ -0,0 +1,739 @@
+// Package bench generates benchmark load against the gRPC and REST servers
+// and measures it. It is the engine behind the benchmark CLI, importable so
+// other Go programs can embed a benchmark instead of shelling out:
+//
+//	report, err := bench.Run(ctx, bench.Config{
+//		Scenario:    "balance",
+//		Protocol:    "grpc",
+//		Addr:        "localhost:50051",
+//		AccountIDs:  accountIDs,
+//		Concurrency: 50,
+//		Duration:    30 * time.Second,
+//	})
+//	if err != nil {
+//		return err
+//	}
+//	fmt.Println(report.Results.Throughput(), report.Results.Percentile(99))
+//
+// For finer control, a Runner issues the requests of one scenario through a
+// BenchmarkClient and a Results collects its samples.
+package bench
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math/rand"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/kaldun-tech/grpc-rest-benchmark/pkg/compression"
+	"github.com/kaldun-tech/grpc-rest-benchmark/pkg/jsoncodec"
+	"github.com/kaldun-tech/grpc-rest-benchmark/pkg/restapi"
+	"github.com/kaldun-tech/grpc-rest-benchmark/pkg/timing"
+	"github.com/kaldun-tech/grpc-rest-benchmark/pkg/workload"
+)
+
+// Scenarios and protocols a run can select.
+var (
+	Scenarios = []string{"balance", "stream", "echo", "stream-balance", "write", "mixed", "fanout", "reference"}
+	Protocols = []string{"grpc", "rest", "connect", "grpc-web", "graphql", "jsonrpc"}
+)
+
+// resourceInterval is how often the resource monitor samples the process.
+const resourceInterval = 100 * time.Millisecond
+
+// Config describes one benchmark run. Zero values select the CLI defaults
+// where the CLI has one, except that write runs with a zero WriteRatio only
+// query balances. The fields not tagged json:"-" are sent to remote workers.
+type Config struct {
+	Scenario string // one of Scenarios
+	Protocol string // one of Protocols
+
+	// Addr is the server address: host:port for grpc, a base URL such as
+	// http://localhost:8080 for the other protocols.
+	Addr            string
+	ConnectEncoding string // Connect codec, one of ConnectEncodings; "" means proto
+	RESTEncoding    string // REST body encoding, one of RESTEncodings; "" means json
+	RESTShape       string // REST JSON body shape, one of RESTShapes; "" means idiomatic
+	RESTStream      string // REST JSON stream framing, one of restapi.StreamFormats; "" means sse
+	JSONEncoder     string // REST and JSON-RPC JSON encoder, one of jsoncodec.Names; "" means jsoncodec.Std
+	Compression     string // compression.Names; "" means none
+	Conn            ConnOptions
+
+	// Client replaces the client Run would dial from Protocol and Addr.
+	// Run does not close it.
+	Client BenchmarkClient `json:"-"`
+
+	// Workers are the addresses of remote workers (see Worker) that
+	// generate the load instead of this process, each a share of the
+	// concurrency and rate. Their samples are combined into one run.
+	Workers []string `json:"-"`
+
+	// AccountIDs are the seeded accounts that balance queries and writes
+	// pick from, required by every scenario but stream, echo and reference.
+	AccountIDs []string
+
+	Concurrency int
+	Duration    time.Duration // ignored with a load profile
+	Rate        int           // events/s per stream, total requests/s for unary scenarios (0 = unlimited), or submissions/s for fanout
+
+	LoadProfile *LoadProfile      // varies concurrency or rate in phases (unary scenarios)
+	Timing      *timing.Replay    `json:"-"` // paces unary requests like recorded traffic
+	Poisson     bool              // exponentially distributed gaps around Rate
+	Accounts    workload.Accounts // account access pattern, uniform by default
+
+	StreamMetric    string  // primary stream latency, one of StreamMetrics; "" means end-to-end for live streams, otherwise delivery
+	Subscribers     int     // stream subscribers in the stream-balance and fanout scenarios
+	StreamRate      int     // events/s per stream-balance subscriber (0 = unlimited)
+	MinStreams      float64 // fraction of stream subscribers that must establish their stream, 0 to 1
+	CheckOrdering   bool    // compare the transactions each stream subscriber receives
+	LiveStreams     bool    // stream scenario streams follow live transactions instead of replaying the table
+	Reconnect       bool    // reopen failed replay streams, resuming after the last transaction received
+	Staleness       bool    // record the age of each returned balance
+	CorrectOmission bool    // also record latencies corrected for coordinated omission
+	PayloadSize     int     // echo scenario response size in bytes
+	WriteRatio      float64 // fraction of write scenario requests that submit a transaction
+	Mix             []MixOperation
+	RequestTimeout  time.Duration // deadline for each unary request (0 = none)
+	Backoff         bool          // back off after requests the server rate limited
+
+	// ChaosDisconnect cuts each replay stream this long after it was
+	// opened, and reconnects it, to measure the cost of reconnecting (0 =
+	// never). It implies Reconnect.
+	ChaosDisconnect time.Duration
+
+	// ChaosRestart restarts the server this long into the run through
+	// RestartServer, to measure the error burst and recovery time of a
+	// rolling restart (0 = never).
+	ChaosRestart  time.Duration
+	RestartServer func(ctx context.Context) error `json:"-"`
+
+	// StreamsPerWorker is how many concurrent streams each of the
+	// Concurrency stream workers owns in the stream scenario, 0 for 1.
+	StreamsPerWorker int
+
+	// ProcessCost is the CPU time spent consuming each response or stream
+	// event, modeling application work in the client (0 = none).
+	ProcessCost time.Duration
+
+	// MaxStoredSamples caps the raw samples kept for storage (0 = all).
+	MaxStoredSamples int
+
+	// MeasureWindow restricts the stats to the requests issued in part of
+	// the run, nil for the whole run.
+	MeasureWindow *MeasureWindow `json:"-"`
+
+	// ProgressInterval is how often interim stats go to the run logger in
+	// ctx (see WithLogger), 0 for never.
+	ProgressInterval time.Duration
+
+	// Interim receives requests to summarize the run so far or rotate its
+	// segment while it runs, nil for none. Summaries go to InterimOut.
+	Interim    <-chan Interim `json:"-"`
+	InterimOut io.Writer      `json:"-"`
+
+	// SampleFlush, if set, receives every sample as it is collected and is
+	// rotated with the interim segments.
+	SampleFlush SampleFlusher `json:"-"`
+
+	// ServerStats, if set, reads the server's counters so far. They are read
+	// before and after the run to measure server efficiency and the hit rate
+	// of its balance cache.
+	ServerStats func(ctx context.Context) (ServerStats, error) `json:"-"`
+}
+
+// ServerStats are cumulative counters of the server under test.
+type ServerStats struct {
+	CPUSeconds  float64 // CPU time the server process has used
+	CacheHits   int64   // GetBalance calls its balance cache answered
+	CacheMisses int64   // GetBalance calls its balance cache passed on
+
+	PoolAcquireWaits    int64 // database connection acquires that waited for a free connection
+	PoolAcquireFailures int64 // acquires given up before a connection was free
+}
+
+// Report is the outcome of a run.
+type Report struct {
+	Results *Results
+
+	// Ordering compares the events each stream subscriber received, nil
+	// unless Config.CheckOrdering is set.
+	Ordering *OrderingReport
+
+	// Load the run is recorded at: the peak level for a load profile's
+	// target, otherwise the configured value.
+	Concurrency int
+	Rate        int
+
+	// RequestInterval is each unary worker's expected time between
+	// requests in the last phase, zero if requests were unpaced.
+	RequestInterval time.Duration
+
+	// ChurnSeed seeded the working set rotations of Accounts, the
+	// configured seed or the one picked for the run; 0 without churn.
+	ChurnSeed int64
+
+	// Warnings are the problems that did not stop the run, such as
+	// resource usage that could not be measured. They are also logged to
+	// the run logger.
+	Warnings []string
+}
+
+// Run runs one benchmark as configured until its duration or load profile
+// is over or ctx is done, and returns the collected results. Printing and
+// storing them is left to the caller, e.g. with Results.PrintSummary and
+// Results.StoreResults. With Workers set, the workers generate the load and
+// Run combines their samples.
+func Run(ctx context.Context, cfg Config) (Report, error) {
+	if err := cfg.validate(); err != nil {
+		return Report{}, err
+	}
+	if err := cfg.seedChurn(ctx); err != nil {
+		return Report{}, err
+	}
+
+	// The load comes from a runner in this process or from remote workers
+	var runner *Runner
+	var remote *remoteRun
+	var transfer TransferClient // counts the bytes of a local run's client, if it can
+	if len(cfg.Workers) > 0 {
+		var err error
+		if remote, err = dialWorkers(cfg); err != nil {
+			return Report{}, err
+		}
+		defer remote.Close()
+	} else {
+		client := cfg.Client
+		if client == nil {
+			var err error
+			if client, err = NewClient(cfg); err != nil {
+				return Report{}, err
+			}
+			defer client.Close()
+		}
+		var err error
+		if runner, err = cfg.newRunner(client); err != nil {
+			return Report{}, err
+		}
+		transfer, _ = client.(TransferClient)
+
+		// Connection setup happens before the measured window, so that
+		// all workers start issuing requests at once
+		connectCtx, cancel := context.WithTimeout(ctx, preconnectTimeout)
+		err = preconnect(connectCtx, client, &cfg)
+		cancel()
+		if err != nil {
+			return Report{}, fmt.Errorf("failed to connect to %s: %w", cfg.Addr, err)
+		}
+	}
+
+	results := NewResults()
+	results.SetMaxStoredSamples(cfg.MaxStoredSamples)
+	if cfg.CorrectOmission {
+		if runner != nil {
+			results.SetExpectedInterval(runner.RequestInterval())
+		} else {
+			results.SetExpectedInterval(cfg.requestInterval(0))
+		}
+	}
+	if n := cfg.StreamSubscribers(); n > 0 {
+		results.SetStreamMetric(cfg.streamMetric())
+		results.SetStreams(n)
+	}
+	if cfg.LoadProfile != nil {
+		results.SetLoadProfile(cfg.LoadProfile)
+	}
+	if cfg.MeasureWindow != nil {
+		results.SetMeasureWindow(cfg.MeasureWindow)
+	}
+	if cfg.ChaosRestart > 0 {
+		results.restart = &restartTracker{}
+	}
+
+	report := Report{Results: results, Concurrency: cfg.Concurrency, Rate: cfg.Rate, ChurnSeed: cfg.Accounts.ChurnSeed}
+	warn := func(format string, args ...any) {
+		msg := fmt.Sprintf(format, args...)
+		report.Warnings = append(report.Warnings, msg)
+		LoggerFrom(ctx).Warn(msg)
+	}
+
+	// Resource usage is measured in this process, so only when it
+	// generates the load
+	var monitor *ResourceMonitor
+	if runner != nil {
+		var err error
+		if monitor, err = NewResourceMonitor(resourceInterval); err != nil {
+			warn("could not initialize resource monitor: %v", err)
+		} else {
+			monitor.SetLoopback(isLoopbackAddr(cfg.Addr))
+		}
+	}
+
+	benchCtx, benchCancel := context.WithTimeout(ctx, cfg.RunDuration())
+	defer benchCancel()
+
+	// Start resource monitoring, on this host and on the server
+	var stopMonitor func() ResourceStats
+	if monitor != nil {
+		stopMonitor = monitor.Start(benchCtx)
+	}
+	var stopGC func() []GCPause
+	if runner != nil {
+		stopGC = watchGCPauses(benchCtx)
+	}
+	var serverStart ServerStats
+	measureServer := cfg.ServerStats != nil
+	if measureServer {
+		var err error
+		if serverStart, err = cfg.ServerStats(ctx); err != nil {
+			warn("server stats unavailable, efficiency is measured on the client only: %v", err)
+			measureServer = false
+		}
+	}
+
+	var samples <-chan Sample
+	var wait func() error
+	startAt := time.Now()
+	if remote != nil {
+		startAt = startAt.Add(workerStartDelay)
+		samples, wait = remote.start(ctx, startAt)
+	} else {
+		samples = runner.Results()
+	}
+	results.SetStartTime(startAt)
+	// Preconnecting moved bytes too
+	var transferStart Transfer
+	if transfer != nil {
+		transferStart = transfer.Transfer()
+	}
+
+	done := make(chan struct{})
+	go func() {
+		collectWithProgress(ctx, results, samples, &cfg)
+		close(done)
+	}()
+	restarted := make(chan error, 1)
+	if cfg.ChaosRestart > 0 {
+		go func() { restarted <- restartServer(benchCtx, &cfg, results, startAt) }()
+	}
+
+	var runErr error
+	if remote != nil {
+		runErr = wait()
+	} else {
+		runScenario(benchCtx, runner, cfg.Scenario)
+	}
+	<-done
+	if runErr != nil {
+		return Report{}, runErr
+	}
+
+	results.SetEndTime(time.Now())
+	if cfg.ChaosRestart > 0 {
+		if err := <-restarted; err != nil {
+			warn("server restart failed, the run was not disrupted: %v", err)
+		}
+	}
+	if w := cfg.MeasureWindow; w != nil && w.Auto {
+		if err := results.detectSteadyState(); err != nil {
+			warn("%v; the stats cover the whole run", err)
+		}
+	}
+	if runner != nil {
+		results.SetStreamEnds(runner.StreamEnds())
+		if fewest, most, ok := runner.StreamSent(); ok {
+			results.SetStreamSent(fewest, most)
+		}
+	}
+
+	if stopMonitor != nil {
+		results.SetResourceStats(stopMonitor())
+	}
+	if transfer != nil {
+		results.SetTransfer(transfer.Transfer().Sub(transferStart))
+	}
+	if stopGC != nil {
+		results.SetGCPauses(stopGC())
+	}
+	if measureServer && ctx.Err() == nil {
+		if end, err := cfg.ServerStats(ctx); err != nil {
+			warn("server stats unavailable: %v", err)
+		} else {
+			results.SetServerCPUSeconds(end.CPUSeconds - serverStart.CPUSeconds)
+			results.SetServerCache(end.CacheHits-serverStart.CacheHits, end.CacheMisses-serverStart.CacheMisses)
+			results.SetServerPool(end.PoolAcquireWaits-serverStart.PoolAcquireWaits, end.PoolAcquireFailures-serverStart.PoolAcquireFailures)
+		}
+	}
+
+	// Streams that failed before their first event leave the run with fewer
+	// subscribers than configured
+	if established, streams, ok := results.StreamsEstablished(); ok && established < streams {
+		if float64(established) < cfg.MinStreams*float64(streams) {
+			return Report{}, fmt.Errorf("only %d/%d streams established, fewer than the required %g%%",
+				established, streams, cfg.MinStreams*100)
+		}
+		warn("only %d/%d streams established", established, streams)
+	}
+
+	if cfg.StreamSubscribers() > 0 && results.SuccessfulRequests() > 0 {
+		if _, ok := results.StreamPercentile(cfg.streamMetric(), 50); !ok {
+			warn("no stream events carried %s latency; primary latency columns will be empty", cfg.streamMetric())
+		}
+	}
+
+	// A profiled run is recorded at its peak load; the phases are in the
+	// samples
+	if p := cfg.LoadProfile; p != nil {
+		if p.Target == ProfileTargetConcurrency {
+			report.Concurrency = p.MaxLevel()
+		} else {
+			report.Rate = p.MaxLevel()
+		}
+	}
+	if runner != nil {
+		if rep, ok := runner.OrderingReport(); ok {
+			report.Ordering = &rep
+		}
+		report.RequestInterval = runner.RequestInterval()
+	} else {
+		report.RequestInterval = cfg.requestInterval(-1)
+	}
+
+	return report, nil
+}
+
+// runScenario runs the configured scenario's load on runner until ctx is
+// done. The runner closes its results channel when the load is over.
+func runScenario(ctx context.Context, runner *Runner, scenario string) {
+	switch scenario {
+	case "balance":
+		runner.RunBalance(ctx)
+	case "stream":
+		runner.RunStream(ctx)
+	case "stream-balance":
+		runner.RunStreamBalance(ctx)
+	case "echo":
+		runner.RunEcho(ctx)
+	case "write":
+		runner.RunWrite(ctx)
+	case "mixed":
+		runner.RunMix(ctx)
+	case "fanout":
+		runner.RunFanout(ctx)
+	case "reference":
+		runner.RunBalance(ctx)
+	}
+}
+
+// NewClient creates the client for cfg.Protocol connected to cfg.Addr, or
+// the in-process stub for the reference scenario.
+func NewClient(cfg Config) (BenchmarkClient, error) {
+	if cfg.Scenario == "reference" {
+		return NewReferenceClient(), nil
+	}
+
+	comp := cfg.Compression
+	if comp == "" {
+		comp = compression.None
+	}
+
+	switch cfg.Protocol {
+	case "grpc":
+		client, err := NewGRPCClient(cfg.Addr, comp, cfg.Conn)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create gRPC client: %w", err)
+		}
+		return client, nil
+	case "rest":
+		encoding := cfg.RESTEncoding
+		if encoding == "" {
+			encoding = "json"
+		}
+		shape := cfg.RESTShape
+		if shape == "" {
+			shape = "idiomatic"
+		}
+		stream := cfg.RESTStream
+		if stream == "" {
+			stream = restapi.StreamSSE
+		}
+		jsonEncoder := cfg.JSONEncoder
+		if jsonEncoder == "" {
+			jsonEncoder = jsoncodec.Std
+		}
+		client, err := NewHTTPClient(cfg.Addr, encoding, shape, stream, jsonEncoder, comp, cfg.Conn)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create HTTP client: %w", err)
+		}
+		return client, nil
+	case "connect":
+		encoding := cfg.ConnectEncoding
+		if encoding == "" {
+			encoding = "proto"
+		}
+		client, err := NewConnectClient(cfg.Addr, encoding, comp, cfg.Conn)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create Connect client: %w", err)
+		}
+		return client, nil
+	case "grpc-web":
+		client, err := NewGRPCWebClient(cfg.Addr, cfg.Conn)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create gRPC-Web client: %w", err)
+		}
+		return client, nil
+	case "graphql":
+		client, err := NewGraphQLClient(cfg.Addr, comp, cfg.Conn)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create GraphQL client: %w", err)
+		}
+		return client, nil
+	case "jsonrpc":
+		jsonEncoder := cfg.JSONEncoder
+		if jsonEncoder == "" {
+			jsonEncoder = jsoncodec.Std
+		}
+		client, err := NewJSONRPCClient(cfg.Addr, jsonEncoder, comp, cfg.Conn)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create JSON-RPC client: %w", err)
+		}
+		return client, nil
+	default:
+		return nil, fmt.Errorf("unsupported protocol: %s", cfg.Protocol)
+	}
+}
+
+// validate checks that cfg describes a run Run can perform. The CLI checks
+// its flags more thoroughly before building a Config.
+func (c *Config) validate() error {
+	if !slices.Contains(Scenarios, c.Scenario) {
+		return fmt.Errorf("invalid scenario: %s (must be one of: %s)", c.Scenario, strings.Join(Scenarios, ", "))
+	}
+	if c.Client == nil && c.Scenario != "reference" && !slices.Contains(Protocols, c.Protocol) {
+		return fmt.Errorf("invalid protocol: %s (must be one of: %s)", c.Protocol, strings.Join(Protocols, ", "))
+	}
+	if c.StreamMetric != "" && !slices.Contains(StreamMetrics, c.StreamMetric) {
+		return fmt.Errorf("invalid stream metric: %s (must be one of: %s)", c.StreamMetric, strings.Join(StreamMetrics, ", "))
+	}
+	if c.Concurrency < 1 {
+		return fmt.Errorf("concurrency must be at least 1")
+	}
+	if c.RunDuration() <= 0 {
+		return fmt.Errorf("duration must be positive")
+	}
+	if c.StreamsPerWorker < 0 || (c.StreamsPerWorker > 1 && c.Scenario != "stream") {
+		return fmt.Errorf("streams per worker must not be negative, and only apply to the stream scenario")
+	}
+	if c.ProcessCost < 0 {
+		return fmt.Errorf("process cost must not be negative")
+	}
+	if c.MinStreams < 0 || c.MinStreams > 1 {
+		return fmt.Errorf("minimum fraction of established streams must be between 0 and 1")
+	}
+	if c.Scenario == "stream-balance" && c.Subscribers < 1 {
+		return fmt.Errorf("stream-balance needs at least 1 subscriber")
+	}
+	if c.LiveStreams && (c.Scenario != "stream" || c.Rate > 0) {
+		return fmt.Errorf("live streams apply to the stream scenario, without a rate limit")
+	}
+	if c.ChaosDisconnect < 0 {
+		return fmt.Errorf("chaos disconnect interval must not be negative")
+	}
+	if c.ChaosRestart < 0 || c.ChaosRestart >= c.RunDuration() {
+		return fmt.Errorf("chaos restart must be within the run")
+	}
+	if c.ChaosRestart > 0 && c.RestartServer == nil {
+		return fmt.Errorf("chaos restart needs a way to restart the server")
+	}
+	if c.reconnects() && (c.LiveStreams || (c.Scenario != "stream" && c.Scenario != "stream-balance")) {
+		return fmt.Errorf("reconnection resumes replay streams, in the stream and stream-balance scenarios without live streams")
+	}
+	if c.Scenario == "fanout" && (c.Subscribers < 1 || c.Rate < 1) {
+		return fmt.Errorf("fanout needs at least 1 subscriber and a submission rate")
+	}
+	if c.Scenario == "reference" && len(c.Workers) > 0 {
+		return fmt.Errorf("the reference scenario runs against an in-process stub, not on workers")
+	}
+	if c.Scenario != "stream" && c.Scenario != "echo" && c.Scenario != "reference" && len(c.AccountIDs) == 0 {
+		return fmt.Errorf("the %s scenario needs account IDs", c.Scenario)
+	}
+	if c.Scenario == "mixed" && len(c.Mix) == 0 {
+		return fmt.Errorf("the mixed scenario needs an operation mix")
+	}
+	if w := c.MeasureWindow; w != nil {
+		if c.LoadProfile != nil {
+			return fmt.Errorf("a measure window does not apply to load profile runs, whose phases are measured separately")
+		}
+		if err := w.validate(c.RunDuration()); err != nil {
+			return err
+		}
+	}
+	if len(c.Workers) > 0 {
+		return c.validateWorkers()
+	}
+	return nil
+}
+
+// reconnects reports whether the run's streams reconnect.
+func (c *Config) reconnects() bool {
+	return c.Reconnect || c.ChaosDisconnect > 0
+}
+
+// newRunner creates a runner for client set up as configured.
+func (c *Config) newRunner(client BenchmarkClient) (*Runner, error) {
+	accountIDs := c.AccountIDs
+	if c.Scenario == "reference" && len(accountIDs) == 0 {
+		accountIDs = ReferenceAccountIDs
+	}
+	runner := NewRunner(client, accountIDs, c.Concurrency, c.Rate)
+	runner.SetStreamMetric(c.streamMetric())
+	runner.SetStreamSubscribers(c.Subscribers, c.StreamRate)
+	runner.SetStreamsPerWorker(c.StreamsPerWorker)
+	runner.SetProcessCost(c.ProcessCost)
+	runner.SetCheckOrdering(c.CheckOrdering)
+	runner.SetRequestTimeout(c.RequestTimeout)
+	runner.SetBackoff(c.Backoff)
+	if err := runner.SetMeasureStaleness(c.Staleness); err != nil {
+		return nil, fmt.Errorf("cannot measure staleness with %s: %w", c.Protocol, err)
+	}
+	if c.LiveStreams {
+		if err := runner.SetLiveStreams(); err != nil {
+			return nil, fmt.Errorf("cannot run live streams with %s: %w", c.Protocol, err)
+		}
+	}
+	if c.reconnects() {
+		if err := runner.SetReconnect(c.ChaosDisconnect); err != nil {
+			return nil, fmt.Errorf("cannot reconnect streams with %s: %w", c.Protocol, err)
+		}
+	}
+	switch c.Scenario {
+	case "echo":
+		if err := runner.SetPayloadSize(c.PayloadSize); err != nil {
+			return nil, fmt.Errorf("cannot run the echo scenario with %s: %w", c.Protocol, err)
+		}
+	case "write":
+		if err := runner.SetWriteRatio(c.WriteRatio); err != nil {
+			return nil, fmt.Errorf("cannot run the write scenario with %s: %w", c.Protocol, err)
+		}
+	case "mixed":
+		if err := runner.SetOperationMix(c.Mix); err != nil {
+			return nil, fmt.Errorf("cannot run the operation mix with %s: %w", c.Protocol, err)
+		}
+	case "fanout":
+		if err := runner.SetFanout(); err != nil {
+			return nil, fmt.Errorf("cannot run the fanout scenario with %s: %w", c.Protocol, err)
+		}
+	}
+	if c.Accounts != (workload.Accounts{}) {
+		if err := runner.SetAccountPattern(c.Accounts); err != nil {
+			return nil, err
+		}
+	}
+	runner.SetPoissonArrivals(c.Poisson)
+	if c.LoadProfile != nil {
+		runner.SetLoadProfile(c.LoadProfile)
+	}
+	if c.Timing != nil {
+		runner.SetTimingReplay(c.Timing)
+	}
+	return runner, nil
+}
+
+// seedChurn picks a seed for account churn if none is configured, so that
+// remote workers rotate the same working sets and the run can be repeated,
+// and logs the rotation schedule to the run logger in ctx.
+func (c *Config) seedChurn(ctx context.Context) error {
+	a := &c.Accounts
+	if a.Churn <= 0 || len(c.AccountIDs) == 0 {
+		return nil
+	}
+	for a.ChurnSeed == 0 {
+		a.ChurnSeed = rand.Int63()
+	}
+	schedule, err := a.ChurnSchedule(len(c.AccountIDs), c.RunDuration())
+	if err != nil {
+		return err
+	}
+
+	logger := LoggerFrom(ctx)
+	logger.Info("account churn", "seed", a.ChurnSeed, "interval", a.ChurnInterval.String(), "rotations", len(schedule))
+	ids := func(indexes []int) []string {
+		out := make([]string, len(indexes))
+		for i, idx := range indexes {
+			out[i] = c.AccountIDs[idx]
+		}
+		return out
+	}
+	for _, r := range schedule {
+		logger.Info("account churn rotation", "at", r.At.String(), "out", ids(r.Out), "in", ids(r.In))
+	}
+	return nil
+}
+
+// streamMetric returns the primary stream latency definition.
+func (c *Config) streamMetric() string {
+	switch {
+	case c.StreamMetric != "":
+		return c.StreamMetric
+	case c.Scenario == "fanout" || c.LiveStreams:
+		return StreamMetricEndToEnd
+	}
+	return StreamMetricDelivery
+}
+
+// StreamSubscribers returns the number of stream subscribers the run opens,
+// 0 for unary scenarios.
+func (c *Config) StreamSubscribers() int {
+	switch c.Scenario {
+	case "stream":
+		return c.Concurrency * max(c.StreamsPerWorker, 1)
+	case "stream-balance", "fanout":
+		return c.Subscribers
+	}
+	return 0
+}
+
+// connections returns how many requests and streams the run has in flight
+// at most, each of which may need a connection of its own.
+func (c *Config) connections() int {
+	n := c.Concurrency
+	if c.LoadProfile != nil && c.LoadProfile.Target == ProfileTargetConcurrency {
+		n = c.LoadProfile.MaxLevel()
+	}
+	switch c.Scenario {
+	case "stream":
+		return c.StreamSubscribers()
+	case "stream-balance", "fanout":
+		return n + c.Subscribers
+	}
+	return n
+}
+
+// RunDuration returns how long the run lasts: the load profile's total
+// duration if one is set, otherwise Duration.
+func (c *Config) RunDuration() time.Duration {
+	if c.LoadProfile != nil {
+		return c.LoadProfile.Duration()
+	}
+	return c.Duration
+}
+
+// requestInterval returns each unary worker's expected time between
+// requests in a load profile phase, counted from 0 with -1 for the last, or
+// under the configured load without a profile. It is 0 for unpaced requests.
+func (c *Config) requestInterval(phase int) time.Duration {
+	concurrency, rate := c.Concurrency, c.Rate
+	if p := c.LoadProfile; p != nil {
+		if phase < 0 {
+			phase = len(p.Phases) - 1
+		}
+		if p.Target == ProfileTargetConcurrency {
+			concurrency = p.Phases[phase].Level
+		} else {
+			rate = p.Phases[phase].Level
+		}
+	}
+	return requestInterval(concurrency, rate)
+}
@@ -0,0 +1,353 @@
+package bench
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/kaldun-tech/grpc-rest-benchmark/pkg/workload"
+)
+
+func TestRun(t *testing.T) {
+	client := &concurrencyClient{}
+	server := ServerStats{CPUSeconds: 10}
+	report, err := Run(context.Background(), Config{
+		Scenario:    "balance",
+		Client:      client,
+		Addr:        "localhost:50051",
+		AccountIDs:  []string{"0.0.1001", "0.0.1002"},
+		Concurrency: 2,
+		Duration:    100 * time.Millisecond,
+		ServerStats: func(ctx context.Context) (ServerStats, error) {
+			server.CPUSeconds += 0.5
+			server.CacheHits += 3
+			server.CacheMisses++
+			return server, nil
+		},
+	})
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	r := report.Results
+	if r.TotalRequests() == 0 || r.SuccessfulRequests() != r.TotalRequests() {
+		t.Errorf("requests = %d, successful = %d; want some, all successful", r.TotalRequests(), r.SuccessfulRequests())
+	}
+	if d := r.Duration(); d < 100*time.Millisecond {
+		t.Errorf("Duration() = %v, want at least the configured 100ms", d)
+	}
+	if _, ok := r.ServerEfficiency(); !ok {
+		t.Error("ServerEfficiency() unavailable, want it measured with ServerStats")
+	}
+	if rate, ok := r.CacheHitRate(); !ok || rate != 0.75 {
+		t.Errorf("CacheHitRate() = %v, %v; want 0.75, true", rate, ok)
+	}
+	if report.Concurrency != 2 || report.Ordering != nil {
+		t.Errorf("report = %+v, want concurrency 2 and no ordering report", report)
+	}
+	if client.maxInFlight > 2 {
+		t.Errorf("max in flight = %d, want at most the concurrency 2", client.maxInFlight)
+	}
+}
+
+func TestRun_LoadProfilePeak(t *testing.T) {
+	report, err := Run(context.Background(), Config{
+		Scenario:    "balance",
+		Client:      &concurrencyClient{},
+		AccountIDs:  []string{"0.0.1001"},
+		Concurrency: 1,
+		LoadProfile: &LoadProfile{
+			Target: ProfileTargetConcurrency,
+			Phases: []LoadPhase{{Level: 1, Duration: 50 * time.Millisecond}, {Level: 3, Duration: 50 * time.Millisecond}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if report.Concurrency != 3 {
+		t.Errorf("Concurrency = %d, want the peak level 3", report.Concurrency)
+	}
+}
+
+// eventClient streams a fixed number of events on every stream.
+type eventClient struct {
+	concurrencyClient
+	events int
+	opened atomic.Int32
+}
+
+func (c *eventClient) StreamTransactions(ctx context.Context, rate int) (<-chan StreamEvent, <-chan error) {
+	c.opened.Add(1)
+	events := make(chan StreamEvent, c.events)
+	for i := 0; i < c.events; i++ {
+		events <- StreamEvent{ReceivedAt: time.Now()}
+	}
+	close(events)
+	return events, make(chan error)
+}
+
+func TestRun_StreamsPerWorker(t *testing.T) {
+	client := &eventClient{events: 5}
+	report, err := Run(context.Background(), Config{
+		Scenario:         "stream",
+		Client:           client,
+		Concurrency:      2,
+		StreamsPerWorker: 3,
+		Duration:         time.Second,
+	})
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	if got := client.opened.Load(); got != 6 {
+		t.Errorf("streams opened = %d, want 2 workers x 3", got)
+	}
+	stats := report.Results.SubscriberStats()
+	if len(stats) != 6 {
+		t.Fatalf("SubscriberStats() = %+v, want 6 subscribers", stats)
+	}
+	for _, s := range stats {
+		if s.Events != 5 {
+			t.Errorf("subscriber %d events = %d, want 5", s.Subscriber, s.Events)
+		}
+	}
+}
+
+// failingStreamClient fails every other stream before its first event.
+type failingStreamClient struct {
+	eventClient
+}
+
+func (c *failingStreamClient) StreamTransactions(ctx context.Context, rate int) (<-chan StreamEvent, <-chan error) {
+	if c.opened.Add(1)%2 == 0 {
+		errs := make(chan error, 1)
+		errs <- errors.New("connection refused")
+		return make(chan StreamEvent), errs
+	}
+	events := make(chan StreamEvent, c.events)
+	for i := 0; i < c.events; i++ {
+		events <- StreamEvent{ReceivedAt: time.Now()}
+	}
+	close(events)
+	return events, make(chan error)
+}
+
+func TestRun_MinStreams(t *testing.T) {
+	for _, tt := range []struct {
+		minStreams float64
+		wantErr    bool
+	}{{0.5, false}, {0.75, true}} {
+		report, err := Run(context.Background(), Config{
+			Scenario:         "stream",
+			Client:           &failingStreamClient{eventClient{events: 5}},
+			Concurrency:      2,
+			StreamsPerWorker: 2,
+			Duration:         time.Second,
+			MinStreams:       tt.minStreams,
+		})
+		if tt.wantErr {
+			if err == nil || !strings.Contains(err.Error(), "only 2/4 streams established") {
+				t.Errorf("Run() with MinStreams %g error = %v, want 2/4 streams established", tt.minStreams, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("Run() with MinStreams %g error = %v", tt.minStreams, err)
+		}
+		if established, streams, _ := report.Results.StreamsEstablished(); established != 2 || streams != 4 {
+			t.Errorf("StreamsEstablished() = %d/%d, want 2/4", established, streams)
+		}
+	}
+}
+
+func TestRun_ProcessCost(t *testing.T) {
+	report, err := Run(context.Background(), Config{
+		Scenario:     "stream",
+		Client:       &eventClient{events: 20},
+		Concurrency:  1,
+		Duration:     time.Second,
+		StreamMetric: StreamMetricProcessing,
+		ProcessCost:  5 * time.Millisecond,
+	})
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	// The last event waits while the 19 before it are consumed
+	r := report.Results
+	if r.TotalRequests() != 20 {
+		t.Fatalf("samples = %d, want 20", r.TotalRequests())
+	}
+	if got := r.MaxLatency(); got < 19*5*time.Millisecond {
+		t.Errorf("max processing latency = %v, want at least 95ms of queued consumption", got)
+	}
+}
+
+func TestRun_AccountChurnSeed(t *testing.T) {
+	cfg := Config{
+		Scenario:    "balance",
+		Client:      &concurrencyClient{},
+		AccountIDs:  []string{"0.0.1001", "0.0.1002", "0.0.1003", "0.0.1004"},
+		Concurrency: 1,
+		Duration:    50 * time.Millisecond,
+		Accounts:    workload.Accounts{Pattern: workload.AccountsUniform, WorkingSet: 0.5, Churn: 0.5, ChurnInterval: time.Second},
+	}
+	report, err := Run(context.Background(), cfg)
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if report.ChurnSeed == 0 {
+		t.Error("ChurnSeed = 0, want the seed picked for the run")
+	}
+
+	cfg.Accounts.ChurnSeed = 42
+	if report, err = Run(context.Background(), cfg); err != nil || report.ChurnSeed != 42 {
+		t.Errorf("Run() ChurnSeed = %d, %v; want the configured 42", report.ChurnSeed, err)
+	}
+}
+
+// liveEventClient yields live events stored 3ms before they arrive.
+type liveEventClient struct {
+	eventClient
+	live atomic.Int32
+}
+
+func (c *liveEventClient) StreamLiveTransactions(ctx context.Context) (<-chan StreamEvent, <-chan error) {
+	c.live.Add(1)
+	events := make(chan StreamEvent, c.events)
+	for range c.events {
+		now := time.Now()
+		events <- StreamEvent{ReceivedAt: now, StoredAt: now.Add(-3 * time.Millisecond)}
+	}
+	close(events)
+	return events, make(chan error)
+}
+
+func TestRun_LiveStreams(t *testing.T) {
+	client := &liveEventClient{eventClient: eventClient{events: 5}}
+	report, err := Run(context.Background(), Config{
+		Scenario:    "stream",
+		Client:      client,
+		Concurrency: 2,
+		LiveStreams: true,
+		Duration:    time.Second,
+	})
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	if live, replayed := client.live.Load(), client.opened.Load(); live != 2 || replayed != 0 {
+		t.Errorf("opened %d live and %d replaying streams, want 2 live", live, replayed)
+	}
+	if p50, ok := report.Results.StreamPercentile(StreamMetricEndToEnd, 50); !ok || p50 < 2*time.Millisecond || p50 > 4*time.Millisecond {
+		t.Errorf("end-to-end p50 = %v, %v; want about 3ms", p50, ok)
+	}
+}
+
+// hubClient delivers every submitted transaction to the live streams open
+// at the time, 2ms after it was stored.
+type hubClient struct {
+	concurrencyClient
+	mu   sync.Mutex
+	subs []chan StreamEvent
+}
+
+func (c *hubClient) SubmitTransaction(ctx context.Context, from, to string, amount int64) error {
+	stored := time.Now()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, sub := range c.subs {
+		select {
+		case sub <- StreamEvent{ReceivedAt: stored.Add(2 * time.Millisecond), StoredAt: stored}:
+		default:
+		}
+	}
+	return nil
+}
+
+func (c *hubClient) StreamLiveTransactions(ctx context.Context) (<-chan StreamEvent, <-chan error) {
+	sub := make(chan StreamEvent, 1024)
+	c.mu.Lock()
+	c.subs = append(c.subs, sub)
+	c.mu.Unlock()
+	return sub, make(chan error)
+}
+
+func TestRun_Fanout(t *testing.T) {
+	report, err := Run(context.Background(), Config{
+		Scenario:    "fanout",
+		Client:      &hubClient{},
+		AccountIDs:  []string{"0.0.1", "0.0.2"},
+		Concurrency: 1,
+		Subscribers: 3,
+		Rate:        200,
+		Duration:    300 * time.Millisecond,
+	})
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	if names := report.Results.ClassNames(); !slices.Contains(names, "write") {
+		t.Errorf("ClassNames() = %v, want write samples", names)
+	}
+	stats := report.Results.SubscriberStats()
+	if len(stats) != 3 {
+		t.Fatalf("SubscriberStats() = %+v, want 3 subscribers", stats)
+	}
+	for _, s := range stats {
+		if s.Events == 0 {
+			t.Errorf("subscriber %d received no submissions", s.Subscriber)
+		}
+	}
+	if p50, ok := report.Results.StreamPercentile(StreamMetricEndToEnd, 50); !ok || p50 < time.Millisecond || p50 > 3*time.Millisecond {
+		t.Errorf("end-to-end p50 = %v, %v; want about 2ms", p50, ok)
+	}
+}
+
+func TestRun_InvalidConfig(t *testing.T) {
+	valid := Config{
+		Scenario:    "balance",
+		Client:      &concurrencyClient{},
+		AccountIDs:  []string{"0.0.1001"},
+		Concurrency: 1,
+		Duration:    time.Second,
+	}
+
+	tests := []struct {
+		name   string
+		modify func(c *Config)
+	}{
+		{"unknown scenario", func(c *Config) { c.Scenario = "nope" }},
+		{"unknown protocol", func(c *Config) { c.Client = nil; c.Protocol = "soap" }},
+		{"no concurrency", func(c *Config) { c.Concurrency = 0 }},
+		{"no duration", func(c *Config) { c.Duration = 0 }},
+		{"no accounts", func(c *Config) { c.AccountIDs = nil }},
+		{"no mix", func(c *Config) { c.Scenario = "mixed" }},
+		{"unsupported echo", func(c *Config) { c.Scenario = "echo" }},
+		{"streams per worker", func(c *Config) { c.StreamsPerWorker = 2 }},
+		{"negative process cost", func(c *Config) { c.ProcessCost = -time.Millisecond }},
+		{"fanout without subscribers", func(c *Config) { c.Scenario = "fanout"; c.Rate = 10 }},
+		{"fanout without rate", func(c *Config) { c.Scenario = "fanout"; c.Subscribers = 1 }},
+		{"live balance", func(c *Config) { c.LiveStreams = true }},
+		{"live stream with rate", func(c *Config) { c.Scenario = "stream"; c.LiveStreams = true; c.Rate = 10 }},
+		{"live without client support", func(c *Config) { c.Scenario = "stream"; c.LiveStreams = true }},
+		{"reconnect balance", func(c *Config) { c.Reconnect = true }},
+		{"reconnect live", func(c *Config) { c.Scenario = "stream"; c.LiveStreams = true; c.ChaosDisconnect = time.Second }},
+		{"negative chaos disconnect", func(c *Config) { c.Scenario = "stream"; c.ChaosDisconnect = -time.Second }},
+		{"reconnect without client support", func(c *Config) { c.Scenario = "stream"; c.Reconnect = true }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := valid
+			tt.modify(&cfg)
+			if _, err := Run(context.Background(), cfg); err == nil {
+				t.Error("Run() error = nil, want an error")
+			}
+		})
+	}
+}
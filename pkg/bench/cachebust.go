@@ -0,0 +1,103 @@
+package bench
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// Cache-busting modes selectable with --cache-bust. They make every request
+// unique, so caches between the client and the server (CDNs, reverse
+// proxies, service meshes) pass it through to the server. REST requests
+// carry the unique value in the query string or a header. gRPC, Connect
+// and gRPC-Web requests are POSTs that no cache answers; they carry the same
+// value as metadata in either mode, so both sides pay for the extra bytes.
+const (
+	CacheBustNone   = "none"
+	CacheBustQuery  = "query"  // append a unique _cb query parameter
+	CacheBustHeader = "header" // send a unique X-Cache-Bust header and Cache-Control: no-cache
+)
+
+// CacheBustModes lists the modes selectable with --cache-bust.
+var CacheBustModes = []string{CacheBustNone, CacheBustQuery, CacheBustHeader}
+
+const (
+	cacheBustParam    = "_cb"
+	cacheBustHeader   = "X-Cache-Bust"
+	cacheBustMetadata = "x-cache-bust"
+)
+
+// cacheBusting reports whether mode makes requests unique.
+func cacheBusting(mode string) bool {
+	return mode != "" && mode != CacheBustNone
+}
+
+// cacheBuster generates values no earlier request carried.
+type cacheBuster struct {
+	prefix string // differs between processes, so runs miss each other's cache entries
+	n      atomic.Uint64
+}
+
+func newCacheBuster() *cacheBuster {
+	return &cacheBuster{prefix: strconv.FormatInt(time.Now().UnixNano(), 36)}
+}
+
+func (b *cacheBuster) next() string {
+	return b.prefix + "-" + strconv.FormatUint(b.n.Add(1), 36)
+}
+
+// cacheBustTransport stamps HTTP requests with a unique query parameter or
+// header before sending them.
+type cacheBustTransport struct {
+	next   http.RoundTripper
+	query  bool
+	buster *cacheBuster
+}
+
+// withCacheBusting returns rt stamping requests as mode selects, or rt
+// itself if mode does not bust caches. Protocols whose requests no cache
+// answers pass header for either mode.
+func withCacheBusting(rt http.RoundTripper, mode string) http.RoundTripper {
+	if !cacheBusting(mode) {
+		return rt
+	}
+	return &cacheBustTransport{next: rt, query: mode == CacheBustQuery, buster: newCacheBuster()}
+}
+
+func (t *cacheBustTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	// A RoundTripper must not modify the caller's request
+	req = req.Clone(req.Context())
+	v := t.buster.next()
+	if t.query {
+		if req.URL.RawQuery != "" {
+			req.URL.RawQuery += "&"
+		}
+		req.URL.RawQuery += cacheBustParam + "=" + v
+	} else {
+		req.Header.Set(cacheBustHeader, v)
+		req.Header.Set("Cache-Control", "no-cache")
+	}
+	return t.next.RoundTrip(req)
+}
+
+// grpcCacheBusting returns the dial options adding a unique x-cache-bust
+// metadata entry to every call, none if mode does not bust caches.
+func grpcCacheBusting(mode string) []grpc.DialOption {
+	if !cacheBusting(mode) {
+		return nil
+	}
+	b := newCacheBuster()
+	return []grpc.DialOption{
+		grpc.WithChainUnaryInterceptor(func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+			return invoker(metadata.AppendToOutgoingContext(ctx, cacheBustMetadata, b.next()), method, req, reply, cc, opts...)
+		}),
+		grpc.WithChainStreamInterceptor(func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+			return streamer(metadata.AppendToOutgoingContext(ctx, cacheBustMetadata, b.next()), desc, cc, method, opts...)
+		}),
+	}
+}
@@ -0,0 +1,98 @@
+package bench
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/kaldun-tech/grpc-rest-benchmark/pkg/jsoncodec"
+	"github.com/kaldun-tech/grpc-rest-benchmark/pkg/restapi"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+)
+
+func TestHTTPClient_CacheBust(t *testing.T) {
+	tests := []struct {
+		mode   string
+		stamp  func(r *http.Request) string
+		busted bool
+	}{
+		{CacheBustNone, func(r *http.Request) string { return r.URL.Query().Get(cacheBustParam) + r.Header.Get(cacheBustHeader) }, false},
+		{CacheBustQuery, func(r *http.Request) string { return r.URL.Query().Get(cacheBustParam) }, true},
+		{CacheBustHeader, func(r *http.Request) string { return r.Header.Get(cacheBustHeader) }, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.mode, func(t *testing.T) {
+			var mu sync.Mutex
+			var stamps []string
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				mu.Lock()
+				stamps = append(stamps, tt.stamp(r))
+				mu.Unlock()
+				if tt.mode == CacheBustHeader && r.Header.Get("Cache-Control") != "no-cache" {
+					t.Errorf("Cache-Control = %q, want no-cache", r.Header.Get("Cache-Control"))
+				}
+				w.Header().Set("Content-Type", "application/json")
+				w.Write([]byte(`{"account_id":"0.0.1","balance":1}`))
+			}))
+			defer srv.Close()
+
+			client, err := NewHTTPClient(srv.URL, "json", "idiomatic", restapi.StreamSSE, jsoncodec.Std, "none", ConnOptions{CacheBust: tt.mode})
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer client.Close()
+			for range 2 {
+				if err := client.GetBalance(context.Background(), "0.0.1"); err != nil {
+					t.Fatalf("GetBalance() error = %v", err)
+				}
+			}
+
+			if len(stamps) != 2 {
+				t.Fatalf("server got %d requests, want 2", len(stamps))
+			}
+			if !tt.busted {
+				if stamps[0] != "" || stamps[1] != "" {
+					t.Errorf("requests carry %q, want them unchanged", stamps)
+				}
+				return
+			}
+			if stamps[0] == "" || stamps[0] == stamps[1] {
+				t.Errorf("requests carry %q, want distinct values", stamps)
+			}
+		})
+	}
+}
+
+func TestGRPCCacheBusting(t *testing.T) {
+	if opts := grpcCacheBusting(CacheBustNone); opts != nil {
+		t.Errorf("grpcCacheBusting(none) = %d options, want none", len(opts))
+	}
+
+	// A last interceptor records the metadata instead of sending the call
+	var seen []string
+	record := func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, _ grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		md, _ := metadata.FromOutgoingContext(ctx)
+		seen = append(seen, md.Get(cacheBustMetadata)...)
+		return nil
+	}
+	conn, err := grpc.NewClient("passthrough:///unused", append(grpcCacheBusting(CacheBustQuery),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithChainUnaryInterceptor(record))...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	for range 2 {
+		if err := conn.Invoke(context.Background(), "/test.Service/Method", nil, nil); err != nil {
+			t.Fatalf("Invoke() error = %v", err)
+		}
+	}
+
+	if len(seen) != 2 || seen[0] == "" || seen[0] == seen[1] {
+		t.Errorf("calls carry %q, want distinct x-cache-bust values", seen)
+	}
+}
@@ -0,0 +1,961 @@
+package bench
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kaldun-tech/grpc-rest-benchmark/pkg/auth"
+	"github.com/kaldun-tech/grpc-rest-benchmark/pkg/compression"
+	"github.com/kaldun-tech/grpc-rest-benchmark/pkg/jsoncodec"
+	"github.com/kaldun-tech/grpc-rest-benchmark/pkg/protos"
+	"github.com/kaldun-tech/grpc-rest-benchmark/pkg/restapi"
+	"github.com/kaldun-tech/grpc-rest-benchmark/pkg/servertiming"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/keepalive"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/encoding/protodelim"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+// ConnOptions configures how clients establish and reuse connections, to
+// separate connection setup cost from request cost.
+type ConnOptions struct {
+	// DisableKeepAlive opens a new connection for every request instead
+	// of reusing connections.
+	DisableKeepAlive bool
+	// MaxConnsPerHost caps the connections HTTP transports open to the
+	// server, 0 for no limit.
+	MaxConnsPerHost int
+	// GRPCConns is the number of gRPC connections the workers are spread
+	// over, each worker keeping to one.
+	// Each connection is one HTTP/2 connection subject to the server's
+	// MAX_CONCURRENT_STREAMS; 0 means 1.
+	GRPCConns int
+
+	// gRPC keepalive pings; zero GRPCKeepaliveTime leaves them disabled
+	GRPCKeepaliveTime       time.Duration
+	GRPCKeepaliveTimeout    time.Duration
+	GRPCPermitWithoutStream bool
+
+	// Auth authenticates the requests: a bearer token with every request,
+	// or a client certificate over TLS.
+	Auth auth.Config
+
+	// CacheBust makes every request unique to intermediary caches, one of
+	// CacheBustModes; "" means none.
+	CacheBust string
+
+	// Headers are added to every REST request and Metadata to every gRPC,
+	// Connect and gRPC-Web call, e.g. to carry realistic tracing headers.
+	Headers  http.Header
+	Metadata metadata.MD
+}
+
+// newTransport returns the HTTP transport shared by the HTTP-based clients,
+// counting the wire bytes of its connections in bytes.
+func newTransport(conn ConnOptions, bytes *byteCounter) *http.Transport {
+	return &http.Transport{
+		DialContext:         bytes.dialer(),
+		MaxIdleConns:        100,
+		MaxIdleConnsPerHost: 100,
+		MaxConnsPerHost:     conn.MaxConnsPerHost,
+		IdleConnTimeout:     90 * time.Second,
+		DisableKeepAlives:   conn.DisableKeepAlive,
+	}
+}
+
+// authenticated returns transport with the authentication of conn.
+func authenticated(transport *http.Transport, conn ConnOptions) (http.RoundTripper, error) {
+	rt, err := conn.Auth.Transport(transport)
+	if err != nil {
+		return nil, fmt.Errorf("failed to configure authentication: %w", err)
+	}
+	return rt, nil
+}
+
+// BenchmarkClient abstracts gRPC and REST for uniform benchmarking.
+type BenchmarkClient interface {
+	GetBalance(ctx context.Context, accountID string) error
+	StreamTransactions(ctx context.Context, rate int) (<-chan StreamEvent, <-chan error)
+	Close() error
+}
+
+// BalanceTimestampClient is implemented by clients that can report when the
+// returned balance was last updated, used to measure balance staleness.
+// Decoding the timestamp costs extra work on some transports, so the runner
+// only uses it when staleness measurement is enabled.
+type BalanceTimestampClient interface {
+	GetBalanceUpdatedAt(ctx context.Context, accountID string) (time.Time, error)
+}
+
+// EchoClient is implemented by clients that can call the echo endpoint,
+// used by the payload size scenario.
+type EchoClient interface {
+	Echo(ctx context.Context, size int) error
+}
+
+// BatchBalanceClient is implemented by clients that can query several
+// balances in one request, used by batch reads in an operation mix.
+type BatchBalanceClient interface {
+	GetBalances(ctx context.Context, accountIDs []string) error
+}
+
+// WriteClient is implemented by clients that can submit transactions, used
+// by the write scenario.
+type WriteClient interface {
+	SubmitTransaction(ctx context.Context, from, to string, amount int64) error
+}
+
+// LiveStreamClient is implemented by clients that can follow transactions
+// as they are submitted instead of replaying stored ones, used by the
+// fanout scenario. Live events carry when the transaction was stored.
+type LiveStreamClient interface {
+	StreamLiveTransactions(ctx context.Context) (<-chan StreamEvent, <-chan error)
+}
+
+// ResumableStreamClient is implemented by clients that can resume a replay
+// stream after the last transaction received, used to reconnect streams.
+type ResumableStreamClient interface {
+	ResumeTransactions(ctx context.Context, rate int, afterTxID string) (<-chan StreamEvent, <-chan error)
+}
+
+// StreamEvent represents a received streaming event.
+type StreamEvent struct {
+	ReceivedAt time.Time
+	SentAt     time.Time // server emit time, zero if the transport does not carry it
+	StoredAt   time.Time // when the server stored the transaction, live streams only
+	TxID       string    // identifies the event for the ordering check
+	Size       int       // serialized size of the message: protobuf bytes, or the JSON of an SSE event
+
+	// End is set on a final event that carries no transaction, sent when
+	// the server signalled the end of the stream.
+	End *StreamEnd
+
+	// Reconnect is how long the stream was disconnected before this event,
+	// the first after reopening it; zero otherwise.
+	Reconnect time.Duration
+}
+
+// messagesSentTrailer is the gRPC and Connect trailer in which servers
+// report how many transactions a stream sent. SSE streams report it in a
+// final "done" event instead.
+const messagesSentTrailer = "messages-sent"
+
+// StreamEnd compares the number of transactions a server reported sending
+// with the number the client received.
+type StreamEnd struct {
+	Sent     int64
+	Received int64
+}
+
+// Shortfall returns how many sent transactions were not received.
+func (e StreamEnd) Shortfall() int64 {
+	return e.Sent - e.Received
+}
+
+// parseStreamEnd builds the StreamEnd of a stream from the values of its
+// messages-sent trailer, nil if the server did not report a valid count.
+func parseStreamEnd(trailer []string, received int64) *StreamEnd {
+	if len(trailer) == 0 {
+		return nil
+	}
+	n, err := strconv.ParseInt(trailer[0], 10, 64)
+	if err != nil || n < 0 {
+		return nil
+	}
+	return &StreamEnd{Sent: n, Received: received}
+}
+
+// transactionEvent returns the event of a transaction received now, with
+// the server's send time if it carried one (sentAt nanoseconds since the
+// Unix epoch, 0 if not) and its timestamp parsed on live streams only, to
+// keep replayed streams from paying for it.
+func transactionEvent(txID, timestamp string, sentAt int64, live bool) StreamEvent {
+	event := StreamEvent{ReceivedAt: time.Now(), TxID: txID}
+	if sentAt != 0 {
+		event.SentAt = time.Unix(0, sentAt)
+	}
+	if live {
+		event.StoredAt, _ = time.Parse(time.RFC3339Nano, timestamp)
+	}
+	return event
+}
+
+// sendStreamEnd delivers the end-of-stream event if the server reported one.
+func sendStreamEnd(ctx context.Context, eventCh chan<- StreamEvent, end *StreamEnd) {
+	if end == nil {
+		return
+	}
+	select {
+	case eventCh <- StreamEvent{ReceivedAt: time.Now(), End: end}:
+	case <-ctx.Done():
+	}
+}
+
+// gRPCClient implements BenchmarkClient using gRPC.
+type gRPCClient struct {
+	conns    []*grpc.ClientConn // shared by the workers, empty when each call dials its own
+	addr     string
+	dialOpts []grpc.DialOption
+	*byteCounter
+}
+
+// NewGRPCClient creates a new gRPC benchmark client. Messages are compressed
+// with the named algorithm unless it is compression.None; the server replies
+// in kind. Each worker's calls and streams go over one of connOpts.GRPCConns
+// connections, assigned by worker index.
+func NewGRPCClient(addr, comp string, connOpts ConnOptions) (BenchmarkClient, error) {
+	opts, err := connOpts.Auth.DialOptions()
+	if err != nil {
+		return nil, fmt.Errorf("failed to configure authentication: %w", err)
+	}
+	opts = append(opts, grpc.WithUnaryInterceptor(grpcDBTime))
+	opts = append(opts, grpcCacheBusting(connOpts.CacheBust)...)
+	opts = append(opts, grpcMetadata(connOpts.Metadata)...)
+	bytes := new(byteCounter)
+	opts = append(opts, grpcTransfer(bytes)...)
+	if comp != compression.None {
+		opts = append(opts, grpc.WithDefaultCallOptions(grpc.UseCompressor(comp)))
+	}
+	if connOpts.GRPCKeepaliveTime > 0 {
+		opts = append(opts, grpc.WithKeepaliveParams(keepalive.ClientParameters{
+			Time:                connOpts.GRPCKeepaliveTime,
+			Timeout:             connOpts.GRPCKeepaliveTimeout,
+			PermitWithoutStream: connOpts.GRPCPermitWithoutStream,
+		}))
+	}
+
+	c := &gRPCClient{addr: addr, dialOpts: opts, byteCounter: bytes}
+	if connOpts.DisableKeepAlive {
+		return c, nil
+	}
+
+	for range max(connOpts.GRPCConns, 1) {
+		conn, err := grpc.NewClient(addr, opts...)
+		if err != nil {
+			c.Close()
+			return nil, fmt.Errorf("failed to connect to gRPC server: %w", err)
+		}
+		c.conns = append(c.conns, conn)
+	}
+	return c, nil
+}
+
+// callConn returns the connection for one call and a function to call once
+// it is done: the shared connection of the worker making it, or a fresh one
+// that is closed again.
+func (c *gRPCClient) callConn(ctx context.Context) (grpc.ClientConnInterface, func(), error) {
+	if len(c.conns) > 0 {
+		return c.conns[workerIndex(ctx)%len(c.conns)], func() {}, nil
+	}
+	conn, err := grpc.NewClient(c.addr, c.dialOpts...)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to connect to gRPC server: %w", err)
+	}
+	return conn, func() { conn.Close() }, nil
+}
+
+func (c *gRPCClient) GetBalance(ctx context.Context, accountID string) error {
+	_, err := c.getBalance(ctx, accountID)
+	return err
+}
+
+func (c *gRPCClient) GetBalanceUpdatedAt(ctx context.Context, accountID string) (time.Time, error) {
+	resp, err := c.getBalance(ctx, accountID)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return parseBalanceTimestamp(resp.Timestamp)
+}
+
+func (c *gRPCClient) getBalance(ctx context.Context, accountID string) (*protos.BalanceResponse, error) {
+	conn, done, err := c.callConn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer done()
+	return protos.NewBalanceServiceClient(conn).GetBalance(ctx, &protos.BalanceRequest{AccountId: accountID})
+}
+
+func (c *gRPCClient) GetBalances(ctx context.Context, accountIDs []string) error {
+	conn, done, err := c.callConn(ctx)
+	if err != nil {
+		return err
+	}
+	defer done()
+	_, err = protos.NewBalanceServiceClient(conn).GetBalances(ctx, &protos.BatchBalanceRequest{AccountIds: accountIDs})
+	return err
+}
+
+func (c *gRPCClient) Echo(ctx context.Context, size int) error {
+	conn, done, err := c.callConn(ctx)
+	if err != nil {
+		return err
+	}
+	defer done()
+	_, err = protos.NewEchoServiceClient(conn).Echo(ctx, &protos.EchoRequest{PayloadSize: int32(size)})
+	return err
+}
+
+func (c *gRPCClient) SubmitTransaction(ctx context.Context, from, to string, amount int64) error {
+	conn, done, err := c.callConn(ctx)
+	if err != nil {
+		return err
+	}
+	defer done()
+	_, err = protos.NewTransactionServiceClient(conn).SubmitTransaction(ctx, &protos.SubmitTransactionRequest{
+		FromAccount:   from,
+		ToAccount:     to,
+		AmountTinybar: amount,
+	})
+	return err
+}
+
+func (c *gRPCClient) StreamTransactions(ctx context.Context, rate int) (<-chan StreamEvent, <-chan error) {
+	return c.streamTransactions(ctx, &protos.StreamRequest{RateLimit: int32(rate)})
+}
+
+func (c *gRPCClient) StreamLiveTransactions(ctx context.Context) (<-chan StreamEvent, <-chan error) {
+	return c.streamTransactions(ctx, &protos.StreamRequest{Live: true})
+}
+
+func (c *gRPCClient) ResumeTransactions(ctx context.Context, rate int, afterTxID string) (<-chan StreamEvent, <-chan error) {
+	return c.streamTransactions(ctx, &protos.StreamRequest{RateLimit: int32(rate), AfterTxId: afterTxID})
+}
+
+func (c *gRPCClient) streamTransactions(ctx context.Context, req *protos.StreamRequest) (<-chan StreamEvent, <-chan error) {
+	eventCh := make(chan StreamEvent, 100)
+	errCh := make(chan error, 1)
+
+	go func() {
+		defer close(eventCh)
+		defer close(errCh)
+
+		conn, done, err := c.callConn(ctx)
+		if err != nil {
+			errCh <- err
+			return
+		}
+		defer done()
+
+		stream, err := protos.NewTransactionServiceClient(conn).StreamTransactions(ctx, req)
+		if err != nil {
+			errCh <- fmt.Errorf("failed to start stream: %w", err)
+			return
+		}
+
+		var received int64
+		for {
+			tx, err := stream.Recv()
+			if err == io.EOF {
+				sendStreamEnd(ctx, eventCh, parseStreamEnd(stream.Trailer().Get(messagesSentTrailer), received))
+				return
+			}
+			if err != nil {
+				if ctx.Err() != nil {
+					return
+				}
+				errCh <- fmt.Errorf("stream received error: %w", err)
+				return
+			}
+
+			event := transactionEvent(tx.GetTxId(), tx.GetTimestamp(), tx.GetSentAtUnixNano(), req.Live)
+			event.Size = proto.Size(tx)
+			select {
+			case eventCh <- event:
+				received++
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return eventCh, errCh
+}
+
+func (c *gRPCClient) Close() error {
+	var firstErr error
+	for _, conn := range c.conns {
+		if err := conn.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// REST encodings selectable with --rest-encoding.
+var RESTEncodings = []string{"json", "proto"}
+
+// REST JSON body shapes selectable with --rest-shape: the idiomatic bodies
+// of pkg/restapi, or parity bodies mirroring the protobuf messages (see
+// restapi.ShapeParam).
+var RESTShapes = []string{"idiomatic", restapi.ParityShape}
+
+// protobufContentType is the media type of protobuf-encoded REST bodies.
+// Streams of it are length-delimited messages.
+const protobufContentType = "application/x-protobuf"
+
+// httpClient implements BenchmarkClient using HTTP/REST.
+type httpClient struct {
+	client      *http.Client
+	baseURL     string
+	proto       bool            // protobuf instead of JSON bodies
+	parity      bool            // parity instead of idiomatic JSON bodies
+	stream      string          // framing of JSON streams, one of restapi.StreamFormats
+	json        jsoncodec.Codec // encodes and decodes idiomatic JSON bodies
+	compression string
+
+	disableKeepAlive bool
+	*byteCounter
+}
+
+// NewHTTPClient creates a new HTTP benchmark client. encoding selects JSON
+// ("json") or binary protobuf ("proto") request and response bodies, so the
+// cost of the encoding can be told from the cost of HTTP/1.1, shape one of
+// RESTShapes for JSON bodies, streamFormat one of restapi.StreamFormats for
+// JSON streams, and jsonEncoder the jsoncodec encoder of idiomatic JSON
+// bodies; parity bodies are encoded with protojson. Responses are requested
+// with the named Content-Encoding unless comp is compression.None.
+func NewHTTPClient(baseURL, encoding, shape, streamFormat, jsonEncoder, comp string, connOpts ConnOptions) (BenchmarkClient, error) {
+	if !slices.Contains(RESTEncodings, encoding) {
+		return nil, fmt.Errorf("unsupported REST encoding: %s", encoding)
+	}
+	if !slices.Contains(RESTShapes, shape) {
+		return nil, fmt.Errorf("unsupported REST shape: %s", shape)
+	}
+	if encoding == "proto" && shape == restapi.ParityShape {
+		return nil, fmt.Errorf("REST shape %s only applies to JSON bodies", shape)
+	}
+	if !slices.Contains(restapi.StreamFormats, streamFormat) {
+		return nil, fmt.Errorf("unsupported REST stream format: %s", streamFormat)
+	}
+	codec, err := jsoncodec.New(jsonEncoder)
+	if err != nil {
+		return nil, err
+	}
+
+	bytes := new(byteCounter)
+	transport := newTransport(connOpts, bytes)
+	// Compression is negotiated explicitly by get, so the transport's
+	// transparent gzip must not kick in for uncompressed runs
+	transport.DisableCompression = true
+	rt, err := authenticated(transport, connOpts)
+	if err != nil {
+		return nil, err
+	}
+
+	return &httpClient{
+		client: &http.Client{
+			Transport: withHeaders(withCacheBusting(countingPayloads(rt, bytes), connOpts.CacheBust), connOpts.Headers),
+			Timeout:   30 * time.Second,
+		},
+		baseURL:     strings.TrimSuffix(baseURL, "/"),
+		proto:       encoding == "proto",
+		parity:      shape == restapi.ParityShape,
+		stream:      streamFormat,
+		json:        codec,
+		compression: comp,
+
+		disableKeepAlive: connOpts.DisableKeepAlive,
+		byteCounter:      bytes,
+	}, nil
+}
+
+// get issues a GET request with the given Accept header (if any) and returns
+// the response with its body decompressed.
+func (c *httpClient) get(ctx context.Context, url, accept string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	if accept != "" {
+		req.Header.Set("Accept", accept)
+	}
+	return c.do(req)
+}
+
+// getUnary issues a GET request for a unary response in the client's
+// encoding.
+func (c *httpClient) getUnary(ctx context.Context, url string) (*http.Response, error) {
+	switch {
+	case c.proto:
+		return c.get(ctx, url, protobufContentType)
+	case c.parity:
+		return c.get(ctx, url, c.withShape("application/json"))
+	}
+	return c.get(ctx, url, "")
+}
+
+// withShape adds the client's body shape to mediaType if it is not the
+// idiomatic one.
+func (c *httpClient) withShape(mediaType string) string {
+	if c.parity {
+		return mime.FormatMediaType(mediaType, map[string]string{restapi.ShapeParam: restapi.ParityShape})
+	}
+	return mediaType
+}
+
+// messages reports whether bodies are decoded into protobuf messages rather
+// than the idiomatic types: binary protobuf or parity JSON.
+func (c *httpClient) messages() bool {
+	return c.proto || c.parity
+}
+
+// post issues a POST request with body, encoded as JSON or as msg in
+// protobuf or parity JSON, and returns the response with its body
+// decompressed. The request body is not compressed.
+func (c *httpClient) post(ctx context.Context, url string, body any, msg proto.Message) (*http.Response, error) {
+	contentType := c.withShape("application/json")
+	marshal := func() ([]byte, error) { return c.json.Marshal(body) }
+	switch {
+	case c.proto:
+		contentType = protobufContentType
+		marshal = func() ([]byte, error) { return proto.Marshal(msg) }
+	case c.parity:
+		marshal = func() ([]byte, error) { return protojson.Marshal(msg) }
+	}
+	data, err := marshal()
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode request: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", contentType)
+	if c.messages() {
+		req.Header.Set("Accept", contentType)
+	}
+	return c.do(req)
+}
+
+// decode decodes the response body into body if it is idiomatic JSON, or
+// into msg if the client speaks protobuf or parity JSON.
+func (c *httpClient) decode(resp *http.Response, body any, msg proto.Message) error {
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+	switch {
+	case c.proto:
+		err = proto.Unmarshal(data, msg)
+	case c.parity:
+		err = protojson.Unmarshal(data, msg)
+	default:
+		err = c.json.Unmarshal(data, body)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	return nil
+}
+
+// do sends req, negotiating the configured response compression, and
+// returns the response with its body decompressed.
+func (c *httpClient) do(req *http.Request) (*http.Response, error) {
+	if c.compression != compression.None {
+		req.Header.Set("Accept-Encoding", c.compression)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	recordDBTime(req.Context(), resp.Header.Values(servertiming.Header))
+
+	if enc := resp.Header.Get("Content-Encoding"); enc != "" {
+		body, err := compression.NewReader(enc, resp.Body)
+		if err != nil {
+			resp.Body.Close()
+			return nil, fmt.Errorf("failed to decompress %s response: %w", enc, err)
+		}
+		resp.Body = &decompressedBody{ReadCloser: body, raw: resp.Body}
+	}
+	return resp, nil
+}
+
+// decompressedBody closes both the decompressor and the underlying body.
+type decompressedBody struct {
+	io.ReadCloser
+	raw io.ReadCloser
+}
+
+func (b *decompressedBody) Close() error {
+	b.ReadCloser.Close()
+	return b.raw.Close()
+}
+
+func (c *httpClient) GetBalance(ctx context.Context, accountID string) error {
+	url := fmt.Sprintf("%s/api/v1/accounts/%s/balance", c.baseURL, accountID)
+	resp, err := c.getUnary(ctx, url)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	// Drain the body to allow connection reuse
+	io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode != http.StatusOK {
+		return &statusError{code: resp.StatusCode}
+	}
+
+	return nil
+}
+
+func (c *httpClient) GetBalanceUpdatedAt(ctx context.Context, accountID string) (time.Time, error) {
+	url := fmt.Sprintf("%s/api/v1/accounts/%s/balance", c.baseURL, accountID)
+	resp, err := c.getUnary(ctx, url)
+	if err != nil {
+		return time.Time{}, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		io.Copy(io.Discard, resp.Body)
+		return time.Time{}, &statusError{code: resp.StatusCode}
+	}
+
+	var body restapi.BalanceResponse
+	var msg protos.BalanceResponse
+	if err := c.decode(resp, &body, &msg); err != nil {
+		return time.Time{}, err
+	}
+	if c.messages() {
+		body.Timestamp = msg.Timestamp
+	}
+
+	return parseBalanceTimestamp(body.Timestamp)
+}
+
+func (c *httpClient) GetBalances(ctx context.Context, accountIDs []string) error {
+	url := fmt.Sprintf("%s/api/v1/balances?ids=%s", c.baseURL, strings.Join(accountIDs, ","))
+	resp, err := c.getUnary(ctx, url)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		io.Copy(io.Discard, resp.Body)
+		return &statusError{code: resp.StatusCode}
+	}
+
+	// Decode the balances, as the gRPC client decodes its reply
+	var body restapi.BatchBalanceResponse
+	return c.decode(resp, &body, &protos.BatchBalanceResponse{})
+}
+
+func (c *httpClient) Echo(ctx context.Context, size int) error {
+	url := fmt.Sprintf("%s/api/v1/echo?size=%d", c.baseURL, size)
+	resp, err := c.getUnary(ctx, url)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		io.Copy(io.Discard, resp.Body)
+		return &statusError{code: resp.StatusCode}
+	}
+
+	// Decode the payload so deserialization cost is measured, just as the
+	// gRPC client pays for protobuf decoding
+	var body restapi.EchoResponse
+	var msg protos.EchoResponse
+	if err := c.decode(resp, &body, &msg); err != nil {
+		return err
+	}
+	if c.messages() {
+		body.Payload = msg.Payload
+	}
+
+	if len(body.Payload) != size {
+		return fmt.Errorf("payload size mismatch: got %d bytes, want %d", len(body.Payload), size)
+	}
+	return nil
+}
+
+func (c *httpClient) SubmitTransaction(ctx context.Context, from, to string, amount int64) error {
+	url := fmt.Sprintf("%s/api/v1/transactions", c.baseURL)
+	resp, err := c.post(ctx, url, restapi.SubmitTransactionRequest{From: from, To: to, Amount: amount},
+		&protos.SubmitTransactionRequest{FromAccount: from, ToAccount: to, AmountTinybar: amount})
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated {
+		io.Copy(io.Discard, resp.Body)
+		return &statusError{code: resp.StatusCode}
+	}
+
+	// Decode the stored transaction, as the gRPC client decodes its reply
+	var body restapi.TransactionEvent
+	return c.decode(resp, &body, &protos.Transaction{})
+}
+
+func (c *httpClient) StreamTransactions(ctx context.Context, rate int) (<-chan StreamEvent, <-chan error) {
+	return c.ResumeTransactions(ctx, rate, "")
+}
+
+func (c *httpClient) StreamLiveTransactions(ctx context.Context) (<-chan StreamEvent, <-chan error) {
+	return c.streamTransactions(ctx, c.baseURL+"/api/v1/transactions/stream?live=true", "", true)
+}
+
+// ResumeTransactions sends the cursor as the Last-Event-ID header, as an
+// EventSource reconnecting to an SSE stream does.
+func (c *httpClient) ResumeTransactions(ctx context.Context, rate int, afterTxID string) (<-chan StreamEvent, <-chan error) {
+	url := fmt.Sprintf("%s/api/v1/transactions/stream", c.baseURL)
+	if rate > 0 {
+		url = fmt.Sprintf("%s?rate=%d", url, rate)
+	}
+	return c.streamTransactions(ctx, url, afterTxID, false)
+}
+
+// decodeEvent decodes the data of an SSE transaction event.
+func (c *httpClient) decodeEvent(data []byte) (restapi.TransactionEvent, error) {
+	var event restapi.TransactionEvent
+	if !c.parity {
+		err := c.json.Unmarshal(data, &event)
+		return event, err
+	}
+
+	var msg protos.Transaction
+	if err := protojson.Unmarshal(data, &msg); err != nil {
+		return event, err
+	}
+	return restapi.TransactionEvent{
+		TxID:      msg.TxId,
+		From:      msg.FromAccount,
+		To:        msg.ToAccount,
+		Amount:    msg.AmountTinybar,
+		Type:      msg.TxType,
+		Timestamp: msg.Timestamp,
+		SentAt:    msg.SentAtUnixNano,
+	}, nil
+}
+
+func (c *httpClient) streamTransactions(ctx context.Context, url, lastEventID string, live bool) (<-chan StreamEvent, <-chan error) {
+	eventCh := make(chan StreamEvent, 100)
+	errCh := make(chan error, 1)
+
+	go func() {
+		defer close(eventCh)
+		defer close(errCh)
+
+		accept := c.withShape(restapi.StreamContentType(c.stream))
+		if c.proto {
+			accept = protobufContentType
+		}
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		if err != nil {
+			errCh <- fmt.Errorf("failed to create request: %w", err)
+			return
+		}
+		req.Header.Set("Accept", accept)
+		if lastEventID != "" {
+			req.Header.Set("Last-Event-ID", lastEventID)
+		}
+		resp, err := c.do(req)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			errCh <- err
+			return
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			errCh <- &statusError{code: resp.StatusCode}
+			return
+		}
+
+		var read func(context.Context, *http.Response, chan<- StreamEvent, bool) error
+		switch {
+		case c.proto:
+			read = readProtoStream
+		case c.stream == restapi.StreamNDJSON:
+			read = c.readNDJSONStream
+		case c.stream == restapi.StreamChunkedArray:
+			read = c.readArrayStream
+		}
+		if read != nil {
+			if err := read(ctx, resp, eventCh, live); err != nil && ctx.Err() == nil {
+				errCh <- err
+			}
+			return
+		}
+
+		// SSE format: "event: transaction" or "event: done", then "data: {...}"
+		var eventType string
+		var received int64
+		scanner := bufio.NewScanner(resp.Body)
+		for scanner.Scan() {
+			line := scanner.Text()
+
+			switch {
+			case line == "":
+				eventType = ""
+			case strings.HasPrefix(line, "event: "):
+				eventType = strings.TrimPrefix(line, "event: ")
+			case strings.HasPrefix(line, "data: ") && eventType == "done":
+				var done restapi.StreamDone
+				if err := c.json.Unmarshal([]byte(strings.TrimPrefix(line, "data: ")), &done); err == nil {
+					sendStreamEnd(ctx, eventCh, &StreamEnd{Sent: done.Sent, Received: received})
+				}
+				return
+			case strings.HasPrefix(line, "data: "):
+				data := []byte(strings.TrimPrefix(line, "data: "))
+				tx, err := c.decodeEvent(data)
+				if err != nil {
+					continue
+				}
+
+				event := transactionEvent(tx.TxID, tx.Timestamp, tx.SentAt, live)
+				event.Size = len(data)
+				select {
+				case eventCh <- event:
+					received++
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+
+		if err := scanner.Err(); err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			errCh <- fmt.Errorf("scanner error: %w", err)
+		}
+	}()
+
+	return eventCh, errCh
+}
+
+// readProtoStream delivers the length-delimited transactions of a protobuf
+// stream, then its end as reported in the messages-sent trailer.
+func readProtoStream(ctx context.Context, resp *http.Response, eventCh chan<- StreamEvent, live bool) error {
+	r := bufio.NewReader(resp.Body)
+	var received int64
+	for {
+		var tx protos.Transaction
+		if err := protodelim.UnmarshalFrom(r, &tx); err != nil {
+			if err == io.EOF {
+				break
+			}
+			return fmt.Errorf("stream read error: %w", err)
+		}
+
+		event := transactionEvent(tx.TxId, tx.Timestamp, tx.SentAtUnixNano, live)
+		event.Size = proto.Size(&tx)
+		select {
+		case eventCh <- event:
+			received++
+		case <-ctx.Done():
+			return nil
+		}
+	}
+
+	// Trailers are read with the end of the body
+	sendStreamEnd(ctx, eventCh, parseStreamEnd(resp.Trailer.Values(messagesSentTrailer), received))
+	return nil
+}
+
+// readNDJSONStream delivers the transactions of an NDJSON stream, one per
+// line, then its end as reported in the messages-sent trailer.
+func (c *httpClient) readNDJSONStream(ctx context.Context, resp *http.Response, eventCh chan<- StreamEvent, live bool) error {
+	var received int64
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+		tx, err := c.decodeEvent(line)
+		if err != nil {
+			continue
+		}
+
+		event := transactionEvent(tx.TxID, tx.Timestamp, tx.SentAt, live)
+		event.Size = len(line)
+		select {
+		case eventCh <- event:
+			received++
+		case <-ctx.Done():
+			return nil
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("stream read error: %w", err)
+	}
+
+	sendStreamEnd(ctx, eventCh, parseStreamEnd(resp.Trailer.Values(messagesSentTrailer), received))
+	return nil
+}
+
+// readArrayStream delivers the transactions of a chunked JSON array stream
+// as its elements arrive, then its end as reported in the messages-sent
+// trailer. A stream that failed ends without closing the array.
+func (c *httpClient) readArrayStream(ctx context.Context, resp *http.Response, eventCh chan<- StreamEvent, live bool) error {
+	dec := json.NewDecoder(resp.Body)
+	if tok, err := dec.Token(); err != nil || tok != json.Delim('[') {
+		return fmt.Errorf("stream read error: not a JSON array")
+	}
+	var received int64
+	for dec.More() {
+		var data json.RawMessage
+		if err := dec.Decode(&data); err != nil {
+			return fmt.Errorf("stream read error: %w", err)
+		}
+		tx, err := c.decodeEvent(data)
+		if err != nil {
+			continue
+		}
+
+		event := transactionEvent(tx.TxID, tx.Timestamp, tx.SentAt, live)
+		event.Size = len(data)
+		select {
+		case eventCh <- event:
+			received++
+		case <-ctx.Done():
+			return nil
+		}
+	}
+	if _, err := dec.Token(); err != nil {
+		return fmt.Errorf("stream read error: %w", err)
+	}
+	// Trailers are read with the end of the body
+	io.Copy(io.Discard, resp.Body)
+
+	sendStreamEnd(ctx, eventCh, parseStreamEnd(resp.Trailer.Values(messagesSentTrailer), received))
+	return nil
+}
+
+func (c *httpClient) Close() error {
+	c.client.CloseIdleConnections()
+	return nil
+}
+
+// parseBalanceTimestamp parses the RFC 3339 balance update time returned by
+// both servers.
+func parseBalanceTimestamp(ts string) (time.Time, error) {
+	t, err := time.Parse(time.RFC3339, ts)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid balance timestamp %q: %w", ts, err)
+	}
+	return t, nil
+}
@@ -0,0 +1,438 @@
+package bench
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/kaldun-tech/grpc-rest-benchmark/pkg/auth"
+	"github.com/kaldun-tech/grpc-rest-benchmark/pkg/jsoncodec"
+	"github.com/kaldun-tech/grpc-rest-benchmark/pkg/protos"
+	"github.com/kaldun-tech/grpc-rest-benchmark/pkg/restapi"
+	"google.golang.org/protobuf/encoding/protodelim"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+// testSentAt is the send time test servers stamp on a streamed event.
+const testSentAt = 1_700_000_000_123_456_789
+
+func TestHTTPClient_StreamEnd(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		// Only the first event carries its send time
+		fmt.Fprintf(w, "event: transaction\ndata: {\"tx_id\":\"tx-1\",\"sent_at_unix_nano\":%d}\n\n", testSentAt)
+		fmt.Fprint(w, "event: transaction\ndata: {\"tx_id\":\"tx-2\"}\n\n")
+		fmt.Fprint(w, "event: done\ndata: {\"sent\":3}\n\n")
+	}))
+	defer srv.Close()
+
+	client, err := NewHTTPClient(srv.URL, "json", "idiomatic", restapi.StreamSSE, jsoncodec.Std, "none", ConnOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	eventCh, errCh := client.StreamTransactions(context.Background(), 0)
+	var ids []string
+	var sentAt []time.Time
+	var sizes []int
+	var end *StreamEnd
+	for event := range eventCh {
+		if event.End != nil {
+			end = event.End
+			continue
+		}
+		ids = append(ids, event.TxID)
+		sentAt = append(sentAt, event.SentAt)
+		sizes = append(sizes, event.Size)
+	}
+	if err := <-errCh; err != nil {
+		t.Fatalf("stream error = %v", err)
+	}
+
+	if len(ids) != 2 || ids[0] != "tx-1" || ids[1] != "tx-2" {
+		t.Errorf("received %v, want the two transactions", ids)
+	}
+	if len(sentAt) != 2 || sentAt[0].UnixNano() != testSentAt || !sentAt[1].IsZero() {
+		t.Errorf("send times = %v, want the first event's only", sentAt)
+	}
+	if len(sizes) != 2 || sizes[1] != len(`{"tx_id":"tx-2"}`) {
+		t.Errorf("sizes = %v, want the bytes of each event's JSON", sizes)
+	}
+	if end == nil || end.Sent != 3 || end.Received != 2 || end.Shortfall() != 1 {
+		t.Errorf("end = %+v, want 3 sent, 2 received", end)
+	}
+}
+
+func TestHTTPClient_StreamFormats(t *testing.T) {
+	tests := []struct {
+		format string
+		body   string
+	}{
+		{restapi.StreamNDJSON, "{\"tx_id\":\"tx-1\"}\n{\"tx_id\":\"tx-2\"}\n"},
+		{restapi.StreamChunkedArray, "[{\"tx_id\":\"tx-1\"},\n{\"tx_id\":\"tx-2\"}]\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if got, want := r.Header.Get("Accept"), restapi.StreamContentType(tt.format); got != want {
+					t.Errorf("Accept = %q, want %q", got, want)
+				}
+				w.Header().Set("Trailer", "Messages-Sent")
+				w.Header().Set("Content-Type", restapi.StreamContentType(tt.format))
+				fmt.Fprint(w, tt.body)
+				w.Header().Set("Messages-Sent", "3")
+			}))
+			defer srv.Close()
+
+			client, err := NewHTTPClient(srv.URL, "json", "idiomatic", tt.format, jsoncodec.Std, "none", ConnOptions{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer client.Close()
+
+			eventCh, errCh := client.StreamTransactions(context.Background(), 0)
+			var ids []string
+			var sizes []int
+			var end *StreamEnd
+			for event := range eventCh {
+				if event.End != nil {
+					end = event.End
+					continue
+				}
+				ids = append(ids, event.TxID)
+				sizes = append(sizes, event.Size)
+			}
+			if err := <-errCh; err != nil {
+				t.Fatalf("stream error = %v", err)
+			}
+
+			if len(ids) != 2 || ids[0] != "tx-1" || ids[1] != "tx-2" {
+				t.Errorf("received %v, want the two transactions", ids)
+			}
+			if len(sizes) != 2 || sizes[1] != len(`{"tx_id":"tx-2"}`) {
+				t.Errorf("sizes = %v, want the bytes of each transaction's JSON", sizes)
+			}
+			if end == nil || end.Sent != 3 || end.Received != 2 {
+				t.Errorf("end = %+v, want 3 sent, 2 received", end)
+			}
+		})
+	}
+}
+
+func TestHTTPClient_ResumeTransactions(t *testing.T) {
+	var lastEventID string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lastEventID = r.Header.Get("Last-Event-ID")
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, "event: transaction\nid: tx-8\ndata: {\"tx_id\":\"tx-8\"}\n\n")
+	}))
+	defer srv.Close()
+
+	client, err := NewHTTPClient(srv.URL, "json", "idiomatic", restapi.StreamSSE, jsoncodec.Std, "none", ConnOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	eventCh, errCh := client.(ResumableStreamClient).ResumeTransactions(context.Background(), 0, "tx-7")
+	var ids []string
+	for event := range eventCh {
+		ids = append(ids, event.TxID)
+	}
+	if err := <-errCh; err != nil {
+		t.Fatalf("stream error = %v", err)
+	}
+
+	if lastEventID != "tx-7" {
+		t.Errorf("Last-Event-ID = %q, want tx-7", lastEventID)
+	}
+	if len(ids) != 1 || ids[0] != "tx-8" {
+		t.Errorf("received %v, want tx-8", ids)
+	}
+}
+
+func TestHTTPClient_SubmitTransaction(t *testing.T) {
+	var got restapi.SubmitTransactionRequest
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/api/v1/transactions" {
+			http.NotFound(w, r)
+			return
+		}
+		json.NewDecoder(r.Body).Decode(&got)
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprint(w, `{"tx_id":"0.0.1001@1700000000.000000001"}`)
+	}))
+	defer srv.Close()
+
+	for _, encoder := range jsoncodec.Names {
+		got = restapi.SubmitTransactionRequest{}
+		client, err := NewHTTPClient(srv.URL, "json", "idiomatic", restapi.StreamSSE, encoder, "none", ConnOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if err := client.(WriteClient).SubmitTransaction(context.Background(), "0.0.1001", "0.0.1002", 500); err != nil {
+			t.Fatalf("SubmitTransaction() with %s error = %v", encoder, err)
+		}
+		want := restapi.SubmitTransactionRequest{From: "0.0.1001", To: "0.0.1002", Amount: 500}
+		if got != want {
+			t.Errorf("request body with %s = %+v, want %+v", encoder, got, want)
+		}
+		client.Close()
+	}
+
+	if _, err := NewHTTPClient(srv.URL, "json", "idiomatic", restapi.StreamSSE, "fastjson", "none", ConnOptions{}); err == nil {
+		t.Error("NewHTTPClient() with an unknown JSON encoder succeeded")
+	}
+}
+
+func TestHTTPClient_Protobuf(t *testing.T) {
+	var submitted protos.SubmitTransactionRequest
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Accept") != protobufContentType {
+			http.Error(w, "not acceptable", http.StatusNotAcceptable)
+			return
+		}
+		w.Header().Set("Content-Type", protobufContentType)
+		switch r.URL.Path {
+		case "/api/v1/accounts/0.0.1001/balance":
+			data, _ := proto.Marshal(&protos.BalanceResponse{AccountId: "0.0.1001", Timestamp: "2026-10-17T12:00:00Z"})
+			w.Write(data)
+		case "/api/v1/echo":
+			data, _ := proto.Marshal(&protos.EchoResponse{Payload: make([]byte, 64)})
+			w.Write(data)
+		case "/api/v1/transactions":
+			data, _ := io.ReadAll(r.Body)
+			proto.Unmarshal(data, &submitted)
+			w.WriteHeader(http.StatusCreated)
+			data, _ = proto.Marshal(&protos.Transaction{TxId: "tx-1"})
+			w.Write(data)
+		case "/api/v1/transactions/stream":
+			w.Header().Set("Trailer", messagesSentTrailer)
+			protodelim.MarshalTo(w, &protos.Transaction{TxId: "tx-1", SentAtUnixNano: testSentAt})
+			protodelim.MarshalTo(w, &protos.Transaction{TxId: "tx-2"})
+			w.Header().Set(messagesSentTrailer, "3")
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	c, err := NewHTTPClient(srv.URL, "proto", "idiomatic", restapi.StreamSSE, jsoncodec.Std, "none", ConnOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	client := c.(*httpClient)
+	ctx := context.Background()
+
+	if at, err := client.GetBalanceUpdatedAt(ctx, "0.0.1001"); err != nil || !at.Equal(time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)) {
+		t.Errorf("GetBalanceUpdatedAt() = %v, %v", at, err)
+	}
+	if err := client.Echo(ctx, 64); err != nil {
+		t.Errorf("Echo() error = %v", err)
+	}
+	if err := client.SubmitTransaction(ctx, "0.0.1001", "0.0.1002", 500); err != nil {
+		t.Errorf("SubmitTransaction() error = %v", err)
+	}
+	if submitted.FromAccount != "0.0.1001" || submitted.ToAccount != "0.0.1002" || submitted.AmountTinybar != 500 {
+		t.Errorf("request body = %v, want the submitted transaction", &submitted)
+	}
+
+	eventCh, errCh := client.StreamTransactions(ctx, 0)
+	var ids []string
+	var sentAt []time.Time
+	var end *StreamEnd
+	for event := range eventCh {
+		if event.End != nil {
+			end = event.End
+			continue
+		}
+		ids = append(ids, event.TxID)
+		sentAt = append(sentAt, event.SentAt)
+	}
+	if err := <-errCh; err != nil {
+		t.Fatalf("stream error = %v", err)
+	}
+	if len(ids) != 2 || ids[0] != "tx-1" || ids[1] != "tx-2" {
+		t.Errorf("received %v, want the two transactions", ids)
+	}
+	if len(sentAt) != 2 || sentAt[0].UnixNano() != testSentAt || !sentAt[1].IsZero() {
+		t.Errorf("send times = %v, want the first event's only", sentAt)
+	}
+	if end == nil || end.Sent != 3 || end.Received != 2 {
+		t.Errorf("end = %+v, want 3 sent, 2 received", end)
+	}
+
+	if _, err := NewHTTPClient(srv.URL, "xml", "idiomatic", restapi.StreamSSE, jsoncodec.Std, "none", ConnOptions{}); err == nil {
+		t.Error("NewHTTPClient() with an unknown encoding succeeded")
+	}
+}
+
+func TestHTTPClient_Parity(t *testing.T) {
+	var submitted protos.SubmitTransactionRequest
+	var submittedType string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, params, err := mime.ParseMediaType(r.Header.Get("Accept")); err != nil || params[restapi.ShapeParam] != restapi.ParityShape {
+			http.Error(w, "not acceptable", http.StatusNotAcceptable)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/api/v1/accounts/0.0.1001/balance":
+			data, _ := protojson.Marshal(&protos.BalanceResponse{AccountId: "0.0.1001", BalanceTinybar: 5, Timestamp: "2026-10-17T12:00:00Z"})
+			w.Write(data)
+		case "/api/v1/echo":
+			data, _ := protojson.Marshal(&protos.EchoResponse{Payload: make([]byte, 64)})
+			w.Write(data)
+		case "/api/v1/transactions":
+			submittedType = r.Header.Get("Content-Type")
+			data, _ := io.ReadAll(r.Body)
+			protojson.Unmarshal(data, &submitted)
+			w.WriteHeader(http.StatusCreated)
+			data, _ = protojson.Marshal(&protos.Transaction{TxId: "tx-1"})
+			w.Write(data)
+		case "/api/v1/transactions/stream":
+			w.Header().Set("Content-Type", "text/event-stream")
+			data, _ := protojson.Marshal(&protos.Transaction{TxId: "tx-1", SentAtUnixNano: testSentAt})
+			fmt.Fprintf(w, "event: transaction\nid: tx-1\ndata: %s\n\n", data)
+			fmt.Fprint(w, "event: done\ndata: {\"sent\":1}\n\n")
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	c, err := NewHTTPClient(srv.URL, "json", restapi.ParityShape, restapi.StreamSSE, jsoncodec.Std, "none", ConnOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	client := c.(*httpClient)
+	ctx := context.Background()
+
+	if at, err := client.GetBalanceUpdatedAt(ctx, "0.0.1001"); err != nil || !at.Equal(time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)) {
+		t.Errorf("GetBalanceUpdatedAt() = %v, %v", at, err)
+	}
+	if err := client.Echo(ctx, 64); err != nil {
+		t.Errorf("Echo() error = %v", err)
+	}
+	if err := client.SubmitTransaction(ctx, "0.0.1001", "0.0.1002", 500); err != nil {
+		t.Errorf("SubmitTransaction() error = %v", err)
+	}
+	if submitted.FromAccount != "0.0.1001" || submitted.ToAccount != "0.0.1002" || submitted.AmountTinybar != 500 {
+		t.Errorf("request body = %v, want the submitted transaction", &submitted)
+	}
+	if _, params, _ := mime.ParseMediaType(submittedType); params[restapi.ShapeParam] != restapi.ParityShape {
+		t.Errorf("request Content-Type = %q, want the parity shape", submittedType)
+	}
+
+	eventCh, errCh := client.StreamTransactions(ctx, 0)
+	var events []StreamEvent
+	for event := range eventCh {
+		events = append(events, event)
+	}
+	if err := <-errCh; err != nil {
+		t.Fatalf("stream error = %v", err)
+	}
+	if len(events) != 2 || events[0].TxID != "tx-1" || events[0].SentAt.UnixNano() != testSentAt || events[1].End == nil {
+		t.Errorf("received %+v, want tx-1 and the end", events)
+	}
+
+	if _, err := NewHTTPClient(srv.URL, "proto", restapi.ParityShape, restapi.StreamSSE, jsoncodec.Std, "none", ConnOptions{}); err == nil {
+		t.Error("NewHTTPClient() with parity protobuf bodies succeeded")
+	}
+}
+
+func TestParseStreamEnd(t *testing.T) {
+	if end := parseStreamEnd([]string{"5"}, 5); end == nil || end.Shortfall() != 0 {
+		t.Errorf("parseStreamEnd(5) = %+v, want no shortfall", end)
+	}
+	for _, trailer := range [][]string{nil, {"x"}, {"-1"}} {
+		if end := parseStreamEnd(trailer, 1); end != nil {
+			t.Errorf("parseStreamEnd(%q) = %+v, want nil", trailer, end)
+		}
+	}
+}
+
+func TestGRPCClient_WorkerConn(t *testing.T) {
+	client, err := NewGRPCClient("localhost:1", "none", ConnOptions{GRPCConns: 4})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+	c := client.(*gRPCClient)
+
+	// Each worker keeps to connection index mod 4, whatever else is called
+	for _, worker := range []int{5, 2, 5, 0, 5} {
+		conn, done, err := c.callConn(withWorker(context.Background(), worker))
+		if err != nil {
+			t.Fatal(err)
+		}
+		done()
+		if conn != c.conns[worker%4] {
+			t.Errorf("worker %d got another connection than %d", worker, worker%4)
+		}
+	}
+}
+
+func TestHTTPClient_DBTime(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Server-Timing", "db;dur=2.5")
+		fmt.Fprint(w, `{"account":"0.0.1"}`)
+	}))
+	defer srv.Close()
+
+	client, err := NewHTTPClient(srv.URL, "json", "idiomatic", restapi.StreamSSE, jsoncodec.Std, "none", ConnOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	ctx, db := withDBTime(context.Background())
+	if err := client.GetBalance(ctx, "0.0.1"); err != nil {
+		t.Fatalf("GetBalance() error = %v", err)
+	}
+	if !db.ok || db.d != 2500*time.Microsecond {
+		t.Errorf("recorded database time = %v, %v; want 2.5ms", db.d, db.ok)
+	}
+
+	// Requests outside withDBTime record nothing
+	if err := client.GetBalance(context.Background(), "0.0.1"); err != nil {
+		t.Fatalf("GetBalance() error = %v", err)
+	}
+}
+
+func TestHTTPClient_Auth(t *testing.T) {
+	cfg := auth.Config{Mode: auth.JWT, Secret: "secret"}
+	srv := httptest.NewServer(auth.New(cfg).Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"account":"0.0.1"}`)
+	})))
+	defer srv.Close()
+
+	for _, tc := range []struct {
+		cfg     auth.Config
+		wantErr bool
+	}{
+		{cfg, false},
+		{auth.Config{Mode: auth.JWT, Secret: "other"}, true},
+		{auth.Config{}, true},
+	} {
+		client, err := NewHTTPClient(srv.URL, "json", "idiomatic", restapi.StreamSSE, jsoncodec.Std, "none", ConnOptions{Auth: tc.cfg})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := client.GetBalance(context.Background(), "0.0.1"); (err != nil) != tc.wantErr {
+			t.Errorf("GetBalance() with %+v error = %v, want error %v", tc.cfg, err, tc.wantErr)
+		}
+		client.Close()
+	}
+}
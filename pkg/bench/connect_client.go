@@ -0,0 +1,188 @@
+package bench
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"connectrpc.com/connect"
+
+	"github.com/kaldun-tech/grpc-rest-benchmark/pkg/auth"
+	"github.com/kaldun-tech/grpc-rest-benchmark/pkg/compression"
+	"github.com/kaldun-tech/grpc-rest-benchmark/pkg/protos"
+	"github.com/kaldun-tech/grpc-rest-benchmark/pkg/protos/protosconnect"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+// Connect codecs selectable with --connect-encoding.
+var ConnectEncodings = []string{"proto", "json"}
+
+// connectClient implements BenchmarkClient using the Connect protocol.
+type connectClient struct {
+	httpClient *http.Client
+	baseURL    string
+	balance    protosconnect.BalanceServiceClient
+	txService  protosconnect.TransactionServiceClient
+	echo       protosconnect.EchoServiceClient
+	json       bool // messages are encoded as JSON, not binary protobuf
+
+	disableKeepAlive bool
+	*byteCounter
+}
+
+// NewConnectClient creates a new Connect benchmark client. encoding selects
+// the binary protobuf ("proto") or JSON ("json") codec and comp the message
+// compression. Requests use cleartext HTTP/2, matching the gRPC transport,
+// or HTTP/2 over TLS with mutual TLS.
+func NewConnectClient(baseURL, encoding, comp string, connOpts ConnOptions) (BenchmarkClient, error) {
+	protocols := new(http.Protocols)
+	if connOpts.Auth.Mode == auth.MTLS {
+		protocols.SetHTTP2(true)
+	} else {
+		protocols.SetUnencryptedHTTP2(true)
+	}
+
+	bytes := new(byteCounter)
+	transport := newTransport(connOpts, bytes)
+	transport.Protocols = protocols
+	rt, err := authenticated(transport, connOpts)
+	if err != nil {
+		return nil, err
+	}
+	rt = countingPayloads(rt, bytes)
+	if cacheBusting(connOpts.CacheBust) {
+		rt = withCacheBusting(rt, CacheBustHeader)
+	}
+	httpClient := &http.Client{Transport: withHeaders(rt, connOpts.Metadata)}
+
+	opts := append(compression.ConnectClientOptions(comp), connect.WithInterceptors(connectDBTime))
+	switch encoding {
+	case "proto":
+	case "json":
+		opts = append(opts, connect.WithProtoJSON())
+	default:
+		return nil, fmt.Errorf("unsupported connect encoding: %s", encoding)
+	}
+
+	baseURL = strings.TrimSuffix(baseURL, "/")
+	return &connectClient{
+		httpClient: httpClient,
+		baseURL:    baseURL,
+		balance:    protosconnect.NewBalanceServiceClient(httpClient, baseURL, opts...),
+		txService:  protosconnect.NewTransactionServiceClient(httpClient, baseURL, opts...),
+		echo:       protosconnect.NewEchoServiceClient(httpClient, baseURL, opts...),
+		json:       encoding == "json",
+
+		disableKeepAlive: connOpts.DisableKeepAlive,
+		byteCounter:      bytes,
+	}, nil
+}
+
+func (c *connectClient) GetBalance(ctx context.Context, accountID string) error {
+	_, err := c.balance.GetBalance(ctx, connect.NewRequest(&protos.BalanceRequest{AccountId: accountID}))
+	return err
+}
+
+func (c *connectClient) GetBalanceUpdatedAt(ctx context.Context, accountID string) (time.Time, error) {
+	resp, err := c.balance.GetBalance(ctx, connect.NewRequest(&protos.BalanceRequest{AccountId: accountID}))
+	if err != nil {
+		return time.Time{}, err
+	}
+	return parseBalanceTimestamp(resp.Msg.Timestamp)
+}
+
+func (c *connectClient) GetBalances(ctx context.Context, accountIDs []string) error {
+	_, err := c.balance.GetBalances(ctx, connect.NewRequest(&protos.BatchBalanceRequest{AccountIds: accountIDs}))
+	return err
+}
+
+func (c *connectClient) Echo(ctx context.Context, size int) error {
+	_, err := c.echo.Echo(ctx, connect.NewRequest(&protos.EchoRequest{PayloadSize: int32(size)}))
+	return err
+}
+
+func (c *connectClient) SubmitTransaction(ctx context.Context, from, to string, amount int64) error {
+	_, err := c.txService.SubmitTransaction(ctx, connect.NewRequest(&protos.SubmitTransactionRequest{
+		FromAccount:   from,
+		ToAccount:     to,
+		AmountTinybar: amount,
+	}))
+	return err
+}
+
+func (c *connectClient) StreamTransactions(ctx context.Context, rate int) (<-chan StreamEvent, <-chan error) {
+	return c.streamTransactions(ctx, &protos.StreamRequest{RateLimit: int32(rate)})
+}
+
+func (c *connectClient) StreamLiveTransactions(ctx context.Context) (<-chan StreamEvent, <-chan error) {
+	return c.streamTransactions(ctx, &protos.StreamRequest{Live: true})
+}
+
+func (c *connectClient) ResumeTransactions(ctx context.Context, rate int, afterTxID string) (<-chan StreamEvent, <-chan error) {
+	return c.streamTransactions(ctx, &protos.StreamRequest{RateLimit: int32(rate), AfterTxId: afterTxID})
+}
+
+func (c *connectClient) streamTransactions(ctx context.Context, req *protos.StreamRequest) (<-chan StreamEvent, <-chan error) {
+	eventCh := make(chan StreamEvent, 100)
+	errCh := make(chan error, 1)
+
+	go func() {
+		defer close(eventCh)
+		defer close(errCh)
+
+		stream, err := c.txService.StreamTransactions(ctx, connect.NewRequest(req))
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			errCh <- fmt.Errorf("failed to start stream: %w", err)
+			return
+		}
+		defer stream.Close()
+
+		var received int64
+		for stream.Receive() {
+			msg := stream.Msg()
+			event := transactionEvent(msg.GetTxId(), msg.GetTimestamp(), msg.GetSentAtUnixNano(), req.Live)
+			event.Size = c.messageSize(msg)
+			select {
+			case eventCh <- event:
+				received++
+			case <-ctx.Done():
+				return
+			}
+		}
+
+		if err := stream.Err(); err != nil {
+			if ctx.Err() == nil {
+				errCh <- fmt.Errorf("stream received error: %w", err)
+			}
+			return
+		}
+		sendStreamEnd(ctx, eventCh, parseStreamEnd(stream.ResponseTrailer().Values(messagesSentTrailer), received))
+	}()
+
+	return eventCh, errCh
+}
+
+// messageSize returns the serialized size of a received message. Connect
+// does not expose the bytes it decoded, so JSON messages are encoded again
+// to measure them.
+func (c *connectClient) messageSize(msg proto.Message) int {
+	if !c.json {
+		return proto.Size(msg)
+	}
+	b, err := protojson.Marshal(msg)
+	if err != nil {
+		return 0
+	}
+	return len(b)
+}
+
+func (c *connectClient) Close() error {
+	c.httpClient.CloseIdleConnections()
+	return nil
+}
@@ -0,0 +1,60 @@
+package bench
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// CostModel prices the resources a run used, so protocols can be compared
+// by cost per request as well as by latency. CPU is the benchmark client's
+// own CPU time and network the bytes counted on the wire
+// (NetStats.WireBytes); the servers are not measured. Prices are in a single
+// currency of the user's choosing.
+type CostModel struct {
+	CPUHour float64 // price of one CPU-hour
+	GB      float64 // price of one GB (10^9 bytes) transferred
+}
+
+// Enabled reports whether any price is set.
+func (m CostModel) Enabled() bool {
+	return m.CPUHour > 0 || m.GB > 0
+}
+
+// String describes the prices as stored with the run, e.g.
+// "cpu=0.04/h net=0.09/GB".
+func (m CostModel) String() string {
+	return "cpu=" + strconv.FormatFloat(m.CPUHour, 'g', -1, 64) + "/h net=" + strconv.FormatFloat(m.GB, 'g', -1, 64) + "/GB"
+}
+
+// Validate checks that prices are not negative.
+func (m CostModel) Validate() error {
+	if m.CPUHour < 0 || m.GB < 0 {
+		return fmt.Errorf("cost prices must not be negative")
+	}
+	return nil
+}
+
+// Estimate prices the measured resources. ok is false when the model is
+// disabled or a priced resource was not measured.
+func (m CostModel) Estimate(stats *ResourceStats) (cost float64, ok bool) {
+	if !m.Enabled() || stats == nil {
+		return 0, false
+	}
+	cost = stats.CPUSeconds / 3600 * m.CPUHour
+	if m.GB > 0 {
+		if stats.Net == nil {
+			return 0, false
+		}
+		cost += float64(stats.Net.WireBytes()) / 1e9 * m.GB
+	}
+	return cost, true
+}
+
+// costPerMillion scales the cost of a run to one million requests, or
+// returns false if the run made none.
+func costPerMillion(cost float64, requests int64) (float64, bool) {
+	if requests <= 0 {
+		return 0, false
+	}
+	return cost / float64(requests) * 1e6, true
+}
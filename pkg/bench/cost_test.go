@@ -0,0 +1,48 @@
+package bench
+
+import (
+	"math"
+	"testing"
+)
+
+func TestCostModel_Estimate(t *testing.T) {
+	stats := &ResourceStats{CPUSeconds: 1800, Net: &NetStats{BytesSent: 2e9, BytesRecv: 1e9}}
+
+	tests := []struct {
+		name   string
+		model  CostModel
+		stats  *ResourceStats
+		want   float64
+		wantOK bool
+	}{
+		{"disabled", CostModel{}, stats, 0, false},
+		{"cpu only", CostModel{CPUHour: 0.04}, stats, 0.02, true},
+		{"cpu and network", CostModel{CPUHour: 0.04, GB: 0.09}, stats, 0.02 + 0.27, true},
+		{"loopback counts bytes once", CostModel{GB: 1}, &ResourceStats{Net: &NetStats{Loopback: true, BytesSent: 1e9, BytesRecv: 1e9}}, 1, true},
+		{"network not measured", CostModel{GB: 0.09}, &ResourceStats{CPUSeconds: 10}, 0, false},
+		{"no resource stats", CostModel{CPUHour: 0.04}, nil, 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := tt.model.Estimate(tt.stats)
+			if ok != tt.wantOK || math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("Estimate() = %v, %v; want %v, %v", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestCostModel_String(t *testing.T) {
+	if got, want := (CostModel{CPUHour: 0.04, GB: 0.09}).String(), "cpu=0.04/h net=0.09/GB"; got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+}
+
+func TestCostPerMillion(t *testing.T) {
+	if got, ok := costPerMillion(0.5, 250_000); !ok || got != 2 {
+		t.Errorf("costPerMillion(0.5, 250000) = %v, %v; want 2, true", got, ok)
+	}
+	if _, ok := costPerMillion(0.5, 0); ok {
+		t.Error("costPerMillion() with no requests should not be ok")
+	}
+}
@@ -0,0 +1,65 @@
+package bench
+
+import (
+	"context"
+	"time"
+
+	"connectrpc.com/connect"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/kaldun-tech/grpc-rest-benchmark/pkg/servertiming"
+)
+
+// dbTime receives the database time a server reported for one request in
+// its Server-Timing header or trailer.
+type dbTime struct {
+	d  time.Duration
+	ok bool
+}
+
+type dbTimeKey struct{}
+
+// withDBTime returns a context in which clients record the database time
+// the server reports for the request.
+func withDBTime(ctx context.Context) (context.Context, *dbTime) {
+	t := new(dbTime)
+	return context.WithValue(ctx, dbTimeKey{}, t), t
+}
+
+// recordDBTime records the database time in the Server-Timing values on the
+// request's dbTime, if it has one and the values report it.
+func recordDBTime(ctx context.Context, values []string) {
+	t, ok := ctx.Value(dbTimeKey{}).(*dbTime)
+	if !ok {
+		return
+	}
+	if d, ok := servertiming.Parse(values); ok {
+		t.d, t.ok = d, true
+	}
+}
+
+// grpcDBTime is a gRPC client interceptor recording the database time from
+// the server-timing trailer of unary calls.
+func grpcDBTime(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+	if _, ok := ctx.Value(dbTimeKey{}).(*dbTime); !ok {
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+	var trailer metadata.MD
+	err := invoker(ctx, method, req, reply, cc, append(opts, grpc.Trailer(&trailer))...)
+	recordDBTime(ctx, trailer.Get(servertiming.Trailer))
+	return err
+}
+
+// connectDBTime is a Connect client interceptor recording the database time
+// of unary calls from the Server-Timing header (Connect) or trailer
+// (gRPC-Web).
+var connectDBTime = connect.UnaryInterceptorFunc(func(next connect.UnaryFunc) connect.UnaryFunc {
+	return func(ctx context.Context, req connect.AnyRequest) (connect.AnyResponse, error) {
+		resp, err := next(ctx, req)
+		if err == nil {
+			recordDBTime(ctx, append(resp.Header().Values(servertiming.Header), resp.Trailer().Values(servertiming.Header)...))
+		}
+		return resp, err
+	}
+})
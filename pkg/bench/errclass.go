@@ -0,0 +1,116 @@
+package bench
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"syscall"
+
+	"connectrpc.com/connect"
+	"google.golang.org/grpc/status"
+
+	"github.com/kaldun-tech/grpc-rest-benchmark/pkg/graphqlapi"
+	"github.com/kaldun-tech/grpc-rest-benchmark/pkg/jsonrpcapi"
+)
+
+// Error types failed samples are stored with, so results show the
+// composition of errors rather than individual messages. RPC failures not
+// covered below are stored as "grpc_" plus the status code, e.g.
+// "grpc_unavailable", and HTTP status failures as "http_4xx" or "http_5xx".
+// GraphQL errors are stored as "graphql_" plus their code, e.g.
+// "graphql_not_found", and JSON-RPC errors as "jsonrpc_" plus the name of
+// their code, e.g. "jsonrpc_method_not_found". Requests the server
+// rejected with 429 Too Many Requests or RESOURCE_EXHAUSTED are
+// "rate_limited" whatever the protocol.
+const (
+	errorTypeTimeout           = "timeout"
+	errorTypeCanceled          = "canceled"
+	errorTypeConnectionRefused = "connection_refused"
+	errorTypeRateLimited       = "rate_limited"
+	errorTypeOther             = "other"
+)
+
+// statusError is returned by the REST client when the server responds with
+// an unexpected HTTP status.
+type statusError struct {
+	code int
+}
+
+func (e *statusError) Error() string {
+	return fmt.Sprintf("unexpected status: %d", e.code)
+}
+
+// workerError is the error of a sample measured by a remote worker, which
+// classified it before sending it.
+type workerError struct {
+	errType string
+	msg     string
+}
+
+func (e *workerError) Error() string {
+	return e.msg
+}
+
+// classifyError returns the error type of a failed request, or "" for nil.
+// gRPC, Connect and gRPC-Web failures are classified by status code, REST
+// failures by HTTP status class.
+func classifyError(err error) string {
+	if err == nil {
+		return ""
+	}
+
+	var workerErr *workerError
+	var statusErr *statusError
+	var connectErr *connect.Error
+	var graphQLErr *graphqlapi.Error
+	var jsonRPCErr *jsonrpcapi.Error
+	var netErr net.Error
+	switch {
+	case errors.As(err, &workerErr):
+		return workerErr.errType
+	case errors.Is(err, context.DeadlineExceeded):
+		return errorTypeTimeout
+	case errors.Is(err, context.Canceled):
+		return errorTypeCanceled
+	case errors.Is(err, syscall.ECONNREFUSED):
+		return errorTypeConnectionRefused
+	case errors.As(err, &statusErr) && statusErr.code == http.StatusTooManyRequests:
+		return errorTypeRateLimited
+	case errors.As(err, &statusErr):
+		return fmt.Sprintf("http_%dxx", statusErr.code/100)
+	case errors.As(err, &connectErr):
+		return classifyCode(connectErr.Code(), connectErr.Message())
+	case errors.As(err, &graphQLErr):
+		return "graphql_" + strings.ToLower(cmp.Or(graphQLErr.Code(), "error"))
+	case errors.As(err, &jsonRPCErr):
+		return "jsonrpc_" + jsonrpcapi.CodeName(jsonRPCErr.Code)
+	case errors.As(err, &netErr) && netErr.Timeout():
+		return errorTypeTimeout
+	}
+	if s, ok := status.FromError(err); ok {
+		// connect.Code shares the gRPC code values
+		return classifyCode(connect.Code(s.Code()), s.Message())
+	}
+	return errorTypeOther
+}
+
+// classifyCode returns the error type of an RPC that failed with code.
+// gRPC reports dial failures as Unavailable, so the message tells refused
+// connections apart.
+func classifyCode(code connect.Code, message string) string {
+	switch {
+	case code == connect.CodeDeadlineExceeded:
+		return errorTypeTimeout
+	case code == connect.CodeCanceled:
+		return errorTypeCanceled
+	case code == connect.CodeResourceExhausted:
+		return errorTypeRateLimited
+	case code == connect.CodeUnavailable && strings.Contains(message, "connection refused"):
+		return errorTypeConnectionRefused
+	}
+	return "grpc_" + code.String()
+}
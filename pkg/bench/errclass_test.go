@@ -0,0 +1,121 @@
+package bench
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"syscall"
+	"testing"
+	"time"
+
+	"connectrpc.com/connect"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/kaldun-tech/grpc-rest-benchmark/pkg/graphqlapi"
+	"github.com/kaldun-tech/grpc-rest-benchmark/pkg/jsoncodec"
+	"github.com/kaldun-tech/grpc-rest-benchmark/pkg/jsonrpcapi"
+	"github.com/kaldun-tech/grpc-rest-benchmark/pkg/restapi"
+)
+
+func TestClassifyError(t *testing.T) {
+	refused := &net.OpError{Op: "dial", Net: "tcp", Err: os.NewSyscallError("connect", syscall.ECONNREFUSED)}
+	tests := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{"nil", nil, ""},
+		{"context deadline", fmt.Errorf("get: %w", context.DeadlineExceeded), "timeout"},
+		{"context canceled", context.Canceled, "canceled"},
+		{"connection refused", fmt.Errorf("get: %w", refused), "connection_refused"},
+		{"http 503", &statusError{code: http.StatusServiceUnavailable}, "http_5xx"},
+		{"http 404", &statusError{code: http.StatusNotFound}, "http_4xx"},
+		{"http 429", &statusError{code: http.StatusTooManyRequests}, "rate_limited"},
+		{"grpc deadline", status.Error(codes.DeadlineExceeded, "context deadline exceeded"), "timeout"},
+		{"grpc refused", status.Error(codes.Unavailable, "connection error: dial tcp: connect: connection refused"), "connection_refused"},
+		{"grpc unavailable", status.Error(codes.Unavailable, "server shutting down"), "grpc_unavailable"},
+		{"grpc not found", status.Error(codes.NotFound, "account not found"), "grpc_not_found"},
+		{"connect resource exhausted", connect.NewError(connect.CodeResourceExhausted, errors.New("too many requests")), "rate_limited"},
+		{"grpc resource exhausted", status.Error(codes.ResourceExhausted, "rate limit exceeded"), "rate_limited"},
+		{"graphql not found", fmt.Errorf("query: %w", graphqlapi.NewError(graphqlapi.CodeNotFound, "account not found")), "graphql_not_found"},
+		{"graphql without code", &graphqlapi.Error{Message: "boom"}, "graphql_error"},
+		{"jsonrpc method not found", jsonrpcapi.NewError(jsonrpcapi.CodeMethodNotFound, "no such method"), "jsonrpc_method_not_found"},
+		{"jsonrpc server error", &jsonrpcapi.Error{Code: -32050, Message: "busy"}, "jsonrpc_server_error"},
+		{"other", errors.New("payload size mismatch"), "other"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := classifyError(tt.err); got != tt.want {
+				t.Errorf("classifyError(%v) = %q, want %q", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRunner_RequestTimeout(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(time.Second):
+		}
+	}))
+	defer srv.Close()
+
+	client, err := NewHTTPClient(srv.URL, "json", "idiomatic", restapi.StreamSSE, jsoncodec.Std, "none", ConnOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	runner := NewRunner(client, []string{"0.0.1001"}, 1, 0)
+	runner.SetRequestTimeout(20 * time.Millisecond)
+	s := runner.issue(context.Background(), runner.balanceRequest)
+
+	if s.Success || classifyError(s.Error) != "timeout" {
+		t.Errorf("sample = success %v, error %v; want a timeout", s.Success, s.Error)
+	}
+	if s.Latency > 500*time.Millisecond {
+		t.Errorf("latency = %v, want the request cut off at the timeout", s.Latency)
+	}
+}
+
+func TestRunner_Backoff(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer srv.Close()
+
+	client, err := NewHTTPClient(srv.URL, "json", "idiomatic", restapi.StreamSSE, jsoncodec.Std, "none", ConnOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	requests := func(backoff bool) int {
+		runner := NewRunner(client, []string{"0.0.1001"}, 1, 0)
+		runner.SetBackoff(backoff)
+		ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+		defer cancel()
+		go runner.RunBalance(ctx)
+
+		// The request in flight when the run ends fails as a timeout
+		n := 0
+		for s := range runner.Results() {
+			if classifyError(s.Error) == "rate_limited" {
+				n++
+			}
+		}
+		return n
+	}
+
+	without, with := requests(false), requests(true)
+	if with >= without || with > 20 {
+		t.Errorf("requests = %d with backoff, %d without; want backoff to hold them back", with, without)
+	}
+}
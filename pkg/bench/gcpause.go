@@ -0,0 +1,102 @@
+package bench
+
+import (
+	"context"
+	"runtime/debug"
+	"runtime/metrics"
+	"slices"
+	"sort"
+	"time"
+)
+
+// gcPollInterval is how often the GC pauses of this process are read
+// during a run. The runtime keeps the last 256, so a poll misses some only
+// if more cycles complete between two polls.
+const gcPollInterval = 10 * time.Millisecond
+
+// gcCyclesMetric counts completed GC cycles, cheap enough to read every
+// poll.
+const gcCyclesMetric = "/gc/cycles/total:gc-cycles"
+
+// GCPause is the time the garbage collector of the benchmark client
+// stopped the world in one GC cycle. The runtime reports a cycle's end and
+// total pause, so Start is approximate when the cycle paused twice.
+type GCPause struct {
+	Start time.Time
+	End   time.Time
+}
+
+// watchGCPauses records the GC pauses of this process that end from now
+// until stop is called, which returns them in order. runtime/metrics tells
+// when a cycle completed, and debug.ReadGCStats, which does not stop the
+// world, when its pauses ended and how long they lasted.
+func watchGCPauses(ctx context.Context) (stop func() []GCPause) {
+	ctx, cancel := context.WithCancel(ctx)
+	done := make(chan []GCPause, 1)
+	go func() {
+		var pauses []GCPause
+		var stats debug.GCStats
+		cycles := []metrics.Sample{{Name: gcCyclesMetric}}
+		counted := func() bool { return cycles[0].Value.Kind() == metrics.KindUint64 }
+		metrics.Read(cycles)
+		var seen uint64
+		if counted() {
+			seen = cycles[0].Value.Uint64()
+		}
+		since := time.Now()
+
+		read := func() {
+			metrics.Read(cycles)
+			if counted() {
+				n := cycles[0].Value.Uint64()
+				if n == seen {
+					return
+				}
+				seen = n
+			}
+			debug.ReadGCStats(&stats)
+			// Most recent first
+			first := len(pauses)
+			for i, end := range stats.PauseEnd {
+				if !end.After(since) {
+					break
+				}
+				pauses = append(pauses, GCPause{Start: end.Add(-stats.Pause[i]), End: end})
+			}
+			if len(pauses) > first {
+				since = pauses[first].End
+				slices.Reverse(pauses[first:])
+			}
+		}
+
+		ticker := time.NewTicker(gcPollInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				read()
+			case <-ctx.Done():
+				read()
+				done <- pauses
+				return
+			}
+		}
+	}()
+	return func() []GCPause {
+		cancel()
+		return <-done
+	}
+}
+
+// overlapsGCPause reports whether the request or stream event of s was in
+// flight during one of pauses, which must be in order. Unary requests start
+// at their timestamp, stream events arrive at theirs.
+func overlapsGCPause(s Sample, pauses []GCPause) bool {
+	start, end := s.Timestamp, s.Timestamp.Add(s.Latency)
+	if s.Subscriber > 0 {
+		start, end = s.Timestamp.Add(-s.Latency), s.Timestamp
+	}
+	// The first pause ending after the request starts
+	i := sort.Search(len(pauses), func(i int) bool { return pauses[i].End.After(start) })
+	return i < len(pauses) && pauses[i].Start.Before(end)
+}
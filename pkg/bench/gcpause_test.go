@@ -0,0 +1,85 @@
+package bench
+
+import (
+	"context"
+	"runtime"
+	"testing"
+	"time"
+)
+
+func TestOverlapsGCPause(t *testing.T) {
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	pause := func(from, to time.Duration) GCPause {
+		return GCPause{Start: start.Add(from), End: start.Add(to)}
+	}
+	pauses := []GCPause{pause(10*time.Millisecond, 11*time.Millisecond), pause(50*time.Millisecond, 52*time.Millisecond)}
+
+	tests := []struct {
+		name string
+		s    Sample
+		want bool
+	}{
+		{"before", Sample{Timestamp: start, Latency: 5 * time.Millisecond}, false},
+		{"spans first", Sample{Timestamp: start.Add(8 * time.Millisecond), Latency: 5 * time.Millisecond}, true},
+		{"between", Sample{Timestamp: start.Add(20 * time.Millisecond), Latency: 10 * time.Millisecond}, false},
+		{"ends in second", Sample{Timestamp: start.Add(45 * time.Millisecond), Latency: 6 * time.Millisecond}, true},
+		{"after", Sample{Timestamp: start.Add(60 * time.Millisecond), Latency: time.Millisecond}, false},
+		// Stream events are stamped on arrival
+		{"event after pause", Sample{Timestamp: start.Add(53 * time.Millisecond), Latency: 2 * time.Millisecond, Subscriber: 1}, true},
+		{"event before pause", Sample{Timestamp: start.Add(9 * time.Millisecond), Latency: 2 * time.Millisecond, Subscriber: 1}, false},
+	}
+	for _, tt := range tests {
+		if got := overlapsGCPause(tt.s, pauses); got != tt.want {
+			t.Errorf("%s: overlapsGCPause() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+	if overlapsGCPause(Sample{Timestamp: start, Latency: time.Second}, nil) {
+		t.Error("overlapsGCPause() without pauses = true")
+	}
+}
+
+func TestResults_GCTail(t *testing.T) {
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	r := NewResults()
+	r.SetStartTime(start)
+	if _, _, ok := r.GCPauses(); ok {
+		t.Error("GCPauses() ok before SetGCPauses")
+	}
+	// 200 fast requests, 10ms apart and clear of the pause, and two slow
+	// ones: one in flight during the pause and one not
+	for i := range 200 {
+		r.Add(Sample{Timestamp: start.Add(time.Duration(i) * 10 * time.Millisecond), Latency: time.Millisecond, Success: true})
+	}
+	r.Add(Sample{Timestamp: start.Add(500 * time.Millisecond), Latency: 50 * time.Millisecond, Success: true})
+	r.Add(Sample{Timestamp: start.Add(1500 * time.Millisecond), Latency: 50 * time.Millisecond, Success: true})
+	r.SetEndTime(start.Add(2 * time.Second))
+	r.SetGCPauses([]GCPause{{Start: start.Add(522 * time.Millisecond), End: start.Add(528 * time.Millisecond)}})
+
+	if n, total, ok := r.GCPauses(); !ok || n != 1 || total != 6*time.Millisecond {
+		t.Errorf("GCPauses() = %d, %v, %v; want 1 pause of 6ms", n, total, ok)
+	}
+	if paused, tail, ok := r.GCTail(); !ok || paused != 1 || tail != 3 {
+		t.Errorf("GCTail() = %d of %d, %v; want 1 of the 3 slowest", paused, tail, ok)
+	}
+}
+
+func TestWatchGCPauses(t *testing.T) {
+	stop := watchGCPauses(context.Background())
+	before := time.Now()
+	runtime.GC()
+	time.Sleep(3 * gcPollInterval)
+	runtime.GC()
+	pauses := stop()
+
+	if len(pauses) < 2 {
+		t.Fatalf("watchGCPauses() saw %d pauses, want at least 2", len(pauses))
+	}
+	for i, p := range pauses {
+		if p.End.Before(before) || p.End.Before(p.Start) {
+			t.Errorf("pause %d = %v..%v, before the watch started at %v", i, p.Start, p.End, before)
+		}
+		if i > 0 && p.End.Before(pauses[i-1].End) {
+			t.Errorf("pause %d ends before pause %d", i, i-1)
+		}
+	}
+}
@@ -0,0 +1,240 @@
+package bench
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/kaldun-tech/grpc-rest-benchmark/pkg/graphqlapi"
+)
+
+// Operations of the GraphQL client. They select every field of the REST
+// bodies, so the payloads of the two match.
+const (
+	graphQLBalanceQuery  = `query Balance($accountId: ID!) { balance(accountId: $accountId) { accountId balanceTinybar timestamp } }`
+	graphQLBalancesQuery = `query Balances($ids: [ID!]!) { balances(ids: $ids) { accountId balanceTinybar timestamp } }`
+
+	graphQLTransactionsSubscription = `subscription Transactions($rateLimit: Int, $after: ID, $live: Boolean) {` +
+		` transactions(rateLimit: $rateLimit, after: $after, live: $live)` +
+		` { txId fromAccount toAccount amountTinybar txType timestamp sentAtUnixNano } }`
+)
+
+// graphQLClient implements BenchmarkClient with the REST server's GraphQL
+// endpoint, over the HTTP/1.1 transport of the REST client.
+type graphQLClient struct {
+	http *httpClient // sends the requests with the REST client's compression, and preconnects
+	url  string
+	*byteCounter
+}
+
+// NewGraphQLClient creates a benchmark client that queries balances and
+// subscribes to transactions with GraphQL. Responses are requested with the
+// named Content-Encoding unless comp is compression.None; requests carry
+// the REST headers of connOpts.
+func NewGraphQLClient(baseURL, comp string, connOpts ConnOptions) (BenchmarkClient, error) {
+	bytes := new(byteCounter)
+	transport := newTransport(connOpts, bytes)
+	transport.DisableCompression = true
+	rt, err := authenticated(transport, connOpts)
+	if err != nil {
+		return nil, err
+	}
+
+	baseURL = strings.TrimSuffix(baseURL, "/")
+	return &graphQLClient{
+		http: &httpClient{
+			client: &http.Client{
+				Transport: withHeaders(withCacheBusting(countingPayloads(rt, bytes), connOpts.CacheBust), connOpts.Headers),
+				Timeout:   30 * time.Second,
+			},
+			baseURL:          baseURL,
+			compression:      comp,
+			disableKeepAlive: connOpts.DisableKeepAlive,
+		},
+		url:         baseURL + "/api/v1/graphql",
+		byteCounter: bytes,
+	}, nil
+}
+
+// post sends a GraphQL request accepting the given media type.
+func (c *graphQLClient) post(ctx context.Context, accept, query string, vars map[string]any) (*http.Response, error) {
+	data, err := json.Marshal(graphqlapi.Request{Query: query, Variables: vars})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode request: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url, bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", accept)
+	return c.http.do(req)
+}
+
+// graphQLQuery executes a query and returns its data. The first error of
+// the response is returned as a *graphqlapi.Error.
+func graphQLQuery[T any](ctx context.Context, c *graphQLClient, query string, vars map[string]any) (T, error) {
+	var resp graphqlapi.Response[T]
+	httpResp, err := c.post(ctx, "application/json", query, vars)
+	if err != nil {
+		return resp.Data, err
+	}
+	defer httpResp.Body.Close()
+
+	if httpResp.StatusCode != http.StatusOK {
+		io.Copy(io.Discard, httpResp.Body)
+		return resp.Data, &statusError{code: httpResp.StatusCode}
+	}
+	body, err := io.ReadAll(httpResp.Body)
+	if err != nil {
+		return resp.Data, fmt.Errorf("failed to read response: %w", err)
+	}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return resp.Data, fmt.Errorf("failed to decode response: %w", err)
+	}
+	if len(resp.Errors) > 0 {
+		return resp.Data, &resp.Errors[0]
+	}
+	return resp.Data, nil
+}
+
+func (c *graphQLClient) GetBalance(ctx context.Context, accountID string) error {
+	_, err := graphQLQuery[graphqlapi.BalanceData](ctx, c, graphQLBalanceQuery, map[string]any{"accountId": accountID})
+	return err
+}
+
+func (c *graphQLClient) GetBalanceUpdatedAt(ctx context.Context, accountID string) (time.Time, error) {
+	data, err := graphQLQuery[graphqlapi.BalanceData](ctx, c, graphQLBalanceQuery, map[string]any{"accountId": accountID})
+	if err != nil {
+		return time.Time{}, err
+	}
+	if data.Balance == nil {
+		return time.Time{}, fmt.Errorf("no balance returned for %s", accountID)
+	}
+	return parseBalanceTimestamp(data.Balance.Timestamp)
+}
+
+func (c *graphQLClient) GetBalances(ctx context.Context, accountIDs []string) error {
+	_, err := graphQLQuery[graphqlapi.BalancesData](ctx, c, graphQLBalancesQuery, map[string]any{"ids": accountIDs})
+	return err
+}
+
+func (c *graphQLClient) StreamTransactions(ctx context.Context, rate int) (<-chan StreamEvent, <-chan error) {
+	return c.ResumeTransactions(ctx, rate, "")
+}
+
+func (c *graphQLClient) StreamLiveTransactions(ctx context.Context) (<-chan StreamEvent, <-chan error) {
+	return c.subscribe(ctx, map[string]any{"live": true}, true)
+}
+
+func (c *graphQLClient) ResumeTransactions(ctx context.Context, rate int, afterTxID string) (<-chan StreamEvent, <-chan error) {
+	vars := map[string]any{}
+	if rate > 0 {
+		vars["rateLimit"] = rate
+	}
+	if afterTxID != "" {
+		vars["after"] = afterTxID
+	}
+	return c.subscribe(ctx, vars, false)
+}
+
+// subscribe opens the transactions subscription and delivers its "next"
+// events, then its end as reported in the messages-sent trailer once the
+// server completes it.
+func (c *graphQLClient) subscribe(ctx context.Context, vars map[string]any, live bool) (<-chan StreamEvent, <-chan error) {
+	eventCh := make(chan StreamEvent, 100)
+	errCh := make(chan error, 1)
+
+	go func() {
+		defer close(eventCh)
+		defer close(errCh)
+
+		resp, err := c.post(ctx, "text/event-stream", graphQLTransactionsSubscription, vars)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			errCh <- err
+			return
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			errCh <- &statusError{code: resp.StatusCode}
+			return
+		}
+		// A request the server rejected is answered with JSON errors
+		if !strings.HasPrefix(resp.Header.Get("Content-Type"), "text/event-stream") {
+			var body graphqlapi.Response[*struct{}]
+			if err := json.NewDecoder(resp.Body).Decode(&body); err == nil && len(body.Errors) > 0 {
+				errCh <- &body.Errors[0]
+				return
+			}
+			errCh <- fmt.Errorf("unexpected subscription response: %s", resp.Header.Get("Content-Type"))
+			return
+		}
+
+		var eventType string
+		var received int64
+		scanner := bufio.NewScanner(resp.Body)
+		for scanner.Scan() {
+			line := scanner.Text()
+
+			switch {
+			case line == "":
+				eventType = ""
+			case strings.HasPrefix(line, "event: "):
+				eventType = strings.TrimPrefix(line, "event: ")
+			case strings.HasPrefix(line, "data:") && eventType == "complete":
+				// Trailers are read with the end of the body
+				io.Copy(io.Discard, resp.Body)
+				sendStreamEnd(ctx, eventCh, parseStreamEnd(resp.Trailer.Values(messagesSentTrailer), received))
+				return
+			case strings.HasPrefix(line, "data: ") && eventType == "next":
+				data := []byte(strings.TrimPrefix(line, "data: "))
+				var next graphqlapi.Response[graphqlapi.TransactionsData]
+				if err := json.Unmarshal(data, &next); err != nil {
+					continue
+				}
+				if len(next.Errors) > 0 {
+					if ctx.Err() == nil {
+						errCh <- &next.Errors[0]
+					}
+					return
+				}
+				tx := next.Data.Transactions
+				if tx == nil {
+					continue
+				}
+
+				event := transactionEvent(tx.TxID, tx.Timestamp, tx.SentAtUnixNano, live)
+				event.Size = len(data)
+				select {
+				case eventCh <- event:
+					received++
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+
+		if err := scanner.Err(); err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			errCh <- fmt.Errorf("scanner error: %w", err)
+		}
+	}()
+
+	return eventCh, errCh
+}
+
+func (c *graphQLClient) Close() error {
+	return c.http.Close()
+}
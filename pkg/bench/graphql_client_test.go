@@ -0,0 +1,128 @@
+package bench
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/kaldun-tech/grpc-rest-benchmark/pkg/graphqlapi"
+)
+
+// graphQLTestServer answers the GraphQL client's operations from two
+// transactions and a single account, 0.0.1.
+func graphQLTestServer(t *testing.T) *httptest.Server {
+	schema, err := graphqlapi.NewSchema(graphqlapi.Resolvers{
+		Balance: func(ctx context.Context, accountID string) (*graphqlapi.Balance, error) {
+			if accountID != "0.0.1" {
+				return nil, graphqlapi.NewError(graphqlapi.CodeNotFound, "account %s not found", accountID)
+			}
+			return &graphqlapi.Balance{AccountID: accountID, BalanceTinybar: 7, Timestamp: "2026-10-18T12:00:00Z"}, nil
+		},
+		Balances: func(ctx context.Context, ids []string) ([]*graphqlapi.Balance, error) {
+			return []*graphqlapi.Balance{{AccountID: "0.0.1"}}, nil
+		},
+		Transactions: func(ctx context.Context, root any, args graphqlapi.TransactionsArgs) (chan any, error) {
+			events := make(chan any, 2)
+			for i := range 2 {
+				events <- &graphqlapi.Transaction{TxID: fmt.Sprintf("tx%d", i), SentAtUnixNano: 1}
+			}
+			close(events)
+			return events, nil
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req graphqlapi.Request
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("request body: %v", err)
+			return
+		}
+		op, gqlErr := graphqlapi.Parse(schema, &req)
+		if gqlErr != nil {
+			t.Errorf("Parse(%q) error = %v", req.Query, gqlErr)
+			return
+		}
+
+		if op.Type == "subscription" {
+			w.Header().Set("Content-Type", "text/event-stream")
+			w.Header().Set("Trailer", "Messages-Sent")
+			for result := range op.Subscribe(r.Context(), nil) {
+				fmt.Fprintf(w, "event: next\ndata: %s\n\n", graphqlapi.Encode(result))
+			}
+			fmt.Fprint(w, "event: complete\ndata:\n\n")
+			w.Header().Set("Messages-Sent", "3")
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(graphqlapi.Encode(op.Execute(r.Context())))
+	}))
+}
+
+func TestGraphQLClient_Queries(t *testing.T) {
+	srv := graphQLTestServer(t)
+	defer srv.Close()
+
+	client, err := NewGraphQLClient(srv.URL, "none", ConnOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	if err := client.GetBalance(context.Background(), "0.0.1"); err != nil {
+		t.Errorf("GetBalance() error = %v", err)
+	}
+	updated, err := client.(BalanceTimestampClient).GetBalanceUpdatedAt(context.Background(), "0.0.1")
+	if err != nil || updated.Year() != 2026 {
+		t.Errorf("GetBalanceUpdatedAt() = %v, %v, want the balance timestamp", updated, err)
+	}
+	if err := client.(BatchBalanceClient).GetBalances(context.Background(), []string{"0.0.1"}); err != nil {
+		t.Errorf("GetBalances() error = %v", err)
+	}
+
+	// Errors of the response fail the request, classified by their code
+	err = client.GetBalance(context.Background(), "0.0.2")
+	if got := classifyError(err); got != "graphql_not_found" {
+		t.Errorf("GetBalance() of a missing account = %v, classified %q, want graphql_not_found", err, got)
+	}
+}
+
+func TestGraphQLClient_Subscription(t *testing.T) {
+	srv := graphQLTestServer(t)
+	defer srv.Close()
+
+	client, err := NewGraphQLClient(srv.URL, "none", ConnOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	eventCh, errCh := client.StreamTransactions(context.Background(), 10)
+	var txIDs []string
+	var end *StreamEnd
+	for event := range eventCh {
+		if event.End != nil {
+			end = event.End
+			continue
+		}
+		if event.SentAt.IsZero() || event.Size == 0 {
+			t.Errorf("event %s has sent time %v and size %d, want both", event.TxID, event.SentAt, event.Size)
+		}
+		txIDs = append(txIDs, event.TxID)
+	}
+	if err := <-errCh; err != nil {
+		t.Fatalf("stream error = %v", err)
+	}
+
+	if len(txIDs) != 2 || txIDs[0] != "tx0" {
+		t.Errorf("received %q, want tx0 and tx1", txIDs)
+	}
+	if end == nil || end.Sent != 3 || end.Shortfall() != 1 {
+		t.Errorf("stream end = %+v, want 3 sent and 1 missing", end)
+	}
+}
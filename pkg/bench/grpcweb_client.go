@@ -0,0 +1,45 @@
+package bench
+
+import (
+	"net/http"
+	"strings"
+
+	"connectrpc.com/connect"
+
+	"github.com/kaldun-tech/grpc-rest-benchmark/pkg/protos/protosconnect"
+)
+
+// NewGRPCWebClient creates a benchmark client that speaks gRPC-Web, the
+// protocol browsers use to reach gRPC services through Envoy or a grpc-web
+// proxy. Requests use HTTP/1.1 and binary protobuf framing, as a browser
+// without TLS would, or HTTP/1.1 over TLS with mutual TLS.
+func NewGRPCWebClient(baseURL string, connOpts ConnOptions) (BenchmarkClient, error) {
+	bytes := new(byteCounter)
+	rt, err := authenticated(newTransport(connOpts, bytes), connOpts)
+	if err != nil {
+		return nil, err
+	}
+	rt = countingPayloads(rt, bytes)
+	if cacheBusting(connOpts.CacheBust) {
+		rt = withCacheBusting(rt, CacheBustHeader)
+	}
+	httpClient := &http.Client{Transport: withHeaders(rt, connOpts.Metadata)}
+
+	opts := []connect.ClientOption{
+		connect.WithGRPCWeb(),
+		connect.WithAcceptCompression("gzip", nil, nil),
+		connect.WithInterceptors(connectDBTime),
+	}
+
+	baseURL = strings.TrimSuffix(baseURL, "/")
+	return &connectClient{
+		httpClient: httpClient,
+		baseURL:    baseURL,
+		balance:    protosconnect.NewBalanceServiceClient(httpClient, baseURL, opts...),
+		txService:  protosconnect.NewTransactionServiceClient(httpClient, baseURL, opts...),
+		echo:       protosconnect.NewEchoServiceClient(httpClient, baseURL, opts...),
+
+		disableKeepAlive: connOpts.DisableKeepAlive,
+		byteCounter:      bytes,
+	}, nil
+}
@@ -0,0 +1,129 @@
+package bench
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"regexp"
+	"slices"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// headerName matches HTTP header field names (RFC 9110 tokens).
+var headerName = regexp.MustCompile("^[!#$%&'*+.^_`|~0-9A-Za-z-]+$")
+
+// metadataKey matches gRPC metadata keys, which are lowercase.
+var metadataKey = regexp.MustCompile(`^[0-9a-z_.-]+$`)
+
+// reservedHeaders are set by the clients themselves and cannot be injected.
+var reservedHeaders = []string{"Accept", "Accept-Encoding", "Connection", "Content-Encoding", "Content-Length", "Content-Type", "Host", "Te", "Transfer-Encoding"}
+
+// ParseHeaders parses --header name=value pairs into the headers added to
+// every REST request. A name given more than once is sent with each value.
+func ParseHeaders(pairs []string) (http.Header, error) {
+	if len(pairs) == 0 {
+		return nil, nil
+	}
+	h := make(http.Header, len(pairs))
+	for _, pair := range pairs {
+		name, value, ok := strings.Cut(pair, "=")
+		if !ok || !headerName.MatchString(name) || strings.ContainsAny(value, "\r\n\x00") {
+			return nil, fmt.Errorf("invalid header: %q (must be name=value)", pair)
+		}
+		name = http.CanonicalHeaderKey(name)
+		if slices.Contains(reservedHeaders, name) {
+			return nil, fmt.Errorf("header %s is set by the client and cannot be injected", name)
+		}
+		h.Add(name, value)
+	}
+	return h, nil
+}
+
+// ParseMetadata parses --metadata key=value pairs into the metadata added to
+// every gRPC, Connect and gRPC-Web call. Keys are lowercased.
+func ParseMetadata(pairs []string) (metadata.MD, error) {
+	if len(pairs) == 0 {
+		return nil, nil
+	}
+	md := make(metadata.MD, len(pairs))
+	for _, pair := range pairs {
+		key, value, ok := strings.Cut(pair, "=")
+		key = strings.ToLower(key)
+		if !ok || !metadataKey.MatchString(key) || strings.ContainsAny(value, "\r\n\x00") {
+			return nil, fmt.Errorf("invalid metadata: %q (must be key=value)", pair)
+		}
+		if strings.HasPrefix(key, "grpc-") || slices.Contains(reservedHeaders, http.CanonicalHeaderKey(key)) {
+			return nil, fmt.Errorf("metadata %s is reserved and cannot be injected", key)
+		}
+		md.Append(key, value)
+	}
+	return md, nil
+}
+
+// InjectedBytes returns the bytes that injected headers or metadata add to
+// every request: the length of each name and value, before HTTP/1.1
+// framing or HTTP/2 header compression.
+func InjectedBytes(h map[string][]string) int {
+	var n int
+	for name, values := range h {
+		for _, v := range values {
+			n += len(name) + len(v)
+		}
+	}
+	return n
+}
+
+// headerTransport adds injected headers to every HTTP request.
+type headerTransport struct {
+	next    http.RoundTripper
+	headers http.Header
+}
+
+// withHeaders returns rt adding headers to every request, or rt itself if
+// there are none.
+func withHeaders(rt http.RoundTripper, headers map[string][]string) http.RoundTripper {
+	if len(headers) == 0 {
+		return rt
+	}
+	h := make(http.Header, len(headers))
+	for name, values := range headers {
+		for _, v := range values {
+			h.Add(name, v)
+		}
+	}
+	return &headerTransport{next: rt, headers: h}
+}
+
+func (t *headerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	// A RoundTripper must not modify the caller's request
+	req = req.Clone(req.Context())
+	for name, values := range t.headers {
+		req.Header[name] = append(req.Header[name], values...)
+	}
+	return t.next.RoundTrip(req)
+}
+
+// grpcMetadata returns the dial options adding md to every call, none if it
+// is empty.
+func grpcMetadata(md metadata.MD) []grpc.DialOption {
+	if len(md) == 0 {
+		return nil
+	}
+	var kv []string
+	for key, values := range md {
+		for _, v := range values {
+			kv = append(kv, key, v)
+		}
+	}
+	return []grpc.DialOption{
+		grpc.WithChainUnaryInterceptor(func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+			return invoker(metadata.AppendToOutgoingContext(ctx, kv...), method, req, reply, cc, opts...)
+		}),
+		grpc.WithChainStreamInterceptor(func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+			return streamer(metadata.AppendToOutgoingContext(ctx, kv...), desc, cc, method, opts...)
+		}),
+	}
+}
@@ -0,0 +1,84 @@
+package bench
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+
+	"github.com/kaldun-tech/grpc-rest-benchmark/pkg/jsoncodec"
+	"github.com/kaldun-tech/grpc-rest-benchmark/pkg/restapi"
+)
+
+func TestParseHeaders(t *testing.T) {
+	h, err := ParseHeaders([]string{"traceparent=00-abc-01", "X-Trace=a", "x-trace=b=c"})
+	if err != nil {
+		t.Fatalf("ParseHeaders() error = %v", err)
+	}
+	if got := h.Values("X-Trace"); !slices.Equal(got, []string{"a", "b=c"}) {
+		t.Errorf("X-Trace = %q, want both values", got)
+	}
+	if got := h.Get("Traceparent"); got != "00-abc-01" {
+		t.Errorf("Traceparent = %q", got)
+	}
+
+	for _, pair := range []string{"noequals", "bad name=v", "X-Ok=line\nbreak", "content-type=text/plain", "Host=example.com"} {
+		if _, err := ParseHeaders([]string{pair}); err == nil {
+			t.Errorf("ParseHeaders(%q) succeeded", pair)
+		}
+	}
+}
+
+func TestParseMetadata(t *testing.T) {
+	md, err := ParseMetadata([]string{"X-Request-Id=42", "baggage=k=v"})
+	if err != nil {
+		t.Fatalf("ParseMetadata() error = %v", err)
+	}
+	if got := md.Get("x-request-id"); !slices.Equal(got, []string{"42"}) {
+		t.Errorf("x-request-id = %q, want the lowercased key", got)
+	}
+
+	for _, pair := range []string{"grpc-timeout=1s", "te=trailers", "bad key=v", "=v"} {
+		if _, err := ParseMetadata([]string{pair}); err == nil {
+			t.Errorf("ParseMetadata(%q) succeeded", pair)
+		}
+	}
+}
+
+func TestInjectedBytes(t *testing.T) {
+	h := map[string][]string{"traceparent": {"0123456789"}, "x-b": {"1", "22"}}
+	if got, want := InjectedBytes(h), 11+10+3+1+3+2; got != want {
+		t.Errorf("InjectedBytes() = %d, want %d", got, want)
+	}
+	if got := InjectedBytes(nil); got != 0 {
+		t.Errorf("InjectedBytes(nil) = %d, want 0", got)
+	}
+}
+
+func TestHTTPClient_Headers(t *testing.T) {
+	var got http.Header
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header.Clone()
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"account_id":"0.0.1","balance":1}`))
+	}))
+	defer srv.Close()
+
+	headers, err := ParseHeaders([]string{"Traceparent=00-abc-01", "X-Trace=a", "X-Trace=b"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	client, err := NewHTTPClient(srv.URL, "json", "idiomatic", restapi.StreamSSE, jsoncodec.Std, "none", ConnOptions{Headers: headers})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+	if err := client.GetBalance(context.Background(), "0.0.1"); err != nil {
+		t.Fatalf("GetBalance() error = %v", err)
+	}
+
+	if got.Get("Traceparent") != "00-abc-01" || !slices.Equal(got.Values("X-Trace"), []string{"a", "b"}) {
+		t.Errorf("server got headers %v, want the injected ones", got)
+	}
+}
@@ -0,0 +1,268 @@
+package bench
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/kaldun-tech/grpc-rest-benchmark/pkg/jsoncodec"
+	"github.com/kaldun-tech/grpc-rest-benchmark/pkg/jsonrpcapi"
+	"github.com/kaldun-tech/grpc-rest-benchmark/pkg/restapi"
+)
+
+// jsonRPCClient implements BenchmarkClient with the REST server's JSON-RPC
+// 2.0 endpoint, over the HTTP/1.1 transport of the REST client.
+type jsonRPCClient struct {
+	http   *httpClient // sends the requests with the REST client's compression and JSON encoder, and preconnects
+	url    string
+	nextID atomic.Int64
+	*byteCounter
+}
+
+// NewJSONRPCClient creates a benchmark client that calls the JSON-RPC
+// methods of the REST server, encoding and decoding their JSON with the
+// named jsoncodec encoder. Responses are requested with the named
+// Content-Encoding unless comp is compression.None; requests carry the REST
+// headers of connOpts.
+func NewJSONRPCClient(baseURL, jsonEncoder, comp string, connOpts ConnOptions) (BenchmarkClient, error) {
+	codec, err := jsoncodec.New(jsonEncoder)
+	if err != nil {
+		return nil, err
+	}
+	bytes := new(byteCounter)
+	transport := newTransport(connOpts, bytes)
+	transport.DisableCompression = true
+	rt, err := authenticated(transport, connOpts)
+	if err != nil {
+		return nil, err
+	}
+
+	baseURL = strings.TrimSuffix(baseURL, "/")
+	return &jsonRPCClient{
+		http: &httpClient{
+			client: &http.Client{
+				Transport: withHeaders(withCacheBusting(countingPayloads(rt, bytes), connOpts.CacheBust), connOpts.Headers),
+				Timeout:   30 * time.Second,
+			},
+			baseURL:          baseURL,
+			json:             codec,
+			compression:      comp,
+			disableKeepAlive: connOpts.DisableKeepAlive,
+		},
+		url:         baseURL + "/api/v1/jsonrpc",
+		byteCounter: bytes,
+	}, nil
+}
+
+// post sends a request for method accepting the given media type, and
+// returns the response with the request's ID.
+func (c *jsonRPCClient) post(ctx context.Context, accept, method string, params any) (*http.Response, string, error) {
+	paramData, err := c.http.json.Marshal(params)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to encode params: %w", err)
+	}
+	id := strconv.FormatInt(c.nextID.Add(1), 10)
+	data, err := c.http.json.Marshal(jsonrpcapi.Request{
+		JSONRPC: jsonrpcapi.Version,
+		Method:  method,
+		Params:  paramData,
+		ID:      json.RawMessage(id),
+	})
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to encode request: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url, bytes.NewReader(data))
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", accept)
+	resp, err := c.http.do(req)
+	return resp, id, err
+}
+
+// decodeResult decodes the result of a response to the request with the
+// given ID into result. The response's error is returned as a
+// *jsonrpcapi.Error.
+func (c *jsonRPCClient) decodeResult(data []byte, id string, result any) error {
+	var resp jsonrpcapi.Response
+	if err := c.http.json.Unmarshal(data, &resp); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	if resp.Error != nil {
+		return resp.Error
+	}
+	if string(resp.ID) != id {
+		return fmt.Errorf("response id %s does not match request id %s", resp.ID, id)
+	}
+	if err := c.http.json.Unmarshal(resp.Result, result); err != nil {
+		return fmt.Errorf("failed to decode result: %w", err)
+	}
+	return nil
+}
+
+// jsonRPCCall calls a unary method and returns its result.
+func jsonRPCCall[T any](ctx context.Context, c *jsonRPCClient, method string, params any) (T, error) {
+	var result T
+	resp, id, err := c.post(ctx, "application/json", method, params)
+	if err != nil {
+		return result, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		io.Copy(io.Discard, resp.Body)
+		return result, &statusError{code: resp.StatusCode}
+	}
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return result, fmt.Errorf("failed to read response: %w", err)
+	}
+	return result, c.decodeResult(data, id, &result)
+}
+
+func (c *jsonRPCClient) GetBalance(ctx context.Context, accountID string) error {
+	_, err := jsonRPCCall[restapi.BalanceResponse](ctx, c, jsonrpcapi.MethodGetBalance, jsonrpcapi.BalanceParams{Account: accountID})
+	return err
+}
+
+func (c *jsonRPCClient) GetBalanceUpdatedAt(ctx context.Context, accountID string) (time.Time, error) {
+	balance, err := jsonRPCCall[restapi.BalanceResponse](ctx, c, jsonrpcapi.MethodGetBalance, jsonrpcapi.BalanceParams{Account: accountID})
+	if err != nil {
+		return time.Time{}, err
+	}
+	return parseBalanceTimestamp(balance.Timestamp)
+}
+
+func (c *jsonRPCClient) GetBalances(ctx context.Context, accountIDs []string) error {
+	_, err := jsonRPCCall[restapi.BatchBalanceResponse](ctx, c, jsonrpcapi.MethodGetBalances, jsonrpcapi.BalancesParams{IDs: accountIDs})
+	return err
+}
+
+func (c *jsonRPCClient) Echo(ctx context.Context, size int) error {
+	echo, err := jsonRPCCall[restapi.EchoResponse](ctx, c, jsonrpcapi.MethodEcho, jsonrpcapi.EchoParams{Size: size})
+	if err != nil {
+		return err
+	}
+	if len(echo.Payload) != size {
+		return fmt.Errorf("payload size mismatch: got %d bytes, want %d", len(echo.Payload), size)
+	}
+	return nil
+}
+
+func (c *jsonRPCClient) SubmitTransaction(ctx context.Context, from, to string, amount int64) error {
+	_, err := jsonRPCCall[restapi.TransactionEvent](ctx, c, jsonrpcapi.MethodSubmitTransaction,
+		restapi.SubmitTransactionRequest{From: from, To: to, Amount: amount})
+	return err
+}
+
+func (c *jsonRPCClient) StreamTransactions(ctx context.Context, rate int) (<-chan StreamEvent, <-chan error) {
+	return c.ResumeTransactions(ctx, rate, "")
+}
+
+func (c *jsonRPCClient) StreamLiveTransactions(ctx context.Context) (<-chan StreamEvent, <-chan error) {
+	return c.stream(ctx, jsonrpcapi.StreamParams{Live: true})
+}
+
+func (c *jsonRPCClient) ResumeTransactions(ctx context.Context, rate int, afterTxID string) (<-chan StreamEvent, <-chan error) {
+	return c.stream(ctx, jsonrpcapi.StreamParams{Rate: rate, After: afterTxID})
+}
+
+// stream calls streamTransactions and delivers its transaction
+// notifications, then its end as reported in the final response.
+func (c *jsonRPCClient) stream(ctx context.Context, params jsonrpcapi.StreamParams) (<-chan StreamEvent, <-chan error) {
+	eventCh := make(chan StreamEvent, 100)
+	errCh := make(chan error, 1)
+
+	go func() {
+		defer close(eventCh)
+		defer close(errCh)
+
+		resp, id, err := c.post(ctx, jsonrpcapi.StreamContentType, jsonrpcapi.MethodStreamTransactions, params)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			errCh <- err
+			return
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			errCh <- &statusError{code: resp.StatusCode}
+			return
+		}
+		// A request the server rejected is answered with a single response
+		if mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type")); mediaType != jsonrpcapi.StreamContentType {
+			data, err := io.ReadAll(resp.Body)
+			if err == nil {
+				err = c.decodeResult(data, id, new(restapi.StreamDone))
+			}
+			if err == nil {
+				err = fmt.Errorf("unexpected stream response: %s", mediaType)
+			}
+			errCh <- err
+			return
+		}
+
+		var received int64
+		scanner := bufio.NewScanner(resp.Body)
+		for scanner.Scan() {
+			line := scanner.Bytes()
+			if len(line) == 0 {
+				continue
+			}
+			var msg jsonrpcapi.Request
+			if err := c.http.json.Unmarshal(line, &msg); err != nil {
+				continue
+			}
+			if msg.Method != jsonrpcapi.NotificationTransaction {
+				// The response ends the stream
+				var done restapi.StreamDone
+				if err := c.decodeResult(line, id, &done); err != nil {
+					if ctx.Err() == nil {
+						errCh <- err
+					}
+					return
+				}
+				sendStreamEnd(ctx, eventCh, &StreamEnd{Sent: done.Sent, Received: received})
+				return
+			}
+
+			var tx restapi.TransactionEvent
+			if err := c.http.json.Unmarshal(msg.Params, &tx); err != nil {
+				continue
+			}
+			event := transactionEvent(tx.TxID, tx.Timestamp, tx.SentAt, params.Live)
+			event.Size = len(line)
+			select {
+			case eventCh <- event:
+				received++
+			case <-ctx.Done():
+				return
+			}
+		}
+
+		if err := scanner.Err(); err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			errCh <- fmt.Errorf("scanner error: %w", err)
+		}
+	}()
+
+	return eventCh, errCh
+}
+
+func (c *jsonRPCClient) Close() error {
+	return c.http.Close()
+}
@@ -0,0 +1,126 @@
+package bench
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/kaldun-tech/grpc-rest-benchmark/pkg/jsonrpcapi"
+	"github.com/kaldun-tech/grpc-rest-benchmark/pkg/restapi"
+)
+
+// jsonRPCTestServer answers the JSON-RPC client's methods from two
+// transactions and a single account, 0.0.1.
+func jsonRPCTestServer(t *testing.T) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req jsonrpcapi.Request
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("request body: %v", err)
+			return
+		}
+		if err := req.Validate(); err != nil {
+			t.Errorf("Validate(%+v) error = %v", req, err)
+			return
+		}
+
+		resp := jsonrpcapi.Response{JSONRPC: jsonrpcapi.Version, ID: req.ID}
+		var result any
+		switch req.Method {
+		case jsonrpcapi.MethodGetBalance:
+			var params jsonrpcapi.BalanceParams
+			json.Unmarshal(req.Params, &params)
+			if params.Account != "0.0.1" {
+				resp.Error = jsonrpcapi.NewError(jsonrpcapi.CodeNotFound, "account %s not found", params.Account)
+			}
+			result = restapi.BalanceResponse{Account: params.Account, Balance: 7, Timestamp: "2026-10-18T12:00:00Z"}
+		case jsonrpcapi.MethodEcho:
+			var params jsonrpcapi.EchoParams
+			json.Unmarshal(req.Params, &params)
+			result = restapi.EchoResponse{Payload: make([]byte, params.Size)}
+		case jsonrpcapi.MethodStreamTransactions:
+			w.Header().Set("Content-Type", jsonrpcapi.StreamContentType)
+			for i := range 2 {
+				event, _ := json.Marshal(restapi.TransactionEvent{TxID: fmt.Sprintf("tx%d", i), SentAt: 1})
+				data, _ := json.Marshal(jsonrpcapi.Request{JSONRPC: jsonrpcapi.Version, Method: jsonrpcapi.NotificationTransaction, Params: event})
+				fmt.Fprintf(w, "%s\n", data)
+			}
+			result = restapi.StreamDone{Sent: 3}
+		default:
+			resp.Error = jsonrpcapi.NewError(jsonrpcapi.CodeMethodNotFound, "method %s not found", req.Method)
+		}
+		if resp.Error == nil {
+			resp.Result, _ = json.Marshal(result)
+		}
+		json.NewEncoder(w).Encode(resp)
+	}))
+}
+
+func TestJSONRPCClient_Calls(t *testing.T) {
+	srv := jsonRPCTestServer(t)
+	defer srv.Close()
+
+	client, err := NewJSONRPCClient(srv.URL, "std", "none", ConnOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	if err := client.GetBalance(context.Background(), "0.0.1"); err != nil {
+		t.Errorf("GetBalance() error = %v", err)
+	}
+	updated, err := client.(BalanceTimestampClient).GetBalanceUpdatedAt(context.Background(), "0.0.1")
+	if err != nil || updated.Year() != 2026 {
+		t.Errorf("GetBalanceUpdatedAt() = %v, %v, want the balance timestamp", updated, err)
+	}
+	if err := client.(EchoClient).Echo(context.Background(), 16); err != nil {
+		t.Errorf("Echo() error = %v", err)
+	}
+
+	// Errors of the response fail the request, classified by their code
+	err = client.GetBalance(context.Background(), "0.0.2")
+	if got := classifyError(err); got != "jsonrpc_not_found" {
+		t.Errorf("GetBalance() of a missing account = %v, classified %q, want jsonrpc_not_found", err, got)
+	}
+	err = client.(BatchBalanceClient).GetBalances(context.Background(), []string{"0.0.1"})
+	if got := classifyError(err); got != "jsonrpc_method_not_found" {
+		t.Errorf("GetBalances() of a server without the method = %v, classified %q, want jsonrpc_method_not_found", err, got)
+	}
+}
+
+func TestJSONRPCClient_Stream(t *testing.T) {
+	srv := jsonRPCTestServer(t)
+	defer srv.Close()
+
+	client, err := NewJSONRPCClient(srv.URL, "std", "none", ConnOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	eventCh, errCh := client.StreamTransactions(context.Background(), 10)
+	var txIDs []string
+	var end *StreamEnd
+	for event := range eventCh {
+		if event.End != nil {
+			end = event.End
+			continue
+		}
+		if event.SentAt.IsZero() || event.Size == 0 {
+			t.Errorf("event %s has sent time %v and size %d, want both", event.TxID, event.SentAt, event.Size)
+		}
+		txIDs = append(txIDs, event.TxID)
+	}
+	if err := <-errCh; err != nil {
+		t.Fatalf("stream error = %v", err)
+	}
+
+	if len(txIDs) != 2 || txIDs[0] != "tx0" {
+		t.Errorf("received %q, want tx0 and tx1", txIDs)
+	}
+	if end == nil || end.Sent != 3 || end.Shortfall() != 1 {
+		t.Errorf("stream end = %+v, want 3 sent and 1 missing", end)
+	}
+}
@@ -0,0 +1,162 @@
+package bench
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"time"
+
+	"github.com/kaldun-tech/grpc-rest-benchmark/pkg/db"
+)
+
+type loggerKey struct{}
+
+// WithLogger returns a context carrying a run logger. Run and the runner
+// record load phases, stream errors, warnings and interim stats to it.
+func WithLogger(ctx context.Context, logger *slog.Logger) context.Context {
+	return context.WithValue(ctx, loggerKey{}, logger)
+}
+
+// LoggerFrom returns the run logger stored in ctx, or a logger that discards
+// everything if none is set.
+func LoggerFrom(ctx context.Context) *slog.Logger {
+	if logger, ok := ctx.Value(loggerKey{}).(*slog.Logger); ok {
+		return logger
+	}
+	return slog.New(slog.NewTextHandler(io.Discard, nil))
+}
+
+// Interim is a request a run answers while it collects samples, sent on
+// Config.Interim, e.g. when the benchmark receives a signal during a long
+// run.
+type Interim int
+
+const (
+	// InterimSummary writes the stats so far to Config.InterimOut: those
+	// of the whole run, and of the current segment.
+	InterimSummary Interim = iota
+	// InterimRotate ends the current segment, logging its stats to the run
+	// logger and rotating Config.SampleFlush, and starts the next: later
+	// summaries cover the samples from then on.
+	InterimRotate
+)
+
+// SampleFlusher writes samples out while a run collects them, so the
+// samples of a long run are kept even if it never finishes. Flushed
+// samples have no run ID, as the run is stored only when it ends, and
+// cover the whole run whatever its measure window.
+type SampleFlusher interface {
+	// Flush writes out one sample.
+	Flush(s *db.BenchmarkSample) error
+	// Rotate completes the output of segment, which just ended, and
+	// starts that of the next.
+	Rotate(segment int) error
+}
+
+// segment holds the stats of the samples collected since the run started
+// or the last InterimRotate.
+type segment struct {
+	n     int
+	start time.Time
+	groupResults
+}
+
+func newSegment(n int, start time.Time) *segment {
+	return &segment{n: n, start: start, groupResults: groupResults{latencies: newLatencyHistogram()}}
+}
+
+// collectWithProgress reads samples into results until ch is closed, logging
+// interim stats to the run logger every cfg.ProgressInterval, answering
+// requests on cfg.Interim and flushing samples to cfg.SampleFlush. A flush
+// error is logged as a warning and stops flushing for the rest of the run.
+func collectWithProgress(ctx context.Context, results *Results, ch <-chan Sample, cfg *Config) {
+	interval, interim, out, flush := cfg.ProgressInterval, cfg.Interim, cfg.InterimOut, cfg.SampleFlush
+	if interval <= 0 && interim == nil && flush == nil {
+		results.Collect(ch)
+		return
+	}
+
+	logger := LoggerFrom(ctx)
+	var tick <-chan time.Time
+	if interval > 0 {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+
+	lastTotal := 0
+	seg := newSegment(1, results.startTime)
+	for {
+		select {
+		case sample, ok := <-ch:
+			if !ok {
+				return
+			}
+			results.Add(sample)
+			seg.add(sample)
+			if flush != nil {
+				if err := flush.Flush(results.dbSample(0, sample)); err != nil {
+					logger.Warn("sample flush stopped", "segment", seg.n, "error", err)
+					flush = nil
+				}
+			}
+		case <-tick:
+			// The whole run so far, as the measure window may not have started
+			total := results.FullRequests()
+			logger.Info("interim stats",
+				"requests", total,
+				"errors", total-results.full.successful,
+				"interval_throughput", float64(total-lastTotal)/interval.Seconds(),
+				"p50_ms", DurationMs(results.FullPercentile(50)),
+				"p99_ms", DurationMs(results.FullPercentile(99)),
+			)
+			lastTotal = total
+		case req := <-interim:
+			now := time.Now()
+			switch req {
+			case InterimSummary:
+				printInterim(out, results, seg, now)
+			case InterimRotate:
+				logger.Info("segment stats",
+					"segment", seg.n,
+					"seconds", now.Sub(seg.start).Seconds(),
+					"requests", seg.total,
+					"errors", seg.total-seg.successful,
+					"p50_ms", DurationMs(seg.percentile(50)),
+					"p99_ms", DurationMs(seg.percentile(99)),
+				)
+				if flush != nil {
+					if err := flush.Rotate(seg.n); err != nil {
+						logger.Warn("sample flush stopped", "segment", seg.n, "error", err)
+						flush = nil
+					}
+				}
+				seg = newSegment(seg.n+1, now)
+				fmt.Fprintf(out, "Started interim segment %d\n", seg.n)
+			}
+		}
+	}
+}
+
+// printInterim writes the stats of the whole run so far and of the current
+// segment at now.
+func printInterim(out io.Writer, results *Results, seg *segment, now time.Time) {
+	elapsed := max(now.Sub(results.startTime), 0)
+	fmt.Fprintf(out, "\nInterim summary at %s:\n", elapsed.Round(time.Second))
+	line := func(label string, g *groupResults, d time.Duration) {
+		var throughput float64
+		if d > 0 {
+			throughput = float64(g.total) / d.Seconds()
+		}
+		fmt.Fprintf(out, "  %-12s %d requests, %d errors, %.1f req/s, p50 %s, p99 %s\n", label, g.total,
+			g.total-g.successful, throughput, FormatLatency(g.percentile(50)), FormatLatency(g.percentile(99)))
+	}
+	line("run:", &results.full, elapsed)
+	line(fmt.Sprintf("segment %d:", seg.n), &seg.groupResults, now.Sub(seg.start))
+}
+
+// DurationMs converts a duration to fractional milliseconds.
+func DurationMs(d time.Duration) float64 {
+	return float64(d.Microseconds()) / 1000
+}
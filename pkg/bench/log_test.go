@@ -0,0 +1,92 @@
+package bench
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kaldun-tech/grpc-rest-benchmark/pkg/db"
+)
+
+func TestLoggerFrom_Default(t *testing.T) {
+	// Without a run logger, logging must be a safe no-op
+	LoggerFrom(context.Background()).Info("discarded")
+}
+
+func TestCollectWithProgress(t *testing.T) {
+	r := NewResults()
+	ch := make(chan Sample, 10)
+	for i := 0; i < 10; i++ {
+		ch <- Sample{Latency: time.Millisecond, Success: true}
+	}
+	close(ch)
+
+	collectWithProgress(context.Background(), r, ch, &Config{ProgressInterval: time.Millisecond})
+
+	if r.TotalRequests() != 10 {
+		t.Errorf("TotalRequests() = %d, want 10", r.TotalRequests())
+	}
+}
+
+func TestCollectWithProgress_Interim(t *testing.T) {
+	var logs, out bytes.Buffer
+	ctx := WithLogger(context.Background(), slog.New(slog.NewTextHandler(&logs, nil)))
+	r := NewResults()
+	r.SetStartTime(time.Now())
+	ch := make(chan Sample)
+	interim := make(chan Interim)
+	flush := &recordingFlusher{}
+	done := make(chan struct{})
+	go func() {
+		collectWithProgress(ctx, r, ch, &Config{Interim: interim, InterimOut: &out, SampleFlush: flush})
+		close(done)
+	}()
+
+	// Unbuffered, so each sample is collected before the next request
+	for range 3 {
+		ch <- Sample{Latency: time.Millisecond, Success: true}
+	}
+	interim <- InterimRotate
+	ch <- Sample{Latency: time.Millisecond}
+	interim <- InterimSummary
+	close(ch)
+	<-done
+
+	if !strings.Contains(logs.String(), `msg="segment stats" segment=1`) || !strings.Contains(logs.String(), "requests=3 errors=0") {
+		t.Errorf("run log = %q, want the stats of segment 1", logs.String())
+	}
+	summary := out.String()
+	for _, want := range []string{"Started interim segment 2", "run:         4 requests, 1 errors", "segment 2:   1 requests, 1 errors"} {
+		if !strings.Contains(summary, want) {
+			t.Errorf("interim output = %q, want %q", summary, want)
+		}
+	}
+	if got := flush.segments; len(got) != 2 || len(got[0]) != 3 || len(got[1]) != 1 || got[1][0].Success {
+		t.Errorf("flushed segments = %v, want 3 samples then the failed one", got)
+	}
+}
+
+// recordingFlusher keeps flushed samples per segment.
+type recordingFlusher struct {
+	segments [][]*db.BenchmarkSample
+}
+
+func (f *recordingFlusher) Flush(s *db.BenchmarkSample) error {
+	if len(f.segments) == 0 {
+		f.segments = append(f.segments, nil)
+	}
+	f.segments[len(f.segments)-1] = append(f.segments[len(f.segments)-1], s)
+	return nil
+}
+
+func (f *recordingFlusher) Rotate(segment int) error {
+	if segment != len(f.segments) {
+		return fmt.Errorf("rotated segment %d, want %d", segment, len(f.segments))
+	}
+	f.segments = append(f.segments, nil)
+	return nil
+}
@@ -0,0 +1,94 @@
+package bench
+
+import (
+	"fmt"
+	"io"
+)
+
+// maxOrderingEvents caps the transaction IDs recorded per subscriber by the
+// ordering check, bounding its memory on long or unthrottled runs. Events
+// past the cap are still measured but not checked.
+const maxOrderingEvents = 100_000
+
+// OrderingReport is the result of comparing the transaction IDs received by
+// each stream subscriber, in arrival order.
+type OrderingReport struct {
+	Subscribers int
+	Checked     int // events of the longest sequence every subscriber was compared against
+	Prefix      int // length of the prefix every subscriber received in full
+	Divergent   int // subscribers whose sequence differs from the reference
+
+	// First is the earliest divergence, nil if all subscribers agree.
+	First *OrderingDivergence
+}
+
+// OrderingDivergence is the first position at which a subscriber received a
+// different transaction than the reference subscriber.
+type OrderingDivergence struct {
+	Index      int // 0-based position in the stream
+	Reference  int // subscriber whose sequence is the reference
+	Subscriber int
+	Want, Got  string
+}
+
+// checkOrdering verifies that every subscriber saw the same ordered prefix
+// of the stream. Subscribers stop at different points, so each sequence is
+// compared against the longest one, which serves as the reference; a
+// subscriber that missed, duplicated or reordered an event diverges at that
+// position.
+func checkOrdering(seqs [][]string) OrderingReport {
+	rep := OrderingReport{Subscribers: len(seqs)}
+	if len(seqs) == 0 {
+		return rep
+	}
+
+	ref := 0
+	rep.Prefix = len(seqs[0])
+	for i, s := range seqs {
+		if len(s) > len(seqs[ref]) {
+			ref = i
+		}
+		rep.Prefix = min(rep.Prefix, len(s))
+	}
+	rep.Checked = len(seqs[ref])
+
+	for i, s := range seqs {
+		if i == ref {
+			continue
+		}
+		for j, id := range s {
+			if id == seqs[ref][j] {
+				continue
+			}
+			rep.Divergent++
+			if rep.First == nil || j < rep.First.Index {
+				rep.First = &OrderingDivergence{
+					Index:      j,
+					Reference:  ref,
+					Subscriber: i,
+					Want:       seqs[ref][j],
+					Got:        id,
+				}
+			}
+			break
+		}
+	}
+	return rep
+}
+
+// PrintOrderingReport writes the ordering check result below the summary.
+func PrintOrderingReport(out io.Writer, rep OrderingReport) {
+	fmt.Fprintln(out, "Ordering check:")
+	if rep.First == nil {
+		fmt.Fprintf(out, "  OK: %d subscribers agree on the first %d events (common prefix %d)\n",
+			rep.Subscribers, rep.Checked, rep.Prefix)
+		fmt.Fprintln(out)
+		return
+	}
+	d := rep.First
+	fmt.Fprintf(out, "  DIVERGED: %d of %d subscribers differ from subscriber %d\n",
+		rep.Divergent, rep.Subscribers, d.Reference)
+	fmt.Fprintf(out, "  First divergence at event %d: subscriber %d got %s, subscriber %d got %s\n",
+		d.Index, d.Subscriber, d.Got, d.Reference, d.Want)
+	fmt.Fprintln(out)
+}
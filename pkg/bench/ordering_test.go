@@ -0,0 +1,66 @@
+package bench
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestCheckOrdering(t *testing.T) {
+	tests := []struct {
+		name      string
+		seqs      [][]string
+		checked   int
+		prefix    int
+		divergent int
+		first     *OrderingDivergence
+	}{
+		{
+			name: "no subscribers",
+		},
+		{
+			name:    "same prefix, different lengths",
+			seqs:    [][]string{{"a", "b"}, {"a", "b", "c", "d"}, {"a", "b", "c"}},
+			checked: 4,
+			prefix:  2,
+		},
+		{
+			name:      "missed event",
+			seqs:      [][]string{{"a", "b", "c", "d"}, {"a", "c", "d"}},
+			checked:   4,
+			prefix:    3,
+			divergent: 1,
+			first:     &OrderingDivergence{Index: 1, Reference: 0, Subscriber: 1, Want: "b", Got: "c"},
+		},
+		{
+			name:      "earliest divergence wins",
+			seqs:      [][]string{{"a", "b", "x"}, {"a", "b", "c", "d"}, {"y", "b"}},
+			checked:   4,
+			prefix:    2,
+			divergent: 2,
+			first:     &OrderingDivergence{Index: 0, Reference: 1, Subscriber: 2, Want: "a", Got: "y"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rep := checkOrdering(tt.seqs)
+			if rep.Subscribers != len(tt.seqs) || rep.Checked != tt.checked || rep.Prefix != tt.prefix || rep.Divergent != tt.divergent {
+				t.Errorf("checkOrdering() = %+v", rep)
+			}
+			switch {
+			case tt.first == nil && rep.First != nil:
+				t.Errorf("First = %+v, want no divergence", *rep.First)
+			case tt.first != nil && (rep.First == nil || *rep.First != *tt.first):
+				t.Errorf("First = %+v, want %+v", rep.First, *tt.first)
+			}
+		})
+	}
+}
+
+func TestPrintOrderingReport(t *testing.T) {
+	var buf bytes.Buffer
+	PrintOrderingReport(&buf, checkOrdering([][]string{{"a", "b"}, {"a", "c"}}))
+	if out := buf.String(); !strings.Contains(out, "DIVERGED: 1 of 2") || !strings.Contains(out, "event 1: subscriber 1 got c, subscriber 0 got b") {
+		t.Errorf("output = %q", out)
+	}
+}
@@ -0,0 +1,108 @@
+package bench
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"google.golang.org/grpc/connectivity"
+)
+
+// preconnectTimeout bounds how long a local run waits for its client to
+// connect to the server before it starts.
+const preconnectTimeout = 10 * time.Second
+
+// Preconnector is implemented by clients that can open their connections
+// to the server ahead of the first request. Runs preconnect before the
+// measured window starts, so that workers which dial faster do not start
+// issuing requests earlier than the others.
+type Preconnector interface {
+	// Preconnect opens the connections n concurrent requests or streams
+	// use and returns once they are established.
+	Preconnect(ctx context.Context, n int) error
+}
+
+// preconnect opens the connections of client for a run of cfg, if the
+// client supports it.
+func preconnect(ctx context.Context, client BenchmarkClient, cfg *Config) error {
+	p, ok := client.(Preconnector)
+	if !ok {
+		return nil
+	}
+	return p.Preconnect(ctx, cfg.connections())
+}
+
+// Preconnect waits for every shared connection to be ready. Clients that
+// dial per call have nothing to open ahead.
+func (c *gRPCClient) Preconnect(ctx context.Context, n int) error {
+	for _, conn := range c.conns {
+		conn.Connect()
+		for state := conn.GetState(); state != connectivity.Ready; state = conn.GetState() {
+			if !conn.WaitForStateChange(ctx, state) {
+				return fmt.Errorf("gRPC connection %s: %w", state, ctx.Err())
+			}
+		}
+	}
+	return nil
+}
+
+// Preconnect opens up to n keep-alive connections. Clients that do not
+// reuse connections are left alone.
+func (c *httpClient) Preconnect(ctx context.Context, n int) error {
+	if c.disableKeepAlive {
+		return nil
+	}
+	return preconnectHTTP(ctx, c.client, c.baseURL, n)
+}
+
+// Preconnect opens up to n keep-alive connections (one with HTTP/2).
+func (c *connectClient) Preconnect(ctx context.Context, n int) error {
+	if c.disableKeepAlive {
+		return nil
+	}
+	return preconnectHTTP(ctx, c.httpClient, c.baseURL, n)
+}
+
+// Preconnect opens up to n keep-alive connections, as the REST client does.
+func (c *graphQLClient) Preconnect(ctx context.Context, n int) error {
+	return c.http.Preconnect(ctx, n)
+}
+
+// Preconnect opens up to n keep-alive connections, as the REST client does.
+func (c *jsonRPCClient) Preconnect(ctx context.Context, n int) error {
+	return c.http.Preconnect(ctx, n)
+}
+
+// preconnectHTTP sends n concurrent HEAD requests for the server's health
+// path, leaving a connection idle in client's pool for each. Any response
+// will do, as only the connection matters.
+func preconnectHTTP(ctx context.Context, client *http.Client, baseURL string, n int) error {
+	errs := make(chan error, n)
+	for range n {
+		go func() {
+			req, err := http.NewRequestWithContext(ctx, http.MethodHead, baseURL+"/health", nil)
+			if err != nil {
+				errs <- err
+				return
+			}
+			resp, err := client.Do(req)
+			if err != nil {
+				errs <- err
+				return
+			}
+			io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+			errs <- nil
+		}()
+	}
+
+	var firstErr error
+	for range n {
+		if err := <-errs; err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
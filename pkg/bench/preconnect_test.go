@@ -0,0 +1,144 @@
+package bench
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+
+	"github.com/kaldun-tech/grpc-rest-benchmark/pkg/jsoncodec"
+	"github.com/kaldun-tech/grpc-rest-benchmark/pkg/protos"
+	"github.com/kaldun-tech/grpc-rest-benchmark/pkg/restapi"
+)
+
+func TestHTTPClient_Preconnect(t *testing.T) {
+	var conns atomic.Int32
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(10 * time.Millisecond) // keep the requests concurrent
+	}))
+	srv.Config.ConnState = func(_ net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			conns.Add(1)
+		}
+	}
+	srv.Start()
+	defer srv.Close()
+
+	for _, tt := range []struct {
+		conn ConnOptions
+		want int32
+	}{{ConnOptions{}, 4}, {ConnOptions{DisableKeepAlive: true}, 0}} {
+		conns.Store(0)
+		client, err := NewHTTPClient(srv.URL, "json", "idiomatic", restapi.StreamSSE, jsoncodec.Std, "none", tt.conn)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := client.(Preconnector).Preconnect(context.Background(), 4); err != nil {
+			t.Fatalf("Preconnect() error = %v", err)
+		}
+		if got := conns.Load(); got != tt.want {
+			t.Errorf("Preconnect(4) with %+v opened %d connections, want %d", tt.conn, got, tt.want)
+		}
+		client.Close()
+	}
+}
+
+// preconnectClient records the connections it is asked to open, failing
+// with err.
+type preconnectClient struct {
+	concurrencyClient
+	err   error
+	block bool // wait for ctx instead
+	n     int
+}
+
+func (c *preconnectClient) Preconnect(ctx context.Context, n int) error {
+	c.n = n
+	if c.block {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+	return c.err
+}
+
+func TestRun_Preconnect(t *testing.T) {
+	client := &preconnectClient{}
+	if _, err := Run(context.Background(), Config{
+		Scenario:    "balance",
+		Client:      client,
+		AccountIDs:  []string{"0.0.1001"},
+		Concurrency: 3,
+		Duration:    50 * time.Millisecond,
+	}); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if client.n != 3 {
+		t.Errorf("Preconnect(%d), want one connection per worker", client.n)
+	}
+
+	client = &preconnectClient{err: errors.New("connection refused")}
+	_, err := Run(context.Background(), Config{
+		Scenario:    "balance",
+		Client:      client,
+		AccountIDs:  []string{"0.0.1001"},
+		Concurrency: 1,
+		Duration:    50 * time.Millisecond,
+	})
+	if err == nil || client.maxInFlight != 0 {
+		t.Errorf("Run() error = %v after %d requests, want the connection failure before any request", err, client.maxInFlight)
+	}
+}
+
+func TestConfig_Connections(t *testing.T) {
+	tests := []struct {
+		cfg  Config
+		want int
+	}{
+		{Config{Scenario: "balance", Concurrency: 4}, 4},
+		{Config{Scenario: "stream", Concurrency: 2, StreamsPerWorker: 3}, 6},
+		{Config{Scenario: "stream-balance", Concurrency: 2, Subscribers: 5}, 7},
+		{Config{Scenario: "balance", Concurrency: 1, LoadProfile: &LoadProfile{
+			Target: ProfileTargetConcurrency,
+			Phases: []LoadPhase{{Level: 2, Duration: time.Second}, {Level: 8, Duration: time.Second}},
+		}}, 8},
+	}
+	for _, tt := range tests {
+		if got := tt.cfg.connections(); got != tt.want {
+			t.Errorf("connections(%s) = %d, want %d", tt.cfg.Scenario, got, tt.want)
+		}
+	}
+}
+
+func TestWorker_PreconnectLate(t *testing.T) {
+	addr := startWorker(t, &preconnectClient{block: true})
+	conn, err := grpc.NewClient(addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	spec, err := json.Marshal(Config{Scenario: "balance", Protocol: "grpc", AccountIDs: []string{"0.0.1001"}, Concurrency: 1, Duration: time.Second})
+	if err != nil {
+		t.Fatal(err)
+	}
+	stream, err := protos.NewWorkerServiceClient(conn).Run(context.Background(), &protos.WorkerRunRequest{
+		Config:        spec,
+		StartUnixNano: time.Now().Add(50 * time.Millisecond).UnixNano(),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := stream.Recv(); status.Code(err) != codes.DeadlineExceeded {
+		t.Errorf("Recv() error = %v, want the worker not connected by the start", err)
+	}
+}
@@ -0,0 +1,159 @@
+package bench
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Load profile targets selectable with --load-profile-target.
+const (
+	ProfileTargetConcurrency = "concurrency" // number of active workers
+	ProfileTargetRate        = "rate"        // total target requests/s
+)
+
+// ProfileTargets lists the load profile targets.
+var ProfileTargets = []string{ProfileTargetConcurrency, ProfileTargetRate}
+
+// defaultRampPhases is the number of steps a ramp is divided into when the
+// spec does not say.
+const defaultRampPhases = 10
+
+// LoadProfile varies the concurrency or target rate of a run through a
+// sequence of phases, so one run can sweep a protocol up to its saturation
+// point. Samples are tagged with the phase they were issued in.
+type LoadProfile struct {
+	Spec   string // as given on the command line
+	Target string // ProfileTargetConcurrency or ProfileTargetRate
+	Phases []LoadPhase
+}
+
+// LoadPhase holds one load level for a fixed time.
+type LoadPhase struct {
+	Level    int
+	Duration time.Duration
+}
+
+// ParseLoadProfile parses a load profile spec:
+//
+//	step:10,50,100,200@30s   each level held for 30s
+//	ramp:0-500@2m            0 to 500 over 2m in 10 equal steps
+//	ramp:0-500@2m/5          ... in 5 steps
+//
+// A ramp's phases end at its levels, so ramp:0-500@2m/5 runs at 100, 200,
+// 300, 400 and 500 for 24s each. Every level must be at least 1 and every
+// phase at least a second long.
+func ParseLoadProfile(spec, target string) (*LoadProfile, error) {
+	kind, rest, ok := strings.Cut(spec, ":")
+	if !ok {
+		return nil, fmt.Errorf("invalid load profile %q: want step:LEVELS@DURATION or ramp:FROM-TO@DURATION", spec)
+	}
+	levels, timing, ok := strings.Cut(rest, "@")
+	if !ok {
+		return nil, fmt.Errorf("invalid load profile %q: missing @DURATION", spec)
+	}
+
+	var phases []LoadPhase
+	var err error
+	switch kind {
+	case "step":
+		phases, err = parseSteps(levels, timing)
+	case "ramp":
+		phases, err = parseRamp(levels, timing)
+	default:
+		return nil, fmt.Errorf("invalid load profile %q: unknown kind %q (must be step or ramp)", spec, kind)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid load profile %q: %w", spec, err)
+	}
+
+	for i, p := range phases {
+		if p.Level < 1 {
+			return nil, fmt.Errorf("invalid load profile %q: phase %d level %d is below 1", spec, i+1, p.Level)
+		}
+		if p.Duration < time.Second {
+			return nil, fmt.Errorf("invalid load profile %q: phases must last at least 1s, got %s", spec, p.Duration)
+		}
+	}
+
+	return &LoadProfile{Spec: spec, Target: target, Phases: phases}, nil
+}
+
+// parseSteps parses "10,50,100@30s".
+func parseSteps(levels, timing string) ([]LoadPhase, error) {
+	d, err := time.ParseDuration(timing)
+	if err != nil {
+		return nil, err
+	}
+	var phases []LoadPhase
+	for _, s := range strings.Split(levels, ",") {
+		level, err := strconv.Atoi(strings.TrimSpace(s))
+		if err != nil {
+			return nil, fmt.Errorf("invalid level %q", s)
+		}
+		phases = append(phases, LoadPhase{Level: level, Duration: d})
+	}
+	return phases, nil
+}
+
+// parseRamp parses "0-500@2m" or "0-500@2m/5".
+func parseRamp(levels, timing string) ([]LoadPhase, error) {
+	fromStr, toStr, ok := strings.Cut(levels, "-")
+	if !ok {
+		return nil, fmt.Errorf("ramp levels must be FROM-TO, got %q", levels)
+	}
+	from, err := strconv.Atoi(fromStr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid level %q", fromStr)
+	}
+	to, err := strconv.Atoi(toStr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid level %q", toStr)
+	}
+
+	n := defaultRampPhases
+	durStr, nStr, ok := strings.Cut(timing, "/")
+	if ok {
+		if n, err = strconv.Atoi(nStr); err != nil || n < 1 {
+			return nil, fmt.Errorf("invalid phase count %q", nStr)
+		}
+	}
+	d, err := time.ParseDuration(durStr)
+	if err != nil {
+		return nil, err
+	}
+
+	phases := make([]LoadPhase, n)
+	for i := range phases {
+		phases[i] = LoadPhase{
+			Level:    from + (to-from)*(i+1)/n,
+			Duration: d / time.Duration(n),
+		}
+	}
+	return phases, nil
+}
+
+// Duration returns the total duration of all phases.
+func (p *LoadProfile) Duration() time.Duration {
+	var d time.Duration
+	for _, phase := range p.Phases {
+		d += phase.Duration
+	}
+	return d
+}
+
+// MaxLevel returns the highest level in the profile.
+func (p *LoadProfile) MaxLevel() int {
+	level := 0
+	for _, phase := range p.Phases {
+		level = max(level, phase.Level)
+	}
+	return level
+}
+
+// String formats the profile as stored with the run, e.g.
+// "concurrency step:10,50@30s".
+func (p *LoadProfile) String() string {
+	return p.Target + " " + p.Spec
+}
@@ -0,0 +1,130 @@
+package bench
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestParseLoadProfile(t *testing.T) {
+	tests := []struct {
+		spec       string
+		wantLevels []int
+		wantPhase  time.Duration
+		wantErr    string
+	}{
+		{spec: "step:10,50,100,200@30s", wantLevels: []int{10, 50, 100, 200}, wantPhase: 30 * time.Second},
+		{spec: "step:5@1m", wantLevels: []int{5}, wantPhase: time.Minute},
+		{spec: "ramp:0-500@2m/5", wantLevels: []int{100, 200, 300, 400, 500}, wantPhase: 24 * time.Second},
+		{spec: "ramp:100-0@40s/4", wantErr: "phase 4 level 0 is below 1"},
+		{spec: "ramp:200-100@10s/2", wantLevels: []int{150, 100}, wantPhase: 5 * time.Second},
+		{spec: "ramp:0-5@10s", wantErr: "phase 1 level 0 is below 1"},
+		{spec: "step:10,20@500ms", wantErr: "at least 1s"},
+		{spec: "step:10,x@30s", wantErr: `invalid level "x"`},
+		{spec: "step:10,20", wantErr: "missing @DURATION"},
+		{spec: "ramp:500@2m", wantErr: "FROM-TO"},
+		{spec: "ramp:0-500@2m/0", wantErr: "invalid phase count"},
+		{spec: "sine:10-20@1m", wantErr: `unknown kind "sine"`},
+		{spec: "10,20@30s", wantErr: "want step:"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			p, err := ParseLoadProfile(tt.spec, ProfileTargetConcurrency)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("ParseLoadProfile() error = %v, want it to contain %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseLoadProfile() error = %v", err)
+			}
+			if len(p.Phases) != len(tt.wantLevels) {
+				t.Fatalf("got %d phases, want %d", len(p.Phases), len(tt.wantLevels))
+			}
+			for i, phase := range p.Phases {
+				if phase.Level != tt.wantLevels[i] || phase.Duration != tt.wantPhase {
+					t.Errorf("phase %d = %+v, want level %d for %s", i+1, phase, tt.wantLevels[i], tt.wantPhase)
+				}
+			}
+		})
+	}
+}
+
+func TestLoadProfile_Totals(t *testing.T) {
+	p, err := ParseLoadProfile("step:10,200,50@30s", ProfileTargetRate)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := p.Duration(); got != 90*time.Second {
+		t.Errorf("Duration() = %v, want 90s", got)
+	}
+	if got := p.MaxLevel(); got != 200 {
+		t.Errorf("MaxLevel() = %d, want 200", got)
+	}
+	if got := p.String(); got != "rate step:10,200,50@30s" {
+		t.Errorf("String() = %q", got)
+	}
+}
+
+// concurrencyClient answers balance queries after a short delay and records
+// the most requests it saw in flight at once.
+type concurrencyClient struct {
+	mu          sync.Mutex
+	inFlight    int
+	maxInFlight int
+}
+
+func (c *concurrencyClient) GetBalance(ctx context.Context, accountID string) error {
+	c.mu.Lock()
+	c.inFlight++
+	c.maxInFlight = max(c.maxInFlight, c.inFlight)
+	c.mu.Unlock()
+
+	time.Sleep(2 * time.Millisecond)
+
+	c.mu.Lock()
+	c.inFlight--
+	c.mu.Unlock()
+	return nil
+}
+
+func (c *concurrencyClient) StreamTransactions(ctx context.Context, rate int) (<-chan StreamEvent, <-chan error) {
+	return nil, nil
+}
+
+func (c *concurrencyClient) Close() error { return nil }
+
+func TestRunner_LoadProfile(t *testing.T) {
+	client := &concurrencyClient{}
+	runner := NewRunner(client, []string{"0.0.1001"}, 1, 0)
+	runner.SetLoadProfile(&LoadProfile{
+		Target: ProfileTargetConcurrency,
+		Phases: []LoadPhase{
+			{Level: 1, Duration: 150 * time.Millisecond},
+			{Level: 4, Duration: 150 * time.Millisecond},
+		},
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 300*time.Millisecond)
+	defer cancel()
+	go runner.RunBalance(ctx)
+
+	perPhase := make(map[int]int)
+	for s := range runner.Results() {
+		perPhase[s.Phase]++
+	}
+
+	if perPhase[0] != 0 {
+		t.Errorf("%d samples were not tagged with a phase", perPhase[0])
+	}
+	if perPhase[1] == 0 || perPhase[2] <= perPhase[1] {
+		t.Errorf("samples per phase = %v, want phase 2 to issue more than phase 1", perPhase)
+	}
+	if client.maxInFlight > 4 {
+		t.Errorf("max in flight = %d, want at most the peak level 4", client.maxInFlight)
+	}
+}
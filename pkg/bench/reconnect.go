@@ -0,0 +1,112 @@
+package bench
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+)
+
+// Reconnection of replay streams. A stream that fails, or that the client
+// cuts to simulate a flaky network (Config.ChaosDisconnect), is reopened
+// after the last transaction it received, so its transactions continue
+// where they left off and the time spent disconnected is measured.
+
+const (
+	// reconnectBackoff is the wait before reopening a stream that failed
+	// without delivering an event, doubling with each failure up to
+	// maxReconnectBackoff.
+	reconnectBackoff    = 100 * time.Millisecond
+	maxReconnectBackoff = 5 * time.Second
+
+	// maxReconnectAttempts is how many times in a row a stream may fail
+	// without delivering an event before its error is reported.
+	maxReconnectAttempts = 10
+)
+
+// reconnectingStream delivers the events of a replay stream at rate
+// events/s, reopening it after the last transaction received whenever it
+// fails or, with chaos set, chaos after each time it was opened. It ends
+// when ctx is done, when the server ends the stream, or with the stream's
+// error once it failed maxReconnectAttempts times in a row. The first event
+// after each reconnect carries how long the stream was disconnected.
+func reconnectingStream(ctx context.Context, client ResumableStreamClient, rate int, chaos time.Duration) (<-chan StreamEvent, <-chan error) {
+	eventCh := make(chan StreamEvent, 100)
+	errCh := make(chan error, 1)
+
+	go func() {
+		defer close(eventCh)
+		defer close(errCh)
+
+		var cursor string
+		var disconnectedAt time.Time
+		failures, backoff := 0, reconnectBackoff
+		for {
+			streamCtx, cancel := context.WithCancel(ctx)
+			var cut atomic.Bool
+			var timer *time.Timer
+			if chaos > 0 {
+				timer = time.AfterFunc(chaos, func() {
+					cut.Store(true)
+					cancel()
+				})
+			}
+
+			events, errs := client.ResumeTransactions(streamCtx, rate, cursor)
+			delivered, ended := false, false
+			for event := range events {
+				if event.End != nil {
+					ended = true
+				} else {
+					cursor = event.TxID
+					delivered = true
+					if !disconnectedAt.IsZero() {
+						event.Reconnect = event.ReceivedAt.Sub(disconnectedAt)
+						disconnectedAt = time.Time{}
+					}
+				}
+				select {
+				case eventCh <- event:
+				case <-ctx.Done():
+				}
+			}
+			err := <-errs
+			if timer != nil {
+				timer.Stop()
+			}
+			cancel()
+
+			switch {
+			case ctx.Err() != nil || ended:
+				return
+			case !cut.Load() && err == nil:
+				// Over without the server's count of transactions sent
+				return
+			}
+
+			// Measured from the first disconnect when reopening fails too
+			if disconnectedAt.IsZero() {
+				disconnectedAt = time.Now()
+			}
+			if cut.Load() {
+				continue
+			}
+			LoggerFrom(ctx).Warn("stream failed, reconnecting", "after", cursor, "error", err.Error())
+			if delivered {
+				failures, backoff = 0, reconnectBackoff
+				continue
+			}
+			if failures++; failures >= maxReconnectAttempts {
+				errCh <- err
+				return
+			}
+			select {
+			case <-time.After(backoff):
+			case <-ctx.Done():
+				return
+			}
+			backoff = min(2*backoff, maxReconnectBackoff)
+		}
+	}()
+
+	return eventCh, errCh
+}
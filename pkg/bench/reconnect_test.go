@@ -0,0 +1,152 @@
+package bench
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// resumableClient replays transactions tx-1 to tx-total, one every gap,
+// from the cursor it is given. The first stream fails after failAfter
+// events if failAfter is set.
+type resumableClient struct {
+	concurrencyClient
+	total     int // 0 for an endless stream
+	gap       time.Duration
+	failAfter int
+	opened    atomic.Int32
+}
+
+func (c *resumableClient) ResumeTransactions(ctx context.Context, rate int, afterTxID string) (<-chan StreamEvent, <-chan error) {
+	first := c.opened.Add(1) == 1
+	next := 1
+	if afterTxID != "" {
+		n, _ := strconv.Atoi(strings.TrimPrefix(afterTxID, "tx-"))
+		next = n + 1
+	}
+
+	events := make(chan StreamEvent)
+	errs := make(chan error, 1)
+	go func() {
+		defer close(events)
+		defer close(errs)
+		for sent := 0; c.total == 0 || next <= c.total; sent, next = sent+1, next+1 {
+			if first && c.failAfter > 0 && sent == c.failAfter {
+				errs <- errors.New("connection reset")
+				return
+			}
+			select {
+			case <-time.After(c.gap):
+			case <-ctx.Done():
+				return
+			}
+			select {
+			case events <- StreamEvent{ReceivedAt: time.Now(), TxID: fmt.Sprintf("tx-%d", next)}:
+			case <-ctx.Done():
+				return
+			}
+		}
+		select {
+		case events <- StreamEvent{ReceivedAt: time.Now(), End: &StreamEnd{}}:
+		case <-ctx.Done():
+		}
+	}()
+	return events, errs
+}
+
+func (c *resumableClient) StreamTransactions(ctx context.Context, rate int) (<-chan StreamEvent, <-chan error) {
+	return c.ResumeTransactions(ctx, rate, "")
+}
+
+// checkConsecutive fails unless ids are tx-1, tx-2, ... in order.
+func checkConsecutive(t *testing.T, ids []string) {
+	t.Helper()
+	for i, id := range ids {
+		if want := fmt.Sprintf("tx-%d", i+1); id != want {
+			t.Fatalf("transaction %d = %s, want %s (received %v)", i, id, want, ids)
+		}
+	}
+}
+
+func TestReconnectingStream_ResumesAfterFailure(t *testing.T) {
+	client := &resumableClient{total: 10, gap: time.Millisecond, failAfter: 3}
+	events, errs := reconnectingStream(context.Background(), client, 0, 0)
+
+	var ids []string
+	var reconnects []time.Duration
+	ended := false
+	for event := range events {
+		if event.End != nil {
+			ended = true
+			continue
+		}
+		ids = append(ids, event.TxID)
+		if event.Reconnect > 0 {
+			reconnects = append(reconnects, event.Reconnect)
+		}
+	}
+	if err := <-errs; err != nil {
+		t.Fatalf("stream error: %v", err)
+	}
+
+	checkConsecutive(t, ids)
+	if len(ids) != 10 || !ended {
+		t.Errorf("received %d transactions, ended %v; want all 10 and the end", len(ids), ended)
+	}
+	if got := client.opened.Load(); got != 2 {
+		t.Errorf("streams opened = %d, want 2", got)
+	}
+	if len(reconnects) != 1 {
+		t.Errorf("events after a reconnect = %v, want 1", reconnects)
+	}
+}
+
+func TestReconnectingStream_ChaosDisconnect(t *testing.T) {
+	client := &resumableClient{gap: 2 * time.Millisecond}
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	events, _ := reconnectingStream(ctx, client, 0, 30*time.Millisecond)
+
+	var ids []string
+	reconnects := 0
+	for event := range events {
+		ids = append(ids, event.TxID)
+		if event.Reconnect > 0 {
+			reconnects++
+		}
+	}
+
+	checkConsecutive(t, ids)
+	if opened := client.opened.Load(); opened < 3 || reconnects < 2 {
+		t.Errorf("opened %d streams with %d reconnects, want one every 30ms", opened, reconnects)
+	}
+}
+
+func TestRun_ChaosDisconnect(t *testing.T) {
+	report, err := Run(context.Background(), Config{
+		Scenario:        "stream",
+		Client:          &resumableClient{gap: time.Millisecond},
+		Concurrency:     2,
+		ChaosDisconnect: 50 * time.Millisecond,
+		Duration:        300 * time.Millisecond,
+	})
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	r := report.Results
+	if r.Reconnects() < 4 {
+		t.Errorf("Reconnects() = %d, want about 5 per stream", r.Reconnects())
+	}
+	if p50, ok := r.ReconnectPercentile(50); !ok || p50 <= 0 {
+		t.Errorf("ReconnectPercentile(50) = %v, %v; want the time disconnected", p50, ok)
+	}
+	if r.ErrorRate() != 0 {
+		t.Errorf("ErrorRate() = %v, want deliberate disconnects not counted as errors", r.ErrorRate())
+	}
+}
@@ -0,0 +1,136 @@
+package bench
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sync/atomic"
+	"time"
+)
+
+// The reference scenario queries balances from an in-process stub instead of
+// a server. Each request takes a fixed synthetic latency, so the percentiles
+// of a run are known in advance and the difference from the measured ones is
+// the overhead of the benchmark harness itself. ReferenceSamples produces the
+// same requests without a clock, for testing the statistics and the summary.
+
+// ReferenceProtocol is the protocol reference runs are reported and stored
+// with.
+const ReferenceProtocol = "stub"
+
+const (
+	// Successful requests take 1 to referenceCycle times referenceStep,
+	// each latency once per cycle in an order shuffled by referenceStride,
+	// which is coprime with referenceCycle.
+	referenceStep   = 100 * time.Microsecond
+	referenceCycle  = 100
+	referenceStride = 37
+
+	// Every referenceFailEvery-th request fails after referenceFailLatency.
+	referenceFailEvery   = 200
+	referenceFailLatency = 20 * time.Millisecond
+)
+
+// ReferenceAccountIDs are the accounts reference runs query when given none.
+// The stub answers for any account.
+var ReferenceAccountIDs = []string{"0.0.1001", "0.0.1002", "0.0.1003", "0.0.1004"}
+
+// errReferenceFailure is the error of failed reference requests, classified
+// as a server error like a 503 from the REST server.
+var errReferenceFailure = &statusError{code: http.StatusServiceUnavailable}
+
+// referenceRequest returns the latency of the i-th reference request,
+// counted from 0, and whether it fails.
+func referenceRequest(i int) (latency time.Duration, failed bool) {
+	if (i+1)%referenceFailEvery == 0 {
+		return referenceFailLatency, true
+	}
+	succeeded := i - (i+1)/referenceFailEvery
+	return time.Duration((succeeded*referenceStride)%referenceCycle+1) * referenceStep, false
+}
+
+// ReferencePercentile returns the latency at percentile p (0-100) of the
+// successful reference requests: the nearest rank over a whole cycle, which
+// the measured percentile of a run should match up to the histogram
+// precision and the harness overhead.
+func ReferencePercentile(p float64) time.Duration {
+	rank := int(math.Ceil(p / 100 * referenceCycle))
+	return time.Duration(max(rank, 1)) * referenceStep
+}
+
+// ReferenceSamples returns the first n reference requests as a single worker
+// would issue them from start, each as soon as the previous one returned.
+func ReferenceSamples(n int, start time.Time) []Sample {
+	samples := make([]Sample, n)
+	at := start
+	for i := range samples {
+		latency, failed := referenceRequest(i)
+		samples[i] = Sample{Latency: latency, Success: !failed, Timestamp: at, Operation: OpGetBalance}
+		if failed {
+			samples[i].Error = errReferenceFailure
+		}
+		at = at.Add(latency)
+	}
+	return samples
+}
+
+// referenceClient is the in-process stub of the reference scenario. Its
+// requests take the reference latencies in the order they are issued,
+// whichever worker issues them.
+type referenceClient struct {
+	requests atomic.Int64
+}
+
+// NewReferenceClient creates the stub client of the reference scenario.
+func NewReferenceClient() BenchmarkClient {
+	return &referenceClient{}
+}
+
+func (c *referenceClient) GetBalance(ctx context.Context, accountID string) error {
+	latency, failed := referenceRequest(int(c.requests.Add(1) - 1))
+	timer := time.NewTimer(latency)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	if failed {
+		return errReferenceFailure
+	}
+	return nil
+}
+
+func (c *referenceClient) StreamTransactions(ctx context.Context, rate int) (<-chan StreamEvent, <-chan error) {
+	eventCh := make(chan StreamEvent)
+	errCh := make(chan error, 1)
+	errCh <- errors.New("the reference stub does not stream")
+	close(eventCh)
+	close(errCh)
+	return eventCh, errCh
+}
+
+func (c *referenceClient) Close() error {
+	return nil
+}
+
+// PrintReferenceOverhead writes the measured latency percentiles of a
+// reference run next to the latencies the stub was set to take. The
+// difference is the harness overhead: timer wakeups, scheduling and sample
+// collection.
+func PrintReferenceOverhead(w io.Writer, r *Results) {
+	fmt.Fprintln(w, "Harness overhead (measured vs reference latency):")
+	for _, p := range r.percentiles {
+		measured, reference := r.Percentile(p), ReferencePercentile(p)
+		overhead := FormatLatency(measured - reference)
+		if measured >= reference {
+			overhead = "+" + overhead
+		}
+		fmt.Fprintf(w, "  %-7s %10s vs %10s  %s\n", fmt.Sprintf("p%g:", p),
+			FormatLatency(measured), FormatLatency(reference), overhead)
+	}
+	fmt.Fprintln(w)
+}
@@ -0,0 +1,170 @@
+package bench
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata")
+
+// referenceRequests is the number of reference requests in the golden runs:
+// 20 whole cycles of successful requests and the 10 failures among them.
+const referenceRequests = 2010
+
+func TestReferencePercentile(t *testing.T) {
+	tests := []struct {
+		p    float64
+		want time.Duration
+	}{
+		{0, 100 * time.Microsecond},
+		{1, 100 * time.Microsecond},
+		{50, 5 * time.Millisecond},
+		{90, 9 * time.Millisecond},
+		{99, 9900 * time.Microsecond},
+		{99.9, 10 * time.Millisecond},
+		{100, 10 * time.Millisecond},
+	}
+	for _, tt := range tests {
+		if got := ReferencePercentile(tt.p); got != tt.want {
+			t.Errorf("ReferencePercentile(%g) = %v, want %v", tt.p, got, tt.want)
+		}
+	}
+}
+
+func TestReferenceSamples(t *testing.T) {
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	samples := ReferenceSamples(referenceRequests, start)
+
+	seen := make(map[time.Duration]int)
+	failed := 0
+	for i, s := range samples {
+		if !s.Success {
+			failed++
+			if s.Latency != referenceFailLatency || classifyError(s.Error) != "http_5xx" {
+				t.Errorf("sample %d: failed after %v with %v, want %v and a 5xx", i, s.Latency, s.Error, referenceFailLatency)
+			}
+			continue
+		}
+		seen[s.Latency]++
+		if i > 0 && s.Timestamp != samples[i-1].Timestamp.Add(samples[i-1].Latency) {
+			t.Errorf("sample %d issued at %v, want when sample %d returned", i, s.Timestamp, i-1)
+		}
+	}
+	if failed != 10 {
+		t.Errorf("failed samples = %d, want 10", failed)
+	}
+	if len(seen) != referenceCycle {
+		t.Errorf("distinct latencies = %d, want %d", len(seen), referenceCycle)
+	}
+	for latency, n := range seen {
+		if n != 20 {
+			t.Errorf("latency %v taken %d times, want 20", latency, n)
+		}
+	}
+}
+
+// TestReference_Golden feeds the reference requests through the statistics
+// and checks the summary and timeseries byte for byte. Run with -update to
+// rewrite the golden files after an intended change of the output.
+func TestReference_Golden(t *testing.T) {
+	tests := []struct {
+		name   string
+		window *MeasureWindow
+	}{
+		{"reference_summary", nil},
+		{"reference_window", &MeasureWindow{From: 2 * time.Second, To: 8 * time.Second}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+			samples := ReferenceSamples(referenceRequests, start)
+			last := samples[len(samples)-1]
+
+			r := NewResults()
+			r.SetStartTime(start)
+			if tt.window != nil {
+				r.SetMeasureWindow(tt.window)
+			}
+			for _, s := range samples {
+				r.Add(s)
+			}
+			r.SetEndTime(last.Timestamp.Add(last.Latency))
+
+			if tt.window == nil {
+				for _, p := range defaultPercentiles {
+					got, want := r.Percentile(p), ReferencePercentile(p)
+					if diff := got - want; diff < 0 || diff > want/1000 {
+						t.Errorf("Percentile(%g) = %v, want %v within 0.1%%", p, got, want)
+					}
+				}
+			}
+
+			var buf bytes.Buffer
+			r.PrintSummary(&buf, "reference", ReferenceProtocol, 1)
+			fmt.Fprintln(&buf, "Timeseries:")
+			for _, pt := range r.Timeseries() {
+				fmt.Fprintf(&buf, "  %3d %5d %3d %10s %10s\n", pt.Second, pt.Requests, pt.Errors, FormatLatency(pt.P50), FormatLatency(pt.P99))
+			}
+
+			path := filepath.Join("testdata", tt.name+".golden")
+			if *update {
+				if err := os.WriteFile(path, buf.Bytes(), 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			want, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatalf("%v (run with -update to create it)", err)
+			}
+			if !bytes.Equal(buf.Bytes(), want) {
+				t.Errorf("output differs from %s:\n--- got\n%s--- want\n%s", path, buf.Bytes(), want)
+			}
+		})
+	}
+}
+
+func TestRun_Reference(t *testing.T) {
+	report, err := Run(context.Background(), Config{
+		Scenario:    "reference",
+		Concurrency: 2,
+		Duration:    time.Second,
+	})
+	if err != nil {
+		t.Fatalf("Run() error: %v", err)
+	}
+	r := report.Results
+	if r.TotalRequests() < referenceFailEvery {
+		t.Fatalf("TotalRequests() = %d, want at least %d", r.TotalRequests(), referenceFailEvery)
+	}
+	for _, e := range r.ErrorTypes() {
+		if e.Type != "http_5xx" && e.Type != errorTypeCanceled && e.Type != errorTypeTimeout {
+			t.Errorf("unexpected error type %s", e.Type)
+		}
+	}
+	// The stub never answers faster than it was set to
+	if p50 := r.Percentile(50); p50 < ReferencePercentile(50)*9/10 {
+		t.Errorf("p50 = %v, want at least about %v", p50, ReferencePercentile(50))
+	}
+
+	var buf bytes.Buffer
+	PrintReferenceOverhead(&buf, r)
+	if !bytes.Contains(buf.Bytes(), []byte("p50:")) {
+		t.Errorf("overhead report missing p50:\n%s", buf.Bytes())
+	}
+}
+
+func TestReferenceClient_Stream(t *testing.T) {
+	events, errs := NewReferenceClient().StreamTransactions(context.Background(), 0)
+	if _, ok := <-events; ok {
+		t.Error("reference stub sent a stream event")
+	}
+	if err := <-errs; err == nil {
+		t.Error("reference stub streamed without an error")
+	}
+}
@@ -0,0 +1,258 @@
+package bench
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+
+	"github.com/kaldun-tech/grpc-rest-benchmark/pkg/protos"
+)
+
+const (
+	// workerStartDelay is how far ahead the coordinator schedules the start
+	// of a distributed run, so that every worker has received its share and
+	// connected to the server before any of them sends load.
+	workerStartDelay = 2 * time.Second
+
+	// workerGrace is how long after the scheduled end of a distributed run
+	// the coordinator waits for workers to send their last samples.
+	workerGrace = 30 * time.Second
+
+	// maxWorkerMsgSize bounds run requests, which carry every account ID.
+	maxWorkerMsgSize = 256 << 20
+)
+
+// remoteRun generates the load of a run on remote workers.
+type remoteRun struct {
+	addrs []string
+	conns []*grpc.ClientConn
+	specs [][]byte // each worker's share of the run, JSON-encoded
+	first []int    // stream subscribers of the workers before each
+	wait  time.Duration
+}
+
+// dialWorkers prepares a run of cfg on cfg.Workers. Connections are opened
+// when the run starts.
+func dialWorkers(cfg Config) (*remoteRun, error) {
+	r := &remoteRun{addrs: cfg.Workers, wait: workerStartDelay + cfg.RunDuration() + workerGrace}
+	subscribers := 0
+	for i, share := range cfg.workerConfigs(len(cfg.Workers)) {
+		spec, err := json.Marshal(share)
+		if err != nil {
+			r.Close()
+			return nil, fmt.Errorf("failed to encode run for worker %s: %w", cfg.Workers[i], err)
+		}
+		conn, err := grpc.NewClient(cfg.Workers[i],
+			grpc.WithTransportCredentials(insecure.NewCredentials()),
+			grpc.WithDefaultCallOptions(grpc.MaxCallSendMsgSize(maxWorkerMsgSize)),
+		)
+		if err != nil {
+			r.Close()
+			return nil, fmt.Errorf("failed to create client for worker %s: %w", cfg.Workers[i], err)
+		}
+		r.specs = append(r.specs, spec)
+		r.first = append(r.first, subscribers)
+		r.conns = append(r.conns, conn)
+		subscribers += share.StreamSubscribers()
+	}
+	return r, nil
+}
+
+// Close closes the worker connections.
+func (r *remoteRun) Close() {
+	for _, conn := range r.conns {
+		conn.Close()
+	}
+}
+
+// start sends every worker its share of the run, to begin at startAt, and
+// returns a channel of their combined samples. The channel is closed when
+// all workers have finished; wait blocks until then and returns the first
+// worker failure, which stops the other workers. Runs stopped by ctx are
+// not failures.
+func (r *remoteRun) start(ctx context.Context, startAt time.Time) (samples <-chan Sample, wait func() error) {
+	ch := make(chan Sample, 1000)
+	ctx, cancel := context.WithTimeout(ctx, time.Until(startAt)+r.wait)
+
+	var wg sync.WaitGroup
+	var once sync.Once
+	var firstErr error
+	for i, conn := range r.conns {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := runWorker(ctx, conn, r.specs[i], r.first[i], startAt, ch); err != nil {
+				once.Do(func() {
+					firstErr = fmt.Errorf("worker %s: %w", r.addrs[i], err)
+					cancel()
+				})
+			}
+		}()
+	}
+	go func() {
+		wg.Wait()
+		cancel()
+		close(ch)
+	}()
+
+	return ch, func() error {
+		wg.Wait()
+		return firstErr
+	}
+}
+
+// runWorker runs spec on one worker, forwarding its samples to ch. Its
+// stream subscribers are numbered after the first of the run's.
+func runWorker(ctx context.Context, conn *grpc.ClientConn, spec []byte, first int, startAt time.Time, ch chan<- Sample) error {
+	stream, err := protos.NewWorkerServiceClient(conn).Run(ctx, &protos.WorkerRunRequest{
+		Config:        spec,
+		StartUnixNano: startAt.UnixNano(),
+	})
+	if err != nil {
+		return err
+	}
+	for {
+		batch, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			if ctx.Err() != nil && !errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				return nil // stopped by the coordinator
+			}
+			return err
+		}
+		for _, p := range batch.Samples {
+			s := sampleFromProto(p)
+			if s.Subscriber > 0 {
+				s.Subscriber += first
+			}
+			ch <- s
+		}
+	}
+}
+
+// workerConfigs splits the load of c between n workers. Concurrency, rate,
+// stream-balance and fanout subscribers and load profile levels are divided as evenly
+// as integers allow, the first workers taking the remainder.
+func (c *Config) workerConfigs(n int) []Config {
+	configs := make([]Config, n)
+	for i := range configs {
+		share := *c
+		share.Concurrency = splitLoad(c.Concurrency, n, i)
+		share.Rate = splitLoad(c.Rate, n, i)
+		share.Subscribers = splitLoad(c.Subscribers, n, i)
+		if c.LoadProfile != nil {
+			profile := *c.LoadProfile
+			profile.Phases = make([]LoadPhase, len(c.LoadProfile.Phases))
+			for j, phase := range c.LoadProfile.Phases {
+				profile.Phases[j] = LoadPhase{Level: splitLoad(phase.Level, n, i), Duration: phase.Duration}
+			}
+			share.LoadProfile = &profile
+		}
+		configs[i] = share
+	}
+	return configs
+}
+
+// splitLoad returns worker i's share of total split between n workers.
+func splitLoad(total, n, i int) int {
+	share := total / n
+	if i < total%n {
+		share++
+	}
+	return share
+}
+
+// validateWorkers checks that the run can be split between its workers:
+// each needs at least one unit of concurrency, and of every rate, as a
+// zero rate would leave a worker unthrottled. Replayed timing and ordering
+// checks need the whole run in one process.
+func (c *Config) validateWorkers() error {
+	n := len(c.Workers)
+	switch {
+	case c.Client != nil:
+		return fmt.Errorf("a client cannot be used with remote workers")
+	case c.Timing != nil:
+		return fmt.Errorf("timing replay cannot run on remote workers")
+	case c.CheckOrdering:
+		return fmt.Errorf("ordering checks cannot run on remote workers")
+	case c.Concurrency < n:
+		return fmt.Errorf("concurrency %d cannot be split between %d workers", c.Concurrency, n)
+	case c.Rate > 0 && c.Rate < n:
+		return fmt.Errorf("rate %d cannot be split between %d workers", c.Rate, n)
+	case (c.Scenario == "stream-balance" || c.Scenario == "fanout") && c.Subscribers < n:
+		return fmt.Errorf("%d subscribers cannot be split between %d workers", c.Subscribers, n)
+	}
+	if p := c.LoadProfile; p != nil && p.Target == ProfileTargetRate {
+		for _, phase := range p.Phases {
+			if phase.Level < n {
+				return fmt.Errorf("load profile rate %d cannot be split between %d workers", phase.Level, n)
+			}
+		}
+	}
+	return nil
+}
+
+// sampleToProto converts a sample for sending to the coordinator.
+func sampleToProto(s Sample) *protos.WorkerSample {
+	p := &protos.WorkerSample{
+		LatencyNs:         int64(s.Latency),
+		Success:           s.Success,
+		TimestampUnixNano: s.Timestamp.UnixNano(),
+		InterArrivalNs:    int64(s.Stream.InterArrival),
+		DeliveryNs:        int64(s.Stream.Delivery),
+		ProcessingNs:      int64(s.Stream.Processing),
+		EndToEndNs:        int64(s.Stream.EndToEnd),
+		ReconnectNs:       int64(s.Reconnect),
+		MessageBytes:      int64(s.MessageBytes),
+		StalenessNs:       int64(s.Staleness),
+		Phase:             int32(s.Phase),
+		Class:             s.Class,
+		Operation:         s.Operation,
+		Subscriber:        int32(s.Subscriber),
+		DbTimeNs:          int64(s.DBTime),
+		DbTimed:           s.DBTimed,
+	}
+	if s.Error != nil {
+		p.ErrorType = classifyError(s.Error)
+		p.Error = s.Error.Error()
+	}
+	return p
+}
+
+// sampleFromProto converts a sample received from a worker. Its error keeps
+// the type the worker classified it as.
+func sampleFromProto(p *protos.WorkerSample) Sample {
+	s := Sample{
+		Latency:   time.Duration(p.GetLatencyNs()),
+		Success:   p.GetSuccess(),
+		Timestamp: time.Unix(0, p.GetTimestampUnixNano()),
+		Stream: StreamLatencies{
+			InterArrival: time.Duration(p.GetInterArrivalNs()),
+			Delivery:     time.Duration(p.GetDeliveryNs()),
+			Processing:   time.Duration(p.GetProcessingNs()),
+			EndToEnd:     time.Duration(p.GetEndToEndNs()),
+		},
+		Reconnect:    time.Duration(p.GetReconnectNs()),
+		MessageBytes: int(p.GetMessageBytes()),
+		Staleness:    time.Duration(p.GetStalenessNs()),
+		Phase:        int(p.GetPhase()),
+		Class:        p.GetClass(),
+		Operation:    p.GetOperation(),
+		Subscriber:   int(p.GetSubscriber()),
+		DBTime:       time.Duration(p.GetDbTimeNs()),
+		DBTimed:      p.GetDbTimed(),
+	}
+	if p.GetErrorType() != "" {
+		s.Error = &workerError{errType: p.GetErrorType(), msg: p.GetError()}
+	}
+	return s
+}
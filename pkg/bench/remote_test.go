@@ -0,0 +1,169 @@
+package bench
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+
+	"github.com/kaldun-tech/grpc-rest-benchmark/pkg/protos"
+)
+
+// startWorker serves a worker whose runs use client, and returns its
+// address.
+func startWorker(t *testing.T, client BenchmarkClient) string {
+	t.Helper()
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := NewWorker(slog.New(slog.NewTextHandler(io.Discard, nil)))
+	w.newClient = func(Config) (BenchmarkClient, error) { return client, nil }
+	srv := grpc.NewServer()
+	protos.RegisterWorkerServiceServer(srv, w)
+	go srv.Serve(lis)
+	t.Cleanup(srv.Stop)
+	return lis.Addr().String()
+}
+
+func TestRun_Workers(t *testing.T) {
+	clients := []*concurrencyClient{{}, {}}
+	report, err := Run(context.Background(), Config{
+		Scenario:    "balance",
+		Protocol:    "grpc",
+		Addr:        "localhost:50051",
+		Workers:     []string{startWorker(t, clients[0]), startWorker(t, clients[1])},
+		AccountIDs:  []string{"0.0.1001", "0.0.1002"},
+		Concurrency: 3,
+		Duration:    100 * time.Millisecond,
+	})
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	r := report.Results
+	if r.TotalRequests() == 0 || r.SuccessfulRequests() != r.TotalRequests() {
+		t.Errorf("requests = %d, successful = %d; want some, all successful", r.TotalRequests(), r.SuccessfulRequests())
+	}
+	if report.Concurrency != 3 {
+		t.Errorf("Concurrency = %d, want the total 3", report.Concurrency)
+	}
+	for i, want := range []int{2, 1} {
+		if got := clients[i].maxInFlight; got == 0 || got > want {
+			t.Errorf("worker %d max in flight = %d, want its share %d", i, got, want)
+		}
+	}
+}
+
+func TestRun_WorkerUnavailable(t *testing.T) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := lis.Addr().String()
+	lis.Close()
+
+	_, err = Run(context.Background(), Config{
+		Scenario:    "balance",
+		Protocol:    "grpc",
+		Workers:     []string{addr},
+		AccountIDs:  []string{"0.0.1001"},
+		Concurrency: 1,
+		Duration:    100 * time.Millisecond,
+	})
+	if err == nil {
+		t.Error("Run() error = nil, want the worker failure")
+	}
+}
+
+func TestConfig_WorkerConfigs(t *testing.T) {
+	cfg := Config{
+		Concurrency: 10,
+		Rate:        101,
+		LoadProfile: &LoadProfile{
+			Target: ProfileTargetRate,
+			Phases: []LoadPhase{{Level: 5, Duration: time.Second}, {Level: 9, Duration: time.Second}},
+		},
+	}
+	shares := cfg.workerConfigs(3)
+
+	var concurrency, rate int
+	for _, s := range shares {
+		concurrency += s.Concurrency
+		rate += s.Rate
+	}
+	if concurrency != 10 || rate != 101 {
+		t.Errorf("shares sum to concurrency %d and rate %d, want 10 and 101", concurrency, rate)
+	}
+	if got := shares[0].LoadProfile.Phases[0].Level; got != 2 {
+		t.Errorf("first share of phase level 5 = %d, want 2", got)
+	}
+	if got := shares[2].LoadProfile.Phases[0].Level; got != 1 {
+		t.Errorf("last share of phase level 5 = %d, want 1", got)
+	}
+	if cfg.LoadProfile.Phases[0].Level != 5 {
+		t.Error("workerConfigs() modified the configured load profile")
+	}
+}
+
+func TestConfig_ValidateWorkers(t *testing.T) {
+	valid := Config{
+		Scenario:    "balance",
+		Protocol:    "grpc",
+		Workers:     []string{"a:1", "b:1"},
+		AccountIDs:  []string{"0.0.1001"},
+		Concurrency: 2,
+		Duration:    time.Second,
+	}
+	if err := valid.validate(); err != nil {
+		t.Fatalf("validate() error = %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		modify func(c *Config)
+	}{
+		{"concurrency below workers", func(c *Config) { c.Concurrency = 1 }},
+		{"rate below workers", func(c *Config) { c.Rate = 1 }},
+		{"client", func(c *Config) { c.Client = &concurrencyClient{} }},
+		{"ordering", func(c *Config) { c.CheckOrdering = true }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := valid
+			tt.modify(&cfg)
+			if err := cfg.validate(); err == nil {
+				t.Error("validate() error = nil, want an error")
+			}
+		})
+	}
+}
+
+func TestSampleProto(t *testing.T) {
+	s := Sample{
+		Latency:   3 * time.Millisecond,
+		Error:     context.DeadlineExceeded,
+		Timestamp: time.Unix(100, 5),
+		Stream:    StreamLatencies{Delivery: time.Millisecond},
+		Reconnect: 40 * time.Millisecond,
+		Phase:     2,
+		Operation: OpGetBalance,
+		DBTime:    time.Millisecond,
+		DBTimed:   true,
+
+		MessageBytes: 212,
+	}
+	got := sampleFromProto(sampleToProto(s))
+	if got.Latency != s.Latency || !got.Timestamp.Equal(s.Timestamp) || got.Stream != s.Stream ||
+		got.Reconnect != s.Reconnect || got.MessageBytes != s.MessageBytes || got.Phase != 2 || got.Operation != OpGetBalance || got.DBTime != s.DBTime || !got.DBTimed {
+		t.Errorf("round trip = %+v, want %+v", got, s)
+	}
+	if errors.Is(got.Error, context.DeadlineExceeded) || classifyError(got.Error) != errorTypeTimeout {
+		t.Errorf("error = %v classified %q, want a worker error classified %q", got.Error, classifyError(got.Error), errorTypeTimeout)
+	}
+}
@@ -0,0 +1,1551 @@
+package bench
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/rand"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/HdrHistogram/hdrhistogram-go"
+
+	"github.com/kaldun-tech/grpc-rest-benchmark/pkg/db"
+)
+
+// Histogram bounds for latency tracking. Values are recorded in microseconds
+// with 3 significant digits, so any reported percentile is within 0.1% of the
+// true value while memory stays constant regardless of run length.
+const (
+	histogramMinMicros = 1
+	histogramMaxMicros = int64(time.Hour / time.Microsecond)
+	histogramSigFigs   = 3
+
+	// Per-subscriber latencies are tracked with 2 significant digits, as a
+	// run may open thousands of streams.
+	subscriberSigFigs = 2
+
+	// Balance staleness is tracked in milliseconds up to 30 days, since
+	// seeded balances may not have been touched for a long time.
+	stalenessMaxMillis = int64(30 * 24 * time.Hour / time.Millisecond)
+
+	// Stream message sizes are tracked in bytes up to the 4 MB gRPC
+	// default message size limit.
+	messageMaxBytes = 4 << 20
+)
+
+// defaultPercentiles are the latency percentiles printed in the summary
+// unless --percentiles says otherwise.
+var defaultPercentiles = []float64{50, 90, 99, 99.9}
+
+// histogramWidth is the width in characters of the longest histogram bar.
+const histogramWidth = 40
+
+// Results collects and analyzes benchmark samples.
+//
+// Latency statistics are computed incrementally from an HDR histogram, so
+// percentile queries are cheap and memory is bounded. Raw samples are only
+// retained for persistence to benchmark_samples; when a cap is set via
+// SetMaxStoredSamples, a uniform reservoir of that size is kept instead.
+type Results struct {
+	samples       []Sample
+	maxSamples    int // 0 = retain every sample
+	total         int
+	successful    int
+	errorTypes    map[string]int // failed requests per error type
+	latencies     *hdrhistogram.Histogram
+	latencySum    time.Duration
+	minLatency    time.Duration
+	maxLatency    time.Duration
+	rng           *rand.Rand
+	streamMetric  string                             // primary stream latency definition, empty for unary scenarios
+	streamHists   map[string]*hdrhistogram.Histogram // per stream latency definition
+	staleness     *hdrhistogram.Histogram            // balance staleness in ms, nil until measured
+	reconnects    *hdrhistogram.Histogram            // time streams were disconnected before resuming, nil until one resumed
+	messageSizes  *hdrhistogram.Histogram            // serialized stream message sizes in bytes, nil until measured
+	dbTimes       *hdrhistogram.Histogram            // database time servers reported, nil until reported
+	networkTimes  *hdrhistogram.Histogram            // the rest of those requests' latency
+	interval      time.Duration                      // expected request interval for coordinated-omission correction
+	corrected     *hdrhistogram.Histogram            // latencies corrected for coordinated omission, nil if disabled
+	profile       *LoadProfile                       // nil for a fixed load
+	phases        []groupResults                     // per load profile phase
+	classes       map[string]*groupResults           // per workload class, nil unless samples carry one
+	subscribers   map[int]*groupResults              // per stream subscriber, nil unless samples carry one
+	percentiles   []float64                          // latency percentiles printed in the summary
+	histogram     bool                               // print the latency histogram in the summary
+	timeseries    *timeseries                        // per-second aggregates, nil until the start time is set
+	streams       int                                // stream subscribers started
+	streamEnds    int                                // streams the server ended with a sent count
+	shortfall     int64                              // transactions those streams sent but did not deliver
+	streamSent    [2]int64                           // fewest and most transactions one of those streams sent
+	startTime     time.Time
+	endTime       time.Time
+	resourceStats *ResourceStats
+	transfer      *Transfer       // bytes the client moved, nil unless it counts them
+	serverCPU     *float64        // CPU-seconds the server used during the run, nil if unknown
+	cacheHits     int64           // GetBalance calls the server's balance cache answered during the run
+	cacheMisses   int64           // and passed on to the database
+	poolWaits     *int64          // server database connection acquires that waited during the run, nil if unknown
+	poolFailures  *int64          // and that were given up
+	gcPauses      []GCPause       // GC pauses of the client during the run, in order
+	gcTracked     bool            // gcPauses were recorded
+	restart       *restartTracker // nil unless the server is restarted during the run
+	costModel     CostModel
+
+	// With a measure window, only the requests issued in it feed the
+	// statistics above but for subscribers and the timeseries; full
+	// covers every request.
+	full       groupResults
+	window     *MeasureWindow
+	windowFrom time.Duration
+	windowTo   time.Duration // 0 for the end of the run
+	windowed   bool          // windowFrom and windowTo are set
+}
+
+// groupResults holds the statistics for one load profile phase, workload
+// class or stream subscriber.
+type groupResults struct {
+	total      int
+	successful int
+	latencies  *hdrhistogram.Histogram
+}
+
+// NewResults creates a new Results collector.
+func NewResults() *Results {
+	return &Results{
+		samples:     make([]Sample, 0, 10000),
+		latencies:   newLatencyHistogram(),
+		rng:         rand.New(rand.NewSource(time.Now().UnixNano())),
+		percentiles: defaultPercentiles,
+		histogram:   true,
+		full:        groupResults{latencies: newLatencyHistogram()},
+	}
+}
+
+// newLatencyHistogram creates an empty latency histogram.
+func newLatencyHistogram() *hdrhistogram.Histogram {
+	return hdrhistogram.New(histogramMinMicros, histogramMaxMicros, histogramSigFigs)
+}
+
+// SetMaxStoredSamples caps the number of raw samples retained for storage.
+// Statistics still cover every sample. Zero disables the cap.
+func (r *Results) SetMaxStoredSamples(n int) {
+	r.maxSamples = n
+}
+
+// SetStreamMetric records which stream latency definition populates the
+// primary latency statistics.
+func (r *Results) SetStreamMetric(metric string) {
+	r.streamMetric = metric
+}
+
+// SetExpectedInterval enables coordinated-omission correction for a
+// closed-loop run in which each worker intends to send one request per
+// interval. Latencies longer than the interval also record the requests that
+// would have been sent during the stall, as HdrHistogram's
+// RecordCorrectedValue does. Must be called before samples are added.
+func (r *Results) SetExpectedInterval(interval time.Duration) {
+	r.interval = interval
+	if interval > 0 {
+		r.corrected = newLatencyHistogram()
+	} else {
+		r.corrected = nil
+	}
+}
+
+// SetLoadProfile enables per-phase statistics for samples tagged with a
+// load profile phase.
+func (r *Results) SetLoadProfile(p *LoadProfile) {
+	r.profile = p
+	r.phases = make([]groupResults, len(p.Phases))
+	for i := range r.phases {
+		r.phases[i].latencies = newLatencyHistogram()
+	}
+}
+
+// SetMeasureWindow restricts the statistics to the requests issued in w.
+// An automatic window measures the whole run until Run detects its steady
+// state. Must be called before samples are added.
+func (r *Results) SetMeasureWindow(w *MeasureWindow) {
+	r.window = w
+	if !w.Auto {
+		r.windowFrom, r.windowTo, r.windowed = w.From, w.To, true
+	}
+}
+
+// SetPercentiles sets the latency percentiles (0-100) printed in the summary.
+func (r *Results) SetPercentiles(ps []float64) {
+	r.percentiles = ps
+}
+
+// SetHistogram enables or disables the latency histogram in the summary.
+func (r *Results) SetHistogram(enabled bool) {
+	r.histogram = enabled
+}
+
+// SetStartTime records when the benchmark started.
+func (r *Results) SetStartTime(t time.Time) {
+	r.startTime = t
+	r.timeseries = newTimeseries(t)
+}
+
+// SetEndTime records when the benchmark ended.
+func (r *Results) SetEndTime(t time.Time) {
+	r.endTime = t
+}
+
+// SetStreams records how many stream subscribers the run started.
+func (r *Results) SetStreams(n int) {
+	r.streams = n
+}
+
+// SetStreamEnds records how many streams the server ended with a sent count
+// and the transactions those did not deliver.
+func (r *Results) SetStreamEnds(ended int, shortfall int64) {
+	r.streamEnds = ended
+	r.shortfall = shortfall
+}
+
+// SetStreamSent records the fewest and most transactions a stream the
+// server ended reported sending.
+func (r *Results) SetStreamSent(fewest, most int64) {
+	r.streamSent = [2]int64{fewest, most}
+}
+
+// SetResourceStats records resource usage metrics.
+func (r *Results) SetResourceStats(stats ResourceStats) {
+	r.resourceStats = &stats
+}
+
+// SetTransfer records the bytes the client moved during the run.
+func (r *Results) SetTransfer(t Transfer) {
+	r.transfer = &t
+}
+
+// Transfer returns the bytes the client moved during the run, and false if
+// they were not counted.
+func (r *Results) Transfer() (Transfer, bool) {
+	if r.transfer == nil {
+		return Transfer{}, false
+	}
+	return *r.transfer, true
+}
+
+// SetServerCPUSeconds records the CPU time the server used during the run.
+func (r *Results) SetServerCPUSeconds(seconds float64) {
+	r.serverCPU = &seconds
+}
+
+// SetServerCache records the server balance cache hits and misses during
+// the run.
+func (r *Results) SetServerCache(hits, misses int64) {
+	r.cacheHits, r.cacheMisses = hits, misses
+}
+
+// SetGCPauses records the GC pauses of the client during the run and tags
+// the retained samples in flight during one.
+func (r *Results) SetGCPauses(pauses []GCPause) {
+	r.gcPauses = pauses
+	r.gcTracked = true
+	for i := range r.samples {
+		r.samples[i].GCPause = overlapsGCPause(r.samples[i], pauses)
+	}
+}
+
+// GCPauses returns the number and total length of the client's GC pauses
+// during the run. ok is false if they were not recorded, as when remote
+// workers generate the load.
+func (r *Results) GCPauses() (n int, total time.Duration, ok bool) {
+	for _, p := range r.gcPauses {
+		total += p.End.Sub(p.Start)
+	}
+	return len(r.gcPauses), total, r.gcTracked
+}
+
+// GCTail returns how many of the slowest 1% of the retained samples are
+// tagged as overlapping a client GC pause, out of how many. The rest of
+// the tail is down to the server and network. ok is false if GC pauses
+// were not recorded or no request succeeded.
+func (r *Results) GCTail() (paused, tail int, ok bool) {
+	if !r.gcTracked {
+		return 0, 0, false
+	}
+	var measured []Sample
+	for _, s := range r.samples {
+		if s.Success && s.Latency > 0 && r.inWindow(s) {
+			measured = append(measured, s)
+		}
+	}
+	if len(measured) == 0 {
+		return 0, 0, false
+	}
+	sort.Slice(measured, func(i, j int) bool { return measured[i].Latency > measured[j].Latency })
+	tail = (len(measured) + 99) / 100
+	for _, s := range measured[:tail] {
+		if s.GCPause {
+			paused++
+		}
+	}
+	return paused, tail, true
+}
+
+// SetServerPool records the server's database connection acquires that
+// waited for a free connection during the run, and those given up.
+func (r *Results) SetServerPool(waits, failures int64) {
+	r.poolWaits = &waits
+	r.poolFailures = &failures
+}
+
+// CacheHitRate returns the fraction of GetBalance calls the server's balance
+// cache answered during the run. It is unavailable if the server has no
+// cache or the run made no GetBalance calls.
+func (r *Results) CacheHitRate() (float64, bool) {
+	lookups := r.cacheHits + r.cacheMisses
+	if lookups <= 0 {
+		return 0, false
+	}
+	return float64(r.cacheHits) / float64(lookups), true
+}
+
+// ClientEfficiency returns the requests, or messages in stream scenarios,
+// handled per CPU-second of the benchmark client.
+func (r *Results) ClientEfficiency() (float64, bool) {
+	if r.resourceStats == nil {
+		return 0, false
+	}
+	return perCPUSecond(r.full.total, r.resourceStats.CPUSeconds)
+}
+
+// ServerEfficiency returns the requests, or messages in stream scenarios,
+// handled per CPU-second of the server.
+func (r *Results) ServerEfficiency() (float64, bool) {
+	if r.serverCPU == nil {
+		return 0, false
+	}
+	return perCPUSecond(r.full.total, *r.serverCPU)
+}
+
+// EfficiencyUnit names what the efficiency counts: stream events for runs
+// with streams, requests otherwise.
+func (r *Results) EfficiencyUnit() string {
+	if r.streamMetric != "" {
+		return "messages"
+	}
+	return "requests"
+}
+
+// perCPUSecond divides a count by CPU time, or returns false if no CPU time
+// was measured.
+func perCPUSecond(n int, cpuSeconds float64) (float64, bool) {
+	if cpuSeconds <= 0 {
+		return 0, false
+	}
+	return float64(n) / cpuSeconds, true
+}
+
+// SetCostModel sets the prices the run's resource usage is costed at.
+func (r *Results) SetCostModel(m CostModel) {
+	r.costModel = m
+}
+
+// Cost returns the estimated cost of the run and of one million of its
+// requests. ok is false without a cost model or resource stats.
+func (r *Results) Cost() (total, perMillion float64, ok bool) {
+	total, ok = r.costModel.Estimate(r.resourceStats)
+	if !ok {
+		return 0, 0, false
+	}
+	perMillion, ok = costPerMillion(total, int64(r.full.total))
+	return total, perMillion, ok
+}
+
+// Add adds a sample to the results.
+func (r *Results) Add(s Sample) {
+	r.full.add(s)
+	if s.Subscriber > 0 {
+		r.subscriber(s.Subscriber).add(s)
+	}
+	if r.timeseries != nil {
+		r.timeseries.add(s)
+	}
+	if r.restart != nil {
+		r.restart.add(s)
+	}
+	r.retain(s)
+	if r.inWindow(s) {
+		r.measure(s)
+	}
+}
+
+// measure records a sample in the statistics of the measure window.
+func (r *Results) measure(s Sample) {
+	r.total++
+	if s.Success {
+		r.successful++
+	} else {
+		r.countError(s.Error)
+	}
+	if s.Success && s.Latency > 0 {
+		r.recordLatency(s.Latency)
+	}
+	if s.Success {
+		r.recordStreamLatencies(s.Stream)
+	}
+	if s.Success && s.Staleness > 0 {
+		r.recordStaleness(s.Staleness)
+	}
+	if s.Success && s.Reconnect > 0 {
+		r.recordReconnect(s.Reconnect)
+	}
+	if s.Success && s.MessageBytes > 0 {
+		r.recordMessageSize(s.MessageBytes)
+	}
+	if s.Success && s.DBTimed {
+		r.recordDBTime(s.Latency, s.DBTime)
+	}
+	if s.Phase > 0 && s.Phase <= len(r.phases) {
+		r.phases[s.Phase-1].add(s)
+	}
+	if s.Class != "" {
+		r.class(s.Class).add(s)
+	}
+}
+
+// inWindow reports whether s was issued in the measure window, or true
+// without one.
+func (r *Results) inWindow(s Sample) bool {
+	if !r.windowed {
+		return true
+	}
+	at := s.Timestamp.Sub(r.startTime)
+	return at >= r.windowFrom && (r.windowTo == 0 || at < r.windowTo)
+}
+
+// detectSteadyState resolves an automatic measure window once every sample
+// has been added, and measures the samples issued in it again. It fails,
+// leaving the whole run measured, if the run has no steady state or not
+// every sample was retained.
+func (r *Results) detectSteadyState() error {
+	if len(r.samples) < r.full.total {
+		return fmt.Errorf("cannot detect the steady state from a sample of %d of %d requests", len(r.samples), r.full.total)
+	}
+	from, to, ok := steadyState(r.Timeseries(), int(r.Duration()/time.Second))
+	if !ok {
+		return fmt.Errorf("no steady state detected")
+	}
+	r.windowFrom, r.windowTo, r.windowed = time.Duration(from)*time.Second, time.Duration(to)*time.Second, true
+
+	r.total, r.successful, r.errorTypes = 0, 0, nil
+	r.latencies.Reset()
+	r.latencySum, r.minLatency, r.maxLatency = 0, 0, 0
+	if r.corrected != nil {
+		r.corrected.Reset()
+	}
+	r.streamHists, r.staleness, r.dbTimes, r.networkTimes, r.classes = nil, nil, nil, nil, nil
+	r.messageSizes = nil
+	for _, s := range r.samples {
+		if r.inWindow(s) {
+			r.measure(s)
+		}
+	}
+	return nil
+}
+
+// MeasuredWindow returns the part of the run its statistics cover. ok is
+// false if they cover the whole run.
+func (r *Results) MeasuredWindow() (from, to time.Duration, ok bool) {
+	if !r.windowed {
+		return 0, 0, false
+	}
+	to = r.Duration()
+	if r.windowTo > 0 && r.windowTo < to {
+		to = r.windowTo
+	}
+	return r.windowFrom, max(to, r.windowFrom), true
+}
+
+// MeasuredDuration returns the length of the measure window, or of the
+// whole run without one.
+func (r *Results) MeasuredDuration() time.Duration {
+	from, to, ok := r.MeasuredWindow()
+	if !ok {
+		return r.Duration()
+	}
+	return to - from
+}
+
+// FullRequests returns the requests of the whole run, including those
+// issued outside the measure window.
+func (r *Results) FullRequests() int {
+	return r.full.total
+}
+
+// FullThroughput returns the requests per second of the whole run.
+func (r *Results) FullThroughput() float64 {
+	duration := r.Duration().Seconds()
+	if duration == 0 {
+		return 0
+	}
+	return float64(r.full.total) / duration
+}
+
+// FullPercentile returns the latency at percentile p of the whole run.
+func (r *Results) FullPercentile(p float64) time.Duration {
+	return r.full.percentile(p)
+}
+
+func (p *groupResults) add(s Sample) {
+	p.total++
+	if s.Success {
+		p.successful++
+		if s.Latency > 0 {
+			p.latencies.RecordValue(clampMicros(s.Latency))
+		}
+	}
+}
+
+// countError counts a failed request by error type.
+func (r *Results) countError(err error) {
+	if r.errorTypes == nil {
+		r.errorTypes = make(map[string]int)
+	}
+	errType := classifyError(err)
+	if errType == "" {
+		errType = errorTypeOther
+	}
+	r.errorTypes[errType]++
+}
+
+// ErrorTypeCount is the number of failed requests with one error type.
+type ErrorTypeCount struct {
+	Type  string
+	Count int
+}
+
+// ErrorTypes returns the failed requests counted per error type, most
+// frequent first.
+func (r *Results) ErrorTypes() []ErrorTypeCount {
+	counts := make([]ErrorTypeCount, 0, len(r.errorTypes))
+	for t, n := range r.errorTypes {
+		counts = append(counts, ErrorTypeCount{Type: t, Count: n})
+	}
+	sort.Slice(counts, func(i, j int) bool {
+		if counts[i].Count != counts[j].Count {
+			return counts[i].Count > counts[j].Count
+		}
+		return counts[i].Type < counts[j].Type
+	})
+	return counts
+}
+
+// class returns the statistics of a workload class, creating them on first use.
+func (r *Results) class(name string) *groupResults {
+	if r.classes == nil {
+		r.classes = make(map[string]*groupResults)
+	}
+	g, ok := r.classes[name]
+	if !ok {
+		g = &groupResults{latencies: newLatencyHistogram()}
+		r.classes[name] = g
+	}
+	return g
+}
+
+// subscriber returns the statistics of a stream subscriber, creating them
+// on first use.
+func (r *Results) subscriber(n int) *groupResults {
+	if r.subscribers == nil {
+		r.subscribers = make(map[int]*groupResults)
+	}
+	g, ok := r.subscribers[n]
+	if !ok {
+		g = &groupResults{latencies: hdrhistogram.New(histogramMinMicros, histogramMaxMicros, subscriberSigFigs)}
+		r.subscribers[n] = g
+	}
+	return g
+}
+
+// SubscriberStats are the events one stream subscriber received and their
+// primary stream latency.
+type SubscriberStats struct {
+	Subscriber int // counted from 1
+	Events     int
+	Errors     int
+	P50        time.Duration
+	P99        time.Duration
+}
+
+// StreamsEstablished returns how many of the run's stream subscribers
+// established their stream, receiving at least one event before it failed
+// or the run ended. ok is false if the run started no streams.
+func (r *Results) StreamsEstablished() (established, streams int, ok bool) {
+	if r.streams == 0 {
+		return 0, 0, false
+	}
+	for _, g := range r.subscribers {
+		if g.successful > 0 {
+			established++
+		}
+	}
+	return established, r.streams, true
+}
+
+// SubscriberStats returns the statistics of each stream subscriber that
+// received an event or failed, in subscriber order.
+func (r *Results) SubscriberStats() []SubscriberStats {
+	stats := make([]SubscriberStats, 0, len(r.subscribers))
+	for n, g := range r.subscribers {
+		stats = append(stats, SubscriberStats{
+			Subscriber: n,
+			Events:     g.successful,
+			Errors:     g.total - g.successful,
+			P50:        g.percentile(50),
+			P99:        g.percentile(99),
+		})
+	}
+	sort.Slice(stats, func(i, j int) bool { return stats[i].Subscriber < stats[j].Subscriber })
+	return stats
+}
+
+// ClassNames returns the workload classes seen in the samples, sorted.
+func (r *Results) ClassNames() []string {
+	names := make([]string, 0, len(r.classes))
+	for name := range r.classes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ClassPercentile returns the latency at percentile p of one workload class.
+func (r *Results) ClassPercentile(class string, p float64) time.Duration {
+	g, ok := r.classes[class]
+	if !ok {
+		return 0
+	}
+	return g.percentile(p)
+}
+
+// percentile returns the group latency at percentile p.
+func (p *groupResults) percentile(q float64) time.Duration {
+	if p.latencies.TotalCount() == 0 {
+		return 0
+	}
+	return time.Duration(p.latencies.ValueAtQuantile(q)) * time.Microsecond
+}
+
+// recordStreamLatencies records every available stream latency definition.
+func (r *Results) recordStreamLatencies(l StreamLatencies) {
+	for _, metric := range StreamMetrics {
+		d := l.Get(metric)
+		if d <= 0 {
+			continue
+		}
+		if r.streamHists == nil {
+			r.streamHists = make(map[string]*hdrhistogram.Histogram)
+		}
+		h, ok := r.streamHists[metric]
+		if !ok {
+			h = newLatencyHistogram()
+			r.streamHists[metric] = h
+		}
+		h.RecordValue(clampMicros(d))
+	}
+}
+
+// recordStaleness records the age of a returned balance.
+func (r *Results) recordStaleness(d time.Duration) {
+	if r.staleness == nil {
+		r.staleness = hdrhistogram.New(1, stalenessMaxMillis, histogramSigFigs)
+	}
+	millis := min(max(d.Milliseconds(), 1), stalenessMaxMillis)
+	r.staleness.RecordValue(millis)
+}
+
+// recordReconnect records how long a stream was disconnected before it
+// resumed.
+func (r *Results) recordReconnect(d time.Duration) {
+	if r.reconnects == nil {
+		r.reconnects = newLatencyHistogram()
+	}
+	r.reconnects.RecordValue(clampMicros(d))
+}
+
+// recordMessageSize records the serialized size of a stream message.
+func (r *Results) recordMessageSize(n int) {
+	if r.messageSizes == nil {
+		r.messageSizes = hdrhistogram.New(1, messageMaxBytes, histogramSigFigs)
+	}
+	r.messageSizes.RecordValue(min(int64(n), messageMaxBytes))
+}
+
+// clampMicros converts d to microseconds within the histogram bounds.
+func clampMicros(d time.Duration) int64 {
+	micros := d.Microseconds()
+	if micros < histogramMinMicros {
+		return histogramMinMicros
+	}
+	if micros > histogramMaxMicros {
+		return histogramMaxMicros
+	}
+	return micros
+}
+
+// recordDBTime splits the latency of a request into the database time the
+// server reported and the rest: network, serialization and server overhead.
+// Both may be zero, e.g. for cached balances.
+func (r *Results) recordDBTime(latency, db time.Duration) {
+	if r.dbTimes == nil {
+		r.dbTimes = newLatencyHistogram()
+		r.networkTimes = newLatencyHistogram()
+	}
+	micros := func(d time.Duration) int64 { return min(max(d.Microseconds(), 0), histogramMaxMicros) }
+	r.dbTimes.RecordValue(micros(db))
+	r.networkTimes.RecordValue(micros(latency - db))
+}
+
+// recordLatency updates the histogram and exact aggregates for a successful sample.
+func (r *Results) recordLatency(d time.Duration) {
+	r.latencies.RecordValue(clampMicros(d))
+	if r.corrected != nil {
+		r.corrected.RecordCorrectedValue(clampMicros(d), r.interval.Microseconds())
+	}
+
+	if r.latencies.TotalCount() == 1 || d < r.minLatency {
+		r.minLatency = d
+	}
+	if d > r.maxLatency {
+		r.maxLatency = d
+	}
+	r.latencySum += d
+}
+
+// retain keeps the sample for storage, using reservoir sampling once the
+// configured cap is reached.
+func (r *Results) retain(s Sample) {
+	if r.maxSamples <= 0 || len(r.samples) < r.maxSamples {
+		r.samples = append(r.samples, s)
+		return
+	}
+	if j := r.rng.Intn(r.full.total); j < r.maxSamples {
+		r.samples[j] = s
+	}
+}
+
+// Collect reads all samples from a channel into results.
+func (r *Results) Collect(ch <-chan Sample) {
+	for sample := range ch {
+		r.Add(sample)
+	}
+}
+
+// TotalRequests returns the total number of requests.
+func (r *Results) TotalRequests() int {
+	return r.total
+}
+
+// SuccessfulRequests returns the count of successful requests.
+func (r *Results) SuccessfulRequests() int {
+	return r.successful
+}
+
+// StoredSamples returns the number of raw samples retained for storage.
+func (r *Results) StoredSamples() int {
+	return len(r.samples)
+}
+
+// ErrorRate returns the percentage of failed requests.
+func (r *Results) ErrorRate() float64 {
+	if r.total == 0 {
+		return 0
+	}
+	errors := r.total - r.successful
+	return float64(errors) / float64(r.total) * 100
+}
+
+// Throughput returns requests per second over the measure window.
+func (r *Results) Throughput() float64 {
+	duration := r.MeasuredDuration().Seconds()
+	if duration == 0 {
+		return 0
+	}
+	return float64(r.total) / duration
+}
+
+// Timeseries returns the per-second aggregates of the run, by the second each
+// request was issued, with the client's runtime samples of the same second.
+// Call it once all samples have been added.
+func (r *Results) Timeseries() []TimeseriesPoint {
+	if r.timeseries == nil {
+		return nil
+	}
+	points := r.timeseries.finish()
+	if r.resourceStats != nil {
+		// The monitor starts shortly before the run
+		offset := int(r.resourceStats.runtimeStart.Sub(r.startTime).Round(time.Second) / time.Second)
+		for i := range r.resourceStats.Runtime {
+			s := &r.resourceStats.Runtime[i]
+			if sec := s.Second + offset; sec >= 0 && sec < len(points) {
+				points[sec].Runtime = s
+			}
+		}
+	}
+	return points
+}
+
+// Duration returns the benchmark duration.
+func (r *Results) Duration() time.Duration {
+	return r.endTime.Sub(r.startTime)
+}
+
+// Percentile returns the latency at the given percentile (0-100).
+func (r *Results) Percentile(p float64) time.Duration {
+	if r.latencies.TotalCount() == 0 {
+		return 0
+	}
+	return time.Duration(r.latencies.ValueAtQuantile(p)) * time.Microsecond
+}
+
+// CorrectedPercentile returns the latency at percentile p corrected for
+// coordinated omission. ok is false if correction is disabled.
+func (r *Results) CorrectedPercentile(p float64) (d time.Duration, ok bool) {
+	if r.corrected == nil || r.corrected.TotalCount() == 0 {
+		return 0, false
+	}
+	return time.Duration(r.corrected.ValueAtQuantile(p)) * time.Microsecond, true
+}
+
+// StreamPercentile returns the latency at percentile p for the given stream
+// latency definition. ok is false if no event carried that measurement.
+func (r *Results) StreamPercentile(metric string, p float64) (d time.Duration, ok bool) {
+	h, found := r.streamHists[metric]
+	if !found || h.TotalCount() == 0 {
+		return 0, false
+	}
+	return time.Duration(h.ValueAtQuantile(p)) * time.Microsecond, true
+}
+
+// StalenessPercentile returns the balance staleness at percentile p. ok is
+// false if staleness was not measured.
+func (r *Results) StalenessPercentile(p float64) (d time.Duration, ok bool) {
+	if r.staleness == nil || r.staleness.TotalCount() == 0 {
+		return 0, false
+	}
+	return time.Duration(r.staleness.ValueAtQuantile(p)) * time.Millisecond, true
+}
+
+// Restart returns how the run weathered the server restart. ok is false
+// unless the server was restarted during the run.
+func (r *Results) Restart() (stats RestartStats, ok bool) {
+	if r.restart == nil {
+		return RestartStats{}, false
+	}
+	return r.restart.stats()
+}
+
+// Reconnects returns how many times streams resumed after a disconnect.
+func (r *Results) Reconnects() int {
+	if r.reconnects == nil {
+		return 0
+	}
+	return int(r.reconnects.TotalCount())
+}
+
+// ReconnectPercentile returns, at percentile p, how long streams were
+// disconnected before resuming: from the disconnect to the first event of
+// the reopened stream. ok is false if no stream resumed.
+func (r *Results) ReconnectPercentile(p float64) (d time.Duration, ok bool) {
+	if r.reconnects == nil {
+		return 0, false
+	}
+	return time.Duration(r.reconnects.ValueAtQuantile(p)) * time.Microsecond, true
+}
+
+// MessageSizePercentile returns the serialized size in bytes of the stream
+// messages at percentile p. ok is false if no message was measured.
+func (r *Results) MessageSizePercentile(p float64) (n int, ok bool) {
+	if r.messageSizes == nil {
+		return 0, false
+	}
+	return int(r.messageSizes.ValueAtQuantile(p)), true
+}
+
+// DBTimePercentile returns the database time servers reported at
+// percentile p. ok is false if no server reported it.
+func (r *Results) DBTimePercentile(p float64) (d time.Duration, ok bool) {
+	if r.dbTimes == nil {
+		return 0, false
+	}
+	return time.Duration(r.dbTimes.ValueAtQuantile(p)) * time.Microsecond, true
+}
+
+// NetworkPercentile returns, at percentile p, the latency of requests
+// whose database time servers reported less that time: network,
+// serialization and server overhead. ok is false if no server reported it.
+func (r *Results) NetworkPercentile(p float64) (d time.Duration, ok bool) {
+	if r.networkTimes == nil {
+		return 0, false
+	}
+	return time.Duration(r.networkTimes.ValueAtQuantile(p)) * time.Microsecond, true
+}
+
+// AvgLatency returns the average latency of successful requests.
+func (r *Results) AvgLatency() time.Duration {
+	count := r.latencies.TotalCount()
+	if count == 0 {
+		return 0
+	}
+	return r.latencySum / time.Duration(count)
+}
+
+// MinLatency returns the minimum latency.
+func (r *Results) MinLatency() time.Duration {
+	return r.minLatency
+}
+
+// MaxLatency returns the maximum latency.
+func (r *Results) MaxLatency() time.Duration {
+	return r.maxLatency
+}
+
+// PrintSummary writes a formatted summary to w.
+func (r *Results) PrintSummary(w io.Writer, scenario, protocol string, concurrency int) {
+	fmt.Fprintf(w, "\nBenchmark: %s / %s\n", scenario, protocol)
+	fmt.Fprintf(w, "Duration: %s | Concurrency: %d\n", r.Duration().Round(time.Second), concurrency)
+	if from, to, ok := r.MeasuredWindow(); ok {
+		steady := ""
+		if r.window.Auto {
+			steady = " (steady state)"
+		}
+		fmt.Fprintf(w, "Measured: %s of the run%s\n", formatWindow(from, to.Round(time.Second)), steady)
+	}
+	fmt.Fprintln(w, "---------------------------------")
+	fmt.Fprintf(w, "Requests:    %d\n", r.TotalRequests())
+	fmt.Fprintf(w, "Throughput:  %.2f req/s\n", r.Throughput())
+	fmt.Fprintln(w, "Latency:")
+	for _, p := range r.percentiles {
+		fmt.Fprintf(w, "  %-7s %s\n", fmt.Sprintf("p%g:", p), FormatLatency(r.Percentile(p)))
+	}
+	fmt.Fprintf(w, "  %-7s %s\n", "avg:", FormatLatency(r.AvgLatency()))
+	fmt.Fprintf(w, "  %-7s %s\n", "min:", FormatLatency(r.MinLatency()))
+	fmt.Fprintf(w, "  %-7s %s\n", "max:", FormatLatency(r.MaxLatency()))
+	if _, ok := r.CorrectedPercentile(50); ok {
+		fmt.Fprintf(w, "Latency (corrected for coordinated omission, interval %s):\n", r.interval)
+		for _, p := range r.percentiles {
+			d, _ := r.CorrectedPercentile(p)
+			fmt.Fprintf(w, "  %-7s %s\n", fmt.Sprintf("p%g:", p), FormatLatency(d))
+		}
+	}
+	if buckets := r.LatencyHistogram(); r.histogram && len(buckets) > 0 {
+		fmt.Fprintln(w, "Latency histogram:")
+		printHistogram(w, buckets)
+	}
+	fmt.Fprintf(w, "Errors:      %d (%.2f%%)\n", r.TotalRequests()-r.SuccessfulRequests(), r.ErrorRate())
+	for _, e := range r.ErrorTypes() {
+		fmt.Fprintf(w, "  %-19s %d\n", e.Type+":", e.Count)
+	}
+	if rs, ok := r.Restart(); ok {
+		if rs.Recovered {
+			fmt.Fprintf(w, "Restart:     %d errors, recovered after %s\n", rs.Errors, FormatLatency(rs.Recovery))
+		} else {
+			fmt.Fprintf(w, "Restart:     %d errors, not recovered by the end of the run\n", rs.Errors)
+		}
+	}
+	if _, _, ok := r.MeasuredWindow(); ok {
+		fmt.Fprintf(w, "Whole run:   %d requests, %.2f req/s, p50 %s, p99 %s\n", r.FullRequests(), r.FullThroughput(),
+			FormatLatency(r.FullPercentile(50)), FormatLatency(r.FullPercentile(99)))
+	}
+
+	if r.streamMetric != "" {
+		fmt.Fprintf(w, "Stream latency (primary: %s):\n", r.streamMetric)
+		for _, metric := range StreamMetrics {
+			p50, ok := r.StreamPercentile(metric, 50)
+			if !ok {
+				fmt.Fprintf(w, "  %-14s unavailable\n", metric+":")
+				continue
+			}
+			p99, _ := r.StreamPercentile(metric, 99)
+			fmt.Fprintf(w, "  %-14s p50=%s p99=%s\n", metric+":", FormatLatency(p50), FormatLatency(p99))
+		}
+		if established, streams, ok := r.StreamsEstablished(); ok {
+			fmt.Fprintf(w, "Streams:     %d/%d established\n", established, streams)
+		}
+		if r.streamEnds > 0 {
+			fmt.Fprintf(w, "Stream end:  %d/%d streams completed by the server, %d transactions missing\n",
+				r.streamEnds, r.streams, r.shortfall)
+		}
+		if p50, ok := r.ReconnectPercentile(50); ok {
+			p99, _ := r.ReconnectPercentile(99)
+			fmt.Fprintf(w, "Reconnects:  %d, disconnected p50=%s p99=%s\n", r.Reconnects(), FormatLatency(p50), FormatLatency(p99))
+		}
+		if p50, ok := r.MessageSizePercentile(50); ok {
+			p99, _ := r.MessageSizePercentile(99)
+			fmt.Fprintf(w, "Msg size:    p50=%d B p99=%d B max=%d B\n", p50, p99, r.messageSizes.Max())
+		}
+	}
+
+	if stats := r.SubscriberStats(); len(stats) > 1 {
+		printSubscriberStats(w, stats)
+	}
+
+	if len(r.classes) > 0 {
+		fmt.Fprintln(w, "Workload classes:")
+		fmt.Fprintf(w, "  %-8s %10s %12s %10s %10s %8s\n", "class", "requests", "req/s", "p50", "p99", "errors")
+		for _, name := range r.ClassNames() {
+			g := r.classes[name]
+			fmt.Fprintf(w, "  %-8s %10d %12.2f %10s %10s %8d\n",
+				name, g.total, float64(g.total)/r.MeasuredDuration().Seconds(),
+				FormatLatency(g.percentile(50)), FormatLatency(g.percentile(99)),
+				g.total-g.successful)
+		}
+	}
+
+	if p50, ok := r.DBTimePercentile(50); ok {
+		p99, _ := r.DBTimePercentile(99)
+		net50, _ := r.NetworkPercentile(50)
+		net99, _ := r.NetworkPercentile(99)
+		fmt.Fprintf(w, "Latency breakdown (%d requests timed by the server):\n", r.dbTimes.TotalCount())
+		fmt.Fprintf(w, "  %-10s p50=%s p99=%s\n", "database:", FormatLatency(p50), FormatLatency(p99))
+		fmt.Fprintf(w, "  %-10s p50=%s p99=%s (network, serialization and server overhead)\n", "network:", FormatLatency(net50), FormatLatency(net99))
+	}
+
+	if p50, ok := r.StalenessPercentile(50); ok {
+		p90, _ := r.StalenessPercentile(90)
+		p99, _ := r.StalenessPercentile(99)
+		fmt.Fprintln(w, "Balance staleness:")
+		fmt.Fprintf(w, "  p50:   %s\n", formatStaleness(p50))
+		fmt.Fprintf(w, "  p90:   %s\n", formatStaleness(p90))
+		fmt.Fprintf(w, "  p99:   %s\n", formatStaleness(p99))
+		fmt.Fprintf(w, "  max:   %s\n", formatStaleness(time.Duration(r.staleness.Max())*time.Millisecond))
+	}
+
+	if r.profile != nil {
+		fmt.Fprintf(w, "Load profile (%s): %s\n", r.profile.Target, r.profile.Spec)
+		fmt.Fprintf(w, "  %-6s %7s %10s %12s %10s %10s %8s\n", "phase", "level", "requests", "req/s", "p50", "p99", "errors")
+		for i, phase := range r.phases {
+			fmt.Fprintf(w, "  %-6d %7d %10d %12.2f %10s %10s %8d\n",
+				i+1, r.profile.Phases[i].Level, phase.total,
+				float64(phase.total)/r.profile.Phases[i].Duration.Seconds(),
+				FormatLatency(phase.percentile(50)), FormatLatency(phase.percentile(99)),
+				phase.total-phase.successful)
+		}
+	}
+
+	if r.resourceStats != nil {
+		fmt.Fprintln(w, "Resources:")
+		fmt.Fprintf(w, "  CPU avg:   %.1f%%\n", r.resourceStats.CPUAvgPercent)
+		fmt.Fprintf(w, "  Mem avg:   %.1f MB\n", r.resourceStats.MemoryAvgMB)
+		fmt.Fprintf(w, "  Mem peak:  %.1f MB\n", r.resourceStats.MemoryPeakMB)
+		if rs := r.resourceStats; len(rs.Runtime) > 0 {
+			fmt.Fprintf(w, "  Heap peak: %.1f MB, %d objects\n", rs.HeapMBPeak, rs.HeapObjectsPeak)
+			fmt.Fprintf(w, "  Runtime:   %d goroutines peak, %d GC cycles (%s paused)\n",
+				rs.GoroutinesPeak, rs.GCCycles, FormatLatency(rs.GCPause))
+		}
+		if n := r.resourceStats.Net; n != nil {
+			r.printNetStats(w, n)
+		}
+		if total, perMillion, ok := r.Cost(); ok {
+			fmt.Fprintf(w, "  Cost:      %.4g (%.4g per 1M requests at %s)\n", total, perMillion, r.costModel)
+		}
+	}
+
+	if t := r.transfer; t != nil && r.full.total > 0 {
+		n := uint64(r.full.total)
+		fmt.Fprintln(w, "Transfer:")
+		fmt.Fprintf(w, "  wire:      %s sent, %s received (%s / %s per request)\n",
+			formatBytes(t.WireSent), formatBytes(t.WireRecv), formatBytes(t.WireSent/n), formatBytes(t.WireRecv/n))
+		fmt.Fprintf(w, "  payload:   %s sent, %s received (%s / %s per request)\n",
+			formatBytes(t.PayloadSent), formatBytes(t.PayloadRecv), formatBytes(t.PayloadSent/n), formatBytes(t.PayloadRecv/n))
+	}
+
+	client, clientOK := r.ClientEfficiency()
+	server, serverOK := r.ServerEfficiency()
+	if clientOK || serverOK {
+		fmt.Fprintln(w, "Efficiency:")
+		if clientOK {
+			fmt.Fprintf(w, "  client:    %.0f %s per CPU-second (%.2f CPU-s)\n", client, r.EfficiencyUnit(), r.resourceStats.CPUSeconds)
+		}
+		if serverOK {
+			fmt.Fprintf(w, "  server:    %.0f %s per CPU-second (%.2f CPU-s)\n", server, r.EfficiencyUnit(), *r.serverCPU)
+		}
+	}
+	if n, total, ok := r.GCPauses(); ok {
+		fmt.Fprintln(w, "Client GC:")
+		fmt.Fprintf(w, "  pauses:    %d, %s total\n", n, FormatLatency(total))
+		if paused, tail, ok := r.GCTail(); ok {
+			fmt.Fprintf(w, "  p99+:      %d of %d samples (%.1f%%) overlap a pause, the rest are server or network\n",
+				paused, tail, float64(paused)/float64(tail)*100)
+		}
+	}
+	if rate, ok := r.CacheHitRate(); ok {
+		fmt.Fprintln(w, "Server cache:")
+		fmt.Fprintf(w, "  hit rate:  %.1f%% (%d of %d GetBalance calls)\n", rate*100, r.cacheHits, r.cacheHits+r.cacheMisses)
+	}
+	fmt.Fprintln(w)
+}
+
+// printNetStats prints the bytes on the wire measured by the interface
+// counters, overall and per request.
+func (r *Results) printNetStats(w io.Writer, n *NetStats) {
+	ifaces := strings.Join(n.Interfaces, ",")
+	if ifaces == "" {
+		ifaces = "no traffic"
+	}
+	if n.Loopback {
+		fmt.Fprintf(w, "  Network:   %s on loopback (%s)\n", formatBytes(n.WireBytes()), ifaces)
+	} else {
+		fmt.Fprintf(w, "  Network:   %s sent, %s received (%s)\n", formatBytes(n.BytesSent), formatBytes(n.BytesRecv), ifaces)
+	}
+	if r.full.total > 0 {
+		fmt.Fprintf(w, "  Wire/req:  %s\n", formatBytes(n.WireBytes()/uint64(r.full.total)))
+	}
+}
+
+// LatencyBucket counts the successful requests with latencies in [From, To).
+type LatencyBucket struct {
+	From, To time.Duration
+	Count    int64
+}
+
+// LatencyHistogram returns successful request latencies in log-scaled
+// buckets with 1-2-5 bounds (100us, 200us, 500us, 1ms, ...), from the
+// bucket holding the fastest request to the one holding the slowest.
+func (r *Results) LatencyHistogram() []LatencyBucket {
+	if r.latencies.TotalCount() == 0 {
+		return nil
+	}
+
+	// Bounds in microseconds, from the bucket holding the minimum to past
+	// the maximum
+	var bounds []int64
+	for decade := int64(1); len(bounds) == 0 || bounds[len(bounds)-1] <= r.latencies.Max(); decade *= 10 {
+		bounds = append(bounds, decade, 2*decade, 5*decade)
+	}
+	for len(bounds) > 2 && bounds[1] <= r.latencies.Min() {
+		bounds = bounds[1:]
+	}
+
+	buckets := make([]LatencyBucket, len(bounds)-1)
+	for i := range buckets {
+		buckets[i].From = time.Duration(bounds[i]) * time.Microsecond
+		buckets[i].To = time.Duration(bounds[i+1]) * time.Microsecond
+	}
+	for _, bar := range r.latencies.Distribution() {
+		if bar.Count == 0 {
+			continue
+		}
+		i := sort.Search(len(bounds), func(i int) bool { return bounds[i] > bar.From }) - 1
+		buckets[min(max(i, 0), len(buckets)-1)].Count += bar.Count
+	}
+
+	// Trim empty buckets at either end
+	for len(buckets) > 1 && buckets[0].Count == 0 {
+		buckets = buckets[1:]
+	}
+	for len(buckets) > 1 && buckets[len(buckets)-1].Count == 0 {
+		buckets = buckets[:len(buckets)-1]
+	}
+	return buckets
+}
+
+// printHistogram prints one line per bucket with a bar scaled to the
+// fullest bucket.
+func printHistogram(w io.Writer, buckets []LatencyBucket) {
+	var total, most int64
+	for _, b := range buckets {
+		total += b.Count
+		most = max(most, b.Count)
+	}
+	for _, b := range buckets {
+		width := int(b.Count * histogramWidth / most)
+		if width == 0 && b.Count > 0 {
+			width = 1
+		}
+		fmt.Fprintf(w, "  %6s - %-6s %9d %6.2f%% |%s\n",
+			formatBound(b.From), formatBound(b.To), b.Count,
+			float64(b.Count)/float64(total)*100, strings.Repeat("#", width))
+	}
+}
+
+// formatBound formats a histogram bucket bound, e.g. 500us, 2ms or 10s.
+func formatBound(d time.Duration) string {
+	switch {
+	case d < time.Millisecond:
+		return fmt.Sprintf("%dus", d.Microseconds())
+	case d < time.Second:
+		return fmt.Sprintf("%dms", d.Milliseconds())
+	default:
+		return fmt.Sprintf("%ds", int64(d.Seconds()))
+	}
+}
+
+// FormatDeltaPct formats a percentage change as "+3.2%", or "n/a" if it
+// could not be computed.
+func FormatDeltaPct(d *float64) string {
+	if d == nil {
+		return "n/a"
+	}
+	return fmt.Sprintf("%+.1f%%", *d)
+}
+
+// ParsePercentiles parses --percentiles values such as "p99.9" or "99.99".
+func ParsePercentiles(values []string) ([]float64, error) {
+	ps := make([]float64, 0, len(values))
+	for _, v := range values {
+		p, err := strconv.ParseFloat(strings.TrimPrefix(strings.ToLower(strings.TrimSpace(v)), "p"), 64)
+		if err != nil || p <= 0 || p > 100 {
+			return nil, fmt.Errorf("invalid percentile: %s (must be between p0 and p100, e.g. p99.9)", v)
+		}
+		ps = append(ps, p)
+	}
+	return ps, nil
+}
+
+// FormatLatency formats a latency in microseconds below 1ms, otherwise in
+// milliseconds.
+func FormatLatency(d time.Duration) string {
+	if d < time.Millisecond {
+		return fmt.Sprintf("%.2fus", float64(d.Microseconds()))
+	}
+	return fmt.Sprintf("%.2fms", float64(d.Microseconds())/1000)
+}
+
+// formatStaleness formats a balance age, which ranges from milliseconds to days.
+func formatStaleness(d time.Duration) string {
+	if d < time.Second {
+		return fmt.Sprintf("%dms", d.Milliseconds())
+	}
+	return d.Round(time.Second).String()
+}
+
+// fillRun sets the duration and the resource, stream and cost metrics of
+// run from the results.
+func (r *Results) fillRun(run *db.BenchmarkRun) {
+	run.DurationSec = int(r.MeasuredDuration().Seconds())
+	if r.window != nil {
+		spec := r.window.String()
+		run.MeasureWindow = &spec
+	}
+	if from, to, ok := r.MeasuredWindow(); ok {
+		window := formatWindow(from, to.Round(time.Second))
+		throughput := r.FullThroughput()
+		p50, p99 := DurationMs(r.FullPercentile(50)), DurationMs(r.FullPercentile(99))
+		run.MeasuredWindow = &window
+		run.FullThroughput = &throughput
+		run.FullP50Ms = &p50
+		run.FullP99Ms = &p99
+	}
+	// The stored samples may be a uniform sample of the requests; the
+	// histograms cover all of them
+	requests, successful := int64(r.total), int64(r.successful)
+	run.Requests, run.SuccessfulRequests = &requests, &successful
+	if r.latencies.TotalCount() > 0 {
+		p50, p90, p99 := DurationMs(r.Percentile(50)), DurationMs(r.Percentile(90)), DurationMs(r.Percentile(99))
+		avg, minMs, maxMs := DurationMs(r.AvgLatency()), DurationMs(r.MinLatency()), DurationMs(r.MaxLatency())
+		run.LatencyP50Ms, run.LatencyP90Ms, run.LatencyP99Ms = &p50, &p90, &p99
+		run.LatencyAvgMs, run.LatencyMinMs, run.LatencyMaxMs = &avg, &minMs, &maxMs
+	}
+	if r.streamMetric != "" {
+		run.LatencyMetric = &r.streamMetric
+	}
+	if r.profile != nil {
+		profile := r.profile.String()
+		run.LoadProfile = &profile
+	}
+	if r.streamEnds > 0 {
+		run.StreamShortfall = &r.shortfall
+	}
+	if established, _, ok := r.StreamsEstablished(); ok {
+		run.StreamsEstablished = &established
+	}
+	if rs, ok := r.Restart(); ok {
+		run.RestartErrors = &rs.Errors
+		if rs.Recovered {
+			recoveryMs := DurationMs(rs.Recovery)
+			run.RestartRecoveryMs = &recoveryMs
+		}
+	}
+	if n := r.Reconnects(); n > 0 {
+		p50, _ := r.ReconnectPercentile(50)
+		p99, _ := r.ReconnectPercentile(99)
+		p50Ms, p99Ms := DurationMs(p50), DurationMs(p99)
+		run.Reconnects = &n
+		run.ReconnectP50Ms = &p50Ms
+		run.ReconnectP99Ms = &p99Ms
+	}
+	if p50, ok := r.MessageSizePercentile(50); ok {
+		p99, _ := r.MessageSizePercentile(99)
+		maxBytes := int(r.messageSizes.Max())
+		run.MessageP50Bytes = &p50
+		run.MessageP99Bytes = &p99
+		run.MessageMaxBytes = &maxBytes
+	}
+
+	// Add resource metrics if available
+	if r.resourceStats != nil {
+		run.CPUUsageAvg = &r.resourceStats.CPUAvgPercent
+		run.MemoryMBAvg = &r.resourceStats.MemoryAvgMB
+		run.MemoryMBPeak = &r.resourceStats.MemoryPeakMB
+		if n := r.resourceStats.Net; n != nil {
+			sent, recv := int64(n.BytesSent), int64(n.BytesRecv)
+			ifaces := strings.Join(n.Interfaces, ",")
+			run.NetBytesSent = &sent
+			run.NetBytesRecv = &recv
+			run.NetInterfaces = &ifaces
+		}
+		if len(r.resourceStats.Runtime) > 0 {
+			objects := int64(r.resourceStats.HeapObjectsPeak)
+			run.GoroutinesPeak = &r.resourceStats.GoroutinesPeak
+			run.HeapObjectsPeak = &objects
+			run.HeapMBPeak = &r.resourceStats.HeapMBPeak
+			run.GCCycles = &r.resourceStats.GCCycles
+		}
+	}
+	if r.resourceStats != nil {
+		run.ClientCPUSeconds = &r.resourceStats.CPUSeconds
+	}
+	if t := r.transfer; t != nil {
+		wireSent, wireRecv := int64(t.WireSent), int64(t.WireRecv)
+		payloadSent, payloadRecv := int64(t.PayloadSent), int64(t.PayloadRecv)
+		run.WireBytesSent = &wireSent
+		run.WireBytesRecv = &wireRecv
+		run.PayloadBytesSent = &payloadSent
+		run.PayloadBytesRecv = &payloadRecv
+	}
+	run.ServerCPUSeconds = r.serverCPU
+	if v, ok := r.ClientEfficiency(); ok {
+		run.ClientPerCPUSec = &v
+	}
+	if v, ok := r.ServerEfficiency(); ok {
+		run.ServerPerCPUSec = &v
+	}
+	if v, ok := r.CacheHitRate(); ok {
+		run.CacheHitRate = &v
+	}
+	if n, total, ok := r.GCPauses(); ok {
+		ms := DurationMs(total)
+		run.GCPauses = &n
+		run.GCPauseMs = &ms
+	}
+	if paused, tail, ok := r.GCTail(); ok {
+		fraction := float64(paused) / float64(tail)
+		run.GCTailFraction = &fraction
+	}
+	if total, perMillion, ok := r.Cost(); ok {
+		model := r.costModel.String()
+		run.Cost = &total
+		run.CostPerMillion = &perMillion
+		run.CostModel = &model
+	}
+}
+
+// StoreResults saves benchmark results to the results store and returns the run ID.
+// The caller fills in the run's identifying fields (scenario, protocol,
+// concurrency, ...); duration and resource metrics are taken from the results.
+func (r *Results) StoreResults(ctx context.Context, database db.ResultsStore, run *db.BenchmarkRun) (int64, error) {
+	r.fillRun(run)
+
+	runID, err := database.RecordRun(ctx, run)
+	if err != nil {
+		return 0, fmt.Errorf("failed to record run: %w", err)
+	}
+
+	// Convert samples for batch insert, leaving out those the stats do not
+	// cover, so stats computed from them match
+	dbSamples := make([]*db.BenchmarkSample, 0, len(r.samples))
+	for _, s := range r.samples {
+		if !r.inWindow(s) {
+			continue
+		}
+		dbSamples = append(dbSamples, r.dbSample(runID, s))
+	}
+
+	// Batch insert samples
+	if err := database.RecordSamples(ctx, dbSamples); err != nil {
+		return runID, fmt.Errorf("failed to record samples: %w", err)
+	}
+
+	var points []db.TimeseriesPoint
+	for _, p := range r.Timeseries() {
+		point := db.TimeseriesPoint{
+			RunID:      runID,
+			ElapsedSec: p.Second,
+			Requests:   int64(p.Requests),
+			Errors:     int64(p.Errors),
+		}
+		if p.P50 > 0 {
+			p50 := float64(p.P50.Microseconds()) / 1000.0
+			p99 := float64(p.P99.Microseconds()) / 1000.0
+			point.P50LatencyMs = &p50
+			point.P99LatencyMs = &p99
+		}
+		if s := p.Runtime; s != nil {
+			objects := int64(s.HeapObjects)
+			pauseMs := float64(s.GCPause.Microseconds()) / 1000.0
+			point.Goroutines = &s.Goroutines
+			point.HeapObjects = &objects
+			point.HeapMB = &s.HeapMB
+			point.GCCycles = &s.GCCycles
+			point.GCPauseMs = &pauseMs
+		}
+		points = append(points, point)
+	}
+	if err := database.RecordTimeseries(ctx, runID, points); err != nil {
+		return runID, fmt.Errorf("failed to record timeseries: %w", err)
+	}
+
+	fmt.Printf("Results saved to database (run_id: %d)\n", runID)
+	if len(dbSamples) < r.total {
+		fmt.Printf("Stored a uniform sample of %d of %d requests; the stored stats cover all of them\n", len(dbSamples), r.total)
+	}
+
+	// Retrieve and print stats from the view
+	stats, err := database.GetStats(ctx, runID)
+	if err != nil {
+		fmt.Printf("Warning: could not retrieve stats from view: %v\n", err)
+		return runID, nil
+	}
+
+	fmt.Printf("\nStored stats:\n")
+	fmt.Printf("  p50: %.2fms, p90: %.2fms, p99: %.2fms\n",
+		stats.P50Latency, stats.P90Latency, stats.P99Latency)
+	if stats.P50Staleness != nil && stats.P90Staleness != nil && stats.P99Staleness != nil {
+		fmt.Printf("  staleness p50: %.0fms, p90: %.0fms, p99: %.0fms\n",
+			*stats.P50Staleness, *stats.P90Staleness, *stats.P99Staleness)
+	}
+	if stats.P50DB != nil && stats.P99DB != nil && stats.P50Network != nil && stats.P99Network != nil {
+		fmt.Printf("  database p50: %.2fms, p99: %.2fms; network p50: %.2fms, p99: %.2fms\n",
+			*stats.P50DB, *stats.P99DB, *stats.P50Network, *stats.P99Network)
+	}
+
+	baseline, err := db.AssignBaseline(ctx, database, runID)
+	if err != nil {
+		fmt.Printf("Warning: could not select a baseline run: %v\n", err)
+		return runID, nil
+	}
+	if baseline != nil {
+		fmt.Printf("  vs previous run %d: p50 %s, p99 %s, throughput %s\n", baseline.RunID,
+			FormatDeltaPct(baseline.P50DeltaPct), FormatDeltaPct(baseline.P99DeltaPct),
+			FormatDeltaPct(baseline.ThroughputDeltaPct))
+	}
+
+	return runID, nil
+}
+
+// dbSample converts s to a sample of run runID for storage.
+func (r *Results) dbSample(runID int64, s Sample) *db.BenchmarkSample {
+	sample := &db.BenchmarkSample{
+		RunID:     runID,
+		LatencyMs: float64(s.Latency.Microseconds()) / 1000.0,
+		Success:   s.Success,
+		Timestamp: s.Timestamp,
+	}
+	if errType := classifyError(s.Error); errType != "" {
+		sample.ErrorType = &errType
+	}
+	if s.Staleness > 0 {
+		stalenessMs := float64(s.Staleness.Microseconds()) / 1000.0
+		sample.StalenessMs = &stalenessMs
+	}
+	if s.Phase > 0 {
+		phase := s.Phase
+		sample.Phase = &phase
+	}
+	if s.Class != "" {
+		class := s.Class
+		sample.WorkloadClass = &class
+	}
+	if s.Operation != "" {
+		op := s.Operation
+		sample.Operation = &op
+	}
+	if s.Success && s.DBTimed {
+		dbMs := float64(s.DBTime.Microseconds()) / 1000.0
+		sample.DBMs = &dbMs
+	}
+	if r.gcTracked {
+		gcPause := s.GCPause
+		sample.GCPause = &gcPause
+	}
+	return sample
+}
+
+// RunReport is the JSON document WriteJSON writes for a run that is not
+// stored in a results database: the run record StoreResults would store,
+// the summary statistics and the per-second timeseries. Raw samples are
+// left out.
+type RunReport struct {
+	Run        *db.BenchmarkRun
+	Requests   int
+	Successful int
+	ErrorRate  float64            // percentage of failed requests
+	Throughput float64            // requests per second over the measure window
+	LatencyMs  map[string]float64 // latency per summary percentile, e.g. "p99"
+	AvgMs      float64
+	MaxMs      float64
+	Errors     []ErrorTypeCount
+	Timeseries []TimeseriesPoint
+}
+
+// WriteJSON writes the results as an indented RunReport instead of storing
+// them, for runs without a results database. The caller fills in run like
+// for StoreResults.
+func (r *Results) WriteJSON(w io.Writer, run *db.BenchmarkRun) error {
+	r.fillRun(run)
+	report := RunReport{
+		Run:        run,
+		Requests:   r.total,
+		Successful: r.successful,
+		ErrorRate:  r.ErrorRate(),
+		Throughput: r.Throughput(),
+		LatencyMs:  make(map[string]float64, len(r.percentiles)),
+		AvgMs:      DurationMs(r.AvgLatency()),
+		MaxMs:      DurationMs(r.MaxLatency()),
+		Errors:     r.ErrorTypes(),
+		Timeseries: r.Timeseries(),
+	}
+	for _, p := range r.percentiles {
+		report.LatencyMs[fmt.Sprintf("p%g", p)] = DurationMs(r.Percentile(p))
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(report); err != nil {
+		return fmt.Errorf("failed to write results: %w", err)
+	}
+	return nil
+}
+
+// printSubscriberStats prints how events and latency spread over the stream
+// subscribers: the lowest, median and highest value of each.
+func printSubscriberStats(w io.Writer, stats []SubscriberStats) {
+	spread := func(value func(SubscriberStats) int64) (lo, median, hi int64) {
+		vs := make([]int64, len(stats))
+		for i, s := range stats {
+			vs[i] = value(s)
+		}
+		sort.Slice(vs, func(i, j int) bool { return vs[i] < vs[j] })
+		return vs[0], vs[len(vs)/2], vs[len(vs)-1]
+	}
+
+	fmt.Fprintf(w, "Per stream (%d streams):\n", len(stats))
+	lo, median, hi := spread(func(s SubscriberStats) int64 { return int64(s.Events) })
+	fmt.Fprintf(w, "  %-8s min=%d median=%d max=%d\n", "events:", lo, median, hi)
+	for _, q := range []struct {
+		name  string
+		value func(SubscriberStats) int64
+	}{
+		{"p50:", func(s SubscriberStats) int64 { return int64(s.P50) }},
+		{"p99:", func(s SubscriberStats) int64 { return int64(s.P99) }},
+	} {
+		lo, median, hi := spread(q.value)
+		fmt.Fprintf(w, "  %-8s best=%s median=%s worst=%s\n", q.name,
+			FormatLatency(time.Duration(lo)), FormatLatency(time.Duration(median)), FormatLatency(time.Duration(hi)))
+	}
+	if lo, _, hi := spread(func(s SubscriberStats) int64 { return int64(s.Errors) }); hi > 0 {
+		fmt.Fprintf(w, "  %-8s min=%d max=%d\n", "errors:", lo, hi)
+	}
+}
@@ -0,0 +1,854 @@
+package bench
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/kaldun-tech/grpc-rest-benchmark/pkg/db"
+)
+
+func TestNewResults(t *testing.T) {
+	r := NewResults()
+	if r == nil {
+		t.Fatal("NewResults() returned nil")
+	}
+	if r.samples == nil {
+		t.Error("samples slice is nil")
+	}
+	if len(r.samples) != 0 {
+		t.Errorf("samples length = %d, want 0", len(r.samples))
+	}
+}
+
+func TestResults_Add(t *testing.T) {
+	r := NewResults()
+
+	sample := Sample{
+		Latency:   time.Millisecond,
+		Success:   true,
+		Timestamp: time.Now(),
+	}
+	r.Add(sample)
+
+	if len(r.samples) != 1 {
+		t.Errorf("samples length = %d, want 1", len(r.samples))
+	}
+}
+
+func TestResults_TotalRequests(t *testing.T) {
+	r := NewResults()
+
+	for i := 0; i < 10; i++ {
+		r.Add(Sample{Latency: time.Millisecond, Success: true})
+	}
+
+	if r.TotalRequests() != 10 {
+		t.Errorf("TotalRequests() = %d, want 10", r.TotalRequests())
+	}
+}
+
+func TestResults_SuccessfulRequests(t *testing.T) {
+	r := NewResults()
+
+	// Add 7 successful and 3 failed
+	for i := 0; i < 7; i++ {
+		r.Add(Sample{Latency: time.Millisecond, Success: true})
+	}
+	for i := 0; i < 3; i++ {
+		r.Add(Sample{Latency: time.Millisecond, Success: false, Error: errors.New("test error")})
+	}
+
+	if r.SuccessfulRequests() != 7 {
+		t.Errorf("SuccessfulRequests() = %d, want 7", r.SuccessfulRequests())
+	}
+}
+
+func TestResults_ErrorRate(t *testing.T) {
+	r := NewResults()
+
+	// 8 successful, 2 failed = 20% error rate
+	for i := 0; i < 8; i++ {
+		r.Add(Sample{Latency: time.Millisecond, Success: true})
+	}
+	for i := 0; i < 2; i++ {
+		r.Add(Sample{Latency: time.Millisecond, Success: false})
+	}
+
+	rate := r.ErrorRate()
+	if rate != 20.0 {
+		t.Errorf("ErrorRate() = %f, want 20.0", rate)
+	}
+}
+
+func TestResults_ErrorTypes(t *testing.T) {
+	r := NewResults()
+
+	r.Add(Sample{Latency: time.Millisecond, Success: true})
+	r.Add(Sample{Latency: time.Millisecond, Success: false, Error: &statusError{code: 503}})
+	r.Add(Sample{Latency: time.Second, Success: false, Error: context.DeadlineExceeded})
+	r.Add(Sample{Latency: time.Second, Success: false, Error: context.DeadlineExceeded})
+	r.Add(Sample{Latency: time.Millisecond, Success: false})
+
+	got := r.ErrorTypes()
+	want := []ErrorTypeCount{{"timeout", 2}, {"http_5xx", 1}, {"other", 1}}
+	if len(got) != len(want) {
+		t.Fatalf("ErrorTypes() = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("ErrorTypes()[%d] = %v, want %v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestResults_ErrorRate_Empty(t *testing.T) {
+	r := NewResults()
+
+	rate := r.ErrorRate()
+	if rate != 0 {
+		t.Errorf("ErrorRate() = %f, want 0", rate)
+	}
+}
+
+func TestResults_Throughput(t *testing.T) {
+	r := NewResults()
+
+	// Add 100 samples
+	for i := 0; i < 100; i++ {
+		r.Add(Sample{Latency: time.Millisecond, Success: true})
+	}
+
+	// Simulate 10 second duration
+	r.SetStartTime(time.Now().Add(-10 * time.Second))
+	r.SetEndTime(time.Now())
+
+	throughput := r.Throughput()
+	// Should be approximately 10 req/s
+	if throughput < 9 || throughput > 11 {
+		t.Errorf("Throughput() = %f, want ~10", throughput)
+	}
+}
+
+func TestResults_Throughput_ZeroDuration(t *testing.T) {
+	r := NewResults()
+	r.Add(Sample{Latency: time.Millisecond, Success: true})
+
+	now := time.Now()
+	r.SetStartTime(now)
+	r.SetEndTime(now)
+
+	throughput := r.Throughput()
+	if throughput != 0 {
+		t.Errorf("Throughput() = %f, want 0 for zero duration", throughput)
+	}
+}
+
+func TestResults_Duration(t *testing.T) {
+	r := NewResults()
+
+	start := time.Now()
+	end := start.Add(5 * time.Second)
+	r.SetStartTime(start)
+	r.SetEndTime(end)
+
+	duration := r.Duration()
+	if duration != 5*time.Second {
+		t.Errorf("Duration() = %v, want 5s", duration)
+	}
+}
+
+func TestResults_Percentile(t *testing.T) {
+	r := NewResults()
+
+	// Add samples with latencies 1ms to 100ms
+	for i := 1; i <= 100; i++ {
+		r.Add(Sample{
+			Latency: time.Duration(i) * time.Millisecond,
+			Success: true,
+		})
+	}
+
+	// P50 should be around 50ms
+	p50 := r.Percentile(50)
+	if p50 < 49*time.Millisecond || p50 > 51*time.Millisecond {
+		t.Errorf("Percentile(50) = %v, want ~50ms", p50)
+	}
+
+	// P90 should be around 90ms
+	p90 := r.Percentile(90)
+	if p90 < 89*time.Millisecond || p90 > 91*time.Millisecond {
+		t.Errorf("Percentile(90) = %v, want ~90ms", p90)
+	}
+
+	// P99 should be around 99ms
+	p99 := r.Percentile(99)
+	if p99 < 98*time.Millisecond || p99 > 100*time.Millisecond {
+		t.Errorf("Percentile(99) = %v, want ~99ms", p99)
+	}
+}
+
+func TestResults_DBTime(t *testing.T) {
+	r := NewResults()
+	if _, ok := r.DBTimePercentile(50); ok {
+		t.Error("DBTimePercentile() ok without reported database times")
+	}
+
+	// Half of the requests were answered without a query, e.g. from a cache
+	for i := 1; i <= 100; i++ {
+		db := time.Duration(0)
+		if i%2 == 0 {
+			db = 8 * time.Millisecond
+		}
+		r.Add(Sample{Latency: 10 * time.Millisecond, Success: true, DBTime: db, DBTimed: true})
+	}
+	r.Add(Sample{Latency: 50 * time.Millisecond, Success: true})
+	r.Add(Sample{Latency: 50 * time.Millisecond, Error: errors.New("timeout"), DBTime: time.Millisecond, DBTimed: true})
+
+	if db, _ := r.DBTimePercentile(50); db != 0 {
+		t.Errorf("DBTimePercentile(50) = %v, want 0", db)
+	}
+	if db, _ := r.DBTimePercentile(99); db < 7990*time.Microsecond || db > 8010*time.Microsecond {
+		t.Errorf("DBTimePercentile(99) = %v, want ~8ms", db)
+	}
+	if net, _ := r.NetworkPercentile(50); net < 1990*time.Microsecond || net > 2010*time.Microsecond {
+		t.Errorf("NetworkPercentile(50) = %v, want ~2ms", net)
+	}
+	if net, _ := r.NetworkPercentile(99); net < 9990*time.Microsecond || net > 10010*time.Microsecond {
+		t.Errorf("NetworkPercentile(99) = %v, want ~10ms", net)
+	}
+}
+
+func TestResults_Percentile_Empty(t *testing.T) {
+	r := NewResults()
+
+	p50 := r.Percentile(50)
+	if p50 != 0 {
+		t.Errorf("Percentile(50) = %v, want 0 for empty results", p50)
+	}
+}
+
+func TestResults_Percentile_OnlyErrors(t *testing.T) {
+	r := NewResults()
+
+	// Add only failed samples
+	for i := 0; i < 10; i++ {
+		r.Add(Sample{
+			Latency: time.Millisecond,
+			Success: false,
+		})
+	}
+
+	p50 := r.Percentile(50)
+	if p50 != 0 {
+		t.Errorf("Percentile(50) = %v, want 0 when all failed", p50)
+	}
+}
+
+func TestResults_AvgLatency(t *testing.T) {
+	r := NewResults()
+
+	// Add samples: 10ms, 20ms, 30ms -> avg = 20ms
+	r.Add(Sample{Latency: 10 * time.Millisecond, Success: true})
+	r.Add(Sample{Latency: 20 * time.Millisecond, Success: true})
+	r.Add(Sample{Latency: 30 * time.Millisecond, Success: true})
+
+	avg := r.AvgLatency()
+	if avg != 20*time.Millisecond {
+		t.Errorf("AvgLatency() = %v, want 20ms", avg)
+	}
+}
+
+func TestResults_AvgLatency_IgnoresFailures(t *testing.T) {
+	r := NewResults()
+
+	// Add successful: 10ms, 20ms, 30ms
+	r.Add(Sample{Latency: 10 * time.Millisecond, Success: true})
+	r.Add(Sample{Latency: 20 * time.Millisecond, Success: true})
+	r.Add(Sample{Latency: 30 * time.Millisecond, Success: true})
+	// Add failed: 1000ms (should be ignored)
+	r.Add(Sample{Latency: 1000 * time.Millisecond, Success: false})
+
+	avg := r.AvgLatency()
+	if avg != 20*time.Millisecond {
+		t.Errorf("AvgLatency() = %v, want 20ms (failures should be ignored)", avg)
+	}
+}
+
+func TestResults_AvgLatency_Empty(t *testing.T) {
+	r := NewResults()
+
+	avg := r.AvgLatency()
+	if avg != 0 {
+		t.Errorf("AvgLatency() = %v, want 0 for empty results", avg)
+	}
+}
+
+func TestResults_MinLatency(t *testing.T) {
+	r := NewResults()
+
+	r.Add(Sample{Latency: 50 * time.Millisecond, Success: true})
+	r.Add(Sample{Latency: 10 * time.Millisecond, Success: true})
+	r.Add(Sample{Latency: 30 * time.Millisecond, Success: true})
+
+	min := r.MinLatency()
+	if min != 10*time.Millisecond {
+		t.Errorf("MinLatency() = %v, want 10ms", min)
+	}
+}
+
+func TestResults_MinLatency_Empty(t *testing.T) {
+	r := NewResults()
+
+	min := r.MinLatency()
+	if min != 0 {
+		t.Errorf("MinLatency() = %v, want 0 for empty results", min)
+	}
+}
+
+func TestResults_MaxLatency(t *testing.T) {
+	r := NewResults()
+
+	r.Add(Sample{Latency: 10 * time.Millisecond, Success: true})
+	r.Add(Sample{Latency: 50 * time.Millisecond, Success: true})
+	r.Add(Sample{Latency: 30 * time.Millisecond, Success: true})
+
+	max := r.MaxLatency()
+	if max != 50*time.Millisecond {
+		t.Errorf("MaxLatency() = %v, want 50ms", max)
+	}
+}
+
+func TestResults_MaxLatency_Empty(t *testing.T) {
+	r := NewResults()
+
+	max := r.MaxLatency()
+	if max != 0 {
+		t.Errorf("MaxLatency() = %v, want 0 for empty results", max)
+	}
+}
+
+func TestResults_SetResourceStats(t *testing.T) {
+	r := NewResults()
+
+	stats := ResourceStats{
+		CPUAvgPercent: 50.5,
+		MemoryAvgMB:   100.0,
+		MemoryPeakMB:  150.0,
+	}
+	r.SetResourceStats(stats)
+
+	if r.resourceStats == nil {
+		t.Fatal("resourceStats is nil after SetResourceStats")
+	}
+	if r.resourceStats.CPUAvgPercent != 50.5 {
+		t.Errorf("CPUAvgPercent = %f, want 50.5", r.resourceStats.CPUAvgPercent)
+	}
+	if r.resourceStats.MemoryAvgMB != 100.0 {
+		t.Errorf("MemoryAvgMB = %f, want 100.0", r.resourceStats.MemoryAvgMB)
+	}
+	if r.resourceStats.MemoryPeakMB != 150.0 {
+		t.Errorf("MemoryPeakMB = %f, want 150.0", r.resourceStats.MemoryPeakMB)
+	}
+}
+
+func TestResults_Collect(t *testing.T) {
+	r := NewResults()
+
+	ch := make(chan Sample, 5)
+
+	// Add samples to channel
+	for i := 0; i < 5; i++ {
+		ch <- Sample{Latency: time.Duration(i) * time.Millisecond, Success: true}
+	}
+	close(ch)
+
+	r.Collect(ch)
+
+	if len(r.samples) != 5 {
+		t.Errorf("samples length = %d after Collect, want 5", len(r.samples))
+	}
+}
+
+func TestFormatLatency(t *testing.T) {
+	tests := []struct {
+		input    time.Duration
+		expected string
+	}{
+		{500 * time.Microsecond, "500.00us"},
+		{999 * time.Microsecond, "999.00us"},
+		{1 * time.Millisecond, "1.00ms"},
+		{1500 * time.Microsecond, "1.50ms"},
+		{10 * time.Millisecond, "10.00ms"},
+		{100 * time.Millisecond, "100.00ms"},
+	}
+
+	for _, tt := range tests {
+		got := FormatLatency(tt.input)
+		if got != tt.expected {
+			t.Errorf("FormatLatency(%v) = %q, want %q", tt.input, got, tt.expected)
+		}
+	}
+}
+
+func TestResults_Percentile_HighPrecision(t *testing.T) {
+	r := NewResults()
+
+	// Add 10,000 samples with latencies 1ms to 10s in 1ms steps
+	for i := 1; i <= 10000; i++ {
+		r.Add(Sample{Latency: time.Duration(i) * time.Millisecond, Success: true})
+	}
+
+	tests := []struct {
+		p    float64
+		want time.Duration
+	}{
+		{50, 5000 * time.Millisecond},
+		{99, 9900 * time.Millisecond},
+		{99.9, 9990 * time.Millisecond},
+	}
+
+	for _, tt := range tests {
+		got := r.Percentile(tt.p)
+		// Histogram precision is 3 significant digits (0.1%)
+		tolerance := tt.want / 1000
+		if got < tt.want-tolerance || got > tt.want+tolerance {
+			t.Errorf("Percentile(%v) = %v, want %v ± %v", tt.p, got, tt.want, tolerance)
+		}
+	}
+}
+
+func TestResults_MaxStoredSamples(t *testing.T) {
+	r := NewResults()
+	r.SetMaxStoredSamples(100)
+
+	for i := 1; i <= 1000; i++ {
+		r.Add(Sample{Latency: time.Duration(i) * time.Millisecond, Success: true})
+	}
+
+	if r.StoredSamples() != 100 {
+		t.Errorf("StoredSamples() = %d, want 100", r.StoredSamples())
+	}
+	if r.TotalRequests() != 1000 {
+		t.Errorf("TotalRequests() = %d, want 1000 (stats must cover every sample)", r.TotalRequests())
+	}
+	if r.MaxLatency() != 1000*time.Millisecond {
+		t.Errorf("MaxLatency() = %v, want 1s", r.MaxLatency())
+	}
+}
+
+func TestResults_MaxStoredSamples_Unlimited(t *testing.T) {
+	r := NewResults()
+
+	for i := 0; i < 500; i++ {
+		r.Add(Sample{Latency: time.Millisecond, Success: true})
+	}
+
+	if r.StoredSamples() != 500 {
+		t.Errorf("StoredSamples() = %d, want 500", r.StoredSamples())
+	}
+}
+
+func TestResults_StreamPercentile(t *testing.T) {
+	r := NewResults()
+	r.SetStreamMetric(StreamMetricInterArrival)
+
+	for i := 1; i <= 100; i++ {
+		lat := StreamLatencies{
+			InterArrival: time.Duration(i) * time.Millisecond,
+			Processing:   time.Duration(i) * time.Microsecond,
+		}
+		r.Add(Sample{Latency: lat.Get(StreamMetricInterArrival), Success: true, Stream: lat})
+	}
+
+	// HDR histogram values are accurate to 3 significant figures
+	if got, ok := r.StreamPercentile(StreamMetricInterArrival, 50); !ok || got < 50*time.Millisecond || got > 50100*time.Microsecond {
+		t.Errorf("inter-arrival p50 = %v, %v; want ~50ms, true", got, ok)
+	}
+	if got, ok := r.StreamPercentile(StreamMetricProcessing, 50); !ok || got != 50*time.Microsecond {
+		t.Errorf("processing p50 = %v, %v; want 50µs, true", got, ok)
+	}
+	if _, ok := r.StreamPercentile(StreamMetricDelivery, 50); ok {
+		t.Error("delivery should be unavailable when no event carried a send time")
+	}
+}
+
+func TestResults_StalenessPercentile(t *testing.T) {
+	r := NewResults()
+
+	if _, ok := r.StalenessPercentile(50); ok {
+		t.Error("staleness should be unavailable before any sample carries it")
+	}
+
+	for i := 1; i <= 100; i++ {
+		r.Add(Sample{
+			Latency:   time.Millisecond,
+			Success:   true,
+			Staleness: time.Duration(i) * time.Second,
+		})
+	}
+	// Failed requests never contribute staleness
+	r.Add(Sample{Success: false, Staleness: time.Hour})
+
+	if got, ok := r.StalenessPercentile(50); !ok || got < 50*time.Second || got > 50100*time.Millisecond {
+		t.Errorf("staleness p50 = %v, %v; want ~50s, true", got, ok)
+	}
+	if got, _ := r.StalenessPercentile(100); got > 101*time.Second {
+		t.Errorf("staleness p100 = %v, want ~100s", got)
+	}
+}
+
+func TestResults_CorrectedPercentile(t *testing.T) {
+	r := NewResults()
+	r.SetExpectedInterval(10 * time.Millisecond)
+
+	// 99 fast responses and one 1s stall: the stall hides ~99 requests that
+	// a closed-loop client would have sent at 10ms intervals.
+	for i := 0; i < 99; i++ {
+		r.Add(Sample{Latency: time.Millisecond, Success: true})
+	}
+	r.Add(Sample{Latency: time.Second, Success: true})
+
+	if got := r.Percentile(90); got > 2*time.Millisecond {
+		t.Errorf("uncorrected p90 = %v, want ~1ms", got)
+	}
+	got, ok := r.CorrectedPercentile(90)
+	if !ok {
+		t.Fatal("CorrectedPercentile() ok = false with an expected interval set")
+	}
+	if got < 500*time.Millisecond {
+		t.Errorf("corrected p90 = %v, want the stall to dominate (>= 500ms)", got)
+	}
+}
+
+func TestResults_CorrectedPercentile_Disabled(t *testing.T) {
+	r := NewResults()
+	r.Add(Sample{Latency: time.Second, Success: true})
+
+	if _, ok := r.CorrectedPercentile(99); ok {
+		t.Error("CorrectedPercentile() ok = true without an expected interval")
+	}
+}
+
+func TestResults_LoadProfilePhases(t *testing.T) {
+	r := NewResults()
+	r.SetLoadProfile(&LoadProfile{
+		Target: ProfileTargetConcurrency,
+		Phases: []LoadPhase{{Level: 1, Duration: time.Second}, {Level: 10, Duration: time.Second}},
+	})
+
+	for i := 0; i < 10; i++ {
+		r.Add(Sample{Latency: time.Millisecond, Success: true, Phase: 1})
+	}
+	for i := 0; i < 20; i++ {
+		r.Add(Sample{Latency: 10 * time.Millisecond, Success: i%10 != 0, Phase: 2})
+	}
+
+	if got := r.phases[0].total; got != 10 {
+		t.Errorf("phase 1 total = %d, want 10", got)
+	}
+	if got, want := r.phases[1].total-r.phases[1].successful, 2; got != want {
+		t.Errorf("phase 2 errors = %d, want %d", got, want)
+	}
+	if got := r.phases[1].percentile(50); got < 9*time.Millisecond || got > 11*time.Millisecond {
+		t.Errorf("phase 2 p50 = %v, want ~10ms", got)
+	}
+	if got := r.TotalRequests(); got != 30 {
+		t.Errorf("TotalRequests() = %d, want 30 across phases", got)
+	}
+}
+
+func TestResults_WorkloadClasses(t *testing.T) {
+	r := NewResults()
+	for i := 0; i < 10; i++ {
+		r.Add(Sample{Latency: time.Millisecond, Success: true, Class: "stream"})
+	}
+	for i := 0; i < 5; i++ {
+		r.Add(Sample{Latency: 20 * time.Millisecond, Success: i != 0, Class: "balance"})
+	}
+
+	if got := r.ClassNames(); len(got) != 2 || got[0] != "balance" || got[1] != "stream" {
+		t.Fatalf("ClassNames() = %v, want [balance stream]", got)
+	}
+	if got := r.classes["balance"].total - r.classes["balance"].successful; got != 1 {
+		t.Errorf("balance errors = %d, want 1", got)
+	}
+	if got := r.ClassPercentile("stream", 50); got < 900*time.Microsecond || got > 1100*time.Microsecond {
+		t.Errorf("stream p50 = %v, want ~1ms", got)
+	}
+	if got := r.ClassPercentile("balance", 50); got < 19*time.Millisecond || got > 21*time.Millisecond {
+		t.Errorf("balance p50 = %v, want ~20ms", got)
+	}
+	if got := r.TotalRequests(); got != 15 {
+		t.Errorf("TotalRequests() = %d, want 15 across classes", got)
+	}
+}
+
+func TestResults_SubscriberStats(t *testing.T) {
+	r := NewResults()
+	for i := 0; i < 10; i++ {
+		r.Add(Sample{Latency: time.Millisecond, Success: true, Subscriber: 2})
+	}
+	r.Add(Sample{Latency: 5 * time.Millisecond, Success: true, Subscriber: 1})
+	r.Add(Sample{Success: false, Subscriber: 1})
+	r.Add(Sample{Latency: time.Millisecond, Success: true})
+
+	stats := r.SubscriberStats()
+	if len(stats) != 2 || stats[0].Subscriber != 1 || stats[1].Subscriber != 2 {
+		t.Fatalf("SubscriberStats() = %+v, want subscribers 1 and 2 in order", stats)
+	}
+	if stats[0].Events != 1 || stats[0].Errors != 1 || stats[1].Events != 10 {
+		t.Errorf("SubscriberStats() = %+v, want 1 event and 1 error, then 10 events", stats)
+	}
+	if got := stats[0].P99; got < 4900*time.Microsecond || got > 5100*time.Microsecond {
+		t.Errorf("subscriber 1 p99 = %v, want ~5ms", got)
+	}
+}
+
+func TestResults_StreamsEstablished(t *testing.T) {
+	r := NewResults()
+	if _, _, ok := r.StreamsEstablished(); ok {
+		t.Error("StreamsEstablished() ok without streams, want false")
+	}
+
+	r.SetStreams(3)
+	r.Add(Sample{Latency: time.Millisecond, Success: true, Subscriber: 1})
+	r.Add(Sample{Success: false, Subscriber: 1})
+	r.Add(Sample{Success: false, Subscriber: 2})
+	if established, streams, ok := r.StreamsEstablished(); !ok || established != 1 || streams != 3 {
+		t.Errorf("StreamsEstablished() = %d, %d, %v; want 1, 3, true", established, streams, ok)
+	}
+}
+
+func TestResults_MessageSizePercentile(t *testing.T) {
+	r := NewResults()
+	r.Add(Sample{Latency: time.Millisecond, Success: true})
+	if _, ok := r.MessageSizePercentile(50); ok {
+		t.Error("MessageSizePercentile() ok without stream messages, want false")
+	}
+
+	for i := 1; i <= 100; i++ {
+		r.Add(Sample{Latency: time.Millisecond, Success: true, MessageBytes: 100 + i})
+	}
+	r.Add(Sample{Success: false, MessageBytes: 5000})
+	if p50, ok := r.MessageSizePercentile(50); !ok || p50 != 150 {
+		t.Errorf("MessageSizePercentile(50) = %d, %v; want 150, true", p50, ok)
+	}
+	if p99, _ := r.MessageSizePercentile(99); p99 != 199 {
+		t.Errorf("MessageSizePercentile(99) = %d, want 199 (failed samples ignored)", p99)
+	}
+
+	run := &db.BenchmarkRun{}
+	r.fillRun(run)
+	if run.MessageP50Bytes == nil || *run.MessageP50Bytes != 150 || *run.MessageMaxBytes != 200 {
+		t.Errorf("run message sizes = %v/%v, want 150/200", run.MessageP50Bytes, run.MessageMaxBytes)
+	}
+}
+
+func TestResults_StoreResults_Local(t *testing.T) {
+	ctx := context.Background()
+	store, err := db.OpenLocal(ctx, filepath.Join(t.TempDir(), "results.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+
+	r := NewResults()
+	start := time.Now()
+	r.SetStartTime(start)
+	for i := 1; i <= 4; i++ {
+		r.Add(Sample{Latency: time.Duration(i) * time.Millisecond, Success: i != 4, Timestamp: start})
+	}
+	r.Add(Sample{Latency: time.Millisecond, Error: errors.New("unavailable"), Timestamp: start})
+	r.SetEndTime(start.Add(2 * time.Second))
+
+	runID, err := r.StoreResults(ctx, store, &db.BenchmarkRun{Scenario: "balance", Protocol: "grpc", Concurrency: 2})
+	if err != nil {
+		t.Fatalf("StoreResults() error = %v", err)
+	}
+
+	stats, err := store.GetStats(ctx, runID)
+	if err != nil {
+		t.Fatalf("GetStats() error = %v", err)
+	}
+	if stats.TotalSamples != 5 || stats.Successful != 3 || stats.DurationSec != 2 {
+		t.Errorf("stored stats = %+v", stats)
+	}
+	// As printed: the latencies of the successful requests
+	if stats.P50Latency != 2 || stats.MaxLatency != 3 {
+		t.Errorf("p50 = %v, max = %v; want 2ms and 3ms", stats.P50Latency, stats.MaxLatency)
+	}
+	if stats.StoredSamples != 5 {
+		t.Errorf("stored samples = %d, want 5", stats.StoredSamples)
+	}
+}
+
+func TestResults_StoreResults_Sampled(t *testing.T) {
+	ctx := context.Background()
+	store, err := db.OpenLocal(ctx, filepath.Join(t.TempDir(), "results.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+
+	// 1000 requests, one of them slow, and room for 10 samples
+	r := NewResults()
+	r.SetMaxStoredSamples(10)
+	start := time.Now()
+	r.SetStartTime(start)
+	for i := range 1000 {
+		latency := time.Millisecond
+		if i == 500 {
+			latency = time.Second
+		}
+		r.Add(Sample{Latency: latency, Success: true, Timestamp: start})
+	}
+	r.SetEndTime(start.Add(time.Second))
+
+	runID, err := r.StoreResults(ctx, store, &db.BenchmarkRun{Scenario: "balance", Protocol: "grpc", Concurrency: 1})
+	if err != nil {
+		t.Fatalf("StoreResults() error = %v", err)
+	}
+	stats, err := store.GetStats(ctx, runID)
+	if err != nil {
+		t.Fatalf("GetStats() error = %v", err)
+	}
+	if stats.TotalSamples != 1000 || stats.Successful != 1000 || stats.StoredSamples != 10 {
+		t.Errorf("stored stats cover %d requests (%d successful) from %d samples, want 1000 from 10",
+			stats.TotalSamples, stats.Successful, stats.StoredSamples)
+	}
+	if want := DurationMs(r.MaxLatency()); stats.MaxLatency != want || stats.P99Latency != DurationMs(r.Percentile(99)) {
+		t.Errorf("stored max = %v, p99 = %v; want the printed %v and %v", stats.MaxLatency, stats.P99Latency, want, DurationMs(r.Percentile(99)))
+	}
+}
+
+func TestResults_WriteJSON(t *testing.T) {
+	r := NewResults()
+	start := time.Now()
+	r.SetStartTime(start)
+	for i := 1; i <= 4; i++ {
+		r.Add(Sample{Latency: time.Duration(i) * time.Millisecond, Success: i != 4, Timestamp: start})
+	}
+	r.SetEndTime(start.Add(2 * time.Second))
+
+	var buf bytes.Buffer
+	if err := r.WriteJSON(&buf, &db.BenchmarkRun{Scenario: "balance", Protocol: "grpc", Concurrency: 2}); err != nil {
+		t.Fatalf("WriteJSON() error = %v", err)
+	}
+	var report RunReport
+	if err := json.Unmarshal(buf.Bytes(), &report); err != nil {
+		t.Fatalf("WriteJSON() wrote invalid JSON: %v", err)
+	}
+	if report.Run.Scenario != "balance" || report.Run.DurationSec != 2 {
+		t.Errorf("run = %+v, want the balance scenario over 2s", report.Run)
+	}
+	if report.Requests != 4 || report.Successful != 3 || report.Throughput != 2 {
+		t.Errorf("report = %d requests, %d successful, %v req/s; want 4, 3, 2", report.Requests, report.Successful, report.Throughput)
+	}
+	if p50 := report.LatencyMs["p50"]; p50 < 1.99 || p50 > 2.01 {
+		t.Errorf("p50 = %vms, want 2ms", p50)
+	}
+}
+
+func TestResults_Efficiency(t *testing.T) {
+	ctx := context.Background()
+	store, err := db.OpenLocal(ctx, filepath.Join(t.TempDir(), "results.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+
+	r := NewResults()
+	start := time.Now()
+	r.SetStartTime(start)
+	for i := 0; i < 1000; i++ {
+		r.Add(Sample{Latency: time.Millisecond, Success: true, Timestamp: start})
+	}
+	r.SetEndTime(start.Add(time.Second))
+
+	if _, ok := r.ClientEfficiency(); ok {
+		t.Error("ClientEfficiency() without resource stats should not be ok")
+	}
+	if _, ok := r.ServerEfficiency(); ok {
+		t.Error("ServerEfficiency() without server CPU time should not be ok")
+	}
+
+	r.SetResourceStats(ResourceStats{CPUSeconds: 0.5})
+	r.SetServerCPUSeconds(0.25)
+	if got, ok := r.ClientEfficiency(); !ok || got != 2000 {
+		t.Errorf("ClientEfficiency() = %v, %v; want 2000, true", got, ok)
+	}
+	if got, ok := r.ServerEfficiency(); !ok || got != 4000 {
+		t.Errorf("ServerEfficiency() = %v, %v; want 4000, true", got, ok)
+	}
+
+	runID, err := r.StoreResults(ctx, store, &db.BenchmarkRun{Scenario: "balance", Protocol: "grpc", Concurrency: 1})
+	if err != nil {
+		t.Fatalf("StoreResults() error = %v", err)
+	}
+	stats, err := store.GetStats(ctx, runID)
+	if err != nil {
+		t.Fatalf("GetStats() error = %v", err)
+	}
+	if stats.ClientPerCPUSec == nil || *stats.ClientPerCPUSec != 2000 || stats.ServerPerCPUSec == nil || *stats.ServerPerCPUSec != 4000 {
+		t.Errorf("stored efficiency = %v, %v; want 2000 and 4000", stats.ClientPerCPUSec, stats.ServerPerCPUSec)
+	}
+	if stats.ServerCPUSeconds == nil || *stats.ServerCPUSeconds != 0.25 {
+		t.Errorf("stored server CPU = %v, want 0.25", stats.ServerCPUSeconds)
+	}
+}
+
+func TestResults_LatencyHistogram(t *testing.T) {
+	r := NewResults()
+	for _, d := range []time.Duration{
+		300 * time.Microsecond, 400 * time.Microsecond, // 200us-500us
+		700 * time.Microsecond,                                              // 500us-1ms
+		3 * time.Millisecond, 4 * time.Millisecond, 4500 * time.Microsecond, // 2ms-5ms
+	} {
+		r.Add(Sample{Latency: d, Success: true})
+	}
+	r.Add(Sample{Latency: time.Second, Success: false})
+
+	buckets := r.LatencyHistogram()
+	want := []LatencyBucket{
+		{200 * time.Microsecond, 500 * time.Microsecond, 2},
+		{500 * time.Microsecond, time.Millisecond, 1},
+		{time.Millisecond, 2 * time.Millisecond, 0},
+		{2 * time.Millisecond, 5 * time.Millisecond, 3},
+	}
+	if len(buckets) != len(want) {
+		t.Fatalf("LatencyHistogram() = %v, want %v", buckets, want)
+	}
+	for i := range want {
+		if buckets[i] != want[i] {
+			t.Errorf("bucket %d = %+v, want %+v", i, buckets[i], want[i])
+		}
+	}
+
+	if got := NewResults().LatencyHistogram(); got != nil {
+		t.Errorf("LatencyHistogram() with no samples = %v, want nil", got)
+	}
+}
+
+func TestParsePercentiles(t *testing.T) {
+	got, err := ParsePercentiles([]string{"p50", "99.9", "P99.99", "p100"})
+	if err != nil {
+		t.Fatalf("ParsePercentiles() error = %v", err)
+	}
+	want := []float64{50, 99.9, 99.99, 100}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("percentile %d = %v, want %v", i, got[i], want[i])
+		}
+	}
+
+	for _, bad := range []string{"p0", "p101", "median", ""} {
+		if _, err := ParsePercentiles([]string{bad}); err == nil {
+			t.Errorf("ParsePercentiles(%q) succeeded, want an error", bad)
+		}
+	}
+}
@@ -0,0 +1,322 @@
+package bench
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/shirou/gopsutil/v4/cpu"
+	psnet "github.com/shirou/gopsutil/v4/net"
+	"github.com/shirou/gopsutil/v4/process"
+)
+
+// ResourceStats holds aggregated resource usage metrics.
+type ResourceStats struct {
+	CPUAvgPercent  float64
+	CPUSeconds     float64 // CPU time used between Start and the last sample
+	MemoryAvgMB    float64
+	MemoryPeakMB   float64
+	SampleCount    int
+	GoroutineCount int
+	Net            *NetStats // nil if the interface counters could not be read
+
+	// Go runtime of the client: peaks over the run, totals, and the
+	// per-second samples they come from
+	GoroutinesPeak  int
+	HeapObjectsPeak uint64
+	HeapMBPeak      float64
+	GCCycles        int
+	GCPause         time.Duration
+	Runtime         []RuntimeSample
+	runtimeStart    time.Time // what RuntimeSample.Second counts from
+}
+
+// NetStats holds the bytes moved through the host's network interfaces
+// during a run, read from the interface counters (/proc/net/dev on Linux).
+// Unlike application-level byte counts they include TCP, TLS and HTTP/2
+// framing, but also any other traffic on the same interfaces.
+type NetStats struct {
+	Interfaces []string // interfaces that carried traffic
+	Loopback   bool     // counted on loopback, where every byte is both sent and received
+	BytesSent  uint64
+	BytesRecv  uint64
+}
+
+// WireBytes returns the bytes that crossed the wire in either direction.
+func (n *NetStats) WireBytes() uint64 {
+	if n.Loopback {
+		return n.BytesSent
+	}
+	return n.BytesSent + n.BytesRecv
+}
+
+// ResourceMonitor samples CPU, memory and Go runtime metrics during
+// benchmark execution.
+type ResourceMonitor struct {
+	proc     *process.Process
+	interval time.Duration
+
+	mu           sync.Mutex
+	cpuSamples   []float64
+	cpuSeconds   float64
+	memSamples   []float64
+	memPeak      float64
+	sampleCount  int
+	lastCPUTimes *cpu.TimesStat
+	lastCPUTime  time.Time
+	runtime      *runtimeSampler
+
+	loopback bool                   // count network bytes on loopback interfaces
+	netStart []psnet.IOCountersStat // per-interface counters at Start, nil if unreadable
+	net      *NetStats
+}
+
+// NewResourceMonitor creates a new monitor for the current process.
+func NewResourceMonitor(interval time.Duration) (*ResourceMonitor, error) {
+	proc, err := process.NewProcess(int32(getPid()))
+	if err != nil {
+		return nil, err
+	}
+
+	return &ResourceMonitor{
+		proc:       proc,
+		interval:   interval,
+		cpuSamples: make([]float64, 0, 100),
+		memSamples: make([]float64, 0, 100),
+	}, nil
+}
+
+func getPid() int {
+	return os.Getpid()
+}
+
+// SetLoopback selects the interfaces whose byte counters are measured: the
+// loopback interfaces when the server runs on the same host, otherwise all
+// others.
+func (m *ResourceMonitor) SetLoopback(loopback bool) {
+	m.loopback = loopback
+}
+
+// Start begins collecting resource samples in the background.
+// Returns a stop function that should be called when monitoring is complete.
+func (m *ResourceMonitor) Start(ctx context.Context) func() ResourceStats {
+	// Take initial CPU reading for delta calculation
+	m.lastCPUTimes, _ = m.proc.TimesWithContext(ctx)
+	m.lastCPUTime = time.Now()
+	m.runtime = newRuntimeSampler(m.lastCPUTime)
+	m.netStart, _ = psnet.IOCountersWithContext(ctx, true)
+
+	stopCh := make(chan struct{})
+	doneCh := make(chan struct{})
+
+	go func() {
+		defer close(doneCh)
+		ticker := time.NewTicker(m.interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-stopCh:
+				return
+			case <-ticker.C:
+				m.sample(ctx)
+			}
+		}
+	}()
+
+	return func() ResourceStats {
+		close(stopCh)
+		<-doneCh
+		m.mu.Lock()
+		m.runtime.sample(time.Now())
+		m.runtime.flush()
+		m.mu.Unlock()
+		m.stopNet()
+		return m.Stats()
+	}
+}
+
+// stopNet computes the bytes moved through the selected interfaces since
+// Start. The run context may be done by now, so the counters are read
+// without it.
+func (m *ResourceMonitor) stopNet() {
+	if m.netStart == nil {
+		return
+	}
+	end, err := psnet.IOCounters(true)
+	if err != nil {
+		return
+	}
+
+	loopbacks := loopbackInterfaces()
+	stats := netDeltas(m.netStart, end, func(name string) bool {
+		return loopbacks[name] == m.loopback
+	})
+	stats.Loopback = m.loopback
+
+	m.mu.Lock()
+	m.net = &stats
+	m.mu.Unlock()
+}
+
+// netDeltas sums the counter increases between two per-interface snapshots
+// over the interfaces include selects.
+func netDeltas(start, end []psnet.IOCountersStat, include func(name string) bool) NetStats {
+	before := make(map[string]psnet.IOCountersStat, len(start))
+	for _, c := range start {
+		before[c.Name] = c
+	}
+
+	var stats NetStats
+	for _, c := range end {
+		b, ok := before[c.Name]
+		if !ok || !include(c.Name) || c.BytesSent < b.BytesSent || c.BytesRecv < b.BytesRecv {
+			continue
+		}
+		sent, recv := c.BytesSent-b.BytesSent, c.BytesRecv-b.BytesRecv
+		if sent == 0 && recv == 0 {
+			continue
+		}
+		stats.Interfaces = append(stats.Interfaces, c.Name)
+		stats.BytesSent += sent
+		stats.BytesRecv += recv
+	}
+	return stats
+}
+
+// loopbackInterfaces returns the names of the host's loopback interfaces.
+func loopbackInterfaces() map[string]bool {
+	names := make(map[string]bool)
+	ifaces, err := net.Interfaces()
+	if err != nil {
+		return names
+	}
+	for _, iface := range ifaces {
+		if iface.Flags&net.FlagLoopback != 0 {
+			names[iface.Name] = true
+		}
+	}
+	return names
+}
+
+// isLoopbackAddr reports whether a server address, with or without a URL
+// scheme, names this host.
+func isLoopbackAddr(addr string) bool {
+	host := addr
+	if u, err := url.Parse(addr); err == nil && u.Host != "" {
+		host = u.Host
+	}
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	if strings.EqualFold(host, "localhost") {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// formatBytes formats a byte count with a binary unit.
+func formatBytes(n uint64) string {
+	switch {
+	case n >= 1<<30:
+		return fmt.Sprintf("%.1f GB", float64(n)/(1<<30))
+	case n >= 1<<20:
+		return fmt.Sprintf("%.1f MB", float64(n)/(1<<20))
+	case n >= 1<<10:
+		return fmt.Sprintf("%.1f KB", float64(n)/(1<<10))
+	default:
+		return fmt.Sprintf("%d B", n)
+	}
+}
+
+func (m *ResourceMonitor) sample(ctx context.Context) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	// Sample memory
+	memInfo, err := m.proc.MemoryInfoWithContext(ctx)
+	if err == nil {
+		memMB := float64(memInfo.RSS) / (1024 * 1024)
+		m.memSamples = append(m.memSamples, memMB)
+		if memMB > m.memPeak {
+			m.memPeak = memMB
+		}
+	}
+
+	// Sample CPU (calculate delta since last sample)
+	cpuTimes, err := m.proc.TimesWithContext(ctx)
+	if err == nil && m.lastCPUTimes != nil {
+		now := time.Now()
+		elapsed := now.Sub(m.lastCPUTime).Seconds()
+		if elapsed > 0 {
+			// Total CPU time used since last sample
+			cpuDelta := (cpuTimes.User + cpuTimes.System) -
+				(m.lastCPUTimes.User + m.lastCPUTimes.System)
+			// Percentage of elapsed wall-clock time
+			cpuPercent := (cpuDelta / elapsed) * 100
+			m.cpuSamples = append(m.cpuSamples, cpuPercent)
+			m.cpuSeconds += cpuDelta
+		}
+		m.lastCPUTimes = cpuTimes
+		m.lastCPUTime = now
+	}
+
+	if m.runtime != nil {
+		m.runtime.sample(time.Now())
+	}
+
+	m.sampleCount++
+}
+
+// Stats returns aggregated resource statistics.
+func (m *ResourceMonitor) Stats() ResourceStats {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	stats := ResourceStats{
+		SampleCount:    m.sampleCount,
+		CPUSeconds:     m.cpuSeconds,
+		MemoryPeakMB:   m.memPeak,
+		GoroutineCount: runtime.NumGoroutine(),
+		Net:            m.net,
+	}
+
+	if m.runtime != nil {
+		stats.Runtime = m.runtime.samples
+		stats.runtimeStart = m.runtime.start
+		for _, s := range stats.Runtime {
+			stats.GoroutinesPeak = max(stats.GoroutinesPeak, s.Goroutines)
+			stats.HeapObjectsPeak = max(stats.HeapObjectsPeak, s.HeapObjects)
+			stats.HeapMBPeak = max(stats.HeapMBPeak, s.HeapMB)
+			stats.GCCycles += s.GCCycles
+			stats.GCPause += s.GCPause
+		}
+	}
+
+	if len(m.cpuSamples) > 0 {
+		var total float64
+		for _, v := range m.cpuSamples {
+			total += v
+		}
+		stats.CPUAvgPercent = total / float64(len(m.cpuSamples))
+	}
+
+	if len(m.memSamples) > 0 {
+		var total float64
+		for _, v := range m.memSamples {
+			total += v
+		}
+		stats.MemoryAvgMB = total / float64(len(m.memSamples))
+	}
+
+	return stats
+}
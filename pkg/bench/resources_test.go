@@ -0,0 +1,110 @@
+package bench
+
+import (
+	"runtime"
+	"slices"
+	"testing"
+	"time"
+
+	psnet "github.com/shirou/gopsutil/v4/net"
+)
+
+func TestNetDeltas(t *testing.T) {
+	start := []psnet.IOCountersStat{
+		{Name: "lo", BytesSent: 1000, BytesRecv: 1000},
+		{Name: "eth0", BytesSent: 500, BytesRecv: 700},
+		{Name: "eth1", BytesSent: 10, BytesRecv: 10},
+		{Name: "wlan0", BytesSent: 900, BytesRecv: 900},
+	}
+	end := []psnet.IOCountersStat{
+		{Name: "lo", BytesSent: 5000, BytesRecv: 5000},
+		{Name: "eth0", BytesSent: 800, BytesRecv: 1700},
+		{Name: "eth1", BytesSent: 10, BytesRecv: 10},    // idle
+		{Name: "wlan0", BytesSent: 100, BytesRecv: 100}, // counter reset
+		{Name: "eth2", BytesSent: 50, BytesRecv: 50},    // appeared during the run
+	}
+
+	got := netDeltas(start, end, func(name string) bool { return name != "lo" })
+	if !slices.Equal(got.Interfaces, []string{"eth0"}) || got.BytesSent != 300 || got.BytesRecv != 1000 {
+		t.Errorf("netDeltas(non-loopback) = %+v, want eth0 with 300 sent and 1000 received", got)
+	}
+
+	lo := netDeltas(start, end, func(name string) bool { return name == "lo" })
+	lo.Loopback = true
+	if lo.BytesSent != 4000 || lo.WireBytes() != 4000 {
+		t.Errorf("netDeltas(loopback) = %+v, want 4000 bytes on the wire", lo)
+	}
+}
+
+func TestIsLoopbackAddr(t *testing.T) {
+	tests := []struct {
+		addr string
+		want bool
+	}{
+		{"localhost:50051", true},
+		{"http://localhost:8080", true},
+		{"127.0.0.1:50051", true},
+		{"http://[::1]:8080", true},
+		{"10.0.0.5:50051", false},
+		{"http://bench.example.com:8080", false},
+		{"rest-server:8080", false},
+	}
+	for _, tt := range tests {
+		if got := isLoopbackAddr(tt.addr); got != tt.want {
+			t.Errorf("isLoopbackAddr(%q) = %v, want %v", tt.addr, got, tt.want)
+		}
+	}
+}
+
+func TestRuntimeSampler(t *testing.T) {
+	start := time.Now()
+	s := newRuntimeSampler(start)
+
+	s.sample(start.Add(100 * time.Millisecond))
+	runtime.GC()
+	s.sample(start.Add(1100 * time.Millisecond))
+	// Nothing sampled in second 2
+	s.sample(start.Add(3500 * time.Millisecond))
+	s.flush()
+
+	seconds := make([]int, len(s.samples))
+	var cycles int
+	for i, rs := range s.samples {
+		seconds[i] = rs.Second
+		cycles += rs.GCCycles
+		if rs.Goroutines < 1 || rs.HeapObjects == 0 || rs.HeapMB <= 0 {
+			t.Errorf("second %d: %+v, want goroutines and heap", rs.Second, rs)
+		}
+	}
+	if want := []int{0, 1, 3}; !slices.Equal(seconds, want) {
+		t.Errorf("seconds = %v, want %v", seconds, want)
+	}
+	if cycles < 1 {
+		t.Errorf("GC cycles = %d, want at least the forced one", cycles)
+	}
+}
+
+func TestResults_TimeseriesRuntime(t *testing.T) {
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	r := NewResults()
+	r.SetStartTime(start)
+	r.Add(Sample{Timestamp: start.Add(500 * time.Millisecond), Latency: time.Millisecond, Success: true})
+	r.Add(Sample{Timestamp: start.Add(1500 * time.Millisecond), Latency: time.Millisecond, Success: true})
+	// The monitor starts a few milliseconds before the run
+	r.SetResourceStats(ResourceStats{
+		Runtime: []RuntimeSample{
+			{Second: 0, Goroutines: 10},
+			{Second: 1, Goroutines: 20, GCCycles: 2},
+			{Second: 2, Goroutines: 30},
+		},
+		runtimeStart: start.Add(-5 * time.Millisecond),
+	})
+
+	points := r.Timeseries()
+	if len(points) != 2 {
+		t.Fatalf("got %d points, want 2", len(points))
+	}
+	if p := points[1].Runtime; p == nil || p.Goroutines != 20 || p.GCCycles != 2 {
+		t.Errorf("second 1 runtime = %+v, want the monitor's second 1", p)
+	}
+}
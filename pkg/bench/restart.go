@@ -0,0 +1,101 @@
+package bench
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+)
+
+// Server restarts during a run (Config.ChaosRestart). The client asks the
+// server to restart gracefully partway through the run and measures the
+// burst of errors the restart causes and how long until requests succeed
+// again.
+
+// RestartStats describes how the run weathered a server restart.
+type RestartStats struct {
+	At time.Time // when the restart was requested
+
+	// Errors counts the requests that failed from the restart until the
+	// client recovered, or until the end of the run if it did not.
+	Errors int
+
+	// Recovered is true once a request sent after the first failure
+	// succeeded, or if no request failed. Recovery is the time from the
+	// restart to the end of that request, 0 if no request failed.
+	Recovered bool
+	Recovery  time.Duration
+}
+
+// restartTracker follows the samples of a run after a server restart. The
+// restart is marked by the goroutine that requests it, while the collector
+// adds the samples.
+type restartTracker struct {
+	at atomic.Int64 // UnixNano of the restart request, 0 until then
+
+	errors       int
+	firstFailure time.Time // end of the first failed request, zero until one failed
+	recovered    time.Time // end of the first request succeeding after it
+}
+
+// mark records that the restart was requested at t.
+func (t *restartTracker) mark(at time.Time) {
+	t.at.Store(at.UnixNano())
+}
+
+// add follows a sample. Requests that ended before the restart, and those
+// canceled at the end of the run, do not count.
+func (t *restartTracker) add(s Sample) {
+	at := t.at.Load()
+	end := s.Timestamp.Add(s.Latency)
+	if at == 0 || end.UnixNano() < at || !t.recovered.IsZero() {
+		return
+	}
+	if !s.Success {
+		if classifyError(s.Error) == errorTypeCanceled {
+			return
+		}
+		t.errors++
+		if t.firstFailure.IsZero() {
+			t.firstFailure = end
+		}
+		return
+	}
+	if !t.firstFailure.IsZero() && !s.Timestamp.Before(t.firstFailure) {
+		t.recovered = end
+	}
+}
+
+// stats returns the restart stats, with ok false if no restart was
+// requested.
+func (t *restartTracker) stats() (stats RestartStats, ok bool) {
+	at := t.at.Load()
+	if at == 0 {
+		return RestartStats{}, false
+	}
+	stats = RestartStats{At: time.Unix(0, at), Errors: t.errors}
+	switch {
+	case t.firstFailure.IsZero():
+		stats.Recovered = true
+	case !t.recovered.IsZero():
+		stats.Recovered = true
+		stats.Recovery = t.recovered.Sub(stats.At)
+	}
+	return stats, true
+}
+
+// restartServer requests the server restart cfg.ChaosRestart after start,
+// marking it in results, unless ctx is done first. It returns the error of
+// the request, or ctx's error if the run ended before it.
+func restartServer(ctx context.Context, cfg *Config, results *Results, start time.Time) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-time.After(time.Until(start.Add(cfg.ChaosRestart))):
+	}
+	at := time.Now()
+	if err := cfg.RestartServer(ctx); err != nil {
+		return err
+	}
+	results.restart.mark(at)
+	return nil
+}
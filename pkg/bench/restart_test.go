@@ -0,0 +1,57 @@
+package bench
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestRestartTracker(t *testing.T) {
+	start := time.Now()
+	at := func(ms int) time.Time { return start.Add(time.Duration(ms) * time.Millisecond) }
+	sample := func(sentMs, latencyMs int, err error) Sample {
+		return Sample{Timestamp: at(sentMs), Latency: time.Duration(latencyMs) * time.Millisecond, Success: err == nil, Error: err}
+	}
+	refused := errors.New("connection refused")
+
+	var tr restartTracker
+	tr.add(sample(0, 10, refused)) // before the restart was requested
+	if _, ok := tr.stats(); ok {
+		t.Fatal("stats() ok before the restart")
+	}
+
+	tr.mark(at(100))
+	tr.add(sample(50, 10, refused)) // ended before the restart
+	tr.add(sample(95, 10, nil))     // drained
+	tr.add(sample(110, 5, refused)) // first failure, ends at 115
+	tr.add(sample(112, 20, nil))    // sent before the first failure
+	tr.add(sample(120, 5, refused))
+	tr.add(sample(300, 20, nil))    // recovered at 320
+	tr.add(sample(330, 5, refused)) // after recovery
+	tr.add(sample(340, 5, context.Canceled))
+
+	stats, ok := tr.stats()
+	if !ok {
+		t.Fatal("stats() not ok after the restart")
+	}
+	if stats.Errors != 2 || !stats.Recovered || stats.Recovery != 220*time.Millisecond {
+		t.Errorf("stats = %d errors, recovered %v after %s; want 2 errors, recovered after 220ms",
+			stats.Errors, stats.Recovered, stats.Recovery)
+	}
+
+	var unrecovered restartTracker
+	unrecovered.mark(at(100))
+	unrecovered.add(sample(110, 5, refused))
+	unrecovered.add(sample(120, 5, context.Canceled))
+	if stats, _ := unrecovered.stats(); stats.Errors != 1 || stats.Recovered {
+		t.Errorf("stats = %d errors, recovered %v; want 1 error, not recovered", stats.Errors, stats.Recovered)
+	}
+
+	var unharmed restartTracker
+	unharmed.mark(at(100))
+	unharmed.add(sample(110, 5, nil))
+	if stats, _ := unharmed.stats(); stats.Errors != 0 || !stats.Recovered || stats.Recovery != 0 {
+		t.Errorf("stats = %+v, want no errors and no recovery time", stats)
+	}
+}
@@ -0,0 +1,1040 @@
+package bench
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/rand"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/kaldun-tech/grpc-rest-benchmark/pkg/timing"
+	"github.com/kaldun-tech/grpc-rest-benchmark/pkg/workload"
+)
+
+// Stream latency definitions selectable with --stream-metric.
+const (
+	StreamMetricInterArrival = "inter-arrival" // gap between consecutive events on a stream
+	StreamMetricDelivery     = "delivery"      // server emit time to client receipt
+	StreamMetricProcessing   = "processing"    // client receipt to consumption by the runner
+	StreamMetricEndToEnd     = "end-to-end"    // transaction stored to client receipt (live streams)
+)
+
+// StreamMetrics lists the stream latency definitions.
+var StreamMetrics = []string{StreamMetricInterArrival, StreamMetricDelivery, StreamMetricProcessing, StreamMetricEndToEnd}
+
+// Sample represents a single benchmark measurement.
+type Sample struct {
+	Latency   time.Duration
+	Success   bool
+	Error     error
+	Timestamp time.Time
+
+	// Stream scenario only: every latency definition measured for the event.
+	// Latency holds whichever one was selected as the primary metric.
+	Stream StreamLatencies
+
+	// Stream scenario only: serialized size of the received message in
+	// bytes. Zero for unary requests.
+	MessageBytes int
+
+	// Reconnecting streams only: how long the stream was disconnected
+	// before this event, the first after reopening it. Zero otherwise.
+	Reconnect time.Duration
+
+	// Balance scenario only: age of the returned balance at receipt
+	// (now - UpdatedAt). Zero unless staleness measurement is enabled.
+	Staleness time.Duration
+
+	// Unary requests only: time the server reported spending in database
+	// queries for the request. DBTimed is false if it did not report it.
+	DBTime  time.Duration
+	DBTimed bool
+
+	// Load profile phase the request was issued in, counted from 1. Zero
+	// when the run has no load profile.
+	Phase int
+
+	// Workload class of the sample when a run mixes workloads: "stream" or
+	// "balance" in the stream-balance scenario, "write" or "balance" in the
+	// write scenario, and the operation in the mixed scenario. Empty
+	// otherwise.
+	Class string
+
+	// Operation is the RPC the sample measured, one of the Op constants.
+	Operation string
+
+	// Stream subscriber the event was received on, counted from 1. Zero
+	// for unary requests.
+	Subscriber int
+
+	// GCPause is set on the retained samples once the run ends if the
+	// request or event was in flight during a GC pause of the client.
+	GCPause bool
+}
+
+// Operations recorded with each sample.
+const (
+	OpGetBalance         = "GetBalance"
+	OpGetBalances        = "GetBalances"
+	OpSubmitTransaction  = "SubmitTransaction"
+	OpEcho               = "Echo"
+	OpStreamTransactions = "StreamTransactions"
+)
+
+// StreamLatencies holds the latency of a stream event under each definition.
+// A zero value means that definition was unavailable for the event.
+type StreamLatencies struct {
+	InterArrival time.Duration
+	Delivery     time.Duration
+	Processing   time.Duration
+	EndToEnd     time.Duration
+}
+
+// Get returns the latency for the named stream metric.
+func (l StreamLatencies) Get(metric string) time.Duration {
+	switch metric {
+	case StreamMetricDelivery:
+		return l.Delivery
+	case StreamMetricProcessing:
+		return l.Processing
+	case StreamMetricEndToEnd:
+		return l.EndToEnd
+	default:
+		return l.InterArrival
+	}
+}
+
+// Runner manages benchmark load generation.
+type Runner struct {
+	client       BenchmarkClient
+	accountIDs   []string
+	concurrency  int
+	rate         int
+	results      chan Sample
+	mu           sync.Mutex
+	rng          *rand.Rand
+	timingReplay *timing.Replay         // Optional timing replay for realistic workloads
+	streamMetric string                 // Stream latency definition used for Sample.Latency
+	staleness    BalanceTimestampClient // Non-nil when measuring balance staleness
+	echo         EchoClient             // Non-nil when a payload size is set
+	batch        BatchBalanceClient     // Non-nil when the operation mix includes batch reads
+	writer       WriteClient            // Non-nil when a write ratio is set or the mix includes writes
+	live         LiveStreamClient       // Non-nil when streams follow live transactions: --live and the fanout scenario
+	resume       ResumableStreamClient  // Non-nil when replay streams reconnect
+	chaos        time.Duration          // Cut each reconnecting stream this long after opening it, 0 for never
+	writeRatio   float64                // Fraction of write scenario requests that submit a transaction
+	payloadSize  int                    // Echo scenario response size in bytes
+	timeout      time.Duration          // Deadline for each unary request, 0 for none
+	backoff      bool                   // Back off after rate limited unary requests
+	accounts     workload.Sampler       // Account access pattern, uniform by default
+	poisson      bool                   // Exponentially distributed gaps around the target rate
+	mix          []MixOperation         // Weighted unary operations for RunMix
+	subscribers  int                    // Stream subscribers in RunStreamBalance and RunFanout
+	streamRate   int                    // Events/s per subscriber in RunStreamBalance
+	checkOrder   bool                   // Record received transaction IDs per subscriber
+	ordering     [][]string             // Per-subscriber transaction IDs, filled by RunStream
+
+	// Streams each RunStream worker goroutine owns, 0 for 1
+	streamsPerWorker int
+
+	// CPU time spent consuming each response or event, 0 for none
+	processCost time.Duration
+
+	// Streams started by the last run, those the server ended with a sent
+	// count, and the transactions they sent but did not deliver
+	streams         int
+	streamEnds      atomic.Int64
+	streamShortfall atomic.Int64
+
+	// Fewest and most transactions a stream the server ended reported
+	// sending; guarded by mu
+	streamSentMin int64
+	streamSentMax int64
+
+	// Closed once every worker of the run is started, so that they begin
+	// issuing requests together
+	started chan struct{}
+
+	// Load profile state; phase and phaseChanged are guarded by mu.
+	profile      *LoadProfile  // nil for a fixed load
+	phase        int           // index of the current profile phase
+	phaseChanged chan struct{} // closed when the phase advances
+}
+
+// MixOperation is one weighted entry in a mixed unary workload.
+type MixOperation struct {
+	Scenario    string // "balance", "batch", "write" or "echo"
+	Weight      int
+	PayloadSize int // echo only, in bytes
+	BatchSize   int // batch only, accounts per request
+}
+
+// NewRunner creates a new benchmark runner.
+func NewRunner(client BenchmarkClient, accountIDs []string, concurrency, rate int) *Runner {
+	return &Runner{
+		client:       client,
+		accountIDs:   accountIDs,
+		concurrency:  concurrency,
+		rate:         rate,
+		results:      make(chan Sample, 10000),
+		started:      make(chan struct{}),
+		rng:          rand.New(rand.NewSource(time.Now().UnixNano())),
+		timingReplay: nil,
+		streamMetric: StreamMetricDelivery,
+	}
+}
+
+// SetAccountPattern selects how balance queries pick accounts.
+func (r *Runner) SetAccountPattern(accounts workload.Accounts) error {
+	if len(r.accountIDs) == 0 {
+		return nil
+	}
+	sampler, err := accounts.NewSampler(r.rng, len(r.accountIDs))
+	if err != nil {
+		return err
+	}
+	r.accounts = sampler
+	return nil
+}
+
+// SetPoissonArrivals makes paced unary workers draw exponentially distributed
+// gaps averaging the target rate, instead of sending at a fixed interval.
+func (r *Runner) SetPoissonArrivals(enabled bool) {
+	r.poisson = enabled
+}
+
+// SetOperationMix sets the weighted operations issued by RunMix. It fails if
+// the mix includes an operation the client cannot perform.
+func (r *Runner) SetOperationMix(ops []MixOperation) error {
+	for _, op := range ops {
+		switch op.Scenario {
+		case "balance":
+		case "batch":
+			bc, ok := r.client.(BatchBalanceClient)
+			if !ok {
+				return fmt.Errorf("client does not support batch balance queries")
+			}
+			if op.BatchSize < 1 {
+				return fmt.Errorf("batch size must be at least 1")
+			}
+			r.batch = bc
+		case "write":
+			wc, ok := r.client.(WriteClient)
+			if !ok {
+				return fmt.Errorf("client does not support submitting transactions")
+			}
+			r.writer = wc
+		case "echo":
+			ec, ok := r.client.(EchoClient)
+			if !ok {
+				return fmt.Errorf("client does not support the echo endpoint")
+			}
+			r.echo = ec
+		default:
+			return fmt.Errorf("scenario %q cannot be part of an operation mix", op.Scenario)
+		}
+		if op.Weight < 0 {
+			return fmt.Errorf("operation weight must not be negative")
+		}
+	}
+	r.mix = ops
+	return nil
+}
+
+// SetLoadProfile varies the concurrency or target rate of unary runs
+// through the profile's phases. With a concurrency profile the runner starts
+// one worker per unit of the highest level and parks those above the
+// current level.
+func (r *Runner) SetLoadProfile(p *LoadProfile) {
+	r.profile = p
+	r.phase = 0
+	r.phaseChanged = make(chan struct{})
+}
+
+// SetTimingReplay sets the timing replay for realistic workload pacing.
+func (r *Runner) SetTimingReplay(tr *timing.Replay) {
+	r.timingReplay = tr
+}
+
+// SetStreamMetric selects which stream latency definition populates
+// Sample.Latency in the stream scenario.
+func (r *Runner) SetStreamMetric(metric string) {
+	r.streamMetric = metric
+}
+
+// SetStreamSubscribers sets the number of stream subscribers RunStreamBalance
+// runs alongside the balance workers, and the events/s each requests (0 =
+// unlimited).
+func (r *Runner) SetStreamSubscribers(n, rate int) {
+	r.subscribers = n
+	r.streamRate = rate
+}
+
+// SetStreamsPerWorker sets how many concurrent streams each RunStream
+// worker goroutine owns, so concurrency counts workers rather than streams.
+func (r *Runner) SetStreamsPerWorker(n int) {
+	r.streamsPerWorker = n
+}
+
+// SetProcessCost sets the CPU time the runner spends consuming each unary
+// response and stream event, modeling application work in the client so
+// that protocol overhead competes with it for CPU. Workers spin rather than
+// sleep for it; a stream worker consumes the next event of its streams only
+// when done, so slow consumers show up as processing latency.
+func (r *Runner) SetProcessCost(cost time.Duration) {
+	r.processCost = cost
+}
+
+// SetCheckOrdering enables recording the transaction IDs each stream
+// subscriber receives, for OrderingReport.
+func (r *Runner) SetCheckOrdering(enabled bool) {
+	r.checkOrder = enabled
+}
+
+// OrderingReport compares the transaction IDs received by each subscriber of
+// the last RunStream. It returns false if the ordering check is disabled.
+func (r *Runner) OrderingReport() (OrderingReport, bool) {
+	if !r.checkOrder {
+		return OrderingReport{}, false
+	}
+	return checkOrdering(r.ordering), true
+}
+
+// StreamEnds returns how many streams of the last run the server ended with
+// a sent count, and how many of the transactions those reported sending
+// were not received.
+func (r *Runner) StreamEnds() (ended int, shortfall int64) {
+	return int(r.streamEnds.Load()), r.streamShortfall.Load()
+}
+
+// StreamSent returns the fewest and most transactions a stream of the last
+// run reported sending when the server ended it. It returns false if the
+// server ended none.
+func (r *Runner) StreamSent() (fewest, most int64, ok bool) {
+	if r.streamEnds.Load() == 0 {
+		return 0, 0, false
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.streamSentMin, r.streamSentMax, true
+}
+
+// SetMeasureStaleness enables recording the age of each returned balance.
+// It fails if the client cannot report balance update times.
+func (r *Runner) SetMeasureStaleness(enabled bool) error {
+	if !enabled {
+		r.staleness = nil
+		return nil
+	}
+	tc, ok := r.client.(BalanceTimestampClient)
+	if !ok {
+		return fmt.Errorf("client does not report balance update times")
+	}
+	r.staleness = tc
+	return nil
+}
+
+// SetPayloadSize sets the response size requested in the echo scenario.
+// It fails if the client cannot call the echo endpoint.
+func (r *Runner) SetPayloadSize(size int) error {
+	ec, ok := r.client.(EchoClient)
+	if !ok {
+		return fmt.Errorf("client does not support the echo endpoint")
+	}
+	r.echo = ec
+	r.payloadSize = size
+	return nil
+}
+
+// SetWriteRatio sets the fraction of write scenario requests that submit a
+// transaction; the others query a balance. It fails if the client cannot
+// submit transactions.
+func (r *Runner) SetWriteRatio(ratio float64) error {
+	if ratio < 0 || ratio > 1 {
+		return fmt.Errorf("write ratio must be between 0 and 1")
+	}
+	wc, ok := r.client.(WriteClient)
+	if !ok {
+		return fmt.Errorf("client does not support submitting transactions")
+	}
+	r.writer = wc
+	r.writeRatio = ratio
+	return nil
+}
+
+// SetLiveStreams makes the runner's streams follow the transactions stored
+// from then on instead of replaying stored ones, so that they last until
+// the run ends. It fails if the client does not support live streams.
+func (r *Runner) SetLiveStreams() error {
+	lc, ok := r.client.(LiveStreamClient)
+	if !ok {
+		return fmt.Errorf("client does not support live streams")
+	}
+	r.live = lc
+	return nil
+}
+
+// SetReconnect makes the runner's replay streams reopen after failures,
+// resuming after the last transaction received, and with chaos set cuts
+// each of them that long after it was opened. It fails if the client
+// cannot resume streams.
+func (r *Runner) SetReconnect(chaos time.Duration) error {
+	rc, ok := r.client.(ResumableStreamClient)
+	if !ok {
+		return fmt.Errorf("client does not support resuming streams")
+	}
+	r.resume = rc
+	r.chaos = chaos
+	return nil
+}
+
+// SetFanout prepares the runner for RunFanout: its streams follow the
+// transactions submitted from then on, and it submits them. It fails if the
+// client cannot do both.
+func (r *Runner) SetFanout() error {
+	if err := r.SetLiveStreams(); err != nil {
+		return err
+	}
+	wc, ok := r.client.(WriteClient)
+	if !ok {
+		return fmt.Errorf("client does not support submitting transactions")
+	}
+	r.writer = wc
+	return nil
+}
+
+// SetRequestTimeout sets the deadline applied to each unary request, 0 for
+// none. Requests that exceed it fail with a timeout error. Streams are not
+// subject to it.
+func (r *Runner) SetRequestTimeout(timeout time.Duration) {
+	r.timeout = timeout
+}
+
+// Backoff after rate limited requests (Runner.SetBackoff).
+const (
+	rateLimitBackoff    = 10 * time.Millisecond
+	maxRateLimitBackoff = time.Second
+)
+
+// SetBackoff makes each unary worker wait after a request the server
+// rejected as rate limited, from rateLimitBackoff doubling up to
+// maxRateLimitBackoff while rejections continue, with full jitter so that
+// workers do not retry in lockstep. Without it workers keep sending at
+// their rate and the rejections show how the server sheds the load.
+func (r *Runner) SetBackoff(enabled bool) {
+	r.backoff = enabled
+}
+
+// Results returns the channel for receiving benchmark samples.
+func (r *Runner) Results() <-chan Sample {
+	return r.results
+}
+
+// RunBalance executes the balance query benchmark.
+func (r *Runner) RunBalance(ctx context.Context) {
+	r.runUnary(ctx, r.balanceRequest)
+}
+
+// RunStreamBalance runs stream subscribers and balance query workers at the
+// same time against the same server and database, to measure how each
+// workload degrades the other. Samples carry their workload class.
+func (r *Runner) RunStreamBalance(ctx context.Context) {
+	var wg sync.WaitGroup
+	r.startStreams(ctx, &wg, r.subscribers, 1, r.streamRate, "stream")
+	r.startUnary(ctx, &wg, func(ctx context.Context) Sample {
+		s := r.balanceRequest(ctx)
+		s.Class = "balance"
+		return s
+	})
+	close(r.started)
+	wg.Wait()
+	close(r.results)
+}
+
+// RunFanout runs the fanout benchmark: the stream subscribers follow live
+// transactions while the unary workers submit new ones at the configured
+// rate, so that the server delivers every submission to every subscriber.
+// Stream samples carry the "stream" class and the generator's "write".
+// Subscribers that connect after the first submissions miss them.
+func (r *Runner) RunFanout(ctx context.Context) {
+	var wg sync.WaitGroup
+	r.startStreams(ctx, &wg, r.subscribers, 1, 0, "stream")
+	r.startUnary(ctx, &wg, r.writeRequest)
+	close(r.started)
+	wg.Wait()
+	close(r.results)
+}
+
+// RunWrite executes the write benchmark: each request submits a transfer
+// between two random accounts with probability writeRatio, and otherwise
+// queries a balance. Samples carry their workload class.
+func (r *Runner) RunWrite(ctx context.Context) {
+	r.runUnary(ctx, func(ctx context.Context) Sample {
+		r.mu.Lock()
+		write := r.rng.Float64() < r.writeRatio
+		r.mu.Unlock()
+		if write {
+			return r.writeRequest(ctx)
+		}
+		s := r.balanceRequest(ctx)
+		s.Class = "balance"
+		return s
+	})
+}
+
+// RunEcho executes the payload size benchmark: each request asks the server
+// to return a payload of the configured size.
+func (r *Runner) RunEcho(ctx context.Context) {
+	r.runUnary(ctx, r.echoRequest(r.payloadSize))
+}
+
+// RunMix executes a weighted mix of balance, batch, write and echo
+// requests, choosing the operation for each request at random in proportion
+// to its weight. Samples carry their operation as the workload class.
+func (r *Runner) RunMix(ctx context.Context) {
+	requests := make([]func(context.Context) Sample, len(r.mix))
+	total := 0
+	for i, op := range r.mix {
+		switch op.Scenario {
+		case "batch":
+			requests[i] = r.batchRequest(op.BatchSize)
+		case "write":
+			requests[i] = r.writeRequest
+		case "echo":
+			requests[i] = r.echoRequest(op.PayloadSize)
+		default:
+			requests[i] = r.balanceRequest
+		}
+		total += op.Weight
+	}
+
+	r.runUnary(ctx, func(ctx context.Context) Sample {
+		r.mu.Lock()
+		n := r.rng.Intn(max(total, 1))
+		r.mu.Unlock()
+		i := len(r.mix) - 1
+		for j, op := range r.mix {
+			if n < op.Weight {
+				i = j
+				break
+			}
+			n -= op.Weight
+		}
+		s := requests[i](ctx)
+		s.Class = r.mix[i].Scenario
+		return s
+	})
+}
+
+// runUnary runs one worker per unit of concurrency, each issuing requests
+// until ctx is done, and closes the results channel when they finish.
+func (r *Runner) runUnary(ctx context.Context, request func(context.Context) Sample) {
+	var wg sync.WaitGroup
+	r.startUnary(ctx, &wg, request)
+	close(r.started)
+	wg.Wait()
+	close(r.results)
+}
+
+// startUnary starts the unary workers, adding them to wg.
+func (r *Runner) startUnary(ctx context.Context, wg *sync.WaitGroup, request func(context.Context) Sample) {
+	workers := r.concurrency
+	if r.profile != nil {
+		if r.profile.Target == ProfileTargetConcurrency {
+			workers = r.profile.MaxLevel()
+		}
+		go r.advancePhases(ctx)
+	}
+
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go r.unaryWorker(withWorker(ctx, i), wg, i, request)
+	}
+}
+
+// advancePhases steps through the load profile until the last phase or
+// until ctx is done.
+func (r *Runner) advancePhases(ctx context.Context) {
+	if !r.awaitStart(ctx) {
+		return
+	}
+	for i, phase := range r.profile.Phases[:len(r.profile.Phases)-1] {
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(phase.Duration):
+		}
+
+		r.mu.Lock()
+		r.phase = i + 1
+		close(r.phaseChanged)
+		r.phaseChanged = make(chan struct{})
+		r.mu.Unlock()
+		LoggerFrom(ctx).Info("load phase",
+			"phase", i+2,
+			"target", r.profile.Target,
+			"level", r.profile.Phases[i+1].Level,
+		)
+	}
+}
+
+// loadState is the load in effect for the current profile phase.
+type loadState struct {
+	phase       int // counted from 1, zero without a profile
+	concurrency int
+	rate        int
+	changed     <-chan struct{} // closed when the phase advances, nil without a profile
+}
+
+// currentLoad returns the concurrency and rate in effect now.
+func (r *Runner) currentLoad() loadState {
+	if r.profile == nil {
+		return loadState{concurrency: r.concurrency, rate: r.rate}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	load := loadState{
+		phase:       r.phase + 1,
+		concurrency: r.concurrency,
+		rate:        r.rate,
+		changed:     r.phaseChanged,
+	}
+	level := r.profile.Phases[r.phase].Level
+	if r.profile.Target == ProfileTargetConcurrency {
+		load.concurrency = level
+	} else {
+		load.rate = level
+	}
+	return load
+}
+
+func (r *Runner) unaryWorker(ctx context.Context, wg *sync.WaitGroup, index int, request func(context.Context) Sample) {
+	defer wg.Done()
+	if !r.awaitStart(ctx) {
+		return
+	}
+
+	next := time.Now()
+
+	var rng *rand.Rand
+	if r.poisson {
+		r.mu.Lock()
+		rng = rand.New(rand.NewSource(r.rng.Int63()))
+		r.mu.Unlock()
+	}
+	backoff := rateLimitBackoff
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		default:
+			load := r.currentLoad()
+
+			// Workers above the current concurrency level wait for the
+			// next phase
+			if index >= load.concurrency {
+				select {
+				case <-ctx.Done():
+					return
+				case <-load.changed:
+				}
+				next = time.Now()
+				continue
+			}
+
+			// Hold each worker to its share of the target rate (closed loop)
+			interval := requestInterval(load.concurrency, load.rate)
+			if interval > 0 {
+				if wait := time.Until(next); wait > 0 {
+					select {
+					case <-ctx.Done():
+						return
+					case <-time.After(wait):
+					}
+				}
+			}
+
+			// Apply timing replay delay if configured
+			if r.timingReplay != nil {
+				delay := r.timingReplay.NextDelay()
+				if delay > 0 {
+					select {
+					case <-ctx.Done():
+						return
+					case <-time.After(delay):
+					}
+				}
+			}
+
+			sample := r.issue(ctx, request)
+			sample.Phase = load.phase
+			r.consume()
+			received := sample.Timestamp.Add(sample.Latency)
+
+			// Sends missed while waiting on a slow response are skipped,
+			// not burst; coordinated-omission correction accounts for them.
+			if interval > 0 {
+				if rng != nil {
+					next = next.Add(time.Duration(rng.ExpFloat64() * float64(interval)))
+				} else {
+					next = next.Add(interval)
+				}
+				if next.Before(received) {
+					next = received
+				}
+			}
+
+			select {
+			case r.results <- sample:
+			case <-ctx.Done():
+				return
+			}
+
+			if r.backoff {
+				if classifyError(sample.Error) != errorTypeRateLimited {
+					backoff = rateLimitBackoff
+					continue
+				}
+				select {
+				case <-ctx.Done():
+					return
+				case <-time.After(time.Duration(rand.Int63n(int64(backoff)) + 1)):
+				}
+				backoff = min(2*backoff, maxRateLimitBackoff)
+				if now := time.Now(); next.Before(now) {
+					next = now
+				}
+			}
+		}
+	}
+}
+
+// issue sends one unary request, bounded by the request timeout if set,
+// with the database time the server reports for it.
+func (r *Runner) issue(ctx context.Context, request func(context.Context) Sample) Sample {
+	if r.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, r.timeout)
+		defer cancel()
+	}
+	ctx, db := withDBTime(ctx)
+	s := request(ctx)
+	s.DBTime, s.DBTimed = db.d, db.ok
+	return s
+}
+
+// consume keeps the calling goroutine busy for the process cost.
+func (r *Runner) consume() {
+	if r.processCost > 0 {
+		burnCPU(r.processCost)
+	}
+}
+
+// burnCPU spins for d. Unlike time.Sleep it occupies a CPU the whole time,
+// as application code handling a message would.
+func burnCPU(d time.Duration) {
+	for deadline := time.Now().Add(d); time.Now().Before(deadline); {
+	}
+}
+
+// balanceRequest queries the balance of a random account.
+func (r *Runner) balanceRequest(ctx context.Context) Sample {
+	accountID := r.randomAccount()
+	start := time.Now()
+	var err error
+	var updatedAt time.Time
+	if r.staleness != nil {
+		updatedAt, err = r.staleness.GetBalanceUpdatedAt(ctx, accountID)
+	} else {
+		err = r.client.GetBalance(ctx, accountID)
+	}
+	received := time.Now()
+
+	var staleness time.Duration
+	if err == nil && !updatedAt.IsZero() {
+		staleness = received.Sub(updatedAt)
+	}
+
+	return Sample{
+		Latency:   received.Sub(start),
+		Success:   err == nil,
+		Error:     err,
+		Timestamp: start,
+		Staleness: staleness,
+		Operation: OpGetBalance,
+	}
+}
+
+// batchRequest returns a request that queries the balances of size random
+// accounts at once.
+func (r *Runner) batchRequest(size int) func(context.Context) Sample {
+	return func(ctx context.Context) Sample {
+		ids := make([]string, size)
+		for i := range ids {
+			ids[i] = r.randomAccount()
+		}
+		start := time.Now()
+		err := r.batch.GetBalances(ctx, ids)
+		return Sample{
+			Latency:   time.Since(start),
+			Success:   err == nil,
+			Error:     err,
+			Timestamp: start,
+			Operation: OpGetBalances,
+		}
+	}
+}
+
+// writeRequest submits a transfer of a random amount between two distinct
+// random accounts.
+func (r *Runner) writeRequest(ctx context.Context) Sample {
+	from := r.randomAccount()
+	to := r.randomAccount()
+	for to == from && len(r.accountIDs) > 1 {
+		to = r.randomAccount()
+	}
+	r.mu.Lock()
+	amount := 1 + r.rng.Int63n(maxWriteAmount)
+	r.mu.Unlock()
+
+	start := time.Now()
+	err := r.writer.SubmitTransaction(ctx, from, to, amount)
+	return Sample{
+		Latency:   time.Since(start),
+		Success:   err == nil,
+		Error:     err,
+		Timestamp: start,
+		Class:     "write",
+		Operation: OpSubmitTransaction,
+	}
+}
+
+// maxWriteAmount is the largest transfer the write scenario submits, in
+// tinybar (1 HBAR).
+const maxWriteAmount = 100_000_000
+
+// echoRequest returns a request that asks the server for a payload of size
+// bytes.
+func (r *Runner) echoRequest(size int) func(context.Context) Sample {
+	return func(ctx context.Context) Sample {
+		start := time.Now()
+		err := r.echo.Echo(ctx, size)
+		return Sample{
+			Latency:   time.Since(start),
+			Success:   err == nil,
+			Error:     err,
+			Timestamp: start,
+			Operation: OpEcho,
+		}
+	}
+}
+
+// RequestInterval returns the expected time between requests from a single
+// unary (balance or echo) worker when a target rate is set, or zero if requests are unpaced.
+// With a load profile it is the interval for the current phase.
+func (r *Runner) RequestInterval() time.Duration {
+	load := r.currentLoad()
+	return requestInterval(load.concurrency, load.rate)
+}
+
+func requestInterval(concurrency, rate int) time.Duration {
+	if rate <= 0 {
+		return 0
+	}
+	return time.Duration(concurrency) * time.Second / time.Duration(rate)
+}
+
+func (r *Runner) randomAccount() string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.accounts != nil {
+		return r.accountIDs[r.accounts.Next()]
+	}
+	return r.accountIDs[r.rng.Intn(len(r.accountIDs))]
+}
+
+// RunStream executes the transaction streaming benchmark: one stream worker
+// per unit of concurrency, each owning SetStreamsPerWorker streams.
+func (r *Runner) RunStream(ctx context.Context) {
+	var wg sync.WaitGroup
+	r.startStreams(ctx, &wg, r.concurrency, max(r.streamsPerWorker, 1), r.rate, "")
+	close(r.started)
+	wg.Wait()
+	close(r.results)
+}
+
+// startStreams starts n stream workers owning perWorker subscriptions at
+// rate events/s each, adding them to wg. Their samples are tagged with
+// class.
+func (r *Runner) startStreams(ctx context.Context, wg *sync.WaitGroup, n, perWorker, rate int, class string) {
+	r.streams = n * perWorker
+	r.streamSentMin, r.streamSentMax = math.MaxInt64, 0
+	if r.checkOrder {
+		r.ordering = make([][]string, r.streams)
+	}
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go r.streamWorker(withWorker(ctx, i), wg, i*perWorker, perWorker, rate, class)
+	}
+}
+
+type workerKey struct{}
+
+// withWorker returns a context whose requests and streams are made by the
+// run's worker with the given index, so clients with a connection pool keep
+// the worker on one connection.
+func withWorker(ctx context.Context, index int) context.Context {
+	return context.WithValue(ctx, workerKey{}, index)
+}
+
+// workerIndex returns the index of the worker making requests with ctx, 0
+// outside the workers.
+func workerIndex(ctx context.Context) int {
+	index, _ := ctx.Value(workerKey{}).(int)
+	return index
+}
+
+// awaitStart blocks until every worker of the run is started, and reports
+// whether ctx is still live.
+func (r *Runner) awaitStart(ctx context.Context) bool {
+	select {
+	case <-r.started:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// subscription is one stream owned by a stream worker.
+type subscription struct {
+	subscriber int // index among the run's streams, counted from 0
+	events     <-chan StreamEvent
+	errs       <-chan error
+	lastEvent  time.Time
+}
+
+// streamWorker opens n streams, the run's subscribers first to first+n-1,
+// and consumes their events in one goroutine, as an asynchronous client
+// multiplexing several streams on one event loop would.
+func (r *Runner) streamWorker(ctx context.Context, wg *sync.WaitGroup, first, n, rate int, class string) {
+	defer wg.Done()
+	if !r.awaitStart(ctx) {
+		return
+	}
+
+	subs := make([]*subscription, n)
+	for i := range subs {
+		var eventCh <-chan StreamEvent
+		var errCh <-chan error
+		switch {
+		case r.live != nil:
+			eventCh, errCh = r.live.StreamLiveTransactions(ctx)
+		case r.resume != nil:
+			eventCh, errCh = reconnectingStream(ctx, r.resume, rate, r.chaos)
+		default:
+			eventCh, errCh = r.client.StreamTransactions(ctx, rate)
+		}
+		subs[i] = &subscription{subscriber: first + i, events: eventCh, errs: errCh}
+	}
+
+	// Select cases: ctx.Done, then the events and errors of each stream
+	cases := make([]reflect.SelectCase, 1+2*n)
+	cases[0] = reflect.SelectCase{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(ctx.Done())}
+	for i, sub := range subs {
+		cases[1+2*i] = reflect.SelectCase{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(sub.events)}
+		cases[2+2*i] = reflect.SelectCase{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(sub.errs)}
+	}
+
+	for open := n; open > 0; {
+		chosen, value, ok := reflect.Select(cases)
+		if chosen == 0 {
+			return
+		}
+		i := (chosen - 1) / 2
+		sub := subs[i]
+		if chosen%2 == 1 && ok {
+			if !r.streamEvent(ctx, sub, value.Interface().(StreamEvent), class) {
+				return
+			}
+			continue
+		}
+		if chosen%2 == 0 && ok && !value.IsNil() && ctx.Err() == nil {
+			err := value.Interface().(error)
+			LoggerFrom(ctx).Warn("stream error", "subscriber", sub.subscriber+1, "error", err.Error())
+			select {
+			case r.results <- Sample{
+				Success:    false,
+				Error:      err,
+				Timestamp:  time.Now(),
+				Class:      class,
+				Operation:  OpStreamTransactions,
+				Subscriber: sub.subscriber + 1,
+			}:
+			case <-ctx.Done():
+				return
+			}
+		}
+
+		// The stream is over: stop selecting on it
+		cases[1+2*i].Chan = reflect.Value{}
+		cases[2+2*i].Chan = reflect.Value{}
+		open--
+	}
+}
+
+// streamEvent records an event received on sub. It returns false if ctx
+// was done before the sample could be sent.
+func (r *Runner) streamEvent(ctx context.Context, sub *subscription, event StreamEvent, class string) bool {
+	if end := event.End; end != nil {
+		r.mu.Lock()
+		r.streamSentMin = min(r.streamSentMin, end.Sent)
+		r.streamSentMax = max(r.streamSentMax, end.Sent)
+		r.mu.Unlock()
+		r.streamEnds.Add(1)
+		r.streamShortfall.Add(end.Shortfall())
+		if end.Shortfall() != 0 {
+			LoggerFrom(ctx).Warn("stream ended short", "subscriber", sub.subscriber+1, "sent", end.Sent, "received", end.Received)
+		}
+		return true
+	}
+
+	lat := StreamLatencies{
+		Processing: time.Since(event.ReceivedAt),
+	}
+	if !sub.lastEvent.IsZero() {
+		lat.InterArrival = event.ReceivedAt.Sub(sub.lastEvent)
+	}
+	if !event.SentAt.IsZero() {
+		lat.Delivery = event.ReceivedAt.Sub(event.SentAt)
+	}
+	if !event.StoredAt.IsZero() {
+		lat.EndToEnd = event.ReceivedAt.Sub(event.StoredAt)
+	}
+	sub.lastEvent = event.ReceivedAt
+	if r.checkOrder && len(r.ordering[sub.subscriber]) < maxOrderingEvents {
+		r.ordering[sub.subscriber] = append(r.ordering[sub.subscriber], event.TxID)
+	}
+	r.consume()
+
+	select {
+	case r.results <- Sample{
+		Latency:      lat.Get(r.streamMetric),
+		Success:      true,
+		Timestamp:    event.ReceivedAt,
+		Stream:       lat,
+		MessageBytes: event.Size,
+		Reconnect:    event.Reconnect,
+		Class:        class,
+		Operation:    OpStreamTransactions,
+		Subscriber:   sub.subscriber + 1,
+	}:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
@@ -0,0 +1,96 @@
+package bench
+
+import (
+	"runtime/debug"
+	"runtime/metrics"
+	"time"
+)
+
+// Go runtime metrics read by the resource monitor on every sample. All are
+// cheap to read and, unlike runtime.ReadMemStats, none stops the world.
+const (
+	goroutinesMetric  = "/sched/goroutines:goroutines"
+	heapObjectsMetric = "/gc/heap/objects:objects"
+	heapBytesMetric   = "/memory/classes/heap/objects:bytes"
+)
+
+// RuntimeSample is the Go runtime state of the benchmark client in one
+// second of a run. CPU and RSS alone do not show when the garbage
+// collector causes a latency spike; GC cycles and pauses next to the heap
+// and goroutine counts do.
+type RuntimeSample struct {
+	Second      int // seconds since monitoring started
+	Goroutines  int // peak in the second
+	HeapObjects uint64
+	HeapMB      float64       // peak bytes occupied by heap objects
+	GCCycles    int           // cycles completed in the second
+	GCPause     time.Duration // stop-the-world time of those cycles
+}
+
+// runtimeSampler turns the runtime metrics read every monitor interval into
+// one RuntimeSample per second.
+type runtimeSampler struct {
+	start   time.Time
+	metrics []metrics.Sample
+	gc      debug.GCStats
+
+	cycles  uint64        // GC cycles completed when the current second began
+	paused  time.Duration // total GC pause when the current second began
+	current RuntimeSample
+	samples []RuntimeSample
+}
+
+func newRuntimeSampler(start time.Time) *runtimeSampler {
+	s := &runtimeSampler{
+		start: start,
+		metrics: []metrics.Sample{
+			{Name: goroutinesMetric},
+			{Name: heapObjectsMetric},
+			{Name: heapBytesMetric},
+			{Name: gcCyclesMetric},
+		},
+	}
+	metrics.Read(s.metrics)
+	s.cycles = s.uint64(3)
+	debug.ReadGCStats(&s.gc)
+	s.paused = s.gc.PauseTotal
+	return s
+}
+
+// uint64 returns metric i, or zero if this runtime does not support it.
+func (s *runtimeSampler) uint64(i int) uint64 {
+	if s.metrics[i].Value.Kind() != metrics.KindUint64 {
+		return 0
+	}
+	return s.metrics[i].Value.Uint64()
+}
+
+// sample reads the runtime metrics at now, closing the current second
+// first if now is past it.
+func (s *runtimeSampler) sample(now time.Time) {
+	if sec := int(now.Sub(s.start) / time.Second); sec > s.current.Second {
+		s.flush()
+		s.current = RuntimeSample{Second: sec}
+	}
+
+	metrics.Read(s.metrics)
+	s.current.Goroutines = max(s.current.Goroutines, int(s.uint64(0)))
+	s.current.HeapObjects = max(s.current.HeapObjects, s.uint64(1))
+	s.current.HeapMB = max(s.current.HeapMB, float64(s.uint64(2))/(1024*1024))
+}
+
+// flush closes the current second, attributing to it the GC cycles and
+// pauses since the previous one. Seconds without a sample are left out.
+func (s *runtimeSampler) flush() {
+	if s.current.Goroutines == 0 {
+		return
+	}
+	if cycles := s.uint64(3); cycles > s.cycles {
+		s.current.GCCycles = int(cycles - s.cycles)
+		s.cycles = cycles
+		debug.ReadGCStats(&s.gc)
+		s.current.GCPause = s.gc.PauseTotal - s.paused
+		s.paused = s.gc.PauseTotal
+	}
+	s.samples = append(s.samples, s.current)
+}
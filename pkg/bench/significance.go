@@ -0,0 +1,136 @@
+package bench
+
+import (
+	"math"
+	"math/rand/v2"
+	"slices"
+)
+
+// MannWhitney is the result of a one-sided Mann-Whitney U test of whether
+// values drawn from b tend to be larger than values drawn from a, e.g. the
+// latencies of a candidate run against those of a baseline. The test uses
+// ranks only, so it suits latency distributions that are far from normal.
+type MannWhitney struct {
+	U      float64 // pairs (x from a, y from b) with y > x, ties counting half
+	Z      float64 // U standardized under the null hypothesis, tie and continuity corrected
+	P      float64 // one-sided p-value: chance of a U at least this large if a and b share one distribution
+	Effect float64 // U / (len(a) * len(b)): 0.5 for no shift, 1 when every y exceeds every x
+}
+
+// MannWhitneyU tests whether b is stochastically larger than a, using the
+// normal approximation of U, which is accurate for the sample sizes of a
+// benchmark run. It returns a P of 1 if either sample is empty or all
+// values are equal.
+func MannWhitneyU(a, b []float64) MannWhitney {
+	n1, n2 := float64(len(a)), float64(len(b))
+	if len(a) == 0 || len(b) == 0 {
+		return MannWhitney{P: 1, Effect: 0.5}
+	}
+
+	type value struct {
+		v     float64
+		fromB bool
+	}
+	all := make([]value, 0, len(a)+len(b))
+	for _, v := range a {
+		all = append(all, value{v: v})
+	}
+	for _, v := range b {
+		all = append(all, value{v: v, fromB: true})
+	}
+	slices.SortFunc(all, func(x, y value) int {
+		switch {
+		case x.v < y.v:
+			return -1
+		case x.v > y.v:
+			return 1
+		}
+		return 0
+	})
+
+	// Tied values share the average of their ranks, counted from 1
+	var rankSumB, tieTerm float64
+	for i := 0; i < len(all); {
+		j := i + 1
+		for j < len(all) && all[j].v == all[i].v {
+			j++
+		}
+		rank := float64(i+j+1) / 2
+		for _, v := range all[i:j] {
+			if v.fromB {
+				rankSumB += rank
+			}
+		}
+		t := float64(j - i)
+		tieTerm += t*t*t - t
+		i = j
+	}
+
+	n := n1 + n2
+	u := rankSumB - n2*(n2+1)/2
+	mean := n1 * n2 / 2
+	variance := n1 * n2 / 12 * ((n + 1) - tieTerm/(n*(n-1)))
+	r := MannWhitney{U: u, P: 1, Effect: u / (n1 * n2)}
+	if variance <= 0 {
+		return r
+	}
+	r.Z = (u - mean - 0.5) / math.Sqrt(variance)
+	r.P = 0.5 * math.Erfc(r.Z/math.Sqrt2)
+	return r
+}
+
+// Bootstrap is a percentile bootstrap confidence interval of the difference
+// median(b) - median(a), e.g. between the p99 latencies of two groups of
+// runs. It makes no assumption about the distribution of the values.
+type Bootstrap struct {
+	Diff      float64 // median(b) - median(a) of the observed values
+	Low, High float64 // bounds of the confidence interval of Diff
+}
+
+// Significant reports whether the interval excludes 0, i.e. whether the
+// medians differ at the interval's confidence level.
+func (b Bootstrap) Significant() bool {
+	return b.Low > 0 || b.High < 0
+}
+
+// BootstrapMedianDiff estimates a confidence interval of median(b) -
+// median(a) by resampling each of a and b with replacement resamples times.
+// confidence is the interval's coverage, e.g. 0.95. a and b must not be
+// empty.
+func BootstrapMedianDiff(a, b []float64, resamples int, confidence float64, rng *rand.Rand) Bootstrap {
+	r := Bootstrap{Diff: Median(b) - Median(a)}
+
+	diffs := make([]float64, resamples)
+	ra, rb := make([]float64, len(a)), make([]float64, len(b))
+	for i := range diffs {
+		for j := range ra {
+			ra[j] = a[rng.IntN(len(a))]
+		}
+		for j := range rb {
+			rb[j] = b[rng.IntN(len(b))]
+		}
+		diffs[i] = medianOf(rb) - medianOf(ra)
+	}
+	slices.Sort(diffs)
+
+	tail := (1 - confidence) / 2
+	r.Low = diffs[int(math.Floor(tail*float64(resamples)))]
+	r.High = diffs[max(int(math.Ceil((1-tail)*float64(resamples)))-1, 0)]
+	return r
+}
+
+// Median returns the median of values, averaging the middle two of an even
+// count. values must not be empty.
+func Median(values []float64) float64 {
+	return medianOf(slices.Clone(values))
+}
+
+// medianOf is Median sorting values in place.
+func medianOf(values []float64) float64 {
+	slices.Sort(values)
+	n := len(values)
+	if n%2 == 1 {
+		return values[n/2]
+	}
+	return (values[n/2-1] + values[n/2]) / 2
+}
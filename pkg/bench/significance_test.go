@@ -0,0 +1,101 @@
+package bench
+
+import (
+	"math"
+	"math/rand/v2"
+	"slices"
+	"testing"
+)
+
+func TestMannWhitneyU(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b []float64
+		u, p float64
+	}{
+		{"b entirely larger", []float64{1, 2, 3, 4, 5}, []float64{6, 7, 8, 9, 10}, 25, 0.006093},
+		{"ties", []float64{1, 2, 2, 3, 5}, []float64{2, 3, 3, 4, 6}, 18, 0.142142},
+		{"same values", []float64{3, 1, 2}, []float64{1, 2, 3}, 4.5, 0.590262},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := MannWhitneyU(tt.a, tt.b)
+			if r.U != tt.u || math.Abs(r.P-tt.p) > 1e-6 {
+				t.Errorf("MannWhitneyU() = U %v, p %.6f; want U %v, p %.6f", r.U, r.P, tt.u, tt.p)
+			}
+			if want := tt.u / float64(len(tt.a)*len(tt.b)); r.Effect != want {
+				t.Errorf("Effect = %v, want %v", r.Effect, want)
+			}
+		})
+	}
+}
+
+func TestMannWhitneyU_Degenerate(t *testing.T) {
+	for name, r := range map[string]MannWhitney{
+		"empty":     MannWhitneyU(nil, []float64{1, 2}),
+		"all equal": MannWhitneyU([]float64{5, 5}, []float64{5, 5, 5}),
+		"one pair":  MannWhitneyU([]float64{1}, []float64{1}),
+	} {
+		if r.P != 1 {
+			t.Errorf("%s: P = %v, want 1", name, r.P)
+		}
+	}
+}
+
+func TestMannWhitneyU_Shift(t *testing.T) {
+	rng := rand.New(rand.NewPCG(1, 2))
+	sample := func(n int, shift float64) []float64 {
+		s := make([]float64, n)
+		for i := range s {
+			s[i] = rng.ExpFloat64()*10 + 5 + shift
+		}
+		return s
+	}
+
+	base := sample(5000, 0)
+	if r := MannWhitneyU(base, sample(5000, 0)); r.P < 0.001 {
+		t.Errorf("same distribution: p = %v, want no significant shift", r.P)
+	}
+	if r := MannWhitneyU(base, sample(5000, 1)); r.P > 1e-6 {
+		t.Errorf("shifted by 1ms: p = %v, want a significant shift", r.P)
+	}
+	if r := MannWhitneyU(base, sample(5000, -1)); r.P < 0.5 {
+		t.Errorf("faster candidate: p = %v, want no significant slowdown", r.P)
+	}
+}
+
+func TestBootstrapMedianDiff(t *testing.T) {
+	rng := rand.New(rand.NewPCG(3, 4))
+	runs := func(n int, center float64) []float64 {
+		s := make([]float64, n)
+		for i := range s {
+			s[i] = center + rng.NormFloat64()
+		}
+		return s
+	}
+
+	a := runs(10, 20)
+	aCopy := slices.Clone(a)
+	same := BootstrapMedianDiff(a, runs(10, 20), 5000, 0.95, rng)
+	if same.Significant() {
+		t.Errorf("same center: %+v, want an interval containing 0", same)
+	}
+	if !slices.Equal(a, aCopy) {
+		t.Error("BootstrapMedianDiff() modified its input")
+	}
+
+	slower := BootstrapMedianDiff(a, runs(10, 25), 5000, 0.95, rng)
+	if !slower.Significant() || slower.Low <= 0 || slower.Diff < 3 || slower.Diff > 7 {
+		t.Errorf("5 slower: %+v, want a significant difference of about 5", slower)
+	}
+	if slower.Low > slower.Diff || slower.High < slower.Diff {
+		t.Errorf("interval [%v, %v] excludes the observed difference %v", slower.Low, slower.High, slower.Diff)
+	}
+
+	b := runs(10, 25)
+	wide := BootstrapMedianDiff(a, b, 5000, 0.99, rng)
+	narrow := BootstrapMedianDiff(a, b, 5000, 0.5, rng)
+	if wide.High-wide.Low <= narrow.High-narrow.Low {
+		t.Errorf("99%% interval %+v not wider than 50%% interval %+v", wide, narrow)
+	}
+}
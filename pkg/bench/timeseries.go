@@ -0,0 +1,98 @@
+package bench
+
+import (
+	"time"
+
+	"github.com/HdrHistogram/hdrhistogram-go"
+)
+
+// Per-second histograms use 2 significant digits (1% precision), enough to
+// spot stalls while keeping the histograms of open seconds small.
+const timeseriesSigFigs = 2
+
+// timeseriesWindow is how many seconds behind the newest sample a second
+// stays open. Samples are timestamped when their request starts but arrive
+// when it completes, so a second only receives its last samples once its
+// slowest requests finish. Samples arriving later still count as requests,
+// but not toward the second's percentiles.
+const timeseriesWindow = 5
+
+// TimeseriesPoint aggregates the requests issued in one second of a run.
+type TimeseriesPoint struct {
+	Second   int // seconds since the run started
+	Requests int
+	Errors   int
+	P50      time.Duration // zero if no request in the second succeeded
+	P99      time.Duration
+	Runtime  *RuntimeSample // nil if the client's runtime was not sampled
+}
+
+// timeseries aggregates samples into per-second points as they arrive, so
+// memory stays bounded by the open window rather than the run length.
+type timeseries struct {
+	start  time.Time
+	points []TimeseriesPoint               // indexed by second
+	open   map[int]*hdrhistogram.Histogram // latencies of seconds still open
+	newest int
+	spare  []*hdrhistogram.Histogram // reset histograms for reuse
+}
+
+func newTimeseries(start time.Time) *timeseries {
+	return &timeseries{start: start, open: make(map[int]*hdrhistogram.Histogram)}
+}
+
+// add records one sample in the second it was issued.
+func (ts *timeseries) add(s Sample) {
+	sec := max(int(s.Timestamp.Sub(ts.start)/time.Second), 0)
+	for len(ts.points) <= sec {
+		ts.points = append(ts.points, TimeseriesPoint{Second: len(ts.points)})
+	}
+
+	p := &ts.points[sec]
+	p.Requests++
+	if !s.Success {
+		p.Errors++
+	} else if s.Latency > 0 && sec > ts.newest-timeseriesWindow {
+		h, ok := ts.open[sec]
+		if !ok {
+			h = ts.histogram()
+			ts.open[sec] = h
+		}
+		h.RecordValue(clampMicros(s.Latency))
+	}
+
+	if sec > ts.newest {
+		ts.newest = sec
+		ts.close(ts.newest - timeseriesWindow)
+	}
+}
+
+// close computes the percentiles of every open second up to and including
+// last and releases their histograms.
+func (ts *timeseries) close(last int) {
+	for sec, h := range ts.open {
+		if sec > last {
+			continue
+		}
+		ts.points[sec].P50 = time.Duration(h.ValueAtQuantile(50)) * time.Microsecond
+		ts.points[sec].P99 = time.Duration(h.ValueAtQuantile(99)) * time.Microsecond
+		h.Reset()
+		ts.spare = append(ts.spare, h)
+		delete(ts.open, sec)
+	}
+}
+
+func (ts *timeseries) histogram() *hdrhistogram.Histogram {
+	if n := len(ts.spare); n > 0 {
+		h := ts.spare[n-1]
+		ts.spare = ts.spare[:n-1]
+		return h
+	}
+	return hdrhistogram.New(histogramMinMicros, histogramMaxMicros, timeseriesSigFigs)
+}
+
+// finish closes every open second and returns all points.
+func (ts *timeseries) finish() []TimeseriesPoint {
+	ts.close(ts.newest)
+	return ts.points
+}
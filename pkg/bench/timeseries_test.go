@@ -0,0 +1,48 @@
+package bench
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTimeseries(t *testing.T) {
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	ts := newTimeseries(start)
+
+	at := func(sec float64) time.Time {
+		return start.Add(time.Duration(sec * float64(time.Second)))
+	}
+	for i := 1; i <= 100; i++ {
+		ts.add(Sample{Timestamp: at(0.5), Latency: time.Duration(i) * time.Millisecond, Success: true})
+	}
+	ts.add(Sample{Timestamp: at(2.1), Success: false})
+	// Samples more than the window behind the newest still count as requests
+	// but their second's percentiles are already computed.
+	ts.add(Sample{Timestamp: at(timeseriesWindow + 1), Latency: time.Millisecond, Success: true})
+	ts.add(Sample{Timestamp: at(0.9), Latency: time.Second, Success: true})
+
+	points := ts.finish()
+	if len(points) != timeseriesWindow+2 {
+		t.Fatalf("got %d points, want %d", len(points), timeseriesWindow+2)
+	}
+
+	if points[0].Requests != 101 || points[0].Errors != 0 {
+		t.Errorf("second 0 = %+v, want 101 requests", points[0])
+	}
+	if p50 := points[0].P50; p50 < 49*time.Millisecond || p50 > 51*time.Millisecond {
+		t.Errorf("second 0 p50 = %v, want ~50ms", p50)
+	}
+	if p99 := points[0].P99; p99 < 98*time.Millisecond || p99 > 100*time.Millisecond {
+		t.Errorf("second 0 p99 = %v, want ~99ms (late sample excluded)", p99)
+	}
+
+	if points[1].Requests != 0 || points[1].P50 != 0 {
+		t.Errorf("second 1 = %+v, want empty", points[1])
+	}
+	if points[2].Requests != 1 || points[2].Errors != 1 || points[2].P50 != 0 {
+		t.Errorf("second 2 = %+v, want one error", points[2])
+	}
+	if last := points[len(points)-1]; last.Requests != 1 || last.P50 == 0 {
+		t.Errorf("last second = %+v, want one success", last)
+	}
+}
@@ -0,0 +1,162 @@
+package bench
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/stats"
+)
+
+// Transfer counts the bytes a client moved. Wire bytes are read from and
+// written to its TCP connections, with the HTTP/1.1 or HTTP/2 framing,
+// headers and TLS records; payload bytes are the messages or bodies in
+// them, as encoded and compressed, with the length prefix of gRPC and
+// gRPC-Web messages. Unlike interface counters they only cover this
+// client's connections.
+type Transfer struct {
+	WireSent    uint64
+	WireRecv    uint64
+	PayloadSent uint64
+	PayloadRecv uint64
+}
+
+// Sub returns the bytes moved since the client counted before.
+func (t Transfer) Sub(before Transfer) Transfer {
+	return Transfer{
+		WireSent:    t.WireSent - before.WireSent,
+		WireRecv:    t.WireRecv - before.WireRecv,
+		PayloadSent: t.PayloadSent - before.PayloadSent,
+		PayloadRecv: t.PayloadRecv - before.PayloadRecv,
+	}
+}
+
+// TransferClient is implemented by clients that count the bytes they
+// transfer, used to report the wire and payload bytes of a run.
+type TransferClient interface {
+	Transfer() Transfer
+}
+
+// byteCounter accumulates a client's Transfer.
+type byteCounter struct {
+	wireSent, wireRecv       atomic.Uint64
+	payloadSent, payloadRecv atomic.Uint64
+}
+
+func (c *byteCounter) Transfer() Transfer {
+	return Transfer{
+		WireSent:    c.wireSent.Load(),
+		WireRecv:    c.wireRecv.Load(),
+		PayloadSent: c.payloadSent.Load(),
+		PayloadRecv: c.payloadRecv.Load(),
+	}
+}
+
+// dialer returns a DialContext counting the bytes of every connection it
+// opens, with the timeouts of http.DefaultTransport.
+func (c *byteCounter) dialer() func(ctx context.Context, network, addr string) (net.Conn, error) {
+	d := &net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second}
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		conn, err := d.DialContext(ctx, network, addr)
+		if err != nil {
+			return nil, err
+		}
+		return &countingConn{Conn: conn, c: c}, nil
+	}
+}
+
+// countingConn counts the bytes read from and written to a connection.
+type countingConn struct {
+	net.Conn
+	c *byteCounter
+}
+
+func (cc *countingConn) Read(p []byte) (int, error) {
+	n, err := cc.Conn.Read(p)
+	cc.c.wireRecv.Add(uint64(n))
+	return n, err
+}
+
+func (cc *countingConn) Write(p []byte) (int, error) {
+	n, err := cc.Conn.Write(p)
+	cc.c.wireSent.Add(uint64(n))
+	return n, err
+}
+
+// payloadTransport counts the request and response body bytes of HTTP
+// requests, as they are sent and received.
+type payloadTransport struct {
+	next http.RoundTripper
+	c    *byteCounter
+}
+
+// countingPayloads returns rt counting body bytes in c.
+func countingPayloads(rt http.RoundTripper, c *byteCounter) http.RoundTripper {
+	return &payloadTransport{next: rt, c: c}
+}
+
+func (t *payloadTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Body != nil && req.Body != http.NoBody {
+		req = req.Clone(req.Context())
+		req.Body = &countingBody{ReadCloser: req.Body, n: &t.c.payloadSent}
+	}
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = &countingBody{ReadCloser: resp.Body, n: &t.c.payloadRecv}
+	return resp, nil
+}
+
+// countingBody counts the bytes read from a body.
+type countingBody struct {
+	io.ReadCloser
+	n *atomic.Uint64
+}
+
+func (b *countingBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.n.Add(uint64(n))
+	return n, err
+}
+
+// grpcTransfer returns the dial options counting the wire bytes of a gRPC
+// client's connections and the payload bytes of its messages in c.
+func grpcTransfer(c *byteCounter) []grpc.DialOption {
+	dial := c.dialer()
+	return []grpc.DialOption{
+		grpc.WithContextDialer(func(ctx context.Context, addr string) (net.Conn, error) {
+			return dial(ctx, "tcp", addr)
+		}),
+		grpc.WithStatsHandler(&payloadStats{c: c}),
+	}
+}
+
+// payloadStats is a gRPC stats.Handler counting message bytes on the wire,
+// compressed and with their length prefix.
+type payloadStats struct {
+	c *byteCounter
+}
+
+func (h *payloadStats) TagRPC(ctx context.Context, _ *stats.RPCTagInfo) context.Context {
+	return ctx
+}
+
+func (h *payloadStats) HandleRPC(_ context.Context, s stats.RPCStats) {
+	switch s := s.(type) {
+	case *stats.OutPayload:
+		h.c.payloadSent.Add(uint64(s.WireLength))
+	case *stats.InPayload:
+		h.c.payloadRecv.Add(uint64(s.WireLength))
+	}
+}
+
+func (h *payloadStats) TagConn(ctx context.Context, _ *stats.ConnTagInfo) context.Context {
+	return ctx
+}
+
+func (h *payloadStats) HandleConn(context.Context, stats.ConnStats) {}
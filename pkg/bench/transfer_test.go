@@ -0,0 +1,64 @@
+package bench
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/kaldun-tech/grpc-rest-benchmark/pkg/db"
+	"github.com/kaldun-tech/grpc-rest-benchmark/pkg/jsoncodec"
+	"github.com/kaldun-tech/grpc-rest-benchmark/pkg/restapi"
+)
+
+func TestHTTPClient_Transfer(t *testing.T) {
+	body := `{"account_id":"0.0.1","balance":1}`
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(body))
+	}))
+	defer srv.Close()
+
+	client, err := NewHTTPClient(srv.URL, "json", "idiomatic", restapi.StreamSSE, jsoncodec.Std, "none", ConnOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+	counter, ok := client.(TransferClient)
+	if !ok {
+		t.Fatal("HTTP client does not count transferred bytes")
+	}
+
+	before := counter.Transfer()
+	for range 2 {
+		if err := client.GetBalance(context.Background(), "0.0.1"); err != nil {
+			t.Fatalf("GetBalance() error = %v", err)
+		}
+	}
+	got := counter.Transfer().Sub(before)
+
+	if want := uint64(2 * len(body)); got.PayloadRecv != want {
+		t.Errorf("PayloadRecv = %d, want %d", got.PayloadRecv, want)
+	}
+	if got.PayloadSent != 0 {
+		t.Errorf("PayloadSent = %d, want 0 for GET requests", got.PayloadSent)
+	}
+	// The wire carries the request lines, headers and status lines as well
+	if got.WireSent == 0 || got.WireRecv <= got.PayloadRecv {
+		t.Errorf("wire bytes = %d sent, %d received, want headers on top of %d payload bytes",
+			got.WireSent, got.WireRecv, got.PayloadRecv)
+	}
+}
+
+func TestResults_Transfer(t *testing.T) {
+	r := NewResults()
+	r.SetTransfer(Transfer{WireSent: 100, WireRecv: 200, PayloadSent: 10, PayloadRecv: 20})
+
+	run := &db.BenchmarkRun{}
+	r.fillRun(run)
+	if run.WireBytesSent == nil || *run.WireBytesSent != 100 || *run.WireBytesRecv != 200 ||
+		*run.PayloadBytesSent != 10 || *run.PayloadBytesRecv != 20 {
+		t.Errorf("run transfer = %v/%v/%v/%v, want 100/200/10/20",
+			run.WireBytesSent, run.WireBytesRecv, run.PayloadBytesSent, run.PayloadBytesRecv)
+	}
+}
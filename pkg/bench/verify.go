@@ -0,0 +1,120 @@
+package bench
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Invariants checked after a run, each only when its data is available.
+const (
+	CheckPool           = "pool"            // no server connection acquire was given up
+	CheckServerLog      = "server_log"      // no panic or fatal error in the server logs
+	CheckStreamDelivery = "stream_delivery" // streams the server ended delivered everything sent
+	CheckStreamRows     = "stream_rows"     // and sent every row of the transactions table
+	CheckWriteRows      = "write_rows"      // acknowledged writes are in the database, once
+)
+
+// ServerFacts are what a run is verified against besides its results,
+// gathered from the servers and the database. Each is nil when unavailable.
+type ServerFacts struct {
+	LogFatal        *int64 // panics and fatal errors in the server logs during the run
+	TransactionRows *int64 // rows in the transactions table when the run started
+	SubmittedRows   *int64 // rows the run's writes added to the transactions table
+}
+
+// Check is the outcome of one invariant.
+type Check struct {
+	Name   string
+	Passed bool
+	Detail string // what was found, e.g. "3 connection acquires given up"
+}
+
+func (c Check) String() string {
+	outcome := "ok"
+	if !c.Passed {
+		outcome = "FAILED"
+	}
+	return fmt.Sprintf("%s %s: %s", c.Name, outcome, c.Detail)
+}
+
+// Verification is the outcome of the invariants checked after a run, which
+// give confidence that its numbers were not produced by a degraded server.
+type Verification struct {
+	Checks []Check
+}
+
+// Checked reports whether any invariant could be checked.
+func (v Verification) Checked() bool {
+	return len(v.Checks) > 0
+}
+
+// Verified reports whether invariants were checked and all of them held.
+func (v Verification) Verified() bool {
+	for _, c := range v.Checks {
+		if !c.Passed {
+			return false
+		}
+	}
+	return v.Checked()
+}
+
+// String lists the checks, e.g. "pool ok: ...; stream_rows FAILED: ...".
+func (v Verification) String() string {
+	checks := make([]string, len(v.Checks))
+	for i, c := range v.Checks {
+		checks[i] = c.String()
+	}
+	return strings.Join(checks, "; ")
+}
+
+// PrintVerification prints the outcome of a run's invariant checks.
+func PrintVerification(w io.Writer, v Verification) {
+	if !v.Checked() {
+		return
+	}
+	status := "verified"
+	if !v.Verified() {
+		status = "NOT verified"
+	}
+	fmt.Fprintf(w, "\nInvariants: run %s\n", status)
+	for _, c := range v.Checks {
+		fmt.Fprintf(w, "  %s\n", c)
+	}
+}
+
+// Verify checks the invariants whose data the results and facts hold.
+func (r *Results) Verify(f ServerFacts) Verification {
+	var v Verification
+	add := func(name string, passed bool, format string, args ...any) {
+		v.Checks = append(v.Checks, Check{Name: name, Passed: passed, Detail: fmt.Sprintf(format, args...)})
+	}
+
+	if r.poolFailures != nil {
+		add(CheckPool, *r.poolFailures == 0, "%d connection acquires given up, %d waited for a free connection",
+			*r.poolFailures, *r.poolWaits)
+	}
+	if f.LogFatal != nil {
+		add(CheckServerLog, *f.LogFatal == 0, "%d panics or fatal errors logged", *f.LogFatal)
+	}
+	if r.streamEnds > 0 {
+		add(CheckStreamDelivery, r.shortfall == 0, "%d transactions sent by %d completed streams not received",
+			r.shortfall, r.streamEnds)
+		if f.TransactionRows != nil {
+			fewest, most := r.streamSent[0], r.streamSent[1]
+			sent := fmt.Sprint(fewest)
+			if most != fewest {
+				sent = fmt.Sprintf("%d to %d", fewest, most)
+			}
+			add(CheckStreamRows, fewest == *f.TransactionRows && most == *f.TransactionRows,
+				"completed streams sent %s transactions, the database held %d", sent, *f.TransactionRows)
+		}
+	}
+	if writes := r.classes["write"]; writes != nil && f.SubmittedRows != nil {
+		// A failed write may still have been committed, e.g. after a timeout
+		rows := *f.SubmittedRows
+		add(CheckWriteRows, rows >= int64(writes.successful) && rows <= int64(writes.total),
+			"%d rows added for %d acknowledged of %d attempted writes", rows, writes.successful, writes.total)
+	}
+	return v
+}
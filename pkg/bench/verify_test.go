@@ -0,0 +1,62 @@
+package bench
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestResults_Verify(t *testing.T) {
+	ptr := func(n int64) *int64 { return &n }
+
+	r := NewResults()
+	if v := r.Verify(ServerFacts{}); v.Checked() || v.Verified() {
+		t.Errorf("Verify() without data = %+v, want nothing checked and not verified", v)
+	}
+
+	r.SetServerPool(12, 0)
+	r.SetStreams(4)
+	r.SetStreamEnds(4, 0)
+	r.SetStreamSent(1000, 1000)
+	v := r.Verify(ServerFacts{LogFatal: ptr(0), TransactionRows: ptr(1000)})
+	if !v.Verified() || len(v.Checks) != 4 {
+		t.Errorf("Verify() of a healthy run = %s, want 4 checks passed", v)
+	}
+
+	r.SetServerPool(12, 3)
+	r.SetStreamEnds(4, 7)
+	r.SetStreamSent(990, 1000)
+	v = r.Verify(ServerFacts{LogFatal: ptr(1), TransactionRows: ptr(1000)})
+	if v.Verified() {
+		t.Errorf("Verify() of a degraded run = %s, want not verified", v)
+	}
+	for _, c := range v.Checks {
+		if c.Passed {
+			t.Errorf("check %s passed, want failed", c)
+		}
+	}
+	if s := v.String(); !strings.Contains(s, "pool FAILED: 3 connection acquires given up") ||
+		!strings.Contains(s, "completed streams sent 990 to 1000 transactions, the database held 1000") {
+		t.Errorf("String() = %q", s)
+	}
+}
+
+func TestResults_VerifyWrites(t *testing.T) {
+	r := NewResults()
+	for i := 0; i < 10; i++ {
+		s := Sample{Latency: time.Millisecond, Success: i < 8, Class: "write", Timestamp: time.Now()}
+		if !s.Success {
+			s.Error = errors.New("timeout")
+		}
+		r.Add(s)
+	}
+
+	// Failed writes may or may not have been committed
+	for rows, want := range map[int64]bool{7: false, 8: true, 10: true, 11: false} {
+		v := r.Verify(ServerFacts{SubmittedRows: &rows})
+		if len(v.Checks) != 1 || v.Checks[0].Name != CheckWriteRows || v.Verified() != want {
+			t.Errorf("Verify() with %d rows added = %s, want verified %v", rows, v, want)
+		}
+	}
+}
@@ -0,0 +1,138 @@
+package bench
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Steady-state detection: a second of the run is steady when its requests
+// are within steadyRequestsTolerance of the median second's and its p50
+// latency within steadyLatencyTolerance above the median p50. The steady
+// window runs from the first steady second to the last, and must cover at
+// least minSteadySeconds and half of the run.
+const (
+	steadyRequestsTolerance = 0.1
+	steadyLatencyTolerance  = 0.25
+	minSteadySeconds        = 3
+)
+
+// MeasureWindow selects the part of a run whose requests feed its primary
+// statistics, leaving out the ramp up at the start and the drain at the
+// end. Requests are attributed by when they were issued. Statistics of the
+// whole run are kept alongside.
+type MeasureWindow struct {
+	Auto bool          // detect the steady state once the run ends
+	From time.Duration // offset from the start of the run
+	To   time.Duration // offset from the start, 0 for the end of the run
+}
+
+// ParseMeasureWindow parses a measure window spec:
+//
+//	10s..50s   requests issued from 10s to 50s into the run
+//	10s..      from 10s to the end of the run
+//	auto       the steady state, detected from the per-second throughput
+//	           and latency of the run
+func ParseMeasureWindow(spec string) (*MeasureWindow, error) {
+	if spec == "auto" {
+		return &MeasureWindow{Auto: true}, nil
+	}
+	from, to, ok := strings.Cut(spec, "..")
+	if !ok {
+		return nil, fmt.Errorf("invalid measure window %q: want FROM..TO, FROM.. or auto", spec)
+	}
+	var w MeasureWindow
+	var err error
+	if w.From, err = time.ParseDuration(from); err != nil {
+		return nil, fmt.Errorf("invalid measure window %q: %w", spec, err)
+	}
+	if to != "" {
+		if w.To, err = time.ParseDuration(to); err != nil {
+			return nil, fmt.Errorf("invalid measure window %q: %w", spec, err)
+		}
+	}
+	if w.From < 0 || (to != "" && w.To <= w.From) {
+		return nil, fmt.Errorf("invalid measure window %q: must start at or after 0 and end after it starts", spec)
+	}
+	return &w, nil
+}
+
+// String returns the spec of the window.
+func (w *MeasureWindow) String() string {
+	if w.Auto {
+		return "auto"
+	}
+	return formatWindow(w.From, w.To)
+}
+
+// formatWindow formats the window from..to, to 0 meaning the end of the run.
+func formatWindow(from, to time.Duration) string {
+	if to == 0 {
+		return from.String() + ".."
+	}
+	return from.String() + ".." + to.String()
+}
+
+// validate checks the window against a run of the given duration.
+func (w *MeasureWindow) validate(duration time.Duration) error {
+	if w.Auto {
+		return nil
+	}
+	if w.From >= duration || w.To > duration {
+		return fmt.Errorf("measure window %s does not fit in the %s run", w, duration)
+	}
+	return nil
+}
+
+// steadyState returns the seconds [from, to) of points that are steady,
+// considering the first seconds whole seconds of the run. ok is false if
+// no steady stretch long enough is found.
+func steadyState(points []TimeseriesPoint, seconds int) (from, to int, ok bool) {
+	points = points[:min(len(points), seconds)]
+	if len(points) < minSteadySeconds {
+		return 0, 0, false
+	}
+
+	requests := make([]int, len(points))
+	var p50s []time.Duration
+	for i, p := range points {
+		requests[i] = p.Requests
+		if p.P50 > 0 {
+			p50s = append(p50s, p.P50)
+		}
+	}
+	medianRequests := float64(median(requests))
+	var medianP50 time.Duration
+	if len(p50s) > 0 {
+		medianP50 = median(p50s)
+	}
+
+	steady := func(p TimeseriesPoint) bool {
+		if math.Abs(float64(p.Requests)-medianRequests) > steadyRequestsTolerance*medianRequests {
+			return false
+		}
+		return medianP50 == 0 || p.P50 == 0 ||
+			float64(p.P50) <= (1+steadyLatencyTolerance)*float64(medianP50)
+	}
+	from = slices.IndexFunc(points, steady)
+	if from < 0 {
+		return 0, 0, false
+	}
+	to = len(points)
+	for !steady(points[to-1]) {
+		to--
+	}
+	if n := to - from; n < minSteadySeconds || 2*n < len(points) {
+		return 0, 0, false
+	}
+	return from, to, true
+}
+
+// median returns the median of values, the upper one of an even count.
+func median[T int | time.Duration](values []T) T {
+	sorted := slices.Clone(values)
+	slices.Sort(sorted)
+	return sorted[len(sorted)/2]
+}
@@ -0,0 +1,140 @@
+package bench
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseMeasureWindow(t *testing.T) {
+	tests := []struct {
+		spec string
+		want MeasureWindow
+	}{
+		{"10s..50s", MeasureWindow{From: 10 * time.Second, To: 50 * time.Second}},
+		{"5s..", MeasureWindow{From: 5 * time.Second}},
+		{"auto", MeasureWindow{Auto: true}},
+	}
+	for _, tt := range tests {
+		w, err := ParseMeasureWindow(tt.spec)
+		if err != nil {
+			t.Fatalf("ParseMeasureWindow(%q) error = %v", tt.spec, err)
+		}
+		if *w != tt.want {
+			t.Errorf("ParseMeasureWindow(%q) = %+v, want %+v", tt.spec, *w, tt.want)
+		}
+		if w.String() != tt.spec {
+			t.Errorf("String() = %q, want %q", w.String(), tt.spec)
+		}
+	}
+
+	for _, spec := range []string{"10s", "10s..5s", "-1s..5s", "a..b", "10s..10s"} {
+		if _, err := ParseMeasureWindow(spec); err == nil {
+			t.Errorf("ParseMeasureWindow(%q) succeeded", spec)
+		}
+	}
+}
+
+// windowSamples adds perSecond requests issued in each second of a run
+// starting at start, with latency(sec).
+func windowSamples(r *Results, start time.Time, seconds int, perSecond func(sec int) int, latency func(sec int) time.Duration) {
+	for sec := range seconds {
+		n := perSecond(sec)
+		for i := range n {
+			r.Add(Sample{
+				Timestamp: start.Add(time.Duration(sec)*time.Second + time.Duration(i)*time.Second/time.Duration(n)),
+				Latency:   latency(sec),
+				Success:   true,
+			})
+		}
+	}
+}
+
+func TestResults_MeasureWindow(t *testing.T) {
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	r := NewResults()
+	r.SetStartTime(start)
+	r.SetMeasureWindow(&MeasureWindow{From: 2 * time.Second, To: 8 * time.Second})
+	// Slow first and last two seconds
+	slow := func(sec int) time.Duration {
+		if sec < 2 || sec >= 8 {
+			return 100 * time.Millisecond
+		}
+		return time.Millisecond
+	}
+	windowSamples(r, start, 10, func(int) int { return 100 }, slow)
+	r.SetEndTime(start.Add(10 * time.Second))
+
+	if r.TotalRequests() != 600 || r.FullRequests() != 1000 {
+		t.Errorf("requests = %d of %d, want 600 of 1000", r.TotalRequests(), r.FullRequests())
+	}
+	if got := r.Throughput(); got != 100 {
+		t.Errorf("Throughput() = %v, want 100 over the 6s window", got)
+	}
+	if p99 := r.Percentile(99); p99 > 2*time.Millisecond {
+		t.Errorf("Percentile(99) = %v, want the window's 1ms", p99)
+	}
+	if p99 := r.FullPercentile(99); p99 < 99*time.Millisecond {
+		t.Errorf("FullPercentile(99) = %v, want the slow seconds' 100ms", p99)
+	}
+	if from, to, ok := r.MeasuredWindow(); !ok || from != 2*time.Second || to != 8*time.Second {
+		t.Errorf("MeasuredWindow() = %v, %v, %v; want 2s..8s", from, to, ok)
+	}
+}
+
+func TestResults_DetectSteadyState(t *testing.T) {
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	r := NewResults()
+	r.SetStartTime(start)
+	r.SetMeasureWindow(&MeasureWindow{Auto: true})
+	// Throughput ramps up over 2 seconds, latency spikes in the last one
+	perSecond := func(sec int) int {
+		return min(50*(sec+1), 150)
+	}
+	latency := func(sec int) time.Duration {
+		if sec == 9 {
+			return 50 * time.Millisecond
+		}
+		return time.Millisecond
+	}
+	windowSamples(r, start, 10, perSecond, latency)
+	r.SetEndTime(start.Add(10 * time.Second))
+
+	if err := r.detectSteadyState(); err != nil {
+		t.Fatalf("detectSteadyState() error = %v", err)
+	}
+	if from, to, _ := r.MeasuredWindow(); from != 2*time.Second || to != 9*time.Second {
+		t.Errorf("MeasuredWindow() = %v..%v, want 2s..9s", from, to)
+	}
+	if r.TotalRequests() != 7*150 || r.MaxLatency() > 2*time.Millisecond {
+		t.Errorf("measured %d requests up to %v, want the 1050 steady ones", r.TotalRequests(), r.MaxLatency())
+	}
+
+	// Without every sample the window cannot be measured again
+	r = NewResults()
+	r.SetStartTime(start)
+	r.SetMaxStoredSamples(10)
+	r.SetMeasureWindow(&MeasureWindow{Auto: true})
+	windowSamples(r, start, 10, perSecond, latency)
+	r.SetEndTime(start.Add(10 * time.Second))
+	if err := r.detectSteadyState(); err == nil {
+		t.Error("detectSteadyState() from a sample succeeded")
+	}
+	if _, _, ok := r.MeasuredWindow(); ok || r.TotalRequests() != r.FullRequests() {
+		t.Error("stats do not cover the whole run after detection failed")
+	}
+}
+
+func TestSteadyState_None(t *testing.T) {
+	// Throughput that keeps climbing has no steady stretch covering half
+	// the run
+	var points []TimeseriesPoint
+	for sec := range 10 {
+		points = append(points, TimeseriesPoint{Second: sec, Requests: 1 << sec})
+	}
+	if from, to, ok := steadyState(points, 10); ok {
+		t.Errorf("steadyState() = %d..%d, want none", from, to)
+	}
+	if _, _, ok := steadyState(points[:2], 2); ok {
+		t.Error("steadyState() of 2 seconds found one")
+	}
+}
@@ -0,0 +1,145 @@
+package bench
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"sync/atomic"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/kaldun-tech/grpc-rest-benchmark/pkg/protos"
+)
+
+const (
+	// workerBatchSize and workerFlushInterval bound how many samples a
+	// worker holds before sending them to the coordinator, and for how long.
+	workerBatchSize     = 500
+	workerFlushInterval = 100 * time.Millisecond
+)
+
+// Worker runs shares of distributed benchmarks for a coordinator, which
+// starts them with Config.Workers. Register it on a gRPC server with
+// protos.RegisterWorkerServiceServer. A worker runs one share at a time.
+type Worker struct {
+	protos.UnimplementedWorkerServiceServer
+
+	logger    *slog.Logger
+	newClient func(Config) (BenchmarkClient, error)
+	busy      atomic.Bool
+}
+
+// NewWorker creates a worker that logs the runs it takes to logger.
+func NewWorker(logger *slog.Logger) *Worker {
+	return &Worker{logger: logger, newClient: NewClient}
+}
+
+// Run runs the share of a benchmark in req from its start time and streams
+// the samples back until the share is over or the coordinator goes away.
+func (w *Worker) Run(req *protos.WorkerRunRequest, stream grpc.ServerStreamingServer[protos.WorkerSamples]) error {
+	var cfg Config
+	if err := json.Unmarshal(req.GetConfig(), &cfg); err != nil {
+		return status.Errorf(codes.InvalidArgument, "invalid run config: %v", err)
+	}
+	if err := cfg.validate(); err != nil {
+		return status.Errorf(codes.InvalidArgument, "invalid run config: %v", err)
+	}
+	if !w.busy.CompareAndSwap(false, true) {
+		return status.Error(codes.ResourceExhausted, "worker is already running a benchmark")
+	}
+	defer w.busy.Store(false)
+
+	client, err := w.newClient(cfg)
+	if err != nil {
+		return status.Errorf(codes.FailedPrecondition, "failed to create client: %v", err)
+	}
+	defer client.Close()
+	runner, err := cfg.newRunner(client)
+	if err != nil {
+		return status.Errorf(codes.InvalidArgument, "%v", err)
+	}
+
+	ctx := stream.Context()
+	startAt := time.Unix(0, req.GetStartUnixNano())
+	w.logger.Info("running benchmark share",
+		"scenario", cfg.Scenario, "protocol", cfg.Protocol, "server", cfg.Addr,
+		"concurrency", cfg.Concurrency, "rate", cfg.Rate, "start", startAt)
+
+	// The scheduled start is the barrier every worker connects to the
+	// server by; a worker that cannot fails the run rather than start late
+	connectCtx, cancel := context.WithDeadline(ctx, startAt)
+	err = preconnect(connectCtx, client, &cfg)
+	late := connectCtx.Err() != nil && ctx.Err() == nil
+	cancel()
+	switch {
+	case late:
+		return status.Errorf(codes.DeadlineExceeded, "not connected to %s by the scheduled start: %v", cfg.Addr, err)
+	case err != nil:
+		return status.Errorf(codes.Unavailable, "failed to connect to %s: %v", cfg.Addr, err)
+	}
+
+	select {
+	case <-time.After(time.Until(startAt)):
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	runCtx, cancel := context.WithTimeout(ctx, cfg.RunDuration())
+	defer cancel()
+	go runScenario(runCtx, runner, cfg.Scenario)
+
+	sent, err := sendSamples(stream, runner.Results())
+	if err != nil {
+		// Stop the load and let the runner finish
+		cancel()
+		for range runner.Results() {
+		}
+		w.logger.Warn("benchmark share stopped", "samples", sent, "error", err)
+		return err
+	}
+	w.logger.Info("benchmark share finished", "samples", sent)
+	return nil
+}
+
+// sendSamples streams samples in batches until the channel is closed, and
+// returns how many it sent.
+func sendSamples(stream grpc.ServerStreamingServer[protos.WorkerSamples], samples <-chan Sample) (int, error) {
+	ticker := time.NewTicker(workerFlushInterval)
+	defer ticker.Stop()
+
+	sent := 0
+	batch := &protos.WorkerSamples{}
+	flush := func() error {
+		if len(batch.Samples) == 0 {
+			return nil
+		}
+		if err := stream.Send(batch); err != nil {
+			return err
+		}
+		sent += len(batch.Samples)
+		batch = &protos.WorkerSamples{}
+		return nil
+	}
+
+	for {
+		select {
+		case s, ok := <-samples:
+			if !ok {
+				return sent, flush()
+			}
+			batch.Samples = append(batch.Samples, sampleToProto(s))
+			if len(batch.Samples) >= workerBatchSize {
+				if err := flush(); err != nil {
+					return sent, err
+				}
+			}
+		case <-ticker.C:
+			if err := flush(); err != nil {
+				return sent, err
+			}
+		}
+	}
+}
@@ -0,0 +1,59 @@
+// Package buildinfo describes the running binary and its host, so stored
+// runs can be told apart by code version and machine.
+package buildinfo
+
+import (
+	"os"
+	"runtime"
+	"runtime/debug"
+	"strings"
+)
+
+// commit overrides the VCS revision, for binaries built without VCS
+// stamping: -ldflags "-X github.com/kaldun-tech/grpc-rest-benchmark/pkg/buildinfo.commit=SHA".
+var commit string
+
+// Commit returns the git commit the binary was built from, with a "-dirty"
+// suffix for uncommitted changes, or "" if unknown, e.g. under go run.
+func Commit() string {
+	if commit != "" {
+		return commit
+	}
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return ""
+	}
+	var revision string
+	var modified bool
+	for _, s := range info.Settings {
+		switch s.Key {
+		case "vcs.revision":
+			revision = s.Value
+		case "vcs.modified":
+			modified = s.Value == "true"
+		}
+	}
+	if revision != "" && modified {
+		revision += "-dirty"
+	}
+	return revision
+}
+
+// Kernel returns the operating system and, on Linux, the kernel release,
+// e.g. "linux 6.8.0-45-generic".
+func Kernel() string {
+	release, err := os.ReadFile("/proc/sys/kernel/osrelease")
+	if err != nil {
+		return runtime.GOOS
+	}
+	return runtime.GOOS + " " + strings.TrimSpace(string(release))
+}
+
+// Hostname returns the host's name, or "" if unknown.
+func Hostname() string {
+	host, err := os.Hostname()
+	if err != nil {
+		return ""
+	}
+	return host
+}
@@ -0,0 +1,21 @@
+package buildinfo
+
+import (
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestCommit_Override(t *testing.T) {
+	defer func(c string) { commit = c }(commit)
+	commit = "0123abc"
+	if got := Commit(); got != "0123abc" {
+		t.Errorf("Commit() = %q, want the -X override", got)
+	}
+}
+
+func TestKernel(t *testing.T) {
+	if got := Kernel(); !strings.HasPrefix(got, runtime.GOOS) {
+		t.Errorf("Kernel() = %q, want it to start with %s", got, runtime.GOOS)
+	}
+}
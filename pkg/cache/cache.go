@@ -0,0 +1,207 @@
+// Package cache serves GetBalance from an in-memory LRU cache with a TTL,
+// to separate the protocol cost of a balance request from the database
+// cost of answering it. Both servers wrap their dataset with it when
+// started with --cache-size, and report the hits and misses in their server
+// stats so every run can store its hit rate.
+package cache
+
+import (
+	"container/list"
+	"context"
+	"flag"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/kaldun-tech/grpc-rest-benchmark/pkg/db"
+	"github.com/kaldun-tech/grpc-rest-benchmark/pkg/qos"
+)
+
+// Config holds the cache flags.
+type Config struct {
+	Size int           // maximum cached balances, 0 disables the cache
+	TTL  time.Duration // how long a cached balance is served, 0 until it is evicted
+}
+
+// RegisterFlags registers the cache flags on fs.
+func (c *Config) RegisterFlags(fs *flag.FlagSet) {
+	fs.IntVar(&c.Size, "cache-size", 0, "Cache up to this many GetBalance results, least recently used evicted first (0 = no cache)")
+	fs.DurationVar(&c.TTL, "cache-ttl", time.Second, "How long a cached balance is served before it is read again (0 = until evicted)")
+}
+
+// Validate checks the cache flags for invalid values.
+func (c Config) Validate() error {
+	if c.Size < 0 {
+		return fmt.Errorf("cache-size must not be negative")
+	}
+	if c.TTL < 0 {
+		return fmt.Errorf("cache-ttl must not be negative")
+	}
+	return nil
+}
+
+// Enabled reports whether balances are cached.
+func (c Config) Enabled() bool {
+	return c.Size > 0
+}
+
+// String describes the cache, as recorded with each run, e.g.
+// "size=10000,ttl=1s". It is empty when balances are not cached.
+func (c Config) String() string {
+	if !c.Enabled() {
+		return ""
+	}
+	if c.TTL == 0 {
+		return fmt.Sprintf("size=%d", c.Size)
+	}
+	return fmt.Sprintf("size=%d,ttl=%s", c.Size, c.TTL)
+}
+
+// Stats counts cache lookups since the cache was created.
+type Stats struct {
+	Hits   int64
+	Misses int64
+}
+
+// LRU is a fixed-size cache evicting the least recently used entry first.
+// Entries older than the TTL are treated as missing. It is safe for
+// concurrent use.
+type LRU[K comparable, V any] struct {
+	size int
+	ttl  time.Duration
+	now  func() time.Time
+
+	mu      sync.Mutex
+	order   *list.List // of *entry[K, V], most recently used first
+	entries map[K]*list.Element
+	stats   Stats
+}
+
+type entry[K comparable, V any] struct {
+	key     K
+	value   V
+	expires time.Time // zero without a TTL
+}
+
+// NewLRU creates a cache holding up to size entries for ttl each, or
+// forever if ttl is 0.
+func NewLRU[K comparable, V any](size int, ttl time.Duration) *LRU[K, V] {
+	return &LRU[K, V]{
+		size:    size,
+		ttl:     ttl,
+		now:     time.Now,
+		order:   list.New(),
+		entries: make(map[K]*list.Element, size),
+	}
+}
+
+// Get returns the cached value for key, counting a hit or a miss.
+func (c *LRU[K, V]) Get(key K) (V, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if el, ok := c.entries[key]; ok {
+		e := el.Value.(*entry[K, V])
+		if e.expires.IsZero() || c.now().Before(e.expires) {
+			c.order.MoveToFront(el)
+			c.stats.Hits++
+			return e.value, true
+		}
+		c.order.Remove(el)
+		delete(c.entries, key)
+	}
+	c.stats.Misses++
+	var zero V
+	return zero, false
+}
+
+// Put caches value for key, evicting the least recently used entry if the
+// cache is full.
+func (c *LRU[K, V]) Put(key K, value V) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var expires time.Time
+	if c.ttl > 0 {
+		expires = c.now().Add(c.ttl)
+	}
+	if el, ok := c.entries[key]; ok {
+		e := el.Value.(*entry[K, V])
+		e.value, e.expires = value, expires
+		c.order.MoveToFront(el)
+		return
+	}
+	if c.order.Len() >= c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*entry[K, V]).key)
+	}
+	c.entries[key] = c.order.PushFront(&entry[K, V]{key: key, value: value, expires: expires})
+}
+
+// Purge drops every entry. The hits and misses so far are kept.
+func (c *LRU[K, V]) Purge() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.order.Init()
+	clear(c.entries)
+}
+
+// Stats returns the hits and misses so far.
+func (c *LRU[K, V]) Stats() Stats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.stats
+}
+
+// Dataset serves GetBalance from a cache in front of another dataset;
+// everything else goes to the wrapped dataset. Cached accounts are shared
+// between callers, who must not modify them. Failed lookups are not cached.
+type Dataset struct {
+	qos.Dataset
+	balances *LRU[string, *db.Account] // nil when caching is disabled
+}
+
+// Wrap returns ds with its balances cached as configured. With caching
+// disabled every GetBalance goes to ds and Stats stays zero.
+func Wrap(ds qos.Dataset, cfg Config) *Dataset {
+	d := &Dataset{Dataset: ds}
+	if cfg.Enabled() {
+		d.balances = NewLRU[string, *db.Account](cfg.Size, cfg.TTL)
+	}
+	return d
+}
+
+// GetBalance returns the cached balance of accountID, reading and caching
+// it on a miss.
+func (d *Dataset) GetBalance(ctx context.Context, accountID string) (*db.Account, error) {
+	if d.balances == nil {
+		return d.Dataset.GetBalance(ctx, accountID)
+	}
+	if account, ok := d.balances.Get(accountID); ok {
+		return account, nil
+	}
+	account, err := d.Dataset.GetBalance(ctx, accountID)
+	if err != nil {
+		return nil, err
+	}
+	d.balances.Put(accountID, account)
+	return account, nil
+}
+
+// Purge drops every cached balance, e.g. after the wrapped dataset switched
+// to another database.
+func (d *Dataset) Purge() {
+	if d.balances != nil {
+		d.balances.Purge()
+	}
+}
+
+// Stats returns the balance cache hits and misses since the server started.
+// A nil *Dataset has none.
+func (d *Dataset) Stats() Stats {
+	if d == nil || d.balances == nil {
+		return Stats{}
+	}
+	return d.balances.Stats()
+}
@@ -0,0 +1,141 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/kaldun-tech/grpc-rest-benchmark/pkg/db"
+	"github.com/kaldun-tech/grpc-rest-benchmark/pkg/qos"
+)
+
+func TestConfig(t *testing.T) {
+	var none Config
+	if none.Enabled() || none.String() != "" {
+		t.Errorf("zero Config is enabled or described as %q", none.String())
+	}
+
+	c := Config{Size: 10000, TTL: time.Second}
+	if err := c.Validate(); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+	if got, want := c.String(), "size=10000,ttl=1s"; got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+	if got, want := (Config{Size: 5}).String(), "size=5"; got != want {
+		t.Errorf("String() without TTL = %q, want %q", got, want)
+	}
+
+	for _, bad := range []Config{{Size: -1}, {Size: 1, TTL: -1}} {
+		if err := bad.Validate(); err == nil {
+			t.Errorf("Validate(%+v) = nil, want an error", bad)
+		}
+	}
+}
+
+func TestLRU_Evict(t *testing.T) {
+	c := NewLRU[string, int](2, 0)
+	c.Put("a", 1)
+	c.Put("b", 2)
+	c.Get("a") // b is now the least recently used
+	c.Put("c", 3)
+
+	if _, ok := c.Get("b"); ok {
+		t.Error("Get(b) hit, want it evicted")
+	}
+	for key, want := range map[string]int{"a": 1, "c": 3} {
+		if got, ok := c.Get(key); !ok || got != want {
+			t.Errorf("Get(%s) = %d, %v; want %d, true", key, got, ok, want)
+		}
+	}
+	if got, want := c.Stats(), (Stats{Hits: 3, Misses: 1}); got != want {
+		t.Errorf("Stats() = %+v, want %+v", got, want)
+	}
+}
+
+func TestLRU_TTL(t *testing.T) {
+	now := time.Unix(0, 0)
+	c := NewLRU[string, int](10, time.Second)
+	c.now = func() time.Time { return now }
+
+	c.Put("a", 1)
+	now = now.Add(999 * time.Millisecond)
+	if _, ok := c.Get("a"); !ok {
+		t.Error("Get() before the TTL missed, want a hit")
+	}
+	now = now.Add(time.Millisecond)
+	if _, ok := c.Get("a"); ok {
+		t.Error("Get() at the TTL hit, want the entry expired")
+	}
+}
+
+func TestLRU_Purge(t *testing.T) {
+	c := NewLRU[string, int](10, 0)
+	c.Put("a", 1)
+	c.Get("a")
+	c.Purge()
+
+	if _, ok := c.Get("a"); ok {
+		t.Error("Get() after Purge() hit, want a miss")
+	}
+	c.Put("b", 2)
+	if got, ok := c.Get("b"); !ok || got != 2 {
+		t.Errorf("Get(b) after Purge() = %d, %v; want 2, true", got, ok)
+	}
+	if got, want := c.Stats(), (Stats{Hits: 2, Misses: 1}); got != want {
+		t.Errorf("Stats() = %+v, want %+v kept across Purge()", got, want)
+	}
+}
+
+// balanceDataset counts GetBalance calls and fails for unknown accounts.
+type balanceDataset struct {
+	qos.Dataset
+	calls int
+}
+
+func (d *balanceDataset) GetBalance(ctx context.Context, accountID string) (*db.Account, error) {
+	d.calls++
+	if accountID == "missing" {
+		return nil, errors.New("account not found")
+	}
+	return &db.Account{AccountID: accountID, Balance: 100}, nil
+}
+
+func TestDataset_GetBalance(t *testing.T) {
+	ctx := context.Background()
+	inner := &balanceDataset{}
+	d := Wrap(inner, Config{Size: 10, TTL: time.Minute})
+
+	for range 3 {
+		account, err := d.GetBalance(ctx, "0.0.1001")
+		if err != nil || account.Balance != 100 {
+			t.Fatalf("GetBalance() = %+v, %v; want balance 100", account, err)
+		}
+	}
+	for range 2 {
+		if _, err := d.GetBalance(ctx, "missing"); err == nil {
+			t.Fatal("GetBalance(missing) error = nil, want the dataset's error")
+		}
+	}
+
+	if inner.calls != 3 {
+		t.Errorf("dataset calls = %d, want 3: one for the cached account and two for the failing one", inner.calls)
+	}
+	if got, want := d.Stats(), (Stats{Hits: 2, Misses: 3}); got != want {
+		t.Errorf("Stats() = %+v, want %+v", got, want)
+	}
+}
+
+func TestDataset_Disabled(t *testing.T) {
+	inner := &balanceDataset{}
+	d := Wrap(inner, Config{})
+	for range 2 {
+		if _, err := d.GetBalance(context.Background(), "0.0.1001"); err != nil {
+			t.Fatalf("GetBalance() error = %v", err)
+		}
+	}
+	if inner.calls != 2 || d.Stats() != (Stats{}) {
+		t.Errorf("dataset calls = %d, stats = %+v; want every call passed through and no stats", inner.calls, d.Stats())
+	}
+}
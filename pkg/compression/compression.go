@@ -0,0 +1,163 @@
+// Package compression provides the message compression algorithms the
+// benchmark can enable on every transport: gRPC compressors, Connect
+// compression options and HTTP Content-Encoding for the REST API.
+package compression
+
+import (
+	"compress/flate"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// Algorithm names, as used in grpc-encoding and Content-Encoding headers.
+const (
+	None    = "none"
+	Gzip    = "gzip"
+	Deflate = "deflate"
+	Zstd    = "zstd"
+)
+
+// Names lists the selectable algorithms, None first.
+var Names = []string{None, Gzip, Deflate, Zstd}
+
+// encoder is a resettable compressing writer.
+type encoder interface {
+	io.WriteCloser
+	Flush() error
+	Reset(w io.Writer)
+}
+
+// decoder is a resettable decompressing reader.
+type decoder interface {
+	io.Reader
+	Reset(r io.Reader) error
+}
+
+// algorithm pools encoders and decoders so each request does not pay for
+// allocating compression state, matching what gRPC and Connect do internally.
+type algorithm struct {
+	newEncoder func(w io.Writer) encoder
+	newDecoder func(r io.Reader) (decoder, error)
+	encoders   sync.Pool
+	decoders   sync.Pool
+}
+
+var algorithms = map[string]*algorithm{
+	Gzip: {
+		newEncoder: func(w io.Writer) encoder { return gzip.NewWriter(w) },
+		newDecoder: func(r io.Reader) (decoder, error) { return gzip.NewReader(r) },
+	},
+	Deflate: {
+		newEncoder: func(w io.Writer) encoder {
+			fw, _ := flate.NewWriter(w, flate.DefaultCompression) // only fails for invalid levels
+			return fw
+		},
+		newDecoder: func(r io.Reader) (decoder, error) { return &flateReader{flate.NewReader(r)}, nil },
+	},
+	Zstd: {
+		newEncoder: func(w io.Writer) encoder {
+			zw, _ := zstd.NewWriter(w, zstd.WithEncoderConcurrency(1)) // only fails for invalid options
+			return zw
+		},
+		newDecoder: func(r io.Reader) (decoder, error) {
+			return zstd.NewReader(r, zstd.WithDecoderConcurrency(1))
+		},
+	},
+}
+
+// flateReader adapts flate's Resetter to the decoder interface.
+type flateReader struct {
+	io.ReadCloser
+}
+
+func (f *flateReader) Reset(r io.Reader) error {
+	return f.ReadCloser.(flate.Resetter).Reset(r, nil)
+}
+
+// Valid reports whether name is a supported algorithm other than None.
+func Valid(name string) bool {
+	_, ok := algorithms[name]
+	return ok
+}
+
+// Writer is a compressing writer. Flush pushes buffered data to the
+// underlying writer, for streaming responses. Close finishes the compressed
+// stream; the Writer must not be used afterwards.
+type Writer interface {
+	io.WriteCloser
+	Flush() error
+}
+
+// NewWriter returns a Writer compressing into w with the named algorithm.
+func NewWriter(name string, w io.Writer) (Writer, error) {
+	alg, ok := algorithms[name]
+	if !ok {
+		return nil, fmt.Errorf("unsupported compression: %q", name)
+	}
+	if e, ok := alg.encoders.Get().(encoder); ok {
+		e.Reset(w)
+		return &pooledWriter{encoder: e, pool: &alg.encoders}, nil
+	}
+	return &pooledWriter{encoder: alg.newEncoder(w), pool: &alg.encoders}, nil
+}
+
+type pooledWriter struct {
+	encoder
+	pool *sync.Pool
+}
+
+func (w *pooledWriter) Close() error {
+	err := w.encoder.Close()
+	w.pool.Put(w.encoder)
+	return err
+}
+
+// NewReader returns a reader decompressing r with the named algorithm. The
+// decoder is recycled when the reader is closed or reaches the end of the
+// stream, whichever comes first.
+func NewReader(name string, r io.Reader) (io.ReadCloser, error) {
+	alg, ok := algorithms[name]
+	if !ok {
+		return nil, fmt.Errorf("unsupported compression: %q", name)
+	}
+	if d, ok := alg.decoders.Get().(decoder); ok {
+		if err := d.Reset(r); err != nil {
+			alg.decoders.Put(d)
+			return nil, err
+		}
+		return &pooledReader{decoder: d, pool: &alg.decoders}, nil
+	}
+	d, err := alg.newDecoder(r)
+	if err != nil {
+		return nil, err
+	}
+	return &pooledReader{decoder: d, pool: &alg.decoders}, nil
+}
+
+type pooledReader struct {
+	decoder
+	pool *sync.Pool
+}
+
+func (r *pooledReader) Read(p []byte) (int, error) {
+	if r.decoder == nil {
+		return 0, io.EOF
+	}
+	n, err := r.decoder.Read(p)
+	if err == io.EOF {
+		r.Close()
+	}
+	return n, err
+}
+
+func (r *pooledReader) Close() error {
+	if r.decoder != nil {
+		r.pool.Put(r.decoder)
+		r.decoder = nil
+	}
+	return nil
+}
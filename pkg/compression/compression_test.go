@@ -0,0 +1,134 @@
+package compression
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRoundTrip(t *testing.T) {
+	want := bytes.Repeat([]byte(`{"account_id":"0.0.1001","balance":123456}`), 100)
+
+	for _, name := range Names[1:] {
+		t.Run(name, func(t *testing.T) {
+			var buf bytes.Buffer
+			w, err := NewWriter(name, &buf)
+			if err != nil {
+				t.Fatalf("NewWriter() error = %v", err)
+			}
+			if _, err := w.Write(want); err != nil {
+				t.Fatalf("Write() error = %v", err)
+			}
+			if err := w.Close(); err != nil {
+				t.Fatalf("Close() error = %v", err)
+			}
+			if buf.Len() >= len(want) {
+				t.Errorf("compressed size %d, want less than %d", buf.Len(), len(want))
+			}
+
+			// Twice, so the second pass reuses pooled state.
+			for range 2 {
+				r, err := NewReader(name, bytes.NewReader(buf.Bytes()))
+				if err != nil {
+					t.Fatalf("NewReader() error = %v", err)
+				}
+				got, err := io.ReadAll(r)
+				r.Close()
+				if err != nil {
+					t.Fatalf("ReadAll() error = %v", err)
+				}
+				if !bytes.Equal(got, want) {
+					t.Fatalf("round trip mismatch: got %d bytes, want %d", len(got), len(want))
+				}
+			}
+		})
+	}
+}
+
+func TestUnknownAlgorithm(t *testing.T) {
+	if Valid("brotli") || Valid(None) {
+		t.Error("Valid() accepted brotli or none")
+	}
+	if _, err := NewWriter("brotli", io.Discard); err == nil {
+		t.Error("NewWriter() expected error for unknown algorithm")
+	}
+	if _, err := NewReader("brotli", strings.NewReader("")); err == nil {
+		t.Error("NewReader() expected error for unknown algorithm")
+	}
+}
+
+func TestNegotiate(t *testing.T) {
+	tests := []struct {
+		header string
+		want   string
+	}{
+		{"", None},
+		{"identity", None},
+		{"gzip", Gzip},
+		{"br, zstd, gzip", Zstd},
+		{"GZIP", Gzip},
+		{"deflate;q=0.5, gzip", Deflate},
+		{"zstd;q=0, gzip", Gzip},
+	}
+
+	for _, tt := range tests {
+		if got := Negotiate(tt.header); got != tt.want {
+			t.Errorf("Negotiate(%q) = %q, want %q", tt.header, got, tt.want)
+		}
+	}
+}
+
+func TestHandler(t *testing.T) {
+	body := strings.Repeat("data: {\"id\":1}\n\n", 50)
+	h := Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Length", "999")
+		io.WriteString(w, body)
+		w.(http.Flusher).Flush()
+	}))
+
+	t.Run("uncompressed", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+		if got := rec.Header().Get("Content-Encoding"); got != "" {
+			t.Errorf("Content-Encoding = %q, want none", got)
+		}
+		if rec.Body.String() != body {
+			t.Error("body was modified")
+		}
+	})
+
+	for _, name := range Names[1:] {
+		t.Run(name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.Header.Set("Accept-Encoding", name)
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+
+			if got := rec.Header().Get("Content-Encoding"); got != name {
+				t.Errorf("Content-Encoding = %q, want %q", got, name)
+			}
+			if got := rec.Header().Get("Content-Length"); got != "" {
+				t.Errorf("Content-Length = %q, want it removed", got)
+			}
+			if !rec.Flushed {
+				t.Error("Flush was not passed through")
+			}
+
+			r, err := NewReader(name, rec.Body)
+			if err != nil {
+				t.Fatalf("NewReader() error = %v", err)
+			}
+			defer r.Close()
+			got, err := io.ReadAll(r)
+			if err != nil {
+				t.Fatalf("ReadAll() error = %v", err)
+			}
+			if string(got) != body {
+				t.Errorf("decompressed body mismatch: got %d bytes, want %d", len(got), len(body))
+			}
+		})
+	}
+}
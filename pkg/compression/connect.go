@@ -0,0 +1,79 @@
+package compression
+
+import (
+	"io"
+
+	"connectrpc.com/connect"
+)
+
+// ConnectHandlerOptions returns handler options that accept every algorithm.
+// Connect handlers support gzip by default.
+func ConnectHandlerOptions() []connect.HandlerOption {
+	return []connect.HandlerOption{
+		connect.WithCompression(Deflate, connectDecompressor(Deflate), connectCompressor(Deflate)),
+		connect.WithCompression(Zstd, connectDecompressor(Zstd), connectCompressor(Zstd)),
+	}
+}
+
+// ConnectClientOptions returns client options that compress requests and
+// accept responses with the named algorithm only. With None, messages are
+// sent and received uncompressed.
+func ConnectClientOptions(name string) []connect.ClientOption {
+	// Connect clients accept gzip responses unless told otherwise
+	opts := []connect.ClientOption{connect.WithAcceptCompression(Gzip, nil, nil)}
+	switch name {
+	case None:
+	case Gzip:
+		opts = []connect.ClientOption{connect.WithSendGzip()}
+	default:
+		opts = append(opts,
+			connect.WithAcceptCompression(name, connectDecompressor(name), connectCompressor(name)),
+			connect.WithSendCompression(name),
+		)
+	}
+	return opts
+}
+
+func connectCompressor(name string) func() connect.Compressor {
+	alg := algorithms[name]
+	return func() connect.Compressor {
+		return alg.newEncoder(io.Discard)
+	}
+}
+
+func connectDecompressor(name string) func() connect.Decompressor {
+	alg := algorithms[name]
+	return func() connect.Decompressor {
+		return &connectDecoder{alg: alg}
+	}
+}
+
+// connectDecoder creates its decoder on the first Reset, since some decoders
+// must read a header from a real stream when created.
+type connectDecoder struct {
+	alg *algorithm
+	dec decoder
+}
+
+func (d *connectDecoder) Read(p []byte) (int, error) {
+	if d.dec == nil {
+		return 0, io.EOF
+	}
+	return d.dec.Read(p)
+}
+
+func (d *connectDecoder) Reset(r io.Reader) error {
+	if d.dec == nil {
+		dec, err := d.alg.newDecoder(r)
+		if err != nil {
+			return err
+		}
+		d.dec = dec
+		return nil
+	}
+	return d.dec.Reset(r)
+}
+
+func (d *connectDecoder) Close() error {
+	return nil
+}
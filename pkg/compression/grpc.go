@@ -0,0 +1,33 @@
+package compression
+
+import (
+	"io"
+
+	"google.golang.org/grpc/encoding"
+	_ "google.golang.org/grpc/encoding/gzip" // registers the gzip compressor
+)
+
+// Importing this package registers deflate and zstd with gRPC alongside
+// gzip, so a server accepts all three and replies with whichever the client
+// used.
+func init() {
+	encoding.RegisterCompressor(grpcCompressor{Deflate})
+	encoding.RegisterCompressor(grpcCompressor{Zstd})
+}
+
+// grpcCompressor adapts an algorithm to gRPC's encoding.Compressor.
+type grpcCompressor struct {
+	name string
+}
+
+func (c grpcCompressor) Compress(w io.Writer) (io.WriteCloser, error) {
+	return NewWriter(c.name, w)
+}
+
+func (c grpcCompressor) Decompress(r io.Reader) (io.Reader, error) {
+	return NewReader(c.name, r)
+}
+
+func (c grpcCompressor) Name() string {
+	return c.name
+}
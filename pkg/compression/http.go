@@ -0,0 +1,96 @@
+package compression
+
+import (
+	"net/http"
+	"strings"
+)
+
+// Handler compresses responses from next with the first algorithm listed in
+// the request's Accept-Encoding that this package supports. Requests that do
+// not ask for compression are served unchanged. Flushes, as used by SSE
+// streams, flush the compressor first so events are not held back.
+func Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		name := Negotiate(r.Header.Get("Accept-Encoding"))
+		if name == None {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		cw := &responseWriter{ResponseWriter: w, name: name}
+		defer cw.close()
+		next.ServeHTTP(cw, r)
+	})
+}
+
+// Negotiate picks the algorithm for an Accept-Encoding header value, or None.
+// Preference follows the order the client listed; q-values other than q=0
+// are not weighed.
+func Negotiate(acceptEncoding string) string {
+	for _, part := range strings.Split(acceptEncoding, ",") {
+		name, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		if strings.TrimSpace(params) == "q=0" {
+			continue
+		}
+		if name = strings.ToLower(strings.TrimSpace(name)); Valid(name) {
+			return name
+		}
+	}
+	return None
+}
+
+// responseWriter compresses the body written through it. The compressor is
+// created on the first write so error responses without a body stay empty.
+type responseWriter struct {
+	http.ResponseWriter
+	name        string
+	writer      Writer
+	wroteHeader bool
+}
+
+func (w *responseWriter) WriteHeader(status int) {
+	if w.wroteHeader {
+		return
+	}
+	w.wroteHeader = true
+	h := w.Header()
+	h.Set("Content-Encoding", w.name)
+	h.Add("Vary", "Accept-Encoding")
+	h.Del("Content-Length")
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *responseWriter) Write(p []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	if w.writer == nil {
+		cw, err := NewWriter(w.name, w.ResponseWriter)
+		if err != nil {
+			return 0, err
+		}
+		w.writer = cw
+	}
+	return w.writer.Write(p)
+}
+
+// Flush implements http.Flusher.
+func (w *responseWriter) Flush() {
+	if w.writer != nil {
+		w.writer.Flush()
+	}
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer.
+func (w *responseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+func (w *responseWriter) close() {
+	if w.writer != nil {
+		w.writer.Close()
+	}
+}
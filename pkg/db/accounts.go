@@ -13,15 +13,18 @@ type Account struct {
 	UpdatedAt time.Time
 }
 
-// GetBalance retrieves the balance for a single account.
+// GetBalance retrieves the balance for a single account, in the query
+// transaction if one is set.
 func (db *DB) GetBalance(ctx context.Context, accountID string) (*Account, error) {
 	var acc Account
-	err := db.Pool.QueryRow(ctx,
-		`SELECT account_id, balance_tinybar, updated_at
-		 FROM accounts
-		 WHERE account_id = $1`,
-		accountID,
-	).Scan(&acc.AccountID, &acc.Balance, &acc.UpdatedAt)
+	err := db.inQueryTx(ctx, func(q querier) error {
+		return q.QueryRow(ctx,
+			`SELECT account_id, balance_tinybar, updated_at
+			 FROM accounts
+			 WHERE account_id = $1`,
+			accountID,
+		).Scan(&acc.AccountID, &acc.Balance, &acc.UpdatedAt)
+	})
 
 	if err != nil {
 		return nil, fmt.Errorf("failed to get balance for %s: %w", accountID, err)
@@ -30,34 +33,42 @@ func (db *DB) GetBalance(ctx context.Context, accountID string) (*Account, error
 	return &acc, nil
 }
 
-// GetBalances retrieves balances for multiple accounts.
+// GetBalances retrieves balances for multiple accounts, in the query
+// transaction if one is set.
 func (db *DB) GetBalances(ctx context.Context, accountIDs []string) ([]*Account, error) {
 	if len(accountIDs) == 0 {
 		return []*Account{}, nil
 	}
 
-	rows, err := db.Pool.Query(ctx,
-		`SELECT account_id, balance_tinybar, updated_at
-		 FROM accounts
-		 WHERE account_id = ANY($1)`,
-		accountIDs,
-	)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get balances: %w", err)
-	}
-	defer rows.Close()
-
-	accounts := make([]*Account, 0, len(accountIDs))
-	for rows.Next() {
-		var acc Account
-		if err := rows.Scan(&acc.AccountID, &acc.Balance, &acc.UpdatedAt); err != nil {
-			return nil, fmt.Errorf("failed to scan account row: %w", err)
+	var accounts []*Account
+	err := db.inQueryTx(ctx, func(q querier) error {
+		rows, err := q.Query(ctx,
+			`SELECT account_id, balance_tinybar, updated_at
+			 FROM accounts
+			 WHERE account_id = ANY($1)`,
+			accountIDs,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to get balances: %w", err)
+		}
+		defer rows.Close()
+
+		accounts = make([]*Account, 0, len(accountIDs))
+		for rows.Next() {
+			var acc Account
+			if err := rows.Scan(&acc.AccountID, &acc.Balance, &acc.UpdatedAt); err != nil {
+				return fmt.Errorf("failed to scan account row: %w", err)
+			}
+			accounts = append(accounts, &acc)
 		}
-		accounts = append(accounts, &acc)
-	}
 
-	if err := rows.Err(); err != nil {
-		return nil, fmt.Errorf("error iterating account rows: %w", err)
+		if err := rows.Err(); err != nil {
+			return fmt.Errorf("error iterating account rows: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	return accounts, nil
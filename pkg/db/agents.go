@@ -0,0 +1,165 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"regexp"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// ErrAgentNotFound is returned when a requested agent is not registered.
+var ErrAgentNotFound = errors.New("agent not found")
+
+// AgentTimeout is how long an agent stays healthy after its last
+// heartbeat, three missed heartbeats of a worker.
+const AgentTimeout = 30 * time.Second
+
+// agentNamePattern restricts agent names to what reads well in run labels
+// and URLs.
+var agentNamePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]{0,62}$`)
+
+// Agent is a load generation agent ('benchmark worker --register') with
+// the capabilities it reported.
+type Agent struct {
+	Name      string
+	Address   string   // host:port the worker accepts coordinators on
+	Protocols []string // protocols it can generate load with
+	CPUs      int
+
+	RegisteredAt  time.Time
+	LastHeartbeat time.Time
+	Healthy       bool // heartbeat within AgentTimeout, as of the query
+}
+
+// Validate checks the agent's name and address.
+func (a *Agent) Validate() error {
+	if !agentNamePattern.MatchString(a.Name) {
+		return fmt.Errorf("invalid agent name %q (letters, digits, '.', '_' and '-', at most 63)", a.Name)
+	}
+	if _, _, err := net.SplitHostPort(a.Address); err != nil {
+		return fmt.Errorf("invalid agent address %q: must be host:port", a.Address)
+	}
+	if a.CPUs < 0 {
+		return fmt.Errorf("agent cpus must not be negative")
+	}
+	return nil
+}
+
+// agentColumns are the columns scanned by scanAgent; $1 is AgentTimeout
+// in seconds.
+const agentColumns = `name, address, protocols, cpus, registered_at, last_heartbeat,
+	last_heartbeat >= NOW() - make_interval(secs => $1::float8)`
+
+func scanAgent(row pgx.Row) (*Agent, error) {
+	var a Agent
+	err := row.Scan(&a.Name, &a.Address, &a.Protocols, &a.CPUs, &a.RegisteredAt, &a.LastHeartbeat, &a.Healthy)
+	if err != nil {
+		return nil, err
+	}
+	return &a, nil
+}
+
+// RegisterAgent registers an agent, or updates the address and
+// capabilities of one registered under the same name, e.g. after it
+// restarted. Registering counts as a heartbeat.
+func (db *DB) RegisterAgent(ctx context.Context, a *Agent) error {
+	if err := a.Validate(); err != nil {
+		return err
+	}
+	protocols := a.Protocols
+	if protocols == nil {
+		protocols = []string{}
+	}
+	row := db.Pool.QueryRow(ctx,
+		`INSERT INTO agents (name, address, protocols, cpus)
+		 VALUES ($2, $3, $4, $5)
+		 ON CONFLICT (name) DO UPDATE
+		 SET address = EXCLUDED.address, protocols = EXCLUDED.protocols, cpus = EXCLUDED.cpus,
+		     registered_at = NOW(), last_heartbeat = NOW()
+		 RETURNING `+agentColumns,
+		AgentTimeout.Seconds(), a.Name, a.Address, protocols, a.CPUs,
+	)
+	registered, err := scanAgent(row)
+	if err != nil {
+		return fmt.Errorf("failed to register agent: %w", err)
+	}
+	*a = *registered
+	return nil
+}
+
+// HeartbeatAgent records a heartbeat of a registered agent. It returns
+// ErrAgentNotFound if there is none, e.g. because it was deleted, so the
+// agent can register again.
+func (db *DB) HeartbeatAgent(ctx context.Context, name string) error {
+	tag, err := db.Pool.Exec(ctx, `UPDATE agents SET last_heartbeat = NOW() WHERE name = $1`, name)
+	if err != nil {
+		return fmt.Errorf("failed to record agent heartbeat: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrAgentNotFound
+	}
+	return nil
+}
+
+// GetAgent retrieves a registered agent, or ErrAgentNotFound.
+func (db *DB) GetAgent(ctx context.Context, name string) (*Agent, error) {
+	a, err := scanAgent(db.Pool.QueryRow(ctx,
+		`SELECT `+agentColumns+` FROM agents WHERE name = $2`,
+		AgentTimeout.Seconds(), name,
+	))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrAgentNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get agent: %w", err)
+	}
+	return a, nil
+}
+
+// ListAgents retrieves the registered agents, only the healthy ones if
+// healthyOnly, ordered by name.
+func (db *DB) ListAgents(ctx context.Context, healthyOnly bool) ([]*Agent, error) {
+	query := `SELECT ` + agentColumns + ` FROM agents`
+	if healthyOnly {
+		query += ` WHERE last_heartbeat >= NOW() - make_interval(secs => $1::float8)`
+	}
+	query += ` ORDER BY name`
+
+	rows, err := db.Pool.Query(ctx, query, AgentTimeout.Seconds())
+	if err != nil {
+		return nil, fmt.Errorf("failed to query agents: %w", err)
+	}
+	defer rows.Close()
+
+	var agents []*Agent
+	for rows.Next() {
+		a, err := scanAgent(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan agent row: %w", err)
+		}
+		agents = append(agents, a)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating agent rows: %w", err)
+	}
+
+	return agents, nil
+}
+
+// DeleteAgent unregisters an agent. It returns ErrAgentNotFound if there is
+// none.
+func (db *DB) DeleteAgent(ctx context.Context, name string) error {
+	tag, err := db.Pool.Exec(ctx, `DELETE FROM agents WHERE name = $1`, name)
+	if err != nil {
+		return fmt.Errorf("failed to delete agent: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrAgentNotFound
+	}
+	return nil
+}
@@ -0,0 +1,76 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestAgent_Validate(t *testing.T) {
+	if err := (&Agent{Name: "east-1", Address: "10.0.0.5:50070", CPUs: 8}).Validate(); err != nil {
+		t.Errorf("Validate() error = %v", err)
+	}
+	for _, a := range []*Agent{
+		{Name: "", Address: "east:50070"},
+		{Name: "east/1", Address: "east:50070"},
+		{Name: "east", Address: "east"},
+		{Name: "east", Address: "east:50070", CPUs: -1},
+	} {
+		if err := a.Validate(); err == nil {
+			t.Errorf("Validate() of %+v = nil, want an error", a)
+		}
+	}
+}
+
+func TestAgents(t *testing.T) {
+	db := testDB(t)
+	defer db.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	a := &Agent{Name: "go-test-agent", Address: "east:50070", Protocols: []string{"grpc", "rest"}, CPUs: 4}
+	if err := db.RegisterAgent(ctx, a); err != nil {
+		t.Fatalf("RegisterAgent() error = %v", err)
+	}
+	defer db.DeleteAgent(ctx, a.Name)
+	if !a.Healthy || a.LastHeartbeat.IsZero() {
+		t.Errorf("RegisterAgent() = %+v, want a healthy agent", a)
+	}
+
+	// Registering again updates the address and capabilities
+	again := &Agent{Name: a.Name, Address: "west:50070", Protocols: []string{"grpc"}, CPUs: 16}
+	if err := db.RegisterAgent(ctx, again); err != nil {
+		t.Fatalf("RegisterAgent() again error = %v", err)
+	}
+	got, err := db.GetAgent(ctx, a.Name)
+	if err != nil {
+		t.Fatalf("GetAgent() error = %v", err)
+	}
+	if got.Address != "west:50070" || got.CPUs != 16 || !slices.Equal(got.Protocols, []string{"grpc"}) {
+		t.Errorf("GetAgent() = %+v, want the re-registered address and capabilities", got)
+	}
+
+	if err := db.HeartbeatAgent(ctx, a.Name); err != nil {
+		t.Fatalf("HeartbeatAgent() error = %v", err)
+	}
+	agents, err := db.ListAgents(ctx, true)
+	if err != nil {
+		t.Fatalf("ListAgents() error = %v", err)
+	}
+	if !slices.ContainsFunc(agents, func(x *Agent) bool { return x.Name == a.Name }) {
+		t.Errorf("ListAgents(healthy) = %d agents, want the registered agent", len(agents))
+	}
+
+	if err := db.DeleteAgent(ctx, a.Name); err != nil {
+		t.Fatalf("DeleteAgent() error = %v", err)
+	}
+	if err := db.HeartbeatAgent(ctx, a.Name); !errors.Is(err, ErrAgentNotFound) {
+		t.Errorf("HeartbeatAgent() after delete error = %v, want ErrAgentNotFound", err)
+	}
+	if _, err := db.GetAgent(ctx, a.Name); !errors.Is(err, ErrAgentNotFound) {
+		t.Errorf("GetAgent() after delete error = %v, want ErrAgentNotFound", err)
+	}
+}
@@ -0,0 +1,97 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// ErrRunNotFound is returned when a requested benchmark run is not stored.
+var ErrRunNotFound = errors.New("run not found")
+
+const (
+	maxLabels     = 32
+	maxLabelValue = 256
+	maxRunNotes   = 4096
+)
+
+// labelKeyPattern restricts label keys to what can be written in a
+// ?label=key=value filter.
+var labelKeyPattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._/-]{0,62}$`)
+
+// RunMetadata changes the labels and notes of a run. Labels are merged into
+// the run's labels, a nil value removing the label; nil Notes leaves the
+// notes as they are and empty Notes clears them.
+type RunMetadata struct {
+	Labels map[string]*string
+	Notes  *string
+}
+
+// Validate checks the label keys and the lengths of the values and notes.
+func (m *RunMetadata) Validate() error {
+	if len(m.Labels) > maxLabels {
+		return fmt.Errorf("at most %d labels may be changed at once", maxLabels)
+	}
+	for k, v := range m.Labels {
+		if !labelKeyPattern.MatchString(k) {
+			return fmt.Errorf("invalid label key %q (letters, digits, '.', '_', '/' and '-', at most 63)", k)
+		}
+		if v != nil && len(*v) > maxLabelValue {
+			return fmt.Errorf("label %s is longer than %d bytes", k, maxLabelValue)
+		}
+	}
+	if m.Notes != nil && len(*m.Notes) > maxRunNotes {
+		return fmt.Errorf("notes are longer than %d bytes", maxRunNotes)
+	}
+	return nil
+}
+
+// UpdateRunMetadata applies m to a run and returns its resulting labels and
+// notes, or ErrRunNotFound. A run keeps at most maxLabels labels.
+func (db *DB) UpdateRunMetadata(ctx context.Context, runID int64, m RunMetadata) (map[string]string, *string, error) {
+	if err := m.Validate(); err != nil {
+		return nil, nil, err
+	}
+	set := map[string]string{}
+	removed := []string{}
+	for k, v := range m.Labels {
+		if v == nil {
+			removed = append(removed, k)
+		} else {
+			set[k] = *v
+		}
+	}
+
+	tx, err := db.Pool.Begin(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var labels map[string]string
+	var notes *string
+	err = tx.QueryRow(ctx,
+		`UPDATE benchmark_runs
+		 SET labels = NULLIF((COALESCE(labels, '{}') || $2::jsonb) - $3::text[], '{}'),
+		     notes = CASE WHEN $4 THEN NULLIF($5, '') ELSE notes END
+		 WHERE id = $1
+		 RETURNING labels, notes`,
+		runID, set, removed, m.Notes != nil, m.Notes,
+	).Scan(&labels, &notes)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil, ErrRunNotFound
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to update run metadata: %w", err)
+	}
+	if len(labels) > maxLabels {
+		return nil, nil, fmt.Errorf("run %d would have %d labels, at most %d are kept", runID, len(labels), maxLabels)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, nil, fmt.Errorf("failed to commit run metadata: %w", err)
+	}
+	return labels, notes, nil
+}
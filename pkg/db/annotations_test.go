@@ -0,0 +1,77 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRunMetadata_Validate(t *testing.T) {
+	env, notes := "staging", "warm-up run"
+	if err := (&RunMetadata{Labels: map[string]*string{"env": &env, "team/owner": nil}, Notes: &notes}).Validate(); err != nil {
+		t.Errorf("Validate() error = %v", err)
+	}
+	long := strings.Repeat("x", maxRunNotes+1)
+	for _, m := range []*RunMetadata{
+		{Labels: map[string]*string{"": &env}},
+		{Labels: map[string]*string{"env=prod": &env}},
+		{Labels: map[string]*string{"env": &long}},
+		{Notes: &long},
+	} {
+		if err := m.Validate(); err == nil {
+			t.Errorf("Validate() of %+v = nil, want an error", m)
+		}
+	}
+}
+
+func TestUpdateRunMetadata(t *testing.T) {
+	db := testDB(t)
+	defer db.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	runID, err := db.RecordRun(ctx, &BenchmarkRun{Scenario: "balance", Protocol: "grpc", Client: "go-test-labels", Concurrency: 1, DurationSec: 1})
+	if err != nil {
+		t.Fatalf("RecordRun() error = %v", err)
+	}
+	defer db.DeleteRun(ctx, runID)
+
+	env, team, notes := "staging", "core", "warm-up run"
+	labels, got, err := db.UpdateRunMetadata(ctx, runID, RunMetadata{Labels: map[string]*string{"env": &env, "team": &team}, Notes: &notes})
+	if err != nil {
+		t.Fatalf("UpdateRunMetadata() error = %v", err)
+	}
+	if len(labels) != 2 || labels["env"] != env || got == nil || *got != notes {
+		t.Errorf("UpdateRunMetadata() = %v, %v, want both labels and the notes", labels, got)
+	}
+
+	// Merging removes a label and leaves the notes
+	labels, got, err = db.UpdateRunMetadata(ctx, runID, RunMetadata{Labels: map[string]*string{"team": nil}})
+	if err != nil {
+		t.Fatalf("UpdateRunMetadata() error = %v", err)
+	}
+	if len(labels) != 1 || labels["env"] != env || got == nil {
+		t.Errorf("UpdateRunMetadata() = %v, %v, want env only and the notes", labels, got)
+	}
+
+	stats, err := db.GetFilteredStats(ctx, StatsFilter{Labels: map[string]string{"env": env}, RunIDs: []int64{runID}})
+	if err != nil {
+		t.Fatalf("GetFilteredStats() error = %v", err)
+	}
+	if len(stats) != 1 || stats[0].Labels["env"] != env {
+		t.Errorf("GetFilteredStats(env=staging) = %d runs, want the labeled run", len(stats))
+	}
+
+	if err := db.DeleteRun(ctx, runID); err != nil {
+		t.Fatalf("DeleteRun() error = %v", err)
+	}
+	if err := db.DeleteRun(ctx, runID); !errors.Is(err, ErrRunNotFound) {
+		t.Errorf("DeleteRun() twice error = %v, want ErrRunNotFound", err)
+	}
+	if _, _, err := db.UpdateRunMetadata(ctx, runID, RunMetadata{Notes: &notes}); !errors.Is(err, ErrRunNotFound) {
+		t.Errorf("UpdateRunMetadata() of a deleted run error = %v, want ErrRunNotFound", err)
+	}
+}
@@ -0,0 +1,173 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// Baseline is the run a new run is automatically compared with: the most
+// recent earlier run with the same scenario, protocol, client, concurrency,
+// rate limit, payload size, write ratio, operation mix, compression,
+// connection flags, server QoS mode, database pools and query transaction,
+// load profile, stream latency metric, dataset hash and dataset fingerprint.
+// Deltas are percentage changes of the new run against the baseline, nil when
+// the baseline value is zero.
+type Baseline struct {
+	RunID              int64
+	P50DeltaPct        *float64 // positive means slower
+	P99DeltaPct        *float64 // positive means slower
+	ThroughputDeltaPct *float64 // positive means faster
+}
+
+// comparableRunQuery selects the baseline of run $1. It is shared by both
+// stores; each substitutes its own placeholder.
+const comparableRunQuery = `SELECT b.id
+	FROM benchmark_runs r
+	JOIN benchmark_runs b
+	  ON (b.created_at, b.id) < (r.created_at, r.id)
+	 AND b.scenario = r.scenario
+	 AND b.protocol = r.protocol
+	 AND b.client = r.client
+	 AND b.concurrency = r.concurrency
+	 AND b.rate_limit IS NOT DISTINCT FROM r.rate_limit
+	 AND b.payload_size IS NOT DISTINCT FROM r.payload_size
+	 AND b.write_ratio IS NOT DISTINCT FROM r.write_ratio
+	 AND b.operation_mix IS NOT DISTINCT FROM r.operation_mix
+	 AND b.compression IS NOT DISTINCT FROM r.compression
+	 AND b.connection IS NOT DISTINCT FROM r.connection
+	 AND b.server_qos IS NOT DISTINCT FROM r.server_qos
+	 AND b.server_pools IS NOT DISTINCT FROM r.server_pools
+	 AND b.server_query_tx IS NOT DISTINCT FROM r.server_query_tx
+	 AND b.server_faults IS NOT DISTINCT FROM r.server_faults
+	 AND b.server_cache IS NOT DISTINCT FROM r.server_cache
+	 AND b.server_middleware IS NOT DISTINCT FROM r.server_middleware
+	 AND b.auth IS NOT DISTINCT FROM r.auth
+	 AND b.cache_bust IS NOT DISTINCT FROM r.cache_bust
+	 AND b.injected_headers IS NOT DISTINCT FROM r.injected_headers
+	 AND b.injected_header_bytes IS NOT DISTINCT FROM r.injected_header_bytes
+	 AND b.server_rate_limit IS NOT DISTINCT FROM r.server_rate_limit
+	 AND b.backoff IS NOT DISTINCT FROM r.backoff
+	 AND b.chaos_restart IS NOT DISTINCT FROM r.chaos_restart
+	 AND b.json_encoder IS NOT DISTINCT FROM r.json_encoder
+	 AND b.rest_stream_format IS NOT DISTINCT FROM r.rest_stream_format
+	 AND b.workers IS NOT DISTINCT FROM r.workers
+	 AND b.streams_per_worker IS NOT DISTINCT FROM r.streams_per_worker
+	 AND b.live_stream IS NOT DISTINCT FROM r.live_stream
+	 AND b.chaos_disconnect_ms IS NOT DISTINCT FROM r.chaos_disconnect_ms
+	 AND b.process_cost_us IS NOT DISTINCT FROM r.process_cost_us
+	 AND b.account_churn IS NOT DISTINCT FROM r.account_churn
+	 AND b.db_target IS NOT DISTINCT FROM r.db_target
+	 AND b.load_profile IS NOT DISTINCT FROM r.load_profile
+	 AND b.measure_window IS NOT DISTINCT FROM r.measure_window
+	 AND b.latency_metric IS NOT DISTINCT FROM r.latency_metric
+	 AND b.dataset_hash IS NOT DISTINCT FROM r.dataset_hash
+	 AND b.dataset_fingerprint IS NOT DISTINCT FROM r.dataset_fingerprint
+	WHERE r.id = %s
+	ORDER BY b.created_at DESC, b.id DESC
+	LIMIT 1`
+
+// Throughput returns the stored requests per second.
+func (s *BenchmarkStats) Throughput() float64 {
+	if s.DurationSec <= 0 {
+		return 0
+	}
+	return float64(s.TotalSamples) / float64(s.DurationSec)
+}
+
+// AssignBaseline selects the baseline for a stored run, records it and the
+// deltas against it with the run, and returns it. It returns nil if no
+// earlier comparable run exists.
+func AssignBaseline(ctx context.Context, store ResultsStore, runID int64) (*Baseline, error) {
+	baseID, ok, err := store.FindBaseline(ctx, runID)
+	if err != nil || !ok {
+		return nil, err
+	}
+
+	run, err := store.GetStats(ctx, runID)
+	if err != nil {
+		return nil, err
+	}
+	base, err := store.GetStats(ctx, baseID)
+	if err != nil {
+		return nil, err
+	}
+
+	b := &Baseline{
+		RunID:              baseID,
+		P50DeltaPct:        PctChange(run.P50Latency, base.P50Latency),
+		P99DeltaPct:        PctChange(run.P99Latency, base.P99Latency),
+		ThroughputDeltaPct: PctChange(run.Throughput(), base.Throughput()),
+	}
+	if err := store.SetBaseline(ctx, runID, b); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// FindBaseline returns the ID of the most recent earlier run comparable
+// with runID. ok is false if there is none.
+func (db *DB) FindBaseline(ctx context.Context, runID int64) (baseID int64, ok bool, err error) {
+	err = db.Pool.QueryRow(ctx, fmt.Sprintf(comparableRunQuery, "$1"), runID).Scan(&baseID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, fmt.Errorf("failed to find baseline run: %w", err)
+	}
+	return baseID, true, nil
+}
+
+// SetBaseline records b as the baseline of runID.
+func (db *DB) SetBaseline(ctx context.Context, runID int64, b *Baseline) error {
+	_, err := db.Pool.Exec(ctx,
+		`UPDATE benchmark_runs
+		 SET baseline_run_id = $2, p50_delta_pct = $3, p99_delta_pct = $4, throughput_delta_pct = $5
+		 WHERE id = $1`,
+		runID, b.RunID, b.P50DeltaPct, b.P99DeltaPct, b.ThroughputDeltaPct,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to set baseline run: %w", err)
+	}
+	return nil
+}
+
+// FindBaseline returns the ID of the most recent earlier run comparable
+// with runID. ok is false if there is none.
+func (l *LocalDB) FindBaseline(ctx context.Context, runID int64) (baseID int64, ok bool, err error) {
+	err = l.db.QueryRowContext(ctx, fmt.Sprintf(comparableRunQuery, "?"), runID).Scan(&baseID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, fmt.Errorf("failed to find baseline run: %w", err)
+	}
+	return baseID, true, nil
+}
+
+// SetBaseline records b as the baseline of runID.
+func (l *LocalDB) SetBaseline(ctx context.Context, runID int64, b *Baseline) error {
+	_, err := l.db.ExecContext(ctx,
+		`UPDATE benchmark_runs
+		 SET baseline_run_id = ?, p50_delta_pct = ?, p99_delta_pct = ?, throughput_delta_pct = ?
+		 WHERE id = ?`,
+		b.RunID, b.P50DeltaPct, b.P99DeltaPct, b.ThroughputDeltaPct, runID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to set baseline run: %w", err)
+	}
+	return nil
+}
+
+// PctChange returns the percentage change from base to v, or nil if base
+// is zero.
+func PctChange(v, base float64) *float64 {
+	if base == 0 {
+		return nil
+	}
+	d := (v - base) / base * 100
+	return &d
+}
@@ -0,0 +1,70 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestAssignBaseline_Local(t *testing.T) {
+	l := testLocalDB(t)
+	ctx := context.Background()
+
+	hash, otherHash := "abc", "def"
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	record := func(protocol string, hash *string, latencyMs float64, samples int, age time.Duration) int64 {
+		t.Helper()
+		id, err := l.RecordRun(ctx, &BenchmarkRun{
+			Scenario: "balance", Protocol: protocol, Concurrency: 10, DurationSec: 10,
+			DatasetHash: hash, CreatedAt: start.Add(age),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		batch := make([]*BenchmarkSample, samples)
+		for i := range batch {
+			batch[i] = &BenchmarkSample{RunID: id, LatencyMs: latencyMs, Success: true, Timestamp: start}
+		}
+		if err := l.RecordSamples(ctx, batch); err != nil {
+			t.Fatal(err)
+		}
+		return id
+	}
+
+	first := record("grpc", &hash, 2, 100, 0)
+	if b, err := AssignBaseline(ctx, l, first); err != nil || b != nil {
+		t.Fatalf("AssignBaseline(first) = %+v, %v; want no baseline", b, err)
+	}
+
+	record("rest", &hash, 5, 100, time.Minute)             // other protocol
+	record("grpc", &otherHash, 1, 100, 2*time.Minute)      // other dataset
+	second := record("grpc", &hash, 3, 150, 3*time.Minute) // 50% slower, 50% more requests
+
+	b, err := AssignBaseline(ctx, l, second)
+	if err != nil {
+		t.Fatalf("AssignBaseline() error = %v", err)
+	}
+	if b == nil || b.RunID != first {
+		t.Fatalf("AssignBaseline() = %+v, want run %d", b, first)
+	}
+	if b.P50DeltaPct == nil || *b.P50DeltaPct != 50 || b.ThroughputDeltaPct == nil || *b.ThroughputDeltaPct != 50 {
+		t.Errorf("deltas = p50 %v, throughput %v; want +50%% each", b.P50DeltaPct, b.ThroughputDeltaPct)
+	}
+
+	stats, err := l.GetStats(ctx, second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.BaselineRunID == nil || *stats.BaselineRunID != first || stats.P99DeltaPct == nil || *stats.P99DeltaPct != 50 {
+		t.Errorf("stored baseline = %v, p99 delta %v", stats.BaselineRunID, stats.P99DeltaPct)
+	}
+}
+
+func TestPctChange(t *testing.T) {
+	if got := PctChange(90, 100); got == nil || *got != -10 {
+		t.Errorf("PctChange(90, 100) = %v, want -10", got)
+	}
+	if got := PctChange(5, 0); got != nil {
+		t.Errorf("PctChange(5, 0) = %v, want nil", *got)
+	}
+}
@@ -3,6 +3,9 @@ package db
 import (
 	"context"
 	"fmt"
+	"slices"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/jackc/pgx/v5"
@@ -23,6 +26,199 @@ type BenchmarkRun struct {
 	CPUUsageAvg  *float64 // average CPU usage percentage during benchmark
 	MemoryMBAvg  *float64 // average memory usage in MB
 	MemoryMBPeak *float64 // peak memory usage in MB
+
+	// Bytes through the client host's network interfaces, nullable
+	NetBytesSent  *int64
+	NetBytesRecv  *int64
+	NetInterfaces *string // comma-separated interfaces counted
+
+	LogPath       *string  // client-side run log file, nullable
+	LatencyMetric *string  // stream latency definition behind latency_ms, nullable
+	ComparisonID  *string  // shared by runs executed together by `benchmark compare`, nullable
+	SuiteID       *string  // shared by the runs of one `benchmark run --config` suite, nullable
+	PayloadSize   *int     // echo scenario response size in bytes, nullable
+	WriteRatio    *float64 // write scenario fraction of requests submitting a transaction, nullable
+	OperationMix  *string  // mixed scenario weighted operations, e.g. "balance:720 batch(10):180 write:100", nullable
+	AccountChurn  *string  // working set rotation, e.g. "working set 5%, 10% every 1m0s, seed 42", nullable
+	DBTarget      *string  // database target the servers were switched to, e.g. "rds", nil when not switched
+	Compression   *string  // message compression algorithm, nil when uncompressed
+	Connection    *string  // non-default connection flags, nil for the defaults
+	ServerQoS     *string  // QoS mode the servers ran with, nil for none
+	ServerPools   *string  // server database pools, e.g. "shared=50/1h", nullable
+	ServerQueryTx *string  // server balance query transaction, e.g. "repeatable-read,read-only", nil for none
+	ServerFaults  *string  // faults the server injected, e.g. "latency=10ms,errors=1%", nil for none
+	ServerCache   *string  // server balance cache, e.g. "size=10000,ttl=1s", nil for none
+	JSONEncoder   *string  // REST JSON encoders, e.g. "sonic" or "client=sonic,server=std", nil for encoding/json
+
+	Cost           *float64 // estimated cost of the run under CostModel, nullable
+	CostPerMillion *float64 // Cost scaled to one million requests, nullable
+	CostModel      *string  // prices behind the estimate, e.g. "cpu=0.04/h net=0.09/GB", nullable
+
+	ClientCPUSeconds *float64 // benchmark client CPU time, nullable
+	ServerCPUSeconds *float64 // server CPU time during the run, nullable
+	ClientPerCPUSec  *float64 // requests (messages in stream scenarios) per client CPU-second, nullable
+	ServerPerCPUSec  *float64 // requests (messages in stream scenarios) per server CPU-second, nullable
+	CacheHitRate     *float64 // fraction of GetBalance calls the server cache answered, nullable
+	Workers          *int     // worker agents that generated the load, nil when this process did
+	StreamsPerWorker *int     // streams each stream worker owned, nil for 1
+	ProcessCostUs    *int64   // simulated client CPU time per response or event in µs, nil for none
+
+	LoadProfile *string // load profile target and spec, e.g. "concurrency step:10,50@30s", nullable
+	DatasetHash *string // hash of the account IDs and timing data used, nullable
+
+	DatasetFingerprint *string // fingerprint of the seeded server dataset, nullable
+	DatasetSize        *int64  // accounts in the seeded server dataset, nullable
+
+	// Transactions that completed streams reported sending but the client
+	// did not receive, nil unless the server ended a stream
+	StreamShortfall *int64
+
+	// Stream subscribers that received an event before failing or the run
+	// ending, nil for scenarios without streams
+	StreamsEstablished *int
+
+	// Part of the run the samples and stats cover (run --measure-window),
+	// as given and as resolved, e.g. "auto" and "12s..47s"; and the
+	// throughput and latency of the whole run. All nil without a window.
+	MeasureWindow  *string
+	MeasuredWindow *string
+	FullThroughput *float64
+	FullP50Ms      *float64
+	FullP99Ms      *float64
+
+	// GC pauses of the benchmark client during the run, their total time,
+	// and the fraction of the slowest 1% of samples in flight during one.
+	// Nil when the load was generated by remote workers.
+	GCPauses       *int
+	GCPauseMs      *float64
+	GCTailFraction *float64
+
+	// Whether the streams followed live transactions (run --live and the
+	// fanout scenario) instead of replaying the table; nil otherwise.
+	LiveStream *bool
+
+	// Interval at which the client cut its streams to measure reconnection
+	// (run --chaos-disconnect), nil otherwise. Reconnects counts the
+	// streams reopened, for cuts and failures alike, and the percentiles
+	// are the time they spent disconnected; nil unless a stream reconnected.
+	ChaosDisconnectMs *int64
+	Reconnects        *int
+	ReconnectP50Ms    *float64
+	ReconnectP99Ms    *float64
+
+	// Request middleware of the server a run was measured against, e.g.
+	// "log=1%,recovery,metrics", nil for none.
+	ServerMiddleware *string
+
+	// Authentication of the requests (run --auth), e.g. "jwt", nil for
+	// none.
+	Auth *string
+
+	// Rate limit of the server a run was measured against, e.g.
+	// "rate=1000/s,burst=1000", nil for none.
+	ServerRateLimit *string
+
+	// Backoff is true when the workers backed off after rate limited
+	// requests (run --backoff), nil otherwise.
+	Backoff *bool
+
+	// Server restart during the run (run --chaos-restart-server), e.g.
+	// "at=30s,downtime=1s", nil for none. RestartErrors counts the requests
+	// it failed until the client recovered, RestartRecoveryMs the time from
+	// the restart until a request succeeded again, nil if none did.
+	ChaosRestart      *string
+	RestartErrors     *int
+	RestartRecoveryMs *float64
+
+	// Agents names the registered agents (benchmark worker --register) that
+	// generated the load, comma-separated, nil when this process did or the
+	// workers were not registered.
+	Agents *string
+
+	// ConcurrentWith names the run that held the target environment's lock
+	// when this one started anyway (--allow-concurrent), nil when the run
+	// had the environment to itself.
+	ConcurrentWith *string
+
+	// Where the run came from: the client's and server's git commits (nil
+	// if not stamped in the binary), the client's Go version, GOMAXPROCS,
+	// kernel and hostname, and the --tag key=value pairs, sorted and
+	// comma-separated
+	ClientCommit *string
+	ServerCommit *string
+	GoVersion    *string
+	GOMAXPROCS   *int
+	Kernel       *string
+	Hostname     *string
+	Tags         *string
+	Environment  *string
+
+	// Go runtime of the client over the run: peak goroutines, heap objects
+	// and heap MB, and GC cycles completed; nil unless the client measured
+	// its own resources. The per-second values are in benchmark_timeseries.
+	GoroutinesPeak  *int
+	HeapObjectsPeak *int64
+	HeapMBPeak      *float64
+	GCCycles        *int
+
+	// Cache-busting mode of the requests (run --cache-bust), "query" or
+	// "header", nil when they were sent unchanged.
+	CacheBust *string
+
+	// Headers (REST) or metadata (other protocols) added to every request
+	// with run --header and --metadata: their names, sorted and
+	// comma-separated, and the bytes of names and values per request; nil
+	// for none.
+	InjectedHeaders     *string
+	InjectedHeaderBytes *int
+
+	// Bytes the client moved during the run: on its TCP connections, with
+	// HTTP framing, headers and TLS, and the message or body payloads in
+	// them; nil when remote workers generated the load.
+	WireBytesSent    *int64
+	WireBytesRecv    *int64
+	PayloadBytesSent *int64
+	PayloadBytesRecv *int64
+
+	// Serialized sizes of the messages stream scenarios received, in
+	// bytes: protobuf messages, or the JSON of REST stream events
+	MessageP50Bytes *int
+	MessageP99Bytes *int
+	MessageMaxBytes *int
+
+	// Framing of REST JSON streams (ndjson or chunked-array), nil for SSE
+	// and for other runs
+	RESTStreamFormat *string
+
+	// Configuration snapshot the run can be repeated from (benchmark
+	// bundle, run --from-bundle): JSON of its non-default run flags and
+	// workload stage, nil for runs stored before snapshots were recorded
+	RunConfig *string
+
+	// Requests measured and their latency statistics in ms, from the
+	// client's histograms rather than the stored samples, which may be a
+	// uniform sample of them (run --max-stored-samples); nil for runs
+	// stored before they were recorded
+	Requests           *int64
+	SuccessfulRequests *int64
+	LatencyP50Ms       *float64
+	LatencyP90Ms       *float64
+	LatencyP99Ms       *float64
+	LatencyAvgMs       *float64
+	LatencyMinMs       *float64
+	LatencyMaxMs       *float64
+
+	// Errors and warnings the servers logged during the run, nil unless
+	// the client read their logs (run --server-log)
+	ServerLogErrors   *int64
+	ServerLogWarnings *int64
+	ServerLogLines    *string // first distinct lines, one per line, e.g. "3x [rest-server.log] Warning: ..."
+
+	// Whether every invariant checked after the run held, and the checks
+	// with their outcomes, e.g. "pool ok: ...; stream_rows FAILED: ...";
+	// nil when none could be checked
+	Verified     *bool
+	Verification *string
 }
 
 // BenchmarkSample represents a single request latency sample.
@@ -33,6 +229,16 @@ type BenchmarkSample struct {
 	Success   bool
 	ErrorType *string // nullable
 	Timestamp time.Time
+
+	StalenessMs *float64 // age of the returned balance, nullable
+	Phase       *int     // load profile phase, counted from 1, nullable
+
+	WorkloadClass *string // "stream" or "balance" in the stream-balance scenario, nullable
+	Operation     *string // RPC measured, e.g. "GetBalance" or "SubmitTransaction", nullable
+
+	DBMs *float64 // database time the server reported for the request, nullable
+
+	GCPause *bool // in flight during a client GC pause, nullable
 }
 
 // BenchmarkStats represents aggregated stats for a run.
@@ -43,6 +249,7 @@ type BenchmarkStats struct {
 	Client       string
 	Concurrency  int
 	DurationSec  int
+	CreatedAt    time.Time
 	TotalSamples int64
 	Successful   int64
 	P50Latency   float64
@@ -54,30 +261,456 @@ type BenchmarkStats struct {
 	CPUUsageAvg  *float64
 	MemoryMBAvg  *float64
 	MemoryMBPeak *float64
+
+	NetBytesSent  *int64
+	NetBytesRecv  *int64
+	NetInterfaces *string
+
+	LatencyMetric *string // stream scenarios only
+	ComparisonID  *string
+	SuiteID       *string
+	PayloadSize   *int     // echo scenario only
+	WriteRatio    *float64 // write scenario only
+	OperationMix  *string  // mixed scenario only
+	AccountChurn  *string  // nil without working set churn
+	DBTarget      *string  // nil when the servers were not switched
+	Compression   *string  // nil when uncompressed
+	Connection    *string  // nil for the default connection settings
+	ServerQoS     *string  // nil when the servers ran without QoS
+	ServerPools   *string  // nil when the server did not record its pools
+	ServerQueryTx *string  // nil when balance queries ran without a transaction
+	ServerFaults  *string  // nil when the server injected no faults
+	ServerCache   *string  // nil when the server cached no balances
+	JSONEncoder   *string  // nil unless a REST run encoded JSON without encoding/json
+
+	Cost           *float64 // nil unless the run was priced
+	CostPerMillion *float64
+	CostModel      *string
+
+	ClientCPUSeconds *float64
+	ServerCPUSeconds *float64 // nil when the server did not report its CPU time
+	ClientPerCPUSec  *float64
+	ServerPerCPUSec  *float64
+	CacheHitRate     *float64 // nil unless the server cache answered or missed a GetBalance call
+	Workers          *int
+	StreamsPerWorker *int
+	ProcessCostUs    *int64
+
+	LoadProfile *string // nil for a fixed load
+	DatasetHash *string
+
+	DatasetFingerprint *string
+	DatasetSize        *int64 // accounts in the seeded dataset
+	StreamShortfall    *int64 // stream scenarios only
+	StreamsEstablished *int   // stream scenarios only
+
+	MeasureWindow  *string  // nil when the stats cover the whole run
+	MeasuredWindow *string  // e.g. "12s..47s"
+	FullThroughput *float64 // whole run, nil without a measure window
+	FullP50Ms      *float64
+	FullP99Ms      *float64
+
+	GCPauses       *int // client GC, nil for remote workers
+	GCPauseMs      *float64
+	GCTailFraction *float64
+
+	LiveStream *bool // nil unless the streams followed live transactions
+
+	ChaosDisconnectMs *int64 // nil unless the client cut its streams
+	Reconnects        *int   // nil unless a stream reconnected
+	ReconnectP50Ms    *float64
+	ReconnectP99Ms    *float64
+
+	ServerMiddleware *string // nil when the server ran no middleware
+	Auth             *string // nil when requests were not authenticated
+	ServerRateLimit  *string // nil when the server did not limit requests
+	Backoff          *bool   // nil unless the workers backed off
+
+	ChaosRestart      *string // nil unless the server was restarted during the run
+	RestartErrors     *int
+	RestartRecoveryMs *float64 // nil if the run did not recover
+
+	Agents         *string // registered agents that generated the load
+	ConcurrentWith *string // run holding the environment, nil unless overlapped
+
+	ClientCommit *string
+	ServerCommit *string
+	GoVersion    *string
+	GOMAXPROCS   *int
+	Kernel       *string
+	Hostname     *string
+	Tags         *string // key=value pairs, comma-separated
+	Environment  *string // registered environment the run measured
+
+	GoroutinesPeak  *int // client Go runtime, nil unless measured
+	HeapObjectsPeak *int64
+	HeapMBPeak      *float64
+	GCCycles        *int
+
+	CacheBust *string // nil unless requests were made unique to caches
+
+	InjectedHeaders     *string // nil unless headers were injected
+	InjectedHeaderBytes *int
+
+	WireBytesSent    *int64 // client connection and payload bytes, nil unless counted
+	WireBytesRecv    *int64
+	PayloadBytesSent *int64
+	PayloadBytesRecv *int64
+
+	MessageP50Bytes *int // stream message sizes, nil for unary scenarios
+	MessageP99Bytes *int
+	MessageMaxBytes *int
+
+	RESTStreamFormat *string // nil for SSE and for runs without REST JSON streams
+
+	RunConfig *string // configuration snapshot, JSON
+
+	// Samples stored in benchmark_samples, fewer than TotalSamples when
+	// only a uniform sample of the requests was stored
+	StoredSamples int64
+
+	// Annotations attached after the run, PostgreSQL only
+	Labels map[string]string
+	Notes  *string
+
+	ServerLogErrors   *int64 // nil unless the client read the server logs
+	ServerLogWarnings *int64
+	ServerLogLines    *string
+
+	Verified     *bool // nil when no invariant could be checked
+	Verification *string
+
+	// Automatically selected baseline run and percentage changes against
+	// it, nil when no earlier comparable run exists
+	BaselineRunID      *int64
+	P50DeltaPct        *float64
+	P99DeltaPct        *float64
+	ThroughputDeltaPct *float64
+
+	// Balance staleness percentiles in ms, nil unless measured
+	P50Staleness *float64
+	P90Staleness *float64
+	P99Staleness *float64
+
+	// Percentiles in ms of the database time servers reported and of the
+	// rest of those requests' latency, nil unless reported
+	P50DB      *float64
+	P99DB      *float64
+	P50Network *float64
+	P99Network *float64
+}
+
+// statsColumns lists the benchmark_stats columns read by scanStats.
+const statsColumns = `run_id, scenario, protocol, client, concurrency, duration_sec, created_at,
+	total_samples, successful,
+	p50_latency, p90_latency, p99_latency, avg_latency, min_latency, max_latency,
+	cpu_usage_avg, memory_mb_avg, memory_mb_peak, net_bytes_sent, net_bytes_recv, net_interfaces, latency_metric, comparison_id, payload_size, compression, connection, stream_shortfall, dataset_size, server_qos, server_pools, write_ratio, operation_mix, server_query_tx, cost, cost_per_million, cost_model, client_cpu_seconds, server_cpu_seconds, client_per_cpu_sec, server_per_cpu_sec, server_faults, server_cache, cache_hit_rate, workers, streams_per_worker, suite_id, process_cost_us, account_churn, db_target, server_log_errors, server_log_warnings, server_log_lines, verified, verification, streams_established, json_encoder, measure_window, measured_window, full_throughput, full_p50_ms, full_p99_ms, gc_pauses, gc_pause_ms, gc_tail_fraction, live_stream, chaos_disconnect_ms, reconnects, reconnect_p50_ms, reconnect_p99_ms, server_middleware, auth, server_rate_limit, backoff, chaos_restart, restart_errors, restart_recovery_ms, agents, concurrent_with, client_commit, server_commit, go_version, gomaxprocs, kernel, hostname, tags, environment, goroutines_peak, heap_objects_peak, heap_mb_peak, gc_cycles, cache_bust, injected_headers, injected_header_bytes, wire_bytes_sent, wire_bytes_recv, payload_bytes_sent, payload_bytes_recv, message_p50_bytes, message_p99_bytes, message_max_bytes, rest_stream_format, run_config, labels, notes, load_profile,
+	dataset_hash, dataset_fingerprint, baseline_run_id, p50_delta_pct, p99_delta_pct, throughput_delta_pct,
+	p50_staleness, p90_staleness, p99_staleness,
+	p50_db, p99_db, p50_network, p99_network, stored_samples`
+
+// scanStats scans a benchmark_stats row selected with statsColumns.
+func scanStats(row pgx.Row) (*BenchmarkStats, error) {
+	var stats BenchmarkStats
+	err := row.Scan(
+		&stats.RunID, &stats.Scenario, &stats.Protocol, &stats.Client, &stats.Concurrency,
+		&stats.DurationSec, &stats.CreatedAt, &stats.TotalSamples, &stats.Successful,
+		&stats.P50Latency, &stats.P90Latency, &stats.P99Latency,
+		&stats.AvgLatency, &stats.MinLatency, &stats.MaxLatency,
+		&stats.CPUUsageAvg, &stats.MemoryMBAvg, &stats.MemoryMBPeak,
+		&stats.NetBytesSent, &stats.NetBytesRecv, &stats.NetInterfaces,
+		&stats.LatencyMetric, &stats.ComparisonID, &stats.PayloadSize, &stats.Compression, &stats.Connection, &stats.StreamShortfall, &stats.DatasetSize, &stats.ServerQoS, &stats.ServerPools, &stats.WriteRatio, &stats.OperationMix, &stats.ServerQueryTx, &stats.Cost, &stats.CostPerMillion, &stats.CostModel, &stats.ClientCPUSeconds, &stats.ServerCPUSeconds, &stats.ClientPerCPUSec, &stats.ServerPerCPUSec, &stats.ServerFaults, &stats.ServerCache, &stats.CacheHitRate, &stats.Workers, &stats.StreamsPerWorker, &stats.SuiteID, &stats.ProcessCostUs, &stats.AccountChurn, &stats.DBTarget, &stats.ServerLogErrors, &stats.ServerLogWarnings, &stats.ServerLogLines, &stats.Verified, &stats.Verification, &stats.StreamsEstablished, &stats.JSONEncoder, &stats.MeasureWindow, &stats.MeasuredWindow, &stats.FullThroughput, &stats.FullP50Ms, &stats.FullP99Ms, &stats.GCPauses, &stats.GCPauseMs, &stats.GCTailFraction, &stats.LiveStream, &stats.ChaosDisconnectMs, &stats.Reconnects, &stats.ReconnectP50Ms, &stats.ReconnectP99Ms, &stats.ServerMiddleware, &stats.Auth, &stats.ServerRateLimit, &stats.Backoff, &stats.ChaosRestart, &stats.RestartErrors, &stats.RestartRecoveryMs, &stats.Agents, &stats.ConcurrentWith, &stats.ClientCommit, &stats.ServerCommit, &stats.GoVersion, &stats.GOMAXPROCS, &stats.Kernel, &stats.Hostname, &stats.Tags, &stats.Environment, &stats.GoroutinesPeak, &stats.HeapObjectsPeak, &stats.HeapMBPeak, &stats.GCCycles, &stats.CacheBust, &stats.InjectedHeaders, &stats.InjectedHeaderBytes, &stats.WireBytesSent, &stats.WireBytesRecv, &stats.PayloadBytesSent, &stats.PayloadBytesRecv, &stats.MessageP50Bytes, &stats.MessageP99Bytes, &stats.MessageMaxBytes, &stats.RESTStreamFormat, &stats.RunConfig, &stats.Labels, &stats.Notes, &stats.LoadProfile,
+		&stats.DatasetHash, &stats.DatasetFingerprint, &stats.BaselineRunID, &stats.P50DeltaPct, &stats.P99DeltaPct, &stats.ThroughputDeltaPct,
+		&stats.P50Staleness, &stats.P90Staleness, &stats.P99Staleness,
+		&stats.P50DB, &stats.P99DB, &stats.P50Network, &stats.P99Network, &stats.StoredSamples,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &stats, nil
 }
 
-// StatsFilter defines filter criteria for querying benchmark stats.
+// PhaseStats represents aggregated stats for one load profile phase of a run.
+type PhaseStats struct {
+	RunID        int64
+	Phase        int
+	TotalSamples int64
+	Successful   int64
+	P50Latency   float64
+	P99Latency   float64
+	StartedAt    time.Time
+	EndedAt      time.Time
+}
+
+// ClassStats represents aggregated stats for one workload class of a run
+// that mixed workloads.
+type ClassStats struct {
+	RunID        int64
+	Class        string
+	TotalSamples int64
+	Successful   int64
+	P50Latency   float64
+	P99Latency   float64
+	StartedAt    time.Time
+	EndedAt      time.Time
+}
+
+// OperationStats represents aggregated stats for one operation (RPC) of a
+// run, e.g. GetBalance, GetBalances or SubmitTransaction.
+type OperationStats struct {
+	RunID        int64
+	Operation    string
+	TotalSamples int64
+	Successful   int64
+	P50Latency   float64
+	P99Latency   float64
+	StartedAt    time.Time
+	EndedAt      time.Time
+}
+
+// ErrorCount is the number of failed samples of a run with one error type,
+// e.g. "timeout", "connection_refused", "http_5xx" or "grpc_unavailable".
+type ErrorCount struct {
+	RunID     int64
+	ErrorType string
+	Count     int64
+}
+
+// Sort keys of StatsFilter.Sort.
+const (
+	SortCreated    = "created_at" // run start
+	SortP99        = "p99"        // p99 latency
+	SortThroughput = "throughput" // samples per second of the run duration
+)
+
+// SortKeys are the sort keys a filter accepts.
+var SortKeys = []string{SortCreated, SortP99, SortThroughput}
+
+// StatsFilter defines filter criteria for querying benchmark stats or runs.
 type StatsFilter struct {
 	Scenario string
 	Protocol string
 	Client   string
 	RunID    *int64
 	Limit    int
+	Offset   int // matching rows skipped before Limit
+
+	ComparisonID string
+	SuiteID      string
+	Environment  string  // registered environment the runs measured
+	RunIDs       []int64 // any of these runs
+
+	// Runs created in [Since, Until); a zero time leaves that end open.
+	Since time.Time
+	Until time.Time
+
+	// Runs with all of these labels; runs are labeled in PostgreSQL only
+	Labels map[string]string
+
+	// Sort orders the rows by one of SortKeys, created_at if empty,
+	// highest first unless Ascending. Ties are broken by run ID. Runs can
+	// only be sorted by created_at, stats by any key.
+	Sort      string
+	Ascending bool
+
+	// After continues a listing after the row the cursor was taken from,
+	// in the same sort order.
+	After *Cursor
+}
+
+// Cursor marks a row of a sorted listing, for keyset pagination: its sort
+// value and run ID.
+type Cursor struct {
+	Value float64 // created_at in Unix microseconds when sorting by created_at
+	RunID int64
+}
+
+// createdAt returns the created_at value of a cursor of a listing sorted by
+// created_at.
+func (c Cursor) createdAt() time.Time {
+	return time.UnixMicro(int64(c.Value)).UTC()
+}
+
+// String encodes the cursor for an API parameter.
+func (c Cursor) String() string {
+	return strconv.FormatFloat(c.Value, 'g', -1, 64) + "_" + strconv.FormatInt(c.RunID, 10)
+}
+
+// ParseCursor decodes a cursor encoded by Cursor.String.
+func ParseCursor(s string) (*Cursor, error) {
+	value, id, ok := strings.Cut(s, "_")
+	if !ok {
+		return nil, fmt.Errorf("invalid cursor: %s", s)
+	}
+	v, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cursor: %s", s)
+	}
+	runID, err := strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cursor: %s", s)
+	}
+	return &Cursor{Value: v, RunID: runID}, nil
+}
+
+// Validate checks the filter's sort key and paging.
+func (f StatsFilter) Validate() error {
+	if f.Sort != "" && !slices.Contains(SortKeys, f.Sort) {
+		return fmt.Errorf("invalid sort: %s (must be one of: %s)", f.Sort, strings.Join(SortKeys, ", "))
+	}
+	if f.Limit < 0 || f.Offset < 0 {
+		return fmt.Errorf("limit and offset must not be negative")
+	}
+	if !f.Since.IsZero() && !f.Until.IsZero() && !f.Since.Before(f.Until) {
+		return fmt.Errorf("since must be before until")
+	}
+	return nil
+}
+
+// validateRuns validates a filter of runs, which cannot be sorted by a
+// statistic.
+func (f StatsFilter) validateRuns() error {
+	if err := f.Validate(); err != nil {
+		return err
+	}
+	if f.sortsByStat() {
+		return fmt.Errorf("runs cannot be sorted by %s", f.Sort)
+	}
+	return nil
+}
+
+// sortsByStat reports whether the filter sorts by an aggregate of the
+// samples rather than by run.
+func (f StatsFilter) sortsByStat() bool {
+	return f.Sort != "" && f.Sort != SortCreated
+}
+
+// CursorOf returns the cursor of a row of the listing, to pass as After
+// for the next page.
+func (f StatsFilter) CursorOf(stats *BenchmarkStats) Cursor {
+	c := Cursor{RunID: stats.RunID}
+	switch f.Sort {
+	case SortP99:
+		c.Value = stats.P99Latency
+	case SortThroughput:
+		c.Value = stats.Throughput()
+	default:
+		c.Value = float64(stats.CreatedAt.UnixMicro())
+	}
+	return c
+}
+
+// sortExpr is the SQL expression of each sort key but created_at in
+// benchmark_stats, matching CursorOf.
+var sortExpr = map[string]string{
+	SortP99:        "COALESCE(p99_latency, 0)",
+	SortThroughput: "COALESCE(total_samples::float8 / NULLIF(duration_sec, 0), 0)",
+}
+
+// clauses builds the WHERE, ORDER BY, LIMIT and OFFSET clauses for the
+// filter. idColumn names the run ID column: run_id in benchmark_stats, id
+// in benchmark_runs.
+func (f StatsFilter) clauses(idColumn string) (string, []interface{}) {
+	clause, args := f.where(idColumn)
+
+	dir, op := "DESC", "<"
+	if f.Ascending {
+		dir, op = "ASC", ">"
+	}
+	// Runs uploaded by 'benchmark sync' get later IDs than the time they
+	// ran, so created_at orders by the time itself
+	expr, ok := sortExpr[f.Sort]
+	if !ok {
+		expr = "created_at"
+	}
+	if f.After != nil {
+		var value interface{} = f.After.Value
+		if !ok {
+			value = f.After.createdAt()
+		}
+		args = append(args, value, f.After.RunID)
+		clause += fmt.Sprintf(" AND (%s, %s) %s ($%d, $%d)", expr, idColumn, op, len(args)-1, len(args))
+	}
+	clause += " ORDER BY " + expr + " " + dir + ", " + idColumn + " " + dir
+
+	if f.Limit > 0 {
+		args = append(args, f.Limit)
+		clause += fmt.Sprintf(" LIMIT $%d", len(args))
+	}
+	if f.Offset > 0 {
+		args = append(args, f.Offset)
+		clause += fmt.Sprintf(" OFFSET $%d", len(args))
+	}
+	return clause, args
+}
+
+// where builds the WHERE clause for the filter, ignoring sorting and
+// paging.
+func (f StatsFilter) where(idColumn string) (string, []interface{}) {
+	clause := " WHERE 1=1"
+	args := []interface{}{}
+	add := func(cond string, arg interface{}) {
+		args = append(args, arg)
+		clause += fmt.Sprintf(" AND "+cond, len(args))
+	}
+
+	if f.RunID != nil {
+		add(idColumn+" = $%d", *f.RunID)
+	}
+	if len(f.RunIDs) > 0 {
+		add(idColumn+" = ANY($%d)", f.RunIDs)
+	}
+	if f.Scenario != "" {
+		add("scenario = $%d", f.Scenario)
+	}
+	if f.Protocol != "" {
+		add("protocol = $%d", f.Protocol)
+	}
+	if f.Client != "" {
+		add("client = $%d", f.Client)
+	}
+	if f.ComparisonID != "" {
+		add("comparison_id = $%d", f.ComparisonID)
+	}
+	if f.SuiteID != "" {
+		add("suite_id = $%d", f.SuiteID)
+	}
+	if f.Environment != "" {
+		add("environment = $%d", f.Environment)
+	}
+	// created_at is a TIMESTAMP in UTC
+	if !f.Since.IsZero() {
+		add("created_at >= $%d", f.Since.UTC())
+	}
+	if !f.Until.IsZero() {
+		add("created_at < $%d", f.Until.UTC())
+	}
+	if len(f.Labels) > 0 {
+		add(idColumn+" IN (SELECT id FROM benchmark_runs WHERE labels @> $%d::jsonb)", f.Labels)
+	}
+	return clause, args
 }
 
 // RecordRun creates a new benchmark run record and returns its ID.
+// created_at is taken from run.CreatedAt when set, so runs uploaded by
+// benchmark sync keep the time they were executed.
 func (db *DB) RecordRun(ctx context.Context, run *BenchmarkRun) (int64, error) {
 	var id int64
 	client := run.Client
 	if client == "" {
 		client = "go"
 	}
+	var createdAt *time.Time
+	if !run.CreatedAt.IsZero() {
+		createdAt = &run.CreatedAt
+	}
 	err := db.Pool.QueryRow(ctx,
-		`INSERT INTO benchmark_runs (scenario, protocol, client, concurrency, duration_sec, rate_limit, cpu_usage_avg, memory_mb_avg, memory_mb_peak)
-		 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		`INSERT INTO benchmark_runs (scenario, protocol, client, concurrency, duration_sec, rate_limit, cpu_usage_avg, memory_mb_avg, memory_mb_peak, log_path, latency_metric, comparison_id, payload_size, compression, load_profile, dataset_hash, dataset_fingerprint, net_bytes_sent, net_bytes_recv, net_interfaces, connection, stream_shortfall, dataset_size, server_qos, server_pools, write_ratio, operation_mix, server_query_tx, cost, cost_per_million, cost_model, client_cpu_seconds, server_cpu_seconds, client_per_cpu_sec, server_per_cpu_sec, server_faults, server_cache, cache_hit_rate, workers, streams_per_worker, suite_id, process_cost_us, account_churn, db_target, server_log_errors, server_log_warnings, server_log_lines, verified, verification, streams_established, json_encoder, measure_window, measured_window, full_throughput, full_p50_ms, full_p99_ms, gc_pauses, gc_pause_ms, gc_tail_fraction, live_stream, chaos_disconnect_ms, reconnects, reconnect_p50_ms, reconnect_p99_ms, server_middleware, auth, server_rate_limit, backoff, chaos_restart, restart_errors, restart_recovery_ms, agents, concurrent_with, client_commit, server_commit, go_version, gomaxprocs, kernel, hostname, tags, environment, goroutines_peak, heap_objects_peak, heap_mb_peak, gc_cycles, cache_bust, injected_headers, injected_header_bytes, wire_bytes_sent, wire_bytes_recv, payload_bytes_sent, payload_bytes_recv, message_p50_bytes, message_p99_bytes, message_max_bytes, rest_stream_format, run_config, requests, successful_requests, latency_p50_ms, latency_p90_ms, latency_p99_ms, latency_avg_ms, latency_min_ms, latency_max_ms, created_at)
+		 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22, $23, $24, $25, $26, $27, $28, $29, $30, $31, $32, $33, $34, $35, $36, $37, $38, $39, $40, $41, $42, $43, $44, $45, $46, $47, $48, $49, $50, $51, $52, $53, $54, $55, $56, $57, $58, $59, $60, $61, $62, $63, $64, $65, $66, $67, $68, $69, $70, $71, $72, $73, $74, $75, $76, $77, $78, $79, $80, $81, $82, $83, $84, $85, $86, $87, $88, $89, $90, $91, $92, $93, $94, $95, $96, $97, $98, $99, $100, $101, $102, $103, $104, $105, COALESCE($106, NOW()))
 		 RETURNING id`,
 		run.Scenario, run.Protocol, client, run.Concurrency, run.DurationSec, run.RateLimit,
-		run.CPUUsageAvg, run.MemoryMBAvg, run.MemoryMBPeak,
+		run.CPUUsageAvg, run.MemoryMBAvg, run.MemoryMBPeak, run.LogPath, run.LatencyMetric, run.ComparisonID, run.PayloadSize, run.Compression, run.LoadProfile,
+		run.DatasetHash, run.DatasetFingerprint, run.NetBytesSent, run.NetBytesRecv, run.NetInterfaces, run.Connection, run.StreamShortfall, run.DatasetSize, run.ServerQoS, run.ServerPools, run.WriteRatio, run.OperationMix, run.ServerQueryTx, run.Cost, run.CostPerMillion, run.CostModel, run.ClientCPUSeconds, run.ServerCPUSeconds, run.ClientPerCPUSec, run.ServerPerCPUSec, run.ServerFaults, run.ServerCache, run.CacheHitRate, run.Workers, run.StreamsPerWorker, run.SuiteID, run.ProcessCostUs, run.AccountChurn, run.DBTarget, run.ServerLogErrors, run.ServerLogWarnings, run.ServerLogLines, run.Verified, run.Verification, run.StreamsEstablished, run.JSONEncoder, run.MeasureWindow, run.MeasuredWindow, run.FullThroughput, run.FullP50Ms, run.FullP99Ms, run.GCPauses, run.GCPauseMs, run.GCTailFraction, run.LiveStream, run.ChaosDisconnectMs, run.Reconnects, run.ReconnectP50Ms, run.ReconnectP99Ms, run.ServerMiddleware, run.Auth, run.ServerRateLimit, run.Backoff, run.ChaosRestart, run.RestartErrors, run.RestartRecoveryMs, run.Agents, run.ConcurrentWith, run.ClientCommit, run.ServerCommit, run.GoVersion, run.GOMAXPROCS, run.Kernel, run.Hostname, run.Tags, run.Environment, run.GoroutinesPeak, run.HeapObjectsPeak, run.HeapMBPeak, run.GCCycles, run.CacheBust, run.InjectedHeaders, run.InjectedHeaderBytes, run.WireBytesSent, run.WireBytesRecv, run.PayloadBytesSent, run.PayloadBytesRecv, run.MessageP50Bytes, run.MessageP99Bytes, run.MessageMaxBytes, run.RESTStreamFormat, run.RunConfig, run.Requests, run.SuccessfulRequests, run.LatencyP50Ms, run.LatencyP90Ms, run.LatencyP99Ms, run.LatencyAvgMs, run.LatencyMinMs, run.LatencyMaxMs, createdAt,
 	).Scan(&id)
 
 	if err != nil {
@@ -87,12 +720,26 @@ func (db *DB) RecordRun(ctx context.Context, run *BenchmarkRun) (int64, error) {
 	return id, nil
 }
 
+// DeleteRun deletes a benchmark run and, by cascade, its samples,
+// timeseries and experiment memberships, or returns ErrRunNotFound. Runs
+// that used it as their baseline keep their deltas.
+func (db *DB) DeleteRun(ctx context.Context, runID int64) error {
+	tag, err := db.Pool.Exec(ctx, `DELETE FROM benchmark_runs WHERE id = $1`, runID)
+	if err != nil {
+		return fmt.Errorf("failed to delete benchmark run: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrRunNotFound
+	}
+	return nil
+}
+
 // RecordSample records a single latency sample for a benchmark run.
 func (db *DB) RecordSample(ctx context.Context, sample *BenchmarkSample) error {
 	_, err := db.Pool.Exec(ctx,
-		`INSERT INTO benchmark_samples (run_id, latency_ms, success, error_type, timestamp)
-		 VALUES ($1, $2, $3, $4, $5)`,
-		sample.RunID, sample.LatencyMs, sample.Success, sample.ErrorType, sample.Timestamp,
+		`INSERT INTO benchmark_samples (run_id, latency_ms, success, error_type, timestamp, staleness_ms, phase, workload_class, operation, db_ms, gc_pause)
+		 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)`,
+		sample.RunID, sample.LatencyMs, sample.Success, sample.ErrorType, sample.Timestamp, sample.StalenessMs, sample.Phase, sample.WorkloadClass, sample.Operation, sample.DBMs, sample.GCPause,
 	)
 
 	if err != nil {
@@ -117,6 +764,12 @@ func (db *DB) RecordSamples(ctx context.Context, samples []*BenchmarkSample) err
 			sample.Success,
 			sample.ErrorType,
 			sample.Timestamp,
+			sample.StalenessMs,
+			sample.Phase,
+			sample.WorkloadClass,
+			sample.Operation,
+			sample.DBMs,
+			sample.GCPause,
 		}
 	}
 
@@ -124,7 +777,7 @@ func (db *DB) RecordSamples(ctx context.Context, samples []*BenchmarkSample) err
 	copied, err := db.Pool.CopyFrom(
 		ctx,
 		pgx.Identifier{"benchmark_samples"},
-		[]string{"run_id", "latency_ms", "success", "error_type", "timestamp"},
+		[]string{"run_id", "latency_ms", "success", "error_type", "timestamp", "staleness_ms", "phase", "workload_class", "operation", "db_ms", "gc_pause"},
 		pgx.CopyFromRows(rows),
 	)
 	if err != nil {
@@ -140,37 +793,23 @@ func (db *DB) RecordSamples(ctx context.Context, samples []*BenchmarkSample) err
 
 // GetStats retrieves aggregated statistics for a benchmark run.
 func (db *DB) GetStats(ctx context.Context, runID int64) (*BenchmarkStats, error) {
-	var stats BenchmarkStats
-	err := db.Pool.QueryRow(ctx,
-		`SELECT run_id, scenario, protocol, client, concurrency, duration_sec,
-		        total_samples, successful,
-		        p50_latency, p90_latency, p99_latency, avg_latency, min_latency, max_latency,
-		        cpu_usage_avg, memory_mb_avg, memory_mb_peak
+	stats, err := scanStats(db.Pool.QueryRow(ctx,
+		`SELECT `+statsColumns+`
 		 FROM benchmark_stats
 		 WHERE run_id = $1`,
 		runID,
-	).Scan(
-		&stats.RunID, &stats.Scenario, &stats.Protocol, &stats.Client, &stats.Concurrency,
-		&stats.DurationSec, &stats.TotalSamples, &stats.Successful,
-		&stats.P50Latency, &stats.P90Latency, &stats.P99Latency,
-		&stats.AvgLatency, &stats.MinLatency, &stats.MaxLatency,
-		&stats.CPUUsageAvg, &stats.MemoryMBAvg, &stats.MemoryMBPeak,
-	)
-
+	))
 	if err != nil {
 		return nil, fmt.Errorf("failed to get benchmark stats: %w", err)
 	}
 
-	return &stats, nil
+	return stats, nil
 }
 
 // GetAllStats retrieves stats for all benchmark runs.
 func (db *DB) GetAllStats(ctx context.Context) ([]*BenchmarkStats, error) {
 	rows, err := db.Pool.Query(ctx,
-		`SELECT run_id, scenario, protocol, client, concurrency, duration_sec,
-		        total_samples, successful,
-		        p50_latency, p90_latency, p99_latency, avg_latency, min_latency, max_latency,
-		        cpu_usage_avg, memory_mb_avg, memory_mb_peak
+		`SELECT `+statsColumns+`
 		 FROM benchmark_stats
 		 ORDER BY run_id DESC`,
 	)
@@ -181,17 +820,11 @@ func (db *DB) GetAllStats(ctx context.Context) ([]*BenchmarkStats, error) {
 
 	var allStats []*BenchmarkStats
 	for rows.Next() {
-		var stats BenchmarkStats
-		if err := rows.Scan(
-			&stats.RunID, &stats.Scenario, &stats.Protocol, &stats.Client, &stats.Concurrency,
-			&stats.DurationSec, &stats.TotalSamples, &stats.Successful,
-			&stats.P50Latency, &stats.P90Latency, &stats.P99Latency,
-			&stats.AvgLatency, &stats.MinLatency, &stats.MaxLatency,
-			&stats.CPUUsageAvg, &stats.MemoryMBAvg, &stats.MemoryMBPeak,
-		); err != nil {
+		stats, err := scanStats(rows)
+		if err != nil {
 			return nil, fmt.Errorf("failed to scan stats row: %w", err)
 		}
-		allStats = append(allStats, &stats)
+		allStats = append(allStats, stats)
 	}
 
 	if err := rows.Err(); err != nil {
@@ -201,70 +834,291 @@ func (db *DB) GetAllStats(ctx context.Context) ([]*BenchmarkStats, error) {
 	return allStats, nil
 }
 
-// GetFilteredStats retrieves stats with optional filtering.
+// GetFilteredStats retrieves stats with optional filtering, sorted and
+// paged as the filter says.
 func (db *DB) GetFilteredStats(ctx context.Context, filter StatsFilter) ([]*BenchmarkStats, error) {
-	query := `SELECT run_id, scenario, protocol, client, concurrency, duration_sec,
-	                 total_samples, successful,
-	                 p50_latency, p90_latency, p99_latency, avg_latency, min_latency, max_latency,
-	                 cpu_usage_avg, memory_mb_avg, memory_mb_peak
-	          FROM benchmark_stats
-	          WHERE 1=1`
+	if err := filter.Validate(); err != nil {
+		return nil, err
+	}
+	clauses, args := filter.clauses("run_id")
+	query := `SELECT ` + statsColumns + `
+	          FROM benchmark_stats` + clauses
 
-	args := []interface{}{}
-	argIdx := 1
+	rows, err := db.Pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query filtered stats: %w", err)
+	}
+	defer rows.Close()
 
-	if filter.RunID != nil {
-		query += fmt.Sprintf(" AND run_id = $%d", argIdx)
-		args = append(args, *filter.RunID)
-		argIdx++
+	var allStats []*BenchmarkStats
+	for rows.Next() {
+		stats, err := scanStats(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan stats row: %w", err)
+		}
+		allStats = append(allStats, stats)
 	}
-	if filter.Scenario != "" {
-		query += fmt.Sprintf(" AND scenario = $%d", argIdx)
-		args = append(args, filter.Scenario)
-		argIdx++
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating stats rows: %w", err)
 	}
-	if filter.Protocol != "" {
-		query += fmt.Sprintf(" AND protocol = $%d", argIdx)
-		args = append(args, filter.Protocol)
-		argIdx++
+
+	return allStats, nil
+}
+
+// ConcurrencyStats averages the runs of one configuration at one
+// concurrency level.
+type ConcurrencyStats struct {
+	Scenario    string
+	Protocol    string
+	Client      string
+	Concurrency int
+	Runs        int64
+	Throughput  float64 // requests per second
+	P50Latency  float64
+	P99Latency  float64
+}
+
+// GetConcurrencyStats averages the runs matching the filter per scenario,
+// protocol, client and concurrency, ordered by concurrency within each
+// configuration. Runs with a load profile are left out because their
+// concurrency is not fixed. The filter's Limit is ignored.
+func (db *DB) GetConcurrencyStats(ctx context.Context, filter StatsFilter) ([]*ConcurrencyStats, error) {
+	where, args := filter.where("run_id")
+	query := `SELECT scenario, protocol, client, concurrency, COUNT(*),
+	                 COALESCE(AVG(total_samples::float8 / NULLIF(duration_sec, 0)), 0),
+	                 COALESCE(AVG(p50_latency), 0), COALESCE(AVG(p99_latency), 0)
+	          FROM benchmark_stats` + where + ` AND load_profile IS NULL
+	          GROUP BY scenario, protocol, client, concurrency
+	          ORDER BY scenario, protocol, client, concurrency`
+
+	rows, err := db.Pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query concurrency stats: %w", err)
 	}
-	if filter.Client != "" {
-		query += fmt.Sprintf(" AND client = $%d", argIdx)
-		args = append(args, filter.Client)
-		argIdx++
+	defer rows.Close()
+
+	var groups []*ConcurrencyStats
+	for rows.Next() {
+		var g ConcurrencyStats
+		if err := rows.Scan(&g.Scenario, &g.Protocol, &g.Client, &g.Concurrency, &g.Runs,
+			&g.Throughput, &g.P50Latency, &g.P99Latency); err != nil {
+			return nil, fmt.Errorf("failed to scan concurrency stats row: %w", err)
+		}
+		groups = append(groups, &g)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating concurrency stats rows: %w", err)
+	}
+
+	return groups, nil
+}
+
+// GetPhaseStats retrieves per-phase stats for a run with a load profile,
+// ordered by phase. The result is empty for runs without one.
+func (db *DB) GetPhaseStats(ctx context.Context, runID int64) ([]*PhaseStats, error) {
+	rows, err := db.Pool.Query(ctx,
+		`SELECT run_id, phase, total_samples, successful, p50_latency, p99_latency, started_at, ended_at
+		 FROM benchmark_phase_stats
+		 WHERE run_id = $1
+		 ORDER BY phase`,
+		runID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query phase stats: %w", err)
 	}
+	defer rows.Close()
 
-	query += " ORDER BY run_id DESC"
+	var phases []*PhaseStats
+	for rows.Next() {
+		var p PhaseStats
+		if err := rows.Scan(&p.RunID, &p.Phase, &p.TotalSamples, &p.Successful,
+			&p.P50Latency, &p.P99Latency, &p.StartedAt, &p.EndedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan phase stats row: %w", err)
+		}
+		phases = append(phases, &p)
+	}
 
-	if filter.Limit > 0 {
-		query += fmt.Sprintf(" LIMIT $%d", argIdx)
-		args = append(args, filter.Limit)
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating phase stats rows: %w", err)
 	}
 
-	rows, err := db.Pool.Query(ctx, query, args...)
+	return phases, nil
+}
+
+// GetClassStats retrieves per-workload-class stats for a run that mixed
+// workloads, ordered by class. The result is empty for other runs.
+func (db *DB) GetClassStats(ctx context.Context, runID int64) ([]*ClassStats, error) {
+	rows, err := db.Pool.Query(ctx,
+		`SELECT run_id, workload_class, total_samples, successful, p50_latency, p99_latency, started_at, ended_at
+		 FROM benchmark_class_stats
+		 WHERE run_id = $1
+		 ORDER BY workload_class`,
+		runID,
+	)
 	if err != nil {
-		return nil, fmt.Errorf("failed to query filtered stats: %w", err)
+		return nil, fmt.Errorf("failed to query class stats: %w", err)
 	}
 	defer rows.Close()
 
-	var allStats []*BenchmarkStats
+	var classes []*ClassStats
 	for rows.Next() {
-		var stats BenchmarkStats
-		if err := rows.Scan(
-			&stats.RunID, &stats.Scenario, &stats.Protocol, &stats.Client, &stats.Concurrency,
-			&stats.DurationSec, &stats.TotalSamples, &stats.Successful,
-			&stats.P50Latency, &stats.P90Latency, &stats.P99Latency,
-			&stats.AvgLatency, &stats.MinLatency, &stats.MaxLatency,
-			&stats.CPUUsageAvg, &stats.MemoryMBAvg, &stats.MemoryMBPeak,
-		); err != nil {
-			return nil, fmt.Errorf("failed to scan stats row: %w", err)
+		var c ClassStats
+		if err := rows.Scan(&c.RunID, &c.Class, &c.TotalSamples, &c.Successful,
+			&c.P50Latency, &c.P99Latency, &c.StartedAt, &c.EndedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan class stats row: %w", err)
 		}
-		allStats = append(allStats, &stats)
+		classes = append(classes, &c)
 	}
 
 	if err := rows.Err(); err != nil {
-		return nil, fmt.Errorf("error iterating stats rows: %w", err)
+		return nil, fmt.Errorf("error iterating class stats rows: %w", err)
 	}
 
-	return allStats, nil
+	return classes, nil
+}
+
+// GetOperationStats retrieves per-operation stats for a run, ordered by
+// operation. The result is empty for runs stored without operations.
+func (db *DB) GetOperationStats(ctx context.Context, runID int64) ([]*OperationStats, error) {
+	rows, err := db.Pool.Query(ctx,
+		`SELECT run_id, operation, total_samples, successful, p50_latency, p99_latency, started_at, ended_at
+		 FROM benchmark_operation_stats
+		 WHERE run_id = $1
+		 ORDER BY operation`,
+		runID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query operation stats: %w", err)
+	}
+	defer rows.Close()
+
+	var ops []*OperationStats
+	for rows.Next() {
+		var o OperationStats
+		if err := rows.Scan(&o.RunID, &o.Operation, &o.TotalSamples, &o.Successful,
+			&o.P50Latency, &o.P99Latency, &o.StartedAt, &o.EndedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan operation stats row: %w", err)
+		}
+		ops = append(ops, &o)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating operation stats rows: %w", err)
+	}
+
+	return ops, nil
+}
+
+// GetErrorCounts retrieves the failed samples of runs counted per error
+// type, ordered by run and most frequent type first. Failed samples stored
+// without an error type are counted as "unknown".
+func (db *DB) GetErrorCounts(ctx context.Context, runIDs []int64) ([]*ErrorCount, error) {
+	rows, err := db.Pool.Query(ctx,
+		`SELECT run_id, COALESCE(error_type, 'unknown') AS error_type, COUNT(*) AS count
+		 FROM benchmark_samples
+		 WHERE run_id = ANY($1) AND NOT success
+		 GROUP BY run_id, 2
+		 ORDER BY run_id, count DESC, error_type`,
+		runIDs,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query error counts: %w", err)
+	}
+	defer rows.Close()
+
+	var counts []*ErrorCount
+	for rows.Next() {
+		var c ErrorCount
+		if err := rows.Scan(&c.RunID, &c.ErrorType, &c.Count); err != nil {
+			return nil, fmt.Errorf("failed to scan error count row: %w", err)
+		}
+		counts = append(counts, &c)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating error count rows: %w", err)
+	}
+
+	return counts, nil
+}
+
+// GetRuns retrieves benchmark run records matching filter, newest first
+// unless sorted ascending.
+// Unlike GetFilteredStats it reads benchmark_runs directly, without
+// aggregating samples.
+func (db *DB) GetRuns(ctx context.Context, filter StatsFilter) ([]*BenchmarkRun, error) {
+	if err := filter.validateRuns(); err != nil {
+		return nil, err
+	}
+	clauses, args := filter.clauses("id")
+	rows, err := db.Pool.Query(ctx,
+		`SELECT id, scenario, protocol, client, concurrency, duration_sec, rate_limit, created_at,
+		        cpu_usage_avg, memory_mb_avg, memory_mb_peak, log_path, latency_metric,
+		        comparison_id, payload_size, compression, load_profile, dataset_hash, dataset_fingerprint,
+		        net_bytes_sent, net_bytes_recv, net_interfaces, connection, stream_shortfall, dataset_size, server_qos, server_pools, write_ratio, operation_mix, server_query_tx, cost, cost_per_million, cost_model, client_cpu_seconds, server_cpu_seconds, client_per_cpu_sec, server_per_cpu_sec, server_faults, server_cache, cache_hit_rate, workers, streams_per_worker, suite_id, process_cost_us, account_churn, db_target, server_log_errors, server_log_warnings, server_log_lines, verified, verification, streams_established, json_encoder, measure_window, measured_window, full_throughput, full_p50_ms, full_p99_ms, gc_pauses, gc_pause_ms, gc_tail_fraction, live_stream, chaos_disconnect_ms, reconnects, reconnect_p50_ms, reconnect_p99_ms, server_middleware, auth, server_rate_limit, backoff, chaos_restart, restart_errors, restart_recovery_ms, agents, concurrent_with, client_commit, server_commit, go_version, gomaxprocs, kernel, hostname, tags, environment, goroutines_peak, heap_objects_peak, heap_mb_peak, gc_cycles, cache_bust, injected_headers, injected_header_bytes, wire_bytes_sent, wire_bytes_recv, payload_bytes_sent, payload_bytes_recv, message_p50_bytes, message_p99_bytes, message_max_bytes, rest_stream_format, run_config, requests, successful_requests, latency_p50_ms, latency_p90_ms, latency_p99_ms, latency_avg_ms, latency_min_ms, latency_max_ms
+		 FROM benchmark_runs`+clauses,
+		args...,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query benchmark runs: %w", err)
+	}
+	defer rows.Close()
+
+	var runs []*BenchmarkRun
+	for rows.Next() {
+		var r BenchmarkRun
+		err := rows.Scan(
+			&r.ID, &r.Scenario, &r.Protocol, &r.Client, &r.Concurrency, &r.DurationSec, &r.RateLimit, &r.CreatedAt,
+			&r.CPUUsageAvg, &r.MemoryMBAvg, &r.MemoryMBPeak, &r.LogPath, &r.LatencyMetric,
+			&r.ComparisonID, &r.PayloadSize, &r.Compression, &r.LoadProfile, &r.DatasetHash, &r.DatasetFingerprint,
+			&r.NetBytesSent, &r.NetBytesRecv, &r.NetInterfaces, &r.Connection, &r.StreamShortfall, &r.DatasetSize, &r.ServerQoS, &r.ServerPools, &r.WriteRatio, &r.OperationMix, &r.ServerQueryTx, &r.Cost, &r.CostPerMillion, &r.CostModel, &r.ClientCPUSeconds, &r.ServerCPUSeconds, &r.ClientPerCPUSec, &r.ServerPerCPUSec, &r.ServerFaults, &r.ServerCache, &r.CacheHitRate, &r.Workers, &r.StreamsPerWorker, &r.SuiteID, &r.ProcessCostUs, &r.AccountChurn, &r.DBTarget, &r.ServerLogErrors, &r.ServerLogWarnings, &r.ServerLogLines, &r.Verified, &r.Verification, &r.StreamsEstablished, &r.JSONEncoder, &r.MeasureWindow, &r.MeasuredWindow, &r.FullThroughput, &r.FullP50Ms, &r.FullP99Ms, &r.GCPauses, &r.GCPauseMs, &r.GCTailFraction, &r.LiveStream, &r.ChaosDisconnectMs, &r.Reconnects, &r.ReconnectP50Ms, &r.ReconnectP99Ms, &r.ServerMiddleware, &r.Auth, &r.ServerRateLimit, &r.Backoff, &r.ChaosRestart, &r.RestartErrors, &r.RestartRecoveryMs, &r.Agents, &r.ConcurrentWith, &r.ClientCommit, &r.ServerCommit, &r.GoVersion, &r.GOMAXPROCS, &r.Kernel, &r.Hostname, &r.Tags, &r.Environment, &r.GoroutinesPeak, &r.HeapObjectsPeak, &r.HeapMBPeak, &r.GCCycles, &r.CacheBust, &r.InjectedHeaders, &r.InjectedHeaderBytes, &r.WireBytesSent, &r.WireBytesRecv, &r.PayloadBytesSent, &r.PayloadBytesRecv, &r.MessageP50Bytes, &r.MessageP99Bytes, &r.MessageMaxBytes, &r.RESTStreamFormat, &r.RunConfig, &r.Requests, &r.SuccessfulRequests, &r.LatencyP50Ms, &r.LatencyP90Ms, &r.LatencyP99Ms, &r.LatencyAvgMs, &r.LatencyMinMs, &r.LatencyMaxMs,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan run row: %w", err)
+		}
+		runs = append(runs, &r)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating run rows: %w", err)
+	}
+
+	return runs, nil
+}
+
+// ForEachSample calls fn for every sample of the given runs, ordered by run
+// and insertion. Rows are streamed rather than loaded at once, so runs with
+// millions of samples can be read in constant memory. The sample passed to
+// fn is reused between calls.
+func (db *DB) ForEachSample(ctx context.Context, runIDs []int64, fn func(*BenchmarkSample) error) error {
+	rows, err := db.Pool.Query(ctx,
+		`SELECT id, run_id, latency_ms, success, error_type, timestamp, staleness_ms, phase, workload_class, operation, db_ms, gc_pause
+		 FROM benchmark_samples
+		 WHERE run_id = ANY($1)
+		 ORDER BY run_id, id`,
+		runIDs,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to query benchmark samples: %w", err)
+	}
+	defer rows.Close()
+
+	var s BenchmarkSample
+	for rows.Next() {
+		err := rows.Scan(&s.ID, &s.RunID, &s.LatencyMs, &s.Success, &s.ErrorType, &s.Timestamp, &s.StalenessMs, &s.Phase, &s.WorkloadClass, &s.Operation, &s.DBMs, &s.GCPause)
+		if err != nil {
+			return fmt.Errorf("failed to scan sample row: %w", err)
+		}
+		if err := fn(&s); err != nil {
+			return err
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("error iterating sample rows: %w", err)
+	}
+
+	return nil
 }
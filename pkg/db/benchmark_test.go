@@ -6,6 +6,23 @@ import (
 	"time"
 )
 
+func TestParseCursor(t *testing.T) {
+	for _, c := range []Cursor{{RunID: 42}, {Value: 12.345678901234567, RunID: 7}, {Value: -1e-9, RunID: 1}} {
+		got, err := ParseCursor(c.String())
+		if err != nil {
+			t.Fatalf("ParseCursor(%q) error = %v", c.String(), err)
+		}
+		if *got != c {
+			t.Errorf("ParseCursor(%q) = %+v, want %+v", c.String(), *got, c)
+		}
+	}
+	for _, s := range []string{"", "42", "x_1", "1_x"} {
+		if _, err := ParseCursor(s); err == nil {
+			t.Errorf("ParseCursor(%q) = nil error, want an error", s)
+		}
+	}
+}
+
 func TestRecordRun(t *testing.T) {
 	db := testDB(t)
 	defer db.Close()
@@ -357,3 +374,54 @@ func TestGetFilteredStats(t *testing.T) {
 	_, _ = db.Pool.Exec(ctx, "DELETE FROM benchmark_runs WHERE id = $1", runID1)
 	_, _ = db.Pool.Exec(ctx, "DELETE FROM benchmark_runs WHERE id = $1", runID2)
 }
+
+func TestGetConcurrencyStats(t *testing.T) {
+	db := testDB(t)
+	defer db.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	const client = "go-test-concurrency"
+	now := time.Now()
+	for _, concurrency := range []int{10, 10, 50} {
+		runID, err := db.RecordRun(ctx, &BenchmarkRun{
+			Scenario:    "balance",
+			Protocol:    "grpc",
+			Client:      client,
+			Concurrency: concurrency,
+			DurationSec: 5,
+		})
+		if err != nil {
+			t.Fatalf("RecordRun() error = %v", err)
+		}
+		defer db.DeleteRun(ctx, runID)
+
+		samples := make([]*BenchmarkSample, 10)
+		for i := range samples {
+			samples[i] = &BenchmarkSample{
+				RunID:     runID,
+				LatencyMs: float64(concurrency),
+				Success:   true,
+				Timestamp: now.Add(time.Duration(i) * time.Millisecond),
+			}
+		}
+		if err := db.RecordSamples(ctx, samples); err != nil {
+			t.Fatalf("RecordSamples() error = %v", err)
+		}
+	}
+
+	groups, err := db.GetConcurrencyStats(ctx, StatsFilter{Client: client})
+	if err != nil {
+		t.Fatalf("GetConcurrencyStats() error = %v", err)
+	}
+	if len(groups) != 2 {
+		t.Fatalf("GetConcurrencyStats() returned %d groups, want 2", len(groups))
+	}
+	if g := groups[0]; g.Concurrency != 10 || g.Runs != 2 || g.Throughput != 2 || g.P99Latency != 10 {
+		t.Errorf("concurrency 10 = %+v, want 2 runs at 2 req/s and 10ms", g)
+	}
+	if g := groups[1]; g.Concurrency != 50 || g.Runs != 1 || g.P50Latency != 50 {
+		t.Errorf("concurrency 50 = %+v, want 1 run at 50ms", g)
+	}
+}
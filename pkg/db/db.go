@@ -5,12 +5,17 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/kaldun-tech/grpc-rest-benchmark/pkg/servertiming"
 )
 
 // DB wraps a PostgreSQL connection pool.
 type DB struct {
 	Pool *pgxpool.Pool
+
+	queryTx *pgx.TxOptions // transaction for balance queries, nil for none
 }
 
 // Config holds database connection parameters.
@@ -32,6 +37,11 @@ type Config struct {
 	RetryInterval time.Duration // Initial retry interval (default: 100ms)
 }
 
+// PasswordEnv is the environment variable the benchmark client reads the
+// password from when --db-pass is not given, keeping it off the command
+// line of the processes that launch it.
+const PasswordEnv = "PGPASSWORD"
+
 // DefaultConfig returns the default config for local development.
 func DefaultConfig() Config {
 	return Config{
@@ -93,6 +103,8 @@ func New(ctx context.Context, cfg Config) (*DB, error) {
 	poolCfg.MinConns = cfg.MinConns
 	poolCfg.MaxConnLifetime = cfg.MaxConnLifetime
 	poolCfg.MaxConnIdleTime = cfg.MaxConnIdleTime
+	// Queries of server requests are timed for their Server-Timing report
+	poolCfg.ConnConfig.Tracer = servertiming.QueryTracer{}
 
 	// Retry loop with exponential backoff
 	var pool *pgxpool.Pool
@@ -122,6 +134,7 @@ func New(ctx context.Context, cfg Config) (*DB, error) {
 			continue
 		}
 
+		trackPool(pool)
 		return &DB{Pool: pool}, nil
 	}
 
@@ -130,5 +143,6 @@ func New(ctx context.Context, cfg Config) (*DB, error) {
 
 // Close closes the connection pool.
 func (db *DB) Close() {
+	untrackPool(db.Pool)
 	db.Pool.Close()
 }
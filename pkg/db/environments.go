@@ -0,0 +1,160 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+var (
+	ErrEnvironmentNotFound = errors.New("environment not found")
+	ErrEnvironmentExists   = errors.New("environment already exists")
+)
+
+const maxEnvironmentNotes = 2000
+
+// environmentNamePattern restricts environment names to what reads well in
+// run records, flags and URLs.
+var environmentNamePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]{0,62}$`)
+
+// Environment is a registered target environment: the servers a run
+// measures, named so runs can select it with --environment instead of
+// retyping addresses, and be filtered by it.
+type Environment struct {
+	Name        string
+	GRPCAddr    string // e.g. "staging:50051", "" if the environment has no gRPC server
+	RESTAddr    string // e.g. "http://staging:8080"
+	GRPCWebAddr string
+	Database    string // the servers' database, e.g. "PostgreSQL 16, db.r6g.xlarge"
+	Hardware    string // notes on the server hardware
+
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// Validate checks the name, that at least one server address is set and
+// the length of the notes.
+func (e *Environment) Validate() error {
+	if !environmentNamePattern.MatchString(e.Name) {
+		return fmt.Errorf("invalid environment name %q (letters, digits, '.', '_' and '-', at most 63)", e.Name)
+	}
+	if e.GRPCAddr == "" && e.RESTAddr == "" && e.GRPCWebAddr == "" {
+		return fmt.Errorf("environment %s needs at least one server address", e.Name)
+	}
+	if len(e.Database) > maxEnvironmentNotes || len(e.Hardware) > maxEnvironmentNotes {
+		return fmt.Errorf("environment database and hardware notes must be at most %d bytes", maxEnvironmentNotes)
+	}
+	return nil
+}
+
+const environmentColumns = `name, grpc_addr, rest_addr, grpc_web_addr, database, hardware, created_at, updated_at`
+
+func scanEnvironment(row pgx.Row) (*Environment, error) {
+	var e Environment
+	if err := row.Scan(&e.Name, &e.GRPCAddr, &e.RESTAddr, &e.GRPCWebAddr, &e.Database, &e.Hardware, &e.CreatedAt, &e.UpdatedAt); err != nil {
+		return nil, err
+	}
+	return &e, nil
+}
+
+// CreateEnvironment registers an environment, or returns
+// ErrEnvironmentExists if the name is taken.
+func (db *DB) CreateEnvironment(ctx context.Context, e *Environment) error {
+	if err := e.Validate(); err != nil {
+		return err
+	}
+	created, err := scanEnvironment(db.Pool.QueryRow(ctx,
+		`INSERT INTO environments (name, grpc_addr, rest_addr, grpc_web_addr, database, hardware)
+		 VALUES ($1, $2, $3, $4, $5, $6)
+		 RETURNING `+environmentColumns,
+		e.Name, e.GRPCAddr, e.RESTAddr, e.GRPCWebAddr, e.Database, e.Hardware,
+	))
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+		return ErrEnvironmentExists
+	}
+	if err != nil {
+		return fmt.Errorf("failed to create environment: %w", err)
+	}
+	*e = *created
+	return nil
+}
+
+// UpdateEnvironment replaces the addresses and notes of a registered
+// environment, or returns ErrEnvironmentNotFound.
+func (db *DB) UpdateEnvironment(ctx context.Context, e *Environment) error {
+	if err := e.Validate(); err != nil {
+		return err
+	}
+	updated, err := scanEnvironment(db.Pool.QueryRow(ctx,
+		`UPDATE environments
+		 SET grpc_addr = $2, rest_addr = $3, grpc_web_addr = $4, database = $5, hardware = $6, updated_at = NOW()
+		 WHERE name = $1
+		 RETURNING `+environmentColumns,
+		e.Name, e.GRPCAddr, e.RESTAddr, e.GRPCWebAddr, e.Database, e.Hardware,
+	))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return ErrEnvironmentNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("failed to update environment: %w", err)
+	}
+	*e = *updated
+	return nil
+}
+
+// GetEnvironment retrieves a registered environment, or
+// ErrEnvironmentNotFound.
+func (db *DB) GetEnvironment(ctx context.Context, name string) (*Environment, error) {
+	e, err := scanEnvironment(db.Pool.QueryRow(ctx, `SELECT `+environmentColumns+` FROM environments WHERE name = $1`, name))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrEnvironmentNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get environment: %w", err)
+	}
+	return e, nil
+}
+
+// ListEnvironments retrieves the registered environments ordered by name.
+func (db *DB) ListEnvironments(ctx context.Context) ([]*Environment, error) {
+	rows, err := db.Pool.Query(ctx, `SELECT `+environmentColumns+` FROM environments ORDER BY name`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query environments: %w", err)
+	}
+	defer rows.Close()
+
+	var environments []*Environment
+	for rows.Next() {
+		e, err := scanEnvironment(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan environment row: %w", err)
+		}
+		environments = append(environments, e)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating environment rows: %w", err)
+	}
+
+	return environments, nil
+}
+
+// DeleteEnvironment unregisters an environment, or returns
+// ErrEnvironmentNotFound. Its runs keep their results and lose the
+// reference.
+func (db *DB) DeleteEnvironment(ctx context.Context, name string) error {
+	tag, err := db.Pool.Exec(ctx, `DELETE FROM environments WHERE name = $1`, name)
+	if err != nil {
+		return fmt.Errorf("failed to delete environment: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrEnvironmentNotFound
+	}
+	return nil
+}
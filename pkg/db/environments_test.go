@@ -0,0 +1,89 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEnvironment_Validate(t *testing.T) {
+	if err := (&Environment{Name: "staging-1", GRPCAddr: "staging:50051", Hardware: "c6i.2xlarge"}).Validate(); err != nil {
+		t.Errorf("Validate() error = %v", err)
+	}
+	for _, e := range []*Environment{
+		{Name: "", GRPCAddr: "staging:50051"},
+		{Name: "staging/1", GRPCAddr: "staging:50051"},
+		{Name: "staging"},
+		{Name: "staging", RESTAddr: "http://staging:8080", Hardware: strings.Repeat("x", maxEnvironmentNotes+1)},
+	} {
+		if err := e.Validate(); err == nil {
+			t.Errorf("Validate() of %+v = nil, want an error", e)
+		}
+	}
+}
+
+func TestEnvironments(t *testing.T) {
+	db := testDB(t)
+	defer db.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	e := &Environment{Name: "go-test-env", GRPCAddr: "staging:50051", RESTAddr: "http://staging:8080", Hardware: "4 vCPU"}
+	if err := db.CreateEnvironment(ctx, e); err != nil {
+		t.Fatalf("CreateEnvironment() error = %v", err)
+	}
+	defer db.DeleteEnvironment(ctx, e.Name)
+	if e.CreatedAt.IsZero() {
+		t.Errorf("CreateEnvironment() = %+v, want the stored environment", e)
+	}
+	if err := db.CreateEnvironment(ctx, &Environment{Name: e.Name, GRPCAddr: "other:50051"}); !errors.Is(err, ErrEnvironmentExists) {
+		t.Errorf("CreateEnvironment() twice error = %v, want ErrEnvironmentExists", err)
+	}
+
+	e.Hardware = "8 vCPU"
+	if err := db.UpdateEnvironment(ctx, e); err != nil {
+		t.Fatalf("UpdateEnvironment() error = %v", err)
+	}
+	got, err := db.GetEnvironment(ctx, e.Name)
+	if err != nil {
+		t.Fatalf("GetEnvironment() error = %v", err)
+	}
+	if got.GRPCAddr != "staging:50051" || got.Hardware != "8 vCPU" {
+		t.Errorf("GetEnvironment() = %+v, want the updated notes", got)
+	}
+
+	// Runs of the environment can be filtered by it, and keep their results
+	// when it is deleted
+	runID, err := db.RecordRun(ctx, &BenchmarkRun{Scenario: "balance", Protocol: "grpc", Client: "go-test-env", Concurrency: 1, DurationSec: 1, Environment: &e.Name})
+	if err != nil {
+		t.Fatalf("RecordRun() error = %v", err)
+	}
+	defer db.DeleteRun(ctx, runID)
+	stats, err := db.GetFilteredStats(ctx, StatsFilter{Environment: e.Name})
+	if err != nil {
+		t.Fatalf("GetFilteredStats() error = %v", err)
+	}
+	if len(stats) != 1 || stats[0].RunID != runID || stats[0].Environment == nil || *stats[0].Environment != e.Name {
+		t.Errorf("GetFilteredStats() = %+v, want run %d of the environment", stats, runID)
+	}
+
+	if err := db.DeleteEnvironment(ctx, e.Name); err != nil {
+		t.Fatalf("DeleteEnvironment() error = %v", err)
+	}
+	if _, err := db.GetEnvironment(ctx, e.Name); !errors.Is(err, ErrEnvironmentNotFound) {
+		t.Errorf("GetEnvironment() after delete error = %v, want ErrEnvironmentNotFound", err)
+	}
+	run, err := db.GetStats(ctx, runID)
+	if err != nil {
+		t.Fatalf("GetStats() error = %v", err)
+	}
+	if run.Environment != nil {
+		t.Errorf("GetStats().Environment = %q after delete, want nil", *run.Environment)
+	}
+	if err := db.UpdateEnvironment(ctx, e); !errors.Is(err, ErrEnvironmentNotFound) {
+		t.Errorf("UpdateEnvironment() after delete error = %v, want ErrEnvironmentNotFound", err)
+	}
+}
@@ -0,0 +1,101 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// EnvironmentBusyError is returned by LockEnvironment when another run
+// holds the environment.
+type EnvironmentBusyError struct {
+	Environment string
+	Holder      string    // empty if the holder did not record itself yet
+	Since       time.Time // zero with Holder
+}
+
+func (e *EnvironmentBusyError) Error() string {
+	if e.Holder == "" {
+		return fmt.Sprintf("environment %s is in use by another run", e.Environment)
+	}
+	return fmt.Sprintf("environment %s is in use by %s since %s", e.Environment, e.Holder, e.Since.Format("2006-01-02 15:04:05"))
+}
+
+// EnvironmentLock is a held environment lock. It is a PostgreSQL session
+// advisory lock, so it is released with its connection if the run dies.
+type EnvironmentLock struct {
+	Environment string
+	conn        *pgxpool.Conn
+	key         int64
+}
+
+// environmentKey maps an environment to its advisory lock key.
+func environmentKey(environment string) int64 {
+	h := fnv.New64a()
+	h.Write([]byte("benchmark-environment:" + environment))
+	return int64(h.Sum64())
+}
+
+// LockEnvironment takes the lock of a target environment for a run
+// described by holder, e.g. "host pid 1234", without waiting. It returns
+// an *EnvironmentBusyError if another run holds it.
+func (db *DB) LockEnvironment(ctx context.Context, environment, holder string) (*EnvironmentLock, error) {
+	conn, err := db.Pool.Acquire(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to acquire connection: %w", err)
+	}
+	key := environmentKey(environment)
+
+	var locked bool
+	if err := conn.QueryRow(ctx, `SELECT pg_try_advisory_lock($1)`, key).Scan(&locked); err != nil {
+		conn.Release()
+		return nil, fmt.Errorf("failed to lock environment: %w", err)
+	}
+	if !locked {
+		conn.Release()
+		busy := &EnvironmentBusyError{Environment: environment}
+		err := db.Pool.QueryRow(ctx,
+			`SELECT holder, acquired_at FROM environment_locks WHERE environment = $1`, environment,
+		).Scan(&busy.Holder, &busy.Since)
+		if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("failed to get environment lock holder: %w", err)
+		}
+		return nil, busy
+	}
+
+	// The row only names the holder; the advisory lock excludes other runs
+	_, err = conn.Exec(ctx,
+		`INSERT INTO environment_locks (environment, holder) VALUES ($1, $2)
+		 ON CONFLICT (environment) DO UPDATE SET holder = EXCLUDED.holder, acquired_at = NOW()`,
+		environment, holder,
+	)
+	if err != nil {
+		conn.Exec(ctx, `SELECT pg_advisory_unlock($1)`, key)
+		conn.Release()
+		return nil, fmt.Errorf("failed to record environment lock holder: %w", err)
+	}
+	return &EnvironmentLock{Environment: environment, conn: conn, key: key}, nil
+}
+
+// Unlock releases the lock. A nil *EnvironmentLock is a no-op.
+func (l *EnvironmentLock) Unlock(ctx context.Context) error {
+	if l == nil {
+		return nil
+	}
+	defer l.conn.Release()
+	if _, err := l.conn.Exec(ctx, `DELETE FROM environment_locks WHERE environment = $1`, l.Environment); err != nil {
+		// Closing the connection ends the session and its lock
+		l.conn.Conn().Close(ctx)
+		return fmt.Errorf("failed to unlock environment: %w", err)
+	}
+	if _, err := l.conn.Exec(ctx, `SELECT pg_advisory_unlock($1)`, l.key); err != nil {
+		l.conn.Conn().Close(ctx)
+		return fmt.Errorf("failed to unlock environment: %w", err)
+	}
+	return nil
+}
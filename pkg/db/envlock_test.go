@@ -0,0 +1,47 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestLockEnvironment(t *testing.T) {
+	db := testDB(t)
+	defer db.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	const environment = "go-test-environment"
+	lock, err := db.LockEnvironment(ctx, environment, "first pid 1")
+	if err != nil {
+		t.Fatalf("LockEnvironment() error = %v", err)
+	}
+
+	var busy *EnvironmentBusyError
+	if _, err := db.LockEnvironment(ctx, environment, "second pid 2"); !errors.As(err, &busy) {
+		t.Fatalf("LockEnvironment() while locked error = %v, want an EnvironmentBusyError", err)
+	}
+	if busy.Holder != "first pid 1" || busy.Since.IsZero() {
+		t.Errorf("EnvironmentBusyError = %+v, want the first holder", busy)
+	}
+
+	other, err := db.LockEnvironment(ctx, environment+"-other", "second pid 2")
+	if err != nil {
+		t.Fatalf("LockEnvironment() of another environment error = %v", err)
+	}
+	if err := other.Unlock(ctx); err != nil {
+		t.Fatalf("Unlock() error = %v", err)
+	}
+
+	if err := lock.Unlock(ctx); err != nil {
+		t.Fatalf("Unlock() error = %v", err)
+	}
+	again, err := db.LockEnvironment(ctx, environment, "second pid 2")
+	if err != nil {
+		t.Fatalf("LockEnvironment() after Unlock() error = %v", err)
+	}
+	again.Unlock(ctx)
+}
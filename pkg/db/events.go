@@ -0,0 +1,141 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// ErrEventNotFound is returned when a requested event is not stored.
+var ErrEventNotFound = errors.New("event not found")
+
+// Event kinds
+const (
+	EventDeploy = "deploy" // a server or client build was deployed
+	EventConfig = "config" // server, database or host configuration changed
+	EventReseed = "reseed" // the dataset was reseeded
+	EventNote   = "note"   // anything else worth marking on the history
+)
+
+// EventKinds lists the valid event kinds.
+var EventKinds = []string{EventDeploy, EventConfig, EventReseed, EventNote}
+
+// maxEventMessage is the longest event message accepted, in bytes.
+const maxEventMessage = 500
+
+// Event is an operational change, e.g. a deploy, config change or dataset
+// reseed, marked on trend charts to explain shifts in benchmark history.
+type Event struct {
+	ID         int64
+	Kind       string
+	Message    string
+	OccurredAt time.Time // when the change happened; zero for now
+	CreatedAt  time.Time
+}
+
+// Validate checks that the event has a known kind and a message of
+// reasonable length.
+func (e *Event) Validate() error {
+	if !slices.Contains(EventKinds, e.Kind) {
+		return fmt.Errorf("invalid event kind %q (must be one of: %s)", e.Kind, strings.Join(EventKinds, ", "))
+	}
+	message := strings.TrimSpace(e.Message)
+	if message == "" {
+		return fmt.Errorf("event message is required")
+	}
+	if len(message) > maxEventMessage {
+		return fmt.Errorf("event message must be at most %d bytes", maxEventMessage)
+	}
+	return nil
+}
+
+// EventFilter selects events. Zero fields match every event.
+type EventFilter struct {
+	Window time.Duration // how far back from now to look
+	Kind   string
+	Limit  int // newest events to return
+}
+
+// RecordEvent stores a new event, setting its ID and timestamps.
+// OccurredAt is taken from e when set, so past changes can be recorded
+// after the fact.
+func (db *DB) RecordEvent(ctx context.Context, e *Event) error {
+	if err := e.Validate(); err != nil {
+		return err
+	}
+	var occurredAt *time.Time
+	if !e.OccurredAt.IsZero() {
+		occurredAt = &e.OccurredAt
+	}
+	err := db.Pool.QueryRow(ctx,
+		`INSERT INTO events (kind, message, occurred_at)
+		 VALUES ($1, $2, COALESCE($3, NOW()))
+		 RETURNING id, occurred_at, created_at`,
+		e.Kind, strings.TrimSpace(e.Message), occurredAt,
+	).Scan(&e.ID, &e.OccurredAt, &e.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to record event: %w", err)
+	}
+	return nil
+}
+
+// ListEvents retrieves the events matching filter, oldest first.
+func (db *DB) ListEvents(ctx context.Context, filter EventFilter) ([]*Event, error) {
+	var conditions []string
+	var args []any
+	if filter.Window > 0 {
+		args = append(args, filter.Window.Seconds())
+		conditions = append(conditions, fmt.Sprintf("occurred_at >= NOW() - make_interval(secs => $%d::float8)", len(args)))
+	}
+	if filter.Kind != "" {
+		args = append(args, filter.Kind)
+		conditions = append(conditions, fmt.Sprintf("kind = $%d", len(args)))
+	}
+	query := `SELECT id, kind, message, occurred_at, created_at FROM events`
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	query += " ORDER BY occurred_at DESC, id DESC"
+	if filter.Limit > 0 {
+		args = append(args, filter.Limit)
+		query += fmt.Sprintf(" LIMIT $%d", len(args))
+	}
+
+	rows, err := db.Pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query events: %w", err)
+	}
+	defer rows.Close()
+
+	var events []*Event
+	for rows.Next() {
+		var e Event
+		if err := rows.Scan(&e.ID, &e.Kind, &e.Message, &e.OccurredAt, &e.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan event row: %w", err)
+		}
+		events = append(events, &e)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating event rows: %w", err)
+	}
+
+	slices.Reverse(events)
+	return events, nil
+}
+
+// DeleteEvent deletes an event. It returns ErrEventNotFound if there is
+// none.
+func (db *DB) DeleteEvent(ctx context.Context, id int64) error {
+	tag, err := db.Pool.Exec(ctx, `DELETE FROM events WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete event: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrEventNotFound
+	}
+	return nil
+}
@@ -0,0 +1,78 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEvent_Validate(t *testing.T) {
+	if err := (&Event{Kind: EventDeploy, Message: "rest-server v1.4"}).Validate(); err != nil {
+		t.Errorf("Validate() error = %v", err)
+	}
+	for _, e := range []*Event{
+		{Kind: "", Message: "rest-server v1.4"},
+		{Kind: "release", Message: "rest-server v1.4"},
+		{Kind: EventNote, Message: "  "},
+		{Kind: EventNote, Message: strings.Repeat("x", maxEventMessage+1)},
+	} {
+		if err := e.Validate(); err == nil {
+			t.Errorf("Validate() with kind %q and message %q = nil, want an error", e.Kind, e.Message)
+		}
+	}
+}
+
+func TestEvents(t *testing.T) {
+	db := testDB(t)
+	defer db.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	occurredAt := time.Now().UTC().Add(-48 * time.Hour).Truncate(time.Second)
+	past := &Event{Kind: EventConfig, Message: "go-test-events: raised max_connections", OccurredAt: occurredAt}
+	now := &Event{Kind: EventDeploy, Message: "go-test-events: rest-server v1.4"}
+	for _, e := range []*Event{past, now} {
+		if err := db.RecordEvent(ctx, e); err != nil {
+			t.Fatalf("RecordEvent() error = %v", err)
+		}
+		defer db.DeleteEvent(ctx, e.ID)
+	}
+	if !past.OccurredAt.Equal(occurredAt) || now.OccurredAt.IsZero() {
+		t.Errorf("RecordEvent() occurred_at = %v and %v, want the given time and now", past.OccurredAt, now.OccurredAt)
+	}
+
+	events, err := db.ListEvents(ctx, EventFilter{Window: 24 * time.Hour})
+	if err != nil {
+		t.Fatalf("ListEvents() error = %v", err)
+	}
+	if !containsEvent(events, now.ID) || containsEvent(events, past.ID) {
+		t.Errorf("ListEvents(24h) = %d events, want the recent event and not the past one", len(events))
+	}
+
+	events, err = db.ListEvents(ctx, EventFilter{Kind: EventConfig, Window: 72 * time.Hour})
+	if err != nil {
+		t.Fatalf("ListEvents() error = %v", err)
+	}
+	if !containsEvent(events, past.ID) || containsEvent(events, now.ID) {
+		t.Errorf("ListEvents(config) = %d events, want only config events", len(events))
+	}
+
+	if err := db.DeleteEvent(ctx, past.ID); err != nil {
+		t.Fatalf("DeleteEvent() error = %v", err)
+	}
+	if err := db.DeleteEvent(ctx, past.ID); !errors.Is(err, ErrEventNotFound) {
+		t.Errorf("DeleteEvent() twice error = %v, want ErrEventNotFound", err)
+	}
+}
+
+func containsEvent(events []*Event, id int64) bool {
+	for _, e := range events {
+		if e.ID == id {
+			return true
+		}
+	}
+	return false
+}
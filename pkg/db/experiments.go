@@ -0,0 +1,174 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// Errors returned by the experiment functions.
+var (
+	ErrExperimentNotFound = errors.New("experiment not found")
+	ErrUnknownRun         = errors.New("unknown benchmark run")
+)
+
+// maxExperimentName is the longest experiment name accepted, in bytes.
+const maxExperimentName = 200
+
+// Experiment is a curated set of related runs, e.g. an "HTTP/2 window
+// sweep", with what it set out to show.
+type Experiment struct {
+	ID          int64
+	Name        string
+	Description string
+	Hypothesis  string
+	RunIDs      []int64 // ordered by run ID
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+// Validate checks that the experiment has a name of reasonable length.
+func (e *Experiment) Validate() error {
+	name := strings.TrimSpace(e.Name)
+	if name == "" {
+		return fmt.Errorf("experiment name is required")
+	}
+	if len(name) > maxExperimentName {
+		return fmt.Errorf("experiment name must be at most %d bytes", maxExperimentName)
+	}
+	return nil
+}
+
+// experimentQuery selects experiments with their run IDs; append a WHERE
+// clause on e.id or nothing, then experimentGroupBy.
+const experimentQuery = `SELECT e.id, e.name, e.description, e.hypothesis, e.created_at, e.updated_at,
+	        COALESCE(array_agg(er.run_id::bigint ORDER BY er.run_id) FILTER (WHERE er.run_id IS NOT NULL), '{}')
+	 FROM experiments e
+	 LEFT JOIN experiment_runs er ON er.experiment_id = e.id`
+
+const experimentGroupBy = ` GROUP BY e.id ORDER BY e.id DESC`
+
+// CreateExperiment stores a new experiment and its runs, setting its ID and
+// timestamps. It returns ErrUnknownRun if a run does not exist.
+func (db *DB) CreateExperiment(ctx context.Context, e *Experiment) error {
+	if err := e.Validate(); err != nil {
+		return err
+	}
+	return pgx.BeginFunc(ctx, db.Pool, func(tx pgx.Tx) error {
+		err := tx.QueryRow(ctx,
+			`INSERT INTO experiments (name, description, hypothesis)
+			 VALUES ($1, $2, $3)
+			 RETURNING id, created_at, updated_at`,
+			strings.TrimSpace(e.Name), e.Description, e.Hypothesis,
+		).Scan(&e.ID, &e.CreatedAt, &e.UpdatedAt)
+		if err != nil {
+			return fmt.Errorf("failed to create experiment: %w", err)
+		}
+		return setExperimentRuns(ctx, tx, e.ID, e.RunIDs)
+	})
+}
+
+// GetExperiment retrieves an experiment, or ErrExperimentNotFound.
+func (db *DB) GetExperiment(ctx context.Context, id int64) (*Experiment, error) {
+	var e Experiment
+	err := db.Pool.QueryRow(ctx, experimentQuery+` WHERE e.id = $1`+experimentGroupBy, id).
+		Scan(&e.ID, &e.Name, &e.Description, &e.Hypothesis, &e.CreatedAt, &e.UpdatedAt, &e.RunIDs)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrExperimentNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get experiment: %w", err)
+	}
+	return &e, nil
+}
+
+// ListExperiments retrieves all experiments, newest first.
+func (db *DB) ListExperiments(ctx context.Context) ([]*Experiment, error) {
+	rows, err := db.Pool.Query(ctx, experimentQuery+experimentGroupBy)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query experiments: %w", err)
+	}
+	defer rows.Close()
+
+	var experiments []*Experiment
+	for rows.Next() {
+		var e Experiment
+		if err := rows.Scan(&e.ID, &e.Name, &e.Description, &e.Hypothesis, &e.CreatedAt, &e.UpdatedAt, &e.RunIDs); err != nil {
+			return nil, fmt.Errorf("failed to scan experiment row: %w", err)
+		}
+		experiments = append(experiments, &e)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating experiment rows: %w", err)
+	}
+
+	return experiments, nil
+}
+
+// UpdateExperiment replaces the name, description, hypothesis and runs of
+// an existing experiment and sets its UpdatedAt. It returns
+// ErrExperimentNotFound or ErrUnknownRun.
+func (db *DB) UpdateExperiment(ctx context.Context, e *Experiment) error {
+	if err := e.Validate(); err != nil {
+		return err
+	}
+	return pgx.BeginFunc(ctx, db.Pool, func(tx pgx.Tx) error {
+		err := tx.QueryRow(ctx,
+			`UPDATE experiments SET name = $2, description = $3, hypothesis = $4, updated_at = NOW()
+			 WHERE id = $1
+			 RETURNING created_at, updated_at`,
+			e.ID, strings.TrimSpace(e.Name), e.Description, e.Hypothesis,
+		).Scan(&e.CreatedAt, &e.UpdatedAt)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrExperimentNotFound
+		}
+		if err != nil {
+			return fmt.Errorf("failed to update experiment: %w", err)
+		}
+		if _, err := tx.Exec(ctx, `DELETE FROM experiment_runs WHERE experiment_id = $1`, e.ID); err != nil {
+			return fmt.Errorf("failed to clear experiment runs: %w", err)
+		}
+		return setExperimentRuns(ctx, tx, e.ID, e.RunIDs)
+	})
+}
+
+// DeleteExperiment deletes an experiment, keeping its runs. It returns
+// ErrExperimentNotFound if there is none.
+func (db *DB) DeleteExperiment(ctx context.Context, id int64) error {
+	tag, err := db.Pool.Exec(ctx, `DELETE FROM experiments WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete experiment: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrExperimentNotFound
+	}
+	return nil
+}
+
+// setExperimentRuns adds runIDs to an experiment that has none, ignoring
+// duplicates. It returns ErrUnknownRun if a run does not exist.
+func setExperimentRuns(ctx context.Context, tx pgx.Tx, id int64, runIDs []int64) error {
+	if len(runIDs) == 0 {
+		return nil
+	}
+	_, err := tx.Exec(ctx,
+		`INSERT INTO experiment_runs (experiment_id, run_id)
+		 SELECT $1, run_id FROM unnest($2::bigint[]) AS run_id
+		 ON CONFLICT DO NOTHING`,
+		id, runIDs,
+	)
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23503" { // foreign_key_violation
+		return ErrUnknownRun
+	}
+	if err != nil {
+		return fmt.Errorf("failed to add experiment runs: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,82 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestExperiment_Validate(t *testing.T) {
+	if err := (&Experiment{Name: "HTTP/2 window sweep"}).Validate(); err != nil {
+		t.Errorf("Validate() error = %v", err)
+	}
+	for _, name := range []string{"", "   ", strings.Repeat("x", maxExperimentName+1)} {
+		if err := (&Experiment{Name: name}).Validate(); err == nil {
+			t.Errorf("Validate() with name %q = nil, want an error", name)
+		}
+	}
+}
+
+func TestExperiments(t *testing.T) {
+	db := testDB(t)
+	defer db.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	var runIDs []int64
+	for i := 0; i < 3; i++ {
+		runID, err := db.RecordRun(ctx, &BenchmarkRun{Scenario: "balance", Protocol: "grpc", Client: "go-test-experiments", Concurrency: 10, DurationSec: 5})
+		if err != nil {
+			t.Fatalf("RecordRun() error = %v", err)
+		}
+		defer db.DeleteRun(ctx, runID)
+		runIDs = append(runIDs, runID)
+	}
+
+	e := &Experiment{Name: "window sweep", Hypothesis: "larger windows help streams", RunIDs: []int64{runIDs[1], runIDs[0], runIDs[0]}}
+	if err := db.CreateExperiment(ctx, e); err != nil {
+		t.Fatalf("CreateExperiment() error = %v", err)
+	}
+	defer db.DeleteExperiment(ctx, e.ID)
+
+	got, err := db.GetExperiment(ctx, e.ID)
+	if err != nil {
+		t.Fatalf("GetExperiment() error = %v", err)
+	}
+	if got.Name != e.Name || got.Hypothesis != e.Hypothesis || len(got.RunIDs) != 2 || got.RunIDs[0] != runIDs[0] || got.RunIDs[1] != runIDs[1] {
+		t.Errorf("GetExperiment() = %+v, want the name, hypothesis and runs %v", got, runIDs[:2])
+	}
+
+	e.Name, e.RunIDs = "window sweep v2", []int64{runIDs[2]}
+	if err := db.UpdateExperiment(ctx, e); err != nil {
+		t.Fatalf("UpdateExperiment() error = %v", err)
+	}
+	list, err := db.ListExperiments(ctx)
+	if err != nil {
+		t.Fatalf("ListExperiments() error = %v", err)
+	}
+	var found *Experiment
+	for _, l := range list {
+		if l.ID == e.ID {
+			found = l
+		}
+	}
+	if found == nil || found.Name != "window sweep v2" || len(found.RunIDs) != 1 || found.RunIDs[0] != runIDs[2] {
+		t.Errorf("ListExperiments() entry = %+v, want the updated experiment", found)
+	}
+
+	e.RunIDs = []int64{-1}
+	if err := db.UpdateExperiment(ctx, e); !errors.Is(err, ErrUnknownRun) {
+		t.Errorf("UpdateExperiment() with an unknown run = %v, want ErrUnknownRun", err)
+	}
+
+	if err := db.DeleteExperiment(ctx, e.ID); err != nil {
+		t.Fatalf("DeleteExperiment() error = %v", err)
+	}
+	if _, err := db.GetExperiment(ctx, e.ID); !errors.Is(err, ErrExperimentNotFound) {
+		t.Errorf("GetExperiment() after delete = %v, want ErrExperimentNotFound", err)
+	}
+}
@@ -0,0 +1,55 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// fingerprintSampleRows is how many accounts and transactions, the first by
+// primary key, the fingerprint checksum covers.
+const fingerprintSampleRows = 1000
+
+// DatasetFingerprint identifies the seeded accounts and transactions a run
+// was measured against. Seeding draws balances, amounts and timestamps at
+// random, so two seeds of the same size still differ in MaxTxAt and Checksum.
+// Transactions submitted by the write scenario are not counted.
+type DatasetFingerprint struct {
+	Accounts     int64
+	Transactions int64
+	MaxTxAt      *time.Time // newest transaction, nil without transactions
+	Checksum     string     // of the sampled accounts and transactions
+}
+
+// String formats the fingerprint as stored with a run, e.g.
+// "accounts=10000 transactions=100000 max_tx=2025-01-02T03:04:05Z checksum=0123456789abcdef".
+func (f *DatasetFingerprint) String() string {
+	maxTx := "-"
+	if f.MaxTxAt != nil {
+		maxTx = f.MaxTxAt.UTC().Format(time.RFC3339)
+	}
+	return fmt.Sprintf("accounts=%d transactions=%d max_tx=%s checksum=%s",
+		f.Accounts, f.Transactions, maxTx, f.Checksum)
+}
+
+// GetDatasetFingerprint computes the fingerprint of the seeded dataset.
+func (db *DB) GetDatasetFingerprint(ctx context.Context) (*DatasetFingerprint, error) {
+	var f DatasetFingerprint
+	err := db.Pool.QueryRow(ctx,
+		`SELECT
+		    (SELECT COUNT(*) FROM accounts),
+		    (SELECT COUNT(*) FROM transactions WHERE NOT submitted),
+		    (SELECT MAX(timestamp) FROM transactions WHERE NOT submitted),
+		    LEFT(md5(
+		        COALESCE((SELECT string_agg(account_id || ':' || balance_tinybar, ',' ORDER BY account_id)
+		                  FROM (SELECT account_id, balance_tinybar FROM accounts ORDER BY account_id LIMIT $1) a), '') || '|' ||
+		        COALESCE((SELECT string_agg(tx_id || ':' || amount_tinybar, ',' ORDER BY tx_id)
+		                  FROM (SELECT tx_id, amount_tinybar FROM transactions WHERE NOT submitted ORDER BY tx_id LIMIT $1) t), '')
+		    ), 16)`,
+		fingerprintSampleRows,
+	).Scan(&f.Accounts, &f.Transactions, &f.MaxTxAt, &f.Checksum)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compute dataset fingerprint: %w", err)
+	}
+	return &f, nil
+}
@@ -0,0 +1,44 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestDatasetFingerprint_String(t *testing.T) {
+	maxTx := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	f := &DatasetFingerprint{Accounts: 10000, Transactions: 100000, MaxTxAt: &maxTx, Checksum: "0123456789abcdef"}
+	want := "accounts=10000 transactions=100000 max_tx=2025-01-02T03:04:05Z checksum=0123456789abcdef"
+	if got := f.String(); got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+
+	empty := &DatasetFingerprint{Checksum: "d41d8cd98f00b204"}
+	if got := empty.String(); got != "accounts=0 transactions=0 max_tx=- checksum=d41d8cd98f00b204" {
+		t.Errorf("String() without transactions = %q", got)
+	}
+}
+
+func TestGetDatasetFingerprint(t *testing.T) {
+	db := testDB(t)
+	defer db.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	first, err := db.GetDatasetFingerprint(ctx)
+	if err != nil {
+		t.Fatalf("GetDatasetFingerprint() error = %v", err)
+	}
+	second, err := db.GetDatasetFingerprint(ctx)
+	if err != nil {
+		t.Fatalf("GetDatasetFingerprint() error = %v", err)
+	}
+	if first.String() != second.String() {
+		t.Errorf("fingerprint changed between calls: %s vs %s", first, second)
+	}
+	if len(first.Checksum) != 16 {
+		t.Errorf("Checksum = %q, want 16 hex characters", first.Checksum)
+	}
+}
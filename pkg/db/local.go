@@ -0,0 +1,1212 @@
+package db
+
+import (
+	"cmp"
+	"context"
+	"database/sql"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+	"time"
+
+	_ "modernc.org/sqlite" // registers the "sqlite" driver
+)
+
+// localSchema mirrors the PostgreSQL results tables. Timestamps are stored
+// as Unix microseconds, the precision PostgreSQL keeps.
+const localSchema = `
+CREATE TABLE IF NOT EXISTS benchmark_runs (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    scenario TEXT NOT NULL,
+    protocol TEXT NOT NULL,
+    client TEXT NOT NULL DEFAULT 'go',
+    concurrency INTEGER NOT NULL,
+    duration_sec INTEGER NOT NULL,
+    rate_limit INTEGER,
+    created_at INTEGER NOT NULL,
+    cpu_usage_avg REAL,
+    memory_mb_avg REAL,
+    memory_mb_peak REAL,
+    log_path TEXT,
+    latency_metric TEXT,
+    comparison_id TEXT,
+    payload_size INTEGER,
+    compression TEXT,
+    load_profile TEXT,
+    dataset_hash TEXT,
+    dataset_fingerprint TEXT,
+    net_bytes_sent INTEGER,
+    net_bytes_recv INTEGER,
+    net_interfaces TEXT,
+    connection TEXT,
+    stream_shortfall INTEGER,
+    dataset_size INTEGER,
+    server_qos TEXT,
+    server_pools TEXT,
+    write_ratio REAL,
+    operation_mix TEXT,
+    server_query_tx TEXT,
+    cost REAL,
+    cost_per_million REAL,
+    cost_model TEXT,
+    client_cpu_seconds REAL,
+    server_cpu_seconds REAL,
+    client_per_cpu_sec REAL,
+    server_per_cpu_sec REAL,
+    server_faults TEXT,
+    server_cache TEXT,
+    cache_hit_rate REAL,
+    workers INTEGER,
+    streams_per_worker INTEGER,
+    suite_id TEXT,
+    process_cost_us INTEGER,
+    account_churn TEXT,
+    db_target TEXT,
+    server_log_errors INTEGER,
+    server_log_warnings INTEGER,
+    server_log_lines TEXT,
+    verified INTEGER,
+    verification TEXT,
+    streams_established INTEGER,
+    json_encoder TEXT,
+    measure_window TEXT,
+    measured_window TEXT,
+    full_throughput REAL,
+    full_p50_ms REAL,
+    full_p99_ms REAL,
+    gc_pauses INTEGER,
+    gc_pause_ms REAL,
+    gc_tail_fraction REAL,
+    live_stream BOOLEAN,
+    chaos_disconnect_ms INTEGER,
+    reconnects INTEGER,
+    reconnect_p50_ms REAL,
+    reconnect_p99_ms REAL,
+    server_middleware TEXT,
+    auth TEXT,
+    server_rate_limit TEXT,
+    backoff BOOLEAN,
+    chaos_restart TEXT,
+    restart_errors INTEGER,
+    restart_recovery_ms REAL,
+    agents TEXT,
+    concurrent_with TEXT,
+    client_commit TEXT,
+    server_commit TEXT,
+    go_version TEXT,
+    gomaxprocs INTEGER,
+    kernel TEXT,
+    hostname TEXT,
+    tags TEXT,
+    environment TEXT,
+    goroutines_peak INTEGER,
+    heap_objects_peak INTEGER,
+    heap_mb_peak REAL,
+    gc_cycles INTEGER,
+    cache_bust TEXT,
+    injected_headers TEXT,
+    injected_header_bytes INTEGER,
+    wire_bytes_sent INTEGER,
+    wire_bytes_recv INTEGER,
+    payload_bytes_sent INTEGER,
+    payload_bytes_recv INTEGER,
+    message_p50_bytes INTEGER,
+    message_p99_bytes INTEGER,
+    message_max_bytes INTEGER,
+    rest_stream_format TEXT,
+    run_config TEXT,
+    requests INTEGER,
+    successful_requests INTEGER,
+    latency_p50_ms REAL,
+    latency_p90_ms REAL,
+    latency_p99_ms REAL,
+    latency_avg_ms REAL,
+    latency_min_ms REAL,
+    latency_max_ms REAL,
+    baseline_run_id INTEGER REFERENCES benchmark_runs(id) ON DELETE SET NULL,
+    p50_delta_pct REAL,
+    p99_delta_pct REAL,
+    throughput_delta_pct REAL,
+    -- benchmark_runs.id in PostgreSQL once uploaded by benchmark sync
+    synced_run_id INTEGER
+);
+
+CREATE TABLE IF NOT EXISTS benchmark_samples (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    run_id INTEGER NOT NULL REFERENCES benchmark_runs(id) ON DELETE CASCADE,
+    latency_ms REAL NOT NULL,
+    success INTEGER NOT NULL,
+    error_type TEXT,
+    timestamp INTEGER NOT NULL,
+    staleness_ms REAL,
+    phase INTEGER,
+    workload_class TEXT,
+    operation TEXT,
+    db_ms REAL,
+    gc_pause INTEGER
+);
+
+CREATE INDEX IF NOT EXISTS idx_samples_run ON benchmark_samples(run_id);
+
+CREATE TABLE IF NOT EXISTS benchmark_timeseries (
+    run_id INTEGER NOT NULL REFERENCES benchmark_runs(id) ON DELETE CASCADE,
+    elapsed_sec INTEGER NOT NULL,
+    requests INTEGER NOT NULL,
+    errors INTEGER NOT NULL,
+    p50_latency_ms REAL,
+    p99_latency_ms REAL,
+    goroutines INTEGER,
+    heap_objects INTEGER,
+    heap_mb REAL,
+    gc_cycles INTEGER,
+    gc_pause_ms REAL,
+    PRIMARY KEY (run_id, elapsed_sec)
+);
+
+-- Runs executed together, e.g. by benchmark run --config, referenced by
+-- benchmark_runs.suite_id
+CREATE TABLE IF NOT EXISTS benchmark_suites (
+    id TEXT PRIMARY KEY,
+    name TEXT NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    created_at INTEGER NOT NULL
+);
+
+-- Account IDs copied from PostgreSQL while it was reachable, so balance
+-- benchmarks can run without it.
+CREATE TABLE IF NOT EXISTS accounts (
+    account_id TEXT PRIMARY KEY
+);
+`
+
+// localColumn is a column added to localSchema after results files were
+// first created. OpenLocal adds such columns to older files.
+type localColumn struct{ name, definition string }
+
+// localAddedColumns are the benchmark_runs columns added to localSchema.
+var localAddedColumns = []localColumn{
+	{"dataset_hash", "TEXT"},
+	{"dataset_fingerprint", "TEXT"},
+	{"net_bytes_sent", "INTEGER"},
+	{"net_bytes_recv", "INTEGER"},
+	{"net_interfaces", "TEXT"},
+	{"connection", "TEXT"},
+	{"stream_shortfall", "INTEGER"},
+	{"dataset_size", "INTEGER"},
+	{"server_qos", "TEXT"},
+	{"server_pools", "TEXT"},
+	{"write_ratio", "REAL"},
+	{"operation_mix", "TEXT"},
+	{"server_query_tx", "TEXT"},
+	{"cost", "REAL"},
+	{"cost_per_million", "REAL"},
+	{"cost_model", "TEXT"},
+	{"client_cpu_seconds", "REAL"},
+	{"server_cpu_seconds", "REAL"},
+	{"client_per_cpu_sec", "REAL"},
+	{"server_per_cpu_sec", "REAL"},
+	{"server_faults", "TEXT"},
+	{"server_cache", "TEXT"},
+	{"cache_hit_rate", "REAL"},
+	{"workers", "INTEGER"},
+	{"streams_per_worker", "INTEGER"},
+	{"suite_id", "TEXT"},
+	{"process_cost_us", "INTEGER"},
+	{"account_churn", "TEXT"},
+	{"db_target", "TEXT"},
+	{"server_log_errors", "INTEGER"},
+	{"server_log_warnings", "INTEGER"},
+	{"server_log_lines", "TEXT"},
+	{"verified", "INTEGER"},
+	{"verification", "TEXT"},
+	{"streams_established", "INTEGER"},
+	{"json_encoder", "TEXT"},
+	{"measure_window", "TEXT"},
+	{"measured_window", "TEXT"},
+	{"full_throughput", "REAL"},
+	{"full_p50_ms", "REAL"},
+	{"full_p99_ms", "REAL"},
+	{"gc_pauses", "INTEGER"},
+	{"gc_pause_ms", "REAL"},
+	{"gc_tail_fraction", "REAL"},
+	{"live_stream", "BOOLEAN"},
+	{"chaos_disconnect_ms", "INTEGER"},
+	{"reconnects", "INTEGER"},
+	{"reconnect_p50_ms", "REAL"},
+	{"reconnect_p99_ms", "REAL"},
+	{"server_middleware", "TEXT"},
+	{"auth", "TEXT"},
+	{"server_rate_limit", "TEXT"},
+	{"backoff", "BOOLEAN"},
+	{"chaos_restart", "TEXT"},
+	{"restart_errors", "INTEGER"},
+	{"restart_recovery_ms", "REAL"},
+	{"agents", "TEXT"},
+	{"concurrent_with", "TEXT"},
+	{"client_commit", "TEXT"},
+	{"server_commit", "TEXT"},
+	{"go_version", "TEXT"},
+	{"gomaxprocs", "INTEGER"},
+	{"kernel", "TEXT"},
+	{"hostname", "TEXT"},
+	{"tags", "TEXT"},
+	{"environment", "TEXT"},
+	{"goroutines_peak", "INTEGER"},
+	{"heap_objects_peak", "INTEGER"},
+	{"heap_mb_peak", "REAL"},
+	{"gc_cycles", "INTEGER"},
+	{"cache_bust", "TEXT"},
+	{"injected_headers", "TEXT"},
+	{"injected_header_bytes", "INTEGER"},
+	{"wire_bytes_sent", "INTEGER"},
+	{"wire_bytes_recv", "INTEGER"},
+	{"payload_bytes_sent", "INTEGER"},
+	{"payload_bytes_recv", "INTEGER"},
+	{"message_p50_bytes", "INTEGER"},
+	{"message_p99_bytes", "INTEGER"},
+	{"message_max_bytes", "INTEGER"},
+	{"rest_stream_format", "TEXT"},
+	{"run_config", "TEXT"},
+	{"requests", "INTEGER"},
+	{"successful_requests", "INTEGER"},
+	{"latency_p50_ms", "REAL"},
+	{"latency_p90_ms", "REAL"},
+	{"latency_p99_ms", "REAL"},
+	{"latency_avg_ms", "REAL"},
+	{"latency_min_ms", "REAL"},
+	{"latency_max_ms", "REAL"},
+	{"baseline_run_id", "INTEGER REFERENCES benchmark_runs(id) ON DELETE SET NULL"},
+	{"p50_delta_pct", "REAL"},
+	{"p99_delta_pct", "REAL"},
+	{"throughput_delta_pct", "REAL"},
+}
+
+// localAddedSampleColumns are the benchmark_samples columns added to
+// localSchema.
+var localAddedSampleColumns = []localColumn{
+	{"workload_class", "TEXT"},
+	{"operation", "TEXT"},
+	{"db_ms", "REAL"},
+	{"gc_pause", "INTEGER"},
+}
+
+// localAddedTimeseriesColumns are the benchmark_timeseries columns added to
+// localSchema.
+var localAddedTimeseriesColumns = []localColumn{
+	{"goroutines", "INTEGER"},
+	{"heap_objects", "INTEGER"},
+	{"heap_mb", "REAL"},
+	{"gc_cycles", "INTEGER"},
+	{"gc_pause_ms", "REAL"},
+}
+
+// LocalDB stores benchmark results in a SQLite file. It computes the same
+// stats as the PostgreSQL benchmark_stats, benchmark_phase_stats,
+// benchmark_class_stats and benchmark_operation_stats views, and SyncTo
+// uploads its runs to PostgreSQL later.
+type LocalDB struct {
+	db   *sql.DB
+	Path string
+}
+
+// OpenLocal opens or creates the SQLite results file at path.
+func OpenLocal(ctx context.Context, path string) (*LocalDB, error) {
+	dsn := "file:" + path + "?_pragma=foreign_keys(1)&_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)"
+	sqlDB, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	if _, err := sqlDB.ExecContext(ctx, localSchema); err != nil {
+		sqlDB.Close()
+		return nil, fmt.Errorf("failed to create schema in %s: %w", path, err)
+	}
+	for table, columns := range map[string][]localColumn{
+		"benchmark_runs":       localAddedColumns,
+		"benchmark_samples":    localAddedSampleColumns,
+		"benchmark_timeseries": localAddedTimeseriesColumns,
+	} {
+		if err := addLocalColumns(ctx, sqlDB, table, columns); err != nil {
+			sqlDB.Close()
+			return nil, fmt.Errorf("failed to upgrade schema in %s: %w", path, err)
+		}
+	}
+	return &LocalDB{db: sqlDB, Path: path}, nil
+}
+
+// addLocalColumns adds any of columns missing from table.
+func addLocalColumns(ctx context.Context, sqlDB *sql.DB, table string, columns []localColumn) error {
+	rows, err := sqlDB.QueryContext(ctx, `SELECT name FROM pragma_table_info(?)`, table)
+	if err != nil {
+		return err
+	}
+	existing := make(map[string]bool)
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			rows.Close()
+			return err
+		}
+		existing[name] = true
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, c := range columns {
+		if existing[c.name] {
+			continue
+		}
+		if _, err := sqlDB.ExecContext(ctx, `ALTER TABLE `+table+` ADD COLUMN `+c.name+` `+c.definition); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Close closes the results file.
+func (l *LocalDB) Close() {
+	l.db.Close()
+}
+
+// RecordRun creates a new benchmark run record and returns its ID.
+func (l *LocalDB) RecordRun(ctx context.Context, run *BenchmarkRun) (int64, error) {
+	client := run.Client
+	if client == "" {
+		client = "go"
+	}
+	createdAt := run.CreatedAt
+	if createdAt.IsZero() {
+		createdAt = time.Now()
+	}
+	res, err := l.db.ExecContext(ctx,
+		`INSERT INTO benchmark_runs (scenario, protocol, client, concurrency, duration_sec, rate_limit, created_at, cpu_usage_avg, memory_mb_avg, memory_mb_peak, log_path, latency_metric, comparison_id, payload_size, compression, load_profile, dataset_hash, dataset_fingerprint, net_bytes_sent, net_bytes_recv, net_interfaces, connection, stream_shortfall, dataset_size, server_qos, server_pools, write_ratio, operation_mix, server_query_tx, cost, cost_per_million, cost_model, client_cpu_seconds, server_cpu_seconds, client_per_cpu_sec, server_per_cpu_sec, server_faults, server_cache, cache_hit_rate, workers, streams_per_worker, suite_id, process_cost_us, account_churn, db_target, server_log_errors, server_log_warnings, server_log_lines, verified, verification, streams_established, json_encoder, measure_window, measured_window, full_throughput, full_p50_ms, full_p99_ms, gc_pauses, gc_pause_ms, gc_tail_fraction, live_stream, chaos_disconnect_ms, reconnects, reconnect_p50_ms, reconnect_p99_ms, server_middleware, auth, server_rate_limit, backoff, chaos_restart, restart_errors, restart_recovery_ms, agents, concurrent_with, client_commit, server_commit, go_version, gomaxprocs, kernel, hostname, tags, environment, goroutines_peak, heap_objects_peak, heap_mb_peak, gc_cycles, cache_bust, injected_headers, injected_header_bytes, wire_bytes_sent, wire_bytes_recv, payload_bytes_sent, payload_bytes_recv, message_p50_bytes, message_p99_bytes, message_max_bytes, rest_stream_format, run_config, requests, successful_requests, latency_p50_ms, latency_p90_ms, latency_p99_ms, latency_avg_ms, latency_min_ms, latency_max_ms)
+		 VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		run.Scenario, run.Protocol, client, run.Concurrency, run.DurationSec, run.RateLimit, createdAt.UnixMicro(),
+		run.CPUUsageAvg, run.MemoryMBAvg, run.MemoryMBPeak, run.LogPath, run.LatencyMetric, run.ComparisonID, run.PayloadSize, run.Compression, run.LoadProfile,
+		run.DatasetHash, run.DatasetFingerprint, run.NetBytesSent, run.NetBytesRecv, run.NetInterfaces, run.Connection, run.StreamShortfall, run.DatasetSize, run.ServerQoS, run.ServerPools, run.WriteRatio, run.OperationMix, run.ServerQueryTx, run.Cost, run.CostPerMillion, run.CostModel, run.ClientCPUSeconds, run.ServerCPUSeconds, run.ClientPerCPUSec, run.ServerPerCPUSec, run.ServerFaults, run.ServerCache, run.CacheHitRate, run.Workers, run.StreamsPerWorker, run.SuiteID, run.ProcessCostUs, run.AccountChurn, run.DBTarget, run.ServerLogErrors, run.ServerLogWarnings, run.ServerLogLines, run.Verified, run.Verification, run.StreamsEstablished, run.JSONEncoder, run.MeasureWindow, run.MeasuredWindow, run.FullThroughput, run.FullP50Ms, run.FullP99Ms, run.GCPauses, run.GCPauseMs, run.GCTailFraction, run.LiveStream, run.ChaosDisconnectMs, run.Reconnects, run.ReconnectP50Ms, run.ReconnectP99Ms, run.ServerMiddleware, run.Auth, run.ServerRateLimit, run.Backoff, run.ChaosRestart, run.RestartErrors, run.RestartRecoveryMs, run.Agents, run.ConcurrentWith, run.ClientCommit, run.ServerCommit, run.GoVersion, run.GOMAXPROCS, run.Kernel, run.Hostname, run.Tags, run.Environment, run.GoroutinesPeak, run.HeapObjectsPeak, run.HeapMBPeak, run.GCCycles, run.CacheBust, run.InjectedHeaders, run.InjectedHeaderBytes, run.WireBytesSent, run.WireBytesRecv, run.PayloadBytesSent, run.PayloadBytesRecv, run.MessageP50Bytes, run.MessageP99Bytes, run.MessageMaxBytes, run.RESTStreamFormat, run.RunConfig, run.Requests, run.SuccessfulRequests, run.LatencyP50Ms, run.LatencyP90Ms, run.LatencyP99Ms, run.LatencyAvgMs, run.LatencyMinMs, run.LatencyMaxMs,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("failed to record benchmark run: %w", err)
+	}
+	return res.LastInsertId()
+}
+
+// RecordSamples records multiple latency samples in one transaction.
+func (l *LocalDB) RecordSamples(ctx context.Context, samples []*BenchmarkSample) error {
+	if len(samples) == 0 {
+		return nil
+	}
+
+	tx, err := l.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.PrepareContext(ctx,
+		`INSERT INTO benchmark_samples (run_id, latency_ms, success, error_type, timestamp, staleness_ms, phase, workload_class, operation, db_ms, gc_pause)
+		 VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`)
+	if err != nil {
+		return fmt.Errorf("failed to prepare sample insert: %w", err)
+	}
+	defer stmt.Close()
+
+	for _, s := range samples {
+		if _, err := stmt.ExecContext(ctx, s.RunID, s.LatencyMs, s.Success, s.ErrorType, s.Timestamp.UnixMicro(), s.StalenessMs, s.Phase, s.WorkloadClass, s.Operation, s.DBMs, s.GCPause); err != nil {
+			return fmt.Errorf("failed to insert sample: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit samples: %w", err)
+	}
+	return nil
+}
+
+// GetStats retrieves aggregated statistics for a benchmark run.
+func (l *LocalDB) GetStats(ctx context.Context, runID int64) (*BenchmarkStats, error) {
+	stats, err := l.GetFilteredStats(ctx, StatsFilter{RunID: &runID})
+	if err != nil {
+		return nil, err
+	}
+	if len(stats) == 0 {
+		return nil, fmt.Errorf("failed to get benchmark stats: %w", sql.ErrNoRows)
+	}
+	return stats[0], nil
+}
+
+// GetFilteredStats retrieves stats with optional filtering, sorted and
+// paged as the filter says.
+func (l *LocalDB) GetFilteredStats(ctx context.Context, filter StatsFilter) ([]*BenchmarkStats, error) {
+	if err := filter.Validate(); err != nil {
+		return nil, err
+	}
+	if filter.sortsByStat() {
+		return l.statSortedStats(ctx, filter)
+	}
+
+	runs, err := l.GetRuns(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	allStats := make([]*BenchmarkStats, 0, len(runs))
+	for _, r := range runs {
+		stats, err := l.runStats(ctx, r)
+		if err != nil {
+			return nil, err
+		}
+		allStats = append(allStats, stats)
+	}
+
+	return allStats, nil
+}
+
+// statSortedStats retrieves stats sorted by a statistic. SQLite does not
+// aggregate the samples, so every matching run is aggregated and the page
+// is cut here.
+func (l *LocalDB) statSortedStats(ctx context.Context, filter StatsFilter) ([]*BenchmarkStats, error) {
+	all := filter
+	all.Sort, all.Ascending, all.After, all.Limit, all.Offset = "", false, nil, 0, 0
+	stats, err := l.GetFilteredStats(ctx, all)
+	if err != nil {
+		return nil, err
+	}
+
+	compare := func(a, b Cursor) int {
+		c := cmp.Or(cmp.Compare(a.Value, b.Value), cmp.Compare(a.RunID, b.RunID))
+		if !filter.Ascending {
+			c = -c
+		}
+		return c
+	}
+	slices.SortFunc(stats, func(a, b *BenchmarkStats) int {
+		return compare(filter.CursorOf(a), filter.CursorOf(b))
+	})
+	if filter.After != nil {
+		stats = slices.DeleteFunc(stats, func(s *BenchmarkStats) bool {
+			return compare(filter.CursorOf(s), *filter.After) <= 0
+		})
+	}
+	stats = stats[min(filter.Offset, len(stats)):]
+	if filter.Limit > 0 && len(stats) > filter.Limit {
+		stats = stats[:filter.Limit]
+	}
+	return stats, nil
+}
+
+// runStats aggregates the samples of a run.
+func (l *LocalDB) runStats(ctx context.Context, r *BenchmarkRun) (*BenchmarkStats, error) {
+	stats := &BenchmarkStats{
+		RunID:         r.ID,
+		Scenario:      r.Scenario,
+		Protocol:      r.Protocol,
+		Client:        r.Client,
+		Concurrency:   r.Concurrency,
+		DurationSec:   r.DurationSec,
+		CreatedAt:     r.CreatedAt,
+		CPUUsageAvg:   r.CPUUsageAvg,
+		MemoryMBAvg:   r.MemoryMBAvg,
+		MemoryMBPeak:  r.MemoryMBPeak,
+		NetBytesSent:  r.NetBytesSent,
+		NetBytesRecv:  r.NetBytesRecv,
+		NetInterfaces: r.NetInterfaces,
+		LatencyMetric: r.LatencyMetric,
+		ComparisonID:  r.ComparisonID,
+		SuiteID:       r.SuiteID,
+		PayloadSize:   r.PayloadSize,
+		WriteRatio:    r.WriteRatio,
+		OperationMix:  r.OperationMix,
+		AccountChurn:  r.AccountChurn,
+		DBTarget:      r.DBTarget,
+		Compression:   r.Compression,
+		Connection:    r.Connection,
+		ServerQoS:     r.ServerQoS,
+		ServerPools:   r.ServerPools,
+		ServerQueryTx: r.ServerQueryTx,
+		ServerFaults:  r.ServerFaults,
+		ServerCache:   r.ServerCache,
+		JSONEncoder:   r.JSONEncoder,
+
+		Cost:           r.Cost,
+		CostPerMillion: r.CostPerMillion,
+		CostModel:      r.CostModel,
+
+		ClientCPUSeconds: r.ClientCPUSeconds,
+		ServerCPUSeconds: r.ServerCPUSeconds,
+		ClientPerCPUSec:  r.ClientPerCPUSec,
+		ServerPerCPUSec:  r.ServerPerCPUSec,
+		CacheHitRate:     r.CacheHitRate,
+		Workers:          r.Workers,
+		StreamsPerWorker: r.StreamsPerWorker,
+		ProcessCostUs:    r.ProcessCostUs,
+
+		LoadProfile: r.LoadProfile,
+		DatasetHash: r.DatasetHash,
+
+		DatasetFingerprint: r.DatasetFingerprint,
+		DatasetSize:        r.DatasetSize,
+		StreamShortfall:    r.StreamShortfall,
+		StreamsEstablished: r.StreamsEstablished,
+
+		MeasureWindow:  r.MeasureWindow,
+		MeasuredWindow: r.MeasuredWindow,
+		FullThroughput: r.FullThroughput,
+		FullP50Ms:      r.FullP50Ms,
+		FullP99Ms:      r.FullP99Ms,
+
+		GCPauses:       r.GCPauses,
+		GCPauseMs:      r.GCPauseMs,
+		GCTailFraction: r.GCTailFraction,
+
+		LiveStream: r.LiveStream,
+
+		ChaosDisconnectMs: r.ChaosDisconnectMs,
+		Reconnects:        r.Reconnects,
+		ReconnectP50Ms:    r.ReconnectP50Ms,
+		ReconnectP99Ms:    r.ReconnectP99Ms,
+
+		ServerMiddleware: r.ServerMiddleware,
+		Auth:             r.Auth,
+		ServerRateLimit:  r.ServerRateLimit,
+		Backoff:          r.Backoff,
+
+		ChaosRestart:      r.ChaosRestart,
+		RestartErrors:     r.RestartErrors,
+		RestartRecoveryMs: r.RestartRecoveryMs,
+
+		Agents:         r.Agents,
+		ConcurrentWith: r.ConcurrentWith,
+
+		ClientCommit: r.ClientCommit,
+		ServerCommit: r.ServerCommit,
+		GoVersion:    r.GoVersion,
+		GOMAXPROCS:   r.GOMAXPROCS,
+		Kernel:       r.Kernel,
+		Hostname:     r.Hostname,
+		Tags:         r.Tags,
+		Environment:  r.Environment,
+
+		GoroutinesPeak:  r.GoroutinesPeak,
+		HeapObjectsPeak: r.HeapObjectsPeak,
+		HeapMBPeak:      r.HeapMBPeak,
+		GCCycles:        r.GCCycles,
+
+		CacheBust: r.CacheBust,
+
+		InjectedHeaders:     r.InjectedHeaders,
+		InjectedHeaderBytes: r.InjectedHeaderBytes,
+
+		WireBytesSent:    r.WireBytesSent,
+		WireBytesRecv:    r.WireBytesRecv,
+		PayloadBytesSent: r.PayloadBytesSent,
+		PayloadBytesRecv: r.PayloadBytesRecv,
+
+		MessageP50Bytes:  r.MessageP50Bytes,
+		MessageP99Bytes:  r.MessageP99Bytes,
+		MessageMaxBytes:  r.MessageMaxBytes,
+		RESTStreamFormat: r.RESTStreamFormat,
+		RunConfig:        r.RunConfig,
+
+		ServerLogErrors:   r.ServerLogErrors,
+		ServerLogWarnings: r.ServerLogWarnings,
+		ServerLogLines:    r.ServerLogLines,
+
+		Verified:     r.Verified,
+		Verification: r.Verification,
+	}
+
+	err := l.db.QueryRowContext(ctx,
+		`SELECT baseline_run_id, p50_delta_pct, p99_delta_pct, throughput_delta_pct
+		 FROM benchmark_runs WHERE id = ?`,
+		r.ID,
+	).Scan(&stats.BaselineRunID, &stats.P50DeltaPct, &stats.P99DeltaPct, &stats.ThroughputDeltaPct)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read baseline: %w", err)
+	}
+
+	err = l.db.QueryRowContext(ctx,
+		`SELECT COUNT(*), COALESCE(SUM(success), 0),
+		        COALESCE(AVG(latency_ms), 0), COALESCE(MIN(latency_ms), 0), COALESCE(MAX(latency_ms), 0)
+		 FROM benchmark_samples WHERE run_id = ?`,
+		r.ID,
+	).Scan(&stats.TotalSamples, &stats.Successful, &stats.AvgLatency, &stats.MinLatency, &stats.MaxLatency)
+	if err != nil {
+		return nil, fmt.Errorf("failed to aggregate samples: %w", err)
+	}
+
+	latencies, err := l.sortedValues(ctx, "latency_ms", "run_id = ?", r.ID)
+	if err != nil {
+		return nil, err
+	}
+	stats.P50Latency = percentileCont(latencies, 0.5)
+	stats.P90Latency = percentileCont(latencies, 0.9)
+	stats.P99Latency = percentileCont(latencies, 0.99)
+	stats.StoredSamples = stats.TotalSamples
+	applyRunLatency(stats, r)
+
+	staleness, err := l.sortedValues(ctx, "staleness_ms", "run_id = ? AND staleness_ms IS NOT NULL", r.ID)
+	if err != nil {
+		return nil, err
+	}
+	if len(staleness) > 0 {
+		p50, p90, p99 := percentileCont(staleness, 0.5), percentileCont(staleness, 0.9), percentileCont(staleness, 0.99)
+		stats.P50Staleness, stats.P90Staleness, stats.P99Staleness = &p50, &p90, &p99
+	}
+
+	dbTimes, err := l.sortedValues(ctx, "db_ms", "run_id = ? AND db_ms IS NOT NULL", r.ID)
+	if err != nil {
+		return nil, err
+	}
+	if len(dbTimes) > 0 {
+		network, err := l.sortedValues(ctx, "latency_ms - db_ms", "run_id = ? AND db_ms IS NOT NULL", r.ID)
+		if err != nil {
+			return nil, err
+		}
+		p50DB, p99DB := percentileCont(dbTimes, 0.5), percentileCont(dbTimes, 0.99)
+		p50Net, p99Net := percentileCont(network, 0.5), percentileCont(network, 0.99)
+		stats.P50DB, stats.P99DB, stats.P50Network, stats.P99Network = &p50DB, &p99DB, &p50Net, &p99Net
+	}
+
+	return stats, nil
+}
+
+// applyRunLatency replaces the request counts and latency statistics
+// aggregated from the stored samples with those the run recorded from all
+// of its requests, as the benchmark_stats view does.
+func applyRunLatency(stats *BenchmarkStats, r *BenchmarkRun) {
+	if r.Requests == nil {
+		return
+	}
+	stats.TotalSamples = *r.Requests
+	for _, v := range []struct {
+		dst *float64
+		src *float64
+	}{
+		{&stats.P50Latency, r.LatencyP50Ms},
+		{&stats.P90Latency, r.LatencyP90Ms},
+		{&stats.P99Latency, r.LatencyP99Ms},
+		{&stats.AvgLatency, r.LatencyAvgMs},
+		{&stats.MinLatency, r.LatencyMinMs},
+		{&stats.MaxLatency, r.LatencyMaxMs},
+	} {
+		if v.src != nil {
+			*v.dst = *v.src
+		}
+	}
+	if r.SuccessfulRequests != nil {
+		stats.Successful = *r.SuccessfulRequests
+	}
+}
+
+// GetPhaseStats retrieves per-phase stats for a run with a load profile,
+// ordered by phase. The result is empty for runs without one.
+func (l *LocalDB) GetPhaseStats(ctx context.Context, runID int64) ([]*PhaseStats, error) {
+	rows, err := l.db.QueryContext(ctx,
+		`SELECT phase, COUNT(*), SUM(success), MIN(timestamp), MAX(timestamp)
+		 FROM benchmark_samples
+		 WHERE run_id = ? AND phase IS NOT NULL
+		 GROUP BY phase
+		 ORDER BY phase`,
+		runID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query phase stats: %w", err)
+	}
+
+	var phases []*PhaseStats
+	for rows.Next() {
+		p := PhaseStats{RunID: runID}
+		var started, ended int64
+		if err := rows.Scan(&p.Phase, &p.TotalSamples, &p.Successful, &started, &ended); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan phase stats row: %w", err)
+		}
+		p.StartedAt, p.EndedAt = time.UnixMicro(started), time.UnixMicro(ended)
+		phases = append(phases, &p)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating phase stats rows: %w", err)
+	}
+
+	for _, p := range phases {
+		latencies, err := l.sortedValues(ctx, "latency_ms", "run_id = ? AND phase = ?", runID, p.Phase)
+		if err != nil {
+			return nil, err
+		}
+		p.P50Latency = percentileCont(latencies, 0.5)
+		p.P99Latency = percentileCont(latencies, 0.99)
+	}
+
+	return phases, nil
+}
+
+// GetClassStats retrieves per-workload-class stats for a run that mixed
+// workloads, ordered by class. The result is empty for other runs.
+func (l *LocalDB) GetClassStats(ctx context.Context, runID int64) ([]*ClassStats, error) {
+	return l.groupStats(ctx, runID, "workload_class")
+}
+
+// GetOperationStats retrieves per-operation stats for a run, ordered by
+// operation. The result is empty for runs stored without operations.
+func (l *LocalDB) GetOperationStats(ctx context.Context, runID int64) ([]*OperationStats, error) {
+	groups, err := l.groupStats(ctx, runID, "operation")
+	if err != nil {
+		return nil, err
+	}
+	ops := make([]*OperationStats, len(groups))
+	for i, g := range groups {
+		ops[i] = &OperationStats{
+			RunID:        g.RunID,
+			Operation:    g.Class,
+			TotalSamples: g.TotalSamples,
+			Successful:   g.Successful,
+			P50Latency:   g.P50Latency,
+			P99Latency:   g.P99Latency,
+			StartedAt:    g.StartedAt,
+			EndedAt:      g.EndedAt,
+		}
+	}
+	return ops, nil
+}
+
+// GetErrorCounts retrieves the failed samples of runs counted per error
+// type, ordered by run and most frequent type first. Failed samples stored
+// without an error type are counted as "unknown".
+func (l *LocalDB) GetErrorCounts(ctx context.Context, runIDs []int64) ([]*ErrorCount, error) {
+	if len(runIDs) == 0 {
+		return nil, nil
+	}
+	args := make([]interface{}, len(runIDs))
+	for i, id := range runIDs {
+		args[i] = id
+	}
+	rows, err := l.db.QueryContext(ctx,
+		`SELECT run_id, COALESCE(error_type, 'unknown') AS type, COUNT(*) AS count
+		 FROM benchmark_samples
+		 WHERE run_id IN (`+placeholders(len(runIDs))+`) AND success = 0
+		 GROUP BY run_id, type
+		 ORDER BY run_id, count DESC, type`,
+		args...,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query error counts: %w", err)
+	}
+	defer rows.Close()
+
+	var counts []*ErrorCount
+	for rows.Next() {
+		var c ErrorCount
+		if err := rows.Scan(&c.RunID, &c.ErrorType, &c.Count); err != nil {
+			return nil, fmt.Errorf("failed to scan error count row: %w", err)
+		}
+		counts = append(counts, &c)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating error count rows: %w", err)
+	}
+
+	return counts, nil
+}
+
+// groupStats aggregates the samples of a run by column, workload_class or
+// operation, as ClassStats keyed by the column value. Samples where it is
+// NULL are skipped.
+func (l *LocalDB) groupStats(ctx context.Context, runID int64, column string) ([]*ClassStats, error) {
+	rows, err := l.db.QueryContext(ctx,
+		`SELECT `+column+`, COUNT(*), SUM(success), MIN(timestamp), MAX(timestamp)
+		 FROM benchmark_samples
+		 WHERE run_id = ? AND `+column+` IS NOT NULL
+		 GROUP BY `+column+`
+		 ORDER BY `+column,
+		runID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query %s stats: %w", column, err)
+	}
+
+	var classes []*ClassStats
+	for rows.Next() {
+		c := ClassStats{RunID: runID}
+		var started, ended int64
+		if err := rows.Scan(&c.Class, &c.TotalSamples, &c.Successful, &started, &ended); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan %s stats row: %w", column, err)
+		}
+		c.StartedAt, c.EndedAt = time.UnixMicro(started), time.UnixMicro(ended)
+		classes = append(classes, &c)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating %s stats rows: %w", column, err)
+	}
+
+	for _, c := range classes {
+		latencies, err := l.sortedValues(ctx, "latency_ms", "run_id = ? AND "+column+" = ?", runID, c.Class)
+		if err != nil {
+			return nil, err
+		}
+		c.P50Latency = percentileCont(latencies, 0.5)
+		c.P99Latency = percentileCont(latencies, 0.99)
+	}
+
+	return classes, nil
+}
+
+// GetRuns retrieves benchmark run records matching filter, newest first.
+func (l *LocalDB) GetRuns(ctx context.Context, filter StatsFilter) ([]*BenchmarkRun, error) {
+	if err := filter.validateRuns(); err != nil {
+		return nil, err
+	}
+	clauses, args := filter.localClauses()
+	return l.queryRuns(ctx, clauses, args...)
+}
+
+// UnsyncedRuns retrieves the runs SyncTo has not uploaded yet, oldest first.
+func (l *LocalDB) UnsyncedRuns(ctx context.Context) ([]*BenchmarkRun, error) {
+	return l.queryRuns(ctx, " WHERE synced_run_id IS NULL ORDER BY id")
+}
+
+func (l *LocalDB) queryRuns(ctx context.Context, clauses string, args ...interface{}) ([]*BenchmarkRun, error) {
+	rows, err := l.db.QueryContext(ctx,
+		`SELECT id, scenario, protocol, client, concurrency, duration_sec, rate_limit, created_at,
+		        cpu_usage_avg, memory_mb_avg, memory_mb_peak, log_path, latency_metric,
+		        comparison_id, payload_size, compression, load_profile, dataset_hash, dataset_fingerprint,
+		        net_bytes_sent, net_bytes_recv, net_interfaces, connection, stream_shortfall, dataset_size, server_qos, server_pools, write_ratio, operation_mix, server_query_tx, cost, cost_per_million, cost_model, client_cpu_seconds, server_cpu_seconds, client_per_cpu_sec, server_per_cpu_sec, server_faults, server_cache, cache_hit_rate, workers, streams_per_worker, suite_id, process_cost_us, account_churn, db_target, server_log_errors, server_log_warnings, server_log_lines, verified, verification, streams_established, json_encoder, measure_window, measured_window, full_throughput, full_p50_ms, full_p99_ms, gc_pauses, gc_pause_ms, gc_tail_fraction, live_stream, chaos_disconnect_ms, reconnects, reconnect_p50_ms, reconnect_p99_ms, server_middleware, auth, server_rate_limit, backoff, chaos_restart, restart_errors, restart_recovery_ms, agents, concurrent_with, client_commit, server_commit, go_version, gomaxprocs, kernel, hostname, tags, environment, goroutines_peak, heap_objects_peak, heap_mb_peak, gc_cycles, cache_bust, injected_headers, injected_header_bytes, wire_bytes_sent, wire_bytes_recv, payload_bytes_sent, payload_bytes_recv, message_p50_bytes, message_p99_bytes, message_max_bytes, rest_stream_format, run_config, requests, successful_requests, latency_p50_ms, latency_p90_ms, latency_p99_ms, latency_avg_ms, latency_min_ms, latency_max_ms
+		 FROM benchmark_runs`+clauses,
+		args...,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query benchmark runs: %w", err)
+	}
+	defer rows.Close()
+
+	var runs []*BenchmarkRun
+	for rows.Next() {
+		var r BenchmarkRun
+		var createdAt int64
+		err := rows.Scan(
+			&r.ID, &r.Scenario, &r.Protocol, &r.Client, &r.Concurrency, &r.DurationSec, &r.RateLimit, &createdAt,
+			&r.CPUUsageAvg, &r.MemoryMBAvg, &r.MemoryMBPeak, &r.LogPath, &r.LatencyMetric,
+			&r.ComparisonID, &r.PayloadSize, &r.Compression, &r.LoadProfile, &r.DatasetHash, &r.DatasetFingerprint,
+			&r.NetBytesSent, &r.NetBytesRecv, &r.NetInterfaces, &r.Connection, &r.StreamShortfall, &r.DatasetSize, &r.ServerQoS, &r.ServerPools, &r.WriteRatio, &r.OperationMix, &r.ServerQueryTx, &r.Cost, &r.CostPerMillion, &r.CostModel, &r.ClientCPUSeconds, &r.ServerCPUSeconds, &r.ClientPerCPUSec, &r.ServerPerCPUSec, &r.ServerFaults, &r.ServerCache, &r.CacheHitRate, &r.Workers, &r.StreamsPerWorker, &r.SuiteID, &r.ProcessCostUs, &r.AccountChurn, &r.DBTarget, &r.ServerLogErrors, &r.ServerLogWarnings, &r.ServerLogLines, &r.Verified, &r.Verification, &r.StreamsEstablished, &r.JSONEncoder, &r.MeasureWindow, &r.MeasuredWindow, &r.FullThroughput, &r.FullP50Ms, &r.FullP99Ms, &r.GCPauses, &r.GCPauseMs, &r.GCTailFraction, &r.LiveStream, &r.ChaosDisconnectMs, &r.Reconnects, &r.ReconnectP50Ms, &r.ReconnectP99Ms, &r.ServerMiddleware, &r.Auth, &r.ServerRateLimit, &r.Backoff, &r.ChaosRestart, &r.RestartErrors, &r.RestartRecoveryMs, &r.Agents, &r.ConcurrentWith, &r.ClientCommit, &r.ServerCommit, &r.GoVersion, &r.GOMAXPROCS, &r.Kernel, &r.Hostname, &r.Tags, &r.Environment, &r.GoroutinesPeak, &r.HeapObjectsPeak, &r.HeapMBPeak, &r.GCCycles, &r.CacheBust, &r.InjectedHeaders, &r.InjectedHeaderBytes, &r.WireBytesSent, &r.WireBytesRecv, &r.PayloadBytesSent, &r.PayloadBytesRecv, &r.MessageP50Bytes, &r.MessageP99Bytes, &r.MessageMaxBytes, &r.RESTStreamFormat, &r.RunConfig, &r.Requests, &r.SuccessfulRequests, &r.LatencyP50Ms, &r.LatencyP90Ms, &r.LatencyP99Ms, &r.LatencyAvgMs, &r.LatencyMinMs, &r.LatencyMaxMs,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan run row: %w", err)
+		}
+		r.CreatedAt = time.UnixMicro(createdAt)
+		runs = append(runs, &r)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating run rows: %w", err)
+	}
+
+	return runs, nil
+}
+
+// ForEachSample calls fn for every sample of the given runs, ordered by run
+// and insertion. The sample passed to fn is reused between calls.
+func (l *LocalDB) ForEachSample(ctx context.Context, runIDs []int64, fn func(*BenchmarkSample) error) error {
+	if len(runIDs) == 0 {
+		return nil
+	}
+	args := make([]interface{}, len(runIDs))
+	for i, id := range runIDs {
+		args[i] = id
+	}
+	rows, err := l.db.QueryContext(ctx,
+		`SELECT id, run_id, latency_ms, success, error_type, timestamp, staleness_ms, phase, workload_class, operation, db_ms, gc_pause
+		 FROM benchmark_samples
+		 WHERE run_id IN (`+placeholders(len(runIDs))+`)
+		 ORDER BY run_id, id`,
+		args...,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to query benchmark samples: %w", err)
+	}
+	defer rows.Close()
+
+	var s BenchmarkSample
+	for rows.Next() {
+		var ts int64
+		err := rows.Scan(&s.ID, &s.RunID, &s.LatencyMs, &s.Success, &s.ErrorType, &ts, &s.StalenessMs, &s.Phase, &s.WorkloadClass, &s.Operation, &s.DBMs, &s.GCPause)
+		if err != nil {
+			return fmt.Errorf("failed to scan sample row: %w", err)
+		}
+		s.Timestamp = time.UnixMicro(ts)
+		if err := fn(&s); err != nil {
+			return err
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("error iterating sample rows: %w", err)
+	}
+
+	return nil
+}
+
+// SaveAccountIDs replaces the cached account IDs.
+func (l *LocalDB) SaveAccountIDs(ctx context.Context, ids []string) error {
+	tx, err := l.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM accounts`); err != nil {
+		return fmt.Errorf("failed to clear account IDs: %w", err)
+	}
+	stmt, err := tx.PrepareContext(ctx, `INSERT INTO accounts (account_id) VALUES (?)`)
+	if err != nil {
+		return fmt.Errorf("failed to prepare account insert: %w", err)
+	}
+	defer stmt.Close()
+	for _, id := range ids {
+		if _, err := stmt.ExecContext(ctx, id); err != nil {
+			return fmt.Errorf("failed to insert account ID: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit account IDs: %w", err)
+	}
+	return nil
+}
+
+// GetAllAccountIDs returns the cached account IDs.
+func (l *LocalDB) GetAllAccountIDs(ctx context.Context) ([]string, error) {
+	rows, err := l.db.QueryContext(ctx, `SELECT account_id FROM accounts`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query account IDs: %w", err)
+	}
+	defer rows.Close()
+
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan account ID: %w", err)
+		}
+		ids = append(ids, id)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating account IDs: %w", err)
+	}
+
+	return ids, nil
+}
+
+// syncBatchSize is the number of samples uploaded per COPY by SyncTo.
+const syncBatchSize = 10000
+
+// SyncTo uploads every run not yet synced, with its samples, to pg and
+// records the PostgreSQL run ID so later syncs skip it. Each uploaded run
+// gets a baseline selected among the PostgreSQL runs. A run whose samples
+// fail to upload is deleted from pg again and left unsynced. fn, if not nil,
+// is called after each run is uploaded. SyncTo returns the number of runs
+// uploaded.
+func (l *LocalDB) SyncTo(ctx context.Context, pg *DB, fn func(localID, remoteID int64, samples int)) (int, error) {
+	runs, err := l.UnsyncedRuns(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	for i, run := range runs {
+		remoteID, samples, err := l.syncRun(ctx, pg, run)
+		if err != nil {
+			return i, fmt.Errorf("run %d: %w", run.ID, err)
+		}
+		if _, err := l.db.ExecContext(ctx,
+			`UPDATE benchmark_runs SET synced_run_id = ? WHERE id = ?`, remoteID, run.ID); err != nil {
+			return i, fmt.Errorf("run %d: uploaded as run %d but failed to mark it synced: %w", run.ID, remoteID, err)
+		}
+		// Baselines are chosen again among the PostgreSQL runs
+		if _, err := AssignBaseline(ctx, pg, remoteID); err != nil {
+			return i + 1, fmt.Errorf("run %d: uploaded as run %d but failed to assign its baseline: %w", run.ID, remoteID, err)
+		}
+		if fn != nil {
+			fn(run.ID, remoteID, samples)
+		}
+	}
+
+	return len(runs), nil
+}
+
+// syncRun uploads one run with its samples and timeseries, returning the PostgreSQL run ID
+// and the number of samples uploaded.
+func (l *LocalDB) syncRun(ctx context.Context, pg *DB, run *BenchmarkRun) (int64, int, error) {
+	if run.SuiteID != nil {
+		if err := l.syncSuite(ctx, pg, *run.SuiteID); err != nil {
+			return 0, 0, err
+		}
+	}
+	remoteID, err := pg.RecordRun(ctx, run)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	count := 0
+	batch := make([]*BenchmarkSample, 0, syncBatchSize)
+	flush := func() error {
+		if err := pg.RecordSamples(ctx, batch); err != nil {
+			return err
+		}
+		count += len(batch)
+		batch = batch[:0]
+		return nil
+	}
+
+	err = l.ForEachSample(ctx, []int64{run.ID}, func(s *BenchmarkSample) error {
+		sample := *s
+		sample.RunID = remoteID
+		batch = append(batch, &sample)
+		if len(batch) == syncBatchSize {
+			return flush()
+		}
+		return nil
+	})
+	if err == nil {
+		err = flush()
+	}
+	if err == nil {
+		var points []TimeseriesPoint
+		if points, err = l.GetTimeseries(ctx, run.ID); err == nil {
+			err = pg.RecordTimeseries(ctx, remoteID, points)
+		}
+	}
+	if err != nil {
+		if delErr := pg.DeleteRun(context.WithoutCancel(ctx), remoteID); delErr != nil {
+			err = fmt.Errorf("%w (and failed to remove partial run %d: %v)", err, remoteID, delErr)
+		}
+		return 0, 0, err
+	}
+
+	return remoteID, count, nil
+}
+
+// localClauses builds the WHERE, ORDER BY, LIMIT and OFFSET clauses for
+// the filter with SQLite placeholders. Sorting by a statistic is left to
+// the caller.
+func (f StatsFilter) localClauses() (string, []interface{}) {
+	clause := " WHERE 1=1"
+	var args []interface{}
+
+	if f.RunID != nil {
+		clause += " AND id = ?"
+		args = append(args, *f.RunID)
+	}
+	if len(f.RunIDs) > 0 {
+		clause += " AND id IN (" + placeholders(len(f.RunIDs)) + ")"
+		for _, id := range f.RunIDs {
+			args = append(args, id)
+		}
+	}
+	if f.Scenario != "" {
+		clause += " AND scenario = ?"
+		args = append(args, f.Scenario)
+	}
+	if f.Protocol != "" {
+		clause += " AND protocol = ?"
+		args = append(args, f.Protocol)
+	}
+	if f.Client != "" {
+		clause += " AND client = ?"
+		args = append(args, f.Client)
+	}
+	if f.ComparisonID != "" {
+		clause += " AND comparison_id = ?"
+		args = append(args, f.ComparisonID)
+	}
+	if f.SuiteID != "" {
+		clause += " AND suite_id = ?"
+		args = append(args, f.SuiteID)
+	}
+	if f.Environment != "" {
+		clause += " AND environment = ?"
+		args = append(args, f.Environment)
+	}
+
+	if !f.Since.IsZero() {
+		clause += " AND created_at >= ?"
+		args = append(args, f.Since.UnixMicro())
+	}
+	if !f.Until.IsZero() {
+		clause += " AND created_at < ?"
+		args = append(args, f.Until.UnixMicro())
+	}
+	// Local runs are never labeled
+	if len(f.Labels) > 0 {
+		clause += " AND 0 = 1"
+	}
+
+	dir, op := "DESC", "<"
+	if f.Ascending {
+		dir, op = "ASC", ">"
+	}
+	if f.After != nil {
+		clause += " AND (created_at, id) " + op + " (?, ?)"
+		args = append(args, int64(f.After.Value), f.After.RunID)
+	}
+	clause += " ORDER BY created_at " + dir + ", id " + dir
+
+	// SQLite only takes OFFSET after a LIMIT, -1 for none
+	if f.Limit > 0 || f.Offset > 0 {
+		limit := f.Limit
+		if limit <= 0 {
+			limit = -1
+		}
+		clause += " LIMIT ?"
+		args = append(args, limit)
+	}
+	if f.Offset > 0 {
+		clause += " OFFSET ?"
+		args = append(args, f.Offset)
+	}
+	return clause, args
+}
+
+// sortedValues returns column for the samples matching where, in ascending
+// order.
+func (l *LocalDB) sortedValues(ctx context.Context, column, where string, args ...interface{}) ([]float64, error) {
+	rows, err := l.db.QueryContext(ctx,
+		`SELECT `+column+` FROM benchmark_samples WHERE `+where+` ORDER BY `+column, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query %s: %w", column, err)
+	}
+	defer rows.Close()
+
+	var values []float64
+	for rows.Next() {
+		var v float64
+		if err := rows.Scan(&v); err != nil {
+			return nil, fmt.Errorf("failed to scan %s: %w", column, err)
+		}
+		values = append(values, v)
+	}
+	return values, rows.Err()
+}
+
+// percentileCont computes PostgreSQL's PERCENTILE_CONT over sorted values:
+// the p-th fraction, interpolated linearly between the two nearest values.
+// It returns 0 for no values.
+func percentileCont(sorted []float64, p float64) float64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+	pos := p * float64(len(sorted)-1)
+	lo := int(math.Floor(pos))
+	hi := int(math.Ceil(pos))
+	return sorted[lo] + (sorted[hi]-sorted[lo])*(pos-float64(lo))
+}
+
+// placeholders returns n comma-separated SQLite placeholders.
+func placeholders(n int) string {
+	return strings.TrimSuffix(strings.Repeat("?,", n), ",")
+}
@@ -0,0 +1,454 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+// testLocalDB opens a results file in a temporary directory.
+func testLocalDB(t *testing.T) *LocalDB {
+	t.Helper()
+
+	l, err := OpenLocal(context.Background(), filepath.Join(t.TempDir(), "results.db"))
+	if err != nil {
+		t.Fatalf("OpenLocal() error = %v", err)
+	}
+	t.Cleanup(l.Close)
+	return l
+}
+
+func TestOpenLocal_AddsColumns(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "old.db")
+	old, err := sql.Open("sqlite", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = old.Exec(`CREATE TABLE benchmark_runs (
+		id INTEGER PRIMARY KEY AUTOINCREMENT, scenario TEXT NOT NULL, protocol TEXT NOT NULL,
+		client TEXT NOT NULL DEFAULT 'go', concurrency INTEGER NOT NULL, duration_sec INTEGER NOT NULL,
+		rate_limit INTEGER, created_at INTEGER NOT NULL, cpu_usage_avg REAL, memory_mb_avg REAL,
+		memory_mb_peak REAL, log_path TEXT, latency_metric TEXT, comparison_id TEXT,
+		payload_size INTEGER, compression TEXT, load_profile TEXT, synced_run_id INTEGER);
+		CREATE TABLE benchmark_samples (
+		id INTEGER PRIMARY KEY AUTOINCREMENT, run_id INTEGER NOT NULL, latency_ms REAL NOT NULL,
+		success INTEGER NOT NULL, error_type TEXT, timestamp INTEGER NOT NULL, staleness_ms REAL, phase INTEGER)`)
+	old.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	l, err := OpenLocal(context.Background(), path)
+	if err != nil {
+		t.Fatalf("OpenLocal() error = %v", err)
+	}
+	defer l.Close()
+
+	hash := "abc"
+	id, err := l.RecordRun(context.Background(), &BenchmarkRun{Scenario: "echo", Protocol: "grpc", DatasetHash: &hash})
+	if err != nil {
+		t.Fatalf("RecordRun() on an upgraded file error = %v", err)
+	}
+	class, op := "balance", "GetBalance"
+	if err := l.RecordSamples(context.Background(), []*BenchmarkSample{{RunID: id, Success: true, WorkloadClass: &class, Operation: &op}}); err != nil {
+		t.Errorf("RecordSamples() on an upgraded file error = %v", err)
+	}
+}
+
+func TestPercentileCont(t *testing.T) {
+	values := []float64{1, 2, 3, 4}
+	tests := []struct {
+		p    float64
+		want float64
+	}{
+		{0, 1},
+		{0.5, 2.5},
+		{0.9, 3.7},
+		{1, 4},
+	}
+	for _, tt := range tests {
+		if got := percentileCont(values, tt.p); got < tt.want-1e-9 || got > tt.want+1e-9 {
+			t.Errorf("percentileCont(%v) = %v, want %v", tt.p, got, tt.want)
+		}
+	}
+	if got := percentileCont(nil, 0.5); got != 0 {
+		t.Errorf("percentileCont(nil) = %v, want 0", got)
+	}
+}
+
+func TestLocalDB_RecordAndStats(t *testing.T) {
+	l := testLocalDB(t)
+	ctx := context.Background()
+
+	created := time.Date(2025, 1, 2, 3, 4, 5, 6000, time.UTC)
+	comparison := "cmp-1"
+	shortfall, size := int64(3), int64(10_000)
+	id, err := l.RecordRun(ctx, &BenchmarkRun{
+		Scenario: "balance", Protocol: "grpc", Concurrency: 4, DurationSec: 10,
+		CreatedAt: created, ComparisonID: &comparison, StreamShortfall: &shortfall, DatasetSize: &size,
+	})
+	if err != nil {
+		t.Fatalf("RecordRun() error = %v", err)
+	}
+
+	start := time.Date(2025, 1, 2, 3, 4, 6, 0, time.UTC)
+	staleness := 250.0
+	var samples []*BenchmarkSample
+	for i := 1; i <= 10; i++ {
+		phase := (i-1)/5 + 1
+		s := &BenchmarkSample{
+			RunID:     id,
+			LatencyMs: float64(i),
+			Success:   i != 10,
+			Timestamp: start.Add(time.Duration(i) * time.Second),
+			Phase:     &phase,
+		}
+		gcPause := i == 10
+		s.GCPause = &gcPause
+		if i == 1 {
+			s.StalenessMs = &staleness
+		}
+		if i <= 2 {
+			dbMs := 0.5 * float64(i)
+			s.DBMs = &dbMs
+		}
+		samples = append(samples, s)
+	}
+	if err := l.RecordSamples(ctx, samples); err != nil {
+		t.Fatalf("RecordSamples() error = %v", err)
+	}
+
+	stats, err := l.GetStats(ctx, id)
+	if err != nil {
+		t.Fatalf("GetStats() error = %v", err)
+	}
+	if stats.TotalSamples != 10 || stats.Successful != 9 {
+		t.Errorf("samples = %d/%d, want 9/10", stats.Successful, stats.TotalSamples)
+	}
+	if stats.P50Latency != 5.5 || stats.MinLatency != 1 || stats.MaxLatency != 10 || stats.AvgLatency != 5.5 {
+		t.Errorf("latency stats = %+v", stats)
+	}
+	if stats.Client != "go" || stats.ComparisonID == nil || *stats.ComparisonID != comparison ||
+		stats.StreamShortfall == nil || *stats.StreamShortfall != shortfall ||
+		stats.DatasetSize == nil || *stats.DatasetSize != size {
+		t.Errorf("run fields = %+v", stats)
+	}
+	if stats.P50Staleness == nil || *stats.P50Staleness != staleness {
+		t.Errorf("P50Staleness = %v, want %v", stats.P50Staleness, staleness)
+	}
+	if stats.P50DB == nil || *stats.P50DB != 0.75 || stats.P50Network == nil || *stats.P50Network != 0.75 {
+		t.Errorf("P50DB = %v, P50Network = %v; want 0.75 and 0.75", stats.P50DB, stats.P50Network)
+	}
+
+	phases, err := l.GetPhaseStats(ctx, id)
+	if err != nil {
+		t.Fatalf("GetPhaseStats() error = %v", err)
+	}
+	if len(phases) != 2 || phases[0].TotalSamples != 5 || phases[1].P50Latency != 8 || phases[1].Successful != 4 {
+		t.Fatalf("phases = %+v %+v", phases[0], phases[1])
+	}
+	if !phases[0].StartedAt.Equal(start.Add(time.Second)) {
+		t.Errorf("phase 1 started at %v", phases[0].StartedAt)
+	}
+
+	runs, err := l.GetRuns(ctx, StatsFilter{ComparisonID: comparison})
+	if err != nil {
+		t.Fatalf("GetRuns() error = %v", err)
+	}
+	if len(runs) != 1 || !runs[0].CreatedAt.Equal(created) {
+		t.Fatalf("GetRuns() = %+v", runs)
+	}
+	if runs, _ := l.GetRuns(ctx, StatsFilter{Protocol: "rest"}); len(runs) != 0 {
+		t.Errorf("GetRuns(rest) returned %d runs, want 0", len(runs))
+	}
+
+	var count int
+	var last BenchmarkSample
+	err = l.ForEachSample(ctx, []int64{id}, func(s *BenchmarkSample) error {
+		count++
+		last = *s
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("ForEachSample() error = %v", err)
+	}
+	if count != 10 || last.Success || last.Phase == nil || *last.Phase != 2 || !last.Timestamp.Equal(start.Add(10*time.Second)) ||
+		last.GCPause == nil || !*last.GCPause {
+		t.Errorf("ForEachSample() saw %d samples, last = %+v", count, last)
+	}
+
+	unsynced, err := l.UnsyncedRuns(ctx)
+	if err != nil || len(unsynced) != 1 {
+		t.Errorf("UnsyncedRuns() = %v, %v; want the one run", unsynced, err)
+	}
+}
+
+func TestLocalDB_ClassStats(t *testing.T) {
+	ctx := context.Background()
+	l := testLocalDB(t)
+
+	id, err := l.RecordRun(ctx, &BenchmarkRun{Scenario: "stream-balance", Protocol: "grpc", Concurrency: 2})
+	if err != nil {
+		t.Fatalf("RecordRun() error = %v", err)
+	}
+	stream, balance := "stream", "balance"
+	start := time.Now().Truncate(time.Millisecond)
+	var samples []*BenchmarkSample
+	for i := 1; i <= 6; i++ {
+		s := &BenchmarkSample{RunID: id, LatencyMs: float64(i), Success: i != 6, Timestamp: start.Add(time.Duration(i) * time.Second)}
+		if i%2 == 0 {
+			s.WorkloadClass = &balance
+		} else {
+			s.WorkloadClass = &stream
+		}
+		samples = append(samples, s)
+	}
+	if err := l.RecordSamples(ctx, samples); err != nil {
+		t.Fatalf("RecordSamples() error = %v", err)
+	}
+
+	classes, err := l.GetClassStats(ctx, id)
+	if err != nil {
+		t.Fatalf("GetClassStats() error = %v", err)
+	}
+	if len(classes) != 2 || classes[0].Class != "balance" || classes[1].Class != "stream" {
+		t.Fatalf("GetClassStats() = %+v", classes)
+	}
+	if b := classes[0]; b.TotalSamples != 3 || b.Successful != 2 || b.P50Latency != 4 || !b.EndedAt.Equal(start.Add(6*time.Second)) {
+		t.Errorf("balance = %+v", b)
+	}
+	if s := classes[1]; s.TotalSamples != 3 || s.Successful != 3 || s.P50Latency != 3 {
+		t.Errorf("stream = %+v", s)
+	}
+}
+
+func TestLocalDB_OperationStats(t *testing.T) {
+	ctx := context.Background()
+	l := testLocalDB(t)
+
+	id, err := l.RecordRun(ctx, &BenchmarkRun{Scenario: "mixed", Protocol: "grpc", Concurrency: 2})
+	if err != nil {
+		t.Fatalf("RecordRun() error = %v", err)
+	}
+	get, write := "GetBalance", "SubmitTransaction"
+	start := time.Now().Truncate(time.Millisecond)
+	samples := []*BenchmarkSample{{RunID: id, LatencyMs: 99, Success: true, Timestamp: start}} // stored without an operation
+	for i := 1; i <= 6; i++ {
+		s := &BenchmarkSample{RunID: id, LatencyMs: float64(i), Success: true, Timestamp: start.Add(time.Duration(i) * time.Second), Operation: &get}
+		if i > 4 {
+			s.LatencyMs, s.Operation = float64(10*i), &write
+		}
+		samples = append(samples, s)
+	}
+	if err := l.RecordSamples(ctx, samples); err != nil {
+		t.Fatalf("RecordSamples() error = %v", err)
+	}
+
+	ops, err := l.GetOperationStats(ctx, id)
+	if err != nil {
+		t.Fatalf("GetOperationStats() error = %v", err)
+	}
+	if len(ops) != 2 || ops[0].Operation != get || ops[1].Operation != write {
+		t.Fatalf("GetOperationStats() = %+v", ops)
+	}
+	if g := ops[0]; g.TotalSamples != 4 || g.P50Latency != 2.5 || g.P99Latency > 4 {
+		t.Errorf("GetBalance = %+v", g)
+	}
+	if w := ops[1]; w.TotalSamples != 2 || w.P50Latency != 55 || w.P99Latency < 59 {
+		t.Errorf("SubmitTransaction = %+v", w)
+	}
+}
+
+func TestLocalDB_ErrorCounts(t *testing.T) {
+	ctx := context.Background()
+	l := testLocalDB(t)
+
+	id, err := l.RecordRun(ctx, &BenchmarkRun{Scenario: "balance", Protocol: "rest", Concurrency: 1})
+	if err != nil {
+		t.Fatalf("RecordRun() error = %v", err)
+	}
+	timeout, refused := "timeout", "connection_refused"
+	now := time.Now()
+	samples := []*BenchmarkSample{
+		{RunID: id, LatencyMs: 1, Success: true, Timestamp: now},
+		{RunID: id, LatencyMs: 5, Success: false, ErrorType: &refused, Timestamp: now},
+		{RunID: id, LatencyMs: 9, Success: false, ErrorType: &timeout, Timestamp: now},
+		{RunID: id, LatencyMs: 9, Success: false, ErrorType: &timeout, Timestamp: now},
+		{RunID: id, LatencyMs: 2, Success: false, Timestamp: now},
+	}
+	if err := l.RecordSamples(ctx, samples); err != nil {
+		t.Fatalf("RecordSamples() error = %v", err)
+	}
+
+	counts, err := l.GetErrorCounts(ctx, []int64{id})
+	if err != nil {
+		t.Fatalf("GetErrorCounts() error = %v", err)
+	}
+	want := []ErrorCount{{id, timeout, 2}, {id, refused, 1}, {id, "unknown", 1}}
+	if len(counts) != len(want) {
+		t.Fatalf("GetErrorCounts() = %d types, want %d", len(counts), len(want))
+	}
+	for i, c := range counts {
+		if *c != want[i] {
+			t.Errorf("GetErrorCounts()[%d] = %+v, want %+v", i, *c, want[i])
+		}
+	}
+}
+
+func TestLocalDB_SortAndPage(t *testing.T) {
+	l := testLocalDB(t)
+	ctx := context.Background()
+
+	// Three runs a day apart, with p99 latencies 30, 10 and 20 ms, then one
+	// stored last but run the day before, as 'benchmark sync' stores runs
+	day := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	days := []int{0, 1, 2, -1}
+	var ids []int64
+	for i, latency := range []float64{30, 10, 20, 5} {
+		id, err := l.RecordRun(ctx, &BenchmarkRun{
+			Scenario: "balance", Protocol: "grpc", Concurrency: 1, DurationSec: 1,
+			CreatedAt: day.AddDate(0, 0, days[i]),
+		})
+		if err != nil {
+			t.Fatalf("RecordRun() error = %v", err)
+		}
+		samples := []*BenchmarkSample{{RunID: id, LatencyMs: latency, Success: true, Timestamp: day}}
+		if err := l.RecordSamples(ctx, samples); err != nil {
+			t.Fatalf("RecordSamples() error = %v", err)
+		}
+		ids = append(ids, id)
+	}
+
+	// created is the cursor value of the run created on day d
+	created := func(d int) float64 {
+		return float64(day.AddDate(0, 0, d).UnixMicro())
+	}
+	runIDs := func(stats []*BenchmarkStats) []int64 {
+		var got []int64
+		for _, s := range stats {
+			got = append(got, s.RunID)
+		}
+		return got
+	}
+	tests := []struct {
+		name   string
+		filter StatsFilter
+		want   []int64
+	}{
+		{"newest first", StatsFilter{}, []int64{ids[2], ids[1], ids[0], ids[3]}},
+		{"oldest first, offset", StatsFilter{Ascending: true, Offset: 1}, []int64{ids[0], ids[1], ids[2]}},
+		{"after cursor", StatsFilter{After: &Cursor{Value: created(2), RunID: ids[2]}, Limit: 1}, []int64{ids[1]}},
+		{"after cursor, synced run", StatsFilter{After: &Cursor{Value: created(0), RunID: ids[0]}}, []int64{ids[3]}},
+		{"p99 highest first", StatsFilter{Sort: SortP99}, []int64{ids[0], ids[2], ids[1], ids[3]}},
+		{"p99 lowest first, limit", StatsFilter{Sort: SortP99, Ascending: true, Limit: 2}, []int64{ids[3], ids[1]}},
+		{"p99 after cursor", StatsFilter{Sort: SortP99, After: &Cursor{Value: 30, RunID: ids[0]}}, []int64{ids[2], ids[1], ids[3]}},
+		{"p99 offset", StatsFilter{Sort: SortP99, Offset: 2}, []int64{ids[1], ids[3]}},
+		{"date range", StatsFilter{Since: day.AddDate(0, 0, 1), Until: day.AddDate(0, 0, 2)}, []int64{ids[1]}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stats, err := l.GetFilteredStats(ctx, tt.filter)
+			if err != nil {
+				t.Fatalf("GetFilteredStats() error = %v", err)
+			}
+			if got := runIDs(stats); !slices.Equal(got, tt.want) {
+				t.Errorf("GetFilteredStats() runs = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := l.GetFilteredStats(ctx, StatsFilter{Sort: "p50"}); err == nil {
+		t.Error("GetFilteredStats(sort=p50) = nil error, want an error")
+	}
+	if _, err := l.GetRuns(ctx, StatsFilter{Sort: SortThroughput}); err == nil {
+		t.Error("GetRuns(sort=throughput) = nil error, want an error")
+	}
+}
+
+func TestLocalDB_AccountIDs(t *testing.T) {
+	l := testLocalDB(t)
+	ctx := context.Background()
+
+	if err := l.SaveAccountIDs(ctx, []string{"0.0.1001", "0.0.1002"}); err != nil {
+		t.Fatalf("SaveAccountIDs() error = %v", err)
+	}
+	if err := l.SaveAccountIDs(ctx, []string{"0.0.2001"}); err != nil {
+		t.Fatalf("SaveAccountIDs() error = %v", err)
+	}
+	ids, err := l.GetAllAccountIDs(ctx)
+	if err != nil {
+		t.Fatalf("GetAllAccountIDs() error = %v", err)
+	}
+	if len(ids) != 1 || ids[0] != "0.0.2001" {
+		t.Errorf("GetAllAccountIDs() = %v, want the replaced cache", ids)
+	}
+}
+
+func TestLocalDB_SyncTo(t *testing.T) {
+	pg := testDB(t)
+	defer pg.Close()
+	l := testLocalDB(t)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	id, err := l.RecordRun(ctx, &BenchmarkRun{Scenario: "balance", Protocol: "rest", Client: "go-test", Concurrency: 1, DurationSec: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := l.RecordSamples(ctx, []*BenchmarkSample{{RunID: id, LatencyMs: 1.5, Success: true, Timestamp: time.Now()}}); err != nil {
+		t.Fatal(err)
+	}
+
+	var remoteID int64
+	n, err := l.SyncTo(ctx, pg, func(localID, remote int64, samples int) {
+		remoteID = remote
+		if samples != 1 {
+			t.Errorf("uploaded %d samples, want 1", samples)
+		}
+	})
+	if err != nil || n != 1 {
+		t.Fatalf("SyncTo() = %d, %v; want 1 run", n, err)
+	}
+	defer pg.DeleteRun(ctx, remoteID)
+
+	if n, err := l.SyncTo(ctx, pg, nil); err != nil || n != 0 {
+		t.Errorf("second SyncTo() = %d, %v; want nothing left to upload", n, err)
+	}
+}
+
+func TestLocalDB_Timeseries(t *testing.T) {
+	l := testLocalDB(t)
+	ctx := context.Background()
+
+	id, err := l.RecordRun(ctx, &BenchmarkRun{Scenario: "echo", Protocol: "grpc", Concurrency: 1, DurationSec: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p50, p99 := 1.5, 9.0
+	err = l.RecordTimeseries(ctx, id, []TimeseriesPoint{
+		{ElapsedSec: 1, Requests: 10, Errors: 10},
+		{ElapsedSec: 0, Requests: 20, P50LatencyMs: &p50, P99LatencyMs: &p99},
+	})
+	if err != nil {
+		t.Fatalf("RecordTimeseries() error = %v", err)
+	}
+
+	points, err := l.GetTimeseries(ctx, id)
+	if err != nil {
+		t.Fatalf("GetTimeseries() error = %v", err)
+	}
+	if len(points) != 2 || points[0].ElapsedSec != 0 || points[0].RunID != id {
+		t.Fatalf("GetTimeseries() = %+v, want two points ordered by second", points)
+	}
+	if points[0].P99LatencyMs == nil || *points[0].P99LatencyMs != p99 {
+		t.Errorf("second 0 p99 = %v, want %v", points[0].P99LatencyMs, p99)
+	}
+	if points[1].Errors != 10 || points[1].P50LatencyMs != nil {
+		t.Errorf("second 1 = %+v, want 10 errors and no latency", points[1])
+	}
+}
@@ -0,0 +1,54 @@
+package db
+
+import (
+	"sync"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// PoolStats are cumulative connection acquire counters of every database
+// pool the process has opened, closed pools included, so they survive a
+// server switching database targets.
+type PoolStats struct {
+	EmptyAcquires    int64 // acquires that waited because every connection was in use
+	CanceledAcquires int64 // acquires given up before a connection was free: pool exhaustion
+}
+
+// pools tracks the open pools and the counters of closed ones.
+var pools = struct {
+	mu     sync.Mutex
+	open   map[*pgxpool.Pool]struct{}
+	closed PoolStats
+}{open: make(map[*pgxpool.Pool]struct{})}
+
+func trackPool(pool *pgxpool.Pool) {
+	pools.mu.Lock()
+	pools.open[pool] = struct{}{}
+	pools.mu.Unlock()
+}
+
+func untrackPool(pool *pgxpool.Pool) {
+	pools.mu.Lock()
+	defer pools.mu.Unlock()
+	if _, ok := pools.open[pool]; !ok {
+		return
+	}
+	delete(pools.open, pool)
+	pools.closed.add(pool.Stat())
+}
+
+func (s *PoolStats) add(stat *pgxpool.Stat) {
+	s.EmptyAcquires += stat.EmptyAcquireCount()
+	s.CanceledAcquires += stat.CanceledAcquireCount()
+}
+
+// AllPoolStats returns the acquire counters of the process's pools.
+func AllPoolStats() PoolStats {
+	pools.mu.Lock()
+	defer pools.mu.Unlock()
+	stats := pools.closed
+	for pool := range pools.open {
+		stats.add(pool.Stat())
+	}
+	return stats
+}
@@ -0,0 +1,99 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// ErrProfileNotFound is returned when a run has no profile of the
+// requested name.
+var ErrProfileNotFound = errors.New("profile not found")
+
+// maxProfileSize bounds an attached profile; CPU profiles of even long
+// runs are a few MB.
+const maxProfileSize = 64 << 20
+
+// profileNamePattern matches profile names like "server-cpu".
+var profileNamePattern = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+
+// RunProfile is a pprof profile attached to a run, named by its source and
+// kind, e.g. "server-cpu" or "client-heap".
+type RunProfile struct {
+	RunID     int64
+	Name      string
+	Size      int
+	Data      []byte // nil when listed
+	CreatedAt time.Time
+}
+
+// AttachProfile stores a profile with a run, replacing one of the same
+// name. Profiles are deleted with their run.
+func (db *DB) AttachProfile(ctx context.Context, runID int64, name string, data []byte) error {
+	if !profileNamePattern.MatchString(name) || len(name) > 63 {
+		return fmt.Errorf("invalid profile name %q", name)
+	}
+	if len(data) == 0 || len(data) > maxProfileSize {
+		return fmt.Errorf("profile %s is %d bytes, must be between 1 and %d", name, len(data), maxProfileSize)
+	}
+	_, err := db.Pool.Exec(ctx,
+		`INSERT INTO run_profiles (run_id, name, data)
+		 VALUES ($1, $2, $3)
+		 ON CONFLICT (run_id, name) DO UPDATE SET data = EXCLUDED.data, created_at = NOW()`,
+		runID, name, data,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to attach profile: %w", err)
+	}
+	return nil
+}
+
+// ListProfiles retrieves the profiles attached to a run, without their
+// data, ordered by name.
+func (db *DB) ListProfiles(ctx context.Context, runID int64) ([]*RunProfile, error) {
+	rows, err := db.Pool.Query(ctx,
+		`SELECT run_id, name, octet_length(data), created_at FROM run_profiles WHERE run_id = $1 ORDER BY name`,
+		runID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query profiles: %w", err)
+	}
+	defer rows.Close()
+
+	var profiles []*RunProfile
+	for rows.Next() {
+		var p RunProfile
+		if err := rows.Scan(&p.RunID, &p.Name, &p.Size, &p.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan profile row: %w", err)
+		}
+		profiles = append(profiles, &p)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating profile rows: %w", err)
+	}
+
+	return profiles, nil
+}
+
+// GetProfile retrieves a profile attached to a run with its data, or
+// ErrProfileNotFound.
+func (db *DB) GetProfile(ctx context.Context, runID int64, name string) (*RunProfile, error) {
+	var p RunProfile
+	err := db.Pool.QueryRow(ctx,
+		`SELECT run_id, name, data, created_at FROM run_profiles WHERE run_id = $1 AND name = $2`,
+		runID, name,
+	).Scan(&p.RunID, &p.Name, &p.Data, &p.CreatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrProfileNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get profile: %w", err)
+	}
+	p.Size = len(p.Data)
+	return &p, nil
+}
@@ -0,0 +1,57 @@
+package db
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestRunProfiles(t *testing.T) {
+	db := testDB(t)
+	defer db.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	runID, err := db.RecordRun(ctx, &BenchmarkRun{Scenario: "balance", Protocol: "grpc", Client: "go-test-profiles", Concurrency: 1, DurationSec: 1})
+	if err != nil {
+		t.Fatalf("RecordRun() error = %v", err)
+	}
+	defer db.DeleteRun(ctx, runID)
+
+	if err := db.AttachProfile(ctx, runID, "server-cpu", []byte("old")); err != nil {
+		t.Fatalf("AttachProfile() error = %v", err)
+	}
+	// Attaching again replaces the profile
+	data := []byte("profile data")
+	if err := db.AttachProfile(ctx, runID, "server-cpu", data); err != nil {
+		t.Fatalf("AttachProfile() again error = %v", err)
+	}
+	if err := db.AttachProfile(ctx, runID, "client-heap", data); err != nil {
+		t.Fatalf("AttachProfile() error = %v", err)
+	}
+	if err := db.AttachProfile(ctx, runID, "../cpu", data); err == nil {
+		t.Error("AttachProfile() with an invalid name = nil, want an error")
+	}
+
+	profiles, err := db.ListProfiles(ctx, runID)
+	if err != nil {
+		t.Fatalf("ListProfiles() error = %v", err)
+	}
+	if len(profiles) != 2 || profiles[0].Name != "client-heap" || profiles[1].Size != len(data) || profiles[1].Data != nil {
+		t.Errorf("ListProfiles() = %+v, want client-heap and server-cpu without data", profiles)
+	}
+
+	p, err := db.GetProfile(ctx, runID, "server-cpu")
+	if err != nil {
+		t.Fatalf("GetProfile() error = %v", err)
+	}
+	if !bytes.Equal(p.Data, data) {
+		t.Errorf("GetProfile().Data = %q, want %q", p.Data, data)
+	}
+	if _, err := db.GetProfile(ctx, runID, "server-heap"); !errors.Is(err, ErrProfileNotFound) {
+		t.Errorf("GetProfile() of a missing profile error = %v, want ErrProfileNotFound", err)
+	}
+}
@@ -0,0 +1,95 @@
+package db
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// Transaction isolation levels for balance queries.
+const (
+	IsolationReadCommitted  = "read-committed"
+	IsolationRepeatableRead = "repeatable-read"
+	IsolationSerializable   = "serializable"
+)
+
+// Isolations lists the valid isolation levels. The empty level leaves the
+// server default in place.
+var Isolations = []string{IsolationReadCommitted, IsolationRepeatableRead, IsolationSerializable}
+
+var isoLevels = map[string]pgx.TxIsoLevel{
+	IsolationReadCommitted:  pgx.ReadCommitted,
+	IsolationRepeatableRead: pgx.RepeatableRead,
+	IsolationSerializable:   pgx.Serializable,
+}
+
+// QueryTx configures the transaction balance queries run in. The zero value
+// runs each query as its own implicit transaction; otherwise every query is
+// wrapped in BEGIN ... COMMIT with the given isolation level and access mode.
+// Some managed PostgreSQL setups route read-only transactions to replicas.
+type QueryTx struct {
+	Isolation string // one of Isolations, empty for the server default
+	ReadOnly  bool   // BEGIN READ ONLY, equivalent to SET TRANSACTION READ ONLY
+}
+
+// RegisterFlags registers the query transaction flags on fs.
+func (q *QueryTx) RegisterFlags(fs *flag.FlagSet) {
+	fs.StringVar(&q.Isolation, "tx-isolation", "", "Run balance queries in an explicit transaction at this isolation level: "+strings.Join(Isolations, " | ")+" (default: no explicit transaction)")
+	fs.BoolVar(&q.ReadOnly, "tx-read-only", false, "Run balance queries in an explicit read-only transaction")
+}
+
+// Validate checks the isolation level.
+func (q QueryTx) Validate() error {
+	if q.Isolation != "" && !slices.Contains(Isolations, q.Isolation) {
+		return fmt.Errorf("invalid tx isolation: %s (must be one of: %s)", q.Isolation, strings.Join(Isolations, ", "))
+	}
+	return nil
+}
+
+// String describes the transaction as recorded with each run, e.g.
+// "repeatable-read,read-only", or "" when queries run without one.
+func (q QueryTx) String() string {
+	var parts []string
+	if q.Isolation != "" {
+		parts = append(parts, q.Isolation)
+	}
+	if q.ReadOnly {
+		parts = append(parts, "read-only")
+	}
+	return strings.Join(parts, ",")
+}
+
+// SetQueryTx sets the transaction GetBalance and GetBalances run in. It is
+// not safe to call while queries are running.
+func (db *DB) SetQueryTx(q QueryTx) {
+	if q == (QueryTx{}) {
+		db.queryTx = nil
+		return
+	}
+	opts := pgx.TxOptions{IsoLevel: isoLevels[q.Isolation]}
+	if q.ReadOnly {
+		opts.AccessMode = pgx.ReadOnly
+	}
+	db.queryTx = &opts
+}
+
+// querier is the query interface shared by the pool and a transaction.
+type querier interface {
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+// inQueryTx runs fn in the configured query transaction, or directly on the
+// pool if there is none.
+func (db *DB) inQueryTx(ctx context.Context, fn func(q querier) error) error {
+	if db.queryTx == nil {
+		return fn(db.Pool)
+	}
+	return pgx.BeginTxFunc(ctx, db.Pool, *db.queryTx, func(tx pgx.Tx) error {
+		return fn(tx)
+	})
+}
@@ -0,0 +1,60 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestQueryTx_String(t *testing.T) {
+	tests := []struct {
+		tx   QueryTx
+		want string
+	}{
+		{QueryTx{}, ""},
+		{QueryTx{ReadOnly: true}, "read-only"},
+		{QueryTx{Isolation: IsolationSerializable}, "serializable"},
+		{QueryTx{Isolation: IsolationRepeatableRead, ReadOnly: true}, "repeatable-read,read-only"},
+	}
+	for _, tt := range tests {
+		if got := tt.tx.String(); got != tt.want {
+			t.Errorf("%+v.String() = %q, want %q", tt.tx, got, tt.want)
+		}
+	}
+}
+
+func TestQueryTx_Validate(t *testing.T) {
+	for _, iso := range append([]string{""}, Isolations...) {
+		if err := (QueryTx{Isolation: iso}).Validate(); err != nil {
+			t.Errorf("Validate(%q) error = %v", iso, err)
+		}
+	}
+	if err := (QueryTx{Isolation: "snapshot"}).Validate(); err == nil {
+		t.Error("Validate(\"snapshot\") succeeded, want an error")
+	}
+}
+
+func TestSetQueryTx(t *testing.T) {
+	db := testDB(t)
+	defer db.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	accountID, err := db.GetRandomAccountID(ctx)
+	if err != nil {
+		t.Fatalf("GetRandomAccountID() error = %v", err)
+	}
+
+	db.SetQueryTx(QueryTx{Isolation: IsolationRepeatableRead, ReadOnly: true})
+	if _, err := db.GetBalance(ctx, accountID); err != nil {
+		t.Errorf("GetBalance() in a read-only transaction error = %v", err)
+	}
+	accounts, err := db.GetBalances(ctx, []string{accountID})
+	if err != nil {
+		t.Fatalf("GetBalances() in a read-only transaction error = %v", err)
+	}
+	if len(accounts) != 1 {
+		t.Errorf("GetBalances() returned %d accounts, want 1", len(accounts))
+	}
+}
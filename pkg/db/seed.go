@@ -0,0 +1,71 @@
+package db
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// DatasetSource yields the accounts and transactions LoadDataset copies.
+// Each method returns nil once its rows are exhausted.
+type DatasetSource interface {
+	NextAccount() *Account
+	NextTransaction() *Transaction
+}
+
+// LoadDataset replaces the accounts and transactions with the rows from src,
+// bulk-loaded with COPY in a single transaction, and returns how many of
+// each were loaded. Benchmark results are kept; runs record the dataset
+// fingerprint they were measured against.
+func (db *DB) LoadDataset(ctx context.Context, src DatasetSource) (accounts, transactions int64, err error) {
+	tx, err := db.Pool.Begin(ctx)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to begin dataset load: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, `TRUNCATE accounts, transactions`); err != nil {
+		return 0, 0, fmt.Errorf("failed to clear dataset: %w", err)
+	}
+
+	accounts, err = tx.CopyFrom(ctx,
+		pgx.Identifier{"accounts"},
+		[]string{"account_id", "balance_tinybar", "updated_at"},
+		pgx.CopyFromFunc(func() ([]any, error) {
+			acc := src.NextAccount()
+			if acc == nil {
+				return nil, nil
+			}
+			return []any{acc.AccountID, acc.Balance, acc.UpdatedAt}, nil
+		}),
+	)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to copy accounts: %w", err)
+	}
+
+	transactions, err = tx.CopyFrom(ctx,
+		pgx.Identifier{"transactions"},
+		[]string{"tx_id", "from_account", "to_account", "amount_tinybar", "tx_type", "timestamp"},
+		pgx.CopyFromFunc(func() ([]any, error) {
+			t := src.NextTransaction()
+			if t == nil {
+				return nil, nil
+			}
+			return []any{t.TxID, t.FromAccount, t.ToAccount, t.Amount, t.TxType, t.Timestamp}, nil
+		}),
+	)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to copy transactions: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, 0, fmt.Errorf("failed to commit dataset load: %w", err)
+	}
+
+	// Refresh planner statistics for the new table sizes
+	if _, err := db.Pool.Exec(ctx, `ANALYZE accounts, transactions`); err != nil {
+		return accounts, transactions, fmt.Errorf("failed to analyze dataset: %w", err)
+	}
+	return accounts, transactions, nil
+}
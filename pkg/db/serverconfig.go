@@ -0,0 +1,82 @@
+package db
+
+import (
+	"context"
+	"fmt"
+)
+
+// Servers as recorded in server_config: "grpc" serves gRPC and gRPC-Web,
+// "rest" serves REST and Connect.
+const (
+	ServerGRPC = "grpc"
+	ServerREST = "rest"
+)
+
+// ServerConfig is the configuration a server started with that runs against
+// it record.
+type ServerConfig struct {
+	Pools   string // database pools, e.g. "shared=50/1h" or "query=50/1h stream=10/5m"
+	QueryTx string // balance query transaction (QueryTx.String), "" for none
+	Faults  string // injected faults, e.g. "latency=10ms,errors=1%", "" for none
+	Cache   string // balance cache, e.g. "size=10000,ttl=1s", "" for none
+
+	// Middleware is the request middleware, e.g. "log=1%,recovery,metrics",
+	// "" for none.
+	Middleware string
+
+	// RateLimit is the token bucket rejecting requests above a rate, e.g.
+	// "rate=1000/s,burst=1000", "" for none.
+	RateLimit string
+
+	JSONEncoder string // JSON encoder of the REST server, e.g. "sonic", "" for the gRPC server
+
+	// ParityMarshal is the encoder of the REST server's parity responses
+	// (restapi.ParityMarshals), "" for the gRPC server and REST servers
+	// that did not record one, which used protojson.
+	ParityMarshal string
+
+	Commit string // git commit the server was built from, "" if unknown
+}
+
+// RecordServerConfig records the configuration a server started with,
+// replacing that of its previous start, so runs against it can store it.
+func (db *DB) RecordServerConfig(ctx context.Context, server string, cfg ServerConfig) error {
+	_, err := db.Pool.Exec(ctx,
+		`INSERT INTO server_config (server, pools, query_tx, faults, cache, json_encoder, middleware, parity_marshal, rate_limit, git_commit, started_at)
+		 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, NOW())
+		 ON CONFLICT (server) DO UPDATE
+		 SET pools = EXCLUDED.pools, query_tx = EXCLUDED.query_tx, faults = EXCLUDED.faults,
+		     cache = EXCLUDED.cache, json_encoder = EXCLUDED.json_encoder, middleware = EXCLUDED.middleware,
+		     parity_marshal = EXCLUDED.parity_marshal, rate_limit = EXCLUDED.rate_limit, git_commit = EXCLUDED.git_commit,
+		     started_at = EXCLUDED.started_at`,
+		server, cfg.Pools, cfg.QueryTx, cfg.Faults, cfg.Cache, cfg.JSONEncoder, cfg.Middleware, cfg.ParityMarshal, cfg.RateLimit, cfg.Commit,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to record server config: %w", err)
+	}
+	return nil
+}
+
+// GetServerConfigs returns the configuration each server last started with,
+// keyed by server.
+func (db *DB) GetServerConfigs(ctx context.Context) (map[string]ServerConfig, error) {
+	rows, err := db.Pool.Query(ctx, `SELECT server, pools, query_tx, faults, cache, json_encoder, middleware, parity_marshal, rate_limit, git_commit FROM server_config`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query server config: %w", err)
+	}
+	defer rows.Close()
+
+	configs := make(map[string]ServerConfig)
+	for rows.Next() {
+		var server string
+		var cfg ServerConfig
+		if err := rows.Scan(&server, &cfg.Pools, &cfg.QueryTx, &cfg.Faults, &cfg.Cache, &cfg.JSONEncoder, &cfg.Middleware, &cfg.ParityMarshal, &cfg.RateLimit, &cfg.Commit); err != nil {
+			return nil, fmt.Errorf("failed to scan server config row: %w", err)
+		}
+		configs[server] = cfg
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating server config rows: %w", err)
+	}
+	return configs, nil
+}
@@ -0,0 +1,32 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestRecordServerConfig(t *testing.T) {
+	db := testDB(t)
+	defer db.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	const server = "test-server"
+	defer db.Pool.Exec(context.Background(), `DELETE FROM server_config WHERE server = $1`, server)
+
+	latest := ServerConfig{Pools: "query=50/1h stream=10/5m", QueryTx: "repeatable-read,read-only", JSONEncoder: "sonic"}
+	for _, cfg := range []ServerConfig{{Pools: "shared=50/1h"}, latest} {
+		if err := db.RecordServerConfig(ctx, server, cfg); err != nil {
+			t.Fatalf("RecordServerConfig() error = %v", err)
+		}
+	}
+	got, err := db.GetServerConfigs(ctx)
+	if err != nil {
+		t.Fatalf("GetServerConfigs() error = %v", err)
+	}
+	if got[server] != latest {
+		t.Errorf("GetServerConfigs()[%q] = %+v, want the latest start %+v", server, got[server], latest)
+	}
+}
@@ -0,0 +1,32 @@
+package db
+
+import "context"
+
+// ResultsStore records benchmark runs and reads back their samples and
+// aggregated stats. *DB keeps results in PostgreSQL; *LocalDB keeps them in a
+// local SQLite file for runs where PostgreSQL is not reachable.
+type ResultsStore interface {
+	RecordRun(ctx context.Context, run *BenchmarkRun) (int64, error)
+	RecordSamples(ctx context.Context, samples []*BenchmarkSample) error
+	GetStats(ctx context.Context, runID int64) (*BenchmarkStats, error)
+	GetFilteredStats(ctx context.Context, filter StatsFilter) ([]*BenchmarkStats, error)
+	GetPhaseStats(ctx context.Context, runID int64) ([]*PhaseStats, error)
+	GetClassStats(ctx context.Context, runID int64) ([]*ClassStats, error)
+	GetOperationStats(ctx context.Context, runID int64) ([]*OperationStats, error)
+	GetErrorCounts(ctx context.Context, runIDs []int64) ([]*ErrorCount, error)
+	GetRuns(ctx context.Context, filter StatsFilter) ([]*BenchmarkRun, error)
+	ForEachSample(ctx context.Context, runIDs []int64, fn func(*BenchmarkSample) error) error
+	RecordTimeseries(ctx context.Context, runID int64, points []TimeseriesPoint) error
+	GetTimeseries(ctx context.Context, runID int64) ([]TimeseriesPoint, error)
+	FindBaseline(ctx context.Context, runID int64) (baseID int64, ok bool, err error)
+	SetBaseline(ctx context.Context, runID int64, b *Baseline) error
+	CreateSuite(ctx context.Context, s *Suite) error
+	GetSuite(ctx context.Context, id string) (*Suite, error)
+	GetSuiteRuns(ctx context.Context, id string) ([]*BenchmarkRun, error)
+	Close()
+}
+
+var (
+	_ ResultsStore = (*DB)(nil)
+	_ ResultsStore = (*LocalDB)(nil)
+)
@@ -0,0 +1,181 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// Errors returned by the suite functions.
+var (
+	ErrSuiteNotFound = errors.New("suite not found")
+	ErrSuiteExists   = errors.New("suite already exists")
+)
+
+// Suite groups the runs executed together as one comparison, e.g. by
+// `benchmark run --config`. Runs refer to it by BenchmarkRun.SuiteID.
+type Suite struct {
+	ID          string // e.g. "suite-20260101-120000"
+	Name        string
+	Description string
+	CreatedAt   time.Time
+	Runs        int // runs stored with the suite ID, set when read
+}
+
+// CreateSuite stores a new suite, setting its CreatedAt if zero. It returns
+// ErrSuiteExists if a suite with its ID exists.
+func (db *DB) CreateSuite(ctx context.Context, s *Suite) error {
+	if s.CreatedAt.IsZero() {
+		s.CreatedAt = time.Now()
+	}
+	_, err := db.Pool.Exec(ctx,
+		`INSERT INTO benchmark_suites (id, name, description, created_at) VALUES ($1, $2, $3, $4)`,
+		s.ID, s.Name, s.Description, s.CreatedAt,
+	)
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23505" { // unique_violation
+		return ErrSuiteExists
+	}
+	if err != nil {
+		return fmt.Errorf("failed to create suite: %w", err)
+	}
+	return nil
+}
+
+// suiteQuery selects suites with their run counts; append a WHERE clause on
+// s.id or nothing, then suiteGroupBy.
+const suiteQuery = `SELECT s.id, s.name, s.description, s.created_at, COUNT(r.id)
+	 FROM benchmark_suites s
+	 LEFT JOIN benchmark_runs r ON r.suite_id = s.id`
+
+const suiteGroupBy = ` GROUP BY s.id ORDER BY s.created_at DESC, s.id DESC`
+
+// GetSuite retrieves a suite, or ErrSuiteNotFound.
+func (db *DB) GetSuite(ctx context.Context, id string) (*Suite, error) {
+	var s Suite
+	err := db.Pool.QueryRow(ctx, suiteQuery+` WHERE s.id = $1`+suiteGroupBy, id).
+		Scan(&s.ID, &s.Name, &s.Description, &s.CreatedAt, &s.Runs)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrSuiteNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get suite: %w", err)
+	}
+	return &s, nil
+}
+
+// ListSuites retrieves all suites, newest first.
+func (db *DB) ListSuites(ctx context.Context) ([]*Suite, error) {
+	rows, err := db.Pool.Query(ctx, suiteQuery+suiteGroupBy)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query suites: %w", err)
+	}
+	defer rows.Close()
+
+	var suites []*Suite
+	for rows.Next() {
+		var s Suite
+		if err := rows.Scan(&s.ID, &s.Name, &s.Description, &s.CreatedAt, &s.Runs); err != nil {
+			return nil, fmt.Errorf("failed to scan suite row: %w", err)
+		}
+		suites = append(suites, &s)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating suite rows: %w", err)
+	}
+
+	return suites, nil
+}
+
+// GetSuiteRuns retrieves the runs of a suite in the order they ran. It
+// returns ErrSuiteNotFound if there is no such suite.
+func (db *DB) GetSuiteRuns(ctx context.Context, id string) ([]*BenchmarkRun, error) {
+	if _, err := db.GetSuite(ctx, id); err != nil {
+		return nil, err
+	}
+	return suiteRuns(db.GetRuns(ctx, StatsFilter{SuiteID: id}))
+}
+
+// CreateSuite stores a new suite, setting its CreatedAt if zero. It returns
+// ErrSuiteExists if a suite with its ID exists.
+func (l *LocalDB) CreateSuite(ctx context.Context, s *Suite) error {
+	if s.CreatedAt.IsZero() {
+		s.CreatedAt = time.Now()
+	}
+	res, err := l.db.ExecContext(ctx,
+		`INSERT INTO benchmark_suites (id, name, description, created_at) VALUES (?, ?, ?, ?)
+		 ON CONFLICT (id) DO NOTHING`,
+		s.ID, s.Name, s.Description, s.CreatedAt.UnixMicro(),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create suite: %w", err)
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return ErrSuiteExists
+	}
+	return nil
+}
+
+// GetSuite retrieves a suite, or ErrSuiteNotFound.
+func (l *LocalDB) GetSuite(ctx context.Context, id string) (*Suite, error) {
+	var s Suite
+	var createdAt int64
+	err := l.db.QueryRowContext(ctx,
+		`SELECT s.id, s.name, s.description, s.created_at, COUNT(r.id)
+		 FROM benchmark_suites s
+		 LEFT JOIN benchmark_runs r ON r.suite_id = s.id
+		 WHERE s.id = ?
+		 GROUP BY s.id`,
+		id,
+	).Scan(&s.ID, &s.Name, &s.Description, &createdAt, &s.Runs)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrSuiteNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get suite: %w", err)
+	}
+	s.CreatedAt = time.UnixMicro(createdAt)
+	return &s, nil
+}
+
+// GetSuiteRuns retrieves the runs of a suite in the order they ran. It
+// returns ErrSuiteNotFound if there is no such suite.
+func (l *LocalDB) GetSuiteRuns(ctx context.Context, id string) ([]*BenchmarkRun, error) {
+	if _, err := l.GetSuite(ctx, id); err != nil {
+		return nil, err
+	}
+	return suiteRuns(l.GetRuns(ctx, StatsFilter{SuiteID: id}))
+}
+
+// suiteRuns reverses the newest-first runs GetRuns returns.
+func suiteRuns(runs []*BenchmarkRun, err error) ([]*BenchmarkRun, error) {
+	if err != nil {
+		return nil, err
+	}
+	slices.Reverse(runs)
+	return runs, nil
+}
+
+// syncSuite copies the suite of a local run to PostgreSQL unless it is
+// there already.
+func (l *LocalDB) syncSuite(ctx context.Context, pg *DB, id string) error {
+	s, err := l.GetSuite(ctx, id)
+	if errors.Is(err, ErrSuiteNotFound) {
+		// Recorded before suites were stored: keep the ID
+		s, err = &Suite{ID: id, Name: id}, nil
+	}
+	if err != nil {
+		return err
+	}
+	if err := pg.CreateSuite(ctx, s); err != nil && !errors.Is(err, ErrSuiteExists) {
+		return err
+	}
+	return nil
+}
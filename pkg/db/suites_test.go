@@ -0,0 +1,94 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestSuites(t *testing.T) {
+	db := testDB(t)
+	defer db.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	s := &Suite{ID: "suite-test-" + time.Now().Format("150405.000000"), Name: "protocol matrix"}
+	if err := db.CreateSuite(ctx, s); err != nil {
+		t.Fatalf("CreateSuite() error = %v", err)
+	}
+	defer db.Pool.Exec(ctx, `DELETE FROM benchmark_suites WHERE id = $1`, s.ID)
+	if err := db.CreateSuite(ctx, &Suite{ID: s.ID, Name: "again"}); !errors.Is(err, ErrSuiteExists) {
+		t.Errorf("CreateSuite() with a used ID error = %v, want ErrSuiteExists", err)
+	}
+
+	var runIDs []int64
+	for _, protocol := range []string{"grpc", "rest"} {
+		runID, err := db.RecordRun(ctx, &BenchmarkRun{Scenario: "balance", Protocol: protocol, Client: "go-test-suites", Concurrency: 10, DurationSec: 5, SuiteID: &s.ID})
+		if err != nil {
+			t.Fatalf("RecordRun() error = %v", err)
+		}
+		defer db.DeleteRun(ctx, runID)
+		runIDs = append(runIDs, runID)
+	}
+
+	runs, err := db.GetSuiteRuns(ctx, s.ID)
+	if err != nil {
+		t.Fatalf("GetSuiteRuns() error = %v", err)
+	}
+	if len(runs) != 2 || runs[0].ID != runIDs[0] || runs[1].ID != runIDs[1] {
+		t.Errorf("GetSuiteRuns() = %d runs, want runs %v in order", len(runs), runIDs)
+	}
+	if got, err := db.GetSuite(ctx, s.ID); err != nil || got.Name != s.Name || got.Runs != 2 {
+		t.Errorf("GetSuite() = %+v, %v; want the suite with 2 runs", got, err)
+	}
+
+	missing := "suite-missing"
+	if _, err := db.GetSuiteRuns(ctx, missing); !errors.Is(err, ErrSuiteNotFound) {
+		t.Errorf("GetSuiteRuns(%q) error = %v, want ErrSuiteNotFound", missing, err)
+	}
+	if _, err := db.RecordRun(ctx, &BenchmarkRun{Scenario: "balance", Protocol: "grpc", Client: "go-test-suites", SuiteID: &missing}); err == nil {
+		t.Error("RecordRun() with an unknown suite succeeded, want a foreign key error")
+	}
+}
+
+func TestLocalDB_Suites(t *testing.T) {
+	l := testLocalDB(t)
+	ctx := context.Background()
+
+	s := &Suite{ID: "suite-20260101-120000", Name: "protocol matrix", Description: "balance and stream"}
+	if err := l.CreateSuite(ctx, s); err != nil {
+		t.Fatalf("CreateSuite() error = %v", err)
+	}
+	if err := l.CreateSuite(ctx, &Suite{ID: s.ID, Name: "again"}); !errors.Is(err, ErrSuiteExists) {
+		t.Errorf("CreateSuite() with a used ID error = %v, want ErrSuiteExists", err)
+	}
+
+	var runIDs []int64
+	for _, protocol := range []string{"grpc", "rest"} {
+		runID, err := l.RecordRun(ctx, &BenchmarkRun{Scenario: "balance", Protocol: protocol, Concurrency: 10, SuiteID: &s.ID})
+		if err != nil {
+			t.Fatalf("RecordRun() error = %v", err)
+		}
+		runIDs = append(runIDs, runID)
+	}
+	if _, err := l.RecordRun(ctx, &BenchmarkRun{Scenario: "balance", Protocol: "grpc", Concurrency: 10}); err != nil {
+		t.Fatalf("RecordRun() error = %v", err)
+	}
+
+	got, err := l.GetSuite(ctx, s.ID)
+	if err != nil || got.Description != s.Description || got.Runs != 2 || !got.CreatedAt.Equal(s.CreatedAt.Truncate(time.Microsecond)) {
+		t.Errorf("GetSuite() = %+v, %v; want %+v with 2 runs", got, err, s)
+	}
+	runs, err := l.GetSuiteRuns(ctx, s.ID)
+	if err != nil {
+		t.Fatalf("GetSuiteRuns() error = %v", err)
+	}
+	if len(runs) != 2 || runs[0].ID != runIDs[0] || runs[1].ID != runIDs[1] {
+		t.Errorf("GetSuiteRuns() = %d runs, want runs %v in order", len(runs), runIDs)
+	}
+	if _, err := l.GetSuiteRuns(ctx, "suite-missing"); !errors.Is(err, ErrSuiteNotFound) {
+		t.Errorf("GetSuiteRuns() of a missing suite error = %v, want ErrSuiteNotFound", err)
+	}
+}
@@ -0,0 +1,154 @@
+package db
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// TimeseriesPoint aggregates the requests issued in one second of a run.
+type TimeseriesPoint struct {
+	RunID        int64
+	ElapsedSec   int // seconds since the run started
+	Requests     int64
+	Errors       int64
+	P50LatencyMs *float64 // nil if no request in the second succeeded
+	P99LatencyMs *float64
+
+	// Go runtime of the client in the second: peak goroutines, heap objects
+	// and heap MB, and the GC cycles completed and their pause; nil unless
+	// the client measured its own resources
+	Goroutines  *int
+	HeapObjects *int64
+	HeapMB      *float64
+	GCCycles    *int
+	GCPauseMs   *float64
+}
+
+// RecordTimeseries records the per-second aggregates of a run using the
+// PostgreSQL COPY protocol.
+func (db *DB) RecordTimeseries(ctx context.Context, runID int64, points []TimeseriesPoint) error {
+	if len(points) == 0 {
+		return nil
+	}
+
+	rows := make([][]interface{}, len(points))
+	for i, p := range points {
+		rows[i] = []interface{}{runID, p.ElapsedSec, p.Requests, p.Errors, p.P50LatencyMs, p.P99LatencyMs,
+			p.Goroutines, p.HeapObjects, p.HeapMB, p.GCCycles, p.GCPauseMs}
+	}
+
+	_, err := db.Pool.CopyFrom(
+		ctx,
+		pgx.Identifier{"benchmark_timeseries"},
+		[]string{"run_id", "elapsed_sec", "requests", "errors", "p50_latency_ms", "p99_latency_ms",
+			"goroutines", "heap_objects", "heap_mb", "gc_cycles", "gc_pause_ms"},
+		pgx.CopyFromRows(rows),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to copy timeseries: %w", err)
+	}
+	return nil
+}
+
+// GetTimeseries retrieves the per-second aggregates of a run, ordered by
+// second. The result is empty for runs stored without them.
+func (db *DB) GetTimeseries(ctx context.Context, runID int64) ([]TimeseriesPoint, error) {
+	rows, err := db.Pool.Query(ctx,
+		`SELECT run_id, elapsed_sec, requests, errors, p50_latency_ms, p99_latency_ms,
+		        goroutines, heap_objects, heap_mb, gc_cycles, gc_pause_ms
+		 FROM benchmark_timeseries
+		 WHERE run_id = $1
+		 ORDER BY elapsed_sec`,
+		runID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query timeseries: %w", err)
+	}
+	defer rows.Close()
+
+	var points []TimeseriesPoint
+	for rows.Next() {
+		var p TimeseriesPoint
+		if err := rows.Scan(&p.RunID, &p.ElapsedSec, &p.Requests, &p.Errors, &p.P50LatencyMs, &p.P99LatencyMs,
+			&p.Goroutines, &p.HeapObjects, &p.HeapMB, &p.GCCycles, &p.GCPauseMs); err != nil {
+			return nil, fmt.Errorf("failed to scan timeseries row: %w", err)
+		}
+		points = append(points, p)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating timeseries rows: %w", err)
+	}
+
+	return points, nil
+}
+
+// RecordTimeseries records the per-second aggregates of a run in one
+// transaction.
+func (l *LocalDB) RecordTimeseries(ctx context.Context, runID int64, points []TimeseriesPoint) error {
+	if len(points) == 0 {
+		return nil
+	}
+
+	tx, err := l.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.PrepareContext(ctx,
+		`INSERT INTO benchmark_timeseries (run_id, elapsed_sec, requests, errors, p50_latency_ms, p99_latency_ms,
+		        goroutines, heap_objects, heap_mb, gc_cycles, gc_pause_ms)
+		 VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`)
+	if err != nil {
+		return fmt.Errorf("failed to prepare timeseries insert: %w", err)
+	}
+	defer stmt.Close()
+
+	for _, p := range points {
+		if _, err := stmt.ExecContext(ctx, runID, p.ElapsedSec, p.Requests, p.Errors, p.P50LatencyMs, p.P99LatencyMs,
+			p.Goroutines, p.HeapObjects, p.HeapMB, p.GCCycles, p.GCPauseMs); err != nil {
+			return fmt.Errorf("failed to insert timeseries point: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit timeseries: %w", err)
+	}
+	return nil
+}
+
+// GetTimeseries retrieves the per-second aggregates of a run, ordered by
+// second.
+func (l *LocalDB) GetTimeseries(ctx context.Context, runID int64) ([]TimeseriesPoint, error) {
+	rows, err := l.db.QueryContext(ctx,
+		`SELECT run_id, elapsed_sec, requests, errors, p50_latency_ms, p99_latency_ms,
+		        goroutines, heap_objects, heap_mb, gc_cycles, gc_pause_ms
+		 FROM benchmark_timeseries
+		 WHERE run_id = ?
+		 ORDER BY elapsed_sec`,
+		runID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query timeseries: %w", err)
+	}
+	defer rows.Close()
+
+	var points []TimeseriesPoint
+	for rows.Next() {
+		var p TimeseriesPoint
+		if err := rows.Scan(&p.RunID, &p.ElapsedSec, &p.Requests, &p.Errors, &p.P50LatencyMs, &p.P99LatencyMs,
+			&p.Goroutines, &p.HeapObjects, &p.HeapMB, &p.GCCycles, &p.GCPauseMs); err != nil {
+			return nil, fmt.Errorf("failed to scan timeseries row: %w", err)
+		}
+		points = append(points, p)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating timeseries rows: %w", err)
+	}
+
+	return points, nil
+}
@@ -2,6 +2,7 @@ package db
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"time"
 )
@@ -21,10 +22,108 @@ type StreamTransactionsOptions struct {
 	Since         time.Time // Start from this timestamp (zero = beginning)
 	FilterAccount string    // Filter by account (empty = all)
 	Limit         int       // Max transactions to return (0 = no limit)
+
+	// After resumes a stream after this transaction, in stream order, as
+	// the cursor of a reconnecting client. An unknown transaction matches
+	// nothing. Empty starts from Since.
+	After string
+
+	// Live follows transactions as they are submitted instead of querying
+	// stored ones. StreamTransactions does not serve live streams: the
+	// servers' fanout.Dataset does, from their own submissions or from
+	// ListenTransactions, and replays stored transactions without it.
+	Live bool
+}
+
+// TransactionsChannel is the notification channel on which the insert
+// trigger publishes each submitted transaction as JSON.
+const TransactionsChannel = "transactions"
+
+// notifyTimestampLayout is how PostgreSQL renders a timestamp in JSON.
+const notifyTimestampLayout = "2006-01-02T15:04:05.999999"
+
+// ListenTransactions follows the transactions submitted from now on by any
+// server or process, through the notifications of the insert trigger.
+// Seeded transactions are not notified. It holds a connection of its own
+// until ctx is done or the connection fails, and ends with the error.
+func (db *DB) ListenTransactions(ctx context.Context) (<-chan *Transaction, <-chan error) {
+	txCh := make(chan *Transaction, 100)
+	errCh := make(chan error, 1)
+
+	go func() {
+		defer close(txCh)
+		defer close(errCh)
+
+		conn, err := db.Pool.Acquire(ctx)
+		if err != nil {
+			errCh <- fmt.Errorf("failed to acquire a connection to listen on: %w", err)
+			return
+		}
+		// A connection that listened does not go back to the pool
+		listener := conn.Hijack()
+		defer listener.Close(context.Background())
+
+		if _, err := listener.Exec(ctx, "LISTEN "+TransactionsChannel); err != nil {
+			errCh <- fmt.Errorf("failed to listen for transactions: %w", err)
+			return
+		}
+		for {
+			n, err := listener.WaitForNotification(ctx)
+			if err != nil {
+				if ctx.Err() != nil {
+					err = ctx.Err()
+				}
+				errCh <- fmt.Errorf("failed to wait for transactions: %w", err)
+				return
+			}
+			tx, err := parseTransactionNotification(n.Payload)
+			if err != nil {
+				errCh <- err
+				return
+			}
+			select {
+			case txCh <- tx:
+			case <-ctx.Done():
+				errCh <- ctx.Err()
+				return
+			}
+		}
+	}()
+
+	return txCh, errCh
+}
+
+// parseTransactionNotification parses the JSON payload of a transaction
+// notification.
+func parseTransactionNotification(payload string) (*Transaction, error) {
+	var n struct {
+		TxID        string `json:"tx_id"`
+		FromAccount string `json:"from_account"`
+		ToAccount   string `json:"to_account"`
+		Amount      int64  `json:"amount_tinybar"`
+		TxType      string `json:"tx_type"`
+		Timestamp   string `json:"timestamp"`
+	}
+	if err := json.Unmarshal([]byte(payload), &n); err != nil {
+		return nil, fmt.Errorf("invalid transaction notification %q: %w", payload, err)
+	}
+	ts, err := time.Parse(notifyTimestampLayout, n.Timestamp)
+	if err != nil {
+		return nil, fmt.Errorf("invalid transaction notification timestamp %q: %w", n.Timestamp, err)
+	}
+	return &Transaction{
+		TxID:        n.TxID,
+		FromAccount: n.FromAccount,
+		ToAccount:   n.ToAccount,
+		Amount:      n.Amount,
+		TxType:      n.TxType,
+		Timestamp:   ts,
+	}, nil
 }
 
 // StreamTransactions retrieves transactions for streaming.
-// Returns a channel that yields transactions in timestamp order.
+// Returns a channel that yields transactions in timestamp order, ties broken
+// by tx_id so concurrent subscribers see the same sequence.
 func (db *DB) StreamTransactions(ctx context.Context, opts StreamTransactionsOptions) (<-chan *Transaction, <-chan error) {
 	txCh := make(chan *Transaction, 100)
 	errCh := make(chan error, 1)
@@ -37,14 +136,15 @@ func (db *DB) StreamTransactions(ctx context.Context, opts StreamTransactionsOpt
 				  FROM transactions
 				  WHERE ($1::timestamp IS NULL OR timestamp >= $1)
 				    AND ($2 = '' OR from_account = $2 OR to_account = $2)
-				  ORDER BY timestamp ASC`
+				    AND ($3 = '' OR (timestamp, tx_id) > (SELECT timestamp, tx_id FROM transactions WHERE tx_id = $3))
+				  ORDER BY timestamp ASC, tx_id ASC`
 
 		var since *time.Time
 		if !opts.Since.IsZero() {
 			since = &opts.Since
 		}
 
-		rows, err := db.Pool.Query(ctx, query, since, opts.FilterAccount)
+		rows, err := db.Pool.Query(ctx, query, since, opts.FilterAccount, opts.After)
 		if err != nil {
 			errCh <- fmt.Errorf("failed to query transactions: %w", err)
 			return
@@ -86,7 +186,7 @@ func (db *DB) GetTransactions(ctx context.Context, opts StreamTransactionsOption
 			  FROM transactions
 			  WHERE ($1::timestamp IS NULL OR timestamp >= $1)
 			    AND ($2 = '' OR from_account = $2 OR to_account = $2)
-			  ORDER BY timestamp ASC`
+			  ORDER BY timestamp ASC, tx_id ASC`
 
 	if opts.Limit > 0 {
 		query += fmt.Sprintf(" LIMIT %d", opts.Limit)
@@ -119,6 +219,59 @@ func (db *DB) GetTransactions(ctx context.Context, opts StreamTransactionsOption
 	return transactions, nil
 }
 
+// Validate checks a submitted transaction: both accounts must be set and
+// differ, and the amount must be positive.
+func (tx *Transaction) Validate() error {
+	if tx.FromAccount == "" || tx.ToAccount == "" {
+		return fmt.Errorf("from and to accounts are required")
+	}
+	if tx.FromAccount == tx.ToAccount {
+		return fmt.Errorf("from and to accounts must differ")
+	}
+	if tx.Amount <= 0 {
+		return fmt.Errorf("amount must be positive")
+	}
+	return nil
+}
+
+// InsertTransaction stores a new transaction, filling in the fields the
+// submitter leaves empty: the timestamp defaults to now, the type to
+// "transfer", and the ID is derived from the payer and the timestamp as a
+// Hedera transaction ID is. The row is marked as submitted, which keeps it
+// out of the dataset fingerprint. Balances are not updated.
+func (db *DB) InsertTransaction(ctx context.Context, tx *Transaction) error {
+	if tx.Timestamp.IsZero() {
+		tx.Timestamp = time.Now().UTC()
+	}
+	if tx.TxType == "" {
+		tx.TxType = "transfer"
+	}
+	if tx.TxID == "" {
+		tx.TxID = fmt.Sprintf("%s@%d.%09d", tx.FromAccount, tx.Timestamp.Unix(), tx.Timestamp.Nanosecond())
+	}
+
+	_, err := db.Pool.Exec(ctx,
+		`INSERT INTO transactions (tx_id, from_account, to_account, amount_tinybar, tx_type, timestamp, submitted)
+		 VALUES ($1, $2, $3, $4, $5, $6, TRUE)`,
+		tx.TxID, tx.FromAccount, tx.ToAccount, tx.Amount, tx.TxType, tx.Timestamp,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to insert transaction %s: %w", tx.TxID, err)
+	}
+	return nil
+}
+
+// GetSubmittedCount returns the number of transactions submitted by the
+// write scenario.
+func (db *DB) GetSubmittedCount(ctx context.Context) (int64, error) {
+	var count int64
+	err := db.Pool.QueryRow(ctx, `SELECT COUNT(*) FROM transactions WHERE submitted`).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get submitted transaction count: %w", err)
+	}
+	return count, nil
+}
+
 // GetTransactionCount returns the total number of transactions.
 func (db *DB) GetTransactionCount(ctx context.Context) (int64, error) {
 	var count int64
@@ -2,6 +2,7 @@ package db
 
 import (
 	"context"
+	"errors"
 	"testing"
 	"time"
 )
@@ -136,6 +137,40 @@ func TestStreamTransactions(t *testing.T) {
 	}
 }
 
+func TestStreamTransactions_After(t *testing.T) {
+	db := testDB(t)
+	defer db.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	all, err := db.GetTransactions(ctx, StreamTransactionsOptions{Limit: 10})
+	if err != nil {
+		t.Fatalf("GetTransactions() error = %v", err)
+	}
+	if len(all) < 2 {
+		t.Skip("need at least 2 transactions")
+	}
+
+	// Resuming after the first transaction continues with the second
+	txCh, errCh := db.StreamTransactions(ctx, StreamTransactionsOptions{After: all[0].TxID, Limit: len(all) - 1})
+	var resumed []*Transaction
+	for tx := range txCh {
+		resumed = append(resumed, tx)
+	}
+	if err := <-errCh; err != nil {
+		t.Fatalf("StreamTransactions() error = %v", err)
+	}
+	if len(resumed) != len(all)-1 {
+		t.Fatalf("resumed stream yielded %d transactions, want %d", len(resumed), len(all)-1)
+	}
+	for i, tx := range resumed {
+		if tx.TxID != all[i+1].TxID {
+			t.Errorf("resumed transaction %d = %s, want %s", i, tx.TxID, all[i+1].TxID)
+		}
+	}
+}
+
 func TestStreamTransactions_Cancellation(t *testing.T) {
 	db := testDB(t)
 	defer db.Close()
@@ -191,3 +226,113 @@ func TestGetTransactionCount(t *testing.T) {
 		t.Errorf("GetTransactionCount() = %d, want > 0", count)
 	}
 }
+
+func TestTransaction_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		tx      Transaction
+		wantErr bool
+	}{
+		{"valid", Transaction{FromAccount: "0.0.1001", ToAccount: "0.0.1002", Amount: 1}, false},
+		{"missing from", Transaction{ToAccount: "0.0.1002", Amount: 1}, true},
+		{"missing to", Transaction{FromAccount: "0.0.1001", Amount: 1}, true},
+		{"same account", Transaction{FromAccount: "0.0.1001", ToAccount: "0.0.1001", Amount: 1}, true},
+		{"zero amount", Transaction{FromAccount: "0.0.1001", ToAccount: "0.0.1002"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.tx.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestInsertTransaction(t *testing.T) {
+	db := testDB(t)
+	defer db.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	tx := &Transaction{FromAccount: "0.0.1001", ToAccount: "0.0.1002", Amount: 500}
+	if err := db.InsertTransaction(ctx, tx); err != nil {
+		t.Fatalf("InsertTransaction() error = %v", err)
+	}
+	defer db.Pool.Exec(context.Background(), `DELETE FROM transactions WHERE tx_id = $1`, tx.TxID)
+
+	if tx.TxID == "" || tx.Timestamp.IsZero() {
+		t.Errorf("InsertTransaction() left TxID %q, Timestamp %v unset", tx.TxID, tx.Timestamp)
+	}
+	if tx.TxType != "transfer" {
+		t.Errorf("TxType = %q, want transfer", tx.TxType)
+	}
+
+	var amount int64
+	var submitted bool
+	if err := db.Pool.QueryRow(ctx, `SELECT amount_tinybar, submitted FROM transactions WHERE tx_id = $1`, tx.TxID).Scan(&amount, &submitted); err != nil {
+		t.Fatalf("stored transaction not found: %v", err)
+	}
+	if amount != 500 || !submitted {
+		t.Errorf("stored amount = %d, submitted = %v, want 500 and submitted", amount, submitted)
+	}
+}
+
+func TestListenTransactions(t *testing.T) {
+	db := testDB(t)
+	defer db.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	listenCtx, stop := context.WithCancel(ctx)
+	defer stop()
+	txs, errs := db.ListenTransactions(listenCtx)
+	// LISTEN takes effect asynchronously; insert until the first arrives
+	var inserted []string
+	defer func() {
+		db.Pool.Exec(context.Background(), `DELETE FROM transactions WHERE tx_id = ANY($1)`, inserted)
+	}()
+	var got *Transaction
+	for got == nil {
+		tx := &Transaction{FromAccount: "0.0.1001", ToAccount: "0.0.1002", Amount: 7}
+		if err := db.InsertTransaction(ctx, tx); err != nil {
+			t.Fatalf("InsertTransaction() error = %v", err)
+		}
+		inserted = append(inserted, tx.TxID)
+		select {
+		case got = <-txs:
+		case err := <-errs:
+			t.Fatalf("ListenTransactions() error = %v", err)
+		case <-time.After(100 * time.Millisecond):
+		}
+	}
+	if got.FromAccount != "0.0.1001" || got.ToAccount != "0.0.1002" || got.Amount != 7 || got.Timestamp.IsZero() {
+		t.Errorf("notified transaction = %+v, want the inserted one", got)
+	}
+
+	stop()
+	for range txs {
+	}
+	if err := <-errs; !errors.Is(err, context.Canceled) {
+		t.Errorf("ListenTransactions() error after cancel = %v, want context.Canceled", err)
+	}
+}
+
+func TestParseTransactionNotification(t *testing.T) {
+	tx, err := parseTransactionNotification(`{"tx_id":"0.0.1@1.5","from_account":"0.0.1","to_account":"0.0.2","amount_tinybar":42,"tx_type":"transfer","timestamp":"2026-10-18T12:00:01.5"}`)
+	if err != nil {
+		t.Fatalf("parseTransactionNotification() error = %v", err)
+	}
+	want := Transaction{TxID: "0.0.1@1.5", FromAccount: "0.0.1", ToAccount: "0.0.2", Amount: 42, TxType: "transfer",
+		Timestamp: time.Date(2026, 10, 18, 12, 0, 1, 500_000_000, time.UTC)}
+	if *tx != want {
+		t.Errorf("parseTransactionNotification() = %+v, want %+v", *tx, want)
+	}
+
+	for _, payload := range []string{`not json`, `{"timestamp":"yesterday"}`} {
+		if _, err := parseTransactionNotification(payload); err == nil {
+			t.Errorf("parseTransactionNotification(%q) error = nil", payload)
+		}
+	}
+}
@@ -0,0 +1,118 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// TrendMetrics maps the metrics GetTrends can follow to their expression
+// over benchmark_stats: latency percentiles in ms, requests per second, and
+// the percentage of failed requests.
+var TrendMetrics = map[string]string{
+	"p50":        "p50_latency",
+	"p90":        "p90_latency",
+	"p99":        "p99_latency",
+	"avg":        "avg_latency",
+	"throughput": "total_samples::float8 / NULLIF(duration_sec, 0)",
+	"error_rate": "(total_samples - successful) * 100.0 / NULLIF(total_samples, 0)",
+}
+
+// TrendMetricNames returns the names of TrendMetrics, sorted.
+func TrendMetricNames() []string {
+	names := make([]string, 0, len(TrendMetrics))
+	for name := range TrendMetrics {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// TrendQuery selects a metric to follow across historical runs and how to
+// downsample it.
+type TrendQuery struct {
+	Metric string        // one of TrendMetrics
+	Window time.Duration // how far back from now to look
+	Points int           // buckets the window is divided into
+}
+
+// Validate checks that the metric is known and the window and number of
+// points are positive.
+func (q TrendQuery) Validate() error {
+	if _, ok := TrendMetrics[q.Metric]; !ok {
+		return fmt.Errorf("unknown metric %q (must be one of %s)", q.Metric, strings.Join(TrendMetricNames(), ", "))
+	}
+	if q.Window <= 0 {
+		return fmt.Errorf("window must be positive")
+	}
+	if q.Points < 1 {
+		return fmt.Errorf("points must be at least 1")
+	}
+	return nil
+}
+
+// Bucket returns the width of one downsampled point, the window divided
+// by the number of points, in whole seconds.
+func (q TrendQuery) Bucket() time.Duration {
+	bucket := (q.Window / time.Duration(q.Points)).Truncate(time.Second)
+	if bucket < time.Second {
+		return time.Second
+	}
+	return bucket
+}
+
+// TrendPoint averages a metric over the runs of one configuration that
+// started in one bucket.
+type TrendPoint struct {
+	Scenario string
+	Protocol string
+	Client   string
+	Bucket   time.Time // start of the bucket
+	Runs     int64
+	Value    float64
+}
+
+// GetTrends averages the query's metric over the runs matching the filter
+// that started within the window, per scenario, protocol, client and
+// bucket, ordered by bucket within each configuration. Buckets without
+// runs are omitted. Runs with a load profile are left out because their
+// aggregates mix load levels. The filter's Limit is ignored.
+func (db *DB) GetTrends(ctx context.Context, filter StatsFilter, q TrendQuery) ([]*TrendPoint, error) {
+	if err := q.Validate(); err != nil {
+		return nil, err
+	}
+
+	where, args := filter.where("run_id")
+	args = append(args, q.Window.Seconds(), q.Bucket().Seconds())
+	window, bucket := len(args)-1, len(args)
+	query := fmt.Sprintf(`SELECT scenario, protocol, client,
+	                 to_timestamp(floor(extract(epoch FROM created_at)::float8 / $%[2]d::float8) * $%[2]d::float8) AS bucket,
+	                 COUNT(*), COALESCE(AVG(%[3]s), 0)
+	          FROM benchmark_stats`+where+` AND load_profile IS NULL
+	            AND created_at >= NOW() - make_interval(secs => $%[1]d::float8)
+	          GROUP BY scenario, protocol, client, bucket
+	          ORDER BY scenario, protocol, client, bucket`, window, bucket, TrendMetrics[q.Metric])
+
+	rows, err := db.Pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query trends: %w", err)
+	}
+	defer rows.Close()
+
+	var points []*TrendPoint
+	for rows.Next() {
+		var p TrendPoint
+		if err := rows.Scan(&p.Scenario, &p.Protocol, &p.Client, &p.Bucket, &p.Runs, &p.Value); err != nil {
+			return nil, fmt.Errorf("failed to scan trend row: %w", err)
+		}
+		points = append(points, &p)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating trend rows: %w", err)
+	}
+
+	return points, nil
+}
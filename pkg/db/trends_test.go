@@ -0,0 +1,84 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestTrendQuery(t *testing.T) {
+	q := TrendQuery{Metric: "p99", Window: 90 * 24 * time.Hour, Points: 90}
+	if err := q.Validate(); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+	if got := q.Bucket(); got != 24*time.Hour {
+		t.Errorf("Bucket() = %v, want 24h", got)
+	}
+	if got := (TrendQuery{Metric: "p99", Window: time.Second, Points: 10}).Bucket(); got != time.Second {
+		t.Errorf("Bucket() of a short window = %v, want 1s", got)
+	}
+
+	for _, bad := range []TrendQuery{
+		{Metric: "p95", Window: time.Hour, Points: 10},
+		{Metric: "p99", Window: 0, Points: 10},
+		{Metric: "p99", Window: time.Hour, Points: 0},
+	} {
+		if err := bad.Validate(); err == nil {
+			t.Errorf("Validate(%+v) = nil, want an error", bad)
+		}
+	}
+}
+
+func TestGetTrends(t *testing.T) {
+	db := testDB(t)
+	defer db.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	const client = "go-test-trends"
+	now := time.Now()
+	// Two runs today, one ten days ago and one outside the window
+	for i, age := range []time.Duration{0, 0, 10 * 24 * time.Hour, 100 * 24 * time.Hour} {
+		runID, err := db.RecordRun(ctx, &BenchmarkRun{
+			Scenario:    "balance",
+			Protocol:    "rest",
+			Client:      client,
+			Concurrency: 10,
+			DurationSec: 5,
+			CreatedAt:   now.Add(-age),
+		})
+		if err != nil {
+			t.Fatalf("RecordRun() error = %v", err)
+		}
+		defer db.DeleteRun(ctx, runID)
+
+		samples := make([]*BenchmarkSample, 10)
+		for j := range samples {
+			samples[j] = &BenchmarkSample{
+				RunID:     runID,
+				LatencyMs: float64(10 * (i + 1)),
+				Success:   true,
+				Timestamp: now.Add(time.Duration(j) * time.Millisecond),
+			}
+		}
+		if err := db.RecordSamples(ctx, samples); err != nil {
+			t.Fatalf("RecordSamples() error = %v", err)
+		}
+	}
+
+	points, err := db.GetTrends(ctx, StatsFilter{Client: client},
+		TrendQuery{Metric: "p99", Window: 30 * 24 * time.Hour, Points: 30})
+	if err != nil {
+		t.Fatalf("GetTrends() error = %v", err)
+	}
+	if len(points) != 2 {
+		t.Fatalf("GetTrends() returned %d points, want 2", len(points))
+	}
+	if p := points[0]; p.Runs != 1 || p.Value != 30 {
+		t.Errorf("older point = %+v, want 1 run at 30ms", p)
+	}
+	if p := points[1]; p.Runs != 2 || p.Value != 15 || !p.Bucket.After(points[0].Bucket) {
+		t.Errorf("recent point = %+v, want 2 runs averaging 15ms", p)
+	}
+}
@@ -0,0 +1,377 @@
+// Package export writes stored benchmark results to columnar files, so runs
+// can be analysed in DuckDB, Spark or pandas without querying Postgres.
+//
+// Runs and samples are written to separate files sharing the run_id column:
+//
+//	SELECT r.protocol, quantile_cont(s.latency_ms, 0.99)
+//	FROM 'runs.parquet' r JOIN 'samples.parquet' s USING (run_id)
+//	GROUP BY r.protocol;
+package export
+
+import (
+	"io"
+	"time"
+
+	"github.com/parquet-go/parquet-go"
+
+	"github.com/kaldun-tech/grpc-rest-benchmark/pkg/db"
+)
+
+// rowGroupSize bounds the samples buffered in memory before a row group is
+// written out.
+const rowGroupSize = 1 << 20
+
+// Run is one benchmark_runs row as written to Parquet.
+type Run struct {
+	RunID       int64     `parquet:"run_id"`
+	CreatedAt   time.Time `parquet:"created_at,timestamp(microsecond)"`
+	Scenario    string    `parquet:"scenario,dict"`
+	Protocol    string    `parquet:"protocol,dict"`
+	Client      string    `parquet:"client,dict"`
+	Concurrency int       `parquet:"concurrency"`
+	DurationSec int       `parquet:"duration_sec"`
+	RateLimit   *int      `parquet:"rate_limit,optional"`
+
+	CPUUsageAvg  *float64 `parquet:"cpu_usage_avg,optional"`
+	MemoryMBAvg  *float64 `parquet:"memory_mb_avg,optional"`
+	MemoryMBPeak *float64 `parquet:"memory_mb_peak,optional"`
+
+	NetBytesSent  *int64  `parquet:"net_bytes_sent,optional"`
+	NetBytesRecv  *int64  `parquet:"net_bytes_recv,optional"`
+	NetInterfaces *string `parquet:"net_interfaces,optional,dict"`
+
+	LogPath       *string  `parquet:"log_path,optional"`
+	LatencyMetric *string  `parquet:"latency_metric,optional"`
+	ComparisonID  *string  `parquet:"comparison_id,optional"`
+	SuiteID       *string  `parquet:"suite_id,optional"`
+	PayloadSize   *int     `parquet:"payload_size,optional"`
+	WriteRatio    *float64 `parquet:"write_ratio,optional"`
+	OperationMix  *string  `parquet:"operation_mix,optional,dict"`
+	AccountChurn  *string  `parquet:"account_churn,optional,dict"`
+	DBTarget      *string  `parquet:"db_target,optional,dict"`
+	Compression   *string  `parquet:"compression,optional"`
+	Connection    *string  `parquet:"connection,optional"`
+	ServerQoS     *string  `parquet:"server_qos,optional"`
+	ServerPools   *string  `parquet:"server_pools,optional,dict"`
+	ServerQueryTx *string  `parquet:"server_query_tx,optional,dict"`
+	ServerFaults  *string  `parquet:"server_faults,optional,dict"`
+	ServerCache   *string  `parquet:"server_cache,optional,dict"`
+	JSONEncoder   *string  `parquet:"json_encoder,optional,dict"`
+
+	Workers          *int `parquet:"workers,optional"`
+	StreamsPerWorker *int `parquet:"streams_per_worker,optional"`
+
+	ProcessCostUs *int64 `parquet:"process_cost_us,optional"`
+
+	Cost           *float64 `parquet:"cost,optional"`
+	CostPerMillion *float64 `parquet:"cost_per_million,optional"`
+	CostModel      *string  `parquet:"cost_model,optional,dict"`
+
+	ClientCPUSeconds *float64 `parquet:"client_cpu_seconds,optional"`
+	ServerCPUSeconds *float64 `parquet:"server_cpu_seconds,optional"`
+	ClientPerCPUSec  *float64 `parquet:"client_per_cpu_sec,optional"`
+	ServerPerCPUSec  *float64 `parquet:"server_per_cpu_sec,optional"`
+	CacheHitRate     *float64 `parquet:"cache_hit_rate,optional"`
+
+	LoadProfile *string `parquet:"load_profile,optional"`
+	DatasetHash *string `parquet:"dataset_hash,optional,dict"`
+
+	DatasetFingerprint *string `parquet:"dataset_fingerprint,optional,dict"`
+	DatasetSize        *int64  `parquet:"dataset_size,optional"`
+	StreamShortfall    *int64  `parquet:"stream_shortfall,optional"`
+	StreamsEstablished *int    `parquet:"streams_established,optional"`
+
+	MeasureWindow  *string  `parquet:"measure_window,optional,dict"`
+	MeasuredWindow *string  `parquet:"measured_window,optional,dict"`
+	FullThroughput *float64 `parquet:"full_throughput,optional"`
+	FullP50Ms      *float64 `parquet:"full_p50_ms,optional"`
+	FullP99Ms      *float64 `parquet:"full_p99_ms,optional"`
+
+	GCPauses       *int     `parquet:"gc_pauses,optional"`
+	GCPauseMs      *float64 `parquet:"gc_pause_ms,optional"`
+	GCTailFraction *float64 `parquet:"gc_tail_fraction,optional"`
+
+	LiveStream *bool `parquet:"live_stream,optional"`
+
+	ChaosDisconnectMs *int64   `parquet:"chaos_disconnect_ms,optional"`
+	Reconnects        *int     `parquet:"reconnects,optional"`
+	ReconnectP50Ms    *float64 `parquet:"reconnect_p50_ms,optional"`
+	ReconnectP99Ms    *float64 `parquet:"reconnect_p99_ms,optional"`
+
+	ServerMiddleware *string `parquet:"server_middleware,optional,dict"`
+	Auth             *string `parquet:"auth,optional,dict"`
+	ServerRateLimit  *string `parquet:"server_rate_limit,optional,dict"`
+	Backoff          *bool   `parquet:"backoff,optional"`
+
+	ChaosRestart      *string  `parquet:"chaos_restart,optional,dict"`
+	RestartErrors     *int     `parquet:"restart_errors,optional"`
+	RestartRecoveryMs *float64 `parquet:"restart_recovery_ms,optional"`
+
+	Agents         *string `parquet:"agents,optional"`
+	ConcurrentWith *string `parquet:"concurrent_with,optional"`
+
+	ClientCommit *string `parquet:"client_commit,optional"`
+	ServerCommit *string `parquet:"server_commit,optional"`
+	GoVersion    *string `parquet:"go_version,optional"`
+	GOMAXPROCS   *int    `parquet:"gomaxprocs,optional"`
+	Kernel       *string `parquet:"kernel,optional"`
+	Hostname     *string `parquet:"hostname,optional"`
+	Tags         *string `parquet:"tags,optional"`
+	Environment  *string `parquet:"environment,optional"`
+
+	GoroutinesPeak  *int     `parquet:"goroutines_peak,optional"`
+	HeapObjectsPeak *int64   `parquet:"heap_objects_peak,optional"`
+	HeapMBPeak      *float64 `parquet:"heap_mb_peak,optional"`
+	GCCycles        *int     `parquet:"gc_cycles,optional"`
+
+	CacheBust *string `parquet:"cache_bust,optional"`
+
+	InjectedHeaders     *string `parquet:"injected_headers,optional"`
+	InjectedHeaderBytes *int    `parquet:"injected_header_bytes,optional"`
+
+	WireBytesSent    *int64 `parquet:"wire_bytes_sent,optional"`
+	WireBytesRecv    *int64 `parquet:"wire_bytes_recv,optional"`
+	PayloadBytesSent *int64 `parquet:"payload_bytes_sent,optional"`
+	PayloadBytesRecv *int64 `parquet:"payload_bytes_recv,optional"`
+
+	MessageP50Bytes  *int    `parquet:"message_p50_bytes,optional"`
+	MessageP99Bytes  *int    `parquet:"message_p99_bytes,optional"`
+	MessageMaxBytes  *int    `parquet:"message_max_bytes,optional"`
+	RESTStreamFormat *string `parquet:"rest_stream_format,optional"`
+	RunConfig        *string `parquet:"run_config,optional"`
+
+	ServerLogErrors   *int64  `parquet:"server_log_errors,optional"`
+	ServerLogWarnings *int64  `parquet:"server_log_warnings,optional"`
+	ServerLogLines    *string `parquet:"server_log_lines,optional"`
+
+	Verified     *bool   `parquet:"verified,optional"`
+	Verification *string `parquet:"verification,optional"`
+}
+
+// Sample is one benchmark_samples row as written to Parquet.
+type Sample struct {
+	RunID       int64     `parquet:"run_id,delta"`
+	Timestamp   time.Time `parquet:"timestamp,timestamp(microsecond),delta"`
+	LatencyMs   float64   `parquet:"latency_ms"`
+	Success     bool      `parquet:"success"`
+	ErrorType   *string   `parquet:"error_type,optional,dict"`
+	StalenessMs *float64  `parquet:"staleness_ms,optional"`
+	Phase       *int      `parquet:"phase,optional"`
+
+	WorkloadClass *string `parquet:"workload_class,optional,dict"`
+	Operation     *string `parquet:"operation,optional,dict"`
+
+	DBMs *float64 `parquet:"db_ms,optional"`
+
+	GCPause *bool `parquet:"gc_pause,optional"`
+}
+
+// WriteRuns writes runs to w as a zstd-compressed Parquet file.
+func WriteRuns(w io.Writer, runs []*db.BenchmarkRun) error {
+	rows := make([]Run, len(runs))
+	for i, r := range runs {
+		rows[i] = Run{
+			RunID:         r.ID,
+			CreatedAt:     r.CreatedAt,
+			Scenario:      r.Scenario,
+			Protocol:      r.Protocol,
+			Client:        r.Client,
+			Concurrency:   r.Concurrency,
+			DurationSec:   r.DurationSec,
+			RateLimit:     r.RateLimit,
+			CPUUsageAvg:   r.CPUUsageAvg,
+			MemoryMBAvg:   r.MemoryMBAvg,
+			MemoryMBPeak:  r.MemoryMBPeak,
+			NetBytesSent:  r.NetBytesSent,
+			NetBytesRecv:  r.NetBytesRecv,
+			NetInterfaces: r.NetInterfaces,
+			LogPath:       r.LogPath,
+			LatencyMetric: r.LatencyMetric,
+			ComparisonID:  r.ComparisonID,
+			SuiteID:       r.SuiteID,
+			PayloadSize:   r.PayloadSize,
+			WriteRatio:    r.WriteRatio,
+			OperationMix:  r.OperationMix,
+			AccountChurn:  r.AccountChurn,
+			DBTarget:      r.DBTarget,
+			Compression:   r.Compression,
+			Connection:    r.Connection,
+			ServerQoS:     r.ServerQoS,
+			ServerPools:   r.ServerPools,
+			ServerQueryTx: r.ServerQueryTx,
+			ServerFaults:  r.ServerFaults,
+			ServerCache:   r.ServerCache,
+			JSONEncoder:   r.JSONEncoder,
+
+			Cost:           r.Cost,
+			CostPerMillion: r.CostPerMillion,
+			CostModel:      r.CostModel,
+
+			ClientCPUSeconds: r.ClientCPUSeconds,
+			ServerCPUSeconds: r.ServerCPUSeconds,
+			ClientPerCPUSec:  r.ClientPerCPUSec,
+			ServerPerCPUSec:  r.ServerPerCPUSec,
+			CacheHitRate:     r.CacheHitRate,
+			Workers:          r.Workers,
+			StreamsPerWorker: r.StreamsPerWorker,
+			ProcessCostUs:    r.ProcessCostUs,
+
+			LoadProfile: r.LoadProfile,
+			DatasetHash: r.DatasetHash,
+
+			DatasetFingerprint: r.DatasetFingerprint,
+			DatasetSize:        r.DatasetSize,
+			StreamShortfall:    r.StreamShortfall,
+			StreamsEstablished: r.StreamsEstablished,
+
+			MeasureWindow:  r.MeasureWindow,
+			MeasuredWindow: r.MeasuredWindow,
+			FullThroughput: r.FullThroughput,
+			FullP50Ms:      r.FullP50Ms,
+			FullP99Ms:      r.FullP99Ms,
+
+			GCPauses:       r.GCPauses,
+			GCPauseMs:      r.GCPauseMs,
+			GCTailFraction: r.GCTailFraction,
+
+			LiveStream: r.LiveStream,
+
+			ChaosDisconnectMs: r.ChaosDisconnectMs,
+			Reconnects:        r.Reconnects,
+			ReconnectP50Ms:    r.ReconnectP50Ms,
+			ReconnectP99Ms:    r.ReconnectP99Ms,
+
+			ServerMiddleware: r.ServerMiddleware,
+			Auth:             r.Auth,
+			ServerRateLimit:  r.ServerRateLimit,
+			Backoff:          r.Backoff,
+
+			ChaosRestart:      r.ChaosRestart,
+			RestartErrors:     r.RestartErrors,
+			RestartRecoveryMs: r.RestartRecoveryMs,
+
+			Agents:         r.Agents,
+			ConcurrentWith: r.ConcurrentWith,
+
+			ClientCommit: r.ClientCommit,
+			ServerCommit: r.ServerCommit,
+			GoVersion:    r.GoVersion,
+			GOMAXPROCS:   r.GOMAXPROCS,
+			Kernel:       r.Kernel,
+			Hostname:     r.Hostname,
+			Tags:         r.Tags,
+			Environment:  r.Environment,
+
+			GoroutinesPeak:  r.GoroutinesPeak,
+			HeapObjectsPeak: r.HeapObjectsPeak,
+			HeapMBPeak:      r.HeapMBPeak,
+			GCCycles:        r.GCCycles,
+
+			CacheBust: r.CacheBust,
+
+			InjectedHeaders:     r.InjectedHeaders,
+			InjectedHeaderBytes: r.InjectedHeaderBytes,
+
+			WireBytesSent:    r.WireBytesSent,
+			WireBytesRecv:    r.WireBytesRecv,
+			PayloadBytesSent: r.PayloadBytesSent,
+			PayloadBytesRecv: r.PayloadBytesRecv,
+
+			MessageP50Bytes:  r.MessageP50Bytes,
+			MessageP99Bytes:  r.MessageP99Bytes,
+			MessageMaxBytes:  r.MessageMaxBytes,
+			RESTStreamFormat: r.RESTStreamFormat,
+			RunConfig:        r.RunConfig,
+
+			ServerLogErrors:   r.ServerLogErrors,
+			ServerLogWarnings: r.ServerLogWarnings,
+			ServerLogLines:    r.ServerLogLines,
+
+			Verified:     r.Verified,
+			Verification: r.Verification,
+		}
+	}
+
+	pw := parquet.NewGenericWriter[Run](w, parquet.Compression(&parquet.Zstd))
+	if _, err := pw.Write(rows); err != nil {
+		return err
+	}
+	return pw.Close()
+}
+
+// SampleWriter writes samples to a zstd-compressed Parquet file one at a
+// time, flushing a row group every million samples so memory use does not
+// grow with the number of samples.
+type SampleWriter struct {
+	pw    *parquet.GenericWriter[Sample]
+	batch []Sample
+	count int
+}
+
+// NewSampleWriter returns a SampleWriter writing to w. Close must be called
+// to complete the file.
+func NewSampleWriter(w io.Writer) *SampleWriter {
+	return &SampleWriter{
+		pw: parquet.NewGenericWriter[Sample](w,
+			parquet.Compression(&parquet.Zstd),
+			parquet.MaxRowsPerRowGroup(rowGroupSize),
+		),
+		batch: make([]Sample, 0, 4096),
+	}
+}
+
+// Write adds one sample. s is copied, so the caller may reuse it.
+func (sw *SampleWriter) Write(s *db.BenchmarkSample) error {
+	sw.batch = append(sw.batch, Sample{
+		RunID:       s.RunID,
+		Timestamp:   s.Timestamp,
+		LatencyMs:   s.LatencyMs,
+		Success:     s.Success,
+		ErrorType:   clone(s.ErrorType),
+		StalenessMs: clone(s.StalenessMs),
+		Phase:       clone(s.Phase),
+
+		WorkloadClass: clone(s.WorkloadClass),
+		Operation:     clone(s.Operation),
+
+		DBMs: clone(s.DBMs),
+
+		GCPause: clone(s.GCPause),
+	})
+	sw.count++
+	if len(sw.batch) == cap(sw.batch) {
+		return sw.flushBatch()
+	}
+	return nil
+}
+
+// Count returns the number of samples written so far.
+func (sw *SampleWriter) Count() int {
+	return sw.count
+}
+
+// Close writes any buffered samples and the file footer.
+func (sw *SampleWriter) Close() error {
+	if err := sw.flushBatch(); err != nil {
+		return err
+	}
+	return sw.pw.Close()
+}
+
+func (sw *SampleWriter) flushBatch() error {
+	if len(sw.batch) == 0 {
+		return nil
+	}
+	_, err := sw.pw.Write(sw.batch)
+	sw.batch = sw.batch[:0]
+	return err
+}
+
+// clone returns a copy of *p, or nil.
+func clone[T any](p *T) *T {
+	if p == nil {
+		return nil
+	}
+	v := *p
+	return &v
+}
@@ -0,0 +1,100 @@
+package export
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/parquet-go/parquet-go"
+
+	"github.com/kaldun-tech/grpc-rest-benchmark/pkg/db"
+)
+
+func TestWriteRuns_RoundTrip(t *testing.T) {
+	created := time.Date(2025, 1, 2, 3, 4, 5, 123456000, time.UTC)
+	rate := 500
+	compression := "zstd"
+	runs := []*db.BenchmarkRun{
+		{ID: 7, Scenario: "balance", Protocol: "grpc", Client: "go", Concurrency: 10, DurationSec: 30, CreatedAt: created},
+		{ID: 8, Scenario: "echo", Protocol: "rest", Client: "go", Concurrency: 20, DurationSec: 60, CreatedAt: created,
+			RateLimit: &rate, Compression: &compression},
+	}
+
+	var buf bytes.Buffer
+	if err := WriteRuns(&buf, runs); err != nil {
+		t.Fatalf("WriteRuns() error = %v", err)
+	}
+
+	got, err := parquet.Read[Run](bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("parquet.Read() error = %v", err)
+	}
+	if len(got) != 2 {
+		t.Fatalf("read %d runs, want 2", len(got))
+	}
+	if got[0].RunID != 7 || got[0].RateLimit != nil || got[0].Compression != nil {
+		t.Errorf("run 0 = %+v", got[0])
+	}
+	if !got[0].CreatedAt.Equal(created) {
+		t.Errorf("CreatedAt = %v, want %v", got[0].CreatedAt, created)
+	}
+	if got[1].RateLimit == nil || *got[1].RateLimit != 500 || got[1].Compression == nil || *got[1].Compression != "zstd" {
+		t.Errorf("run 1 optional fields = %+v", got[1])
+	}
+}
+
+func TestSampleWriter_RoundTrip(t *testing.T) {
+	start := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	errType := "deadline exceeded"
+
+	var buf bytes.Buffer
+	sw := NewSampleWriter(&buf)
+
+	// One sample value reused for every row, as db.ForEachSample does
+	var s db.BenchmarkSample
+	const n = 10000
+	for i := 0; i < n; i++ {
+		phase := i/5000 + 1
+		s = db.BenchmarkSample{
+			RunID:     1,
+			LatencyMs: float64(i) / 10,
+			Success:   i%100 != 0,
+			Timestamp: start.Add(time.Duration(i) * time.Millisecond),
+			Phase:     &phase,
+		}
+		if !s.Success {
+			s.ErrorType = &errType
+		}
+		if err := sw.Write(&s); err != nil {
+			t.Fatalf("Write() error = %v", err)
+		}
+	}
+	if sw.Count() != n {
+		t.Errorf("Count() = %d, want %d", sw.Count(), n)
+	}
+	if err := sw.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	got, err := parquet.Read[Sample](bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("parquet.Read() error = %v", err)
+	}
+	if len(got) != n {
+		t.Fatalf("read %d samples, want %d", len(got), n)
+	}
+
+	first, last := got[0], got[n-1]
+	if first.Success || first.ErrorType == nil || *first.ErrorType != errType {
+		t.Errorf("sample 0 = %+v, want a failure with its error type", first)
+	}
+	if first.Phase == nil || *first.Phase != 1 || last.Phase == nil || *last.Phase != 2 {
+		t.Errorf("phases = %v, %v; want 1 and 2 (copied, not shared)", first.Phase, last.Phase)
+	}
+	if last.ErrorType != nil || last.LatencyMs != float64(n-1)/10 {
+		t.Errorf("sample %d = %+v", n-1, last)
+	}
+	if !last.Timestamp.Equal(start.Add((n - 1) * time.Millisecond)) {
+		t.Errorf("last timestamp = %v", last.Timestamp)
+	}
+}
@@ -0,0 +1,193 @@
+// Package fanout delivers submitted transactions to live stream subscribers
+// as soon as they are stored, the real-time counterpart of replaying the
+// transactions table. Both servers wrap their dataset with it: a stream that
+// asks for live transactions subscribes to the hub instead of querying the
+// database. By default every transaction submitted through the server is
+// published to its subscribers once stored; with --live-source=notify the
+// server instead follows the database's notifications, and so also delivers
+// transactions submitted through other servers or processes.
+package fanout
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/kaldun-tech/grpc-rest-benchmark/pkg/db"
+	"github.com/kaldun-tech/grpc-rest-benchmark/pkg/qos"
+)
+
+// Sources of the transactions live streams deliver (--live-source).
+const (
+	SourceServer = "server" // transactions submitted through this server
+	SourceNotify = "notify" // database notifications of every submitted transaction
+)
+
+// Sources lists the valid --live-source values.
+var Sources = []string{SourceServer, SourceNotify}
+
+// Config holds the live stream flags.
+type Config struct {
+	Source string // one of Sources
+}
+
+// RegisterFlags registers the live stream flags on fs.
+func (c *Config) RegisterFlags(fs *flag.FlagSet) {
+	fs.StringVar(&c.Source, "live-source", SourceServer, "Transactions live streams deliver: "+strings.Join(Sources, " | ")+" (notify follows the database, including other servers' submissions)")
+}
+
+// Validate checks the live stream flags for invalid values.
+func (c Config) Validate() error {
+	if !slices.Contains(Sources, c.Source) {
+		return fmt.Errorf("invalid live-source: %s (must be one of: %s)", c.Source, strings.Join(Sources, ", "))
+	}
+	return nil
+}
+
+// ListenFunc follows the transactions stored from now on, as
+// db.DB.ListenTransactions does, until ctx is done or it fails.
+type ListenFunc func(ctx context.Context) (<-chan *db.Transaction, <-chan error)
+
+// followRetry is how long Follow waits before listening again after the
+// source failed.
+const followRetry = time.Second
+
+// subscriberBuffer is how many transactions a live subscriber may fall
+// behind before its stream is ended with ErrSlowSubscriber, so that one slow
+// subscriber holds up neither the submitter nor the other subscribers.
+const subscriberBuffer = 1024
+
+// ErrSlowSubscriber ends a live stream that fell more than subscriberBuffer
+// transactions behind.
+var ErrSlowSubscriber = errors.New("live stream fell behind and was dropped")
+
+// Dataset serves live streams from the transactions submitted through it;
+// everything else goes to the wrapped dataset. Published transactions are
+// shared between subscribers, who must not modify them.
+type Dataset struct {
+	qos.Dataset
+
+	following atomic.Bool // publishing from Follow rather than InsertTransaction
+
+	mu   sync.Mutex
+	subs map[*subscriber]struct{}
+}
+
+// subscriber is one live stream.
+type subscriber struct {
+	filterAccount string
+	txs           chan *db.Transaction
+	dropped       chan struct{} // closed when the subscriber fell behind
+}
+
+// Wrap returns ds with live streams served from the transactions submitted
+// through it.
+func Wrap(ds qos.Dataset) *Dataset {
+	return &Dataset{Dataset: ds, subs: make(map[*subscriber]struct{})}
+}
+
+// InsertTransaction stores tx and publishes it to the live subscribers,
+// unless they follow another source.
+func (d *Dataset) InsertTransaction(ctx context.Context, tx *db.Transaction) error {
+	if err := d.Dataset.InsertTransaction(ctx, tx); err != nil {
+		return err
+	}
+	if !d.following.Load() {
+		d.publish(tx)
+	}
+	return nil
+}
+
+// Follow publishes the transactions listen yields to the live subscribers
+// instead of those submitted through d, until ctx is done. When listen
+// fails, Follow reports the error to onError and listens again a second
+// later; transactions stored in between are not delivered.
+func (d *Dataset) Follow(ctx context.Context, listen ListenFunc, onError func(error)) {
+	d.following.Store(true)
+	for {
+		txs, errs := listen(ctx)
+		for tx := range txs {
+			d.publish(tx)
+		}
+		err := <-errs
+		if ctx.Err() != nil {
+			return
+		}
+		if err == nil {
+			err = errors.New("live transaction source ended")
+		}
+		onError(err)
+		select {
+		case <-time.After(followRetry):
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// StreamTransactions subscribes to the transactions submitted from now on
+// if opts.Live is set, and otherwise replays stored ones. A live stream
+// ends when ctx is done, with its error, or when it falls behind, with
+// ErrSlowSubscriber.
+func (d *Dataset) StreamTransactions(ctx context.Context, opts db.StreamTransactionsOptions) (<-chan *db.Transaction, <-chan error) {
+	if !opts.Live {
+		return d.Dataset.StreamTransactions(ctx, opts)
+	}
+
+	sub := &subscriber{
+		filterAccount: opts.FilterAccount,
+		txs:           make(chan *db.Transaction, subscriberBuffer),
+		dropped:       make(chan struct{}),
+	}
+	d.mu.Lock()
+	d.subs[sub] = struct{}{}
+	d.mu.Unlock()
+
+	errCh := make(chan error, 1)
+	go func() {
+		defer close(errCh)
+		select {
+		case <-ctx.Done():
+			d.mu.Lock()
+			delete(d.subs, sub)
+			d.mu.Unlock()
+			errCh <- ctx.Err()
+		case <-sub.dropped:
+			errCh <- ErrSlowSubscriber
+		}
+		// Publishers send under mu, and no longer see the subscriber
+		close(sub.txs)
+	}()
+	return sub.txs, errCh
+}
+
+// Subscribers returns the number of live streams.
+func (d *Dataset) Subscribers() int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return len(d.subs)
+}
+
+// publish delivers tx to every live subscriber it concerns, dropping those
+// whose buffer is full.
+func (d *Dataset) publish(tx *db.Transaction) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for sub := range d.subs {
+		if f := sub.filterAccount; f != "" && f != tx.FromAccount && f != tx.ToAccount {
+			continue
+		}
+		select {
+		case sub.txs <- tx:
+		default:
+			delete(d.subs, sub)
+			close(sub.dropped)
+		}
+	}
+}
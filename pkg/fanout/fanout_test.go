@@ -0,0 +1,143 @@
+package fanout
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/kaldun-tech/grpc-rest-benchmark/pkg/db"
+	"github.com/kaldun-tech/grpc-rest-benchmark/pkg/qos"
+)
+
+// insertDataset stores nothing and fails transactions from "0.0.bad".
+type insertDataset struct {
+	qos.Dataset
+	inserted int
+}
+
+func (d *insertDataset) InsertTransaction(ctx context.Context, tx *db.Transaction) error {
+	if tx.FromAccount == "0.0.bad" {
+		return errors.New("insert failed")
+	}
+	d.inserted++
+	return nil
+}
+
+func TestDataset_Live(t *testing.T) {
+	ctx := context.Background()
+	d := Wrap(&insertDataset{})
+
+	allCtx, cancelAll := context.WithCancel(ctx)
+	all, allErrs := d.StreamTransactions(allCtx, db.StreamTransactionsOptions{Live: true})
+	filtered, _ := d.StreamTransactions(ctx, db.StreamTransactionsOptions{Live: true, FilterAccount: "0.0.2"})
+	if n := d.Subscribers(); n != 2 {
+		t.Fatalf("Subscribers() = %d, want 2", n)
+	}
+
+	for _, tx := range []*db.Transaction{
+		{TxID: "a", FromAccount: "0.0.1", ToAccount: "0.0.2"},
+		{TxID: "b", FromAccount: "0.0.1", ToAccount: "0.0.3"},
+		{TxID: "c", FromAccount: "0.0.bad", ToAccount: "0.0.2"},
+	} {
+		d.InsertTransaction(ctx, tx)
+	}
+	for _, want := range []string{"a", "b"} {
+		if tx := <-all; tx.TxID != want {
+			t.Errorf("all received %s, want %s", tx.TxID, want)
+		}
+	}
+	if tx := <-filtered; tx.TxID != "a" {
+		t.Errorf("filtered received %s, want a", tx.TxID)
+	}
+	select {
+	case tx := <-filtered:
+		t.Errorf("filtered received %s, want nothing more", tx.TxID)
+	default:
+	}
+
+	cancelAll()
+	if _, ok := <-all; ok {
+		t.Error("stream still open after its context was canceled")
+	}
+	if err := <-allErrs; !errors.Is(err, context.Canceled) {
+		t.Errorf("stream error = %v, want context.Canceled", err)
+	}
+	if n := d.Subscribers(); n != 1 {
+		t.Errorf("Subscribers() = %d after one canceled, want 1", n)
+	}
+}
+
+func TestDataset_SlowSubscriber(t *testing.T) {
+	ctx := context.Background()
+	d := Wrap(&insertDataset{})
+	txs, errs := d.StreamTransactions(ctx, db.StreamTransactionsOptions{Live: true})
+
+	// The subscriber reads nothing, so one more than its buffer drops it
+	for range subscriberBuffer + 1 {
+		d.InsertTransaction(ctx, &db.Transaction{FromAccount: "0.0.1", ToAccount: "0.0.2"})
+	}
+	select {
+	case err := <-errs:
+		if !errors.Is(err, ErrSlowSubscriber) {
+			t.Errorf("stream error = %v, want ErrSlowSubscriber", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("slow subscriber was not dropped")
+	}
+	received := 0
+	for range txs {
+		received++
+	}
+	if received != subscriberBuffer || d.Subscribers() != 0 {
+		t.Errorf("received %d of the buffered %d, %d subscribers left", received, subscriberBuffer, d.Subscribers())
+	}
+}
+
+func TestDataset_Follow(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	d := Wrap(&insertDataset{})
+	sub, _ := d.StreamTransactions(ctx, db.StreamTransactionsOptions{Live: true})
+
+	// The source yields two transactions and fails
+	listen := func(ctx context.Context) (<-chan *db.Transaction, <-chan error) {
+		txs := make(chan *db.Transaction, 2)
+		txs <- &db.Transaction{TxID: "x"}
+		txs <- &db.Transaction{TxID: "y"}
+		close(txs)
+		errs := make(chan error, 1)
+		errs <- errors.New("connection lost")
+		close(errs)
+		return txs, errs
+	}
+	var reported error
+	d.Follow(ctx, listen, func(err error) {
+		reported = err
+		// Submissions are not published while following
+		d.InsertTransaction(ctx, &db.Transaction{TxID: "submitted", FromAccount: "0.0.1", ToAccount: "0.0.2"})
+		cancel()
+	})
+
+	if reported == nil || reported.Error() != "connection lost" {
+		t.Errorf("Follow() reported %v, want connection lost", reported)
+	}
+	var got []string
+	for tx := range sub {
+		got = append(got, tx.TxID)
+	}
+	if len(got) != 2 || got[0] != "x" || got[1] != "y" {
+		t.Errorf("subscriber received %v, want [x y]", got)
+	}
+}
+
+func TestConfig_Validate(t *testing.T) {
+	for _, source := range Sources {
+		if err := (Config{Source: source}).Validate(); err != nil {
+			t.Errorf("Validate(%s) error = %v", source, err)
+		}
+	}
+	if err := (Config{Source: "kafka"}).Validate(); err == nil {
+		t.Error("Validate(kafka) error = nil")
+	}
+}
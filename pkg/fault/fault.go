@@ -0,0 +1,178 @@
+// Package fault injects latency and errors into server responses, to study
+// how each protocol's client behaves under a degraded server without
+// modifying the database. The REST server applies faults as HTTP middleware
+// (REST and Connect), the gRPC server as interceptors (gRPC and gRPC-Web).
+package fault
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kaldun-tech/grpc-rest-benchmark/pkg/internal/grpcmethod"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ErrInjected is the error of requests failed by the injector.
+var ErrInjected = errors.New("injected fault")
+
+// Config holds the fault injection flags.
+type Config struct {
+	Latency   time.Duration // delay added to every request
+	Jitter    time.Duration // up to this much more delay, uniformly distributed
+	ErrorRate float64       // fraction of requests failed after the delay
+}
+
+// RegisterFlags registers the fault injection flags on fs.
+func (c *Config) RegisterFlags(fs *flag.FlagSet) {
+	fs.DurationVar(&c.Latency, "inject-latency", 0, "Delay added to every benchmark request (0 = none)")
+	fs.DurationVar(&c.Jitter, "inject-jitter", 0, "Up to this much more delay per request, uniformly distributed (0 = none)")
+	fs.Float64Var(&c.ErrorRate, "inject-error-rate", 0, "Fraction of benchmark requests failed with an unavailable error, 0 to 1")
+}
+
+// Validate checks the fault injection flags for invalid values.
+func (c Config) Validate() error {
+	if c.Latency < 0 || c.Jitter < 0 {
+		return fmt.Errorf("inject-latency and inject-jitter must not be negative")
+	}
+	if c.ErrorRate < 0 || c.ErrorRate > 1 {
+		return fmt.Errorf("inject-error-rate must be between 0 and 1")
+	}
+	return nil
+}
+
+// Enabled reports whether any fault is injected.
+func (c Config) Enabled() bool {
+	return c != Config{}
+}
+
+// String describes the injected faults, as recorded with each run, e.g.
+// "latency=10ms,jitter=5ms,errors=1%". It is empty when none are injected.
+func (c Config) String() string {
+	var parts []string
+	if c.Latency > 0 {
+		parts = append(parts, "latency="+c.Latency.String())
+	}
+	if c.Jitter > 0 {
+		parts = append(parts, "jitter="+c.Jitter.String())
+	}
+	if c.ErrorRate > 0 {
+		parts = append(parts, fmt.Sprintf("errors=%g%%", c.ErrorRate*100))
+	}
+	return strings.Join(parts, ",")
+}
+
+// Injector delays and fails requests as configured. A nil *Injector
+// injects nothing.
+type Injector struct {
+	cfg Config
+	mu  sync.Mutex // guards rng
+	rng *rand.Rand
+}
+
+// New creates an injector for cfg, or returns nil if cfg injects nothing.
+func New(cfg Config) *Injector {
+	if !cfg.Enabled() {
+		return nil
+	}
+	return &Injector{cfg: cfg, rng: rand.New(rand.NewSource(time.Now().UnixNano()))}
+}
+
+// Inject waits out the configured delay, then returns ErrInjected for the
+// configured fraction of calls. It returns the context's error if ctx is
+// done first.
+func (i *Injector) Inject(ctx context.Context) error {
+	if i == nil {
+		return nil
+	}
+
+	i.mu.Lock()
+	delay := i.cfg.Latency
+	if i.cfg.Jitter > 0 {
+		delay += time.Duration(i.rng.Int63n(int64(i.cfg.Jitter) + 1))
+	}
+	fail := i.cfg.ErrorRate > 0 && i.rng.Float64() < i.cfg.ErrorRate
+	i.mu.Unlock()
+
+	if delay > 0 {
+		timer := time.NewTimer(delay)
+		defer timer.Stop()
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+	if fail {
+		return ErrInjected
+	}
+	return nil
+}
+
+// Handler returns next with faults injected before each request. Failed
+// requests get 503 Service Unavailable with a JSON error body, as the REST
+// handlers return errors.
+func (i *Injector) Handler(next http.Handler) http.Handler {
+	if i == nil {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := i.Inject(r.Context()); err != nil {
+			if r.Context().Err() != nil {
+				return // the client is gone
+			}
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusServiceUnavailable)
+			json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// UnaryServerInterceptor injects faults before unary calls to the given
+// services (full names, e.g. "benchmark.BalanceService"); calls to other
+// services, such as health checks, pass through. Failed calls return
+// Unavailable.
+func (i *Injector) UnaryServerInterceptor(services ...string) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if i == nil || !grpcmethod.InServices(info.FullMethod, services) {
+			return handler(ctx, req)
+		}
+		if err := i.Inject(ctx); err != nil {
+			return nil, rpcError(err)
+		}
+		return handler(ctx, req)
+	}
+}
+
+// StreamServerInterceptor injects faults before streaming calls to the
+// given services start, like UnaryServerInterceptor.
+func (i *Injector) StreamServerInterceptor(services ...string) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if i == nil || !grpcmethod.InServices(info.FullMethod, services) {
+			return handler(srv, ss)
+		}
+		if err := i.Inject(ss.Context()); err != nil {
+			return rpcError(err)
+		}
+		return handler(srv, ss)
+	}
+}
+
+// rpcError converts an Inject error to a gRPC status error.
+func rpcError(err error) error {
+	if errors.Is(err, ErrInjected) {
+		return status.Error(codes.Unavailable, err.Error())
+	}
+	return status.FromContextError(err).Err()
+}
@@ -0,0 +1,91 @@
+package fault
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestConfig(t *testing.T) {
+	var none Config
+	if none.Enabled() || none.String() != "" || New(none) != nil {
+		t.Errorf("zero Config is enabled or described as %q", none.String())
+	}
+
+	c := Config{Latency: 10 * time.Millisecond, Jitter: 5 * time.Millisecond, ErrorRate: 0.01}
+	if err := c.Validate(); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+	if got, want := c.String(), "latency=10ms,jitter=5ms,errors=1%"; got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+
+	for _, bad := range []Config{{Latency: -1}, {Jitter: -1}, {ErrorRate: 1.5}} {
+		if err := bad.Validate(); err == nil {
+			t.Errorf("Validate(%+v) = nil, want an error", bad)
+		}
+	}
+}
+
+func TestInjector_Inject(t *testing.T) {
+	var nilInjector *Injector
+	if err := nilInjector.Inject(context.Background()); err != nil {
+		t.Errorf("nil Inject() = %v, want nil", err)
+	}
+
+	i := New(Config{Latency: 20 * time.Millisecond, Jitter: 10 * time.Millisecond})
+	start := time.Now()
+	if err := i.Inject(context.Background()); err != nil {
+		t.Fatalf("Inject() error = %v", err)
+	}
+	if d := time.Since(start); d < 20*time.Millisecond {
+		t.Errorf("Inject() returned after %v, want at least the 20ms latency", d)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := i.Inject(ctx); err != context.Canceled {
+		t.Errorf("Inject() with a done context = %v, want context.Canceled", err)
+	}
+
+	if err := New(Config{ErrorRate: 1}).Inject(context.Background()); err != ErrInjected {
+		t.Errorf("Inject() at error rate 1 = %v, want ErrInjected", err)
+	}
+}
+
+func TestInjector_Handler(t *testing.T) {
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+
+	rec := httptest.NewRecorder()
+	New(Config{ErrorRate: 1}).Handler(ok).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/echo", nil))
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("status = %d, want 503", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	New(Config{Latency: time.Millisecond}).Handler(ok).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/echo", nil))
+	if rec.Code != http.StatusOK {
+		t.Errorf("status = %d, want 200", rec.Code)
+	}
+}
+
+func TestInjector_UnaryServerInterceptor(t *testing.T) {
+	intercept := New(Config{ErrorRate: 1}).UnaryServerInterceptor("benchmark.BalanceService")
+	handler := func(ctx context.Context, req any) (any, error) { return "ok", nil }
+
+	_, err := intercept(context.Background(), nil, &grpc.UnaryServerInfo{FullMethod: "/benchmark.BalanceService/GetBalance"}, handler)
+	if status.Code(err) != codes.Unavailable {
+		t.Errorf("faulted call error = %v, want Unavailable", err)
+	}
+
+	resp, err := intercept(context.Background(), nil, &grpc.UnaryServerInfo{FullMethod: "/grpc.health.v1.Health/Check"}, handler)
+	if err != nil || resp != "ok" {
+		t.Errorf("other service = %v, %v; want it to pass through", resp, err)
+	}
+}
@@ -0,0 +1,83 @@
+// Package feed generates transactions at a steady rate (server --feed-rate),
+// so live streams keep delivering for as long as a benchmark runs without a
+// writer of their own. Generated transactions are transfers between random
+// seeded accounts, stored and published as submitted ones are.
+package feed
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"math/rand"
+	"time"
+
+	"github.com/kaldun-tech/grpc-rest-benchmark/pkg/db"
+)
+
+// maxAmount is the largest transfer the feed generates, in tinybars.
+const maxAmount = 100_000_000
+
+// Config holds the feed flags.
+type Config struct {
+	Rate int // transactions generated per second, 0 for none
+}
+
+// RegisterFlags registers the feed flags on fs.
+func (c *Config) RegisterFlags(fs *flag.FlagSet) {
+	fs.IntVar(&c.Rate, "feed-rate", 0, "Generate this many transactions per second for live streams (0 = none)")
+}
+
+// Validate checks the feed flags for invalid values.
+func (c Config) Validate() error {
+	if c.Rate < 0 {
+		return fmt.Errorf("feed-rate must not be negative")
+	}
+	return nil
+}
+
+// Enabled reports whether transactions are generated.
+func (c Config) Enabled() bool {
+	return c.Rate > 0
+}
+
+// Inserter stores a transaction, filling in its ID and timestamp.
+type Inserter interface {
+	InsertTransaction(ctx context.Context, tx *db.Transaction) error
+}
+
+// Run inserts rate transfers per second between random accounts into ins
+// until ctx is done. Insert errors are reported to onError and do not stop
+// the feed. Inserts are made one at a time, so a rate above the inverse of
+// the insert latency is not reached.
+func Run(ctx context.Context, ins Inserter, accountIDs []string, rate int, onError func(error)) error {
+	if len(accountIDs) < 2 {
+		return fmt.Errorf("the feed needs at least 2 accounts, have %d", len(accountIDs))
+	}
+	if rate < 1 {
+		return fmt.Errorf("feed rate must be positive")
+	}
+
+	rng := rand.New(rand.NewSource(time.Now().UnixNano()))
+	ticker := time.NewTicker(time.Second / time.Duration(rate))
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return nil
+		}
+		from := rng.Intn(len(accountIDs))
+		to := rng.Intn(len(accountIDs) - 1)
+		if to >= from {
+			to++
+		}
+		tx := &db.Transaction{
+			FromAccount: accountIDs[from],
+			ToAccount:   accountIDs[to],
+			Amount:      1 + rng.Int63n(maxAmount),
+		}
+		if err := ins.InsertTransaction(ctx, tx); err != nil && ctx.Err() == nil {
+			onError(err)
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package feed
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/kaldun-tech/grpc-rest-benchmark/pkg/db"
+)
+
+// recordingInserter keeps the transactions it is given and fails every
+// third one.
+type recordingInserter struct {
+	mu  sync.Mutex
+	txs []*db.Transaction
+}
+
+func (r *recordingInserter) InsertTransaction(ctx context.Context, tx *db.Transaction) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.txs = append(r.txs, tx)
+	if len(r.txs)%3 == 0 {
+		return errors.New("insert failed")
+	}
+	return nil
+}
+
+func TestRun(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 300*time.Millisecond)
+	defer cancel()
+	ins := &recordingInserter{}
+	failures := 0
+	err := Run(ctx, ins, []string{"0.0.1", "0.0.2", "0.0.3"}, 100, func(error) { failures++ })
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	if n := len(ins.txs); n < 10 || n > 31 {
+		t.Errorf("Run() generated %d transactions in 300ms, want about 30", n)
+	}
+	if failures != len(ins.txs)/3 {
+		t.Errorf("Run() reported %d failures, want %d", failures, len(ins.txs)/3)
+	}
+	for _, tx := range ins.txs {
+		if err := tx.Validate(); err != nil {
+			t.Errorf("generated %+v: %v", tx, err)
+		}
+	}
+}
+
+func TestRun_Invalid(t *testing.T) {
+	ctx := context.Background()
+	if err := Run(ctx, &recordingInserter{}, []string{"0.0.1"}, 10, nil); err == nil {
+		t.Error("Run() with one account error = nil")
+	}
+	if err := Run(ctx, &recordingInserter{}, []string{"0.0.1", "0.0.2"}, 0, nil); err == nil {
+		t.Error("Run() without a rate error = nil")
+	}
+}
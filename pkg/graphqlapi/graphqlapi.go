@@ -0,0 +1,418 @@
+// Package graphqlapi defines the GraphQL API of the REST server, shared by
+// the server and the benchmark client: its schema, built with graphql-go,
+// the request and response bodies, and the parsing, validation and
+// response encoding the server executes operations with.
+//
+// Queries and subscriptions are POSTed as JSON to the server's
+// /api/v1/graphql endpoint. Query responses are JSON; subscriptions are
+// answered with GraphQL over Server-Sent Events, a "next" event per
+// transaction and a "complete" event at the end, with the number of
+// transactions sent in a Messages-Sent trailer as the REST NDJSON streams
+// do.
+package graphqlapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/gqlerrors"
+	"github.com/graphql-go/graphql/language/ast"
+	"github.com/graphql-go/graphql/language/parser"
+	"github.com/graphql-go/graphql/language/source"
+)
+
+// Request is the JSON body of a GraphQL request.
+type Request struct {
+	Query         string         `json:"query"`
+	OperationName string         `json:"operationName,omitempty"`
+	Variables     map[string]any `json:"variables,omitempty"`
+}
+
+// Response is the JSON body of a GraphQL response, or of a subscription
+// event, whose data is of type T.
+type Response[T any] struct {
+	Data   T       `json:"data"`
+	Errors []Error `json:"errors,omitempty"`
+}
+
+// Data of the operations the benchmark client sends.
+type (
+	BalanceData struct {
+		Balance *Balance `json:"balance"`
+	}
+	BalancesData struct {
+		Balances []Balance `json:"balances"`
+	}
+	TransactionsData struct {
+		Transactions *Transaction `json:"transactions"`
+	}
+)
+
+// Error codes of the errors' extensions.
+const (
+	CodeParseFailed      = "GRAPHQL_PARSE_FAILED"
+	CodeValidationFailed = "GRAPHQL_VALIDATION_FAILED"
+	CodeBadUserInput     = "BAD_USER_INPUT"
+	CodeNotFound         = "NOT_FOUND"
+	CodeInternal         = "INTERNAL_SERVER_ERROR"
+)
+
+// Error is an error of a GraphQL response. Errors of fields have the path
+// of the field.
+type Error struct {
+	Message    string           `json:"message"`
+	Path       []any            `json:"path,omitempty"`
+	Extensions *ErrorExtensions `json:"extensions,omitempty"`
+}
+
+// ErrorExtensions classify an error.
+type ErrorExtensions struct {
+	Code string `json:"code"`
+}
+
+// NewError returns an error with the given code. Resolvers return them to
+// fail their field with the code (see Encode).
+func NewError(code, format string, args ...any) *Error {
+	return &Error{Message: fmt.Sprintf(format, args...), Extensions: &ErrorExtensions{Code: code}}
+}
+
+func (e *Error) Error() string {
+	if len(e.Path) > 0 {
+		return fmt.Sprintf("%s: %s", formatPath(e.Path), e.Message)
+	}
+	return e.Message
+}
+
+// formatPath formats a response path like "balances.0.accountId".
+func formatPath(path []any) string {
+	var s string
+	for i, key := range path {
+		if i > 0 {
+			s += "."
+		}
+		s += fmt.Sprint(key)
+	}
+	return s
+}
+
+// Code returns the error's code, "" if it has none.
+func (e *Error) Code() string {
+	if e.Extensions == nil {
+		return ""
+	}
+	return e.Extensions.Code
+}
+
+// Balance is the Balance type of the schema.
+type Balance struct {
+	AccountID      string `json:"accountId"`
+	BalanceTinybar int64  `json:"balanceTinybar"`
+	Timestamp      string `json:"timestamp"`
+}
+
+// Transaction is the Transaction type of the schema.
+type Transaction struct {
+	TxID           string `json:"txId"`
+	FromAccount    string `json:"fromAccount"`
+	ToAccount      string `json:"toAccount"`
+	AmountTinybar  int64  `json:"amountTinybar"`
+	TxType         string `json:"txType"`
+	Timestamp      string `json:"timestamp"`
+	SentAtUnixNano int64  `json:"sentAtUnixNano"`
+}
+
+// TransactionsArgs are the arguments of the transactions subscription.
+type TransactionsArgs struct {
+	Since     string
+	Account   string
+	RateLimit int
+	After     string
+	Live      bool
+}
+
+// Resolvers fetch the values of the schema's root fields. Transactions
+// starts the subscription, with the root value the operation was executed
+// with; it sends a *Transaction per event on the returned channel, or an
+// error that fails the stream, and closes it at the end.
+type Resolvers struct {
+	Balance      func(ctx context.Context, accountID string) (*Balance, error)
+	Balances     func(ctx context.Context, ids []string) ([]*Balance, error)
+	Transactions func(ctx context.Context, root any, args TransactionsArgs) (chan any, error)
+}
+
+// Long is a 64-bit integer, serialized as a JSON number like the REST
+// API's amounts.
+var Long = graphql.NewScalar(graphql.ScalarConfig{
+	Name:        "Long",
+	Description: "A 64-bit integer",
+	Serialize: func(value any) any {
+		if v, ok := value.(int64); ok {
+			return v
+		}
+		return nil
+	},
+	ParseValue: func(value any) any {
+		switch v := value.(type) {
+		case int64:
+			return v
+		case int:
+			return int64(v)
+		case float64:
+			if v == math.Trunc(v) && math.Abs(v) < 1<<63 {
+				return int64(v)
+			}
+		}
+		return nil
+	},
+	ParseLiteral: func(value ast.Value) any {
+		if v, ok := value.(*ast.IntValue); ok {
+			if n, err := strconv.ParseInt(v.Value, 10, 64); err == nil {
+				return n
+			}
+		}
+		return nil
+	},
+})
+
+// NewSchema returns the schema of the API, resolving its root fields with
+// r:
+//
+//	type Query {
+//	  balance(accountId: ID!): Balance
+//	  balances(ids: [ID!]!): [Balance!]!
+//	}
+//
+//	type Subscription {
+//	  transactions(since: String, account: ID, rateLimit: Int, after: ID, live: Boolean): Transaction!
+//	}
+func NewSchema(r Resolvers) (graphql.Schema, error) {
+	balance := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Balance",
+		Fields: graphql.Fields{
+			"accountId":      &graphql.Field{Type: graphql.NewNonNull(graphql.ID)},
+			"balanceTinybar": &graphql.Field{Type: graphql.NewNonNull(Long)},
+			"timestamp":      &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+		},
+	})
+	transaction := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Transaction",
+		Fields: graphql.Fields{
+			"txId":           &graphql.Field{Type: graphql.NewNonNull(graphql.ID)},
+			"fromAccount":    &graphql.Field{Type: graphql.NewNonNull(graphql.ID)},
+			"toAccount":      &graphql.Field{Type: graphql.NewNonNull(graphql.ID)},
+			"amountTinybar":  &graphql.Field{Type: graphql.NewNonNull(Long)},
+			"txType":         &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"timestamp":      &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"sentAtUnixNano": &graphql.Field{Type: graphql.NewNonNull(Long)},
+		},
+	})
+
+	query := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Query",
+		Fields: graphql.Fields{
+			"balance": &graphql.Field{
+				Type: balance,
+				Args: graphql.FieldConfigArgument{
+					"accountId": {Type: graphql.NewNonNull(graphql.ID)},
+				},
+				Resolve: func(p graphql.ResolveParams) (any, error) {
+					id, _ := p.Args["accountId"].(string)
+					b, err := r.Balance(p.Context, id)
+					if err != nil {
+						return nil, err
+					}
+					return b, nil
+				},
+			},
+			"balances": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(balance))),
+				Args: graphql.FieldConfigArgument{
+					"ids": {Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(graphql.ID)))},
+				},
+				Resolve: func(p graphql.ResolveParams) (any, error) {
+					items, _ := p.Args["ids"].([]any)
+					ids := make([]string, len(items))
+					for i, item := range items {
+						ids[i], _ = item.(string)
+					}
+					return r.Balances(p.Context, ids)
+				},
+			},
+		},
+	})
+
+	subscription := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Subscription",
+		Fields: graphql.Fields{
+			"transactions": &graphql.Field{
+				Type: graphql.NewNonNull(transaction),
+				Args: graphql.FieldConfigArgument{
+					"since":     {Type: graphql.String},
+					"account":   {Type: graphql.ID},
+					"rateLimit": {Type: graphql.Int},
+					"after":     {Type: graphql.ID},
+					"live":      {Type: graphql.Boolean},
+				},
+				Subscribe: func(p graphql.ResolveParams) (any, error) {
+					var args TransactionsArgs
+					args.Since, _ = p.Args["since"].(string)
+					args.Account, _ = p.Args["account"].(string)
+					args.RateLimit, _ = p.Args["rateLimit"].(int)
+					args.After, _ = p.Args["after"].(string)
+					args.Live, _ = p.Args["live"].(bool)
+					return r.Transactions(p.Context, p.Info.RootValue, args)
+				},
+				// Each event is executed with the sent value as its source
+				Resolve: func(p graphql.ResolveParams) (any, error) {
+					if err, ok := p.Source.(error); ok {
+						return nil, err
+					}
+					return p.Source, nil
+				},
+			},
+		},
+	})
+
+	return graphql.NewSchema(graphql.SchemaConfig{Query: query, Subscription: subscription})
+}
+
+// Operation is the operation of a request, parsed and validated against
+// the schema.
+type Operation struct {
+	Type string // "query" or "subscription"
+
+	params graphql.ExecuteParams
+}
+
+// Parse parses the request's query and selects the operation to execute,
+// then validates it against schema. Mutations are not supported, and
+// subscriptions select a single field. The error has the code
+// CodeParseFailed or CodeValidationFailed.
+func Parse(schema graphql.Schema, req *Request) (*Operation, *Error) {
+	doc, err := parser.Parse(parser.ParseParams{Source: source.NewSource(&source.Source{
+		Body: []byte(req.Query),
+		Name: "GraphQL request",
+	})})
+	if err != nil {
+		return nil, NewError(CodeParseFailed, "%s", gqlerrors.FormatError(err).Message)
+	}
+	if result := graphql.ValidateDocument(&schema, doc, nil); !result.IsValid {
+		return nil, NewError(CodeValidationFailed, "%s", result.Errors[0].Message)
+	}
+
+	op, err := operation(doc, req.OperationName)
+	if err != nil {
+		return nil, NewError(CodeValidationFailed, "%v", err)
+	}
+	switch op.Operation {
+	case ast.OperationTypeQuery:
+	case ast.OperationTypeSubscription:
+		if len(op.SelectionSet.Selections) != 1 {
+			return nil, NewError(CodeValidationFailed, "subscription must select exactly one field")
+		}
+	default:
+		return nil, NewError(CodeValidationFailed, "%s operations are not supported", op.Operation)
+	}
+
+	return &Operation{
+		Type: op.Operation,
+		params: graphql.ExecuteParams{
+			Schema:        schema,
+			AST:           doc,
+			OperationName: req.OperationName,
+			Args:          req.Variables,
+		},
+	}, nil
+}
+
+// operation returns the operation of doc to execute: the one with the
+// given name, or the only one.
+func operation(doc *ast.Document, name string) (*ast.OperationDefinition, error) {
+	var found *ast.OperationDefinition
+	for _, def := range doc.Definitions {
+		op, ok := def.(*ast.OperationDefinition)
+		if !ok {
+			continue
+		}
+		if name == "" {
+			if found != nil {
+				return nil, fmt.Errorf("must provide operation name if query contains multiple operations")
+			}
+			found = op
+		} else if op.Name != nil && op.Name.Value == name {
+			return op, nil
+		}
+	}
+	if found == nil {
+		return nil, fmt.Errorf("unknown operation named %q", name)
+	}
+	return found, nil
+}
+
+// Execute executes a query.
+func (op *Operation) Execute(ctx context.Context) *graphql.Result {
+	p := op.params
+	p.Context = ctx
+	return graphql.Execute(p)
+}
+
+// Subscribe executes a subscription, passing root to the subscription's
+// resolver. The channel delivers a result per event and is closed once the
+// stream ends or ctx is done; it must be read to the end.
+func (op *Operation) Subscribe(ctx context.Context, root any) chan *graphql.Result {
+	p := op.params
+	p.Context = ctx
+	p.Root = root
+	return graphql.ExecuteSubscription(p)
+}
+
+// Encode returns the JSON of a result, with the code of each error: that of
+// the *Error a resolver returned, otherwise internal for errors of fields
+// and bad user input for those without a path, which fail the operation
+// before it executes, e.g. invalid variables.
+func Encode(result *graphql.Result) []byte {
+	for i := range result.Errors {
+		e := &result.Errors[i]
+		code := errorCode(e)
+		if code == "" {
+			code = CodeInternal
+			if len(e.Path) == 0 {
+				code = CodeBadUserInput
+			}
+		}
+		e.Extensions = map[string]any{"code": code}
+	}
+	b, err := json.Marshal(result)
+	if err != nil {
+		return ErrorResponse(NewError(CodeInternal, "Failed to encode response: %v", err))
+	}
+	return b
+}
+
+// errorCode returns the code of the *Error behind a formatted error, "" if
+// there is none.
+func errorCode(e *gqlerrors.FormattedError) string {
+	err := e.OriginalError()
+	if located, ok := err.(*gqlerrors.Error); ok {
+		err = located.OriginalError
+	}
+	var apiErr *Error
+	if errors.As(err, &apiErr) {
+		return apiErr.Code()
+	}
+	return ""
+}
+
+// ErrorResponse returns the JSON response to a request that failed before
+// execution, which has no data.
+func ErrorResponse(err *Error) []byte {
+	b, _ := json.Marshal(struct {
+		Errors []Error `json:"errors"`
+	}{[]Error{*err}})
+	return b
+}
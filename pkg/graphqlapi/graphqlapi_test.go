@@ -0,0 +1,180 @@
+package graphqlapi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// testParser parses requests against a schema that resolves a single
+// account, 0.0.1, and streams two transactions, then fails the stream if
+// asked to with the account "fail".
+func testParser(t *testing.T) func(Request) (*Operation, *Error) {
+	s, err := NewSchema(Resolvers{
+		Balance: func(ctx context.Context, accountID string) (*Balance, error) {
+			if accountID != "0.0.1" {
+				return nil, NewError(CodeNotFound, "account %s not found", accountID)
+			}
+			return &Balance{AccountID: accountID, BalanceTinybar: 1 << 60, Timestamp: "now"}, nil
+		},
+		Balances: func(ctx context.Context, ids []string) ([]*Balance, error) {
+			if slices.Contains(ids, "down") {
+				return nil, NewError(CodeInternal, "database unavailable")
+			}
+			balances := make([]*Balance, len(ids))
+			for i, id := range ids {
+				balances[i] = &Balance{AccountID: id, Timestamp: "a \"quoted\"\n time"}
+			}
+			return balances, nil
+		},
+		Transactions: func(ctx context.Context, root any, args TransactionsArgs) (chan any, error) {
+			if args.RateLimit < 0 {
+				return nil, NewError(CodeBadUserInput, "negative rate")
+			}
+			events := make(chan any, 3)
+			for i := range 2 {
+				events <- &Transaction{TxID: fmt.Sprintf("tx%d", i), FromAccount: args.Account, SentAtUnixNano: int64(args.RateLimit)}
+			}
+			if args.Account == "fail" {
+				events <- NewError(CodeInternal, "stream failed")
+			}
+			close(events)
+			return events, nil
+		},
+	})
+	if err != nil {
+		t.Fatalf("NewSchema() error = %v", err)
+	}
+	return func(req Request) (*Operation, *Error) { return Parse(s, &req) }
+}
+
+func TestParse(t *testing.T) {
+	parse := testParser(t)
+	tests := []struct {
+		query string
+		name  string
+		code  string // "" for a valid operation
+		want  string
+	}{
+		{`query Balances($ids: [ID!]!) { balances(ids: $ids) { accountId } }`, "", "", ""},
+		{`subscription { transactions(rateLimit: 10) { txId sentAtUnixNano } }`, "", "", ""},
+		{`query A { __typename } query B { __typename }`, "B", "", ""},
+		{`query {`, "", CodeParseFailed, "Syntax Error"},
+		{`{ balance(accountId: "1") { owner } }`, "", CodeValidationFailed, `Cannot query field "owner" on type "Balance"`},
+		{`{ balance(id: "1") { accountId } }`, "", CodeValidationFailed, `Unknown argument "id"`},
+		{`{ balance(accountId: "1") }`, "", CodeValidationFailed, "must have a sub selection"},
+		{`{ balance(accountId: $id) { accountId } }`, "", CodeValidationFailed, `Variable "$id" is not defined`},
+		{`subscription { a: transactions { txId } b: transactions { txId } }`, "", CodeValidationFailed, "exactly one field"},
+		{`query A { __typename } query B { __typename }`, "", CodeValidationFailed, "operation name"},
+		{`mutation { submit }`, "", CodeValidationFailed, "mutation"},
+	}
+	for _, tt := range tests {
+		op, err := parse(Request{Query: tt.query, OperationName: tt.name})
+		if tt.code == "" {
+			if err != nil || op == nil {
+				t.Errorf("Parse(%q) error = %v", tt.query, err)
+			}
+			continue
+		}
+		if err == nil || err.Code() != tt.code || !strings.Contains(err.Message, tt.want) {
+			t.Errorf("Parse(%q) error = %v, want %s containing %q", tt.query, err, tt.code, tt.want)
+		}
+	}
+}
+
+func TestOperation_Execute(t *testing.T) {
+	parse := testParser(t)
+	op, gqlErr := parse(Request{Query: `query ($ids: [ID!]!) {
+		a: balance(accountId: "0.0.1") { __typename accountId balanceTinybar }
+		missing: balance(accountId: 9) { accountId }
+		balances(ids: $ids) { timestamp }
+	}`, Variables: map[string]any{"ids": []any{"0.0.2"}}})
+	if gqlErr != nil {
+		t.Fatal(gqlErr)
+	}
+	if op.Type != "query" {
+		t.Errorf("operation type = %q, want query", op.Type)
+	}
+
+	var resp Response[struct {
+		A        *Balance  `json:"a"`
+		Missing  *Balance  `json:"missing"`
+		Balances []Balance `json:"balances"`
+	}]
+	if err := json.Unmarshal(Encode(op.Execute(context.Background())), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if a := resp.Data.A; a == nil || a.AccountID != "0.0.1" || a.BalanceTinybar != 1<<60 {
+		t.Errorf("a = %+v, want 0.0.1 with its 64-bit balance", a)
+	}
+	if resp.Data.Missing != nil || len(resp.Data.Balances) != 1 || resp.Data.Balances[0].Timestamp != "a \"quoted\"\n time" {
+		t.Errorf("data = %+v, want a null missing balance and one balance", resp.Data)
+	}
+	// The error of a field keeps the code its resolver gave it
+	if len(resp.Errors) != 1 || resp.Errors[0].Code() != CodeNotFound || resp.Errors[0].Error() != "missing: account 9 not found" {
+		t.Errorf("errors = %+v, want the missing account not found", resp.Errors)
+	}
+
+	// A failed non-null field nulls the data
+	op, _ = parse(Request{Query: `{ balances(ids: ["down"]) { accountId } }`})
+	var failed Response[*BalancesData]
+	if err := json.Unmarshal(Encode(op.Execute(context.Background())), &failed); err != nil {
+		t.Fatal(err)
+	}
+	if failed.Data != nil || len(failed.Errors) != 1 || failed.Errors[0].Code() != CodeInternal {
+		t.Errorf("response = %+v, want null data and the internal error", failed)
+	}
+
+	// Invalid variables fail the operation before it executes
+	op, _ = parse(Request{Query: `query ($ids: [ID!]!) { balances(ids: $ids) { accountId } }`})
+	var invalid Response[*BalancesData]
+	if err := json.Unmarshal(Encode(op.Execute(context.Background())), &invalid); err != nil {
+		t.Fatal(err)
+	}
+	if invalid.Data != nil || len(invalid.Errors) != 1 || invalid.Errors[0].Code() != CodeBadUserInput {
+		t.Errorf("response without the required $ids = %+v, want a bad user input error", invalid)
+	}
+}
+
+func TestOperation_Subscribe(t *testing.T) {
+	parse := testParser(t)
+	subscribe := func(query string, vars map[string]any) []Response[TransactionsData] {
+		op, gqlErr := parse(Request{Query: query, Variables: vars})
+		if gqlErr != nil {
+			t.Fatal(gqlErr)
+		}
+		var events []Response[TransactionsData]
+		for result := range op.Subscribe(context.Background(), nil) {
+			var event Response[TransactionsData]
+			if err := json.Unmarshal(Encode(result), &event); err != nil {
+				t.Fatal(err)
+			}
+			events = append(events, event)
+		}
+		return events
+	}
+
+	events := subscribe(`subscription ($rate: Int) { transactions(rateLimit: $rate, account: "0.0.1") { txId fromAccount sentAtUnixNano } }`,
+		map[string]any{"rate": 10.0})
+	if len(events) != 2 {
+		t.Fatalf("received %d events, want 2", len(events))
+	}
+	if tx := events[1].Data.Transactions; tx == nil || tx.TxID != "tx1" || tx.FromAccount != "0.0.1" || tx.SentAtUnixNano != 10 {
+		t.Errorf("second event = %+v, want tx1 with the arguments", tx)
+	}
+
+	// An error sent on the stream fails its event
+	events = subscribe(`subscription { transactions(account: "fail") { txId } }`, nil)
+	if last := events[len(events)-1]; len(events) != 3 || last.Data.Transactions != nil || last.Errors[0].Code() != CodeInternal {
+		t.Errorf("events = %+v, want the stream error last", events)
+	}
+
+	// As does an error subscribing, in the only result
+	events = subscribe(`subscription { transactions(rateLimit: -1) { txId } }`, nil)
+	if len(events) != 1 || len(events[0].Errors) != 1 || events[0].Errors[0].Code() != CodeBadUserInput {
+		t.Errorf("events = %+v, want the subscription error", events)
+	}
+}
@@ -0,0 +1,17 @@
+// Package grpcmethod holds helpers for the full gRPC method names the
+// servers' interceptors see, shared by the packages that only act on the
+// benchmark services.
+package grpcmethod
+
+import "strings"
+
+// InServices reports whether fullMethod ("/package.Service/Method")
+// belongs to one of services.
+func InServices(fullMethod string, services []string) bool {
+	for _, s := range services {
+		if strings.HasPrefix(fullMethod, "/"+s+"/") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,19 @@
+package grpcmethod
+
+import "testing"
+
+func TestInServices(t *testing.T) {
+	services := []string{"benchmark.BalanceService", "benchmark.TransactionService"}
+	tests := map[string]bool{
+		"/benchmark.BalanceService/GetBalance":                      true,
+		"/benchmark.TransactionService/StreamTransactions":          true,
+		"/grpc.health.v1.Health/Check":                              false,
+		"/benchmark.BalanceServiceV2/GetBalance":                    false,
+		"/grpc.reflection.v1.ServerReflection/ServerReflectionInfo": false,
+	}
+	for method, want := range tests {
+		if got := InServices(method, services); got != want {
+			t.Errorf("InServices(%q) = %v, want %v", method, got, want)
+		}
+	}
+}
@@ -0,0 +1,83 @@
+// Package jsoncodec provides the JSON encoders the REST server and client
+// can switch between, to measure how much of REST's cost is the encoder.
+// Every encoder produces the same JSON as encoding/json, so a client and
+// server using different ones still interoperate.
+package jsoncodec
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/bytedance/sonic"
+	jsoniter "github.com/json-iterator/go"
+	"github.com/mailru/easyjson"
+)
+
+// Encoder names. Std is the standard library's encoding/json; EasyJSON uses
+// code generated for the types of pkg/restapi and falls back to Std for
+// other values.
+const (
+	Std      = "std"
+	JSONIter = "jsoniter"
+	Sonic    = "sonic"
+	EasyJSON = "easyjson"
+)
+
+// Names lists the selectable encoders.
+var Names = []string{Std, JSONIter, Sonic, EasyJSON}
+
+// Codec marshals and unmarshals JSON.
+type Codec interface {
+	Marshal(v any) ([]byte, error)
+	Unmarshal(data []byte, v any) error
+}
+
+// New returns the named encoder.
+func New(name string) (Codec, error) {
+	switch name {
+	case Std:
+		return stdCodec{}, nil
+	case JSONIter:
+		return jsoniter.ConfigCompatibleWithStandardLibrary, nil
+	case Sonic:
+		return sonic.ConfigStd, nil
+	case EasyJSON:
+		return easyCodec{}, nil
+	}
+	return nil, fmt.Errorf("unknown JSON encoder %q (must be one of: %s)", name, strings.Join(Names, ", "))
+}
+
+// Label describes the encoders of a client and server for the run record:
+// "" if both use Std, the name if they use the same one, and otherwise
+// e.g. "client=sonic,server=std".
+func Label(client, server string) string {
+	switch {
+	case client == server && client == Std:
+		return ""
+	case client == server:
+		return client
+	}
+	return "client=" + client + ",server=" + server
+}
+
+type stdCodec struct{}
+
+func (stdCodec) Marshal(v any) ([]byte, error)      { return json.Marshal(v) }
+func (stdCodec) Unmarshal(data []byte, v any) error { return json.Unmarshal(data, v) }
+
+type easyCodec struct{}
+
+func (easyCodec) Marshal(v any) ([]byte, error) {
+	if m, ok := v.(easyjson.Marshaler); ok {
+		return easyjson.Marshal(m)
+	}
+	return json.Marshal(v)
+}
+
+func (easyCodec) Unmarshal(data []byte, v any) error {
+	if u, ok := v.(easyjson.Unmarshaler); ok {
+		return easyjson.Unmarshal(data, u)
+	}
+	return json.Unmarshal(data, v)
+}
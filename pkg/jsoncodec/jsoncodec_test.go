@@ -0,0 +1,68 @@
+package jsoncodec
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/kaldun-tech/grpc-rest-benchmark/pkg/restapi"
+)
+
+func TestCodecs_MatchStd(t *testing.T) {
+	values := []any{
+		&restapi.BalanceResponse{Account: "0.0.1001", Balance: 500, Timestamp: "2026-10-18T12:00:00Z"},
+		&restapi.BatchBalanceResponse{Balances: []restapi.BalanceResponse{{Account: "0.0.1001"}, {Account: "0.0.1002", Balance: -1}}},
+		&restapi.TransactionEvent{TxID: "0.0.1001@1700000000.000000001", From: "0.0.1001", To: "0.0.1002", Amount: 500, Type: "transfer"},
+		&restapi.SubmitTransactionRequest{From: "0.0.1001", To: "0.0.1002", Amount: 500},
+		&restapi.EchoResponse{Payload: []byte{0, 1, 2, 0xff}},
+		&restapi.ErrorResponse{Error: `account "<0.0.1>" not found`},
+		&restapi.ParityBatchBalanceResponse{Balances: []restapi.ParityBalanceResponse{{AccountID: "0.0.1001", BalanceTinybar: 500}, {Timestamp: "2026-10-18T12:00:00Z"}}},
+		&restapi.ParityTransaction{TxID: "0.0.1001@1700000000.000000001", AmountTinybar: -5, SentAtUnixNano: 1_700_000_000_123_456_789},
+		&restapi.ParityEchoResponse{Payload: []byte{0, 1}},
+		&map[string]string{"status": "ok"},
+	}
+	for _, name := range Names {
+		codec, err := New(name)
+		if err != nil {
+			t.Fatalf("New(%s) error = %v", name, err)
+		}
+		for _, v := range values {
+			want, _ := json.Marshal(v)
+			got, err := codec.Marshal(v)
+			if err != nil {
+				t.Fatalf("%s Marshal(%T) error = %v", name, v, err)
+			}
+			if !bytes.Equal(got, want) {
+				t.Errorf("%s Marshal(%T) = %s, want %s", name, v, got, want)
+			}
+
+			decoded := reflect.New(reflect.TypeOf(v).Elem()).Interface()
+			if err := codec.Unmarshal(want, decoded); err != nil {
+				t.Fatalf("%s Unmarshal(%T) error = %v", name, v, err)
+			}
+			if !reflect.DeepEqual(decoded, v) {
+				t.Errorf("%s Unmarshal(%s) = %+v, want %+v", name, want, decoded, v)
+			}
+		}
+	}
+
+	if _, err := New("fastjson"); err == nil {
+		t.Error("New(fastjson) succeeded")
+	}
+}
+
+func TestLabel(t *testing.T) {
+	tests := []struct {
+		client, server, want string
+	}{
+		{Std, Std, ""},
+		{Sonic, Sonic, "sonic"},
+		{EasyJSON, Std, "client=easyjson,server=std"},
+	}
+	for _, tt := range tests {
+		if got := Label(tt.client, tt.server); got != tt.want {
+			t.Errorf("Label(%s, %s) = %q, want %q", tt.client, tt.server, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,169 @@
+// Package jsonrpcapi defines the JSON-RPC 2.0 API of the REST server's
+// /api/v1/jsonrpc endpoint, shared by the server and the jsonrpc benchmark
+// client.
+//
+// Clients POST a request object, or a batch array of them, and get the
+// response object, or array, of the JSON-RPC 2.0 specification;
+// notifications (requests without an id) are executed without a response.
+// Parameters are named. The methods return the JSON bodies of the
+// equivalent REST endpoints (see restapi), so that comparing the two
+// measures the envelope rather than differing payloads:
+//
+//	getBalance          BalanceParams                     restapi.BalanceResponse
+//	getBalances         BalancesParams                    restapi.BatchBalanceResponse
+//	submitTransaction   restapi.SubmitTransactionRequest  restapi.TransactionEvent
+//	echo                EchoParams                        restapi.EchoResponse
+//	streamTransactions  StreamParams                      restapi.StreamDone
+//
+// JSON-RPC has no streaming of its own. A streamTransactions request is
+// answered with newline-delimited JSON (StreamContentType): a "transaction"
+// notification per transaction, whose params are a restapi.TransactionEvent,
+// then the response, whose result holds the number of transactions sent,
+// or the error that ended the stream. It cannot be part of a batch.
+package jsonrpcapi
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// Version is the value of the jsonrpc member of every request and response.
+const Version = "2.0"
+
+// Methods of the API.
+const (
+	MethodGetBalance         = "getBalance"
+	MethodGetBalances        = "getBalances"
+	MethodSubmitTransaction  = "submitTransaction"
+	MethodEcho               = "echo"
+	MethodStreamTransactions = "streamTransactions"
+
+	// NotificationTransaction is the method of the notifications carrying
+	// the transactions of a stream.
+	NotificationTransaction = "transaction"
+)
+
+// StreamContentType is the media type of streamTransactions responses.
+const StreamContentType = "application/x-ndjson"
+
+// Request is a request or, without an ID, a notification. The streams'
+// transaction notifications are Requests too.
+type Request struct {
+	JSONRPC string          `json:"jsonrpc"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+	ID      json.RawMessage `json:"id,omitempty"`
+}
+
+// IsNotification reports whether r expects no response. A null ID is an ID.
+func (r *Request) IsNotification() bool {
+	return r.ID == nil
+}
+
+// Validate checks that r is a JSON-RPC 2.0 request, returning an error
+// with CodeInvalidRequest if not.
+func (r *Request) Validate() *Error {
+	if r.JSONRPC != Version {
+		return NewError(CodeInvalidRequest, "jsonrpc must be %q", Version)
+	}
+	if r.Method == "" {
+		return NewError(CodeInvalidRequest, "method is required")
+	}
+	if len(r.ID) > 0 {
+		switch c := r.ID[0]; {
+		case c == '"', c == '-', c >= '0' && c <= '9', string(r.ID) == "null":
+		default:
+			return NewError(CodeInvalidRequest, "id must be a string, a number or null")
+		}
+	}
+	if len(r.Params) > 0 && r.Params[0] != '{' {
+		return NewError(CodeInvalidParams, "params must be an object, by-position parameters are not supported")
+	}
+	return nil
+}
+
+// Response is the response to a request, with either a result or an error.
+// Its ID is null if the request's could not be read.
+type Response struct {
+	JSONRPC string          `json:"jsonrpc"`
+	Result  json.RawMessage `json:"result,omitempty"`
+	Error   *Error          `json:"error,omitempty"`
+	ID      json.RawMessage `json:"id"`
+}
+
+// BalanceParams are the parameters of getBalance.
+type BalanceParams struct {
+	Account string `json:"account"`
+}
+
+// BalancesParams are the parameters of getBalances.
+type BalancesParams struct {
+	IDs []string `json:"ids"`
+}
+
+// EchoParams are the parameters of echo, whose result carries a payload of
+// size bytes.
+type EchoParams struct {
+	Size int `json:"size"`
+}
+
+// StreamParams are the parameters of streamTransactions, those of the query
+// of the REST stream.
+type StreamParams struct {
+	Since   string `json:"since,omitempty"` // RFC 3339
+	Account string `json:"account,omitempty"`
+	Rate    int    `json:"rate,omitempty"`
+	After   string `json:"after,omitempty"` // resume after this transaction ID
+	Live    bool   `json:"live,omitempty"`
+}
+
+// Error codes. The first five are defined by the specification, which
+// reserves -32000 to -32099 for server errors such as CodeNotFound.
+const (
+	CodeParseError     = -32700
+	CodeInvalidRequest = -32600
+	CodeMethodNotFound = -32601
+	CodeInvalidParams  = -32602
+	CodeInternalError  = -32603
+
+	// CodeNotFound reports a request for an account that does not exist.
+	CodeNotFound = -32004
+)
+
+// codeNames are the names of the error codes, as CodeName returns them.
+var codeNames = map[int]string{
+	CodeParseError:     "parse_error",
+	CodeInvalidRequest: "invalid_request",
+	CodeMethodNotFound: "method_not_found",
+	CodeInvalidParams:  "invalid_params",
+	CodeInternalError:  "internal_error",
+	CodeNotFound:       "not_found",
+}
+
+// CodeName returns the name of an error code, e.g. "method_not_found":
+// "server_error" for other codes of the server error range, and "error"
+// for codes outside it.
+func CodeName(code int) string {
+	if name, ok := codeNames[code]; ok {
+		return name
+	}
+	if code >= -32099 && code <= -32000 {
+		return "server_error"
+	}
+	return "error"
+}
+
+// Error is the error of a response.
+type Error struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// NewError returns an error with the given code and formatted message.
+func NewError(code int, format string, args ...any) *Error {
+	return &Error{Code: code, Message: fmt.Sprintf(format, args...)}
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("JSON-RPC error %d: %s", e.Code, e.Message)
+}
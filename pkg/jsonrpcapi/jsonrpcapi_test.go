@@ -0,0 +1,61 @@
+package jsonrpcapi
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestRequest_Validate(t *testing.T) {
+	tests := []struct {
+		body string
+		want int // 0 for a valid request
+	}{
+		{`{"jsonrpc":"2.0","method":"getBalance","params":{"account":"0.0.1"},"id":1}`, 0},
+		{`{"jsonrpc":"2.0","method":"echo","id":"a"}`, 0},
+		{`{"jsonrpc":"2.0","method":"echo","id":null}`, 0},
+		{`{"jsonrpc":"1.0","method":"echo","id":1}`, CodeInvalidRequest},
+		{`{"jsonrpc":"2.0","id":1}`, CodeInvalidRequest},
+		{`{"jsonrpc":"2.0","method":"echo","id":{}}`, CodeInvalidRequest},
+		{`{"jsonrpc":"2.0","method":"echo","params":[1024],"id":1}`, CodeInvalidParams},
+	}
+	for _, tt := range tests {
+		var req Request
+		if err := json.Unmarshal([]byte(tt.body), &req); err != nil {
+			t.Fatal(err)
+		}
+		err := req.Validate()
+		if tt.want == 0 && err != nil || tt.want != 0 && (err == nil || err.Code != tt.want) {
+			t.Errorf("Validate(%s) = %v, want code %d", tt.body, err, tt.want)
+		}
+	}
+}
+
+func TestRequest_IsNotification(t *testing.T) {
+	var req Request
+	if err := json.Unmarshal([]byte(`{"jsonrpc":"2.0","method":"echo"}`), &req); err != nil {
+		t.Fatal(err)
+	}
+	if !req.IsNotification() {
+		t.Error("IsNotification() of a request without an id = false, want true")
+	}
+	if err := json.Unmarshal([]byte(`{"jsonrpc":"2.0","method":"echo","id":null}`), &req); err != nil {
+		t.Fatal(err)
+	}
+	if req.IsNotification() {
+		t.Error("IsNotification() of a request with a null id = true, want false")
+	}
+}
+
+func TestCodeName(t *testing.T) {
+	tests := map[int]string{
+		CodeParseError: "parse_error",
+		CodeNotFound:   "not_found",
+		-32050:         "server_error",
+		42:             "error",
+	}
+	for code, want := range tests {
+		if got := CodeName(code); got != want {
+			t.Errorf("CodeName(%d) = %q, want %q", code, got, want)
+		}
+	}
+}
@@ -0,0 +1,433 @@
+// Package launch starts benchmark runs on request of the results API, so
+// that a dashboard can drive the whole workflow: the REST server executes
+// the benchmark client (--launch-cmd) with a workload file sent in the
+// request, locally or generating the load on a worker agent, configured
+// (--launch-agent) or registered in the agent registry, follows its status
+// and links the runs it stores.
+//
+// One launched run executes at a time, so runs do not compete for the
+// servers they measure. Launching is disabled unless --launch-cmd is set;
+// enable it on trusted networks only, since anyone reaching the API can
+// start runs.
+package launch
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"maps"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kaldun-tech/grpc-rest-benchmark/pkg/db"
+	"github.com/kaldun-tech/grpc-rest-benchmark/pkg/workload"
+)
+
+// Statuses of a launch.
+const (
+	StatusRunning   = "running"
+	StatusSucceeded = "succeeded"
+	StatusFailed    = "failed"
+	StatusCanceled  = "canceled"
+)
+
+const (
+	// maxOutput bounds the output of a run kept with its launch, the end
+	// of it.
+	maxOutput = 64 << 10
+
+	// maxLaunches bounds the finished launches kept, the newest.
+	maxLaunches = 100
+
+	// stopTimeout is how long a canceled run may take to store its results
+	// and exit before it is killed.
+	stopTimeout = 30 * time.Second
+)
+
+var (
+	// ErrBusy is returned when a run is launched while another one runs.
+	ErrBusy = errors.New("a launched run is still running")
+
+	// ErrInvalidRequest wraps the errors of a request with an invalid
+	// workload or an unknown agent.
+	ErrInvalidRequest = errors.New("invalid launch request")
+
+	// ErrNotFound is returned for a launch the server does not know.
+	ErrNotFound = errors.New("launch not found")
+)
+
+// Config holds the launch flags.
+type Config struct {
+	Command string            // benchmark client executable; empty disables launching
+	Args    string            // extra flags passed to every run, e.g. server addresses
+	Agents  map[string]string // worker agent addresses by name
+}
+
+// RegisterFlags registers the launch flags on fs.
+func (c *Config) RegisterFlags(fs *flag.FlagSet) {
+	fs.StringVar(&c.Command, "launch-cmd", "", "Benchmark client executable that POST /api/v1/runs/launch runs, e.g. ./benchmark (empty = launching disabled)")
+	fs.StringVar(&c.Args, "launch-args", "", "Extra flags passed to every launched run, e.g. \"--grpc-addr=grpc:50051 --log-dir=\"")
+	fs.Func("launch-agent", "Worker agent launched runs may generate their load on, as name=HOST:PORT of a 'benchmark worker' (repeatable)", c.setAgent)
+}
+
+// setAgent adds one name=HOST:PORT agent.
+func (c *Config) setAgent(v string) error {
+	name, addr, ok := strings.Cut(v, "=")
+	if !ok || name == "" || addr == "" {
+		return fmt.Errorf("want name=HOST:PORT, got %q", v)
+	}
+	if _, dup := c.Agents[name]; dup {
+		return fmt.Errorf("agent %s given twice", name)
+	}
+	if c.Agents == nil {
+		c.Agents = make(map[string]string)
+	}
+	c.Agents[name] = addr
+	return nil
+}
+
+// Validate checks the launch flags for invalid combinations.
+func (c Config) Validate() error {
+	if !c.Enabled() && (c.Args != "" || len(c.Agents) > 0) {
+		return fmt.Errorf("launch-args and launch-agent require --launch-cmd")
+	}
+	return nil
+}
+
+// Enabled reports whether runs may be launched.
+func (c Config) Enabled() bool {
+	return c.Command != ""
+}
+
+// AgentNames returns the names of the configured agents, sorted.
+func (c Config) AgentNames() []string {
+	return slices.Sorted(maps.Keys(c.Agents))
+}
+
+// String describes the configuration for the server log, e.g.
+// "./benchmark, agents: east, west".
+func (c Config) String() string {
+	if len(c.Agents) == 0 {
+		return c.Command
+	}
+	return c.Command + ", agents: " + strings.Join(c.AgentNames(), ", ")
+}
+
+// Request is a run to launch.
+type Request struct {
+	Workload string `json:"workload"`        // workload file contents, YAML or JSON
+	Agent    string `json:"agent,omitempty"` // registered agent generating the load, empty to run locally
+}
+
+// Launch is the state of a launched run.
+type Launch struct {
+	ID         int64
+	Status     string // one of the Status constants
+	Workload   string // workload name
+	Agent      string // empty when run locally
+	StartedAt  time.Time
+	FinishedAt time.Time // zero while running
+	Error      string    // why the run failed
+	RunIDs     []int64   // runs stored so far, one per workload stage
+	Output     string    // the end of the client's output
+}
+
+// Registry looks up the agents registered by 'benchmark worker --register'.
+// *db.DB implements it.
+type Registry interface {
+	GetAgent(ctx context.Context, name string) (*db.Agent, error)
+}
+
+// Launcher starts and follows launched runs. It is safe for concurrent
+// use; Agents and Close accept a nil *Launcher.
+type Launcher struct {
+	cfg      Config
+	dbArgs   []string // flags of the results database, without the password
+	dbEnv    []string // environment of the client, with the database password
+	registry Registry // nil to launch on configured agents only
+
+	ctx    context.Context // canceled by Close, ending every run
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+
+	mu       sync.Mutex
+	nextID   int64
+	launches []*launch // oldest first
+	running  *launch
+}
+
+// launch is a Launch with the state of its process.
+type launch struct {
+	Launch
+	dir     string // temporary directory with the workload and run ID files
+	output  *tailBuffer
+	cancel  context.CancelFunc
+	stopped bool // canceled by Cancel
+}
+
+// New returns a launcher whose runs store their results in database, or
+// nil if launching is disabled. Agents not configured with --launch-agent
+// are looked up in registry, if not nil.
+func New(cfg Config, database db.Config, registry Registry) *Launcher {
+	if !cfg.Enabled() {
+		return nil
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Launcher{
+		cfg: cfg,
+		dbArgs: []string{
+			"--db-host=" + database.Host,
+			"--db-port=" + strconv.Itoa(database.Port),
+			"--db-user=" + database.User,
+			"--db-name=" + database.Database,
+		},
+		// Out of the arguments, which any local user can read
+		dbEnv:    append(os.Environ(), db.PasswordEnv+"="+database.Password),
+		registry: registry,
+		ctx:      ctx,
+		cancel:   cancel,
+	}
+}
+
+// Agents returns the names of the configured agents, sorted.
+func (l *Launcher) Agents() []string {
+	if l == nil {
+		return nil
+	}
+	return l.cfg.AgentNames()
+}
+
+// Start validates the request's workload and launches its run, returning
+// without waiting for it. It returns ErrBusy while another run executes.
+func (l *Launcher) Start(ctx context.Context, req Request) (Launch, error) {
+	w, err := workload.Parse([]byte(req.Workload))
+	if err != nil {
+		return Launch{}, fmt.Errorf("%w: %w", ErrInvalidRequest, err)
+	}
+	agentArgs, err := l.agentArgs(ctx, req.Agent)
+	if err != nil {
+		return Launch{}, err
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.running != nil {
+		return Launch{}, ErrBusy
+	}
+
+	dir, err := os.MkdirTemp("", "benchmark-launch-")
+	if err != nil {
+		return Launch{}, fmt.Errorf("failed to create launch directory: %w", err)
+	}
+	workloadPath := filepath.Join(dir, "workload.yaml")
+	if err := os.WriteFile(workloadPath, []byte(req.Workload), 0o600); err != nil {
+		os.RemoveAll(dir)
+		return Launch{}, fmt.Errorf("failed to write workload: %w", err)
+	}
+
+	args := []string{"run", "--workload=" + workloadPath, "--run-ids-file=" + filepath.Join(dir, "run-ids")}
+	args = append(args, agentArgs...)
+	args = append(args, l.dbArgs...)
+	args = append(args, strings.Fields(l.cfg.Args)...)
+
+	runCtx, cancel := context.WithCancel(l.ctx)
+	output := &tailBuffer{max: maxOutput}
+	cmd := exec.CommandContext(runCtx, l.cfg.Command, args...)
+	cmd.Env = l.dbEnv
+	cmd.Stdout, cmd.Stderr = output, output
+	// Interrupt the client like Ctrl-C, so it stores the run in progress
+	cmd.Cancel = func() error { return cmd.Process.Signal(os.Interrupt) }
+	cmd.WaitDelay = stopTimeout
+	if err := cmd.Start(); err != nil {
+		cancel()
+		os.RemoveAll(dir)
+		return Launch{}, fmt.Errorf("failed to start %s: %w", l.cfg.Command, err)
+	}
+
+	l.nextID++
+	ln := &launch{
+		Launch: Launch{
+			ID:        l.nextID,
+			Status:    StatusRunning,
+			Workload:  w.Name,
+			Agent:     req.Agent,
+			StartedAt: time.Now(),
+		},
+		dir:    dir,
+		output: output,
+		cancel: cancel,
+	}
+	l.launches = append(l.launches, ln)
+	l.running = ln
+	if len(l.launches) > maxLaunches {
+		l.launches = slices.Delete(l.launches, 0, len(l.launches)-maxLaunches)
+	}
+
+	l.wg.Add(1)
+	go l.wait(ln, cmd)
+	return ln.snapshot(), nil
+}
+
+// wait records the outcome of a launched run once its process exits.
+func (l *Launcher) wait(ln *launch, cmd *exec.Cmd) {
+	defer l.wg.Done()
+	err := cmd.Wait()
+	ln.cancel()
+	runIDs := readRunIDs(filepath.Join(ln.dir, "run-ids"))
+	defer os.RemoveAll(ln.dir)
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	ln.FinishedAt = time.Now()
+	ln.RunIDs = runIDs
+	ln.Output = ln.output.String()
+	switch {
+	case ln.stopped || l.ctx.Err() != nil:
+		ln.Status = StatusCanceled
+	case err != nil:
+		ln.Status = StatusFailed
+		ln.Error = err.Error()
+	default:
+		ln.Status = StatusSucceeded
+	}
+	l.running = nil
+}
+
+// agentArgs returns the client flags generating the load on agent, none to
+// run locally. A configured agent is passed by address; a registered one
+// by name, so the client records it with the runs, and must be healthy.
+func (l *Launcher) agentArgs(ctx context.Context, agent string) ([]string, error) {
+	if agent == "" {
+		return nil, nil
+	}
+	if addr, ok := l.cfg.Agents[agent]; ok {
+		return []string{"--workers=" + addr}, nil
+	}
+	if l.registry == nil {
+		return nil, fmt.Errorf("%w: unknown agent %s (must be one of: %s)", ErrInvalidRequest, agent, strings.Join(l.cfg.AgentNames(), ", "))
+	}
+	a, err := l.registry.GetAgent(ctx, agent)
+	if errors.Is(err, db.ErrAgentNotFound) {
+		return nil, fmt.Errorf("%w: unknown agent %s", ErrInvalidRequest, agent)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up agent %s: %w", agent, err)
+	}
+	if !a.Healthy {
+		return nil, fmt.Errorf("%w: agent %s is not healthy (last heartbeat %s)", ErrInvalidRequest, agent, a.LastHeartbeat.Format(time.RFC3339))
+	}
+	return []string{"--agents=" + agent}, nil
+}
+
+// Get returns the launch with the given ID.
+func (l *Launcher) Get(id int64) (Launch, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for _, ln := range l.launches {
+		if ln.ID == id {
+			return ln.snapshot(), nil
+		}
+	}
+	return Launch{}, ErrNotFound
+}
+
+// List returns the launches the launcher keeps, newest first.
+func (l *Launcher) List() []Launch {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	launches := make([]Launch, 0, len(l.launches))
+	for _, ln := range slices.Backward(l.launches) {
+		launches = append(launches, ln.snapshot())
+	}
+	return launches
+}
+
+// Cancel stops a running launch. Runs it already stored are kept; it does
+// nothing to a finished launch.
+func (l *Launcher) Cancel(id int64) (Launch, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for _, ln := range l.launches {
+		if ln.ID == id {
+			if ln.Status == StatusRunning {
+				ln.stopped = true
+				ln.cancel()
+			}
+			return ln.snapshot(), nil
+		}
+	}
+	return Launch{}, ErrNotFound
+}
+
+// Close stops the running launch, if any, and waits for it to exit.
+func (l *Launcher) Close() {
+	if l == nil {
+		return
+	}
+	l.cancel()
+	l.wg.Wait()
+}
+
+// snapshot copies the launch, reading the runs and output of a running one
+// so far. The launcher's lock must be held.
+func (ln *launch) snapshot() Launch {
+	s := ln.Launch
+	if s.Status == StatusRunning {
+		s.RunIDs = readRunIDs(filepath.Join(ln.dir, "run-ids"))
+		s.Output = ln.output.String()
+	}
+	s.RunIDs = slices.Clone(s.RunIDs)
+	return s
+}
+
+// readRunIDs reads the run IDs a run appended to its --run-ids-file, none
+// if it stored no run yet.
+func readRunIDs(path string) []int64 {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+
+	var ids []int64
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if id, err := strconv.ParseInt(strings.TrimSpace(scanner.Text()), 10, 64); err == nil {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// tailBuffer keeps the last max bytes written to it. It is safe for
+// concurrent use.
+type tailBuffer struct {
+	max int
+
+	mu  sync.Mutex
+	buf []byte
+}
+
+func (b *tailBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.buf = append(b.buf, p...)
+	if over := len(b.buf) - b.max; over > 0 {
+		b.buf = append(b.buf[:0], b.buf[over:]...)
+	}
+	return len(p), nil
+}
+
+func (b *tailBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return string(b.buf)
+}
@@ -0,0 +1,212 @@
+package launch
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kaldun-tech/grpc-rest-benchmark/pkg/db"
+)
+
+const minimal = `
+version: 1
+name: smoke
+protocol: grpc
+operations:
+  - scenario: balance
+stages:
+  - name: only
+    duration: 1s
+`
+
+// testRegistry is an agent registry of fixed agents.
+type testRegistry map[string]*db.Agent
+
+func (r testRegistry) GetAgent(ctx context.Context, name string) (*db.Agent, error) {
+	a, ok := r[name]
+	if !ok {
+		return nil, db.ErrAgentNotFound
+	}
+	return a, nil
+}
+
+// testLauncher returns a launcher running a shell script in place of the
+// benchmark client.
+func testLauncher(t *testing.T, script string, agents map[string]string) *Launcher {
+	return testRegistryLauncher(t, script, agents, nil)
+}
+
+// testRegistryLauncher is testLauncher looking up other agents in registry.
+func testRegistryLauncher(t *testing.T, script string, agents map[string]string, registry Registry) *Launcher {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "benchmark")
+	if err := os.WriteFile(path, []byte("#!/bin/sh\n"+script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	l := New(Config{Command: path, Agents: agents}, db.Config{Host: "localhost", Port: 5432, Password: "secret"}, registry)
+	t.Cleanup(l.Close)
+	return l
+}
+
+// waitDone polls a launch until it finishes.
+func waitDone(t *testing.T, l *Launcher, id int64) Launch {
+	t.Helper()
+	deadline := time.Now().Add(10 * time.Second)
+	for time.Now().Before(deadline) {
+		ln, err := l.Get(id)
+		if err != nil {
+			t.Fatalf("Get(%d) error = %v", id, err)
+		}
+		if ln.Status != StatusRunning {
+			return ln
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("launch %d still running", id)
+	return Launch{}
+}
+
+func TestNew_Disabled(t *testing.T) {
+	if l := New(Config{}, db.Config{}, nil); l != nil {
+		t.Error("New() without a command = non-nil, want nil")
+	}
+}
+
+func TestLauncher_Start(t *testing.T) {
+	l := testLauncher(t, `
+for a in "$@"; do
+	case "$a" in --run-ids-file=*) ids="${a#--run-ids-file=}" ;; esac
+done
+echo "$@"
+echo "password $PGPASSWORD"
+echo 7 >> "$ids"
+echo 8 >> "$ids"
+`, map[string]string{"east": "east:50070"})
+
+	started, err := l.Start(context.Background(), Request{Workload: minimal, Agent: "east"})
+	if err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	if started.Status != StatusRunning || started.Workload != "smoke" || started.Agent != "east" {
+		t.Errorf("Start() = %+v, want a running launch of smoke on east", started)
+	}
+
+	ln := waitDone(t, l, started.ID)
+	if ln.Status != StatusSucceeded {
+		t.Fatalf("status = %s (%s), want %s", ln.Status, ln.Error, StatusSucceeded)
+	}
+	if !slices.Equal(ln.RunIDs, []int64{7, 8}) {
+		t.Errorf("RunIDs = %v, want [7 8]", ln.RunIDs)
+	}
+	for _, arg := range []string{"run --workload=", "--workers=east:50070", "--db-host=localhost"} {
+		if !strings.Contains(ln.Output, arg) {
+			t.Errorf("client args %q do not contain %q", ln.Output, arg)
+		}
+	}
+	if !strings.Contains(ln.Output, "password secret") || strings.Contains(ln.Output, "--db-pass") {
+		t.Errorf("client output %q, want the database password in the environment only", ln.Output)
+	}
+	if got := l.List(); len(got) != 1 || got[0].ID != started.ID {
+		t.Errorf("List() = %+v, want the launch", got)
+	}
+}
+
+func TestLauncher_Failed(t *testing.T) {
+	l := testLauncher(t, "echo boom >&2\nexit 3\n", nil)
+	started, err := l.Start(context.Background(), Request{Workload: minimal})
+	if err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	ln := waitDone(t, l, started.ID)
+	if ln.Status != StatusFailed || ln.Error == "" || !strings.Contains(ln.Output, "boom") {
+		t.Errorf("launch = %+v, want failed with the client's output", ln)
+	}
+}
+
+func TestLauncher_BusyAndCancel(t *testing.T) {
+	l := testLauncher(t, "exec sleep 10\n", nil)
+	started, err := l.Start(context.Background(), Request{Workload: minimal})
+	if err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	if _, err := l.Start(context.Background(), Request{Workload: minimal}); !errors.Is(err, ErrBusy) {
+		t.Errorf("second Start() error = %v, want ErrBusy", err)
+	}
+
+	if _, err := l.Cancel(started.ID); err != nil {
+		t.Fatalf("Cancel() error = %v", err)
+	}
+	if ln := waitDone(t, l, started.ID); ln.Status != StatusCanceled {
+		t.Errorf("status = %s, want %s", ln.Status, StatusCanceled)
+	}
+	if _, err := l.Cancel(99); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Cancel(99) error = %v, want ErrNotFound", err)
+	}
+}
+
+func TestLauncher_InvalidRequest(t *testing.T) {
+	l := testLauncher(t, "exit 0\n", map[string]string{"east": "east:50070"})
+	for name, req := range map[string]Request{
+		"empty workload": {},
+		"bad workload":   {Workload: strings.Replace(minimal, "grpc", "smtp", 1)},
+		"unknown agent":  {Workload: minimal, Agent: "west"},
+	} {
+		if _, err := l.Start(context.Background(), req); !errors.Is(err, ErrInvalidRequest) {
+			t.Errorf("%s: Start() error = %v, want ErrInvalidRequest", name, err)
+		}
+	}
+	if got := l.List(); len(got) != 0 {
+		t.Errorf("List() = %+v, want no launches", got)
+	}
+}
+
+func TestLauncher_RegisteredAgent(t *testing.T) {
+	registry := testRegistry{
+		"west":  {Name: "west", Address: "west:50070", Healthy: true},
+		"north": {Name: "north", Address: "north:50070"},
+	}
+	l := testRegistryLauncher(t, `echo "$@"`+"\n", map[string]string{"east": "east:50070"}, registry)
+
+	for name, agent := range map[string]string{"unknown agent": "south", "unhealthy agent": "north"} {
+		if _, err := l.Start(context.Background(), Request{Workload: minimal, Agent: agent}); !errors.Is(err, ErrInvalidRequest) {
+			t.Errorf("%s: Start() error = %v, want ErrInvalidRequest", name, err)
+		}
+	}
+
+	started, err := l.Start(context.Background(), Request{Workload: minimal, Agent: "west"})
+	if err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	ln := waitDone(t, l, started.ID)
+	if !strings.Contains(ln.Output, "--agents=west") || strings.Contains(ln.Output, "--workers") {
+		t.Errorf("client args %q, want --agents=west", ln.Output)
+	}
+}
+
+func TestConfig_Validate(t *testing.T) {
+	var c Config
+	if err := c.setAgent("east=east:50070"); err != nil {
+		t.Fatalf("setAgent() error = %v", err)
+	}
+	if err := c.setAgent("east=other:50070"); err == nil {
+		t.Error("setAgent() of a duplicate = nil error, want an error")
+	}
+	if err := c.setAgent("east"); err == nil {
+		t.Error("setAgent() without an address = nil error, want an error")
+	}
+	if err := c.Validate(); err == nil {
+		t.Error("Validate() of agents without --launch-cmd = nil error, want an error")
+	}
+	c.Command = "./benchmark"
+	if err := c.Validate(); err != nil {
+		t.Errorf("Validate() error = %v", err)
+	}
+	if got, want := c.String(), "./benchmark, agents: east"; got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,420 @@
+// Package middleware provides the request logging, panic recovery and
+// metrics a production server typically runs every request through, so their
+// overhead can be measured per protocol. The REST server applies them as HTTP
+// middleware (REST and Connect), the gRPC server as interceptors (gRPC and
+// gRPC-Web), in front of the benchmark services only, like pkg/fault.
+//
+// Logged requests are access log entries with the request's method, path,
+// status, duration and response bytes: key=value lines in the server log,
+// or JSON lines in a file of their own, which keeps server-side evidence of
+// a benchmark run next to the client's results. gRPC calls are logged with
+// method POST, their full method as path, their status code, and the
+// serialized size of the messages they sent.
+package middleware
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"log/slog"
+	"math/rand/v2"
+	"net/http"
+	"os"
+	"runtime/debug"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/HdrHistogram/hdrhistogram-go"
+	"github.com/kaldun-tech/grpc-rest-benchmark/pkg/internal/grpcmethod"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// Config holds the middleware flags.
+type Config struct {
+	LogSample float64 // fraction of requests logged, 0 for none
+	AccessLog string  // JSON lines file of the logged requests, "" for the server log
+	Recovery  bool    // recover from panics in handlers
+	Metrics   bool    // count requests and record their latency per method
+}
+
+// RegisterFlags registers the middleware flags on fs.
+func (c *Config) RegisterFlags(fs *flag.FlagSet) {
+	fs.Float64Var(&c.LogSample, "log-requests", 0, "Fraction of benchmark requests logged with their method, path, status, duration and response bytes, 0 to 1 (0 = none)")
+	fs.StringVar(&c.AccessLog, "access-log", "", "Append the requests --log-requests logs to this file as JSON lines instead of the server log")
+	fs.BoolVar(&c.Recovery, "recover-panics", false, "Recover from panics in benchmark handlers, failing the request instead of the server")
+	fs.BoolVar(&c.Metrics, "request-metrics", false, "Count benchmark requests and record their latency per method, served on /metrics")
+}
+
+// Validate checks the middleware flags for invalid values.
+func (c Config) Validate() error {
+	if c.LogSample < 0 || c.LogSample > 1 {
+		return fmt.Errorf("log-requests must be between 0 and 1")
+	}
+	if c.AccessLog != "" && c.LogSample == 0 {
+		return fmt.Errorf("access-log requires --log-requests")
+	}
+	return nil
+}
+
+// Enabled reports whether any middleware runs.
+func (c Config) Enabled() bool {
+	return c != Config{}
+}
+
+// String describes the middleware, as recorded with each run, e.g.
+// "log=1%,recovery,metrics". It is empty when none runs. Where requests
+// are logged is not recorded.
+func (c Config) String() string {
+	var parts []string
+	if c.LogSample > 0 {
+		parts = append(parts, fmt.Sprintf("log=%g%%", c.LogSample*100))
+	}
+	if c.Recovery {
+		parts = append(parts, "recovery")
+	}
+	if c.Metrics {
+		parts = append(parts, "metrics")
+	}
+	return strings.Join(parts, ",")
+}
+
+// errPanic is the error of requests whose handler panicked.
+var errPanic = errors.New("internal error")
+
+// Chain runs requests through the configured middleware: recovery
+// outermost, so it also covers the others, then metrics and logging. A nil
+// *Chain passes requests through.
+type Chain struct {
+	cfg     Config
+	logger  *log.Logger
+	access  *slog.Logger // the access log of the sampled requests
+	file    *os.File     // the access log file, if any
+	metrics *Metrics
+}
+
+// New creates a chain for cfg logging to logger, or returns nil if cfg
+// enables no middleware. It opens the access log file, if any; Close closes
+// it.
+func New(cfg Config, logger *log.Logger) (*Chain, error) {
+	if !cfg.Enabled() {
+		return nil, nil
+	}
+	c := &Chain{cfg: cfg, logger: logger}
+	if cfg.AccessLog != "" {
+		f, err := os.OpenFile(cfg.AccessLog, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o644)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open access log: %w", err)
+		}
+		c.file = f
+		c.access = slog.New(slog.NewJSONHandler(f, nil))
+	} else if cfg.LogSample > 0 {
+		c.access = slog.New(slog.NewTextHandler(logger.Writer(), nil))
+	}
+	if cfg.Metrics {
+		c.metrics = NewMetrics()
+	}
+	return c, nil
+}
+
+// Close closes the access log file.
+func (c *Chain) Close() error {
+	if c == nil || c.file == nil {
+		return nil
+	}
+	return c.file.Close()
+}
+
+// Metrics returns the chain's request metrics, nil unless enabled.
+func (c *Chain) Metrics() *Metrics {
+	if c == nil {
+		return nil
+	}
+	return c.metrics
+}
+
+// sampled reports whether a request is to be logged.
+func (c *Chain) sampled() bool {
+	return c.cfg.LogSample > 0 && (c.cfg.LogSample == 1 || rand.Float64() < c.cfg.LogSample)
+}
+
+// request is a request observed by the chain.
+type request struct {
+	key    string // the metrics method: the route pattern, or the full gRPC method
+	method string
+	path   string
+	start  time.Time
+	logged bool
+	bytes  int64 // response bytes, counted for logged requests
+}
+
+// observe records a finished request with the metrics and, if sampled, in
+// the access log.
+func (c *Chain) observe(req *request, code string, failed bool) {
+	d := time.Since(req.start)
+	if c.metrics != nil {
+		c.metrics.Observe(req.key, failed, d)
+	}
+	if req.logged {
+		c.access.LogAttrs(context.Background(), slog.LevelInfo, "request",
+			slog.String("method", req.method),
+			slog.String("path", req.path),
+			slog.String("status", code),
+			slog.Float64("duration_ms", float64(d)/float64(time.Millisecond)),
+			slog.Int64("bytes", req.bytes),
+		)
+	}
+}
+
+// recovered logs a panic recovered from the handler of method.
+func (c *Chain) recovered(method string, v any) {
+	c.logger.Printf("panic in %s: %v\n%s", method, v, debug.Stack())
+}
+
+// Handler returns next with the middleware in front of each request, keyed
+// by the route pattern it was registered with. A request whose handler
+// panicked gets 500 Internal Server Error with a JSON error body if no
+// response was written yet.
+func (c *Chain) Handler(next http.Handler) http.Handler {
+	if c == nil {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		req := &request{key: r.Pattern, method: r.Method, path: r.URL.Path, start: time.Now(), logged: c.sampled()}
+		if req.key == "" {
+			req.key = r.Method + " " + r.URL.Path
+		}
+		sw := &statusWriter{ResponseWriter: w}
+		if c.cfg.Recovery {
+			defer func() {
+				v := recover()
+				if v == nil {
+					return
+				}
+				if v == http.ErrAbortHandler {
+					panic(v)
+				}
+				c.recovered(req.key, v)
+				if !sw.wroteHeader {
+					sw.Header().Set("Content-Type", "application/json")
+					sw.WriteHeader(http.StatusInternalServerError)
+					fmt.Fprintf(sw, "{\"error\":%q}\n", errPanic.Error())
+				}
+				req.bytes = sw.bytes
+				c.observe(req, "500", true)
+			}()
+		}
+		next.ServeHTTP(sw, r)
+		code := sw.status
+		if code == 0 {
+			code = http.StatusOK
+		}
+		req.bytes = sw.bytes
+		c.observe(req, fmt.Sprint(code), code >= 400)
+	})
+}
+
+// statusWriter records the status and body size of the response.
+type statusWriter struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+	bytes       int64
+}
+
+func (w *statusWriter) WriteHeader(code int) {
+	if !w.wroteHeader {
+		w.wroteHeader = true
+		w.status = code
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *statusWriter) Write(b []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	n, err := w.ResponseWriter.Write(b)
+	w.bytes += int64(n)
+	return n, err
+}
+
+// Flush implements http.Flusher for streamed responses.
+func (w *statusWriter) Flush() {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer.
+func (w *statusWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// UnaryServerInterceptor runs unary calls to the given services (full
+// names, e.g. "benchmark.BalanceService") through the middleware; calls to
+// other services, such as health checks, pass through. A call whose handler
+// panicked returns Internal.
+func (c *Chain) UnaryServerInterceptor(services ...string) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp any, err error) {
+		if c == nil || !grpcmethod.InServices(info.FullMethod, services) {
+			return handler(ctx, req)
+		}
+		call := c.startRPC(info.FullMethod)
+		defer c.rpcDone(call, &err)
+		resp, err = handler(ctx, req)
+		if call.logged {
+			call.bytes = messageSize(resp)
+		}
+		return resp, err
+	}
+}
+
+// StreamServerInterceptor runs streaming calls to the given services
+// through the middleware, like UnaryServerInterceptor. A stream is observed
+// once it ends.
+func (c *Chain) StreamServerInterceptor(services ...string) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+		if c == nil || !grpcmethod.InServices(info.FullMethod, services) {
+			return handler(srv, ss)
+		}
+		call := c.startRPC(info.FullMethod)
+		defer c.rpcDone(call, &err)
+		if call.logged {
+			ss = &countingStream{ServerStream: ss, bytes: &call.bytes}
+		}
+		return handler(srv, ss)
+	}
+}
+
+// startRPC returns the request of a call to method starting now.
+func (c *Chain) startRPC(method string) *request {
+	return &request{key: method, method: http.MethodPost, path: method, start: time.Now(), logged: c.sampled()}
+}
+
+// rpcDone observes a call that ended with *err, recovering a panic of its
+// handler into *err if enabled. It must be deferred.
+func (c *Chain) rpcDone(call *request, err *error) {
+	if c.cfg.Recovery {
+		if v := recover(); v != nil {
+			c.recovered(call.key, v)
+			*err = status.Error(codes.Internal, errPanic.Error())
+		}
+	}
+	code := status.Code(*err)
+	c.observe(call, code.String(), code != codes.OK)
+}
+
+// countingStream counts the serialized size of the messages a stream sends.
+type countingStream struct {
+	grpc.ServerStream
+	bytes *int64
+}
+
+func (s *countingStream) SendMsg(m any) error {
+	if err := s.ServerStream.SendMsg(m); err != nil {
+		return err
+	}
+	*s.bytes += messageSize(m)
+	return nil
+}
+
+// messageSize returns the serialized size of a protobuf message, 0 for
+// other values.
+func messageSize(m any) int64 {
+	if msg, ok := m.(proto.Message); ok {
+		return int64(proto.Size(msg))
+	}
+	return 0
+}
+
+// Latency range and precision of the request metrics, in microseconds
+const (
+	metricsMinMicros = 1
+	metricsMaxMicros = int64(10 * time.Minute / time.Microsecond)
+	metricsSigFigs   = 2
+)
+
+// Metrics counts requests and records their latency per method, as a
+// Prometheus client library would. It is safe for concurrent use.
+type Metrics struct {
+	mu      sync.Mutex
+	methods map[string]*methodMetrics
+}
+
+type methodMetrics struct {
+	requests, errors int64
+	latency          *hdrhistogram.Histogram
+}
+
+// NewMetrics creates empty request metrics.
+func NewMetrics() *Metrics {
+	return &Metrics{methods: make(map[string]*methodMetrics)}
+}
+
+// Observe records a request to method that took d.
+func (m *Metrics) Observe(method string, failed bool, d time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	mm := m.methods[method]
+	if mm == nil {
+		mm = &methodMetrics{latency: hdrhistogram.New(metricsMinMicros, metricsMaxMicros, metricsSigFigs)}
+		m.methods[method] = mm
+	}
+	mm.requests++
+	if failed {
+		mm.errors++
+	}
+	mm.latency.RecordValue(max(d.Microseconds(), metricsMinMicros))
+}
+
+// WriteTo writes the metrics in the Prometheus text format: request and
+// error counts and latency quantiles per method, in method order.
+func (m *Metrics) WriteTo(w io.Writer) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	methods := make([]string, 0, len(m.methods))
+	for method := range m.methods {
+		methods = append(methods, method)
+	}
+	sort.Strings(methods)
+
+	var b strings.Builder
+	b.WriteString("# TYPE requests_total counter\n")
+	for _, method := range methods {
+		fmt.Fprintf(&b, "requests_total{method=%q} %d\n", method, m.methods[method].requests)
+	}
+	b.WriteString("# TYPE request_errors_total counter\n")
+	for _, method := range methods {
+		fmt.Fprintf(&b, "request_errors_total{method=%q} %d\n", method, m.methods[method].errors)
+	}
+	b.WriteString("# TYPE request_duration_seconds summary\n")
+	for _, method := range methods {
+		h := m.methods[method].latency
+		for _, q := range []float64{0.5, 0.9, 0.99} {
+			seconds := float64(h.ValueAtQuantile(q*100)) / 1e6
+			fmt.Fprintf(&b, "request_duration_seconds{method=%q,quantile=\"%g\"} %g\n", method, q, seconds)
+		}
+		fmt.Fprintf(&b, "request_duration_seconds_count{method=%q} %d\n", method, h.TotalCount())
+	}
+	n, err := io.WriteString(w, b.String())
+	return int64(n), err
+}
+
+// Handler serves the metrics in the Prometheus text format.
+func (m *Metrics) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+		m.WriteTo(w)
+	})
+}
@@ -0,0 +1,202 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/kaldun-tech/grpc-rest-benchmark/pkg/protos"
+)
+
+func TestConfig(t *testing.T) {
+	var none Config
+	if chain, _ := New(none, nil); none.Enabled() || none.String() != "" || chain != nil {
+		t.Errorf("zero Config is enabled or described as %q", none.String())
+	}
+
+	c := Config{LogSample: 0.01, Recovery: true, Metrics: true}
+	if err := c.Validate(); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+	if got, want := c.String(), "log=1%,recovery,metrics"; got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+
+	for _, bad := range []Config{{LogSample: -0.1}, {LogSample: 1.5}, {AccessLog: "access.jsonl"}} {
+		if err := bad.Validate(); err == nil {
+			t.Errorf("Validate(%+v) = nil, want an error", bad)
+		}
+	}
+}
+
+func TestChain_Handler(t *testing.T) {
+	var logs bytes.Buffer
+	chain, err := New(Config{LogSample: 1, Recovery: true, Metrics: true}, log.New(&logs, "", 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/ok/", chain.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.(http.Flusher).Flush()
+		io.WriteString(w, "ok")
+	})))
+	mux.Handle("/missing", chain.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.NotFound(w, r)
+	})))
+	mux.Handle("/panic", chain.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic("boom")
+	})))
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	for _, path := range []string{"/ok/1", "/ok/2", "/missing", "/panic"} {
+		resp, err := http.Get(srv.URL + path)
+		if err != nil {
+			t.Fatalf("GET %s error = %v", path, err)
+		}
+		resp.Body.Close()
+		if path == "/panic" && resp.StatusCode != http.StatusInternalServerError {
+			t.Errorf("GET /panic status = %d, want 500", resp.StatusCode)
+		}
+	}
+
+	var metrics strings.Builder
+	chain.Metrics().WriteTo(&metrics)
+	for _, want := range []string{
+		`requests_total{method="/ok/"} 2`,
+		`request_errors_total{method="/missing"} 1`,
+		`request_errors_total{method="/panic"} 1`,
+		`request_duration_seconds_count{method="/ok/"} 2`,
+	} {
+		if !strings.Contains(metrics.String(), want) {
+			t.Errorf("metrics missing %s:\n%s", want, metrics.String())
+		}
+	}
+	if got := strings.Count(logs.String(), "request method="); got != 4 {
+		t.Errorf("logged %d requests, want 4:\n%s", got, logs.String())
+	}
+	if !strings.Contains(logs.String(), "method=GET path=/ok/1 status=200") || !strings.Contains(logs.String(), "bytes=2") {
+		t.Errorf("access log entries lack the request's fields:\n%s", logs.String())
+	}
+	if !strings.Contains(logs.String(), "panic in /panic: boom") {
+		t.Errorf("panic not logged:\n%s", logs.String())
+	}
+}
+
+func TestChain_UnaryServerInterceptor(t *testing.T) {
+	chain, err := New(Config{Recovery: true, Metrics: true}, log.New(io.Discard, "", 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	interceptor := chain.UnaryServerInterceptor("benchmark.BalanceService")
+
+	panicking := func(ctx context.Context, req any) (any, error) { panic("boom") }
+	info := &grpc.UnaryServerInfo{FullMethod: "/benchmark.BalanceService/GetBalance"}
+	if _, err := interceptor(context.Background(), nil, info, panicking); status.Code(err) != codes.Internal {
+		t.Errorf("panicking call error = %v, want Internal", err)
+	}
+
+	ok := func(ctx context.Context, req any) (any, error) { return "ok", nil }
+	if resp, err := interceptor(context.Background(), nil, info, ok); err != nil || resp != "ok" {
+		t.Errorf("call = %v, %v; want ok", resp, err)
+	}
+
+	// Other services pass through unobserved
+	health := &grpc.UnaryServerInfo{FullMethod: "/grpc.health.v1.Health/Check"}
+	interceptor(context.Background(), nil, health, ok)
+
+	var metrics strings.Builder
+	chain.Metrics().WriteTo(&metrics)
+	if !strings.Contains(metrics.String(), `requests_total{method="/benchmark.BalanceService/GetBalance"} 2`) ||
+		!strings.Contains(metrics.String(), `request_errors_total{method="/benchmark.BalanceService/GetBalance"} 1`) {
+		t.Errorf("metrics = %s, want 2 calls with 1 error", metrics.String())
+	}
+	if strings.Contains(metrics.String(), "Health") {
+		t.Errorf("metrics include the health check:\n%s", metrics.String())
+	}
+}
+
+func TestChain_AccessLog(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "access.jsonl")
+	chain, err := New(Config{LogSample: 1, AccessLog: path}, log.New(io.Discard, "", 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A streaming call is logged with the size of the messages it sent
+	interceptor := chain.StreamServerInterceptor("benchmark.TransactionService")
+	info := &grpc.StreamServerInfo{FullMethod: "/benchmark.TransactionService/StreamTransactions"}
+	handler := func(srv any, ss grpc.ServerStream) error {
+		ss.SendMsg(&protos.Transaction{TxId: "tx1"})
+		ss.SendMsg(&protos.Transaction{TxId: "tx2"})
+		return status.Error(codes.Unavailable, "gone")
+	}
+	interceptor(nil, discardStream{}, info, handler)
+	if err := chain.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var entry struct {
+		Msg, Method, Path, Status string
+		DurationMs                float64 `json:"duration_ms"`
+		Bytes                     int64
+	}
+	if err := json.Unmarshal(data, &entry); err != nil {
+		t.Fatalf("access log %q: %v", data, err)
+	}
+	want := int64(2 * proto.Size(&protos.Transaction{TxId: "tx1"}))
+	if entry.Msg != "request" || entry.Method != "POST" || entry.Path != info.FullMethod || entry.Status != "Unavailable" || entry.Bytes != want {
+		t.Errorf("access log entry = %+v, want a POST to %s with status Unavailable and %d bytes", entry, info.FullMethod, want)
+	}
+}
+
+// discardStream is a server stream that discards the messages sent on it.
+type discardStream struct {
+	grpc.ServerStream
+}
+
+func (discardStream) SendMsg(m any) error { return nil }
+
+func TestChain_Nil(t *testing.T) {
+	var chain *Chain
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+	if chain.Handler(next) == nil || chain.Metrics() != nil {
+		t.Error("nil chain does not pass requests through")
+	}
+	ok := func(ctx context.Context, req any) (any, error) { return "ok", nil }
+	info := &grpc.UnaryServerInfo{FullMethod: "/benchmark.BalanceService/GetBalance"}
+	if resp, err := chain.UnaryServerInterceptor("benchmark.BalanceService")(context.Background(), nil, info, ok); resp != "ok" || err != nil {
+		t.Errorf("nil chain call = %v, %v", resp, err)
+	}
+}
+
+func TestMetrics_Quantiles(t *testing.T) {
+	m := NewMetrics()
+	for i := 1; i <= 100; i++ {
+		m.Observe("GET /x", false, time.Duration(i)*time.Millisecond)
+	}
+	var b strings.Builder
+	m.WriteTo(&b)
+	if !strings.Contains(b.String(), `request_duration_seconds{method="GET /x",quantile="0.5"} 0.05`) {
+		t.Errorf("p50 missing or wrong:\n%s", b.String())
+	}
+}
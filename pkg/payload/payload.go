@@ -0,0 +1,75 @@
+// Package payload provides the fixed-size response bodies returned by the
+// echo endpoints, used to measure how serialization cost scales with message
+// size.
+package payload
+
+import (
+	"fmt"
+	"math/rand"
+	"strconv"
+	"strings"
+)
+
+// MaxSize is the largest payload the servers will return. It stays well
+// below gRPC's default 4 MiB message limit.
+const MaxSize = 2 << 20
+
+// data is shared by every response. Payloads are only ever read, so callers
+// may pass slices of it to encoders concurrently. The bytes are random so
+// that compression, where enabled, cannot shrink them unrealistically.
+var data = func() []byte {
+	b := make([]byte, MaxSize)
+	rand.New(rand.NewSource(1)).Read(b)
+	return b
+}()
+
+// Bytes returns a payload of n bytes. The returned slice must not be modified.
+func Bytes(n int) ([]byte, error) {
+	if n < 0 || n > MaxSize {
+		return nil, fmt.Errorf("payload size %d out of range (0-%d bytes)", n, MaxSize)
+	}
+	return data[:n:n], nil
+}
+
+// ParseSize parses a byte size such as "100", "100B", "4KB" or "1MB".
+// Multipliers are binary (1KB = 1024 bytes).
+func ParseSize(s string) (int, error) {
+	str := strings.ToUpper(strings.TrimSpace(s))
+	mult := 1
+	for _, unit := range []struct {
+		suffix string
+		mult   int
+	}{
+		{"MB", 1 << 20},
+		{"KB", 1 << 10},
+		{"B", 1},
+	} {
+		if strings.HasSuffix(str, unit.suffix) {
+			str = strings.TrimSpace(strings.TrimSuffix(str, unit.suffix))
+			mult = unit.mult
+			break
+		}
+	}
+
+	n, err := strconv.Atoi(str)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid payload size: %q", s)
+	}
+	if n*mult > MaxSize {
+		return 0, fmt.Errorf("payload size %s exceeds maximum of %d bytes", s, MaxSize)
+	}
+	return n * mult, nil
+}
+
+// FormatSize formats a byte count using the largest exact unit, the inverse
+// of ParseSize.
+func FormatSize(n int) string {
+	switch {
+	case n >= 1<<20 && n%(1<<20) == 0:
+		return fmt.Sprintf("%dMB", n>>20)
+	case n >= 1<<10 && n%(1<<10) == 0:
+		return fmt.Sprintf("%dKB", n>>10)
+	default:
+		return fmt.Sprintf("%dB", n)
+	}
+}
@@ -0,0 +1,57 @@
+package payload
+
+import "testing"
+
+func TestParseSize(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    int
+		wantErr bool
+	}{
+		{"100", 100, false},
+		{"100B", 100, false},
+		{"4KB", 4096, false},
+		{"4kb", 4096, false},
+		{"1MB", 1 << 20, false},
+		{"0", 0, false},
+		{"3MB", 0, true},
+		{"-1", 0, true},
+		{"1.5KB", 0, true},
+		{"lots", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := ParseSize(tt.in)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseSize(%q) error = %v, wantErr %v", tt.in, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseSize(%q) = %d, want %d", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatSize_RoundTrip(t *testing.T) {
+	for _, n := range []int{0, 100, 1000, 1024, 65536, 1 << 20} {
+		s := FormatSize(n)
+		got, err := ParseSize(s)
+		if err != nil || got != n {
+			t.Errorf("ParseSize(FormatSize(%d) = %q) = %d, %v", n, s, got, err)
+		}
+	}
+}
+
+func TestBytes(t *testing.T) {
+	b, err := Bytes(1000)
+	if err != nil {
+		t.Fatalf("Bytes() error = %v", err)
+	}
+	if len(b) != 1000 || cap(b) != 1000 {
+		t.Errorf("Bytes(1000) len=%d cap=%d, want 1000", len(b), cap(b))
+	}
+	if _, err := Bytes(MaxSize + 1); err == nil {
+		t.Error("Bytes() expected error above MaxSize")
+	}
+}
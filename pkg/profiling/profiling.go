@@ -0,0 +1,89 @@
+// Package profiling captures pprof profiles of the running process on
+// demand: the servers serve them from their admin endpoints, and the
+// benchmark captures them from the servers and itself during a run
+// (run --profile-cpu, --profile-mem), so a slow protocol can be explained
+// with profiles tied to the run that measured it.
+package profiling
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/pprof"
+	"runtime"
+	rpprof "runtime/pprof"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Profile kinds.
+const (
+	CPU  = "cpu"  // CPU samples over the capture duration
+	Heap = "heap" // live and allocated memory since the process started
+)
+
+// Kinds are the profile kinds Capture accepts.
+var Kinds = []string{CPU, Heap}
+
+// MaxDuration bounds how long a CPU profile may be captured.
+const MaxDuration = time.Hour
+
+// ErrBusy is returned when a CPU profile is requested while another one is
+// being captured; the runtime profiles one at a time.
+var ErrBusy = errors.New("a CPU profile is already being captured")
+
+// Validate checks the kind and, for CPU profiles, the duration.
+func Validate(kind string, duration time.Duration) error {
+	if !slices.Contains(Kinds, kind) {
+		return fmt.Errorf("unknown profile kind %q (must be one of %s)", kind, strings.Join(Kinds, ", "))
+	}
+	if kind == CPU && (duration <= 0 || duration > MaxDuration) {
+		return fmt.Errorf("CPU profile duration must be between 0 and %s, got %s", MaxDuration, duration)
+	}
+	return nil
+}
+
+// Capture writes a profile of the process to w in the gzipped protobuf
+// format of go tool pprof. A CPU profile covers duration, or less if ctx
+// is done first; a heap profile is taken at once, after a garbage
+// collection so it reflects the live heap.
+func Capture(ctx context.Context, w io.Writer, kind string, duration time.Duration) error {
+	if err := Validate(kind, duration); err != nil {
+		return err
+	}
+	if kind == Heap {
+		runtime.GC()
+		return rpprof.Lookup("heap").WriteTo(w, 0)
+	}
+
+	if err := rpprof.StartCPUProfile(w); err != nil {
+		return ErrBusy
+	}
+	timer := time.NewTimer(duration)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+	case <-ctx.Done():
+	}
+	rpprof.StopCPUProfile()
+	return nil
+}
+
+// Name names a profile by its source ("client" or "server") and kind, e.g.
+// "server-cpu", as profile files and run attachments are named.
+func Name(source, kind string) string {
+	return source + "-" + kind
+}
+
+// RegisterDebug serves the standard net/http/pprof endpoints under
+// /debug/pprof/ on mux, for interactive use with go tool pprof.
+func RegisterDebug(mux *http.ServeMux) {
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+}
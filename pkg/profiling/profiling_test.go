@@ -0,0 +1,73 @@
+package profiling
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"io"
+	rpprof "runtime/pprof"
+	"testing"
+	"time"
+)
+
+func TestValidate(t *testing.T) {
+	if err := Validate(CPU, time.Second); err != nil {
+		t.Errorf("Validate(cpu, 1s) error = %v", err)
+	}
+	if err := Validate(Heap, 0); err != nil {
+		t.Errorf("Validate(heap, 0) error = %v", err)
+	}
+	for _, c := range []struct {
+		kind     string
+		duration time.Duration
+	}{
+		{"goroutine", time.Second},
+		{CPU, 0},
+		{CPU, MaxDuration + time.Second},
+	} {
+		if err := Validate(c.kind, c.duration); err == nil {
+			t.Errorf("Validate(%s, %s) = nil, want an error", c.kind, c.duration)
+		}
+	}
+}
+
+func TestCapture(t *testing.T) {
+	for _, kind := range Kinds {
+		var buf bytes.Buffer
+		if err := Capture(context.Background(), &buf, kind, 50*time.Millisecond); err != nil {
+			t.Fatalf("Capture(%s) error = %v", kind, err)
+		}
+		// Profiles are gzipped protobuf
+		zr, err := gzip.NewReader(&buf)
+		if err != nil {
+			t.Fatalf("Capture(%s) is not gzipped: %v", kind, err)
+		}
+		if data, err := io.ReadAll(zr); err != nil || len(data) == 0 {
+			t.Errorf("Capture(%s) = %d bytes, %v, want a profile", kind, len(data), err)
+		}
+	}
+}
+
+func TestCapture_Busy(t *testing.T) {
+	if err := rpprof.StartCPUProfile(io.Discard); err != nil {
+		t.Fatalf("StartCPUProfile() error = %v", err)
+	}
+	err := Capture(context.Background(), io.Discard, CPU, time.Second)
+	rpprof.StopCPUProfile()
+	if !errors.Is(err, ErrBusy) {
+		t.Errorf("Capture() during another CPU profile error = %v, want ErrBusy", err)
+	}
+}
+
+func TestCapture_Cancel(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	if err := Capture(ctx, io.Discard, CPU, time.Minute); err != nil {
+		t.Fatalf("Capture() error = %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 10*time.Second {
+		t.Errorf("Capture() took %s after its context was done", elapsed)
+	}
+}
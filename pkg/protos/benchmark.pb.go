@@ -67,7 +67,7 @@ func (x HealthCheckResponse_ServingStatus) Number() protoreflect.EnumNumber {
 
 // Deprecated: Use HealthCheckResponse_ServingStatus.Descriptor instead.
 func (HealthCheckResponse_ServingStatus) EnumDescriptor() ([]byte, []int) {
-	return file_pkg_protos_benchmark_proto_rawDescGZIP(), []int{7, 0}
+	return file_pkg_protos_benchmark_proto_rawDescGZIP(), []int{19, 0}
 }
 
 type BalanceRequest struct {
@@ -270,6 +270,15 @@ type StreamRequest struct {
 	RateLimit int32 `protobuf:"varint,2,opt,name=rate_limit,json=rateLimit,proto3" json:"rate_limit,omitempty"`
 	// Optional filter by account (empty = all transactions)
 	FilterAccount string `protobuf:"bytes,3,opt,name=filter_account,json=filterAccount,proto3" json:"filter_account,omitempty"`
+	// Follow transactions as they are submitted instead of replaying stored
+	// ones; the stream lasts until the client cancels it. Live events carry
+	// their timestamp to the nanosecond, and are neither rate limited nor
+	// paced.
+	Live bool `protobuf:"varint,4,opt,name=live,proto3" json:"live,omitempty"`
+	// Resume a replay stream after this transaction, the last one a
+	// reconnecting client received (empty = start from since_timestamp).
+	// Ignored by live streams, which keep no history.
+	AfterTxId     string `protobuf:"bytes,5,opt,name=after_tx_id,json=afterTxId,proto3" json:"after_tx_id,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -325,6 +334,88 @@ func (x *StreamRequest) GetFilterAccount() string {
 	return ""
 }
 
+func (x *StreamRequest) GetLive() bool {
+	if x != nil {
+		return x.Live
+	}
+	return false
+}
+
+func (x *StreamRequest) GetAfterTxId() string {
+	if x != nil {
+		return x.AfterTxId
+	}
+	return ""
+}
+
+type SubmitTransactionRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	FromAccount   string                 `protobuf:"bytes,1,opt,name=from_account,json=fromAccount,proto3" json:"from_account,omitempty"`
+	ToAccount     string                 `protobuf:"bytes,2,opt,name=to_account,json=toAccount,proto3" json:"to_account,omitempty"`
+	AmountTinybar int64                  `protobuf:"varint,3,opt,name=amount_tinybar,json=amountTinybar,proto3" json:"amount_tinybar,omitempty"`
+	TxType        string                 `protobuf:"bytes,4,opt,name=tx_type,json=txType,proto3" json:"tx_type,omitempty"` // empty = 'transfer'
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SubmitTransactionRequest) Reset() {
+	*x = SubmitTransactionRequest{}
+	mi := &file_pkg_protos_benchmark_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SubmitTransactionRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SubmitTransactionRequest) ProtoMessage() {}
+
+func (x *SubmitTransactionRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_protos_benchmark_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SubmitTransactionRequest.ProtoReflect.Descriptor instead.
+func (*SubmitTransactionRequest) Descriptor() ([]byte, []int) {
+	return file_pkg_protos_benchmark_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *SubmitTransactionRequest) GetFromAccount() string {
+	if x != nil {
+		return x.FromAccount
+	}
+	return ""
+}
+
+func (x *SubmitTransactionRequest) GetToAccount() string {
+	if x != nil {
+		return x.ToAccount
+	}
+	return ""
+}
+
+func (x *SubmitTransactionRequest) GetAmountTinybar() int64 {
+	if x != nil {
+		return x.AmountTinybar
+	}
+	return 0
+}
+
+func (x *SubmitTransactionRequest) GetTxType() string {
+	if x != nil {
+		return x.TxType
+	}
+	return ""
+}
+
 type Transaction struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	TxId          string                 `protobuf:"bytes,1,opt,name=tx_id,json=txId,proto3" json:"tx_id,omitempty"`
@@ -333,13 +424,16 @@ type Transaction struct {
 	AmountTinybar int64                  `protobuf:"varint,4,opt,name=amount_tinybar,json=amountTinybar,proto3" json:"amount_tinybar,omitempty"`
 	TxType        string                 `protobuf:"bytes,5,opt,name=tx_type,json=txType,proto3" json:"tx_type,omitempty"` // 'transfer', 'vesting_release', 'contract_call'
 	Timestamp     string                 `protobuf:"bytes,6,opt,name=timestamp,proto3" json:"timestamp,omitempty"`         // ISO 8601 format
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+	// When the server sent a streamed transaction, in nanoseconds since the
+	// Unix epoch, for the client's delivery latency; 0 outside streams.
+	SentAtUnixNano int64 `protobuf:"varint,7,opt,name=sent_at_unix_nano,json=sentAtUnixNano,proto3" json:"sent_at_unix_nano,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
 }
 
 func (x *Transaction) Reset() {
 	*x = Transaction{}
-	mi := &file_pkg_protos_benchmark_proto_msgTypes[5]
+	mi := &file_pkg_protos_benchmark_proto_msgTypes[6]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -351,7 +445,7 @@ func (x *Transaction) String() string {
 func (*Transaction) ProtoMessage() {}
 
 func (x *Transaction) ProtoReflect() protoreflect.Message {
-	mi := &file_pkg_protos_benchmark_proto_msgTypes[5]
+	mi := &file_pkg_protos_benchmark_proto_msgTypes[6]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -364,7 +458,7 @@ func (x *Transaction) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Transaction.ProtoReflect.Descriptor instead.
 func (*Transaction) Descriptor() ([]byte, []int) {
-	return file_pkg_protos_benchmark_proto_rawDescGZIP(), []int{5}
+	return file_pkg_protos_benchmark_proto_rawDescGZIP(), []int{6}
 }
 
 func (x *Transaction) GetTxId() string {
@@ -409,6 +503,523 @@ func (x *Transaction) GetTimestamp() string {
 	return ""
 }
 
+func (x *Transaction) GetSentAtUnixNano() int64 {
+	if x != nil {
+		return x.SentAtUnixNano
+	}
+	return 0
+}
+
+type EchoRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	PayloadSize   int32                  `protobuf:"varint,1,opt,name=payload_size,json=payloadSize,proto3" json:"payload_size,omitempty"` // bytes of payload to return
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *EchoRequest) Reset() {
+	*x = EchoRequest{}
+	mi := &file_pkg_protos_benchmark_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *EchoRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EchoRequest) ProtoMessage() {}
+
+func (x *EchoRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_protos_benchmark_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use EchoRequest.ProtoReflect.Descriptor instead.
+func (*EchoRequest) Descriptor() ([]byte, []int) {
+	return file_pkg_protos_benchmark_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *EchoRequest) GetPayloadSize() int32 {
+	if x != nil {
+		return x.PayloadSize
+	}
+	return 0
+}
+
+type EchoResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Payload       []byte                 `protobuf:"bytes,1,opt,name=payload,proto3" json:"payload,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *EchoResponse) Reset() {
+	*x = EchoResponse{}
+	mi := &file_pkg_protos_benchmark_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *EchoResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EchoResponse) ProtoMessage() {}
+
+func (x *EchoResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_protos_benchmark_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use EchoResponse.ProtoReflect.Descriptor instead.
+func (*EchoResponse) Descriptor() ([]byte, []int) {
+	return file_pkg_protos_benchmark_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *EchoResponse) GetPayload() []byte {
+	if x != nil {
+		return x.Payload
+	}
+	return nil
+}
+
+type ServerStatsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ServerStatsRequest) Reset() {
+	*x = ServerStatsRequest{}
+	mi := &file_pkg_protos_benchmark_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ServerStatsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ServerStatsRequest) ProtoMessage() {}
+
+func (x *ServerStatsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_protos_benchmark_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ServerStatsRequest.ProtoReflect.Descriptor instead.
+func (*ServerStatsRequest) Descriptor() ([]byte, []int) {
+	return file_pkg_protos_benchmark_proto_rawDescGZIP(), []int{9}
+}
+
+type ServerStats struct {
+	state               protoimpl.MessageState `protogen:"open.v1"`
+	CpuSeconds          float64                `protobuf:"fixed64,1,opt,name=cpu_seconds,json=cpuSeconds,proto3" json:"cpu_seconds,omitempty"`                             // user + system CPU time of the server process since it started
+	CacheHits           int64                  `protobuf:"varint,2,opt,name=cache_hits,json=cacheHits,proto3" json:"cache_hits,omitempty"`                                 // GetBalance calls served from the balance cache (--cache-size)
+	CacheMisses         int64                  `protobuf:"varint,3,opt,name=cache_misses,json=cacheMisses,proto3" json:"cache_misses,omitempty"`                           // GetBalance calls the balance cache passed to the database
+	PoolAcquireWaits    int64                  `protobuf:"varint,4,opt,name=pool_acquire_waits,json=poolAcquireWaits,proto3" json:"pool_acquire_waits,omitempty"`          // database connection acquires that waited for a free connection
+	PoolAcquireFailures int64                  `protobuf:"varint,5,opt,name=pool_acquire_failures,json=poolAcquireFailures,proto3" json:"pool_acquire_failures,omitempty"` // acquires given up before a connection was free: pool exhaustion
+	unknownFields       protoimpl.UnknownFields
+	sizeCache           protoimpl.SizeCache
+}
+
+func (x *ServerStats) Reset() {
+	*x = ServerStats{}
+	mi := &file_pkg_protos_benchmark_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ServerStats) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ServerStats) ProtoMessage() {}
+
+func (x *ServerStats) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_protos_benchmark_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ServerStats.ProtoReflect.Descriptor instead.
+func (*ServerStats) Descriptor() ([]byte, []int) {
+	return file_pkg_protos_benchmark_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *ServerStats) GetCpuSeconds() float64 {
+	if x != nil {
+		return x.CpuSeconds
+	}
+	return 0
+}
+
+func (x *ServerStats) GetCacheHits() int64 {
+	if x != nil {
+		return x.CacheHits
+	}
+	return 0
+}
+
+func (x *ServerStats) GetCacheMisses() int64 {
+	if x != nil {
+		return x.CacheMisses
+	}
+	return 0
+}
+
+func (x *ServerStats) GetPoolAcquireWaits() int64 {
+	if x != nil {
+		return x.PoolAcquireWaits
+	}
+	return 0
+}
+
+func (x *ServerStats) GetPoolAcquireFailures() int64 {
+	if x != nil {
+		return x.PoolAcquireFailures
+	}
+	return 0
+}
+
+type DBTargetRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DBTargetRequest) Reset() {
+	*x = DBTargetRequest{}
+	mi := &file_pkg_protos_benchmark_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DBTargetRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DBTargetRequest) ProtoMessage() {}
+
+func (x *DBTargetRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_protos_benchmark_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DBTargetRequest.ProtoReflect.Descriptor instead.
+func (*DBTargetRequest) Descriptor() ([]byte, []int) {
+	return file_pkg_protos_benchmark_proto_rawDescGZIP(), []int{11}
+}
+
+type SetDBTargetRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"` // one of DBTarget.targets
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SetDBTargetRequest) Reset() {
+	*x = SetDBTargetRequest{}
+	mi := &file_pkg_protos_benchmark_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SetDBTargetRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetDBTargetRequest) ProtoMessage() {}
+
+func (x *SetDBTargetRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_protos_benchmark_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SetDBTargetRequest.ProtoReflect.Descriptor instead.
+func (*SetDBTargetRequest) Descriptor() ([]byte, []int) {
+	return file_pkg_protos_benchmark_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *SetDBTargetRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+type DBTarget struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`       // target the benchmark services use now
+	Targets       []string               `protobuf:"bytes,2,rep,name=targets,proto3" json:"targets,omitempty"` // targets the server was started with, "default" first
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DBTarget) Reset() {
+	*x = DBTarget{}
+	mi := &file_pkg_protos_benchmark_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DBTarget) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DBTarget) ProtoMessage() {}
+
+func (x *DBTarget) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_protos_benchmark_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DBTarget.ProtoReflect.Descriptor instead.
+func (*DBTarget) Descriptor() ([]byte, []int) {
+	return file_pkg_protos_benchmark_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *DBTarget) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *DBTarget) GetTargets() []string {
+	if x != nil {
+		return x.Targets
+	}
+	return nil
+}
+
+type RestartRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	DowntimeMs    int64                  `protobuf:"varint,1,opt,name=downtime_ms,json=downtimeMs,proto3" json:"downtime_ms,omitempty"` // how long the server stays down once drained
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RestartRequest) Reset() {
+	*x = RestartRequest{}
+	mi := &file_pkg_protos_benchmark_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RestartRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RestartRequest) ProtoMessage() {}
+
+func (x *RestartRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_protos_benchmark_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RestartRequest.ProtoReflect.Descriptor instead.
+func (*RestartRequest) Descriptor() ([]byte, []int) {
+	return file_pkg_protos_benchmark_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *RestartRequest) GetDowntimeMs() int64 {
+	if x != nil {
+		return x.DowntimeMs
+	}
+	return 0
+}
+
+// RestartResponse is sent before the restart begins.
+type RestartResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RestartResponse) Reset() {
+	*x = RestartResponse{}
+	mi := &file_pkg_protos_benchmark_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RestartResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RestartResponse) ProtoMessage() {}
+
+func (x *RestartResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_protos_benchmark_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RestartResponse.ProtoReflect.Descriptor instead.
+func (*RestartResponse) Descriptor() ([]byte, []int) {
+	return file_pkg_protos_benchmark_proto_rawDescGZIP(), []int{15}
+}
+
+type ProfileRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Kind          string                 `protobuf:"bytes,1,opt,name=kind,proto3" json:"kind,omitempty"`                                // "cpu" or "heap"
+	DurationMs    int64                  `protobuf:"varint,2,opt,name=duration_ms,json=durationMs,proto3" json:"duration_ms,omitempty"` // how long a CPU profile samples
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ProfileRequest) Reset() {
+	*x = ProfileRequest{}
+	mi := &file_pkg_protos_benchmark_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ProfileRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ProfileRequest) ProtoMessage() {}
+
+func (x *ProfileRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_protos_benchmark_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ProfileRequest.ProtoReflect.Descriptor instead.
+func (*ProfileRequest) Descriptor() ([]byte, []int) {
+	return file_pkg_protos_benchmark_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *ProfileRequest) GetKind() string {
+	if x != nil {
+		return x.Kind
+	}
+	return ""
+}
+
+func (x *ProfileRequest) GetDurationMs() int64 {
+	if x != nil {
+		return x.DurationMs
+	}
+	return 0
+}
+
+// Profile is a pprof profile in the gzipped protobuf format of go tool pprof.
+type Profile struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Data          []byte                 `protobuf:"bytes,1,opt,name=data,proto3" json:"data,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Profile) Reset() {
+	*x = Profile{}
+	mi := &file_pkg_protos_benchmark_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Profile) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Profile) ProtoMessage() {}
+
+func (x *Profile) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_protos_benchmark_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Profile.ProtoReflect.Descriptor instead.
+func (*Profile) Descriptor() ([]byte, []int) {
+	return file_pkg_protos_benchmark_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *Profile) GetData() []byte {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
 type HealthCheckRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Service       string                 `protobuf:"bytes,1,opt,name=service,proto3" json:"service,omitempty"`
@@ -418,7 +1029,7 @@ type HealthCheckRequest struct {
 
 func (x *HealthCheckRequest) Reset() {
 	*x = HealthCheckRequest{}
-	mi := &file_pkg_protos_benchmark_proto_msgTypes[6]
+	mi := &file_pkg_protos_benchmark_proto_msgTypes[18]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -430,7 +1041,7 @@ func (x *HealthCheckRequest) String() string {
 func (*HealthCheckRequest) ProtoMessage() {}
 
 func (x *HealthCheckRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_pkg_protos_benchmark_proto_msgTypes[6]
+	mi := &file_pkg_protos_benchmark_proto_msgTypes[18]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -443,7 +1054,7 @@ func (x *HealthCheckRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use HealthCheckRequest.ProtoReflect.Descriptor instead.
 func (*HealthCheckRequest) Descriptor() ([]byte, []int) {
-	return file_pkg_protos_benchmark_proto_rawDescGZIP(), []int{6}
+	return file_pkg_protos_benchmark_proto_rawDescGZIP(), []int{18}
 }
 
 func (x *HealthCheckRequest) GetService() string {
@@ -462,7 +1073,7 @@ type HealthCheckResponse struct {
 
 func (x *HealthCheckResponse) Reset() {
 	*x = HealthCheckResponse{}
-	mi := &file_pkg_protos_benchmark_proto_msgTypes[7]
+	mi := &file_pkg_protos_benchmark_proto_msgTypes[19]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -474,7 +1085,7 @@ func (x *HealthCheckResponse) String() string {
 func (*HealthCheckResponse) ProtoMessage() {}
 
 func (x *HealthCheckResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_pkg_protos_benchmark_proto_msgTypes[7]
+	mi := &file_pkg_protos_benchmark_proto_msgTypes[19]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -487,7 +1098,7 @@ func (x *HealthCheckResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use HealthCheckResponse.ProtoReflect.Descriptor instead.
 func (*HealthCheckResponse) Descriptor() ([]byte, []int) {
-	return file_pkg_protos_benchmark_proto_rawDescGZIP(), []int{7}
+	return file_pkg_protos_benchmark_proto_rawDescGZIP(), []int{19}
 }
 
 func (x *HealthCheckResponse) GetStatus() HealthCheckResponse_ServingStatus {
@@ -514,12 +1125,20 @@ const file_pkg_protos_benchmark_proto_rawDesc = "" +
 	"\vaccount_ids\x18\x01 \x03(\tR\n" +
 	"accountIds\"N\n" +
 	"\x14BatchBalanceResponse\x126\n" +
-	"\bbalances\x18\x01 \x03(\v2\x1a.benchmark.BalanceResponseR\bbalances\"~\n" +
+	"\bbalances\x18\x01 \x03(\v2\x1a.benchmark.BalanceResponseR\bbalances\"\xb2\x01\n" +
 	"\rStreamRequest\x12'\n" +
 	"\x0fsince_timestamp\x18\x01 \x01(\tR\x0esinceTimestamp\x12\x1d\n" +
 	"\n" +
 	"rate_limit\x18\x02 \x01(\x05R\trateLimit\x12%\n" +
-	"\x0efilter_account\x18\x03 \x01(\tR\rfilterAccount\"\xc2\x01\n" +
+	"\x0efilter_account\x18\x03 \x01(\tR\rfilterAccount\x12\x12\n" +
+	"\x04live\x18\x04 \x01(\bR\x04live\x12\x1e\n" +
+	"\vafter_tx_id\x18\x05 \x01(\tR\tafterTxId\"\x9c\x01\n" +
+	"\x18SubmitTransactionRequest\x12!\n" +
+	"\ffrom_account\x18\x01 \x01(\tR\vfromAccount\x12\x1d\n" +
+	"\n" +
+	"to_account\x18\x02 \x01(\tR\ttoAccount\x12%\n" +
+	"\x0eamount_tinybar\x18\x03 \x01(\x03R\ramountTinybar\x12\x17\n" +
+	"\atx_type\x18\x04 \x01(\tR\x06txType\"\xed\x01\n" +
 	"\vTransaction\x12\x13\n" +
 	"\x05tx_id\x18\x01 \x01(\tR\x04txId\x12!\n" +
 	"\ffrom_account\x18\x02 \x01(\tR\vfromAccount\x12\x1d\n" +
@@ -527,7 +1146,37 @@ const file_pkg_protos_benchmark_proto_rawDesc = "" +
 	"to_account\x18\x03 \x01(\tR\ttoAccount\x12%\n" +
 	"\x0eamount_tinybar\x18\x04 \x01(\x03R\ramountTinybar\x12\x17\n" +
 	"\atx_type\x18\x05 \x01(\tR\x06txType\x12\x1c\n" +
-	"\ttimestamp\x18\x06 \x01(\tR\ttimestamp\".\n" +
+	"\ttimestamp\x18\x06 \x01(\tR\ttimestamp\x12)\n" +
+	"\x11sent_at_unix_nano\x18\a \x01(\x03R\x0esentAtUnixNano\"0\n" +
+	"\vEchoRequest\x12!\n" +
+	"\fpayload_size\x18\x01 \x01(\x05R\vpayloadSize\"(\n" +
+	"\fEchoResponse\x12\x18\n" +
+	"\apayload\x18\x01 \x01(\fR\apayload\"\x14\n" +
+	"\x12ServerStatsRequest\"\xd2\x01\n" +
+	"\vServerStats\x12\x1f\n" +
+	"\vcpu_seconds\x18\x01 \x01(\x01R\n" +
+	"cpuSeconds\x12\x1d\n" +
+	"\n" +
+	"cache_hits\x18\x02 \x01(\x03R\tcacheHits\x12!\n" +
+	"\fcache_misses\x18\x03 \x01(\x03R\vcacheMisses\x12,\n" +
+	"\x12pool_acquire_waits\x18\x04 \x01(\x03R\x10poolAcquireWaits\x122\n" +
+	"\x15pool_acquire_failures\x18\x05 \x01(\x03R\x13poolAcquireFailures\"\x11\n" +
+	"\x0fDBTargetRequest\"(\n" +
+	"\x12SetDBTargetRequest\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\"8\n" +
+	"\bDBTarget\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x18\n" +
+	"\atargets\x18\x02 \x03(\tR\atargets\"1\n" +
+	"\x0eRestartRequest\x12\x1f\n" +
+	"\vdowntime_ms\x18\x01 \x01(\x03R\n" +
+	"downtimeMs\"\x11\n" +
+	"\x0fRestartResponse\"E\n" +
+	"\x0eProfileRequest\x12\x12\n" +
+	"\x04kind\x18\x01 \x01(\tR\x04kind\x12\x1f\n" +
+	"\vduration_ms\x18\x02 \x01(\x03R\n" +
+	"durationMs\"\x1d\n" +
+	"\aProfile\x12\x12\n" +
+	"\x04data\x18\x01 \x01(\fR\x04data\".\n" +
 	"\x12HealthCheckRequest\x12\x18\n" +
 	"\aservice\x18\x01 \x01(\tR\aservice\"\x97\x01\n" +
 	"\x13HealthCheckResponse\x12D\n" +
@@ -539,9 +1188,19 @@ const file_pkg_protos_benchmark_proto_rawDesc = "" +
 	"\x0eBalanceService\x12C\n" +
 	"\n" +
 	"GetBalance\x12\x19.benchmark.BalanceRequest\x1a\x1a.benchmark.BalanceResponse\x12N\n" +
-	"\vGetBalances\x12\x1e.benchmark.BatchBalanceRequest\x1a\x1f.benchmark.BatchBalanceResponse2^\n" +
+	"\vGetBalances\x12\x1e.benchmark.BatchBalanceRequest\x1a\x1f.benchmark.BatchBalanceResponse2\xb0\x01\n" +
 	"\x12TransactionService\x12H\n" +
-	"\x12StreamTransactions\x12\x18.benchmark.StreamRequest\x1a\x16.benchmark.Transaction0\x012P\n" +
+	"\x12StreamTransactions\x12\x18.benchmark.StreamRequest\x1a\x16.benchmark.Transaction0\x01\x12P\n" +
+	"\x11SubmitTransaction\x12#.benchmark.SubmitTransactionRequest\x1a\x16.benchmark.Transaction2F\n" +
+	"\vEchoService\x127\n" +
+	"\x04Echo\x12\x16.benchmark.EchoRequest\x1a\x17.benchmark.EchoResponse2]\n" +
+	"\x12ServerStatsService\x12G\n" +
+	"\x0eGetServerStats\x12\x1d.benchmark.ServerStatsRequest\x1a\x16.benchmark.ServerStats2\x94\x02\n" +
+	"\fAdminService\x12>\n" +
+	"\vGetDBTarget\x12\x1a.benchmark.DBTargetRequest\x1a\x13.benchmark.DBTarget\x12A\n" +
+	"\vSetDBTarget\x12\x1d.benchmark.SetDBTargetRequest\x1a\x13.benchmark.DBTarget\x12@\n" +
+	"\aRestart\x12\x19.benchmark.RestartRequest\x1a\x1a.benchmark.RestartResponse\x12?\n" +
+	"\x0eCaptureProfile\x12\x19.benchmark.ProfileRequest\x1a\x12.benchmark.Profile2P\n" +
 	"\x06Health\x12F\n" +
 	"\x05Check\x12\x1d.benchmark.HealthCheckRequest\x1a\x1e.benchmark.HealthCheckResponseB7Z5github.com/kaldun-tech/grpc-rest-benchmark/pkg/protosb\x06proto3"
 
@@ -558,7 +1217,7 @@ func file_pkg_protos_benchmark_proto_rawDescGZIP() []byte {
 }
 
 var file_pkg_protos_benchmark_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
-var file_pkg_protos_benchmark_proto_msgTypes = make([]protoimpl.MessageInfo, 8)
+var file_pkg_protos_benchmark_proto_msgTypes = make([]protoimpl.MessageInfo, 20)
 var file_pkg_protos_benchmark_proto_goTypes = []any{
 	(HealthCheckResponse_ServingStatus)(0), // 0: benchmark.HealthCheckResponse.ServingStatus
 	(*BalanceRequest)(nil),                 // 1: benchmark.BalanceRequest
@@ -566,26 +1225,52 @@ var file_pkg_protos_benchmark_proto_goTypes = []any{
 	(*BatchBalanceRequest)(nil),            // 3: benchmark.BatchBalanceRequest
 	(*BatchBalanceResponse)(nil),           // 4: benchmark.BatchBalanceResponse
 	(*StreamRequest)(nil),                  // 5: benchmark.StreamRequest
-	(*Transaction)(nil),                    // 6: benchmark.Transaction
-	(*HealthCheckRequest)(nil),             // 7: benchmark.HealthCheckRequest
-	(*HealthCheckResponse)(nil),            // 8: benchmark.HealthCheckResponse
+	(*SubmitTransactionRequest)(nil),       // 6: benchmark.SubmitTransactionRequest
+	(*Transaction)(nil),                    // 7: benchmark.Transaction
+	(*EchoRequest)(nil),                    // 8: benchmark.EchoRequest
+	(*EchoResponse)(nil),                   // 9: benchmark.EchoResponse
+	(*ServerStatsRequest)(nil),             // 10: benchmark.ServerStatsRequest
+	(*ServerStats)(nil),                    // 11: benchmark.ServerStats
+	(*DBTargetRequest)(nil),                // 12: benchmark.DBTargetRequest
+	(*SetDBTargetRequest)(nil),             // 13: benchmark.SetDBTargetRequest
+	(*DBTarget)(nil),                       // 14: benchmark.DBTarget
+	(*RestartRequest)(nil),                 // 15: benchmark.RestartRequest
+	(*RestartResponse)(nil),                // 16: benchmark.RestartResponse
+	(*ProfileRequest)(nil),                 // 17: benchmark.ProfileRequest
+	(*Profile)(nil),                        // 18: benchmark.Profile
+	(*HealthCheckRequest)(nil),             // 19: benchmark.HealthCheckRequest
+	(*HealthCheckResponse)(nil),            // 20: benchmark.HealthCheckResponse
 }
 var file_pkg_protos_benchmark_proto_depIdxs = []int32{
-	2, // 0: benchmark.BatchBalanceResponse.balances:type_name -> benchmark.BalanceResponse
-	0, // 1: benchmark.HealthCheckResponse.status:type_name -> benchmark.HealthCheckResponse.ServingStatus
-	1, // 2: benchmark.BalanceService.GetBalance:input_type -> benchmark.BalanceRequest
-	3, // 3: benchmark.BalanceService.GetBalances:input_type -> benchmark.BatchBalanceRequest
-	5, // 4: benchmark.TransactionService.StreamTransactions:input_type -> benchmark.StreamRequest
-	7, // 5: benchmark.Health.Check:input_type -> benchmark.HealthCheckRequest
-	2, // 6: benchmark.BalanceService.GetBalance:output_type -> benchmark.BalanceResponse
-	4, // 7: benchmark.BalanceService.GetBalances:output_type -> benchmark.BatchBalanceResponse
-	6, // 8: benchmark.TransactionService.StreamTransactions:output_type -> benchmark.Transaction
-	8, // 9: benchmark.Health.Check:output_type -> benchmark.HealthCheckResponse
-	6, // [6:10] is the sub-list for method output_type
-	2, // [2:6] is the sub-list for method input_type
-	2, // [2:2] is the sub-list for extension type_name
-	2, // [2:2] is the sub-list for extension extendee
-	0, // [0:2] is the sub-list for field type_name
+	2,  // 0: benchmark.BatchBalanceResponse.balances:type_name -> benchmark.BalanceResponse
+	0,  // 1: benchmark.HealthCheckResponse.status:type_name -> benchmark.HealthCheckResponse.ServingStatus
+	1,  // 2: benchmark.BalanceService.GetBalance:input_type -> benchmark.BalanceRequest
+	3,  // 3: benchmark.BalanceService.GetBalances:input_type -> benchmark.BatchBalanceRequest
+	5,  // 4: benchmark.TransactionService.StreamTransactions:input_type -> benchmark.StreamRequest
+	6,  // 5: benchmark.TransactionService.SubmitTransaction:input_type -> benchmark.SubmitTransactionRequest
+	8,  // 6: benchmark.EchoService.Echo:input_type -> benchmark.EchoRequest
+	10, // 7: benchmark.ServerStatsService.GetServerStats:input_type -> benchmark.ServerStatsRequest
+	12, // 8: benchmark.AdminService.GetDBTarget:input_type -> benchmark.DBTargetRequest
+	13, // 9: benchmark.AdminService.SetDBTarget:input_type -> benchmark.SetDBTargetRequest
+	15, // 10: benchmark.AdminService.Restart:input_type -> benchmark.RestartRequest
+	17, // 11: benchmark.AdminService.CaptureProfile:input_type -> benchmark.ProfileRequest
+	19, // 12: benchmark.Health.Check:input_type -> benchmark.HealthCheckRequest
+	2,  // 13: benchmark.BalanceService.GetBalance:output_type -> benchmark.BalanceResponse
+	4,  // 14: benchmark.BalanceService.GetBalances:output_type -> benchmark.BatchBalanceResponse
+	7,  // 15: benchmark.TransactionService.StreamTransactions:output_type -> benchmark.Transaction
+	7,  // 16: benchmark.TransactionService.SubmitTransaction:output_type -> benchmark.Transaction
+	9,  // 17: benchmark.EchoService.Echo:output_type -> benchmark.EchoResponse
+	11, // 18: benchmark.ServerStatsService.GetServerStats:output_type -> benchmark.ServerStats
+	14, // 19: benchmark.AdminService.GetDBTarget:output_type -> benchmark.DBTarget
+	14, // 20: benchmark.AdminService.SetDBTarget:output_type -> benchmark.DBTarget
+	16, // 21: benchmark.AdminService.Restart:output_type -> benchmark.RestartResponse
+	18, // 22: benchmark.AdminService.CaptureProfile:output_type -> benchmark.Profile
+	20, // 23: benchmark.Health.Check:output_type -> benchmark.HealthCheckResponse
+	13, // [13:24] is the sub-list for method output_type
+	2,  // [2:13] is the sub-list for method input_type
+	2,  // [2:2] is the sub-list for extension type_name
+	2,  // [2:2] is the sub-list for extension extendee
+	0,  // [0:2] is the sub-list for field type_name
 }
 
 func init() { file_pkg_protos_benchmark_proto_init() }
@@ -599,9 +1284,9 @@ func file_pkg_protos_benchmark_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_pkg_protos_benchmark_proto_rawDesc), len(file_pkg_protos_benchmark_proto_rawDesc)),
 			NumEnums:      1,
-			NumMessages:   8,
+			NumMessages:   20,
 			NumExtensions: 0,
-			NumServices:   3,
+			NumServices:   6,
 		},
 		GoTypes:           file_pkg_protos_benchmark_proto_goTypes,
 		DependencyIndexes: file_pkg_protos_benchmark_proto_depIdxs,
@@ -164,6 +164,7 @@ var BalanceService_ServiceDesc = grpc.ServiceDesc{
 
 const (
 	TransactionService_StreamTransactions_FullMethodName = "/benchmark.TransactionService/StreamTransactions"
+	TransactionService_SubmitTransaction_FullMethodName  = "/benchmark.TransactionService/SubmitTransaction"
 )
 
 // TransactionServiceClient is the client API for TransactionService service.
@@ -172,6 +173,9 @@ const (
 type TransactionServiceClient interface {
 	// Server streaming RPC: Subscribe to transaction events
 	StreamTransactions(ctx context.Context, in *StreamRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Transaction], error)
+	// Unary RPC: Submit a new transaction (write scenario). Returns the stored
+	// transaction with its assigned ID and timestamp.
+	SubmitTransaction(ctx context.Context, in *SubmitTransactionRequest, opts ...grpc.CallOption) (*Transaction, error)
 }
 
 type transactionServiceClient struct {
@@ -201,12 +205,25 @@ func (c *transactionServiceClient) StreamTransactions(ctx context.Context, in *S
 // This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
 type TransactionService_StreamTransactionsClient = grpc.ServerStreamingClient[Transaction]
 
+func (c *transactionServiceClient) SubmitTransaction(ctx context.Context, in *SubmitTransactionRequest, opts ...grpc.CallOption) (*Transaction, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Transaction)
+	err := c.cc.Invoke(ctx, TransactionService_SubmitTransaction_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // TransactionServiceServer is the server API for TransactionService service.
 // All implementations must embed UnimplementedTransactionServiceServer
 // for forward compatibility.
 type TransactionServiceServer interface {
 	// Server streaming RPC: Subscribe to transaction events
 	StreamTransactions(*StreamRequest, grpc.ServerStreamingServer[Transaction]) error
+	// Unary RPC: Submit a new transaction (write scenario). Returns the stored
+	// transaction with its assigned ID and timestamp.
+	SubmitTransaction(context.Context, *SubmitTransactionRequest) (*Transaction, error)
 	mustEmbedUnimplementedTransactionServiceServer()
 }
 
@@ -220,6 +237,9 @@ type UnimplementedTransactionServiceServer struct{}
 func (UnimplementedTransactionServiceServer) StreamTransactions(*StreamRequest, grpc.ServerStreamingServer[Transaction]) error {
 	return status.Error(codes.Unimplemented, "method StreamTransactions not implemented")
 }
+func (UnimplementedTransactionServiceServer) SubmitTransaction(context.Context, *SubmitTransactionRequest) (*Transaction, error) {
+	return nil, status.Error(codes.Unimplemented, "method SubmitTransaction not implemented")
+}
 func (UnimplementedTransactionServiceServer) mustEmbedUnimplementedTransactionServiceServer() {}
 func (UnimplementedTransactionServiceServer) testEmbeddedByValue()                            {}
 
@@ -252,13 +272,36 @@ func _TransactionService_StreamTransactions_Handler(srv interface{}, stream grpc
 // This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
 type TransactionService_StreamTransactionsServer = grpc.ServerStreamingServer[Transaction]
 
+func _TransactionService_SubmitTransaction_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SubmitTransactionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TransactionServiceServer).SubmitTransaction(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TransactionService_SubmitTransaction_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TransactionServiceServer).SubmitTransaction(ctx, req.(*SubmitTransactionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // TransactionService_ServiceDesc is the grpc.ServiceDesc for TransactionService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
 var TransactionService_ServiceDesc = grpc.ServiceDesc{
 	ServiceName: "benchmark.TransactionService",
 	HandlerType: (*TransactionServiceServer)(nil),
-	Methods:     []grpc.MethodDesc{},
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "SubmitTransaction",
+			Handler:    _TransactionService_SubmitTransaction_Handler,
+		},
+	},
 	Streams: []grpc.StreamDesc{
 		{
 			StreamName:    "StreamTransactions",
@@ -269,6 +312,430 @@ var TransactionService_ServiceDesc = grpc.ServiceDesc{
 	Metadata: "pkg/protos/benchmark.proto",
 }
 
+const (
+	EchoService_Echo_FullMethodName = "/benchmark.EchoService/Echo"
+)
+
+// EchoServiceClient is the client API for EchoService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type EchoServiceClient interface {
+	// Unary RPC: Return a payload of the requested size, to measure how
+	// serialization cost scales with message size
+	Echo(ctx context.Context, in *EchoRequest, opts ...grpc.CallOption) (*EchoResponse, error)
+}
+
+type echoServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewEchoServiceClient(cc grpc.ClientConnInterface) EchoServiceClient {
+	return &echoServiceClient{cc}
+}
+
+func (c *echoServiceClient) Echo(ctx context.Context, in *EchoRequest, opts ...grpc.CallOption) (*EchoResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(EchoResponse)
+	err := c.cc.Invoke(ctx, EchoService_Echo_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// EchoServiceServer is the server API for EchoService service.
+// All implementations must embed UnimplementedEchoServiceServer
+// for forward compatibility.
+type EchoServiceServer interface {
+	// Unary RPC: Return a payload of the requested size, to measure how
+	// serialization cost scales with message size
+	Echo(context.Context, *EchoRequest) (*EchoResponse, error)
+	mustEmbedUnimplementedEchoServiceServer()
+}
+
+// UnimplementedEchoServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedEchoServiceServer struct{}
+
+func (UnimplementedEchoServiceServer) Echo(context.Context, *EchoRequest) (*EchoResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method Echo not implemented")
+}
+func (UnimplementedEchoServiceServer) mustEmbedUnimplementedEchoServiceServer() {}
+func (UnimplementedEchoServiceServer) testEmbeddedByValue()                     {}
+
+// UnsafeEchoServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to EchoServiceServer will
+// result in compilation errors.
+type UnsafeEchoServiceServer interface {
+	mustEmbedUnimplementedEchoServiceServer()
+}
+
+func RegisterEchoServiceServer(s grpc.ServiceRegistrar, srv EchoServiceServer) {
+	// If the following call panics, it indicates UnimplementedEchoServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&EchoService_ServiceDesc, srv)
+}
+
+func _EchoService_Echo_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(EchoRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(EchoServiceServer).Echo(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: EchoService_Echo_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(EchoServiceServer).Echo(ctx, req.(*EchoRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// EchoService_ServiceDesc is the grpc.ServiceDesc for EchoService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var EchoService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "benchmark.EchoService",
+	HandlerType: (*EchoServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Echo",
+			Handler:    _EchoService_Echo_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "pkg/protos/benchmark.proto",
+}
+
+const (
+	ServerStatsService_GetServerStats_FullMethodName = "/benchmark.ServerStatsService/GetServerStats"
+)
+
+// ServerStatsServiceClient is the client API for ServerStatsService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type ServerStatsServiceClient interface {
+	GetServerStats(ctx context.Context, in *ServerStatsRequest, opts ...grpc.CallOption) (*ServerStats, error)
+}
+
+type serverStatsServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewServerStatsServiceClient(cc grpc.ClientConnInterface) ServerStatsServiceClient {
+	return &serverStatsServiceClient{cc}
+}
+
+func (c *serverStatsServiceClient) GetServerStats(ctx context.Context, in *ServerStatsRequest, opts ...grpc.CallOption) (*ServerStats, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ServerStats)
+	err := c.cc.Invoke(ctx, ServerStatsService_GetServerStats_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ServerStatsServiceServer is the server API for ServerStatsService service.
+// All implementations must embed UnimplementedServerStatsServiceServer
+// for forward compatibility.
+type ServerStatsServiceServer interface {
+	GetServerStats(context.Context, *ServerStatsRequest) (*ServerStats, error)
+	mustEmbedUnimplementedServerStatsServiceServer()
+}
+
+// UnimplementedServerStatsServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedServerStatsServiceServer struct{}
+
+func (UnimplementedServerStatsServiceServer) GetServerStats(context.Context, *ServerStatsRequest) (*ServerStats, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetServerStats not implemented")
+}
+func (UnimplementedServerStatsServiceServer) mustEmbedUnimplementedServerStatsServiceServer() {}
+func (UnimplementedServerStatsServiceServer) testEmbeddedByValue()                            {}
+
+// UnsafeServerStatsServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to ServerStatsServiceServer will
+// result in compilation errors.
+type UnsafeServerStatsServiceServer interface {
+	mustEmbedUnimplementedServerStatsServiceServer()
+}
+
+func RegisterServerStatsServiceServer(s grpc.ServiceRegistrar, srv ServerStatsServiceServer) {
+	// If the following call panics, it indicates UnimplementedServerStatsServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&ServerStatsService_ServiceDesc, srv)
+}
+
+func _ServerStatsService_GetServerStats_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ServerStatsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ServerStatsServiceServer).GetServerStats(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ServerStatsService_GetServerStats_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ServerStatsServiceServer).GetServerStats(ctx, req.(*ServerStatsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// ServerStatsService_ServiceDesc is the grpc.ServiceDesc for ServerStatsService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var ServerStatsService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "benchmark.ServerStatsService",
+	HandlerType: (*ServerStatsServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetServerStats",
+			Handler:    _ServerStatsService_GetServerStats_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "pkg/protos/benchmark.proto",
+}
+
+const (
+	AdminService_GetDBTarget_FullMethodName    = "/benchmark.AdminService/GetDBTarget"
+	AdminService_SetDBTarget_FullMethodName    = "/benchmark.AdminService/SetDBTarget"
+	AdminService_Restart_FullMethodName        = "/benchmark.AdminService/Restart"
+	AdminService_CaptureProfile_FullMethodName = "/benchmark.AdminService/CaptureProfile"
+)
+
+// AdminServiceClient is the client API for AdminService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type AdminServiceClient interface {
+	GetDBTarget(ctx context.Context, in *DBTargetRequest, opts ...grpc.CallOption) (*DBTarget, error)
+	SetDBTarget(ctx context.Context, in *SetDBTargetRequest, opts ...grpc.CallOption) (*DBTarget, error)
+	Restart(ctx context.Context, in *RestartRequest, opts ...grpc.CallOption) (*RestartResponse, error)
+	CaptureProfile(ctx context.Context, in *ProfileRequest, opts ...grpc.CallOption) (*Profile, error)
+}
+
+type adminServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewAdminServiceClient(cc grpc.ClientConnInterface) AdminServiceClient {
+	return &adminServiceClient{cc}
+}
+
+func (c *adminServiceClient) GetDBTarget(ctx context.Context, in *DBTargetRequest, opts ...grpc.CallOption) (*DBTarget, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DBTarget)
+	err := c.cc.Invoke(ctx, AdminService_GetDBTarget_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminServiceClient) SetDBTarget(ctx context.Context, in *SetDBTargetRequest, opts ...grpc.CallOption) (*DBTarget, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DBTarget)
+	err := c.cc.Invoke(ctx, AdminService_SetDBTarget_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminServiceClient) Restart(ctx context.Context, in *RestartRequest, opts ...grpc.CallOption) (*RestartResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RestartResponse)
+	err := c.cc.Invoke(ctx, AdminService_Restart_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminServiceClient) CaptureProfile(ctx context.Context, in *ProfileRequest, opts ...grpc.CallOption) (*Profile, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Profile)
+	err := c.cc.Invoke(ctx, AdminService_CaptureProfile_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// AdminServiceServer is the server API for AdminService service.
+// All implementations must embed UnimplementedAdminServiceServer
+// for forward compatibility.
+type AdminServiceServer interface {
+	GetDBTarget(context.Context, *DBTargetRequest) (*DBTarget, error)
+	SetDBTarget(context.Context, *SetDBTargetRequest) (*DBTarget, error)
+	Restart(context.Context, *RestartRequest) (*RestartResponse, error)
+	CaptureProfile(context.Context, *ProfileRequest) (*Profile, error)
+	mustEmbedUnimplementedAdminServiceServer()
+}
+
+// UnimplementedAdminServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedAdminServiceServer struct{}
+
+func (UnimplementedAdminServiceServer) GetDBTarget(context.Context, *DBTargetRequest) (*DBTarget, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetDBTarget not implemented")
+}
+func (UnimplementedAdminServiceServer) SetDBTarget(context.Context, *SetDBTargetRequest) (*DBTarget, error) {
+	return nil, status.Error(codes.Unimplemented, "method SetDBTarget not implemented")
+}
+func (UnimplementedAdminServiceServer) Restart(context.Context, *RestartRequest) (*RestartResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method Restart not implemented")
+}
+func (UnimplementedAdminServiceServer) CaptureProfile(context.Context, *ProfileRequest) (*Profile, error) {
+	return nil, status.Error(codes.Unimplemented, "method CaptureProfile not implemented")
+}
+func (UnimplementedAdminServiceServer) mustEmbedUnimplementedAdminServiceServer() {}
+func (UnimplementedAdminServiceServer) testEmbeddedByValue()                      {}
+
+// UnsafeAdminServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to AdminServiceServer will
+// result in compilation errors.
+type UnsafeAdminServiceServer interface {
+	mustEmbedUnimplementedAdminServiceServer()
+}
+
+func RegisterAdminServiceServer(s grpc.ServiceRegistrar, srv AdminServiceServer) {
+	// If the following call panics, it indicates UnimplementedAdminServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&AdminService_ServiceDesc, srv)
+}
+
+func _AdminService_GetDBTarget_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DBTargetRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServiceServer).GetDBTarget(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AdminService_GetDBTarget_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServiceServer).GetDBTarget(ctx, req.(*DBTargetRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AdminService_SetDBTarget_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetDBTargetRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServiceServer).SetDBTarget(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AdminService_SetDBTarget_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServiceServer).SetDBTarget(ctx, req.(*SetDBTargetRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AdminService_Restart_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RestartRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServiceServer).Restart(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AdminService_Restart_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServiceServer).Restart(ctx, req.(*RestartRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AdminService_CaptureProfile_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ProfileRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServiceServer).CaptureProfile(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AdminService_CaptureProfile_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServiceServer).CaptureProfile(ctx, req.(*ProfileRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// AdminService_ServiceDesc is the grpc.ServiceDesc for AdminService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var AdminService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "benchmark.AdminService",
+	HandlerType: (*AdminServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetDBTarget",
+			Handler:    _AdminService_GetDBTarget_Handler,
+		},
+		{
+			MethodName: "SetDBTarget",
+			Handler:    _AdminService_SetDBTarget_Handler,
+		},
+		{
+			MethodName: "Restart",
+			Handler:    _AdminService_Restart_Handler,
+		},
+		{
+			MethodName: "CaptureProfile",
+			Handler:    _AdminService_CaptureProfile_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "pkg/protos/benchmark.proto",
+}
+
 const (
 	Health_Check_FullMethodName = "/benchmark.Health/Check"
 )
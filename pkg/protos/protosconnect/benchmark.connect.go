@@ -0,0 +1,640 @@
+// Code generated by protoc-gen-connect-go. DO NOT EDIT.
+//
+// Source: pkg/protos/benchmark.proto
+
+package protosconnect
+
+import (
+	connect "connectrpc.com/connect"
+	context "context"
+	errors "errors"
+	protos "github.com/kaldun-tech/grpc-rest-benchmark/pkg/protos"
+	http "net/http"
+	strings "strings"
+)
+
+// This is a compile-time assertion to ensure that this generated file and the connect package are
+// compatible. If you get a compiler error that this constant is not defined, this code was
+// generated with a version of connect newer than the one compiled into your binary. You can fix the
+// problem by either regenerating this code with an older version of connect or updating the connect
+// version compiled into your binary.
+const _ = connect.IsAtLeastVersion1_13_0
+
+const (
+	// BalanceServiceName is the fully-qualified name of the BalanceService service.
+	BalanceServiceName = "benchmark.BalanceService"
+	// TransactionServiceName is the fully-qualified name of the TransactionService service.
+	TransactionServiceName = "benchmark.TransactionService"
+	// EchoServiceName is the fully-qualified name of the EchoService service.
+	EchoServiceName = "benchmark.EchoService"
+	// ServerStatsServiceName is the fully-qualified name of the ServerStatsService service.
+	ServerStatsServiceName = "benchmark.ServerStatsService"
+	// AdminServiceName is the fully-qualified name of the AdminService service.
+	AdminServiceName = "benchmark.AdminService"
+	// HealthName is the fully-qualified name of the Health service.
+	HealthName = "benchmark.Health"
+)
+
+// These constants are the fully-qualified names of the RPCs defined in this package. They're
+// exposed at runtime as Spec.Procedure and as the final two segments of the HTTP route.
+//
+// Note that these are different from the fully-qualified method names used by
+// google.golang.org/protobuf/reflect/protoreflect. To convert from these constants to
+// reflection-formatted method names, remove the leading slash and convert the remaining slash to a
+// period.
+const (
+	// BalanceServiceGetBalanceProcedure is the fully-qualified name of the BalanceService's GetBalance
+	// RPC.
+	BalanceServiceGetBalanceProcedure = "/benchmark.BalanceService/GetBalance"
+	// BalanceServiceGetBalancesProcedure is the fully-qualified name of the BalanceService's
+	// GetBalances RPC.
+	BalanceServiceGetBalancesProcedure = "/benchmark.BalanceService/GetBalances"
+	// TransactionServiceStreamTransactionsProcedure is the fully-qualified name of the
+	// TransactionService's StreamTransactions RPC.
+	TransactionServiceStreamTransactionsProcedure = "/benchmark.TransactionService/StreamTransactions"
+	// TransactionServiceSubmitTransactionProcedure is the fully-qualified name of the
+	// TransactionService's SubmitTransaction RPC.
+	TransactionServiceSubmitTransactionProcedure = "/benchmark.TransactionService/SubmitTransaction"
+	// EchoServiceEchoProcedure is the fully-qualified name of the EchoService's Echo RPC.
+	EchoServiceEchoProcedure = "/benchmark.EchoService/Echo"
+	// ServerStatsServiceGetServerStatsProcedure is the fully-qualified name of the ServerStatsService's
+	// GetServerStats RPC.
+	ServerStatsServiceGetServerStatsProcedure = "/benchmark.ServerStatsService/GetServerStats"
+	// AdminServiceGetDBTargetProcedure is the fully-qualified name of the AdminService's GetDBTarget
+	// RPC.
+	AdminServiceGetDBTargetProcedure = "/benchmark.AdminService/GetDBTarget"
+	// AdminServiceSetDBTargetProcedure is the fully-qualified name of the AdminService's SetDBTarget
+	// RPC.
+	AdminServiceSetDBTargetProcedure = "/benchmark.AdminService/SetDBTarget"
+	// AdminServiceRestartProcedure is the fully-qualified name of the AdminService's Restart RPC.
+	AdminServiceRestartProcedure = "/benchmark.AdminService/Restart"
+	// AdminServiceCaptureProfileProcedure is the fully-qualified name of the AdminService's
+	// CaptureProfile RPC.
+	AdminServiceCaptureProfileProcedure = "/benchmark.AdminService/CaptureProfile"
+	// HealthCheckProcedure is the fully-qualified name of the Health's Check RPC.
+	HealthCheckProcedure = "/benchmark.Health/Check"
+)
+
+// BalanceServiceClient is a client for the benchmark.BalanceService service.
+type BalanceServiceClient interface {
+	// Unary RPC: Get balance for a single account
+	GetBalance(context.Context, *connect.Request[protos.BalanceRequest]) (*connect.Response[protos.BalanceResponse], error)
+	// Optional: Batch balance query (bonus scenario)
+	GetBalances(context.Context, *connect.Request[protos.BatchBalanceRequest]) (*connect.Response[protos.BatchBalanceResponse], error)
+}
+
+// NewBalanceServiceClient constructs a client for the benchmark.BalanceService service. By default,
+// it uses the Connect protocol with the binary Protobuf Codec, asks for gzipped responses, and
+// sends uncompressed requests. To use the gRPC or gRPC-Web protocols, supply the connect.WithGRPC()
+// or connect.WithGRPCWeb() options.
+//
+// The URL supplied here should be the base URL for the Connect or gRPC server (for example,
+// http://api.acme.com or https://acme.com/grpc).
+func NewBalanceServiceClient(httpClient connect.HTTPClient, baseURL string, opts ...connect.ClientOption) BalanceServiceClient {
+	baseURL = strings.TrimRight(baseURL, "/")
+	balanceServiceMethods := protos.File_pkg_protos_benchmark_proto.Services().ByName("BalanceService").Methods()
+	return &balanceServiceClient{
+		getBalance: connect.NewClient[protos.BalanceRequest, protos.BalanceResponse](
+			httpClient,
+			baseURL+BalanceServiceGetBalanceProcedure,
+			connect.WithSchema(balanceServiceMethods.ByName("GetBalance")),
+			connect.WithClientOptions(opts...),
+		),
+		getBalances: connect.NewClient[protos.BatchBalanceRequest, protos.BatchBalanceResponse](
+			httpClient,
+			baseURL+BalanceServiceGetBalancesProcedure,
+			connect.WithSchema(balanceServiceMethods.ByName("GetBalances")),
+			connect.WithClientOptions(opts...),
+		),
+	}
+}
+
+// balanceServiceClient implements BalanceServiceClient.
+type balanceServiceClient struct {
+	getBalance  *connect.Client[protos.BalanceRequest, protos.BalanceResponse]
+	getBalances *connect.Client[protos.BatchBalanceRequest, protos.BatchBalanceResponse]
+}
+
+// GetBalance calls benchmark.BalanceService.GetBalance.
+func (c *balanceServiceClient) GetBalance(ctx context.Context, req *connect.Request[protos.BalanceRequest]) (*connect.Response[protos.BalanceResponse], error) {
+	return c.getBalance.CallUnary(ctx, req)
+}
+
+// GetBalances calls benchmark.BalanceService.GetBalances.
+func (c *balanceServiceClient) GetBalances(ctx context.Context, req *connect.Request[protos.BatchBalanceRequest]) (*connect.Response[protos.BatchBalanceResponse], error) {
+	return c.getBalances.CallUnary(ctx, req)
+}
+
+// BalanceServiceHandler is an implementation of the benchmark.BalanceService service.
+type BalanceServiceHandler interface {
+	// Unary RPC: Get balance for a single account
+	GetBalance(context.Context, *connect.Request[protos.BalanceRequest]) (*connect.Response[protos.BalanceResponse], error)
+	// Optional: Batch balance query (bonus scenario)
+	GetBalances(context.Context, *connect.Request[protos.BatchBalanceRequest]) (*connect.Response[protos.BatchBalanceResponse], error)
+}
+
+// NewBalanceServiceHandler builds an HTTP handler from the service implementation. It returns the
+// path on which to mount the handler and the handler itself.
+//
+// By default, handlers support the Connect, gRPC, and gRPC-Web protocols with the binary Protobuf
+// and JSON codecs. They also support gzip compression.
+func NewBalanceServiceHandler(svc BalanceServiceHandler, opts ...connect.HandlerOption) (string, http.Handler) {
+	balanceServiceMethods := protos.File_pkg_protos_benchmark_proto.Services().ByName("BalanceService").Methods()
+	balanceServiceGetBalanceHandler := connect.NewUnaryHandler(
+		BalanceServiceGetBalanceProcedure,
+		svc.GetBalance,
+		connect.WithSchema(balanceServiceMethods.ByName("GetBalance")),
+		connect.WithHandlerOptions(opts...),
+	)
+	balanceServiceGetBalancesHandler := connect.NewUnaryHandler(
+		BalanceServiceGetBalancesProcedure,
+		svc.GetBalances,
+		connect.WithSchema(balanceServiceMethods.ByName("GetBalances")),
+		connect.WithHandlerOptions(opts...),
+	)
+	return "/benchmark.BalanceService/", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case BalanceServiceGetBalanceProcedure:
+			balanceServiceGetBalanceHandler.ServeHTTP(w, r)
+		case BalanceServiceGetBalancesProcedure:
+			balanceServiceGetBalancesHandler.ServeHTTP(w, r)
+		default:
+			http.NotFound(w, r)
+		}
+	})
+}
+
+// UnimplementedBalanceServiceHandler returns CodeUnimplemented from all methods.
+type UnimplementedBalanceServiceHandler struct{}
+
+func (UnimplementedBalanceServiceHandler) GetBalance(context.Context, *connect.Request[protos.BalanceRequest]) (*connect.Response[protos.BalanceResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("benchmark.BalanceService.GetBalance is not implemented"))
+}
+
+func (UnimplementedBalanceServiceHandler) GetBalances(context.Context, *connect.Request[protos.BatchBalanceRequest]) (*connect.Response[protos.BatchBalanceResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("benchmark.BalanceService.GetBalances is not implemented"))
+}
+
+// TransactionServiceClient is a client for the benchmark.TransactionService service.
+type TransactionServiceClient interface {
+	// Server streaming RPC: Subscribe to transaction events
+	StreamTransactions(context.Context, *connect.Request[protos.StreamRequest]) (*connect.ServerStreamForClient[protos.Transaction], error)
+	// Unary RPC: Submit a new transaction (write scenario). Returns the stored
+	// transaction with its assigned ID and timestamp.
+	SubmitTransaction(context.Context, *connect.Request[protos.SubmitTransactionRequest]) (*connect.Response[protos.Transaction], error)
+}
+
+// NewTransactionServiceClient constructs a client for the benchmark.TransactionService service. By
+// default, it uses the Connect protocol with the binary Protobuf Codec, asks for gzipped responses,
+// and sends uncompressed requests. To use the gRPC or gRPC-Web protocols, supply the
+// connect.WithGRPC() or connect.WithGRPCWeb() options.
+//
+// The URL supplied here should be the base URL for the Connect or gRPC server (for example,
+// http://api.acme.com or https://acme.com/grpc).
+func NewTransactionServiceClient(httpClient connect.HTTPClient, baseURL string, opts ...connect.ClientOption) TransactionServiceClient {
+	baseURL = strings.TrimRight(baseURL, "/")
+	transactionServiceMethods := protos.File_pkg_protos_benchmark_proto.Services().ByName("TransactionService").Methods()
+	return &transactionServiceClient{
+		streamTransactions: connect.NewClient[protos.StreamRequest, protos.Transaction](
+			httpClient,
+			baseURL+TransactionServiceStreamTransactionsProcedure,
+			connect.WithSchema(transactionServiceMethods.ByName("StreamTransactions")),
+			connect.WithClientOptions(opts...),
+		),
+		submitTransaction: connect.NewClient[protos.SubmitTransactionRequest, protos.Transaction](
+			httpClient,
+			baseURL+TransactionServiceSubmitTransactionProcedure,
+			connect.WithSchema(transactionServiceMethods.ByName("SubmitTransaction")),
+			connect.WithClientOptions(opts...),
+		),
+	}
+}
+
+// transactionServiceClient implements TransactionServiceClient.
+type transactionServiceClient struct {
+	streamTransactions *connect.Client[protos.StreamRequest, protos.Transaction]
+	submitTransaction  *connect.Client[protos.SubmitTransactionRequest, protos.Transaction]
+}
+
+// StreamTransactions calls benchmark.TransactionService.StreamTransactions.
+func (c *transactionServiceClient) StreamTransactions(ctx context.Context, req *connect.Request[protos.StreamRequest]) (*connect.ServerStreamForClient[protos.Transaction], error) {
+	return c.streamTransactions.CallServerStream(ctx, req)
+}
+
+// SubmitTransaction calls benchmark.TransactionService.SubmitTransaction.
+func (c *transactionServiceClient) SubmitTransaction(ctx context.Context, req *connect.Request[protos.SubmitTransactionRequest]) (*connect.Response[protos.Transaction], error) {
+	return c.submitTransaction.CallUnary(ctx, req)
+}
+
+// TransactionServiceHandler is an implementation of the benchmark.TransactionService service.
+type TransactionServiceHandler interface {
+	// Server streaming RPC: Subscribe to transaction events
+	StreamTransactions(context.Context, *connect.Request[protos.StreamRequest], *connect.ServerStream[protos.Transaction]) error
+	// Unary RPC: Submit a new transaction (write scenario). Returns the stored
+	// transaction with its assigned ID and timestamp.
+	SubmitTransaction(context.Context, *connect.Request[protos.SubmitTransactionRequest]) (*connect.Response[protos.Transaction], error)
+}
+
+// NewTransactionServiceHandler builds an HTTP handler from the service implementation. It returns
+// the path on which to mount the handler and the handler itself.
+//
+// By default, handlers support the Connect, gRPC, and gRPC-Web protocols with the binary Protobuf
+// and JSON codecs. They also support gzip compression.
+func NewTransactionServiceHandler(svc TransactionServiceHandler, opts ...connect.HandlerOption) (string, http.Handler) {
+	transactionServiceMethods := protos.File_pkg_protos_benchmark_proto.Services().ByName("TransactionService").Methods()
+	transactionServiceStreamTransactionsHandler := connect.NewServerStreamHandler(
+		TransactionServiceStreamTransactionsProcedure,
+		svc.StreamTransactions,
+		connect.WithSchema(transactionServiceMethods.ByName("StreamTransactions")),
+		connect.WithHandlerOptions(opts...),
+	)
+	transactionServiceSubmitTransactionHandler := connect.NewUnaryHandler(
+		TransactionServiceSubmitTransactionProcedure,
+		svc.SubmitTransaction,
+		connect.WithSchema(transactionServiceMethods.ByName("SubmitTransaction")),
+		connect.WithHandlerOptions(opts...),
+	)
+	return "/benchmark.TransactionService/", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case TransactionServiceStreamTransactionsProcedure:
+			transactionServiceStreamTransactionsHandler.ServeHTTP(w, r)
+		case TransactionServiceSubmitTransactionProcedure:
+			transactionServiceSubmitTransactionHandler.ServeHTTP(w, r)
+		default:
+			http.NotFound(w, r)
+		}
+	})
+}
+
+// UnimplementedTransactionServiceHandler returns CodeUnimplemented from all methods.
+type UnimplementedTransactionServiceHandler struct{}
+
+func (UnimplementedTransactionServiceHandler) StreamTransactions(context.Context, *connect.Request[protos.StreamRequest], *connect.ServerStream[protos.Transaction]) error {
+	return connect.NewError(connect.CodeUnimplemented, errors.New("benchmark.TransactionService.StreamTransactions is not implemented"))
+}
+
+func (UnimplementedTransactionServiceHandler) SubmitTransaction(context.Context, *connect.Request[protos.SubmitTransactionRequest]) (*connect.Response[protos.Transaction], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("benchmark.TransactionService.SubmitTransaction is not implemented"))
+}
+
+// EchoServiceClient is a client for the benchmark.EchoService service.
+type EchoServiceClient interface {
+	// Unary RPC: Return a payload of the requested size, to measure how
+	// serialization cost scales with message size
+	Echo(context.Context, *connect.Request[protos.EchoRequest]) (*connect.Response[protos.EchoResponse], error)
+}
+
+// NewEchoServiceClient constructs a client for the benchmark.EchoService service. By default, it
+// uses the Connect protocol with the binary Protobuf Codec, asks for gzipped responses, and sends
+// uncompressed requests. To use the gRPC or gRPC-Web protocols, supply the connect.WithGRPC() or
+// connect.WithGRPCWeb() options.
+//
+// The URL supplied here should be the base URL for the Connect or gRPC server (for example,
+// http://api.acme.com or https://acme.com/grpc).
+func NewEchoServiceClient(httpClient connect.HTTPClient, baseURL string, opts ...connect.ClientOption) EchoServiceClient {
+	baseURL = strings.TrimRight(baseURL, "/")
+	echoServiceMethods := protos.File_pkg_protos_benchmark_proto.Services().ByName("EchoService").Methods()
+	return &echoServiceClient{
+		echo: connect.NewClient[protos.EchoRequest, protos.EchoResponse](
+			httpClient,
+			baseURL+EchoServiceEchoProcedure,
+			connect.WithSchema(echoServiceMethods.ByName("Echo")),
+			connect.WithClientOptions(opts...),
+		),
+	}
+}
+
+// echoServiceClient implements EchoServiceClient.
+type echoServiceClient struct {
+	echo *connect.Client[protos.EchoRequest, protos.EchoResponse]
+}
+
+// Echo calls benchmark.EchoService.Echo.
+func (c *echoServiceClient) Echo(ctx context.Context, req *connect.Request[protos.EchoRequest]) (*connect.Response[protos.EchoResponse], error) {
+	return c.echo.CallUnary(ctx, req)
+}
+
+// EchoServiceHandler is an implementation of the benchmark.EchoService service.
+type EchoServiceHandler interface {
+	// Unary RPC: Return a payload of the requested size, to measure how
+	// serialization cost scales with message size
+	Echo(context.Context, *connect.Request[protos.EchoRequest]) (*connect.Response[protos.EchoResponse], error)
+}
+
+// NewEchoServiceHandler builds an HTTP handler from the service implementation. It returns the path
+// on which to mount the handler and the handler itself.
+//
+// By default, handlers support the Connect, gRPC, and gRPC-Web protocols with the binary Protobuf
+// and JSON codecs. They also support gzip compression.
+func NewEchoServiceHandler(svc EchoServiceHandler, opts ...connect.HandlerOption) (string, http.Handler) {
+	echoServiceMethods := protos.File_pkg_protos_benchmark_proto.Services().ByName("EchoService").Methods()
+	echoServiceEchoHandler := connect.NewUnaryHandler(
+		EchoServiceEchoProcedure,
+		svc.Echo,
+		connect.WithSchema(echoServiceMethods.ByName("Echo")),
+		connect.WithHandlerOptions(opts...),
+	)
+	return "/benchmark.EchoService/", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case EchoServiceEchoProcedure:
+			echoServiceEchoHandler.ServeHTTP(w, r)
+		default:
+			http.NotFound(w, r)
+		}
+	})
+}
+
+// UnimplementedEchoServiceHandler returns CodeUnimplemented from all methods.
+type UnimplementedEchoServiceHandler struct{}
+
+func (UnimplementedEchoServiceHandler) Echo(context.Context, *connect.Request[protos.EchoRequest]) (*connect.Response[protos.EchoResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("benchmark.EchoService.Echo is not implemented"))
+}
+
+// ServerStatsServiceClient is a client for the benchmark.ServerStatsService service.
+type ServerStatsServiceClient interface {
+	GetServerStats(context.Context, *connect.Request[protos.ServerStatsRequest]) (*connect.Response[protos.ServerStats], error)
+}
+
+// NewServerStatsServiceClient constructs a client for the benchmark.ServerStatsService service. By
+// default, it uses the Connect protocol with the binary Protobuf Codec, asks for gzipped responses,
+// and sends uncompressed requests. To use the gRPC or gRPC-Web protocols, supply the
+// connect.WithGRPC() or connect.WithGRPCWeb() options.
+//
+// The URL supplied here should be the base URL for the Connect or gRPC server (for example,
+// http://api.acme.com or https://acme.com/grpc).
+func NewServerStatsServiceClient(httpClient connect.HTTPClient, baseURL string, opts ...connect.ClientOption) ServerStatsServiceClient {
+	baseURL = strings.TrimRight(baseURL, "/")
+	serverStatsServiceMethods := protos.File_pkg_protos_benchmark_proto.Services().ByName("ServerStatsService").Methods()
+	return &serverStatsServiceClient{
+		getServerStats: connect.NewClient[protos.ServerStatsRequest, protos.ServerStats](
+			httpClient,
+			baseURL+ServerStatsServiceGetServerStatsProcedure,
+			connect.WithSchema(serverStatsServiceMethods.ByName("GetServerStats")),
+			connect.WithClientOptions(opts...),
+		),
+	}
+}
+
+// serverStatsServiceClient implements ServerStatsServiceClient.
+type serverStatsServiceClient struct {
+	getServerStats *connect.Client[protos.ServerStatsRequest, protos.ServerStats]
+}
+
+// GetServerStats calls benchmark.ServerStatsService.GetServerStats.
+func (c *serverStatsServiceClient) GetServerStats(ctx context.Context, req *connect.Request[protos.ServerStatsRequest]) (*connect.Response[protos.ServerStats], error) {
+	return c.getServerStats.CallUnary(ctx, req)
+}
+
+// ServerStatsServiceHandler is an implementation of the benchmark.ServerStatsService service.
+type ServerStatsServiceHandler interface {
+	GetServerStats(context.Context, *connect.Request[protos.ServerStatsRequest]) (*connect.Response[protos.ServerStats], error)
+}
+
+// NewServerStatsServiceHandler builds an HTTP handler from the service implementation. It returns
+// the path on which to mount the handler and the handler itself.
+//
+// By default, handlers support the Connect, gRPC, and gRPC-Web protocols with the binary Protobuf
+// and JSON codecs. They also support gzip compression.
+func NewServerStatsServiceHandler(svc ServerStatsServiceHandler, opts ...connect.HandlerOption) (string, http.Handler) {
+	serverStatsServiceMethods := protos.File_pkg_protos_benchmark_proto.Services().ByName("ServerStatsService").Methods()
+	serverStatsServiceGetServerStatsHandler := connect.NewUnaryHandler(
+		ServerStatsServiceGetServerStatsProcedure,
+		svc.GetServerStats,
+		connect.WithSchema(serverStatsServiceMethods.ByName("GetServerStats")),
+		connect.WithHandlerOptions(opts...),
+	)
+	return "/benchmark.ServerStatsService/", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case ServerStatsServiceGetServerStatsProcedure:
+			serverStatsServiceGetServerStatsHandler.ServeHTTP(w, r)
+		default:
+			http.NotFound(w, r)
+		}
+	})
+}
+
+// UnimplementedServerStatsServiceHandler returns CodeUnimplemented from all methods.
+type UnimplementedServerStatsServiceHandler struct{}
+
+func (UnimplementedServerStatsServiceHandler) GetServerStats(context.Context, *connect.Request[protos.ServerStatsRequest]) (*connect.Response[protos.ServerStats], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("benchmark.ServerStatsService.GetServerStats is not implemented"))
+}
+
+// AdminServiceClient is a client for the benchmark.AdminService service.
+type AdminServiceClient interface {
+	GetDBTarget(context.Context, *connect.Request[protos.DBTargetRequest]) (*connect.Response[protos.DBTarget], error)
+	SetDBTarget(context.Context, *connect.Request[protos.SetDBTargetRequest]) (*connect.Response[protos.DBTarget], error)
+	Restart(context.Context, *connect.Request[protos.RestartRequest]) (*connect.Response[protos.RestartResponse], error)
+	CaptureProfile(context.Context, *connect.Request[protos.ProfileRequest]) (*connect.Response[protos.Profile], error)
+}
+
+// NewAdminServiceClient constructs a client for the benchmark.AdminService service. By default, it
+// uses the Connect protocol with the binary Protobuf Codec, asks for gzipped responses, and sends
+// uncompressed requests. To use the gRPC or gRPC-Web protocols, supply the connect.WithGRPC() or
+// connect.WithGRPCWeb() options.
+//
+// The URL supplied here should be the base URL for the Connect or gRPC server (for example,
+// http://api.acme.com or https://acme.com/grpc).
+func NewAdminServiceClient(httpClient connect.HTTPClient, baseURL string, opts ...connect.ClientOption) AdminServiceClient {
+	baseURL = strings.TrimRight(baseURL, "/")
+	adminServiceMethods := protos.File_pkg_protos_benchmark_proto.Services().ByName("AdminService").Methods()
+	return &adminServiceClient{
+		getDBTarget: connect.NewClient[protos.DBTargetRequest, protos.DBTarget](
+			httpClient,
+			baseURL+AdminServiceGetDBTargetProcedure,
+			connect.WithSchema(adminServiceMethods.ByName("GetDBTarget")),
+			connect.WithClientOptions(opts...),
+		),
+		setDBTarget: connect.NewClient[protos.SetDBTargetRequest, protos.DBTarget](
+			httpClient,
+			baseURL+AdminServiceSetDBTargetProcedure,
+			connect.WithSchema(adminServiceMethods.ByName("SetDBTarget")),
+			connect.WithClientOptions(opts...),
+		),
+		restart: connect.NewClient[protos.RestartRequest, protos.RestartResponse](
+			httpClient,
+			baseURL+AdminServiceRestartProcedure,
+			connect.WithSchema(adminServiceMethods.ByName("Restart")),
+			connect.WithClientOptions(opts...),
+		),
+		captureProfile: connect.NewClient[protos.ProfileRequest, protos.Profile](
+			httpClient,
+			baseURL+AdminServiceCaptureProfileProcedure,
+			connect.WithSchema(adminServiceMethods.ByName("CaptureProfile")),
+			connect.WithClientOptions(opts...),
+		),
+	}
+}
+
+// adminServiceClient implements AdminServiceClient.
+type adminServiceClient struct {
+	getDBTarget    *connect.Client[protos.DBTargetRequest, protos.DBTarget]
+	setDBTarget    *connect.Client[protos.SetDBTargetRequest, protos.DBTarget]
+	restart        *connect.Client[protos.RestartRequest, protos.RestartResponse]
+	captureProfile *connect.Client[protos.ProfileRequest, protos.Profile]
+}
+
+// GetDBTarget calls benchmark.AdminService.GetDBTarget.
+func (c *adminServiceClient) GetDBTarget(ctx context.Context, req *connect.Request[protos.DBTargetRequest]) (*connect.Response[protos.DBTarget], error) {
+	return c.getDBTarget.CallUnary(ctx, req)
+}
+
+// SetDBTarget calls benchmark.AdminService.SetDBTarget.
+func (c *adminServiceClient) SetDBTarget(ctx context.Context, req *connect.Request[protos.SetDBTargetRequest]) (*connect.Response[protos.DBTarget], error) {
+	return c.setDBTarget.CallUnary(ctx, req)
+}
+
+// Restart calls benchmark.AdminService.Restart.
+func (c *adminServiceClient) Restart(ctx context.Context, req *connect.Request[protos.RestartRequest]) (*connect.Response[protos.RestartResponse], error) {
+	return c.restart.CallUnary(ctx, req)
+}
+
+// CaptureProfile calls benchmark.AdminService.CaptureProfile.
+func (c *adminServiceClient) CaptureProfile(ctx context.Context, req *connect.Request[protos.ProfileRequest]) (*connect.Response[protos.Profile], error) {
+	return c.captureProfile.CallUnary(ctx, req)
+}
+
+// AdminServiceHandler is an implementation of the benchmark.AdminService service.
+type AdminServiceHandler interface {
+	GetDBTarget(context.Context, *connect.Request[protos.DBTargetRequest]) (*connect.Response[protos.DBTarget], error)
+	SetDBTarget(context.Context, *connect.Request[protos.SetDBTargetRequest]) (*connect.Response[protos.DBTarget], error)
+	Restart(context.Context, *connect.Request[protos.RestartRequest]) (*connect.Response[protos.RestartResponse], error)
+	CaptureProfile(context.Context, *connect.Request[protos.ProfileRequest]) (*connect.Response[protos.Profile], error)
+}
+
+// NewAdminServiceHandler builds an HTTP handler from the service implementation. It returns the
+// path on which to mount the handler and the handler itself.
+//
+// By default, handlers support the Connect, gRPC, and gRPC-Web protocols with the binary Protobuf
+// and JSON codecs. They also support gzip compression.
+func NewAdminServiceHandler(svc AdminServiceHandler, opts ...connect.HandlerOption) (string, http.Handler) {
+	adminServiceMethods := protos.File_pkg_protos_benchmark_proto.Services().ByName("AdminService").Methods()
+	adminServiceGetDBTargetHandler := connect.NewUnaryHandler(
+		AdminServiceGetDBTargetProcedure,
+		svc.GetDBTarget,
+		connect.WithSchema(adminServiceMethods.ByName("GetDBTarget")),
+		connect.WithHandlerOptions(opts...),
+	)
+	adminServiceSetDBTargetHandler := connect.NewUnaryHandler(
+		AdminServiceSetDBTargetProcedure,
+		svc.SetDBTarget,
+		connect.WithSchema(adminServiceMethods.ByName("SetDBTarget")),
+		connect.WithHandlerOptions(opts...),
+	)
+	adminServiceRestartHandler := connect.NewUnaryHandler(
+		AdminServiceRestartProcedure,
+		svc.Restart,
+		connect.WithSchema(adminServiceMethods.ByName("Restart")),
+		connect.WithHandlerOptions(opts...),
+	)
+	adminServiceCaptureProfileHandler := connect.NewUnaryHandler(
+		AdminServiceCaptureProfileProcedure,
+		svc.CaptureProfile,
+		connect.WithSchema(adminServiceMethods.ByName("CaptureProfile")),
+		connect.WithHandlerOptions(opts...),
+	)
+	return "/benchmark.AdminService/", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case AdminServiceGetDBTargetProcedure:
+			adminServiceGetDBTargetHandler.ServeHTTP(w, r)
+		case AdminServiceSetDBTargetProcedure:
+			adminServiceSetDBTargetHandler.ServeHTTP(w, r)
+		case AdminServiceRestartProcedure:
+			adminServiceRestartHandler.ServeHTTP(w, r)
+		case AdminServiceCaptureProfileProcedure:
+			adminServiceCaptureProfileHandler.ServeHTTP(w, r)
+		default:
+			http.NotFound(w, r)
+		}
+	})
+}
+
+// UnimplementedAdminServiceHandler returns CodeUnimplemented from all methods.
+type UnimplementedAdminServiceHandler struct{}
+
+func (UnimplementedAdminServiceHandler) GetDBTarget(context.Context, *connect.Request[protos.DBTargetRequest]) (*connect.Response[protos.DBTarget], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("benchmark.AdminService.GetDBTarget is not implemented"))
+}
+
+func (UnimplementedAdminServiceHandler) SetDBTarget(context.Context, *connect.Request[protos.SetDBTargetRequest]) (*connect.Response[protos.DBTarget], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("benchmark.AdminService.SetDBTarget is not implemented"))
+}
+
+func (UnimplementedAdminServiceHandler) Restart(context.Context, *connect.Request[protos.RestartRequest]) (*connect.Response[protos.RestartResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("benchmark.AdminService.Restart is not implemented"))
+}
+
+func (UnimplementedAdminServiceHandler) CaptureProfile(context.Context, *connect.Request[protos.ProfileRequest]) (*connect.Response[protos.Profile], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("benchmark.AdminService.CaptureProfile is not implemented"))
+}
+
+// HealthClient is a client for the benchmark.Health service.
+type HealthClient interface {
+	Check(context.Context, *connect.Request[protos.HealthCheckRequest]) (*connect.Response[protos.HealthCheckResponse], error)
+}
+
+// NewHealthClient constructs a client for the benchmark.Health service. By default, it uses the
+// Connect protocol with the binary Protobuf Codec, asks for gzipped responses, and sends
+// uncompressed requests. To use the gRPC or gRPC-Web protocols, supply the connect.WithGRPC() or
+// connect.WithGRPCWeb() options.
+//
+// The URL supplied here should be the base URL for the Connect or gRPC server (for example,
+// http://api.acme.com or https://acme.com/grpc).
+func NewHealthClient(httpClient connect.HTTPClient, baseURL string, opts ...connect.ClientOption) HealthClient {
+	baseURL = strings.TrimRight(baseURL, "/")
+	healthMethods := protos.File_pkg_protos_benchmark_proto.Services().ByName("Health").Methods()
+	return &healthClient{
+		check: connect.NewClient[protos.HealthCheckRequest, protos.HealthCheckResponse](
+			httpClient,
+			baseURL+HealthCheckProcedure,
+			connect.WithSchema(healthMethods.ByName("Check")),
+			connect.WithClientOptions(opts...),
+		),
+	}
+}
+
+// healthClient implements HealthClient.
+type healthClient struct {
+	check *connect.Client[protos.HealthCheckRequest, protos.HealthCheckResponse]
+}
+
+// Check calls benchmark.Health.Check.
+func (c *healthClient) Check(ctx context.Context, req *connect.Request[protos.HealthCheckRequest]) (*connect.Response[protos.HealthCheckResponse], error) {
+	return c.check.CallUnary(ctx, req)
+}
+
+// HealthHandler is an implementation of the benchmark.Health service.
+type HealthHandler interface {
+	Check(context.Context, *connect.Request[protos.HealthCheckRequest]) (*connect.Response[protos.HealthCheckResponse], error)
+}
+
+// NewHealthHandler builds an HTTP handler from the service implementation. It returns the path on
+// which to mount the handler and the handler itself.
+//
+// By default, handlers support the Connect, gRPC, and gRPC-Web protocols with the binary Protobuf
+// and JSON codecs. They also support gzip compression.
+func NewHealthHandler(svc HealthHandler, opts ...connect.HandlerOption) (string, http.Handler) {
+	healthMethods := protos.File_pkg_protos_benchmark_proto.Services().ByName("Health").Methods()
+	healthCheckHandler := connect.NewUnaryHandler(
+		HealthCheckProcedure,
+		svc.Check,
+		connect.WithSchema(healthMethods.ByName("Check")),
+		connect.WithHandlerOptions(opts...),
+	)
+	return "/benchmark.Health/", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case HealthCheckProcedure:
+			healthCheckHandler.ServeHTTP(w, r)
+		default:
+			http.NotFound(w, r)
+		}
+	})
+}
+
+// UnimplementedHealthHandler returns CodeUnimplemented from all methods.
+type UnimplementedHealthHandler struct{}
+
+func (UnimplementedHealthHandler) Check(context.Context, *connect.Request[protos.HealthCheckRequest]) (*connect.Response[protos.HealthCheckResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("benchmark.Health.Check is not implemented"))
+}
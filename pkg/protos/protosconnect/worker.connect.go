@@ -0,0 +1,112 @@
+// Code generated by protoc-gen-connect-go. DO NOT EDIT.
+//
+// Source: pkg/protos/worker.proto
+
+package protosconnect
+
+import (
+	connect "connectrpc.com/connect"
+	context "context"
+	errors "errors"
+	protos "github.com/kaldun-tech/grpc-rest-benchmark/pkg/protos"
+	http "net/http"
+	strings "strings"
+)
+
+// This is a compile-time assertion to ensure that this generated file and the connect package are
+// compatible. If you get a compiler error that this constant is not defined, this code was
+// generated with a version of connect newer than the one compiled into your binary. You can fix the
+// problem by either regenerating this code with an older version of connect or updating the connect
+// version compiled into your binary.
+const _ = connect.IsAtLeastVersion1_13_0
+
+const (
+	// WorkerServiceName is the fully-qualified name of the WorkerService service.
+	WorkerServiceName = "benchmark.WorkerService"
+)
+
+// These constants are the fully-qualified names of the RPCs defined in this package. They're
+// exposed at runtime as Spec.Procedure and as the final two segments of the HTTP route.
+//
+// Note that these are different from the fully-qualified method names used by
+// google.golang.org/protobuf/reflect/protoreflect. To convert from these constants to
+// reflection-formatted method names, remove the leading slash and convert the remaining slash to a
+// period.
+const (
+	// WorkerServiceRunProcedure is the fully-qualified name of the WorkerService's Run RPC.
+	WorkerServiceRunProcedure = "/benchmark.WorkerService/Run"
+)
+
+// WorkerServiceClient is a client for the benchmark.WorkerService service.
+type WorkerServiceClient interface {
+	// Server streaming RPC: Run this worker's share of a benchmark and stream
+	// its samples in batches until the run is over
+	Run(context.Context, *connect.Request[protos.WorkerRunRequest]) (*connect.ServerStreamForClient[protos.WorkerSamples], error)
+}
+
+// NewWorkerServiceClient constructs a client for the benchmark.WorkerService service. By default,
+// it uses the Connect protocol with the binary Protobuf Codec, asks for gzipped responses, and
+// sends uncompressed requests. To use the gRPC or gRPC-Web protocols, supply the connect.WithGRPC()
+// or connect.WithGRPCWeb() options.
+//
+// The URL supplied here should be the base URL for the Connect or gRPC server (for example,
+// http://api.acme.com or https://acme.com/grpc).
+func NewWorkerServiceClient(httpClient connect.HTTPClient, baseURL string, opts ...connect.ClientOption) WorkerServiceClient {
+	baseURL = strings.TrimRight(baseURL, "/")
+	workerServiceMethods := protos.File_pkg_protos_worker_proto.Services().ByName("WorkerService").Methods()
+	return &workerServiceClient{
+		run: connect.NewClient[protos.WorkerRunRequest, protos.WorkerSamples](
+			httpClient,
+			baseURL+WorkerServiceRunProcedure,
+			connect.WithSchema(workerServiceMethods.ByName("Run")),
+			connect.WithClientOptions(opts...),
+		),
+	}
+}
+
+// workerServiceClient implements WorkerServiceClient.
+type workerServiceClient struct {
+	run *connect.Client[protos.WorkerRunRequest, protos.WorkerSamples]
+}
+
+// Run calls benchmark.WorkerService.Run.
+func (c *workerServiceClient) Run(ctx context.Context, req *connect.Request[protos.WorkerRunRequest]) (*connect.ServerStreamForClient[protos.WorkerSamples], error) {
+	return c.run.CallServerStream(ctx, req)
+}
+
+// WorkerServiceHandler is an implementation of the benchmark.WorkerService service.
+type WorkerServiceHandler interface {
+	// Server streaming RPC: Run this worker's share of a benchmark and stream
+	// its samples in batches until the run is over
+	Run(context.Context, *connect.Request[protos.WorkerRunRequest], *connect.ServerStream[protos.WorkerSamples]) error
+}
+
+// NewWorkerServiceHandler builds an HTTP handler from the service implementation. It returns the
+// path on which to mount the handler and the handler itself.
+//
+// By default, handlers support the Connect, gRPC, and gRPC-Web protocols with the binary Protobuf
+// and JSON codecs. They also support gzip compression.
+func NewWorkerServiceHandler(svc WorkerServiceHandler, opts ...connect.HandlerOption) (string, http.Handler) {
+	workerServiceMethods := protos.File_pkg_protos_worker_proto.Services().ByName("WorkerService").Methods()
+	workerServiceRunHandler := connect.NewServerStreamHandler(
+		WorkerServiceRunProcedure,
+		svc.Run,
+		connect.WithSchema(workerServiceMethods.ByName("Run")),
+		connect.WithHandlerOptions(opts...),
+	)
+	return "/benchmark.WorkerService/", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case WorkerServiceRunProcedure:
+			workerServiceRunHandler.ServeHTTP(w, r)
+		default:
+			http.NotFound(w, r)
+		}
+	})
+}
+
+// UnimplementedWorkerServiceHandler returns CodeUnimplemented from all methods.
+type UnimplementedWorkerServiceHandler struct{}
+
+func (UnimplementedWorkerServiceHandler) Run(context.Context, *connect.Request[protos.WorkerRunRequest], *connect.ServerStream[protos.WorkerSamples]) error {
+	return connect.NewError(connect.CodeUnimplemented, errors.New("benchmark.WorkerService.Run is not implemented"))
+}
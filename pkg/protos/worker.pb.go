@@ -0,0 +1,391 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.11
+// 	protoc        v3.21.12
+// source: pkg/protos/worker.proto
+
+package protos
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type WorkerRunRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Config        []byte                 `protobuf:"bytes,1,opt,name=config,proto3" json:"config,omitempty"`                                       // JSON-encoded bench.Config with this worker's share of the load
+	StartUnixNano int64                  `protobuf:"varint,2,opt,name=start_unix_nano,json=startUnixNano,proto3" json:"start_unix_nano,omitempty"` // when to start the load, so all workers start together
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WorkerRunRequest) Reset() {
+	*x = WorkerRunRequest{}
+	mi := &file_pkg_protos_worker_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WorkerRunRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WorkerRunRequest) ProtoMessage() {}
+
+func (x *WorkerRunRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_protos_worker_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WorkerRunRequest.ProtoReflect.Descriptor instead.
+func (*WorkerRunRequest) Descriptor() ([]byte, []int) {
+	return file_pkg_protos_worker_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *WorkerRunRequest) GetConfig() []byte {
+	if x != nil {
+		return x.Config
+	}
+	return nil
+}
+
+func (x *WorkerRunRequest) GetStartUnixNano() int64 {
+	if x != nil {
+		return x.StartUnixNano
+	}
+	return 0
+}
+
+type WorkerSamples struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Samples       []*WorkerSample        `protobuf:"bytes,1,rep,name=samples,proto3" json:"samples,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WorkerSamples) Reset() {
+	*x = WorkerSamples{}
+	mi := &file_pkg_protos_worker_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WorkerSamples) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WorkerSamples) ProtoMessage() {}
+
+func (x *WorkerSamples) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_protos_worker_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WorkerSamples.ProtoReflect.Descriptor instead.
+func (*WorkerSamples) Descriptor() ([]byte, []int) {
+	return file_pkg_protos_worker_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *WorkerSamples) GetSamples() []*WorkerSample {
+	if x != nil {
+		return x.Samples
+	}
+	return nil
+}
+
+type WorkerSample struct {
+	state             protoimpl.MessageState `protogen:"open.v1"`
+	LatencyNs         int64                  `protobuf:"varint,1,opt,name=latency_ns,json=latencyNs,proto3" json:"latency_ns,omitempty"`
+	Success           bool                   `protobuf:"varint,2,opt,name=success,proto3" json:"success,omitempty"`
+	ErrorType         string                 `protobuf:"bytes,3,opt,name=error_type,json=errorType,proto3" json:"error_type,omitempty"` // error type of a failed sample, e.g. "timeout"
+	Error             string                 `protobuf:"bytes,4,opt,name=error,proto3" json:"error,omitempty"`                          // its error message
+	TimestampUnixNano int64                  `protobuf:"varint,5,opt,name=timestamp_unix_nano,json=timestampUnixNano,proto3" json:"timestamp_unix_nano,omitempty"`
+	InterArrivalNs    int64                  `protobuf:"varint,6,opt,name=inter_arrival_ns,json=interArrivalNs,proto3" json:"inter_arrival_ns,omitempty"` // stream latencies, 0 where unavailable
+	DeliveryNs        int64                  `protobuf:"varint,7,opt,name=delivery_ns,json=deliveryNs,proto3" json:"delivery_ns,omitempty"`
+	ProcessingNs      int64                  `protobuf:"varint,8,opt,name=processing_ns,json=processingNs,proto3" json:"processing_ns,omitempty"`
+	StalenessNs       int64                  `protobuf:"varint,9,opt,name=staleness_ns,json=stalenessNs,proto3" json:"staleness_ns,omitempty"`
+	Phase             int32                  `protobuf:"varint,10,opt,name=phase,proto3" json:"phase,omitempty"`
+	Class             string                 `protobuf:"bytes,11,opt,name=class,proto3" json:"class,omitempty"`
+	Operation         string                 `protobuf:"bytes,12,opt,name=operation,proto3" json:"operation,omitempty"`
+	Subscriber        int32                  `protobuf:"varint,13,opt,name=subscriber,proto3" json:"subscriber,omitempty"`               // stream subscriber, counted from 1 across all workers
+	DbTimeNs          int64                  `protobuf:"varint,14,opt,name=db_time_ns,json=dbTimeNs,proto3" json:"db_time_ns,omitempty"` // database time the server reported, if db_timed
+	DbTimed           bool                   `protobuf:"varint,15,opt,name=db_timed,json=dbTimed,proto3" json:"db_timed,omitempty"`
+	EndToEndNs        int64                  `protobuf:"varint,16,opt,name=end_to_end_ns,json=endToEndNs,proto3" json:"end_to_end_ns,omitempty"`   // live stream latency from the transaction being stored, 0 if unavailable
+	ReconnectNs       int64                  `protobuf:"varint,17,opt,name=reconnect_ns,json=reconnectNs,proto3" json:"reconnect_ns,omitempty"`    // time a stream was disconnected before this event, 0 unless it is the first after a reconnect
+	MessageBytes      int64                  `protobuf:"varint,18,opt,name=message_bytes,json=messageBytes,proto3" json:"message_bytes,omitempty"` // serialized size of a stream message, 0 for unary requests
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
+}
+
+func (x *WorkerSample) Reset() {
+	*x = WorkerSample{}
+	mi := &file_pkg_protos_worker_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WorkerSample) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WorkerSample) ProtoMessage() {}
+
+func (x *WorkerSample) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_protos_worker_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WorkerSample.ProtoReflect.Descriptor instead.
+func (*WorkerSample) Descriptor() ([]byte, []int) {
+	return file_pkg_protos_worker_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *WorkerSample) GetLatencyNs() int64 {
+	if x != nil {
+		return x.LatencyNs
+	}
+	return 0
+}
+
+func (x *WorkerSample) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+func (x *WorkerSample) GetErrorType() string {
+	if x != nil {
+		return x.ErrorType
+	}
+	return ""
+}
+
+func (x *WorkerSample) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+func (x *WorkerSample) GetTimestampUnixNano() int64 {
+	if x != nil {
+		return x.TimestampUnixNano
+	}
+	return 0
+}
+
+func (x *WorkerSample) GetInterArrivalNs() int64 {
+	if x != nil {
+		return x.InterArrivalNs
+	}
+	return 0
+}
+
+func (x *WorkerSample) GetDeliveryNs() int64 {
+	if x != nil {
+		return x.DeliveryNs
+	}
+	return 0
+}
+
+func (x *WorkerSample) GetProcessingNs() int64 {
+	if x != nil {
+		return x.ProcessingNs
+	}
+	return 0
+}
+
+func (x *WorkerSample) GetStalenessNs() int64 {
+	if x != nil {
+		return x.StalenessNs
+	}
+	return 0
+}
+
+func (x *WorkerSample) GetPhase() int32 {
+	if x != nil {
+		return x.Phase
+	}
+	return 0
+}
+
+func (x *WorkerSample) GetClass() string {
+	if x != nil {
+		return x.Class
+	}
+	return ""
+}
+
+func (x *WorkerSample) GetOperation() string {
+	if x != nil {
+		return x.Operation
+	}
+	return ""
+}
+
+func (x *WorkerSample) GetSubscriber() int32 {
+	if x != nil {
+		return x.Subscriber
+	}
+	return 0
+}
+
+func (x *WorkerSample) GetDbTimeNs() int64 {
+	if x != nil {
+		return x.DbTimeNs
+	}
+	return 0
+}
+
+func (x *WorkerSample) GetDbTimed() bool {
+	if x != nil {
+		return x.DbTimed
+	}
+	return false
+}
+
+func (x *WorkerSample) GetEndToEndNs() int64 {
+	if x != nil {
+		return x.EndToEndNs
+	}
+	return 0
+}
+
+func (x *WorkerSample) GetReconnectNs() int64 {
+	if x != nil {
+		return x.ReconnectNs
+	}
+	return 0
+}
+
+func (x *WorkerSample) GetMessageBytes() int64 {
+	if x != nil {
+		return x.MessageBytes
+	}
+	return 0
+}
+
+var File_pkg_protos_worker_proto protoreflect.FileDescriptor
+
+const file_pkg_protos_worker_proto_rawDesc = "" +
+	"\n" +
+	"\x17pkg/protos/worker.proto\x12\tbenchmark\"R\n" +
+	"\x10WorkerRunRequest\x12\x16\n" +
+	"\x06config\x18\x01 \x01(\fR\x06config\x12&\n" +
+	"\x0fstart_unix_nano\x18\x02 \x01(\x03R\rstartUnixNano\"B\n" +
+	"\rWorkerSamples\x121\n" +
+	"\asamples\x18\x01 \x03(\v2\x17.benchmark.WorkerSampleR\asamples\"\xcd\x04\n" +
+	"\fWorkerSample\x12\x1d\n" +
+	"\n" +
+	"latency_ns\x18\x01 \x01(\x03R\tlatencyNs\x12\x18\n" +
+	"\asuccess\x18\x02 \x01(\bR\asuccess\x12\x1d\n" +
+	"\n" +
+	"error_type\x18\x03 \x01(\tR\terrorType\x12\x14\n" +
+	"\x05error\x18\x04 \x01(\tR\x05error\x12.\n" +
+	"\x13timestamp_unix_nano\x18\x05 \x01(\x03R\x11timestampUnixNano\x12(\n" +
+	"\x10inter_arrival_ns\x18\x06 \x01(\x03R\x0einterArrivalNs\x12\x1f\n" +
+	"\vdelivery_ns\x18\a \x01(\x03R\n" +
+	"deliveryNs\x12#\n" +
+	"\rprocessing_ns\x18\b \x01(\x03R\fprocessingNs\x12!\n" +
+	"\fstaleness_ns\x18\t \x01(\x03R\vstalenessNs\x12\x14\n" +
+	"\x05phase\x18\n" +
+	" \x01(\x05R\x05phase\x12\x14\n" +
+	"\x05class\x18\v \x01(\tR\x05class\x12\x1c\n" +
+	"\toperation\x18\f \x01(\tR\toperation\x12\x1e\n" +
+	"\n" +
+	"subscriber\x18\r \x01(\x05R\n" +
+	"subscriber\x12\x1c\n" +
+	"\n" +
+	"db_time_ns\x18\x0e \x01(\x03R\bdbTimeNs\x12\x19\n" +
+	"\bdb_timed\x18\x0f \x01(\bR\adbTimed\x12!\n" +
+	"\rend_to_end_ns\x18\x10 \x01(\x03R\n" +
+	"endToEndNs\x12!\n" +
+	"\freconnect_ns\x18\x11 \x01(\x03R\vreconnectNs\x12#\n" +
+	"\rmessage_bytes\x18\x12 \x01(\x03R\fmessageBytes2O\n" +
+	"\rWorkerService\x12>\n" +
+	"\x03Run\x12\x1b.benchmark.WorkerRunRequest\x1a\x18.benchmark.WorkerSamples0\x01B7Z5github.com/kaldun-tech/grpc-rest-benchmark/pkg/protosb\x06proto3"
+
+var (
+	file_pkg_protos_worker_proto_rawDescOnce sync.Once
+	file_pkg_protos_worker_proto_rawDescData []byte
+)
+
+func file_pkg_protos_worker_proto_rawDescGZIP() []byte {
+	file_pkg_protos_worker_proto_rawDescOnce.Do(func() {
+		file_pkg_protos_worker_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_pkg_protos_worker_proto_rawDesc), len(file_pkg_protos_worker_proto_rawDesc)))
+	})
+	return file_pkg_protos_worker_proto_rawDescData
+}
+
+var file_pkg_protos_worker_proto_msgTypes = make([]protoimpl.MessageInfo, 3)
+var file_pkg_protos_worker_proto_goTypes = []any{
+	(*WorkerRunRequest)(nil), // 0: benchmark.WorkerRunRequest
+	(*WorkerSamples)(nil),    // 1: benchmark.WorkerSamples
+	(*WorkerSample)(nil),     // 2: benchmark.WorkerSample
+}
+var file_pkg_protos_worker_proto_depIdxs = []int32{
+	2, // 0: benchmark.WorkerSamples.samples:type_name -> benchmark.WorkerSample
+	0, // 1: benchmark.WorkerService.Run:input_type -> benchmark.WorkerRunRequest
+	1, // 2: benchmark.WorkerService.Run:output_type -> benchmark.WorkerSamples
+	2, // [2:3] is the sub-list for method output_type
+	1, // [1:2] is the sub-list for method input_type
+	1, // [1:1] is the sub-list for extension type_name
+	1, // [1:1] is the sub-list for extension extendee
+	0, // [0:1] is the sub-list for field type_name
+}
+
+func init() { file_pkg_protos_worker_proto_init() }
+func file_pkg_protos_worker_proto_init() {
+	if File_pkg_protos_worker_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_pkg_protos_worker_proto_rawDesc), len(file_pkg_protos_worker_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   3,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_pkg_protos_worker_proto_goTypes,
+		DependencyIndexes: file_pkg_protos_worker_proto_depIdxs,
+		MessageInfos:      file_pkg_protos_worker_proto_msgTypes,
+	}.Build()
+	File_pkg_protos_worker_proto = out.File
+	file_pkg_protos_worker_proto_goTypes = nil
+	file_pkg_protos_worker_proto_depIdxs = nil
+}
@@ -0,0 +1,230 @@
+// Package qos implements the server-side options for isolating streaming
+// load from balance query load: a separate database pool for streams, or a
+// priority semaphore that serves queries first. Both servers apply the same
+// mode to every protocol they serve.
+package qos
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/kaldun-tech/grpc-rest-benchmark/pkg/db"
+)
+
+// QoS modes.
+const (
+	ModeNone     = "none"     // queries and streams share one pool, first come first served
+	ModePools    = "pools"    // streams use a separate, smaller pool
+	ModePriority = "priority" // a priority semaphore gates both, queries first
+)
+
+// Modes lists the valid QoS modes.
+var Modes = []string{ModeNone, ModePools, ModePriority}
+
+// Config holds the server QoS and database pool flags.
+type Config struct {
+	Mode        string
+	QueryPool   PoolConfig // the main pool: balance queries, and streams unless ModePools
+	StreamPool  PoolConfig // ModePools: the separate stream pool
+	Slots       int        // ModePriority: concurrent queries and streams
+	StreamSlots int        // ModePriority: slots streams may hold at once
+}
+
+// PoolConfig sizes a database connection pool.
+type PoolConfig struct {
+	Size     int
+	Lifetime time.Duration // maximum age of a connection, checked when it is released
+}
+
+// String formats the pool as "<size>/<lifetime>", e.g. "50/1h".
+func (p PoolConfig) String() string {
+	lifetime := p.Lifetime.String()
+	if strings.HasSuffix(lifetime, "m0s") {
+		lifetime = strings.TrimSuffix(lifetime, "0s")
+	}
+	if strings.HasSuffix(lifetime, "h0m") {
+		lifetime = strings.TrimSuffix(lifetime, "0m")
+	}
+	return fmt.Sprintf("%d/%s", p.Size, lifetime)
+}
+
+// apply sets the pool size and lifetime on a database config.
+func (p PoolConfig) apply(cfg *db.Config) {
+	cfg.MaxConns = int32(p.Size)
+	cfg.MinConns = min(cfg.MaxConns, 5)
+	cfg.MaxConnLifetime = p.Lifetime
+}
+
+// RegisterFlags registers the QoS and pool flags on fs.
+func (c *Config) RegisterFlags(fs *flag.FlagSet) {
+	fs.StringVar(&c.Mode, "qos", ModeNone, "Isolation of streams from balance queries: "+strings.Join(Modes, " | "))
+	fs.IntVar(&c.QueryPool.Size, "query-pool-size", 50, "Database connections of the main pool, used by balance queries (and streams unless --qos=pools)")
+	fs.DurationVar(&c.QueryPool.Lifetime, "query-pool-lifetime", time.Hour, "Maximum age of a main pool connection")
+	fs.IntVar(&c.StreamPool.Size, "stream-pool-size", 10, "Database connections of the separate stream pool (--qos=pools)")
+	fs.DurationVar(&c.StreamPool.Lifetime, "stream-pool-lifetime", time.Hour, "Maximum age of a stream pool connection (--qos=pools)")
+	fs.IntVar(&c.Slots, "qos-slots", 40, "Concurrent balance queries and streams (--qos=priority)")
+	fs.IntVar(&c.StreamSlots, "qos-stream-slots", 20, "Of --qos-slots, the most that streams may hold at once (--qos=priority)")
+}
+
+// Validate checks the QoS and pool flags for invalid values.
+func (c Config) Validate() error {
+	if c.QueryPool.Size < 1 || c.QueryPool.Lifetime <= 0 {
+		return fmt.Errorf("query-pool-size must be at least 1 and query-pool-lifetime positive")
+	}
+	switch c.Mode {
+	case ModeNone:
+	case ModePools:
+		if c.StreamPool.Size < 1 || c.StreamPool.Lifetime <= 0 {
+			return fmt.Errorf("stream-pool-size must be at least 1 and stream-pool-lifetime positive")
+		}
+	case ModePriority:
+		if c.Slots < 1 || c.StreamSlots < 1 {
+			return fmt.Errorf("qos-slots and qos-stream-slots must be at least 1")
+		}
+		if c.StreamSlots > c.Slots {
+			return fmt.Errorf("qos-stream-slots must not exceed qos-slots")
+		}
+	default:
+		return fmt.Errorf("invalid qos mode: %s (must be one of: %s)", c.Mode, strings.Join(Modes, ", "))
+	}
+	return nil
+}
+
+// String describes the mode for the server log, e.g.
+// "priority (40 slots, streams at most 20)".
+func (c Config) String() string {
+	switch c.Mode {
+	case ModePools:
+		return fmt.Sprintf("pools (stream pool of %d connections)", c.StreamPool.Size)
+	case ModePriority:
+		return fmt.Sprintf("priority (%d slots, streams at most %d)", c.Slots, c.StreamSlots)
+	}
+	return ModeNone
+}
+
+// PoolsLabel describes the database pools, as recorded with each run:
+// "shared=50/1h", or "query=50/1h stream=10/5m" in ModePools.
+func (c Config) PoolsLabel() string {
+	if c.Mode == ModePools {
+		return fmt.Sprintf("query=%s stream=%s", c.QueryPool, c.StreamPool)
+	}
+	return fmt.Sprintf("shared=%s", c.QueryPool)
+}
+
+// DBConfig returns base with the main pool settings applied.
+func (c Config) DBConfig(base db.Config) db.Config {
+	c.QueryPool.apply(&base)
+	return base
+}
+
+// Dataset is the account and transaction access the balance, stream and
+// write handlers use. *db.DB implements it without any isolation.
+type Dataset interface {
+	GetBalance(ctx context.Context, accountID string) (*db.Account, error)
+	GetBalances(ctx context.Context, accountIDs []string) ([]*db.Account, error)
+	StreamTransactions(ctx context.Context, opts db.StreamTransactionsOptions) (<-chan *db.Transaction, <-chan error)
+	InsertTransaction(ctx context.Context, tx *db.Transaction) error
+}
+
+// Open returns the dataset for the configured mode. Queries use database,
+// opened with DBConfig; in ModePools streams use a second pool connected
+// with dbCfg and the stream pool settings. The returned function closes any
+// pool Open created.
+func Open(ctx context.Context, cfg Config, database *db.DB, dbCfg db.Config) (Dataset, func(), error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, nil, err
+	}
+	switch cfg.Mode {
+	case ModePools:
+		cfg.StreamPool.apply(&dbCfg)
+		streams, err := db.New(ctx, dbCfg)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to open stream pool: %w", err)
+		}
+		return &pooled{DB: database, streams: streams}, streams.Close, nil
+	case ModePriority:
+		return &prioritized{Dataset: database, sem: NewSemaphore(cfg.Slots, cfg.StreamSlots)}, func() {}, nil
+	}
+	return database, func() {}, nil
+}
+
+// pooled serves queries and writes from the main pool and streams from their own.
+type pooled struct {
+	*db.DB
+	streams *db.DB
+}
+
+func (p *pooled) StreamTransactions(ctx context.Context, opts db.StreamTransactionsOptions) (<-chan *db.Transaction, <-chan error) {
+	return p.streams.StreamTransactions(ctx, opts)
+}
+
+// prioritized takes a semaphore slot for every query and write, and for the
+// lifetime of every stream's database query. Writes are short unary calls
+// and share the queries' priority.
+type prioritized struct {
+	Dataset
+	sem *Semaphore
+}
+
+func (p *prioritized) GetBalance(ctx context.Context, accountID string) (*db.Account, error) {
+	release, err := p.sem.Acquire(ctx, High)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	return p.Dataset.GetBalance(ctx, accountID)
+}
+
+func (p *prioritized) GetBalances(ctx context.Context, accountIDs []string) ([]*db.Account, error) {
+	release, err := p.sem.Acquire(ctx, High)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	return p.Dataset.GetBalances(ctx, accountIDs)
+}
+
+func (p *prioritized) InsertTransaction(ctx context.Context, tx *db.Transaction) error {
+	release, err := p.sem.Acquire(ctx, High)
+	if err != nil {
+		return err
+	}
+	defer release()
+	return p.Dataset.InsertTransaction(ctx, tx)
+}
+
+// StreamTransactions waits for a stream slot, then forwards the stream and
+// releases the slot once its query has finished.
+func (p *prioritized) StreamTransactions(ctx context.Context, opts db.StreamTransactionsOptions) (<-chan *db.Transaction, <-chan error) {
+	txCh := make(chan *db.Transaction, 100)
+	errCh := make(chan error, 1)
+
+	go func() {
+		defer close(txCh)
+		defer close(errCh)
+
+		release, err := p.sem.Acquire(ctx, Low)
+		if err != nil {
+			errCh <- err
+			return
+		}
+		defer release()
+
+		innerTx, innerErr := p.Dataset.StreamTransactions(ctx, opts)
+		for tx := range innerTx {
+			select {
+			case txCh <- tx:
+			case <-ctx.Done():
+				// The inner stream ends on the same context
+			}
+		}
+		if err := <-innerErr; err != nil {
+			errCh <- err
+		}
+	}()
+
+	return txCh, errCh
+}
@@ -0,0 +1,66 @@
+package qos
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kaldun-tech/grpc-rest-benchmark/pkg/db"
+)
+
+func testConfig() Config {
+	return Config{
+		Mode:        ModeNone,
+		QueryPool:   PoolConfig{Size: 50, Lifetime: time.Hour},
+		StreamPool:  PoolConfig{Size: 10, Lifetime: 5 * time.Minute},
+		Slots:       40,
+		StreamSlots: 20,
+	}
+}
+
+func TestConfig_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		modify  func(*Config)
+		wantErr bool
+	}{
+		{"defaults", func(c *Config) {}, false},
+		{"pools", func(c *Config) { c.Mode = ModePools }, false},
+		{"priority", func(c *Config) { c.Mode = ModePriority }, false},
+		{"unknown mode", func(c *Config) { c.Mode = "fair" }, true},
+		{"empty query pool", func(c *Config) { c.QueryPool.Size = 0 }, true},
+		{"zero stream lifetime", func(c *Config) { c.Mode = ModePools; c.StreamPool.Lifetime = 0 }, true},
+		{"stream lifetime unused", func(c *Config) { c.StreamPool.Lifetime = 0 }, false},
+		{"stream slots above slots", func(c *Config) { c.Mode = ModePriority; c.StreamSlots = 41 }, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := testConfig()
+			tt.modify(&c)
+			if err := c.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestConfig_PoolsLabel(t *testing.T) {
+	c := testConfig()
+	if got, want := c.PoolsLabel(), "shared=50/1h"; got != want {
+		t.Errorf("PoolsLabel() = %q, want %q", got, want)
+	}
+	c.Mode = ModePools
+	c.QueryPool.Lifetime = 90 * time.Minute
+	c.StreamPool.Lifetime = 30 * time.Second
+	if got, want := c.PoolsLabel(), "query=50/1h30m stream=10/30s"; got != want {
+		t.Errorf("PoolsLabel() = %q, want %q", got, want)
+	}
+}
+
+func TestConfig_DBConfig(t *testing.T) {
+	c := testConfig()
+	c.QueryPool = PoolConfig{Size: 3, Lifetime: time.Minute}
+	got := c.DBConfig(db.Config{Host: "db"})
+	if got.Host != "db" || got.MaxConns != 3 || got.MinConns != 3 || got.MaxConnLifetime != time.Minute {
+		t.Errorf("DBConfig() = %+v", got)
+	}
+}
@@ -0,0 +1,102 @@
+package qos
+
+import (
+	"context"
+	"sync"
+)
+
+// Priority orders waiters for a Semaphore slot.
+type Priority int
+
+const (
+	Low  Priority = iota // streams
+	High                 // balance queries and writes
+)
+
+// Semaphore limits concurrent dataset operations to a fixed number of
+// slots. A waiting query is always granted the next free slot before a
+// waiting stream, and streams hold at most streamSlots slots at once, so
+// long-lived streams cannot starve queries.
+type Semaphore struct {
+	mu          sync.Mutex
+	free        int
+	streamSlots int
+	streamsHeld int
+	waiting     [2][]chan struct{} // FIFO queues, indexed by Priority
+}
+
+// NewSemaphore returns a semaphore with slots slots, of which streams may
+// hold at most streamSlots.
+func NewSemaphore(slots, streamSlots int) *Semaphore {
+	return &Semaphore{free: slots, streamSlots: min(streamSlots, slots)}
+}
+
+// Acquire waits for a slot at priority p and returns the function that
+// releases it. It returns ctx's error if ctx ends first.
+func (s *Semaphore) Acquire(ctx context.Context, p Priority) (release func(), err error) {
+	release = func() { s.release(p) }
+
+	s.mu.Lock()
+	if len(s.waiting[High]) == 0 && (p == High || len(s.waiting[Low]) == 0) && s.grantable(p) {
+		s.take(p)
+		s.mu.Unlock()
+		return release, nil
+	}
+	ch := make(chan struct{})
+	s.waiting[p] = append(s.waiting[p], ch)
+	s.mu.Unlock()
+
+	select {
+	case <-ch:
+		return release, nil
+	case <-ctx.Done():
+		s.mu.Lock()
+		for i, w := range s.waiting[p] {
+			if w == ch {
+				s.waiting[p] = append(s.waiting[p][:i], s.waiting[p][i+1:]...)
+				s.mu.Unlock()
+				return nil, ctx.Err()
+			}
+		}
+		s.mu.Unlock()
+		// Granted while giving up; hand the slot on
+		s.release(p)
+		return nil, ctx.Err()
+	}
+}
+
+// grantable reports whether a slot can be taken at priority p. The caller
+// holds s.mu.
+func (s *Semaphore) grantable(p Priority) bool {
+	return s.free > 0 && (p == High || s.streamsHeld < s.streamSlots)
+}
+
+// take claims a slot at priority p. The caller holds s.mu.
+func (s *Semaphore) take(p Priority) {
+	s.free--
+	if p == Low {
+		s.streamsHeld++
+	}
+}
+
+// release returns a slot taken at priority p and wakes the waiters it
+// unblocks, queries first.
+func (s *Semaphore) release(p Priority) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.free++
+	if p == Low {
+		s.streamsHeld--
+	}
+	for _, q := range []Priority{High, Low} {
+		for len(s.waiting[q]) > 0 && s.grantable(q) {
+			s.take(q)
+			close(s.waiting[q][0])
+			s.waiting[q] = s.waiting[q][1:]
+		}
+		if len(s.waiting[q]) > 0 && q == High {
+			return // queries still waiting keep streams queued
+		}
+	}
+}
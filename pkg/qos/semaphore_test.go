@@ -0,0 +1,112 @@
+package qos
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// acquireAsync starts an Acquire and returns a channel that receives its
+// release function once a slot is granted.
+func acquireAsync(ctx context.Context, s *Semaphore, p Priority) <-chan func() {
+	ch := make(chan func(), 1)
+	go func() {
+		if release, err := s.Acquire(ctx, p); err == nil {
+			ch <- release
+		}
+	}()
+	return ch
+}
+
+// queued returns the number of queued waiters at priority p.
+func (s *Semaphore) queued(p Priority) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.waiting[p])
+}
+
+// waitQueued polls until n waiters are queued at priority p.
+func waitQueued(t *testing.T, s *Semaphore, p Priority, n int) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for s.queued(p) != n {
+		if time.Now().After(deadline) {
+			t.Fatalf("%d waiters queued at priority %d, want %d", s.queued(p), p, n)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestSemaphore_QueriesFirst(t *testing.T) {
+	ctx := context.Background()
+	s := NewSemaphore(1, 1)
+
+	release, err := s.Acquire(ctx, High)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stream := acquireAsync(ctx, s, Low)
+	waitQueued(t, s, Low, 1)
+	query := acquireAsync(ctx, s, High)
+	waitQueued(t, s, High, 1)
+
+	// The query queued after the stream is served first
+	release()
+	releaseQuery := <-query
+	select {
+	case <-stream:
+		t.Fatal("stream granted while a query held the only slot")
+	default:
+	}
+	releaseQuery()
+	(<-stream)()
+}
+
+func TestSemaphore_StreamSlots(t *testing.T) {
+	ctx := context.Background()
+	s := NewSemaphore(3, 1)
+
+	releaseStream, err := s.Acquire(ctx, Low)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second := acquireAsync(ctx, s, Low)
+	waitQueued(t, s, Low, 1)
+
+	// Free slots remain for queries while the stream share is used up
+	for i := 0; i < 2; i++ {
+		if _, err := s.Acquire(ctx, High); err != nil {
+			t.Fatal(err)
+		}
+	}
+	select {
+	case <-second:
+		t.Fatal("second stream granted beyond the stream share")
+	default:
+	}
+
+	releaseStream()
+	(<-second)()
+}
+
+func TestSemaphore_Cancel(t *testing.T) {
+	s := NewSemaphore(1, 1)
+	release, err := s.Acquire(context.Background(), High)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := s.Acquire(ctx, Low); err != context.DeadlineExceeded {
+		t.Fatalf("Acquire() error = %v, want deadline exceeded", err)
+	}
+	if n := s.queued(Low); n != 0 {
+		t.Errorf("%d waiters left queued after cancellation", n)
+	}
+
+	release()
+	if _, err := s.Acquire(context.Background(), Low); err != nil {
+		t.Errorf("Acquire() after release error = %v", err)
+	}
+}
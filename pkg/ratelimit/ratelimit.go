@@ -0,0 +1,192 @@
+// Package ratelimit sheds requests above a configured rate with a token
+// bucket, to benchmark how gracefully each protocol handles load the server
+// turns away. Rejected requests get 429 Too Many Requests over REST and
+// RESOURCE_EXHAUSTED over gRPC, gRPC-Web and Connect. The REST server limits
+// requests as HTTP middleware (REST and Connect), the gRPC server as
+// interceptors (gRPC and gRPC-Web), in front of the benchmark services only,
+// like pkg/fault. One bucket is shared by every client and protocol a server
+// serves; a stream takes one token when it is opened.
+package ratelimit
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"connectrpc.com/connect"
+	"github.com/kaldun-tech/grpc-rest-benchmark/pkg/internal/grpcmethod"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ErrLimited is the error of requests rejected by the limiter.
+var ErrLimited = errors.New("rate limit exceeded")
+
+// Config holds the rate limiting flags.
+type Config struct {
+	Rate  float64 // requests admitted per second, 0 for unlimited
+	Burst int     // requests admitted at once, 0 for one second's worth
+}
+
+// RegisterFlags registers the rate limiting flags on fs.
+func (c *Config) RegisterFlags(fs *flag.FlagSet) {
+	fs.Float64Var(&c.Rate, "rate-limit", 0, "Benchmark requests admitted per second across all clients; the rest are rejected with 429 or RESOURCE_EXHAUSTED (0 = unlimited)")
+	fs.IntVar(&c.Burst, "rate-limit-burst", 0, "Requests admitted at once above --rate-limit (0 = one second's worth)")
+}
+
+// Validate checks the rate limiting flags for invalid values.
+func (c Config) Validate() error {
+	if c.Rate < 0 || c.Burst < 0 {
+		return fmt.Errorf("rate-limit and rate-limit-burst must not be negative")
+	}
+	if c.Rate == 0 && c.Burst > 0 {
+		return fmt.Errorf("rate-limit-burst requires --rate-limit")
+	}
+	return nil
+}
+
+// Enabled reports whether requests are rate limited.
+func (c Config) Enabled() bool {
+	return c.Rate > 0
+}
+
+// burst returns the bucket size: Burst, or one second's worth of requests.
+func (c Config) burst() int {
+	if c.Burst > 0 {
+		return c.Burst
+	}
+	return max(1, int(math.Ceil(c.Rate)))
+}
+
+// String describes the limit, as recorded with each run, e.g.
+// "rate=1000/s,burst=1000". It is empty when requests are not limited.
+func (c Config) String() string {
+	if !c.Enabled() {
+		return ""
+	}
+	return fmt.Sprintf("rate=%g/s,burst=%d", c.Rate, c.burst())
+}
+
+// Limiter admits requests from a token bucket refilled at the configured
+// rate. A nil *Limiter admits every request. It is safe for concurrent
+// use.
+type Limiter struct {
+	rate  float64 // tokens per second
+	burst float64
+
+	mu     sync.Mutex
+	tokens float64
+	last   time.Time
+	now    func() time.Time
+}
+
+// New creates a limiter for cfg, or returns nil if cfg limits nothing. The
+// bucket starts full.
+func New(cfg Config) *Limiter {
+	if !cfg.Enabled() {
+		return nil
+	}
+	burst := float64(cfg.burst())
+	return &Limiter{rate: cfg.Rate, burst: burst, tokens: burst, last: time.Now(), now: time.Now}
+}
+
+// Allow takes a token if one is available. Otherwise it returns false and
+// how long until the next token.
+func (l *Limiter) Allow() (ok bool, retryAfter time.Duration) {
+	if l == nil {
+		return true, 0
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := l.now()
+	l.tokens = min(l.burst, l.tokens+now.Sub(l.last).Seconds()*l.rate)
+	l.last = now
+	if l.tokens >= 1 {
+		l.tokens--
+		return true, 0
+	}
+	return false, time.Duration((1 - l.tokens) / l.rate * float64(time.Second))
+}
+
+// retryAfterSeconds returns the Retry-After value for a wait of d: whole
+// seconds, at least 1.
+func retryAfterSeconds(d time.Duration) string {
+	return strconv.Itoa(max(1, int(math.Ceil(d.Seconds()))))
+}
+
+// Handler returns next behind the limiter. Rejected requests get 429 Too
+// Many Requests with a Retry-After header and a JSON error body, as the
+// REST handlers return errors.
+func (l *Limiter) Handler(next http.Handler) http.Handler {
+	if l == nil {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if ok, retryAfter := l.Allow(); !ok {
+			w.Header().Set("Retry-After", retryAfterSeconds(retryAfter))
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusTooManyRequests)
+			json.NewEncoder(w).Encode(map[string]string{"error": ErrLimited.Error()})
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// ConnectHandler returns Connect handler next behind the limiter. Rejected
+// requests fail with ResourceExhausted in the request's protocol, which a
+// plain 429 would not convey to Connect streaming clients.
+func (l *Limiter) ConnectHandler(next http.Handler) http.Handler {
+	if l == nil {
+		return next
+	}
+	errWriter := connect.NewErrorWriter()
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ok, retryAfter := l.Allow()
+		if ok {
+			next.ServeHTTP(w, r)
+			return
+		}
+		w.Header().Set("Retry-After", retryAfterSeconds(retryAfter))
+		if !errWriter.IsSupported(r) {
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		errWriter.Write(w, r, connect.NewError(connect.CodeResourceExhausted, ErrLimited))
+	})
+}
+
+// UnaryServerInterceptor limits unary calls to the given services (full
+// names, e.g. "benchmark.BalanceService"); calls to other services, such as
+// health checks, pass through. Rejected calls fail with ResourceExhausted.
+func (l *Limiter) UnaryServerInterceptor(services ...string) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if l != nil && grpcmethod.InServices(info.FullMethod, services) {
+			if ok, _ := l.Allow(); !ok {
+				return nil, status.Error(codes.ResourceExhausted, ErrLimited.Error())
+			}
+		}
+		return handler(ctx, req)
+	}
+}
+
+// StreamServerInterceptor limits the opening of streaming calls to the
+// given services, like UnaryServerInterceptor.
+func (l *Limiter) StreamServerInterceptor(services ...string) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if l != nil && grpcmethod.InServices(info.FullMethod, services) {
+			if ok, _ := l.Allow(); !ok {
+				return status.Error(codes.ResourceExhausted, ErrLimited.Error())
+			}
+		}
+		return handler(srv, ss)
+	}
+}
@@ -0,0 +1,107 @@
+package ratelimit
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestConfig(t *testing.T) {
+	var none Config
+	if none.Enabled() || none.String() != "" || New(none) != nil {
+		t.Errorf("zero Config is enabled or described as %q", none.String())
+	}
+
+	if got, want := (Config{Rate: 250.5}).String(), "rate=250.5/s,burst=251"; got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+	if got, want := (Config{Rate: 1000, Burst: 10}).String(), "rate=1000/s,burst=10"; got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+
+	for _, bad := range []Config{{Rate: -1}, {Rate: 10, Burst: -1}, {Burst: 10}} {
+		if err := bad.Validate(); err == nil {
+			t.Errorf("Validate(%+v) = nil, want an error", bad)
+		}
+	}
+}
+
+func TestLimiter_Allow(t *testing.T) {
+	var nilLimiter *Limiter
+	if ok, _ := nilLimiter.Allow(); !ok {
+		t.Error("nil Allow() = false, want true")
+	}
+
+	l := New(Config{Rate: 10, Burst: 2})
+	now := time.Now()
+	l.last = now
+	l.now = func() time.Time { return now }
+
+	for i := range 2 {
+		if ok, _ := l.Allow(); !ok {
+			t.Fatalf("request %d within the burst was rejected", i+1)
+		}
+	}
+	ok, retryAfter := l.Allow()
+	if ok {
+		t.Fatal("request above the burst was admitted")
+	}
+	if retryAfter != 100*time.Millisecond {
+		t.Errorf("retryAfter = %v, want 100ms at 10/s", retryAfter)
+	}
+
+	now = now.Add(100 * time.Millisecond)
+	if ok, _ := l.Allow(); !ok {
+		t.Error("request after one refill interval was rejected")
+	}
+	if ok, _ := l.Allow(); ok {
+		t.Error("second request after one refill interval was admitted")
+	}
+}
+
+func TestLimiter_Handler(t *testing.T) {
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+	h := New(Config{Rate: 0.001, Burst: 1}).Handler(ok)
+
+	get := func() *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/accounts/0.0.1", nil))
+		return rec
+	}
+	if rec := get(); rec.Code != http.StatusOK {
+		t.Fatalf("first request status = %d, want 200", rec.Code)
+	}
+	rec := get()
+	if rec.Code != http.StatusTooManyRequests {
+		t.Fatalf("second request status = %d, want 429", rec.Code)
+	}
+	if rec.Header().Get("Retry-After") == "" {
+		t.Error("429 response without a Retry-After header")
+	}
+}
+
+func TestLimiter_UnaryServerInterceptor(t *testing.T) {
+	interceptor := New(Config{Rate: 0.001, Burst: 1}).UnaryServerInterceptor("benchmark.BalanceService")
+	ok := func(ctx context.Context, req any) (any, error) { return "ok", nil }
+	call := func(method string) error {
+		_, err := interceptor(context.Background(), nil, &grpc.UnaryServerInfo{FullMethod: method}, ok)
+		return err
+	}
+
+	const balance = "/benchmark.BalanceService/GetBalance"
+	if err := call(balance); err != nil {
+		t.Fatalf("first call error = %v", err)
+	}
+	if err := call(balance); status.Code(err) != codes.ResourceExhausted {
+		t.Errorf("second call error = %v, want ResourceExhausted", err)
+	}
+	if err := call("/grpc.health.v1.Health/Check"); err != nil {
+		t.Errorf("health check error = %v, want it exempt from the limit", err)
+	}
+}
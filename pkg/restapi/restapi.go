@@ -0,0 +1,161 @@
+// Package restapi defines the JSON bodies of the REST benchmark endpoints,
+// shared by the REST server and the benchmark client.
+//
+// restapi_easyjson.go holds marshalers generated for them by easyjson,
+// without the MarshalJSON and UnmarshalJSON methods, so that only the
+// easyjson encoder of pkg/jsoncodec uses them. Regenerate it with
+// go generate after changing a type.
+package restapi
+
+//go:generate go run github.com/mailru/easyjson/easyjson -all -no_std_marshalers restapi.go
+
+// Parity bodies. By default the endpoints use the idiomatic types of this
+// package. A client that adds the ShapeParam media type parameter with
+// value ParityShape to its Accept header, e.g. "application/json;
+// shape=parity", gets the protojson encoding of the protobuf messages the
+// gRPC server returns instead: the same fields under the same names, so
+// that differing payloads do not skew a protocol comparison. Submissions
+// with the parameter in their Content-Type are decoded the same way.
+// Errors and the end of an SSE stream keep their idiomatic bodies.
+const (
+	ShapeParam  = "shape"
+	ParityShape = "parity"
+)
+
+// Encoders of parity responses, selected with the REST server's
+// --parity-marshal flag. ParityProtoJSON marshals the generated protobuf
+// types with protojson; ParityStructs marshals the hand-written Parity
+// types of this package, which produce the same JSON, with the server's
+// JSON encoder. Comparing the two isolates the cost of protojson.
+const (
+	ParityProtoJSON = "protojson"
+	ParityStructs   = "structs"
+)
+
+// ParityMarshals lists the encoders of parity responses.
+var ParityMarshals = []string{ParityProtoJSON, ParityStructs}
+
+// Framings of JSON transaction streams, selected with --rest-stream-format.
+// SSE streams send each transaction as an event with event:, id: and data:
+// lines and end with a "done" event. NDJSON streams send one transaction per
+// line, and chunked-array streams a single JSON array whose elements are
+// flushed as they are sent; both report the number of transactions sent in
+// a Messages-Sent trailer, as protobuf streams do, and end without it on
+// failure. A client picks one with the media type of its Accept header
+// (see StreamContentType); the server's format applies to other clients.
+const (
+	StreamSSE          = "sse"
+	StreamNDJSON       = "ndjson"
+	StreamChunkedArray = "chunked-array"
+)
+
+// StreamFormats lists the framings of JSON transaction streams.
+var StreamFormats = []string{StreamSSE, StreamNDJSON, StreamChunkedArray}
+
+// streamContentTypes are the media types of the stream framings.
+var streamContentTypes = map[string]string{
+	StreamSSE:          "text/event-stream",
+	StreamNDJSON:       "application/x-ndjson",
+	StreamChunkedArray: "application/json",
+}
+
+// StreamContentType returns the media type of a stream framing.
+func StreamContentType(format string) string {
+	return streamContentTypes[format]
+}
+
+// StreamFormatOf returns the stream framing with the given media type, ""
+// if there is none.
+func StreamFormatOf(mediaType string) string {
+	for format, contentType := range streamContentTypes {
+		if contentType == mediaType {
+			return format
+		}
+	}
+	return ""
+}
+
+// BalanceResponse is the response for balance queries.
+type BalanceResponse struct {
+	Account   string `json:"account"`
+	Balance   int64  `json:"balance"`
+	Timestamp string `json:"timestamp"`
+}
+
+// BatchBalanceResponse is the response for batch balance queries.
+type BatchBalanceResponse struct {
+	Balances []BalanceResponse `json:"balances"`
+}
+
+// TransactionEvent is the payload of SSE transaction events, and the
+// response for transaction submissions.
+type TransactionEvent struct {
+	TxID      string `json:"tx_id"`
+	From      string `json:"from"`
+	To        string `json:"to"`
+	Amount    int64  `json:"amount"`
+	Type      string `json:"type"`
+	Timestamp string `json:"timestamp"`
+
+	// SentAt is when the server sent a streamed event, in nanoseconds since
+	// the Unix epoch; omitted outside streams.
+	SentAt int64 `json:"sent_at_unix_nano,omitempty"`
+}
+
+// SubmitTransactionRequest is the body of a transaction submission. An
+// empty type means "transfer".
+type SubmitTransactionRequest struct {
+	From   string `json:"from"`
+	To     string `json:"to"`
+	Amount int64  `json:"amount"`
+	Type   string `json:"type,omitempty"`
+}
+
+// StreamDone is the payload of the SSE event ending a stream, with the
+// number of transactions it sent.
+type StreamDone struct {
+	Sent int64 `json:"sent"`
+}
+
+// EchoResponse is the response for echo requests. The payload is base64
+// encoded, as protobuf JSON encodes bytes fields.
+type EchoResponse struct {
+	Payload []byte `json:"payload"`
+}
+
+// ErrorResponse is the response for errors.
+type ErrorResponse struct {
+	Error string `json:"error"`
+}
+
+// The Parity types mirror the protobuf messages of the parity responses as
+// protojson encodes them: lowerCamelCase names, int64 values as strings and
+// zero values omitted.
+
+// ParityBalanceResponse mirrors protos.BalanceResponse.
+type ParityBalanceResponse struct {
+	AccountID      string `json:"accountId,omitempty"`
+	BalanceTinybar int64  `json:"balanceTinybar,omitempty,string"`
+	Timestamp      string `json:"timestamp,omitempty"`
+}
+
+// ParityBatchBalanceResponse mirrors protos.BatchBalanceResponse.
+type ParityBatchBalanceResponse struct {
+	Balances []ParityBalanceResponse `json:"balances,omitempty"`
+}
+
+// ParityTransaction mirrors protos.Transaction.
+type ParityTransaction struct {
+	TxID           string `json:"txId,omitempty"`
+	FromAccount    string `json:"fromAccount,omitempty"`
+	ToAccount      string `json:"toAccount,omitempty"`
+	AmountTinybar  int64  `json:"amountTinybar,omitempty,string"`
+	TxType         string `json:"txType,omitempty"`
+	Timestamp      string `json:"timestamp,omitempty"`
+	SentAtUnixNano int64  `json:"sentAtUnixNano,omitempty,string"`
+}
+
+// ParityEchoResponse mirrors protos.EchoResponse.
+type ParityEchoResponse struct {
+	Payload []byte `json:"payload,omitempty"`
+}
@@ -0,0 +1,840 @@
+// Code generated by easyjson for marshaling/unmarshaling. DO NOT EDIT.
+
+package restapi
+
+import (
+	json "encoding/json"
+	easyjson "github.com/mailru/easyjson"
+	jlexer "github.com/mailru/easyjson/jlexer"
+	jwriter "github.com/mailru/easyjson/jwriter"
+)
+
+// suppress unused package warning
+var (
+	_ *json.RawMessage
+	_ *jlexer.Lexer
+	_ *jwriter.Writer
+	_ easyjson.Marshaler
+)
+
+func easyjsonE9463c14DecodeGithubComKaldunTechGrpcRestBenchmarkPkgRestapi(in *jlexer.Lexer, out *TransactionEvent) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "tx_id":
+			out.TxID = string(in.String())
+		case "from":
+			out.From = string(in.String())
+		case "to":
+			out.To = string(in.String())
+		case "amount":
+			out.Amount = int64(in.Int64())
+		case "type":
+			out.Type = string(in.String())
+		case "timestamp":
+			out.Timestamp = string(in.String())
+		case "sent_at_unix_nano":
+			out.SentAt = int64(in.Int64())
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonE9463c14EncodeGithubComKaldunTechGrpcRestBenchmarkPkgRestapi(out *jwriter.Writer, in TransactionEvent) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"tx_id\":"
+		out.RawString(prefix[1:])
+		out.String(string(in.TxID))
+	}
+	{
+		const prefix string = ",\"from\":"
+		out.RawString(prefix)
+		out.String(string(in.From))
+	}
+	{
+		const prefix string = ",\"to\":"
+		out.RawString(prefix)
+		out.String(string(in.To))
+	}
+	{
+		const prefix string = ",\"amount\":"
+		out.RawString(prefix)
+		out.Int64(int64(in.Amount))
+	}
+	{
+		const prefix string = ",\"type\":"
+		out.RawString(prefix)
+		out.String(string(in.Type))
+	}
+	{
+		const prefix string = ",\"timestamp\":"
+		out.RawString(prefix)
+		out.String(string(in.Timestamp))
+	}
+	if in.SentAt != 0 {
+		const prefix string = ",\"sent_at_unix_nano\":"
+		out.RawString(prefix)
+		out.Int64(int64(in.SentAt))
+	}
+	out.RawByte('}')
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v TransactionEvent) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonE9463c14EncodeGithubComKaldunTechGrpcRestBenchmarkPkgRestapi(w, v)
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *TransactionEvent) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonE9463c14DecodeGithubComKaldunTechGrpcRestBenchmarkPkgRestapi(l, v)
+}
+func easyjsonE9463c14DecodeGithubComKaldunTechGrpcRestBenchmarkPkgRestapi1(in *jlexer.Lexer, out *SubmitTransactionRequest) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "from":
+			out.From = string(in.String())
+		case "to":
+			out.To = string(in.String())
+		case "amount":
+			out.Amount = int64(in.Int64())
+		case "type":
+			out.Type = string(in.String())
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonE9463c14EncodeGithubComKaldunTechGrpcRestBenchmarkPkgRestapi1(out *jwriter.Writer, in SubmitTransactionRequest) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"from\":"
+		out.RawString(prefix[1:])
+		out.String(string(in.From))
+	}
+	{
+		const prefix string = ",\"to\":"
+		out.RawString(prefix)
+		out.String(string(in.To))
+	}
+	{
+		const prefix string = ",\"amount\":"
+		out.RawString(prefix)
+		out.Int64(int64(in.Amount))
+	}
+	if in.Type != "" {
+		const prefix string = ",\"type\":"
+		out.RawString(prefix)
+		out.String(string(in.Type))
+	}
+	out.RawByte('}')
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v SubmitTransactionRequest) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonE9463c14EncodeGithubComKaldunTechGrpcRestBenchmarkPkgRestapi1(w, v)
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *SubmitTransactionRequest) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonE9463c14DecodeGithubComKaldunTechGrpcRestBenchmarkPkgRestapi1(l, v)
+}
+func easyjsonE9463c14DecodeGithubComKaldunTechGrpcRestBenchmarkPkgRestapi2(in *jlexer.Lexer, out *StreamDone) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "sent":
+			out.Sent = int64(in.Int64())
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonE9463c14EncodeGithubComKaldunTechGrpcRestBenchmarkPkgRestapi2(out *jwriter.Writer, in StreamDone) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"sent\":"
+		out.RawString(prefix[1:])
+		out.Int64(int64(in.Sent))
+	}
+	out.RawByte('}')
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v StreamDone) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonE9463c14EncodeGithubComKaldunTechGrpcRestBenchmarkPkgRestapi2(w, v)
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *StreamDone) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonE9463c14DecodeGithubComKaldunTechGrpcRestBenchmarkPkgRestapi2(l, v)
+}
+func easyjsonE9463c14DecodeGithubComKaldunTechGrpcRestBenchmarkPkgRestapi3(in *jlexer.Lexer, out *ParityTransaction) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "txId":
+			out.TxID = string(in.String())
+		case "fromAccount":
+			out.FromAccount = string(in.String())
+		case "toAccount":
+			out.ToAccount = string(in.String())
+		case "amountTinybar":
+			out.AmountTinybar = int64(in.Int64Str())
+		case "txType":
+			out.TxType = string(in.String())
+		case "timestamp":
+			out.Timestamp = string(in.String())
+		case "sentAtUnixNano":
+			out.SentAtUnixNano = int64(in.Int64Str())
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonE9463c14EncodeGithubComKaldunTechGrpcRestBenchmarkPkgRestapi3(out *jwriter.Writer, in ParityTransaction) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	if in.TxID != "" {
+		const prefix string = ",\"txId\":"
+		first = false
+		out.RawString(prefix[1:])
+		out.String(string(in.TxID))
+	}
+	if in.FromAccount != "" {
+		const prefix string = ",\"fromAccount\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.String(string(in.FromAccount))
+	}
+	if in.ToAccount != "" {
+		const prefix string = ",\"toAccount\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.String(string(in.ToAccount))
+	}
+	if in.AmountTinybar != 0 {
+		const prefix string = ",\"amountTinybar\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.Int64Str(int64(in.AmountTinybar))
+	}
+	if in.TxType != "" {
+		const prefix string = ",\"txType\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.String(string(in.TxType))
+	}
+	if in.Timestamp != "" {
+		const prefix string = ",\"timestamp\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.String(string(in.Timestamp))
+	}
+	if in.SentAtUnixNano != 0 {
+		const prefix string = ",\"sentAtUnixNano\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.Int64Str(int64(in.SentAtUnixNano))
+	}
+	out.RawByte('}')
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v ParityTransaction) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonE9463c14EncodeGithubComKaldunTechGrpcRestBenchmarkPkgRestapi3(w, v)
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *ParityTransaction) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonE9463c14DecodeGithubComKaldunTechGrpcRestBenchmarkPkgRestapi3(l, v)
+}
+func easyjsonE9463c14DecodeGithubComKaldunTechGrpcRestBenchmarkPkgRestapi4(in *jlexer.Lexer, out *ParityEchoResponse) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "payload":
+			if in.IsNull() {
+				in.Skip()
+				out.Payload = nil
+			} else {
+				out.Payload = in.Bytes()
+			}
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonE9463c14EncodeGithubComKaldunTechGrpcRestBenchmarkPkgRestapi4(out *jwriter.Writer, in ParityEchoResponse) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	if len(in.Payload) != 0 {
+		const prefix string = ",\"payload\":"
+		first = false
+		out.RawString(prefix[1:])
+		out.Base64Bytes(in.Payload)
+	}
+	out.RawByte('}')
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v ParityEchoResponse) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonE9463c14EncodeGithubComKaldunTechGrpcRestBenchmarkPkgRestapi4(w, v)
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *ParityEchoResponse) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonE9463c14DecodeGithubComKaldunTechGrpcRestBenchmarkPkgRestapi4(l, v)
+}
+func easyjsonE9463c14DecodeGithubComKaldunTechGrpcRestBenchmarkPkgRestapi5(in *jlexer.Lexer, out *ParityBatchBalanceResponse) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "balances":
+			if in.IsNull() {
+				in.Skip()
+				out.Balances = nil
+			} else {
+				in.Delim('[')
+				if out.Balances == nil {
+					if !in.IsDelim(']') {
+						out.Balances = make([]ParityBalanceResponse, 0, 1)
+					} else {
+						out.Balances = []ParityBalanceResponse{}
+					}
+				} else {
+					out.Balances = (out.Balances)[:0]
+				}
+				for !in.IsDelim(']') {
+					var v4 ParityBalanceResponse
+					(v4).UnmarshalEasyJSON(in)
+					out.Balances = append(out.Balances, v4)
+					in.WantComma()
+				}
+				in.Delim(']')
+			}
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonE9463c14EncodeGithubComKaldunTechGrpcRestBenchmarkPkgRestapi5(out *jwriter.Writer, in ParityBatchBalanceResponse) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	if len(in.Balances) != 0 {
+		const prefix string = ",\"balances\":"
+		first = false
+		out.RawString(prefix[1:])
+		{
+			out.RawByte('[')
+			for v5, v6 := range in.Balances {
+				if v5 > 0 {
+					out.RawByte(',')
+				}
+				(v6).MarshalEasyJSON(out)
+			}
+			out.RawByte(']')
+		}
+	}
+	out.RawByte('}')
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v ParityBatchBalanceResponse) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonE9463c14EncodeGithubComKaldunTechGrpcRestBenchmarkPkgRestapi5(w, v)
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *ParityBatchBalanceResponse) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonE9463c14DecodeGithubComKaldunTechGrpcRestBenchmarkPkgRestapi5(l, v)
+}
+func easyjsonE9463c14DecodeGithubComKaldunTechGrpcRestBenchmarkPkgRestapi6(in *jlexer.Lexer, out *ParityBalanceResponse) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "accountId":
+			out.AccountID = string(in.String())
+		case "balanceTinybar":
+			out.BalanceTinybar = int64(in.Int64Str())
+		case "timestamp":
+			out.Timestamp = string(in.String())
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonE9463c14EncodeGithubComKaldunTechGrpcRestBenchmarkPkgRestapi6(out *jwriter.Writer, in ParityBalanceResponse) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	if in.AccountID != "" {
+		const prefix string = ",\"accountId\":"
+		first = false
+		out.RawString(prefix[1:])
+		out.String(string(in.AccountID))
+	}
+	if in.BalanceTinybar != 0 {
+		const prefix string = ",\"balanceTinybar\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.Int64Str(int64(in.BalanceTinybar))
+	}
+	if in.Timestamp != "" {
+		const prefix string = ",\"timestamp\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.String(string(in.Timestamp))
+	}
+	out.RawByte('}')
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v ParityBalanceResponse) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonE9463c14EncodeGithubComKaldunTechGrpcRestBenchmarkPkgRestapi6(w, v)
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *ParityBalanceResponse) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonE9463c14DecodeGithubComKaldunTechGrpcRestBenchmarkPkgRestapi6(l, v)
+}
+func easyjsonE9463c14DecodeGithubComKaldunTechGrpcRestBenchmarkPkgRestapi7(in *jlexer.Lexer, out *ErrorResponse) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "error":
+			out.Error = string(in.String())
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonE9463c14EncodeGithubComKaldunTechGrpcRestBenchmarkPkgRestapi7(out *jwriter.Writer, in ErrorResponse) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"error\":"
+		out.RawString(prefix[1:])
+		out.String(string(in.Error))
+	}
+	out.RawByte('}')
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v ErrorResponse) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonE9463c14EncodeGithubComKaldunTechGrpcRestBenchmarkPkgRestapi7(w, v)
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *ErrorResponse) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonE9463c14DecodeGithubComKaldunTechGrpcRestBenchmarkPkgRestapi7(l, v)
+}
+func easyjsonE9463c14DecodeGithubComKaldunTechGrpcRestBenchmarkPkgRestapi8(in *jlexer.Lexer, out *EchoResponse) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "payload":
+			if in.IsNull() {
+				in.Skip()
+				out.Payload = nil
+			} else {
+				out.Payload = in.Bytes()
+			}
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonE9463c14EncodeGithubComKaldunTechGrpcRestBenchmarkPkgRestapi8(out *jwriter.Writer, in EchoResponse) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"payload\":"
+		out.RawString(prefix[1:])
+		out.Base64Bytes(in.Payload)
+	}
+	out.RawByte('}')
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v EchoResponse) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonE9463c14EncodeGithubComKaldunTechGrpcRestBenchmarkPkgRestapi8(w, v)
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *EchoResponse) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonE9463c14DecodeGithubComKaldunTechGrpcRestBenchmarkPkgRestapi8(l, v)
+}
+func easyjsonE9463c14DecodeGithubComKaldunTechGrpcRestBenchmarkPkgRestapi9(in *jlexer.Lexer, out *BatchBalanceResponse) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "balances":
+			if in.IsNull() {
+				in.Skip()
+				out.Balances = nil
+			} else {
+				in.Delim('[')
+				if out.Balances == nil {
+					if !in.IsDelim(']') {
+						out.Balances = make([]BalanceResponse, 0, 1)
+					} else {
+						out.Balances = []BalanceResponse{}
+					}
+				} else {
+					out.Balances = (out.Balances)[:0]
+				}
+				for !in.IsDelim(']') {
+					var v10 BalanceResponse
+					(v10).UnmarshalEasyJSON(in)
+					out.Balances = append(out.Balances, v10)
+					in.WantComma()
+				}
+				in.Delim(']')
+			}
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonE9463c14EncodeGithubComKaldunTechGrpcRestBenchmarkPkgRestapi9(out *jwriter.Writer, in BatchBalanceResponse) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"balances\":"
+		out.RawString(prefix[1:])
+		if in.Balances == nil && (out.Flags&jwriter.NilSliceAsEmpty) == 0 {
+			out.RawString("null")
+		} else {
+			out.RawByte('[')
+			for v11, v12 := range in.Balances {
+				if v11 > 0 {
+					out.RawByte(',')
+				}
+				(v12).MarshalEasyJSON(out)
+			}
+			out.RawByte(']')
+		}
+	}
+	out.RawByte('}')
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v BatchBalanceResponse) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonE9463c14EncodeGithubComKaldunTechGrpcRestBenchmarkPkgRestapi9(w, v)
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *BatchBalanceResponse) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonE9463c14DecodeGithubComKaldunTechGrpcRestBenchmarkPkgRestapi9(l, v)
+}
+func easyjsonE9463c14DecodeGithubComKaldunTechGrpcRestBenchmarkPkgRestapi10(in *jlexer.Lexer, out *BalanceResponse) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "account":
+			out.Account = string(in.String())
+		case "balance":
+			out.Balance = int64(in.Int64())
+		case "timestamp":
+			out.Timestamp = string(in.String())
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonE9463c14EncodeGithubComKaldunTechGrpcRestBenchmarkPkgRestapi10(out *jwriter.Writer, in BalanceResponse) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"account\":"
+		out.RawString(prefix[1:])
+		out.String(string(in.Account))
+	}
+	{
+		const prefix string = ",\"balance\":"
+		out.RawString(prefix)
+		out.Int64(int64(in.Balance))
+	}
+	{
+		const prefix string = ",\"timestamp\":"
+		out.RawString(prefix)
+		out.String(string(in.Timestamp))
+	}
+	out.RawByte('}')
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v BalanceResponse) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonE9463c14EncodeGithubComKaldunTechGrpcRestBenchmarkPkgRestapi10(w, v)
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *BalanceResponse) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonE9463c14DecodeGithubComKaldunTechGrpcRestBenchmarkPkgRestapi10(l, v)
+}
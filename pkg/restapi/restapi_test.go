@@ -0,0 +1,63 @@
+package restapi
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/kaldun-tech/grpc-rest-benchmark/pkg/protos"
+)
+
+func TestParityTypes_MatchProtoJSON(t *testing.T) {
+	tests := []struct {
+		msg    proto.Message
+		parity any
+	}{
+		{
+			&protos.BalanceResponse{AccountId: "0.0.1001", BalanceTinybar: 500, Timestamp: "2026-10-18T12:00:00Z"},
+			ParityBalanceResponse{AccountID: "0.0.1001", BalanceTinybar: 500, Timestamp: "2026-10-18T12:00:00Z"},
+		},
+		{
+			&protos.BatchBalanceResponse{Balances: []*protos.BalanceResponse{{AccountId: "0.0.1001"}, {BalanceTinybar: -1}}},
+			ParityBatchBalanceResponse{Balances: []ParityBalanceResponse{{AccountID: "0.0.1001"}, {BalanceTinybar: -1}}},
+		},
+		{&protos.BatchBalanceResponse{}, ParityBatchBalanceResponse{}},
+		{
+			&protos.Transaction{TxId: "0.0.1001@1700000000.000000001", FromAccount: "0.0.1001", ToAccount: "0.0.1002", AmountTinybar: 500, TxType: "transfer", Timestamp: "2026-10-18T12:00:00.5Z", SentAtUnixNano: 1_700_000_000_123_456_789},
+			ParityTransaction{TxID: "0.0.1001@1700000000.000000001", FromAccount: "0.0.1001", ToAccount: "0.0.1002", AmountTinybar: 500, TxType: "transfer", Timestamp: "2026-10-18T12:00:00.5Z", SentAtUnixNano: 1_700_000_000_123_456_789},
+		},
+		{&protos.EchoResponse{Payload: []byte{0, 1, 0xff}}, ParityEchoResponse{Payload: []byte{0, 1, 0xff}}},
+	}
+	for _, tt := range tests {
+		want, err := protojson.Marshal(tt.msg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := json.Marshal(tt.parity)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// protojson varies its whitespace, so compare the decoded values
+		var wantValue, gotValue any
+		json.Unmarshal(want, &wantValue)
+		json.Unmarshal(got, &gotValue)
+		if !reflect.DeepEqual(gotValue, wantValue) {
+			t.Errorf("%T encodes as %s, protojson as %s", tt.parity, got, want)
+		}
+	}
+}
+
+func TestStreamFormatOf(t *testing.T) {
+	for _, format := range StreamFormats {
+		if got := StreamFormatOf(StreamContentType(format)); got != format {
+			t.Errorf("StreamFormatOf(%q) = %q, want %q", StreamContentType(format), got, format)
+		}
+	}
+	if got := StreamFormatOf("application/x-protobuf"); got != "" {
+		t.Errorf("StreamFormatOf(application/x-protobuf) = %q, want none", got)
+	}
+}
@@ -0,0 +1,74 @@
+// Package restart restarts a server in-process on request, the way a
+// rolling deploy replaces it: the server stops accepting connections,
+// drains the requests in flight, stays down for a while and listens again
+// on the same ports. The benchmark triggers it through the servers' admin
+// endpoints (run --chaos-restart-server) to measure how many requests each
+// protocol fails during a restart and how long its clients take to recover.
+package restart
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+const (
+	// MaxDowntime bounds how long a restart may keep a server down.
+	MaxDowntime = time.Minute
+
+	// DrainTimeout is how long a restarting server waits for requests and
+	// streams in flight to finish before it closes their connections.
+	DrainTimeout = 5 * time.Second
+)
+
+// ErrPending is returned when a restart is requested while another one
+// has not started yet.
+var ErrPending = errors.New("a restart is already pending")
+
+// Trigger passes restart requests from the admin endpoints to the serve
+// loop of a server. It is safe for concurrent use.
+type Trigger struct {
+	ch chan time.Duration
+}
+
+// NewTrigger creates a trigger without a pending restart.
+func NewTrigger() *Trigger {
+	return &Trigger{ch: make(chan time.Duration, 1)}
+}
+
+// Restart requests a restart that keeps the server down for downtime once
+// it has drained. It returns without waiting for the restart, so that the
+// request triggering it can complete first.
+func (t *Trigger) Restart(downtime time.Duration) error {
+	if downtime < 0 || downtime > MaxDowntime {
+		return fmt.Errorf("downtime must be between 0 and %s", MaxDowntime)
+	}
+	select {
+	case t.ch <- downtime:
+		return nil
+	default:
+		return ErrPending
+	}
+}
+
+// C delivers the downtime of each requested restart.
+func (t *Trigger) C() <-chan time.Duration {
+	return t.ch
+}
+
+// Drain runs graceful, which stops a server once its requests in flight
+// are done, and runs force if that takes longer than timeout. It returns
+// once graceful has returned.
+func Drain(timeout time.Duration, graceful, force func()) {
+	done := make(chan struct{})
+	go func() {
+		graceful()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(timeout):
+		force()
+		<-done
+	}
+}
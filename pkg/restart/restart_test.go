@@ -0,0 +1,43 @@
+package restart
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestTrigger_Restart(t *testing.T) {
+	trigger := NewTrigger()
+	for _, bad := range []time.Duration{-time.Second, MaxDowntime + time.Second} {
+		if err := trigger.Restart(bad); err == nil {
+			t.Errorf("Restart(%s) = nil, want an error", bad)
+		}
+	}
+
+	if err := trigger.Restart(2 * time.Second); err != nil {
+		t.Fatalf("Restart() error = %v", err)
+	}
+	if err := trigger.Restart(time.Second); !errors.Is(err, ErrPending) {
+		t.Errorf("second Restart() error = %v, want ErrPending", err)
+	}
+	if got := <-trigger.C(); got != 2*time.Second {
+		t.Errorf("C() delivered %s, want 2s", got)
+	}
+	if err := trigger.Restart(time.Second); err != nil {
+		t.Errorf("Restart() after the pending one started: %v", err)
+	}
+}
+
+func TestDrain(t *testing.T) {
+	forced := false
+	Drain(time.Second, func() {}, func() { forced = true })
+	if forced {
+		t.Error("Drain forced a stop that completed in time")
+	}
+
+	stop := make(chan struct{})
+	Drain(10*time.Millisecond, func() { <-stop }, func() { forced = true; close(stop) })
+	if !forced {
+		t.Error("Drain did not force a stop that outlasted the timeout")
+	}
+}
@@ -0,0 +1,328 @@
+// Package results is a Go client for the REST server's results API, for CI
+// scripts and tools that consume stored benchmark runs without raw HTTP
+// calls or SQL.
+//
+//	c := results.NewClient("http://localhost:8080", nil)
+//	runs, err := c.Runs(ctx, results.Filter{Scenario: "balance", Limit: 10})
+//	cmp, err := c.Compare(ctx, runs[1].RunID, runs[0].RunID)
+package results
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// ErrRunNotFound is returned when a requested run is not stored.
+var ErrRunNotFound = errors.New("run not found")
+
+// APIError is a non-2xx response from the results API.
+type APIError struct {
+	StatusCode int
+	Message    string // the API's error message, or the status text
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("results API: %d %s", e.StatusCode, e.Message)
+}
+
+// Filter selects runs. Zero fields match every run.
+type Filter struct {
+	Scenario     string
+	Protocol     string
+	Client       string // client implementation, e.g. "go" or "python"
+	ComparisonID string
+	SuiteID      string // only the runs of one `benchmark run --config` suite
+	Environment  string // only the runs of one registered environment
+	RunID        int64
+	Experiment   int64             // only the runs of this experiment
+	Labels       map[string]string // only runs with all of these labels
+	Limit        int               // newest runs to return, 0 for the API default
+}
+
+func (f Filter) values() url.Values {
+	v := url.Values{}
+	set := func(key, value string) {
+		if value != "" {
+			v.Set(key, value)
+		}
+	}
+	set("scenario", f.Scenario)
+	set("protocol", f.Protocol)
+	set("client", f.Client)
+	set("comparison_id", f.ComparisonID)
+	set("suite_id", f.SuiteID)
+	set("environment", f.Environment)
+	if f.RunID > 0 {
+		v.Set("run_id", strconv.FormatInt(f.RunID, 10))
+	}
+	if f.Experiment > 0 {
+		v.Set("experiment", strconv.FormatInt(f.Experiment, 10))
+	}
+	for _, k := range slices.Sorted(maps.Keys(f.Labels)) {
+		v.Add("label", k+"="+f.Labels[k])
+	}
+	if f.Limit > 0 {
+		v.Set("limit", strconv.Itoa(f.Limit))
+	}
+	return v
+}
+
+// TrendQuery selects the history returned by Trends. Zero fields use the
+// API defaults: p99 over 90 days in 90 points.
+type TrendQuery struct {
+	Scenario string
+	Protocol string
+	Client   string
+	Metric   string // p50, p90, p99, avg, throughput or error_rate
+	Window   string // e.g. "90d", "4w" or "12h"
+	Points   int
+}
+
+// EventQuery selects the events returned by Events. Zero fields use the
+// API defaults: every kind over 90 days.
+type EventQuery struct {
+	Kind   string // deploy, config, reseed or note
+	Window string // e.g. "90d", "4w" or "12h"
+	Limit  int    // newest events to return, 0 for the API default
+}
+
+// Client queries a REST server's results API. It is safe for concurrent
+// use.
+type Client struct {
+	baseURL    string
+	httpClient *http.Client
+}
+
+// NewClient returns a client for the REST server at baseURL, e.g.
+// "http://localhost:8080". A nil httpClient uses http.DefaultClient.
+func NewClient(baseURL string, httpClient *http.Client) *Client {
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	return &Client{baseURL: strings.TrimSuffix(baseURL, "/"), httpClient: httpClient}
+}
+
+// Runs returns the runs matching f, newest first.
+func (c *Client) Runs(ctx context.Context, f Filter) ([]Run, error) {
+	var resp struct {
+		Results []Run `json:"results"`
+	}
+	if err := c.get(ctx, "/api/v1/results", f.values(), &resp); err != nil {
+		return nil, err
+	}
+	return resp.Results, nil
+}
+
+// Run returns the stored run id, or ErrRunNotFound.
+func (c *Client) Run(ctx context.Context, id int64) (*Run, error) {
+	runs, err := c.Runs(ctx, Filter{RunID: id, Limit: 1})
+	if err != nil {
+		return nil, err
+	}
+	if len(runs) == 0 {
+		return nil, fmt.Errorf("run %d: %w", id, ErrRunNotFound)
+	}
+	return &runs[0], nil
+}
+
+// UpdateRun changes the labels and notes of run id and returns the updated
+// run. A 404 *APIError means the run is not stored.
+func (c *Client) UpdateRun(ctx context.Context, id int64, m RunMetadata) (*Run, error) {
+	var run Run
+	if err := c.do(ctx, http.MethodPatch, fmt.Sprintf("/api/v1/results/%d", id), nil, m, &run); err != nil {
+		return nil, err
+	}
+	return &run, nil
+}
+
+// DeleteRun deletes run id with its samples. A 404 *APIError means the run
+// is not stored.
+func (c *Client) DeleteRun(ctx context.Context, id int64) error {
+	return c.do(ctx, http.MethodDelete, fmt.Sprintf("/api/v1/results/%d", id), nil, nil, nil)
+}
+
+// ConcurrencyGroups returns the runs matching f averaged per configuration
+// and concurrency level.
+func (c *Client) ConcurrencyGroups(ctx context.Context, f Filter) ([]ConcurrencyGroup, error) {
+	v := f.values()
+	v.Set("group_by", "concurrency")
+	var resp struct {
+		Groups []ConcurrencyGroup `json:"groups"`
+	}
+	if err := c.get(ctx, "/api/v1/results", v, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Groups, nil
+}
+
+// Timeseries returns the per-second timeseries of run id.
+func (c *Client) Timeseries(ctx context.Context, id int64) ([]TimeseriesPoint, error) {
+	var resp Timeseries
+	if err := c.get(ctx, fmt.Sprintf("/api/v1/results/%d/timeseries", id), nil, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Points, nil
+}
+
+// Trends returns the downsampled history of a metric per configuration.
+func (c *Client) Trends(ctx context.Context, q TrendQuery) (*Trends, error) {
+	v := Filter{Scenario: q.Scenario, Protocol: q.Protocol, Client: q.Client}.values()
+	if q.Metric != "" {
+		v.Set("metric", q.Metric)
+	}
+	if q.Window != "" {
+		v.Set("window", q.Window)
+	}
+	if q.Points > 0 {
+		v.Set("points", strconv.Itoa(q.Points))
+	}
+	var resp Trends
+	if err := c.get(ctx, "/api/v1/trends", v, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// Events returns the operational events matching q, oldest first.
+func (c *Client) Events(ctx context.Context, q EventQuery) ([]Event, error) {
+	v := url.Values{}
+	if q.Kind != "" {
+		v.Set("kind", q.Kind)
+	}
+	if q.Window != "" {
+		v.Set("window", q.Window)
+	}
+	if q.Limit > 0 {
+		v.Set("limit", strconv.Itoa(q.Limit))
+	}
+	var resp Events
+	if err := c.get(ctx, "/api/v1/events", v, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Events, nil
+}
+
+// Profiles returns the pprof profiles attached to a run.
+func (c *Client) Profiles(ctx context.Context, runID int64) ([]Profile, error) {
+	var resp Profiles
+	if err := c.get(ctx, "/api/v1/results/"+strconv.FormatInt(runID, 10)+"/profiles", nil, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Profiles, nil
+}
+
+// Agents returns the registered load generation agents, only the healthy
+// ones if healthy, ordered by name.
+func (c *Client) Agents(ctx context.Context, healthy bool) ([]Agent, error) {
+	v := url.Values{}
+	if healthy {
+		v.Set("healthy", "true")
+	}
+	var resp Agents
+	if err := c.get(ctx, "/api/v1/agents", v, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Agents, nil
+}
+
+// RegisterAgent registers a load generation agent, or updates the one
+// registered under the same name, and returns it as stored.
+func (c *Client) RegisterAgent(ctx context.Context, reg AgentRegistration) (*Agent, error) {
+	var agent Agent
+	if err := c.do(ctx, http.MethodPost, "/api/v1/agents", nil, reg, &agent); err != nil {
+		return nil, err
+	}
+	return &agent, nil
+}
+
+// Heartbeat records a heartbeat of the registered agent name. A 404
+// *APIError means the agent is no longer registered.
+func (c *Client) Heartbeat(ctx context.Context, name string) error {
+	return c.do(ctx, http.MethodPost, "/api/v1/agents/"+url.PathEscape(name)+"/heartbeat", nil, nil, nil)
+}
+
+// Environments returns the registered target environments, ordered by
+// name.
+func (c *Client) Environments(ctx context.Context) ([]Environment, error) {
+	var resp Environments
+	if err := c.get(ctx, "/api/v1/environments", nil, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Environments, nil
+}
+
+// Environment returns the registered target environment name. A 404
+// *APIError means there is none.
+func (c *Client) Environment(ctx context.Context, name string) (*Environment, error) {
+	var env Environment
+	if err := c.get(ctx, "/api/v1/environments/"+url.PathEscape(name), nil, &env); err != nil {
+		return nil, err
+	}
+	return &env, nil
+}
+
+// get fetches path with the query values and decodes the JSON response
+// into out.
+func (c *Client) get(ctx context.Context, path string, query url.Values, out any) error {
+	return c.do(ctx, http.MethodGet, path, query, nil, out)
+}
+
+// do sends a request to path with the query values and body, if not nil,
+// encoded as JSON, and decodes the JSON response into out, if not nil.
+// Error responses are returned as *APIError.
+func (c *Client) do(ctx context.Context, method, path string, query url.Values, body, out any) error {
+	u := c.baseURL + path
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+	var reqBody io.Reader
+	if body != nil {
+		b, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reqBody = bytes.NewReader(b)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, u, reqBody)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("results API: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		apiErr := &APIError{StatusCode: resp.StatusCode, Message: http.StatusText(resp.StatusCode)}
+		var body struct {
+			Error string `json:"error"`
+		}
+		if json.NewDecoder(resp.Body).Decode(&body) == nil && body.Error != "" {
+			apiErr.Message = body.Error
+		}
+		return apiErr
+	}
+	if out == nil {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("results API: failed to decode %s: %w", path, err)
+	}
+	return nil
+}
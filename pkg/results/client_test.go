@@ -0,0 +1,336 @@
+package results
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// testRuns are served by newTestServer, newest first.
+var testRuns = []Run{
+	{RunID: 2, Scenario: "balance", Protocol: "grpc", Client: "go", Concurrency: 10, DurationSec: 10,
+		TotalSamples: 1000, Successful: 990, Throughput: 110, P50Latency: 1.5, P99Latency: 6},
+	{RunID: 1, Scenario: "balance", Protocol: "grpc", Client: "go", Concurrency: 10, DurationSec: 10,
+		TotalSamples: 1000, Successful: 1000, Throughput: 100, P50Latency: 2, P99Latency: 4},
+}
+
+// newTestServer serves testRuns from a minimal results API, recording the
+// query of the last request.
+func newTestServer(t *testing.T, lastQuery *string) *Client {
+	t.Helper()
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/results", func(w http.ResponseWriter, r *http.Request) {
+		*lastQuery = r.URL.RawQuery
+		runs := []Run{}
+		for _, run := range testRuns {
+			if id := r.URL.Query().Get("run_id"); id == "" || id == strconv.FormatInt(run.RunID, 10) {
+				runs = append(runs, run)
+			}
+		}
+		json.NewEncoder(w).Encode(map[string]any{"results": runs, "count": len(runs)})
+	})
+	mux.HandleFunc("/api/v1/results/2", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodPatch:
+			var m RunMetadata
+			json.NewDecoder(r.Body).Decode(&m)
+			run := testRuns[0]
+			run.Labels = map[string]string{}
+			for k, v := range m.Labels {
+				if v != nil {
+					run.Labels[k] = *v
+				}
+			}
+			run.Notes = m.Notes
+			json.NewEncoder(w).Encode(run)
+		case http.MethodDelete:
+			w.WriteHeader(http.StatusNoContent)
+		}
+	})
+	mux.HandleFunc("/api/v1/results/2/timeseries", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"run_id":2,"points":[{"elapsed_sec":0,"requests":100,"errors":1}]}`))
+	})
+	mux.HandleFunc("/api/v1/trends", func(w http.ResponseWriter, r *http.Request) {
+		*lastQuery = r.URL.RawQuery
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"error":"Invalid window: 0d"}`))
+	})
+	mux.HandleFunc("/api/v1/events", func(w http.ResponseWriter, r *http.Request) {
+		*lastQuery = r.URL.RawQuery
+		w.Write([]byte(`{"events":[{"id":7,"kind":"deploy","message":"rest-server v1.4","occurred_at":"2026-03-02T10:00:00Z","created_at":"2026-03-02T10:05:00Z"}],"count":1}`))
+	})
+	mux.HandleFunc("/api/v1/agents", func(w http.ResponseWriter, r *http.Request) {
+		*lastQuery = r.URL.RawQuery
+		if r.Method == http.MethodPost {
+			var reg AgentRegistration
+			json.NewDecoder(r.Body).Decode(&reg)
+			w.WriteHeader(http.StatusCreated)
+			json.NewEncoder(w).Encode(Agent{Name: reg.Name, Address: reg.Address, Protocols: reg.Protocols, CPUs: reg.CPUs, Healthy: true})
+			return
+		}
+		w.Write([]byte(`{"agents":[{"name":"east","address":"east:50070","protocols":["grpc"],"cpus":8,"healthy":true}],"count":1}`))
+	})
+	mux.HandleFunc("/api/v1/agents/east/heartbeat", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+	mux.HandleFunc("/api/v1/results/2/profiles", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"profiles":[{"name":"client-cpu","size":1200},{"name":"server-cpu","size":3400}],"count":2}`))
+	})
+	mux.HandleFunc("/api/v1/environments", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"environments":[{"name":"staging","grpc_addr":"staging:50051","rest_addr":"http://staging:8080"}],"count":1}`))
+	})
+	mux.HandleFunc("/api/v1/environments/staging", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"name":"staging","grpc_addr":"staging:50051","rest_addr":"http://staging:8080","hardware":"c6i.2xlarge"}`))
+	})
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	return NewClient(srv.URL+"/", srv.Client())
+}
+
+func TestClient_Runs(t *testing.T) {
+	var query string
+	c := newTestServer(t, &query)
+
+	runs, err := c.Runs(context.Background(), Filter{Scenario: "balance", Protocol: "grpc", Limit: 5})
+	if err != nil {
+		t.Fatalf("Runs() error = %v", err)
+	}
+	if len(runs) != 2 || runs[0].RunID != 2 {
+		t.Errorf("Runs() = %+v, want the two test runs, newest first", runs)
+	}
+	if want := "limit=5&protocol=grpc&scenario=balance"; query != want {
+		t.Errorf("query = %q, want %q", query, want)
+	}
+}
+
+func TestClient_Run(t *testing.T) {
+	var query string
+	c := newTestServer(t, &query)
+
+	run, err := c.Run(context.Background(), 1)
+	if err != nil || run.RunID != 1 {
+		t.Fatalf("Run(1) = %+v, %v; want run 1", run, err)
+	}
+	if _, err := c.Run(context.Background(), 99); !errors.Is(err, ErrRunNotFound) {
+		t.Errorf("Run(99) error = %v, want ErrRunNotFound", err)
+	}
+}
+
+func TestClient_Compare(t *testing.T) {
+	var query string
+	c := newTestServer(t, &query)
+
+	cmp, err := c.Compare(context.Background(), 1, 2)
+	if err != nil {
+		t.Fatalf("Compare() error = %v", err)
+	}
+	for name, tt := range map[string]struct {
+		got  *float64
+		want float64
+	}{
+		"p50":        {cmp.P50DeltaPct, -25},
+		"p99":        {cmp.P99DeltaPct, 50},
+		"throughput": {cmp.ThroughputDeltaPct, 10},
+	} {
+		if tt.got == nil || *tt.got != tt.want {
+			t.Errorf("%s delta = %v, want %v", name, tt.got, tt.want)
+		}
+	}
+	if got := cmp.Run.ErrorRate(); got != 0.01 {
+		t.Errorf("ErrorRate() = %v, want 0.01", got)
+	}
+}
+
+func TestClient_Timeseries(t *testing.T) {
+	var query string
+	c := newTestServer(t, &query)
+
+	points, err := c.Timeseries(context.Background(), 2)
+	if err != nil {
+		t.Fatalf("Timeseries() error = %v", err)
+	}
+	if len(points) != 1 || points[0].Requests != 100 || points[0].Errors != 1 {
+		t.Errorf("Timeseries() = %+v, want one point of 100 requests and 1 error", points)
+	}
+}
+
+func TestClient_Events(t *testing.T) {
+	var query string
+	c := newTestServer(t, &query)
+
+	events, err := c.Events(context.Background(), EventQuery{Kind: "deploy", Window: "4w"})
+	if err != nil {
+		t.Fatalf("Events() error = %v", err)
+	}
+	if len(events) != 1 || events[0].Kind != "deploy" || events[0].OccurredAt.Hour() != 10 {
+		t.Errorf("Events() = %+v, want the deploy at 10:00", events)
+	}
+	if want := "kind=deploy&window=4w"; query != want {
+		t.Errorf("query = %q, want %q", query, want)
+	}
+}
+
+func TestClient_UpdateAndDeleteRun(t *testing.T) {
+	var query string
+	c := newTestServer(t, &query)
+	ctx := context.Background()
+
+	env, notes := "staging", "warm-up run"
+	run, err := c.UpdateRun(ctx, 2, RunMetadata{Labels: map[string]*string{"env": &env, "old": nil}, Notes: &notes})
+	if err != nil {
+		t.Fatalf("UpdateRun() error = %v", err)
+	}
+	if run.RunID != 2 || run.Labels["env"] != "staging" || len(run.Labels) != 1 || run.Notes == nil || *run.Notes != notes {
+		t.Errorf("UpdateRun() = %+v, want run 2 labeled env=staging with notes", run)
+	}
+
+	if err := c.DeleteRun(ctx, 2); err != nil {
+		t.Errorf("DeleteRun() error = %v", err)
+	}
+	var apiErr *APIError
+	if err := c.DeleteRun(ctx, 3); !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusNotFound {
+		t.Errorf("DeleteRun() of an unknown run error = %v, want a 404 APIError", err)
+	}
+
+	if _, err := c.Runs(ctx, Filter{Labels: map[string]string{"team": "core", "env": "staging"}}); err != nil {
+		t.Fatalf("Runs() error = %v", err)
+	}
+	if want := "label=env%3Dstaging&label=team%3Dcore"; query != want {
+		t.Errorf("query = %q, want %q", query, want)
+	}
+}
+
+func TestClient_Agents(t *testing.T) {
+	var query string
+	c := newTestServer(t, &query)
+	ctx := context.Background()
+
+	agents, err := c.Agents(ctx, true)
+	if err != nil {
+		t.Fatalf("Agents() error = %v", err)
+	}
+	if len(agents) != 1 || agents[0].Name != "east" || !agents[0].Healthy {
+		t.Errorf("Agents() = %+v, want the healthy east agent", agents)
+	}
+	if want := "healthy=true"; query != want {
+		t.Errorf("query = %q, want %q", query, want)
+	}
+
+	agent, err := c.RegisterAgent(ctx, AgentRegistration{Name: "west", Address: "west:50070", Protocols: []string{"rest"}, CPUs: 4})
+	if err != nil {
+		t.Fatalf("RegisterAgent() error = %v", err)
+	}
+	if agent.Name != "west" || agent.CPUs != 4 {
+		t.Errorf("RegisterAgent() = %+v, want the registered west agent", agent)
+	}
+
+	if err := c.Heartbeat(ctx, "east"); err != nil {
+		t.Errorf("Heartbeat() error = %v", err)
+	}
+	var apiErr *APIError
+	if err := c.Heartbeat(ctx, "west"); !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusNotFound {
+		t.Errorf("Heartbeat() of an unknown agent error = %v, want a 404 APIError", err)
+	}
+}
+
+func TestClient_Profiles(t *testing.T) {
+	var query string
+	c := newTestServer(t, &query)
+
+	profiles, err := c.Profiles(context.Background(), 2)
+	if err != nil {
+		t.Fatalf("Profiles() error = %v", err)
+	}
+	if len(profiles) != 2 || profiles[1].Name != "server-cpu" || profiles[1].Size != 3400 {
+		t.Errorf("Profiles() = %+v, want the client and server CPU profiles", profiles)
+	}
+}
+
+func TestClient_Environments(t *testing.T) {
+	var query string
+	c := newTestServer(t, &query)
+	ctx := context.Background()
+
+	envs, err := c.Environments(ctx)
+	if err != nil {
+		t.Fatalf("Environments() error = %v", err)
+	}
+	if len(envs) != 1 || envs[0].Name != "staging" || envs[0].GRPCAddr != "staging:50051" {
+		t.Errorf("Environments() = %+v, want the staging environment", envs)
+	}
+
+	env, err := c.Environment(ctx, "staging")
+	if err != nil {
+		t.Fatalf("Environment() error = %v", err)
+	}
+	if env.RESTAddr != "http://staging:8080" || env.Hardware != "c6i.2xlarge" {
+		t.Errorf("Environment() = %+v, want staging with its hardware notes", env)
+	}
+
+	var apiErr *APIError
+	if _, err := c.Environment(ctx, "prod"); !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusNotFound {
+		t.Errorf("Environment() of an unknown environment error = %v, want a 404 APIError", err)
+	}
+
+	if _, err := c.Runs(ctx, Filter{Environment: "staging"}); err != nil {
+		t.Fatalf("Runs() error = %v", err)
+	}
+	if want := "environment=staging"; query != want {
+		t.Errorf("query = %q, want %q", query, want)
+	}
+}
+
+func TestClient_APIError(t *testing.T) {
+	var query string
+	c := newTestServer(t, &query)
+
+	_, err := c.Trends(context.Background(), TrendQuery{Metric: "p50", Window: "0d"})
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("Trends() error = %v, want an *APIError", err)
+	}
+	if apiErr.StatusCode != http.StatusBadRequest || apiErr.Message != "Invalid window: 0d" {
+		t.Errorf("APIError = %+v, want 400 with the API's message", apiErr)
+	}
+	if want := "metric=p50&window=0d"; query != want {
+		t.Errorf("query = %q, want %q", query, want)
+	}
+}
+
+func TestClient_Export(t *testing.T) {
+	var query string
+	c := newTestServer(t, &query)
+	ctx := context.Background()
+
+	var buf bytes.Buffer
+	if err := c.Export(ctx, &buf, Filter{}, "csv"); err != nil {
+		t.Fatalf("Export(csv) error = %v", err)
+	}
+	records, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatalf("reading CSV: %v", err)
+	}
+	if len(records) != 3 || records[1][0] != "2" || records[1][8] != "110" {
+		t.Errorf("CSV = %v, want a header and the two runs", records)
+	}
+
+	buf.Reset()
+	if err := c.Export(ctx, &buf, Filter{RunID: 1}, "json"); err != nil {
+		t.Fatalf("Export(json) error = %v", err)
+	}
+	var runs []Run
+	if err := json.Unmarshal(buf.Bytes(), &runs); err != nil || len(runs) != 1 || runs[0].RunID != 1 {
+		t.Errorf("JSON = %s, want run 1 (%v)", buf.String(), err)
+	}
+
+	if err := c.Export(ctx, &buf, Filter{}, "xml"); err == nil || !strings.Contains(err.Error(), "invalid export format") {
+		t.Errorf("Export(xml) error = %v, want an invalid format error", err)
+	}
+}
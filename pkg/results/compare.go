@@ -0,0 +1,52 @@
+package results
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/kaldun-tech/grpc-rest-benchmark/pkg/db"
+)
+
+// Comparison diffs a run against a baseline run. Deltas are percentage
+// changes from the baseline, nil when the baseline value is zero.
+type Comparison struct {
+	Baseline Run
+	Run      Run
+
+	P50DeltaPct        *float64 // positive means slower
+	P99DeltaPct        *float64 // positive means slower
+	ThroughputDeltaPct *float64 // positive means faster
+}
+
+// Compare fetches runs baselineID and runID and diffs their latency and
+// throughput.
+func (c *Client) Compare(ctx context.Context, baselineID, runID int64) (*Comparison, error) {
+	base, err := c.Run(ctx, baselineID)
+	if err != nil {
+		return nil, fmt.Errorf("baseline: %w", err)
+	}
+	run, err := c.Run(ctx, runID)
+	if err != nil {
+		return nil, err
+	}
+	return Compare(*base, *run), nil
+}
+
+// Compare diffs run against base.
+func Compare(base, run Run) *Comparison {
+	return &Comparison{
+		Baseline:           base,
+		Run:                run,
+		P50DeltaPct:        db.PctChange(run.P50Latency, base.P50Latency),
+		P99DeltaPct:        db.PctChange(run.P99Latency, base.P99Latency),
+		ThroughputDeltaPct: db.PctChange(run.Throughput, base.Throughput),
+	}
+}
+
+// ErrorRate returns the fraction of the run's samples that failed.
+func (r Run) ErrorRate() float64 {
+	if r.TotalSamples == 0 {
+		return 0
+	}
+	return float64(r.TotalSamples-r.Successful) / float64(r.TotalSamples)
+}
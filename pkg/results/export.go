@@ -0,0 +1,92 @@
+package results
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// ExportFormats are the formats Export writes.
+var ExportFormats = []string{"json", "csv"}
+
+// csvHeader names the columns WriteCSV writes. Configuration columns are
+// empty for runs without them.
+var csvHeader = []string{
+	"run_id", "scenario", "protocol", "client", "concurrency", "duration_sec",
+	"total_samples", "successful", "throughput",
+	"p50_latency_ms", "p90_latency_ms", "p99_latency_ms", "avg_latency_ms", "min_latency_ms", "max_latency_ms",
+	"comparison_id", "suite_id", "payload_size", "compression", "load_profile",
+	"baseline_run_id", "p50_delta_pct", "p99_delta_pct", "throughput_delta_pct",
+}
+
+// Export writes the runs matching f to w as a JSON array or as CSV.
+func (c *Client) Export(ctx context.Context, w io.Writer, f Filter, format string) error {
+	var write func(io.Writer, []Run) error
+	switch format {
+	case "json":
+		write = WriteJSON
+	case "csv":
+		write = WriteCSV
+	default:
+		return fmt.Errorf("invalid export format: %s (must be one of: %s)", format, strings.Join(ExportFormats, ", "))
+	}
+
+	runs, err := c.Runs(ctx, f)
+	if err != nil {
+		return err
+	}
+	return write(w, runs)
+}
+
+// WriteJSON writes runs to w as an indented JSON array.
+func WriteJSON(w io.Writer, runs []Run) error {
+	if runs == nil {
+		runs = []Run{}
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(runs)
+}
+
+// WriteCSV writes runs to w as CSV with a header row: the run's
+// configuration, latency and throughput, and its baseline comparison.
+func WriteCSV(w io.Writer, runs []Run) error {
+	cw := csv.NewWriter(w)
+	cw.Write(csvHeader)
+	for _, r := range runs {
+		cw.Write([]string{
+			strconv.FormatInt(r.RunID, 10), r.Scenario, r.Protocol, r.Client,
+			strconv.Itoa(r.Concurrency), strconv.Itoa(r.DurationSec),
+			strconv.FormatInt(r.TotalSamples, 10), strconv.FormatInt(r.Successful, 10), formatFloat(r.Throughput),
+			formatFloat(r.P50Latency), formatFloat(r.P90Latency), formatFloat(r.P99Latency),
+			formatFloat(r.AvgLatency), formatFloat(r.MinLatency), formatFloat(r.MaxLatency),
+			optional(r.ComparisonID, func(s string) string { return s }),
+			optional(r.SuiteID, func(s string) string { return s }),
+			optional(r.PayloadSize, strconv.Itoa),
+			optional(r.Compression, func(s string) string { return s }),
+			optional(r.LoadProfile, func(s string) string { return s }),
+			optional(r.BaselineRunID, func(id int64) string { return strconv.FormatInt(id, 10) }),
+			optional(r.P50DeltaPct, formatFloat),
+			optional(r.P99DeltaPct, formatFloat),
+			optional(r.ThroughputDeltaPct, formatFloat),
+		})
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
+
+// optional formats *v, or returns "" if v is nil.
+func optional[T any](v *T, format func(T) string) string {
+	if v == nil {
+		return ""
+	}
+	return format(*v)
+}
@@ -0,0 +1,305 @@
+package results
+
+import "time"
+
+// Run is the results of one stored benchmark run.
+type Run struct {
+	RunID        int64    `json:"run_id"`
+	Scenario     string   `json:"scenario"`
+	Protocol     string   `json:"protocol"`
+	Client       string   `json:"client"`
+	Concurrency  int      `json:"concurrency"`
+	DurationSec  int      `json:"duration_sec"`
+	TotalSamples int64    `json:"total_samples"`
+	Successful   int64    `json:"successful"`
+	Throughput   float64  `json:"throughput"`
+	P50Latency   float64  `json:"p50_latency_ms"`
+	P90Latency   float64  `json:"p90_latency_ms"`
+	P99Latency   float64  `json:"p99_latency_ms"`
+	AvgLatency   float64  `json:"avg_latency_ms"`
+	MinLatency   float64  `json:"min_latency_ms"`
+	MaxLatency   float64  `json:"max_latency_ms"`
+	CPUUsageAvg  *float64 `json:"cpu_usage_avg,omitempty"`
+	MemoryMBAvg  *float64 `json:"memory_mb_avg,omitempty"`
+	MemoryMBPeak *float64 `json:"memory_mb_peak,omitempty"`
+
+	NetBytesSent  *int64  `json:"net_bytes_sent,omitempty"`
+	NetBytesRecv  *int64  `json:"net_bytes_recv,omitempty"`
+	NetInterfaces *string `json:"net_interfaces,omitempty"`
+
+	LatencyMetric *string  `json:"latency_metric,omitempty"`
+	ComparisonID  *string  `json:"comparison_id,omitempty"`
+	SuiteID       *string  `json:"suite_id,omitempty"`
+	PayloadSize   *int     `json:"payload_size,omitempty"`
+	WriteRatio    *float64 `json:"write_ratio,omitempty"`
+	OperationMix  *string  `json:"operation_mix,omitempty"`
+	AccountChurn  *string  `json:"account_churn,omitempty"`
+	DBTarget      *string  `json:"db_target,omitempty"`
+	Compression   *string  `json:"compression,omitempty"`
+	Connection    *string  `json:"connection,omitempty"`
+	ServerQoS     *string  `json:"server_qos,omitempty"`
+	ServerPools   *string  `json:"server_pools,omitempty"`
+	ServerQueryTx *string  `json:"server_query_tx,omitempty"`
+	ServerFaults  *string  `json:"server_faults,omitempty"`
+	ServerCache   *string  `json:"server_cache,omitempty"`
+	JSONEncoder   *string  `json:"json_encoder,omitempty"`
+
+	Cost           *float64 `json:"cost,omitempty"`
+	CostPerMillion *float64 `json:"cost_per_million,omitempty"`
+	CostModel      *string  `json:"cost_model,omitempty"`
+
+	ClientCPUSeconds *float64 `json:"client_cpu_seconds,omitempty"`
+	ServerCPUSeconds *float64 `json:"server_cpu_seconds,omitempty"`
+	ClientPerCPUSec  *float64 `json:"client_per_cpu_sec,omitempty"`
+	ServerPerCPUSec  *float64 `json:"server_per_cpu_sec,omitempty"`
+	CacheHitRate     *float64 `json:"cache_hit_rate,omitempty"`
+	Workers          *int     `json:"workers,omitempty"`
+	StreamsPerWorker *int     `json:"streams_per_worker,omitempty"`
+	ProcessCostUs    *int64   `json:"process_cost_us,omitempty"`
+
+	LoadProfile *string `json:"load_profile,omitempty"`
+	DatasetHash *string `json:"dataset_hash,omitempty"`
+
+	DatasetFingerprint *string `json:"dataset_fingerprint,omitempty"`
+	DatasetSize        *int64  `json:"dataset_size,omitempty"`
+	StreamShortfall    *int64  `json:"stream_shortfall,omitempty"`
+	StreamsEstablished *int    `json:"streams_established,omitempty"`
+
+	MeasureWindow  *string  `json:"measure_window,omitempty"`
+	MeasuredWindow *string  `json:"measured_window,omitempty"`
+	FullThroughput *float64 `json:"full_throughput,omitempty"`
+	FullP50Ms      *float64 `json:"full_p50_ms,omitempty"`
+	FullP99Ms      *float64 `json:"full_p99_ms,omitempty"`
+
+	GCPauses       *int     `json:"gc_pauses,omitempty"`
+	GCPauseMs      *float64 `json:"gc_pause_ms,omitempty"`
+	GCTailFraction *float64 `json:"gc_tail_fraction,omitempty"`
+
+	LiveStream *bool `json:"live_stream,omitempty"`
+
+	ChaosDisconnectMs *int64   `json:"chaos_disconnect_ms,omitempty"`
+	Reconnects        *int     `json:"reconnects,omitempty"`
+	ReconnectP50Ms    *float64 `json:"reconnect_p50_ms,omitempty"`
+	ReconnectP99Ms    *float64 `json:"reconnect_p99_ms,omitempty"`
+
+	ServerMiddleware *string `json:"server_middleware,omitempty"`
+	Auth             *string `json:"auth,omitempty"`
+	ServerRateLimit  *string `json:"server_rate_limit,omitempty"`
+	Backoff          *bool   `json:"backoff,omitempty"`
+
+	ChaosRestart      *string  `json:"chaos_restart,omitempty"`
+	RestartErrors     *int     `json:"restart_errors,omitempty"`
+	RestartRecoveryMs *float64 `json:"restart_recovery_ms,omitempty"`
+
+	Agents         *string `json:"agents,omitempty"`          // registered agents that generated the load
+	ConcurrentWith *string `json:"concurrent_with,omitempty"` // run holding the environment, nil unless overlapped
+
+	ClientCommit *string `json:"client_commit,omitempty"`
+	ServerCommit *string `json:"server_commit,omitempty"`
+	GoVersion    *string `json:"go_version,omitempty"`
+	GOMAXPROCS   *int    `json:"gomaxprocs,omitempty"`
+	Kernel       *string `json:"kernel,omitempty"`
+	Hostname     *string `json:"hostname,omitempty"`
+	Tags         *string `json:"tags,omitempty"` // key=value pairs, comma-separated
+	Environment  *string `json:"environment,omitempty"`
+
+	GoroutinesPeak  *int     `json:"goroutines_peak,omitempty"`
+	HeapObjectsPeak *int64   `json:"heap_objects_peak,omitempty"`
+	HeapMBPeak      *float64 `json:"heap_mb_peak,omitempty"`
+	GCCycles        *int     `json:"gc_cycles,omitempty"`
+
+	CacheBust *string `json:"cache_bust,omitempty"` // query or header, omitted for unchanged requests
+
+	InjectedHeaders     *string `json:"injected_headers,omitempty"` // names of the injected headers or metadata
+	InjectedHeaderBytes *int    `json:"injected_header_bytes,omitempty"`
+
+	WireBytesSent    *int64 `json:"wire_bytes_sent,omitempty"` // client connection bytes, with HTTP framing and TLS
+	WireBytesRecv    *int64 `json:"wire_bytes_recv,omitempty"`
+	PayloadBytesSent *int64 `json:"payload_bytes_sent,omitempty"` // message and body bytes
+	PayloadBytesRecv *int64 `json:"payload_bytes_recv,omitempty"`
+
+	MessageP50Bytes *int `json:"message_p50_bytes,omitempty"` // serialized stream message sizes
+	MessageP99Bytes *int `json:"message_p99_bytes,omitempty"`
+	MessageMaxBytes *int `json:"message_max_bytes,omitempty"`
+
+	RESTStreamFormat *string `json:"rest_stream_format,omitempty"` // ndjson or chunked-array, omitted for SSE
+
+	RunConfig *string `json:"run_config,omitempty"` // configuration snapshot, JSON
+
+	// Annotations attached after the run
+	Labels map[string]string `json:"labels,omitempty"`
+	Notes  *string           `json:"notes,omitempty"`
+
+	ServerLogErrors   *int64  `json:"server_log_errors,omitempty"`
+	ServerLogWarnings *int64  `json:"server_log_warnings,omitempty"`
+	ServerLogLines    *string `json:"server_log_lines,omitempty"`
+
+	Verified     *bool   `json:"verified,omitempty"`
+	Verification *string `json:"verification,omitempty"`
+
+	// Automatically selected baseline run and percentage changes against it
+	BaselineRunID      *int64   `json:"baseline_run_id,omitempty"`
+	P50DeltaPct        *float64 `json:"p50_delta_pct,omitempty"`
+	P99DeltaPct        *float64 `json:"p99_delta_pct,omitempty"`
+	ThroughputDeltaPct *float64 `json:"throughput_delta_pct,omitempty"`
+
+	P50Staleness *float64 `json:"p50_staleness_ms,omitempty"`
+	P90Staleness *float64 `json:"p90_staleness_ms,omitempty"`
+	P99Staleness *float64 `json:"p99_staleness_ms,omitempty"`
+
+	// Database time servers reported for requests, and the rest of their
+	// latency: network, serialization and server overhead
+	P50DB      *float64 `json:"p50_db_ms,omitempty"`
+	P99DB      *float64 `json:"p99_db_ms,omitempty"`
+	P50Network *float64 `json:"p50_network_ms,omitempty"`
+	P99Network *float64 `json:"p99_network_ms,omitempty"`
+
+	// Failed samples per error type, e.g. {"timeout": 12, "http_5xx": 3}
+	Errors map[string]int64 `json:"errors,omitempty"`
+}
+
+// ConcurrencyGroup averages the runs of one configuration at one
+// concurrency level.
+type ConcurrencyGroup struct {
+	Scenario    string  `json:"scenario"`
+	Protocol    string  `json:"protocol"`
+	Client      string  `json:"client"`
+	Concurrency int     `json:"concurrency"`
+	Runs        int64   `json:"runs"`
+	Throughput  float64 `json:"throughput"`
+	P50Latency  float64 `json:"p50_latency_ms"`
+	P99Latency  float64 `json:"p99_latency_ms"`
+}
+
+// TimeseriesPoint is one second of a run's timeseries.
+type TimeseriesPoint struct {
+	ElapsedSec int      `json:"elapsed_sec"`
+	Requests   int64    `json:"requests"`
+	Errors     int64    `json:"errors"`
+	P50Latency *float64 `json:"p50_latency_ms,omitempty"`
+	P99Latency *float64 `json:"p99_latency_ms,omitempty"`
+
+	// Go runtime of the client in the second, omitted unless measured
+	Goroutines  *int     `json:"goroutines,omitempty"`
+	HeapObjects *int64   `json:"heap_objects,omitempty"`
+	HeapMB      *float64 `json:"heap_mb,omitempty"`
+	GCCycles    *int     `json:"gc_cycles,omitempty"`
+	GCPause     *float64 `json:"gc_pause_ms,omitempty"`
+}
+
+// Timeseries is a run's per-second timeseries.
+type Timeseries struct {
+	RunID  int64             `json:"run_id"`
+	Points []TimeseriesPoint `json:"points"`
+}
+
+// TrendPoint averages a metric over the runs of one configuration that
+// started in one bucket.
+type TrendPoint struct {
+	Time  time.Time `json:"time"` // start of the bucket
+	Runs  int64     `json:"runs"`
+	Value float64   `json:"value"`
+}
+
+// TrendSeries is the downsampled history of a metric for one
+// configuration.
+type TrendSeries struct {
+	Scenario string       `json:"scenario"`
+	Protocol string       `json:"protocol"`
+	Client   string       `json:"client"`
+	Points   []TrendPoint `json:"points"`
+}
+
+// Trends is the downsampled history of a metric for every matching
+// configuration.
+type Trends struct {
+	Metric    string        `json:"metric"`
+	Window    string        `json:"window"`
+	BucketSec int64         `json:"bucket_sec"`
+	Series    []TrendSeries `json:"series"`
+}
+
+// Event is an operational change, e.g. a deploy, config change or dataset
+// reseed, that may explain a shift in the trends.
+type Event struct {
+	ID         int64     `json:"id"`
+	Kind       string    `json:"kind"` // deploy, config, reseed or note
+	Message    string    `json:"message"`
+	OccurredAt time.Time `json:"occurred_at"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// Events is the JSON response for the event list, oldest first.
+type Events struct {
+	Events []Event `json:"events"`
+	Count  int     `json:"count"`
+}
+
+// Agent is a registered load generation agent with the capabilities it
+// reported.
+type Agent struct {
+	Name          string    `json:"name"`
+	Address       string    `json:"address"` // host:port coordinators connect to
+	Protocols     []string  `json:"protocols"`
+	CPUs          int       `json:"cpus"`
+	RegisteredAt  time.Time `json:"registered_at"`
+	LastHeartbeat time.Time `json:"last_heartbeat"`
+	Healthy       bool      `json:"healthy"`
+}
+
+// Agents is the JSON response for the agent list, ordered by name.
+type Agents struct {
+	Agents []Agent `json:"agents"`
+	Count  int     `json:"count"`
+}
+
+// AgentRegistration is the JSON body registering an agent.
+type AgentRegistration struct {
+	Name      string   `json:"name"`
+	Address   string   `json:"address"`
+	Protocols []string `json:"protocols"`
+	CPUs      int      `json:"cpus"`
+}
+
+// Environment is a registered target environment, the body of POST and
+// PUT /api/v1/environments. Runs select it with --environment NAME.
+type Environment struct {
+	Name        string    `json:"name"`
+	GRPCAddr    string    `json:"grpc_addr,omitempty"`
+	RESTAddr    string    `json:"rest_addr,omitempty"`
+	GRPCWebAddr string    `json:"grpc_web_addr,omitempty"`
+	Database    string    `json:"database,omitempty"` // the servers' database
+	Hardware    string    `json:"hardware,omitempty"` // notes on the server hardware
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// Environments is the JSON response for the environment list, ordered by
+// name.
+type Environments struct {
+	Environments []Environment `json:"environments"`
+	Count        int           `json:"count"`
+}
+
+// Profile is a pprof profile attached to a run, named by its source and
+// kind, e.g. "server-cpu". GET /api/v1/results/{run_id}/profiles/{name}
+// serves its data.
+type Profile struct {
+	Name      string    `json:"name"`
+	Size      int       `json:"size"` // bytes
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Profiles is the JSON response for the profiles of a run, ordered by name.
+type Profiles struct {
+	Profiles []Profile `json:"profiles"`
+	Count    int       `json:"count"`
+}
+
+// RunMetadata is the JSON body of PATCH /api/v1/results/{run_id}. Labels
+// are merged into the run's labels, a null value removing the label; absent
+// notes are left as they are and empty notes clear them.
+type RunMetadata struct {
+	Labels map[string]*string `json:"labels,omitempty"`
+	Notes  *string            `json:"notes,omitempty"`
+}
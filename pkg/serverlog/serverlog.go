@@ -0,0 +1,445 @@
+// Package serverlog counts the errors and warnings servers log while a
+// benchmark runs, read from log files or docker containers, to catch
+// server-side failures that never reach the client as errors: a failed
+// cache refresh, a database warning, a panic recovered by a handler.
+//
+// Lines are classified by the level field of JSON (e.g. slog) and logfmt
+// lines, and by the first level keyword of plain ones, such as the
+// standard log package's "Warning: ..." or PostgreSQL's "ERROR:  ...".
+package serverlog
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// MaxLines bounds the representative lines kept per run.
+const MaxLines = 5
+
+// pollInterval is how often a log file is checked for new lines.
+const pollInterval = 250 * time.Millisecond
+
+// dockerDrain is how long container logs are still read after the run, as
+// docker delivers them with a delay.
+const dockerDrain = 500 * time.Millisecond
+
+// dockerPrefix selects a docker container's logs instead of a file.
+const dockerPrefix = "docker:"
+
+// Level is the severity of a log line.
+type Level int
+
+const (
+	LevelOther Level = iota
+	LevelWarning
+	LevelError
+	LevelFatal // a panic or fatal error, which is counted as an error too
+)
+
+func (l Level) String() string {
+	switch l {
+	case LevelFatal:
+		return "fatal"
+	case LevelError:
+		return "error"
+	case LevelWarning:
+		return "warning"
+	}
+	return "other"
+}
+
+// Source is a server log to read: a file, or a container's output read
+// through docker logs.
+type Source struct {
+	Path      string // log file, empty for a container
+	Container string // docker container, empty for a file
+}
+
+// ParseSource parses a source given as "FILE" or "docker:CONTAINER".
+func ParseSource(s string) (Source, error) {
+	if container, ok := strings.CutPrefix(s, dockerPrefix); ok {
+		if container == "" {
+			return Source{}, fmt.Errorf("invalid server log %q: missing container name", s)
+		}
+		return Source{Container: container}, nil
+	}
+	if s == "" {
+		return Source{}, fmt.Errorf("invalid server log: empty path")
+	}
+	return Source{Path: s}, nil
+}
+
+func (s Source) String() string {
+	if s.Container != "" {
+		return dockerPrefix + s.Container
+	}
+	return s.Path
+}
+
+// name is the short name lines from the source are labeled with.
+func (s Source) name() string {
+	if s.Container != "" {
+		return s.Container
+	}
+	return filepath.Base(s.Path)
+}
+
+// Line is a representative log line: one of the distinct messages logged
+// during the run.
+type Line struct {
+	Source string // short name of the source, e.g. "rest-server.log"
+	Level  Level
+	Text   string // the line without its leading timestamp
+	Count  int64  // times the line was logged during the run
+}
+
+func (l Line) String() string {
+	s := fmt.Sprintf("[%s] %s", l.Source, l.Text)
+	if l.Count > 1 {
+		s = fmt.Sprintf("%dx %s", l.Count, s)
+	}
+	return s
+}
+
+// Summary counts the errors and warnings logged during a run.
+type Summary struct {
+	Errors   int64
+	Warnings int64
+	Fatal    int64 // errors that were panics or fatal errors
+
+	// The first MaxLines distinct errors, then warnings if there are fewer
+	// errors, with their counts
+	Lines []Line
+}
+
+// Excerpt returns the representative lines, one per line, or "" if there
+// are none.
+func (s Summary) Excerpt() string {
+	lines := make([]string, len(s.Lines))
+	for i, l := range s.Lines {
+		lines[i] = l.String()
+	}
+	return strings.Join(lines, "\n")
+}
+
+// PrintSummary prints the errors and warnings of a run's server logs.
+func PrintSummary(w io.Writer, s Summary) {
+	fmt.Fprintf(w, "\nServer logs: %d errors, %d warnings\n", s.Errors, s.Warnings)
+	for _, l := range s.Lines {
+		fmt.Fprintf(w, "  %s\n", l)
+	}
+}
+
+var (
+	// timestampRE matches the leading timestamp of a plain line, e.g.
+	// "2026/10/17 23:14:04 " (Go log) or "2026-10-17 23:14:04.123 UTC "
+	// (PostgreSQL)
+	timestampRE = regexp.MustCompile(`^\d{4}[-/]\d{2}[-/]\d{2}[T ]\d{2}:\d{2}:\d{2}(\.\d+)?(Z|[+-]\d{2}:?\d{2}| UTC| GMT)?\s+`)
+
+	// logfmtLevelRE matches the level field of a logfmt line
+	logfmtLevelRE = regexp.MustCompile(`(?:^|\s)(?:level|lvl)="?(\w+)`)
+
+	// keywordRE matches the level keywords of a plain line
+	keywordRE = regexp.MustCompile(`(?i)\b(panic|fatal|error|failed|warn|warning)\b`)
+)
+
+// Classify returns the level of a log line and its text without the
+// leading timestamp, with JSON lines reduced to their message and error.
+func Classify(line string) (Level, string) {
+	line = strings.TrimSpace(line)
+	if strings.HasPrefix(line, "{") {
+		var fields map[string]any
+		if json.Unmarshal([]byte(line), &fields) == nil {
+			return classifyJSON(fields, line)
+		}
+	}
+
+	text := timestampRE.ReplaceAllString(line, "")
+	if m := logfmtLevelRE.FindStringSubmatch(text); m != nil {
+		return parseLevel(m[1]), text
+	}
+	m := keywordRE.FindStringSubmatch(text)
+	if m == nil {
+		return LevelOther, text
+	}
+	switch keyword := strings.ToLower(m[1]); {
+	case strings.HasPrefix(keyword, "warn"):
+		return LevelWarning, text
+	case keyword == "panic" || keyword == "fatal":
+		return LevelFatal, text
+	}
+	return LevelError, text
+}
+
+// classifyJSON classifies a JSON line by its level field.
+func classifyJSON(fields map[string]any, line string) (Level, string) {
+	level := LevelOther
+	for _, key := range []string{"level", "lvl", "severity"} {
+		if s, ok := fields[key].(string); ok {
+			level = parseLevel(s)
+			break
+		}
+	}
+
+	text := line
+	for _, key := range []string{"msg", "message"} {
+		if s, ok := fields[key].(string); ok {
+			text = s
+			break
+		}
+	}
+	for _, key := range []string{"error", "err"} {
+		if s, ok := fields[key].(string); ok {
+			text += ": " + s
+			break
+		}
+	}
+	return level, text
+}
+
+// parseLevel maps a level name such as "ERROR", "warn" or "crit" to a Level.
+func parseLevel(s string) Level {
+	s = strings.ToUpper(s)
+	for _, prefix := range []string{"FATAL", "PANIC", "DPANIC"} {
+		if strings.HasPrefix(s, prefix) {
+			return LevelFatal
+		}
+	}
+	for _, prefix := range []string{"ERR", "CRIT", "ALERT", "EMERG"} {
+		if strings.HasPrefix(s, prefix) {
+			return LevelError
+		}
+	}
+	if strings.HasPrefix(s, "WARN") {
+		return LevelWarning
+	}
+	return LevelOther
+}
+
+// collector counts classified lines and keeps the distinct ones.
+type collector struct {
+	mu       sync.Mutex
+	summary  Summary
+	errors   []*Line
+	warnings []*Line
+	seen     map[Line]*Line // keyed by source, level and text
+}
+
+func newCollector() *collector {
+	return &collector{seen: make(map[Line]*Line)}
+}
+
+// add classifies and counts a line read from source.
+func (c *collector) add(source, line string) {
+	level, text := Classify(line)
+	if level == LevelOther {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	kept := &c.warnings
+	if level >= LevelError {
+		c.summary.Errors++
+		kept = &c.errors
+	} else {
+		c.summary.Warnings++
+	}
+	if level == LevelFatal {
+		c.summary.Fatal++
+	}
+
+	key := Line{Source: source, Level: level, Text: text}
+	if l, ok := c.seen[key]; ok {
+		l.Count++
+		return
+	}
+	if len(*kept) < MaxLines {
+		l := key
+		l.Count = 1
+		c.seen[key] = &l
+		*kept = append(*kept, &l)
+	}
+}
+
+// result returns the summary of the lines added so far.
+func (c *collector) result() Summary {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	s := c.summary
+	s.Lines = nil
+	for _, l := range slices.Concat(c.errors, c.warnings) {
+		if len(s.Lines) == MaxLines {
+			break
+		}
+		s.Lines = append(s.Lines, *l)
+	}
+	return s
+}
+
+// Scraper reads server logs from when it is started until it is stopped.
+type Scraper struct {
+	collector *collector
+	stop      chan struct{}
+	cancel    context.CancelFunc
+	wg        sync.WaitGroup
+
+	mu   sync.Mutex
+	errs []error
+}
+
+// Start starts reading the lines the sources log from now on. It fails if
+// a log file cannot be opened or docker cannot be started; a container
+// that does not exist is reported by Stop.
+func Start(ctx context.Context, sources []Source) (*Scraper, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	s := &Scraper{collector: newCollector(), stop: make(chan struct{}), cancel: cancel}
+	for _, src := range sources {
+		var err error
+		if src.Container != "" {
+			err = s.followContainer(ctx, src)
+		} else {
+			err = s.tailFile(ctx, src)
+		}
+		if err != nil {
+			s.Stop()
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+// Stop stops reading, after the lines already logged, and returns the
+// summary and any errors reading the sources.
+func (s *Scraper) Stop() (Summary, error) {
+	close(s.stop)
+	s.wg.Wait()
+	s.cancel()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.collector.result(), errors.Join(s.errs...)
+}
+
+func (s *Scraper) fail(err error) {
+	s.mu.Lock()
+	s.errs = append(s.errs, err)
+	s.mu.Unlock()
+}
+
+// tailFile reads the lines appended to a log file from now on, polling for
+// them. It reads the lines logged before Stop before returning.
+func (s *Scraper) tailFile(ctx context.Context, src Source) error {
+	f, err := os.Open(src.Path)
+	if err != nil {
+		return fmt.Errorf("failed to open server log: %w", err)
+	}
+	if _, err := f.Seek(0, io.SeekEnd); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to open server log: %w", err)
+	}
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		defer f.Close()
+
+		r := bufio.NewReader(f)
+		var partial string
+		ticker := time.NewTicker(pollInterval)
+		defer ticker.Stop()
+		for {
+			// Read the complete lines appended so far
+			for {
+				line, err := r.ReadString('\n')
+				if err != nil {
+					partial += line
+					if err != io.EOF {
+						s.fail(fmt.Errorf("failed to read server log %s: %w", src.Path, err))
+						return
+					}
+					break
+				}
+				s.collector.add(src.name(), partial+line)
+				partial = ""
+			}
+
+			select {
+			case <-ticker.C:
+			case <-s.stop:
+				if partial != "" {
+					s.collector.add(src.name(), partial)
+				}
+				return
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return nil
+}
+
+// followContainer reads a container's output from now on with docker logs,
+// until shortly after Stop.
+func (s *Scraper) followContainer(ctx context.Context, src Source) error {
+	ctx, cancel := context.WithCancel(ctx)
+	since := time.Now().UTC().Format(time.RFC3339Nano)
+	cmd := exec.CommandContext(ctx, "docker", "logs", "--follow", "--since", since, src.Container)
+	pr, pw := io.Pipe()
+	cmd.Stdout = pw
+	cmd.Stderr = pw
+	if err := cmd.Start(); err != nil {
+		cancel()
+		return fmt.Errorf("failed to read logs of container %s: %w", src.Container, err)
+	}
+
+	s.wg.Add(2)
+	go func() {
+		defer s.wg.Done()
+		sc := bufio.NewScanner(pr)
+		sc.Buffer(nil, 1<<20)
+		for sc.Scan() {
+			s.collector.add(src.name(), sc.Text())
+		}
+		// Drain what remains after a too long line, so docker does not block
+		io.Copy(io.Discard, pr)
+	}()
+	go func() {
+		defer s.wg.Done()
+		done := make(chan error, 1)
+		go func() { done <- cmd.Wait() }()
+
+		select {
+		case err := <-done:
+			// No such container, or it stopped during the run
+			if err == nil {
+				err = errors.New("container stopped")
+			}
+			s.fail(fmt.Errorf("docker logs %s ended during the run: %w", src.Container, err))
+		case <-s.stop:
+			select {
+			case <-time.After(dockerDrain):
+			case <-ctx.Done():
+			}
+			cancel()
+			<-done
+		case <-ctx.Done():
+			<-done
+		}
+		cancel()
+		pw.Close()
+	}()
+	return nil
+}
@@ -0,0 +1,115 @@
+package serverlog
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseSource(t *testing.T) {
+	if s, err := ParseSource("logs/rest-server.log"); err != nil || s.Path != "logs/rest-server.log" || s.name() != "rest-server.log" {
+		t.Errorf("ParseSource(file) = %+v, %v", s, err)
+	}
+	if s, err := ParseSource("docker:benchmark-postgres-1"); err != nil || s.Container != "benchmark-postgres-1" || s.String() != "docker:benchmark-postgres-1" {
+		t.Errorf("ParseSource(docker) = %+v, %v", s, err)
+	}
+	for _, bad := range []string{"", "docker:"} {
+		if _, err := ParseSource(bad); err == nil {
+			t.Errorf("ParseSource(%q) succeeded, want an error", bad)
+		}
+	}
+}
+
+func TestClassify(t *testing.T) {
+	tests := []struct {
+		line  string
+		level Level
+		text  string
+	}{
+		{"2026/10/17 23:14:04 REST server listening on :8080", LevelOther, "REST server listening on :8080"},
+		{"2026/10/17 23:14:04 Warning: runs will not record the server config: failed to query", LevelWarning, "Warning: runs will not record the server config: failed to query"},
+		{"2026/10/17 23:14:04 Failed to serve: listener closed", LevelError, "Failed to serve: listener closed"},
+		{"2026/10/17 23:14:04 Injecting faults: latency=10ms,errors=1%", LevelOther, "Injecting faults: latency=10ms,errors=1%"},
+		{"2026-10-17 23:14:04.123 UTC [42] ERROR:  deadlock detected", LevelError, "[42] ERROR:  deadlock detected"},
+		{"2026-10-17 23:14:04.123 UTC [42] WARNING:  there is no transaction in progress", LevelWarning, "[42] WARNING:  there is no transaction in progress"},
+		{"panic: runtime error: index out of range", LevelFatal, "panic: runtime error: index out of range"},
+		{`{"time":"2026-10-17T23:14:04Z","level":"ERROR","msg":"cache refresh failed","error":"timeout"}`, LevelError, "cache refresh failed: timeout"},
+		{`{"time":"2026-10-17T23:14:04Z","level":"FATAL","msg":"listener closed"}`, LevelFatal, "listener closed"},
+		{`{"time":"2026-10-17T23:14:04Z","level":"WARN","msg":"slow query"}`, LevelWarning, "slow query"},
+		{`{"time":"2026-10-17T23:14:04Z","level":"INFO","msg":"error budget ok"}`, LevelOther, "error budget ok"},
+		{`time=2026-10-17T23:14:04Z level=warn msg="pool exhausted"`, LevelWarning, `time=2026-10-17T23:14:04Z level=warn msg="pool exhausted"`},
+		{`time=2026-10-17T23:14:04Z level=info msg="error rate 0"`, LevelOther, `time=2026-10-17T23:14:04Z level=info msg="error rate 0"`},
+	}
+	for _, tt := range tests {
+		level, text := Classify(tt.line)
+		if level != tt.level || text != tt.text {
+			t.Errorf("Classify(%q) = %v, %q; want %v, %q", tt.line, level, text, tt.level, tt.text)
+		}
+	}
+}
+
+func TestCollector(t *testing.T) {
+	c := newCollector()
+	for i := 0; i < 3; i++ {
+		c.add("rest.log", fmt.Sprintf("2026/10/17 23:14:0%d Warning: slow query", i))
+	}
+	for i := 0; i < MaxLines+2; i++ {
+		c.add("rest.log", fmt.Sprintf("ERROR: failure %d", i))
+	}
+	c.add("rest.log", "ERROR: failure 0")
+	c.add("rest.log", "request served")
+	c.add("rest.log", "panic: nil map")
+
+	s := c.result()
+	if s.Errors != MaxLines+4 || s.Warnings != 3 || s.Fatal != 1 {
+		t.Errorf("counts = %d errors, %d warnings, %d fatal; want %d, 3, 1", s.Errors, s.Warnings, s.Fatal, MaxLines+4)
+	}
+	if len(s.Lines) != MaxLines {
+		t.Fatalf("kept %d lines, want %d: %v", len(s.Lines), MaxLines, s.Lines)
+	}
+	if got := s.Lines[0].String(); got != "2x [rest.log] ERROR: failure 0" {
+		t.Errorf("first line = %q", got)
+	}
+
+	// Warnings fill the lines errors leave
+	c = newCollector()
+	c.add("a", "ERROR: boom")
+	for i := 0; i < 3; i++ {
+		c.add("b", "2026/10/17 23:14:04 Warning: slow query")
+	}
+	if got, want := c.result().Excerpt(), "[a] ERROR: boom\n3x [b] Warning: slow query"; got != want {
+		t.Errorf("Excerpt() = %q, want %q", got, want)
+	}
+}
+
+func TestScraper_File(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "server.log")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	fmt.Fprintln(f, "ERROR: logged before the run")
+
+	s, err := Start(context.Background(), []Source{{Path: path}})
+	if err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	fmt.Fprintln(f, "2026/10/17 23:14:04 Warning: pool exhausted")
+	fmt.Fprintln(f, "2026/10/17 23:14:04 request served")
+	fmt.Fprint(f, "ERROR: no newline yet")
+
+	summary, err := s.Stop()
+	if err != nil {
+		t.Fatalf("Stop() error = %v", err)
+	}
+	if summary.Errors != 1 || summary.Warnings != 1 {
+		t.Errorf("Stop() = %+v, want 1 error and 1 warning", summary)
+	}
+
+	if _, err := Start(context.Background(), []Source{{Path: filepath.Join(t.TempDir(), "missing.log")}}); err == nil {
+		t.Error("Start() with a missing file succeeded")
+	}
+}
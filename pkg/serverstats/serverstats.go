@@ -0,0 +1,25 @@
+// Package serverstats reads the resource usage of a server process, which
+// the servers report so the benchmark can measure server-side efficiency
+// from readings taken before and after a run.
+package serverstats
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/shirou/gopsutil/v4/process"
+)
+
+// CPUSeconds returns the user and system CPU time the current process has
+// used since it started.
+func CPUSeconds() (float64, error) {
+	proc, err := process.NewProcess(int32(os.Getpid()))
+	if err != nil {
+		return 0, fmt.Errorf("failed to open process: %w", err)
+	}
+	times, err := proc.Times()
+	if err != nil {
+		return 0, fmt.Errorf("failed to read CPU times: %w", err)
+	}
+	return times.User + times.System, nil
+}
@@ -0,0 +1,27 @@
+package serverstats
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCPUSeconds(t *testing.T) {
+	before, err := CPUSeconds()
+	if err != nil {
+		t.Fatalf("CPUSeconds() error = %v", err)
+	}
+
+	// Burn enough CPU for the clock tick granularity of the counters
+	deadline := time.Now().Add(200 * time.Millisecond)
+	for n := 0; time.Now().Before(deadline); n++ {
+		_ = n * n
+	}
+
+	after, err := CPUSeconds()
+	if err != nil {
+		t.Fatalf("CPUSeconds() error = %v", err)
+	}
+	if after <= before {
+		t.Errorf("CPUSeconds() = %v after busy work, want more than %v", after, before)
+	}
+}
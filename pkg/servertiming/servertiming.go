@@ -0,0 +1,173 @@
+// Package servertiming measures how long a server spends in database queries
+// while answering a request and reports it to the client, so the benchmark
+// can tell database latency from network and serialization latency. The
+// REST server reports it in a Server-Timing response header (REST and
+// Connect), the gRPC server in a server-timing trailer (gRPC and gRPC-Web),
+// both as "db;dur=<milliseconds>".
+package servertiming
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/kaldun-tech/grpc-rest-benchmark/pkg/internal/grpcmethod"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// Header is the HTTP response header and Trailer the gRPC trailer carrying
+// the timing.
+const (
+	Header  = "Server-Timing"
+	Trailer = "server-timing"
+)
+
+// metricDB names the database time in the header.
+const metricDB = "db"
+
+// Timer accumulates the time a request spends in database queries. Queries
+// of one request may run concurrently, so it is safe for concurrent use.
+type Timer struct {
+	db atomic.Int64
+}
+
+// DB returns the time spent in database queries so far.
+func (t *Timer) DB() time.Duration {
+	return time.Duration(t.db.Load())
+}
+
+type timerKey struct{}
+
+// WithTimer returns a context whose database queries are timed by the
+// returned Timer.
+func WithTimer(ctx context.Context) (context.Context, *Timer) {
+	t := new(Timer)
+	return context.WithValue(ctx, timerKey{}, t), t
+}
+
+// QueryTracer times queries on the Timer of their context, from the start
+// of the query until its rows are closed. Queries whose context has no
+// Timer, such as streams and the benchmark's own result writes, are not
+// timed.
+type QueryTracer struct{}
+
+type queryStartKey struct{}
+
+// TraceQueryStart implements pgx.QueryTracer.
+func (QueryTracer) TraceQueryStart(ctx context.Context, _ *pgx.Conn, _ pgx.TraceQueryStartData) context.Context {
+	if _, ok := ctx.Value(timerKey{}).(*Timer); !ok {
+		return ctx
+	}
+	return context.WithValue(ctx, queryStartKey{}, time.Now())
+}
+
+// TraceQueryEnd implements pgx.QueryTracer.
+func (QueryTracer) TraceQueryEnd(ctx context.Context, _ *pgx.Conn, _ pgx.TraceQueryEndData) {
+	t, ok := ctx.Value(timerKey{}).(*Timer)
+	if !ok {
+		return
+	}
+	if start, ok := ctx.Value(queryStartKey{}).(time.Time); ok {
+		t.db.Add(int64(time.Since(start)))
+	}
+}
+
+// Format formats the database time as a Server-Timing value, e.g.
+// "db;dur=1.234".
+func Format(db time.Duration) string {
+	return metricDB + ";dur=" + strconv.FormatFloat(float64(db)/float64(time.Millisecond), 'f', 3, 64)
+}
+
+// Parse returns the database time from Server-Timing values. ok is false if
+// none of them reports it.
+func Parse(values []string) (db time.Duration, ok bool) {
+	for _, value := range values {
+		for metric := range strings.SplitSeq(value, ",") {
+			name, params, _ := strings.Cut(strings.TrimSpace(metric), ";")
+			if strings.TrimSpace(name) != metricDB {
+				continue
+			}
+			for param := range strings.SplitSeq(params, ";") {
+				key, dur, _ := strings.Cut(strings.TrimSpace(param), "=")
+				if key != "dur" {
+					continue
+				}
+				ms, err := strconv.ParseFloat(dur, 64)
+				if err != nil || ms < 0 {
+					return 0, false
+				}
+				return time.Duration(ms * float64(time.Millisecond)), true
+			}
+		}
+	}
+	return 0, false
+}
+
+// Handler returns next with the database time of each request reported in
+// the Server-Timing header. The header is added when the response headers
+// are written, so streamed responses report the queries finished before
+// their first message.
+func Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx, timer := WithTimer(r.Context())
+		next.ServeHTTP(&timingWriter{ResponseWriter: w, timer: timer}, r.WithContext(ctx))
+	})
+}
+
+// timingWriter adds the Server-Timing header before the response headers
+// are written.
+type timingWriter struct {
+	http.ResponseWriter
+	timer       *Timer
+	wroteHeader bool
+}
+
+func (w *timingWriter) WriteHeader(code int) {
+	if !w.wroteHeader {
+		w.wroteHeader = true
+		w.Header().Set(Header, Format(w.timer.DB()))
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *timingWriter) Write(b []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+// Flush implements http.Flusher for streamed responses.
+func (w *timingWriter) Flush() {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer.
+func (w *timingWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// UnaryServerInterceptor reports the database time of unary calls to the
+// given services (full names, e.g. "benchmark.BalanceService") in the
+// server-timing trailer; calls to other services pass through.
+func UnaryServerInterceptor(services ...string) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if !grpcmethod.InServices(info.FullMethod, services) {
+			return handler(ctx, req)
+		}
+		ctx, timer := WithTimer(ctx)
+		resp, err := handler(ctx, req)
+		grpc.SetTrailer(ctx, metadata.Pairs(Trailer, Format(timer.DB())))
+		return resp, err
+	}
+}
@@ -0,0 +1,60 @@
+package servertiming
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+func TestFormatParse(t *testing.T) {
+	if got := Format(1234567 * time.Nanosecond); got != "db;dur=1.235" {
+		t.Errorf("Format() = %q, want db;dur=1.235", got)
+	}
+
+	tests := []struct {
+		values []string
+		want   time.Duration
+		ok     bool
+	}{
+		{[]string{"db;dur=1.5"}, 1500 * time.Microsecond, true},
+		{[]string{`cache;desc="Cache Read";dur=0.2, db;dur=0`}, 0, true},
+		{[]string{"app;dur=3", "db; dur=2"}, 2 * time.Millisecond, true},
+		{[]string{"db"}, 0, false},
+		{[]string{"db;dur=-1"}, 0, false},
+		{[]string{"app;dur=3"}, 0, false},
+		{nil, 0, false},
+	}
+	for _, tt := range tests {
+		got, ok := Parse(tt.values)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("Parse(%q) = %v, %v; want %v, %v", tt.values, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestHandler(t *testing.T) {
+	var tracer QueryTracer
+	handler := Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		for range 2 {
+			ctx := tracer.TraceQueryStart(r.Context(), nil, pgx.TraceQueryStartData{})
+			time.Sleep(2 * time.Millisecond)
+			tracer.TraceQueryEnd(ctx, nil, pgx.TraceQueryEndData{})
+		}
+		w.Write([]byte("{}"))
+	}))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/accounts/0.0.1/balance", nil))
+	db, ok := Parse(rec.Header().Values(Header))
+	if !ok || db < 4*time.Millisecond || db > time.Second {
+		t.Errorf("Server-Timing = %q, want the time of both queries", rec.Header().Values(Header))
+	}
+
+	// Queries outside a timed request are not timed
+	ctx := tracer.TraceQueryStart(context.Background(), nil, pgx.TraceQueryStartData{})
+	tracer.TraceQueryEnd(ctx, nil, pgx.TraceQueryEndData{})
+}
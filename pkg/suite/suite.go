@@ -0,0 +1,205 @@
+// Package suite parses run configuration files: a matrix of scenarios,
+// protocols, concurrency levels and durations that `benchmark run --config`
+// expands into one run per combination and executes in sequence.
+//
+// A minimal suite:
+//
+//	version: 1
+//	name: protocol-matrix
+//	scenarios: [balance, stream]
+//	protocols: [grpc, rest]
+//	concurrency: [10, 50, 100]
+//	durations: [30s]
+//
+// An optional db_targets list adds the database as a dimension: the servers
+// are switched to each target (started with --db-target) before its runs.
+//
+// JSON files with the same keys are accepted too.
+package suite
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/kaldun-tech/grpc-rest-benchmark/pkg/bench"
+)
+
+// Version is the suite schema version understood by this package.
+const Version = 1
+
+// Suite is a parsed run configuration file.
+type Suite struct {
+	Version     int    `yaml:"version"`
+	Name        string `yaml:"name"`
+	Description string `yaml:"description,omitempty"`
+
+	// The matrix: every combination is one run
+	Scenarios   []string        `yaml:"scenarios"`
+	Protocols   []string        `yaml:"protocols"`
+	Concurrency []int           `yaml:"concurrency"`
+	Durations   []time.Duration `yaml:"durations"`
+	DBTargets   []string        `yaml:"db_targets,omitempty"` // optional server database targets
+
+	// Settings shared by every run; flags the file does not set are kept
+	Rate  int           `yaml:"rate,omitempty"`  // target rate, as --rate (0 = unlimited)
+	Pause time.Duration `yaml:"pause,omitempty"` // idle time between runs
+}
+
+// Run is one combination of the matrix.
+type Run struct {
+	Scenario    string
+	Protocol    string
+	Concurrency int
+	Duration    time.Duration
+	DBTarget    string // empty without db_targets
+}
+
+func (r Run) String() string {
+	s := fmt.Sprintf("%s %s concurrency=%d duration=%s", r.Scenario, r.Protocol, r.Concurrency, r.Duration)
+	if r.DBTarget != "" {
+		s += " db=" + r.DBTarget
+	}
+	return s
+}
+
+// Load reads and validates a suite file.
+func Load(path string) (*Suite, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	s, err := Parse(b)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return s, nil
+}
+
+// Parse decodes and validates a suite in YAML or JSON. Unknown fields are
+// rejected so typos surface before anything runs.
+func Parse(b []byte) (*Suite, error) {
+	dec := yaml.NewDecoder(bytes.NewReader(b))
+	dec.KnownFields(true)
+
+	var s Suite
+	if err := dec.Decode(&s); err != nil {
+		return nil, fmt.Errorf("invalid suite: %w", err)
+	}
+	if err := s.Validate(); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+// Validate checks the suite for missing or invalid fields. All problems are
+// reported together.
+func (s *Suite) Validate() error {
+	var errs []error
+	add := func(format string, args ...any) {
+		errs = append(errs, fmt.Errorf(format, args...))
+	}
+
+	if s.Version != Version {
+		add("version: must be %d, got %d", Version, s.Version)
+	}
+	if s.Name == "" {
+		add("name: required")
+	}
+	checkList(add, "scenarios", s.Scenarios, func(v string) error {
+		if !slices.Contains(bench.Scenarios, v) {
+			return fmt.Errorf("%q is not one of %s", v, strings.Join(bench.Scenarios, ", "))
+		}
+		return nil
+	})
+	checkList(add, "protocols", s.Protocols, func(v string) error {
+		if !slices.Contains(bench.Protocols, v) {
+			return fmt.Errorf("%q is not one of %s", v, strings.Join(bench.Protocols, ", "))
+		}
+		return nil
+	})
+	checkList(add, "concurrency", s.Concurrency, func(v int) error {
+		if v < 1 {
+			return fmt.Errorf("must be at least 1, got %d", v)
+		}
+		return nil
+	})
+	checkList(add, "durations", s.Durations, func(v time.Duration) error {
+		if v < time.Second {
+			return fmt.Errorf("must be at least 1s, got %s", v)
+		}
+		return nil
+	})
+	if len(s.DBTargets) > 0 {
+		checkList(add, "db_targets", s.DBTargets, func(v string) error {
+			if v == "" {
+				return errors.New("must not be empty")
+			}
+			return nil
+		})
+	}
+	if s.Rate < 0 {
+		add("rate: must not be negative")
+	}
+	if s.Pause < 0 {
+		add("pause: must not be negative")
+	}
+	return errors.Join(errs...)
+}
+
+// checkList reports an empty list, duplicate values and the values check
+// rejects.
+func checkList[T comparable](add func(string, ...any), name string, values []T, check func(T) error) {
+	if len(values) == 0 {
+		add("%s: at least one value required", name)
+		return
+	}
+	for i, v := range values {
+		if err := check(v); err != nil {
+			add("%s[%d]: %v", name, i, err)
+		}
+		if slices.Index(values, v) != i {
+			add("%s[%d]: duplicate value %v", name, i, v)
+		}
+	}
+}
+
+// Runs expands the matrix into its runs. Protocols vary fastest, so the
+// runs that compare protocols at one setting execute back to back, then
+// durations, concurrency levels and scenarios. Database targets vary
+// slowest, so the servers switch database once per target.
+func (s *Suite) Runs() []Run {
+	targets := s.DBTargets
+	if len(targets) == 0 {
+		targets = []string{""}
+	}
+	runs := make([]Run, 0, len(targets)*len(s.Scenarios)*len(s.Concurrency)*len(s.Durations)*len(s.Protocols))
+	for _, target := range targets {
+		for _, scenario := range s.Scenarios {
+			for _, concurrency := range s.Concurrency {
+				for _, duration := range s.Durations {
+					for _, protocol := range s.Protocols {
+						runs = append(runs, Run{Scenario: scenario, Protocol: protocol, Concurrency: concurrency, Duration: duration, DBTarget: target})
+					}
+				}
+			}
+		}
+	}
+	return runs
+}
+
+// Duration returns the total run time of the suite, pauses included.
+func (s *Suite) Duration() time.Duration {
+	runs := s.Runs()
+	var total time.Duration
+	for _, r := range runs {
+		total += r.Duration
+	}
+	return total + time.Duration(len(runs)-1)*s.Pause
+}
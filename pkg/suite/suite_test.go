@@ -0,0 +1,98 @@
+package suite
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+const matrix = `
+version: 1
+name: test
+scenarios: [balance, stream]
+protocols: [grpc, rest]
+concurrency: [10, 50]
+durations: [30s]
+pause: 5s
+`
+
+func TestParse_Runs(t *testing.T) {
+	s, err := Parse([]byte(matrix))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	runs := s.Runs()
+	if len(runs) != 8 {
+		t.Fatalf("Runs() = %d runs, want 2 scenarios x 2 protocols x 2 concurrency levels", len(runs))
+	}
+	want := []Run{
+		{"balance", "grpc", 10, 30 * time.Second, ""},
+		{"balance", "rest", 10, 30 * time.Second, ""},
+		{"balance", "grpc", 50, 30 * time.Second, ""},
+	}
+	for i, w := range want {
+		if runs[i] != w {
+			t.Errorf("Runs()[%d] = %v, want %v", i, runs[i], w)
+		}
+	}
+	if got, want := s.Duration(), 8*30*time.Second+7*5*time.Second; got != want {
+		t.Errorf("Duration() = %v, want %v", got, want)
+	}
+}
+
+func TestParse_DBTargets(t *testing.T) {
+	s, err := Parse([]byte(matrix + "db_targets: [local, rds]\n"))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	runs := s.Runs()
+	if len(runs) != 16 {
+		t.Fatalf("Runs() = %d runs, want 2 targets x 8", len(runs))
+	}
+	for i, r := range runs {
+		if want := s.DBTargets[i/8]; r.DBTarget != want {
+			t.Errorf("Runs()[%d].DBTarget = %q, want %q: targets vary slowest", i, r.DBTarget, want)
+		}
+	}
+	if got, want := runs[8].String(), "balance grpc concurrency=10 duration=30s db=rds"; got != want {
+		t.Errorf("Runs()[8].String() = %q, want %q", got, want)
+	}
+}
+
+func TestParse_JSON(t *testing.T) {
+	s, err := Parse([]byte(`{"version": 1, "name": "json", "scenarios": ["echo"], "protocols": ["connect"],
+		"concurrency": [4], "durations": ["1m"], "rate": 100}`))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if runs := s.Runs(); len(runs) != 1 || runs[0].Duration != time.Minute || s.Rate != 100 {
+		t.Errorf("Runs() = %v with rate %d, want one 1m echo run at rate 100", runs, s.Rate)
+	}
+}
+
+func TestParse_Invalid(t *testing.T) {
+	tests := []struct {
+		name string
+		old  string
+		new  string
+		want string
+	}{
+		{"unknown scenario", "[balance, stream]", "[balance, soap]", `scenarios[1]: "soap" is not one of`},
+		{"duplicate protocol", "[grpc, rest]", "[grpc, grpc]", "protocols[1]: duplicate value grpc"},
+		{"no concurrency", "concurrency: [10, 50]", "concurrency: []", "concurrency: at least one value required"},
+		{"short duration", "[30s]", "[500ms]", "durations[0]: must be at least 1s"},
+		{"unknown field", "pause: 5s", "pasue: 5s", "field pasue not found"},
+		{"duplicate db target", "pause: 5s", "db_targets: [rds, rds]", "db_targets[1]: duplicate value rds"},
+		{"empty db target", "pause: 5s", `db_targets: [""]`, "db_targets[0]: must not be empty"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse([]byte(strings.Replace(matrix, tt.old, tt.new, 1)))
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Parse() error = %v, want it to contain %q", err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,227 @@
+package timing
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+)
+
+// Distribution is a fitted inter-arrival distribution over milliseconds.
+type Distribution interface {
+	// Name identifies the distribution family, e.g. "lognormal".
+	Name() string
+	// Params returns the fitted parameters by name.
+	Params() map[string]float64
+	// Mean returns the mean inter-arrival time, which may be +Inf.
+	Mean() float64
+	// CDF returns P(X <= x).
+	CDF(x float64) float64
+	// Sample draws a random inter-arrival time.
+	Sample(rng *rand.Rand) float64
+	String() string
+}
+
+// Exponential is an exponential inter-arrival distribution, the model for a
+// Poisson arrival process.
+type Exponential struct {
+	Rate float64 // λ, arrivals per millisecond
+}
+
+// Mean returns the mean inter-arrival time in milliseconds.
+func (e Exponential) Mean() float64 {
+	return 1 / e.Rate
+}
+
+// CDF returns P(X <= x) for x in milliseconds.
+func (e Exponential) CDF(x float64) float64 {
+	if x <= 0 {
+		return 0
+	}
+	return 1 - math.Exp(-e.Rate*x)
+}
+
+// Name implements Distribution.
+func (e Exponential) Name() string { return "exponential" }
+
+// Params implements Distribution.
+func (e Exponential) Params() map[string]float64 {
+	return map[string]float64{"rate": e.Rate}
+}
+
+// Sample implements Distribution.
+func (e Exponential) Sample(rng *rand.Rand) float64 {
+	return rng.ExpFloat64() / e.Rate
+}
+
+func (e Exponential) String() string {
+	return fmt.Sprintf("exponential(λ=%.4g/ms, mean=%.1fms)", e.Rate, e.Mean())
+}
+
+// Lognormal is a log-normal inter-arrival distribution: ln(X) is normally
+// distributed with mean Mu and standard deviation Sigma (X in milliseconds).
+// It captures the heavy right tail of bursty traffic better than an
+// exponential.
+type Lognormal struct {
+	Mu    float64
+	Sigma float64
+}
+
+// Mean returns the mean inter-arrival time in milliseconds.
+func (l Lognormal) Mean() float64 {
+	return math.Exp(l.Mu + l.Sigma*l.Sigma/2)
+}
+
+// Median returns the median inter-arrival time in milliseconds.
+func (l Lognormal) Median() float64 {
+	return math.Exp(l.Mu)
+}
+
+// CDF returns P(X <= x) for x in milliseconds.
+func (l Lognormal) CDF(x float64) float64 {
+	if x <= 0 {
+		return 0
+	}
+	if l.Sigma == 0 {
+		if math.Log(x) < l.Mu {
+			return 0
+		}
+		return 1
+	}
+	return 0.5 * math.Erfc(-(math.Log(x)-l.Mu)/(l.Sigma*math.Sqrt2))
+}
+
+// Name implements Distribution.
+func (l Lognormal) Name() string { return "lognormal" }
+
+// Params implements Distribution.
+func (l Lognormal) Params() map[string]float64 {
+	return map[string]float64{"mu": l.Mu, "sigma": l.Sigma}
+}
+
+// Sample implements Distribution.
+func (l Lognormal) Sample(rng *rand.Rand) float64 {
+	return math.Exp(l.Mu + l.Sigma*rng.NormFloat64())
+}
+
+func (l Lognormal) String() string {
+	return fmt.Sprintf("lognormal(μ=%.4g, σ=%.4g, median=%.1fms, mean=%.1fms)", l.Mu, l.Sigma, l.Median(), l.Mean())
+}
+
+// Pareto is a Pareto (power-law) inter-arrival distribution with minimum Xm
+// milliseconds and shape Alpha. Smaller Alpha means a heavier tail; the mean
+// is infinite for Alpha <= 1.
+type Pareto struct {
+	Xm    float64
+	Alpha float64
+}
+
+// Mean returns the mean inter-arrival time in milliseconds, or +Inf if
+// Alpha <= 1.
+func (p Pareto) Mean() float64 {
+	if p.Alpha <= 1 {
+		return math.Inf(1)
+	}
+	return p.Alpha * p.Xm / (p.Alpha - 1)
+}
+
+// CDF returns P(X <= x) for x in milliseconds.
+func (p Pareto) CDF(x float64) float64 {
+	if x < p.Xm {
+		return 0
+	}
+	return 1 - math.Pow(p.Xm/x, p.Alpha)
+}
+
+// Name implements Distribution.
+func (p Pareto) Name() string { return "pareto" }
+
+// Params implements Distribution.
+func (p Pareto) Params() map[string]float64 {
+	return map[string]float64{"xm": p.Xm, "alpha": p.Alpha}
+}
+
+// Sample implements Distribution.
+func (p Pareto) Sample(rng *rand.Rand) float64 {
+	// 1 - Float64() is in (0, 1], avoiding division by zero
+	return p.Xm / math.Pow(1-rng.Float64(), 1/p.Alpha)
+}
+
+func (p Pareto) String() string {
+	return fmt.Sprintf("pareto(xm=%.4gms, α=%.4g)", p.Xm, p.Alpha)
+}
+
+// positive returns the strictly positive values in samples. Zero gaps
+// (messages sharing a consensus timestamp) carry no information for these
+// fits and are excluded.
+func positive(samples []float64) []float64 {
+	out := make([]float64, 0, len(samples))
+	for _, v := range samples {
+		if v > 0 {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+// FitExponential returns the maximum-likelihood exponential fit of the
+// positive inter-arrival samples (in milliseconds).
+func FitExponential(samples []float64) (Exponential, error) {
+	values := positive(samples)
+	if len(values) == 0 {
+		return Exponential{}, fmt.Errorf("no positive inter-arrival samples to fit")
+	}
+
+	var sum float64
+	for _, v := range values {
+		sum += v
+	}
+	return Exponential{Rate: float64(len(values)) / sum}, nil
+}
+
+// FitLognormal returns the maximum-likelihood log-normal fit of the positive
+// inter-arrival samples (in milliseconds).
+func FitLognormal(samples []float64) (Lognormal, error) {
+	values := positive(samples)
+	if len(values) == 0 {
+		return Lognormal{}, fmt.Errorf("no positive inter-arrival samples to fit")
+	}
+
+	var sum float64
+	for _, v := range values {
+		sum += math.Log(v)
+	}
+	mu := sum / float64(len(values))
+
+	var sq float64
+	for _, v := range values {
+		d := math.Log(v) - mu
+		sq += d * d
+	}
+	sigma := math.Sqrt(sq / float64(len(values)))
+
+	return Lognormal{Mu: mu, Sigma: sigma}, nil
+}
+
+// FitPareto returns the maximum-likelihood Pareto fit of the positive
+// inter-arrival samples (in milliseconds).
+func FitPareto(samples []float64) (Pareto, error) {
+	values := positive(samples)
+	if len(values) == 0 {
+		return Pareto{}, fmt.Errorf("no positive inter-arrival samples to fit")
+	}
+
+	xm := values[0]
+	for _, v := range values[1:] {
+		xm = min(xm, v)
+	}
+
+	var sum float64
+	for _, v := range values {
+		sum += math.Log(v / xm)
+	}
+	if sum == 0 {
+		return Pareto{}, fmt.Errorf("cannot fit pareto: all samples are equal")
+	}
+
+	return Pareto{Xm: xm, Alpha: float64(len(values)) / sum}, nil
+}
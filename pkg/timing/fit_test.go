@@ -0,0 +1,101 @@
+package timing
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestFitExponential(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	samples := make([]float64, 20000)
+	for i := range samples {
+		samples[i] = rng.ExpFloat64() * 50 // mean 50ms
+	}
+
+	fit, err := FitExponential(samples)
+	if err != nil {
+		t.Fatalf("FitExponential() error = %v", err)
+	}
+	if math.Abs(fit.Mean()-50) > 2 {
+		t.Errorf("Mean() = %.2f, want ~50", fit.Mean())
+	}
+	if got := fit.CDF(fit.Mean()); math.Abs(got-(1-1/math.E)) > 1e-9 {
+		t.Errorf("CDF(mean) = %f, want 1-1/e", got)
+	}
+}
+
+func TestFitLognormal(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	samples := make([]float64, 20000)
+	for i := range samples {
+		samples[i] = math.Exp(3 + 0.8*rng.NormFloat64())
+	}
+
+	fit, err := FitLognormal(samples)
+	if err != nil {
+		t.Fatalf("FitLognormal() error = %v", err)
+	}
+	if math.Abs(fit.Mu-3) > 0.05 {
+		t.Errorf("Mu = %.3f, want ~3", fit.Mu)
+	}
+	if math.Abs(fit.Sigma-0.8) > 0.05 {
+		t.Errorf("Sigma = %.3f, want ~0.8", fit.Sigma)
+	}
+	if got := fit.CDF(fit.Median()); math.Abs(got-0.5) > 1e-9 {
+		t.Errorf("CDF(median) = %f, want 0.5", got)
+	}
+}
+
+func TestFit_IgnoresNonPositive(t *testing.T) {
+	samples := []float64{0, 0, 10, 10, -1}
+
+	exp, err := FitExponential(samples)
+	if err != nil {
+		t.Fatalf("FitExponential() error = %v", err)
+	}
+	if exp.Mean() != 10 {
+		t.Errorf("exponential Mean() = %v, want 10", exp.Mean())
+	}
+
+	ln, err := FitLognormal(samples)
+	if err != nil {
+		t.Fatalf("FitLognormal() error = %v", err)
+	}
+	if ln.Sigma != 0 || math.Abs(ln.Median()-10) > 1e-9 {
+		t.Errorf("lognormal = %+v, want median 10 and sigma 0", ln)
+	}
+}
+
+func TestFit_NoSamples(t *testing.T) {
+	if _, err := FitExponential([]float64{0}); err == nil {
+		t.Error("FitExponential() expected error with no positive samples")
+	}
+	if _, err := FitLognormal(nil); err == nil {
+		t.Error("FitLognormal() expected error with no samples")
+	}
+}
+
+func TestFitPareto(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	want := Pareto{Xm: 5, Alpha: 2.5}
+	samples := make([]float64, 20000)
+	for i := range samples {
+		samples[i] = want.Sample(rng)
+	}
+
+	fit, err := FitPareto(samples)
+	if err != nil {
+		t.Fatalf("FitPareto() error = %v", err)
+	}
+	if math.Abs(fit.Xm-5) > 0.01 {
+		t.Errorf("Xm = %.3f, want ~5", fit.Xm)
+	}
+	if math.Abs(fit.Alpha-2.5) > 0.1 {
+		t.Errorf("Alpha = %.3f, want ~2.5", fit.Alpha)
+	}
+
+	if _, err := FitPareto([]float64{10, 10, 10}); err == nil {
+		t.Error("FitPareto() expected error when all samples are equal")
+	}
+}
@@ -0,0 +1,93 @@
+package timing
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"sort"
+)
+
+// FitResult is a fitted distribution with its goodness of fit.
+type FitResult struct {
+	Dist Distribution
+	// KS is the Kolmogorov-Smirnov statistic: the largest distance between
+	// the empirical and fitted CDFs. Smaller is better.
+	KS float64
+	// PValue is the asymptotic KS p-value. Parameters are estimated from the
+	// same samples, so it is optimistic; use it to rank fits rather than as
+	// a formal test.
+	PValue float64
+}
+
+// FitAll fits every candidate distribution (exponential, lognormal, Pareto)
+// to the positive samples and returns the results ordered best first by KS
+// statistic. Candidates that cannot be fitted are skipped.
+func FitAll(samples []float64) ([]FitResult, error) {
+	values := positive(samples)
+	if len(values) == 0 {
+		return nil, fmt.Errorf("no positive inter-arrival samples to fit")
+	}
+	sort.Float64s(values)
+
+	var dists []Distribution
+	if d, err := FitExponential(values); err == nil {
+		dists = append(dists, d)
+	}
+	if d, err := FitLognormal(values); err == nil {
+		dists = append(dists, d)
+	}
+	if d, err := FitPareto(values); err == nil {
+		dists = append(dists, d)
+	}
+
+	results := make([]FitResult, 0, len(dists))
+	for _, d := range dists {
+		ks := ksStatistic(values, d)
+		results = append(results, FitResult{Dist: d, KS: ks, PValue: ksPValue(ks, len(values))})
+	}
+	slices.SortStableFunc(results, func(a, b FitResult) int {
+		switch {
+		case a.KS < b.KS:
+			return -1
+		case a.KS > b.KS:
+			return 1
+		}
+		return 0
+	})
+
+	return results, nil
+}
+
+// ksStatistic returns the Kolmogorov-Smirnov distance between the sorted
+// samples and dist.
+func ksStatistic(sorted []float64, dist Distribution) float64 {
+	n := float64(len(sorted))
+	var d float64
+	for i, x := range sorted {
+		cdf := dist.CDF(x)
+		d = max(d, float64(i+1)/n-cdf, cdf-float64(i)/n)
+	}
+	return d
+}
+
+// ksPValue returns the asymptotic p-value of KS statistic d for n samples,
+// using Stephens' small-sample correction.
+func ksPValue(d float64, n int) float64 {
+	if d <= 0 {
+		return 1
+	}
+	sqrtN := math.Sqrt(float64(n))
+	lambda := (sqrtN + 0.12 + 0.11/sqrtN) * d
+
+	var sum float64
+	sign := 1.0
+	for k := 1; k <= 100; k++ {
+		term := sign * math.Exp(-2*float64(k*k)*lambda*lambda)
+		sum += term
+		if math.Abs(term) < 1e-12 {
+			break
+		}
+		sign = -sign
+	}
+	return min(max(2*sum, 0), 1)
+}
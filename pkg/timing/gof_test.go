@@ -0,0 +1,62 @@
+package timing
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestFitAll_RanksGeneratingDistributionFirst(t *testing.T) {
+	tests := []struct {
+		name string
+		dist Distribution
+	}{
+		{"exponential", Exponential{Rate: 0.02}},
+		{"lognormal", Lognormal{Mu: 3, Sigma: 0.8}},
+		{"pareto", Pareto{Xm: 5, Alpha: 1.5}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rng := rand.New(rand.NewSource(1))
+			samples := make([]float64, 5000)
+			for i := range samples {
+				samples[i] = tt.dist.Sample(rng)
+			}
+
+			fits, err := FitAll(samples)
+			if err != nil {
+				t.Fatalf("FitAll() error = %v", err)
+			}
+			if len(fits) != 3 {
+				t.Fatalf("FitAll() returned %d fits, want 3", len(fits))
+			}
+			if got := fits[0].Dist.Name(); got != tt.name {
+				t.Errorf("best fit = %s, want %s", got, tt.name)
+			}
+			if fits[0].KS > 0.05 {
+				t.Errorf("best fit KS = %.4f, want < 0.05", fits[0].KS)
+			}
+			if fits[0].PValue < 0.01 {
+				t.Errorf("best fit p-value = %.4f, want a plausible fit", fits[0].PValue)
+			}
+			for i := 1; i < len(fits); i++ {
+				if fits[i].KS < fits[i-1].KS {
+					t.Errorf("fits not ordered by KS: %v", fits)
+				}
+			}
+		})
+	}
+}
+
+func TestKSPValue(t *testing.T) {
+	if p := ksPValue(0, 100); p != 1 {
+		t.Errorf("ksPValue(0) = %f, want 1", p)
+	}
+	if p := ksPValue(0.5, 1000); p > 1e-6 {
+		t.Errorf("ksPValue(0.5, 1000) = %g, want ~0", p)
+	}
+	if p := ksPValue(0.05, 1000); math.Abs(p-0.0135) > 0.005 {
+		t.Errorf("ksPValue(0.05, 1000) = %f, want ~0.0135", p)
+	}
+}
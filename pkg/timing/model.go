@@ -0,0 +1,198 @@
+package timing
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"os"
+	"sort"
+	"time"
+)
+
+// Model is a fitted distribution saved in place of raw inter-arrival times.
+// It is a few hundred bytes regardless of how many messages were captured,
+// and Load expands it back into synthetic timing data.
+type Model struct {
+	Distribution string             `json:"distribution"`
+	Params       map[string]float64 `json:"params"`
+	MessageCount int                `json:"message_count"`
+
+	// Provenance of the data the model was fitted to
+	TopicID string  `json:"topic_id,omitempty"`
+	Network string  `json:"network,omitempty"`
+	KS      float64 `json:"ks,omitempty"`
+}
+
+// NewModel describes fit, fitted to data, as a model.
+func NewModel(fit FitResult, data *Data) *Model {
+	return &Model{
+		Distribution: fit.Dist.Name(),
+		Params:       fit.Dist.Params(),
+		MessageCount: data.MessageCount,
+		TopicID:      data.TopicID,
+		Network:      data.Network,
+		KS:           fit.KS,
+	}
+}
+
+// Dist returns the distribution described by the model.
+func (m *Model) Dist() (Distribution, error) {
+	param := func(name string) (float64, error) {
+		v, ok := m.Params[name]
+		if !ok {
+			return 0, fmt.Errorf("%s model is missing parameter %q", m.Distribution, name)
+		}
+		return v, nil
+	}
+
+	switch m.Distribution {
+	case "exponential":
+		rate, err := param("rate")
+		if err != nil {
+			return nil, err
+		}
+		if rate <= 0 {
+			return nil, fmt.Errorf("exponential rate must be positive")
+		}
+		return Exponential{Rate: rate}, nil
+	case "lognormal":
+		mu, err := param("mu")
+		if err != nil {
+			return nil, err
+		}
+		sigma, err := param("sigma")
+		if err != nil {
+			return nil, err
+		}
+		if sigma < 0 {
+			return nil, fmt.Errorf("lognormal sigma must not be negative")
+		}
+		return Lognormal{Mu: mu, Sigma: sigma}, nil
+	case "pareto":
+		xm, err := param("xm")
+		if err != nil {
+			return nil, err
+		}
+		alpha, err := param("alpha")
+		if err != nil {
+			return nil, err
+		}
+		if xm <= 0 || alpha <= 0 {
+			return nil, fmt.Errorf("pareto xm and alpha must be positive")
+		}
+		return Pareto{Xm: xm, Alpha: alpha}, nil
+	default:
+		return nil, fmt.Errorf("unknown distribution: %q", m.Distribution)
+	}
+}
+
+// SaveModel writes a model to a JSON file.
+func SaveModel(path string, m *Model) error {
+	b, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode model: %w", err)
+	}
+	return os.WriteFile(path, append(b, '\n'), 0644)
+}
+
+// LoadModel reads a model from a JSON file.
+func LoadModel(path string) (*Model, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var m Model
+	if err := json.Unmarshal(b, &m); err != nil {
+		return nil, fmt.Errorf("failed to parse model: %w", err)
+	}
+	if m.Distribution == "" {
+		return nil, fmt.Errorf("%s is not a timing model (no distribution)", path)
+	}
+	return &m, nil
+}
+
+// isModelFile reports whether path holds a Model rather than raw timing data.
+func isModelFile(path string) bool {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return false
+	}
+	var probe struct {
+		Distribution string `json:"distribution"`
+	}
+	return json.Unmarshal(b, &probe) == nil && probe.Distribution != ""
+}
+
+// GenerateFromModel draws the model's message count (or count, if positive)
+// of inter-arrival times from the fitted distribution.
+func GenerateFromModel(m *Model, count int, rng *rand.Rand) (*Data, error) {
+	dist, err := m.Dist()
+	if err != nil {
+		return nil, err
+	}
+	if count <= 0 {
+		count = m.MessageCount
+	}
+	if count <= 0 {
+		return nil, fmt.Errorf("model has no message count; specify how many samples to generate")
+	}
+
+	data := Generate(dist, count, rng)
+	if m.TopicID != "" {
+		data.TopicID = m.TopicID
+		data.Network = m.Network
+	}
+	return data, nil
+}
+
+// Generate draws count inter-arrival times from dist and returns them as
+// timing data with summary statistics filled in.
+func Generate(dist Distribution, count int, rng *rand.Rand) *Data {
+	if rng == nil {
+		rng = rand.New(rand.NewSource(time.Now().UnixNano()))
+	}
+
+	values := make([]float64, count)
+	for i := range values {
+		values[i] = dist.Sample(rng)
+	}
+	return newData(values, "synthetic", "generated")
+}
+
+// newData builds timing data with summary statistics from inter-arrival times.
+func newData(values []float64, topicID, network string) *Data {
+	data := &Data{
+		TopicID:        topicID,
+		Network:        network,
+		MessageCount:   len(values),
+		InterArrivalMs: values,
+	}
+	if len(values) == 0 {
+		return data
+	}
+
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+
+	var sum float64
+	for _, v := range values {
+		sum += v
+	}
+	percentile := func(p float64) float64 {
+		return sorted[min(int(p*float64(len(sorted))), len(sorted)-1)]
+	}
+
+	data.TimeSpanSeconds = sum / 1000
+	if sum > 0 {
+		data.AvgRatePerSecond = float64(len(values)) / data.TimeSpanSeconds
+	}
+	data.Stats = Stats{
+		MinMs: sorted[0],
+		MaxMs: sorted[len(sorted)-1],
+		AvgMs: sum / float64(len(values)),
+		P50Ms: percentile(0.50),
+		P90Ms: percentile(0.90),
+		P99Ms: percentile(0.99),
+	}
+	return data
+}
@@ -0,0 +1,86 @@
+package timing
+
+import (
+	"math"
+	"math/rand"
+	"path/filepath"
+	"testing"
+)
+
+func TestModel_RoundTrip(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	source := Generate(Lognormal{Mu: 3, Sigma: 0.5}, 2000, rng)
+	source.TopicID = "0.0.1234"
+	source.Network = "testnet"
+
+	fits, err := FitAll(source.InterArrivalMs)
+	if err != nil {
+		t.Fatalf("FitAll() error = %v", err)
+	}
+
+	path := filepath.Join(t.TempDir(), "model.json")
+	if err := SaveModel(path, NewModel(fits[0], source)); err != nil {
+		t.Fatalf("SaveModel() error = %v", err)
+	}
+
+	m, err := LoadModel(path)
+	if err != nil {
+		t.Fatalf("LoadModel() error = %v", err)
+	}
+	if m.Distribution != "lognormal" || m.MessageCount != 2000 || m.TopicID != "0.0.1234" {
+		t.Errorf("LoadModel() = %+v", m)
+	}
+
+	// Load expands a model file into generated timing data
+	data, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load(model) error = %v", err)
+	}
+	if data.MessageCount != 2000 || len(data.InterArrivalMs) != 2000 {
+		t.Errorf("generated %d samples, want 2000", len(data.InterArrivalMs))
+	}
+	if math.Abs(data.Stats.P50Ms-math.Exp(3)) > 2 {
+		t.Errorf("generated p50 = %.1fms, want ~%.1fms", data.Stats.P50Ms, math.Exp(3))
+	}
+}
+
+func TestModel_Dist(t *testing.T) {
+	tests := []struct {
+		name    string
+		model   Model
+		wantErr bool
+	}{
+		{"exponential", Model{Distribution: "exponential", Params: map[string]float64{"rate": 0.1}}, false},
+		{"pareto", Model{Distribution: "pareto", Params: map[string]float64{"xm": 1, "alpha": 2}}, false},
+		{"missing param", Model{Distribution: "lognormal", Params: map[string]float64{"mu": 1}}, true},
+		{"bad rate", Model{Distribution: "exponential", Params: map[string]float64{"rate": 0}}, true},
+		{"unknown", Model{Distribution: "weibull"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dist, err := tt.model.Dist()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Dist() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && dist.Name() != tt.model.Distribution {
+				t.Errorf("Dist().Name() = %s, want %s", dist.Name(), tt.model.Distribution)
+			}
+		})
+	}
+}
+
+func TestGenerateFromModel_Count(t *testing.T) {
+	m := &Model{Distribution: "exponential", Params: map[string]float64{"rate": 0.1}}
+	if _, err := GenerateFromModel(m, 0, nil); err == nil {
+		t.Error("GenerateFromModel() expected error without a count")
+	}
+
+	data, err := GenerateFromModel(m, 500, rand.New(rand.NewSource(1)))
+	if err != nil {
+		t.Fatalf("GenerateFromModel() error = %v", err)
+	}
+	if data.MessageCount != 500 {
+		t.Errorf("MessageCount = %d, want 500", data.MessageCount)
+	}
+}
@@ -0,0 +1,77 @@
+package timing
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Schedule is loaded timing data plus the replay settings. It is shared
+// read-only; each paced stream gets its own Pacer.
+type Schedule struct {
+	data    *Data
+	mode    string
+	speedup float64
+}
+
+// LoadSchedule loads a timing file. mode is ModeSequential or ModeSample;
+// speedup scales replay speed (1.0 = real-time, 10.0 = 10x faster).
+func LoadSchedule(path, mode string, speedup float64) (*Schedule, error) {
+	data, err := Load(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load timing data: %w", err)
+	}
+	return NewSchedule(data, mode, speedup)
+}
+
+// NewSchedule creates a schedule from timing data already in memory.
+func NewSchedule(data *Data, mode string, speedup float64) (*Schedule, error) {
+	if mode != ModeSequential && mode != ModeSample {
+		return nil, fmt.Errorf("invalid replay mode: %s (use %s or %s)", mode, ModeSequential, ModeSample)
+	}
+	if speedup <= 0 {
+		return nil, fmt.Errorf("speedup must be positive")
+	}
+
+	return &Schedule{data: data, mode: mode, speedup: speedup}, nil
+}
+
+// EffectiveRate returns the average events per second of a single pacer.
+func (s *Schedule) EffectiveRate() float64 {
+	return s.data.AvgRatePerSecond * s.speedup
+}
+
+// String summarizes the schedule for startup logs.
+func (s *Schedule) String() string {
+	return fmt.Sprintf("%s topic %s, %d messages, mode %s, speedup %.1fx (~%.2f events/s per stream)",
+		s.data.Network, s.data.TopicID, s.data.MessageCount, s.mode, s.speedup, s.EffectiveRate())
+}
+
+// NewPacer returns a pacer that starts from the beginning of the schedule.
+func (s *Schedule) NewPacer() *Pacer {
+	return &Pacer{replay: NewReplay(s.data, s.mode, s.speedup)}
+}
+
+// Pacer spaces out the events of a single stream.
+type Pacer struct {
+	replay *Replay
+}
+
+// Wait blocks for the next inter-arrival delay. It returns ctx.Err() if the
+// context is cancelled first.
+func (p *Pacer) Wait(ctx context.Context) error {
+	delay := p.replay.NextDelay()
+	if delay <= 0 {
+		return ctx.Err()
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
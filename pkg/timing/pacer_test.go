@@ -0,0 +1,76 @@
+package timing
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/kaldun-tech/hiero-hcs-replay"
+)
+
+func testData() *hcsreplay.TimingData {
+	return &hcsreplay.TimingData{
+		TopicID:          "0.0.123456",
+		Network:          "testnet",
+		MessageCount:     4,
+		TimeSpanSeconds:  0.04,
+		AvgRatePerSecond: 100,
+		InterArrivalMs:   []float64{10, 10, 10, 10},
+	}
+}
+
+func TestNewSchedule_InvalidMode(t *testing.T) {
+	if _, err := NewSchedule(testData(), "burst", 1.0); err == nil {
+		t.Error("NewSchedule() with invalid mode should fail")
+	}
+}
+
+func TestNewSchedule_InvalidSpeedup(t *testing.T) {
+	if _, err := NewSchedule(testData(), ModeSample, 0); err == nil {
+		t.Error("NewSchedule() with zero speedup should fail")
+	}
+}
+
+func TestSchedule_EffectiveRate(t *testing.T) {
+	s, err := NewSchedule(testData(), ModeSequential, 10)
+	if err != nil {
+		t.Fatalf("NewSchedule() error = %v", err)
+	}
+	if got := s.EffectiveRate(); got != 1000 {
+		t.Errorf("EffectiveRate() = %v, want 1000", got)
+	}
+}
+
+func TestPacer_Wait(t *testing.T) {
+	s, err := NewSchedule(testData(), ModeSequential, 1.0)
+	if err != nil {
+		t.Fatalf("NewSchedule() error = %v", err)
+	}
+	p := s.NewPacer()
+
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		if err := p.Wait(context.Background()); err != nil {
+			t.Fatalf("Wait() error = %v", err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed < 25*time.Millisecond {
+		t.Errorf("3 waits took %v, want at least ~30ms", elapsed)
+	}
+}
+
+func TestPacer_Wait_Cancelled(t *testing.T) {
+	data := testData()
+	data.InterArrivalMs = []float64{10_000}
+	s, err := NewSchedule(data, ModeSequential, 1.0)
+	if err != nil {
+		t.Fatalf("NewSchedule() error = %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if err := s.NewPacer().Wait(ctx); err != context.Canceled {
+		t.Errorf("Wait() error = %v, want context.Canceled", err)
+	}
+}
@@ -0,0 +1,137 @@
+// Package timing loads, replays and models recorded message inter-arrival
+// times, so benchmark traffic reproduces real Hedera Consensus Service burst
+// patterns instead of uniform ticks. Timing files use the hcsreplay JSON
+// format.
+package timing
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/kaldun-tech/hiero-hcs-replay"
+)
+
+// Replay modes.
+const (
+	ModeSequential = "sequential" // replay inter-arrivals in recorded order
+	ModeSample     = "sample"     // draw inter-arrivals at random
+)
+
+// Data is recorded inter-arrival timing for a stream of messages, in the
+// hcsreplay JSON format.
+type Data = hcsreplay.TimingData
+
+// Stats summarizes the inter-arrival times in Data.
+type Stats = hcsreplay.Stats
+
+// Load reads timing data from a JSON file. The file may hold recorded
+// inter-arrival times or a fitted Model, in which case synthetic timing data
+// is generated from it.
+func Load(path string) (*Data, error) {
+	if isModelFile(path) {
+		m, err := LoadModel(path)
+		if err != nil {
+			return nil, err
+		}
+		return GenerateFromModel(m, 0, nil)
+	}
+	return hcsreplay.LoadTiming(path)
+}
+
+// Save writes timing data to a JSON file for later reuse.
+func Save(path string, data *Data) error {
+	return hcsreplay.SaveTiming(path, data)
+}
+
+// Fetch retrieves timing data for an HCS topic from the Hedera Mirror Node
+// REST API. network is mainnet, testnet or previewnet. onProgress, if not
+// nil, is called with the running message count.
+func Fetch(ctx context.Context, topicID, network string, limit int, onProgress func(int)) (*Data, error) {
+	var net hcsreplay.Network
+	switch network {
+	case "mainnet":
+		net = hcsreplay.Mainnet
+	case "testnet":
+		net = hcsreplay.Testnet
+	case "previewnet":
+		net = hcsreplay.Previewnet
+	default:
+		return nil, fmt.Errorf("unknown network: %s (use mainnet, testnet, or previewnet)", network)
+	}
+
+	opts := hcsreplay.DefaultFetchOptions()
+	opts.OnProgress = onProgress
+
+	return hcsreplay.FetchTimingWithOptions(ctx, topicID, net, limit, opts)
+}
+
+// GenerateSynthetic creates log-normally distributed timing data with the
+// given mean and standard deviation, for testing without a recorded file.
+func GenerateSynthetic(count int, avgMs, stddevMs float64) *Data {
+	return hcsreplay.GenerateSynthetic(count, avgMs, stddevMs)
+}
+
+// Replay produces inter-arrival delays from timing data. It is safe for
+// concurrent use; all callers share one position in the schedule.
+type Replay struct {
+	replay *hcsreplay.Replay
+}
+
+// NewReplay creates a replay of data. mode is ModeSequential (exact order)
+// or ModeSample (random sampling, also used for unrecognized modes). speedup
+// controls replay speed (1.0 = real-time, 10.0 = 10x faster); non-positive
+// values mean real-time.
+func NewReplay(data *Data, mode string, speedup float64) *Replay {
+	replayMode := hcsreplay.ModeSample
+	if mode == ModeSequential {
+		replayMode = hcsreplay.ModeSequential
+	}
+
+	return &Replay{
+		replay: hcsreplay.NewReplay(data, replayMode, speedup),
+	}
+}
+
+// NextDelay returns the delay to wait before the next operation.
+func (r *Replay) NextDelay() time.Duration {
+	return r.replay.NextDelay()
+}
+
+// EffectiveRate returns the message rate after applying speedup.
+func (r *Replay) EffectiveRate() float64 {
+	return r.replay.EffectiveRate()
+}
+
+// Data returns the underlying timing data.
+func (r *Replay) Data() *Data {
+	return r.replay.Data()
+}
+
+// Mode returns the replay mode.
+func (r *Replay) Mode() string {
+	return string(r.replay.Mode())
+}
+
+// Speedup returns the replay speed multiplier.
+func (r *Replay) Speedup() float64 {
+	return r.replay.Speedup()
+}
+
+// WriteSummary writes a human-readable description of the replay, including
+// fitted distribution parameters, to w.
+func (r *Replay) WriteSummary(w io.Writer) {
+	data := r.Data()
+	fmt.Fprintf(w, "Timing replay loaded:\n")
+	fmt.Fprintf(w, "  Source: %s topic %s\n", data.Network, data.TopicID)
+	fmt.Fprintf(w, "  Messages: %d over %.1fs (%.2f msg/s)\n",
+		data.MessageCount, data.TimeSpanSeconds, data.AvgRatePerSecond)
+	fmt.Fprintf(w, "  Inter-arrival: p50=%.1fms, p99=%.1fms\n",
+		data.Stats.P50Ms, data.Stats.P99Ms)
+	if fits, err := FitAll(data.InterArrivalMs); err == nil {
+		fmt.Fprintf(w, "  Best fit: %s (KS=%.3f)\n", fits[0].Dist, fits[0].KS)
+	}
+	fmt.Fprintf(w, "  Mode: %s, speedup: %.1fx\n", r.Mode(), r.Speedup())
+	fmt.Fprintf(w, "  Effective rate: ~%.2f req/s per worker\n", r.EffectiveRate())
+}
@@ -1,4 +1,4 @@
-package main
+package timing
 
 import (
 	"encoding/json"
@@ -6,20 +6,18 @@ import (
 	"path/filepath"
 	"testing"
 	"time"
-
-	"github.com/kaldun-tech/hiero-hcs-replay"
 )
 
-func TestLoadTimingData(t *testing.T) {
+func TestLoad(t *testing.T) {
 	// Create a temporary timing file
-	data := hcsreplay.TimingData{
+	data := Data{
 		TopicID:          "0.0.123456",
 		Network:          "testnet",
 		MessageCount:     5,
 		TimeSpanSeconds:  10.0,
 		AvgRatePerSecond: 0.5,
 		InterArrivalMs:   []float64{100, 200, 150, 300, 250},
-		Stats: hcsreplay.Stats{
+		Stats: Stats{
 			MinMs: 100,
 			MaxMs: 300,
 			AvgMs: 200,
@@ -42,9 +40,9 @@ func TestLoadTimingData(t *testing.T) {
 	}
 
 	// Test loading
-	loaded, err := LoadTimingData(tmpFile)
+	loaded, err := Load(tmpFile)
 	if err != nil {
-		t.Fatalf("LoadTimingData() error = %v", err)
+		t.Fatalf("Load() error = %v", err)
 	}
 
 	if loaded.TopicID != data.TopicID {
@@ -58,14 +56,14 @@ func TestLoadTimingData(t *testing.T) {
 	}
 }
 
-func TestLoadTimingData_NotFound(t *testing.T) {
-	_, err := LoadTimingData("/nonexistent/path/timing.json")
+func TestLoad_NotFound(t *testing.T) {
+	_, err := Load("/nonexistent/path/timing.json")
 	if err == nil {
-		t.Error("LoadTimingData() expected error for non-existent file, got nil")
+		t.Error("Load() expected error for non-existent file, got nil")
 	}
 }
 
-func TestLoadTimingData_InvalidJSON(t *testing.T) {
+func TestLoad_InvalidJSON(t *testing.T) {
 	tmpDir := t.TempDir()
 	tmpFile := filepath.Join(tmpDir, "invalid.json")
 
@@ -73,17 +71,17 @@ func TestLoadTimingData_InvalidJSON(t *testing.T) {
 		t.Fatalf("Failed to write test file: %v", err)
 	}
 
-	_, err := LoadTimingData(tmpFile)
+	_, err := Load(tmpFile)
 	if err == nil {
-		t.Error("LoadTimingData() expected error for invalid JSON, got nil")
+		t.Error("Load() expected error for invalid JSON, got nil")
 	}
 }
 
-func TestLoadTimingData_EmptyInterArrivals(t *testing.T) {
+func TestLoad_EmptyInterArrivals(t *testing.T) {
 	tmpDir := t.TempDir()
 	tmpFile := filepath.Join(tmpDir, "empty.json")
 
-	data := hcsreplay.TimingData{
+	data := Data{
 		TopicID:        "0.0.123",
 		InterArrivalMs: []float64{}, // Empty
 	}
@@ -92,21 +90,21 @@ func TestLoadTimingData_EmptyInterArrivals(t *testing.T) {
 		t.Fatalf("Failed to write test file: %v", err)
 	}
 
-	_, err := LoadTimingData(tmpFile)
+	_, err := Load(tmpFile)
 	if err == nil {
-		t.Error("LoadTimingData() expected error for empty inter-arrivals, got nil")
+		t.Error("Load() expected error for empty inter-arrivals, got nil")
 	}
 }
 
-func TestNewTimingReplay(t *testing.T) {
-	data := &hcsreplay.TimingData{
+func TestNewReplay(t *testing.T) {
+	data := &Data{
 		TopicID:          "0.0.123",
 		Network:          "testnet",
 		MessageCount:     3,
 		TimeSpanSeconds:  1.0,
 		AvgRatePerSecond: 3.0,
 		InterArrivalMs:   []float64{100, 200, 300},
-		Stats: hcsreplay.Stats{
+		Stats: Stats{
 			MinMs: 100,
 			MaxMs: 300,
 			AvgMs: 200,
@@ -116,9 +114,9 @@ func TestNewTimingReplay(t *testing.T) {
 		},
 	}
 
-	tr := NewTimingReplay(data, "sample", 1.0)
+	tr := NewReplay(data, ModeSample, 1.0)
 	if tr == nil {
-		t.Fatal("NewTimingReplay() returned nil")
+		t.Fatal("NewReplay() returned nil")
 	}
 
 	// Verify effective rate calculation
@@ -128,15 +126,15 @@ func TestNewTimingReplay(t *testing.T) {
 	}
 }
 
-func TestNewTimingReplay_InvalidSpeedup(t *testing.T) {
-	data := &hcsreplay.TimingData{
+func TestNewReplay_InvalidSpeedup(t *testing.T) {
+	data := &Data{
 		TopicID:          "0.0.123",
 		Network:          "testnet",
 		MessageCount:     3,
 		TimeSpanSeconds:  1.0,
 		AvgRatePerSecond: 3.0,
 		InterArrivalMs:   []float64{100, 200, 300},
-		Stats: hcsreplay.Stats{
+		Stats: Stats{
 			MinMs: 100,
 			MaxMs: 300,
 			AvgMs: 200,
@@ -147,28 +145,28 @@ func TestNewTimingReplay_InvalidSpeedup(t *testing.T) {
 	}
 
 	// Zero speedup should default to 1.0
-	tr := NewTimingReplay(data, "sample", 0)
+	tr := NewReplay(data, ModeSample, 0)
 	// Effective rate with speedup 1.0 should equal the original rate
 	if tr.EffectiveRate() != 3.0 {
 		t.Errorf("EffectiveRate() = %f, want 3.0 (default speedup 1.0)", tr.EffectiveRate())
 	}
 
 	// Negative speedup should default to 1.0
-	tr = NewTimingReplay(data, "sample", -5)
+	tr = NewReplay(data, ModeSample, -5)
 	if tr.EffectiveRate() != 3.0 {
 		t.Errorf("EffectiveRate() = %f, want 3.0 (default speedup 1.0)", tr.EffectiveRate())
 	}
 }
 
-func TestTimingReplay_NextDelay_Sequential(t *testing.T) {
-	data := &hcsreplay.TimingData{
+func TestReplay_NextDelay_Sequential(t *testing.T) {
+	data := &Data{
 		TopicID:          "0.0.123",
 		Network:          "testnet",
 		MessageCount:     3,
 		TimeSpanSeconds:  1.0,
 		AvgRatePerSecond: 3.0,
 		InterArrivalMs:   []float64{100, 200, 300},
-		Stats: hcsreplay.Stats{
+		Stats: Stats{
 			MinMs: 100,
 			MaxMs: 300,
 			AvgMs: 200,
@@ -178,7 +176,7 @@ func TestTimingReplay_NextDelay_Sequential(t *testing.T) {
 		},
 	}
 
-	tr := NewTimingReplay(data, "sequential", 1.0)
+	tr := NewReplay(data, ModeSequential, 1.0)
 
 	// Sequential mode should return values in order
 	expected := []time.Duration{
@@ -196,15 +194,15 @@ func TestTimingReplay_NextDelay_Sequential(t *testing.T) {
 	}
 }
 
-func TestTimingReplay_NextDelay_Sample(t *testing.T) {
-	data := &hcsreplay.TimingData{
+func TestReplay_NextDelay_Sample(t *testing.T) {
+	data := &Data{
 		TopicID:          "0.0.123",
 		Network:          "testnet",
 		MessageCount:     3,
 		TimeSpanSeconds:  1.0,
 		AvgRatePerSecond: 3.0,
 		InterArrivalMs:   []float64{100, 200, 300},
-		Stats: hcsreplay.Stats{
+		Stats: Stats{
 			MinMs: 100,
 			MaxMs: 300,
 			AvgMs: 200,
@@ -214,7 +212,7 @@ func TestTimingReplay_NextDelay_Sample(t *testing.T) {
 		},
 	}
 
-	tr := NewTimingReplay(data, "sample", 1.0)
+	tr := NewReplay(data, ModeSample, 1.0)
 
 	// Sample mode should return values from the distribution
 	validDelays := map[time.Duration]bool{
@@ -231,15 +229,15 @@ func TestTimingReplay_NextDelay_Sample(t *testing.T) {
 	}
 }
 
-func TestTimingReplay_NextDelay_Speedup(t *testing.T) {
-	data := &hcsreplay.TimingData{
+func TestReplay_NextDelay_Speedup(t *testing.T) {
+	data := &Data{
 		TopicID:          "0.0.123",
 		Network:          "testnet",
 		MessageCount:     3,
 		TimeSpanSeconds:  1.0,
 		AvgRatePerSecond: 3.0,
 		InterArrivalMs:   []float64{100, 200, 300},
-		Stats: hcsreplay.Stats{
+		Stats: Stats{
 			MinMs: 100,
 			MaxMs: 300,
 			AvgMs: 200,
@@ -250,7 +248,7 @@ func TestTimingReplay_NextDelay_Speedup(t *testing.T) {
 	}
 
 	// 2x speedup means delays should be halved
-	tr := NewTimingReplay(data, "sequential", 2.0)
+	tr := NewReplay(data, ModeSequential, 2.0)
 
 	expected := []time.Duration{
 		50 * time.Millisecond,  // 100 / 2
@@ -266,11 +264,11 @@ func TestTimingReplay_NextDelay_Speedup(t *testing.T) {
 	}
 }
 
-func TestGenerateSyntheticTiming(t *testing.T) {
-	data := GenerateSyntheticTiming(100, 50.0, 20.0)
+func TestGenerateSynthetic(t *testing.T) {
+	data := GenerateSynthetic(100, 50.0, 20.0)
 
 	if data == nil {
-		t.Fatal("GenerateSyntheticTiming() returned nil")
+		t.Fatal("GenerateSynthetic() returned nil")
 	}
 	if data.TopicID != "synthetic" {
 		t.Errorf("TopicID = %q, want %q", data.TopicID, "synthetic")
@@ -304,15 +302,15 @@ func TestGenerateSyntheticTiming(t *testing.T) {
 	}
 }
 
-func TestTimingReplay_Data(t *testing.T) {
-	data := &hcsreplay.TimingData{
+func TestReplay_Data(t *testing.T) {
+	data := &Data{
 		TopicID:          "0.0.123",
 		Network:          "testnet",
 		MessageCount:     3,
 		TimeSpanSeconds:  1.0,
 		AvgRatePerSecond: 3.0,
 		InterArrivalMs:   []float64{100, 200, 300},
-		Stats: hcsreplay.Stats{
+		Stats: Stats{
 			MinMs: 100,
 			MaxMs: 300,
 			AvgMs: 200,
@@ -322,7 +320,7 @@ func TestTimingReplay_Data(t *testing.T) {
 		},
 	}
 
-	tr := NewTimingReplay(data, "sample", 1.0)
+	tr := NewReplay(data, ModeSample, 1.0)
 	retrievedData := tr.Data()
 
 	if retrievedData.TopicID != data.TopicID {
@@ -0,0 +1,190 @@
+package workload
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"time"
+)
+
+// Sampler picks account indexes in [0, n) according to an access pattern.
+// Samplers are not safe for concurrent use.
+type Sampler interface {
+	Next() int
+}
+
+// NewSampler returns a sampler over n accounts following the pattern, drawing
+// randomness from rng. With churn, the working set rotates on the schedule
+// ChurnSchedule returns, timed from the first draw.
+func (a Accounts) NewSampler(rng *rand.Rand, n int) (Sampler, error) {
+	if n < 1 {
+		return nil, fmt.Errorf("no accounts to sample")
+	}
+
+	// The pattern draws ranks in [0, size); churn maps them to accounts
+	size := a.workingSetSize(n)
+	var s Sampler
+	switch a.Pattern {
+	case "", AccountsUniform:
+		s = uniformSampler{rng: rng, n: size}
+	case AccountsZipf:
+		z := rand.NewZipf(rng, a.ZipfS, 1, uint64(size-1))
+		if z == nil {
+			return nil, fmt.Errorf("invalid zipf exponent: %v", a.ZipfS)
+		}
+		s = zipfSampler{z}
+	case AccountsHot:
+		s = hotSampler{rng: rng, n: n, hot: size, weight: a.HotWeight}
+	default:
+		return nil, fmt.Errorf("unknown account pattern: %q", a.Pattern)
+	}
+
+	if a.Churn <= 0 {
+		return s, nil
+	}
+	c, err := a.newChurn(n)
+	if err != nil {
+		return nil, err
+	}
+	c.inner = s
+	c.now = time.Now
+	return c, nil
+}
+
+// workingSetSize returns how many of n accounts the pattern concentrates
+// on: the hot set, the working set, or all of them.
+func (a Accounts) workingSetSize(n int) int {
+	switch {
+	case a.Pattern == AccountsHot:
+		return min(int(math.Ceil(a.HotFraction*float64(n))), n)
+	case a.WorkingSet > 0:
+		return min(max(int(math.Ceil(a.WorkingSet*float64(n))), 1), n)
+	}
+	return n
+}
+
+type uniformSampler struct {
+	rng *rand.Rand
+	n   int
+}
+
+func (s uniformSampler) Next() int {
+	return s.rng.Intn(s.n)
+}
+
+// zipfSampler makes the account at index k the k+1'th most popular.
+type zipfSampler struct {
+	z *rand.Zipf
+}
+
+func (s zipfSampler) Next() int {
+	return int(s.z.Uint64())
+}
+
+// hotSampler sends weight of requests to the first hot accounts and the rest
+// uniformly to the others.
+type hotSampler struct {
+	rng    *rand.Rand
+	n      int
+	hot    int
+	weight float64
+}
+
+func (s hotSampler) Next() int {
+	if s.hot == s.n || s.rng.Float64() < s.weight {
+		return s.rng.Intn(s.hot)
+	}
+	return s.hot + s.rng.Intn(s.n-s.hot)
+}
+
+// Rotation is one change of a churning working set: the accounts, by
+// index, that left it and those that took their place.
+type Rotation struct {
+	At  time.Duration // since the first draw
+	Out []int
+	In  []int
+}
+
+// ChurnSchedule returns the rotations of the working set of n accounts over
+// a run of duration d. The schedule depends only on the settings, n and
+// ChurnSeed, so a run with the same seed and accounts queries the same
+// working sets.
+func (a Accounts) ChurnSchedule(n int, d time.Duration) ([]Rotation, error) {
+	if a.Churn <= 0 {
+		return nil, nil
+	}
+	c, err := a.newChurn(n)
+	if err != nil {
+		return nil, err
+	}
+	var schedule []Rotation
+	for at := a.ChurnInterval; at < d; at += a.ChurnInterval {
+		r := c.rotate()
+		r.At = at
+		schedule = append(schedule, r)
+	}
+	return schedule, nil
+}
+
+// churnSampler rotates the working set of an inner sampler: every interval
+// it swaps a share of the working set for accounts outside it. The inner
+// sampler draws ranks, which perm maps to accounts; the working set is the
+// first size of them.
+type churnSampler struct {
+	inner    Sampler
+	perm     []int
+	size     int
+	replace  int
+	interval time.Duration
+	rng      *rand.Rand // draws the rotations, seeded with ChurnSeed
+	now      func() time.Time
+	start    time.Time // first draw
+	rotated  int       // rotations applied
+}
+
+func (a Accounts) newChurn(n int) (*churnSampler, error) {
+	size := a.workingSetSize(n)
+	if size >= n {
+		return nil, fmt.Errorf("account churn needs a working set smaller than the %d accounts", n)
+	}
+	if a.ChurnInterval <= 0 {
+		return nil, fmt.Errorf("account churn needs a positive interval")
+	}
+	perm := make([]int, n)
+	for i := range perm {
+		perm[i] = i
+	}
+	return &churnSampler{
+		perm:     perm,
+		size:     size,
+		replace:  min(int(math.Round(a.Churn*float64(size))), n-size),
+		interval: a.ChurnInterval,
+		rng:      rand.New(rand.NewSource(a.ChurnSeed)),
+	}, nil
+}
+
+func (s *churnSampler) Next() int {
+	now := s.now()
+	if s.start.IsZero() {
+		s.start = now
+	}
+	for due := int(now.Sub(s.start) / s.interval); s.rotated < due; {
+		s.rotate()
+	}
+	return s.perm[s.inner.Next()]
+}
+
+// rotate swaps replace random members of the working set for random
+// accounts outside it.
+func (s *churnSampler) rotate() Rotation {
+	s.rotated++
+	out := s.rng.Perm(s.size)[:s.replace]
+	in := s.rng.Perm(len(s.perm) - s.size)[:s.replace]
+	r := Rotation{Out: make([]int, s.replace), In: make([]int, s.replace)}
+	for i := range out {
+		a, b := out[i], s.size+in[i]
+		r.Out[i], r.In[i] = s.perm[a], s.perm[b]
+		s.perm[a], s.perm[b] = s.perm[b], s.perm[a]
+	}
+	return r
+}
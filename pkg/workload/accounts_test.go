@@ -0,0 +1,122 @@
+package workload
+
+import (
+	"math/rand"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestSampler_Uniform(t *testing.T) {
+	s, err := Accounts{Pattern: AccountsUniform}.NewSampler(rand.New(rand.NewSource(1)), 10)
+	if err != nil {
+		t.Fatalf("NewSampler() error = %v", err)
+	}
+	counts := make([]int, 10)
+	for i := 0; i < 10000; i++ {
+		counts[s.Next()]++
+	}
+	for i, c := range counts {
+		if c < 800 || c > 1200 {
+			t.Errorf("account %d drawn %d times, want ~1000", i, c)
+		}
+	}
+}
+
+func TestSampler_Zipf(t *testing.T) {
+	s, err := Accounts{Pattern: AccountsZipf, ZipfS: 1.2}.NewSampler(rand.New(rand.NewSource(1)), 1000)
+	if err != nil {
+		t.Fatalf("NewSampler() error = %v", err)
+	}
+	counts := make([]int, 1000)
+	for i := 0; i < 10000; i++ {
+		n := s.Next()
+		if n < 0 || n >= 1000 {
+			t.Fatalf("Next() = %d, out of range", n)
+		}
+		counts[n]++
+	}
+	if counts[0] <= counts[1] || counts[1] <= counts[10] {
+		t.Errorf("zipf counts not decreasing by rank: %d, %d, %d", counts[0], counts[1], counts[10])
+	}
+}
+
+func TestSampler_Hot(t *testing.T) {
+	s, err := Accounts{Pattern: AccountsHot, HotFraction: 0.01, HotWeight: 0.9}.NewSampler(rand.New(rand.NewSource(1)), 1000)
+	if err != nil {
+		t.Fatalf("NewSampler() error = %v", err)
+	}
+	hot := 0
+	for i := 0; i < 10000; i++ {
+		if s.Next() < 10 {
+			hot++
+		}
+	}
+	// 90% of draws should land on the 10 hot accounts
+	if hot < 8800 || hot > 9200 {
+		t.Errorf("hot accounts drew %d of 10000, want ~9000", hot)
+	}
+}
+
+func TestSampler_NoAccounts(t *testing.T) {
+	if _, err := (Accounts{}).NewSampler(rand.New(rand.NewSource(1)), 0); err == nil {
+		t.Error("NewSampler() expected error with no accounts")
+	}
+}
+
+func TestSampler_Churn(t *testing.T) {
+	a := Accounts{Pattern: AccountsHot, HotFraction: 0.1, HotWeight: 1, Churn: 0.5, ChurnInterval: time.Minute, ChurnSeed: 7}
+	s, err := a.NewSampler(rand.New(rand.NewSource(1)), 100)
+	if err != nil {
+		t.Fatalf("NewSampler() error = %v", err)
+	}
+	now := time.Unix(0, 0)
+	s.(*churnSampler).now = func() time.Time { return now }
+
+	drawn := func() map[int]bool {
+		set := map[int]bool{}
+		for i := 0; i < 2000; i++ {
+			set[s.Next()] = true
+		}
+		return set
+	}
+	if set := drawn(); len(set) != 10 || !set[0] || !set[9] {
+		t.Fatalf("drew %v, want the 10 initial hot accounts", set)
+	}
+
+	schedule, err := a.ChurnSchedule(100, 2*time.Minute)
+	if err != nil {
+		t.Fatalf("ChurnSchedule() error = %v", err)
+	}
+	if len(schedule) != 1 || schedule[0].At != time.Minute || len(schedule[0].Out) != 5 {
+		t.Fatalf("ChurnSchedule() = %+v, want one rotation of 5 accounts at 1m", schedule)
+	}
+
+	now = now.Add(time.Minute)
+	set := drawn()
+	if len(set) != 10 {
+		t.Errorf("drew %d accounts after the rotation, want a working set of 10", len(set))
+	}
+	for i, out := range schedule[0].Out {
+		if set[out] || !set[schedule[0].In[i]] {
+			t.Errorf("account %d still drawn or %d not drawn, want the scheduled rotation", out, schedule[0].In[i])
+		}
+	}
+
+	again, _ := a.ChurnSchedule(100, 2*time.Minute)
+	if !slices.Equal(again[0].In, schedule[0].In) {
+		t.Errorf("ChurnSchedule() = %v then %v, want the same schedule for the same seed", schedule, again)
+	}
+}
+
+func TestSampler_WorkingSet(t *testing.T) {
+	s, err := Accounts{Pattern: AccountsUniform, WorkingSet: 0.05}.NewSampler(rand.New(rand.NewSource(1)), 1000)
+	if err != nil {
+		t.Fatalf("NewSampler() error = %v", err)
+	}
+	for i := 0; i < 1000; i++ {
+		if n := s.Next(); n >= 50 {
+			t.Fatalf("Next() = %d, want one of the 50 working set accounts", n)
+		}
+	}
+}
@@ -0,0 +1,523 @@
+// Package workload parses declarative YAML workload definitions: a sequence
+// of stages, each running a weighted mix of operations against one protocol
+// with a chosen arrival process and account access pattern.
+//
+// A minimal workload:
+//
+//	version: 1
+//	name: balance-ramp
+//	protocol: grpc
+//	operations:
+//	  - scenario: balance
+//	stages:
+//	  - name: warmup
+//	    duration: 30s
+//	    concurrency: 5
+//	  - name: peak
+//	    duration: 2m
+//	    concurrency: 50
+//	    rate: 5000
+package workload
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/kaldun-tech/grpc-rest-benchmark/pkg/payload"
+)
+
+// Version is the workload schema version understood by this package.
+const Version = 1
+
+// Operation scenarios. Stream cannot be mixed with other operations.
+const (
+	ScenarioBalance = "balance"
+	ScenarioBatch   = "batch" // several balances in one request
+	ScenarioWrite   = "write" // submit a transaction
+	ScenarioStream  = "stream"
+	ScenarioEcho    = "echo"
+)
+
+// DefaultBatchSize is the number of accounts a batch operation queries when
+// its batch_size is unset.
+const DefaultBatchSize = 10
+
+// Protocols, as accepted by the run subcommand's --protocol flag.
+const (
+	ProtocolGRPC    = "grpc"
+	ProtocolREST    = "rest"
+	ProtocolConnect = "connect"
+	ProtocolGRPCWeb = "grpc-web"
+	ProtocolGraphQL = "graphql"
+	ProtocolJSONRPC = "jsonrpc"
+)
+
+// Arrival processes.
+const (
+	ArrivalClosed  = "closed"  // back to back, or closed-loop paced to the rate if one is set
+	ArrivalPoisson = "poisson" // exponentially distributed gaps averaging the rate
+	ArrivalReplay  = "replay"  // gaps replayed from a timing file
+)
+
+// Account access patterns.
+const (
+	AccountsUniform = "uniform" // every account equally likely
+	AccountsZipf    = "zipf"    // power-law popularity by account rank
+	AccountsHot     = "hot"     // a small hot set receives most requests
+)
+
+var (
+	validScenarios = []string{ScenarioBalance, ScenarioBatch, ScenarioWrite, ScenarioStream, ScenarioEcho}
+	validProtocols = []string{ProtocolGRPC, ProtocolREST, ProtocolConnect, ProtocolGRPCWeb, ProtocolGraphQL, ProtocolJSONRPC}
+	validArrivals  = []string{ArrivalClosed, ArrivalPoisson, ArrivalReplay}
+	validPatterns  = []string{AccountsUniform, AccountsZipf, AccountsHot}
+)
+
+// Workload is a parsed workload definition. Stage fields left unset inherit
+// the workload-level value.
+type Workload struct {
+	Version     int    `yaml:"version"`
+	Name        string `yaml:"name"`
+	Description string `yaml:"description,omitempty"`
+
+	Protocol    string      `yaml:"protocol"`
+	Concurrency int         `yaml:"concurrency,omitempty"`
+	Rate        int         `yaml:"rate,omitempty"`
+	Operations  []Operation `yaml:"operations"`
+	Arrival     Arrival     `yaml:"arrival,omitempty"`
+	Accounts    Accounts    `yaml:"accounts,omitempty"`
+
+	Stages []Stage `yaml:"stages"`
+}
+
+// Stage is one phase of a workload, run and stored as a separate benchmark run.
+type Stage struct {
+	Name        string        `yaml:"name"`
+	Duration    time.Duration `yaml:"duration"`
+	Concurrency int           `yaml:"concurrency,omitempty"`
+	Rate        int           `yaml:"rate,omitempty"`
+	Operations  []Operation   `yaml:"operations,omitempty"`
+}
+
+// Operation is one entry in an operation mix.
+type Operation struct {
+	Scenario    string `yaml:"scenario"`
+	Weight      int    `yaml:"weight,omitempty"`       // relative share of requests, default 1
+	PayloadSize string `yaml:"payload_size,omitempty"` // echo only, e.g. "4KB"
+	BatchSize   int    `yaml:"batch_size,omitempty"`   // batch only, accounts per request, default 10
+}
+
+// Arrival describes when requests are issued.
+type Arrival struct {
+	Process string  `yaml:"process,omitempty"`
+	Timing  string  `yaml:"timing,omitempty"`  // replay only: timing or model JSON file
+	Mode    string  `yaml:"mode,omitempty"`    // replay only: sequential | sample
+	Speedup float64 `yaml:"speedup,omitempty"` // replay only
+}
+
+// Accounts describes which accounts balance queries hit.
+type Accounts struct {
+	Pattern     string  `yaml:"pattern,omitempty"`
+	ZipfS       float64 `yaml:"zipf_s,omitempty"`       // zipf only: exponent, > 1 (default 1.1)
+	HotFraction float64 `yaml:"hot_fraction,omitempty"` // hot only: share of accounts that are hot (default 0.01)
+	HotWeight   float64 `yaml:"hot_weight,omitempty"`   // hot only: share of requests sent to them (default 0.9)
+
+	// Working set churn: the accounts queried rotate over time. The
+	// working set is the hot set, or for uniform and zipf the first
+	// working_set share of the accounts (default all).
+	WorkingSet    float64       `yaml:"working_set,omitempty"`    // uniform and zipf only
+	Churn         float64       `yaml:"churn,omitempty"`          // share of the working set replaced per interval
+	ChurnInterval time.Duration `yaml:"churn_interval,omitempty"` // default 1m
+	ChurnSeed     int64         `yaml:"churn_seed,omitempty"`     // seeds the rotations, 0 for a random seed
+}
+
+// Load reads and validates a workload file.
+func Load(path string) (*Workload, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	w, err := Parse(b)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return w, nil
+}
+
+// Parse decodes and validates a workload definition. Unknown fields are
+// rejected so typos surface before anything runs. When there is more than
+// one problem the error is an errors.Join of one error per problem.
+func Parse(b []byte) (*Workload, error) {
+	dec := yaml.NewDecoder(bytes.NewReader(b))
+	dec.KnownFields(true)
+
+	var w Workload
+	if err := dec.Decode(&w); err != nil {
+		return nil, decodeError(err)
+	}
+	w.applyDefaults()
+	if err := w.Validate(); err != nil {
+		return nil, err
+	}
+	return &w, nil
+}
+
+// decodeError splits YAML type errors into one error per problem, naming
+// unknown fields and suggesting the closest known one.
+func decodeError(err error) error {
+	var te *yaml.TypeError
+	if !errors.As(err, &te) {
+		return fmt.Errorf("invalid workload: %w", err)
+	}
+	errs := make([]error, len(te.Errors))
+	for i, msg := range te.Errors {
+		errs[i] = errors.New(unknownFieldHint(msg))
+	}
+	return errors.Join(errs...)
+}
+
+// unknownFieldPattern matches the yaml.v3 message for a field missing from
+// one of the workload types.
+var unknownFieldPattern = regexp.MustCompile(`^(line \d+): field (\S+) not found in type workload\.(\w+)$`)
+
+// fieldTypes maps the type names in YAML errors to the types they name.
+var fieldTypes = map[string]reflect.Type{
+	"Workload":  reflect.TypeFor[Workload](),
+	"Stage":     reflect.TypeFor[Stage](),
+	"Operation": reflect.TypeFor[Operation](),
+	"Arrival":   reflect.TypeFor[Arrival](),
+	"Accounts":  reflect.TypeFor[Accounts](),
+}
+
+// unknownFieldHint rewrites an unknown field message, adding a suggestion
+// when a known field is a likely typo of it. Other messages are returned
+// unchanged.
+func unknownFieldHint(msg string) string {
+	m := unknownFieldPattern.FindStringSubmatch(msg)
+	if m == nil {
+		return msg
+	}
+	hint := fmt.Sprintf("%s: unknown field %q", m[1], m[2])
+	if t, ok := fieldTypes[m[3]]; ok {
+		if s := closest(m[2], yamlFields(t)); s != "" {
+			hint += fmt.Sprintf(" (did you mean %q?)", s)
+		}
+	}
+	return hint
+}
+
+// yamlFields returns the YAML keys of struct type t.
+func yamlFields(t reflect.Type) []string {
+	fields := make([]string, 0, t.NumField())
+	for f := range t.Fields() {
+		name, _, _ := strings.Cut(f.Tag.Get("yaml"), ",")
+		if name != "" && name != "-" {
+			fields = append(fields, name)
+		}
+	}
+	return fields
+}
+
+// closest returns the candidate within two edits of s, or "" if none is.
+func closest(s string, candidates []string) string {
+	best, bestDist := "", 3
+	for _, c := range candidates {
+		if d := editDistance(s, c); d < bestDist {
+			best, bestDist = c, d
+		}
+	}
+	return best
+}
+
+// editDistance returns the Levenshtein distance between a and b.
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
+
+// applyDefaults fills in optional fields.
+func (w *Workload) applyDefaults() {
+	if w.Concurrency == 0 {
+		w.Concurrency = 10
+	}
+	if w.Arrival.Process == "" {
+		w.Arrival.Process = ArrivalClosed
+	}
+	if w.Arrival.Process == ArrivalReplay {
+		if w.Arrival.Mode == "" {
+			w.Arrival.Mode = "sample"
+		}
+		if w.Arrival.Speedup == 0 {
+			w.Arrival.Speedup = 1
+		}
+	}
+	if w.Accounts.Pattern == "" {
+		w.Accounts.Pattern = AccountsUniform
+	}
+	if w.Accounts.Pattern == AccountsZipf && w.Accounts.ZipfS == 0 {
+		w.Accounts.ZipfS = 1.1
+	}
+	if w.Accounts.Pattern == AccountsHot {
+		if w.Accounts.HotFraction == 0 {
+			w.Accounts.HotFraction = 0.01
+		}
+		if w.Accounts.HotWeight == 0 {
+			w.Accounts.HotWeight = 0.9
+		}
+	}
+	if w.Accounts.Churn > 0 && w.Accounts.ChurnInterval == 0 {
+		w.Accounts.ChurnInterval = time.Minute
+	}
+	setWeights(w.Operations)
+	for i := range w.Stages {
+		setWeights(w.Stages[i].Operations)
+	}
+}
+
+func setWeights(ops []Operation) {
+	for i := range ops {
+		if ops[i].Weight == 0 {
+			ops[i].Weight = 1
+		}
+		if ops[i].Scenario == ScenarioBatch && ops[i].BatchSize == 0 {
+			ops[i].BatchSize = DefaultBatchSize
+		}
+	}
+}
+
+// Validate checks the workload for missing or inconsistent fields. All
+// problems are reported together.
+func (w *Workload) Validate() error {
+	var errs []error
+	add := func(format string, args ...any) {
+		errs = append(errs, fmt.Errorf(format, args...))
+	}
+
+	if w.Version != Version {
+		add("version: must be %d, got %d", Version, w.Version)
+	}
+	if w.Name == "" {
+		add("name: required")
+	}
+	if w.Protocol == "" {
+		add("protocol: required")
+	} else if !slices.Contains(validProtocols, w.Protocol) {
+		add("protocol: %q is not one of %s", w.Protocol, strings.Join(validProtocols, ", "))
+	}
+	if w.Concurrency < 1 {
+		add("concurrency: must be at least 1")
+	}
+	if w.Rate < 0 {
+		add("rate: must not be negative")
+	}
+
+	if !slices.Contains(validArrivals, w.Arrival.Process) {
+		add("arrival.process: %q is not one of %s", w.Arrival.Process, strings.Join(validArrivals, ", "))
+	}
+	if w.Arrival.Process == ArrivalReplay {
+		if w.Arrival.Timing == "" {
+			add("arrival.timing: required for replay arrivals")
+		}
+		if w.Arrival.Mode != "sequential" && w.Arrival.Mode != "sample" {
+			add("arrival.mode: %q is not one of sequential, sample", w.Arrival.Mode)
+		}
+		if w.Arrival.Speedup <= 0 {
+			add("arrival.speedup: must be positive")
+		}
+	} else if w.Arrival.Timing != "" {
+		add("arrival.timing: only used with replay arrivals")
+	}
+
+	if !slices.Contains(validPatterns, w.Accounts.Pattern) {
+		add("accounts.pattern: %q is not one of %s", w.Accounts.Pattern, strings.Join(validPatterns, ", "))
+	}
+	if w.Accounts.Pattern == AccountsZipf && w.Accounts.ZipfS <= 1 {
+		add("accounts.zipf_s: must be greater than 1")
+	}
+	if w.Accounts.Pattern == AccountsHot {
+		if w.Accounts.HotFraction <= 0 || w.Accounts.HotFraction >= 1 {
+			add("accounts.hot_fraction: must be between 0 and 1")
+		}
+		if w.Accounts.HotWeight <= 0 || w.Accounts.HotWeight > 1 {
+			add("accounts.hot_weight: must be between 0 and 1")
+		}
+		if w.Accounts.WorkingSet != 0 {
+			add("accounts.working_set: the hot set is the working set of the hot pattern")
+		}
+	}
+	if w.Accounts.WorkingSet < 0 || w.Accounts.WorkingSet >= 1 {
+		add("accounts.working_set: must be between 0 and 1")
+	}
+	switch {
+	case w.Accounts.Churn < 0 || w.Accounts.Churn > 1:
+		add("accounts.churn: must be between 0 and 1")
+	case w.Accounts.Churn > 0:
+		if w.Accounts.Pattern != AccountsHot && w.Accounts.WorkingSet == 0 {
+			add("accounts.churn: needs a working set smaller than all accounts (hot pattern or working_set)")
+		}
+		if w.Accounts.ChurnInterval < time.Second {
+			add("accounts.churn_interval: must be at least 1s")
+		}
+	case w.Accounts.ChurnInterval != 0 || w.Accounts.ChurnSeed != 0:
+		add("accounts.churn_interval, churn_seed: only used with churn")
+	}
+
+	errs = append(errs, validateOperations("operations", w.Operations, false)...)
+
+	if len(w.Stages) == 0 {
+		add("stages: at least one stage is required")
+	}
+	names := make(map[string]bool)
+	for i, s := range w.Stages {
+		prefix := fmt.Sprintf("stages[%d]", i)
+		if s.Name == "" {
+			add("%s.name: required", prefix)
+		} else if names[s.Name] {
+			add("%s.name: duplicate stage name %q", prefix, s.Name)
+		}
+		names[s.Name] = true
+		if s.Duration < time.Second {
+			add("%s.duration: must be at least 1s", prefix)
+		}
+		if s.Concurrency < 0 {
+			add("%s.concurrency: must not be negative", prefix)
+		}
+		if s.Rate < 0 {
+			add("%s.rate: must not be negative", prefix)
+		}
+		errs = append(errs, validateOperations(prefix+".operations", s.Operations, true)...)
+
+		ops := w.StageOperations(s)
+		if w.Arrival.Process == ArrivalPoisson && w.StageRate(s) == 0 && !isStream(ops) {
+			add("%s: poisson arrivals need a rate", prefix)
+		}
+		if w.Arrival.Process == ArrivalReplay && w.StageRate(s) > 0 && !isStream(ops) {
+			add("%s: rate and replay arrivals cannot be combined", prefix)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// validateOperations checks an operation mix. Stage mixes may be empty, in
+// which case the workload mix applies.
+func validateOperations(field string, ops []Operation, optional bool) []error {
+	var errs []error
+	if len(ops) == 0 {
+		if !optional {
+			errs = append(errs, fmt.Errorf("%s: at least one operation is required", field))
+		}
+		return errs
+	}
+
+	for i, op := range ops {
+		prefix := fmt.Sprintf("%s[%d]", field, i)
+		if !slices.Contains(validScenarios, op.Scenario) {
+			errs = append(errs, fmt.Errorf("%s.scenario: %q is not one of %s", prefix, op.Scenario, strings.Join(validScenarios, ", ")))
+		}
+		if op.Weight < 0 {
+			errs = append(errs, fmt.Errorf("%s.weight: must not be negative", prefix))
+		}
+		if op.PayloadSize != "" {
+			if op.Scenario != ScenarioEcho {
+				errs = append(errs, fmt.Errorf("%s.payload_size: only used by echo", prefix))
+			} else if _, err := payload.ParseSize(op.PayloadSize); err != nil {
+				errs = append(errs, fmt.Errorf("%s.payload_size: %w", prefix, err))
+			}
+		}
+		if op.BatchSize != 0 {
+			if op.Scenario != ScenarioBatch {
+				errs = append(errs, fmt.Errorf("%s.batch_size: only used by batch", prefix))
+			} else if op.BatchSize < 0 {
+				errs = append(errs, fmt.Errorf("%s.batch_size: must be positive", prefix))
+			}
+		}
+	}
+	if len(ops) > 1 && isStreamMix(ops) {
+		errs = append(errs, fmt.Errorf("%s: stream cannot be mixed with other operations", field))
+	}
+	return errs
+}
+
+func isStreamMix(ops []Operation) bool {
+	return slices.ContainsFunc(ops, func(op Operation) bool { return op.Scenario == ScenarioStream })
+}
+
+func isStream(ops []Operation) bool {
+	return len(ops) == 1 && ops[0].Scenario == ScenarioStream
+}
+
+// StageOperations returns the operation mix for stage s.
+func (w *Workload) StageOperations(s Stage) []Operation {
+	if len(s.Operations) > 0 {
+		return s.Operations
+	}
+	return w.Operations
+}
+
+// StageConcurrency returns the concurrency for stage s.
+func (w *Workload) StageConcurrency(s Stage) int {
+	if s.Concurrency > 0 {
+		return s.Concurrency
+	}
+	return w.Concurrency
+}
+
+// StageRate returns the target rate for stage s, zero if unpaced.
+func (w *Workload) StageRate(s Stage) int {
+	if s.Rate > 0 {
+		return s.Rate
+	}
+	return w.Rate
+}
+
+// Duration returns the total duration of all stages.
+func (w *Workload) Duration() time.Duration {
+	var d time.Duration
+	for _, s := range w.Stages {
+		d += s.Duration
+	}
+	return d
+}
+
+// UsesAccounts reports whether any stage picks seeded accounts: for balance
+// or batch queries, or as the parties of submitted transactions.
+func (w *Workload) UsesAccounts() bool {
+	return w.UsesScenario(ScenarioBalance) || w.UsesScenario(ScenarioBatch) || w.UsesScenario(ScenarioWrite)
+}
+
+// UsesScenario reports whether any stage runs the named scenario.
+func (w *Workload) UsesScenario(scenario string) bool {
+	for _, s := range w.Stages {
+		for _, op := range w.StageOperations(s) {
+			if op.Scenario == scenario {
+				return true
+			}
+		}
+	}
+	return false
+}
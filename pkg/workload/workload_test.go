@@ -0,0 +1,220 @@
+package workload
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+const minimal = `
+version: 1
+name: test
+protocol: grpc
+operations:
+  - scenario: balance
+stages:
+  - name: only
+    duration: 10s
+`
+
+func TestParse_Defaults(t *testing.T) {
+	w, err := Parse([]byte(minimal))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if w.Concurrency != 10 {
+		t.Errorf("Concurrency = %d, want default 10", w.Concurrency)
+	}
+	if w.Arrival.Process != ArrivalClosed {
+		t.Errorf("Arrival.Process = %q, want %q", w.Arrival.Process, ArrivalClosed)
+	}
+	if w.Accounts.Pattern != AccountsUniform {
+		t.Errorf("Accounts.Pattern = %q, want %q", w.Accounts.Pattern, AccountsUniform)
+	}
+	if w.Operations[0].Weight != 1 {
+		t.Errorf("Operations[0].Weight = %d, want default 1", w.Operations[0].Weight)
+	}
+	if got := w.Stages[0].Duration; got != 10*time.Second {
+		t.Errorf("Stages[0].Duration = %v, want 10s", got)
+	}
+}
+
+func TestParse_ReadWriteMix(t *testing.T) {
+	yaml := strings.Replace(minimal, "  - scenario: balance",
+		"  - scenario: balance\n    weight: 8\n  - scenario: batch\n  - scenario: write", 1)
+	w, err := Parse([]byte(yaml))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if got := w.Operations[1].BatchSize; got != DefaultBatchSize {
+		t.Errorf("batch BatchSize = %d, want default %d", got, DefaultBatchSize)
+	}
+	if got := w.Operations[2].BatchSize; got != 0 {
+		t.Errorf("write BatchSize = %d, want 0", got)
+	}
+	if !w.UsesAccounts() {
+		t.Error("UsesAccounts() = false for a balance, batch and write mix")
+	}
+}
+
+func TestParse_StageInheritance(t *testing.T) {
+	w, err := Parse([]byte(`
+version: 1
+name: test
+protocol: rest
+concurrency: 4
+rate: 100
+operations:
+  - scenario: balance
+stages:
+  - name: inherit
+    duration: 5s
+  - name: override
+    duration: 5s
+    concurrency: 8
+    rate: 200
+    operations:
+      - scenario: echo
+        payload_size: 4KB
+`))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	inherit, override := w.Stages[0], w.Stages[1]
+	if w.StageConcurrency(inherit) != 4 || w.StageRate(inherit) != 100 {
+		t.Errorf("inherit stage = concurrency %d rate %d, want 4 and 100", w.StageConcurrency(inherit), w.StageRate(inherit))
+	}
+	if w.StageConcurrency(override) != 8 || w.StageRate(override) != 200 {
+		t.Errorf("override stage = concurrency %d rate %d, want 8 and 200", w.StageConcurrency(override), w.StageRate(override))
+	}
+	if ops := w.StageOperations(override); len(ops) != 1 || ops[0].Scenario != ScenarioEcho {
+		t.Errorf("override operations = %+v, want echo", ops)
+	}
+	if !w.UsesScenario(ScenarioEcho) || w.UsesScenario(ScenarioStream) {
+		t.Error("UsesScenario() does not reflect stage operations")
+	}
+	if w.Duration() != 10*time.Second {
+		t.Errorf("Duration() = %v, want 10s", w.Duration())
+	}
+}
+
+func TestParse_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		yaml    string
+		wantErr string
+	}{
+		{
+			name:    "unknown field",
+			yaml:    minimal + "concurency: 5\n",
+			wantErr: `line 10: unknown field "concurency" (did you mean "concurrency"?)`,
+		},
+		{
+			name:    "unknown stage field",
+			yaml:    minimal + "    rps: 5\n",
+			wantErr: `line 10: unknown field "rps"`,
+		},
+		{
+			name:    "wrong version",
+			yaml:    strings.Replace(minimal, "version: 1", "version: 2", 1),
+			wantErr: "version: must be 1",
+		},
+		{
+			name:    "unknown protocol",
+			yaml:    strings.Replace(minimal, "protocol: grpc", "protocol: http", 1),
+			wantErr: `protocol: "http" is not one of`,
+		},
+		{
+			name:    "unknown scenario",
+			yaml:    strings.Replace(minimal, "scenario: balance", "scenario: transfer", 1),
+			wantErr: `operations[0].scenario: "transfer"`,
+		},
+		{
+			name:    "short stage",
+			yaml:    strings.Replace(minimal, "duration: 10s", "duration: 500ms", 1),
+			wantErr: "stages[0].duration",
+		},
+		{
+			name: "stream in a mix",
+			yaml: strings.Replace(minimal, "  - scenario: balance",
+				"  - scenario: balance\n  - scenario: stream", 1),
+			wantErr: "stream cannot be mixed",
+		},
+		{
+			name:    "payload size on balance",
+			yaml:    strings.Replace(minimal, "  - scenario: balance", "  - scenario: balance\n    payload_size: 1KB", 1),
+			wantErr: "payload_size: only used by echo",
+		},
+		{
+			name:    "batch size on write",
+			yaml:    strings.Replace(minimal, "  - scenario: balance", "  - scenario: write\n    batch_size: 5", 1),
+			wantErr: "batch_size: only used by batch",
+		},
+		{
+			name:    "poisson without rate",
+			yaml:    minimal + "arrival:\n  process: poisson\n",
+			wantErr: "poisson arrivals need a rate",
+		},
+		{
+			name:    "replay without timing",
+			yaml:    minimal + "arrival:\n  process: replay\n",
+			wantErr: "arrival.timing: required",
+		},
+		{
+			name:    "bad hot fraction",
+			yaml:    minimal + "accounts:\n  pattern: hot\n  hot_fraction: 2\n",
+			wantErr: "accounts.hot_fraction",
+		},
+		{
+			name:    "churn of all accounts",
+			yaml:    minimal + "accounts:\n  churn: 0.1\n",
+			wantErr: "accounts.churn: needs a working set",
+		},
+		{
+			name:    "churn interval without churn",
+			yaml:    minimal + "accounts:\n  pattern: hot\n  churn_interval: 30s\n",
+			wantErr: "only used with churn",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse([]byte(tt.yaml))
+			if err == nil {
+				t.Fatal("Parse() expected error")
+			}
+			if !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Parse() error = %v, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidate_ReportsAllProblems(t *testing.T) {
+	_, err := Parse([]byte("version: 1\nstages: []\n"))
+	if err == nil {
+		t.Fatal("Parse() expected error")
+	}
+	for _, want := range []string{"name: required", "protocol: required", "operations: at least one", "stages: at least one"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error %q is missing %q", err, want)
+		}
+	}
+}
+
+func TestLoad_ExampleWorkloads(t *testing.T) {
+	paths, err := filepath.Glob("../../workloads/*.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(paths) == 0 {
+		t.Skip("no example workloads")
+	}
+	for _, path := range paths {
+		if _, err := Load(path); err != nil {
+			t.Errorf("Load(%s) error = %v", path, err)
+		}
+	}
+}
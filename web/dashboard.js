@@ -1,20 +1,52 @@
 // Dashboard state
 let latencyChart = null;
 let throughputChart = null;
+let concurrencyThroughputChart = null;
+let concurrencyLatencyChart = null;
+let errorChart = null;
+let trendChart = null;
 let allResults = [];
 
+// Experiment shared by URL (/?experiment=ID), limiting results to its runs
+const experimentId = new URLSearchParams(location.search).get('experiment');
+
+// Suite shared by URL (/?suite=ID), limiting results to the runs of one run --config
+const suiteId = new URLSearchParams(location.search).get('suite');
+
 // Colors for protocols
 const COLORS = {
     grpc: 'rgba(66, 133, 244, 0.8)',
     rest: 'rgba(234, 67, 53, 0.8)'
 };
 
-// Fetch results from API
-async function fetchResults(filters = {}) {
+// Colors of the event markers on the trend chart, by event kind
+const EVENT_COLORS = {
+    deploy: 'rgba(52, 168, 83, 0.8)',
+    config: 'rgba(251, 188, 4, 0.9)',
+    reseed: 'rgba(153, 102, 255, 0.8)',
+    note: 'rgba(128, 128, 128, 0.8)'
+};
+
+// Colors for error types; other gRPC codes and HTTP classes share the last
+const ERROR_COLORS = {
+    timeout: 'rgba(255, 159, 64, 0.8)',
+    connection_refused: 'rgba(153, 102, 255, 0.8)',
+    http_5xx: 'rgba(255, 99, 132, 0.8)',
+    http_4xx: 'rgba(255, 206, 86, 0.8)',
+    canceled: 'rgba(201, 203, 207, 0.8)',
+    other: 'rgba(100, 100, 100, 0.8)'
+};
+const GRPC_ERROR_COLOR = 'rgba(54, 162, 235, 0.8)';
+
+// Fetch results from API, averaged per concurrency level when groupBy is set
+async function fetchResults(filters = {}, groupBy = '') {
     const params = new URLSearchParams();
     if (filters.scenario) params.set('scenario', filters.scenario);
     if (filters.protocol) params.set('protocol', filters.protocol);
     if (filters.client) params.set('client', filters.client);
+    if (experimentId) params.set('experiment', experimentId);
+    if (suiteId) params.set('suite_id', suiteId);
+    if (groupBy) params.set('group_by', groupBy);
 
     const url = `/api/v1/results?${params.toString()}`;
     const response = await fetch(url);
@@ -24,6 +56,85 @@ async function fetchResults(filters = {}) {
     return response.json();
 }
 
+// Fetch the downsampled history of a metric per configuration
+async function fetchTrends(filters = {}, metric = 'p99', window = '90d') {
+    const params = new URLSearchParams({ metric: metric, window: window });
+    if (filters.scenario) params.set('scenario', filters.scenario);
+    if (filters.protocol) params.set('protocol', filters.protocol);
+    if (filters.client) params.set('client', filters.client);
+
+    const response = await fetch(`/api/v1/trends?${params.toString()}`);
+    if (!response.ok) {
+        throw new Error(`HTTP ${response.status}: ${response.statusText}`);
+    }
+    return response.json();
+}
+
+// Fetch the operational events (deploys, config changes, reseeds) of the
+// trend window
+async function fetchEvents(window = '90d') {
+    const params = new URLSearchParams({ window: window });
+    const response = await fetch(`/api/v1/events?${params.toString()}`);
+    if (!response.ok) {
+        throw new Error(`HTTP ${response.status}: ${response.statusText}`);
+    }
+    return response.json();
+}
+
+// Fetch an experiment's name, description and hypothesis
+async function fetchExperiment(id) {
+    const response = await fetch(`/api/v1/experiments/${encodeURIComponent(id)}`);
+    if (!response.ok) {
+        throw new Error(`HTTP ${response.status}: ${response.statusText}`);
+    }
+    return response.json();
+}
+
+// Show which experiment the dashboard is limited to
+async function showExperiment() {
+    const section = document.getElementById('experiment');
+    try {
+        const e = await fetchExperiment(experimentId);
+        document.getElementById('experiment-name').textContent = e.name;
+        document.getElementById('experiment-description').textContent = e.description;
+        document.getElementById('experiment-hypothesis').textContent =
+            e.hypothesis ? `Hypothesis: ${e.hypothesis}` : '';
+        document.getElementById('experiment-report').href = `/api/v1/experiments/${e.id}/report`;
+    } catch (error) {
+        console.error('Failed to fetch experiment:', error);
+        document.getElementById('experiment-name').textContent =
+            `Experiment ${experimentId} could not be loaded: ${error.message}`;
+    }
+    section.hidden = false;
+}
+
+// Fetch a suite's name, description and run count
+async function fetchSuite(id) {
+    const response = await fetch(`/api/v1/suites/${encodeURIComponent(id)}`);
+    if (!response.ok) {
+        throw new Error(`HTTP ${response.status}: ${response.statusText}`);
+    }
+    return response.json();
+}
+
+// Show which suite the dashboard is limited to
+async function showSuite() {
+    const section = document.getElementById('suite');
+    try {
+        const report = await fetchSuite(suiteId);
+        const s = report.suite;
+        document.getElementById('suite-name').textContent = `${s.name} (${s.id})`;
+        document.getElementById('suite-description').textContent = s.description;
+        document.getElementById('suite-runs').textContent =
+            `${s.runs} run${s.runs === 1 ? '' : 's'} started ${new Date(s.created_at).toLocaleString()}`;
+    } catch (error) {
+        console.error('Failed to fetch suite:', error);
+        document.getElementById('suite-name').textContent =
+            `Suite ${suiteId} could not be loaded: ${error.message}`;
+    }
+    section.hidden = false;
+}
+
 // Get current filter values
 function getFilters() {
     return {
@@ -62,6 +173,23 @@ function updateSummary(results) {
     }
 }
 
+// Warn when the results were measured on different seeded datasets, which
+// makes them non-comparable across datasets
+function updateDatasetWarning(results) {
+    const warning = document.getElementById('dataset-warning');
+    const runs = {};
+    for (const r of results) {
+        if (r.dataset_fingerprint) {
+            (runs[r.dataset_fingerprint] = runs[r.dataset_fingerprint] || []).push(r.run_id);
+        }
+    }
+    const fingerprints = Object.keys(runs);
+    warning.hidden = fingerprints.length < 2;
+    warning.textContent = warning.hidden ? '' :
+        `These runs used ${fingerprints.length} different datasets and are not comparable across them: ` +
+        fingerprints.map(f => `runs ${runs[f].join(', ')} (${f})`).join('; ');
+}
+
 // Render latency chart
 function renderLatencyChart(results) {
     const ctx = document.getElementById('latency-chart').getContext('2d');
@@ -205,6 +333,246 @@ function renderThroughputChart(results) {
     });
 }
 
+// Render failed requests per error type as stacked bars, summed over the
+// latest run of each configuration
+function renderErrorChart(results) {
+    const ctx = document.getElementById('error-chart').getContext('2d');
+
+    // Destroy existing chart
+    if (errorChart) {
+        errorChart.destroy();
+    }
+
+    const latestResults = getLatestPerConfig(results);
+    const labels = [...new Set(latestResults.map(r => `${r.protocol}-${r.client}`))];
+    const types = [...new Set(latestResults.flatMap(r => Object.keys(r.errors || {})))].sort();
+
+    const datasets = types.map(type => {
+        const color = ERROR_COLORS[type] || GRPC_ERROR_COLOR;
+        return {
+            label: type,
+            data: labels.map(label => latestResults
+                .filter(r => `${r.protocol}-${r.client}` === label)
+                .reduce((sum, r) => sum + ((r.errors || {})[type] || 0), 0)),
+            backgroundColor: color,
+            borderColor: color.replace('0.8', '1'),
+            borderWidth: 1
+        };
+    });
+
+    errorChart = new Chart(ctx, {
+        type: 'bar',
+        data: {
+            labels: labels,
+            datasets: datasets
+        },
+        options: {
+            responsive: true,
+            plugins: {
+                legend: {
+                    position: 'top'
+                }
+            },
+            scales: {
+                x: {
+                    stacked: true
+                },
+                y: {
+                    stacked: true,
+                    beginAtZero: true,
+                    title: {
+                        display: true,
+                        text: 'Failed requests'
+                    }
+                }
+            }
+        }
+    });
+}
+
+// Draws a dashed vertical line at each event inside the trend chart's
+// time range, labelled with its kind. Their messages are listed below the
+// chart.
+const eventMarkers = {
+    id: 'eventMarkers',
+    afterDatasetsDraw(chart, args, options) {
+        const { ctx, chartArea, scales } = chart;
+        ctx.save();
+        ctx.font = '11px sans-serif';
+        ctx.textAlign = 'left';
+        for (const e of options.events || []) {
+            const x = scales.x.getPixelForValue(Date.parse(e.occurred_at));
+            if (x < chartArea.left || x > chartArea.right) continue;
+            const color = EVENT_COLORS[e.kind] || EVENT_COLORS.note;
+            ctx.strokeStyle = color;
+            ctx.fillStyle = color;
+            ctx.setLineDash([4, 4]);
+            ctx.beginPath();
+            ctx.moveTo(x, chartArea.top);
+            ctx.lineTo(x, chartArea.bottom);
+            ctx.stroke();
+            ctx.fillText(e.kind, x + 3, chartArea.top + 10);
+        }
+        ctx.restore();
+    }
+};
+
+// Render the trend series as one line per configuration over time, with
+// the events of the window marked on it
+function renderTrendChart(trends, events = []) {
+    const ctx = document.getElementById('trend-chart').getContext('2d');
+
+    // Destroy existing chart
+    if (trendChart) {
+        trendChart.destroy();
+    }
+
+    // Name lines by scenario only when several are shown
+    const series = trends.series || [];
+    const multipleScenarios = new Set(series.map(s => s.scenario)).size > 1;
+    const datasets = series.map(s => {
+        const color = s.protocol === 'grpc' ? COLORS.grpc : COLORS.rest;
+        return {
+            label: (multipleScenarios ? `${s.scenario} ` : '') + `${s.protocol}-${s.client}`,
+            data: s.points.map(p => ({ x: Date.parse(p.time), y: p.value })),
+            borderColor: color,
+            backgroundColor: color,
+            // Dash the lines of non-Go clients to tell them apart
+            borderDash: s.client === 'go' ? [] : [6, 4],
+            tension: 0.2
+        };
+    });
+
+    trendChart = new Chart(ctx, {
+        type: 'line',
+        data: { datasets: datasets },
+        options: {
+            responsive: true,
+            plugins: {
+                legend: {
+                    position: 'top'
+                },
+                eventMarkers: {
+                    events: events
+                }
+            },
+            scales: {
+                x: {
+                    type: 'linear',
+                    ticks: {
+                        callback: value => new Date(value).toLocaleDateString()
+                    }
+                },
+                y: {
+                    beginAtZero: true,
+                    title: {
+                        display: true,
+                        text: 'p99 Latency (ms)'
+                    }
+                }
+            }
+        },
+        plugins: [eventMarkers]
+    });
+    renderEventList(events);
+}
+
+// List the events marked on the trend chart, newest first
+function renderEventList(events) {
+    const list = document.getElementById('trend-events');
+    list.innerHTML = '';
+    for (const e of [...events].reverse()) {
+        const item = document.createElement('li');
+        const color = EVENT_COLORS[e.kind] || EVENT_COLORS.note;
+        item.innerHTML = `<span class="event-kind" style="color: ${color}"></span> `;
+        item.firstChild.textContent = e.kind;
+        item.append(`${new Date(e.occurred_at).toLocaleString()}: ${e.message}`);
+        list.appendChild(item);
+    }
+}
+
+// Render a metric of the concurrency groups as one line per configuration,
+// so gRPC and REST curves share the chart. Returns the new chart.
+function renderConcurrencyChart(canvasId, existing, groups, metric, yTitle) {
+    const ctx = document.getElementById(canvasId).getContext('2d');
+
+    // Destroy existing chart
+    if (existing) {
+        existing.destroy();
+    }
+
+    // Name lines by scenario only when several are shown
+    const multipleScenarios = new Set(groups.map(g => g.scenario)).size > 1;
+    const series = {};
+    for (const g of groups) {
+        const label = (multipleScenarios ? `${g.scenario} ` : '') + `${g.protocol}-${g.client}`;
+        if (!series[label]) {
+            series[label] = { protocol: g.protocol, client: g.client, points: [] };
+        }
+        series[label].points.push({ x: g.concurrency, y: g[metric] });
+    }
+
+    const datasets = Object.entries(series).map(([label, s]) => {
+        const color = s.protocol === 'grpc' ? COLORS.grpc : COLORS.rest;
+        return {
+            label: label,
+            data: s.points,
+            borderColor: color,
+            backgroundColor: color,
+            // Dash the lines of non-Go clients to tell them apart
+            borderDash: s.client === 'go' ? [] : [6, 4],
+            tension: 0.2
+        };
+    });
+
+    return new Chart(ctx, {
+        type: 'line',
+        data: { datasets: datasets },
+        options: {
+            responsive: true,
+            plugins: {
+                legend: {
+                    position: 'top'
+                }
+            },
+            scales: {
+                x: {
+                    type: 'linear',
+                    title: {
+                        display: true,
+                        text: 'Concurrency'
+                    }
+                },
+                y: {
+                    beginAtZero: true,
+                    title: {
+                        display: true,
+                        text: yTitle
+                    }
+                }
+            }
+        }
+    });
+}
+
+// Format the change against the automatically selected baseline run.
+// Higher p99 latency and lower throughput are regressions.
+function formatVsPrevious(r) {
+    if (r.baseline_run_id == null) {
+        return '-';
+    }
+    const delta = (value, higherIsWorse) => {
+        if (value == null) {
+            return 'n/a';
+        }
+        const worse = higherIsWorse ? value > 0 : value < 0;
+        const sign = value > 0 ? '+' : '';
+        return `<span class="${worse ? 'worse' : 'better'}">${sign}${value.toFixed(1)}%</span>`;
+    };
+    return `#${r.baseline_run_id}: p99 ${delta(r.p99_delta_pct, true)}, ` +
+        `req/s ${delta(r.throughput_delta_pct, false)}`;
+}
+
 // Render results table
 function renderTable(results) {
     const tbody = document.getElementById('results-body');
@@ -227,6 +595,7 @@ function renderTable(results) {
             <td>${r.p90_latency_ms.toFixed(2)}</td>
             <td>${r.p99_latency_ms.toFixed(2)}</td>
             <td>${successRate}</td>
+            <td>${formatVsPrevious(r)}</td>
         `;
         tbody.appendChild(row);
     }
@@ -236,17 +605,30 @@ function renderTable(results) {
 async function refreshDashboard() {
     try {
         const filters = getFilters();
-        const data = await fetchResults(filters);
+        const [data, grouped, trends, events] = await Promise.all([
+            fetchResults(filters),
+            fetchResults(filters, 'concurrency'),
+            fetchTrends(filters),
+            fetchEvents()
+        ]);
         allResults = data.results || [];
+        const groups = grouped.groups || [];
 
         updateSummary(allResults);
+        updateDatasetWarning(allResults);
         renderLatencyChart(allResults);
         renderThroughputChart(allResults);
+        concurrencyThroughputChart = renderConcurrencyChart('concurrency-throughput-chart',
+            concurrencyThroughputChart, groups, 'throughput', 'Requests/sec');
+        concurrencyLatencyChart = renderConcurrencyChart('concurrency-latency-chart',
+            concurrencyLatencyChart, groups, 'p99_latency_ms', 'p99 Latency (ms)');
+        renderErrorChart(allResults);
+        renderTrendChart(trends, events.events || []);
         renderTable(allResults);
     } catch (error) {
         console.error('Failed to fetch results:', error);
         document.getElementById('results-body').innerHTML =
-            `<tr><td colspan="10" class="error">Failed to load results: ${error.message}</td></tr>`;
+            `<tr><td colspan="11" class="error">Failed to load results: ${error.message}</td></tr>`;
     }
 }
 
@@ -259,5 +641,7 @@ document.addEventListener('DOMContentLoaded', () => {
     document.getElementById('client-filter').addEventListener('change', refreshDashboard);
 
     // Initial load
+    if (experimentId) showExperiment();
+    if (suiteId) showSuite();
     refreshDashboard();
 });